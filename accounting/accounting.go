@@ -0,0 +1,449 @@
+// Package accounting meters subscriber traffic for billing. An Aggregator
+// samples the byte and packet counters of each subscriber periodically,
+// turns successive samples into usage deltas, keeps a rolling total per
+// subscriber and hands every interval's usage to a Sink: a CSV file, a
+// RADIUS accounting server or a Kafka topic.
+//
+// Counters are read with GetSubscriberStats, or from the ONU list of the
+// device for subscribers identified by their ONU serial, which takes one
+// request per device rather than one per subscriber. A counter lower than
+// the previous sample means the counters were reset, e.g. by an ONU
+// reboot; the usage of that interval is then counted from zero.
+package accounting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/state"
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
+	"github.com/nanoncore/nano-southbound/workers"
+)
+
+// Defaults applied by New for zero Config fields
+const (
+	DefaultInterval    = 5 * time.Minute
+	DefaultTimeout     = 2 * time.Minute
+	DefaultConcurrency = 8
+	DefaultWindow      = 24 * time.Hour
+)
+
+// keyPrefix is the state.Store namespace of this package
+const keyPrefix = "accounting/"
+
+// Subscriber is a subscriber whose traffic is metered
+type Subscriber struct {
+	// ID identifies the subscriber in records, and is passed to
+	// GetSubscriberStats
+	ID string
+
+	// Device is the name of the device serving the subscriber
+	Device string
+
+	// Serial is the serial of the subscriber's ONU. If set, counters are
+	// read from the ONU list of the device instead of GetSubscriberStats.
+	Serial string
+}
+
+// Record is the usage of one subscriber over one sampling interval
+type Record struct {
+	Subscriber string    `json:"subscriber"`
+	Device     string    `json:"device"`
+	Serial     string    `json:"serial,omitempty"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+
+	BytesUp     uint64 `json:"bytes_up"`
+	BytesDown   uint64 `json:"bytes_down"`
+	PacketsUp   uint64 `json:"packets_up"`
+	PacketsDown uint64 `json:"packets_down"`
+
+	// Reset reports that the counters were reset during the interval
+	Reset bool `json:"reset,omitempty"`
+
+	// SessionStart is when metering of the subscriber started, and
+	// TotalUp and TotalDown the bytes metered since, across resets
+	SessionStart time.Time `json:"session_start"`
+	TotalUp      uint64    `json:"total_up"`
+	TotalDown    uint64    `json:"total_down"`
+}
+
+// Usage is the rolling usage of a subscriber over Config.Window
+type Usage struct {
+	Subscriber  string    `json:"subscriber"`
+	Since       time.Time `json:"since"` // start of the oldest interval counted
+	BytesUp     uint64    `json:"bytes_up"`
+	BytesDown   uint64    `json:"bytes_down"`
+	PacketsUp   uint64    `json:"packets_up"`
+	PacketsDown uint64    `json:"packets_down"`
+}
+
+// Sink receives the usage records of each sampling round
+type Sink interface {
+	WriteUsage(ctx context.Context, records []Record) error
+}
+
+// Config configures an Aggregator
+type Config struct {
+	// Interval between samples. Defaults to DefaultInterval.
+	Interval time.Duration
+
+	// Timeout bounds the sampling of one device, including connecting.
+	// Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// Concurrency is the number of devices sampled at once when Pool is
+	// nil. Defaults to DefaultConcurrency.
+	Concurrency int
+
+	// Pool bounds the devices sampled at once. If nil, a pool of
+	// Concurrency with one task per device is used.
+	Pool *workers.Pool
+
+	// Window is the span of the rolling usage. Defaults to DefaultWindow.
+	Window time.Duration
+
+	// Store persists the last counters of each subscriber, so no usage is
+	// lost across restarts. If nil, the first sample after a start is a
+	// new baseline.
+	Store state.Store
+}
+
+// counters is one sample of a subscriber's counters
+type counters struct {
+	BytesUp     uint64    `json:"bytes_up"`
+	BytesDown   uint64    `json:"bytes_down"`
+	PacketsUp   uint64    `json:"packets_up"`
+	PacketsDown uint64    `json:"packets_down"`
+	At          time.Time `json:"at"`
+}
+
+// meter is the persisted accounting state of a subscriber
+type meter struct {
+	Last         counters  `json:"last"`
+	SessionStart time.Time `json:"session_start"`
+	TotalUp      uint64    `json:"total_up"`
+	TotalDown    uint64    `json:"total_down"`
+
+	loaded  bool
+	history []Record // intervals within the window, oldest first
+}
+
+// onuLister is implemented by drivers that list provisioned ONUs with
+// their traffic counters
+type onuLister interface {
+	GetONUList(ctx context.Context, filter *types.ONUFilter) ([]types.ONUInfo, error)
+}
+
+// Aggregator meters a fixed set of subscribers. It is safe for concurrent
+// use.
+type Aggregator struct {
+	devices     map[string]*types.EquipmentConfig
+	subscribers []Subscriber
+	factory     types.DriverFactory
+	sink        Sink
+	config      Config
+
+	mu     sync.Mutex
+	meters map[string]*meter // by subscriber ID
+}
+
+// New creates an Aggregator for subscribers served by devices. sink may be
+// nil. A driver that is not connected is connected for each sample and
+// disconnected after.
+func New(devices []*types.EquipmentConfig, subscribers []Subscriber, factory types.DriverFactory, sink Sink, config Config) *Aggregator {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	if config.Pool == nil {
+		config.Pool = workers.New(config.Concurrency, 1)
+	}
+	if config.Window <= 0 {
+		config.Window = DefaultWindow
+	}
+	a := &Aggregator{
+		devices:     make(map[string]*types.EquipmentConfig, len(devices)),
+		subscribers: subscribers,
+		factory:     factory,
+		sink:        sink,
+		config:      config,
+		meters:      make(map[string]*meter),
+	}
+	for _, d := range devices {
+		a.devices[d.Name] = d
+	}
+	return a
+}
+
+// Run samples immediately and then every Interval until ctx is cancelled
+func (a *Aggregator) Run(ctx context.Context) error {
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+	for {
+		a.Sample(ctx) //nolint:errcheck // failures are logged
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sample reads the counters of every subscriber, writes the usage since
+// the previous sample to the sink and returns it. Subscribers sampled for
+// the first time only set their baseline. The error joins the failures of
+// devices that could not be read and of the sink; the records of the
+// devices that were read are returned regardless.
+func (a *Aggregator) Sample(ctx context.Context) ([]Record, error) {
+	var names []string
+	byDevice := make(map[string][]Subscriber)
+	for _, s := range a.subscribers {
+		if _, ok := byDevice[s.Device]; !ok {
+			names = append(names, s.Device)
+		}
+		byDevice[s.Device] = append(byDevice[s.Device], s)
+	}
+
+	samples := make([]map[string]counters, len(names))
+	readErrs := make([]error, len(names))
+	poolErrs := a.config.Pool.Each(ctx, names, func(i int) error {
+		samples[i], readErrs[i] = a.read(ctx, names[i], byDevice[names[i]])
+		return nil
+	})
+
+	var errs []error
+	var records []Record
+	for i, name := range names {
+		if err := errors.Join(poolErrs[i], readErrs[i]); err != nil {
+			a.devices[name].Log().Warn("subscriber accounting sample failed", "error", err)
+			errs = append(errs, err)
+		}
+		for _, s := range byDevice[name] {
+			c, ok := samples[i][s.ID]
+			if !ok {
+				continue
+			}
+			r, ok, err := a.update(ctx, s, c)
+			if err != nil {
+				a.devices[name].Log().Warn("subscriber accounting update failed", "subscriber", s.ID, "error", err)
+				errs = append(errs, err)
+			}
+			if ok {
+				records = append(records, r)
+			}
+		}
+	}
+
+	if a.sink != nil && len(records) > 0 {
+		if err := a.sink.WriteUsage(ctx, records); err != nil {
+			slog.Warn("failed to write subscriber usage", "records", len(records), "error", err)
+			errs = append(errs, fmt.Errorf("failed to write usage: %w", err))
+		}
+	}
+	return records, errors.Join(errs...)
+}
+
+// read samples the counters of the subscribers of one device, by
+// subscriber ID
+func (a *Aggregator) read(ctx context.Context, name string, subscribers []Subscriber) (map[string]counters, error) {
+	config, ok := a.devices[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown device %s", name)
+	}
+	ctx, cancel := context.WithTimeout(ctx, a.config.Timeout)
+	defer cancel()
+
+	driver, err := a.factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver for %s: %w", name, err)
+	}
+	if !driver.IsConnected() {
+		if err := driver.Connect(ctx, config); err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", name, err)
+		}
+		defer driver.Disconnect(context.WithoutCancel(ctx)) //nolint:errcheck // best effort
+	}
+
+	sampled := make(map[string]counters, len(subscribers))
+	var onus map[string]types.ONUInfo
+	var listErr error
+	var errs []error
+	for _, s := range subscribers {
+		if s.Serial == "" {
+			stats, err := driver.GetSubscriberStats(ctx, s.ID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to read stats of subscriber %s: %w", s.ID, err))
+				continue
+			}
+			at := stats.Timestamp
+			if at.IsZero() {
+				at = time.Now()
+			}
+			sampled[s.ID] = counters{BytesUp: stats.BytesUp, BytesDown: stats.BytesDown,
+				PacketsUp: stats.PacketsUp, PacketsDown: stats.PacketsDown, At: at}
+			continue
+		}
+
+		if onus == nil && listErr == nil {
+			onus, listErr = listONUs(ctx, driver, name)
+			if listErr != nil {
+				errs = append(errs, listErr)
+			}
+		}
+		if listErr != nil {
+			continue
+		}
+		o, ok := onus[common.SerialKey(s.Serial)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("ONU %s of subscriber %s not found on %s", s.Serial, s.ID, name))
+			continue
+		}
+		sampled[s.ID] = counters{BytesUp: o.BytesUp, BytesDown: o.BytesDown,
+			PacketsUp: o.PacketsUp, PacketsDown: o.PacketsDown, At: time.Now()}
+	}
+	return sampled, errors.Join(errs...)
+}
+
+// listONUs returns the ONUs of a device by serial key (see common.SerialKey)
+func listONUs(ctx context.Context, driver types.Driver, name string) (map[string]types.ONUInfo, error) {
+	lister, ok := driver.(onuLister)
+	if !ok {
+		return nil, fmt.Errorf("%s cannot list ONUs", name)
+	}
+	list, err := lister.GetONUList(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ONUs of %s: %w", name, err)
+	}
+	onus := make(map[string]types.ONUInfo, len(list))
+	for _, o := range list {
+		onus[common.SerialKey(o.Serial)] = o
+	}
+	return onus, nil
+}
+
+// update records a sample of subscriber s and returns the usage since the
+// previous one, if there was one
+func (a *Aggregator) update(ctx context.Context, s Subscriber, c counters) (Record, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m, err := a.meter(ctx, s.ID)
+	if err != nil {
+		return Record{}, false, err
+	}
+	first := m.Last.At.IsZero()
+	if first {
+		m.SessionStart = c.At
+	}
+
+	var r Record
+	if !first {
+		r = Record{
+			Subscriber: s.ID,
+			Device:     s.Device,
+			Serial:     s.Serial,
+			Start:      m.Last.At,
+			End:        c.At,
+		}
+		var reset bool
+		r.BytesUp, reset = delta(m.Last.BytesUp, c.BytesUp)
+		r.Reset = r.Reset || reset
+		r.BytesDown, reset = delta(m.Last.BytesDown, c.BytesDown)
+		r.Reset = r.Reset || reset
+		r.PacketsUp, reset = delta(m.Last.PacketsUp, c.PacketsUp)
+		r.Reset = r.Reset || reset
+		r.PacketsDown, reset = delta(m.Last.PacketsDown, c.PacketsDown)
+		r.Reset = r.Reset || reset
+
+		m.TotalUp += r.BytesUp
+		m.TotalDown += r.BytesDown
+		r.SessionStart, r.TotalUp, r.TotalDown = m.SessionStart, m.TotalUp, m.TotalDown
+
+		m.history = append(m.history, r)
+		cutoff := c.At.Add(-a.config.Window)
+		i := 0
+		for i < len(m.history) && !m.history[i].Start.After(cutoff) {
+			i++
+		}
+		m.history = m.history[i:]
+	}
+	m.Last = c
+
+	if a.config.Store != nil {
+		if err := state.PutJSON(ctx, a.config.Store, keyPrefix+s.ID, m); err != nil {
+			return r, !first, fmt.Errorf("failed to save counters of subscriber %s: %w", s.ID, err)
+		}
+	}
+	return r, !first, nil
+}
+
+// meter returns the accounting state of a subscriber, loading it from the
+// store on first use
+func (a *Aggregator) meter(ctx context.Context, id string) (*meter, error) {
+	m, ok := a.meters[id]
+	if ok && m.loaded {
+		return m, nil
+	}
+	m = &meter{}
+	if a.config.Store != nil {
+		err := state.GetJSON(ctx, a.config.Store, keyPrefix+id, m)
+		if err != nil && !errors.Is(err, state.ErrNotFound) {
+			return nil, fmt.Errorf("failed to load counters of subscriber %s: %w", id, err)
+		}
+	}
+	m.loaded = true
+	a.meters[id] = m
+	return m, nil
+}
+
+// delta returns the increase of a counter from prev to cur. A counter
+// that went down was reset, and cur is what it counted since.
+func delta(prev, cur uint64) (uint64, bool) {
+	if cur < prev {
+		return cur, true
+	}
+	return cur - prev, false
+}
+
+// Usage returns the rolling usage of a subscriber over Window, as of its
+// last sample
+func (a *Aggregator) Usage(subscriber string) Usage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	u := Usage{Subscriber: subscriber}
+	m, ok := a.meters[subscriber]
+	if !ok {
+		return u
+	}
+	for i, r := range m.history {
+		if i == 0 {
+			u.Since = r.Start
+		}
+		u.BytesUp += r.BytesUp
+		u.BytesDown += r.BytesDown
+		u.PacketsUp += r.PacketsUp
+		u.PacketsDown += r.PacketsDown
+	}
+	return u
+}
+
+// UsageAll returns the rolling usage of every subscriber, in subscriber
+// order
+func (a *Aggregator) UsageAll() []Usage {
+	usage := make([]Usage, len(a.subscribers))
+	for i, s := range a.subscribers {
+		usage[i] = a.Usage(s.ID)
+	}
+	return usage
+}
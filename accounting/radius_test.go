@@ -0,0 +1,91 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // RADIUS authenticators are defined with MD5
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// radiusServer answers Accounting-Requests, dropping the first drop of
+// them, and sends the attributes of each answered request on received
+func radiusServer(t *testing.T, secret []byte, drop int) (string, <-chan map[byte][]byte) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	received := make(chan map[byte][]byte, 10)
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			req := append([]byte(nil), buf[:n]...)
+
+			// Verify the Request Authenticator
+			check := append([]byte(nil), req...)
+			copy(check[4:20], make([]byte, 16))
+			sum := md5.Sum(append(check, secret...)) //nolint:gosec // see import
+			if req[0] != radiusAccountingRequest || !bytes.Equal(sum[:], req[4:20]) {
+				t.Errorf("invalid request %x", req)
+				continue
+			}
+			if drop > 0 {
+				drop--
+				continue
+			}
+
+			attrs := make(map[byte][]byte)
+			for a := req[20:]; len(a) >= 2; a = a[a[1]:] {
+				attrs[a[0]] = a[2:a[1]]
+			}
+			received <- attrs
+
+			resp := []byte{radiusAccountingResponse, req[1], 0, 20}
+			h := md5.New() //nolint:gosec // see import
+			h.Write(resp[:4])
+			h.Write(req[4:20])
+			h.Write(secret)
+			resp = append(resp, h.Sum(nil)...)
+			conn.WriteTo(resp, addr) //nolint:errcheck // the client retries
+		}
+	}()
+	return conn.LocalAddr().String(), received
+}
+
+func TestRADIUSSink(t *testing.T) {
+	secret := []byte("testing123")
+	addr, received := radiusServer(t, secret, 1)
+	sink := NewRADIUSSink(RADIUSConfig{Address: addr, Secret: secret, NASIdentifier: "nano", Timeout: 100 * time.Millisecond})
+
+	start := time.Unix(1700000000, 0)
+	r := Record{Subscriber: "sub-1", Start: start, End: start.Add(5 * time.Minute), SessionStart: start,
+		TotalUp: 1000, TotalDown: 5<<32 + 7}
+	if err := sink.WriteUsage(context.Background(), []Record{r}); err != nil {
+		t.Fatal(err)
+	}
+	attrs := <-received
+	u32 := func(t byte) uint32 { return binary.BigEndian.Uint32(attrs[t]) }
+	if string(attrs[attrUserName]) != "sub-1" || string(attrs[attrNASIdentifier]) != "nano" ||
+		u32(attrAcctStatusType) != acctStatusInterimUpdate || u32(attrAcctInputOctets) != 1000 ||
+		u32(attrAcctOutputOctets) != 7 || u32(attrAcctOutputGigawords) != 5 || u32(attrAcctSessionTime) != 300 {
+		t.Errorf("attributes = %v", attrs)
+	}
+}
+
+func TestRADIUSSinkNoResponse(t *testing.T) {
+	secret := []byte("testing123")
+	addr, _ := radiusServer(t, secret, 10)
+	sink := NewRADIUSSink(RADIUSConfig{Address: addr, Secret: secret, Timeout: 20 * time.Millisecond, Retries: 1})
+	if err := sink.WriteUsage(context.Background(), []Record{{Subscriber: "sub-1"}}); err == nil {
+		t.Error("unanswered request did not fail")
+	}
+}
@@ -0,0 +1,89 @@
+package accounting
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CSVSink writes usage records as CSV, with a header row before the first
+// record
+type CSVSink struct {
+	mu     sync.Mutex
+	w      *csv.Writer
+	header bool
+}
+
+var _ Sink = (*CSVSink)(nil)
+
+// NewCSVSink creates a CSVSink writing to w
+func NewCSVSink(w io.Writer) *CSVSink {
+	return &CSVSink{w: csv.NewWriter(w)}
+}
+
+// WriteUsage writes one row per record
+func (s *CSVSink) WriteUsage(_ context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows [][]string
+	if !s.header {
+		rows = append(rows, []string{"subscriber", "device", "serial", "start", "end",
+			"bytes_up", "bytes_down", "packets_up", "packets_down", "reset",
+			"session_start", "total_up", "total_down"})
+	}
+	for _, r := range records {
+		rows = append(rows, []string{r.Subscriber, r.Device, r.Serial,
+			r.Start.UTC().Format(time.RFC3339), r.End.UTC().Format(time.RFC3339),
+			strconv.FormatUint(r.BytesUp, 10), strconv.FormatUint(r.BytesDown, 10),
+			strconv.FormatUint(r.PacketsUp, 10), strconv.FormatUint(r.PacketsDown, 10),
+			strconv.FormatBool(r.Reset), r.SessionStart.UTC().Format(time.RFC3339),
+			strconv.FormatUint(r.TotalUp, 10), strconv.FormatUint(r.TotalDown, 10)})
+	}
+	if err := s.w.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write usage CSV: %w", err)
+	}
+	s.header = true
+	return nil
+}
+
+// Producer sends a message to a Kafka topic. It is implemented with a few
+// lines over any Kafka client, keeping this package free of one.
+type Producer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+// KafkaSink publishes each usage record as a JSON message keyed by
+// subscriber ID, so the records of a subscriber stay in order on one
+// partition
+type KafkaSink struct {
+	producer Producer
+	topic    string
+}
+
+var _ Sink = (*KafkaSink)(nil)
+
+// NewKafkaSink creates a KafkaSink producing to topic
+func NewKafkaSink(producer Producer, topic string) *KafkaSink {
+	return &KafkaSink{producer: producer, topic: topic}
+}
+
+// WriteUsage produces one message per record, stopping at the first
+// failure
+func (s *KafkaSink) WriteUsage(ctx context.Context, records []Record) error {
+	for _, r := range records {
+		value, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to encode usage of %s: %w", r.Subscriber, err)
+		}
+		if err := s.producer.Produce(ctx, s.topic, []byte(r.Subscriber), value); err != nil {
+			return fmt.Errorf("failed to produce usage of %s to %s: %w", r.Subscriber, s.topic, err)
+		}
+	}
+	return nil
+}
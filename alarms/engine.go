@@ -0,0 +1,254 @@
+package alarms
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Source identifies how an alarm was learned
+type Source string
+
+// Alarm sources
+const (
+	SourceCLI  Source = "cli"
+	SourceSNMP Source = "snmp"
+	SourceTrap Source = "trap"
+
+	// SourceEnvironment is fan and power supply failures polled with
+	// GetEnvironment, kept apart from the alarm list of SourceCLI and
+	// SourceSNMP
+	SourceEnvironment Source = "environment"
+)
+
+// Active is an alarm that has been raised and not yet cleared
+type Active struct {
+	Alarm
+
+	Device string `json:"device"`
+
+	// CorrelationID is assigned when the alarm is raised and carried by
+	// its clear, so consumers can pair them
+	CorrelationID string `json:"correlation_id"`
+
+	// Sources currently reporting the alarm
+	Sources []Source `json:"sources"`
+
+	RaisedAt  time.Time  `json:"raised_at"`
+	LastSeen  time.Time  `json:"last_seen"`
+	ClearedAt *time.Time `json:"cleared_at,omitempty"`
+
+	// Count is the number of reports merged into this alarm
+	Count int `json:"count"`
+}
+
+// Transition is an alarm being raised or cleared
+type Transition struct {
+	Cleared bool
+	Alarm   Active
+}
+
+// Config configures an Engine
+type Config struct {
+	// Rules are tried before DefaultRules
+	Rules []Rule
+}
+
+// Engine deduplicates normalized alarms and tracks their lifecycle. Alarms
+// are identified by device, kind and object, so the same condition
+// reported by several sources, or several times by one, is one alarm.
+// Unknown kinds are further told apart by their vendor type. An alarm
+// clears when no source reports it any more.
+type Engine struct {
+	rules []Rule
+
+	mu     sync.Mutex
+	active map[string]*Active                    // key -> alarm
+	polled map[string]map[Source]map[string]bool // device -> source -> keys in the last snapshot
+}
+
+// NewEngine creates an Engine
+func NewEngine(config Config) *Engine {
+	return &Engine{
+		rules:  append(slices.Clone(config.Rules), DefaultRules...),
+		active: make(map[string]*Active),
+		polled: make(map[string]map[Source]map[string]bool),
+	}
+}
+
+// Observe records a snapshot of the active alarms of device from a polled
+// source, e.g. GetAlarms over CLI. Alarms missing from the snapshot are no
+// longer reported by that source. It returns the resulting transitions.
+func (e *Engine) Observe(device string, vendor types.Vendor, source Source, alarms []types.OLTAlarm) []Transition {
+	now := time.Now()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var out []Transition
+	keys := make(map[string]bool, len(alarms))
+	for _, a := range alarms {
+		key, t := e.raise(device, vendor, source, a, now)
+		keys[key] = true
+		if t != nil {
+			out = append(out, *t)
+		}
+	}
+
+	if e.polled[device] == nil {
+		e.polled[device] = make(map[Source]map[string]bool)
+	}
+	prev := e.polled[device][source]
+	e.polled[device][source] = keys
+	for _, key := range sortedKeys(prev) {
+		if !keys[key] {
+			if t := e.withdraw(key, source, now); t != nil {
+				out = append(out, *t)
+			}
+		}
+	}
+	return out
+}
+
+// Raise records an alarm reported by an event source such as a trap
+func (e *Engine) Raise(device string, vendor types.Vendor, source Source, a types.OLTAlarm) []Transition {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, t := e.raise(device, vendor, source, a, time.Now()); t != nil {
+		return []Transition{*t}
+	}
+	return nil
+}
+
+// Clear records the clear of an alarm by an event source. The alarm stays
+// active while other sources still report it.
+func (e *Engine) Clear(device string, vendor types.Vendor, source Source, a types.OLTAlarm) []Transition {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if t := e.withdraw(e.key(device, normalize(vendor, a, e.rules)), source, time.Now()); t != nil {
+		return []Transition{*t}
+	}
+	return nil
+}
+
+// Active returns the active alarms of device, or of every device if device
+// is empty, oldest first
+func (e *Engine) Active(device string) []Active {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var out []Active
+	for _, a := range e.active {
+		if device == "" || a.Device == device {
+			out = append(out, a.clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RaisedAt.Before(out[j].RaisedAt) })
+	return out
+}
+
+// Forget drops the alarms of device without clearing them, e.g. when it is
+// removed
+func (e *Engine) Forget(device string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for key, a := range e.active {
+		if a.Device == device {
+			delete(e.active, key)
+		}
+	}
+	delete(e.polled, device)
+}
+
+// raise adds a report of a to the matching active alarm, or raises a new
+// one. It returns the alarm key and the transition, if any.
+func (e *Engine) raise(device string, vendor types.Vendor, source Source, a types.OLTAlarm, now time.Time) (string, *Transition) {
+	n := normalize(vendor, a, e.rules)
+	key := e.key(device, n)
+	if cur, ok := e.active[key]; ok {
+		cur.LastSeen = now
+		cur.Count++
+		if !slices.Contains(cur.Sources, source) {
+			cur.Sources = append(cur.Sources, source)
+		}
+		// Keep the most severe report
+		if severityRank[n.Severity] > severityRank[cur.Severity] {
+			cur.Severity = n.Severity
+		}
+		return key, nil
+	}
+
+	raisedAt := a.RaisedAt
+	if raisedAt.IsZero() {
+		raisedAt = now
+	}
+	cur := &Active{
+		Alarm:         n,
+		Device:        device,
+		CorrelationID: newCorrelationID(),
+		Sources:       []Source{source},
+		RaisedAt:      raisedAt,
+		LastSeen:      now,
+		Count:         1,
+	}
+	e.active[key] = cur
+	return key, &Transition{Alarm: cur.clone()}
+}
+
+// withdraw removes source from the alarm at key and clears it if no source
+// is left
+func (e *Engine) withdraw(key string, source Source, now time.Time) *Transition {
+	cur, ok := e.active[key]
+	if !ok {
+		return nil
+	}
+	cur.Sources = slices.DeleteFunc(cur.Sources, func(s Source) bool { return s == source })
+	if len(cur.Sources) > 0 {
+		return nil
+	}
+	delete(e.active, key)
+	cur.ClearedAt = &now
+	return &Transition{Cleared: true, Alarm: cur.clone()}
+}
+
+func (e *Engine) key(device string, n Alarm) string {
+	key := device + "|" + string(n.Kind) + "|" + n.Object
+	if n.Kind == KindUnknown {
+		key += "|" + n.Raw.Type
+	}
+	return key
+}
+
+var severityRank = map[Severity]int{
+	SeverityUnknown:  0,
+	SeverityInfo:     1,
+	SeverityWarning:  2,
+	SeverityMinor:    3,
+	SeverityMajor:    4,
+	SeverityCritical: 5,
+}
+
+func (a *Active) clone() Active {
+	c := *a
+	c.Sources = slices.Clone(a.Sources)
+	return c
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newCorrelationID returns a random 64-bit hex ID
+func newCorrelationID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
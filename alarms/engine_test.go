@@ -0,0 +1,83 @@
+package alarms
+
+import (
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestEngineLifecycle(t *testing.T) {
+	e := NewEngine(Config{})
+	los := types.OLTAlarm{ID: "1", Type: "los", SourceID: "0/0/1:5", Severity: "critical"}
+
+	raised := e.Observe("olt-1", types.VendorHuawei, SourceCLI, []types.OLTAlarm{los, los})
+	if len(raised) != 1 || raised[0].Cleared || raised[0].Alarm.CorrelationID == "" {
+		t.Fatalf("raise = %+v, want one raise with a correlation ID", raised)
+	}
+	id := raised[0].Alarm.CorrelationID
+
+	if got := e.Observe("olt-1", types.VendorHuawei, SourceCLI, []types.OLTAlarm{los}); len(got) != 0 {
+		t.Errorf("repeat snapshot produced %+v", got)
+	}
+	active := e.Active("olt-1")
+	if len(active) != 1 || active[0].Count != 3 {
+		t.Fatalf("active = %+v, want one alarm seen 3 times", active)
+	}
+
+	cleared := e.Observe("olt-1", types.VendorHuawei, SourceCLI, nil)
+	if len(cleared) != 1 || !cleared[0].Cleared || cleared[0].Alarm.CorrelationID != id || cleared[0].Alarm.ClearedAt == nil {
+		t.Fatalf("clear = %+v, want clear with correlation ID %s", cleared, id)
+	}
+	if len(e.Active("")) != 0 {
+		t.Error("alarm still active after clear")
+	}
+
+	again := e.Observe("olt-1", types.VendorHuawei, SourceCLI, []types.OLTAlarm{los})
+	if len(again) != 1 || again[0].Alarm.CorrelationID == id {
+		t.Errorf("re-raise = %+v, want a new correlation ID", again)
+	}
+}
+
+func TestEngineDeduplicatesSources(t *testing.T) {
+	e := NewEngine(Config{})
+	cli := types.OLTAlarm{Type: "los", SourceID: "0/0/1:5", Severity: "major"}
+	trap := types.OLTAlarm{Type: "ONT LOSi", Message: "ONT 0/0/1:5 loss of signal", Severity: "critical"}
+
+	if got := e.Raise("olt-1", types.VendorHuawei, SourceTrap, trap); len(got) != 1 {
+		t.Fatalf("trap raise = %+v", got)
+	}
+	if got := e.Observe("olt-1", types.VendorHuawei, SourceCLI, []types.OLTAlarm{cli}); len(got) != 0 {
+		t.Errorf("CLI report of the same alarm produced %+v", got)
+	}
+	active := e.Active("olt-1")
+	if len(active) != 1 || len(active[0].Sources) != 2 || active[0].Severity != SeverityCritical {
+		t.Fatalf("active = %+v, want one critical alarm from two sources", active)
+	}
+
+	if got := e.Clear("olt-1", types.VendorHuawei, SourceTrap, trap); len(got) != 0 {
+		t.Errorf("trap clear while CLI still reports it produced %+v", got)
+	}
+	if got := e.Observe("olt-1", types.VendorHuawei, SourceCLI, nil); len(got) != 1 || !got[0].Cleared {
+		t.Errorf("CLI clear = %+v, want clear", got)
+	}
+}
+
+func TestEngineDevicesAndUnknownKinds(t *testing.T) {
+	e := NewEngine(Config{})
+	a := types.OLTAlarm{Type: "weird-1", Source: "system"}
+	b := types.OLTAlarm{Type: "weird-2", Source: "system"}
+
+	e.Observe("olt-1", types.VendorVSOL, SourceCLI, []types.OLTAlarm{a, b})
+	e.Observe("olt-2", types.VendorVSOL, SourceCLI, []types.OLTAlarm{a})
+	if n := len(e.Active("olt-1")); n != 2 {
+		t.Errorf("olt-1 has %d alarms, want 2 distinct unknown alarms", n)
+	}
+	if n := len(e.Active("")); n != 3 {
+		t.Errorf("got %d alarms in total, want 3", n)
+	}
+
+	e.Forget("olt-1")
+	if n := len(e.Active("")); n != 1 {
+		t.Errorf("got %d alarms after Forget, want 1", n)
+	}
+}
@@ -0,0 +1,200 @@
+// Package alarms maps vendor alarms to a canonical taxonomy and tracks
+// their raise/clear lifecycle.
+//
+// Vendors report the same condition under different names ("LOS", "ONT
+// LOSi", "Loss of signal"), with different severities and object formats.
+// Normalize classifies an OLTAlarm into a Kind with a canonical severity
+// and object, and Engine deduplicates the normalized alarms across the
+// sources that report them (CLI polls, SNMP polls, traps), assigning each
+// alarm a correlation ID that is kept until it clears.
+package alarms
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Kind is a canonical alarm type
+type Kind string
+
+// Canonical alarm kinds
+const (
+	KindLOS          Kind = "los"
+	KindLOF          Kind = "lof"
+	KindDyingGasp    Kind = "dying_gasp"
+	KindRxLow        Kind = "rx_low"
+	KindRxHigh       Kind = "rx_high"
+	KindTxLow        Kind = "tx_low"
+	KindTxHigh       Kind = "tx_high"
+	KindHighTemp     Kind = "high_temp"
+	KindFanFailure   Kind = "fan_failure"
+	KindPowerFailure Kind = "power_failure"
+	KindLinkDown     Kind = "link_down"
+	KindRogueONU     Kind = "rogue_onu"
+	KindONUOffline   Kind = "onu_offline"
+	KindAuthFailure  Kind = "auth_failure"
+	KindConfig       Kind = "config"
+	KindUnknown      Kind = "unknown"
+)
+
+// Severity is a canonical alarm severity
+type Severity string
+
+// Canonical severities
+const (
+	SeverityCritical Severity = "critical"
+	SeverityMajor    Severity = "major"
+	SeverityMinor    Severity = "minor"
+	SeverityWarning  Severity = "warning"
+	SeverityInfo     Severity = "info"
+	SeverityUnknown  Severity = "unknown"
+)
+
+// defaultSeverity is used when the vendor severity is missing or unknown
+var defaultSeverity = map[Kind]Severity{
+	KindLOS:          SeverityCritical,
+	KindLOF:          SeverityMajor,
+	KindDyingGasp:    SeverityMajor,
+	KindRxLow:        SeverityMinor,
+	KindRxHigh:       SeverityMinor,
+	KindTxLow:        SeverityMinor,
+	KindTxHigh:       SeverityMinor,
+	KindHighTemp:     SeverityMajor,
+	KindFanFailure:   SeverityMajor,
+	KindPowerFailure: SeverityCritical,
+	KindLinkDown:     SeverityMajor,
+	KindRogueONU:     SeverityCritical,
+	KindONUOffline:   SeverityMinor,
+	KindAuthFailure:  SeverityWarning,
+	KindConfig:       SeverityInfo,
+}
+
+// Rule classifies alarms whose type and message match Pattern. The text
+// is lowercased and "_" and "-" are replaced by spaces before matching.
+// Rules with a Vendor apply only to that vendor and are tried before
+// generic ones.
+type Rule struct {
+	Vendor  types.Vendor
+	Pattern *regexp.Regexp
+	Kind    Kind
+}
+
+// DefaultRules classify the alarm names used by the supported vendors.
+// Order matters: dying gasp is checked before power, and optical levels
+// before generic power failures.
+var DefaultRules = []Rule{
+	{Pattern: regexp.MustCompile(`dying ?gasp|\bdgi\b`), Kind: KindDyingGasp},
+	{Pattern: regexp.MustCompile(`rogue`), Kind: KindRogueONU},
+	{Pattern: regexp.MustCompile(`\blos[a-z]?\b|loss of signal`), Kind: KindLOS},
+	{Pattern: regexp.MustCompile(`\blof[a-z]?\b|loss of frame`), Kind: KindLOF},
+	{Pattern: regexp.MustCompile(`rx ?(optical ?)?power ?(too ?)?(low|under)|(low|weak) ?rx|receive[a-z ]*power ?low`), Kind: KindRxLow},
+	{Pattern: regexp.MustCompile(`rx ?(optical ?)?power ?(too ?)?(high|over)|high ?rx|receive[a-z ]*power ?high`), Kind: KindRxHigh},
+	{Pattern: regexp.MustCompile(`tx ?(optical ?)?power ?(too ?)?(low|under)|low ?tx|transmit[a-z ]*power ?low`), Kind: KindTxLow},
+	{Pattern: regexp.MustCompile(`tx ?(optical ?)?power ?(too ?)?(high|over)|high ?tx|transmit[a-z ]*power ?high`), Kind: KindTxHigh},
+	{Pattern: regexp.MustCompile(`temp[a-z]* ?(too ?)?(high|over|exceed)|(over|high) ?temp`), Kind: KindHighTemp},
+	{Pattern: regexp.MustCompile(`\bfan`), Kind: KindFanFailure},
+	{Pattern: regexp.MustCompile(`power ?(supply|fail|loss|off)|\bpsu\b|mains`), Kind: KindPowerFailure},
+	{Pattern: regexp.MustCompile(`link ?down|port ?down|\blink\b`), Kind: KindLinkDown},
+	{Pattern: regexp.MustCompile(`offline|deactivat|onu ?down|ont ?down`), Kind: KindONUOffline},
+	{Pattern: regexp.MustCompile(`(auth|login|register)[a-z]* ?fail|illegal|invalid ?(sn|password)`), Kind: KindAuthFailure},
+	{Pattern: regexp.MustCompile(`config`), Kind: KindConfig},
+}
+
+// reObject finds an ONU ("0/1/3:5", "GPON0/3:5") or port ("0/1/3", "0/3")
+// location in an alarm source or message
+var reObject = regexp.MustCompile(`\d+/\d+(?:/\d+)?(?::\d+)?\b`)
+
+// Alarm is a normalized alarm
+type Alarm struct {
+	Kind     Kind     `json:"kind"`
+	Severity Severity `json:"severity"`
+
+	// Object is the affected entity: an ONU ("0/1/3:5"), a port ("0/1/3")
+	// or the alarm source ("system") if no location is reported
+	Object string `json:"object"`
+
+	Message string `json:"message,omitempty"`
+
+	// Raw is the alarm as reported by the vendor adapter
+	Raw types.OLTAlarm `json:"raw"`
+}
+
+// Normalize classifies a vendor alarm using DefaultRules
+func Normalize(vendor types.Vendor, a types.OLTAlarm) Alarm {
+	return normalize(vendor, a, DefaultRules)
+}
+
+func normalize(vendor types.Vendor, a types.OLTAlarm, rules []Rule) Alarm {
+	n := Alarm{
+		Kind:    classify(vendor, a, rules),
+		Object:  object(a),
+		Message: a.Message,
+		Raw:     a,
+	}
+	n.Severity = NormalizeSeverity(a.Severity)
+	if n.Severity == SeverityUnknown {
+		if s, ok := defaultSeverity[n.Kind]; ok {
+			n.Severity = s
+		}
+	}
+	return n
+}
+
+// separators are replaced by spaces before matching, so "ONT_LOSi" and
+// "ont-losi" match the same word boundaries as "ONT LOSi"
+var separators = strings.NewReplacer("_", " ", "-", " ")
+
+// classify returns the Kind of the first matching rule, trying the
+// vendor's rules first
+func classify(vendor types.Vendor, a types.OLTAlarm, rules []Rule) Kind {
+	text := separators.Replace(strings.ToLower(a.Type + " " + a.Message))
+	for _, vendorOnly := range []bool{true, false} {
+		for _, r := range rules {
+			if (r.Vendor != "") != vendorOnly || (vendorOnly && r.Vendor != vendor) {
+				continue
+			}
+			if r.Pattern.MatchString(text) {
+				return r.Kind
+			}
+		}
+	}
+	return KindUnknown
+}
+
+// object returns the canonical location of the alarmed entity
+func object(a types.OLTAlarm) string {
+	for _, s := range []string{a.SourceID, a.Message} {
+		if loc := reObject.FindString(s); loc != "" {
+			return loc
+		}
+	}
+	if a.SourceID != "" {
+		return strings.ToLower(a.SourceID)
+	}
+	if a.Source != "" {
+		return strings.ToLower(a.Source)
+	}
+	return "system"
+}
+
+// NormalizeSeverity maps a vendor severity name to a Severity
+func NormalizeSeverity(s string) Severity {
+	switch s = strings.ToLower(strings.TrimSpace(s)); {
+	case s == "":
+		return SeverityUnknown
+	case strings.HasPrefix(s, "crit"), s == "emergency", s == "alert":
+		return SeverityCritical
+	case strings.HasPrefix(s, "maj"), s == "error":
+		return SeverityMajor
+	case strings.HasPrefix(s, "min"):
+		return SeverityMinor
+	case strings.HasPrefix(s, "warn"):
+		return SeverityWarning
+	case strings.HasPrefix(s, "info"), s == "notice", s == "event":
+		return SeverityInfo
+	default:
+		return SeverityUnknown
+	}
+}
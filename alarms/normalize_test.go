@@ -0,0 +1,85 @@
+package alarms
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		vendor   types.Vendor
+		alarm    types.OLTAlarm
+		kind     Kind
+		severity Severity
+		object   string
+	}{
+		{"huawei los", types.VendorHuawei,
+			types.OLTAlarm{Type: "los", Severity: "critical", SourceID: "0/0/1:5"},
+			KindLOS, SeverityCritical, "0/0/1:5"},
+		{"huawei dying gasp", types.VendorHuawei,
+			types.OLTAlarm{Type: "dying_gasp", SourceID: "0/0/1:7"},
+			KindDyingGasp, SeverityMajor, "0/0/1:7"},
+		{"vsol oamlog losi", types.VendorVSOL,
+			types.OLTAlarm{Type: "onu_losi", Severity: "warning", Source: "system", Message: "GPON0/3:12 ONU LOSi"},
+			KindLOS, SeverityWarning, "0/3:12"},
+		{"loss of signal message", types.VendorVSOL,
+			types.OLTAlarm{Type: "alarm", Message: "Loss of signal on PON 0/1"},
+			KindLOS, SeverityCritical, "0/1"},
+		{"rx power low", types.VendorVSOL,
+			types.OLTAlarm{Type: "power", Severity: "Minor", Message: "Rx power low"},
+			KindRxLow, SeverityMinor, "system"},
+		{"high temperature", types.VendorHuawei,
+			types.OLTAlarm{Type: "Temperature-too-high", Severity: "Major", Source: "system"},
+			KindHighTemp, SeverityMajor, "system"},
+		{"fan", types.VendorCData,
+			types.OLTAlarm{Type: "FAN_FAULT", Severity: "crit"},
+			KindFanFailure, SeverityCritical, "system"},
+		{"unknown", types.VendorHuawei,
+			types.OLTAlarm{Type: "something-odd", Source: "port", SourceID: "eth0"},
+			KindUnknown, SeverityUnknown, "eth0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Normalize(tt.vendor, tt.alarm)
+			if got.Kind != tt.kind || got.Severity != tt.severity || got.Object != tt.object {
+				t.Errorf("Normalize() = %s/%s/%s, want %s/%s/%s",
+					got.Kind, got.Severity, got.Object, tt.kind, tt.severity, tt.object)
+			}
+		})
+	}
+}
+
+func TestVendorRules(t *testing.T) {
+	rules := append([]Rule{
+		{Vendor: types.VendorHuawei, Pattern: regexp.MustCompile(`0x2e112005`), Kind: KindDyingGasp},
+	}, DefaultRules...)
+	alarm := types.OLTAlarm{Type: "0x2e112005", Message: "ONT power off"}
+
+	if got := normalize(types.VendorHuawei, alarm, rules).Kind; got != KindDyingGasp {
+		t.Errorf("huawei kind = %s, want %s", got, KindDyingGasp)
+	}
+	if got := normalize(types.VendorVSOL, alarm, rules).Kind; got != KindPowerFailure {
+		t.Errorf("vsol kind = %s, want %s", got, KindPowerFailure)
+	}
+}
+
+func TestNormalizeSeverity(t *testing.T) {
+	tests := map[string]Severity{
+		"Critical": SeverityCritical,
+		"CRIT":     SeverityCritical,
+		"major":    SeverityMajor,
+		"Minor":    SeverityMinor,
+		"warn":     SeverityWarning,
+		"notice":   SeverityInfo,
+		"":         SeverityUnknown,
+		"bogus":    SeverityUnknown,
+	}
+	for in, want := range tests {
+		if got := NormalizeSeverity(in); got != want {
+			t.Errorf("NormalizeSeverity(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
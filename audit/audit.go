@@ -0,0 +1,173 @@
+// Package audit provides sinks for the configuration change records that
+// drivers emit through types.EquipmentConfig.Audit.
+//
+// Log writes records as JSON lines and chains them: each record carries a
+// sequence number, the hash of the previous record and its own SHA-256
+// hash, so deleting, reordering or editing a record breaks the chain and
+// is detected by Verify.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Log is a tamper-evident AuditSink writing JSON lines
+type Log struct {
+	mu   sync.Mutex
+	w    io.Writer
+	seq  uint64
+	prev string
+}
+
+var _ types.AuditSink = (*Log)(nil)
+
+// NewLog starts a new chain on w
+func NewLog(w io.Writer) *Log {
+	return &Log{w: w}
+}
+
+// OpenFile opens or creates the log at path for appending. The existing
+// records are verified and the chain continues from the last one. Close
+// the Log to close the file.
+func OpenFile(path string) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	last, err := verify(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("audit log %s: %w", path, err)
+	}
+	l := NewLog(f)
+	if last != nil {
+		l.seq, l.prev = last.Seq, last.Hash
+	}
+	return l, nil
+}
+
+// Close closes the underlying writer if it is an io.Closer
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if c, ok := l.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// WriteAudit chains and writes one record
+func (l *Log) WriteAudit(_ context.Context, record *types.AuditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r := *record
+	r.Seq = l.seq + 1
+	r.PrevHash = l.prev
+	r.Hash = ""
+	hash, err := Hash(&r)
+	if err != nil {
+		return err
+	}
+	r.Hash = hash
+
+	line, err := json.Marshal(&r)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	if _, err := l.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	l.seq, l.prev = r.Seq, r.Hash
+	record.Seq, record.PrevHash, record.Hash = r.Seq, r.PrevHash, r.Hash
+	return nil
+}
+
+// Hash returns the hex SHA-256 of record's JSON encoding with Hash empty
+func Hash(record *types.AuditRecord) (string, error) {
+	r := *record
+	r.Hash = ""
+	data, err := json.Marshal(&r)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ErrTampered is returned by Verify when the chain is broken
+var ErrTampered = errors.New("audit log chain is broken")
+
+// Verify checks the chain of the JSON-lines log in r and returns the
+// number of records
+func Verify(r io.Reader) (int, error) {
+	last, err := verify(r)
+	if last == nil {
+		return 0, err
+	}
+	return int(last.Seq), err
+}
+
+func verify(r io.Reader) (*types.AuditRecord, error) {
+	var last *types.AuditRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var rec types.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return last, fmt.Errorf("line %d: %w", line, err)
+		}
+		want, err := Hash(&rec)
+		if err != nil {
+			return last, err
+		}
+		switch {
+		case rec.Hash != want:
+			return last, fmt.Errorf("%w: record %d was modified", ErrTampered, rec.Seq)
+		case last == nil && (rec.Seq != 1 || rec.PrevHash != ""):
+			return last, fmt.Errorf("%w: log starts at record %d", ErrTampered, rec.Seq)
+		case last != nil && (rec.Seq != last.Seq+1 || rec.PrevHash != last.Hash):
+			return last, fmt.Errorf("%w: record %d does not follow record %d", ErrTampered, rec.Seq, last.Seq)
+		}
+		last = &rec
+	}
+	if err := scanner.Err(); err != nil {
+		return last, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return last, nil
+}
+
+// MemorySink keeps records in memory, for tests and for forwarding records
+// elsewhere
+type MemorySink struct {
+	mu      sync.Mutex
+	records []types.AuditRecord
+}
+
+var _ types.AuditSink = (*MemorySink)(nil)
+
+// WriteAudit stores a copy of record
+func (m *MemorySink) WriteAudit(_ context.Context, record *types.AuditRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, *record)
+	return nil
+}
+
+// Records returns the stored records in write order
+func (m *MemorySink) Records() []types.AuditRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]types.AuditRecord(nil), m.records...)
+}
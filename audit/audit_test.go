@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func testRecord(op string) *types.AuditRecord {
+	return &types.AuditRecord{
+		Time:      time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Device:    "olt-1",
+		Vendor:    types.VendorHuawei,
+		Operation: op,
+		Caller:    "alice",
+		Protocol:  "cli",
+		Commands:  []string{"ont add 0 5 sn-auth HWTC00001234"},
+		Success:   true,
+	}
+}
+
+func TestLogVerify(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLog(&buf)
+	for _, op := range []string{"CreateSubscriber", "ApplyProfile", "DeleteSubscriber"} {
+		if err := l.WriteAudit(context.Background(), testRecord(op)); err != nil {
+			t.Fatalf("WriteAudit() error = %v", err)
+		}
+	}
+
+	n, err := Verify(bytes.NewReader(buf.Bytes()))
+	if err != nil || n != 3 {
+		t.Fatalf("Verify() = %d, %v", n, err)
+	}
+
+	lines := strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	tests := map[string]string{
+		"modified":        strings.Replace(buf.String(), "alice", "mallory", 1),
+		"deleted":         lines[0] + lines[2],
+		"reordered":       lines[1] + lines[0] + lines[2],
+		"truncated start": lines[1] + lines[2],
+	}
+	for name, log := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Verify(strings.NewReader(log)); !errors.Is(err, ErrTampered) {
+				t.Errorf("Verify() error = %v, want ErrTampered", err)
+			}
+		})
+	}
+}
+
+func TestOpenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	if err := l.WriteAudit(context.Background(), testRecord("CreateSubscriber")); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	// The chain continues across reopen
+	l, err = OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() reopen error = %v", err)
+	}
+	rec := testRecord("DeleteSubscriber")
+	if err := l.WriteAudit(context.Background(), rec); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+	if rec.Seq != 2 || rec.PrevHash == "" {
+		t.Errorf("record = %+v, want seq 2 chained to seq 1", rec)
+	}
+
+	f, _ := os.Open(path)
+	defer f.Close()
+	if n, err := Verify(f); err != nil || n != 2 {
+		t.Errorf("Verify() = %d, %v", n, err)
+	}
+
+	// A tampered log is refused
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, bytes.Replace(data, []byte("DeleteSubscriber"), []byte("HealthCheck"), 1), 0o600) //nolint:errcheck
+	if _, err := OpenFile(path); !errors.Is(err, ErrTampered) {
+		t.Errorf("OpenFile() on tampered log error = %v", err)
+	}
+}
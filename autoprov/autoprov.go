@@ -0,0 +1,475 @@
+// Package autoprov provisions ONUs as they appear in autofind according to
+// rules, e.g. "ONUs with serial prefix FHTT on OLTs tagged region=north get
+// tier residential-100M and a VLAN from pool north-cvlan".
+//
+// The Engine consumes events.TypeONUDiscovered, as published by
+// events.Tracker from collector.MetricDiscovery samples. The first rule
+// that matches an ONU decides: it creates a subscriber named after the
+// serial on the port the ONU was found on, or with DryRun only logs what
+// it would create. A rule's rate limit holds ONUs back in the queue until
+// the rule may provision again.
+package autoprov
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/events"
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/state"
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
+)
+
+// Defaults applied by New for zero Config and Rule fields
+const (
+	DefaultTimeout    = 2 * time.Minute
+	DefaultRateWindow = time.Hour
+)
+
+// keyPrefix is the state.Store namespace of this package
+const keyPrefix = "autoprov/"
+
+// Operation is the operation of the provisioning events of this package
+const Operation = "autoprov"
+
+// RuleAnnotation is set on the subscribers an Engine creates to the name
+// of the rule that created them
+const RuleAnnotation = "nanoncore.com/autoprov-rule"
+
+// VLANPool is a range of VLANs handed out one per subscriber
+type VLANPool struct {
+	Name  string `json:"name"`
+	First int    `json:"first"`
+	Last  int    `json:"last"`
+}
+
+// Rule decides the provisioning of the discovered ONUs it matches. Empty
+// match fields match every ONU.
+type Rule struct {
+	Name string `json:"name"`
+
+	// SerialPrefix matches the start of the serial in ASCII form, ignoring
+	// case
+	SerialPrefix string `json:"serial_prefix,omitempty"`
+
+	// Model matches the ONU model reported in autofind, ignoring case
+	Model string `json:"model,omitempty"`
+
+	// Devices matches the tags of the OLT, e.g. an OLT group
+	Devices types.Selector `json:"devices,omitempty"`
+
+	// PONPorts limits the rule to these ports
+	PONPorts []string `json:"pon_ports,omitempty"`
+
+	// Tier is the service tier of the subscriber. Required.
+	Tier *model.ServiceTier `json:"tier"`
+
+	// VLAN is the subscriber VLAN, or VLANPool the name of the pool to
+	// take it from. One of them is required.
+	VLAN     int    `json:"vlan,omitempty"`
+	VLANPool string `json:"vlan_pool,omitempty"`
+
+	// DryRun logs the subscriber the rule would create instead of
+	// creating it
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// RateLimit is the number of ONUs the rule tries to provision per
+	// RateWindow, zero for no limit. RateWindow defaults to DefaultRateWindow.
+	RateLimit  int           `json:"rate_limit,omitempty"`
+	RateWindow time.Duration `json:"rate_window,omitempty"`
+}
+
+// matches reports whether the rule applies to an ONU discovered on a
+// device with the given tags
+func (r *Rule) matches(onu discovered, tags map[string]string) bool {
+	// Serials reported in hex or with a separator are matched in ASCII form
+	serial := common.SerialKey(onu.serial)
+	if r.SerialPrefix != "" && !strings.HasPrefix(serial, strings.ToUpper(r.SerialPrefix)) {
+		return false
+	}
+	if r.Model != "" && !strings.EqualFold(r.Model, onu.model) {
+		return false
+	}
+	if len(r.PONPorts) > 0 && !slices.Contains(r.PONPorts, onu.ponPort) {
+		return false
+	}
+	return r.Devices.Matches(tags)
+}
+
+// Config configures an Engine
+type Config struct {
+	// VLANPools are the pools rules take VLANs from
+	VLANPools []VLANPool
+
+	// Store keeps the VLANs allocated from the pools. Defaults to a
+	// state.MemoryStore.
+	Store state.Store
+
+	// Timeout bounds the provisioning of one ONU, including connecting.
+	// Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// TagsOf returns the current tags of a device for Rule.Devices. If
+	// nil, EquipmentConfig.Tags is used.
+	TagsOf func(device *types.EquipmentConfig) map[string]string
+}
+
+// Action is what an Engine did with a discovered ONU
+type Action string
+
+const (
+	// ActionProvisioned means the subscriber was created
+	ActionProvisioned Action = "provisioned"
+	// ActionDryRun means the rule is a dry run and nothing was created
+	ActionDryRun Action = "dry_run"
+	// ActionRateLimited means the rule reached its rate limit; the ONU
+	// stays queued
+	ActionRateLimited Action = "rate_limited"
+	// ActionNoMatch means no rule matched the ONU
+	ActionNoMatch Action = "no_match"
+	// ActionFailed means provisioning failed
+	ActionFailed Action = "failed"
+)
+
+// Decision is the outcome of an ONU taken from the queue
+type Decision struct {
+	Device     string `json:"device"`
+	PONPort    string `json:"pon_port"`
+	Serial     string `json:"serial"`
+	Rule       string `json:"rule,omitempty"`
+	Action     Action `json:"action"`
+	Subscriber string `json:"subscriber,omitempty"`
+	VLAN       int    `json:"vlan,omitempty"`
+	Err        error  `json:"-"`
+}
+
+// discovered is a queued ONU
+type discovered struct {
+	device  string
+	ponPort string
+	serial  string
+	model   string
+}
+
+func (d discovered) key() string {
+	return d.device + "|" + d.ponPort + "|" + common.SerialKey(d.serial)
+}
+
+// Engine applies rules to discovered ONUs. Handle queues ONUs from events
+// and Run or Process works the queue. It is safe for concurrent use.
+type Engine struct {
+	rules     []Rule
+	pools     map[string]VLANPool
+	devices   map[string]*types.EquipmentConfig
+	factory   types.DriverFactory
+	publisher events.Publisher
+	config    Config
+	wake      chan struct{}
+
+	mu      sync.Mutex
+	queue   []discovered
+	queued  map[string]bool
+	history map[string][]time.Time // provisioning times per rule
+
+	processing sync.Mutex // serializes Process
+}
+
+// New creates an Engine for the rules, in order of precedence, on devices.
+// publisher may be nil.
+func New(rules []Rule, devices []*types.EquipmentConfig, factory types.DriverFactory, publisher events.Publisher, config Config) (*Engine, error) {
+	if config.Store == nil {
+		config.Store = state.NewMemoryStore()
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	e := &Engine{
+		pools:     make(map[string]VLANPool, len(config.VLANPools)),
+		devices:   make(map[string]*types.EquipmentConfig, len(devices)),
+		factory:   factory,
+		publisher: publisher,
+		config:    config,
+		wake:      make(chan struct{}, 1),
+		queued:    make(map[string]bool),
+		history:   make(map[string][]time.Time),
+	}
+	for _, p := range config.VLANPools {
+		if p.Name == "" || p.First < 1 || p.Last > 4094 || p.First > p.Last {
+			return nil, fmt.Errorf("invalid VLAN pool %q: %d-%d", p.Name, p.First, p.Last)
+		}
+		e.pools[p.Name] = p
+	}
+	for _, r := range rules {
+		if r.Name == "" || r.Tier == nil {
+			return nil, fmt.Errorf("rule %q: name and tier are required", r.Name)
+		}
+		if (r.VLAN == 0) == (r.VLANPool == "") {
+			return nil, fmt.Errorf("rule %s: exactly one of VLAN and VLAN pool is required", r.Name)
+		}
+		if _, ok := e.pools[r.VLANPool]; r.VLANPool != "" && !ok {
+			return nil, fmt.Errorf("rule %s: unknown VLAN pool %s", r.Name, r.VLANPool)
+		}
+		if r.RateWindow <= 0 {
+			r.RateWindow = DefaultRateWindow
+		}
+		e.rules = append(e.rules, r)
+	}
+	for _, d := range devices {
+		e.devices[d.Name] = d
+	}
+	return e, nil
+}
+
+// Handle queues the ONU of an events.TypeONUDiscovered event. It does not
+// block and can be subscribed to a bus directly:
+//
+//	bus.Subscribe(engine.Handle, events.TypeONUDiscovered)
+//
+// ONUs of devices the Engine does not know, and ONUs already queued, are
+// ignored.
+func (e *Engine) Handle(ev events.Event) {
+	if ev.Type != events.TypeONUDiscovered {
+		return
+	}
+	if _, ok := e.devices[ev.Device]; !ok {
+		return
+	}
+	onu := discovered{device: ev.Device}
+	onu.ponPort, _ = ev.Data["pon_port"].(string)
+	onu.serial, _ = ev.Data["serial"].(string)
+	onu.model, _ = ev.Data["model"].(string)
+	if onu.serial == "" {
+		return
+	}
+
+	e.mu.Lock()
+	if !e.queued[onu.key()] {
+		e.queued[onu.key()] = true
+		e.queue = append(e.queue, onu)
+	}
+	e.mu.Unlock()
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run processes the queue as ONUs are queued, and every minute for ONUs
+// held back by rate limits, until ctx is cancelled
+func (e *Engine) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		e.Process(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process decides every queued ONU and returns the decisions. ONUs held
+// back by a rate limit stay queued; the others leave the queue, so an ONU
+// that failed is only tried again once it is discovered again.
+func (e *Engine) Process(ctx context.Context) []Decision {
+	e.processing.Lock()
+	defer e.processing.Unlock()
+
+	e.mu.Lock()
+	queue := e.queue
+	e.queue = nil
+	e.mu.Unlock()
+
+	var decisions []Decision
+	var held []discovered
+	for _, onu := range queue {
+		if ctx.Err() != nil {
+			held = append(held, onu)
+			continue
+		}
+		d := e.decide(ctx, onu)
+		decisions = append(decisions, d)
+		if d.Action == ActionRateLimited {
+			held = append(held, onu)
+		}
+	}
+
+	e.mu.Lock()
+	e.queue = append(held, e.queue...)
+	e.queued = make(map[string]bool, len(e.queue))
+	for _, onu := range e.queue {
+		e.queued[onu.key()] = true
+	}
+	e.mu.Unlock()
+	return decisions
+}
+
+// decide applies the first matching rule to an ONU
+func (e *Engine) decide(ctx context.Context, onu discovered) Decision {
+	config := e.devices[onu.device]
+	d := Decision{Device: onu.device, PONPort: onu.ponPort, Serial: onu.serial, Action: ActionNoMatch}
+	tags := config.Tags
+	if e.config.TagsOf != nil {
+		tags = e.config.TagsOf(config)
+	}
+	var rule *Rule
+	for i := range e.rules {
+		if e.rules[i].matches(onu, tags) {
+			rule = &e.rules[i]
+			break
+		}
+	}
+	if rule == nil {
+		return d
+	}
+	d.Rule = rule.Name
+	d.Subscriber = subscriberName(onu.serial)
+	log := config.Log().With("rule", rule.Name, "serial", onu.serial, "pon_port", onu.ponPort)
+
+	if !rule.DryRun && !e.allow(rule) {
+		d.Action = ActionRateLimited
+		return d
+	}
+
+	d.VLAN = rule.VLAN
+	if rule.VLANPool != "" {
+		vlan, err := e.allocate(ctx, e.pools[rule.VLANPool], onu.serial, rule.DryRun)
+		if err != nil {
+			d.Action, d.Err = ActionFailed, err
+			log.Warn("auto-provisioning failed", "error", err)
+			return d
+		}
+		d.VLAN = vlan
+	}
+	if rule.DryRun {
+		d.Action = ActionDryRun
+		log.Info("auto-provisioning dry run", "subscriber", d.Subscriber, "tier", rule.Tier.Name, "vlan", d.VLAN)
+		return d
+	}
+
+	sub := &model.Subscriber{
+		Name:        d.Subscriber,
+		Annotations: map[string]string{RuleAnnotation: rule.Name},
+		Spec: model.SubscriberSpec{
+			ONUSerial: onu.serial,
+			VLAN:      d.VLAN,
+			Tier:      rule.Tier.Name,
+		},
+	}
+	err := e.provision(ctx, config, types.PlaceSubscriber(sub, onu.ponPort), rule.Tier)
+	if e.publisher != nil {
+		events.PublishProvisioning(e.publisher, onu.device, Operation, sub, err)
+	}
+	if err != nil {
+		if rule.VLANPool != "" {
+			e.release(context.WithoutCancel(ctx), config, rule.VLANPool, d.VLAN)
+		}
+		d.Action, d.Err = ActionFailed, err
+		log.Warn("auto-provisioning failed", "error", err)
+		return d
+	}
+	d.Action = ActionProvisioned
+	log.Info("auto-provisioned ONU", "subscriber", d.Subscriber, "vlan", d.VLAN)
+	return d
+}
+
+// provision creates a subscriber on a device
+func (e *Engine) provision(ctx context.Context, config *types.EquipmentConfig, sub *model.Subscriber, tier *model.ServiceTier) error {
+	ctx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancel()
+
+	driver, err := e.factory(config)
+	if err != nil {
+		return fmt.Errorf("failed to create driver for %s: %w", config.Name, err)
+	}
+	if !driver.IsConnected() {
+		if err := driver.Connect(ctx, config); err != nil {
+			return fmt.Errorf("failed to connect to %s: %w", config.Name, err)
+		}
+		defer driver.Disconnect(context.WithoutCancel(ctx)) //nolint:errcheck // best effort
+	}
+	_, err = driver.CreateSubscriber(ctx, sub, tier)
+	return err
+}
+
+// allow reports whether a rule is within its rate limit and, if so,
+// counts a provisioning against it
+func (e *Engine) allow(rule *Rule) bool {
+	if rule.RateLimit <= 0 {
+		return true
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := time.Now()
+	recent := e.history[rule.Name][:0]
+	for _, t := range e.history[rule.Name] {
+		if now.Sub(t) < rule.RateWindow {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= rule.RateLimit {
+		e.history[rule.Name] = recent
+		return false
+	}
+	e.history[rule.Name] = append(recent, now)
+	return true
+}
+
+// allocate returns the lowest free VLAN of a pool and records it as taken
+// by serial, unless peek is set. A serial that already holds a VLAN of the
+// pool gets it again.
+func (e *Engine) allocate(ctx context.Context, pool VLANPool, serial string, peek bool) (int, error) {
+	prefix := keyPrefix + "vlans/" + pool.Name + "/"
+	keys, err := e.config.Store.List(ctx, prefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list VLANs of pool %s: %w", pool.Name, err)
+	}
+	taken := make(map[int]bool, len(keys))
+	for _, key := range keys {
+		vlan, err := strconv.Atoi(strings.TrimPrefix(key, prefix))
+		if err != nil {
+			continue
+		}
+		owner, err := e.config.Store.Get(ctx, key)
+		if err != nil && !errors.Is(err, state.ErrNotFound) {
+			return 0, err
+		}
+		if strings.EqualFold(string(owner), serial) {
+			return vlan, nil
+		}
+		taken[vlan] = true
+	}
+	for vlan := pool.First; vlan <= pool.Last; vlan++ {
+		if taken[vlan] {
+			continue
+		}
+		if !peek {
+			if err := e.config.Store.Put(ctx, prefix+strconv.Itoa(vlan), []byte(serial)); err != nil {
+				return 0, fmt.Errorf("failed to allocate VLAN %d of pool %s: %w", vlan, pool.Name, err)
+			}
+		}
+		return vlan, nil
+	}
+	return 0, fmt.Errorf("VLAN pool %s is exhausted", pool.Name)
+}
+
+// release returns a VLAN to its pool
+func (e *Engine) release(ctx context.Context, config *types.EquipmentConfig, pool string, vlan int) {
+	if err := e.config.Store.Delete(ctx, keyPrefix+"vlans/"+pool+"/"+strconv.Itoa(vlan)); err != nil {
+		config.Log().Warn("failed to release VLAN", "pool", pool, "vlan", vlan, "error", err)
+	}
+}
+
+// subscriberName is the name of the subscriber created for a serial
+func subscriberName(serial string) string {
+	return "auto-" + strings.ToLower(serial)
+}
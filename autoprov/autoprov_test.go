@@ -0,0 +1,150 @@
+package autoprov
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/events"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (r *recorder) Publish(e events.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func discoveredEvent(device, ponPort, serial string) events.Event {
+	return events.Event{Type: events.TypeONUDiscovered, Device: device, Data: map[string]any{"pon_port": ponPort, "serial": serial}}
+}
+
+func newEngine(t *testing.T, rules []Rule, publisher events.Publisher) (*Engine, map[string]*testutil.MockDriverV2) {
+	t.Helper()
+	drivers := map[string]*testutil.MockDriverV2{"north-1": {Connected: true}, "south-1": {Connected: true}}
+	devices := []*types.EquipmentConfig{
+		{Name: "north-1", Tags: map[string]string{"region": "north"}},
+		{Name: "south-1", Tags: map[string]string{"region": "south"}},
+	}
+	factory := func(c *types.EquipmentConfig) (types.Driver, error) {
+		d, ok := drivers[c.Name]
+		if !ok {
+			return nil, errors.New("no driver")
+		}
+		return d, nil
+	}
+	e, err := New(rules, devices, factory, publisher, Config{VLANPools: []VLANPool{{Name: "north-cvlan", First: 1001, Last: 1002}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e, drivers
+}
+
+func TestProcess(t *testing.T) {
+	ctx := context.Background()
+	rec := &recorder{}
+	e, drivers := newEngine(t, []Rule{
+		{Name: "fiberhome-north", SerialPrefix: "fhtt", Devices: types.Selector{"region": "north"}, Tier: testutil.NewTestServiceTier(50, 100), VLANPool: "north-cvlan"},
+		{Name: "south-trial", Devices: types.Selector{"region": "south"}, Tier: testutil.NewTestServiceTier(10, 10), VLAN: 300, DryRun: true},
+	}, rec)
+
+	e.Handle(discoveredEvent("north-1", "0/1", "FHTT00000001"))
+	e.Handle(discoveredEvent("north-1", "0/1", "FHTT00000001")) // queued once
+	e.Handle(discoveredEvent("north-1", "0/2", "HWTC00000001"))
+	e.Handle(discoveredEvent("south-1", "0/1", "FHTT00000002"))
+	e.Handle(discoveredEvent("east-1", "0/1", "FHTT00000003")) // unknown device
+
+	decisions := e.Process(ctx)
+	if len(decisions) != 3 {
+		t.Fatalf("Process() = %+v, want 3 decisions", decisions)
+	}
+	if d := decisions[0]; d.Action != ActionProvisioned || d.Rule != "fiberhome-north" || d.VLAN != 1001 || d.Subscriber != "auto-fhtt00000001" {
+		t.Errorf("decisions[0] = %+v", d)
+	}
+	if d := decisions[1]; d.Action != ActionNoMatch {
+		t.Errorf("decisions[1] = %+v", d)
+	}
+	if d := decisions[2]; d.Action != ActionDryRun || d.VLAN != 300 {
+		t.Errorf("decisions[2] = %+v", d)
+	}
+
+	north := drivers["north-1"]
+	if len(north.ONUs) != 1 || north.ONUs[0].PONPort != "0/1" || north.ONUs[0].VLAN != 1001 || north.ONUs[0].BandwidthDown != 100 {
+		t.Errorf("north-1 ONUs = %+v", north.ONUs)
+	}
+	if len(drivers["south-1"].ONUs) != 0 {
+		t.Errorf("dry run provisioned %+v", drivers["south-1"].ONUs)
+	}
+	if len(rec.events) != 1 || rec.events[0].Type != events.TypeProvisioningSucceeded || rec.events[0].Data["operation"] != Operation {
+		t.Errorf("events = %+v", rec.events)
+	}
+	if len(e.Process(ctx)) != 0 {
+		t.Error("Process() of an empty queue returned decisions")
+	}
+
+	// A failure returns the VLAN to the pool
+	north.Faults = map[string]*testutil.Fault{"CreateSubscriber": {Err: errors.New("ONT ID exhausted")}}
+	e.Handle(discoveredEvent("north-1", "0/1", "FHTT00000004"))
+	if d := e.Process(ctx); len(d) != 1 || d[0].Action != ActionFailed || d[0].Err == nil {
+		t.Fatalf("Process() = %+v", d)
+	}
+	north.Faults = nil
+	e.Handle(discoveredEvent("north-1", "0/1", "FHTT00000005"))
+	e.Handle(discoveredEvent("north-1", "0/1", "FHTT00000006"))
+	d := e.Process(ctx)
+	if len(d) != 2 || d[0].VLAN != 1002 || d[1].Action != ActionFailed {
+		t.Errorf("Process() = %+v, want VLAN 1002 reused and the pool exhausted", d)
+	}
+}
+
+func TestProcessRateLimit(t *testing.T) {
+	ctx := context.Background()
+	e, drivers := newEngine(t, []Rule{{Name: "any", Tier: testutil.NewTestServiceTier(50, 100), VLAN: 100, RateLimit: 1}}, nil)
+
+	e.Handle(discoveredEvent("north-1", "0/1", "FHTT00000001"))
+	e.Handle(discoveredEvent("north-1", "0/1", "FHTT00000002"))
+	d := e.Process(ctx)
+	if len(d) != 2 || d[0].Action != ActionProvisioned || d[1].Action != ActionRateLimited {
+		t.Fatalf("Process() = %+v", d)
+	}
+	// The held ONU stays queued
+	if d := e.Process(ctx); len(d) != 1 || d[0].Serial != "FHTT00000002" || d[0].Action != ActionRateLimited {
+		t.Errorf("Process() = %+v", d)
+	}
+	if len(drivers["north-1"].ONUs) != 1 {
+		t.Errorf("ONUs = %+v", drivers["north-1"].ONUs)
+	}
+}
+
+func TestNewValidates(t *testing.T) {
+	tier := testutil.NewTestServiceTier(50, 100)
+	for name, rule := range map[string]Rule{
+		"no tier":      {Name: "r", VLAN: 100},
+		"no vlan":      {Name: "r", Tier: tier},
+		"both vlans":   {Name: "r", Tier: tier, VLAN: 100, VLANPool: "p"},
+		"unknown pool": {Name: "r", Tier: tier, VLANPool: "p"},
+	} {
+		if _, err := New([]Rule{rule}, nil, nil, nil, Config{}); err == nil {
+			t.Errorf("New(%s) error = nil", name)
+		}
+	}
+}
+
+func TestRuleMatchesSerialForms(t *testing.T) {
+	r := &Rule{SerialPrefix: "fhtt"}
+	for _, serial := range []string{"FHTT00000001", "fhtt-00000001", "4648545400000001"} {
+		if !r.matches(discovered{serial: serial}, nil) {
+			t.Errorf("matches(%q) = false, want true", serial)
+		}
+	}
+	if r.matches(discovered{serial: "HWTC00000001"}, nil) {
+		t.Error("matches(HWTC00000001) = true, want false")
+	}
+}
@@ -0,0 +1,226 @@
+// Package backup schedules configuration backups across a set of devices
+// and keeps them in a pluggable Store.
+//
+// Devices are backed up through types.ConfigBackupManager, which vendor
+// adapters implement with NETCONF get-config or CLI "show running-config".
+// Backups are taken inline so the Store holds the configuration itself.
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Defaults applied by New for zero Config fields
+const (
+	DefaultInterval    = 24 * time.Hour
+	DefaultTimeout     = 5 * time.Minute
+	DefaultConcurrency = 4
+)
+
+// Config configures a Scheduler
+type Config struct {
+	// Interval between backup runs. Defaults to DefaultInterval, so a
+	// scheduler started at night keeps taking nightly backups.
+	Interval time.Duration
+
+	// Retention is the number of backups kept per device. Older backups
+	// are deleted after each successful backup. Zero keeps all.
+	Retention int
+
+	// SkipUnchanged does not store a backup whose checksum matches the
+	// latest stored backup of the device
+	SkipUnchanged bool
+
+	// Timeout bounds the backup of one device, including connecting.
+	// Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// Concurrency is the number of devices backed up at once. Defaults to
+	// DefaultConcurrency.
+	Concurrency int
+}
+
+// Result is the outcome of backing up one device
+type Result struct {
+	Device   string
+	Entry    Entry // set when the backup was stored
+	Skipped  bool  // unchanged since the latest backup
+	Duration time.Duration
+	Err      error
+}
+
+// Handler receives results. It is called concurrently and should not block
+// for long.
+type Handler func(Result)
+
+// Scheduler backs up a fixed set of devices periodically
+type Scheduler struct {
+	devices []*types.EquipmentConfig
+	factory types.DriverFactory
+	store   Store
+	handler Handler
+	config  Config
+}
+
+// New creates a Scheduler. handler may be nil.
+func New(devices []*types.EquipmentConfig, factory types.DriverFactory, store Store, handler Handler, config Config) *Scheduler {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	return &Scheduler{
+		devices: devices,
+		factory: factory,
+		store:   store,
+		handler: handler,
+		config:  config,
+	}
+}
+
+// Run backs up every device immediately and then every Interval until ctx
+// is cancelled
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		s.BackupAll(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// BackupAll backs up every device, at most Concurrency at a time, and
+// returns the results in device order
+func (s *Scheduler) BackupAll(ctx context.Context) []Result {
+	results := make([]Result, len(s.devices))
+	sem := make(chan struct{}, s.config.Concurrency)
+	var wg sync.WaitGroup
+	for i, cfg := range s.devices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = Result{Device: cfg.Name, Err: ctx.Err()}
+				return
+			}
+			defer func() { <-sem }()
+			results[i] = s.Backup(ctx, cfg)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// Backup backs up one device, stores the configuration and applies
+// retention. The result is also passed to the handler.
+func (s *Scheduler) Backup(ctx context.Context, config *types.EquipmentConfig) Result {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	res := Result{Device: config.Name}
+	res.Entry, res.Skipped, res.Err = s.backup(ctx, config)
+	res.Duration = time.Since(start)
+	if res.Err != nil {
+		config.Log().Warn("config backup failed", "error", res.Err)
+	}
+	if s.handler != nil {
+		s.handler(res)
+	}
+	return res
+}
+
+func (s *Scheduler) backup(ctx context.Context, config *types.EquipmentConfig) (Entry, bool, error) {
+	b, err := s.take(ctx, config)
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	if s.config.SkipUnchanged {
+		entries, err := s.store.List(ctx, config.Name)
+		if err != nil {
+			return Entry{}, false, fmt.Errorf("failed to list backups of %s: %w", config.Name, err)
+		}
+		if n := len(entries); n > 0 && b.Checksum != "" && entries[n-1].Checksum == b.Checksum {
+			return entries[n-1], true, nil
+		}
+	}
+
+	entry, err := s.store.Save(ctx, config.Name, b)
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("failed to store backup of %s: %w", config.Name, err)
+	}
+	if err := s.prune(ctx, config.Name); err != nil {
+		config.Log().Warn("backup retention failed", "error", err)
+	}
+	return entry, false, nil
+}
+
+// take reads the configuration of a device
+func (s *Scheduler) take(ctx context.Context, config *types.EquipmentConfig) (*types.ConfigBackup, error) {
+	return Capture(ctx, s.factory, config)
+}
+
+// Capture connects to the device if needed and reads its running
+// configuration inline. A driver that was already connected, e.g. one
+// shared through a registry or pool, is left connected.
+func Capture(ctx context.Context, factory types.DriverFactory, config *types.EquipmentConfig) (*types.ConfigBackup, error) {
+	driver, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver for %s: %w", config.Name, err)
+	}
+	manager, ok := driver.(types.ConfigBackupManager)
+	if !ok {
+		return nil, fmt.Errorf("%s (%s) does not support config backup", config.Name, config.Vendor)
+	}
+
+	if !driver.IsConnected() {
+		if err := driver.Connect(ctx, config); err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", config.Name, err)
+		}
+		defer func() {
+			if err := driver.Disconnect(context.WithoutCancel(ctx)); err != nil {
+				config.Log().Warn("disconnect after backup failed", "error", err)
+			}
+		}()
+	}
+
+	b, err := manager.BackupConfig(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// prune deletes the oldest backups beyond Retention
+func (s *Scheduler) prune(ctx context.Context, device string) error {
+	if s.config.Retention <= 0 {
+		return nil
+	}
+	entries, err := s.store.List(ctx, device)
+	if err != nil {
+		return err
+	}
+	for len(entries) > s.config.Retention {
+		if err := s.store.Delete(ctx, device, entries[0].ID); err != nil {
+			return err
+		}
+		entries = entries[1:]
+	}
+	return nil
+}
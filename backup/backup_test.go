@@ -0,0 +1,150 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// fakeOLT is a MockDriver that returns a configurable configuration
+type fakeOLT struct {
+	*testutil.MockDriver
+	config atomic.Value // string
+	err    error
+}
+
+func (f *fakeOLT) BackupConfig(_ context.Context, destination string) (*types.ConfigBackup, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	content := []byte(f.config.Load().(string))
+	sum := sha256.Sum256(content)
+	return &types.ConfigBackup{
+		Vendor:   "mock",
+		Source:   "running",
+		Format:   "text",
+		Content:  content,
+		Checksum: hex.EncodeToString(sum[:]),
+		TakenAt:  time.Now(),
+	}, nil
+}
+
+func (f *fakeOLT) RestoreConfig(context.Context, string) error { return nil }
+
+func newFakeOLT(config string) *fakeOLT {
+	f := &fakeOLT{MockDriver: &testutil.MockDriver{}}
+	f.config.Store(config)
+	return f
+}
+
+func factoryFor(drivers map[string]types.Driver) types.DriverFactory {
+	return func(c *types.EquipmentConfig) (types.Driver, error) {
+		d, ok := drivers[c.Name]
+		if !ok {
+			return nil, errors.New("no driver")
+		}
+		return d, nil
+	}
+}
+
+func TestBackupAll(t *testing.T) {
+	ok := newFakeOLT("sysname olt-1")
+	broken := newFakeOLT("")
+	broken.err = errors.New("permission denied")
+	drivers := map[string]types.Driver{
+		"olt-1": ok,
+		"olt-2": broken,
+		"olt-3": &testutil.MockDriver{},
+	}
+	devices := []*types.EquipmentConfig{{Name: "olt-1"}, {Name: "olt-2"}, {Name: "olt-3"}}
+
+	store := NewMemoryStore()
+	var handled atomic.Int32
+	s := New(devices, factoryFor(drivers), store, func(Result) { handled.Add(1) }, Config{})
+	results := s.BackupAll(context.Background())
+
+	if len(results) != 3 || handled.Load() != 3 {
+		t.Fatalf("results = %d, handled = %d", len(results), handled.Load())
+	}
+	if results[0].Err != nil || results[0].Entry.ID == "" {
+		t.Errorf("olt-1 result = %+v", results[0])
+	}
+	if results[1].Err == nil || results[2].Err == nil {
+		t.Errorf("expected errors for olt-2 and olt-3: %+v %+v", results[1], results[2])
+	}
+
+	stored, err := store.Load(context.Background(), "olt-1", results[0].Entry.ID)
+	if err != nil || string(stored.Content) != "sysname olt-1" {
+		t.Errorf("Load() = %v, %v", stored, err)
+	}
+	if ok.IsConnected() {
+		t.Error("driver connected by the scheduler should be disconnected")
+	}
+}
+
+func TestBackupLeavesSharedDriverConnected(t *testing.T) {
+	d := newFakeOLT("cfg")
+	d.Connected = true
+	s := New([]*types.EquipmentConfig{{Name: "olt-1"}}, factoryFor(map[string]types.Driver{"olt-1": d}), NewMemoryStore(), nil, Config{})
+
+	if res := s.BackupAll(context.Background()); res[0].Err != nil {
+		t.Fatalf("backup error: %v", res[0].Err)
+	}
+	if !d.IsConnected() {
+		t.Error("already connected driver was disconnected")
+	}
+}
+
+func TestRetentionAndSkipUnchanged(t *testing.T) {
+	d := newFakeOLT("v1")
+	store := NewMemoryStore()
+	cfg := &types.EquipmentConfig{Name: "olt-1"}
+	s := New([]*types.EquipmentConfig{cfg}, factoryFor(map[string]types.Driver{"olt-1": d}), store, nil,
+		Config{Retention: 2, SkipUnchanged: true})
+	ctx := context.Background()
+
+	if res := s.Backup(ctx, cfg); res.Err != nil || res.Skipped {
+		t.Fatalf("first backup = %+v", res)
+	}
+	if res := s.Backup(ctx, cfg); !res.Skipped {
+		t.Errorf("unchanged config should be skipped: %+v", res)
+	}
+	for _, v := range []string{"v2", "v3"} {
+		d.config.Store(v)
+		if res := s.Backup(ctx, cfg); res.Err != nil || res.Skipped {
+			t.Fatalf("backup %s = %+v", v, res)
+		}
+	}
+
+	entries, _ := store.List(ctx, "olt-1")
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(entries))
+	}
+	latest, _ := store.Load(ctx, "olt-1", entries[1].ID)
+	if string(latest.Content) != "v3" {
+		t.Errorf("latest = %q, want v3", latest.Content)
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	d := newFakeOLT("cfg")
+	store := NewMemoryStore()
+	s := New([]*types.EquipmentConfig{{Name: "olt-1"}}, factoryFor(map[string]types.Driver{"olt-1": d}), store, nil,
+		Config{Interval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 55*time.Millisecond)
+	defer cancel()
+	if err := s.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run() = %v", err)
+	}
+	if entries, _ := store.List(context.Background(), "olt-1"); len(entries) < 2 {
+		t.Errorf("expected repeated backups, got %d", len(entries))
+	}
+}
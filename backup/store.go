@@ -0,0 +1,250 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// ErrNotFound is returned by Store.Load and Store.Delete for unknown backups
+var ErrNotFound = errors.New("backup not found")
+
+// Entry identifies a stored backup
+type Entry struct {
+	Device   string    `json:"device"`
+	ID       string    `json:"id"`
+	TakenAt  time.Time `json:"taken_at"`
+	Checksum string    `json:"checksum,omitempty"`
+	Size     int       `json:"size"`
+}
+
+// Store persists configuration backups per device. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	// Save stores a backup and returns its entry
+	Save(ctx context.Context, device string, backup *types.ConfigBackup) (Entry, error)
+
+	// List returns the backups of a device, oldest first
+	List(ctx context.Context, device string) ([]Entry, error)
+
+	// Load returns a stored backup
+	Load(ctx context.Context, device, id string) (*types.ConfigBackup, error)
+
+	// Delete removes a stored backup
+	Delete(ctx context.Context, device, id string) error
+}
+
+// backupID derives a sortable ID from the backup time
+func backupID(b *types.ConfigBackup) string {
+	t := b.TakenAt
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.UTC().Format("20060102T150405.000000000Z")
+}
+
+func entryOf(device, id string, b *types.ConfigBackup) Entry {
+	return Entry{Device: device, ID: id, TakenAt: b.TakenAt, Checksum: b.Checksum, Size: len(b.Content)}
+}
+
+// MemoryStore keeps backups in memory. It is meant for tests and for
+// callers that ship backups elsewhere from a Handler.
+type MemoryStore struct {
+	mu      sync.Mutex
+	backups map[string]map[string]*types.ConfigBackup
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{backups: make(map[string]map[string]*types.ConfigBackup)}
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(_ context.Context, device string, b *types.ConfigBackup) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.backups[device] == nil {
+		s.backups[device] = make(map[string]*types.ConfigBackup)
+	}
+	id := backupID(b)
+	stored := *b
+	s.backups[device][id] = &stored
+	return entryOf(device, id, b), nil
+}
+
+// List implements Store
+func (s *MemoryStore) List(_ context.Context, device string) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]Entry, 0, len(s.backups[device]))
+	for id, b := range s.backups[device] {
+		entries = append(entries, entryOf(device, id, b))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries, nil
+}
+
+// Load implements Store
+func (s *MemoryStore) Load(_ context.Context, device, id string) (*types.ConfigBackup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.backups[device][id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	loaded := *b
+	return &loaded, nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(_ context.Context, device, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.backups[device][id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.backups[device], id)
+	return nil
+}
+
+// FileStore keeps each backup as a JSON file under dir/<device>/<id>.json
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates a FileStore rooted at dir, creating it if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// deviceDir returns the directory of a device, rejecting names that would
+// escape the store
+func (s *FileStore) deviceDir(device string) (string, error) {
+	if device == "" || device == "." || device == ".." || strings.ContainsAny(device, `/\`) {
+		return "", fmt.Errorf("invalid device name %q", device)
+	}
+	return filepath.Join(s.dir, device), nil
+}
+
+func (s *FileStore) path(device, id string) (string, error) {
+	dir, err := s.deviceDir(device)
+	if err != nil {
+		return "", err
+	}
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid backup ID %q", id)
+	}
+	return filepath.Join(dir, id+".json"), nil
+}
+
+// Save implements Store. Files are written atomically.
+func (s *FileStore) Save(_ context.Context, device string, b *types.ConfigBackup) (Entry, error) {
+	dir, err := s.deviceDir(device)
+	if err != nil {
+		return Entry{}, err
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to encode backup: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return Entry{}, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	id := backupID(b)
+	path := filepath.Join(dir, id+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return Entry{}, fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp) //nolint:errcheck // best effort
+		return Entry{}, fmt.Errorf("failed to write backup: %w", err)
+	}
+	return entryOf(device, id, b), nil
+}
+
+// List implements Store
+func (s *FileStore) List(ctx context.Context, device string) ([]Entry, error) {
+	dir, err := s.deviceDir(device)
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var entries []Entry
+	for _, f := range files {
+		id, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok || f.IsDir() {
+			continue
+		}
+		b, err := s.Load(ctx, device, id)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entryOf(device, id, b))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries, nil
+}
+
+// Load implements Store
+func (s *FileStore) Load(_ context.Context, device, id string) (*types.ConfigBackup, error) {
+	path, err := s.path(device, id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	var b types.ConfigBackup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to decode backup %s/%s: %w", device, id, err)
+	}
+	return &b, nil
+}
+
+// Delete implements Store
+func (s *FileStore) Delete(_ context.Context, device, id string) error {
+	path, err := s.path(device, id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete backup: %w", err)
+	}
+	return nil
+}
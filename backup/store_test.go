@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for i, content := range []string{"first", "second"} {
+		b := &types.ConfigBackup{Vendor: "vsol", Format: "text", Content: []byte(content), TakenAt: base.Add(time.Duration(i) * time.Hour)}
+		if _, err := store.Save(ctx, "olt-1", b); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	entries, err := store.List(ctx, "olt-1")
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("List() = %v, %v", entries, err)
+	}
+	if !entries[0].TakenAt.Before(entries[1].TakenAt) {
+		t.Errorf("entries not oldest first: %+v", entries)
+	}
+
+	b, err := store.Load(ctx, "olt-1", entries[1].ID)
+	if err != nil || string(b.Content) != "second" || b.Vendor != "vsol" {
+		t.Errorf("Load() = %+v, %v", b, err)
+	}
+
+	if err := store.Delete(ctx, "olt-1", entries[0].ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Load(ctx, "olt-1", entries[0].ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() of deleted backup error = %v", err)
+	}
+	if err := store.Delete(ctx, "olt-1", entries[0].ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete() error = %v", err)
+	}
+	if entries, _ := store.List(ctx, "other"); len(entries) != 0 {
+		t.Errorf("unknown device has entries: %v", entries)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+
+	if _, err := store.Save(context.Background(), "../escape", &types.ConfigBackup{}); err == nil {
+		t.Error("device names with path separators should be rejected")
+	}
+}
@@ -0,0 +1,369 @@
+// Package cache wraps a DriverV2 adapter with a read-through cache, so
+// dashboards polling the same data do not each reach the OLT.
+//
+// Each cached method has its own TTL. Concurrent identical reads share one
+// request to the device, errors are not cached, and writes through the
+// wrapper invalidate the reads they affect, whether or not they succeed.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Method names a cached read method
+type Method string
+
+// Cached methods
+const (
+	MethodGetONUList       Method = "GetONUList"
+	MethodGetOLTStatus     Method = "GetOLTStatus"
+	MethodListVLANs        Method = "ListVLANs"
+	MethodListPorts        Method = "ListPorts"
+	MethodListServicePorts Method = "ListServicePorts"
+)
+
+// DefaultTTLs are the TTLs used when Config.TTLs is nil
+var DefaultTTLs = map[Method]time.Duration{
+	MethodGetONUList:       30 * time.Second,
+	MethodGetOLTStatus:     15 * time.Second,
+	MethodListVLANs:        5 * time.Minute,
+	MethodListPorts:        30 * time.Second,
+	MethodListServicePorts: time.Minute,
+}
+
+// Reads invalidated by each kind of write
+var (
+	subscriberReads  = []Method{MethodGetONUList, MethodGetOLTStatus, MethodListServicePorts}
+	portReads        = []Method{MethodListPorts, MethodGetOLTStatus, MethodGetONUList}
+	vlanReads        = []Method{MethodListVLANs, MethodListServicePorts}
+	servicePortReads = []Method{MethodListServicePorts, MethodListVLANs}
+)
+
+// Config configures a Driver
+type Config struct {
+	// TTLs sets how long results of each method are served from the cache.
+	// Methods that are absent or have a non-positive TTL are not cached.
+	// If nil, DefaultTTLs is used.
+	TTLs map[Method]time.Duration
+}
+
+// Stats counts cache lookups
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// Driver is a DriverV2 that caches read methods of the wrapped adapter.
+// Methods that are not cached pass through. Optional interfaces of the
+// wrapped adapter, such as types.FirmwareManager, are not exposed; use
+// Unwrap to reach them, bearing in mind that writes made that way do not
+// invalidate the cache.
+type Driver struct {
+	types.DriverV2
+	ttls map[Method]time.Duration
+
+	mu       sync.Mutex
+	entries  map[Method]map[string]entry
+	inflight map[Method]map[string]*call
+	stats    Stats
+}
+
+var _ types.DriverV2 = (*Driver)(nil)
+
+type entry struct {
+	value   any
+	expires time.Time
+}
+
+// call is a read in progress shared by concurrent callers
+type call struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+// New wraps driver
+func New(driver types.DriverV2, config Config) *Driver {
+	if config.TTLs == nil {
+		config.TTLs = DefaultTTLs
+	}
+	return &Driver{
+		DriverV2: driver,
+		ttls:     config.TTLs,
+		entries:  make(map[Method]map[string]entry),
+		inflight: make(map[Method]map[string]*call),
+	}
+}
+
+// Unwrap returns the wrapped adapter
+func (d *Driver) Unwrap() types.DriverV2 {
+	return d.DriverV2
+}
+
+// Capabilities implements types.CapabilityReporter. Capabilities of
+// optional interfaces are dropped, as the wrapper does not expose them.
+func (d *Driver) Capabilities() types.Capabilities {
+	c := types.CapabilitiesOf(d.DriverV2)
+	detected := types.DetectCapabilities(d)
+	c.ONUStream = detected.ONUStream
+	c.BulkSubscribers = detected.BulkSubscribers
+	c.ConfigBackup, c.ConfigRestore = detected.ConfigBackup, detected.ConfigRestore
+	c.FirmwareUpgrade, c.ONUFirmwareUpgrade = detected.FirmwareUpgrade, detected.ONUFirmwareUpgrade
+	c.WiFi, c.ONUWAN, c.ONULAN = detected.WiFi, detected.ONUWAN, detected.ONULAN
+	c.ONUEthPorts, c.Voice, c.ONUTests = detected.ONUEthPorts, detected.Voice, detected.ONUTests
+	c.RogueDetection = detected.RogueDetection
+	c.LineProfiles, c.ServiceProfiles = detected.LineProfiles, detected.ServiceProfiles
+	c.DBAProfiles = detected.DBAProfiles
+	c.TrafficProfiles, c.ONUProfiles = detected.TrafficProfiles, detected.ONUProfiles
+	return c
+}
+
+// Invalidate drops cached results of the given methods, or of every method
+// if none are given
+func (d *Driver) Invalidate(methods ...Method) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(methods) == 0 {
+		clear(d.entries)
+		return
+	}
+	for _, m := range methods {
+		delete(d.entries, m)
+	}
+}
+
+// Stats returns the hit and miss counts
+func (d *Driver) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stats
+}
+
+// get returns the cached result of method for key, or calls fetch and
+// caches its result. Concurrent callers with the same key wait for one
+// fetch.
+func get[T any](ctx context.Context, d *Driver, method Method, key string, fetch func(context.Context) (T, error)) (T, error) {
+	ttl := d.ttls[method]
+	if ttl <= 0 {
+		return fetch(ctx)
+	}
+
+	d.mu.Lock()
+	if e, ok := d.entries[method][key]; ok && time.Now().Before(e.expires) {
+		d.stats.Hits++
+		d.mu.Unlock()
+		return e.value.(T), nil
+	}
+	d.stats.Misses++
+	if c, ok := d.inflight[method][key]; ok {
+		d.mu.Unlock()
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+		if c.err != nil {
+			var zero T
+			return zero, c.err
+		}
+		return c.value.(T), nil
+	}
+	c := &call{done: make(chan struct{})}
+	if d.inflight[method] == nil {
+		d.inflight[method] = make(map[string]*call)
+	}
+	d.inflight[method][key] = c
+	d.mu.Unlock()
+
+	value, err := fetch(ctx)
+	c.value, c.err = value, err
+	close(c.done)
+
+	d.mu.Lock()
+	delete(d.inflight[method], key)
+	if err == nil {
+		if d.entries[method] == nil {
+			d.entries[method] = make(map[string]entry)
+		}
+		d.entries[method][key] = entry{value: value, expires: time.Now().Add(ttl)}
+	}
+	d.mu.Unlock()
+	return value, err
+}
+
+// GetONUList implements types.DriverV2. Results are cached per filter.
+func (d *Driver) GetONUList(ctx context.Context, filter *types.ONUFilter) ([]types.ONUInfo, error) {
+	key := ""
+	if filter != nil {
+		data, _ := json.Marshal(filter) //nolint:errcheck // plain struct
+		key = string(data)
+	}
+	onus, err := get(ctx, d, MethodGetONUList, key, func(ctx context.Context) ([]types.ONUInfo, error) {
+		return d.DriverV2.GetONUList(ctx, filter)
+	})
+	return slices.Clone(onus), err
+}
+
+// GetOLTStatus implements types.DriverV2
+func (d *Driver) GetOLTStatus(ctx context.Context) (*types.OLTStatus, error) {
+	status, err := get(ctx, d, MethodGetOLTStatus, "", d.DriverV2.GetOLTStatus)
+	if status == nil {
+		return nil, err
+	}
+	c := *status
+	return &c, err
+}
+
+// ListVLANs implements types.DriverV2
+func (d *Driver) ListVLANs(ctx context.Context) ([]types.VLANInfo, error) {
+	vlans, err := get(ctx, d, MethodListVLANs, "", d.DriverV2.ListVLANs)
+	return slices.Clone(vlans), err
+}
+
+// ListPorts implements types.DriverV2
+func (d *Driver) ListPorts(ctx context.Context) ([]*types.PONPortStatus, error) {
+	ports, err := get(ctx, d, MethodListPorts, "", d.DriverV2.ListPorts)
+	return slices.Clone(ports), err
+}
+
+// ListServicePorts implements types.DriverV2
+func (d *Driver) ListServicePorts(ctx context.Context) ([]types.ServicePort, error) {
+	sps, err := get(ctx, d, MethodListServicePorts, "", d.DriverV2.ListServicePorts)
+	return slices.Clone(sps), err
+}
+
+// Writes invalidate the reads they may change
+
+// CreateSubscriber implements types.Driver
+func (d *Driver) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (*types.SubscriberResult, error) {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.CreateSubscriber(ctx, subscriber, tier)
+}
+
+// UpdateSubscriber implements types.Driver
+func (d *Driver) UpdateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) error {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.UpdateSubscriber(ctx, subscriber, tier)
+}
+
+// DeleteSubscriber implements types.Driver
+func (d *Driver) DeleteSubscriber(ctx context.Context, subscriberID string) error {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.DeleteSubscriber(ctx, subscriberID)
+}
+
+// SuspendSubscriber implements types.Driver
+func (d *Driver) SuspendSubscriber(ctx context.Context, subscriberID string) error {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.SuspendSubscriber(ctx, subscriberID)
+}
+
+// ResumeSubscriber implements types.Driver
+func (d *Driver) ResumeSubscriber(ctx context.Context, subscriberID string) error {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.ResumeSubscriber(ctx, subscriberID)
+}
+
+// RestartONU implements types.DriverV2
+func (d *Driver) RestartONU(ctx context.Context, ponPort string, onuID int) (*types.RestartONUResult, error) {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.RestartONU(ctx, ponPort, onuID)
+}
+
+// FactoryResetONU implements types.DriverV2
+func (d *Driver) FactoryResetONU(ctx context.Context, ponPort string, onuID int, opts *types.FactoryResetOptions) (*types.FactoryResetResult, error) {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.FactoryResetONU(ctx, ponPort, onuID, opts)
+}
+
+// ApplyProfile implements types.DriverV2
+func (d *Driver) ApplyProfile(ctx context.Context, ponPort string, onuID int, profile *types.ONUProfile) error {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.ApplyProfile(ctx, ponPort, onuID, profile)
+}
+
+// BulkProvision implements types.DriverV2
+func (d *Driver) BulkProvision(ctx context.Context, operations []types.BulkProvisionOp) (*types.BulkResult, error) {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.BulkProvision(ctx, operations)
+}
+
+// RestartOLT implements types.DriverV2. Every cached result is dropped.
+func (d *Driver) RestartOLT(ctx context.Context) (*types.RestartOLTResult, error) {
+	defer d.Invalidate()
+	return d.DriverV2.RestartOLT(ctx)
+}
+
+// SetPortState implements types.DriverV2
+func (d *Driver) SetPortState(ctx context.Context, port string, enabled bool) error {
+	defer d.Invalidate(portReads...)
+	return d.DriverV2.SetPortState(ctx, port, enabled)
+}
+
+// CreateVLAN implements types.DriverV2
+func (d *Driver) CreateVLAN(ctx context.Context, req *types.CreateVLANRequest) error {
+	defer d.Invalidate(vlanReads...)
+	return d.DriverV2.CreateVLAN(ctx, req)
+}
+
+// DeleteVLAN implements types.DriverV2
+func (d *Driver) DeleteVLAN(ctx context.Context, vlanID int, force bool) error {
+	defer d.Invalidate(vlanReads...)
+	return d.DriverV2.DeleteVLAN(ctx, vlanID, force)
+}
+
+// AddServicePort implements types.DriverV2
+func (d *Driver) AddServicePort(ctx context.Context, req *types.AddServicePortRequest) error {
+	defer d.Invalidate(servicePortReads...)
+	return d.DriverV2.AddServicePort(ctx, req)
+}
+
+// DeleteServicePort implements types.DriverV2
+func (d *Driver) DeleteServicePort(ctx context.Context, ponPort string, ontID int) error {
+	defer d.Invalidate(servicePortReads...)
+	return d.DriverV2.DeleteServicePort(ctx, ponPort, ontID)
+}
+
+// RestoreSubscriberConfig implements types.DriverV2
+func (d *Driver) RestoreSubscriberConfig(ctx context.Context, snapshot *types.SubscriberSnapshot, targetPONPort string, targetONUID int) (*types.SubscriberResult, error) {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.RestoreSubscriberConfig(ctx, snapshot, targetPONPort, targetONUID)
+}
+
+// ReplaceONU implements types.DriverV2
+func (d *Driver) ReplaceONU(ctx context.Context, subscriberID string, newSerial string) (*types.ReplaceResult, error) {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.ReplaceONU(ctx, subscriberID, newSerial)
+}
+
+// SoftSuspendSubscriber implements types.DriverV2
+func (d *Driver) SoftSuspendSubscriber(ctx context.Context, subscriberID string, opts *types.SuspendOptions) (*types.SuspensionState, error) {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.SoftSuspendSubscriber(ctx, subscriberID, opts)
+}
+
+// MoveSubscriber implements types.DriverV2
+func (d *Driver) MoveSubscriber(ctx context.Context, subscriberID string, targetPONPort string, targetONUID int) (*types.MoveResult, error) {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.MoveSubscriber(ctx, subscriberID, targetPONPort, targetONUID)
+}
+
+// AddONUToSubscriber implements types.DriverV2
+func (d *Driver) AddONUToSubscriber(ctx context.Context, subscriberID string, binding model.ONUBinding, tier *model.ServiceTier) (*types.SubscriberResult, error) {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.AddONUToSubscriber(ctx, subscriberID, binding, tier)
+}
+
+// RemoveONUFromSubscriber implements types.DriverV2
+func (d *Driver) RemoveONUFromSubscriber(ctx context.Context, subscriberID string, serial string) error {
+	defer d.Invalidate(subscriberReads...)
+	return d.DriverV2.RemoveONUFromSubscriber(ctx, subscriberID, serial)
+}
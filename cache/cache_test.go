@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// fakeDriver counts reads. Methods it does not override panic.
+type fakeDriver struct {
+	types.DriverV2
+
+	onuCalls    atomic.Int32
+	statusCalls atomic.Int32
+	vlanCalls   atomic.Int32
+	err         error
+	block       chan struct{}
+}
+
+func (f *fakeDriver) GetONUList(_ context.Context, filter *types.ONUFilter) ([]types.ONUInfo, error) {
+	f.onuCalls.Add(1)
+	if f.block != nil {
+		<-f.block
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	port := "0/1"
+	if filter != nil && filter.PONPort != "" {
+		port = filter.PONPort
+	}
+	return []types.ONUInfo{{PONPort: port, ONUID: 1, Serial: "VSOL00000001"}}, nil
+}
+
+func (f *fakeDriver) GetOLTStatus(context.Context) (*types.OLTStatus, error) {
+	f.statusCalls.Add(1)
+	return &types.OLTStatus{Vendor: "vsol"}, nil
+}
+
+func (f *fakeDriver) ListVLANs(context.Context) ([]types.VLANInfo, error) {
+	f.vlanCalls.Add(1)
+	return []types.VLANInfo{{ID: 100}}, nil
+}
+
+func (f *fakeDriver) CreateVLAN(context.Context, *types.CreateVLANRequest) error {
+	return errors.New("rejected")
+}
+
+func (f *fakeDriver) RestartOLT(context.Context) (*types.RestartOLTResult, error) {
+	return &types.RestartOLTResult{}, nil
+}
+
+func TestCachesReads(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeDriver{}
+	d := New(fake, Config{})
+
+	for i := 0; i < 3; i++ {
+		onus, err := d.GetONUList(ctx, nil)
+		if err != nil || len(onus) != 1 {
+			t.Fatalf("GetONUList() = %v, %v", onus, err)
+		}
+		onus[0].Serial = "changed"
+	}
+	if got := fake.onuCalls.Load(); got != 1 {
+		t.Errorf("driver called %d times, want 1", got)
+	}
+	onus, _ := d.GetONUList(ctx, nil)
+	if onus[0].Serial != "VSOL00000001" {
+		t.Errorf("cached result was modified by a caller: %q", onus[0].Serial)
+	}
+
+	// Filters are cached separately
+	onus, _ = d.GetONUList(ctx, &types.ONUFilter{PONPort: "0/2"})
+	if onus[0].PONPort != "0/2" || fake.onuCalls.Load() != 2 {
+		t.Errorf("filtered GetONUList() = %v after %d calls", onus, fake.onuCalls.Load())
+	}
+
+	if s := d.Stats(); s.Hits != 3 || s.Misses != 2 {
+		t.Errorf("Stats() = %+v, want 3 hits and 2 misses", s)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeDriver{}
+	d := New(fake, Config{TTLs: map[Method]time.Duration{
+		MethodGetOLTStatus: 10 * time.Millisecond,
+	}})
+
+	d.GetOLTStatus(ctx) //nolint:errcheck // counted below
+	d.GetOLTStatus(ctx) //nolint:errcheck // counted below
+	if got := fake.statusCalls.Load(); got != 1 {
+		t.Fatalf("driver called %d times, want 1", got)
+	}
+	time.Sleep(20 * time.Millisecond)
+	d.GetOLTStatus(ctx) //nolint:errcheck // counted below
+	if got := fake.statusCalls.Load(); got != 2 {
+		t.Errorf("driver called %d times after expiry, want 2", got)
+	}
+
+	// Methods without a TTL are not cached
+	d.ListVLANs(ctx) //nolint:errcheck // counted below
+	d.ListVLANs(ctx) //nolint:errcheck // counted below
+	if got := fake.vlanCalls.Load(); got != 2 {
+		t.Errorf("uncached ListVLANs called driver %d times, want 2", got)
+	}
+}
+
+func TestErrorsNotCached(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeDriver{err: errors.New("timeout")}
+	d := New(fake, Config{})
+
+	if _, err := d.GetONUList(ctx, nil); err == nil {
+		t.Fatal("expected error")
+	}
+	fake.err = nil
+	if _, err := d.GetONUList(ctx, nil); err != nil {
+		t.Fatalf("GetONUList() error = %v", err)
+	}
+	if got := fake.onuCalls.Load(); got != 2 {
+		t.Errorf("driver called %d times, want 2", got)
+	}
+}
+
+func TestConcurrentReadsShareFetch(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeDriver{block: make(chan struct{})}
+	d := New(fake, Config{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if onus, err := d.GetONUList(ctx, nil); err != nil || len(onus) != 1 {
+				t.Errorf("GetONUList() = %v, %v", onus, err)
+			}
+		}()
+	}
+	// Wait for the fetch to start, then for the others to queue behind it
+	for fake.onuCalls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(fake.block)
+	wg.Wait()
+
+	if got := fake.onuCalls.Load(); got != 1 {
+		t.Errorf("driver called %d times, want 1", got)
+	}
+}
+
+func TestWritesInvalidate(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeDriver{}
+	d := New(fake, Config{})
+
+	d.GetONUList(ctx, nil) //nolint:errcheck // counted below
+	d.ListVLANs(ctx)       //nolint:errcheck // counted below
+
+	// A failed write still invalidates, the device may have applied part of it
+	if err := d.CreateVLAN(ctx, &types.CreateVLANRequest{ID: 200}); err == nil {
+		t.Fatal("expected error")
+	}
+	d.GetONUList(ctx, nil) //nolint:errcheck // counted below
+	d.ListVLANs(ctx)       //nolint:errcheck // counted below
+	if fake.onuCalls.Load() != 1 || fake.vlanCalls.Load() != 2 {
+		t.Errorf("after CreateVLAN: %d ONU list and %d VLAN reads, want 1 and 2",
+			fake.onuCalls.Load(), fake.vlanCalls.Load())
+	}
+
+	if _, err := d.RestartOLT(ctx); err != nil {
+		t.Fatal(err)
+	}
+	d.GetONUList(ctx, nil) //nolint:errcheck // counted below
+	d.ListVLANs(ctx)       //nolint:errcheck // counted below
+	if fake.onuCalls.Load() != 2 || fake.vlanCalls.Load() != 3 {
+		t.Errorf("after RestartOLT: %d ONU list and %d VLAN reads, want 2 and 3",
+			fake.onuCalls.Load(), fake.vlanCalls.Load())
+	}
+
+	d.Invalidate(MethodGetONUList)
+	d.GetONUList(ctx, nil) //nolint:errcheck // counted below
+	if got := fake.onuCalls.Load(); got != 3 {
+		t.Errorf("after Invalidate: %d ONU list reads, want 3", got)
+	}
+}
+
+// reportingDriver is a fakeDriver that reports capabilities
+type reportingDriver struct {
+	fakeDriver
+}
+
+func (*reportingDriver) Capabilities() types.Capabilities {
+	return types.Capabilities{ONUList: true, OLTRestart: false, WiFi: true}
+}
+
+func TestCapabilities(t *testing.T) {
+	c := New(&reportingDriver{}, Config{}).Capabilities()
+	if !c.ONUList || c.OLTRestart || c.WiFi {
+		t.Errorf("Capabilities() = %+v, want the wrapped driver's without WiFi", c)
+	}
+}
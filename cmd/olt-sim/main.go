@@ -0,0 +1,91 @@
+// Command olt-sim serves a simulated OLT for testing integrations without
+// hardware:
+//
+//	olt-sim -vendor vsol -ssh 127.0.0.1:2222 -snmp 127.0.0.1:1161 -discover 4
+//
+// The V-SOL simulator on port 2222 is what the integration tests of
+// vendors/vsol expect.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/nanoncore/nano-southbound/simulator"
+	"github.com/nanoncore/nano-southbound/simulator/cdatasim"
+	"github.com/nanoncore/nano-southbound/simulator/huaweisim"
+	"github.com/nanoncore/nano-southbound/simulator/vsolsim"
+)
+
+// olt is what main needs of a vendor simulator
+type olt interface {
+	SSHAddr() net.Addr
+	Close() error
+}
+
+func main() {
+	vendor := flag.String("vendor", "vsol", "simulated vendor: vsol, huawei or cdata")
+	sshAddr := flag.String("ssh", "127.0.0.1:2222", "CLI listen address")
+	snmpAddr := flag.String("snmp", "127.0.0.1:1161", "SNMP listen address, empty to disable")
+	discover := flag.Int("discover", 0, "unprovisioned ONUs to put in the autofind list of the first port")
+	silent := flag.String("silent", "", "comma-separated command prefixes that fail silently")
+	flag.Parse()
+
+	o, err := start(*vendor, *sshAddr, *snmpAddr, *discover, *silent)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%s OLT simulator: CLI on %s", *vendor, o.SSHAddr())
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+	o.Close() //nolint:errcheck // exiting
+}
+
+func start(vendor, sshAddr, snmpAddr string, discover int, silent string) (olt, error) {
+	// quirks adds the silent failures to the vendor defaults
+	quirks := func(defaults simulator.Quirks) *simulator.Quirks {
+		if silent == "" {
+			return nil
+		}
+		defaults.SilentFailures = strings.Split(silent, ",")
+		return &defaults
+	}
+	seed := func(inv *simulator.Inventory, port, prefix string) {
+		for i := range discover {
+			inv.Discover(simulator.ONU{Port: port, Serial: fmt.Sprintf("%s%08X", prefix, i+1)})
+		}
+	}
+
+	switch vendor {
+	case "vsol":
+		o, err := vsolsim.New(vsolsim.Config{Quirks: quirks(vsolsim.DefaultQuirks)})
+		if err != nil {
+			return nil, err
+		}
+		seed(o.ONUs, "0/1", "VSOL")
+		return o, o.Start(sshAddr, snmpAddr)
+	case "huawei":
+		o, err := huaweisim.New(huaweisim.Config{Quirks: quirks(huaweisim.DefaultQuirks)})
+		if err != nil {
+			return nil, err
+		}
+		seed(o.ONUs, "0/1/0", "HWTC")
+		return o, o.Start(sshAddr, snmpAddr)
+	case "cdata":
+		o, err := cdatasim.New(cdatasim.Config{Quirks: quirks(cdatasim.DefaultQuirks)})
+		if err != nil {
+			return nil, err
+		}
+		seed(o.ONUs, "1/1/1", "CDAT")
+		return o, o.Start(sshAddr)
+	}
+	return nil, fmt.Errorf("unknown vendor %q", vendor)
+}
@@ -0,0 +1,415 @@
+// Package collector schedules periodic telemetry collection across a set of
+// devices. Each metric class runs on its own interval with jitter, and the
+// number of concurrent requests to any one device is bounded.
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/workers"
+)
+
+// MetricClass identifies a group of metrics collected together
+type MetricClass string
+
+const (
+	// MetricONUList collects the provisioned ONU list (GetONUList)
+	MetricONUList MetricClass = "onu_list"
+	// MetricOLTStatus collects OLT status (GetOLTStatus)
+	MetricOLTStatus MetricClass = "olt_status"
+	// MetricOptical collects ONU optical levels (GetBulkONUOpticalSNMP)
+	MetricOptical MetricClass = "optical"
+	// MetricAlarms collects active OLT alarms (GetAlarms). It is not in
+	// DefaultIntervals and must be enabled explicitly.
+	MetricAlarms MetricClass = "alarms"
+	// MetricDiscovery collects the unprovisioned ONUs in autofind
+	// (DiscoverONUs). It is not in DefaultIntervals and must be enabled
+	// explicitly.
+	MetricDiscovery MetricClass = "discovery"
+	// MetricEnvironment collects the fans, power supplies and temperature
+	// sensors of the OLT (GetEnvironment). It is not in DefaultIntervals
+	// and must be enabled explicitly.
+	MetricEnvironment MetricClass = "environment"
+)
+
+// DefaultIntervals are the collection intervals used when Config.Intervals
+// is nil
+var DefaultIntervals = map[MetricClass]time.Duration{
+	MetricONUList:   5 * time.Minute,
+	MetricOLTStatus: time.Minute,
+	MetricOptical:   5 * time.Minute,
+}
+
+// ONULister is implemented by drivers that can list provisioned ONUs
+type ONULister interface {
+	GetONUList(ctx context.Context, filter *types.ONUFilter) ([]types.ONUInfo, error)
+}
+
+// OLTStatusReader is implemented by drivers that report OLT status
+type OLTStatusReader interface {
+	GetOLTStatus(ctx context.Context) (*types.OLTStatus, error)
+}
+
+// BulkOpticalReader is implemented by drivers that read optical levels for
+// every ONU in one operation
+type BulkOpticalReader interface {
+	GetBulkONUOpticalSNMP(ctx context.Context) (map[string]*types.ONUPowerReading, error)
+}
+
+// AlarmReader is implemented by drivers that report active alarms
+type AlarmReader interface {
+	GetAlarms(ctx context.Context) ([]types.OLTAlarm, error)
+}
+
+// ONUDiscoverer is implemented by drivers that list unprovisioned ONUs
+type ONUDiscoverer interface {
+	DiscoverONUs(ctx context.Context, ponPorts []string) ([]types.ONUDiscovery, error)
+}
+
+// EnvironmentReader is implemented by drivers that report the OLT
+// environment, i.e. types.EnvironmentMonitor
+type EnvironmentReader interface {
+	GetEnvironment(ctx context.Context) (*types.EnvironmentStatus, error)
+}
+
+// Sample is the result of one collection
+type Sample struct {
+	Device      string
+	Vendor      types.Vendor
+	Class       MetricClass
+	CollectedAt time.Time
+	Duration    time.Duration
+
+	// Exactly one of these is set on success, according to Class
+	ONUs        []types.ONUInfo
+	OLTStatus   *types.OLTStatus
+	Optical     map[string]*types.ONUPowerReading
+	Alarms      []types.OLTAlarm
+	Discovered  []types.ONUDiscovery
+	Environment *types.EnvironmentStatus
+
+	// DriverMetrics is a snapshot of the driver's operational counters
+	// after the collection, if the driver implements types.MetricsProvider
+	DriverMetrics *types.DriverMetrics
+
+	Err error
+}
+
+// Handler receives samples. It is called concurrently from collection
+// goroutines and should not block for long.
+type Handler func(Sample)
+
+// Config configures a Collector
+type Config struct {
+	// Intervals sets the collection interval per metric class. Classes that
+	// are absent or have a non-positive interval are not collected. If nil,
+	// DefaultIntervals is used.
+	Intervals map[MetricClass]time.Duration
+
+	// Jitter randomizes each wait by up to this fraction of the interval
+	// (0.1 = ±10%) and staggers the first collection by up to one full
+	// jittered interval, so devices are not polled in lockstep. Clamped
+	// to [0, 1].
+	Jitter float64
+
+	// MaxConcurrentPerDevice bounds in-flight requests to a single device
+	// across all metric classes. Defaults to 1.
+	MaxConcurrentPerDevice int
+
+	// Timeout bounds a single collection, including connecting. Defaults to
+	// the class interval.
+	Timeout time.Duration
+
+	// Pool, if set, is shared with other subsystems so collections wait
+	// while they work on a device, e.g. an orchestrator job
+	Pool *workers.Pool
+
+	// Tags, if not empty, limits collection to the devices whose tags
+	// match. It is checked at every collection, so devices join and leave
+	// as their tags change.
+	Tags types.Selector
+
+	// TagsOf returns the current tags of a device for Tags. If nil,
+	// EquipmentConfig.Tags is used. Registry.TagsOf can be used to select
+	// by tags set at runtime.
+	TagsOf func(device *types.EquipmentConfig) map[string]string
+}
+
+// Collector polls a fixed set of devices
+type Collector struct {
+	config  Config
+	factory types.DriverFactory
+	handler Handler
+	devices []*device
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+// device holds the shared driver and concurrency limit for one device
+type device struct {
+	config *types.EquipmentConfig
+	sem    chan struct{}
+
+	mu     sync.Mutex
+	driver types.Driver
+}
+
+// New creates a Collector for devices. Drivers are created with factory on
+// first use and shared by all metric classes of a device.
+func New(devices []*types.EquipmentConfig, factory types.DriverFactory, handler Handler, config Config) *Collector {
+	if config.Intervals == nil {
+		config.Intervals = DefaultIntervals
+	}
+	if config.MaxConcurrentPerDevice <= 0 {
+		config.MaxConcurrentPerDevice = 1
+	}
+	if config.Jitter < 0 {
+		config.Jitter = 0
+	}
+	if config.Jitter > 1 {
+		config.Jitter = 1
+	}
+
+	c := &Collector{
+		config:  config,
+		factory: factory,
+		handler: handler,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, cfg := range devices {
+		c.devices = append(c.devices, &device{
+			config: cfg,
+			sem:    make(chan struct{}, config.MaxConcurrentPerDevice),
+		})
+	}
+	return c
+}
+
+// Run collects until ctx is cancelled, then disconnects every driver it
+// created and returns
+func (c *Collector) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, dev := range c.devices {
+		for class, interval := range c.config.Intervals {
+			if interval <= 0 {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.loop(ctx, dev, class, interval)
+			}()
+		}
+	}
+	wg.Wait()
+
+	for _, dev := range c.devices {
+		dev.close()
+	}
+	return ctx.Err()
+}
+
+// CollectOnce runs one collection of class on every device and returns when
+// all have been delivered to the handler. Devices that do not support class
+// are skipped.
+func (c *Collector) CollectOnce(ctx context.Context, class MetricClass) {
+	var wg sync.WaitGroup
+	for _, dev := range c.devices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.collect(ctx, dev, class, c.config.Intervals[class])
+		}()
+	}
+	wg.Wait()
+}
+
+// loop collects one metric class from one device at its interval
+func (c *Collector) loop(ctx context.Context, dev *device, class MetricClass, interval time.Duration) {
+	wait := time.Duration(0)
+	if c.config.Jitter > 0 {
+		wait = time.Duration(c.randFloat() * float64(c.jittered(interval)))
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		if !c.collect(ctx, dev, class, interval) {
+			return
+		}
+		timer.Reset(c.jittered(interval))
+	}
+}
+
+// collect runs one collection and delivers the sample. It returns false if
+// the device does not support class, so the loop can stop.
+func (c *Collector) collect(ctx context.Context, dev *device, class MetricClass, interval time.Duration) bool {
+	if !c.selected(dev.config) {
+		return true
+	}
+	select {
+	case dev.sem <- struct{}{}:
+	case <-ctx.Done():
+		return true
+	}
+	defer func() { <-dev.sem }()
+	if c.config.Pool != nil {
+		release, err := c.config.Pool.Acquire(ctx, dev.config.Name)
+		if err != nil {
+			return true
+		}
+		defer release()
+	}
+
+	timeout := c.config.Timeout
+	if timeout <= 0 {
+		timeout = interval
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	sample := Sample{Device: dev.config.Name, Vendor: dev.config.Vendor, Class: class}
+	start := time.Now()
+	supported := true
+
+	driver, err := dev.connect(ctx, c.factory)
+	if err == nil {
+		supported, err = fetch(ctx, driver, &sample)
+	}
+	if !supported {
+		return false
+	}
+
+	sample.Err = err
+	if p, ok := driver.(types.MetricsProvider); ok {
+		m := p.DriverMetrics()
+		sample.DriverMetrics = &m
+	}
+	sample.CollectedAt = time.Now()
+	sample.Duration = sample.CollectedAt.Sub(start)
+	if c.handler != nil {
+		c.handler(sample)
+	}
+	return true
+}
+
+// selected reports whether the device matches Config.Tags
+func (c *Collector) selected(config *types.EquipmentConfig) bool {
+	if len(c.config.Tags) == 0 {
+		return true
+	}
+	tags := config.Tags
+	if c.config.TagsOf != nil {
+		tags = c.config.TagsOf(config)
+	}
+	return c.config.Tags.Matches(tags)
+}
+
+// fetch fills sample with the data for its class. It reports false if
+// driver does not implement the class.
+func fetch(ctx context.Context, driver types.Driver, sample *Sample) (bool, error) {
+	var err error
+	switch sample.Class {
+	case MetricONUList:
+		lister, ok := driver.(ONULister)
+		if !ok {
+			return false, nil
+		}
+		sample.ONUs, err = lister.GetONUList(ctx, nil)
+	case MetricOLTStatus:
+		reader, ok := driver.(OLTStatusReader)
+		if !ok {
+			return false, nil
+		}
+		sample.OLTStatus, err = reader.GetOLTStatus(ctx)
+	case MetricOptical:
+		reader, ok := driver.(BulkOpticalReader)
+		if !ok {
+			return false, nil
+		}
+		sample.Optical, err = reader.GetBulkONUOpticalSNMP(ctx)
+	case MetricAlarms:
+		reader, ok := driver.(AlarmReader)
+		if !ok {
+			return false, nil
+		}
+		sample.Alarms, err = reader.GetAlarms(ctx)
+	case MetricDiscovery:
+		discoverer, ok := driver.(ONUDiscoverer)
+		if !ok {
+			return false, nil
+		}
+		sample.Discovered, err = discoverer.DiscoverONUs(ctx, nil)
+	case MetricEnvironment:
+		reader, ok := driver.(EnvironmentReader)
+		if !ok {
+			return false, nil
+		}
+		sample.Environment, err = reader.GetEnvironment(ctx)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// jittered returns interval adjusted by up to ±Jitter
+func (c *Collector) jittered(interval time.Duration) time.Duration {
+	if c.config.Jitter == 0 {
+		return interval
+	}
+	delta := (c.randFloat()*2 - 1) * c.config.Jitter * float64(interval)
+	d := interval + time.Duration(delta)
+	if d <= 0 {
+		d = time.Millisecond
+	}
+	return d
+}
+
+func (c *Collector) randFloat() float64 {
+	c.randMu.Lock()
+	defer c.randMu.Unlock()
+	return c.rand.Float64()
+}
+
+// connect returns the device driver, creating and connecting it if needed
+func (d *device) connect(ctx context.Context, factory types.DriverFactory) (types.Driver, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.driver == nil {
+		driver, err := factory(d.config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create driver for %s: %w", d.config.Name, err)
+		}
+		d.driver = driver
+	}
+	if !d.driver.IsConnected() {
+		if err := d.driver.Connect(ctx, d.config); err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", d.config.Name, err)
+		}
+	}
+	return d.driver, nil
+}
+
+func (d *device) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.driver != nil && d.driver.IsConnected() {
+		_ = d.driver.Disconnect(context.Background()) //nolint:errcheck // best effort
+	}
+}
@@ -0,0 +1,271 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// fakeOLT is a driver supporting ONU list and OLT status collection
+type fakeOLT struct {
+	*testutil.MockDriver
+	delay    time.Duration
+	listErr  error
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	calls    atomic.Int32
+}
+
+func newFakeOLT() *fakeOLT {
+	return &fakeOLT{MockDriver: &testutil.MockDriver{}}
+}
+
+func (f *fakeOLT) enter() func() {
+	f.calls.Add(1)
+	n := f.inFlight.Add(1)
+	for {
+		p := f.peak.Load()
+		if n <= p || f.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(f.delay)
+	return func() { f.inFlight.Add(-1) }
+}
+
+func (f *fakeOLT) GetONUList(_ context.Context, _ *types.ONUFilter) ([]types.ONUInfo, error) {
+	defer f.enter()()
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	return []types.ONUInfo{{Serial: "HWTC00000001"}}, nil
+}
+
+func (f *fakeOLT) GetOLTStatus(_ context.Context) (*types.OLTStatus, error) {
+	defer f.enter()()
+	return &types.OLTStatus{}, nil
+}
+
+func (f *fakeOLT) DiscoverONUs(_ context.Context, _ []string) ([]types.ONUDiscovery, error) {
+	defer f.enter()()
+	return []types.ONUDiscovery{{PONPort: "0/1", Serial: "FHTT00000001"}}, nil
+}
+
+func (f *fakeOLT) GetAlarms(_ context.Context) ([]types.OLTAlarm, error) {
+	defer f.enter()()
+	return []types.OLTAlarm{{ID: "1", Type: "los", Severity: "critical"}}, nil
+}
+
+func (f *fakeOLT) GetEnvironment(_ context.Context) (*types.EnvironmentStatus, error) {
+	defer f.enter()()
+	return &types.EnvironmentStatus{Temperatures: []types.TemperatureSensor{{Name: "system", Celsius: 41}}}, nil
+}
+
+type sampleLog struct {
+	mu      sync.Mutex
+	samples []Sample
+}
+
+func (l *sampleLog) handle(s Sample) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.samples = append(l.samples, s)
+}
+
+func (l *sampleLog) count(class MetricClass) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for _, s := range l.samples {
+		if s.Class == class {
+			n++
+		}
+	}
+	return n
+}
+
+func factoryFor(drivers map[string]*fakeOLT) types.DriverFactory {
+	return func(c *types.EquipmentConfig) (types.Driver, error) {
+		d, ok := drivers[c.Name]
+		if !ok {
+			return nil, errors.New("unknown device")
+		}
+		return d, nil
+	}
+}
+
+func TestCollectOnce(t *testing.T) {
+	olt := newFakeOLT()
+	olt.listErr = errors.New("timeout")
+	log := &sampleLog{}
+	devices := []*types.EquipmentConfig{{Name: "olt-1"}, {Name: "olt-2"}}
+	c := New(devices, factoryFor(map[string]*fakeOLT{"olt-1": olt}), log.handle, Config{})
+
+	c.CollectOnce(context.Background(), MetricONUList)
+
+	if len(log.samples) != 2 {
+		t.Fatalf("got %d samples, want 2", len(log.samples))
+	}
+	for _, s := range log.samples {
+		if s.Err == nil {
+			t.Errorf("%s: expected error", s.Device)
+		}
+		if s.CollectedAt.IsZero() {
+			t.Errorf("%s: CollectedAt not set", s.Device)
+		}
+	}
+	if !olt.IsConnected() {
+		t.Error("driver should be connected on first use")
+	}
+}
+
+func TestCollectOnceUnsupported(t *testing.T) {
+	olt := newFakeOLT()
+	log := &sampleLog{}
+	c := New([]*types.EquipmentConfig{{Name: "olt-1"}}, factoryFor(map[string]*fakeOLT{"olt-1": olt}), log.handle, Config{})
+
+	c.CollectOnce(context.Background(), MetricOptical)
+	if len(log.samples) != 0 {
+		t.Errorf("got %d samples for unsupported class, want 0", len(log.samples))
+	}
+}
+
+func TestCollectOnceAlarms(t *testing.T) {
+	olt := newFakeOLT()
+	log := &sampleLog{}
+	c := New([]*types.EquipmentConfig{{Name: "olt-1"}}, factoryFor(map[string]*fakeOLT{"olt-1": olt}), log.handle, Config{})
+
+	c.CollectOnce(context.Background(), MetricAlarms)
+	if len(log.samples) != 1 || len(log.samples[0].Alarms) != 1 {
+		t.Fatalf("got samples %+v, want one with one alarm", log.samples)
+	}
+}
+
+func TestCollectOnceDiscovery(t *testing.T) {
+	olt := newFakeOLT()
+	log := &sampleLog{}
+	c := New([]*types.EquipmentConfig{{Name: "olt-1"}}, factoryFor(map[string]*fakeOLT{"olt-1": olt}), log.handle, Config{})
+
+	c.CollectOnce(context.Background(), MetricDiscovery)
+	if len(log.samples) != 1 || len(log.samples[0].Discovered) != 1 {
+		t.Fatalf("got samples %+v, want one with one discovered ONU", log.samples)
+	}
+}
+
+func TestCollectOnceEnvironment(t *testing.T) {
+	olt := newFakeOLT()
+	log := &sampleLog{}
+	c := New([]*types.EquipmentConfig{{Name: "olt-1"}}, factoryFor(map[string]*fakeOLT{"olt-1": olt}), log.handle, Config{})
+
+	c.CollectOnce(context.Background(), MetricEnvironment)
+	if len(log.samples) != 1 || log.samples[0].Environment == nil || len(log.samples[0].Environment.Temperatures) != 1 {
+		t.Fatalf("got samples %+v, want one with the environment", log.samples)
+	}
+}
+
+func TestCollectOnceTags(t *testing.T) {
+	drivers := map[string]*fakeOLT{"olt-1": newFakeOLT(), "olt-2": newFakeOLT()}
+	devices := []*types.EquipmentConfig{
+		{Name: "olt-1", Tags: map[string]string{"site": "ams"}},
+		{Name: "olt-2", Tags: map[string]string{"site": "fra"}},
+	}
+	runtime := map[string]string{"site": "ams"}
+	log := &sampleLog{}
+	c := New(devices, factoryFor(drivers), log.handle, Config{
+		Tags: types.Selector{"site": "ams"},
+		TagsOf: func(d *types.EquipmentConfig) map[string]string {
+			if d.Name == "olt-2" {
+				return runtime
+			}
+			return d.Tags
+		},
+	})
+
+	c.CollectOnce(context.Background(), MetricOLTStatus)
+	if len(log.samples) != 2 {
+		t.Fatalf("got %d samples, want both devices with site=ams", len(log.samples))
+	}
+	runtime["site"] = "fra"
+	c.CollectOnce(context.Background(), MetricOLTStatus)
+	if len(log.samples) != 3 || log.samples[2].Device != "olt-1" {
+		t.Errorf("olt-2 left site=ams and should not be collected: %+v", log.samples)
+	}
+}
+
+func TestRunIntervals(t *testing.T) {
+	olt := newFakeOLT()
+	log := &sampleLog{}
+	c := New([]*types.EquipmentConfig{{Name: "olt-1"}}, factoryFor(map[string]*fakeOLT{"olt-1": olt}), log.handle, Config{
+		Intervals: map[MetricClass]time.Duration{
+			MetricOLTStatus: 10 * time.Millisecond,
+			MetricONUList:   time.Hour,
+			MetricOptical:   10 * time.Millisecond, // unsupported, loop exits
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 75*time.Millisecond)
+	defer cancel()
+	if err := c.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if n := log.count(MetricOLTStatus); n < 3 {
+		t.Errorf("olt_status collected %d times, want at least 3", n)
+	}
+	// Without jitter the first collection is immediate
+	if n := log.count(MetricONUList); n != 1 {
+		t.Errorf("onu_list collected %d times, want 1", n)
+	}
+	if olt.IsConnected() {
+		t.Error("Run should disconnect drivers on exit")
+	}
+}
+
+func TestPerDeviceConcurrency(t *testing.T) {
+	for _, limit := range []int{1, 2} {
+		olt := newFakeOLT()
+		olt.delay = 20 * time.Millisecond
+		c := New([]*types.EquipmentConfig{{Name: "olt-1"}}, factoryFor(map[string]*fakeOLT{"olt-1": olt}), nil, Config{
+			Intervals: map[MetricClass]time.Duration{
+				MetricOLTStatus: time.Millisecond,
+				MetricONUList:   time.Millisecond,
+			},
+			MaxConcurrentPerDevice: limit,
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		_ = c.Run(ctx)
+		cancel()
+
+		if p := olt.peak.Load(); p > int32(limit) {
+			t.Errorf("limit %d: peak concurrency %d", limit, p)
+		}
+		if olt.calls.Load() == 0 {
+			t.Errorf("limit %d: no collections", limit)
+		}
+	}
+}
+
+func TestJittered(t *testing.T) {
+	c := New(nil, nil, nil, Config{Jitter: 0.2})
+	for i := 0; i < 100; i++ {
+		d := c.jittered(time.Second)
+		if d < 800*time.Millisecond || d > 1200*time.Millisecond {
+			t.Fatalf("jittered(1s) = %v, outside ±20%%", d)
+		}
+	}
+
+	c = New(nil, nil, nil, Config{Jitter: 5})
+	if c.config.Jitter != 1 {
+		t.Errorf("Jitter = %v, want clamped to 1", c.config.Jitter)
+	}
+	if d := c.jittered(time.Second); d <= 0 {
+		t.Errorf("jittered() = %v, want positive", d)
+	}
+}
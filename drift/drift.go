@@ -0,0 +1,220 @@
+// Package drift detects configuration drift: it periodically captures the
+// running configuration of each device, normalizes it, and compares it
+// section by section against the device's golden configuration.
+//
+// Golden configurations are kept in a backup.Store, the latest entry of a
+// device being its baseline. Each drifted section is published as an
+// events.TypeConfigDrift event.
+package drift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/backup"
+	"github.com/nanoncore/nano-southbound/events"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Defaults applied by New for zero Config fields
+const (
+	DefaultInterval    = time.Hour
+	DefaultTimeout     = 5 * time.Minute
+	DefaultConcurrency = 4
+)
+
+// ErrNoBaseline is reported for devices without a golden configuration
+var ErrNoBaseline = errors.New("no golden configuration")
+
+// Config configures a Detector
+type Config struct {
+	// Interval between checks. Defaults to DefaultInterval.
+	Interval time.Duration
+
+	// Timeout bounds the check of one device, including connecting.
+	// Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// Concurrency is the number of devices checked at once. Defaults to
+	// DefaultConcurrency.
+	Concurrency int
+
+	// Ignore drops matching lines, after trimming, in addition to the
+	// built-in timestamp and banner patterns
+	Ignore []*regexp.Regexp
+}
+
+// Report is the outcome of checking one device
+type Report struct {
+	Device   string
+	Baseline string // ID of the golden configuration compared against
+	Sections []SectionDiff
+	Duration time.Duration
+	Err      error
+}
+
+// Drifted reports whether the device differs from its golden configuration
+func (r Report) Drifted() bool {
+	return len(r.Sections) > 0
+}
+
+// Detector checks a fixed set of devices for drift periodically
+type Detector struct {
+	devices   []*types.EquipmentConfig
+	factory   types.DriverFactory
+	golden    backup.Store
+	publisher events.Publisher
+	config    Config
+}
+
+// New creates a Detector. publisher may be nil.
+func New(devices []*types.EquipmentConfig, factory types.DriverFactory, golden backup.Store, publisher events.Publisher, config Config) *Detector {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	return &Detector{
+		devices:   devices,
+		factory:   factory,
+		golden:    golden,
+		publisher: publisher,
+		config:    config,
+	}
+}
+
+// Run checks every device immediately and then every Interval until ctx is
+// cancelled
+func (d *Detector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+	for {
+		d.CheckAll(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll checks every device, at most Concurrency at a time, and returns
+// the reports in device order
+func (d *Detector) CheckAll(ctx context.Context) []Report {
+	reports := make([]Report, len(d.devices))
+	sem := make(chan struct{}, d.config.Concurrency)
+	var wg sync.WaitGroup
+	for i, cfg := range d.devices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				reports[i] = Report{Device: cfg.Name, Err: ctx.Err()}
+				return
+			}
+			defer func() { <-sem }()
+			reports[i] = d.Check(ctx, cfg)
+		}()
+	}
+	wg.Wait()
+	return reports
+}
+
+// Check compares the running configuration of one device with its golden
+// configuration and publishes an event per drifted section
+func (d *Detector) Check(ctx context.Context, config *types.EquipmentConfig) Report {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	report := Report{Device: config.Name}
+	report.Baseline, report.Sections, report.Err = d.check(ctx, config)
+	report.Duration = time.Since(start)
+	if report.Err != nil {
+		config.Log().Warn("config drift check failed", "error", report.Err)
+		return report
+	}
+	if report.Drifted() {
+		config.Log().Info("config drift detected", "sections", len(report.Sections))
+	}
+	if d.publisher != nil {
+		for _, s := range report.Sections {
+			d.publisher.Publish(events.Event{
+				Type:   events.TypeConfigDrift,
+				Device: config.Name,
+				Data: map[string]any{
+					"baseline": report.Baseline,
+					"section":  s.Section,
+					"added":    s.Added,
+					"removed":  s.Removed,
+				},
+			})
+		}
+	}
+	return report
+}
+
+func (d *Detector) check(ctx context.Context, config *types.EquipmentConfig) (string, []SectionDiff, error) {
+	baseline, err := d.baseline(ctx, config.Name)
+	if err != nil {
+		return "", nil, err
+	}
+	golden, err := d.golden.Load(ctx, config.Name, baseline)
+	if err != nil {
+		return baseline, nil, fmt.Errorf("failed to load golden configuration of %s: %w", config.Name, err)
+	}
+
+	running, err := backup.Capture(ctx, d.factory, config)
+	if err != nil {
+		return baseline, nil, err
+	}
+
+	diffs := Diff(
+		Sections(Normalize(string(golden.Content), d.config.Ignore)),
+		Sections(Normalize(string(running.Content), d.config.Ignore)),
+	)
+	return baseline, diffs, nil
+}
+
+// baseline returns the ID of the latest golden configuration of device
+func (d *Detector) baseline(ctx context.Context, device string) (string, error) {
+	entries, err := d.golden.List(ctx, device)
+	if err != nil {
+		return "", fmt.Errorf("failed to list golden configurations of %s: %w", device, err)
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("%s: %w", device, ErrNoBaseline)
+	}
+	return entries[len(entries)-1].ID, nil
+}
+
+// SetBaseline stores b as the golden configuration of device
+func (d *Detector) SetBaseline(ctx context.Context, device string, b *types.ConfigBackup) (backup.Entry, error) {
+	if b == nil || len(b.Content) == 0 {
+		return backup.Entry{}, fmt.Errorf("golden configuration of %s is empty", device)
+	}
+	if b.TakenAt.IsZero() {
+		b.TakenAt = time.Now()
+	}
+	return d.golden.Save(ctx, device, b)
+}
+
+// Accept makes the current running configuration of a device its golden
+// configuration, e.g. after an approved change
+func (d *Detector) Accept(ctx context.Context, config *types.EquipmentConfig) (backup.Entry, error) {
+	running, err := backup.Capture(ctx, d.factory, config)
+	if err != nil {
+		return backup.Entry{}, err
+	}
+	return d.SetBaseline(ctx, config.Name, running)
+}
@@ -0,0 +1,109 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/backup"
+	"github.com/nanoncore/nano-southbound/events"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// fakeOLT is a MockDriver with a settable running configuration
+type fakeOLT struct {
+	*testutil.MockDriver
+	mu      sync.Mutex
+	running string
+}
+
+func (f *fakeOLT) BackupConfig(context.Context, string) (*types.ConfigBackup, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &types.ConfigBackup{Format: "text", Content: []byte(f.running), TakenAt: time.Now()}, nil
+}
+
+func (f *fakeOLT) RestoreConfig(context.Context, string) error { return nil }
+
+func (f *fakeOLT) set(config string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.running = config
+}
+
+func factoryFor(drivers map[string]types.Driver) types.DriverFactory {
+	return func(c *types.EquipmentConfig) (types.Driver, error) {
+		d, ok := drivers[c.Name]
+		if !ok {
+			return nil, errors.New("no driver")
+		}
+		return d, nil
+	}
+}
+
+func TestDetector(t *testing.T) {
+	ctx := context.Background()
+	olt := &fakeOLT{MockDriver: &testutil.MockDriver{}, running: vsolGolden}
+	devices := []*types.EquipmentConfig{{Name: "olt-1"}, {Name: "olt-2"}}
+	factory := factoryFor(map[string]types.Driver{"olt-1": olt, "olt-2": &fakeOLT{MockDriver: &testutil.MockDriver{}}})
+
+	bus := events.NewBus()
+	var mu sync.Mutex
+	var published []events.Event
+	bus.Subscribe(func(e events.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		published = append(published, e)
+	}, events.TypeConfigDrift)
+
+	d := New(devices, factory, backup.NewMemoryStore(), bus, Config{})
+	entry, err := d.Accept(ctx, devices[0])
+	if err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+
+	reports := d.CheckAll(ctx)
+	if reports[0].Err != nil || reports[0].Drifted() || reports[0].Baseline != entry.ID {
+		t.Errorf("olt-1 report = %+v", reports[0])
+	}
+	if !errors.Is(reports[1].Err, ErrNoBaseline) {
+		t.Errorf("olt-2 error = %v, want ErrNoBaseline", reports[1].Err)
+	}
+	if len(published) != 0 {
+		t.Fatalf("published %d events without drift", len(published))
+	}
+
+	olt.set(vsolGolden + "interface gpon 0/3\n onu 1 type V2801F sn VSOL00000010\nexit\n")
+	report := d.Check(ctx, devices[0])
+	if !report.Drifted() || len(report.Sections) != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	if len(published) != 1 {
+		t.Fatalf("published %d events, want 1", len(published))
+	}
+	e := published[0]
+	if e.Device != "olt-1" || e.Data["section"] != "interface gpon 0/3" || e.Data["baseline"] != entry.ID {
+		t.Errorf("event = %+v", e)
+	}
+
+	// Accepting the change makes it the new baseline
+	if _, err := d.Accept(ctx, devices[0]); err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+	if report := d.Check(ctx, devices[0]); report.Err != nil || report.Drifted() {
+		t.Errorf("report after accept = %+v", report)
+	}
+}
+
+func TestSetBaseline(t *testing.T) {
+	d := New(nil, nil, backup.NewMemoryStore(), nil, Config{})
+	if _, err := d.SetBaseline(context.Background(), "olt-1", &types.ConfigBackup{}); err == nil {
+		t.Error("expected error for empty golden configuration")
+	}
+	if _, err := d.SetBaseline(context.Background(), "olt-1", &types.ConfigBackup{Content: []byte("hostname olt-1")}); err != nil {
+		t.Errorf("SetBaseline() error = %v", err)
+	}
+}
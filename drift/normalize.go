@@ -0,0 +1,181 @@
+package drift
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// GlobalSection collects top-level commands that have no body, such as
+// "hostname olt-1"
+const GlobalSection = "global"
+
+// defaultIgnore matches lines that change without a configuration change:
+// timestamps, banners printed by show commands and pager residue
+var defaultIgnore = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^!?\s*(last configuration change|nvram config last updated|time:)`),
+	regexp.MustCompile(`(?i)^(building configuration|current configuration\s*:)`),
+	regexp.MustCompile(`^\[MA5\w*V\d+R\d+.*\]$`), // Huawei "[MA5800V100R019: 8000]" banner
+	regexp.MustCompile(`(?i)^(---- more|--more--|press any key)`),
+	regexp.MustCompile(`(?i)^\s*(ntp clock-period|config generated|configuration saved)`),
+	regexp.MustCompile(`^\s*(return|end)\s*$`),
+}
+
+// closers end a block in V-SOL, C-Data and Huawei configurations
+var closers = map[string]bool{"exit": true, "quit": true, "!": true, "#": true}
+
+// Section is a top-level configuration block: a header line such as
+// "interface gpon 0/1" and the indented lines below it
+type Section struct {
+	Name  string
+	Lines []string
+}
+
+// Normalize returns config in a canonical form for comparison: line
+// endings unified, trailing whitespace and blank lines removed, and lines
+// matching the default ignore patterns or ignore dropped. Indentation is
+// kept because it defines sections.
+func Normalize(config string, ignore []*regexp.Regexp) string {
+	var out []string
+	for _, line := range strings.Split(strings.ReplaceAll(config, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || ignored(trimmed, defaultIgnore) || ignored(trimmed, ignore) {
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+func ignored(line string, patterns []*regexp.Regexp) bool {
+	for _, re := range patterns {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sections splits a normalized configuration into sections. Top-level
+// commands without a body are collected in GlobalSection, and repeated
+// blocks with the same header are merged. Body lines are trimmed so that
+// indentation changes alone are not drift.
+func Sections(config string) []Section {
+	var sections []Section
+	index := make(map[string]int)
+	global := Section{Name: GlobalSection}
+	var cur *Section
+
+	flush := func() {
+		switch {
+		case cur == nil:
+			return
+		case len(cur.Lines) == 0:
+			global.Lines = append(global.Lines, cur.Name)
+		default:
+			if i, ok := index[cur.Name]; ok {
+				sections[i].Lines = append(sections[i].Lines, cur.Lines...)
+			} else {
+				index[cur.Name] = len(sections)
+				sections = append(sections, *cur)
+			}
+		}
+		cur = nil
+	}
+
+	for _, line := range strings.Split(config, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indented := line[0] == ' ' || line[0] == '\t'
+		switch {
+		case closers[trimmed]:
+			flush()
+		case !indented:
+			flush()
+			cur = &Section{Name: trimmed}
+		case cur != nil:
+			cur.Lines = append(cur.Lines, trimmed)
+		default:
+			// indented line outside a block, e.g. after "exit"
+			global.Lines = append(global.Lines, trimmed)
+		}
+	}
+	flush()
+	if len(global.Lines) > 0 {
+		sections = append([]Section{global}, sections...)
+	}
+	return sections
+}
+
+// SectionDiff lists the lines of a section that were added or removed
+// relative to the golden configuration
+type SectionDiff struct {
+	Section string   `json:"section"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Diff compares the sections of a golden and an actual configuration.
+// Lines are compared as multisets, so reordering within a section is not
+// drift. A section missing from one side is reported with its header and
+// all of its lines.
+func Diff(golden, actual []Section) []SectionDiff {
+	actualByName := make(map[string]Section, len(actual))
+	for _, s := range actual {
+		actualByName[s.Name] = s
+	}
+	goldenNames := make(map[string]bool, len(golden))
+
+	var diffs []SectionDiff
+	for _, g := range golden {
+		goldenNames[g.Name] = true
+		a, ok := actualByName[g.Name]
+		if !ok {
+			diffs = append(diffs, SectionDiff{Section: g.Name, Removed: withHeader(g)})
+			continue
+		}
+		added, removed := diffLines(g.Lines, a.Lines)
+		if len(added) > 0 || len(removed) > 0 {
+			diffs = append(diffs, SectionDiff{Section: g.Name, Added: added, Removed: removed})
+		}
+	}
+	for _, a := range actual {
+		if !goldenNames[a.Name] {
+			diffs = append(diffs, SectionDiff{Section: a.Name, Added: withHeader(a)})
+		}
+	}
+	return diffs
+}
+
+func withHeader(s Section) []string {
+	if s.Name == GlobalSection {
+		return slices.Clone(s.Lines)
+	}
+	return append([]string{s.Name}, s.Lines...)
+}
+
+// diffLines returns the lines only in actual and the lines only in golden,
+// counting duplicates
+func diffLines(golden, actual []string) (added, removed []string) {
+	counts := make(map[string]int, len(golden))
+	for _, l := range golden {
+		counts[l]++
+	}
+	for _, l := range actual {
+		if counts[l] > 0 {
+			counts[l]--
+			continue
+		}
+		added = append(added, l)
+	}
+	for _, l := range golden {
+		if counts[l] > 0 {
+			counts[l]--
+			removed = append(removed, l)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,97 @@
+package drift
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+const vsolGolden = `Building configuration...
+! Last configuration change at 10:02:11 2026-10-01
+hostname olt-1
+snmp-server community public ro
+!
+interface gpon 0/1
+ onu 1 type V2802GW sn VSOL00000001
+ onu 1 profile line DEFAULT
+exit
+interface gpon 0/2
+ onu 1 type V2801F sn VSOL00000009
+exit
+end
+`
+
+func TestNormalize(t *testing.T) {
+	got := Normalize("hostname olt-1   \r\n\r\n ntp clock-period 17179\r\n interface x\r\n", nil)
+	if want := "hostname olt-1\n interface x"; got != want {
+		t.Errorf("Normalize() = %q, want %q", got, want)
+	}
+
+	got = Normalize("hostname olt-1\nuptime 12345\n", []*regexp.Regexp{regexp.MustCompile(`^uptime`)})
+	if got != "hostname olt-1" {
+		t.Errorf("Normalize() with ignore = %q", got)
+	}
+}
+
+func TestSections(t *testing.T) {
+	sections := Sections(Normalize(vsolGolden, nil))
+	want := []Section{
+		{Name: GlobalSection, Lines: []string{"hostname olt-1", "snmp-server community public ro"}},
+		{Name: "interface gpon 0/1", Lines: []string{"onu 1 type V2802GW sn VSOL00000001", "onu 1 profile line DEFAULT"}},
+		{Name: "interface gpon 0/2", Lines: []string{"onu 1 type V2801F sn VSOL00000009"}},
+	}
+	if !reflect.DeepEqual(sections, want) {
+		t.Errorf("Sections() = %+v, want %+v", sections, want)
+	}
+
+	// Huawei repeats block headers and separates blocks with "#"
+	huawei := "[MA5800V100R019: 8000]\n#\ninterface gpon 0/1\n ont add 0 1\nquit\n#\ninterface gpon 0/1\n ont add 0 2\nquit\nreturn\n"
+	sections = Sections(Normalize(huawei, nil))
+	want = []Section{{Name: "interface gpon 0/1", Lines: []string{"ont add 0 1", "ont add 0 2"}}}
+	if !reflect.DeepEqual(sections, want) {
+		t.Errorf("Sections(huawei) = %+v, want %+v", sections, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	golden := Sections(Normalize(vsolGolden, nil))
+
+	t.Run("unchanged apart from timestamps and order", func(t *testing.T) {
+		actual := `! Last configuration change at 08:00:00 2026-10-14
+snmp-server community public ro
+hostname olt-1
+interface gpon 0/1
+  onu 1 profile line DEFAULT
+  onu 1 type V2802GW sn VSOL00000001
+exit
+interface gpon 0/2
+ onu 1 type V2801F sn VSOL00000009
+exit
+`
+		if diffs := Diff(golden, Sections(Normalize(actual, nil))); len(diffs) != 0 {
+			t.Errorf("Diff() = %+v, want none", diffs)
+		}
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		actual := `hostname olt-1
+snmp-server community private rw
+interface gpon 0/1
+ onu 1 type V2802GW sn VSOL00000001
+ onu 1 profile line FAST
+exit
+interface gpon 0/3
+ onu 1 type V2801F sn VSOL00000010
+exit
+`
+		want := []SectionDiff{
+			{Section: GlobalSection, Added: []string{"snmp-server community private rw"}, Removed: []string{"snmp-server community public ro"}},
+			{Section: "interface gpon 0/1", Added: []string{"onu 1 profile line FAST"}, Removed: []string{"onu 1 profile line DEFAULT"}},
+			{Section: "interface gpon 0/2", Removed: []string{"interface gpon 0/2", "onu 1 type V2801F sn VSOL00000009"}},
+			{Section: "interface gpon 0/3", Added: []string{"interface gpon 0/3", "onu 1 type V2801F sn VSOL00000010"}},
+		}
+		if diffs := Diff(golden, Sections(Normalize(actual, nil))); !reflect.DeepEqual(diffs, want) {
+			t.Errorf("Diff() = %+v\nwant %+v", diffs, want)
+		}
+	})
+}
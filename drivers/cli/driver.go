@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"strings"
 	"time"
 
@@ -17,6 +16,7 @@ type Driver struct {
 	config        *types.EquipmentConfig
 	sshClient     *ssh.Client
 	expectSession *ExpectSession
+	metrics       types.DriverCounters
 }
 
 // NewDriver creates a new CLI driver
@@ -49,6 +49,11 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 	if config != nil {
 		d.config = config
 	}
+	resolved, err := types.ResolveCredentials(ctx, d.config)
+	if err != nil {
+		return err
+	}
+	d.config = resolved
 
 	// Build auth methods.
 	// PasswordAuthOnly disables keyboard-interactive for devices with non-compliant
@@ -80,7 +85,7 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 	// infrastructure which is tracked as a future improvement.
 	hostKeyCallback := ssh.InsecureIgnoreHostKey() //nolint:gosec // see comment below
 	if !d.config.TLSSkipVerify {
-		slog.Debug("SSH host key verification disabled: TLSSkipVerify not explicitly set, using insecure callback",
+		d.config.Log().Debug("SSH host key verification disabled: TLSSkipVerify not explicitly set, using insecure callback",
 			"address", d.config.Address, "port", d.config.Port)
 	}
 
@@ -97,7 +102,7 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 	// Establish SSH connection
 	client, err := ssh.Dial("tcp", target, sshConfig)
 	if err != nil {
-		return fmt.Errorf("failed to dial SSH: %w", err)
+		return types.ConnectError(string(d.config.Vendor), fmt.Errorf("failed to dial SSH: %w", err))
 	}
 
 	d.sshClient = client
@@ -119,6 +124,7 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 	}
 
 	d.expectSession = expectSession
+	d.metrics.RecordConnect()
 
 	return nil
 }
@@ -140,7 +146,9 @@ func (d *Driver) shouldDisablePager() bool {
 // Disconnect closes the SSH connection
 func (d *Driver) Disconnect(ctx context.Context) error {
 	if d.expectSession != nil {
-		_ = d.expectSession.Close()
+		if err := d.expectSession.Close(); err != nil {
+			d.config.Log().Warn("CLI session close failed", "error", err)
+		}
 		d.expectSession = nil
 	}
 	if d.sshClient != nil {
@@ -165,11 +173,28 @@ func (d *Driver) execCommand(ctx context.Context, command string) (string, error
 		return "", fmt.Errorf("not connected to device")
 	}
 
-	// Execute command using expect session (handles interactive CLI properly)
-	output, err := d.expectSession.Execute(command)
+	// Execute command using expect session (handles interactive CLI properly).
+	// Only read-only commands are retried; a timed-out change may have been
+	// applied.
+	policy := d.config.RetryPolicy()
+	if !isReadOnly(command) {
+		policy = &types.NoRetry
+	}
+	var output string
+	err := policy.Do(ctx, func(attempt int) error {
+		if attempt > 1 {
+			d.metrics.RecordRetry()
+		}
+		var err error
+		output, err = d.expectSession.Execute(command)
+		d.metrics.RecordCommand(err)
+		return err
+	})
 	if err != nil {
+		d.config.Log().Debug("CLI command failed", "command", command, "output", output, "error", err)
 		return output, fmt.Errorf("command failed: %w", err)
 	}
+	d.config.Log().Debug("CLI command", "command", command, "output", output)
 
 	return output, nil
 }
@@ -358,20 +383,76 @@ func (d *Driver) HealthCheck(ctx context.Context) error {
 
 // ExecCommand implements types.CLIExecutor - executes a single CLI command
 func (d *Driver) ExecCommand(ctx context.Context, command string) (string, error) {
-	return d.execCommand(ctx, command)
+	if isReadOnly(command) {
+		return d.execCommand(ctx, command)
+	}
+	if err := types.CheckCommands(d.config, "cli", []string{command}); err != nil {
+		return "", err
+	}
+	output, err := d.execCommand(ctx, command)
+	types.AuditCommands(ctx, d.config, "cli", []string{command}, err)
+	return output, err
 }
 
 // ExecCommands implements types.CLIExecutor - executes multiple CLI commands sequentially
 func (d *Driver) ExecCommands(ctx context.Context, commands []string) ([]string, error) {
+	// Check the whole batch first so a denied command does not leave it
+	// half applied
+	var writes []string
+	for _, cmd := range commands {
+		if !isReadOnly(cmd) {
+			writes = append(writes, cmd)
+		}
+	}
+	if err := types.CheckCommands(d.config, "cli", writes); err != nil {
+		return nil, err
+	}
+
 	results := make([]string, 0, len(commands))
+	var err error
 	for _, cmd := range commands {
-		output, err := d.execCommand(ctx, cmd)
+		var output string
+		output, err = d.execCommand(ctx, cmd)
 		if err != nil {
-			return results, fmt.Errorf("command %q failed: %w", cmd, err)
+			err = fmt.Errorf("command %q failed: %w", cmd, err)
+			break
 		}
 		results = append(results, output)
 	}
-	return results, nil
+
+	// Audit the whole batch, including mode changes, so the record shows
+	// the context of each change. The failed command is included.
+	if len(writes) > 0 {
+		types.AuditCommands(ctx, d.config, "cli", commands[:min(len(results)+1, len(commands))], err)
+	}
+	return results, err
+}
+
+// readOnlyPrefixes are commands that do not change the configuration:
+// show commands and CLI mode navigation
+var readOnlyPrefixes = []string{
+	"show ", "display ", "ping ", "traceroute ", "terminal ", "screen-length ",
+	"scroll ", "enable", "disable", "configure terminal", "config", "diagnose",
+	"interface ", "exit", "quit", "end", "return",
+}
+
+// isReadOnly reports whether command leaves the configuration unchanged
+func isReadOnly(command string) bool {
+	command = strings.ToLower(strings.TrimSpace(command))
+	if command == "" || command == "show" || command == "display" {
+		return true
+	}
+	for _, p := range readOnlyPrefixes {
+		if command == strings.TrimSpace(p) || strings.HasPrefix(command, p) && strings.HasSuffix(p, " ") {
+			return true
+		}
+	}
+	return false
+}
+
+// DriverMetrics implements types.MetricsProvider
+func (d *Driver) DriverMetrics() types.DriverMetrics {
+	return d.metrics.Snapshot()
 }
 
 // Ensure Driver implements CLIExecutor
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -469,6 +470,7 @@ func TestPagerMoreREPattern(t *testing.T) {
 		{name: "matches --More--", input: "--More--", want: true},
 		{name: "matches More:", input: "More:", want: true},
 		{name: "matches Press any key to continue", input: "Press any key to continue", want: true},
+		{name: "matches Huawei pager", input: "  ---- More ( Press 'Q' to break ) ----", want: true},
 		{name: "does not match regular text", input: "regular output line", want: false},
 		{name: "does not match empty string", input: "", want: false},
 	}
@@ -554,3 +556,80 @@ func searchSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestIsReadOnly(t *testing.T) {
+	tests := map[string]bool{
+		"show running-config":        true,
+		"display ont info 0 1 0 5":   true,
+		"configure terminal":         true,
+		"interface gpon 0/1":         true,
+		"scroll 512":                 true,
+		"exit":                       true,
+		"enable":                     true,
+		"onu add 1 sn VSOL00000001":  false,
+		"ont delete 0 5":             false,
+		"enable password secret":     false,
+		"reboot":                     false,
+		"undo service-port port 0/1": false,
+	}
+	for cmd, want := range tests {
+		if got := isReadOnly(cmd); got != want {
+			t.Errorf("isReadOnly(%q) = %v, want %v", cmd, got, want)
+		}
+	}
+}
+
+type auditRecorder struct{ records []types.AuditRecord }
+
+func (a *auditRecorder) WriteAudit(_ context.Context, r *types.AuditRecord) error {
+	a.records = append(a.records, *r)
+	return nil
+}
+
+func TestExecCommandsAudit(t *testing.T) {
+	sink := &auditRecorder{}
+	d := &Driver{config: &types.EquipmentConfig{Name: "olt-1", Audit: sink}}
+	ctx := types.WithCaller(context.Background(), "alice")
+
+	d.ExecCommand(ctx, "show version")                            //nolint:errcheck // not connected
+	d.ExecCommands(ctx, []string{"show onu state", "show vlan"})  //nolint:errcheck // not connected
+	d.ExecCommands(ctx, []string{"onu add 1 sn X", "onu 1 desc"}) //nolint:errcheck // not connected
+
+	if len(sink.records) != 1 {
+		t.Fatalf("got %d records, want 1 for the write batch: %+v", len(sink.records), sink.records)
+	}
+	r := sink.records[0]
+	if r.Caller != "alice" || r.Protocol != "cli" || r.Success || len(r.Commands) != 1 || r.Commands[0] != "onu add 1 sn X" {
+		t.Errorf("record = %+v", r)
+	}
+}
+
+func TestExecCommandsPolicy(t *testing.T) {
+	sink := &auditRecorder{}
+	policy := &types.CommandPolicy{
+		Default: types.CommandRules{Deny: types.DefaultDeny},
+		Vendors: map[types.Vendor]types.CommandRules{types.VendorVSOL: {Allow: []string{`^onu `, `^vlan `}}},
+	}
+	d := &Driver{config: &types.EquipmentConfig{Name: "olt-1", Vendor: types.VendorVSOL, Audit: sink, CommandPolicy: policy}}
+	ctx := context.Background()
+
+	_, err := d.ExecCommands(ctx, []string{"configure terminal", "onu add 1 sn X", "erase startup-config"})
+	var pe *types.PolicyError
+	if !errors.As(err, &pe) || pe.Command != "erase startup-config" || pe.Rule == "" || pe.Device != "olt-1" {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := d.ExecCommand(ctx, "ip route 0.0.0.0/0 192.0.2.1"); !errors.Is(err, types.ErrPolicyViolation) {
+		t.Errorf("command outside the allowlist: err = %v", err)
+	}
+	if len(sink.records) != 0 {
+		t.Errorf("denied commands must not be sent: %+v", sink.records)
+	}
+
+	// Allowed writes and reads reach the (unconnected) session
+	if _, err := d.ExecCommand(ctx, "onu 1 desc lobby"); errors.Is(err, types.ErrPolicyViolation) {
+		t.Errorf("allowed command denied: %v", err)
+	}
+	if _, err := d.ExecCommand(ctx, "show running-config"); errors.Is(err, types.ErrPolicyViolation) {
+		t.Errorf("read denied: %v", err)
+	}
+}
@@ -14,7 +14,7 @@ import (
 // DefaultPromptPattern matches common CLI prompts like "hostname#" or "hostname>"
 var DefaultPromptPattern = regexp.MustCompile(`(?m)[\w\-\[\]()]+[#>]\s*$`)
 
-var pagerMoreRE = regexp.MustCompile(`(?m)(--More--|More:|Press any key to continue)`)
+var pagerMoreRE = regexp.MustCompile(`(?m)(--More--|More:|More \( Press 'Q' to break \)|Press any key to continue)`)
 
 // VendorPrompts contains vendor-specific prompt patterns
 var VendorPrompts = map[string]*regexp.Regexp{
@@ -231,7 +231,7 @@ func (s *ExpectSession) Execute(command string) (string, error) {
 		outputBuilder.WriteString(chunk)
 
 		if pagerMoreRE.MatchString(chunk) {
-			// V-SOL expects a space to advance pager output.
+			// V-SOL and Huawei expect a space to advance pager output.
 			if err := s.expecter.Send(" "); err != nil {
 				return outputBuilder.String(), fmt.Errorf("failed to advance pager: %w", err)
 			}
@@ -0,0 +1,136 @@
+package gnmi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/drivers/netconf"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// BBF TR-385 paths for OLTs exposing the OB-BAA models over gNMI.
+// The module-qualified first element selects the BBF model without
+// relying on a vendor-specific origin.
+const (
+	PathBBFONUStates = "/bbf-xpon-onu-states:xpon-onu-states"
+	PathBBFONUs      = "/bbf-xpon:xpon/onus"
+)
+
+// ParseBBFONUStatesJSON decodes a JSON_IETF xpon-onu-states value into BBF
+// ONU states. Module prefixes on member names and identity values are
+// ignored, so both "onu-state" and "bbf-xpon-onu-states:onu-state" match.
+func ParseBBFONUStatesJSON(value interface{}) []netconf.BBFONUState {
+	root, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	// Accept either the container itself or a wrapper keyed by it
+	if inner, ok := jsonMember(root, "xpon-onu-states").(map[string]interface{}); ok {
+		root = inner
+	}
+	list, _ := jsonMember(root, "onu-state").([]interface{})
+
+	states := make([]netconf.BBFONUState, 0, len(list))
+	for _, item := range list {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		state := netconf.BBFONUState{
+			Name:               jsonString(entry, "onu-ref"),
+			SerialNumber:       jsonString(entry, "detected-serial-number"),
+			PresenceState:      stripModulePrefix(jsonString(entry, "onu-presence-state")),
+			ChannelTermination: jsonString(entry, "onu-channel-termination-ref"),
+			ChannelPartition:   jsonString(entry, "channel-partition-ref"),
+			ONUID:              -1,
+		}
+		if id, ok := jsonNumber(entry, "onu-id"); ok {
+			state.ONUID = int(id)
+		}
+		if dist, ok := jsonNumber(entry, "onu-distance"); ok {
+			state.ONUDistance = int(dist)
+		}
+		if optical, ok := jsonMember(entry, "optical-info").(map[string]interface{}); ok {
+			state.RxPowerDbm, _ = jsonNumber(optical, "rx-power")
+			state.TxPowerDbm, _ = jsonNumber(optical, "tx-power")
+			state.LaserBiasCurrent, _ = jsonNumber(optical, "laser-bias-current")
+			state.Temperature, _ = jsonNumber(optical, "temperature")
+			state.Voltage, _ = jsonNumber(optical, "voltage")
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// GetBBFONUStates reads ONU states using the BBF xpon-onu-states model
+func (d *Driver) GetBBFONUStates(ctx context.Context) ([]netconf.BBFONUState, error) {
+	values, err := d.Get(ctx, []string{PathBBFONUStates})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BBF ONU states: %w", err)
+	}
+
+	var states []netconf.BBFONUState
+	for _, value := range values {
+		states = append(states, ParseBBFONUStatesJSON(value)...)
+	}
+	return states, nil
+}
+
+// GetBBFONUList returns present, provisioned ONUs from the BBF model
+func (d *Driver) GetBBFONUList(ctx context.Context) ([]types.ONUInfo, error) {
+	states, err := d.GetBBFONUStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	onus := make([]types.ONUInfo, 0, len(states))
+	for _, s := range states {
+		if s.IsUnprovisioned() {
+			continue
+		}
+		onus = append(onus, s.ToONUInfo())
+	}
+	return onus, nil
+}
+
+// jsonMember looks up name in m, with or without a module prefix
+func jsonMember(m map[string]interface{}, name string) interface{} {
+	if v, ok := m[name]; ok {
+		return v
+	}
+	for k, v := range m {
+		if strings.HasSuffix(k, ":"+name) {
+			return v
+		}
+	}
+	return nil
+}
+
+func jsonString(m map[string]interface{}, name string) string {
+	s, _ := jsonMember(m, name).(string)
+	return s
+}
+
+// jsonNumber handles numbers encoded as JSON numbers or, per RFC 7951
+// for 64-bit and decimal types, as strings
+func jsonNumber(m map[string]interface{}, name string) (float64, bool) {
+	switch v := jsonMember(m, name).(type) {
+	case float64:
+		return v, true
+	case string:
+		var f float64
+		if _, err := fmt.Sscanf(v, "%g", &f); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// stripModulePrefix removes an identityref module prefix ("mod:value")
+func stripModulePrefix(s string) string {
+	if idx := strings.LastIndex(s, ":"); idx != -1 {
+		return s[idx+1:]
+	}
+	return s
+}
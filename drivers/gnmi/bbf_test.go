@@ -0,0 +1,103 @@
+package gnmi
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+)
+
+const testBBFONUStatesJSON = `{
+  "bbf-xpon-onu-states:onu-state": [
+    {
+      "onu-ref": "ont1",
+      "detected-serial-number": "ADTN12345678",
+      "onu-presence-state": "bbf-xpon-onu-types:onu-present-and-on-expected-channel-termination",
+      "onu-id": 1,
+      "onu-channel-termination-ref": "ct-1/1/1",
+      "onu-distance": "1500",
+      "optical-info": {"rx-power": "-19.25", "temperature": 40}
+    },
+    {
+      "detected-serial-number": "HWTC87654321",
+      "onu-presence-state": "onu-present-without-v-ani"
+    }
+  ]
+}`
+
+func TestParseBBFONUStatesJSON(t *testing.T) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(testBBFONUStatesJSON), &value); err != nil {
+		t.Fatal(err)
+	}
+
+	states := ParseBBFONUStatesJSON(value)
+	if len(states) != 2 {
+		t.Fatalf("states = %d, want 2", len(states))
+	}
+	s := states[0]
+	if s.Name != "ont1" || s.ONUID != 1 || s.ChannelTermination != "ct-1/1/1" {
+		t.Errorf("state[0] = %+v", s)
+	}
+	if s.PresenceState != "onu-present-and-on-expected-channel-termination" {
+		t.Errorf("PresenceState = %q, want module prefix stripped", s.PresenceState)
+	}
+	if s.ONUDistance != 1500 || s.RxPowerDbm != -19.25 || s.Temperature != 40 {
+		t.Errorf("distance/optics = %d/%v/%v", s.ONUDistance, s.RxPowerDbm, s.Temperature)
+	}
+	if states[1].ONUID != -1 || !states[1].IsUnprovisioned() {
+		t.Errorf("state[1] = %+v", states[1])
+	}
+
+	t.Run("wrapped container", func(t *testing.T) {
+		wrapped := map[string]interface{}{"bbf-xpon-onu-states:xpon-onu-states": value}
+		if got := ParseBBFONUStatesJSON(wrapped); len(got) != 2 {
+			t.Errorf("states = %d, want 2", len(got))
+		}
+	})
+
+	t.Run("non-object value", func(t *testing.T) {
+		if got := ParseBBFONUStatesJSON("oops"); got != nil {
+			t.Errorf("got %v, want nil", got)
+		}
+	})
+}
+
+// bbfGetClient answers Get with a fixed JSON_IETF value
+type bbfGetClient struct {
+	gnmipb.GNMIClient
+	json string
+}
+
+func (c *bbfGetClient) Get(ctx context.Context, in *gnmipb.GetRequest, opts ...grpc.CallOption) (*gnmipb.GetResponse, error) {
+	return &gnmipb.GetResponse{Notification: []*gnmipb.Notification{{
+		Update: []*gnmipb.Update{{
+			Path: in.Path[0],
+			Val:  &gnmipb.TypedValue{Value: &gnmipb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(c.json)}},
+		}},
+	}}}, nil
+}
+
+func TestGetBBFONUList(t *testing.T) {
+	d := &Driver{
+		config:     &types.EquipmentConfig{Timeout: time.Second},
+		gnmiClient: &bbfGetClient{json: testBBFONUStatesJSON},
+	}
+
+	onus, err := d.GetBBFONUList(context.Background())
+	if err != nil {
+		t.Fatalf("GetBBFONUList() error: %v", err)
+	}
+	if len(onus) != 1 || onus[0].Serial != "ADTN12345678" || !onus[0].IsOnline {
+		t.Errorf("GetBBFONUList() = %+v", onus)
+	}
+
+	d.gnmiClient = nil
+	if _, err := d.GetBBFONUList(context.Background()); err == nil {
+		t.Error("expected error when not connected")
+	}
+}
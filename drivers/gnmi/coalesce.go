@@ -0,0 +1,132 @@
+package gnmi
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Coalescer merges rapid telemetry updates for the same path and drops
+// duplicate values before handing them to a downstream handler.
+//
+// With a non-zero window, updates are buffered and flushed once per window,
+// keeping only the latest update per path in first-seen order. With
+// dropDuplicates, an update is discarded when its value equals the last value
+// delivered for that path. Resync markers flush pending updates, reset the
+// duplicate cache and are always delivered.
+//
+// emit is called with the Coalescer's lock held and must not call back
+// into the Coalescer.
+type Coalescer struct {
+	window         time.Duration
+	dropDuplicates bool
+	emit           TelemetryHandler
+
+	mu      sync.Mutex
+	pending map[string]TelemetryUpdate
+	order   []string
+	last    map[string]TelemetryUpdate
+	timer   *time.Timer
+	stopped bool
+}
+
+// NewCoalescer creates a Coalescer that delivers merged updates to emit
+func NewCoalescer(window time.Duration, dropDuplicates bool, emit TelemetryHandler) *Coalescer {
+	return &Coalescer{
+		window:         window,
+		dropDuplicates: dropDuplicates,
+		emit:           emit,
+		pending:        make(map[string]TelemetryUpdate),
+		last:           make(map[string]TelemetryUpdate),
+	}
+}
+
+// Add queues updates for delivery
+func (c *Coalescer) Add(updates []TelemetryUpdate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return
+	}
+
+	for _, u := range updates {
+		if IsResyncMarker(u) {
+			c.flushLocked()
+			c.last = make(map[string]TelemetryUpdate)
+			c.emit([]TelemetryUpdate{u})
+			continue
+		}
+		if _, ok := c.pending[u.Path]; !ok {
+			c.order = append(c.order, u.Path)
+		}
+		c.pending[u.Path] = u
+	}
+
+	if c.window <= 0 {
+		c.flushLocked()
+		return
+	}
+	if len(c.pending) > 0 && c.timer == nil {
+		c.timer = time.AfterFunc(c.window, c.Flush)
+	}
+}
+
+// Flush delivers all pending updates immediately
+func (c *Coalescer) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return
+	}
+	c.flushLocked()
+}
+
+// Stop cancels the flush timer and discards pending updates.
+// No updates are delivered after Stop returns.
+func (c *Coalescer) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopped = true
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.pending = make(map[string]TelemetryUpdate)
+	c.order = nil
+}
+
+// flushLocked emits pending updates; caller must hold c.mu
+func (c *Coalescer) flushLocked() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+
+	var out []TelemetryUpdate
+	for _, path := range c.order {
+		u := c.pending[path]
+		if c.dropDuplicates {
+			if prev, ok := c.last[path]; ok && sameUpdate(prev, u) {
+				continue
+			}
+			c.last[path] = u
+		}
+		out = append(out, u)
+	}
+	c.pending = make(map[string]TelemetryUpdate)
+	c.order = nil
+
+	if len(out) > 0 {
+		c.emit(out)
+	}
+}
+
+// sameUpdate reports whether two updates carry the same value and delete state
+func sameUpdate(a, b TelemetryUpdate) bool {
+	aDeleted, _ := a.Metadata["deleted"].(bool)
+	bDeleted, _ := b.Metadata["deleted"].(bool)
+	return aDeleted == bDeleted && reflect.DeepEqual(a.Value, b.Value)
+}
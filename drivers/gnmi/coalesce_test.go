@@ -0,0 +1,135 @@
+package gnmi
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder collects batches emitted by a Coalescer
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]TelemetryUpdate
+}
+
+func (r *recorder) emit(updates []TelemetryUpdate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, updates)
+}
+
+func (r *recorder) snapshot() [][]TelemetryUpdate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]TelemetryUpdate(nil), r.batches...)
+}
+
+func TestCoalescerPassThrough(t *testing.T) {
+	rec := &recorder{}
+	c := NewCoalescer(0, false, rec.emit)
+
+	c.Add([]TelemetryUpdate{{Path: "/a", Value: 1}})
+	c.Add([]TelemetryUpdate{{Path: "/a", Value: 1}})
+
+	if got := len(rec.snapshot()); got != 2 {
+		t.Fatalf("batches = %d, want 2", got)
+	}
+}
+
+func TestCoalescerDropDuplicates(t *testing.T) {
+	rec := &recorder{}
+	c := NewCoalescer(0, true, rec.emit)
+
+	c.Add([]TelemetryUpdate{{Path: "/a", Value: int64(1)}, {Path: "/b", Value: "up"}})
+	c.Add([]TelemetryUpdate{{Path: "/a", Value: int64(1)}, {Path: "/b", Value: "down"}})
+	c.Add([]TelemetryUpdate{{Path: "/a", Value: int64(1)}})
+
+	batches := rec.snapshot()
+	if len(batches) != 2 {
+		t.Fatalf("batches = %d, want 2 (third batch fully duplicate)", len(batches))
+	}
+	if len(batches[1]) != 1 || batches[1][0].Path != "/b" {
+		t.Errorf("second batch = %+v, want only /b", batches[1])
+	}
+
+	t.Run("delete after value is not a duplicate", func(t *testing.T) {
+		c.Add([]TelemetryUpdate{{Path: "/b", Metadata: map[string]interface{}{"deleted": true}}})
+		if got := len(rec.snapshot()); got != 3 {
+			t.Errorf("batches = %d, want 3", got)
+		}
+	})
+}
+
+func TestCoalescerWindow(t *testing.T) {
+	rec := &recorder{}
+	c := NewCoalescer(time.Hour, false, rec.emit)
+
+	c.Add([]TelemetryUpdate{{Path: "/a", Value: 1}, {Path: "/b", Value: 1}})
+	c.Add([]TelemetryUpdate{{Path: "/a", Value: 2}})
+	if got := len(rec.snapshot()); got != 0 {
+		t.Fatalf("batches before flush = %d, want 0", got)
+	}
+
+	c.Flush()
+	batches := rec.snapshot()
+	if len(batches) != 1 || len(batches[0]) != 2 {
+		t.Fatalf("batches = %+v, want one batch of two", batches)
+	}
+	if batches[0][0].Path != "/a" || batches[0][0].Value != 2 {
+		t.Errorf("first update = %+v, want latest /a=2 in first-seen order", batches[0][0])
+	}
+}
+
+func TestCoalescerTimerFlush(t *testing.T) {
+	done := make(chan []TelemetryUpdate, 1)
+	c := NewCoalescer(5*time.Millisecond, false, func(u []TelemetryUpdate) { done <- u })
+
+	c.Add([]TelemetryUpdate{{Path: "/a", Value: 1}})
+	select {
+	case u := <-done:
+		if len(u) != 1 {
+			t.Errorf("flushed %d updates, want 1", len(u))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timer did not flush")
+	}
+}
+
+func TestCoalescerResyncMarker(t *testing.T) {
+	rec := &recorder{}
+	c := NewCoalescer(time.Hour, true, rec.emit)
+
+	c.Add([]TelemetryUpdate{{Path: "/a", Value: 1}})
+	c.Flush()
+	c.Add([]TelemetryUpdate{{Path: "/b", Value: 1}})
+	c.Add([]TelemetryUpdate{{Metadata: map[string]interface{}{MetadataResync: true}}})
+
+	batches := rec.snapshot()
+	if len(batches) != 3 {
+		t.Fatalf("batches = %d, want 3 (flush, pending /b, marker)", len(batches))
+	}
+	if !IsResyncMarker(batches[2][0]) {
+		t.Errorf("last batch = %+v, want resync marker", batches[2])
+	}
+
+	// Duplicate cache was reset, so /a=1 is delivered again
+	c.Add([]TelemetryUpdate{{Path: "/a", Value: 1}})
+	c.Flush()
+	if got := len(rec.snapshot()); got != 4 {
+		t.Errorf("batches = %d, want 4", got)
+	}
+}
+
+func TestCoalescerStop(t *testing.T) {
+	rec := &recorder{}
+	c := NewCoalescer(time.Hour, false, rec.emit)
+
+	c.Add([]TelemetryUpdate{{Path: "/a", Value: 1}})
+	c.Stop()
+	c.Flush()
+	c.Add([]TelemetryUpdate{{Path: "/b", Value: 1}})
+
+	if got := len(rec.snapshot()); got != 0 {
+		t.Errorf("batches after Stop = %d, want 0", got)
+	}
+}
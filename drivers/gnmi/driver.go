@@ -2,11 +2,11 @@ package gnmi
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -14,10 +14,14 @@ import (
 	"github.com/nanoncore/nano-southbound/model"
 	"github.com/nanoncore/nano-southbound/types"
 	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // SubscriptionMode defines the type of telemetry subscription
@@ -51,14 +55,89 @@ type SubscriptionConfig struct {
 	Handler           TelemetryHandler // Callback for updates
 	SuppressRedundant bool             // Suppress redundant updates
 	HeartbeatInterval time.Duration    // Heartbeat interval for ON_CHANGE
+
+	// AutoResume re-subscribes with backoff when the stream drops and emits
+	// a resync marker (see IsResyncMarker) once the new stream is up
+	AutoResume       bool
+	ResumeBackoff    time.Duration // Initial resume delay (default 1s)
+	MaxResumeBackoff time.Duration // Maximum resume delay (default 60s)
+
+	// Prefix is a common path prefix for all Paths (may carry an origin)
+	Prefix string
+	// Target names the target for multi-target collectors and proxies.
+	// Defaults to the gnmi_target equipment metadata when empty.
+	Target string
+
+	// CoalesceWindow merges updates for the same path received within the
+	// window and delivers only the latest value per path (0 disables)
+	CoalesceWindow time.Duration
+	// DropDuplicates suppresses updates whose value equals the last value
+	// delivered for the same path
+	DropDuplicates bool
+
+	// History replays stored telemetry via the gNMI history extension
+	// instead of subscribing to live updates (cannot be used with AutoResume)
+	History *HistoryRequest
+}
+
+// MetadataResync marks the TelemetryUpdate emitted after an auto-resumed
+// subscription reconnects. Consumers should treat all previously received
+// state as stale and expect a full resync from the target.
+const MetadataResync = "resync"
+
+// IsResyncMarker reports whether an update is a resync marker
+func IsResyncMarker(u TelemetryUpdate) bool {
+	resync, _ := u.Metadata[MetadataResync].(bool)
+	return resync
+}
+
+// SetOperationType selects how a path is applied within a gNMI Set request
+type SetOperationType int
+
+const (
+	// SetOperationUpdate merges the value into the existing data tree
+	SetOperationUpdate SetOperationType = iota
+	// SetOperationReplace replaces the subtree at the path with the value
+	SetOperationReplace
+	// SetOperationDelete removes the subtree at the path
+	SetOperationDelete
+	// SetOperationUnionReplace replaces the union of native and OpenConfig
+	// data at the path (gNMI 0.10.0+)
+	SetOperationUnionReplace
+)
+
+// String returns the gNMI name of the operation
+func (t SetOperationType) String() string {
+	switch t {
+	case SetOperationUpdate:
+		return "update"
+	case SetOperationReplace:
+		return "replace"
+	case SetOperationDelete:
+		return "delete"
+	case SetOperationUnionReplace:
+		return "union_replace"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(t))
+	}
+}
+
+// SetOperation is a single path operation within a gNMI Set transaction
+type SetOperation struct {
+	Type  SetOperationType // Update, replace, delete or union_replace
+	Path  string           // XPath-style path
+	Value interface{}      // Value to apply (ignored for deletes)
 }
 
 // GNMIExecutor interface for vendor adapters to use gNMI operations
 type GNMIExecutor interface {
 	// Get retrieves values at the specified paths
 	Get(ctx context.Context, paths []string) (map[string]interface{}, error)
-	// Set performs a gNMI Set operation
+	// Set performs a gNMI Set operation with merge (update) semantics
 	Set(ctx context.Context, updates map[string]interface{}, deletes []string) error
+	// SetTransaction applies a mix of update, replace, delete and
+	// union_replace operations in a single atomic SetRequest
+	SetTransaction(ctx context.Context, ops []SetOperation) error
 	// Subscribe starts a telemetry subscription
 	Subscribe(ctx context.Context, config *SubscriptionConfig) (Subscription, error)
 	// Capabilities returns the device's gNMI capabilities
@@ -100,21 +179,34 @@ type Driver struct {
 	// Subscription management
 	subscriptions map[string]*subscriptionState
 	subMu         sync.Mutex
+
+	// Channel health
+	connState    connectivity.State
+	connHandlers []ConnectivityHandler
+	healthCancel context.CancelFunc
+	healthMu     sync.RWMutex
+
+	metrics types.DriverCounters
 }
 
 // subscriptionState tracks an active subscription
 type subscriptionState struct {
-	cancel   context.CancelFunc
-	updates  chan []TelemetryUpdate
-	errors   chan error
-	stopped  bool
-	stopOnce sync.Once
+	cancel    context.CancelFunc
+	updates   chan []TelemetryUpdate
+	errors    chan error
+	handler   TelemetryHandler
+	coalescer *Coalescer
+	stopped   bool
+	stopOnce  sync.Once
 }
 
 func (s *subscriptionState) Stop() error {
 	s.stopOnce.Do(func() {
 		s.stopped = true
 		s.cancel()
+		if s.coalescer != nil {
+			s.coalescer.Stop()
+		}
 		close(s.updates)
 		close(s.errors)
 	})
@@ -168,20 +260,33 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 	if config != nil {
 		d.config = config
 	}
+	resolved, err := types.ResolveCredentials(ctx, d.config)
+	if err != nil {
+		return err
+	}
+	d.config = resolved
 
 	// Prepare gRPC dial options
 	var opts []grpc.DialOption
 
 	// TLS configuration
 	if d.config.TLSEnabled {
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: d.config.TLSSkipVerify, //nolint:gosec // User-controlled
+		tlsConfig, err := buildTLSConfig(d.config)
+		if err != nil {
+			return fmt.Errorf("invalid TLS configuration: %w", err)
 		}
 		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	} else {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
+	// Keepalive so half-open connections are detected
+	kaParams, err := keepaliveParams(d.config)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, grpc.WithKeepaliveParams(kaParams))
+
 	// Connection timeout
 	opts = append(opts, grpc.WithBlock()) //nolint:staticcheck // supported throughout 1.x
 
@@ -195,7 +300,7 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 	// Establish connection
 	conn, err := grpc.DialContext(connectCtx, target, opts...) //nolint:staticcheck // supported throughout 1.x
 	if err != nil {
-		return fmt.Errorf("failed to dial %s: %w", target, err)
+		return types.ConnectError(string(d.config.Vendor), fmt.Errorf("failed to dial %s: %w", target, err))
 	}
 
 	d.conn = conn
@@ -210,6 +315,9 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 		return fmt.Errorf("capabilities check failed: %w", err)
 	}
 	d.capabilities = caps
+	d.metrics.RecordConnect()
+
+	d.startHealthWatcher(conn, target)
 
 	return nil
 }
@@ -221,12 +329,16 @@ func (d *Driver) Disconnect(ctx context.Context) error {
 
 	// Stop all subscriptions
 	d.subMu.Lock()
-	for _, sub := range d.subscriptions {
-		_ = sub.Stop()
+	for id, sub := range d.subscriptions {
+		if err := sub.Stop(); err != nil {
+			d.config.Log().Warn("gNMI subscription stop failed", "subscription", id, "error", err)
+		}
 	}
 	d.subscriptions = make(map[string]*subscriptionState)
 	d.subMu.Unlock()
 
+	d.stopHealthWatcher()
+
 	if d.conn != nil {
 		err := d.conn.Close()
 		d.conn = nil
@@ -237,11 +349,16 @@ func (d *Driver) Disconnect(ctx context.Context) error {
 	return nil
 }
 
-// IsConnected returns true if connected
+// DriverMetrics implements types.MetricsProvider
+func (d *Driver) DriverMetrics() types.DriverMetrics {
+	return d.metrics.Snapshot()
+}
+
+// IsConnected returns true if the gRPC channel is established and ready
 func (d *Driver) IsConnected() bool {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	return d.conn != nil
+	return d.conn != nil && d.ConnectivityState() == connectivity.Ready
 }
 
 // fetchCapabilities is the internal implementation that does not acquire d.mu.
@@ -301,6 +418,7 @@ func (d *Driver) Get(ctx context.Context, paths []string) (map[string]interface{
 	}
 
 	getReq := &gnmipb.GetRequest{
+		Prefix:   d.requestPrefix(),
 		Path:     gnmiPaths,
 		Encoding: gnmipb.Encoding_JSON_IETF,
 	}
@@ -308,7 +426,18 @@ func (d *Driver) Get(ctx context.Context, paths []string) (map[string]interface{
 	getCtx, cancel := context.WithTimeout(ctx, d.config.Timeout)
 	defer cancel()
 
-	resp, err := d.gnmiClient.Get(getCtx, getReq)
+	log := d.config.Log()
+	log.Debug("gNMI Get", "paths", paths)
+	var resp *gnmipb.GetResponse
+	err := d.config.RetryPolicy().Do(ctx, func(attempt int) error {
+		if attempt > 1 {
+			d.metrics.RecordRetry()
+		}
+		var err error
+		resp, err = d.gnmiClient.Get(getCtx, getReq)
+		d.metrics.RecordCommand(err)
+		return classifyStatus(err)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("gNMI Get failed: %w", err)
 	}
@@ -317,52 +446,165 @@ func (d *Driver) Get(ctx context.Context, paths []string) (map[string]interface{
 	result := make(map[string]interface{})
 	for _, notification := range resp.Notification {
 		for _, update := range notification.Update {
-			path := PathToString(update.Path)
+			path := PathToString(JoinPath(notification.Prefix, update.Path))
 			value := decodeTypedValue(update.Val)
 			result[path] = value
 		}
 	}
+	log.Debug("gNMI Get response", "paths", paths, "values", result)
 
 	return result, nil
 }
 
 // Set performs a gNMI Set operation
 func (d *Driver) Set(ctx context.Context, updates map[string]interface{}, deletes []string) error {
+	ops := make([]SetOperation, 0, len(updates)+len(deletes))
+	for path, value := range updates {
+		ops = append(ops, SetOperation{Type: SetOperationUpdate, Path: path, Value: value})
+	}
+	for _, path := range deletes {
+		ops = append(ops, SetOperation{Type: SetOperationDelete, Path: path})
+	}
+	return d.SetTransaction(ctx, ops)
+}
+
+// SetTransaction applies all operations in a single gNMI SetRequest.
+// The target applies the request atomically: deletes first, then
+// replaces, union_replaces and updates, rolling back on any failure.
+func (d *Driver) SetTransaction(ctx context.Context, ops []SetOperation) error {
 	if d.gnmiClient == nil {
 		return fmt.Errorf("not connected to device")
 	}
+	described := describeSetOperations(ops)
+	if err := types.CheckCommands(d.config, "gnmi", described); err != nil {
+		return err
+	}
+
+	setReq, err := buildSetRequest(ops)
+	if err != nil {
+		return err
+	}
+	setReq.Prefix = d.requestPrefix()
+
+	if len(setReq.UnionReplace) > 0 && !d.supportsUnionReplace() {
+		return fmt.Errorf("union_replace not supported by target (gNMI version %s)", d.capabilities.GNMIVersion)
+	}
 
 	ctx = d.addAuthMetadata(ctx)
 
+	setCtx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	d.config.Log().Debug("gNMI Set", "operations", len(ops),
+		"deletes", len(setReq.Delete), "replaces", len(setReq.Replace),
+		"union_replaces", len(setReq.UnionReplace), "updates", len(setReq.Update))
+	// Set requests are declarative and applied atomically, so resending
+	// one after a transient failure is safe
+	err = d.config.RetryPolicy().Do(ctx, func(attempt int) error {
+		if attempt > 1 {
+			d.metrics.RecordRetry()
+		}
+		_, err := d.gnmiClient.Set(setCtx, setReq)
+		d.metrics.RecordCommand(err)
+		return classifyStatus(err)
+	})
+	if err != nil {
+		err = fmt.Errorf("gNMI Set failed: %w", err)
+	}
+	types.AuditCommands(ctx, d.config, "gnmi", described, err)
+	return err
+}
+
+// classifyStatus marks gRPC errors the target may recover from as
+// transient
+func classifyStatus(err error) error {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+		return types.Transient(err)
+	}
+	return err
+}
+
+// describeSetOperations renders ops for audit records, e.g.
+// "update /interfaces/interface[name=eth0]/config/enabled = true"
+func describeSetOperations(ops []SetOperation) []string {
+	out := make([]string, 0, len(ops))
+	for _, op := range ops {
+		if op.Type == SetOperationDelete {
+			out = append(out, fmt.Sprintf("%s %s", op.Type, op.Path))
+			continue
+		}
+		out = append(out, fmt.Sprintf("%s %s = %v", op.Type, op.Path, op.Value))
+	}
+	return out
+}
+
+// buildSetRequest converts SetOperations into a gNMI SetRequest
+func buildSetRequest(ops []SetOperation) (*gnmipb.SetRequest, error) {
 	setReq := &gnmipb.SetRequest{}
 
-	// Build updates
-	for path, value := range updates {
-		gnmiPath := ParsePath(path)
-		typedVal, err := encodeTypedValue(value)
+	for _, op := range ops {
+		gnmiPath := ParsePath(op.Path)
+
+		if op.Type == SetOperationDelete {
+			setReq.Delete = append(setReq.Delete, gnmiPath)
+			continue
+		}
+
+		typedVal, err := encodeTypedValue(op.Value)
 		if err != nil {
-			return fmt.Errorf("failed to encode value for %s: %w", path, err)
+			return nil, fmt.Errorf("failed to encode value for %s: %w", op.Path, err)
 		}
-		setReq.Update = append(setReq.Update, &gnmipb.Update{
+		update := &gnmipb.Update{
 			Path: gnmiPath,
 			Val:  typedVal,
-		})
-	}
+		}
 
-	// Build deletes
-	for _, path := range deletes {
-		setReq.Delete = append(setReq.Delete, ParsePath(path))
+		switch op.Type {
+		case SetOperationUpdate:
+			setReq.Update = append(setReq.Update, update)
+		case SetOperationReplace:
+			setReq.Replace = append(setReq.Replace, update)
+		case SetOperationUnionReplace:
+			setReq.UnionReplace = append(setReq.UnionReplace, update)
+		default:
+			return nil, fmt.Errorf("unsupported set operation %s for %s", op.Type, op.Path)
+		}
 	}
 
-	setCtx, cancel := context.WithTimeout(ctx, d.config.Timeout)
-	defer cancel()
+	return setReq, nil
+}
 
-	_, err := d.gnmiClient.Set(setCtx, setReq)
-	if err != nil {
-		return fmt.Errorf("gNMI Set failed: %w", err)
+// supportsUnionReplace reports whether the target advertises a gNMI version
+// that includes union_replace. Targets with unknown capabilities are trusted.
+func (d *Driver) supportsUnionReplace() bool {
+	if d.capabilities == nil || d.capabilities.GNMIVersion == "" {
+		return true
 	}
+	return compareGNMIVersion(d.capabilities.GNMIVersion, "0.10.0") >= 0
+}
 
-	return nil
+// compareGNMIVersion compares dotted version strings numerically.
+// Non-numeric components compare as zero.
+func compareGNMIVersion(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var av, bv int
+		if i < len(as) {
+			av, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			bv, _ = strconv.Atoi(bs[i])
+		}
+		if av != bv {
+			if av < bv {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
 }
 
 // Subscribe starts a telemetry subscription
@@ -371,6 +613,15 @@ func (d *Driver) Subscribe(ctx context.Context, config *SubscriptionConfig) (Sub
 		return nil, fmt.Errorf("not connected to device")
 	}
 
+	if config.History != nil {
+		if err := config.History.Validate(); err != nil {
+			return nil, err
+		}
+		if config.AutoResume {
+			return nil, fmt.Errorf("history subscriptions cannot auto-resume")
+		}
+	}
+
 	ctx = d.addAuthMetadata(ctx)
 
 	// Create subscription context
@@ -380,8 +631,39 @@ func (d *Driver) Subscribe(ctx context.Context, config *SubscriptionConfig) (Sub
 		cancel:  cancel,
 		updates: make(chan []TelemetryUpdate, 100),
 		errors:  make(chan error, 10),
+		handler: config.Handler,
+	}
+	if config.CoalesceWindow > 0 || config.DropDuplicates {
+		state.coalescer = NewCoalescer(config.CoalesceWindow, config.DropDuplicates, state.deliver)
+	}
+
+	target := config.Target
+	if target == "" {
+		target = d.target()
 	}
+	subReq := buildSubscribeRequest(config, target)
+
+	stream, err := d.openSubscribeStream(subCtx, subReq)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Start goroutine to process updates
+	go d.runSubscription(subCtx, stream, subReq, state, config)
 
+	// Track subscription
+	d.subMu.Lock()
+	subID := fmt.Sprintf("sub-%d", time.Now().UnixNano())
+	d.subscriptions[subID] = state
+	d.subMu.Unlock()
+
+	return state, nil
+}
+
+// buildSubscribeRequest creates the subscribe request for a config. Live
+// and history range requests use STREAM mode; history snapshots use ONCE.
+func buildSubscribeRequest(config *SubscriptionConfig, target string) *gnmipb.SubscribeRequest {
 	// Build subscription list
 	subs := make([]*gnmipb.Subscription, len(config.Paths))
 	for i, path := range config.Paths {
@@ -406,10 +688,16 @@ func (d *Driver) Subscribe(ctx context.Context, config *SubscriptionConfig) (Sub
 		subs[i] = sub
 	}
 
-	// Create subscribe request
-	subReq := &gnmipb.SubscribeRequest{
+	var prefix *gnmipb.Path
+	if config.Prefix != "" || target != "" {
+		prefix = ParsePath(config.Prefix)
+		prefix.Target = target
+	}
+
+	req := &gnmipb.SubscribeRequest{
 		Request: &gnmipb.SubscribeRequest_Subscribe{
 			Subscribe: &gnmipb.SubscriptionList{
+				Prefix:       prefix,
 				Subscription: subs,
 				Mode:         gnmipb.SubscriptionList_STREAM,
 				Encoding:     gnmipb.Encoding_JSON_IETF,
@@ -417,65 +705,159 @@ func (d *Driver) Subscribe(ctx context.Context, config *SubscriptionConfig) (Sub
 		},
 	}
 
-	// Start subscription stream
-	stream, err := d.gnmiClient.Subscribe(subCtx)
+	if config.History != nil {
+		req.Extension = []*gnmi_ext.Extension{config.History.extension()}
+		if config.History.IsSnapshot() {
+			req.GetSubscribe().Mode = gnmipb.SubscriptionList_ONCE
+			for _, sub := range subs {
+				sub.Mode = gnmipb.SubscriptionMode_TARGET_DEFINED
+				sub.SampleInterval = 0
+				sub.HeartbeatInterval = 0
+			}
+		}
+	}
+
+	return req
+}
+
+// openSubscribeStream opens a Subscribe stream and sends the request
+func (d *Driver) openSubscribeStream(ctx context.Context, subReq *gnmipb.SubscribeRequest) (gnmipb.GNMI_SubscribeClient, error) {
+	stream, err := d.gnmiClient.Subscribe(ctx)
 	if err != nil {
-		cancel()
 		return nil, fmt.Errorf("failed to create subscription stream: %w", err)
 	}
 
-	// Send subscribe request
 	if err := stream.Send(subReq); err != nil {
-		cancel()
 		return nil, fmt.Errorf("failed to send subscribe request: %w", err)
 	}
 
-	// Start goroutine to process updates
-	go d.processSubscriptionUpdates(subCtx, stream, state, config.Handler)
+	return stream, nil
+}
 
-	// Track subscription
-	d.subMu.Lock()
-	subID := fmt.Sprintf("sub-%d", time.Now().UnixNano())
-	d.subscriptions[subID] = state
-	d.subMu.Unlock()
+// runSubscription processes the stream and, when AutoResume is set,
+// re-subscribes with exponential backoff after the stream drops.
+func (d *Driver) runSubscription(
+	ctx context.Context,
+	stream gnmipb.GNMI_SubscribeClient,
+	subReq *gnmipb.SubscribeRequest,
+	state *subscriptionState,
+	config *SubscriptionConfig,
+) {
+	backoff := config.ResumeBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	maxBackoff := config.MaxResumeBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 60 * time.Second
+	}
 
-	return state, nil
+	for {
+		err := d.processSubscriptionUpdates(ctx, stream, state)
+		if err != nil {
+			state.sendError(err)
+		}
+		if !config.AutoResume || ctx.Err() != nil || state.stopped {
+			return
+		}
+
+		// Reconnect with backoff until a stream is established
+		delay := backoff
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			stream, err = d.openSubscribeStream(ctx, subReq)
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			state.sendError(fmt.Errorf("subscription resume failed: %w", err))
+			delay *= 2
+			if delay > maxBackoff {
+				delay = maxBackoff
+			}
+		}
+
+		state.publish([]TelemetryUpdate{{
+			Timestamp: time.Now(),
+			Metadata:  map[string]interface{}{MetadataResync: true},
+		}})
+	}
+}
+
+// publish routes updates through the coalescer, if configured
+func (s *subscriptionState) publish(updates []TelemetryUpdate) {
+	if s.coalescer != nil {
+		s.coalescer.Add(updates)
+		return
+	}
+	s.deliver(updates)
+}
+
+// deliver sends updates to the channel and handler.
+// Non-blocking; updates are dropped if the subscription is stopped or the
+// channel is full.
+func (s *subscriptionState) deliver(updates []TelemetryUpdate) {
+	if s.stopped {
+		return
+	}
+	select {
+	case s.updates <- updates:
+	default:
+		// Channel full, drop update
+	}
+
+	// Call handler if provided
+	if s.handler != nil {
+		s.handler(updates)
+	}
+}
+
+// sendError performs a non-blocking send on the errors channel,
+// skipping if the subscription is stopped or the channel is full
+func (s *subscriptionState) sendError(err error) {
+	if s.stopped {
+		return
+	}
+	select {
+	case s.errors <- err:
+	default:
+	}
 }
 
-// processSubscriptionUpdates handles incoming subscription updates.
+// processSubscriptionUpdates handles incoming subscription updates until the
+// stream ends. Returns the stream error, or nil on EOF or cancellation.
 // Safely checks state.stopped before sending to channels to prevent
 // panics on closed channels during Disconnect.
 func (d *Driver) processSubscriptionUpdates(
 	ctx context.Context,
 	stream gnmipb.GNMI_SubscribeClient,
 	state *subscriptionState,
-	handler TelemetryHandler,
-) {
+) error {
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			return nil
 		default:
 		}
 
 		resp, err := stream.Recv()
 		if err != nil {
 			if err == io.EOF || ctx.Err() != nil {
-				return
+				return nil
 			}
-			// Non-blocking send; skip if stopped or channel full
-			if !state.stopped {
-				select {
-				case state.errors <- fmt.Errorf("subscription error: %w", err):
-				default:
-				}
-			}
-			return
+			return fmt.Errorf("subscription error: %w", err)
 		}
 
 		// Check stopped before processing to avoid writing to closed channels
 		if state.stopped {
-			return
+			return nil
 		}
 
 		// Process response
@@ -483,17 +865,7 @@ func (d *Driver) processSubscriptionUpdates(
 		case *gnmipb.SubscribeResponse_Update:
 			updates := d.parseNotification(r.Update)
 			if len(updates) > 0 {
-				// Non-blocking send to updates channel
-				select {
-				case state.updates <- updates:
-				default:
-					// Channel full, drop update
-				}
-
-				// Call handler if provided
-				if handler != nil {
-					handler(updates)
-				}
+				state.publish(updates)
 			}
 
 		case *gnmipb.SubscribeResponse_SyncResponse:
@@ -501,50 +873,73 @@ func (d *Driver) processSubscriptionUpdates(
 			continue
 
 		case *gnmipb.SubscribeResponse_Error:
-			if !state.stopped {
-				select {
-				case state.errors <- fmt.Errorf("subscription error from device: %s", r.Error.Message): //nolint:staticcheck // deprecated but needed for backwards compat
-				default:
-				}
-			}
+			state.sendError(fmt.Errorf("subscription error from device: %s", r.Error.Message)) //nolint:staticcheck // deprecated but needed for backwards compat
 		}
 	}
 }
 
-// parseNotification converts a gNMI Notification to TelemetryUpdates
+// parseNotification converts a gNMI Notification to TelemetryUpdates.
+// Paths are joined with the notification prefix; a prefix target is
+// reported in each update's metadata under "target".
 func (d *Driver) parseNotification(notification *gnmipb.Notification) []TelemetryUpdate {
 	var updates []TelemetryUpdate
 
 	timestamp := time.Unix(0, notification.Timestamp)
+	prefix := notification.Prefix
+
+	newMetadata := func() map[string]interface{} {
+		md := make(map[string]interface{})
+		if prefix.GetTarget() != "" {
+			md["target"] = prefix.GetTarget()
+		}
+		return md
+	}
 
 	for _, update := range notification.Update {
-		path := PathToString(update.Path)
+		path := PathToString(JoinPath(prefix, update.Path))
 		value := decodeTypedValue(update.Val)
 
 		updates = append(updates, TelemetryUpdate{
 			Path:      path,
 			Value:     value,
 			Timestamp: timestamp,
-			Metadata:  make(map[string]interface{}),
+			Metadata:  newMetadata(),
 		})
 	}
 
 	// Handle deletes
 	for _, deletePath := range notification.Delete {
-		path := PathToString(deletePath)
+		path := PathToString(JoinPath(prefix, deletePath))
+		metadata := newMetadata()
+		metadata["deleted"] = true
 		updates = append(updates, TelemetryUpdate{
 			Path:      path,
 			Value:     nil, // nil indicates deletion
 			Timestamp: timestamp,
-			Metadata: map[string]interface{}{
-				"deleted": true,
-			},
+			Metadata:  metadata,
 		})
 	}
 
 	return updates
 }
 
+// target returns the gNMI target name from equipment metadata, if any
+func (d *Driver) target() string {
+	if d.config == nil {
+		return ""
+	}
+	return d.config.Metadata["gnmi_target"]
+}
+
+// requestPrefix returns the request prefix carrying the configured target,
+// or nil when no target is configured
+func (d *Driver) requestPrefix() *gnmipb.Path {
+	if target := d.target(); target != "" {
+		return &gnmipb.Path{Target: target}
+	}
+	return nil
+}
+
 // addAuthMetadata adds authentication to the context
 func (d *Driver) addAuthMetadata(ctx context.Context) context.Context {
 	if d.config.Username != "" && d.config.Password != "" {
@@ -561,16 +956,34 @@ func (d *Driver) addAuthMetadata(ctx context.Context) context.Context {
 // Supports formats:
 //   - /interfaces/interface[name=eth0]/state/counters
 //   - interfaces/interface[name=eth0]/state/counters
+//   - openconfig:/interfaces/interface[name=eth0]/state (origin "openconfig")
+//   - Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces (origin is the module)
+//
+// An origin is recognised only when the path does not start with "/" and a
+// colon appears before the first "/" or "[".
 func ParsePath(path string) *gnmipb.Path {
+	gnmiPath := &gnmipb.Path{}
+
+	if !strings.HasPrefix(path, "/") {
+		if end := strings.IndexAny(path, "/["); end != 0 {
+			head := path
+			if end > 0 {
+				head = path[:end]
+			}
+			if idx := strings.Index(head, ":"); idx > 0 {
+				gnmiPath.Origin = path[:idx]
+				path = path[idx+1:]
+			}
+		}
+	}
+
 	// Remove leading slash
 	path = strings.TrimPrefix(path, "/")
 
 	if path == "" {
-		return &gnmipb.Path{}
+		return gnmiPath
 	}
 
-	gnmiPath := &gnmipb.Path{}
-
 	// Split by / but handle keys properly
 	var elems []string
 	var current strings.Builder
@@ -644,6 +1057,7 @@ func ParsePath(path string) *gnmipb.Path {
 
 // PathToString converts a gNMI Path to a deterministic string format.
 // Keys are sorted alphabetically to ensure consistent output.
+// A non-empty origin is rendered as "origin:/path" so ParsePath round-trips.
 func PathToString(path *gnmipb.Path) string {
 	if path == nil {
 		return ""
@@ -666,9 +1080,28 @@ func PathToString(path *gnmipb.Path) string {
 		parts = append(parts, part)
 	}
 
+	if path.Origin != "" {
+		return path.Origin + ":/" + strings.Join(parts, "/")
+	}
 	return "/" + strings.Join(parts, "/")
 }
 
+// JoinPath prepends the elements of prefix to path. The origin is taken
+// from the prefix when set, otherwise from path. Either argument may be nil.
+func JoinPath(prefix, path *gnmipb.Path) *gnmipb.Path {
+	if prefix == nil {
+		return path
+	}
+	joined := &gnmipb.Path{
+		Origin: prefix.Origin,
+		Elem:   append(append([]*gnmipb.PathElem{}, prefix.Elem...), path.GetElem()...),
+	}
+	if joined.Origin == "" {
+		joined.Origin = path.GetOrigin()
+	}
+	return joined
+}
+
 // decodeTypedValue converts a gNMI TypedValue to Go value
 func decodeTypedValue(tv *gnmipb.TypedValue) interface{} {
 	if tv == nil {
@@ -754,13 +1187,9 @@ func encodeTypedValue(value interface{}) (*gnmipb.TypedValue, error) {
 	}
 }
 
-// CreateSubscriber provisions a subscriber using gNMI Set operation
-func (d *Driver) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (*types.SubscriberResult, error) {
-	if !d.IsConnected() {
-		return nil, fmt.Errorf("not connected to device")
-	}
-
-	// Build subscriber configuration
+// buildSubscriberConfig returns the interface name, config path and
+// configuration payload for a subscriber
+func buildSubscriberConfig(subscriber *model.Subscriber, tier *model.ServiceTier) (string, string, map[string]interface{}) {
 	interfaceName := fmt.Sprintf("sub-%s", subscriber.Spec.ONUSerial)
 
 	config := map[string]interface{}{
@@ -782,8 +1211,18 @@ func (d *Driver) CreateSubscriber(ctx context.Context, subscriber *model.Subscri
 	}
 	config["qos"] = qosConfig
 
-	// Build path and set
 	basePath := fmt.Sprintf("/interfaces/interface[name=%s]/config", interfaceName)
+	return interfaceName, basePath, config
+}
+
+// CreateSubscriber provisions a subscriber using gNMI Set operation
+func (d *Driver) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (*types.SubscriberResult, error) {
+	if !d.IsConnected() {
+		return nil, fmt.Errorf("not connected to device")
+	}
+
+	// Build subscriber configuration
+	interfaceName, basePath, config := buildSubscriberConfig(subscriber, tier)
 	updates := map[string]interface{}{
 		basePath: config,
 	}
@@ -806,12 +1245,22 @@ func (d *Driver) CreateSubscriber(ctx context.Context, subscriber *model.Subscri
 	return result, nil
 }
 
-// UpdateSubscriber updates subscriber configuration using gNMI Set
+// UpdateSubscriber atomically replaces the subscriber interface configuration
+// so stale leaves from the previous tier are removed in the same transaction
 func (d *Driver) UpdateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) error {
-	// Delete and recreate for now
-	_ = d.DeleteSubscriber(ctx, subscriber.Name)
-	_, err := d.CreateSubscriber(ctx, subscriber, tier)
-	return err
+	if !d.IsConnected() {
+		return fmt.Errorf("not connected to device")
+	}
+
+	_, basePath, config := buildSubscriberConfig(subscriber, tier)
+	ops := []SetOperation{
+		{Type: SetOperationReplace, Path: basePath, Value: config},
+	}
+
+	if err := d.SetTransaction(ctx, ops); err != nil {
+		return fmt.Errorf("failed to update subscriber: %w", err)
+	}
+	return nil
 }
 
 // DeleteSubscriber removes a subscriber using gNMI Delete operation
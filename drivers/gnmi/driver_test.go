@@ -10,6 +10,7 @@ import (
 	"github.com/nanoncore/nano-southbound/types"
 	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -663,16 +664,28 @@ func TestIsConnected(t *testing.T) {
 		}
 	})
 
-	t.Run("driver with conn set returns true", func(t *testing.T) {
+	t.Run("driver with ready conn returns true", func(t *testing.T) {
 		d := &Driver{
 			config: &types.EquipmentConfig{Address: "10.0.0.1"},
 		}
 		// Set conn to a non-nil value to simulate connected state.
 		// We use an empty grpc.ClientConn struct pointer (no real connection).
-		// This is safe because IsConnected only checks for nil.
+		// This is safe because IsConnected only reads the tracked state.
 		d.conn = &dummyConn
+		d.connState = connectivity.Ready
 		if !d.IsConnected() {
-			t.Error("IsConnected() = false, want true when conn is set")
+			t.Error("IsConnected() = false, want true when conn is ready")
+		}
+	})
+
+	t.Run("driver with failed conn returns false", func(t *testing.T) {
+		d := &Driver{
+			config:    &types.EquipmentConfig{Address: "10.0.0.1"},
+			conn:      &dummyConn,
+			connState: connectivity.TransientFailure,
+		}
+		if d.IsConnected() {
+			t.Error("IsConnected() = true, want false in TRANSIENT_FAILURE")
 		}
 	})
 }
@@ -1621,6 +1634,498 @@ func TestParsePathAdditional(t *testing.T) {
 	})
 }
 
+// ---------------------------------------------------------------------------
+// SetTransaction
+// ---------------------------------------------------------------------------
+
+// fakeGNMIClient records requests; unimplemented RPCs panic via the nil embed.
+type fakeGNMIClient struct {
+	gnmipb.GNMIClient
+	setReq *gnmipb.SetRequest
+	setErr error
+
+	// streams are handed out by successive Subscribe calls
+	streams    []*fakeSubscribeStream
+	subscribes int
+}
+
+func (f *fakeGNMIClient) Subscribe(ctx context.Context, opts ...grpc.CallOption) (gnmipb.GNMI_SubscribeClient, error) {
+	if f.subscribes >= len(f.streams) {
+		return nil, fmt.Errorf("no more streams")
+	}
+	stream := f.streams[f.subscribes]
+	f.subscribes++
+	stream.ctx = ctx
+	return stream, nil
+}
+
+// fakeSubscribeStream replays responses, then returns err (or blocks until
+// the context is cancelled when err is nil).
+type fakeSubscribeStream struct {
+	grpc.ClientStream
+	ctx       context.Context
+	responses []*gnmipb.SubscribeResponse
+	err       error
+	sent      []*gnmipb.SubscribeRequest
+}
+
+func (s *fakeSubscribeStream) Send(req *gnmipb.SubscribeRequest) error {
+	s.sent = append(s.sent, req)
+	return nil
+}
+
+func (s *fakeSubscribeStream) Recv() (*gnmipb.SubscribeResponse, error) {
+	if len(s.responses) > 0 {
+		resp := s.responses[0]
+		s.responses = s.responses[1:]
+		return resp, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
+
+func updateResponse(path string, val int64) *gnmipb.SubscribeResponse {
+	return &gnmipb.SubscribeResponse{Response: &gnmipb.SubscribeResponse_Update{Update: &gnmipb.Notification{
+		Update: []*gnmipb.Update{{
+			Path: ParsePath(path),
+			Val:  &gnmipb.TypedValue{Value: &gnmipb.TypedValue_IntVal{IntVal: val}},
+		}},
+	}}}
+}
+
+func (f *fakeGNMIClient) Set(ctx context.Context, in *gnmipb.SetRequest, opts ...grpc.CallOption) (*gnmipb.SetResponse, error) {
+	f.setReq = in
+	return &gnmipb.SetResponse{}, f.setErr
+}
+
+func TestBuildSetRequest(t *testing.T) {
+	ops := []SetOperation{
+		{Type: SetOperationUpdate, Path: "/a/b", Value: "x"},
+		{Type: SetOperationReplace, Path: "/interfaces/interface[name=eth0]/config", Value: map[string]interface{}{"mtu": 1500}},
+		{Type: SetOperationDelete, Path: "/c", Value: "ignored"},
+		{Type: SetOperationUnionReplace, Path: "/d", Value: true},
+	}
+
+	req, err := buildSetRequest(ops)
+	if err != nil {
+		t.Fatalf("buildSetRequest() error: %v", err)
+	}
+	if len(req.Update) != 1 || PathToString(req.Update[0].Path) != "/a/b" {
+		t.Errorf("Update = %v, want one update at /a/b", req.Update)
+	}
+	if len(req.Replace) != 1 || PathToString(req.Replace[0].Path) != "/interfaces/interface[name=eth0]/config" {
+		t.Errorf("Replace = %v, want one replace at interface config", req.Replace)
+	}
+	if len(req.Delete) != 1 || PathToString(req.Delete[0]) != "/c" {
+		t.Errorf("Delete = %v, want one delete at /c", req.Delete)
+	}
+	if len(req.UnionReplace) != 1 || PathToString(req.UnionReplace[0].Path) != "/d" {
+		t.Errorf("UnionReplace = %v, want one union_replace at /d", req.UnionReplace)
+	}
+
+	t.Run("unknown operation type", func(t *testing.T) {
+		_, err := buildSetRequest([]SetOperation{{Type: SetOperationType(99), Path: "/x", Value: 1}})
+		if err == nil || !containsSubstr(err.Error(), "unsupported set operation") {
+			t.Errorf("error = %v, want unsupported set operation", err)
+		}
+	})
+
+	t.Run("unencodable value", func(t *testing.T) {
+		_, err := buildSetRequest([]SetOperation{{Type: SetOperationReplace, Path: "/x", Value: make(chan int)}})
+		if err == nil || !containsSubstr(err.Error(), "failed to encode value for /x") {
+			t.Errorf("error = %v, want encode failure", err)
+		}
+	})
+}
+
+func TestSetOperationTypeString(t *testing.T) {
+	tests := map[SetOperationType]string{
+		SetOperationUpdate:       "update",
+		SetOperationReplace:      "replace",
+		SetOperationDelete:       "delete",
+		SetOperationUnionReplace: "union_replace",
+		SetOperationType(42):     "unknown(42)",
+	}
+	for op, want := range tests {
+		if got := op.String(); got != want {
+			t.Errorf("SetOperationType(%d).String() = %q, want %q", int(op), got, want)
+		}
+	}
+}
+
+func TestSetTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sends all operations in one request", func(t *testing.T) {
+		client := &fakeGNMIClient{}
+		d := &Driver{
+			config:     &types.EquipmentConfig{Address: "10.0.0.1", Timeout: time.Second},
+			gnmiClient: client,
+		}
+		err := d.SetTransaction(ctx, []SetOperation{
+			{Type: SetOperationReplace, Path: "/a", Value: "v"},
+			{Type: SetOperationDelete, Path: "/b"},
+		})
+		if err != nil {
+			t.Fatalf("SetTransaction() error: %v", err)
+		}
+		if client.setReq == nil || len(client.setReq.Replace) != 1 || len(client.setReq.Delete) != 1 {
+			t.Errorf("SetRequest = %v, want one replace and one delete", client.setReq)
+		}
+	})
+
+	t.Run("Set maps to update and delete", func(t *testing.T) {
+		client := &fakeGNMIClient{}
+		d := &Driver{
+			config:     &types.EquipmentConfig{Address: "10.0.0.1", Timeout: time.Second},
+			gnmiClient: client,
+		}
+		if err := d.Set(ctx, map[string]interface{}{"/a": 1}, []string{"/b"}); err != nil {
+			t.Fatalf("Set() error: %v", err)
+		}
+		if len(client.setReq.Update) != 1 || len(client.setReq.Delete) != 1 || len(client.setReq.Replace) != 0 {
+			t.Errorf("SetRequest = %v, want one update and one delete", client.setReq)
+		}
+	})
+
+	t.Run("device error is wrapped", func(t *testing.T) {
+		client := &fakeGNMIClient{setErr: fmt.Errorf("rpc failed")}
+		d := &Driver{
+			config:     &types.EquipmentConfig{Address: "10.0.0.1", Timeout: time.Second},
+			gnmiClient: client,
+		}
+		err := d.SetTransaction(ctx, []SetOperation{{Type: SetOperationUpdate, Path: "/a", Value: 1}})
+		if err == nil || !containsSubstr(err.Error(), "gNMI Set failed") {
+			t.Errorf("error = %v, want gNMI Set failed", err)
+		}
+	})
+
+	t.Run("union_replace rejected on old targets", func(t *testing.T) {
+		client := &fakeGNMIClient{}
+		d := &Driver{
+			config:       &types.EquipmentConfig{Address: "10.0.0.1", Timeout: time.Second},
+			gnmiClient:   client,
+			capabilities: &DeviceCapabilities{GNMIVersion: "0.7.0"},
+		}
+		err := d.SetTransaction(ctx, []SetOperation{{Type: SetOperationUnionReplace, Path: "/a", Value: 1}})
+		if err == nil || !containsSubstr(err.Error(), "union_replace not supported") {
+			t.Errorf("error = %v, want union_replace not supported", err)
+		}
+		if client.setReq != nil {
+			t.Error("request should not be sent when union_replace is unsupported")
+		}
+	})
+
+	t.Run("union_replace allowed on 0.10.0", func(t *testing.T) {
+		client := &fakeGNMIClient{}
+		d := &Driver{
+			config:       &types.EquipmentConfig{Address: "10.0.0.1", Timeout: time.Second},
+			gnmiClient:   client,
+			capabilities: &DeviceCapabilities{GNMIVersion: "0.10.0"},
+		}
+		err := d.SetTransaction(ctx, []SetOperation{{Type: SetOperationUnionReplace, Path: "/a", Value: 1}})
+		if err != nil {
+			t.Fatalf("SetTransaction() error: %v", err)
+		}
+	})
+
+	t.Run("not connected", func(t *testing.T) {
+		d := &Driver{config: &types.EquipmentConfig{Address: "10.0.0.1"}}
+		err := d.SetTransaction(ctx, nil)
+		if err == nil || !containsSubstr(err.Error(), "not connected") {
+			t.Errorf("error = %v, want not connected", err)
+		}
+	})
+}
+
+func TestCompareGNMIVersion(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.10.0", "0.10.0", 0},
+		{"0.7.0", "0.10.0", -1},
+		{"0.10.1", "0.10.0", 1},
+		{"1.0", "0.10.0", 1},
+		{"0.10", "0.10.0", 0},
+	}
+	for _, tt := range tests {
+		if got := compareGNMIVersion(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareGNMIVersion(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateSubscriberUsesReplace(t *testing.T) {
+	client := &fakeGNMIClient{}
+	d := &Driver{
+		config:     &types.EquipmentConfig{Address: "10.0.0.1", Timeout: time.Second},
+		conn:       &dummyConn,
+		connState:  connectivity.Ready,
+		gnmiClient: client,
+	}
+	subscriber := &model.Subscriber{
+		Name: "sub-test",
+		Spec: model.SubscriberSpec{ONUSerial: "ABCD12345678", VLAN: 100},
+	}
+	tier := &model.ServiceTier{Spec: model.ServiceTierSpec{BandwidthDown: 100, BandwidthUp: 50}}
+
+	if err := d.UpdateSubscriber(context.Background(), subscriber, tier); err != nil {
+		t.Fatalf("UpdateSubscriber() error: %v", err)
+	}
+	if len(client.setReq.Replace) != 1 || len(client.setReq.Update) != 0 || len(client.setReq.Delete) != 0 {
+		t.Fatalf("SetRequest = %v, want a single replace", client.setReq)
+	}
+	want := "/interfaces/interface[name=sub-ABCD12345678]/config"
+	if got := PathToString(client.setReq.Replace[0].Path); got != want {
+		t.Errorf("replace path = %q, want %q", got, want)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Subscription auto-resume
+// ---------------------------------------------------------------------------
+
+func TestSubscribeAutoResume(t *testing.T) {
+	client := &fakeGNMIClient{streams: []*fakeSubscribeStream{
+		{responses: []*gnmipb.SubscribeResponse{updateResponse("/a", 1)}, err: fmt.Errorf("connection reset")},
+		{err: fmt.Errorf("unavailable")},
+		{responses: []*gnmipb.SubscribeResponse{updateResponse("/a", 2)}},
+	}}
+	d := &Driver{
+		config:        &types.EquipmentConfig{Address: "10.0.0.1"},
+		gnmiClient:    client,
+		subscriptions: make(map[string]*subscriptionState),
+	}
+
+	sub, err := d.Subscribe(context.Background(), &SubscriptionConfig{
+		Paths:         []string{"/a"},
+		Mode:          SubscriptionModeOnChange,
+		AutoResume:    true,
+		ResumeBackoff: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error: %v", err)
+	}
+	defer sub.Stop()
+
+	next := func() []TelemetryUpdate {
+		t.Helper()
+		select {
+		case u := <-sub.Updates():
+			return u
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for update")
+			return nil
+		}
+	}
+
+	if u := next(); u[0].Value != int64(1) {
+		t.Errorf("first update value = %v, want 1", u[0].Value)
+	}
+	// Second stream ends immediately, so two resumes happen
+	if u := next(); !IsResyncMarker(u[0]) {
+		t.Errorf("expected resync marker, got %+v", u)
+	}
+	if u := next(); !IsResyncMarker(u[0]) {
+		t.Errorf("expected resync marker, got %+v", u)
+	}
+	if u := next(); u[0].Value != int64(2) {
+		t.Errorf("update after resume = %v, want 2", u[0].Value)
+	}
+
+	select {
+	case err := <-sub.Errors():
+		if !containsSubstr(err.Error(), "connection reset") {
+			t.Errorf("error = %v, want connection reset", err)
+		}
+	default:
+		t.Error("expected stream error to be reported")
+	}
+	if client.subscribes != 3 {
+		t.Errorf("subscribes = %d, want 3", client.subscribes)
+	}
+}
+
+func TestSubscribeWithoutAutoResume(t *testing.T) {
+	client := &fakeGNMIClient{streams: []*fakeSubscribeStream{
+		{err: fmt.Errorf("connection reset")},
+		{},
+	}}
+	d := &Driver{
+		config:        &types.EquipmentConfig{Address: "10.0.0.1"},
+		gnmiClient:    client,
+		subscriptions: make(map[string]*subscriptionState),
+	}
+
+	sub, err := d.Subscribe(context.Background(), &SubscriptionConfig{Paths: []string{"/a"}})
+	if err != nil {
+		t.Fatalf("Subscribe() error: %v", err)
+	}
+	defer sub.Stop()
+
+	select {
+	case err := <-sub.Errors():
+		if !containsSubstr(err.Error(), "connection reset") {
+			t.Errorf("error = %v, want connection reset", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for error")
+	}
+	time.Sleep(10 * time.Millisecond)
+	if client.subscribes != 1 {
+		t.Errorf("subscribes = %d, want 1", client.subscribes)
+	}
+}
+
+func TestIsResyncMarker(t *testing.T) {
+	if IsResyncMarker(TelemetryUpdate{Metadata: map[string]interface{}{"deleted": true}}) {
+		t.Error("delete update should not be a resync marker")
+	}
+	if !IsResyncMarker(TelemetryUpdate{Metadata: map[string]interface{}{MetadataResync: true}}) {
+		t.Error("resync metadata should be a resync marker")
+	}
+	if IsResyncMarker(TelemetryUpdate{}) {
+		t.Error("nil metadata should not be a resync marker")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Origin, prefix and target
+// ---------------------------------------------------------------------------
+
+func TestParsePathOrigin(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantOrigin string
+		wantFirst  string
+		wantElems  int
+	}{
+		{"origin with slash", "openconfig:/interfaces/interface[name=eth0]", "openconfig", "interfaces", 2},
+		{"vendor module origin", "Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces", "Cisco-IOS-XR-infra-statsd-oper", "infra-statistics", 2},
+		{"leading slash keeps module-qualified name", "/openconfig-interfaces:interfaces/interface", "", "openconfig-interfaces:interfaces", 2},
+		{"colon in key is not an origin", "interface[name=eth0:1]/state", "", "interface", 2},
+		{"origin only", "cli:", "cli", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParsePath(tt.path)
+			if got.Origin != tt.wantOrigin {
+				t.Errorf("Origin = %q, want %q", got.Origin, tt.wantOrigin)
+			}
+			if len(got.Elem) != tt.wantElems {
+				t.Fatalf("elem count = %d, want %d", len(got.Elem), tt.wantElems)
+			}
+			if tt.wantElems > 0 && got.Elem[0].Name != tt.wantFirst {
+				t.Errorf("elem[0] = %q, want %q", got.Elem[0].Name, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestPathToStringOriginRoundTrip(t *testing.T) {
+	in := "openconfig:/interfaces/interface[name=eth0]/state"
+	if got := PathToString(ParsePath(in)); got != in {
+		t.Errorf("round trip = %q, want %q", got, in)
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	prefix := ParsePath("openconfig:/interfaces/interface[name=eth0]")
+	prefix.Target = "olt-1"
+	path := ParsePath("state/counters/in-octets")
+
+	if got := PathToString(JoinPath(prefix, path)); got != "openconfig:/interfaces/interface[name=eth0]/state/counters/in-octets" {
+		t.Errorf("JoinPath = %q", got)
+	}
+	if got := JoinPath(nil, path); got != path {
+		t.Error("JoinPath(nil, path) should return path unchanged")
+	}
+	if got := PathToString(JoinPath(&gnmipb.Path{}, ParsePath("native:/a"))); got != "native:/a" {
+		t.Errorf("JoinPath with empty prefix = %q, want origin from path", got)
+	}
+	if len(prefix.Elem) != 2 {
+		t.Error("JoinPath must not modify the prefix")
+	}
+}
+
+func TestParseNotificationPrefix(t *testing.T) {
+	d := &Driver{}
+	prefix := ParsePath("/interfaces/interface[name=eth0]")
+	prefix.Target = "olt-1"
+
+	updates := d.parseNotification(&gnmipb.Notification{
+		Prefix: prefix,
+		Update: []*gnmipb.Update{{
+			Path: ParsePath("state/oper-status"),
+			Val:  &gnmipb.TypedValue{Value: &gnmipb.TypedValue_StringVal{StringVal: "UP"}},
+		}},
+		Delete: []*gnmipb.Path{ParsePath("state/description")},
+	})
+
+	if len(updates) != 2 {
+		t.Fatalf("updates = %d, want 2", len(updates))
+	}
+	if updates[0].Path != "/interfaces/interface[name=eth0]/state/oper-status" {
+		t.Errorf("update path = %q", updates[0].Path)
+	}
+	if updates[0].Metadata["target"] != "olt-1" {
+		t.Errorf("target metadata = %v, want olt-1", updates[0].Metadata["target"])
+	}
+	if updates[1].Metadata["deleted"] != true || updates[1].Metadata["target"] != "olt-1" {
+		t.Errorf("delete metadata = %v", updates[1].Metadata)
+	}
+}
+
+func TestBuildSubscribeRequestPrefix(t *testing.T) {
+	t.Run("prefix and target", func(t *testing.T) {
+		req := buildSubscribeRequest(&SubscriptionConfig{
+			Prefix: "openconfig:/interfaces",
+			Paths:  []string{"interface/state"},
+		}, "olt-1")
+		prefix := req.GetSubscribe().Prefix
+		if prefix == nil || prefix.Target != "olt-1" || prefix.Origin != "openconfig" || len(prefix.Elem) != 1 {
+			t.Errorf("prefix = %v", prefix)
+		}
+	})
+
+	t.Run("no prefix or target", func(t *testing.T) {
+		req := buildSubscribeRequest(&SubscriptionConfig{Paths: []string{"/a"}}, "")
+		if req.GetSubscribe().Prefix != nil {
+			t.Errorf("prefix = %v, want nil", req.GetSubscribe().Prefix)
+		}
+	})
+}
+
+func TestRequestPrefixFromMetadata(t *testing.T) {
+	d := &Driver{config: &types.EquipmentConfig{Metadata: map[string]string{"gnmi_target": "olt-1"}}}
+	if p := d.requestPrefix(); p == nil || p.Target != "olt-1" {
+		t.Errorf("requestPrefix() = %v, want target olt-1", p)
+	}
+
+	d = &Driver{config: &types.EquipmentConfig{}}
+	if p := d.requestPrefix(); p != nil {
+		t.Errorf("requestPrefix() = %v, want nil", p)
+	}
+
+	client := &fakeGNMIClient{}
+	d = &Driver{
+		config:     &types.EquipmentConfig{Timeout: time.Second, Metadata: map[string]string{"gnmi_target": "olt-1"}},
+		gnmiClient: client,
+	}
+	if err := d.Set(context.Background(), map[string]interface{}{"/a": 1}, nil); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	if client.setReq.Prefix.GetTarget() != "olt-1" {
+		t.Errorf("SetRequest prefix = %v, want target olt-1", client.setReq.Prefix)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Helpers
 // ---------------------------------------------------------------------------
@@ -0,0 +1,133 @@
+package gnmi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Keepalive defaults. Pings are only sent while RPCs (e.g. subscriptions)
+// are active, which keeps us within the default server enforcement policy.
+const (
+	DefaultKeepaliveTime    = 30 * time.Second
+	DefaultKeepaliveTimeout = 10 * time.Second
+)
+
+// ConnectivityEvent describes a change in the gRPC channel state
+type ConnectivityEvent struct {
+	Target   string
+	Previous connectivity.State
+	Current  connectivity.State
+	Time     time.Time
+}
+
+// ConnectivityHandler is called when the channel state changes
+type ConnectivityHandler func(event ConnectivityEvent)
+
+// OnConnectivityChange registers a handler for channel state changes.
+// Handlers run on the watcher goroutine and should not block.
+func (d *Driver) OnConnectivityChange(handler ConnectivityHandler) {
+	d.healthMu.Lock()
+	defer d.healthMu.Unlock()
+	d.connHandlers = append(d.connHandlers, handler)
+}
+
+// ConnectivityState returns the last observed gRPC channel state
+func (d *Driver) ConnectivityState() connectivity.State {
+	d.healthMu.RLock()
+	defer d.healthMu.RUnlock()
+	return d.connState
+}
+
+// keepaliveParams builds client keepalive parameters. The defaults can be
+// overridden with the gnmi_keepalive_time and gnmi_keepalive_timeout metadata
+// keys (Go duration strings).
+func keepaliveParams(config *types.EquipmentConfig) (keepalive.ClientParameters, error) {
+	params := keepalive.ClientParameters{
+		Time:    DefaultKeepaliveTime,
+		Timeout: DefaultKeepaliveTimeout,
+	}
+	if config.Metadata == nil {
+		return params, nil
+	}
+
+	if v := config.Metadata["gnmi_keepalive_time"]; v != "" {
+		dur, err := time.ParseDuration(v)
+		if err != nil || dur <= 0 {
+			return params, fmt.Errorf("invalid gnmi_keepalive_time %q", v)
+		}
+		params.Time = dur
+	}
+	if v := config.Metadata["gnmi_keepalive_timeout"]; v != "" {
+		dur, err := time.ParseDuration(v)
+		if err != nil || dur <= 0 {
+			return params, fmt.Errorf("invalid gnmi_keepalive_timeout %q", v)
+		}
+		params.Timeout = dur
+	}
+	return params, nil
+}
+
+// startHealthWatcher begins tracking the state of conn. Caller must hold d.mu.
+func (d *Driver) startHealthWatcher(conn *grpc.ClientConn, target string) {
+	d.stopHealthWatcher()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.healthCancel = cancel
+
+	state := conn.GetState()
+	d.healthMu.Lock()
+	d.connState = state
+	d.healthMu.Unlock()
+
+	go d.watchConnectivity(ctx, conn, target, state)
+}
+
+// stopHealthWatcher stops the watcher goroutine, if any. Caller must hold d.mu.
+func (d *Driver) stopHealthWatcher() {
+	if d.healthCancel != nil {
+		d.healthCancel()
+		d.healthCancel = nil
+	}
+}
+
+// watchConnectivity records state transitions and notifies handlers until
+// ctx is cancelled or the channel shuts down. An idle channel is kicked to
+// reconnect so that a lost connection surfaces as TRANSIENT_FAILURE rather
+// than sitting in IDLE until the next RPC.
+func (d *Driver) watchConnectivity(ctx context.Context, conn *grpc.ClientConn, target string, state connectivity.State) {
+	for {
+		if state == connectivity.Idle {
+			conn.Connect()
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return
+		}
+		next := conn.GetState()
+
+		d.healthMu.Lock()
+		d.connState = next
+		handlers := append([]ConnectivityHandler(nil), d.connHandlers...)
+		d.healthMu.Unlock()
+
+		event := ConnectivityEvent{
+			Target:   target,
+			Previous: state,
+			Current:  next,
+			Time:     time.Now(),
+		}
+		for _, h := range handlers {
+			h(event)
+		}
+
+		if next == connectivity.Shutdown {
+			return
+		}
+		state = next
+	}
+}
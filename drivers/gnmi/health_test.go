@@ -0,0 +1,132 @@
+package gnmi
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// capsServer is a gNMI server that only answers Capabilities
+type capsServer struct {
+	gnmipb.UnimplementedGNMIServer
+}
+
+func (capsServer) Capabilities(context.Context, *gnmipb.CapabilityRequest) (*gnmipb.CapabilityResponse, error) {
+	return &gnmipb.CapabilityResponse{GNMIVersion: "0.10.0"}, nil
+}
+
+func TestKeepaliveParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		metadata    map[string]string
+		wantTime    time.Duration
+		wantTimeout time.Duration
+		wantErr     bool
+	}{
+		{
+			name:        "defaults",
+			wantTime:    DefaultKeepaliveTime,
+			wantTimeout: DefaultKeepaliveTimeout,
+		},
+		{
+			name:        "overrides",
+			metadata:    map[string]string{"gnmi_keepalive_time": "1m", "gnmi_keepalive_timeout": "5s"},
+			wantTime:    time.Minute,
+			wantTimeout: 5 * time.Second,
+		},
+		{
+			name:     "invalid duration",
+			metadata: map[string]string{"gnmi_keepalive_time": "soon"},
+			wantErr:  true,
+		},
+		{
+			name:     "non-positive duration",
+			metadata: map[string]string{"gnmi_keepalive_timeout": "0s"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := keepaliveParams(&types.EquipmentConfig{Metadata: tt.metadata})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("keepaliveParams() error: %v", err)
+			}
+			if params.Time != tt.wantTime || params.Timeout != tt.wantTimeout {
+				t.Errorf("params = %+v", params)
+			}
+		})
+	}
+}
+
+func TestConnectivityWatcher(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	gnmipb.RegisterGNMIServer(srv, capsServer{})
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	addr := lis.Addr().(*net.TCPAddr)
+	d := &Driver{
+		config: &types.EquipmentConfig{
+			Address: "127.0.0.1",
+			Port:    addr.Port,
+			Timeout: 5 * time.Second,
+		},
+		subscriptions: make(map[string]*subscriptionState),
+	}
+
+	var mu sync.Mutex
+	var events []ConnectivityEvent
+	d.OnConnectivityChange(func(e ConnectivityEvent) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	})
+
+	if err := d.Connect(context.Background(), nil); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	defer func() { _ = d.Disconnect(context.Background()) }()
+
+	if !d.IsConnected() {
+		t.Fatalf("IsConnected() = false after Connect, state %v", d.ConnectivityState())
+	}
+
+	// Kill the server; the watcher should notice without any RPC being made
+	srv.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for d.IsConnected() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if d.IsConnected() {
+		t.Fatal("IsConnected() still true after server stopped")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) == 0 {
+		t.Fatal("no connectivity events emitted")
+	}
+	first := events[0]
+	if first.Previous != connectivity.Ready || first.Target != lis.Addr().String() {
+		t.Errorf("first event = %+v, want transition out of READY for %s", first, lis.Addr())
+	}
+}
@@ -0,0 +1,125 @@
+package gnmi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
+)
+
+// HistoryRequest asks the target to replay stored telemetry using the gNMI
+// history extension. Set either SnapshotTime, or Start and End.
+//
+// A snapshot returns the state of the paths as of SnapshotTime and is sent
+// as a ONCE subscription. A range replays every update between Start and
+// End as a STREAM subscription, which the target closes after End.
+type HistoryRequest struct {
+	SnapshotTime time.Time
+	Start        time.Time
+	End          time.Time
+}
+
+// IsSnapshot reports whether the request is for a point-in-time snapshot
+func (h *HistoryRequest) IsSnapshot() bool {
+	return !h.SnapshotTime.IsZero()
+}
+
+// Validate checks that exactly one of snapshot or range is set
+func (h *HistoryRequest) Validate() error {
+	hasRange := !h.Start.IsZero() || !h.End.IsZero()
+	switch {
+	case h.IsSnapshot() && hasRange:
+		return fmt.Errorf("history request cannot set both snapshot time and range")
+	case h.IsSnapshot():
+		return nil
+	case h.Start.IsZero() || h.End.IsZero():
+		return fmt.Errorf("history range requires both start and end")
+	case h.End.Before(h.Start):
+		return fmt.Errorf("history range end %s is before start %s",
+			h.End.Format(time.RFC3339), h.Start.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// extension builds the gNMI history extension for the request
+func (h *HistoryRequest) extension() *gnmi_ext.Extension {
+	history := &gnmi_ext.History{}
+	if h.IsSnapshot() {
+		history.Request = &gnmi_ext.History_SnapshotTime{SnapshotTime: h.SnapshotTime.UnixNano()}
+	} else {
+		history.Request = &gnmi_ext.History_Range{Range: &gnmi_ext.TimeRange{
+			Start: h.Start.UnixNano(),
+			End:   h.End.UnixNano(),
+		}}
+	}
+	return &gnmi_ext.Extension{Ext: &gnmi_ext.Extension_History{History: history}}
+}
+
+// Backfill replays all updates for paths recorded between start and end.
+// Use it to fill gaps after collector downtime; the target must support
+// the gNMI history extension.
+func (d *Driver) Backfill(ctx context.Context, paths []string, start, end time.Time) ([]TelemetryUpdate, error) {
+	return d.collectHistory(ctx, &SubscriptionConfig{
+		Paths:   paths,
+		Mode:    SubscriptionModeTargetDefined,
+		History: &HistoryRequest{Start: start, End: end},
+	})
+}
+
+// Snapshot returns the state of paths as it was at the given time
+func (d *Driver) Snapshot(ctx context.Context, paths []string, at time.Time) ([]TelemetryUpdate, error) {
+	return d.collectHistory(ctx, &SubscriptionConfig{
+		Paths:   paths,
+		History: &HistoryRequest{SnapshotTime: at},
+	})
+}
+
+// collectHistory runs a history subscription to completion and returns
+// every update received
+func (d *Driver) collectHistory(ctx context.Context, config *SubscriptionConfig) ([]TelemetryUpdate, error) {
+	if d.gnmiClient == nil {
+		return nil, fmt.Errorf("not connected to device")
+	}
+	if err := config.History.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(d.addAuthMetadata(ctx))
+	defer cancel()
+
+	target := config.Target
+	if target == "" {
+		target = d.target()
+	}
+	stream, err := d.openSubscribeStream(ctx, buildSubscribeRequest(config, target))
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []TelemetryUpdate
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return updates, nil
+		}
+		if err != nil {
+			return updates, fmt.Errorf("history replay failed: %w", err)
+		}
+
+		switch r := resp.Response.(type) {
+		case *gnmipb.SubscribeResponse_Update:
+			updates = append(updates, d.parseNotification(r.Update)...)
+		case *gnmipb.SubscribeResponse_SyncResponse:
+			// A snapshot is complete at sync; ranges run until the target
+			// closes the stream after the end time
+			if config.History.IsSnapshot() {
+				return updates, nil
+			}
+		case *gnmipb.SubscribeResponse_Error:
+			return updates, fmt.Errorf("history replay error from device: %s", r.Error.Message) //nolint:staticcheck // deprecated but needed for backwards compat
+		}
+	}
+}
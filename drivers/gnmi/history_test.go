@@ -0,0 +1,144 @@
+package gnmi
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
+)
+
+func TestHistoryRequestValidate(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	tests := []struct {
+		name    string
+		req     HistoryRequest
+		wantErr bool
+	}{
+		{name: "snapshot", req: HistoryRequest{SnapshotTime: t0}},
+		{name: "range", req: HistoryRequest{Start: t0, End: t0.Add(time.Hour)}},
+		{name: "empty", req: HistoryRequest{}, wantErr: true},
+		{name: "snapshot and range", req: HistoryRequest{SnapshotTime: t0, Start: t0, End: t0}, wantErr: true},
+		{name: "missing end", req: HistoryRequest{Start: t0}, wantErr: true},
+		{name: "end before start", req: HistoryRequest{Start: t0, End: t0.Add(-time.Second)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildSubscribeRequestHistory(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+
+	t.Run("snapshot uses ONCE", func(t *testing.T) {
+		req := buildSubscribeRequest(&SubscriptionConfig{
+			Paths:          []string{"/a"},
+			Mode:           SubscriptionModeSample,
+			SampleInterval: time.Second,
+			History:        &HistoryRequest{SnapshotTime: t0},
+		}, "")
+		if got := req.GetSubscribe().Mode; got != gnmipb.SubscriptionList_ONCE {
+			t.Errorf("list mode = %v, want ONCE", got)
+		}
+		if sub := req.GetSubscribe().Subscription[0]; sub.SampleInterval != 0 {
+			t.Errorf("sample interval = %d, want 0 for ONCE", sub.SampleInterval)
+		}
+		h := req.Extension[0].GetHistory()
+		if h == nil || h.GetSnapshotTime() != t0.UnixNano() {
+			t.Errorf("history extension = %v", req.Extension)
+		}
+	})
+
+	t.Run("range uses STREAM", func(t *testing.T) {
+		req := buildSubscribeRequest(&SubscriptionConfig{
+			Paths:   []string{"/a"},
+			History: &HistoryRequest{Start: t0, End: t0.Add(time.Minute)},
+		}, "")
+		if got := req.GetSubscribe().Mode; got != gnmipb.SubscriptionList_STREAM {
+			t.Errorf("list mode = %v, want STREAM", got)
+		}
+		r := req.Extension[0].GetHistory().GetRange()
+		if r == nil || r.Start != t0.UnixNano() || r.End != t0.Add(time.Minute).UnixNano() {
+			t.Errorf("range = %v", r)
+		}
+	})
+
+	t.Run("live subscription has no extension", func(t *testing.T) {
+		req := buildSubscribeRequest(&SubscriptionConfig{Paths: []string{"/a"}}, "")
+		if len(req.Extension) != 0 {
+			t.Errorf("extensions = %v, want none", req.Extension)
+		}
+	})
+}
+
+func TestBackfill(t *testing.T) {
+	stream := &fakeSubscribeStream{
+		responses: []*gnmipb.SubscribeResponse{
+			updateResponse("/a", 1),
+			{Response: &gnmipb.SubscribeResponse_SyncResponse{SyncResponse: true}},
+			updateResponse("/a", 2),
+		},
+		err: io.EOF,
+	}
+	d := &Driver{
+		config:     &types.EquipmentConfig{},
+		gnmiClient: &fakeGNMIClient{streams: []*fakeSubscribeStream{stream}},
+	}
+
+	start := time.Now().Add(-time.Hour)
+	updates, err := d.Backfill(context.Background(), []string{"/a"}, start, time.Now())
+	if err != nil {
+		t.Fatalf("Backfill() error: %v", err)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("updates = %d, want 2 (range continues past sync)", len(updates))
+	}
+	ext := stream.sent[0].Extension[0].Ext.(*gnmi_ext.Extension_History)
+	if ext.History.GetRange().GetStart() != start.UnixNano() {
+		t.Errorf("range start = %d, want %d", ext.History.GetRange().GetStart(), start.UnixNano())
+	}
+
+	if _, err := d.Backfill(context.Background(), []string{"/a"}, time.Now(), start); err == nil {
+		t.Error("Backfill() with end before start should fail")
+	}
+}
+
+func TestSnapshotStopsAtSync(t *testing.T) {
+	stream := &fakeSubscribeStream{
+		responses: []*gnmipb.SubscribeResponse{
+			updateResponse("/a", 7),
+			{Response: &gnmipb.SubscribeResponse_SyncResponse{SyncResponse: true}},
+		},
+	}
+	d := &Driver{
+		config:     &types.EquipmentConfig{},
+		gnmiClient: &fakeGNMIClient{streams: []*fakeSubscribeStream{stream}},
+	}
+
+	updates, err := d.Snapshot(context.Background(), []string{"/a"}, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("Snapshot() error: %v", err)
+	}
+	if len(updates) != 1 || updates[0].Value != int64(7) {
+		t.Errorf("Snapshot() = %+v", updates)
+	}
+}
+
+func TestSubscribeHistoryRejectsAutoResume(t *testing.T) {
+	d := &Driver{config: &types.EquipmentConfig{}, gnmiClient: &fakeGNMIClient{}}
+	_, err := d.Subscribe(context.Background(), &SubscriptionConfig{
+		Paths:      []string{"/a"},
+		AutoResume: true,
+		History:    &HistoryRequest{SnapshotTime: time.Now()},
+	})
+	if err == nil || !containsSubstr(err.Error(), "auto-resume") {
+		t.Errorf("Subscribe() error = %v, want auto-resume error", err)
+	}
+}
@@ -0,0 +1,145 @@
+package gnmi
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// CanonicalPath identifies a vendor-agnostic telemetry path.
+// The PathMapper translates it into the model each vendor actually supports.
+type CanonicalPath string
+
+const (
+	// CanonicalInterfaceCounters is the interface traffic counter container
+	CanonicalInterfaceCounters CanonicalPath = "interface-counters"
+	// CanonicalInterfaceOperStatus is the interface operational status leaf
+	CanonicalInterfaceOperStatus CanonicalPath = "interface-oper-status"
+	// CanonicalOpticsState is the transceiver optical power/temperature state
+	CanonicalOpticsState CanonicalPath = "optics-state"
+	// CanonicalSubscriberState is the per-subscriber session state
+	CanonicalSubscriberState CanonicalPath = "subscriber-state"
+)
+
+// openConfigPaths are used for vendors without a specific mapping.
+// Templates take a single key (interface or subscriber name).
+var openConfigPaths = map[CanonicalPath]string{
+	CanonicalInterfaceCounters:   "openconfig:/interfaces/interface[name=%s]/state/counters",
+	CanonicalInterfaceOperStatus: "openconfig:/interfaces/interface[name=%s]/state/oper-status",
+	CanonicalOpticsState:         "openconfig:/components/component[name=%s]/transceiver/physical-channels/channel/state",
+}
+
+// vendorPaths override the OpenConfig defaults per vendor
+var vendorPaths = map[types.Vendor]map[CanonicalPath]string{
+	types.VendorCisco: {
+		CanonicalInterfaceCounters:   "Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces/interface[interface-name=%s]/latest/generic-counters",
+		CanonicalInterfaceOperStatus: "Cisco-IOS-XR-pfi-im-cmd-oper:interfaces/interface-xr/interface[interface-name=%s]/state",
+		CanonicalOpticsState:         "Cisco-IOS-XR-controller-optics-oper:optics-oper/optics-ports/optics-port[name=%s]/optics-info",
+		CanonicalSubscriberState:     "Cisco-IOS-XR-subscriber-session-mon-oper:subscriber-session-mon/nodes/node/session-ids/session-id[session-id=%s]",
+	},
+	types.VendorNokia: {
+		CanonicalInterfaceCounters:   "/state/port[port-id=%s]/statistics",
+		CanonicalInterfaceOperStatus: "/state/port[port-id=%s]/oper-state",
+		CanonicalOpticsState:         "/state/port[port-id=%s]/transceiver",
+		CanonicalSubscriberState:     "/state/subscriber-mgmt/subscriber[subscriber-id=%s]",
+	},
+	types.VendorJuniper: {
+		CanonicalOpticsState: "/junos/system/linecard/optics/interface[name=%s]",
+	},
+	types.VendorAdtran: {
+		CanonicalInterfaceCounters:   "ietf-interfaces:interfaces-state/interface[name=%s]/statistics",
+		CanonicalInterfaceOperStatus: "ietf-interfaces:interfaces-state/interface[name=%s]/oper-status",
+	},
+}
+
+// PathMapper resolves canonical paths to vendor-specific gNMI paths.
+// Lookups fall back to OpenConfig when a vendor has no specific mapping.
+type PathMapper struct {
+	mu       sync.RWMutex
+	vendors  map[types.Vendor]map[CanonicalPath]string
+	fallback map[CanonicalPath]string
+}
+
+// NewPathMapper creates a PathMapper with the built-in vendor mappings
+func NewPathMapper() *PathMapper {
+	m := &PathMapper{
+		vendors:  make(map[types.Vendor]map[CanonicalPath]string),
+		fallback: make(map[CanonicalPath]string),
+	}
+	for canonical, template := range openConfigPaths {
+		m.fallback[canonical] = template
+	}
+	for vendor, paths := range vendorPaths {
+		for canonical, template := range paths {
+			m.Register(vendor, canonical, template)
+		}
+	}
+	return m
+}
+
+// DefaultPathMapper is the shared mapper used by Driver.ResolveCanonicalPath
+var DefaultPathMapper = NewPathMapper()
+
+// Register adds or overrides the template for a vendor.
+// The template must contain exactly one %s verb for the key.
+func (m *PathMapper) Register(vendor types.Vendor, canonical CanonicalPath, template string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.vendors[vendor] == nil {
+		m.vendors[vendor] = make(map[CanonicalPath]string)
+	}
+	m.vendors[vendor][canonical] = template
+}
+
+// Supports reports whether a canonical path can be resolved for a vendor
+func (m *PathMapper) Supports(vendor types.Vendor, canonical CanonicalPath) bool {
+	_, ok := m.template(vendor, canonical)
+	return ok
+}
+
+// Resolve returns the vendor path for a canonical path and key.
+// An empty key becomes the gNMI wildcard "*".
+func (m *PathMapper) Resolve(vendor types.Vendor, canonical CanonicalPath, key string) (string, error) {
+	template, ok := m.template(vendor, canonical)
+	if !ok {
+		return "", fmt.Errorf("canonical path %q not supported for vendor %q", canonical, vendor)
+	}
+	if key == "" {
+		key = "*"
+	}
+	return strings.Replace(template, "%s", key, 1), nil
+}
+
+// ResolveAll resolves several canonical paths with the same key
+func (m *PathMapper) ResolveAll(vendor types.Vendor, canonicals []CanonicalPath, key string) ([]string, error) {
+	paths := make([]string, 0, len(canonicals))
+	for _, canonical := range canonicals {
+		path, err := m.Resolve(vendor, canonical, key)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// template returns the vendor template, falling back to OpenConfig
+func (m *PathMapper) template(vendor types.Vendor, canonical CanonicalPath) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if template, ok := m.vendors[vendor][canonical]; ok {
+		return template, true
+	}
+	template, ok := m.fallback[canonical]
+	return template, ok
+}
+
+// ResolveCanonicalPath maps a canonical path to this device's vendor model
+// using DefaultPathMapper
+func (d *Driver) ResolveCanonicalPath(canonical CanonicalPath, key string) (string, error) {
+	return DefaultPathMapper.Resolve(d.config.Vendor, canonical, key)
+}
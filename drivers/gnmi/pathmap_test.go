@@ -0,0 +1,123 @@
+package gnmi
+
+import (
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestPathMapperResolve(t *testing.T) {
+	m := NewPathMapper()
+
+	tests := []struct {
+		name      string
+		vendor    types.Vendor
+		canonical CanonicalPath
+		key       string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "cisco native counters with module origin",
+			vendor:    types.VendorCisco,
+			canonical: CanonicalInterfaceCounters,
+			key:       "Bundle-Ether1",
+			want:      "Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces/interface[interface-name=Bundle-Ether1]/latest/generic-counters",
+		},
+		{
+			name:      "nokia subscriber state",
+			vendor:    types.VendorNokia,
+			canonical: CanonicalSubscriberState,
+			key:       "sub-1",
+			want:      "/state/subscriber-mgmt/subscriber[subscriber-id=sub-1]",
+		},
+		{
+			name:      "juniper falls back to openconfig for counters",
+			vendor:    types.VendorJuniper,
+			canonical: CanonicalInterfaceCounters,
+			key:       "ge-0/0/0",
+			want:      "openconfig:/interfaces/interface[name=ge-0/0/0]/state/counters",
+		},
+		{
+			name:      "empty key becomes wildcard",
+			vendor:    types.VendorAdtran,
+			canonical: CanonicalInterfaceOperStatus,
+			want:      "ietf-interfaces:interfaces-state/interface[name=*]/oper-status",
+		},
+		{
+			name:      "subscriber state has no openconfig fallback",
+			vendor:    types.VendorJuniper,
+			canonical: CanonicalSubscriberState,
+			key:       "sub-1",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Resolve(tt.vendor, tt.canonical, tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Resolve() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathMapperResolvedPathsParse(t *testing.T) {
+	m := NewPathMapper()
+	path, err := m.Resolve(types.VendorCisco, CanonicalOpticsState, "0/0/0/1")
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+	p := ParsePath(path)
+	if p.Origin != "Cisco-IOS-XR-controller-optics-oper" {
+		t.Errorf("Origin = %q", p.Origin)
+	}
+	if got := p.Elem[2].Key["name"]; got != "0/0/0/1" {
+		t.Errorf("optics-port key = %q, want 0/0/0/1", got)
+	}
+}
+
+func TestPathMapperRegisterAndSupports(t *testing.T) {
+	m := NewPathMapper()
+	if m.Supports(types.VendorZTE, CanonicalSubscriberState) {
+		t.Error("ZTE subscriber state should be unsupported by default")
+	}
+
+	m.Register(types.VendorZTE, CanonicalSubscriberState, "/zxr10/subscriber[name=%s]")
+	if !m.Supports(types.VendorZTE, CanonicalSubscriberState) {
+		t.Error("Supports() = false after Register")
+	}
+
+	paths, err := m.ResolveAll(types.VendorZTE, []CanonicalPath{CanonicalSubscriberState, CanonicalInterfaceCounters}, "x")
+	if err != nil {
+		t.Fatalf("ResolveAll() error: %v", err)
+	}
+	if paths[0] != "/zxr10/subscriber[name=x]" || paths[1] != "openconfig:/interfaces/interface[name=x]/state/counters" {
+		t.Errorf("ResolveAll() = %v", paths)
+	}
+
+	if _, err := m.ResolveAll(types.VendorJuniper, []CanonicalPath{CanonicalSubscriberState}, "x"); err == nil {
+		t.Error("ResolveAll() should fail on unsupported path")
+	}
+}
+
+func TestDriverResolveCanonicalPath(t *testing.T) {
+	d := &Driver{config: &types.EquipmentConfig{Vendor: types.VendorNokia}}
+	got, err := d.ResolveCanonicalPath(CanonicalInterfaceCounters, "1/1/1")
+	if err != nil {
+		t.Fatalf("ResolveCanonicalPath() error: %v", err)
+	}
+	if got != "/state/port[port-id=1/1/1]/statistics" {
+		t.Errorf("ResolveCanonicalPath() = %q", got)
+	}
+}
@@ -0,0 +1,228 @@
+package gnmi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// defaultReloadInterval is how often the TLS files are checked for changes
+const defaultReloadInterval = 5 * time.Second
+
+// fileWatch tracks the modification times of the files a reloader reads
+type fileWatch struct {
+	files    []string
+	mods     []time.Time
+	lastStat time.Time
+	interval time.Duration
+}
+
+// modTimes returns the current modification times of the files
+func (w *fileWatch) modTimes() ([]time.Time, error) {
+	mods := make([]time.Time, len(w.files))
+	for i, file := range w.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		mods[i] = info.ModTime()
+	}
+	return mods, nil
+}
+
+// changed reports whether a file has a newer modification time than when
+// it was last loaded. Files are checked at most once per interval.
+func (w *fileWatch) changed() bool {
+	now := time.Now()
+	if now.Sub(w.lastStat) < w.interval {
+		return false
+	}
+	w.lastStat = now
+	mods, err := w.modTimes()
+	if err != nil {
+		return false
+	}
+	return !slices.EqualFunc(mods, w.mods, time.Time.Equal)
+}
+
+// certReloader serves the client certificate for mutual TLS and reloads it
+// from disk when the certificate or key file changes, so rotated credentials
+// are picked up on the next handshake without reconnecting the driver.
+type certReloader struct {
+	fileWatch
+	log *slog.Logger
+
+	mu   sync.Mutex
+	cert *tls.Certificate
+}
+
+// newCertReloader loads the initial key pair and returns a reloader
+func newCertReloader(certFile, keyFile string, log *slog.Logger) (*certReloader, error) {
+	r := &certReloader{
+		fileWatch: fileWatch{files: []string{certFile, keyFile}, interval: defaultReloadInterval},
+		log:       log,
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload reads the key pair and records the file modification times
+func (r *certReloader) reload() error {
+	mods, err := r.modTimes()
+	if err != nil {
+		return fmt.Errorf("failed to stat client key pair: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(r.files[0], r.files[1])
+	if err != nil {
+		return fmt.Errorf("failed to load client key pair: %w", err)
+	}
+	r.cert = &cert
+	r.mods = mods
+	return nil
+}
+
+// GetClientCertificate implements tls.Config.GetClientCertificate. If a
+// rotated pair fails to load (e.g. cert written before key), the failure
+// is logged and the previous pair is kept.
+func (r *certReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.changed() {
+		if err := r.reload(); err != nil {
+			r.log.Warn("gnmi: client certificate reload failed, keeping the previous one", "error", err)
+		}
+	}
+	return r.cert, nil
+}
+
+// caReloader verifies the server certificate against a CA bundle it
+// reloads from disk when the file changes, so a rotated CA is trusted on
+// the next handshake without reconnecting the driver.
+type caReloader struct {
+	fileWatch
+	serverName string
+	log        *slog.Logger
+
+	mu    sync.Mutex
+	roots *x509.CertPool
+}
+
+// newCAReloader loads the initial CA bundle and returns a reloader that
+// verifies certificates for serverName
+func newCAReloader(caFile, serverName string, log *slog.Logger) (*caReloader, error) {
+	r := &caReloader{
+		fileWatch:  fileWatch{files: []string{caFile}, interval: defaultReloadInterval},
+		serverName: serverName,
+		log:        log,
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload reads the CA bundle and records its modification time
+func (r *caReloader) reload() error {
+	mods, err := r.modTimes()
+	if err != nil {
+		return fmt.Errorf("failed to read CA file: %w", err)
+	}
+	caPEM, err := os.ReadFile(r.files[0])
+	if err != nil {
+		return fmt.Errorf("failed to read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return fmt.Errorf("no certificates found in CA file %s", r.files[0])
+	}
+	r.roots = pool
+	r.mods = mods
+	return nil
+}
+
+// pool returns the current CA pool, reloading it if the file changed. A
+// bundle that fails to load is logged and the previous pool is kept.
+func (r *caReloader) pool() *x509.CertPool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.changed() {
+		if err := r.reload(); err != nil {
+			r.log.Warn("gnmi: CA reload failed, keeping the previous bundle", "error", err)
+		}
+	}
+	return r.roots
+}
+
+// VerifyConnection implements tls.Config.VerifyConnection with the checks
+// crypto/tls makes itself, against the current CA pool
+func (r *caReloader) VerifyConnection(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+	opts := x509.VerifyOptions{
+		Roots:         r.pool(),
+		DNSName:       r.serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// buildTLSConfig creates the client TLS configuration from equipment config.
+// Returns an error if only one of TLSCertFile/TLSKeyFile is set or if any
+// referenced file cannot be loaded.
+func buildTLSConfig(config *types.EquipmentConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.TLSSkipVerify, //nolint:gosec // User-controlled
+		ServerName:         config.TLSServerName,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if config.TLSCAFile != "" {
+		// gRPC verifies the server against the dialed host unless a
+		// server name is set
+		serverName := config.TLSServerName
+		if serverName == "" {
+			serverName = config.Address
+		}
+		reloader, err := newCAReloader(config.TLSCAFile, serverName, config.Log())
+		if err != nil {
+			return nil, err
+		}
+		if !config.TLSSkipVerify {
+			// crypto/tls only verifies against a fixed RootCAs pool, so the
+			// reloader verifies the server in its place
+			tlsConfig.InsecureSkipVerify = true //nolint:gosec // Verified by VerifyConnection
+			tlsConfig.VerifyConnection = reloader.VerifyConnection
+		}
+	}
+
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return nil, fmt.Errorf("both TLS client certificate and key are required for mutual TLS")
+	}
+
+	if config.TLSCertFile != "" {
+		reloader, err := newCertReloader(config.TLSCertFile, config.TLSKeyFile, config.Log())
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = reloader.GetClientCertificate
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,281 @@
+package gnmi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// writeTestKeyPair writes a self-signed certificate and key with the given
+// common name into dir and returns their paths.
+func writeTestKeyPair(t *testing.T, dir, cn string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		DNSNames:              []string{cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey: %v", err)
+	}
+
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func leafCN(t *testing.T, der []byte) string {
+	t.Helper()
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	return cert.Subject.CommonName
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, dir, "client-a")
+
+	t.Run("skip verify and server name only", func(t *testing.T) {
+		cfg, err := buildTLSConfig(&types.EquipmentConfig{TLSSkipVerify: true, TLSServerName: "olt.example"})
+		if err != nil {
+			t.Fatalf("buildTLSConfig() error: %v", err)
+		}
+		if !cfg.InsecureSkipVerify || cfg.ServerName != "olt.example" {
+			t.Errorf("InsecureSkipVerify=%v ServerName=%q", cfg.InsecureSkipVerify, cfg.ServerName)
+		}
+		if cfg.GetClientCertificate != nil || cfg.RootCAs != nil {
+			t.Error("no client cert or CA pool expected")
+		}
+	})
+
+	t.Run("client cert and CA", func(t *testing.T) {
+		cfg, err := buildTLSConfig(&types.EquipmentConfig{
+			TLSCertFile: certFile,
+			TLSKeyFile:  keyFile,
+			TLSCAFile:   certFile,
+		})
+		if err != nil {
+			t.Fatalf("buildTLSConfig() error: %v", err)
+		}
+		if !cfg.InsecureSkipVerify || cfg.VerifyConnection == nil {
+			t.Error("server not verified against the reloaded CA")
+		}
+		cert, err := cfg.GetClientCertificate(nil)
+		if err != nil || cert == nil {
+			t.Fatalf("GetClientCertificate() = %v, %v", cert, err)
+		}
+		if cn := leafCN(t, cert.Certificate[0]); cn != "client-a" {
+			t.Errorf("CN = %q, want client-a", cn)
+		}
+	})
+
+	t.Run("cert without key", func(t *testing.T) {
+		_, err := buildTLSConfig(&types.EquipmentConfig{TLSCertFile: certFile})
+		if err == nil || !containsSubstr(err.Error(), "both TLS client certificate and key") {
+			t.Errorf("error = %v, want missing key error", err)
+		}
+	})
+
+	t.Run("missing CA file", func(t *testing.T) {
+		_, err := buildTLSConfig(&types.EquipmentConfig{TLSCAFile: filepath.Join(dir, "nope.pem")})
+		if err == nil || !containsSubstr(err.Error(), "failed to read CA file") {
+			t.Errorf("error = %v, want CA read error", err)
+		}
+	})
+
+	t.Run("CA file without certificates", func(t *testing.T) {
+		bad := filepath.Join(dir, "bad.pem")
+		if err := os.WriteFile(bad, []byte("not a cert"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := buildTLSConfig(&types.EquipmentConfig{TLSCAFile: bad})
+		if err == nil || !containsSubstr(err.Error(), "no certificates found") {
+			t.Errorf("error = %v, want no certificates error", err)
+		}
+	})
+}
+
+func TestCertReloaderRotation(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, dir, "client-a")
+
+	r, err := newCertReloader(certFile, keyFile, slog.Default())
+	if err != nil {
+		t.Fatalf("newCertReloader() error: %v", err)
+	}
+	r.interval = 0
+
+	// Rotate in place with a later mtime so the change is detected even on
+	// filesystems with coarse timestamps.
+	writeTestKeyPair(t, dir, "client-b")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(certFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(keyFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := r.GetClientCertificate(nil)
+	if err != nil {
+		t.Fatalf("GetClientCertificate() error: %v", err)
+	}
+	if cn := leafCN(t, cert.Certificate[0]); cn != "client-b" {
+		t.Errorf("CN after rotation = %q, want client-b", cn)
+	}
+
+	t.Run("broken rotation keeps previous pair", func(t *testing.T) {
+		if err := os.WriteFile(keyFile, []byte("garbage"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		evenLater := later.Add(time.Minute)
+		if err := os.Chtimes(keyFile, evenLater, evenLater); err != nil {
+			t.Fatal(err)
+		}
+		cert, err := r.GetClientCertificate(nil)
+		if err != nil {
+			t.Fatalf("GetClientCertificate() error: %v", err)
+		}
+		if cn := leafCN(t, cert.Certificate[0]); cn != "client-b" {
+			t.Errorf("CN = %q, want previous client-b", cn)
+		}
+	})
+}
+
+func TestNewCertReloaderMissingFiles(t *testing.T) {
+	_, err := newCertReloader("/nonexistent/client.crt", "/nonexistent/client.key", slog.Default())
+	if err == nil {
+		t.Fatal("expected error for missing files")
+	}
+}
+
+// handshake runs a TLS handshake against a server presenting the key pair
+// in certFile and keyFile, verified by verify
+func handshake(t *testing.T, verify func(tls.ConnectionState) error, certFile, keyFile string) error {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+			return &cert, err
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.(*tls.Conn).Handshake() // The client reports the outcome
+	}()
+
+	conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec // Verified by verify
+		VerifyConnection:   verify,
+	})
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func TestCAReloaderRotation(t *testing.T) {
+	serverDir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, serverDir, "olt.example")
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	copyFile := func(mod time.Time) {
+		t.Helper()
+		pem, err := os.ReadFile(certFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(caFile, pem, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(caFile, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	copyFile(time.Now())
+
+	r, err := newCAReloader(caFile, "olt.example", slog.Default())
+	if err != nil {
+		t.Fatalf("newCAReloader() error: %v", err)
+	}
+	r.interval = 0
+	if err := handshake(t, r.VerifyConnection, certFile, keyFile); err != nil {
+		t.Fatalf("handshake() error: %v", err)
+	}
+
+	// The server moves to a certificate of a new CA
+	writeTestKeyPair(t, serverDir, "olt.example")
+	if err := handshake(t, r.VerifyConnection, certFile, keyFile); err == nil {
+		t.Fatal("handshake() with the old CA error = nil")
+	}
+	later := time.Now().Add(time.Minute)
+	copyFile(later)
+	if err := handshake(t, r.VerifyConnection, certFile, keyFile); err != nil {
+		t.Fatalf("handshake() after CA rotation error: %v", err)
+	}
+
+	t.Run("broken rotation keeps previous CA", func(t *testing.T) {
+		if err := os.WriteFile(caFile, []byte("garbage"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		evenLater := later.Add(time.Minute)
+		if err := os.Chtimes(caFile, evenLater, evenLater); err != nil {
+			t.Fatal(err)
+		}
+		if err := handshake(t, r.VerifyConnection, certFile, keyFile); err != nil {
+			t.Errorf("handshake() error: %v", err)
+		}
+	})
+
+	t.Run("wrong server name", func(t *testing.T) {
+		copyFile(time.Now())
+		other, err := newCAReloader(caFile, "other.example", slog.Default())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := handshake(t, other.VerifyConnection, certFile, keyFile); err == nil {
+			t.Error("handshake() error = nil for another server name")
+		}
+	})
+}
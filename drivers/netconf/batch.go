@@ -0,0 +1,120 @@
+package netconf
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Batch queues edit-config fragments for one device and applies them as a
+// single transaction: one <edit-config> carrying every fragment, followed
+// by one commit when the device has a candidate datastore.
+//
+// A Batch is not safe for concurrent use.
+type Batch struct {
+	exec      NETCONFExecutor
+	options   []EditOption
+	fragments []string
+}
+
+// NewBatch creates an empty batch. options apply to the combined
+// edit-config (e.g. WithMerge, WithRollbackOnError).
+func NewBatch(exec NETCONFExecutor, options ...EditOption) *Batch {
+	return &Batch{exec: exec, options: options}
+}
+
+// Add queues a configuration fragment
+func (b *Batch) Add(config string) {
+	b.fragments = append(b.fragments, config)
+}
+
+// Len returns the number of queued fragments
+func (b *Batch) Len() int {
+	return len(b.fragments)
+}
+
+// Config returns the combined configuration sent by Apply
+func (b *Batch) Config() string {
+	return strings.Join(b.fragments, "\n")
+}
+
+// Apply sends the queued fragments. With a candidate datastore the edit is
+// made under a candidate lock, committed once, and discarded on failure, so
+// either every fragment is applied or none is. Without one the edit goes to
+// running, where atomicity depends on rollback-on-error support.
+func (b *Batch) Apply(ctx context.Context) error {
+	if len(b.fragments) == 0 {
+		return nil
+	}
+	config := b.Config()
+
+	if !b.exec.HasCapability(CapCandidate) {
+		return b.exec.EditConfig(ctx, "running", config, b.options...)
+	}
+
+	// EditConfig on candidate commits, so the whole batch lands in one commit
+	return WithLease(ctx, b.exec, "candidate", 0, func(ctx context.Context) error {
+		if err := b.exec.EditConfig(ctx, "candidate", config, b.options...); err != nil {
+			_, _ = b.exec.RPC(ctx, "<discard-changes/>") //nolint:errcheck // best effort
+			return err
+		}
+		return nil
+	})
+}
+
+// BulkConfigFunc generates the configuration for request i and fills in
+// its identifying fields (Serial, PONPort, Metadata) on op
+type BulkConfigFunc func(i int, op *types.BulkOpResult) (string, error)
+
+// ProvisionBulk builds n configurations with build and applies them as one
+// Batch. Requests that fail to build are reported individually; if the
+// device rejects the batch, every queued request fails with that error.
+func ProvisionBulk(ctx context.Context, exec NETCONFExecutor, vendor string, n int, build BulkConfigFunc, options ...EditOption) *types.BulkResult {
+	result := &types.BulkResult{Results: make([]types.BulkOpResult, n)}
+	batch := NewBatch(exec, options...)
+	var queued []int
+
+	for i := range result.Results {
+		op := &result.Results[i]
+		config, err := build(i, op)
+		if err != nil {
+			setBulkError(op, AsHumanError(vendor, err))
+			continue
+		}
+		batch.Add(config)
+		queued = append(queued, i)
+	}
+
+	applyErr := batch.Apply(ctx)
+	if applyErr != nil {
+		applyErr = AsHumanError(vendor, applyErr)
+	}
+	for _, i := range queued {
+		if applyErr != nil {
+			setBulkError(&result.Results[i], applyErr)
+		} else {
+			result.Results[i].Success = true
+		}
+	}
+
+	for _, op := range result.Results {
+		if op.Success {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	return result
+}
+
+func setBulkError(op *types.BulkOpResult, err error) {
+	op.Success = false
+	op.Error = err.Error()
+	op.ErrorCode = types.ErrCodeUnknown
+	var he *types.HumanError
+	if errors.As(err, &he) {
+		op.ErrorCode = he.Code
+	}
+}
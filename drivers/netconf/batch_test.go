@@ -0,0 +1,155 @@
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// batchExecutor records edits on top of copyExecutor's operation log
+type batchExecutor struct {
+	copyExecutor
+	editErr error
+	edits   []string
+}
+
+func (e *batchExecutor) EditConfig(_ context.Context, target, config string, _ ...EditOption) error {
+	e.record("edit:" + target)
+	e.edits = append(e.edits, config)
+	return e.editErr
+}
+
+func TestBatchApply(t *testing.T) {
+	t.Run("candidate", func(t *testing.T) {
+		exec := &batchExecutor{copyExecutor: copyExecutor{caps: []string{CapCandidate}}}
+		b := NewBatch(exec, WithMerge())
+		b.Add("<a/>")
+		b.Add("<b/>")
+		if b.Len() != 2 {
+			t.Fatalf("Len() = %d, want 2", b.Len())
+		}
+		if err := b.Apply(context.Background()); err != nil {
+			t.Fatalf("Apply() error: %v", err)
+		}
+		if got := exec.operations(); got != "lock:candidate edit:candidate unlock:candidate" {
+			t.Errorf("operations = %q", got)
+		}
+		if len(exec.edits) != 1 || exec.edits[0] != "<a/>\n<b/>" {
+			t.Errorf("edits = %q, want one combined edit", exec.edits)
+		}
+	})
+
+	t.Run("running", func(t *testing.T) {
+		exec := &batchExecutor{}
+		b := NewBatch(exec)
+		b.Add("<a/>")
+		if err := b.Apply(context.Background()); err != nil {
+			t.Fatalf("Apply() error: %v", err)
+		}
+		if got := exec.operations(); got != "edit:running" {
+			t.Errorf("operations = %q", got)
+		}
+	})
+
+	t.Run("failure discards candidate", func(t *testing.T) {
+		exec := &batchExecutor{copyExecutor: copyExecutor{caps: []string{CapCandidate}}, editErr: errors.New("commit failed")}
+		b := NewBatch(exec)
+		b.Add("<a/>")
+		if err := b.Apply(context.Background()); err == nil {
+			t.Fatal("Apply() should fail")
+		}
+		if got := exec.operations(); got != "lock:candidate edit:candidate rpc:<discard-changes/> unlock:candidate" {
+			t.Errorf("operations = %q", got)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		exec := &batchExecutor{}
+		if err := NewBatch(exec).Apply(context.Background()); err != nil {
+			t.Fatalf("Apply() error: %v", err)
+		}
+		if got := exec.operations(); got != "" {
+			t.Errorf("operations = %q, want none", got)
+		}
+	})
+}
+
+func TestBatchSingleCommitOnDriver(t *testing.T) {
+	// lock, edit-config, commit, unlock
+	ok := "<rpc-reply><ok/></rpc-reply>]]>]]>"
+	var sent bytes.Buffer
+	d := &Driver{
+		config:       &types.EquipmentConfig{Address: "10.0.0.1"},
+		connected:    true,
+		capabilities: []string{CapCandidate},
+		stdin:        &netconfWriter{writer: &sent},
+		stdout:       &netconfReader{reader: newMockReader(ok, ok, ok, ok)},
+	}
+
+	b := NewBatch(d, WithMerge())
+	b.Add("<interfaces><interface><name>a</name></interface></interfaces>")
+	b.Add("<interfaces><interface><name>b</name></interface></interfaces>")
+	if err := b.Apply(context.Background()); err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+
+	got := sent.String()
+	if n := strings.Count(got, "<edit-config>"); n != 1 {
+		t.Errorf("edit-config count = %d, want 1", n)
+	}
+	if n := strings.Count(got, "<commit/>"); n != 1 {
+		t.Errorf("commit count = %d, want 1", n)
+	}
+	if !strings.Contains(got, "<name>a</name>") || !strings.Contains(got, "<name>b</name>") {
+		t.Errorf("combined edit missing fragments: %s", got)
+	}
+}
+
+func TestProvisionBulk(t *testing.T) {
+	configs := []string{"<a/>", "", "<c/>"}
+	build := func(i int, op *types.BulkOpResult) (string, error) {
+		op.Serial = string(rune('A' + i))
+		if configs[i] == "" {
+			return "", &SchemaError{Path: "/x", Reason: "bad"}
+		}
+		return configs[i], nil
+	}
+
+	t.Run("partial build failure", func(t *testing.T) {
+		exec := &batchExecutor{}
+		result := ProvisionBulk(context.Background(), exec, "test", len(configs), build)
+		if result.Succeeded != 2 || result.Failed != 1 {
+			t.Errorf("Succeeded=%d Failed=%d, want 2/1", result.Succeeded, result.Failed)
+		}
+		if r := result.Results[1]; r.Success || r.ErrorCode != types.ErrCodeValidationFailed || r.Serial != "B" {
+			t.Errorf("Results[1] = %+v", r)
+		}
+		if len(exec.edits) != 1 || exec.edits[0] != "<a/>\n<c/>" {
+			t.Errorf("edits = %q", exec.edits)
+		}
+	})
+
+	t.Run("transaction rejected", func(t *testing.T) {
+		exec := &batchExecutor{editErr: errors.New("RPC error: application: data-exists")}
+		result := ProvisionBulk(context.Background(), exec, "test", len(configs), build)
+		if result.Succeeded != 0 || result.Failed != 3 {
+			t.Errorf("Succeeded=%d Failed=%d, want 0/3", result.Succeeded, result.Failed)
+		}
+		if r := result.Results[0]; r.ErrorCode != types.ErrCodeUnknown || !strings.Contains(r.Error, "data-exists") {
+			t.Errorf("Results[0] = %+v", r)
+		}
+	})
+
+	t.Run("lock denied", func(t *testing.T) {
+		exec := &batchExecutor{copyExecutor: copyExecutor{caps: []string{CapCandidate}}}
+		exec.lockErr = &LockDeniedError{Target: "candidate"}
+		result := ProvisionBulk(context.Background(), exec, "test", len(configs), build)
+		if r := result.Results[2]; r.Success || r.ErrorCode != types.ErrCodeConfigLocked {
+			t.Errorf("Results[2] = %+v", r)
+		}
+	})
+}
@@ -0,0 +1,496 @@
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// BBF/OB-BAA ONU inventory and provisioning over standard TR-385 models.
+// Adapters for compliant OLTs (OB-BAA pAnce, Nokia Lightspan, Adtran SDX)
+// can implement DriverV2 ONU operations on top of BBFClient instead of
+// vendor-native models.
+
+// GetAllONUStatesFilterXML is the filter for all ONU operational states
+const GetAllONUStatesFilterXML = `<xpon-onu-states xmlns="urn:bbf:yang:bbf-xpon-onu-states"/>`
+
+// GetAllONUsFilterXML is the filter for all configured ONUs
+const GetAllONUsFilterXML = `<xpon xmlns="urn:bbf:yang:bbf-xpon"><onus/></xpon>`
+
+// BBFONUConfig is the configuration of an ONU in the bbf-xpon model
+type BBFONUConfig struct {
+	Name                   string
+	SerialNumber           string
+	ChannelPartition       string
+	ExpectedRegistrationID string
+	AdminState             string // AdminStateLocked or AdminStateUnlocked
+}
+
+// bbfONUStateXML mirrors an onu-state list entry in bbf-xpon-onu-states
+type bbfONUStateXML struct {
+	ONURef             string `xml:"onu-ref"`
+	DetectedSerial     string `xml:"detected-serial-number"`
+	PresenceState      string `xml:"onu-presence-state"`
+	ONUID              *int   `xml:"onu-id"`
+	ChannelTermination string `xml:"onu-channel-termination-ref"`
+	ChannelPartition   string `xml:"channel-partition-ref"`
+	AdminState         string `xml:"admin-state"`
+	OperState          string `xml:"oper-state"`
+	Distance           int    `xml:"onu-distance"`
+	OpticalInfo        struct {
+		RxPower     float64 `xml:"rx-power"`
+		TxPower     float64 `xml:"tx-power"`
+		BiasCurrent float64 `xml:"laser-bias-current"`
+		Temperature float64 `xml:"temperature"`
+		Voltage     float64 `xml:"voltage"`
+	} `xml:"optical-info"`
+}
+
+// bbfONUConfigXML mirrors an onu list entry in bbf-xpon
+type bbfONUConfigXML struct {
+	XMLName                xml.Name `xml:"onu"`
+	Name                   string   `xml:"name"`
+	SerialNumber           string   `xml:"serial-number,omitempty"`
+	ChannelPartition       string   `xml:"channel-partition-ref,omitempty"`
+	ExpectedRegistrationID string   `xml:"expected-registration-id,omitempty"`
+	AdminState             string   `xml:"admin-state,omitempty"`
+}
+
+// decodeElement finds the first element with the given local name anywhere
+// in data (e.g. inside rpc-reply/data) and decodes it into v.
+// Returns false if the element is not present.
+func decodeElement(data []byte, local string, v interface{}) (bool, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to parse XML: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == local {
+			if err := dec.DecodeElement(v, &start); err != nil {
+				return false, fmt.Errorf("failed to decode %s: %w", local, err)
+			}
+			return true, nil
+		}
+	}
+}
+
+// ParseBBFONUStates parses a bbf-xpon-onu-states response.
+// A reply without xpon-onu-states yields an empty slice.
+func ParseBBFONUStates(data []byte) ([]BBFONUState, error) {
+	var doc struct {
+		States []bbfONUStateXML `xml:"onu-state"`
+	}
+	if _, err := decodeElement(data, "xpon-onu-states", &doc); err != nil {
+		return nil, err
+	}
+
+	states := make([]BBFONUState, 0, len(doc.States))
+	for _, s := range doc.States {
+		state := BBFONUState{
+			Name:               s.ONURef,
+			SerialNumber:       s.DetectedSerial,
+			ChannelPartition:   s.ChannelPartition,
+			ChannelTermination: s.ChannelTermination,
+			PresenceState:      s.PresenceState,
+			AdminState:         s.AdminState,
+			OperState:          s.OperState,
+			RxPowerDbm:         s.OpticalInfo.RxPower,
+			TxPowerDbm:         s.OpticalInfo.TxPower,
+			LaserBiasCurrent:   s.OpticalInfo.BiasCurrent,
+			Temperature:        s.OpticalInfo.Temperature,
+			Voltage:            s.OpticalInfo.Voltage,
+			ONUDistance:        s.Distance,
+			ONUID:              -1,
+		}
+		if s.ONUID != nil {
+			state.ONUID = *s.ONUID
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// ParseBBFONUConfigs parses the onus container of a bbf-xpon response
+func ParseBBFONUConfigs(data []byte) ([]BBFONUConfig, error) {
+	var doc struct {
+		ONUs []bbfONUConfigXML `xml:"onus>onu"`
+	}
+	if _, err := decodeElement(data, "xpon", &doc); err != nil {
+		return nil, err
+	}
+
+	configs := make([]BBFONUConfig, 0, len(doc.ONUs))
+	for _, o := range doc.ONUs {
+		configs = append(configs, BBFONUConfig{
+			Name:                   o.Name,
+			SerialNumber:           o.SerialNumber,
+			ChannelPartition:       o.ChannelPartition,
+			ExpectedRegistrationID: o.ExpectedRegistrationID,
+			AdminState:             o.AdminState,
+		})
+	}
+	return configs, nil
+}
+
+// BuildBBFONUConfig renders an ONU configuration for edit-config.
+// Values are XML-escaped; name and serial number are mandatory.
+func BuildBBFONUConfig(cfg BBFONUConfig) (string, error) {
+	if cfg.Name == "" {
+		return "", fmt.Errorf("ONU name is required")
+	}
+	if cfg.SerialNumber == "" {
+		return "", fmt.Errorf("ONU serial number is required")
+	}
+	switch cfg.AdminState {
+	case "", AdminStateLocked, AdminStateUnlocked:
+	default:
+		return "", fmt.Errorf("invalid ONU admin state %q", cfg.AdminState)
+	}
+
+	onu, err := xml.Marshal(bbfONUConfigXML{
+		Name:                   cfg.Name,
+		SerialNumber:           cfg.SerialNumber,
+		ChannelPartition:       cfg.ChannelPartition,
+		ExpectedRegistrationID: cfg.ExpectedRegistrationID,
+		AdminState:             cfg.AdminState,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal ONU config: %w", err)
+	}
+	return fmt.Sprintf(`<xpon xmlns="%s"><onus>%s</onus></xpon>`, NSBBFXpon, onu), nil
+}
+
+// IsPresent reports whether the ONU is physically present on the PON
+func (s BBFONUState) IsPresent() bool {
+	return strings.HasPrefix(s.PresenceState, "onu-present")
+}
+
+// IsUnprovisioned reports whether the ONU is present but has no v-ANI,
+// i.e. it has been detected but not yet provisioned
+func (s BBFONUState) IsUnprovisioned() bool {
+	return s.PresenceState == ONUPresenceStateOnuPresentNoVANI
+}
+
+// ToONUInfo converts BBF ONU state into the DriverV2 ONUInfo model
+func (s BBFONUState) ToONUInfo() types.ONUInfo {
+	info := types.ONUInfo{
+		PONPort:     s.ChannelTermination,
+		ONUID:       s.ONUID,
+		Serial:      s.SerialNumber,
+		OperState:   s.PresenceState,
+		IsOnline:    s.PresenceState == ONUPresenceStateOnuPresentAndOnExpectedCT,
+		RxPowerDBm:  s.RxPowerDbm,
+		TxPowerDBm:  s.TxPowerDbm,
+		DistanceM:   s.ONUDistance,
+		Temperature: s.Temperature,
+		Voltage:     s.Voltage,
+		BiasCurrent: s.LaserBiasCurrent,
+	}
+	switch s.AdminState {
+	case AdminStateUnlocked:
+		info.AdminState = "enabled"
+	case AdminStateLocked:
+		info.AdminState = "disabled"
+	}
+	return info
+}
+
+// BBFClient implements ONU inventory and provisioning over the BBF
+// TR-385 models using any NETCONFExecutor
+type BBFClient struct {
+	executor NETCONFExecutor
+}
+
+// NewBBFClient creates a BBFClient
+func NewBBFClient(executor NETCONFExecutor) *BBFClient {
+	return &BBFClient{executor: executor}
+}
+
+// ONUStates returns the operational state of all ONUs known to the OLT
+func (c *BBFClient) ONUStates(ctx context.Context) ([]BBFONUState, error) {
+	reply, err := c.executor.Get(ctx, GetAllONUStatesFilterXML, WithDefaults(WithDefaultsReportAll))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ONU states: %w", err)
+	}
+	return ParseBBFONUStates(reply)
+}
+
+// ListONUs returns provisioned ONUs (present or not) as ONUInfo.
+// Configured admin state from running config is merged into the result.
+func (c *BBFClient) ListONUs(ctx context.Context) ([]types.ONUInfo, error) {
+	states, err := c.ONUStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	configReply, err := c.executor.GetConfig(ctx, "running", GetAllONUsFilterXML, WithDefaults(WithDefaultsReportAll))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ONU config: %w", err)
+	}
+	configs, err := ParseBBFONUConfigs(configReply)
+	if err != nil {
+		return nil, err
+	}
+
+	bySerial := make(map[string]BBFONUConfig, len(configs))
+	for _, cfg := range configs {
+		bySerial[cfg.SerialNumber] = cfg
+	}
+
+	onus := make([]types.ONUInfo, 0, len(configs))
+	seen := make(map[string]bool, len(configs))
+	for _, s := range states {
+		cfg, ok := bySerial[s.SerialNumber]
+		if !ok || s.IsUnprovisioned() {
+			continue
+		}
+		if s.AdminState == "" {
+			s.AdminState = cfg.AdminState
+		}
+		seen[cfg.SerialNumber] = true
+		onus = append(onus, s.ToONUInfo())
+	}
+
+	// Configured ONUs with no state entry are provisioned but absent
+	for _, cfg := range configs {
+		if seen[cfg.SerialNumber] {
+			continue
+		}
+		onus = append(onus, BBFONUState{
+			Name:          cfg.Name,
+			SerialNumber:  cfg.SerialNumber,
+			AdminState:    cfg.AdminState,
+			PresenceState: ONUPresenceStateOnuNotPresent,
+			ONUID:         -1,
+		}.ToONUInfo())
+	}
+	return onus, nil
+}
+
+// DiscoverONUs returns present ONUs that have no v-ANI yet.
+// If ponPorts is non-empty, only those channel terminations are included.
+func (c *BBFClient) DiscoverONUs(ctx context.Context, ponPorts []string) ([]types.ONUDiscovery, error) {
+	states, err := c.ONUStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(ponPorts))
+	for _, p := range ponPorts {
+		wanted[p] = true
+	}
+
+	now := time.Now()
+	var discoveries []types.ONUDiscovery
+	for _, s := range states {
+		if !s.IsUnprovisioned() {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[s.ChannelTermination] {
+			continue
+		}
+		discoveries = append(discoveries, types.ONUDiscovery{
+			PONPort:      s.ChannelTermination,
+			Serial:       s.SerialNumber,
+			State:        s.PresenceState,
+			DistanceM:    s.ONUDistance,
+			RxPowerDBm:   s.RxPowerDbm,
+			DiscoveredAt: now,
+		})
+	}
+	return discoveries, nil
+}
+
+// GetONUBySerial returns the ONU with the given serial, or nil if not found
+func (c *BBFClient) GetONUBySerial(ctx context.Context, serial string) (*types.ONUInfo, error) {
+	onus, err := c.ListONUs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range onus {
+		if strings.EqualFold(onus[i].Serial, serial) {
+			return &onus[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ProvisionONU creates or updates an ONU entry
+func (c *BBFClient) ProvisionONU(ctx context.Context, cfg BBFONUConfig) error {
+	config, err := BuildBBFONUConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if err := c.executor.EditConfig(ctx, "", config, WithMerge()); err != nil {
+		return fmt.Errorf("failed to provision ONU %s: %w", cfg.Name, err)
+	}
+	return nil
+}
+
+// SetONUAdminState locks or unlocks a provisioned ONU
+func (c *BBFClient) SetONUAdminState(ctx context.Context, name string, enabled bool) error {
+	state := AdminStateLocked
+	if enabled {
+		state = AdminStateUnlocked
+	}
+
+	var escaped bytes.Buffer
+	_ = xml.EscapeText(&escaped, []byte(name))
+	config := fmt.Sprintf(`<xpon xmlns="%s"><onus><onu><name>%s</name><admin-state>%s</admin-state></onu></onus></xpon>`,
+		NSBBFXpon, escaped.String(), state)
+
+	if err := c.executor.EditConfig(ctx, "", config, WithMerge()); err != nil {
+		return fmt.Errorf("failed to set ONU %s admin state: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteONU removes an ONU entry
+func (c *BBFClient) DeleteONU(ctx context.Context, name string) error {
+	var escaped bytes.Buffer
+	_ = xml.EscapeText(&escaped, []byte(name))
+	if err := c.executor.EditConfig(ctx, "", fmt.Sprintf(DeleteONUXML, escaped.String())); err != nil {
+		return fmt.Errorf("failed to delete ONU %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetAllInterfaceStatesFilterXML is the filter for the state of all
+// interfaces, channel terminations included
+const GetAllInterfaceStatesFilterXML = `<interfaces-state xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces"/>`
+
+// bbfInterfaceStateXML mirrors an interface list entry in
+// ietf-interfaces interfaces-state
+type bbfInterfaceStateXML struct {
+	Name        string `xml:"name"`
+	Type        string `xml:"type"`
+	AdminStatus string `xml:"admin-status"`
+	OperStatus  string `xml:"oper-status"`
+}
+
+// ParseBBFChannelTerminations parses the channel terminations (PON ports)
+// of an interfaces-state response. Other interface types are skipped.
+func ParseBBFChannelTerminations(data []byte) ([]*types.PONPortStatus, error) {
+	var doc struct {
+		Interfaces []bbfInterfaceStateXML `xml:"interface"`
+	}
+	if _, err := decodeElement(data, "interfaces-state", &doc); err != nil {
+		return nil, err
+	}
+
+	var ports []*types.PONPortStatus
+	for _, i := range doc.Interfaces {
+		// The type is an identity, usually prefixed: bbf-xponift:channel-termination
+		if i.Type != "channel-termination" && !strings.HasSuffix(i.Type, ":channel-termination") {
+			continue
+		}
+		port := &types.PONPortStatus{Port: i.Name, AdminState: "disabled", OperState: i.OperStatus}
+		if i.AdminStatus == "up" {
+			port.AdminState = "enabled"
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// GetONUList returns provisioned ONUs matching filter. ONUs of the BBF
+// models have no line profile or VLAN, so such filters match nothing.
+func (c *BBFClient) GetONUList(ctx context.Context, filter *types.ONUFilter) ([]types.ONUInfo, error) {
+	onus, err := c.ListONUs(ctx)
+	if err != nil || filter == nil {
+		return onus, err
+	}
+	filtered := make([]types.ONUInfo, 0, len(onus))
+	for _, onu := range onus {
+		if filter.PONPort != "" && onu.PONPort != filter.PONPort ||
+			filter.Status == "online" && !onu.IsOnline ||
+			filter.Status == "offline" && onu.IsOnline ||
+			filter.Profile != "" && onu.LineProfile != filter.Profile && onu.ServiceProfile != filter.Profile ||
+			filter.Serial != "" && !strings.Contains(strings.ToUpper(onu.Serial), strings.ToUpper(filter.Serial)) ||
+			filter.VLAN != 0 && onu.VLAN != filter.VLAN {
+			continue
+		}
+		filtered = append(filtered, onu)
+	}
+	return types.PageONUs(filtered, filter), nil
+}
+
+// GetONUPower returns the optical levels of the ONU with the given ID on a
+// channel termination, from its onu-state optical-info
+func (c *BBFClient) GetONUPower(ctx context.Context, ponPort string, onuID int) (*types.ONUPowerReading, error) {
+	states, err := c.ONUStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range states {
+		if s.ChannelTermination != ponPort || s.ONUID != onuID {
+			continue
+		}
+		reading := &types.ONUPowerReading{
+			PONPort:         ponPort,
+			ONUID:           onuID,
+			Serial:          s.SerialNumber,
+			TxPowerDBm:      s.TxPowerDbm,
+			RxPowerDBm:      s.RxPowerDbm,
+			DistanceM:       s.ONUDistance,
+			TxHighThreshold: types.GPONTxHighThreshold,
+			TxLowThreshold:  types.GPONTxLowThreshold,
+			RxHighThreshold: types.GPONRxHighThreshold,
+			RxLowThreshold:  types.GPONRxLowThreshold,
+			Timestamp:       time.Now(),
+			Metadata:        map[string]interface{}{"presence_state": s.PresenceState},
+		}
+		reading.IsWithinSpec = types.IsPowerWithinSpec(reading.RxPowerDBm, reading.TxPowerDBm)
+		return reading, nil
+	}
+	return nil, fmt.Errorf("ONU %s:%d not found", ponPort, onuID)
+}
+
+// ListPorts returns the channel terminations of the OLT
+func (c *BBFClient) ListPorts(ctx context.Context) ([]*types.PONPortStatus, error) {
+	reply, err := c.executor.Get(ctx, GetAllInterfaceStatesFilterXML)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interface states: %w", err)
+	}
+	return ParseBBFChannelTerminations(reply)
+}
+
+// AddPONState adds the channel terminations and ONU counts of the OLT to
+// status, for adapters that read the rest of their OLT status from a
+// vendor model. Both reads are best effort: their errors are recorded in
+// status.Metadata.
+func (c *BBFClient) AddPONState(ctx context.Context, status *types.OLTStatus) {
+	if status.Metadata == nil {
+		status.Metadata = make(map[string]interface{})
+	}
+	onusPerPort := make(map[string]int)
+	if onus, err := c.ListONUs(ctx); err != nil {
+		status.Metadata["onu_error"] = err.Error()
+	} else {
+		status.TotalONUs = len(onus)
+		for _, onu := range onus {
+			onusPerPort[onu.PONPort]++
+			if onu.IsOnline {
+				status.ActiveONUs++
+			}
+		}
+	}
+	if ports, err := c.ListPorts(ctx); err != nil {
+		status.Metadata["port_error"] = err.Error()
+	} else {
+		for _, p := range ports {
+			p.ONUCount = onusPerPort[p.Port]
+			status.PONPorts = append(status.PONPorts, *p)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package netconf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// BBFDriver implements the DriverV2 monitoring operations of a TR-385
+// compliant OLT on a BBFClient, for vendor adapters to embed. The adapter
+// adds GetOLTStatus; the other DriverV2 operations return an error until
+// the adapter overrides them, and ClearUnsupported removes them from its
+// Capabilities.
+type BBFDriver struct {
+	vendor   string
+	executor NETCONFExecutor
+}
+
+// NewBBFDriver returns a BBFDriver on executor, which may be nil if the
+// base driver has no NETCONF session. vendor names the OLT vendor in the
+// errors of unsupported operations.
+func NewBBFDriver(vendor string, executor NETCONFExecutor) BBFDriver {
+	return BBFDriver{vendor: vendor, executor: executor}
+}
+
+// bbf returns a BBF client on the NETCONF session
+func (d *BBFDriver) bbf() (*BBFClient, error) {
+	if d.executor == nil {
+		return nil, fmt.Errorf("NETCONF executor not available")
+	}
+	return NewBBFClient(d.executor), nil
+}
+
+// ClearUnsupported clears the capabilities of the DriverV2 operations
+// BBFDriver does not implement. Of DriverV2 only the ONU list, discovery,
+// ONU optical levels and OLT status remain.
+func (d *BBFDriver) ClearUnsupported(c *types.Capabilities) {
+	c.Diagnostics = false
+	c.Alarms = false
+	c.ONURestart = false
+	c.ONUFactoryReset = false
+	c.OLTRestart = false
+	c.ApplyProfile = false
+	c.BulkProvision = false
+	c.Ports = false
+	c.VLANs = false
+	c.ServicePorts = false
+	c.SubscriberMigration = false
+	c.MultiONU = false
+	c.ONUProfileQuery = false
+}
+
+// GetONUList returns the provisioned ONUs from bbf-xpon-onu-states
+func (d *BBFDriver) GetONUList(ctx context.Context, filter *types.ONUFilter) ([]types.ONUInfo, error) {
+	client, err := d.bbf()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetONUList(ctx, filter)
+}
+
+// GetONUBySerial returns the provisioned ONU with the given serial, or nil
+// if there is none
+func (d *BBFDriver) GetONUBySerial(ctx context.Context, serial string) (*types.ONUInfo, error) {
+	client, err := d.bbf()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetONUBySerial(ctx, serial)
+}
+
+// DiscoverONUs returns the present ONUs that have no v-ANI yet
+func (d *BBFDriver) DiscoverONUs(ctx context.Context, ponPorts []string) ([]types.ONUDiscovery, error) {
+	client, err := d.bbf()
+	if err != nil {
+		return nil, err
+	}
+	return client.DiscoverONUs(ctx, ponPorts)
+}
+
+// GetONUPower returns the optical levels an ONU reports in its onu-state
+func (d *BBFDriver) GetONUPower(ctx context.Context, ponPort string, onuID int) (*types.ONUPowerReading, error) {
+	client, err := d.bbf()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetONUPower(ctx, ponPort, onuID)
+}
+
+// GetONUDistance returns the ranged distance of an ONU, or -1 if the OLT
+// does not report it
+func (d *BBFDriver) GetONUDistance(ctx context.Context, ponPort string, onuID int) (int, error) {
+	power, err := d.GetONUPower(ctx, ponPort, onuID)
+	if err != nil {
+		return -1, err
+	}
+	if power.DistanceM == 0 {
+		return -1, nil
+	}
+	return power.DistanceM, nil
+}
+
+// ListPorts returns the channel terminations of the OLT
+func (d *BBFDriver) ListPorts(ctx context.Context) ([]*types.PONPortStatus, error) {
+	client, err := d.bbf()
+	if err != nil {
+		return nil, err
+	}
+	return client.ListPorts(ctx)
+}
+
+// notImplemented is the error of the DriverV2 operations BBFDriver does
+// not support
+func (d *BBFDriver) notImplemented(operation string) error {
+	return fmt.Errorf("%s not yet implemented for %s", operation, d.vendor)
+}
+
+func (d *BBFDriver) GetONUProfiles(ctx context.Context) ([]types.ONUInfo, error) {
+	return nil, d.notImplemented("GetONUProfiles")
+}
+
+func (d *BBFDriver) GetPONPower(ctx context.Context, ponPort string) (*types.PONPowerReading, error) {
+	return nil, d.notImplemented("GetPONPower")
+}
+
+func (d *BBFDriver) RunDiagnostics(ctx context.Context, ponPort string, onuID int) (*types.ONUDiagnostics, error) {
+	return nil, d.notImplemented("RunDiagnostics")
+}
+
+func (d *BBFDriver) GetAlarms(ctx context.Context) ([]types.OLTAlarm, error) {
+	return nil, d.notImplemented("GetAlarms")
+}
+
+func (d *BBFDriver) RestartONU(ctx context.Context, ponPort string, onuID int) (*types.RestartONUResult, error) {
+	return nil, d.notImplemented("RestartONU")
+}
+
+func (d *BBFDriver) FactoryResetONU(ctx context.Context, ponPort string, onuID int, opts *types.FactoryResetOptions) (*types.FactoryResetResult, error) {
+	return nil, d.notImplemented("FactoryResetONU")
+}
+
+func (d *BBFDriver) ApplyProfile(ctx context.Context, ponPort string, onuID int, profile *types.ONUProfile) error {
+	return d.notImplemented("ApplyProfile")
+}
+
+func (d *BBFDriver) BulkProvision(ctx context.Context, operations []types.BulkProvisionOp) (*types.BulkResult, error) {
+	return nil, d.notImplemented("BulkProvision")
+}
+
+func (d *BBFDriver) RestartOLT(ctx context.Context) (*types.RestartOLTResult, error) {
+	err := d.notImplemented("RestartOLT")
+	return &types.RestartOLTResult{Error: err.Error(), Message: d.vendor + " OLT reboot not yet implemented"}, err
+}
+
+func (d *BBFDriver) SetPortState(ctx context.Context, port string, enabled bool) error {
+	return d.notImplemented("SetPortState")
+}
+
+func (d *BBFDriver) ListVLANs(ctx context.Context) ([]types.VLANInfo, error) {
+	return nil, d.notImplemented("ListVLANs")
+}
+
+func (d *BBFDriver) GetVLAN(ctx context.Context, vlanID int) (*types.VLANInfo, error) {
+	return nil, d.notImplemented("GetVLAN")
+}
+
+func (d *BBFDriver) CreateVLAN(ctx context.Context, req *types.CreateVLANRequest) error {
+	return d.notImplemented("CreateVLAN")
+}
+
+func (d *BBFDriver) DeleteVLAN(ctx context.Context, vlanID int, force bool) error {
+	return d.notImplemented("DeleteVLAN")
+}
+
+func (d *BBFDriver) ListServicePorts(ctx context.Context) ([]types.ServicePort, error) {
+	return nil, d.notImplemented("ListServicePorts")
+}
+
+func (d *BBFDriver) AddServicePort(ctx context.Context, req *types.AddServicePortRequest) error {
+	return d.notImplemented("AddServicePort")
+}
+
+func (d *BBFDriver) DeleteServicePort(ctx context.Context, ponPort string, ontID int) error {
+	return d.notImplemented("DeleteServicePort")
+}
+
+func (d *BBFDriver) CaptureSubscriberConfig(ctx context.Context, subscriberID string) (*types.SubscriberSnapshot, error) {
+	return nil, d.notImplemented("CaptureSubscriberConfig")
+}
+
+func (d *BBFDriver) RestoreSubscriberConfig(ctx context.Context, snapshot *types.SubscriberSnapshot, targetPONPort string, targetONUID int) (*types.SubscriberResult, error) {
+	return nil, d.notImplemented("RestoreSubscriberConfig")
+}
+
+func (d *BBFDriver) ReplaceONU(ctx context.Context, subscriberID string, newSerial string) (*types.ReplaceResult, error) {
+	return nil, d.notImplemented("ReplaceONU")
+}
+
+func (d *BBFDriver) SoftSuspendSubscriber(ctx context.Context, subscriberID string, opts *types.SuspendOptions) (*types.SuspensionState, error) {
+	return nil, d.notImplemented("SoftSuspendSubscriber")
+}
+
+func (d *BBFDriver) GetSuspensionState(ctx context.Context, subscriberID string) (*types.SuspensionState, error) {
+	return nil, d.notImplemented("GetSuspensionState")
+}
+
+func (d *BBFDriver) MoveSubscriber(ctx context.Context, subscriberID string, targetPONPort string, targetONUID int) (*types.MoveResult, error) {
+	return nil, d.notImplemented("MoveSubscriber")
+}
+
+func (d *BBFDriver) CheckONUCompatibility(ctx context.Context, subscriberID string, newSerial string) (*types.CompatibilityReport, error) {
+	return nil, d.notImplemented("CheckONUCompatibility")
+}
+
+func (d *BBFDriver) AddONUToSubscriber(ctx context.Context, subscriberID string, binding model.ONUBinding, tier *model.ServiceTier) (*types.SubscriberResult, error) {
+	return nil, d.notImplemented("AddONUToSubscriber")
+}
+
+func (d *BBFDriver) RemoveONUFromSubscriber(ctx context.Context, subscriberID string, serial string) error {
+	return d.notImplemented("RemoveONUFromSubscriber")
+}
+
+func (d *BBFDriver) ListSubscriberONUs(ctx context.Context, subscriberID string) ([]model.ONUBinding, error) {
+	return nil, d.notImplemented("ListSubscriberONUs")
+}
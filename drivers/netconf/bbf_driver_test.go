@@ -0,0 +1,42 @@
+package netconf
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestBBFDriver(t *testing.T) {
+	exec := &fakeExecutor{getReply: []byte(testONUStatesReply), getConfigReply: []byte(testONUConfigReply)}
+	d := NewBBFDriver("Adtran", exec)
+	ctx := context.Background()
+
+	onus, err := d.GetONUList(ctx, nil)
+	if err != nil || len(onus) == 0 {
+		t.Errorf("GetONUList() = %+v, %v", onus, err)
+	}
+	if distance, err := d.GetONUDistance(ctx, "ct-1/1/1", 1); err != nil || distance != 1200 {
+		t.Errorf("GetONUDistance() = %d, %v", distance, err)
+	}
+	if _, err := d.GetAlarms(ctx); err == nil || !strings.Contains(err.Error(), "Adtran") {
+		t.Errorf("GetAlarms() error = %v", err)
+	}
+	if res, err := d.RestartOLT(ctx); err == nil || res.Message != "Adtran OLT reboot not yet implemented" {
+		t.Errorf("RestartOLT() = %+v, %v", res, err)
+	}
+
+	c := types.Capabilities{ONUList: true, Alarms: true, VLANs: true}
+	d.ClearUnsupported(&c)
+	if !c.ONUList || c.Alarms || c.VLANs {
+		t.Errorf("ClearUnsupported() = %+v", c)
+	}
+}
+
+func TestBBFDriver_NoExecutor(t *testing.T) {
+	d := NewBBFDriver("Nokia", nil)
+	if _, err := d.GetONUList(context.Background(), nil); err == nil {
+		t.Error("GetONUList() error = nil without executor")
+	}
+}
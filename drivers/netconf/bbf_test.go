@@ -0,0 +1,317 @@
+package netconf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// fakeExecutor is a minimal NETCONFExecutor for BBF client tests.
+// testutil cannot be used here because it imports this package.
+type fakeExecutor struct {
+	getReply       []byte
+	getConfigReply []byte
+	getErr         error
+	edits          []string
+}
+
+func (f *fakeExecutor) RPC(context.Context, string) ([]byte, error) { return nil, nil }
+func (f *fakeExecutor) Get(context.Context, string, ...GetOption) ([]byte, error) {
+	return f.getReply, f.getErr
+}
+func (f *fakeExecutor) GetConfig(context.Context, string, string, ...GetOption) ([]byte, error) {
+	return f.getConfigReply, nil
+}
+func (f *fakeExecutor) EditConfig(_ context.Context, _, config string, _ ...EditOption) error {
+	f.edits = append(f.edits, config)
+	return nil
+}
+func (f *fakeExecutor) CopyConfig(context.Context, string, string) error { return nil }
+func (f *fakeExecutor) Commit(context.Context) error                     { return nil }
+func (f *fakeExecutor) Lock(context.Context, string) error               { return nil }
+func (f *fakeExecutor) Unlock(context.Context, string) error             { return nil }
+func (f *fakeExecutor) Validate(context.Context, string) error           { return nil }
+func (f *fakeExecutor) ValidateConfig(context.Context, string) error {
+	return nil
+}
+func (f *fakeExecutor) HasCapability(string) bool { return false }
+func (f *fakeExecutor) GetCapabilities() []string { return nil }
+
+const testONUStatesReply = `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" message-id="1">
+  <data>
+    <xpon-onu-states xmlns="urn:bbf:yang:bbf-xpon-onu-states">
+      <onu-state>
+        <onu-ref>ont1</onu-ref>
+        <detected-serial-number>ADTN12345678</detected-serial-number>
+        <onu-presence-state>onu-present-and-on-expected-channel-termination</onu-presence-state>
+        <onu-id>1</onu-id>
+        <onu-channel-termination-ref>ct-1/1/1</onu-channel-termination-ref>
+        <onu-distance>1200</onu-distance>
+        <optical-info>
+          <rx-power>-18.5</rx-power>
+          <tx-power>2.1</tx-power>
+          <temperature>41</temperature>
+        </optical-info>
+      </onu-state>
+      <onu-state>
+        <detected-serial-number>HWTC87654321</detected-serial-number>
+        <onu-presence-state>onu-present-without-v-ani</onu-presence-state>
+        <onu-channel-termination-ref>ct-1/1/2</onu-channel-termination-ref>
+      </onu-state>
+    </xpon-onu-states>
+  </data>
+</rpc-reply>`
+
+const testONUConfigReply = `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" message-id="2">
+  <data>
+    <xpon xmlns="urn:bbf:yang:bbf-xpon">
+      <onus>
+        <onu>
+          <name>ont1</name>
+          <serial-number>ADTN12345678</serial-number>
+          <admin-state>unlocked</admin-state>
+        </onu>
+        <onu>
+          <name>ont9</name>
+          <serial-number>ALCL00000009</serial-number>
+          <admin-state>locked</admin-state>
+        </onu>
+      </onus>
+    </xpon>
+  </data>
+</rpc-reply>`
+
+const testInterfaceStatesReply = `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" message-id="3">
+  <data>
+    <interfaces-state xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces">
+      <interface>
+        <name>ct-1/1/1</name>
+        <type xmlns:bbf-xponift="urn:bbf:yang:bbf-xpon-if-type">bbf-xponift:channel-termination</type>
+        <admin-status>up</admin-status>
+        <oper-status>up</oper-status>
+      </interface>
+      <interface>
+        <name>ct-1/1/2</name>
+        <type xmlns:bbf-xponift="urn:bbf:yang:bbf-xpon-if-type">bbf-xponift:channel-termination</type>
+        <admin-status>down</admin-status>
+        <oper-status>down</oper-status>
+      </interface>
+      <interface>
+        <name>eth-1/1/1</name>
+        <type xmlns:ianaift="urn:ietf:params:xml:ns:yang:iana-if-type">ianaift:ethernetCsmacd</type>
+        <admin-status>up</admin-status>
+        <oper-status>up</oper-status>
+      </interface>
+    </interfaces-state>
+  </data>
+</rpc-reply>`
+
+func TestParseBBFONUStates(t *testing.T) {
+	states, err := ParseBBFONUStates([]byte(testONUStatesReply))
+	if err != nil {
+		t.Fatalf("ParseBBFONUStates() error: %v", err)
+	}
+	if len(states) != 2 {
+		t.Fatalf("states = %d, want 2", len(states))
+	}
+
+	s := states[0]
+	if s.Name != "ont1" || s.SerialNumber != "ADTN12345678" || s.ONUID != 1 || s.ChannelTermination != "ct-1/1/1" {
+		t.Errorf("state[0] = %+v", s)
+	}
+	if s.RxPowerDbm != -18.5 || s.ONUDistance != 1200 {
+		t.Errorf("optical/distance = %v/%d", s.RxPowerDbm, s.ONUDistance)
+	}
+	if !s.IsPresent() || s.IsUnprovisioned() {
+		t.Error("state[0] should be present and provisioned")
+	}
+	if states[1].ONUID != -1 || !states[1].IsUnprovisioned() {
+		t.Errorf("state[1] = %+v, want unprovisioned with no ONU ID", states[1])
+	}
+
+	t.Run("no states container", func(t *testing.T) {
+		states, err := ParseBBFONUStates([]byte(`<rpc-reply><data/></rpc-reply>`))
+		if err != nil || len(states) != 0 {
+			t.Errorf("got %v, %v; want empty, nil", states, err)
+		}
+	})
+
+	t.Run("malformed XML", func(t *testing.T) {
+		if _, err := ParseBBFONUStates([]byte(`<rpc-reply><data><xpon-onu-states><onu-state>`)); err == nil {
+			t.Error("expected error for malformed XML")
+		}
+	})
+}
+
+func TestBBFONUStateToONUInfo(t *testing.T) {
+	info := BBFONUState{
+		SerialNumber:       "ADTN12345678",
+		ChannelTermination: "ct-1",
+		ONUID:              3,
+		PresenceState:      ONUPresenceStateOnuPresentAndOnExpectedCT,
+		AdminState:         AdminStateLocked,
+		RxPowerDbm:         -20,
+	}.ToONUInfo()
+
+	if !info.IsOnline || info.AdminState != "disabled" || info.PONPort != "ct-1" || info.ONUID != 3 {
+		t.Errorf("ToONUInfo() = %+v", info)
+	}
+}
+
+func TestBuildBBFONUConfig(t *testing.T) {
+	got, err := BuildBBFONUConfig(BBFONUConfig{
+		Name:         "ont<1>",
+		SerialNumber: "ADTN12345678",
+		AdminState:   AdminStateUnlocked,
+	})
+	if err != nil {
+		t.Fatalf("BuildBBFONUConfig() error: %v", err)
+	}
+	for _, want := range []string{
+		`<xpon xmlns="urn:bbf:yang:bbf-xpon">`,
+		"<name>ont&lt;1&gt;</name>",
+		"<serial-number>ADTN12345678</serial-number>",
+		"<admin-state>unlocked</admin-state>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("config missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "channel-partition-ref") {
+		t.Error("empty optional leaves should be omitted")
+	}
+
+	errCases := []BBFONUConfig{
+		{SerialNumber: "X"},
+		{Name: "ont1"},
+		{Name: "ont1", SerialNumber: "X", AdminState: "bogus"},
+	}
+	for _, cfg := range errCases {
+		if _, err := BuildBBFONUConfig(cfg); err == nil {
+			t.Errorf("BuildBBFONUConfig(%+v) expected error", cfg)
+		}
+	}
+}
+
+func TestBBFClientListONUs(t *testing.T) {
+	exec := &fakeExecutor{
+		getReply:       []byte(testONUStatesReply),
+		getConfigReply: []byte(testONUConfigReply),
+	}
+	client := NewBBFClient(exec)
+
+	onus, err := client.ListONUs(context.Background())
+	if err != nil {
+		t.Fatalf("ListONUs() error: %v", err)
+	}
+	if len(onus) != 2 {
+		t.Fatalf("ONUs = %d, want 2 (present ont1 + absent ont9)", len(onus))
+	}
+	if onus[0].Serial != "ADTN12345678" || !onus[0].IsOnline || onus[0].AdminState != "enabled" {
+		t.Errorf("onus[0] = %+v", onus[0])
+	}
+	if onus[1].Serial != "ALCL00000009" || onus[1].IsOnline || onus[1].OperState != ONUPresenceStateOnuNotPresent {
+		t.Errorf("onus[1] = %+v", onus[1])
+	}
+
+	onu, err := client.GetONUBySerial(context.Background(), "adtn12345678")
+	if err != nil || onu == nil || onu.ONUID != 1 {
+		t.Errorf("GetONUBySerial() = %+v, %v", onu, err)
+	}
+	onu, err = client.GetONUBySerial(context.Background(), "NOPE")
+	if err != nil || onu != nil {
+		t.Errorf("GetONUBySerial(missing) = %+v, %v; want nil, nil", onu, err)
+	}
+}
+
+func TestBBFClientDiscoverONUs(t *testing.T) {
+	client := NewBBFClient(&fakeExecutor{getReply: []byte(testONUStatesReply)})
+
+	found, err := client.DiscoverONUs(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("DiscoverONUs() error: %v", err)
+	}
+	if len(found) != 1 || found[0].Serial != "HWTC87654321" || found[0].PONPort != "ct-1/1/2" {
+		t.Errorf("DiscoverONUs() = %+v", found)
+	}
+
+	found, err = client.DiscoverONUs(context.Background(), []string{"ct-1/1/1"})
+	if err != nil || len(found) != 0 {
+		t.Errorf("DiscoverONUs(filtered) = %+v, %v; want none", found, err)
+	}
+
+	client = NewBBFClient(&fakeExecutor{getErr: fmt.Errorf("timeout")})
+	if _, err := client.DiscoverONUs(context.Background(), nil); err == nil {
+		t.Error("expected error from executor")
+	}
+}
+
+func TestBBFClientWrites(t *testing.T) {
+	exec := &fakeExecutor{}
+	client := NewBBFClient(exec)
+	ctx := context.Background()
+
+	if err := client.ProvisionONU(ctx, BBFONUConfig{Name: "ont1", SerialNumber: "ADTN12345678"}); err != nil {
+		t.Fatalf("ProvisionONU() error: %v", err)
+	}
+	if err := client.SetONUAdminState(ctx, "ont1", false); err != nil {
+		t.Fatalf("SetONUAdminState() error: %v", err)
+	}
+	if err := client.DeleteONU(ctx, "ont1"); err != nil {
+		t.Fatalf("DeleteONU() error: %v", err)
+	}
+	if err := client.ProvisionONU(ctx, BBFONUConfig{}); err == nil {
+		t.Error("ProvisionONU() with empty config should fail")
+	}
+
+	if len(exec.edits) != 3 {
+		t.Fatalf("edits = %d, want 3", len(exec.edits))
+	}
+	if !strings.Contains(exec.edits[1], "<admin-state>locked</admin-state>") {
+		t.Errorf("admin state edit = %s", exec.edits[1])
+	}
+	if !strings.Contains(exec.edits[2], `nc:operation="delete"`) {
+		t.Errorf("delete edit = %s", exec.edits[2])
+	}
+}
+
+func TestBBFClientMonitoring(t *testing.T) {
+	exec := &fakeExecutor{getReply: []byte(testONUStatesReply), getConfigReply: []byte(testONUConfigReply)}
+	client := NewBBFClient(exec)
+	ctx := context.Background()
+
+	onus, err := client.GetONUList(ctx, &types.ONUFilter{Status: "online"})
+	if err != nil || len(onus) != 1 || onus[0].Serial != "ADTN12345678" {
+		t.Errorf("GetONUList(online) = %+v, %v", onus, err)
+	}
+
+	power, err := client.GetONUPower(ctx, "ct-1/1/1", 1)
+	if err != nil {
+		t.Fatalf("GetONUPower() error: %v", err)
+	}
+	if power.RxPowerDBm != -18.5 || power.TxPowerDBm != 2.1 || power.DistanceM != 1200 || !power.IsWithinSpec {
+		t.Errorf("GetONUPower() = %+v", power)
+	}
+	if _, err := client.GetONUPower(ctx, "ct-1/1/1", 2); err == nil {
+		t.Error("GetONUPower(missing) error = nil")
+	}
+}
+
+func TestParseBBFChannelTerminations(t *testing.T) {
+	ports, err := ParseBBFChannelTerminations([]byte(testInterfaceStatesReply))
+	if err != nil {
+		t.Fatalf("ParseBBFChannelTerminations() error: %v", err)
+	}
+	if len(ports) != 2 {
+		t.Fatalf("ports = %+v, want the 2 channel terminations", ports)
+	}
+	if ports[0].Port != "ct-1/1/1" || ports[0].AdminState != "enabled" || ports[0].OperState != "up" {
+		t.Errorf("ports[0] = %+v", ports[0])
+	}
+	if ports[1].AdminState != "disabled" || ports[1].OperState != "down" {
+		t.Errorf("ports[1] = %+v", ports[1])
+	}
+}
@@ -274,18 +274,20 @@ const (
 
 // BBFONUState represents parsed ONU state from BBF model
 type BBFONUState struct {
-	Name             string
-	SerialNumber     string
-	ChannelPartition string
-	PresenceState    string
-	AdminState       string
-	OperState        string
-	RxPowerDbm       float64
-	TxPowerDbm       float64
-	LaserBiasCurrent float64
-	Temperature      float64
-	Voltage          float64
-	ONUDistance      int
+	Name               string
+	SerialNumber       string
+	ChannelPartition   string
+	ChannelTermination string
+	ONUID              int // -1 when the OLT has not assigned an ONU ID
+	PresenceState      string
+	AdminState         string
+	OperState          string
+	RxPowerDbm         float64
+	TxPowerDbm         float64
+	LaserBiasCurrent   float64
+	Temperature        float64
+	Voltage            float64
+	ONUDistance        int
 }
 
 // BBFONUCounters represents ONU traffic counters
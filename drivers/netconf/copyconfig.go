@@ -0,0 +1,161 @@
+package netconf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// CapURL is the RFC 6241 :url capability. Servers append
+// ?scheme=<schemes> listing the URL schemes they accept.
+const CapURL = "urn:ietf:params:netconf:capability:url:1.0"
+
+// isURL reports whether a copy-config endpoint is a URL rather than a
+// datastore name
+func isURL(s string) bool {
+	return strings.Contains(s, "://")
+}
+
+// URLSchemes returns the URL schemes advertised with the :url capability
+func URLSchemes(caps []string) []string {
+	for _, c := range caps {
+		base, query, _ := strings.Cut(c, "?")
+		if base != CapURL {
+			continue
+		}
+		params, err := url.ParseQuery(query)
+		if err != nil {
+			return nil
+		}
+		var schemes []string
+		for _, s := range strings.Split(params.Get("scheme"), ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				schemes = append(schemes, s)
+			}
+		}
+		return schemes
+	}
+	return nil
+}
+
+// copyEndpoint renders a copy-config <source> or <target> child: a
+// datastore element or a <url>. URLs are checked against the :url
+// capability.
+func (d *Driver) copyEndpoint(endpoint string) (string, error) {
+	if !isURL(endpoint) {
+		return fmt.Sprintf("<%s/>", endpoint), nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid copy-config URL: %w", err)
+	}
+	schemes := URLSchemes(d.capabilities)
+	if schemes == nil {
+		return "", fmt.Errorf("NETCONF server does not support url capability")
+	}
+	supported := false
+	for _, s := range schemes {
+		if strings.EqualFold(s, u.Scheme) {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return "", fmt.Errorf("NETCONF server does not support %q URLs (supported: %s)", u.Scheme, strings.Join(schemes, ", "))
+	}
+	return "<url>" + xmlEscape(endpoint) + "</url>", nil
+}
+
+// CopyConfig performs NETCONF copy-config. source and target are datastore
+// names ("running", "candidate", "startup") or URLs supported by the
+// server's :url capability.
+func (d *Driver) CopyConfig(ctx context.Context, source, target string) error {
+	if source == "" || target == "" {
+		return fmt.Errorf("copy-config source and target are required")
+	}
+	src, err := d.copyEndpoint(source)
+	if err != nil {
+		return err
+	}
+	dst, err := d.copyEndpoint(target)
+	if err != nil {
+		return err
+	}
+
+	operation := fmt.Sprintf(`<copy-config>
+  <target>
+    %s
+  </target>
+  <source>
+    %s
+  </source>
+</copy-config>`, dst, src)
+
+	_, err = d.RPC(ctx, operation)
+	return err
+}
+
+// BackupConfig saves the running configuration of exec for vendor. A URL
+// destination is written by the device via copy-config; an empty
+// destination returns the configuration inline via get-config.
+func BackupConfig(ctx context.Context, exec NETCONFExecutor, vendor, destination string) (*types.ConfigBackup, error) {
+	backup := &types.ConfigBackup{
+		Vendor:      vendor,
+		Source:      "running",
+		Destination: destination,
+		Format:      "xml",
+	}
+
+	if destination != "" {
+		if err := exec.CopyConfig(ctx, "running", destination); err != nil {
+			return nil, fmt.Errorf("%s config backup failed: %w", vendor, err)
+		}
+		backup.TakenAt = time.Now()
+		return backup, nil
+	}
+
+	content, err := exec.GetConfig(ctx, "running", "", WithDefaults(WithDefaultsReportAll))
+	if err != nil {
+		return nil, fmt.Errorf("%s config backup failed: %w", vendor, err)
+	}
+	sum := sha256.Sum256(content)
+	backup.Content = content
+	backup.Checksum = hex.EncodeToString(sum[:])
+	backup.TakenAt = time.Now()
+	return backup, nil
+}
+
+// RestoreConfig replaces the configuration of exec with the one at the
+// source URL. With a candidate datastore the restore is staged under a
+// lock and committed; otherwise running is overwritten directly.
+func RestoreConfig(ctx context.Context, exec NETCONFExecutor, vendor, source string) error {
+	if !isURL(source) {
+		return fmt.Errorf("%s config restore requires a source URL, got %q", vendor, source)
+	}
+
+	if !exec.HasCapability(CapCandidate) {
+		if err := exec.CopyConfig(ctx, source, "running"); err != nil {
+			return fmt.Errorf("%s config restore failed: %w", vendor, err)
+		}
+		return nil
+	}
+
+	err := WithLease(ctx, exec, "candidate", 0, func(ctx context.Context) error {
+		if err := exec.CopyConfig(ctx, source, "candidate"); err != nil {
+			_, _ = exec.RPC(ctx, "<discard-changes/>") //nolint:errcheck // best effort
+			return err
+		}
+		return exec.Commit(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("%s config restore failed: %w", vendor, err)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestURLSchemes(t *testing.T) {
+	caps := []string{NetconfBase10, CapURL + "?scheme=file,scp, sftp"}
+	got := URLSchemes(caps)
+	if strings.Join(got, ",") != "file,scp,sftp" {
+		t.Errorf("URLSchemes() = %v", got)
+	}
+	if URLSchemes([]string{NetconfBase10}) != nil {
+		t.Error("URLSchemes() should be nil without the url capability")
+	}
+}
+
+func newCopyDriver(sent *bytes.Buffer, caps ...string) *Driver {
+	return &Driver{
+		config:       &types.EquipmentConfig{Address: "10.0.0.1"},
+		connected:    true,
+		capabilities: caps,
+		stdin:        &netconfWriter{writer: sent},
+		stdout:       &netconfReader{reader: newMockReader("<rpc-reply><ok/></rpc-reply>]]>]]>")},
+	}
+}
+
+func TestCopyConfig(t *testing.T) {
+	t.Run("running to URL", func(t *testing.T) {
+		var sent bytes.Buffer
+		d := newCopyDriver(&sent, CapURL+"?scheme=file,scp")
+		if err := d.CopyConfig(context.Background(), "running", "scp://backup/olt1.xml?a=1&b=2"); err != nil {
+			t.Fatalf("CopyConfig() error: %v", err)
+		}
+		got := sent.String()
+		if !strings.Contains(got, "<target>\n    <url>scp://backup/olt1.xml?a=1&amp;b=2</url>") {
+			t.Errorf("sent RPC = %s, want escaped target URL", got)
+		}
+		if !strings.Contains(got, "<source>\n    <running/>") {
+			t.Errorf("sent RPC = %s, want running source", got)
+		}
+	})
+
+	t.Run("URL to candidate", func(t *testing.T) {
+		var sent bytes.Buffer
+		d := newCopyDriver(&sent, CapURL+"?scheme=file")
+		if err := d.CopyConfig(context.Background(), "file:///backup.xml", "candidate"); err != nil {
+			t.Fatalf("CopyConfig() error: %v", err)
+		}
+		got := sent.String()
+		if !strings.Contains(got, "<target>\n    <candidate/>") || !strings.Contains(got, "<url>file:///backup.xml</url>") {
+			t.Errorf("sent RPC = %s", got)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		caps    []string
+		source  string
+		target  string
+		wantErr string
+	}{
+		{"missing target", nil, "running", "", "source and target are required"},
+		{"no url capability", []string{NetconfBase10}, "running", "file:///x.xml", "does not support url capability"},
+		{"unsupported scheme", []string{CapURL + "?scheme=file"}, "running", "ftp://host/x.xml", `does not support "ftp" URLs`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent bytes.Buffer
+			d := newCopyDriver(&sent, tt.caps...)
+			err := d.CopyConfig(context.Background(), tt.source, tt.target)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("CopyConfig() error = %v, want containing %q", err, tt.wantErr)
+			}
+			if sent.Len() != 0 {
+				t.Errorf("no RPC expected, sent %s", sent.String())
+			}
+		})
+	}
+}
+
+// copyExecutor records the operation order of backup and restore
+type copyExecutor struct {
+	lockExecutor
+	caps    []string
+	copyErr error
+
+	opsMu sync.Mutex
+	ops   []string
+}
+
+func (e *copyExecutor) record(op string) {
+	e.opsMu.Lock()
+	defer e.opsMu.Unlock()
+	e.ops = append(e.ops, op)
+}
+
+func (e *copyExecutor) Lock(ctx context.Context, target string) error {
+	e.record("lock:" + target)
+	return e.lockExecutor.Lock(ctx, target)
+}
+
+func (e *copyExecutor) Unlock(ctx context.Context, target string) error {
+	e.record("unlock:" + target)
+	return e.lockExecutor.Unlock(ctx, target)
+}
+
+func (e *copyExecutor) CopyConfig(_ context.Context, source, target string) error {
+	e.record("copy:" + source + "->" + target)
+	return e.copyErr
+}
+
+func (e *copyExecutor) Commit(context.Context) error {
+	e.record("commit")
+	return nil
+}
+
+func (e *copyExecutor) RPC(_ context.Context, op string) ([]byte, error) {
+	e.record("rpc:" + op)
+	return nil, nil
+}
+
+func (e *copyExecutor) HasCapability(c string) bool {
+	for _, have := range e.caps {
+		if strings.Contains(have, c) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *copyExecutor) operations() string {
+	e.opsMu.Lock()
+	defer e.opsMu.Unlock()
+	return strings.Join(e.ops, " ")
+}
+
+func TestBackupConfig(t *testing.T) {
+	t.Run("to URL", func(t *testing.T) {
+		exec := &copyExecutor{}
+		backup, err := BackupConfig(context.Background(), exec, "cisco", "scp://host/olt.xml")
+		if err != nil {
+			t.Fatalf("BackupConfig() error: %v", err)
+		}
+		if exec.operations() != "copy:running->scp://host/olt.xml" {
+			t.Errorf("operations = %q", exec.operations())
+		}
+		if backup.Destination != "scp://host/olt.xml" || backup.Content != nil || backup.TakenAt.IsZero() {
+			t.Errorf("backup = %+v", backup)
+		}
+	})
+
+	t.Run("inline", func(t *testing.T) {
+		exec := &copyExecutor{}
+		exec.getConfigReply = []byte("<data><interfaces/></data>")
+		backup, err := BackupConfig(context.Background(), exec, "nokia", "")
+		if err != nil {
+			t.Fatalf("BackupConfig() error: %v", err)
+		}
+		sum := sha256.Sum256(exec.getConfigReply)
+		if backup.Checksum != hex.EncodeToString(sum[:]) {
+			t.Errorf("Checksum = %s", backup.Checksum)
+		}
+		if string(backup.Content) != "<data><interfaces/></data>" || backup.Vendor != "nokia" || backup.Format != "xml" {
+			t.Errorf("backup = %+v", backup)
+		}
+	})
+
+	t.Run("copy fails", func(t *testing.T) {
+		exec := &copyExecutor{copyErr: errors.New("permission denied")}
+		if _, err := BackupConfig(context.Background(), exec, "cisco", "file:///x.xml"); err == nil || !strings.Contains(err.Error(), "cisco config backup failed") {
+			t.Errorf("BackupConfig() error = %v", err)
+		}
+	})
+}
+
+func TestRestoreConfig(t *testing.T) {
+	t.Run("through candidate", func(t *testing.T) {
+		exec := &copyExecutor{caps: []string{CapCandidate}}
+		if err := RestoreConfig(context.Background(), exec, "cisco", "file:///backup.xml"); err != nil {
+			t.Fatalf("RestoreConfig() error: %v", err)
+		}
+		want := "lock:candidate copy:file:///backup.xml->candidate commit unlock:candidate"
+		if exec.operations() != want {
+			t.Errorf("operations = %q, want %q", exec.operations(), want)
+		}
+	})
+
+	t.Run("running without candidate", func(t *testing.T) {
+		exec := &copyExecutor{}
+		if err := RestoreConfig(context.Background(), exec, "adtran", "file:///backup.xml"); err != nil {
+			t.Fatalf("RestoreConfig() error: %v", err)
+		}
+		if exec.operations() != "copy:file:///backup.xml->running" {
+			t.Errorf("operations = %q", exec.operations())
+		}
+	})
+
+	t.Run("copy failure discards candidate", func(t *testing.T) {
+		exec := &copyExecutor{caps: []string{CapCandidate}, copyErr: errors.New("file not found")}
+		err := RestoreConfig(context.Background(), exec, "cisco", "file:///missing.xml")
+		if err == nil || !strings.Contains(err.Error(), "file not found") {
+			t.Fatalf("RestoreConfig() error = %v", err)
+		}
+		want := "lock:candidate copy:file:///missing.xml->candidate rpc:<discard-changes/> unlock:candidate"
+		if exec.operations() != want {
+			t.Errorf("operations = %q, want %q", exec.operations(), want)
+		}
+	})
+
+	t.Run("lock denied", func(t *testing.T) {
+		exec := &copyExecutor{caps: []string{CapCandidate}}
+		exec.lockErr = &LockDeniedError{Target: "candidate", SessionID: "9"}
+		err := RestoreConfig(context.Background(), exec, "cisco", "file:///backup.xml")
+		var lde *LockDeniedError
+		if !errors.As(err, &lde) {
+			t.Fatalf("RestoreConfig() error = %v, want *LockDeniedError", err)
+		}
+		if strings.Contains(exec.operations(), "copy:") {
+			t.Errorf("no copy expected without the lock: %q", exec.operations())
+		}
+	})
+
+	t.Run("datastore source rejected", func(t *testing.T) {
+		exec := &copyExecutor{}
+		if err := RestoreConfig(context.Background(), exec, "cisco", "startup"); err == nil {
+			t.Fatal("RestoreConfig() should require a URL")
+		}
+	})
+}
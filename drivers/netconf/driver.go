@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/xml"
 	"fmt"
-	"log/slog"
 	"strconv"
 	"strings"
 	"sync"
@@ -53,6 +52,15 @@ type Driver struct {
 	capabilities []string
 	sessionID    string
 	mu           sync.Mutex
+
+	// notifying is set once the session is dedicated to notifications
+	notifying bool
+
+	// Datastore lock leases held through AcquireLease
+	leases  map[*Lease]struct{}
+	leaseMu sync.Mutex
+
+	metrics types.DriverCounters
 }
 
 // netconfWriter wraps SSH stdin for NETCONF framing
@@ -63,7 +71,10 @@ type netconfWriter struct {
 
 func (w *netconfWriter) Write(data []byte) (int, error) {
 	if w.useChunk {
-		// NETCONF 1.1 chunked framing
+		// NETCONF 1.1 chunked framing; a chunk cannot be empty
+		if len(data) == 0 {
+			return 0, nil
+		}
 		chunk := fmt.Sprintf("\n#%d\n%s\n##\n", len(data), string(data))
 		return w.writer.Write([]byte(chunk))
 	}
@@ -75,9 +86,17 @@ func (w *netconfWriter) Write(data []byte) (int, error) {
 type netconfReader struct {
 	reader   interface{ Read([]byte) (int, error) }
 	useChunk bool
+
+	// pending holds bytes read past the end of the previous message
+	pending []byte
 }
 
 func (r *netconfReader) Read(p []byte) (int, error) {
+	if len(r.pending) > 0 {
+		n := copy(p, r.pending)
+		r.pending = r.pending[n:]
+		return n, nil
+	}
 	return r.reader.Read(p)
 }
 
@@ -89,14 +108,42 @@ func (r *netconfReader) ReadMessage() ([]byte, error) {
 }
 
 // ReadMessageContext reads a complete NETCONF message with context support.
+// Bytes following the message delimiter are kept for the next call.
 func (r *netconfReader) ReadMessageContext(ctx context.Context) ([]byte, error) {
 	buf := make([]byte, 64*1024)
-	var message []byte
+	message := r.pending
+	r.pending = nil
 
 	for {
+		// Check for end-of-message marker (NETCONF 1.0)
+		if !r.useChunk {
+			if idx := strings.Index(string(message), NetconfFrameEnd); idx != -1 {
+				r.keep(message[idx+len(NetconfFrameEnd):])
+				return []byte(strings.TrimSpace(string(message[:idx]))), nil
+			}
+		}
+
+		// NETCONF 1.1 chunked framing
+		if r.useChunk {
+			end, err := chunkedFrameEnd(message)
+			if err != nil {
+				return nil, err
+			}
+			if end != -1 {
+				r.keep(message[end:])
+				return parseChunkedMessage(message[:end])
+			}
+		}
+
+		// Enforce size limit to prevent OOM
+		if len(message) > maxMessageSize {
+			return nil, fmt.Errorf("NETCONF message exceeds maximum size (%d bytes)", maxMessageSize)
+		}
+
 		// Check context before each read
 		select {
 		case <-ctx.Done():
+			r.keep(message)
 			return nil, ctx.Err()
 		default:
 		}
@@ -105,25 +152,60 @@ func (r *netconfReader) ReadMessageContext(ctx context.Context) ([]byte, error)
 		if err != nil {
 			return nil, err
 		}
-
 		message = append(message, buf[:n]...)
+	}
+}
 
-		// Enforce size limit to prevent OOM
-		if len(message) > maxMessageSize {
-			return nil, fmt.Errorf("NETCONF message exceeds maximum size (%d bytes)", maxMessageSize)
+// keep stores leftover bytes for the next message
+func (r *netconfReader) keep(rest []byte) {
+	if len(rest) > 0 {
+		r.pending = append([]byte(nil), rest...)
+	}
+}
+
+// chunkedFrameEnd walks the RFC 6242 chunk headers in data and returns the
+// offset just past the end-of-chunks marker, or -1 if the message is not yet
+// complete. Chunk data is skipped by size, so "\n##\n" inside a chunk is
+// not mistaken for the end of the message.
+func chunkedFrameEnd(data []byte) (int, error) {
+	pos := 0
+	for {
+		// Need at least "\n#" plus one byte to classify the header
+		if len(data)-pos < 4 {
+			return -1, nil
+		}
+		if data[pos] != '\n' || data[pos+1] != '#' {
+			return 0, fmt.Errorf("malformed NETCONF chunk: expected chunk header at offset %d", pos)
+		}
+		if data[pos+2] == '#' {
+			if data[pos+3] != '\n' {
+				return 0, fmt.Errorf("malformed NETCONF chunk: invalid end-of-chunks marker")
+			}
+			return pos + 4, nil
 		}
 
-		// Check for end-of-message marker (NETCONF 1.0)
-		if !r.useChunk && strings.Contains(string(message), NetconfFrameEnd) {
-			// Remove the EOM marker
-			msg := strings.TrimSuffix(string(message), NetconfFrameEnd)
-			return []byte(strings.TrimSpace(msg)), nil
+		// \n#<size>\n; RFC 6242 limits chunk-size to 10 digits
+		sizeEnd := strings.IndexByte(string(data[pos+2:]), '\n')
+		if sizeEnd == -1 {
+			if len(data)-pos-2 > 10 {
+				return 0, fmt.Errorf("malformed NETCONF chunk: missing size terminator")
+			}
+			return -1, nil
+		}
+		sizeStr := string(data[pos+2 : pos+2+sizeEnd])
+		chunkSize, err := strconv.Atoi(sizeStr)
+		if err != nil || chunkSize <= 0 {
+			return 0, fmt.Errorf("malformed NETCONF chunk: invalid size %q", sizeStr)
+		}
+		if chunkSize > maxMessageSize {
+			return 0, fmt.Errorf("NETCONF message exceeds maximum size (%d bytes)", maxMessageSize)
 		}
 
-		// NETCONF 1.1 chunked framing
-		if r.useChunk && strings.Contains(string(message), "\n##\n") {
-			return parseChunkedMessage(message)
+		next := pos + 2 + sizeEnd + 1 + chunkSize
+		if next > len(data) {
+			return -1, nil
 		}
+		pos = next
 	}
 }
 
@@ -211,6 +293,11 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 	if config != nil {
 		d.config = config
 	}
+	resolved, err := types.ResolveCredentials(ctx, d.config)
+	if err != nil {
+		return err
+	}
+	d.config = resolved
 
 	// Build SSH config
 	sshConfig := &ssh.ClientConfig{
@@ -226,7 +313,7 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 	// but log a warning — proper known_hosts support is a future improvement.
 	sshConfig.HostKeyCallback = ssh.InsecureIgnoreHostKey() //nolint:gosec // known_hosts support is planned
 	if !d.config.TLSSkipVerify {
-		slog.Warn("NETCONF SSH host key verification disabled: TLSSkipVerify not explicitly set",
+		d.config.Log().Warn("NETCONF SSH host key verification disabled: TLSSkipVerify not explicitly set",
 			"address", d.config.Address, "port", d.config.Port)
 	}
 
@@ -234,7 +321,7 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 	addr := fmt.Sprintf("%s:%d", d.config.Address, d.config.Port)
 	client, err := ssh.Dial("tcp", addr, sshConfig)
 	if err != nil {
-		return fmt.Errorf("SSH dial failed: %w", err)
+		return types.ConnectError(string(d.config.Vendor), fmt.Errorf("SSH dial failed: %w", err))
 	}
 	d.sshClient = client
 
@@ -279,10 +366,47 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 	}
 
 	d.connected = true
+	d.notifying = false
+	d.metrics.RecordConnect()
 	return nil
 }
 
-// exchangeHello performs NETCONF hello exchange
+// clientHello is sent to the server. It advertises :base:1.1 unless the
+// session is pinned to NETCONF 1.0.
+func clientHello(base11 bool) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<hello xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">
+  <capabilities>
+    <capability>urn:ietf:params:netconf:base:1.0</capability>`)
+	if base11 {
+		b.WriteString(`
+    <capability>urn:ietf:params:netconf:base:1.1</capability>`)
+	}
+	b.WriteString(`
+    <capability>urn:ietf:params:netconf:capability:writable-running:1.0</capability>
+    <capability>urn:ietf:params:netconf:capability:candidate:1.0</capability>
+    <capability>urn:ietf:params:netconf:capability:confirmed-commit:1.0</capability>
+    <capability>urn:ietf:params:netconf:capability:rollback-on-error:1.0</capability>
+    <capability>urn:ietf:params:netconf:capability:validate:1.0</capability>
+  </capabilities>
+</hello>`)
+	return b.String()
+}
+
+// base11Enabled reports whether :base:1.1 may be negotiated. Setting
+// Metadata["netconf_base"] to "1.0" pins the session to EOM framing for
+// devices with broken chunked framing.
+func (d *Driver) base11Enabled() bool {
+	if d.config == nil || d.config.Metadata == nil {
+		return true
+	}
+	return d.config.Metadata["netconf_base"] != "1.0"
+}
+
+// exchangeHello performs NETCONF hello exchange. Hellos are always sent
+// with EOM framing (RFC 6242 §4.1); chunked framing is used afterwards
+// only if both peers advertise :base:1.1.
 func (d *Driver) exchangeHello() error {
 	// Read server hello
 	serverHello, err := d.stdout.ReadMessage()
@@ -293,36 +417,33 @@ func (d *Driver) exchangeHello() error {
 	// Parse server capabilities
 	d.capabilities, d.sessionID = parseHello(serverHello)
 
-	// Check for NETCONF 1.1 support and switch to chunked framing
-	for _, cap := range d.capabilities {
-		if strings.Contains(cap, "base:1.1") {
-			d.stdin.useChunk = true
-			d.stdout.useChunk = true
-			break
-		}
+	base11 := d.base11Enabled()
+	if !base11 && !d.HasCapability(NetconfBase10) {
+		return fmt.Errorf("NETCONF server does not support %s", NetconfBase10)
 	}
 
 	// Send client hello
-	clientHello := `<?xml version="1.0" encoding="UTF-8"?>
-<hello xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">
-  <capabilities>
-    <capability>urn:ietf:params:netconf:base:1.0</capability>
-    <capability>urn:ietf:params:netconf:base:1.1</capability>
-    <capability>urn:ietf:params:netconf:capability:writable-running:1.0</capability>
-    <capability>urn:ietf:params:netconf:capability:candidate:1.0</capability>
-    <capability>urn:ietf:params:netconf:capability:confirmed-commit:1.0</capability>
-    <capability>urn:ietf:params:netconf:capability:rollback-on-error:1.0</capability>
-    <capability>urn:ietf:params:netconf:capability:validate:1.0</capability>
-  </capabilities>
-</hello>`
-
-	if _, err := d.stdin.Write([]byte(clientHello)); err != nil {
+	if _, err := d.stdin.Write([]byte(clientHello(base11))); err != nil {
 		return fmt.Errorf("failed to send client hello: %w", err)
 	}
 
+	// Switch to chunked framing if both sides support NETCONF 1.1
+	if base11 && d.HasCapability(NetconfBase11) {
+		d.stdin.useChunk = true
+		d.stdout.useChunk = true
+	}
+
 	return nil
 }
 
+// ChunkedFraming reports whether the session negotiated NETCONF 1.1
+// chunked framing
+func (d *Driver) ChunkedFraming() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stdout != nil && d.stdout.useChunk
+}
+
 // parseHello extracts capabilities and session ID from hello message
 func parseHello(data []byte) ([]string, string) {
 	var capabilities []string
@@ -346,8 +467,10 @@ func parseHello(data []byte) ([]string, string) {
 	return capabilities, sessionID
 }
 
-// Disconnect closes the NETCONF session
+// Disconnect closes the NETCONF session, unlocking any held leases first
 func (d *Driver) Disconnect(ctx context.Context) error {
+	d.releaseLeases(ctx)
+
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -372,6 +495,11 @@ func (d *Driver) Disconnect(ctx context.Context) error {
 	return nil
 }
 
+// DriverMetrics implements types.MetricsProvider
+func (d *Driver) DriverMetrics() types.DriverMetrics {
+	return d.metrics.Snapshot()
+}
+
 // IsConnected returns true if connected
 func (d *Driver) IsConnected() bool {
 	d.mu.Lock()
@@ -400,12 +528,66 @@ func (d *Driver) HasCapability(cap string) bool {
 
 // RPC sends a NETCONF RPC and returns the response
 func (d *Driver) RPC(ctx context.Context, operation string) ([]byte, error) {
+	readOnly := readOnlyRPCs[rpcName(operation)]
+	if !readOnly {
+		if err := types.CheckCommands(d.config, "netconf", []string{operation}); err != nil {
+			return nil, err
+		}
+	}
+	var reply []byte
+	err := d.config.RetryPolicy().Do(ctx, func(attempt int) error {
+		if attempt > 1 {
+			d.metrics.RecordRetry()
+		}
+		var err error
+		reply, err = d.rpc(ctx, operation)
+		return err
+	})
+	if !readOnly {
+		types.AuditCommands(ctx, d.config, "netconf", []string{operation}, err)
+	}
+	return reply, err
+}
+
+// readOnlyRPCs are operations that do not change the configuration
+var readOnlyRPCs = map[string]bool{
+	"get":                 true,
+	"get-config":          true,
+	"get-data":            true,
+	"get-schema":          true,
+	"lock":                true,
+	"unlock":              true,
+	"validate":            true,
+	"close-session":       true,
+	"create-subscription": true,
+}
+
+// rpcName returns the element name of an RPC operation, e.g. "edit-config"
+func rpcName(operation string) string {
+	operation = strings.TrimSpace(operation)
+	if !strings.HasPrefix(operation, "<") {
+		return ""
+	}
+	name := operation[1:]
+	if i := strings.IndexAny(name, " />\t\n"); i >= 0 {
+		name = name[:i]
+	}
+	if _, local, ok := strings.Cut(name, ":"); ok {
+		name = local
+	}
+	return name
+}
+
+func (d *Driver) rpc(ctx context.Context, operation string) ([]byte, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
 	if !d.connected {
 		return nil, fmt.Errorf("not connected to device")
 	}
+	if d.notifying {
+		return nil, fmt.Errorf("session is dedicated to notifications")
+	}
 
 	msgID := nextMessageID()
 	rpc := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
@@ -413,76 +595,86 @@ func (d *Driver) RPC(ctx context.Context, operation string) ([]byte, error) {
 %s
 </rpc>`, msgID, operation)
 
+	log := d.config.Log()
+	log.Debug("NETCONF RPC", "message_id", msgID, "rpc", operation)
+	// A transport failure leaves the session unusable, so it is not retried
 	if _, err := d.stdin.Write([]byte(rpc)); err != nil {
-		return nil, fmt.Errorf("failed to send RPC: %w", err)
+		d.metrics.RecordCommand(err)
+		return nil, types.Permanent(fmt.Errorf("failed to send RPC: %w", err))
 	}
 
 	reply, err := d.stdout.ReadMessage()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read RPC reply: %w", err)
+		d.metrics.RecordCommand(err)
+		return nil, types.Permanent(fmt.Errorf("failed to read RPC reply: %w", err))
 	}
+	log.Debug("NETCONF RPC reply", "message_id", msgID, "reply", string(reply))
 
 	// Check for RPC error
 	if strings.Contains(string(reply), "<rpc-error>") {
-		return reply, fmt.Errorf("RPC error: %s", extractRPCError(reply))
+		err := fmt.Errorf("RPC error: %s", extractRPCError(reply))
+		d.metrics.RecordCommand(err)
+		if isTransientRPCError(reply) {
+			err = types.Transient(err)
+		}
+		return reply, err
 	}
 
+	d.metrics.RecordCommand(nil)
 	return reply, nil
 }
 
 // extractRPCError extracts error message from RPC error response
 func extractRPCError(data []byte) string {
-	type RPCError struct {
-		XMLName      xml.Name `xml:"rpc-error"`
-		ErrorType    string   `xml:"error-type"`
-		ErrorTag     string   `xml:"error-tag"`
-		ErrorMessage string   `xml:"error-message"`
-	}
-	type RPCReply struct {
-		XMLName xml.Name   `xml:"rpc-reply"`
-		Errors  []RPCError `xml:"rpc-error"`
-	}
-
-	var reply RPCReply
-	if err := xml.Unmarshal(data, &reply); err == nil && len(reply.Errors) > 0 {
-		e := reply.Errors[0]
-		return fmt.Sprintf("%s: %s - %s", e.ErrorType, e.ErrorTag, e.ErrorMessage)
+	if errs := ParseRPCErrors(data); len(errs) > 0 {
+		e := errs[0]
+		return fmt.Sprintf("%s: %s - %s", e.Type, e.Tag, e.Message)
 	}
 	return string(data)
 }
 
 // Get performs NETCONF get operation
-func (d *Driver) Get(ctx context.Context, filter string) ([]byte, error) {
-	var operation string
-	if filter != "" {
-		operation = fmt.Sprintf(`<get>
-  <filter type="subtree">
-    %s
-  </filter>
-</get>`, filter)
-	} else {
-		operation = "<get/>"
+func (d *Driver) Get(ctx context.Context, filter string, options ...GetOption) ([]byte, error) {
+	opts := &getOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+
+	filterParam, err := d.filterParam(filter, opts)
+	if err != nil {
+		return nil, err
 	}
+	operation := "<get>" + filterParam + d.withDefaultsParam(opts)
+	if operation == "<get>" {
+		return d.RPC(ctx, "<get/>")
+	}
+	operation += "\n</get>"
+
 	return d.RPC(ctx, operation)
 }
 
 // GetConfig performs NETCONF get-config operation
-func (d *Driver) GetConfig(ctx context.Context, source, filter string) ([]byte, error) {
+func (d *Driver) GetConfig(ctx context.Context, source, filter string, options ...GetOption) ([]byte, error) {
 	if source == "" {
 		source = "running"
 	}
 
+	opts := &getOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+
+	filterParam, err := d.filterParam(filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	operation := fmt.Sprintf(`<get-config>
   <source>
     <%s/>
   </source>`, source)
-
-	if filter != "" {
-		operation += fmt.Sprintf(`
-  <filter type="subtree">
-    %s
-  </filter>`, filter)
-	}
+	operation += filterParam
+	operation += d.withDefaultsParam(opts)
 	operation += "\n</get-config>"
 
 	return d.RPC(ctx, operation)
@@ -579,7 +771,8 @@ func (d *Driver) DiscardChanges(ctx context.Context) error {
 	return err
 }
 
-// Lock locks a configuration datastore
+// Lock locks a configuration datastore.
+// Returns *LockDeniedError if another session holds the lock.
 func (d *Driver) Lock(ctx context.Context, target string) error {
 	if target == "" {
 		target = "running"
@@ -589,8 +782,11 @@ func (d *Driver) Lock(ctx context.Context, target string) error {
     <%s/>
   </target>
 </lock>`, target)
-	_, err := d.RPC(ctx, operation)
-	return err
+	reply, err := d.RPC(ctx, operation)
+	if err != nil {
+		return lockError(target, reply, err)
+	}
+	return nil
 }
 
 // Unlock unlocks a configuration datastore
@@ -607,21 +803,40 @@ func (d *Driver) Unlock(ctx context.Context, target string) error {
 	return err
 }
 
-// Validate validates configuration
+// Validate validates a configuration datastore (normally candidate).
+// Device-side validation failures are returned as *ValidationError.
 func (d *Driver) Validate(ctx context.Context, source string) error {
-	if !d.HasCapability(CapValidate) {
-		return fmt.Errorf("NETCONF server does not support validate capability")
-	}
 	if source == "" {
 		source = "candidate"
 	}
+	return d.validate(ctx, fmt.Sprintf("<%s/>", source))
+}
+
+// ValidateConfig validates a configuration fragment without applying it,
+// so generated XML can be checked before edit-config and commit
+func (d *Driver) ValidateConfig(ctx context.Context, config string) error {
+	return d.validate(ctx, fmt.Sprintf(`<config>
+      %s
+    </config>`, config))
+}
+
+func (d *Driver) validate(ctx context.Context, source string) error {
+	if !d.HasCapability(CapValidate) {
+		return fmt.Errorf("NETCONF server does not support validate capability")
+	}
 	operation := fmt.Sprintf(`<validate>
   <source>
-    <%s/>
+    %s
   </source>
 </validate>`, source)
-	_, err := d.RPC(ctx, operation)
-	return err
+	reply, err := d.RPC(ctx, operation)
+	if err != nil {
+		if errs := ParseRPCErrors(reply); len(errs) > 0 {
+			return &ValidationError{Errors: errs, Raw: string(reply)}
+		}
+		return err
+	}
+	return nil
 }
 
 // CreateSubscriber provisions a subscriber using NETCONF edit-config
@@ -717,14 +932,17 @@ type NETCONFExecutor interface {
 	RPC(ctx context.Context, operation string) ([]byte, error)
 
 	// Get performs NETCONF get
-	Get(ctx context.Context, filter string) ([]byte, error)
+	Get(ctx context.Context, filter string, options ...GetOption) ([]byte, error)
 
 	// GetConfig performs NETCONF get-config
-	GetConfig(ctx context.Context, source, filter string) ([]byte, error)
+	GetConfig(ctx context.Context, source, filter string, options ...GetOption) ([]byte, error)
 
 	// EditConfig performs NETCONF edit-config
 	EditConfig(ctx context.Context, target, config string, options ...EditOption) error
 
+	// CopyConfig performs NETCONF copy-config between datastores or URLs
+	CopyConfig(ctx context.Context, source, target string) error
+
 	// Commit commits candidate configuration
 	Commit(ctx context.Context) error
 
@@ -734,6 +952,12 @@ type NETCONFExecutor interface {
 	// Unlock unlocks a configuration datastore
 	Unlock(ctx context.Context, target string) error
 
+	// Validate validates a configuration datastore
+	Validate(ctx context.Context, source string) error
+
+	// ValidateConfig validates a configuration fragment without applying it
+	ValidateConfig(ctx context.Context, config string) error
+
 	// HasCapability checks for a specific capability
 	HasCapability(cap string) bool
 
@@ -3,6 +3,7 @@ package netconf
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"strings"
 	"testing"
@@ -1357,3 +1358,343 @@ func TestValidateWithEmptySource(t *testing.T) {
 		t.Errorf("error %q does not contain 'not connected'", err.Error())
 	}
 }
+
+// ---------------------------------------------------------------------------
+// AG. ValidateConfig surfaces device rejections as *ValidationError
+// ---------------------------------------------------------------------------
+
+func TestValidateConfigRejected(t *testing.T) {
+	reply := `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" message-id="1">
+  <rpc-error>
+    <error-type>application</error-type>
+    <error-tag>invalid-value</error-tag>
+    <error-severity>error</error-severity>
+    <error-path>/interfaces/interface[name='ge-0/0/1.100']/vlan-id</error-path>
+    <error-message>VLAN 5000 out of range</error-message>
+  </rpc-error>
+</rpc-reply>]]>]]>`
+
+	var sent bytes.Buffer
+	d := &Driver{
+		config:       &types.EquipmentConfig{Address: "10.0.0.1", Vendor: types.VendorCisco},
+		connected:    true,
+		capabilities: []string{CapValidate},
+		stdin:        &netconfWriter{writer: &sent},
+		stdout:       &netconfReader{reader: newMockReader(reply)},
+	}
+
+	err := d.ValidateConfig(context.Background(), "<interfaces/>")
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("ValidateConfig() error = %v, want *ValidationError", err)
+	}
+	if len(verr.Errors) != 1 || verr.Errors[0].Tag != "invalid-value" {
+		t.Errorf("Errors = %+v", verr.Errors)
+	}
+	if !strings.Contains(sent.String(), "<config>") || !strings.Contains(sent.String(), "<interfaces/>") {
+		t.Errorf("sent RPC = %s, want inline config source", sent.String())
+	}
+
+	he := verr.ToHumanError("cisco")
+	if he.Code != types.ErrCodeValidationFailed || he.Recoverable {
+		t.Errorf("HumanError = %+v", he)
+	}
+	if !strings.Contains(he.Message, "VLAN 5000 out of range") || !strings.Contains(he.Message, "vlan-id") {
+		t.Errorf("HumanError message = %q", he.Message)
+	}
+}
+
+func TestValidateConfigAccepted(t *testing.T) {
+	d := &Driver{
+		config:       &types.EquipmentConfig{Address: "10.0.0.1"},
+		connected:    true,
+		capabilities: []string{CapValidate},
+		stdin:        &netconfWriter{writer: &bytes.Buffer{}},
+		stdout:       &netconfReader{reader: newMockReader(`<rpc-reply><ok/></rpc-reply>]]>]]>`)},
+	}
+	if err := d.ValidateConfig(context.Background(), "<interfaces/>"); err != nil {
+		t.Errorf("ValidateConfig() error: %v", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// AH. Hello exchange negotiates chunked framing
+// ---------------------------------------------------------------------------
+
+func serverHello(caps ...string) string {
+	var b strings.Builder
+	b.WriteString(`<hello xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><capabilities>`)
+	for _, c := range caps {
+		b.WriteString("<capability>" + c + "</capability>")
+	}
+	b.WriteString("</capabilities><session-id>7</session-id></hello>]]>]]>")
+	return b.String()
+}
+
+func TestExchangeHello(t *testing.T) {
+	tests := []struct {
+		name        string
+		serverCaps  []string
+		metadata    map[string]string
+		wantChunked bool
+		wantBase11  bool // client hello advertises :base:1.1
+	}{
+		{
+			name:        "both sides support 1.1",
+			serverCaps:  []string{NetconfBase10, NetconfBase11},
+			wantChunked: true,
+			wantBase11:  true,
+		},
+		{
+			name:        "server supports 1.0 only",
+			serverCaps:  []string{NetconfBase10},
+			wantChunked: false,
+			wantBase11:  true,
+		},
+		{
+			name:        "pinned to 1.0",
+			serverCaps:  []string{NetconfBase10, NetconfBase11},
+			metadata:    map[string]string{"netconf_base": "1.0"},
+			wantChunked: false,
+			wantBase11:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent bytes.Buffer
+			d := &Driver{
+				config: &types.EquipmentConfig{Address: "10.0.0.1", Metadata: tt.metadata},
+				stdin:  &netconfWriter{writer: &sent},
+				stdout: &netconfReader{reader: newMockReader(serverHello(tt.serverCaps...))},
+			}
+
+			if err := d.exchangeHello(); err != nil {
+				t.Fatalf("exchangeHello() error: %v", err)
+			}
+
+			// The client hello is always EOM-framed
+			hello := sent.String()
+			if !strings.HasPrefix(hello, "<?xml") || !strings.HasSuffix(hello, NetconfFrameEnd) {
+				t.Errorf("client hello not EOM-framed: %q", hello)
+			}
+			if got := strings.Contains(hello, NetconfBase11); got != tt.wantBase11 {
+				t.Errorf("client hello advertises 1.1 = %v, want %v", got, tt.wantBase11)
+			}
+			if d.ChunkedFraming() != tt.wantChunked {
+				t.Errorf("ChunkedFraming() = %v, want %v", d.ChunkedFraming(), tt.wantChunked)
+			}
+			if d.stdin.useChunk != tt.wantChunked {
+				t.Errorf("writer useChunk = %v, want %v", d.stdin.useChunk, tt.wantChunked)
+			}
+			if d.sessionID != "7" {
+				t.Errorf("sessionID = %q, want 7", d.sessionID)
+			}
+		})
+	}
+}
+
+func TestExchangeHelloPinnedWithoutBase10(t *testing.T) {
+	d := &Driver{
+		config: &types.EquipmentConfig{Metadata: map[string]string{"netconf_base": "1.0"}},
+		stdin:  &netconfWriter{writer: &bytes.Buffer{}},
+		stdout: &netconfReader{reader: newMockReader(serverHello(NetconfBase11))},
+	}
+	if err := d.exchangeHello(); err == nil {
+		t.Fatal("exchangeHello() should fail when pinned to 1.0 and the server lacks it")
+	}
+}
+
+func TestChunkedSessionAfterHello(t *testing.T) {
+	// Server hello and first chunked reply arrive in the same read
+	var sent bytes.Buffer
+	d := &Driver{
+		config: &types.EquipmentConfig{Address: "10.0.0.1"},
+		stdin:  &netconfWriter{writer: &sent},
+		stdout: &netconfReader{reader: newMockReader(
+			serverHello(NetconfBase10, NetconfBase11) + "\n#20\n<rpc-reply><ok/></rp\n#8\nc-reply>\n##\n",
+		)},
+	}
+	if err := d.exchangeHello(); err != nil {
+		t.Fatalf("exchangeHello() error: %v", err)
+	}
+	d.connected = true
+	sent.Reset()
+
+	reply, err := d.RPC(context.Background(), "<get/>")
+	if err != nil {
+		t.Fatalf("RPC() error: %v", err)
+	}
+	if strings.TrimSpace(string(reply)) != "<rpc-reply><ok/></rpc-reply>" {
+		t.Errorf("RPC() = %q", reply)
+	}
+	if !strings.HasPrefix(sent.String(), "\n#") || !strings.HasSuffix(sent.String(), "\n##\n") {
+		t.Errorf("RPC not chunk-framed: %q", sent.String())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// AI. Framing across read boundaries
+// ---------------------------------------------------------------------------
+
+func TestReadMessageContext_FramingBoundaries(t *testing.T) {
+	tests := []struct {
+		name     string
+		useChunk bool
+		reads    []string
+		want     []string
+	}{
+		{
+			name:  "two EOM messages in one read",
+			reads: []string{"<a/>]]>]]><b/>]]>]]>"},
+			want:  []string{"<a/>", "<b/>"},
+		},
+		{
+			name:  "EOM marker split across reads",
+			reads: []string{"<a/>]]>", "]]><b/>", "]]>]]>"},
+			want:  []string{"<a/>", "<b/>"},
+		},
+		{
+			name:     "two chunked messages in one read",
+			useChunk: true,
+			reads:    []string{"\n#4\n<a/>\n##\n\n#4\n<b/>\n##\n"},
+			want:     []string{"<a/>", "<b/>"},
+		},
+		{
+			name:     "end marker inside chunk data",
+			useChunk: true,
+			reads:    []string{"\n#11\n<a>\n##\n</a>\n##\n"},
+			want:     []string{"<a>\n##\n</a>"},
+		},
+		{
+			name:     "headers split across reads",
+			useChunk: true,
+			reads:    []string{"\n#", "1", "2\n<rpc-reply", "/>\n#", "#", "\n"},
+			want:     []string{"<rpc-reply/>"},
+		},
+		{
+			name:     "chunk size larger than one read",
+			useChunk: true,
+			reads:    []string{"\n#26\nabcdefghijklm", "nopqrstuvwxyz\n##\n"},
+			want:     []string{"abcdefghijklmnopqrstuvwxyz"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &netconfReader{reader: newMockReader(tt.reads...), useChunk: tt.useChunk}
+			for i, want := range tt.want {
+				got, err := reader.ReadMessage()
+				if err != nil {
+					t.Fatalf("message %d: ReadMessage() error: %v", i, err)
+				}
+				if string(got) != want {
+					t.Errorf("message %d = %q, want %q", i, got, want)
+				}
+			}
+			if _, err := reader.ReadMessage(); err != io.EOF {
+				t.Errorf("trailing ReadMessage() error = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestReadMessageContext_MalformedChunk(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"EOM data on chunked session", "<rpc-reply/>]]>]]>", "expected chunk header"},
+		{"invalid size", "\n#abc\ndata\n##\n", `invalid size "abc"`},
+		{"zero size", "\n#0\n\n##\n", `invalid size "0"`},
+		{"bad end marker", "\n#3\nabc\n##x", "invalid end-of-chunks marker"},
+		{"oversized header", "\n#12345678901234", "missing size terminator"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &netconfReader{reader: newMockReader(tt.input), useChunk: true}
+			_, err := reader.ReadMessage()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadMessage() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// AJ. Operational metrics
+// ---------------------------------------------------------------------------
+
+func TestRPCMetrics(t *testing.T) {
+	var sent bytes.Buffer
+	d := newWithDefaultsDriver(&sent)
+	d.stdout = &netconfReader{reader: newMockReader(
+		"<rpc-reply><ok/></rpc-reply>]]>]]>",
+		"<rpc-reply><rpc-error><error-tag>invalid-value</error-tag></rpc-error></rpc-reply>]]>]]>",
+	)}
+
+	if _, err := d.RPC(context.Background(), "<get/>"); err != nil {
+		t.Fatalf("RPC() error: %v", err)
+	}
+	if _, err := d.RPC(context.Background(), "<get/>"); err == nil {
+		t.Fatal("RPC() expected rpc-error")
+	}
+
+	m := d.DriverMetrics()
+	if m.Commands != 2 || m.CommandErrors != 1 {
+		t.Errorf("DriverMetrics() = %+v, want 2 commands, 1 error", m)
+	}
+}
+
+func TestRPCName(t *testing.T) {
+	tests := map[string]string{
+		"<edit-config>\n  <target><running/></target>": "edit-config",
+		"<commit/>":                  "commit",
+		"  <get-config><source/>":    "get-config",
+		`<nc:lock xmlns:nc="urn:x">`: "lock",
+		"not xml":                    "",
+	}
+	for op, want := range tests {
+		if got := rpcName(op); got != want {
+			t.Errorf("rpcName(%q) = %q, want %q", op, got, want)
+		}
+	}
+}
+
+func TestRPCRetriesInUse(t *testing.T) {
+	inUse := `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" message-id="1">
+  <rpc-error>
+    <error-type>protocol</error-type>
+    <error-tag>in-use</error-tag>
+    <error-severity>error</error-severity>
+  </rpc-error>
+</rpc-reply>]]>]]>`
+	ok := `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" message-id="2"><ok/></rpc-reply>]]>]]>`
+
+	d := &Driver{
+		config: &types.EquipmentConfig{
+			Address: "10.0.0.1",
+			Retry:   &types.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+		},
+		connected: true,
+		stdin:     &netconfWriter{writer: &bytes.Buffer{}},
+		stdout:    &netconfReader{reader: newMockReader(inUse, ok)},
+	}
+	if err := d.EditConfig(context.Background(), "running", "<system/>"); err != nil {
+		t.Fatalf("EditConfig() error = %v, want success on retry", err)
+	}
+	if m := d.DriverMetrics(); m.Retries != 1 || m.CommandErrors != 1 {
+		t.Errorf("metrics = %+v, want one retry", m)
+	}
+
+	// A transport failure is not retried: the write may have been applied
+	d.stdout = &netconfReader{reader: newMockReader()}
+	if err := d.EditConfig(context.Background(), "running", "<system/>"); err == nil {
+		t.Fatal("EditConfig() succeeded without a reply")
+	}
+	if m := d.DriverMetrics(); m.Retries != 1 {
+		t.Errorf("Retries = %d after a transport failure", m.Retries)
+	}
+}
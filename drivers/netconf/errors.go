@@ -0,0 +1,104 @@
+package netconf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// RPCError is a single <rpc-error> from a NETCONF reply (RFC 6241 §4.3)
+type RPCError struct {
+	Type     string `xml:"error-type"`
+	Tag      string `xml:"error-tag"`
+	Severity string `xml:"error-severity"`
+	AppTag   string `xml:"error-app-tag"`
+	Path     string `xml:"error-path"`
+	Message  string `xml:"error-message"`
+
+	// SessionID is the session holding a lock (lock-denied only)
+	SessionID string `xml:"error-info>session-id"`
+}
+
+// String formats the error as "type: tag - message [at path]"
+func (e RPCError) String() string {
+	s := fmt.Sprintf("%s: %s - %s", e.Type, e.Tag, strings.TrimSpace(e.Message))
+	if path := strings.TrimSpace(e.Path); path != "" {
+		s += " at " + path
+	}
+	return s
+}
+
+// ParseRPCErrors returns the rpc-error elements in a reply, or nil if the
+// reply has none or is not valid XML
+func ParseRPCErrors(reply []byte) []RPCError {
+	var r struct {
+		XMLName xml.Name   `xml:"rpc-reply"`
+		Errors  []RPCError `xml:"rpc-error"`
+	}
+	if err := xml.Unmarshal(reply, &r); err != nil {
+		return nil
+	}
+	return r.Errors
+}
+
+// transientErrorTags are rpc-error tags for requests the device refused
+// without applying them, which may succeed once the condition clears.
+// lock-denied is left to the caller of Lock, which reports the holder.
+var transientErrorTags = map[string]bool{
+	"in-use":          true,
+	"resource-denied": true,
+}
+
+// isTransientRPCError reports whether every rpc-error in reply is one the
+// request can be retried after
+func isTransientRPCError(reply []byte) bool {
+	errs := ParseRPCErrors(reply)
+	for _, e := range errs {
+		if !transientErrorTags[strings.TrimSpace(e.Tag)] {
+			return false
+		}
+	}
+	return len(errs) > 0
+}
+
+// ValidationError is returned when the device rejects a configuration
+// during <validate>. It carries every rpc-error so callers can report
+// each offending path.
+type ValidationError struct {
+	Errors []RPCError
+	Raw    string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 0 {
+		return "configuration validation failed"
+	}
+	msgs := make([]string, len(e.Errors))
+	for i, re := range e.Errors {
+		msgs[i] = re.String()
+	}
+	return "configuration validation failed: " + strings.Join(msgs, "; ")
+}
+
+// ToHumanError converts the validation failure into a provisioning error
+// for the given vendor
+func (e *ValidationError) ToHumanError(vendor string) *types.HumanError {
+	msg := "device rejected the generated configuration"
+	if len(e.Errors) > 0 {
+		msg = strings.TrimSpace(e.Errors[0].Message)
+		if path := strings.TrimSpace(e.Errors[0].Path); path != "" {
+			msg += " at " + path
+		}
+	}
+	return &types.HumanError{
+		Code:        types.ErrCodeValidationFailed,
+		Message:     msg,
+		Action:      "Check the subscriber and profile parameters against the device configuration",
+		Vendor:      vendor,
+		Raw:         e.Raw,
+		Recoverable: false,
+	}
+}
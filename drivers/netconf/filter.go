@@ -0,0 +1,87 @@
+package netconf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// XPathFilter is an RFC 6241 §8.9 XPath filter. Every prefix used in
+// Select must be bound in Namespaces.
+type XPathFilter struct {
+	Select     string
+	Namespaces map[string]string // prefix -> namespace URI
+}
+
+// WithXPath selects data with an XPath filter when the server advertises
+// the :xpath capability. Otherwise the subtree filter passed to Get or
+// GetConfig is sent instead, so callers should supply both.
+func WithXPath(f XPathFilter) GetOption {
+	return func(o *getOptions) { o.xpath = &f }
+}
+
+// element renders the <filter type="xpath"> element
+func (f *XPathFilter) element() string {
+	prefixes := make([]string, 0, len(f.Namespaces))
+	for p := range f.Namespaces {
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+
+	var b strings.Builder
+	b.WriteString(`<filter type="xpath"`)
+	for _, p := range prefixes {
+		fmt.Fprintf(&b, ` xmlns:%s="%s"`, p, xmlAttrEscape(f.Namespaces[p]))
+	}
+	fmt.Fprintf(&b, ` select="%s"/>`, xmlAttrEscape(f.Select))
+	return b.String()
+}
+
+// filterParam renders the filter for a retrieval operation: the XPath
+// filter if requested and supported, else the subtree filter, else "".
+func (d *Driver) filterParam(subtree string, opts *getOptions) (string, error) {
+	if opts.xpath != nil && d.HasCapability(CapXPath) {
+		return "\n  " + opts.xpath.element(), nil
+	}
+	if subtree == "" {
+		if opts.xpath != nil {
+			return "", fmt.Errorf("NETCONF server does not support xpath capability")
+		}
+		return "", nil
+	}
+	return fmt.Sprintf(`
+  <filter type="subtree">
+    %s
+  </filter>`, subtree), nil
+}
+
+// XPathLiteral quotes s as an XPath 1.0 string literal, for use in key
+// predicates built from user input
+func XPathLiteral(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	if !strings.Contains(s, `"`) {
+		return `"` + s + `"`
+	}
+	// Both quote kinds: concat('a', "'", 'b')
+	parts := strings.Split(s, "'")
+	for i, p := range parts {
+		parts[i] = "'" + p + "'"
+	}
+	return "concat(" + strings.Join(parts, `, "'", `) + ")"
+}
+
+// XPathLeaves selects the given children of base, so the reply carries
+// only those leaves (and the ancestors and keys needed to locate them)
+func XPathLeaves(base string, leaves ...string) string {
+	paths := make([]string, len(leaves))
+	for i, leaf := range leaves {
+		paths[i] = base + "/" + leaf
+	}
+	return strings.Join(paths, " | ")
+}
+
+func xmlAttrEscape(s string) string {
+	return strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `"`, "&quot;").Replace(s)
+}
@@ -0,0 +1,108 @@
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestXPathLiteral(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"GigabitEthernet0/0/0/1.100", "'GigabitEthernet0/0/0/1.100'"},
+		{"it's", `"it's"`},
+		{`a'b"c`, `concat('a', "'", 'b"c')`},
+		{"", "''"},
+	}
+	for _, tt := range tests {
+		if got := XPathLiteral(tt.in); got != tt.want {
+			t.Errorf("XPathLiteral(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestXPathLeaves(t *testing.T) {
+	got := XPathLeaves("/a:x[a:k='1']", "a:y", "a:z")
+	want := "/a:x[a:k='1']/a:y | /a:x[a:k='1']/a:z"
+	if got != want {
+		t.Errorf("XPathLeaves() = %q, want %q", got, want)
+	}
+}
+
+var testXPath = XPathFilter{
+	Select: "/if:interfaces/if:interface[if:name='eth0' and if:type!=\"x\"]/if:oper-status",
+	Namespaces: map[string]string{
+		"if": "urn:ietf:params:xml:ns:yang:ietf-interfaces",
+		"ex": "urn:example",
+	},
+}
+
+func TestGetXPathFilter(t *testing.T) {
+	t.Run("sent when supported", func(t *testing.T) {
+		var sent bytes.Buffer
+		d := newWithDefaultsDriver(&sent, CapXPath)
+		if _, err := d.Get(context.Background(), "<interfaces/>", WithXPath(testXPath)); err != nil {
+			t.Fatalf("Get() error: %v", err)
+		}
+		got := sent.String()
+		want := `<filter type="xpath" xmlns:ex="urn:example" xmlns:if="urn:ietf:params:xml:ns:yang:ietf-interfaces" ` +
+			`select="/if:interfaces/if:interface[if:name='eth0' and if:type!=&quot;x&quot;]/if:oper-status"/>`
+		if !strings.Contains(got, want) {
+			t.Errorf("sent RPC = %s, want %s", got, want)
+		}
+		if strings.Contains(got, "subtree") {
+			t.Errorf("subtree filter should not be sent with xpath: %s", got)
+		}
+	})
+
+	t.Run("subtree fallback", func(t *testing.T) {
+		var sent bytes.Buffer
+		d := newWithDefaultsDriver(&sent, NetconfBase10)
+		if _, err := d.Get(context.Background(), "<interfaces/>", WithXPath(testXPath)); err != nil {
+			t.Fatalf("Get() error: %v", err)
+		}
+		got := sent.String()
+		if !strings.Contains(got, `<filter type="subtree">`) || strings.Contains(got, "xpath") {
+			t.Errorf("sent RPC = %s, want subtree filter", got)
+		}
+	})
+
+	t.Run("no fallback", func(t *testing.T) {
+		var sent bytes.Buffer
+		d := newWithDefaultsDriver(&sent, NetconfBase10)
+		_, err := d.Get(context.Background(), "", WithXPath(testXPath))
+		if err == nil || !strings.Contains(err.Error(), "xpath capability") {
+			t.Fatalf("Get() error = %v, want xpath capability error", err)
+		}
+		if sent.Len() != 0 {
+			t.Errorf("no RPC expected, sent %s", sent.String())
+		}
+	})
+
+	t.Run("with defaults", func(t *testing.T) {
+		var sent bytes.Buffer
+		d := newWithDefaultsDriver(&sent, CapXPath, CapWithDefaults+"?basic-mode=report-all")
+		if _, err := d.Get(context.Background(), "", WithXPath(testXPath), WithDefaults(WithDefaultsReportAll)); err != nil {
+			t.Fatalf("Get() error: %v", err)
+		}
+		got := sent.String()
+		if strings.Index(got, `<filter type="xpath"`) > strings.Index(got, "<with-defaults") {
+			t.Errorf("filter should precede with-defaults: %s", got)
+		}
+	})
+}
+
+func TestGetConfigXPathFilter(t *testing.T) {
+	var sent bytes.Buffer
+	d := newWithDefaultsDriver(&sent, CapXPath)
+	if _, err := d.GetConfig(context.Background(), "candidate", "", WithXPath(testXPath)); err != nil {
+		t.Fatalf("GetConfig() error: %v", err)
+	}
+	got := sent.String()
+	if !strings.Contains(got, "<candidate/>") || !strings.Contains(got, `<filter type="xpath"`) {
+		t.Errorf("sent RPC = %s", got)
+	}
+}
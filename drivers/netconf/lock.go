@@ -0,0 +1,284 @@
+package netconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// DefaultLeaseTTL is the lease duration used when none is given
+const DefaultLeaseTTL = 2 * time.Minute
+
+// heartbeatFilter selects nothing (RFC 6241 §6.4.2), so the heartbeat
+// <get> is answered with an empty <data/> and costs the device nothing
+const heartbeatFilter = `<get><filter type="subtree"/></get>`
+
+// ErrLeaseExpired is returned by a lease whose TTL elapsed before renewal
+var ErrLeaseExpired = errors.New("datastore lock lease expired")
+
+// LockDeniedError is returned when another session holds the datastore lock
+type LockDeniedError struct {
+	Target    string
+	SessionID string // Session holding the lock, if reported by the device
+	Raw       string
+}
+
+// Error implements the error interface
+func (e *LockDeniedError) Error() string {
+	if e.SessionID != "" {
+		return fmt.Sprintf("%s datastore is locked by session %s", e.Target, e.SessionID)
+	}
+	return fmt.Sprintf("%s datastore is locked by another session", e.Target)
+}
+
+// ToHumanError converts the lock conflict into a provisioning error
+func (e *LockDeniedError) ToHumanError(vendor string) *types.HumanError {
+	return &types.HumanError{
+		Code:        types.ErrCodeConfigLocked,
+		Message:     e.Error(),
+		Action:      "Another controller or operator is editing this device; retry later",
+		Vendor:      vendor,
+		Raw:         e.Raw,
+		Recoverable: true,
+	}
+}
+
+// lockError converts a failed <lock> reply into a *LockDeniedError when the
+// device reports lock-denied
+func lockError(target string, reply []byte, err error) error {
+	for _, re := range ParseRPCErrors(reply) {
+		if re.Tag == "lock-denied" {
+			return &LockDeniedError{Target: target, SessionID: re.SessionID, Raw: string(reply)}
+		}
+	}
+	return err
+}
+
+// Lease is a datastore lock held for a bounded time. While held, a
+// heartbeat keeps the NETCONF session active and detects session loss.
+// The lock is released when the lease is released, when the TTL elapses
+// without renewal, or when the session closes.
+//
+// A lease guards against interleaved edits from two controllers: the
+// device grants the lock to one session only, and a crashed or stuck
+// holder loses the lock after one TTL instead of holding it indefinitely.
+type Lease struct {
+	exec    NETCONFExecutor
+	target  string
+	onClose func(*Lease)
+
+	mu      sync.Mutex
+	expires time.Time
+	timer   *time.Timer
+	err     error
+	done    chan struct{}
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// AcquireLease locks target on exec and returns a lease valid for ttl.
+// A lock conflict is returned as *LockDeniedError.
+func AcquireLease(ctx context.Context, exec NETCONFExecutor, target string, ttl time.Duration) (*Lease, error) {
+	return acquireLease(ctx, exec, target, ttl, nil)
+}
+
+func acquireLease(ctx context.Context, exec NETCONFExecutor, target string, ttl time.Duration, onClose func(*Lease)) (*Lease, error) {
+	if target == "" {
+		target = "running"
+	}
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+
+	if err := exec.Lock(ctx, target); err != nil {
+		return nil, err
+	}
+
+	l := &Lease{
+		exec:    exec,
+		target:  target,
+		onClose: onClose,
+		expires: time.Now().Add(ttl),
+		done:    make(chan struct{}),
+		stop:    make(chan struct{}),
+	}
+	l.mu.Lock()
+	l.timer = time.AfterFunc(ttl, func() { l.end(ErrLeaseExpired, true) })
+	l.mu.Unlock()
+	go l.heartbeat(heartbeatInterval(ttl))
+	return l, nil
+}
+
+// heartbeatInterval sends three heartbeats per TTL, at most once a second
+func heartbeatInterval(ttl time.Duration) time.Duration {
+	interval := ttl / 3
+	if interval < time.Second {
+		interval = time.Second
+	}
+	return interval
+}
+
+// Target returns the locked datastore
+func (l *Lease) Target() string {
+	return l.target
+}
+
+// Expires returns when the lease lapses unless renewed
+func (l *Lease) Expires() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.expires
+}
+
+// Done is closed when the lease ends for any reason
+func (l *Lease) Done() <-chan struct{} {
+	return l.done
+}
+
+// Err returns nil while the lease is held, or why it ended
+func (l *Lease) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Renew extends the lease by ttl from now
+func (l *Lease) Renew(ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return l.err
+	}
+	l.expires = time.Now().Add(ttl)
+	l.timer.Reset(ttl)
+	return nil
+}
+
+// Release unlocks the datastore and ends the lease
+func (l *Lease) Release(ctx context.Context) error {
+	if l.Err() != nil {
+		return nil
+	}
+	err := l.exec.Unlock(ctx, l.target)
+	l.end(errLeaseReleased, false)
+	return err
+}
+
+var errLeaseReleased = errors.New("datastore lock lease released")
+
+// end records why the lease ended, optionally unlocking the datastore
+func (l *Lease) end(reason error, unlock bool) {
+	l.once.Do(func() {
+		l.mu.Lock()
+		l.err = reason
+		l.timer.Stop()
+		l.mu.Unlock()
+
+		close(l.stop)
+		if unlock {
+			_ = l.exec.Unlock(context.Background(), l.target) //nolint:errcheck // best effort
+		}
+		if l.onClose != nil {
+			l.onClose(l)
+		}
+		close(l.done)
+	})
+}
+
+// heartbeat probes the session until the lease ends. A failed probe means
+// the session (and with it the lock) is gone.
+func (l *Lease) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			_, err := l.exec.RPC(ctx, heartbeatFilter)
+			cancel()
+			if err != nil {
+				l.end(fmt.Errorf("datastore lock lost: heartbeat failed: %w", err), false)
+				return
+			}
+		}
+	}
+}
+
+// WithLease runs fn while holding a lease on target. The context passed to
+// fn is cancelled if the lease is lost; the lease is released afterwards.
+func WithLease(ctx context.Context, exec NETCONFExecutor, target string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	lease, err := AcquireLease(ctx, exec, target, ttl)
+	if err != nil {
+		return err
+	}
+	defer lease.Release(context.Background()) //nolint:errcheck // best effort
+
+	fnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-lease.Done():
+			cancel()
+		case <-fnCtx.Done():
+		}
+	}()
+
+	if err := fn(fnCtx); err != nil {
+		return err
+	}
+	return lease.Err()
+}
+
+// AcquireLease locks target for ttl. Leases held by the driver are
+// unlocked before the session is closed by Disconnect.
+func (d *Driver) AcquireLease(ctx context.Context, target string, ttl time.Duration) (*Lease, error) {
+	lease, err := acquireLease(ctx, d, target, ttl, d.forgetLease)
+	if err != nil {
+		return nil, err
+	}
+	d.leaseMu.Lock()
+	if d.leases == nil {
+		d.leases = make(map[*Lease]struct{})
+	}
+	d.leases[lease] = struct{}{}
+	d.leaseMu.Unlock()
+
+	// The lease may have ended before it was tracked
+	if lease.Err() != nil {
+		d.forgetLease(lease)
+	}
+	return lease, nil
+}
+
+func (d *Driver) forgetLease(l *Lease) {
+	d.leaseMu.Lock()
+	delete(d.leases, l)
+	d.leaseMu.Unlock()
+}
+
+// releaseLeases unlocks every lease held through the driver
+func (d *Driver) releaseLeases(ctx context.Context) {
+	d.leaseMu.Lock()
+	leases := make([]*Lease, 0, len(d.leases))
+	for l := range d.leases {
+		leases = append(leases, l)
+	}
+	d.leaseMu.Unlock()
+
+	for _, l := range leases {
+		// Best effort before close-session, which releases locks anyway
+		if err := l.Release(ctx); err != nil {
+			d.config.Log().Warn("NETCONF lock release failed", "target", l.target, "error", err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// lockExecutor records lock traffic for lease tests
+type lockExecutor struct {
+	fakeExecutor
+	mu      sync.Mutex
+	locks   int
+	unlocks int
+	lockErr error
+	rpcErr  error
+}
+
+func (e *lockExecutor) Lock(context.Context, string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.locks++
+	return e.lockErr
+}
+
+func (e *lockExecutor) Unlock(context.Context, string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.unlocks++
+	return nil
+}
+
+func (e *lockExecutor) RPC(context.Context, string) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return nil, e.rpcErr
+}
+
+func (e *lockExecutor) counts() (int, int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.locks, e.unlocks
+}
+
+func waitDone(t *testing.T, l *Lease) {
+	t.Helper()
+	select {
+	case <-l.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("lease did not end")
+	}
+}
+
+func TestLeaseAcquireRelease(t *testing.T) {
+	exec := &lockExecutor{}
+	lease, err := AcquireLease(context.Background(), exec, "", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLease() error: %v", err)
+	}
+	if lease.Target() != "running" {
+		t.Errorf("Target() = %q, want running", lease.Target())
+	}
+	if lease.Err() != nil {
+		t.Errorf("Err() = %v while held", lease.Err())
+	}
+
+	if err := lease.Release(context.Background()); err != nil {
+		t.Fatalf("Release() error: %v", err)
+	}
+	waitDone(t, lease)
+	if err := lease.Release(context.Background()); err != nil {
+		t.Errorf("second Release() error: %v", err)
+	}
+	if locks, unlocks := exec.counts(); locks != 1 || unlocks != 1 {
+		t.Errorf("locks/unlocks = %d/%d, want 1/1", locks, unlocks)
+	}
+	if err := lease.Renew(time.Minute); err == nil {
+		t.Error("Renew() after release should fail")
+	}
+}
+
+func TestLeaseExpires(t *testing.T) {
+	exec := &lockExecutor{}
+	lease, err := AcquireLease(context.Background(), exec, "candidate", 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("AcquireLease() error: %v", err)
+	}
+	waitDone(t, lease)
+
+	if !errors.Is(lease.Err(), ErrLeaseExpired) {
+		t.Errorf("Err() = %v, want ErrLeaseExpired", lease.Err())
+	}
+	if _, unlocks := exec.counts(); unlocks != 1 {
+		t.Errorf("unlocks = %d, want 1 after expiry", unlocks)
+	}
+}
+
+func TestLeaseRenew(t *testing.T) {
+	lease, err := AcquireLease(context.Background(), &lockExecutor{}, "candidate", 80*time.Millisecond)
+	if err != nil {
+		t.Fatalf("AcquireLease() error: %v", err)
+	}
+	defer lease.Release(context.Background()) //nolint:errcheck
+
+	time.Sleep(40 * time.Millisecond)
+	if err := lease.Renew(time.Second); err != nil {
+		t.Fatalf("Renew() error: %v", err)
+	}
+	time.Sleep(80 * time.Millisecond)
+	if lease.Err() != nil {
+		t.Errorf("lease ended despite renewal: %v", lease.Err())
+	}
+}
+
+func TestLeaseHeartbeatFailure(t *testing.T) {
+	exec := &lockExecutor{}
+	lease, err := AcquireLease(context.Background(), exec, "candidate", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLease() error: %v", err)
+	}
+
+	exec.mu.Lock()
+	exec.rpcErr = fmt.Errorf("session closed")
+	exec.mu.Unlock()
+	go lease.heartbeat(10 * time.Millisecond)
+
+	waitDone(t, lease)
+	if err := lease.Err(); err == nil || !strings.Contains(err.Error(), "heartbeat failed") {
+		t.Errorf("Err() = %v, want heartbeat failure", err)
+	}
+	if _, unlocks := exec.counts(); unlocks != 0 {
+		t.Errorf("unlocks = %d, want 0 once the session is gone", unlocks)
+	}
+}
+
+func TestWithLease(t *testing.T) {
+	exec := &lockExecutor{}
+	ran := false
+	err := WithLease(context.Background(), exec, "candidate", time.Minute, func(ctx context.Context) error {
+		ran = true
+		return ctx.Err()
+	})
+	if err != nil || !ran {
+		t.Fatalf("WithLease() = %v, ran = %v", err, ran)
+	}
+	if _, unlocks := exec.counts(); unlocks != 1 {
+		t.Errorf("unlocks = %d, want 1", unlocks)
+	}
+
+	exec.lockErr = &LockDeniedError{Target: "candidate", SessionID: "42"}
+	err = WithLease(context.Background(), exec, "candidate", time.Minute, func(context.Context) error {
+		t.Error("fn should not run without the lock")
+		return nil
+	})
+	var denied *LockDeniedError
+	if !errors.As(err, &denied) {
+		t.Errorf("WithLease() error = %v, want *LockDeniedError", err)
+	}
+}
+
+func TestLockDenied(t *testing.T) {
+	reply := `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" message-id="1">
+  <rpc-error>
+    <error-type>protocol</error-type>
+    <error-tag>lock-denied</error-tag>
+    <error-severity>error</error-severity>
+    <error-info><session-id>454</session-id></error-info>
+    <error-message>Lock failed, lock is already held</error-message>
+  </rpc-error>
+</rpc-reply>]]>]]>`
+
+	d := &Driver{
+		config:    &types.EquipmentConfig{Address: "10.0.0.1"},
+		connected: true,
+		stdin:     &netconfWriter{writer: &bytes.Buffer{}},
+		stdout:    &netconfReader{reader: newMockReader(reply)},
+	}
+
+	err := d.Lock(context.Background(), "candidate")
+	var denied *LockDeniedError
+	if !errors.As(err, &denied) {
+		t.Fatalf("Lock() error = %v, want *LockDeniedError", err)
+	}
+	if denied.SessionID != "454" || denied.Target != "candidate" {
+		t.Errorf("LockDeniedError = %+v", denied)
+	}
+	he := denied.ToHumanError("nokia")
+	if he.Code != types.ErrCodeConfigLocked || !he.Recoverable {
+		t.Errorf("HumanError = %+v", he)
+	}
+}
+
+func TestDisconnectReleasesLeases(t *testing.T) {
+	ok := `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><ok/></rpc-reply>]]>]]>`
+	var sent bytes.Buffer
+	d := &Driver{
+		config:    &types.EquipmentConfig{Address: "10.0.0.1"},
+		connected: true,
+		stdin:     &netconfWriter{writer: &sent},
+		stdout:    &netconfReader{reader: newMockReader(ok, ok)},
+	}
+
+	lease, err := d.AcquireLease(context.Background(), "candidate", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLease() error: %v", err)
+	}
+	if err := d.Disconnect(context.Background()); err != nil {
+		t.Fatalf("Disconnect() error: %v", err)
+	}
+
+	waitDone(t, lease)
+	out := sent.String()
+	unlock := strings.Index(out, "<unlock>")
+	closeSession := strings.Index(out, "<close-session/>")
+	if unlock == -1 || closeSession == -1 || unlock > closeSession {
+		t.Errorf("expected unlock before close-session, sent:\n%s", out)
+	}
+	if len(d.leases) != 0 {
+		t.Errorf("leases = %d after Disconnect, want 0", len(d.leases))
+	}
+}
@@ -0,0 +1,292 @@
+package netconf
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RFC 5277 capabilities
+const (
+	CapNotification = "urn:ietf:params:netconf:capability:notification:1.0"
+	CapInterleave   = "urn:ietf:params:netconf:capability:interleave:1.0"
+)
+
+// Well-known notification event names
+const (
+	// EventReplayComplete marks the end of replayed events (RFC 5277)
+	EventReplayComplete = "replayComplete"
+	// EventNotificationComplete is sent when a subscription's stop time passes
+	EventNotificationComplete = "notificationComplete"
+	// EventAlarmNotification is raised by ietf-alarms (RFC 8632)
+	EventAlarmNotification = "alarm-notification"
+	// EventConfigChange is raised by ietf-netconf-notifications (RFC 6470)
+	EventConfigChange = "netconf-config-change"
+)
+
+// Notification is a single RFC 5277 <notification> message
+type Notification struct {
+	EventTime time.Time
+	Name      string // Local name of the event element
+	Namespace string // Namespace of the event element
+	Body      []byte // The event element, including its start and end tags
+}
+
+// Decode unmarshals the event element into v
+func (n *Notification) Decode(v interface{}) error {
+	return xml.Unmarshal(n.Body, v)
+}
+
+// ParseNotification parses a <notification> message. The event is the
+// first child element other than <eventTime>.
+func ParseNotification(data []byte) (*Notification, error) {
+	dec := xml.NewDecoder(strings.NewReader(string(data)))
+	n := &Notification{}
+	inNotification := false
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if !inNotification {
+			if start.Name.Local != "notification" {
+				return nil, fmt.Errorf("invalid notification: unexpected <%s>", start.Name.Local)
+			}
+			inNotification = true
+			continue
+		}
+
+		if start.Name.Local == "eventTime" {
+			var ts string
+			if err := dec.DecodeElement(&ts, &start); err != nil {
+				return nil, fmt.Errorf("invalid notification eventTime: %w", err)
+			}
+			n.EventTime, err = time.Parse(time.RFC3339Nano, strings.TrimSpace(ts))
+			if err != nil {
+				return nil, fmt.Errorf("invalid notification eventTime %q: %w", ts, err)
+			}
+			continue
+		}
+
+		// The event element: capture it verbatim so Decode sees the original
+		offset := dec.InputOffset()
+		if err := dec.Skip(); err != nil {
+			return nil, fmt.Errorf("invalid notification event: %w", err)
+		}
+		n.Name = start.Name.Local
+		n.Namespace = start.Name.Space
+		n.Body = eventBody(data, offset, dec.InputOffset())
+		return n, nil
+	}
+}
+
+// eventBody returns the raw bytes of an element given the offsets just after
+// its start tag and just after its end tag
+func eventBody(data []byte, afterStart, afterEnd int64) []byte {
+	// Find the opening '<' of the start tag before afterStart
+	open := strings.LastIndex(string(data[:afterStart]), "<")
+	if open == -1 {
+		open = 0
+	}
+	body := make([]byte, afterEnd-int64(open))
+	copy(body, data[open:afterEnd])
+	return body
+}
+
+// IsNotification reports whether a message is a <notification> rather than
+// an <rpc-reply>
+func IsNotification(data []byte) bool {
+	dec := xml.NewDecoder(strings.NewReader(string(data)))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local == "notification"
+		}
+	}
+}
+
+// NotificationHandler receives notifications
+type NotificationHandler func(n *Notification)
+
+// Dispatcher routes notifications to handlers by event name. It is safe
+// for concurrent use and can be passed to Subscribe via its Dispatch method.
+type Dispatcher struct {
+	mu       sync.RWMutex
+	handlers map[string][]NotificationHandler
+	fallback NotificationHandler
+}
+
+// NewDispatcher creates an empty notification dispatcher
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[string][]NotificationHandler)}
+}
+
+// On registers a handler for an event name (e.g. EventAlarmNotification)
+func (d *Dispatcher) On(event string, handler NotificationHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[event] = append(d.handlers[event], handler)
+}
+
+// OnUnhandled registers a handler for events without a specific handler
+func (d *Dispatcher) OnUnhandled(handler NotificationHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fallback = handler
+}
+
+// Dispatch delivers n to the handlers registered for its event name
+func (d *Dispatcher) Dispatch(n *Notification) {
+	d.mu.RLock()
+	handlers := d.handlers[n.Name]
+	fallback := d.fallback
+	d.mu.RUnlock()
+
+	if len(handlers) == 0 {
+		if fallback != nil {
+			fallback(n)
+		}
+		return
+	}
+	for _, h := range handlers {
+		h(n)
+	}
+}
+
+// SubscriptionOptions configures <create-subscription>
+type SubscriptionOptions struct {
+	Stream    string    // Event stream (default "NETCONF")
+	Filter    string    // Optional subtree filter
+	StartTime time.Time // Replay events from this time (requires replay support)
+	StopTime  time.Time // Stop the subscription at this time
+}
+
+// buildCreateSubscription renders the create-subscription RPC body
+func buildCreateSubscription(opts SubscriptionOptions) string {
+	var b strings.Builder
+	b.WriteString(`<create-subscription xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0">`)
+	if opts.Stream != "" {
+		fmt.Fprintf(&b, "\n  <stream>%s</stream>", xmlEscape(opts.Stream))
+	}
+	if opts.Filter != "" {
+		fmt.Fprintf(&b, "\n  <filter type=\"subtree\">\n    %s\n  </filter>", opts.Filter)
+	}
+	if !opts.StartTime.IsZero() {
+		fmt.Fprintf(&b, "\n  <startTime>%s</startTime>", opts.StartTime.UTC().Format(time.RFC3339))
+	}
+	if !opts.StopTime.IsZero() {
+		fmt.Fprintf(&b, "\n  <stopTime>%s</stopTime>", opts.StopTime.UTC().Format(time.RFC3339))
+	}
+	b.WriteString("\n</create-subscription>")
+	return b.String()
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// NotificationSubscriber is implemented by drivers that support RFC 5277
+// notifications. Adapters type-assert their base driver to it.
+type NotificationSubscriber interface {
+	Subscribe(ctx context.Context, opts SubscriptionOptions, handler NotificationHandler) (*NotificationSubscription, error)
+}
+
+// Ensure Driver implements NotificationSubscriber
+var _ NotificationSubscriber = (*Driver)(nil)
+
+// NotificationSubscription is an active RFC 5277 subscription
+type NotificationSubscription struct {
+	driver *Driver
+	done   chan struct{}
+	mu     sync.Mutex
+	err    error
+}
+
+// Done is closed when the subscription ends
+func (s *NotificationSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the error that ended the subscription, if any
+func (s *NotificationSubscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Stop ends the subscription. RFC 5277 has no cancel operation, so this
+// closes the NETCONF session.
+func (s *NotificationSubscription) Stop(ctx context.Context) error {
+	return s.driver.Disconnect(ctx)
+}
+
+// Subscribe sends <create-subscription> and delivers notifications to
+// handler until the session closes.
+//
+// Once subscribed, the session is dedicated to notifications and further
+// RPCs on this driver fail; use a separate Driver for configuration and
+// operational queries.
+func (d *Driver) Subscribe(ctx context.Context, opts SubscriptionOptions, handler NotificationHandler) (*NotificationSubscription, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("notification handler is required")
+	}
+	if !d.HasCapability(CapNotification) {
+		return nil, fmt.Errorf("NETCONF server does not support notification capability")
+	}
+
+	if _, err := d.RPC(ctx, buildCreateSubscription(opts)); err != nil {
+		return nil, fmt.Errorf("create-subscription failed: %w", err)
+	}
+
+	d.mu.Lock()
+	d.notifying = true
+	reader := d.stdout
+	d.mu.Unlock()
+
+	sub := &NotificationSubscription{driver: d, done: make(chan struct{})}
+	go sub.readLoop(reader, handler)
+	return sub, nil
+}
+
+// readLoop reads notifications until the session ends
+func (s *NotificationSubscription) readLoop(reader *netconfReader, handler NotificationHandler) {
+	defer close(s.done)
+
+	for {
+		msg, err := reader.ReadMessage()
+		if err != nil {
+			if s.driver.IsConnected() {
+				s.mu.Lock()
+				s.err = fmt.Errorf("notification stream ended: %w", err)
+				s.mu.Unlock()
+			}
+			return
+		}
+		if !IsNotification(msg) {
+			continue
+		}
+
+		n, err := ParseNotification(msg)
+		if err != nil {
+			continue
+		}
+		handler(n)
+		if n.Name == EventNotificationComplete {
+			return
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+const testAlarmNotification = `<notification xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0">
+  <eventTime>2026-03-01T10:15:30.5Z</eventTime>
+  <alarm-notification xmlns="urn:ietf:params:xml:ns:yang:ietf-alarms">
+    <resource>ct-1/1/1</resource>
+    <alarm-type-id>los</alarm-type-id>
+    <perceived-severity>major</perceived-severity>
+    <alarm-text>Loss of signal &amp; frame</alarm-text>
+  </alarm-notification>
+</notification>`
+
+func TestParseNotification(t *testing.T) {
+	n, err := ParseNotification([]byte(testAlarmNotification))
+	if err != nil {
+		t.Fatalf("ParseNotification() error: %v", err)
+	}
+	if n.Name != EventAlarmNotification || n.Namespace != "urn:ietf:params:xml:ns:yang:ietf-alarms" {
+		t.Errorf("Name/Namespace = %q/%q", n.Name, n.Namespace)
+	}
+	want := time.Date(2026, 3, 1, 10, 15, 30, 500000000, time.UTC)
+	if !n.EventTime.Equal(want) {
+		t.Errorf("EventTime = %v, want %v", n.EventTime, want)
+	}
+
+	var alarm struct {
+		Resource string `xml:"resource"`
+		Severity string `xml:"perceived-severity"`
+		Text     string `xml:"alarm-text"`
+	}
+	if err := n.Decode(&alarm); err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if alarm.Resource != "ct-1/1/1" || alarm.Severity != "major" || alarm.Text != "Loss of signal & frame" {
+		t.Errorf("decoded alarm = %+v", alarm)
+	}
+
+	for _, bad := range []string{
+		`<rpc-reply><ok/></rpc-reply>`,
+		`<notification><eventTime>yesterday</eventTime><x/></notification>`,
+		`<notification><eventTime>2026-03-01T10:15:30Z</eventTime>`,
+	} {
+		if _, err := ParseNotification([]byte(bad)); err == nil {
+			t.Errorf("ParseNotification(%q) expected error", bad)
+		}
+	}
+}
+
+func TestIsNotification(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{testAlarmNotification, true},
+		{`<?xml version="1.0"?><nc:notification xmlns:nc="x"/>`, true},
+		{`<rpc-reply message-id="1"><ok/></rpc-reply>`, false},
+		{`garbage`, false},
+	}
+	for _, tt := range tests {
+		if got := IsNotification([]byte(tt.data)); got != tt.want {
+			t.Errorf("IsNotification(%.30q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	d := NewDispatcher()
+	var alarms, others int
+	d.On(EventAlarmNotification, func(*Notification) { alarms++ })
+	d.On(EventAlarmNotification, func(*Notification) { alarms++ })
+	d.OnUnhandled(func(*Notification) { others++ })
+
+	d.Dispatch(&Notification{Name: EventAlarmNotification})
+	d.Dispatch(&Notification{Name: EventConfigChange})
+
+	if alarms != 2 || others != 1 {
+		t.Errorf("alarms/others = %d/%d, want 2/1", alarms, others)
+	}
+}
+
+func TestBuildCreateSubscription(t *testing.T) {
+	got := buildCreateSubscription(SubscriptionOptions{
+		Stream:    "NETCONF",
+		Filter:    `<alarm-notification xmlns="urn:ietf:params:xml:ns:yang:ietf-alarms"/>`,
+		StartTime: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	for _, want := range []string{
+		`<create-subscription xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0">`,
+		"<stream>NETCONF</stream>",
+		`<filter type="subtree">`,
+		"<startTime>2026-01-02T03:04:05Z</startTime>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("create-subscription missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "stopTime") {
+		t.Error("stopTime should be omitted when unset")
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	ok := `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><ok/></rpc-reply>]]>]]>`
+	var sent bytes.Buffer
+	d := &Driver{
+		config:       &types.EquipmentConfig{Address: "10.0.0.1"},
+		connected:    true,
+		capabilities: []string{CapNotification},
+		stdin:        &netconfWriter{writer: &sent},
+		stdout:       &netconfReader{reader: newMockReader(ok, testAlarmNotification+"]]>]]>")},
+	}
+
+	var mu sync.Mutex
+	var got []*Notification
+	sub, err := d.Subscribe(context.Background(), SubscriptionOptions{}, func(n *Notification) {
+		mu.Lock()
+		got = append(got, n)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error: %v", err)
+	}
+	if !strings.Contains(sent.String(), "<create-subscription") {
+		t.Errorf("sent = %s", sent.String())
+	}
+
+	select {
+	case <-sub.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscription did not end at EOF")
+	}
+	if sub.Err() == nil {
+		t.Error("Err() = nil, want stream ended error while still connected")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || got[0].Name != EventAlarmNotification {
+		t.Errorf("notifications = %+v", got)
+	}
+
+	if _, err := d.RPC(context.Background(), "<get/>"); err == nil || !strings.Contains(err.Error(), "dedicated") {
+		t.Errorf("RPC() after Subscribe error = %v, want dedicated-session error", err)
+	}
+}
+
+func TestSubscribeWithoutCapability(t *testing.T) {
+	d := &Driver{config: &types.EquipmentConfig{}, connected: true}
+	if _, err := d.Subscribe(context.Background(), SubscriptionOptions{}, func(*Notification) {}); err == nil {
+		t.Error("expected error without notification capability")
+	}
+}
@@ -0,0 +1,128 @@
+package netconf
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// ConfigModel is a YANG-shaped configuration tree built from encoding/xml
+// structs. Validate checks the schema constraints (mandatory leaves, ranges,
+// formats) that the device would otherwise reject at edit-config time.
+type ConfigModel interface {
+	Validate() error
+}
+
+// MarshalConfig validates m and renders it as edit-config content.
+// Constraint violations are returned as *SchemaError.
+func MarshalConfig(m ConfigModel) (string, error) {
+	if err := m.Validate(); err != nil {
+		return "", err
+	}
+	out, err := xml.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	return string(out), nil
+}
+
+// SchemaError reports a generated configuration that violates its model
+type SchemaError struct {
+	Path   string // Schema path of the offending node
+	Reason string
+}
+
+// Error implements the error interface
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("invalid configuration at %s: %s", e.Path, e.Reason)
+}
+
+// ToHumanError converts the schema violation into a provisioning error
+func (e *SchemaError) ToHumanError(vendor string) *types.HumanError {
+	return &types.HumanError{
+		Code:        types.ErrCodeValidationFailed,
+		Message:     e.Error(),
+		Action:      "Fix the subscriber or service tier parameters",
+		Vendor:      vendor,
+		Recoverable: false,
+	}
+}
+
+// AsHumanError converts schema, validation and lock-denied failures into
+// *types.HumanError for vendor. Other errors are classified by their
+// message with types.AsHumanError.
+func AsHumanError(vendor string, err error) error {
+	var serr *SchemaError
+	if errors.As(err, &serr) {
+		return serr.ToHumanError(vendor)
+	}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return verr.ToHumanError(vendor)
+	}
+	var lerr *LockDeniedError
+	if errors.As(err, &lerr) {
+		return lerr.ToHumanError(vendor)
+	}
+	return types.AsHumanError(vendor, err)
+}
+
+// RequireLeaf fails if a mandatory leaf is empty
+func RequireLeaf(path, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return &SchemaError{Path: path, Reason: "mandatory leaf is missing"}
+	}
+	return nil
+}
+
+// CheckRange fails if v is outside [min, max]
+func CheckRange(path string, v, min, max int) error {
+	if v < min || v > max {
+		return &SchemaError{Path: path, Reason: fmt.Sprintf("value %d out of range %d..%d", v, min, max)}
+	}
+	return nil
+}
+
+// CheckVLANID fails unless id is a usable 802.1Q VLAN ID (1..4094)
+func CheckVLANID(path string, id int) error {
+	return CheckRange(path, id, 1, 4094)
+}
+
+// CheckMAC fails if a non-empty value is not a MAC address
+func CheckMAC(path, value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := net.ParseMAC(value); err != nil {
+		return &SchemaError{Path: path, Reason: fmt.Sprintf("invalid MAC address %q", value)}
+	}
+	return nil
+}
+
+// CheckIP fails if a non-empty value is not an IP address
+func CheckIP(path, value string) error {
+	if value == "" {
+		return nil
+	}
+	if net.ParseIP(value) == nil {
+		return &SchemaError{Path: path, Reason: fmt.Sprintf("invalid IP address %q", value)}
+	}
+	return nil
+}
+
+// FirstError returns the first non-nil error, for chaining leaf checks
+func FirstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Empty marshals as a YANG empty-type leaf (e.g. <dhcp/>)
+type Empty struct{}
@@ -0,0 +1,114 @@
+package netconf
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+type testModel struct {
+	XMLName xml.Name `xml:"urn:example:test widget"`
+	Name    string   `xml:"name"`
+	VLAN    int      `xml:"vlan"`
+	MAC     string   `xml:"mac,omitempty"`
+	Enabled *Empty   `xml:"enabled"`
+}
+
+func (m *testModel) Validate() error {
+	return FirstError(
+		RequireLeaf("/widget/name", m.Name),
+		CheckVLANID("/widget/vlan", m.VLAN),
+		CheckMAC("/widget/mac", m.MAC),
+	)
+}
+
+func TestMarshalConfig_Valid(t *testing.T) {
+	out, err := MarshalConfig(&testModel{Name: "w1", VLAN: 100, Enabled: &Empty{}})
+	if err != nil {
+		t.Fatalf("MarshalConfig failed: %v", err)
+	}
+	for _, want := range []string{
+		`<widget xmlns="urn:example:test">`,
+		"<name>w1</name>",
+		"<vlan>100</vlan>",
+		"<enabled></enabled>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<mac>") {
+		t.Errorf("empty optional leaf should be omitted:\n%s", out)
+	}
+}
+
+func TestMarshalConfig_EscapesValues(t *testing.T) {
+	out, err := MarshalConfig(&testModel{Name: "a<b&c", VLAN: 1})
+	if err != nil {
+		t.Fatalf("MarshalConfig failed: %v", err)
+	}
+	if !strings.Contains(out, "<name>a&lt;b&amp;c</name>") {
+		t.Errorf("value not escaped:\n%s", out)
+	}
+}
+
+func TestMarshalConfig_SchemaErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		model testModel
+		path  string
+	}{
+		{"missing name", testModel{VLAN: 100}, "/widget/name"},
+		{"VLAN zero", testModel{Name: "w", VLAN: 0}, "/widget/vlan"},
+		{"VLAN too high", testModel{Name: "w", VLAN: 4095}, "/widget/vlan"},
+		{"bad MAC", testModel{Name: "w", VLAN: 10, MAC: "zz:zz"}, "/widget/mac"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MarshalConfig(&tt.model)
+			var serr *SchemaError
+			if !errors.As(err, &serr) {
+				t.Fatalf("expected *SchemaError, got %v", err)
+			}
+			if serr.Path != tt.path {
+				t.Errorf("Path = %q, want %q", serr.Path, tt.path)
+			}
+		})
+	}
+}
+
+func TestCheckIP(t *testing.T) {
+	if err := CheckIP("/ip", ""); err != nil {
+		t.Errorf("empty value should pass: %v", err)
+	}
+	if err := CheckIP("/ip", "2001:db8::1"); err != nil {
+		t.Errorf("IPv6 should pass: %v", err)
+	}
+	if err := CheckIP("/ip", "10.0.0.256"); err == nil {
+		t.Error("expected error for invalid IPv4")
+	}
+}
+
+func TestAsHumanError(t *testing.T) {
+	he, ok := AsHumanError("test", &SchemaError{Path: "/x", Reason: "bad"}).(*types.HumanError)
+	if !ok {
+		t.Fatal("expected *types.HumanError for SchemaError")
+	}
+	if he.Code != types.ErrCodeValidationFailed || he.Vendor != "test" || he.Recoverable {
+		t.Errorf("HumanError = %+v", he)
+	}
+
+	verr := &ValidationError{Errors: []RPCError{{Tag: "invalid-value", Message: "bad"}}}
+	if _, ok := AsHumanError("test", verr).(*types.HumanError); !ok {
+		t.Error("expected *types.HumanError for ValidationError")
+	}
+
+	other := errors.New("boom")
+	if got := AsHumanError("test", other); got != other {
+		t.Errorf("other errors should pass through, got %v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package netconf
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// CapWithDefaults is the RFC 6243 with-defaults capability. Servers append
+// ?basic-mode=<mode>[&also-supported=<modes>] to it.
+const CapWithDefaults = "urn:ietf:params:netconf:capability:with-defaults:1.0"
+
+// WithDefaultsMode controls how default-valued leaves are reported (RFC 6243)
+type WithDefaultsMode string
+
+const (
+	// WithDefaultsReportAll reports every leaf, including defaults
+	WithDefaultsReportAll WithDefaultsMode = "report-all"
+	// WithDefaultsReportAllTagged reports every leaf and tags defaults
+	// with the wd:default attribute
+	WithDefaultsReportAllTagged WithDefaultsMode = "report-all-tagged"
+	// WithDefaultsTrim omits leaves whose value equals the schema default
+	WithDefaultsTrim WithDefaultsMode = "trim"
+	// WithDefaultsExplicit reports leaves that were explicitly set
+	WithDefaultsExplicit WithDefaultsMode = "explicit"
+)
+
+// GetOption configures get and get-config behavior
+type GetOption func(*getOptions)
+
+type getOptions struct {
+	withDefaults WithDefaultsMode
+	xpath        *XPathFilter
+}
+
+// WithDefaults requests a with-defaults retrieval mode. The parameter is
+// only sent when the server advertises support for mode; otherwise the
+// server's basic mode applies.
+func WithDefaults(mode WithDefaultsMode) GetOption {
+	return func(o *getOptions) { o.withDefaults = mode }
+}
+
+// WithDefaultsSupport describes a server's with-defaults capability
+type WithDefaultsSupport struct {
+	BasicMode     WithDefaultsMode
+	AlsoSupported []WithDefaultsMode
+}
+
+// Supports reports whether mode may be requested
+func (s *WithDefaultsSupport) Supports(mode WithDefaultsMode) bool {
+	if s == nil {
+		return false
+	}
+	// RFC 6243 §4.3: the basic mode is always accepted
+	if mode == s.BasicMode {
+		return true
+	}
+	for _, m := range s.AlsoSupported {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseWithDefaultsCapability returns the with-defaults support advertised
+// in caps, or nil if the server does not support with-defaults
+func ParseWithDefaultsCapability(caps []string) *WithDefaultsSupport {
+	for _, c := range caps {
+		base, query, _ := strings.Cut(c, "?")
+		if base != CapWithDefaults {
+			continue
+		}
+		params, err := url.ParseQuery(query)
+		if err != nil {
+			return nil
+		}
+		s := &WithDefaultsSupport{BasicMode: WithDefaultsMode(params.Get("basic-mode"))}
+		if also := params.Get("also-supported"); also != "" {
+			for _, m := range strings.Split(also, ",") {
+				s.AlsoSupported = append(s.AlsoSupported, WithDefaultsMode(strings.TrimSpace(m)))
+			}
+		}
+		return s
+	}
+	return nil
+}
+
+// withDefaultsParam renders the <with-defaults> parameter for a retrieval
+// operation, or "" if it should be omitted
+func (d *Driver) withDefaultsParam(opts *getOptions) string {
+	if opts.withDefaults == "" {
+		return ""
+	}
+	if !ParseWithDefaultsCapability(d.capabilities).Supports(opts.withDefaults) {
+		return ""
+	}
+	return fmt.Sprintf("\n  <with-defaults xmlns=\"urn:ietf:params:xml:ns:yang:ietf-netconf-with-defaults\">%s</with-defaults>", opts.withDefaults)
+}
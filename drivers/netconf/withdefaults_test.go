@@ -0,0 +1,148 @@
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestParseWithDefaultsCapability(t *testing.T) {
+	tests := []struct {
+		name      string
+		caps      []string
+		wantNil   bool
+		wantBasic WithDefaultsMode
+		supports  []WithDefaultsMode
+		rejects   []WithDefaultsMode
+	}{
+		{
+			name:    "not advertised",
+			caps:    []string{NetconfBase10, CapCandidate},
+			wantNil: true,
+		},
+		{
+			name:      "basic mode only",
+			caps:      []string{CapWithDefaults + "?basic-mode=explicit"},
+			wantBasic: WithDefaultsExplicit,
+			supports:  []WithDefaultsMode{WithDefaultsExplicit},
+			rejects:   []WithDefaultsMode{WithDefaultsReportAll, WithDefaultsTrim},
+		},
+		{
+			name:      "also-supported list",
+			caps:      []string{CapWithDefaults + "?basic-mode=trim&also-supported=report-all,report-all-tagged"},
+			wantBasic: WithDefaultsTrim,
+			supports:  []WithDefaultsMode{WithDefaultsTrim, WithDefaultsReportAll, WithDefaultsReportAllTagged},
+			rejects:   []WithDefaultsMode{WithDefaultsExplicit},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := ParseWithDefaultsCapability(tt.caps)
+			if tt.wantNil {
+				if s != nil {
+					t.Fatalf("ParseWithDefaultsCapability() = %+v, want nil", s)
+				}
+				if s.Supports(WithDefaultsReportAll) {
+					t.Error("nil support should reject every mode")
+				}
+				return
+			}
+			if s == nil {
+				t.Fatal("ParseWithDefaultsCapability() = nil")
+			}
+			if s.BasicMode != tt.wantBasic {
+				t.Errorf("BasicMode = %q, want %q", s.BasicMode, tt.wantBasic)
+			}
+			for _, m := range tt.supports {
+				if !s.Supports(m) {
+					t.Errorf("Supports(%q) = false", m)
+				}
+			}
+			for _, m := range tt.rejects {
+				if s.Supports(m) {
+					t.Errorf("Supports(%q) = true", m)
+				}
+			}
+		})
+	}
+}
+
+func newWithDefaultsDriver(sent *bytes.Buffer, caps ...string) *Driver {
+	return &Driver{
+		config:       &types.EquipmentConfig{Address: "10.0.0.1"},
+		connected:    true,
+		capabilities: caps,
+		stdin:        &netconfWriter{writer: sent},
+		stdout:       &netconfReader{reader: newMockReader("<rpc-reply><data/></rpc-reply>]]>]]>")},
+	}
+}
+
+func TestGetWithDefaults(t *testing.T) {
+	const param = `<with-defaults xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-with-defaults">report-all</with-defaults>`
+
+	t.Run("sent when supported", func(t *testing.T) {
+		var sent bytes.Buffer
+		d := newWithDefaultsDriver(&sent, CapWithDefaults+"?basic-mode=explicit&also-supported=report-all")
+		if _, err := d.Get(context.Background(), "<interfaces/>", WithDefaults(WithDefaultsReportAll)); err != nil {
+			t.Fatalf("Get() error: %v", err)
+		}
+		if !strings.Contains(sent.String(), param) {
+			t.Errorf("sent RPC = %s, want with-defaults parameter", sent.String())
+		}
+		if !strings.Contains(sent.String(), "<interfaces/>") {
+			t.Errorf("sent RPC = %s, want filter", sent.String())
+		}
+	})
+
+	t.Run("sent without filter", func(t *testing.T) {
+		var sent bytes.Buffer
+		d := newWithDefaultsDriver(&sent, CapWithDefaults+"?basic-mode=report-all")
+		if _, err := d.Get(context.Background(), "", WithDefaults(WithDefaultsReportAll)); err != nil {
+			t.Fatalf("Get() error: %v", err)
+		}
+		if !strings.Contains(sent.String(), "<get>\n  "+param+"\n</get>") {
+			t.Errorf("sent RPC = %s", sent.String())
+		}
+	})
+
+	t.Run("omitted when unsupported", func(t *testing.T) {
+		var sent bytes.Buffer
+		d := newWithDefaultsDriver(&sent, CapWithDefaults+"?basic-mode=explicit")
+		if _, err := d.Get(context.Background(), "", WithDefaults(WithDefaultsTrim)); err != nil {
+			t.Fatalf("Get() error: %v", err)
+		}
+		if strings.Contains(sent.String(), "with-defaults") || !strings.Contains(sent.String(), "<get/>") {
+			t.Errorf("sent RPC = %s, want plain <get/>", sent.String())
+		}
+	})
+
+	t.Run("omitted without capability", func(t *testing.T) {
+		var sent bytes.Buffer
+		d := newWithDefaultsDriver(&sent, NetconfBase10)
+		if _, err := d.Get(context.Background(), "<interfaces/>", WithDefaults(WithDefaultsReportAll)); err != nil {
+			t.Fatalf("Get() error: %v", err)
+		}
+		if strings.Contains(sent.String(), "with-defaults") {
+			t.Errorf("sent RPC = %s, want no with-defaults", sent.String())
+		}
+	})
+}
+
+func TestGetConfigWithDefaults(t *testing.T) {
+	var sent bytes.Buffer
+	d := newWithDefaultsDriver(&sent, CapWithDefaults+"?basic-mode=report-all&also-supported=trim")
+	if _, err := d.GetConfig(context.Background(), "", "<interfaces/>", WithDefaults(WithDefaultsTrim)); err != nil {
+		t.Fatalf("GetConfig() error: %v", err)
+	}
+	got := sent.String()
+	if !strings.Contains(got, "<running/>") || !strings.Contains(got, ">trim</with-defaults>") {
+		t.Errorf("sent RPC = %s", got)
+	}
+	if strings.Index(got, "</filter>") > strings.Index(got, "<with-defaults") {
+		t.Errorf("with-defaults should follow the filter: %s", got)
+	}
+}
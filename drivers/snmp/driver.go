@@ -3,7 +3,6 @@ package snmp
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"time"
 
 	"github.com/gosnmp/gosnmp"
@@ -15,8 +14,9 @@ import (
 // Note: SNMP is primarily used for monitoring, not configuration
 // Configuration typically requires CLI or other protocol
 type Driver struct {
-	config *types.EquipmentConfig
-	snmp   *gosnmp.GoSNMP
+	config  *types.EquipmentConfig
+	snmp    *gosnmp.GoSNMP
+	metrics types.DriverCounters
 }
 
 // NewDriver creates a new SNMP driver
@@ -49,6 +49,11 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 	if config != nil {
 		d.config = config
 	}
+	resolved, err := types.ResolveCredentials(ctx, d.config)
+	if err != nil {
+		return err
+	}
+	d.config = resolved
 
 	// Get SNMP version from metadata (default v2c)
 	version := gosnmp.Version2c
@@ -65,7 +70,9 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 
 	// Get community string (default: public)
 	community := "public"
-	if c, ok := d.config.Metadata["snmp_community"]; ok {
+	if d.config.SNMPCommunity != "" {
+		community = d.config.SNMPCommunity
+	} else if c, ok := d.config.Metadata["snmp_community"]; ok {
 		community = c
 	}
 
@@ -81,6 +88,8 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 		Version:   version,
 		Timeout:   d.config.Timeout,
 		Retries:   3,
+		OnSent:    func(*gosnmp.GoSNMP) { d.metrics.RecordSNMPPDU() },
+		OnRetry:   func(*gosnmp.GoSNMP) { d.metrics.RecordRetry() },
 	}
 
 	// For SNMPv3, set security parameters
@@ -97,12 +106,12 @@ func (d *Driver) Connect(ctx context.Context, config *types.EquipmentConfig) err
 	}
 
 	// Connect
-	err := snmpClient.Connect()
-	if err != nil {
+	if err := snmpClient.Connect(); err != nil {
 		return fmt.Errorf("failed to connect SNMP: %w", err)
 	}
 
 	d.snmp = snmpClient
+	d.metrics.RecordConnect()
 
 	return nil
 }
@@ -251,13 +260,28 @@ func convertSNMPValue(pdu gosnmp.SnmpPDU) interface{} {
 	}
 }
 
+// get sends an SNMP GET, retrying transient failures per the retry policy
+func (d *Driver) get(ctx context.Context, oids []string) (*gosnmp.SnmpPacket, error) {
+	var result *gosnmp.SnmpPacket
+	err := d.config.RetryPolicy().Do(ctx, func(attempt int) error {
+		if attempt > 1 {
+			d.metrics.RecordRetry()
+		}
+		var err error
+		result, err = d.snmp.Get(oids)
+		d.metrics.RecordCommand(err)
+		return err
+	})
+	return result, err
+}
+
 // getSNMPValue retrieves a single SNMP value
-func (d *Driver) getSNMPValue(oid string) (interface{}, error) {
+func (d *Driver) getSNMPValue(ctx context.Context, oid string) (interface{}, error) {
 	if !d.IsConnected() {
 		return nil, fmt.Errorf("not connected")
 	}
 
-	result, err := d.snmp.Get([]string{oid})
+	result, err := d.get(ctx, []string{oid})
 	if err != nil {
 		return nil, fmt.Errorf("SNMP GET failed: %w", err)
 	}
@@ -271,7 +295,9 @@ func (d *Driver) getSNMPValue(oid string) (interface{}, error) {
 		return nil, fmt.Errorf("no such object for OID %s", oid)
 	}
 
-	return convertSNMPValue(variable), nil
+	value := convertSNMPValue(variable)
+	d.config.Log().Debug("SNMP GET", "oid", oid, "value", value)
+	return value, nil
 }
 
 // HealthCheck performs a health check
@@ -281,7 +307,7 @@ func (d *Driver) HealthCheck(ctx context.Context) error {
 	}
 
 	// Query sysDescr (1.3.6.1.2.1.1.1.0) as health check
-	_, err := d.getSNMPValue("1.3.6.1.2.1.1.1.0")
+	_, err := d.getSNMPValue(ctx, "1.3.6.1.2.1.1.1.0")
 	return err
 }
 
@@ -290,7 +316,7 @@ func (d *Driver) GetSNMP(ctx context.Context, oid string) (interface{}, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	return d.getSNMPValue(oid)
+	return d.getSNMPValue(ctx, oid)
 }
 
 // WalkSNMP implements types.SNMPExecutor - performs SNMP walk
@@ -306,23 +332,30 @@ func (d *Driver) WalkSNMP(ctx context.Context, oid string) (map[string]interface
 		return nil, fmt.Errorf("SNMP connection is closed")
 	}
 
-	results := make(map[string]interface{})
-
-	err := d.snmp.Walk(oid, func(pdu gosnmp.SnmpPDU) error {
-		// Extract the index from the OID (last part after base OID)
-		if len(pdu.Name) <= len(oid)+1 {
-			slog.Debug("SNMP Walk: PDU OID too short to extract index",
-				"oid", oid, "pdu_name", pdu.Name)
-			return nil
+	var results map[string]interface{}
+	err := d.config.RetryPolicy().Do(ctx, func(attempt int) error {
+		if attempt > 1 {
+			d.metrics.RecordRetry()
 		}
-		index := pdu.Name[len(oid)+1:] // Skip base OID and dot
-		results[index] = convertSNMPValue(pdu)
-		return nil
+		results = make(map[string]interface{})
+		err := d.snmp.Walk(oid, func(pdu gosnmp.SnmpPDU) error {
+			// Extract the index from the OID (last part after base OID)
+			if len(pdu.Name) <= len(oid)+1 {
+				d.config.Log().Debug("SNMP Walk: PDU OID too short to extract index",
+					"oid", oid, "pdu_name", pdu.Name)
+				return nil
+			}
+			index := pdu.Name[len(oid)+1:] // Skip base OID and dot
+			results[index] = convertSNMPValue(pdu)
+			return nil
+		})
+		d.metrics.RecordCommand(err)
+		return err
 	})
-
 	if err != nil {
 		return nil, fmt.Errorf("SNMP WALK failed: %w", err)
 	}
+	d.config.Log().Debug("SNMP WALK", "oid", oid, "results", len(results))
 
 	return results, nil
 }
@@ -336,7 +369,7 @@ func (d *Driver) BulkGetSNMP(ctx context.Context, oids []string) (map[string]int
 		return nil, fmt.Errorf("not connected")
 	}
 
-	result, err := d.snmp.Get(oids)
+	result, err := d.get(ctx, oids)
 	if err != nil {
 		return nil, fmt.Errorf("SNMP GET failed: %w", err)
 	}
@@ -345,9 +378,15 @@ func (d *Driver) BulkGetSNMP(ctx context.Context, oids []string) (map[string]int
 	for _, variable := range result.Variables {
 		results[variable.Name] = convertSNMPValue(variable)
 	}
+	d.config.Log().Debug("SNMP GET", "oids", oids, "values", results)
 
 	return results, nil
 }
 
+// DriverMetrics implements types.MetricsProvider
+func (d *Driver) DriverMetrics() types.DriverMetrics {
+	return d.metrics.Snapshot()
+}
+
 // Ensure Driver implements SNMPExecutor
 var _ types.SNMPExecutor = (*Driver)(nil)
@@ -660,7 +660,7 @@ func TestGetSNMPValueNotConnected(t *testing.T) {
 		snmp:   nil,
 	}
 
-	_, err := d.getSNMPValue("1.3.6.1.2.1.1.1.0")
+	_, err := d.getSNMPValue(context.Background(), "1.3.6.1.2.1.1.1.0")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -0,0 +1,160 @@
+// Package events is an in-process event bus. Subsystems publish events
+// about the devices they manage, and subscribers receive the event types
+// they registered for.
+//
+// Tracker turns polled ONU, alarm and optical snapshots into state change
+// events, and Webhook delivers events to external systems over HTTP.
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Type identifies a kind of event, e.g. "config.drift"
+type Type string
+
+// Event types published by this module
+const (
+	// TypeConfigDrift is published per configuration section that differs
+	// from the device's golden configuration
+	TypeConfigDrift Type = "config.drift"
+
+	// TypeONUUp and TypeONUDown are published when an ONU goes online or
+	// offline
+	TypeONUUp   Type = "onu.up"
+	TypeONUDown Type = "onu.down"
+
+	// TypeONUStateChanged is published by the lifecycle tracker for every
+	// ONU state transition, with durations and flap detection
+	TypeONUStateChanged Type = "onu.state_changed"
+
+	// TypeONUDiscovered is published when an unprovisioned ONU appears in
+	// the autofind list of an OLT
+	TypeONUDiscovered Type = "onu.discovered"
+
+	// TypeAlarmRaised and TypeAlarmCleared are published when an OLT alarm
+	// appears in or disappears from the active alarm list
+	TypeAlarmRaised  Type = "alarm.raised"
+	TypeAlarmCleared Type = "alarm.cleared"
+
+	// TypeProvisioningSucceeded and TypeProvisioningFailed report the
+	// outcome of a subscriber provisioning operation
+	TypeProvisioningSucceeded Type = "provisioning.succeeded"
+	TypeProvisioningFailed    Type = "provisioning.failed"
+
+	// TypeThresholdCrossed is published when an optical level leaves or
+	// returns to its normal range
+	TypeThresholdCrossed Type = "threshold.crossed"
+
+	// TypeDeviceHealthChanged is published by the health prober when a
+	// device moves between healthy, degraded and unreachable
+	TypeDeviceHealthChanged Type = "device.health_changed"
+
+	// TypeRogueONUDetected and TypeRogueONUCleared are published by the
+	// rogue ONU detector when a rogue ONU is first seen and when it is gone
+	TypeRogueONUDetected Type = "onu.rogue_detected"
+	TypeRogueONUCleared  Type = "onu.rogue_cleared"
+
+	// TypeONUDyingGasp is published by the outage correlator for a dying
+	// gasp not part of a probable power outage
+	TypeONUDyingGasp Type = "onu.dying_gasp"
+
+	// TypePowerOutage is published by the outage correlator for a cluster
+	// of dying gasps on one PON port
+	TypePowerOutage Type = "power.outage"
+)
+
+// Event is something that happened on a device
+type Event struct {
+	ID     string         `json:"id"`
+	Type   Type           `json:"type"`
+	Device string         `json:"device"`
+	Time   time.Time      `json:"time"`
+	Data   map[string]any `json:"data,omitempty"`
+}
+
+// Handler receives events. Handlers are called synchronously by Publish
+// and must not block; slow consumers should queue internally.
+type Handler func(Event)
+
+// Publisher is implemented by Bus. Subsystems depend on Publisher so they
+// can be used without a bus.
+type Publisher interface {
+	Publish(e Event)
+}
+
+// Bus dispatches published events to subscribers. The zero value is not
+// usable; create one with NewBus.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[int]subscription
+	next int
+}
+
+type subscription struct {
+	handler Handler
+	types   map[Type]bool // nil matches every type
+}
+
+// NewBus creates an empty Bus
+func NewBus() *Bus {
+	return &Bus{subs: make(map[int]subscription)}
+}
+
+// Subscribe registers h for the given event types, or for every event if
+// none are given. The returned function removes the subscription.
+func (b *Bus) Subscribe(h Handler, types ...Type) (unsubscribe func()) {
+	sub := subscription{handler: h}
+	if len(types) > 0 {
+		sub.types = make(map[Type]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	id := b.next
+	b.next++
+	b.subs[id] = sub
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		delete(b.subs, id)
+		b.mu.Unlock()
+	}
+}
+
+// Publish delivers e to every matching subscriber. ID and Time are set if
+// empty.
+func (b *Bus) Publish(e Event) {
+	if e.ID == "" {
+		e.ID = newID()
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.subs))
+	for _, sub := range b.subs {
+		if sub.types == nil || sub.types[e.Type] {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(e)
+	}
+}
+
+// newID returns a random 128-bit hex ID
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,35 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestBusSubscribe(t *testing.T) {
+	bus := NewBus()
+
+	var all, drift []Event
+	bus.Subscribe(func(e Event) { all = append(all, e) })
+	unsubscribe := bus.Subscribe(func(e Event) { drift = append(drift, e) }, TypeConfigDrift)
+
+	bus.Publish(Event{Type: TypeConfigDrift, Device: "olt-1"})
+	bus.Publish(Event{Type: "other", Device: "olt-1"})
+
+	if len(all) != 2 {
+		t.Errorf("unfiltered subscriber got %d events, want 2", len(all))
+	}
+	if len(drift) != 1 || drift[0].Type != TypeConfigDrift {
+		t.Errorf("filtered subscriber got %+v", drift)
+	}
+	if drift[0].ID == "" || drift[0].Time.IsZero() {
+		t.Errorf("Publish should set ID and Time, got %+v", drift[0])
+	}
+
+	unsubscribe()
+	bus.Publish(Event{Type: TypeConfigDrift})
+	if len(drift) != 1 {
+		t.Error("unsubscribed handler still called")
+	}
+	if len(all) != 3 {
+		t.Errorf("unfiltered subscriber got %d events, want 3", len(all))
+	}
+}
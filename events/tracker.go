@@ -0,0 +1,307 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nanoncore/nano-southbound/alarms"
+	"github.com/nanoncore/nano-southbound/collector"
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/thresholds"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Tracker compares successive snapshots of each device and publishes an
+// event for every change: ONUs going online or offline, alarms raised or
+// cleared, and optical and environmental levels crossing their warning or
+// critical thresholds.
+//
+// The first snapshot of each kind from a device is the baseline and
+// publishes nothing, so restarting a Tracker does not replay the current
+// state as events.
+type Tracker struct {
+	publisher  Publisher
+	engine     *alarms.Engine
+	thresholds *thresholds.Engine
+
+	mu          sync.Mutex
+	onus        map[string]map[string]bool // device -> ONU key -> online
+	alarmsSeen  map[string]bool            // devices with an alarm baseline
+	opticalSeen map[string]bool            // devices with an optical baseline
+	envSeen     map[string]bool            // devices with an environment baseline
+	discovered  map[string]map[string]bool // device -> ONU key -> in autofind
+}
+
+// TrackerConfig configures the alarm and optical threshold engines of a
+// Tracker. The zero value uses their defaults.
+type TrackerConfig struct {
+	Alarms     alarms.Config
+	Thresholds thresholds.Config
+}
+
+// NewTracker creates a Tracker publishing to p
+func NewTracker(p Publisher, config TrackerConfig) *Tracker {
+	return &Tracker{
+		publisher:   p,
+		onus:        make(map[string]map[string]bool),
+		engine:      alarms.NewEngine(config.Alarms),
+		alarmsSeen:  make(map[string]bool),
+		thresholds:  thresholds.NewEngine(config.Thresholds),
+		opticalSeen: make(map[string]bool),
+		envSeen:     make(map[string]bool),
+		discovered:  make(map[string]map[string]bool),
+	}
+}
+
+// Observe feeds a collector sample to the tracker. It can be passed
+// directly as a collector.Handler. Failed samples are ignored.
+func (t *Tracker) Observe(s collector.Sample) {
+	if s.Err != nil {
+		return
+	}
+	switch s.Class {
+	case collector.MetricONUList:
+		t.ONUs(s.Device, s.ONUs)
+	case collector.MetricAlarms:
+		// GetAlarms is CLI-based on every adapter that implements it
+		t.Alarms(s.Device, s.Vendor, alarms.SourceCLI, s.Alarms)
+	case collector.MetricOptical:
+		t.Optical(s.Device, s.Optical)
+	case collector.MetricDiscovery:
+		t.Discovered(s.Device, s.Discovered)
+	case collector.MetricEnvironment:
+		t.Environment(s.Device, s.Vendor, s.Environment)
+	}
+}
+
+// ONUs records the ONU list of device and publishes TypeONUUp and
+// TypeONUDown for ONUs whose online state changed. An ONU that appears
+// online is up; ONUs that disappear from the list were deprovisioned and
+// are forgotten.
+func (t *Tracker) ONUs(device string, onus []types.ONUInfo) {
+	t.mu.Lock()
+	prev, seen := t.onus[device]
+	cur := make(map[string]bool, len(onus))
+	var out []Event
+	for _, onu := range onus {
+		key := fmt.Sprintf("%s:%d", onu.PONPort, onu.ONUID)
+		cur[key] = onu.IsOnline
+		online, known := prev[key]
+		if !seen || online == onu.IsOnline || (!known && !onu.IsOnline) {
+			continue
+		}
+		typ := TypeONUDown
+		if onu.IsOnline {
+			typ = TypeONUUp
+		}
+		out = append(out, Event{Type: typ, Device: device, Data: map[string]any{
+			"pon_port":   onu.PONPort,
+			"onu_id":     onu.ONUID,
+			"serial":     onu.Serial,
+			"oper_state": onu.OperState,
+		}})
+	}
+	t.onus[device] = cur
+	t.mu.Unlock()
+	t.publish(out)
+}
+
+// Discovered records the autofind list of device and publishes
+// TypeONUDiscovered for the ONUs that were not in the previous list. Unlike
+// the other snapshots, the first list is not a silent baseline: an ONU
+// waiting in autofind is something to act on, so every ONU in it is
+// published.
+func (t *Tracker) Discovered(device string, discovered []types.ONUDiscovery) {
+	t.mu.Lock()
+	prev := t.discovered[device]
+	cur := make(map[string]bool, len(discovered))
+	var out []Event
+	for _, onu := range discovered {
+		key := onu.PONPort + ":" + strings.ToUpper(onu.Serial)
+		cur[key] = true
+		if prev[key] {
+			continue
+		}
+		out = append(out, Event{Type: TypeONUDiscovered, Device: device, Data: map[string]any{
+			"pon_port": onu.PONPort,
+			"serial":   onu.Serial,
+			"mac":      onu.MAC,
+			"model":    onu.Model,
+			"vendor":   onu.Vendor,
+		}})
+	}
+	t.discovered[device] = cur
+	t.mu.Unlock()
+	t.publish(out)
+}
+
+// Alarms records the active alarms of device as polled from source.
+// Alarms are normalized and deduplicated with an alarms.Engine;
+// TypeAlarmRaised is published for new alarms and TypeAlarmCleared, with
+// the same correlation ID, once no source reports them.
+func (t *Tracker) Alarms(device string, vendor types.Vendor, source alarms.Source, list []types.OLTAlarm) {
+	t.mu.Lock()
+	seen := t.alarmsSeen[device]
+	t.alarmsSeen[device] = true
+	t.mu.Unlock()
+
+	var out []Event
+	for _, tr := range t.engine.Observe(device, vendor, source, list) {
+		if seen || tr.Cleared {
+			out = append(out, alarmEvent(tr))
+		}
+	}
+	t.publish(out)
+}
+
+// AlarmRaised records an alarm reported by an event source, e.g. a trap
+func (t *Tracker) AlarmRaised(device string, vendor types.Vendor, source alarms.Source, a types.OLTAlarm) {
+	t.publishAlarms(t.engine.Raise(device, vendor, source, a))
+}
+
+// AlarmCleared records the clear of an alarm by an event source
+func (t *Tracker) AlarmCleared(device string, vendor types.Vendor, source alarms.Source, a types.OLTAlarm) {
+	t.publishAlarms(t.engine.Clear(device, vendor, source, a))
+}
+
+// ActiveAlarms returns the active normalized alarms of device
+func (t *Tracker) ActiveAlarms(device string) []alarms.Active {
+	return t.engine.Active(device)
+}
+
+func (t *Tracker) publishAlarms(transitions []alarms.Transition) {
+	out := make([]Event, 0, len(transitions))
+	for _, tr := range transitions {
+		out = append(out, alarmEvent(tr))
+	}
+	t.publish(out)
+}
+
+func alarmEvent(tr alarms.Transition) Event {
+	a := tr.Alarm
+	e := Event{Type: TypeAlarmRaised, Device: a.Device, Time: a.RaisedAt, Data: map[string]any{
+		"correlation_id": a.CorrelationID,
+		"kind":           string(a.Kind),
+		"severity":       string(a.Severity),
+		"object":         a.Object,
+		"message":        a.Message,
+		"sources":        a.Sources,
+		"raised_at":      a.RaisedAt,
+		"vendor_type":    a.Raw.Type,
+	}}
+	if tr.Cleared {
+		e.Type = TypeAlarmCleared
+		e.Time = *a.ClearedAt
+		e.Data["cleared_at"] = *a.ClearedAt
+	}
+	return e
+}
+
+// Optical evaluates optical readings of device, keyed as returned by
+// GetBulkONUOpticalSNMP, and publishes TypeThresholdCrossed when a metric
+// changes level
+func (t *Tracker) Optical(device string, readings map[string]*types.ONUPowerReading) {
+	t.mu.Lock()
+	seen := t.opticalSeen[device]
+	t.opticalSeen[device] = true
+	t.mu.Unlock()
+
+	alerts := t.thresholds.EvaluateONUs(device, readings)
+	if seen {
+		t.publishAlerts(alerts)
+	}
+}
+
+// PONPower evaluates a PON port reading of device, e.g. from GetPONPower,
+// and publishes TypeThresholdCrossed when a metric changes level
+func (t *Tracker) PONPower(device string, reading *types.PONPowerReading) {
+	t.publishAlerts(t.thresholds.EvaluatePON(device, reading))
+}
+
+// Environment records the environment of device, e.g. from GetEnvironment.
+// Failed fans and power supplies are raised as alarms from
+// alarms.SourceEnvironment and cleared when they recover; temperatures and
+// fan speeds publish TypeThresholdCrossed when they change level.
+func (t *Tracker) Environment(device string, vendor types.Vendor, env *types.EnvironmentStatus) {
+	if env == nil {
+		return
+	}
+	t.mu.Lock()
+	seen := t.envSeen[device]
+	t.envSeen[device] = true
+	t.mu.Unlock()
+
+	var out []Event
+	for _, tr := range t.engine.Observe(device, vendor, alarms.SourceEnvironment, env.Alarms()) {
+		if seen || tr.Cleared {
+			out = append(out, alarmEvent(tr))
+		}
+	}
+	t.publish(out)
+
+	alerts := t.thresholds.EvaluateEnvironment(device, env)
+	if seen {
+		t.publishAlerts(alerts)
+	}
+}
+
+func (t *Tracker) publishAlerts(alerts []thresholds.Alert) {
+	out := make([]Event, 0, len(alerts))
+	for _, a := range alerts {
+		out = append(out, Event{Type: TypeThresholdCrossed, Device: a.Device, Data: map[string]any{
+			"object":    a.Object,
+			"pon_port":  a.PONPort,
+			"onu_id":    a.ONUID,
+			"serial":    a.Serial,
+			"metric":    string(a.Metric),
+			"value":     a.Value,
+			"level":     a.Level.String(),
+			"previous":  a.Previous.String(),
+			"direction": a.Direction,
+			"limits":    a.Limits,
+		}})
+	}
+	t.publish(out)
+}
+
+// Forget drops the state of device, e.g. when it is removed
+func (t *Tracker) Forget(device string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.onus, device)
+	delete(t.alarmsSeen, device)
+	t.engine.Forget(device)
+	delete(t.opticalSeen, device)
+	delete(t.envSeen, device)
+	t.thresholds.Forget(device)
+	delete(t.discovered, device)
+}
+
+// publish is called without the lock held, so handlers may call back into
+// the Tracker
+func (t *Tracker) publish(events []Event) {
+	for _, e := range events {
+		t.publisher.Publish(e)
+	}
+}
+
+// PublishProvisioning publishes TypeProvisioningSucceeded or
+// TypeProvisioningFailed for a provisioning operation on a subscriber,
+// e.g.
+//
+//	_, err := driver.CreateSubscriber(ctx, sub, tier)
+//	events.PublishProvisioning(bus, config.Name, "CreateSubscriber", sub, err)
+func PublishProvisioning(p Publisher, device, operation string, subscriber *model.Subscriber, err error) {
+	data := map[string]any{"operation": operation}
+	if subscriber != nil {
+		data["subscriber"] = subscriber.Name
+		data["serial"] = subscriber.Spec.ONUSerial
+	}
+	typ := TypeProvisioningSucceeded
+	if err != nil {
+		typ = TypeProvisioningFailed
+		data["error"] = err.Error()
+	}
+	p.Publish(Event{Type: typ, Device: device, Data: data})
+}
@@ -0,0 +1,202 @@
+package events
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/alarms"
+	"github.com/nanoncore/nano-southbound/collector"
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+type recorder struct{ events []Event }
+
+func (r *recorder) Publish(e Event) { r.events = append(r.events, e) }
+
+func (r *recorder) take() []Event {
+	out := r.events
+	r.events = nil
+	return out
+}
+
+func TestTrackerONUs(t *testing.T) {
+	rec := &recorder{}
+	tr := NewTracker(rec, TrackerConfig{})
+
+	tr.ONUs("olt-1", []types.ONUInfo{
+		{PONPort: "0/1", ONUID: 1, Serial: "A", IsOnline: true},
+		{PONPort: "0/1", ONUID: 2, Serial: "B", IsOnline: false},
+	})
+	if got := rec.take(); len(got) != 0 {
+		t.Fatalf("baseline published %+v", got)
+	}
+
+	tr.ONUs("olt-1", []types.ONUInfo{
+		{PONPort: "0/1", ONUID: 1, Serial: "A", IsOnline: false, OperState: "los"},
+		{PONPort: "0/1", ONUID: 2, Serial: "B", IsOnline: true},
+		{PONPort: "0/1", ONUID: 3, Serial: "C", IsOnline: false},
+		{PONPort: "0/1", ONUID: 4, Serial: "D", IsOnline: true},
+	})
+	got := rec.take()
+	want := map[string]Type{"A": TypeONUDown, "B": TypeONUUp, "D": TypeONUUp}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %v", got, want)
+	}
+	for _, e := range got {
+		if serial := e.Data["serial"].(string); want[serial] != e.Type || e.Device != "olt-1" {
+			t.Errorf("unexpected event %+v", e)
+		}
+	}
+}
+
+func TestTrackerAlarms(t *testing.T) {
+	rec := &recorder{}
+	tr := NewTracker(rec, TrackerConfig{})
+	los := types.OLTAlarm{ID: "1", Type: "los", Source: "onu", SourceID: "0/1/1:1", Severity: "critical"}
+	fan := types.OLTAlarm{ID: "2", Type: "fan", Source: "system", Severity: "major"}
+
+	tr.Alarms("olt-1", types.VendorHuawei, alarms.SourceCLI, []types.OLTAlarm{los})
+	tr.Alarms("olt-1", types.VendorHuawei, alarms.SourceCLI, []types.OLTAlarm{los, fan})
+	tr.Alarms("olt-1", types.VendorHuawei, alarms.SourceCLI, []types.OLTAlarm{fan})
+
+	got := rec.take()
+	if len(got) != 2 {
+		t.Fatalf("got %+v, want raise and clear", got)
+	}
+	if got[0].Type != TypeAlarmRaised || got[0].Data["kind"] != "fan_failure" {
+		t.Errorf("first event = %+v, want fan raised", got[0])
+	}
+	if got[1].Type != TypeAlarmCleared || got[1].Data["kind"] != "los" || got[1].Data["object"] != "0/1/1:1" {
+		t.Errorf("second event = %+v, want los cleared", got[1])
+	}
+
+	// A trap for the active fan alarm is merged; clearing the trap leaves
+	// it active while the CLI still reports it
+	tr.AlarmRaised("olt-1", types.VendorHuawei, alarms.SourceTrap, fan)
+	tr.AlarmCleared("olt-1", types.VendorHuawei, alarms.SourceTrap, fan)
+	if got := rec.take(); len(got) != 0 {
+		t.Errorf("duplicate trap published %+v", got)
+	}
+	if active := tr.ActiveAlarms("olt-1"); len(active) != 1 || active[0].Kind != alarms.KindFanFailure {
+		t.Errorf("active = %+v", active)
+	}
+}
+
+func TestTrackerOptical(t *testing.T) {
+	rec := &recorder{}
+	tr := NewTracker(rec, TrackerConfig{})
+	reading := func(rx float64) map[string]*types.ONUPowerReading {
+		return map[string]*types.ONUPowerReading{"0/1:1": {PONPort: "0/1", ONUID: 1, RxPowerDBm: rx, TxPowerDBm: 2}}
+	}
+
+	tr.Optical("olt-1", reading(-30)) // baseline, already critical
+	tr.Optical("olt-1", reading(-31))
+	if got := rec.take(); len(got) != 0 {
+		t.Fatalf("no crossing expected, got %+v", got)
+	}
+
+	tr.Optical("olt-1", reading(-20))
+	tr.Optical("olt-1", reading(-8.5))
+	got := rec.take()
+	if len(got) != 2 {
+		t.Fatalf("got %+v, want two crossings", got)
+	}
+	if got[0].Data["level"] != "normal" || got[0].Data["previous"] != "critical" {
+		t.Errorf("first crossing = %+v", got[0].Data)
+	}
+	if got[1].Data["level"] != "warning" || got[1].Data["direction"] != "high" || got[1].Data["metric"] != "onu_rx_power_dbm" {
+		t.Errorf("second crossing = %+v", got[1].Data)
+	}
+
+	tr.PONPower("olt-1", &types.PONPowerReading{PONPort: "0/1", TxPowerDBm: 3, Temperature: 85})
+	if got := rec.take(); len(got) != 1 || got[0].Data["metric"] != "pon_temperature_c" || got[0].Data["level"] != "critical" {
+		t.Errorf("PON alert = %+v", got)
+	}
+}
+
+func TestTrackerEnvironment(t *testing.T) {
+	rec := &recorder{}
+	tr := NewTracker(rec, TrackerConfig{})
+	env := func(fanStatus string, celsius float64) *types.EnvironmentStatus {
+		return &types.EnvironmentStatus{
+			Fans:          []types.FanStatus{{Name: "0", Status: fanStatus, Normal: fanStatus == "Normal"}},
+			PowerSupplies: []types.PowerSupplyStatus{{Name: "0/18", Status: "Normal", Normal: true}},
+			Temperatures:  []types.TemperatureSensor{{Name: "0/1", Celsius: celsius}},
+		}
+	}
+
+	tr.Environment("olt-1", types.VendorHuawei, env("Normal", 40)) // baseline
+	tr.Environment("olt-1", types.VendorHuawei, env("Failed", 70))
+	got := rec.take()
+	if len(got) != 2 {
+		t.Fatalf("got %+v, want fan raised and temperature crossing", got)
+	}
+	if got[0].Type != TypeAlarmRaised || got[0].Data["kind"] != "fan_failure" || got[0].Data["object"] != "fan 0" {
+		t.Errorf("first event = %+v, want fan raised", got[0])
+	}
+	if got[1].Type != TypeThresholdCrossed || got[1].Data["metric"] != "sensor_temperature_c" || got[1].Data["level"] != "warning" {
+		t.Errorf("second event = %+v, want temperature warning", got[1])
+	}
+
+	// The fan alarm is kept apart from the CLI alarm list
+	tr.Alarms("olt-1", types.VendorHuawei, alarms.SourceCLI, nil)
+	tr.Alarms("olt-1", types.VendorHuawei, alarms.SourceCLI, nil)
+	if got := rec.take(); len(got) != 0 {
+		t.Errorf("CLI alarms published %+v", got)
+	}
+	tr.Observe(collector.Sample{Device: "olt-1", Class: collector.MetricEnvironment, Environment: env("Normal", 40)})
+	got = rec.take()
+	if len(got) != 2 || got[0].Type != TypeAlarmCleared || got[1].Data["level"] != "normal" {
+		t.Errorf("got %+v, want fan cleared and temperature back to normal", got)
+	}
+}
+
+func TestTrackerObserve(t *testing.T) {
+	rec := &recorder{}
+	tr := NewTracker(rec, TrackerConfig{})
+
+	tr.Observe(collector.Sample{Device: "olt-1", Class: collector.MetricONUList, ONUs: []types.ONUInfo{{ONUID: 1, IsOnline: true}}})
+	tr.Observe(collector.Sample{Device: "olt-1", Class: collector.MetricONUList, Err: errors.New("timeout")})
+	tr.Observe(collector.Sample{Device: "olt-1", Class: collector.MetricONUList, ONUs: []types.ONUInfo{{ONUID: 1}}})
+
+	if got := rec.take(); len(got) != 1 || got[0].Type != TypeONUDown {
+		t.Errorf("got %+v, want one onu.down", got)
+	}
+}
+
+func TestTrackerDiscovered(t *testing.T) {
+	rec := &recorder{}
+	tr := NewTracker(rec, TrackerConfig{})
+
+	tr.Observe(collector.Sample{Device: "olt-1", Class: collector.MetricDiscovery, Discovered: []types.ONUDiscovery{{PONPort: "0/1", Serial: "FHTT00000001"}}})
+	if got := rec.take(); len(got) != 1 || got[0].Type != TypeONUDiscovered || got[0].Data["serial"] != "FHTT00000001" {
+		t.Errorf("first list = %+v, want its ONU published", got)
+	}
+	tr.Discovered("olt-1", []types.ONUDiscovery{{PONPort: "0/1", Serial: "fhtt00000001"}, {PONPort: "0/2", Serial: "FHTT00000002"}})
+	if got := rec.take(); len(got) != 1 || got[0].Data["pon_port"] != "0/2" {
+		t.Errorf("second list = %+v, want only the new ONU", got)
+	}
+	// An ONU that left autofind and came back is published again
+	tr.Discovered("olt-1", nil)
+	tr.Discovered("olt-1", []types.ONUDiscovery{{PONPort: "0/1", Serial: "FHTT00000001"}})
+	if got := rec.take(); len(got) != 1 {
+		t.Errorf("reappeared = %+v", got)
+	}
+}
+
+func TestPublishProvisioning(t *testing.T) {
+	rec := &recorder{}
+	sub := &model.Subscriber{Name: "sub-1", Spec: model.SubscriberSpec{ONUSerial: "HWTC00000001"}}
+
+	PublishProvisioning(rec, "olt-1", "CreateSubscriber", sub, nil)
+	PublishProvisioning(rec, "olt-1", "CreateSubscriber", sub, errors.New("ONT already exists"))
+
+	got := rec.take()
+	if got[0].Type != TypeProvisioningSucceeded || got[0].Data["serial"] != "HWTC00000001" {
+		t.Errorf("success event = %+v", got[0])
+	}
+	if got[1].Type != TypeProvisioningFailed || got[1].Data["error"] != "ONT already exists" {
+		t.Errorf("failure event = %+v", got[1])
+	}
+}
@@ -0,0 +1,273 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers set on webhook requests
+const (
+	HeaderEvent     = "X-Nano-Event"
+	HeaderDelivery  = "X-Nano-Delivery"
+	HeaderTimestamp = "X-Nano-Timestamp"
+	HeaderSignature = "X-Nano-Signature"
+)
+
+// Defaults applied by NewWebhook for zero WebhookConfig fields
+const (
+	DefaultWebhookTimeout = 10 * time.Second
+	DefaultMaxRetries     = 5
+	DefaultRetryBackoff   = time.Second
+	DefaultMaxBackoff     = time.Minute
+	DefaultQueueSize      = 1024
+)
+
+// ErrQueueFull is passed to OnFailure for events dropped because the
+// delivery queue is full
+var ErrQueueFull = errors.New("webhook queue is full")
+
+// ErrClosed is passed to OnFailure for events published after Close
+var ErrClosed = errors.New("webhook is closed")
+
+// WebhookConfig configures a Webhook
+type WebhookConfig struct {
+	// URL receives each event as a JSON POST
+	URL string
+
+	// Secret signs requests with HMAC-SHA256 in HeaderSignature. Requests
+	// are not signed if empty.
+	Secret []byte
+
+	// Timeout bounds one delivery attempt. Defaults to
+	// DefaultWebhookTimeout.
+	Timeout time.Duration
+
+	// MaxRetries is the number of retries after a failed attempt. Defaults
+	// to DefaultMaxRetries; negative disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the wait before the first retry, doubled after each
+	// further attempt up to MaxBackoff. Defaults to DefaultRetryBackoff and
+	// DefaultMaxBackoff.
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+
+	// QueueSize is the number of events buffered for delivery. Defaults to
+	// DefaultQueueSize.
+	QueueSize int
+
+	// Client sends the requests. Defaults to a client with Timeout.
+	Client *http.Client
+
+	// OnFailure is called for events that could not be delivered
+	OnFailure func(e Event, err error)
+}
+
+// Webhook delivers events to an HTTP endpoint. Publish queues the event and
+// returns immediately; a background goroutine posts events in order,
+// retrying network errors, 429 and 5xx responses with exponential backoff.
+// Other responses are permanent failures.
+//
+// Receivers verify requests with VerifySignature.
+type Webhook struct {
+	config WebhookConfig
+	queue  chan Event
+	done   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+var _ Publisher = (*Webhook)(nil)
+
+// NewWebhook creates a Webhook and starts its delivery goroutine. Call Close
+// to stop it.
+func NewWebhook(config WebhookConfig) *Webhook {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultWebhookTimeout
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = DefaultMaxRetries
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = DefaultRetryBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: config.Timeout}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Webhook{
+		config: config,
+		queue:  make(chan Event, config.QueueSize),
+		done:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	go w.run()
+	return w
+}
+
+// Publish queues e for delivery. It can be passed to Bus.Subscribe as a
+// Handler. ID and Time are set if empty. Events are dropped with
+// ErrQueueFull if the queue is full.
+func (w *Webhook) Publish(e Event) {
+	if e.ID == "" {
+		e.ID = newID()
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		w.fail(e, ErrClosed)
+		return
+	}
+	select {
+	case w.queue <- e:
+	default:
+		w.fail(e, ErrQueueFull)
+	}
+}
+
+// Close stops accepting events and waits for the queued ones to be
+// delivered. If ctx ends first, pending deliveries are abandoned and
+// ctx.Err() is returned.
+func (w *Webhook) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.cancel()
+		<-w.done
+		return ctx.Err()
+	}
+}
+
+func (w *Webhook) run() {
+	defer close(w.done)
+	defer w.cancel()
+	for e := range w.queue {
+		if w.ctx.Err() != nil {
+			w.fail(e, w.ctx.Err())
+			continue
+		}
+		if err := w.deliver(e); err != nil {
+			w.fail(e, err)
+		}
+	}
+}
+
+// deliver posts e, retrying transient failures
+func (w *Webhook) deliver(e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	backoff := w.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(e, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.config.MaxRetries {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-w.ctx.Done():
+			return err
+		}
+		backoff = min(2*backoff, w.config.MaxBackoff)
+	}
+}
+
+// post makes one delivery attempt and reports whether a failure is worth
+// retrying
+func (w *Webhook) post(e Event, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(w.ctx, w.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(e.Type))
+	req.Header.Set(HeaderDelivery, e.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if len(w.config.Secret) > 0 {
+		req.Header.Set(HeaderSignature, Sign(w.config.Secret, timestamp, body))
+	}
+
+	resp, err := w.config.Client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("webhook request failed: %w", err)
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024)) //nolint:errcheck // drain for connection reuse
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+}
+
+func (w *Webhook) fail(e Event, err error) {
+	if w.config.OnFailure != nil {
+		w.config.OnFailure(e, err)
+	}
+}
+
+// Sign returns the HeaderSignature value for a request body sent at
+// timestamp: "sha256=" and the hex HMAC-SHA256 of "timestamp.body"
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks the signature of a webhook request. Receivers
+// should also reject timestamps too far from the current time to prevent
+// replays.
+func VerifySignature(secret []byte, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
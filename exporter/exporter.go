@@ -0,0 +1,427 @@
+// Package exporter exposes OLT and ONU telemetry as Prometheus metrics.
+//
+// Data is pushed in with the Set methods (or Observe, for collector
+// samples) and rendered in the Prometheus text exposition format on
+// scrape. Each Set call replaces the previous data for that OLT, so ONUs
+// and ports that disappear stop being reported.
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nanoncore/nano-southbound/collector"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// ContentType is the Prometheus text exposition format content type
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultNamespace prefixes every metric name
+const DefaultNamespace = "southbound"
+
+// Exporter holds the latest telemetry per OLT. It is safe for concurrent
+// use.
+type Exporter struct {
+	namespace string
+
+	mu   sync.RWMutex
+	olts map[string]*oltData
+}
+
+// oltData is the latest data reported for one OLT
+type oltData struct {
+	vendor  string
+	status  *types.OLTStatus
+	ports   []*types.PONPortStatus
+	onus    []types.ONUInfo
+	optical []*types.ONUPowerReading
+
+	// driver is scraped for operational counters; metrics holds the last
+	// snapshot from a collector sample when no driver is registered
+	driver  types.MetricsProvider
+	metrics *types.DriverMetrics
+
+	collectionErrors map[collector.MetricClass]uint64
+	collectionTime   map[collector.MetricClass]float64
+}
+
+// New creates an Exporter. An empty namespace uses DefaultNamespace.
+func New(namespace string) *Exporter {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	return &Exporter{
+		namespace: namespace,
+		olts:      make(map[string]*oltData),
+	}
+}
+
+// olt returns the data for olt, creating it if needed. Callers hold e.mu.
+func (e *Exporter) olt(olt, vendor string) *oltData {
+	d, ok := e.olts[olt]
+	if !ok {
+		d = &oltData{
+			collectionErrors: make(map[collector.MetricClass]uint64),
+			collectionTime:   make(map[collector.MetricClass]float64),
+		}
+		e.olts[olt] = d
+	}
+	if vendor != "" {
+		d.vendor = vendor
+	}
+	return d
+}
+
+// SetOLTStatus records OLT status, including its PON ports unless
+// SetPONPorts has been called for olt
+func (e *Exporter) SetOLTStatus(olt, vendor string, status *types.OLTStatus) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.olt(olt, vendor).status = status
+}
+
+// SetPONPorts records PON port status, e.g. from ListPorts
+func (e *Exporter) SetPONPorts(olt, vendor string, ports []*types.PONPortStatus) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.olt(olt, vendor).ports = ports
+}
+
+// SetONUs records the ONU list of olt
+func (e *Exporter) SetONUs(olt, vendor string, onus []types.ONUInfo) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.olt(olt, vendor).onus = onus
+}
+
+// SetONUPower records ONU optical readings. They take precedence over the
+// power levels in the ONU list.
+func (e *Exporter) SetONUPower(olt, vendor string, readings []*types.ONUPowerReading) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.olt(olt, vendor).optical = readings
+}
+
+// SetDriver registers the driver of olt. Its operational counters are read
+// on every scrape.
+func (e *Exporter) SetDriver(olt, vendor string, driver types.MetricsProvider) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.olt(olt, vendor).driver = driver
+}
+
+// Remove drops every metric for olt
+func (e *Exporter) Remove(olt string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.olts, olt)
+}
+
+// Observe records a collector sample. It can be passed directly as a
+// collector.Handler.
+func (e *Exporter) Observe(s collector.Sample) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	d := e.olt(s.Device, string(s.Vendor))
+	d.collectionTime[s.Class] = s.Duration.Seconds()
+	if s.DriverMetrics != nil {
+		d.metrics = s.DriverMetrics
+	}
+	if s.Err != nil {
+		d.collectionErrors[s.Class]++
+		return
+	}
+
+	switch s.Class {
+	case collector.MetricONUList:
+		d.onus = s.ONUs
+	case collector.MetricOLTStatus:
+		d.status = s.OLTStatus
+	case collector.MetricOptical:
+		keys := make([]string, 0, len(s.Optical))
+		for k := range s.Optical {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		d.optical = make([]*types.ONUPowerReading, 0, len(keys))
+		for _, k := range keys {
+			if r := s.Optical[k]; r != nil {
+				d.optical = append(d.optical, r)
+			}
+		}
+	}
+}
+
+// ServeHTTP serves the metrics, so an Exporter can be mounted at /metrics
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_, _ = e.WriteTo(w) //nolint:errcheck // client went away
+}
+
+// Handler returns an http.Handler serving the metrics
+func (e *Exporter) Handler() http.Handler {
+	return e
+}
+
+// WriteTo writes every metric in the Prometheus text format
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	e.mu.RLock()
+	set := e.gather()
+	e.mu.RUnlock()
+
+	var b strings.Builder
+	for _, f := range families {
+		series := set[f.name]
+		if len(series) == 0 {
+			continue
+		}
+		name := e.namespace + "_" + f.name
+		fmt.Fprintf(&b, "# HELP %s %s\n", name, f.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", name, f.kind)
+		for _, s := range series {
+			b.WriteString(name)
+			writeLabels(&b, s.labels)
+			b.WriteByte(' ')
+			b.WriteString(formatValue(s.value))
+			b.WriteByte('\n')
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// label is one name="value" pair
+type label struct {
+	name, value string
+}
+
+type series struct {
+	labels []label
+	value  float64
+}
+
+// seriesSet maps family name to its series in output order
+type seriesSet map[string][]series
+
+func (s seriesSet) add(family string, value float64, labels ...label) {
+	s[family] = append(s[family], series{labels: labels, value: value})
+}
+
+// gather builds every series, OLTs in name order. Callers hold e.mu.
+func (e *Exporter) gather() seriesSet {
+	names := make([]string, 0, len(e.olts))
+	for name := range e.olts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	set := make(seriesSet)
+	for _, name := range names {
+		e.olts[name].gather(set, name)
+	}
+	return set
+}
+
+func (d *oltData) gather(set seriesSet, olt string) {
+	base := []label{{"vendor", d.vendor}, {"olt", olt}}
+
+	if st := d.status; st != nil {
+		set.add("olt_up", boolValue(st.IsReachable), base...)
+		set.add("olt_healthy", boolValue(st.IsHealthy), base...)
+		set.add("olt_uptime_seconds", float64(st.UptimeSeconds), base...)
+		set.add("olt_cpu_percent", st.CPUPercent, base...)
+		set.add("olt_memory_percent", st.MemoryPercent, base...)
+		if st.Temperature != 0 {
+			set.add("olt_temperature_celsius", st.Temperature, base...)
+		}
+		set.add("olt_onus_active", float64(st.ActiveONUs), base...)
+		set.add("olt_onus_total", float64(st.TotalONUs), base...)
+	}
+
+	ports := d.ports
+	if ports == nil && d.status != nil {
+		for i := range d.status.PONPorts {
+			ports = append(ports, &d.status.PONPorts[i])
+		}
+	}
+	for _, p := range ports {
+		l := withLabels(base, label{"pon_port", p.Port})
+		set.add("pon_port_up", boolValue(strings.EqualFold(p.OperState, "up")), l...)
+		set.add("pon_port_onus", float64(p.ONUCount), l...)
+		if p.MaxONUs > 0 {
+			set.add("pon_port_onus_max", float64(p.MaxONUs), l...)
+		}
+		if p.RxPowerDBm != 0 {
+			set.add("pon_port_rx_power_dbm", p.RxPowerDBm, l...)
+		}
+		if p.TxPowerDBm != 0 {
+			set.add("pon_port_tx_power_dbm", p.TxPowerDBm, l...)
+		}
+		set.add("pon_port_input_rate_bps", float64(p.InputRateBps), l...)
+		set.add("pon_port_output_rate_bps", float64(p.OutputRateBps), l...)
+		if p.InOctets > 0 || p.OutOctets > 0 {
+			set.add("pon_port_in_octets_total", float64(p.InOctets), l...)
+			set.add("pon_port_out_octets_total", float64(p.OutOctets), l...)
+		}
+	}
+
+	// Optical readings override ONU list power levels for the same ONU
+	optical := make(map[onuKey]*types.ONUPowerReading, len(d.optical))
+	for _, r := range d.optical {
+		optical[onuKey{r.PONPort, r.ONUID}] = r
+	}
+	serials := make(map[onuKey]string, len(d.onus))
+
+	for _, o := range d.onus {
+		key := onuKey{o.PONPort, o.ONUID}
+		serials[key] = o.Serial
+		l := onuLabels(base, o.PONPort, o.ONUID, o.Serial)
+
+		set.add("onu_online", boolValue(o.IsOnline), l...)
+		rx, tx, distance := o.RxPowerDBm, o.TxPowerDBm, o.DistanceM
+		if r, ok := optical[key]; ok {
+			rx, tx = r.RxPowerDBm, r.TxPowerDBm
+			if r.DistanceM > 0 {
+				distance = r.DistanceM
+			}
+			if r.OLTRxDBm != 0 {
+				set.add("onu_olt_rx_power_dbm", r.OLTRxDBm, l...)
+			}
+		}
+		if rx != 0 {
+			set.add("onu_rx_power_dbm", rx, l...)
+		}
+		if tx != 0 {
+			set.add("onu_tx_power_dbm", tx, l...)
+		}
+		if distance > 0 {
+			set.add("onu_distance_meters", float64(distance), l...)
+		}
+		if o.Temperature != 0 {
+			set.add("onu_temperature_celsius", o.Temperature, l...)
+		}
+		if o.Voltage != 0 {
+			set.add("onu_voltage_volts", o.Voltage, l...)
+		}
+		if o.BiasCurrent != 0 {
+			set.add("onu_bias_current_milliamps", o.BiasCurrent, l...)
+		}
+		if o.BytesUp > 0 || o.BytesDown > 0 {
+			set.add("onu_upstream_bytes_total", float64(o.BytesUp), l...)
+			set.add("onu_downstream_bytes_total", float64(o.BytesDown), l...)
+		}
+	}
+
+	// Readings for ONUs missing from the ONU list
+	for _, r := range d.optical {
+		key := onuKey{r.PONPort, r.ONUID}
+		if _, ok := serials[key]; ok {
+			continue
+		}
+		l := onuLabels(base, r.PONPort, r.ONUID, r.Serial)
+		if r.RxPowerDBm != 0 {
+			set.add("onu_rx_power_dbm", r.RxPowerDBm, l...)
+		}
+		if r.TxPowerDBm != 0 {
+			set.add("onu_tx_power_dbm", r.TxPowerDBm, l...)
+		}
+		if r.OLTRxDBm != 0 {
+			set.add("onu_olt_rx_power_dbm", r.OLTRxDBm, l...)
+		}
+		if r.DistanceM > 0 {
+			set.add("onu_distance_meters", float64(r.DistanceM), l...)
+		}
+	}
+
+	metrics := d.metrics
+	if d.driver != nil {
+		m := d.driver.DriverMetrics()
+		metrics = &m
+	}
+	if m := metrics; m != nil {
+		set.add("driver_commands_total", float64(m.Commands), base...)
+		set.add("driver_command_errors_total", float64(m.CommandErrors), base...)
+		set.add("driver_snmp_pdus_total", float64(m.SNMPPDUs), base...)
+		set.add("driver_retries_total", float64(m.Retries), base...)
+		set.add("driver_timeouts_total", float64(m.Timeouts), base...)
+		set.add("driver_reconnects_total", float64(m.Reconnects), base...)
+		set.add("driver_parse_failures_total", float64(m.ParseFailures), base...)
+	}
+
+	classes := make([]string, 0, len(d.collectionTime))
+	for c := range d.collectionTime {
+		classes = append(classes, string(c))
+	}
+	sort.Strings(classes)
+	for _, c := range classes {
+		l := withLabels(base, label{"class", c})
+		class := collector.MetricClass(c)
+		set.add("collection_duration_seconds", d.collectionTime[class], l...)
+		set.add("collection_errors_total", float64(d.collectionErrors[class]), l...)
+	}
+}
+
+type onuKey struct {
+	ponPort string
+	onuID   int
+}
+
+func onuLabels(base []label, ponPort string, onuID int, serial string) []label {
+	return withLabels(base,
+		label{"pon_port", ponPort},
+		label{"onu_id", strconv.Itoa(onuID)},
+		label{"serial", serial},
+	)
+}
+
+func withLabels(base []label, extra ...label) []label {
+	l := make([]label, 0, len(base)+len(extra))
+	l = append(l, base...)
+	return append(l, extra...)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func writeLabels(b *strings.Builder, labels []label) {
+	if len(labels) == 0 {
+		return
+	}
+	b.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(l.name)
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(l.value))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,184 @@
+package exporter
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/collector"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func render(t *testing.T, e *Exporter) string {
+	t.Helper()
+	var b strings.Builder
+	if _, err := e.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo() error: %v", err)
+	}
+	return b.String()
+}
+
+func assertContains(t *testing.T, out string, lines ...string) {
+	t.Helper()
+	for _, l := range lines {
+		if !strings.Contains(out, l+"\n") {
+			t.Errorf("output missing %q\n%s", l, out)
+		}
+	}
+}
+
+func TestOLTStatusMetrics(t *testing.T) {
+	e := New("")
+	e.SetOLTStatus("olt-1", "vsol", &types.OLTStatus{
+		IsReachable:   true,
+		IsHealthy:     true,
+		UptimeSeconds: 3600,
+		CPUPercent:    12.5,
+		ActiveONUs:    3,
+		TotalONUs:     4,
+		PONPorts: []types.PONPortStatus{
+			{Port: "0/1", OperState: "up", ONUCount: 3, MaxONUs: 128, InOctets: 10},
+			{Port: "0/2", OperState: "down"},
+		},
+	})
+
+	out := render(t, e)
+	assertContains(t, out,
+		"# HELP southbound_olt_up Whether the OLT is reachable (1) or not (0).",
+		"# TYPE southbound_olt_up gauge",
+		`southbound_olt_up{vendor="vsol",olt="olt-1"} 1`,
+		`southbound_olt_uptime_seconds{vendor="vsol",olt="olt-1"} 3600`,
+		`southbound_olt_cpu_percent{vendor="vsol",olt="olt-1"} 12.5`,
+		`southbound_olt_onus_total{vendor="vsol",olt="olt-1"} 4`,
+		`southbound_pon_port_up{vendor="vsol",olt="olt-1",pon_port="0/1"} 1`,
+		`southbound_pon_port_up{vendor="vsol",olt="olt-1",pon_port="0/2"} 0`,
+		`southbound_pon_port_onus_max{vendor="vsol",olt="olt-1",pon_port="0/1"} 128`,
+		"# TYPE southbound_pon_port_in_octets_total counter",
+	)
+	if strings.Contains(out, "olt_temperature_celsius") {
+		t.Error("unset temperature should be omitted")
+	}
+
+	// Explicit port status replaces the ports embedded in OLT status
+	e.SetPONPorts("olt-1", "vsol", []*types.PONPortStatus{{Port: "0/3", OperState: "UP"}})
+	out = render(t, e)
+	assertContains(t, out, `southbound_pon_port_up{vendor="vsol",olt="olt-1",pon_port="0/3"} 1`)
+	if strings.Contains(out, `pon_port="0/1"`) {
+		t.Error("status ports should be replaced by SetPONPorts")
+	}
+}
+
+func TestONUMetrics(t *testing.T) {
+	e := New("nano")
+	e.SetONUs("olt-1", "huawei", []types.ONUInfo{
+		{PONPort: "0/1", ONUID: 1, Serial: "HWTC00000001", IsOnline: true, RxPowerDBm: -30, DistanceM: 1200, BytesUp: 5},
+		{PONPort: "0/1", ONUID: 2, Serial: "HWTC00000002"},
+	})
+	e.SetONUPower("olt-1", "huawei", []*types.ONUPowerReading{
+		{PONPort: "0/1", ONUID: 1, RxPowerDBm: -21.5, TxPowerDBm: 2.1, OLTRxDBm: -24},
+		{PONPort: "0/2", ONUID: 7, Serial: "HWTC00000007", RxPowerDBm: -19},
+	})
+
+	out := render(t, e)
+	l1 := `{vendor="huawei",olt="olt-1",pon_port="0/1",onu_id="1",serial="HWTC00000001"}`
+	assertContains(t, out,
+		"nano_onu_online"+l1+" 1",
+		"nano_onu_rx_power_dbm"+l1+" -21.5",
+		"nano_onu_tx_power_dbm"+l1+" 2.1",
+		"nano_onu_olt_rx_power_dbm"+l1+" -24",
+		"nano_onu_distance_meters"+l1+" 1200",
+		"nano_onu_upstream_bytes_total"+l1+" 5",
+		`nano_onu_online{vendor="huawei",olt="olt-1",pon_port="0/1",onu_id="2",serial="HWTC00000002"} 0`,
+		`nano_onu_rx_power_dbm{vendor="huawei",olt="olt-1",pon_port="0/2",onu_id="7",serial="HWTC00000007"} -19`,
+	)
+	if strings.Contains(out, " -30\n") {
+		t.Error("optical reading should override ONU list power")
+	}
+
+	// A new ONU list replaces the old one
+	e.SetONUs("olt-1", "huawei", nil)
+	e.SetONUPower("olt-1", "huawei", nil)
+	if out := render(t, e); strings.Contains(out, "onu_") {
+		t.Errorf("stale ONU metrics reported:\n%s", out)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	e := New("")
+	e.Observe(collector.Sample{
+		Device:   "olt-1",
+		Vendor:   types.VendorVSOL,
+		Class:    collector.MetricOptical,
+		Duration: 250 * time.Millisecond,
+		Optical: map[string]*types.ONUPowerReading{
+			"0/1:1": {PONPort: "0/1", ONUID: 1, RxPowerDBm: -20},
+		},
+	})
+	e.Observe(collector.Sample{Device: "olt-1", Class: collector.MetricOLTStatus, Err: errors.New("timeout")})
+	e.Observe(collector.Sample{Device: "olt-1", Class: collector.MetricOLTStatus, Err: errors.New("timeout")})
+
+	out := render(t, e)
+	assertContains(t, out,
+		`southbound_onu_rx_power_dbm{vendor="vsol",olt="olt-1",pon_port="0/1",onu_id="1",serial=""} -20`,
+		`southbound_collection_duration_seconds{vendor="vsol",olt="olt-1",class="optical"} 0.25`,
+		`southbound_collection_errors_total{vendor="vsol",olt="olt-1",class="olt_status"} 2`,
+		`southbound_collection_errors_total{vendor="vsol",olt="olt-1",class="optical"} 0`,
+	)
+	if strings.Contains(out, "olt_up") {
+		t.Error("failed collection should not report OLT status")
+	}
+
+	e.Remove("olt-1")
+	if out := render(t, e); out != "" {
+		t.Errorf("Remove() left metrics:\n%s", out)
+	}
+}
+
+func TestLabelEscaping(t *testing.T) {
+	e := New("")
+	e.SetOLTStatus("olt \"a\"\\b\nc", "zte", &types.OLTStatus{})
+	assertContains(t, render(t, e), `southbound_olt_up{vendor="zte",olt="olt \"a\"\\b\nc"} 0`)
+}
+
+func TestHandler(t *testing.T) {
+	e := New("")
+	e.SetOLTStatus("olt-1", "zte", &types.OLTStatus{IsReachable: true})
+
+	rec := httptest.NewRecorder()
+	e.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	assertContains(t, rec.Body.String(), `southbound_olt_up{vendor="zte",olt="olt-1"} 1`)
+}
+
+type fixedMetrics types.DriverMetrics
+
+func (m fixedMetrics) DriverMetrics() types.DriverMetrics { return types.DriverMetrics(m) }
+
+func TestDriverMetrics(t *testing.T) {
+	e := New("")
+	e.Observe(collector.Sample{
+		Device:        "olt-1",
+		Vendor:        types.VendorHuawei,
+		Class:         collector.MetricOLTStatus,
+		OLTStatus:     &types.OLTStatus{},
+		DriverMetrics: &types.DriverMetrics{Commands: 7, Timeouts: 1},
+	})
+	assertContains(t, render(t, e),
+		"# TYPE southbound_driver_commands_total counter",
+		`southbound_driver_commands_total{vendor="huawei",olt="olt-1"} 7`,
+		`southbound_driver_timeouts_total{vendor="huawei",olt="olt-1"} 1`,
+	)
+
+	// A registered driver is read on every scrape
+	e.SetDriver("olt-1", "", fixedMetrics{Commands: 9, SNMPPDUs: 20, ParseFailures: 2})
+	assertContains(t, render(t, e),
+		`southbound_driver_commands_total{vendor="huawei",olt="olt-1"} 9`,
+		`southbound_driver_snmp_pdus_total{vendor="huawei",olt="olt-1"} 20`,
+		`southbound_driver_parse_failures_total{vendor="huawei",olt="olt-1"} 2`,
+	)
+}
@@ -0,0 +1,57 @@
+package exporter
+
+// family describes one metric family
+type family struct {
+	name string
+	kind string
+	help string
+}
+
+// families lists every metric in output order. Names are prefixed with the
+// Exporter namespace.
+//
+// Labels: OLT metrics carry vendor and olt; PON port metrics add pon_port;
+// ONU metrics add pon_port, onu_id and serial; collection metrics add
+// class. Driver metrics carry only vendor and olt.
+var families = []family{
+	{"olt_up", "gauge", "Whether the OLT is reachable (1) or not (0)."},
+	{"olt_healthy", "gauge", "Whether the OLT reports itself healthy (1) or not (0)."},
+	{"olt_uptime_seconds", "gauge", "OLT uptime in seconds."},
+	{"olt_cpu_percent", "gauge", "OLT CPU utilization in percent."},
+	{"olt_memory_percent", "gauge", "OLT memory utilization in percent."},
+	{"olt_temperature_celsius", "gauge", "OLT system temperature in degrees Celsius."},
+	{"olt_onus_active", "gauge", "Number of online ONUs on the OLT."},
+	{"olt_onus_total", "gauge", "Number of provisioned ONUs on the OLT."},
+
+	{"pon_port_up", "gauge", "Whether the PON port is operationally up (1) or not (0)."},
+	{"pon_port_onus", "gauge", "Number of ONUs on the PON port."},
+	{"pon_port_onus_max", "gauge", "Maximum number of ONUs supported on the PON port."},
+	{"pon_port_rx_power_dbm", "gauge", "PON port receive power in dBm."},
+	{"pon_port_tx_power_dbm", "gauge", "PON port transmit power in dBm."},
+	{"pon_port_input_rate_bps", "gauge", "PON port input rate in bits per second."},
+	{"pon_port_output_rate_bps", "gauge", "PON port output rate in bits per second."},
+	{"pon_port_in_octets_total", "counter", "Octets received on the PON port."},
+	{"pon_port_out_octets_total", "counter", "Octets transmitted on the PON port."},
+
+	{"onu_online", "gauge", "Whether the ONU is online (1) or not (0)."},
+	{"onu_rx_power_dbm", "gauge", "ONU receive power in dBm."},
+	{"onu_tx_power_dbm", "gauge", "ONU transmit power in dBm."},
+	{"onu_olt_rx_power_dbm", "gauge", "Power received by the OLT from the ONU in dBm."},
+	{"onu_distance_meters", "gauge", "Estimated fiber distance to the ONU in meters."},
+	{"onu_temperature_celsius", "gauge", "ONU temperature in degrees Celsius."},
+	{"onu_voltage_volts", "gauge", "ONU supply voltage in volts."},
+	{"onu_bias_current_milliamps", "gauge", "ONU laser bias current in milliamps."},
+	{"onu_upstream_bytes_total", "counter", "Bytes transmitted upstream by the ONU."},
+	{"onu_downstream_bytes_total", "counter", "Bytes received downstream by the ONU."},
+
+	{"driver_commands_total", "counter", "Requests sent to the device (CLI commands, NETCONF RPCs, gNMI and SNMP requests)."},
+	{"driver_command_errors_total", "counter", "Requests to the device that failed."},
+	{"driver_snmp_pdus_total", "counter", "SNMP PDUs sent to the device, including retries."},
+	{"driver_retries_total", "counter", "Requests retransmitted after no response."},
+	{"driver_timeouts_total", "counter", "Requests that timed out."},
+	{"driver_reconnects_total", "counter", "Reconnections to the device after the first connect."},
+	{"driver_parse_failures_total", "counter", "Device responses that could not be parsed."},
+
+	{"collection_duration_seconds", "gauge", "Duration of the last collection in seconds."},
+	{"collection_errors_total", "counter", "Failed collections."},
+}
@@ -174,8 +174,16 @@ type VendorCapabilities struct {
 	SupportsStreaming  bool
 }
 
-// NewDriver creates a new southbound driver based on vendor and protocol
-func NewDriver(vendor Vendor, protocol Protocol, config *EquipmentConfig) (Driver, error) {
+// NewDriver creates a new southbound driver based on vendor and protocol.
+// Options such as WithModel set typed settings of the config.
+func NewDriver(vendor Vendor, protocol Protocol, config *EquipmentConfig, opts ...Option) (Driver, error) {
+	// Applied to a copy so the caller's config is left as is
+	if len(opts) > 0 {
+		configured := *config
+		configured.Apply(opts...)
+		config = &configured
+	}
+
 	// Validate vendor capabilities
 	caps, ok := CapabilityMatrix[vendor]
 	if !ok {
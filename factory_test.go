@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
 )
 
 func TestNewDriver(t *testing.T) {
@@ -257,3 +258,53 @@ func searchSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestAdapterCapabilities(t *testing.T) {
+	tests := []struct {
+		vendor Vendor
+		want   func(c types.Capabilities) bool
+	}{
+		{VendorVSOL, func(c types.Capabilities) bool {
+			return c.ONUList && c.VLANs && c.WiFi && c.ONUEthPorts && c.Voice && c.ONULAN && c.ONUTests && c.FirmwareUpgrade && c.ConfigBackup && !c.ConfigRestore
+		}},
+		{VendorHuawei, func(c types.Capabilities) bool {
+			return c.ONUList && c.BulkProvision && !c.OLTRestart && !c.ONUProfileQuery && !c.WiFi && c.ONUWAN && c.ONULAN && c.Voice && c.ONUTests && c.ConfigRestore && c.LineProfiles && c.ServiceProfiles && c.TrafficProfiles
+		}},
+		{VendorNokia, func(c types.Capabilities) bool {
+			return c.Subscribers && c.BulkSubscribers && c.ConfigRestore && c.ONUList && c.OLTStatus && !c.Alarms
+		}},
+		{VendorCData, func(c types.Capabilities) bool {
+			return c.ONUDiscovery && c.ONUEthPorts && c.ONURestart && c.ONUList && c.Alarms && !c.VLANs && !c.ConfigRestore
+		}},
+		{VendorZTE, func(c types.Capabilities) bool {
+			return c == types.Capabilities{Subscribers: true}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.vendor), func(t *testing.T) {
+			driver, err := NewDriver(tt.vendor, "", testutil.NewTestEquipmentConfig(tt.vendor, "10.0.0.1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := driver.(types.CapabilityReporter); !ok {
+				t.Fatal("adapter does not implement types.CapabilityReporter")
+			}
+			if c := types.CapabilitiesOf(driver); !tt.want(c) {
+				t.Errorf("unexpected capabilities %+v", c)
+			}
+		})
+	}
+}
+
+func TestNewDriverOptions(t *testing.T) {
+	config := testutil.NewTestEquipmentConfig(VendorHuawei, "10.0.0.1")
+	driver, err := NewDriver(VendorHuawei, "", config, WithModel("ma5800-x7"), WithSNMPCommunity("private"))
+	if err != nil || driver == nil {
+		t.Fatalf("NewDriver() = %v, %v", driver, err)
+	}
+	// Options do not change the caller's config
+	if config.Model != "" || config.SNMPCommunity == "private" {
+		t.Errorf("config = %+v", config)
+	}
+}
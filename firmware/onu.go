@@ -0,0 +1,261 @@
+package firmware
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
+)
+
+// Defaults applied for zero ONUOptions fields
+const (
+	DefaultONUConcurrency = 8
+	DefaultONUTimeout     = 30 * time.Minute
+)
+
+// ONUSelector selects the ONUs to upgrade. Empty fields match everything;
+// values within a field are alternatives.
+type ONUSelector struct {
+	// Models matches ONUInfo.Model, case-insensitively
+	Models []string
+
+	// Versions matches the running version, e.g. to upgrade only ONUs on
+	// a known-bad release
+	Versions []string
+
+	PONPorts []string
+	Serials  []string
+}
+
+// matchesONU reports whether onu is selected by model, port and serial
+func (s ONUSelector) matchesONU(onu types.ONUInfo) bool {
+	if len(s.Models) > 0 && !slices.ContainsFunc(s.Models, func(m string) bool { return strings.EqualFold(m, onu.Model) }) {
+		return false
+	}
+	if len(s.PONPorts) > 0 && !slices.Contains(s.PONPorts, onu.PONPort) {
+		return false
+	}
+	if len(s.Serials) > 0 && !slices.ContainsFunc(s.Serials, func(sn string) bool { return common.SerialsEqual(sn, onu.Serial) }) {
+		return false
+	}
+	return true
+}
+
+// matchesVersion reports whether an ONU running version is selected
+func (s ONUSelector) matchesVersion(version string) bool {
+	return len(s.Versions) == 0 || slices.Contains(s.Versions, version)
+}
+
+// ONUOptions configures an ONU upgrade
+type ONUOptions struct {
+	// Concurrency is the number of ONUs upgraded at once. OLTs limit
+	// concurrent OMCI downloads, so keep this low. Defaults to
+	// DefaultONUConcurrency.
+	Concurrency int
+
+	// Timeout bounds the upgrade of one ONU. Defaults to DefaultONUTimeout.
+	Timeout time.Duration
+
+	// PollInterval is the delay between status polls. Defaults to
+	// DefaultPollInterval.
+	PollInterval time.Duration
+
+	// SkipUpload assumes the image is already on the OLT
+	SkipUpload bool
+
+	// Progress is called on every status change of an ONU. It is called
+	// concurrently and may be nil.
+	Progress func(ONUProgress)
+}
+
+// ONUProgress reports the upgrade state of one ONU
+type ONUProgress struct {
+	PONPort string
+	ONUID   int
+	Serial  string
+	State   string // one of the types.ONUUpgrade* states
+	Percent int
+	Err     error
+	At      time.Time
+}
+
+// ONUResult is the outcome of upgrading one ONU
+type ONUResult struct {
+	PONPort         string        `json:"pon_port"`
+	ONUID           int           `json:"onu_id"`
+	Serial          string        `json:"serial"`
+	Model           string        `json:"model,omitempty"`
+	PreviousVersion string        `json:"previous_version"`
+	Version         string        `json:"version"`
+	Skipped         bool          `json:"skipped"` // already running the image
+	Err             error         `json:"-"`
+	Duration        time.Duration `json:"duration"`
+}
+
+// ONULister is implemented by drivers that list provisioned ONUs
+type ONULister interface {
+	GetONUList(ctx context.Context, filter *types.ONUFilter) ([]types.ONUInfo, error)
+}
+
+// UpgradeONUs uploads image to the OLT behind driver and upgrades every
+// online ONU that sel selects and that is not already running
+// image.Version. driver must implement types.ONUFirmwareManager and
+// ONULister.
+//
+// The returned error covers listing ONUs and uploading the image; the
+// outcome of each ONU is in its ONUResult, in ONU list order.
+func UpgradeONUs(ctx context.Context, driver types.Driver, config *types.EquipmentConfig, image *types.FirmwareImage, sel ONUSelector, opts ONUOptions) ([]ONUResult, error) {
+	if image == nil || image.Version == "" || image.URL == "" {
+		return nil, fmt.Errorf("firmware image needs a version and a URL")
+	}
+	fm, ok := driver.(types.ONUFirmwareManager)
+	if !ok {
+		return nil, fmt.Errorf("%s (%s) does not support ONU firmware upgrades", config.Name, config.Vendor)
+	}
+	lister, ok := driver.(ONULister)
+	if !ok {
+		return nil, fmt.Errorf("%s (%s) cannot list ONUs", config.Name, config.Vendor)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultONUConcurrency
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultONUTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+
+	onus, err := lister.GetONUList(ctx, &types.ONUFilter{Status: "online"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ONUs: %w", err)
+	}
+
+	// Read the running versions first so the image is only uploaded if
+	// some ONU needs it
+	var results []ONUResult
+	var pending []int
+	for _, onu := range onus {
+		if !onu.IsOnline || !sel.matchesONU(onu) {
+			continue
+		}
+		res := ONUResult{PONPort: onu.PONPort, ONUID: onu.ONUID, Serial: onu.Serial, Model: onu.Model}
+		status, err := fm.GetONUFirmwareStatus(ctx, onu.PONPort, onu.ONUID)
+		if err != nil {
+			res.Err = fmt.Errorf("failed to read ONU firmware status: %w", err)
+			results = append(results, res)
+			continue
+		}
+		if !sel.matchesVersion(status.Version) {
+			continue
+		}
+		res.PreviousVersion = status.Version
+		res.Version = status.Version
+		res.Skipped = status.Version == image.Version
+		results = append(results, res)
+		if !res.Skipped {
+			pending = append(pending, len(results)-1)
+		}
+	}
+	if len(pending) == 0 {
+		return results, nil
+	}
+
+	if !opts.SkipUpload {
+		config.Log().Info("uploading ONU firmware", "version", image.Version, "url", image.URL)
+		if err := fm.UploadONUFirmware(ctx, image); err != nil {
+			return results, fmt.Errorf("failed to upload ONU image: %w", err)
+		}
+	}
+
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for _, i := range pending {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i].Err = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+			upgradeONU(ctx, fm, config, image, &results[i], opts)
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// upgradeONU starts the upgrade of one ONU and polls until it finishes
+func upgradeONU(ctx context.Context, fm types.ONUFirmwareManager, config *types.EquipmentConfig, image *types.FirmwareImage, res *ONUResult, opts ONUOptions) {
+	start := time.Now()
+	defer func() { res.Duration = time.Since(start) }()
+	report := func(state string, percent int, err error) {
+		if err != nil {
+			res.Err = err
+			config.Log().Warn("ONU firmware upgrade failed", "pon_port", res.PONPort, "onu_id", res.ONUID, "error", err)
+		}
+		if opts.Progress != nil {
+			opts.Progress(ONUProgress{PONPort: res.PONPort, ONUID: res.ONUID, Serial: res.Serial,
+				State: state, Percent: percent, Err: err, At: time.Now()})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	if err := fm.UpgradeONUFirmware(ctx, res.PONPort, res.ONUID, image); err != nil {
+		report(types.ONUUpgradeFailed, 0, fmt.Errorf("failed to start upgrade: %w", err))
+		return
+	}
+	report(types.ONUUpgradeInProgress, 0, nil)
+
+	lastPercent, lastState := 0, ""
+	for {
+		select {
+		case <-ctx.Done():
+			err := fmt.Errorf("upgrade did not finish within %s", opts.Timeout)
+			if res.Version != res.PreviousVersion || lastState == types.ONUUpgradeSucceeded {
+				err = fmt.Errorf("ONU runs %s after upgrade, expected %s", res.Version, image.Version)
+			}
+			report(types.ONUUpgradeFailed, lastPercent, err)
+			return
+		case <-time.After(opts.PollInterval):
+		}
+
+		// The ONU reboots into the new image, so status reads fail for a
+		// while; keep polling until the timeout
+		status, err := fm.GetONUFirmwareStatus(ctx, res.PONPort, res.ONUID)
+		if err != nil {
+			continue
+		}
+		if status.Version != "" {
+			res.Version = status.Version
+		}
+		lastState = status.State
+		switch status.State {
+		case types.ONUUpgradeFailed:
+			report(types.ONUUpgradeFailed, status.Percent, fmt.Errorf("ONU upgrade failed: %s", status.Message))
+			return
+		case types.ONUUpgradeSucceeded, types.ONUUpgradeIdle:
+			// The download may report success before the ONU has rebooted
+			// into the new image, so wait for the version to change
+			if status.Version == image.Version {
+				report(types.ONUUpgradeSucceeded, 100, nil)
+				return
+			}
+		case types.ONUUpgradeInProgress:
+			if status.Percent != lastPercent {
+				lastPercent = status.Percent
+				report(types.ONUUpgradeInProgress, status.Percent, nil)
+			}
+		}
+	}
+}
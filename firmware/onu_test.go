@@ -0,0 +1,210 @@
+package firmware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// fakeONUOLT upgrades ONUs after a fixed number of status polls
+type fakeONUOLT struct {
+	*testutil.MockDriver
+	mu       sync.Mutex
+	onus     []types.ONUInfo
+	versions map[int]string // by ONU ID
+	polls    map[int]int
+	failing  map[int]bool
+	uploads  int
+	started  []int
+}
+
+func newFakeONUOLT(onus ...types.ONUInfo) *fakeONUOLT {
+	f := &fakeONUOLT{
+		MockDriver: &testutil.MockDriver{Connected: true},
+		onus:       onus,
+		versions:   make(map[int]string),
+		polls:      make(map[int]int),
+		failing:    make(map[int]bool),
+	}
+	for _, o := range onus {
+		f.versions[o.ONUID] = "V1.0"
+	}
+	return f
+}
+
+func (f *fakeONUOLT) GetONUList(context.Context, *types.ONUFilter) ([]types.ONUInfo, error) {
+	return f.onus, nil
+}
+
+func (f *fakeONUOLT) UploadONUFirmware(context.Context, *types.FirmwareImage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.uploads++
+	return nil
+}
+
+func (f *fakeONUOLT) UpgradeONUFirmware(_ context.Context, _ string, onuID int, _ *types.FirmwareImage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.started = append(f.started, onuID)
+	f.polls[onuID] = 0
+	return nil
+}
+
+func (f *fakeONUOLT) GetONUFirmwareStatus(_ context.Context, ponPort string, onuID int) (*types.ONUFirmwareStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	status := &types.ONUFirmwareStatus{PONPort: ponPort, ONUID: onuID, Version: f.versions[onuID], State: types.ONUUpgradeIdle}
+	n, upgrading := f.polls[onuID]
+	if !upgrading {
+		return status, nil
+	}
+	f.polls[onuID] = n + 1
+	switch {
+	case n < 2:
+		status.State = types.ONUUpgradeInProgress
+		status.Percent = (n + 1) * 40
+	case f.failing[onuID]:
+		status.State = types.ONUUpgradeFailed
+		status.Message = "image CRC error"
+	case n == 2:
+		// ONU rebooting into the new image
+		return nil, fmt.Errorf("ONU %d offline", onuID)
+	default:
+		f.versions[onuID] = "V2.0"
+		status.Version = "V2.0"
+		status.State = types.ONUUpgradeSucceeded
+	}
+	return status, nil
+}
+
+var onuImage = &types.FirmwareImage{Version: "V2.0", URL: "tftp://192.0.2.10/onu.bin"}
+
+func onuOptions() ONUOptions {
+	return ONUOptions{PollInterval: time.Millisecond, Timeout: time.Second}
+}
+
+func TestUpgradeONUs(t *testing.T) {
+	olt := newFakeONUOLT(
+		types.ONUInfo{PONPort: "0/1", ONUID: 1, Serial: "VSOL00000001", Model: "V2802GW", IsOnline: true},
+		types.ONUInfo{PONPort: "0/1", ONUID: 2, Serial: "VSOL00000002", Model: "V2801F", IsOnline: true},
+		types.ONUInfo{PONPort: "0/2", ONUID: 3, Serial: "VSOL00000003", Model: "V2802GW", IsOnline: true},
+		types.ONUInfo{PONPort: "0/2", ONUID: 4, Serial: "VSOL00000004", Model: "V2802GW", IsOnline: false},
+	)
+	olt.versions[3] = "V2.0"
+	config := testutil.NewTestEquipmentConfig(types.VendorVSOL, "192.0.2.1")
+
+	var mu sync.Mutex
+	var progress []ONUProgress
+	opts := onuOptions()
+	opts.Progress = func(p ONUProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		progress = append(progress, p)
+	}
+
+	results, err := UpgradeONUs(context.Background(), olt, config, onuImage, ONUSelector{Models: []string{"v2802gw"}}, opts)
+	if err != nil {
+		t.Fatalf("UpgradeONUs() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2 (model filter, offline ONU excluded): %+v", len(results), results)
+	}
+	if r := results[0]; r.ONUID != 1 || r.Err != nil || r.PreviousVersion != "V1.0" || r.Version != "V2.0" || r.Skipped {
+		t.Errorf("ONU 1 result = %+v", r)
+	}
+	if r := results[1]; r.ONUID != 3 || !r.Skipped {
+		t.Errorf("ONU 3 should be skipped, got %+v", r)
+	}
+	if olt.uploads != 1 || len(olt.started) != 1 {
+		t.Errorf("uploads = %d, started = %v", olt.uploads, olt.started)
+	}
+	last := progress[len(progress)-1]
+	if last.ONUID != 1 || last.State != types.ONUUpgradeSucceeded || last.Percent != 100 {
+		t.Errorf("last progress = %+v", last)
+	}
+}
+
+func TestUpgradeONUs_Selector(t *testing.T) {
+	olt := newFakeONUOLT(
+		types.ONUInfo{PONPort: "0/1", ONUID: 1, Serial: "VSOL00000001", IsOnline: true},
+		types.ONUInfo{PONPort: "0/2", ONUID: 2, Serial: "VSOL00000002", IsOnline: true},
+	)
+	olt.versions[2] = "V0.9"
+	config := testutil.NewTestEquipmentConfig(types.VendorVSOL, "192.0.2.1")
+
+	results, err := UpgradeONUs(context.Background(), olt, config, onuImage, ONUSelector{Versions: []string{"V0.9"}}, onuOptions())
+	if err != nil {
+		t.Fatalf("UpgradeONUs() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ONUID != 2 {
+		t.Errorf("version selector results = %+v", results)
+	}
+
+	// Serials match whatever their representation
+	results, err = UpgradeONUs(context.Background(), olt, config, onuImage, ONUSelector{Serials: []string{"56534F4C00000002"}}, onuOptions())
+	if err != nil || len(results) != 1 || results[0].ONUID != 2 {
+		t.Errorf("serial selector results = %+v, err = %v", results, err)
+	}
+
+	olt = newFakeONUOLT(types.ONUInfo{PONPort: "0/1", ONUID: 1, IsOnline: true})
+	olt.versions[1] = "V2.0"
+	results, err = UpgradeONUs(context.Background(), olt, config, onuImage, ONUSelector{}, onuOptions())
+	if err != nil || len(results) != 1 || !results[0].Skipped || olt.uploads != 0 {
+		t.Errorf("nothing to upgrade should not upload: results = %+v, uploads = %d, err = %v", results, olt.uploads, err)
+	}
+}
+
+func TestUpgradeONUs_Failures(t *testing.T) {
+	config := testutil.NewTestEquipmentConfig(types.VendorVSOL, "192.0.2.1")
+	olt := newFakeONUOLT(
+		types.ONUInfo{PONPort: "0/1", ONUID: 1, IsOnline: true},
+		types.ONUInfo{PONPort: "0/1", ONUID: 2, IsOnline: true},
+	)
+	olt.failing[2] = true
+
+	results, err := UpgradeONUs(context.Background(), olt, config, onuImage, ONUSelector{}, onuOptions())
+	if err != nil {
+		t.Fatalf("UpgradeONUs() error = %v", err)
+	}
+	if results[0].Err != nil {
+		t.Errorf("ONU 1 error = %v", results[0].Err)
+	}
+	if results[1].Err == nil || results[1].Version != "V1.0" {
+		t.Errorf("ONU 2 should fail and keep V1.0, got %+v", results[1])
+	}
+
+	if _, err := UpgradeONUs(context.Background(), &testutil.MockDriver{}, config, onuImage, ONUSelector{}, onuOptions()); err == nil {
+		t.Error("expected error for driver without ONU firmware support")
+	}
+	if _, err := UpgradeONUs(context.Background(), olt, config, &types.FirmwareImage{}, ONUSelector{}, onuOptions()); err == nil {
+		t.Error("expected error for image without version")
+	}
+}
+
+// stuckOLT never finishes an upgrade
+type stuckOLT struct{ *fakeONUOLT }
+
+func (s stuckOLT) GetONUFirmwareStatus(_ context.Context, ponPort string, onuID int) (*types.ONUFirmwareStatus, error) {
+	return &types.ONUFirmwareStatus{PONPort: ponPort, ONUID: onuID, Version: "V1.0", State: types.ONUUpgradeInProgress}, nil
+}
+
+func TestUpgradeONUs_Timeout(t *testing.T) {
+	config := testutil.NewTestEquipmentConfig(types.VendorVSOL, "192.0.2.1")
+	olt := stuckOLT{newFakeONUOLT(types.ONUInfo{PONPort: "0/1", ONUID: 1, IsOnline: true})}
+	opts := onuOptions()
+	opts.Timeout = 20 * time.Millisecond
+
+	results, err := UpgradeONUs(context.Background(), olt, config, onuImage, ONUSelector{}, opts)
+	if err != nil {
+		t.Fatalf("UpgradeONUs() error = %v", err)
+	}
+	if results[0].Err == nil {
+		t.Errorf("expected timeout error, got %v", results[0].Err)
+	}
+}
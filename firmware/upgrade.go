@@ -0,0 +1,301 @@
+// Package firmware orchestrates OLT firmware upgrades: pre-checks, image
+// staging and verification, activation, reboot and post-reboot version
+// verification, reporting progress at each stage. It also upgrades fleets
+// of ONUs over OMCI through the OLT.
+package firmware
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // vendors publish MD5 digests for images
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Defaults applied for zero Options fields
+const (
+	DefaultRebootTimeout = 15 * time.Minute
+	DefaultPollInterval  = 30 * time.Second
+)
+
+// ErrActiveAlarms is returned when the OLT has active critical or major
+// alarms and the upgrade is not forced
+var ErrActiveAlarms = errors.New("OLT has active critical or major alarms")
+
+// Stage is a step of an upgrade
+type Stage string
+
+const (
+	StagePrecheck      Stage = "precheck"
+	StageChecksum      Stage = "checksum"
+	StageStage         Stage = "stage"
+	StageVerify        Stage = "verify"
+	StageActivate      Stage = "activate"
+	StageReboot        Stage = "reboot"
+	StageWaitOnline    Stage = "wait_online"
+	StageVerifyVersion Stage = "verify_version"
+	StageDone          Stage = "done"
+)
+
+// Progress reports the start of a stage, or its failure if Err is set
+type Progress struct {
+	Device  string
+	Stage   Stage
+	Message string
+	Err     error
+	At      time.Time
+}
+
+// Options configures an upgrade
+type Options struct {
+	// Force upgrades despite active alarms, and reinstalls an image whose
+	// version is already running
+	Force bool
+
+	// NoReboot stops after activation. The new image runs after the next
+	// reboot and the version is not verified.
+	NoReboot bool
+
+	// RebootTimeout bounds the wait for the OLT to come back. Defaults to
+	// DefaultRebootTimeout.
+	RebootTimeout time.Duration
+
+	// PollInterval is the delay between reconnect attempts after the
+	// reboot. Defaults to DefaultPollInterval.
+	PollInterval time.Duration
+
+	// Progress is called at each stage. It may be nil.
+	Progress func(Progress)
+}
+
+// Result describes a finished upgrade
+type Result struct {
+	Device          string        `json:"device"`
+	PreviousVersion string        `json:"previous_version"`
+	Version         string        `json:"version"`
+	Skipped         bool          `json:"skipped"` // already running the image
+	Rebooted        bool          `json:"rebooted"`
+	Duration        time.Duration `json:"duration"`
+}
+
+// AlarmReader is implemented by drivers that report active alarms
+type AlarmReader interface {
+	GetAlarms(ctx context.Context) ([]types.OLTAlarm, error)
+}
+
+// Restarter is implemented by drivers that can reboot the OLT
+type Restarter interface {
+	RestartOLT(ctx context.Context) (*types.RestartOLTResult, error)
+}
+
+// Upgrade installs image on the OLT behind driver. driver must implement
+// types.FirmwareManager and, unless NoReboot is set, Restarter. config is
+// used to reconnect after the reboot.
+//
+// The upgrade refuses to start while the OLT has active critical or major
+// alarms, and skips OLTs already running image.Version, unless forced.
+func Upgrade(ctx context.Context, driver types.Driver, config *types.EquipmentConfig, image *types.FirmwareImage, opts Options) (*Result, error) {
+	if opts.RebootTimeout <= 0 {
+		opts.RebootTimeout = DefaultRebootTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	u := &upgrade{driver: driver, config: config, image: image, opts: opts}
+	start := time.Now()
+	res, err := u.run(ctx)
+	if res != nil {
+		res.Duration = time.Since(start)
+	}
+	return res, err
+}
+
+type upgrade struct {
+	driver types.Driver
+	config *types.EquipmentConfig
+	image  *types.FirmwareImage
+	opts   Options
+}
+
+func (u *upgrade) run(ctx context.Context) (*Result, error) {
+	if u.image == nil || u.image.Version == "" || u.image.URL == "" {
+		return nil, fmt.Errorf("firmware image needs a version and a URL")
+	}
+	fm, ok := u.driver.(types.FirmwareManager)
+	if !ok {
+		return nil, fmt.Errorf("%s (%s) does not support firmware upgrades", u.config.Name, u.config.Vendor)
+	}
+	restarter, canRestart := u.driver.(Restarter)
+	if !u.opts.NoReboot && !canRestart {
+		return nil, fmt.Errorf("%s (%s) cannot be rebooted; set NoReboot to stage only", u.config.Name, u.config.Vendor)
+	}
+
+	res := &Result{Device: u.config.Name}
+
+	u.report(StagePrecheck, "checking version and alarms")
+	current, err := fm.FirmwareVersion(ctx)
+	if err != nil {
+		return nil, u.fail(StagePrecheck, fmt.Errorf("failed to read firmware version: %w", err))
+	}
+	res.PreviousVersion = current
+	res.Version = current
+	if current == u.image.Version && !u.opts.Force {
+		res.Skipped = true
+		u.report(StageDone, "already running "+current)
+		return res, nil
+	}
+	if err := u.checkAlarms(ctx); err != nil {
+		return nil, u.fail(StagePrecheck, err)
+	}
+
+	if u.image.Path != "" {
+		u.report(StageChecksum, "verifying local image checksum")
+		if err := VerifyChecksum(u.image.Path, u.image.Checksum, u.image.ChecksumType); err != nil {
+			return nil, u.fail(StageChecksum, err)
+		}
+	}
+
+	u.report(StageStage, "downloading "+u.image.URL)
+	if err := fm.StageFirmware(ctx, u.image); err != nil {
+		return nil, u.fail(StageStage, fmt.Errorf("failed to stage image: %w", err))
+	}
+
+	if v, ok := u.driver.(types.FirmwareVerifier); ok && u.image.Checksum != "" {
+		u.report(StageVerify, "verifying staged image on device")
+		if err := v.VerifyFirmware(ctx, u.image); err != nil {
+			return nil, u.fail(StageVerify, fmt.Errorf("staged image verification failed: %w", err))
+		}
+	}
+
+	u.report(StageActivate, "activating "+u.image.Version)
+	if err := fm.ActivateFirmware(ctx, u.image); err != nil {
+		return nil, u.fail(StageActivate, fmt.Errorf("failed to activate image: %w", err))
+	}
+
+	if u.opts.NoReboot {
+		u.report(StageDone, "activated; reboot required")
+		return res, nil
+	}
+
+	u.report(StageReboot, "rebooting")
+	if _, err := restarter.RestartOLT(ctx); err != nil {
+		return nil, u.fail(StageReboot, fmt.Errorf("failed to reboot: %w", err))
+	}
+	res.Rebooted = true
+
+	u.report(StageWaitOnline, "waiting for the OLT to come back")
+	if err := u.waitOnline(ctx); err != nil {
+		return res, u.fail(StageWaitOnline, err)
+	}
+
+	u.report(StageVerifyVersion, "verifying running version")
+	running, err := fm.FirmwareVersion(ctx)
+	if err != nil {
+		return res, u.fail(StageVerifyVersion, fmt.Errorf("failed to read firmware version: %w", err))
+	}
+	res.Version = running
+	if running != u.image.Version {
+		return res, u.fail(StageVerifyVersion, fmt.Errorf("OLT runs %s after upgrade, expected %s", running, u.image.Version))
+	}
+
+	u.report(StageDone, "running "+running)
+	return res, nil
+}
+
+// checkAlarms fails on active critical or major alarms unless forced.
+// Drivers without alarm support pass.
+func (u *upgrade) checkAlarms(ctx context.Context) error {
+	reader, ok := u.driver.(AlarmReader)
+	if !ok || u.opts.Force {
+		return nil
+	}
+	alarms, err := reader.GetAlarms(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read alarms: %w", err)
+	}
+	var active []string
+	for _, a := range alarms {
+		sev := strings.ToLower(a.Severity)
+		if a.ClearedAt == nil && (sev == "critical" || sev == "major") {
+			active = append(active, fmt.Sprintf("%s %s", sev, a.Type))
+		}
+	}
+	if len(active) > 0 {
+		return fmt.Errorf("%w: %s", ErrActiveAlarms, strings.Join(active, ", "))
+	}
+	return nil
+}
+
+// waitOnline reconnects until the OLT answers or RebootTimeout passes
+func (u *upgrade) waitOnline(ctx context.Context) error {
+	u.driver.Disconnect(ctx) //nolint:errcheck // session is already dropped by the reboot
+
+	ctx, cancel := context.WithTimeout(ctx, u.opts.RebootTimeout)
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("OLT did not come back within %s", u.opts.RebootTimeout)
+		case <-time.After(u.opts.PollInterval):
+		}
+		if err := u.driver.Connect(ctx, u.config); err != nil {
+			u.config.Log().Debug("OLT not reachable yet after reboot", "error", err)
+			continue
+		}
+		return nil
+	}
+}
+
+func (u *upgrade) report(stage Stage, msg string) {
+	u.config.Log().Info("firmware upgrade", "stage", stage, "message", msg)
+	if u.opts.Progress != nil {
+		u.opts.Progress(Progress{Device: u.config.Name, Stage: stage, Message: msg, At: time.Now()})
+	}
+}
+
+func (u *upgrade) fail(stage Stage, err error) error {
+	u.config.Log().Warn("firmware upgrade failed", "stage", stage, "error", err)
+	if u.opts.Progress != nil {
+		u.opts.Progress(Progress{Device: u.config.Name, Stage: stage, Err: err, At: time.Now()})
+	}
+	return err
+}
+
+// VerifyChecksum checks the digest of the file at path. checksumType is
+// "md5" or "sha256"; empty means md5. An empty checksum fails, since an
+// unverified image must not be installed.
+func VerifyChecksum(path, checksum, checksumType string) error {
+	if checksum == "" {
+		return fmt.Errorf("no checksum given for %s", path)
+	}
+	var h hash.Hash
+	switch strings.ToLower(checksumType) {
+	case "", "md5":
+		h = md5.New() //nolint:gosec // see import
+	case "sha256":
+		h = sha256.New()
+	default:
+		return fmt.Errorf("unsupported checksum type %q", checksumType)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open image: %w", err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("failed to read image: %w", err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, checksum) {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", path, got, checksum)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package firmware
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // test digest
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// fakeOLT is a MockDriver that installs firmware and reboots
+type fakeOLT struct {
+	*testutil.MockDriver
+	version   string
+	staged    string
+	active    string
+	alarms    []types.OLTAlarm
+	stageErr  error
+	rebooted  bool
+	bootsInto string // overrides the activated version after reboot
+}
+
+func (f *fakeOLT) FirmwareVersion(context.Context) (string, error) { return f.version, nil }
+
+func (f *fakeOLT) StageFirmware(_ context.Context, image *types.FirmwareImage) error {
+	if f.stageErr != nil {
+		return f.stageErr
+	}
+	f.staged = image.Version
+	return nil
+}
+
+func (f *fakeOLT) ActivateFirmware(context.Context, *types.FirmwareImage) error {
+	f.active = f.staged
+	return nil
+}
+
+func (f *fakeOLT) GetAlarms(context.Context) ([]types.OLTAlarm, error) { return f.alarms, nil }
+
+func (f *fakeOLT) RestartOLT(context.Context) (*types.RestartOLTResult, error) {
+	f.rebooted = true
+	f.version = f.active
+	if f.bootsInto != "" {
+		f.version = f.bootsInto
+	}
+	return &types.RestartOLTResult{Success: true}, nil
+}
+
+func newFakeOLT(version string) *fakeOLT {
+	return &fakeOLT{MockDriver: &testutil.MockDriver{Connected: true}, version: version}
+}
+
+func testOptions(stages *[]Stage) Options {
+	return Options{
+		PollInterval:  time.Millisecond,
+		RebootTimeout: time.Second,
+		Progress: func(p Progress) {
+			if p.Err == nil {
+				*stages = append(*stages, p.Stage)
+			}
+		},
+	}
+}
+
+var testImage = &types.FirmwareImage{Version: "V2.1.7", URL: "tftp://192.0.2.10/fw.bin"}
+
+func TestUpgrade(t *testing.T) {
+	olt := newFakeOLT("V2.1.6")
+	config := testutil.NewTestEquipmentConfig(types.VendorVSOL, "192.0.2.1")
+	var stages []Stage
+
+	res, err := Upgrade(context.Background(), olt, config, testImage, testOptions(&stages))
+	if err != nil {
+		t.Fatalf("Upgrade() error = %v", err)
+	}
+	if res.PreviousVersion != "V2.1.6" || res.Version != "V2.1.7" || !res.Rebooted || res.Skipped {
+		t.Errorf("result = %+v", res)
+	}
+	if !olt.IsConnected() {
+		t.Error("driver not reconnected after reboot")
+	}
+	want := []Stage{StagePrecheck, StageStage, StageActivate, StageReboot, StageWaitOnline, StageVerifyVersion, StageDone}
+	if len(stages) != len(want) {
+		t.Fatalf("stages = %v, want %v", stages, want)
+	}
+	for i := range want {
+		if stages[i] != want[i] {
+			t.Errorf("stage[%d] = %s, want %s", i, stages[i], want[i])
+		}
+	}
+}
+
+func TestUpgrade_Skip(t *testing.T) {
+	olt := newFakeOLT("V2.1.7")
+	config := testutil.NewTestEquipmentConfig(types.VendorVSOL, "192.0.2.1")
+	var stages []Stage
+
+	res, err := Upgrade(context.Background(), olt, config, testImage, testOptions(&stages))
+	if err != nil {
+		t.Fatalf("Upgrade() error = %v", err)
+	}
+	if !res.Skipped || olt.staged != "" || olt.rebooted {
+		t.Errorf("expected skip without staging, got %+v", res)
+	}
+}
+
+func TestUpgrade_ActiveAlarms(t *testing.T) {
+	olt := newFakeOLT("V2.1.6")
+	olt.alarms = []types.OLTAlarm{{Type: "los", Severity: "critical"}}
+	config := testutil.NewTestEquipmentConfig(types.VendorVSOL, "192.0.2.1")
+	var stages []Stage
+
+	_, err := Upgrade(context.Background(), olt, config, testImage, testOptions(&stages))
+	if !errors.Is(err, ErrActiveAlarms) {
+		t.Fatalf("Upgrade() error = %v, want ErrActiveAlarms", err)
+	}
+	if olt.staged != "" {
+		t.Error("image staged despite active alarms")
+	}
+
+	cleared := time.Now()
+	olt.alarms = []types.OLTAlarm{{Type: "los", Severity: "critical", ClearedAt: &cleared}, {Type: "dying_gasp", Severity: "minor"}}
+	if _, err := Upgrade(context.Background(), olt, config, testImage, testOptions(&stages)); err != nil {
+		t.Errorf("cleared and minor alarms should not block: %v", err)
+	}
+
+	olt = newFakeOLT("V2.1.6")
+	olt.alarms = []types.OLTAlarm{{Type: "los", Severity: "Major"}}
+	opts := testOptions(&stages)
+	opts.Force = true
+	if _, err := Upgrade(context.Background(), olt, config, testImage, opts); err != nil {
+		t.Errorf("forced upgrade error = %v", err)
+	}
+}
+
+func TestUpgrade_NoReboot(t *testing.T) {
+	olt := newFakeOLT("V2.1.6")
+	config := testutil.NewTestEquipmentConfig(types.VendorVSOL, "192.0.2.1")
+	var stages []Stage
+	opts := testOptions(&stages)
+	opts.NoReboot = true
+
+	res, err := Upgrade(context.Background(), olt, config, testImage, opts)
+	if err != nil {
+		t.Fatalf("Upgrade() error = %v", err)
+	}
+	if olt.rebooted || res.Rebooted || olt.active != "V2.1.7" {
+		t.Errorf("expected activation without reboot, got %+v", res)
+	}
+}
+
+func TestUpgrade_Failures(t *testing.T) {
+	config := testutil.NewTestEquipmentConfig(types.VendorVSOL, "192.0.2.1")
+
+	t.Run("stage", func(t *testing.T) {
+		olt := newFakeOLT("V2.1.6")
+		olt.stageErr = errors.New("tftp timeout")
+		var failed Stage
+		opts := Options{Progress: func(p Progress) {
+			if p.Err != nil {
+				failed = p.Stage
+			}
+		}}
+		if _, err := Upgrade(context.Background(), olt, config, testImage, opts); err == nil {
+			t.Fatal("expected error")
+		}
+		if failed != StageStage || olt.rebooted {
+			t.Errorf("failed stage = %s, rebooted = %v", failed, olt.rebooted)
+		}
+	})
+
+	t.Run("version mismatch", func(t *testing.T) {
+		olt := newFakeOLT("V2.1.6")
+		olt.bootsInto = "V2.1.6" // fell back to the old image
+		var stages []Stage
+		res, err := Upgrade(context.Background(), olt, config, testImage, testOptions(&stages))
+		if err == nil {
+			t.Fatal("expected version mismatch error")
+		}
+		if res == nil || res.Version != "V2.1.6" || !res.Rebooted {
+			t.Errorf("result = %+v", res)
+		}
+	})
+
+	t.Run("not back online", func(t *testing.T) {
+		olt := newFakeOLT("V2.1.6")
+		olt.ConnectError = errors.New("connection refused")
+		var stages []Stage
+		opts := testOptions(&stages)
+		opts.RebootTimeout = 20 * time.Millisecond
+		if _, err := Upgrade(context.Background(), olt, config, testImage, opts); err == nil {
+			t.Fatal("expected timeout error")
+		}
+	})
+
+	t.Run("unsupported driver", func(t *testing.T) {
+		if _, err := Upgrade(context.Background(), &testutil.MockDriver{}, config, testImage, Options{}); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fw.bin")
+	content := []byte("firmware")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sum := md5.Sum(content) //nolint:gosec // test digest
+	digest := hex.EncodeToString(sum[:])
+
+	if err := VerifyChecksum(path, digest, ""); err != nil {
+		t.Errorf("VerifyChecksum() error = %v", err)
+	}
+	if err := VerifyChecksum(path, "00", "md5"); err == nil {
+		t.Error("expected checksum mismatch")
+	}
+	if err := VerifyChecksum(path, "", "md5"); err == nil {
+		t.Error("expected error for empty checksum")
+	}
+	if err := VerifyChecksum(path, digest, "crc32"); err == nil {
+		t.Error("expected error for unsupported checksum type")
+	}
+
+	olt := newFakeOLT("V2.1.6")
+	config := testutil.NewTestEquipmentConfig(types.VendorVSOL, "192.0.2.1")
+	image := *testImage
+	image.Path = path
+	image.Checksum = "00"
+	if _, err := Upgrade(context.Background(), olt, config, &image, Options{NoReboot: true}); err == nil || olt.staged != "" {
+		t.Errorf("expected checksum failure before staging, err = %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package southbound
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/events"
+)
+
+// HealthState is the reachability of a device as seen by a Prober
+type HealthState string
+
+const (
+	// HealthUnknown means the device was not probed yet
+	HealthUnknown HealthState = "unknown"
+	// HealthHealthy means the last probe succeeded
+	HealthHealthy HealthState = "healthy"
+	// HealthDegraded means the last probe failed or was slow, but the
+	// device has not failed UnreachableAfter probes in a row
+	HealthDegraded HealthState = "degraded"
+	// HealthUnreachable means the device failed UnreachableAfter probes in
+	// a row
+	HealthUnreachable HealthState = "unreachable"
+)
+
+// Defaults applied by NewProber for zero ProberConfig fields
+const (
+	DefaultProbeInterval      = 30 * time.Second
+	DefaultProbeTimeout       = 10 * time.Second
+	DefaultProbeConcurrency   = 16
+	DefaultUnreachableAfter   = 3
+	DefaultAvailabilityWindow = 100
+)
+
+// Health is the probe record of a device
+type Health struct {
+	State HealthState
+	Since time.Time // when State was entered
+
+	ConsecutiveFailures int
+	LastProbe           time.Time
+	LastSuccess         time.Time
+	LastLatency         time.Duration
+	LastErr             error
+
+	// Availability is the fraction of successful probes among the last
+	// AvailabilityWindow, 0 before the first probe
+	Availability float64
+}
+
+// health is the probe history of a Device, guarded by its own lock so
+// reading it does not wait on a connect in progress
+type health struct {
+	mu      sync.Mutex
+	current Health
+	results []bool // ring of the last probe outcomes
+	next    int
+}
+
+// Health returns the probe record of the device
+func (d *Device) Health() Health {
+	d.health.mu.Lock()
+	defer d.health.mu.Unlock()
+	h := d.health.current
+	if h.State == "" {
+		h.State = HealthUnknown
+	}
+	return h
+}
+
+// record adds a probe outcome and returns the health before and after it
+func (h *health) record(cfg *ProberConfig, at time.Time, latency time.Duration, err error) (Health, Health) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	prev := h.current
+	if prev.State == "" {
+		prev.State = HealthUnknown
+	}
+
+	if len(h.results) < cfg.AvailabilityWindow {
+		h.results = append(h.results, err == nil)
+	} else {
+		h.results[h.next] = err == nil
+		h.next = (h.next + 1) % len(h.results)
+	}
+	ok := 0
+	for _, r := range h.results {
+		if r {
+			ok++
+		}
+	}
+
+	cur := prev
+	cur.LastProbe = at
+	cur.LastLatency = latency
+	cur.LastErr = err
+	cur.Availability = float64(ok) / float64(len(h.results))
+	switch {
+	case err != nil:
+		cur.ConsecutiveFailures++
+		cur.State = HealthDegraded
+		if cur.ConsecutiveFailures >= cfg.UnreachableAfter {
+			cur.State = HealthUnreachable
+		}
+	case cfg.SlowAfter > 0 && latency > cfg.SlowAfter:
+		cur.ConsecutiveFailures = 0
+		cur.LastSuccess = at
+		cur.State = HealthDegraded
+	default:
+		cur.ConsecutiveFailures = 0
+		cur.LastSuccess = at
+		cur.State = HealthHealthy
+	}
+	if cur.State != prev.State {
+		cur.Since = at
+	}
+	h.current = cur
+	return prev, cur
+}
+
+// ProberConfig configures a Prober
+type ProberConfig struct {
+	// Interval between probe rounds. Defaults to DefaultProbeInterval.
+	Interval time.Duration
+
+	// Timeout bounds one HealthCheck, including reconnecting. Defaults to
+	// DefaultProbeTimeout.
+	Timeout time.Duration
+
+	// Concurrency is the number of devices probed at once. Defaults to
+	// DefaultProbeConcurrency.
+	Concurrency int
+
+	// UnreachableAfter is the number of consecutive failed probes after
+	// which a device is unreachable rather than degraded. Defaults to
+	// DefaultUnreachableAfter.
+	UnreachableAfter int
+
+	// SlowAfter marks a device degraded when a successful probe takes
+	// longer. Zero disables the latency check.
+	SlowAfter time.Duration
+
+	// AvailabilityWindow is the number of recent probes availability is
+	// computed over. Defaults to DefaultAvailabilityWindow.
+	AvailabilityWindow int
+
+	// Reconnect connects a device whose driver is not connected before
+	// probing it. Devices disconnected on request are never reconnected.
+	Reconnect bool
+}
+
+// Prober runs HealthCheck on the devices of a Registry periodically and
+// keeps the health of each device. Devices that were never connected or
+// were disconnected on request are not probed.
+type Prober struct {
+	registry  *Registry
+	publisher events.Publisher
+	config    ProberConfig
+}
+
+// NewProber creates a Prober for the devices of r. publisher may be nil.
+func NewProber(r *Registry, publisher events.Publisher, config ProberConfig) *Prober {
+	if config.Interval <= 0 {
+		config.Interval = DefaultProbeInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultProbeTimeout
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultProbeConcurrency
+	}
+	if config.UnreachableAfter <= 0 {
+		config.UnreachableAfter = DefaultUnreachableAfter
+	}
+	if config.AvailabilityWindow <= 0 {
+		config.AvailabilityWindow = DefaultAvailabilityWindow
+	}
+	return &Prober{registry: r, publisher: publisher, config: config}
+}
+
+// Run probes every device immediately and then every Interval until ctx is
+// cancelled
+func (p *Prober) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+	for {
+		p.ProbeAll(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProbeAll probes every registered device, at most Concurrency at a time,
+// and returns the probe errors keyed by device name
+func (p *Prober) ProbeAll(ctx context.Context) map[string]error {
+	sem := make(chan struct{}, p.config.Concurrency)
+	return p.registry.each(p.registry.List(), func(dev *Device) error {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		defer func() { <-sem }()
+		_, err := p.Probe(ctx, dev)
+		return err
+	})
+}
+
+// Probe runs HealthCheck on one device, updates its health and publishes
+// an events.TypeDeviceHealthChanged event if its state changed. Devices
+// that are not probed return their health unchanged.
+func (p *Prober) Probe(ctx context.Context, dev *Device) (Health, error) {
+	if state, _ := dev.State(); state == DeviceStateRegistered || state == DeviceStateDisconnected {
+		return dev.Health(), nil
+	}
+
+	start := time.Now()
+	err := p.check(ctx, dev)
+	prev, cur := dev.health.record(&p.config, start, time.Since(start), err)
+
+	if cur.State != prev.State {
+		dev.config.Log().Info("device health changed", "from", prev.State, "to", cur.State, "failures", cur.ConsecutiveFailures)
+		if p.publisher != nil {
+			data := map[string]any{
+				"from":         string(prev.State),
+				"to":           string(cur.State),
+				"failures":     cur.ConsecutiveFailures,
+				"availability": cur.Availability,
+			}
+			if err != nil {
+				data["error"] = err.Error()
+			}
+			p.publisher.Publish(events.Event{
+				Type:   events.TypeDeviceHealthChanged,
+				Device: dev.Name(),
+				Data:   data,
+			})
+		}
+	}
+	return cur, err
+}
+
+func (p *Prober) check(ctx context.Context, dev *Device) error {
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+
+	if p.config.Reconnect && !dev.driver.IsConnected() {
+		if err := dev.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if err := dev.driver.HealthCheck(ctx); err != nil {
+		return fmt.Errorf("health check of %s failed: %w", dev.Name(), err)
+	}
+	return nil
+}
+
+// ByHealth returns the devices in the given health state, sorted by name
+func (r *Registry) ByHealth(state HealthState) []*Device {
+	return r.Select(func(d *Device) bool { return d.Health().State == state })
+}
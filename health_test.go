@@ -0,0 +1,94 @@
+package southbound
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/events"
+)
+
+type recorder []events.Event
+
+func (r *recorder) Publish(e events.Event) { *r = append(*r, e) }
+
+func TestProber(t *testing.T) {
+	ctx := context.Background()
+	r, drivers := newTestRegistry(t)
+	if err := r.Connect(ctx, "olt-a"); err != nil {
+		t.Fatal(err)
+	}
+	var published recorder
+	p := NewProber(r, &published, ProberConfig{UnreachableAfter: 2, AvailabilityWindow: 4})
+
+	// Only olt-a was connected; the others are not probed
+	if errs := p.ProbeAll(ctx); len(errs) != 0 {
+		t.Fatalf("ProbeAll() errors = %v", errs)
+	}
+	dev, _ := r.Get("olt-a")
+	if h := dev.Health(); h.State != HealthHealthy || h.Availability != 1 {
+		t.Errorf("Health() = %+v, want healthy", h)
+	}
+	if h, _ := r.Get("olt-b"); h.Health().State != HealthUnknown {
+		t.Errorf("unprobed device state = %s", h.Health().State)
+	}
+
+	drivers["olt-a"].HealthCheckError = errors.New("timeout")
+	for _, want := range []HealthState{HealthDegraded, HealthUnreachable, HealthUnreachable} {
+		h, err := p.Probe(ctx, dev)
+		if err == nil || h.State != want {
+			t.Errorf("Probe() = %s, %v; want %s", h.State, err, want)
+		}
+	}
+	if got := names(r.ByHealth(HealthUnreachable)); got != "olt-a" {
+		t.Errorf("ByHealth(unreachable) = %s", got)
+	}
+
+	drivers["olt-a"].HealthCheckError = nil
+	h, _ := p.Probe(ctx, dev)
+	if h.State != HealthHealthy || h.ConsecutiveFailures != 0 {
+		t.Errorf("Probe() after recovery = %+v", h)
+	}
+	// The first success rolled out of the window of 4
+	if h.Availability != 0.25 {
+		t.Errorf("Availability = %v, want 0.25", h.Availability)
+	}
+
+	var transitions []string
+	for _, e := range published {
+		if e.Type != events.TypeDeviceHealthChanged || e.Device != "olt-a" {
+			t.Errorf("unexpected event %+v", e)
+		}
+		transitions = append(transitions, e.Data["to"].(string))
+	}
+	if len(transitions) != 4 || transitions[0] != "healthy" || transitions[1] != "degraded" ||
+		transitions[2] != "unreachable" || transitions[3] != "healthy" {
+		t.Errorf("transitions = %v", transitions)
+	}
+}
+
+func TestProberReconnect(t *testing.T) {
+	ctx := context.Background()
+	r, drivers := newTestRegistry(t)
+	if err := r.Connect(ctx, "olt-a"); err != nil {
+		t.Fatal(err)
+	}
+	drivers["olt-a"].Connected = false // dropped by the device
+	if err := r.Connect(ctx, "olt-b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Disconnect(ctx, "olt-b"); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewProber(r, nil, ProberConfig{Reconnect: true})
+	if errs := p.ProbeAll(ctx); len(errs) != 0 {
+		t.Fatalf("ProbeAll() errors = %v", errs)
+	}
+	if !drivers["olt-a"].IsConnected() {
+		t.Error("dropped device was not reconnected")
+	}
+	if drivers["olt-b"].IsConnected() {
+		t.Error("device disconnected on request was reconnected")
+	}
+}
@@ -0,0 +1,102 @@
+package inventory
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Table is a kind of equipment written as one CSV table
+type Table string
+
+// Tables of a snapshot
+const (
+	TableOLTs   Table = "olts"
+	TableBoards Table = "boards"
+	TablePorts  Table = "ports"
+	TableONUs   Table = "onus"
+
+	TableTransceivers Table = "transceivers"
+)
+
+// Tables lists every table, in the order they are usually exported
+var Tables = []Table{TableOLTs, TableBoards, TablePorts, TableTransceivers, TableONUs}
+
+// WriteJSON writes the snapshot as indented JSON
+func (s *Snapshot) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return fmt.Errorf("failed to write inventory: %w", err)
+	}
+	return nil
+}
+
+// WriteCSV writes one table of the snapshot as CSV with a header row. Rows
+// of every table start with the OLT name.
+func (s *Snapshot) WriteCSV(w io.Writer, table Table) error {
+	var rows [][]string
+	switch table {
+	case TableOLTs:
+		rows = append(rows, []string{"olt", "vendor", "address", "model", "firmware", "serial_number", "boards", "ports", "onus", "errors"})
+		for _, o := range s.OLTs {
+			rows = append(rows, []string{o.Name, o.Vendor, o.Address, o.Model, o.Firmware, o.SerialNumber,
+				strconv.Itoa(len(o.Boards)), strconv.Itoa(len(o.Ports)), strconv.Itoa(len(o.ONUs)), strconv.Itoa(len(o.Errors))})
+		}
+	case TableBoards:
+		rows = append(rows, []string{"olt", "slot", "type", "status", "online", "serial_number", "hardware", "firmware", "ports"})
+		for _, o := range s.OLTs {
+			for _, b := range o.Boards {
+				rows = append(rows, []string{o.Name, b.Slot, b.Type, b.Status, strconv.FormatBool(b.Online),
+					b.SerialNumber, b.Hardware, b.Firmware, strconv.Itoa(b.Ports)})
+			}
+		}
+	case TablePorts:
+		rows = append(rows, []string{"olt", "port", "admin_state", "oper_state", "onu_count", "max_onus", "tx_power_dbm", "description"})
+		for _, o := range s.OLTs {
+			for _, p := range o.Ports {
+				rows = append(rows, []string{o.Name, p.Port, p.AdminState, p.OperState, strconv.Itoa(p.ONUCount),
+					strconv.Itoa(p.MaxONUs), formatFloat(p.TxPowerDBm), p.Description})
+			}
+		}
+	case TableTransceivers:
+		rows = append(rows, []string{"olt", "port", "port_type", "present", "type", "vendor", "part_number", "serial_number",
+			"wavelength_nm", "temperature_c", "tx_power_dbm", "rx_power_dbm"})
+		for _, o := range s.OLTs {
+			for _, t := range o.Transceivers {
+				rows = append(rows, []string{o.Name, t.Port, t.PortType, strconv.FormatBool(t.Present), t.Type, t.Vendor,
+					t.PartNumber, t.SerialNumber, strconv.Itoa(t.WavelengthNM), formatFloat(t.TemperatureC),
+					formatFloat(t.TxPowerDBm), formatFloat(t.RxPowerDBm)})
+			}
+		}
+	case TableONUs:
+		rows = append(rows, []string{"olt", "pon_port", "onu_id", "serial", "mac", "vendor", "model", "firmware",
+			"admin_state", "oper_state", "online", "rx_power_dbm", "tx_power_dbm", "distance_m",
+			"line_profile", "service_profile", "vlan"})
+		for _, o := range s.OLTs {
+			for _, u := range o.ONUs {
+				rows = append(rows, []string{o.Name, u.PONPort, strconv.Itoa(u.ONUID), u.Serial, u.MAC, u.Vendor, u.Model,
+					u.Firmware, u.AdminState, u.OperState, strconv.FormatBool(u.Online), formatFloat(u.RxPowerDBm),
+					formatFloat(u.TxPowerDBm), strconv.Itoa(u.DistanceM), u.LineProfile, u.ServiceProfile, strconv.Itoa(u.VLAN)})
+			}
+		}
+	default:
+		return fmt.Errorf("unknown inventory table %q", table)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write %s table: %w", table, err)
+	}
+	return nil
+}
+
+// formatFloat formats a reading, leaving readings that were not taken empty
+func formatFloat(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
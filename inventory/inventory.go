@@ -0,0 +1,288 @@
+// Package inventory takes snapshots of the equipment managed through the
+// adapters: OLTs, their boards and PON ports, and every ONU with its
+// serial, model, firmware and optical levels. Snapshots are written as
+// JSON, or as one CSV table per kind of equipment, for asset systems and
+// audits.
+//
+// Collection is best effort. Whatever an adapter does not support is left
+// out, and failures are recorded on the OLT instead of failing the
+// snapshot.
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/workers"
+)
+
+// Defaults applied by New for zero Config fields
+const (
+	DefaultConcurrency = 8
+	DefaultTimeout     = 5 * time.Minute
+)
+
+// Config configures a Collector
+type Config struct {
+	// Concurrency is the number of devices read at once when Pool is nil.
+	// Defaults to DefaultConcurrency.
+	Concurrency int
+
+	// Timeout bounds the collection of one device, including connecting.
+	// Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// ONUFirmware reads each ONU's firmware version, one request per ONU,
+	// from adapters with GetONUFirmwareStatus
+	ONUFirmware bool
+
+	// Pool bounds the devices read at once. If nil, a pool of Concurrency
+	// with one task per device is used.
+	Pool *workers.Pool
+}
+
+// Snapshot is the inventory at a point in time
+type Snapshot struct {
+	TakenAt time.Time `json:"taken_at"`
+	OLTs    []OLT     `json:"olts"`
+}
+
+// OLT is the inventory of one device
+type OLT struct {
+	Name         string `json:"name"`
+	Vendor       string `json:"vendor"`
+	Address      string `json:"address"`
+	Model        string `json:"model,omitempty"`
+	Firmware     string `json:"firmware,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+
+	Boards       []types.BoardInfo       `json:"boards,omitempty"`
+	Ports        []types.PONPortStatus   `json:"ports,omitempty"`
+	Transceivers []types.TransceiverInfo `json:"transceivers,omitempty"`
+	ONUs         []ONU                   `json:"onus,omitempty"`
+
+	// Errors lists what could not be read
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ONU is the inventory of one ONU
+type ONU struct {
+	PONPort        string  `json:"pon_port"`
+	ONUID          int     `json:"onu_id"`
+	Serial         string  `json:"serial"`
+	MAC            string  `json:"mac,omitempty"`
+	Vendor         string  `json:"vendor,omitempty"`
+	Model          string  `json:"model,omitempty"`
+	Firmware       string  `json:"firmware,omitempty"`
+	AdminState     string  `json:"admin_state,omitempty"`
+	OperState      string  `json:"oper_state,omitempty"`
+	Online         bool    `json:"online"`
+	RxPowerDBm     float64 `json:"rx_power_dbm,omitempty"`
+	TxPowerDBm     float64 `json:"tx_power_dbm,omitempty"`
+	DistanceM      int     `json:"distance_m,omitempty"`
+	LineProfile    string  `json:"line_profile,omitempty"`
+	ServiceProfile string  `json:"service_profile,omitempty"`
+	VLAN           int     `json:"vlan,omitempty"`
+}
+
+// bulkOpticalReader is implemented by adapters that read the optical
+// levels of every ONU in one operation
+type bulkOpticalReader interface {
+	GetBulkONUOpticalSNMP(ctx context.Context) (map[string]*types.ONUPowerReading, error)
+}
+
+// onuFirmwareReader is the read side of types.ONUFirmwareManager
+type onuFirmwareReader interface {
+	GetONUFirmwareStatus(ctx context.Context, ponPort string, onuID int) (*types.ONUFirmwareStatus, error)
+}
+
+// Collector takes inventory snapshots of a set of devices
+type Collector struct {
+	devices []*types.EquipmentConfig
+	factory types.DriverFactory
+	config  Config
+}
+
+// New creates a Collector. A driver that is not connected is connected for
+// the snapshot and disconnected after.
+func New(devices []*types.EquipmentConfig, factory types.DriverFactory, config Config) *Collector {
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Pool == nil {
+		config.Pool = workers.New(config.Concurrency, 1)
+	}
+	return &Collector{devices: devices, factory: factory, config: config}
+}
+
+// Snapshot reads the inventory of every device, OLTs in device order
+func (c *Collector) Snapshot(ctx context.Context) *Snapshot {
+	snap := &Snapshot{TakenAt: time.Now().UTC(), OLTs: make([]OLT, len(c.devices))}
+	keys := make([]string, len(c.devices))
+	for i, d := range c.devices {
+		keys[i] = d.Name
+	}
+	errs := c.config.Pool.Each(ctx, keys, func(i int) error {
+		snap.OLTs[i] = c.collect(ctx, c.devices[i])
+		return nil
+	})
+	for i, err := range errs {
+		if err != nil {
+			snap.OLTs[i] = newOLT(c.devices[i])
+			snap.OLTs[i].Errors = []string{err.Error()}
+		}
+	}
+	return snap
+}
+
+func newOLT(config *types.EquipmentConfig) OLT {
+	return OLT{Name: config.Name, Vendor: string(config.Vendor), Address: config.Address}
+}
+
+// collect reads the inventory of one device
+func (c *Collector) collect(ctx context.Context, config *types.EquipmentConfig) OLT {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	olt := newOLT(config)
+	fail := func(what string, err error) {
+		config.Log().Warn("inventory read failed", "what", what, "error", err)
+		olt.Errors = append(olt.Errors, fmt.Sprintf("%s: %v", what, err))
+	}
+
+	driver, err := c.factory(config)
+	if err != nil {
+		fail("driver", err)
+		return olt
+	}
+	if !driver.IsConnected() {
+		if err := driver.Connect(ctx, config); err != nil {
+			fail("connect", err)
+			return olt
+		}
+		defer driver.Disconnect(context.WithoutCancel(ctx)) //nolint:errcheck // best effort
+	}
+
+	v2, ok := driver.(types.DriverV2)
+	if !ok {
+		return olt
+	}
+
+	if status, err := v2.GetOLTStatus(ctx); err != nil {
+		fail("status", err)
+	} else {
+		olt.Model = status.Model
+		olt.Firmware = status.Firmware
+		olt.SerialNumber = status.SerialNumber
+	}
+
+	if b, ok := driver.(types.BoardInventory); ok {
+		if olt.Boards, err = b.GetBoards(ctx); err != nil {
+			fail("boards", err)
+		}
+	}
+
+	if t, ok := driver.(types.TransceiverInventory); ok {
+		if olt.Transceivers, err = t.GetTransceivers(ctx); err != nil {
+			fail("transceivers", err)
+		}
+	}
+
+	if ports, err := v2.ListPorts(ctx); err != nil {
+		fail("ports", err)
+	} else {
+		for _, p := range ports {
+			olt.Ports = append(olt.Ports, *p)
+		}
+		sort.Slice(olt.Ports, func(i, j int) bool { return olt.Ports[i].Port < olt.Ports[j].Port })
+	}
+
+	onus, err := v2.GetONUList(ctx, nil)
+	if err != nil {
+		fail("onus", err)
+		return olt
+	}
+	olt.ONUs = make([]ONU, len(onus))
+	for i, o := range onus {
+		olt.ONUs[i] = ONU{
+			PONPort:        o.PONPort,
+			ONUID:          o.ONUID,
+			Serial:         o.Serial,
+			MAC:            o.MAC,
+			Vendor:         o.Vendor,
+			Model:          o.Model,
+			AdminState:     o.AdminState,
+			OperState:      o.OperState,
+			Online:         o.IsOnline,
+			RxPowerDBm:     o.RxPowerDBm,
+			TxPowerDBm:     o.TxPowerDBm,
+			DistanceM:      o.DistanceM,
+			LineProfile:    o.LineProfile,
+			ServiceProfile: o.ServiceProfile,
+			VLAN:           o.VLAN,
+		}
+	}
+	sort.Slice(olt.ONUs, func(i, j int) bool {
+		if olt.ONUs[i].PONPort != olt.ONUs[j].PONPort {
+			return olt.ONUs[i].PONPort < olt.ONUs[j].PONPort
+		}
+		return olt.ONUs[i].ONUID < olt.ONUs[j].ONUID
+	})
+
+	if bulk, ok := driver.(bulkOpticalReader); ok {
+		if readings, err := bulk.GetBulkONUOpticalSNMP(ctx); err != nil {
+			fail("optical", err)
+		} else {
+			mergeOptical(olt.ONUs, readings)
+		}
+	}
+
+	if fm, ok := driver.(onuFirmwareReader); ok && c.config.ONUFirmware {
+		for i := range olt.ONUs {
+			onu := &olt.ONUs[i]
+			status, err := fm.GetONUFirmwareStatus(ctx, onu.PONPort, onu.ONUID)
+			if err != nil {
+				fail(fmt.Sprintf("firmware of %s:%d", onu.PONPort, onu.ONUID), err)
+				if ctx.Err() != nil {
+					break
+				}
+				continue
+			}
+			onu.Firmware = status.Version
+		}
+	}
+	return olt
+}
+
+// mergeOptical fills in the optical levels the ONU list did not carry
+func mergeOptical(onus []ONU, readings map[string]*types.ONUPowerReading) {
+	type key struct {
+		port string
+		id   int
+	}
+	byONU := make(map[key]*types.ONUPowerReading, len(readings))
+	for _, r := range readings {
+		byONU[key{r.PONPort, r.ONUID}] = r
+	}
+	for i := range onus {
+		r := byONU[key{onus[i].PONPort, onus[i].ONUID}]
+		if r == nil {
+			continue
+		}
+		if onus[i].RxPowerDBm == 0 {
+			onus[i].RxPowerDBm = r.RxPowerDBm
+		}
+		if onus[i].TxPowerDBm == 0 {
+			onus[i].TxPowerDBm = r.TxPowerDBm
+		}
+		if onus[i].DistanceM == 0 {
+			onus[i].DistanceM = r.DistanceM
+		}
+	}
+}
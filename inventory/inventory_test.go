@@ -0,0 +1,121 @@
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// chassisOLT is a MockDriverV2 with boards, optical modules and a bulk
+// optical read
+type chassisOLT struct {
+	*testutil.MockDriverV2
+}
+
+func (c chassisOLT) GetBoards(context.Context) ([]types.BoardInfo, error) {
+	return []types.BoardInfo{{Slot: "0/1", Type: "H901GPHF", Status: "normal", Online: true, Ports: 16}}, nil
+}
+
+func (c chassisOLT) GetTransceivers(context.Context) ([]types.TransceiverInfo, error) {
+	return []types.TransceiverInfo{{Port: "0/1/1", PortType: types.TransceiverPortPON, Present: true, Vendor: "HUAWEI", TxPowerDBm: 4.2}}, nil
+}
+
+func (c chassisOLT) GetBulkONUOpticalSNMP(context.Context) (map[string]*types.ONUPowerReading, error) {
+	return map[string]*types.ONUPowerReading{
+		"0/1:2": {PONPort: "0/1", ONUID: 2, RxPowerDBm: -23.5, TxPowerDBm: 2.1, DistanceM: 1200},
+	}, nil
+}
+
+func (c chassisOLT) GetONUFirmwareStatus(_ context.Context, ponPort string, onuID int) (*types.ONUFirmwareStatus, error) {
+	if onuID == 2 {
+		return nil, errors.New("no answer")
+	}
+	return &types.ONUFirmwareStatus{PONPort: ponPort, ONUID: onuID, Version: "V3R017C10"}, nil
+}
+
+func TestSnapshot(t *testing.T) {
+	olt := chassisOLT{&testutil.MockDriverV2{
+		OLT:   types.OLTStatus{Model: "MA5800-X7", Firmware: "V100R019", SerialNumber: "2102351"},
+		Ports: []types.PONPortStatus{{Port: "0/1/1", AdminState: "enabled", MaxONUs: 128}},
+		ONUs: []types.ONUInfo{
+			{PONPort: "0/1", ONUID: 2, Serial: "HWTC00000002", Model: "HG8245H", IsOnline: true},
+			{PONPort: "0/1", ONUID: 1, Serial: "HWTC00000001", RxPowerDBm: -19.8},
+		},
+	}}
+	devices := []*types.EquipmentConfig{
+		{Name: "olt-1", Vendor: types.VendorHuawei, Address: "192.0.2.1"},
+		{Name: "olt-2", Vendor: types.VendorVSOL, Address: "192.0.2.2"},
+	}
+	factory := func(c *types.EquipmentConfig) (types.Driver, error) {
+		if c.Name == "olt-2" {
+			return nil, errors.New("unsupported")
+		}
+		return olt, nil
+	}
+
+	snap := New(devices, factory, Config{ONUFirmware: true}).Snapshot(context.Background())
+	if len(snap.OLTs) != 2 {
+		t.Fatalf("OLTs = %d", len(snap.OLTs))
+	}
+	got := snap.OLTs[0]
+	if got.Model != "MA5800-X7" || len(got.Boards) != 1 || len(got.Ports) != 1 || len(got.Transceivers) != 1 || len(got.ONUs) != 2 {
+		t.Fatalf("olt-1 = %+v", got)
+	}
+	if got.ONUs[0].Serial != "HWTC00000001" || got.ONUs[0].RxPowerDBm != -19.8 {
+		t.Errorf("first ONU = %+v", got.ONUs[0])
+	}
+	if got.ONUs[1].RxPowerDBm != -23.5 || got.ONUs[1].DistanceM != 1200 {
+		t.Errorf("bulk optical not merged: %+v", got.ONUs[1])
+	}
+	if got.ONUs[0].Firmware == "" || got.ONUs[1].Firmware != "" || len(got.Errors) != 1 {
+		t.Errorf("firmware = %q, %q; errors = %v", got.ONUs[0].Firmware, got.ONUs[1].Firmware, got.Errors)
+	}
+	if olt.IsConnected() {
+		t.Error("driver left connected")
+	}
+	if e := snap.OLTs[1].Errors; len(e) != 1 || !strings.Contains(e[0], "unsupported") {
+		t.Errorf("olt-2 errors = %v", e)
+	}
+
+	var buf bytes.Buffer
+	if err := snap.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Snapshot
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.OLTs[0].ONUs) != 2 {
+		t.Errorf("JSON round trip = %+v, %v", decoded, err)
+	}
+
+	buf.Reset()
+	if err := snap.WriteCSV(&buf, TableONUs); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "olt,pon_port,onu_id,serial") ||
+		!strings.HasPrefix(lines[2], "olt-1,0/1,2,HWTC00000002,,,HG8245H,,") || !strings.Contains(lines[2], ",-23.50,2.10,1200,") {
+		t.Errorf("ONU table:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := snap.WriteCSV(&buf, TableTransceivers); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 || lines[1] != "olt-1,0/1/1,pon,true,,HUAWEI,,,0,,4.20," {
+		t.Errorf("transceiver table:\n%s", buf.String())
+	}
+
+	for _, table := range Tables {
+		if err := snap.WriteCSV(&bytes.Buffer{}, table); err != nil {
+			t.Errorf("WriteCSV(%s) error = %v", table, err)
+		}
+	}
+	if err := snap.WriteCSV(&bytes.Buffer{}, "splitters"); err == nil {
+		t.Error("WriteCSV() of an unknown table succeeded")
+	}
+}
@@ -0,0 +1,288 @@
+// Package lifecycle tracks the operational state of ONUs over time. It
+// ingests successive ONU lists, records each ONU's state in a state.Store
+// and reports transitions with the time spent in the previous state, the
+// time an ONU took to recover, and whether it is flapping.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/events"
+	"github.com/nanoncore/nano-southbound/state"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// State is the operational state of an ONU
+type State string
+
+// ONU states
+const (
+	StateWorking   State = "working"
+	StateLOS       State = "los"
+	StateDyingGasp State = "dying_gasp"
+	StateOffline   State = "offline"
+)
+
+// Defaults applied by New for zero Config fields
+const (
+	DefaultFlapWindow    = 10 * time.Minute
+	DefaultFlapThreshold = 4
+)
+
+// keyPrefix is the state.Store namespace of this package
+const keyPrefix = "lifecycle/"
+
+// StateOf maps the vendor oper state of an ONU to a State. V-SOL reports
+// "working", "los" and "dying_gasp"; most adapters only report "online" or
+// "offline", so unrecognized states fall back to IsOnline.
+func StateOf(onu types.ONUInfo) State {
+	s := strings.ToLower(strings.NewReplacer("-", "_", " ", "_").Replace(onu.OperState))
+	switch {
+	case s == "working", s == "online", s == "up", s == "active":
+		return StateWorking
+	case strings.Contains(s, "los"), strings.Contains(s, "lof"):
+		return StateLOS
+	case strings.Contains(s, "dying"), s == "dg", s == "dgi":
+		return StateDyingGasp
+	case onu.IsOnline:
+		return StateWorking
+	default:
+		return StateOffline
+	}
+}
+
+// Record is the persisted lifecycle of one ONU
+type Record struct {
+	Device  string `json:"device"`
+	PONPort string `json:"pon_port"`
+	ONUID   int    `json:"onu_id"`
+	Serial  string `json:"serial,omitempty"`
+
+	State State     `json:"state"`
+	Since time.Time `json:"since"`
+
+	// DownSince is when the ONU left StateWorking, zero while working
+	DownSince time.Time `json:"down_since,omitempty"`
+
+	// Changes are the transition times within the flap window
+	Changes  []time.Time `json:"changes,omitempty"`
+	Flapping bool        `json:"flapping"`
+}
+
+// Transition is an ONU changing state
+type Transition struct {
+	Device  string    `json:"device"`
+	PONPort string    `json:"pon_port"`
+	ONUID   int       `json:"onu_id"`
+	Serial  string    `json:"serial,omitempty"`
+	From    State     `json:"from"`
+	To      State     `json:"to"`
+	At      time.Time `json:"at"`
+
+	// Duration is the time spent in From
+	Duration time.Duration `json:"duration"`
+
+	// TimeToRecover is set when the ONU returns to StateWorking: the time
+	// since it left it
+	TimeToRecover time.Duration `json:"time_to_recover,omitempty"`
+
+	// Flapping reports whether the ONU changed state at least
+	// FlapThreshold times within FlapWindow, including this transition
+	Flapping bool `json:"flapping"`
+}
+
+// Config configures a Tracker
+type Config struct {
+	// Store persists ONU records. Defaults to a state.MemoryStore.
+	Store state.Store
+
+	// Publisher receives an events.TypeONUStateChanged event per
+	// transition. Optional.
+	Publisher events.Publisher
+
+	// FlapWindow and FlapThreshold define flapping. Default to
+	// DefaultFlapWindow and DefaultFlapThreshold.
+	FlapWindow    time.Duration
+	FlapThreshold int
+}
+
+// Tracker records ONU state transitions
+type Tracker struct {
+	config Config
+}
+
+// New creates a Tracker
+func New(config Config) *Tracker {
+	if config.Store == nil {
+		config.Store = state.NewMemoryStore()
+	}
+	if config.FlapWindow <= 0 {
+		config.FlapWindow = DefaultFlapWindow
+	}
+	if config.FlapThreshold <= 0 {
+		config.FlapThreshold = DefaultFlapThreshold
+	}
+	return &Tracker{config: config}
+}
+
+// Observe records the ONU list of device read at time at and returns the
+// transitions since the previous observation. ONUs seen for the first time
+// are recorded without a transition. Calls for the same device must not
+// run concurrently.
+func (t *Tracker) Observe(ctx context.Context, device string, onus []types.ONUInfo, at time.Time) ([]Transition, error) {
+	var transitions []Transition
+	for _, onu := range onus {
+		tr, err := t.observe(ctx, device, onu, at)
+		if err != nil {
+			return transitions, err
+		}
+		if tr != nil {
+			transitions = append(transitions, *tr)
+		}
+	}
+	return transitions, nil
+}
+
+func (t *Tracker) observe(ctx context.Context, device string, onu types.ONUInfo, at time.Time) (*Transition, error) {
+	key := recordKey(device, onu.PONPort, onu.ONUID)
+	cur := StateOf(onu)
+
+	var rec Record
+	err := state.GetJSON(ctx, t.config.Store, key, &rec)
+	switch {
+	case errors.Is(err, state.ErrNotFound):
+		return nil, t.put(ctx, key, newRecord(device, onu, cur, at))
+	case err != nil:
+		return nil, fmt.Errorf("failed to load ONU %s:%d state: %w", onu.PONPort, onu.ONUID, err)
+	}
+
+	// An ONU replaced on the same port and ID starts a new history
+	if onu.Serial != "" && rec.Serial != "" && onu.Serial != rec.Serial {
+		return nil, t.put(ctx, key, newRecord(device, onu, cur, at))
+	}
+
+	rec.Changes = t.recent(rec.Changes, at)
+	if cur == rec.State {
+		if rec.Flapping && len(rec.Changes) < t.config.FlapThreshold {
+			rec.Flapping = false
+			return nil, t.put(ctx, key, &rec)
+		}
+		return nil, nil
+	}
+
+	tr := &Transition{
+		Device:   device,
+		PONPort:  onu.PONPort,
+		ONUID:    onu.ONUID,
+		Serial:   onu.Serial,
+		From:     rec.State,
+		To:       cur,
+		At:       at,
+		Duration: at.Sub(rec.Since),
+	}
+	switch {
+	case cur == StateWorking:
+		if !rec.DownSince.IsZero() {
+			tr.TimeToRecover = at.Sub(rec.DownSince)
+		}
+		rec.DownSince = time.Time{}
+	case rec.State == StateWorking:
+		rec.DownSince = at
+	}
+	rec.Changes = append(rec.Changes, at)
+	rec.Flapping = len(rec.Changes) >= t.config.FlapThreshold
+	tr.Flapping = rec.Flapping
+	rec.State, rec.Since = cur, at
+	if onu.Serial != "" {
+		rec.Serial = onu.Serial
+	}
+
+	if err := t.put(ctx, key, &rec); err != nil {
+		return nil, err
+	}
+	t.publish(tr)
+	return tr, nil
+}
+
+func newRecord(device string, onu types.ONUInfo, cur State, at time.Time) *Record {
+	rec := &Record{Device: device, PONPort: onu.PONPort, ONUID: onu.ONUID, Serial: onu.Serial, State: cur, Since: at}
+	if cur != StateWorking {
+		rec.DownSince = at
+	}
+	return rec
+}
+
+// Get returns the record of an ONU, or state.ErrNotFound
+func (t *Tracker) Get(ctx context.Context, device, ponPort string, onuID int) (*Record, error) {
+	var rec Record
+	if err := state.GetJSON(ctx, t.config.Store, recordKey(device, ponPort, onuID), &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// List returns the records of every ONU of device
+func (t *Tracker) List(ctx context.Context, device string) ([]Record, error) {
+	keys, err := t.config.Store.List(ctx, keyPrefix+device+"/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ONU states of %s: %w", device, err)
+	}
+	records := make([]Record, 0, len(keys))
+	for _, key := range keys {
+		var rec Record
+		if err := state.GetJSON(ctx, t.config.Store, key, &rec); err != nil {
+			if errors.Is(err, state.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// recent drops change times older than the flap window
+func (t *Tracker) recent(changes []time.Time, at time.Time) []time.Time {
+	cutoff := at.Add(-t.config.FlapWindow)
+	i := 0
+	for i < len(changes) && !changes[i].After(cutoff) {
+		i++
+	}
+	return changes[i:]
+}
+
+func (t *Tracker) put(ctx context.Context, key string, rec *Record) error {
+	if err := state.PutJSON(ctx, t.config.Store, key, rec); err != nil {
+		return fmt.Errorf("failed to save ONU %s:%d state: %w", rec.PONPort, rec.ONUID, err)
+	}
+	return nil
+}
+
+func (t *Tracker) publish(tr *Transition) {
+	if t.config.Publisher == nil {
+		return
+	}
+	t.config.Publisher.Publish(events.Event{
+		Type:   events.TypeONUStateChanged,
+		Device: tr.Device,
+		Time:   tr.At,
+		Data: map[string]any{
+			"pon_port":        tr.PONPort,
+			"onu_id":          tr.ONUID,
+			"serial":          tr.Serial,
+			"from":            string(tr.From),
+			"to":              string(tr.To),
+			"duration":        tr.Duration.Seconds(),
+			"time_to_recover": tr.TimeToRecover.Seconds(),
+			"flapping":        tr.Flapping,
+		},
+	})
+}
+
+func recordKey(device, ponPort string, onuID int) string {
+	return fmt.Sprintf("%s%s/%s:%d", keyPrefix, device, ponPort, onuID)
+}
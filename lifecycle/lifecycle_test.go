@@ -0,0 +1,115 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/events"
+	"github.com/nanoncore/nano-southbound/state"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func onu(state string) []types.ONUInfo {
+	return []types.ONUInfo{{PONPort: "0/1", ONUID: 5, Serial: "VSOL00000005", OperState: state}}
+}
+
+func TestStateOf(t *testing.T) {
+	tests := []struct {
+		onu  types.ONUInfo
+		want State
+	}{
+		{types.ONUInfo{OperState: "working"}, StateWorking},
+		{types.ONUInfo{OperState: "online", IsOnline: true}, StateWorking},
+		{types.ONUInfo{OperState: "LOS"}, StateLOS},
+		{types.ONUInfo{OperState: "dying_gasp"}, StateDyingGasp},
+		{types.ONUInfo{OperState: "Dying-Gasp"}, StateDyingGasp},
+		{types.ONUInfo{OperState: "offline"}, StateOffline},
+		{types.ONUInfo{OperState: "registered", IsOnline: true}, StateWorking},
+		{types.ONUInfo{}, StateOffline},
+	}
+	for _, tt := range tests {
+		if got := StateOf(tt.onu); got != tt.want {
+			t.Errorf("StateOf(%q, online=%v) = %s, want %s", tt.onu.OperState, tt.onu.IsOnline, got, tt.want)
+		}
+	}
+}
+
+func TestTransitions(t *testing.T) {
+	ctx := context.Background()
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe(func(e events.Event) { published = append(published, e) })
+	tr := New(Config{Publisher: bus})
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if got, err := tr.Observe(ctx, "olt-1", onu("working"), t0); err != nil || len(got) != 0 {
+		t.Fatalf("first observation = %+v, %v", got, err)
+	}
+	if got, _ := tr.Observe(ctx, "olt-1", onu("working"), t0.Add(time.Minute)); len(got) != 0 {
+		t.Fatalf("unchanged state produced %+v", got)
+	}
+
+	got, _ := tr.Observe(ctx, "olt-1", onu("los"), t0.Add(time.Hour))
+	if len(got) != 1 || got[0].From != StateWorking || got[0].To != StateLOS || got[0].Duration != time.Hour {
+		t.Fatalf("down transition = %+v", got)
+	}
+	tr.Observe(ctx, "olt-1", onu("dying_gasp"), t0.Add(time.Hour+time.Minute)) //nolint:errcheck // checked below
+	got, _ = tr.Observe(ctx, "olt-1", onu("working"), t0.Add(time.Hour+5*time.Minute))
+	if len(got) != 1 || got[0].TimeToRecover != 5*time.Minute || got[0].Duration != 4*time.Minute {
+		t.Fatalf("recovery = %+v, want 5m to recover after 4m in dying gasp", got)
+	}
+	if len(published) != 3 || published[2].Type != events.TypeONUStateChanged || published[2].Data["to"] != "working" {
+		t.Errorf("published %+v", published)
+	}
+
+	rec, err := tr.Get(ctx, "olt-1", "0/1", 5)
+	if err != nil || rec.State != StateWorking || !rec.DownSince.IsZero() || len(rec.Changes) != 3 {
+		t.Errorf("record = %+v, %v", rec, err)
+	}
+}
+
+func TestFlapping(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore()
+	tr := New(Config{Store: store, FlapWindow: 10 * time.Minute, FlapThreshold: 3})
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tr.Observe(ctx, "olt-1", onu("working"), t0) //nolint:errcheck // memory store
+	var last []Transition
+	for i, s := range []string{"los", "working", "los"} {
+		last, _ = tr.Observe(ctx, "olt-1", onu(s), t0.Add(time.Duration(i+1)*time.Minute))
+	}
+	if len(last) != 1 || !last[0].Flapping {
+		t.Fatalf("third change in window = %+v, want flapping", last)
+	}
+
+	// State is persisted, so a new tracker on the same store continues
+	tr = New(Config{Store: store, FlapWindow: 10 * time.Minute, FlapThreshold: 3})
+	tr.Observe(ctx, "olt-1", onu("los"), t0.Add(20*time.Minute)) //nolint:errcheck // memory store
+	rec, _ := tr.Get(ctx, "olt-1", "0/1", 5)
+	if rec.Flapping || len(rec.Changes) != 0 {
+		t.Errorf("record after quiet period = %+v, want flapping cleared", rec)
+	}
+
+	records, err := tr.List(ctx, "olt-1")
+	if err != nil || len(records) != 1 {
+		t.Errorf("List() = %+v, %v", records, err)
+	}
+}
+
+func TestReplacedONU(t *testing.T) {
+	ctx := context.Background()
+	tr := New(Config{})
+	t0 := time.Now()
+
+	tr.Observe(ctx, "olt-1", onu("working"), t0) //nolint:errcheck // memory store
+	replaced := []types.ONUInfo{{PONPort: "0/1", ONUID: 5, Serial: "VSOL00000099", OperState: "offline"}}
+	if got, _ := tr.Observe(ctx, "olt-1", replaced, t0.Add(time.Minute)); len(got) != 0 {
+		t.Errorf("replaced ONU produced %+v", got)
+	}
+	rec, _ := tr.Get(ctx, "olt-1", "0/1", 5)
+	if rec.Serial != "VSOL00000099" || rec.State != StateOffline {
+		t.Errorf("record = %+v", rec)
+	}
+}
@@ -0,0 +1,220 @@
+package maintenance
+
+import (
+	"context"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Driver is a DriverV2 that checks the policy before every write to the
+// wrapped adapter. Reads pass through. ExecCommand and ExecCommands are not
+// checked, as they may be either. Optional interfaces of the wrapped
+// adapter are not exposed; use Unwrap to reach them, bearing in mind that
+// writes made that way are not checked.
+type Driver struct {
+	types.DriverV2
+	policy *Policy
+	device string
+}
+
+var _ types.DriverV2 = (*Driver)(nil)
+
+// Wrap returns driver with writes to the named device checked against the
+// policy
+func (p *Policy) Wrap(driver types.DriverV2, device string) *Driver {
+	return &Driver{DriverV2: driver, policy: p, device: device}
+}
+
+// Unwrap returns the wrapped adapter
+func (d *Driver) Unwrap() types.DriverV2 {
+	return d.DriverV2
+}
+
+// Capabilities implements types.CapabilityReporter. Capabilities of
+// optional interfaces are dropped, as the wrapper does not expose them.
+func (d *Driver) Capabilities() types.Capabilities {
+	c := types.CapabilitiesOf(d.DriverV2)
+	detected := types.DetectCapabilities(d)
+	c.ONUStream, c.AutoFind = detected.ONUStream, detected.AutoFind
+	c.Boards, c.Transceivers = detected.Boards, detected.Transceivers
+	c.Environment, c.LineQuality = detected.Environment, detected.LineQuality
+	c.BulkSubscribers = detected.BulkSubscribers
+	c.ConfigBackup, c.ConfigRestore = detected.ConfigBackup, detected.ConfigRestore
+	c.FirmwareUpgrade, c.ONUFirmwareUpgrade = detected.FirmwareUpgrade, detected.ONUFirmwareUpgrade
+	c.WiFi, c.ONUWAN, c.ONULAN = detected.WiFi, detected.ONUWAN, detected.ONULAN
+	c.ONUEthPorts, c.Voice, c.ONUTests = detected.ONUEthPorts, detected.Voice, detected.ONUTests
+	c.RogueDetection = detected.RogueDetection
+	c.LineProfiles, c.ServiceProfiles = detected.LineProfiles, detected.ServiceProfiles
+	c.DBAProfiles = detected.DBAProfiles
+	c.TrafficProfiles, c.ONUProfiles = detected.TrafficProfiles, detected.ONUProfiles
+	return c
+}
+
+// CreateSubscriber implements types.Driver
+func (d *Driver) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (*types.SubscriberResult, error) {
+	if err := d.policy.Allow(ctx, d.device, "CreateSubscriber"); err != nil {
+		return nil, err
+	}
+	return d.DriverV2.CreateSubscriber(ctx, subscriber, tier)
+}
+
+// UpdateSubscriber implements types.Driver
+func (d *Driver) UpdateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) error {
+	if err := d.policy.Allow(ctx, d.device, "UpdateSubscriber"); err != nil {
+		return err
+	}
+	return d.DriverV2.UpdateSubscriber(ctx, subscriber, tier)
+}
+
+// DeleteSubscriber implements types.Driver
+func (d *Driver) DeleteSubscriber(ctx context.Context, subscriberID string) error {
+	if err := d.policy.Allow(ctx, d.device, "DeleteSubscriber"); err != nil {
+		return err
+	}
+	return d.DriverV2.DeleteSubscriber(ctx, subscriberID)
+}
+
+// SuspendSubscriber implements types.Driver
+func (d *Driver) SuspendSubscriber(ctx context.Context, subscriberID string) error {
+	if err := d.policy.Allow(ctx, d.device, "SuspendSubscriber"); err != nil {
+		return err
+	}
+	return d.DriverV2.SuspendSubscriber(ctx, subscriberID)
+}
+
+// ResumeSubscriber implements types.Driver
+func (d *Driver) ResumeSubscriber(ctx context.Context, subscriberID string) error {
+	if err := d.policy.Allow(ctx, d.device, "ResumeSubscriber"); err != nil {
+		return err
+	}
+	return d.DriverV2.ResumeSubscriber(ctx, subscriberID)
+}
+
+// RestartONU implements types.DriverV2
+func (d *Driver) RestartONU(ctx context.Context, ponPort string, onuID int) (*types.RestartONUResult, error) {
+	if err := d.policy.Allow(ctx, d.device, "RestartONU"); err != nil {
+		return nil, err
+	}
+	return d.DriverV2.RestartONU(ctx, ponPort, onuID)
+}
+
+// FactoryResetONU implements types.DriverV2
+func (d *Driver) FactoryResetONU(ctx context.Context, ponPort string, onuID int, opts *types.FactoryResetOptions) (*types.FactoryResetResult, error) {
+	if err := d.policy.Allow(ctx, d.device, "FactoryResetONU"); err != nil {
+		return nil, err
+	}
+	return d.DriverV2.FactoryResetONU(ctx, ponPort, onuID, opts)
+}
+
+// ApplyProfile implements types.DriverV2
+func (d *Driver) ApplyProfile(ctx context.Context, ponPort string, onuID int, profile *types.ONUProfile) error {
+	if err := d.policy.Allow(ctx, d.device, "ApplyProfile"); err != nil {
+		return err
+	}
+	return d.DriverV2.ApplyProfile(ctx, ponPort, onuID, profile)
+}
+
+// BulkProvision implements types.DriverV2
+func (d *Driver) BulkProvision(ctx context.Context, operations []types.BulkProvisionOp) (*types.BulkResult, error) {
+	if err := d.policy.Allow(ctx, d.device, "BulkProvision"); err != nil {
+		return nil, err
+	}
+	return d.DriverV2.BulkProvision(ctx, operations)
+}
+
+// RestartOLT implements types.DriverV2
+func (d *Driver) RestartOLT(ctx context.Context) (*types.RestartOLTResult, error) {
+	if err := d.policy.Allow(ctx, d.device, "RestartOLT"); err != nil {
+		return nil, err
+	}
+	return d.DriverV2.RestartOLT(ctx)
+}
+
+// SetPortState implements types.DriverV2
+func (d *Driver) SetPortState(ctx context.Context, port string, enabled bool) error {
+	if err := d.policy.Allow(ctx, d.device, "SetPortState"); err != nil {
+		return err
+	}
+	return d.DriverV2.SetPortState(ctx, port, enabled)
+}
+
+// CreateVLAN implements types.DriverV2
+func (d *Driver) CreateVLAN(ctx context.Context, req *types.CreateVLANRequest) error {
+	if err := d.policy.Allow(ctx, d.device, "CreateVLAN"); err != nil {
+		return err
+	}
+	return d.DriverV2.CreateVLAN(ctx, req)
+}
+
+// DeleteVLAN implements types.DriverV2
+func (d *Driver) DeleteVLAN(ctx context.Context, vlanID int, force bool) error {
+	if err := d.policy.Allow(ctx, d.device, "DeleteVLAN"); err != nil {
+		return err
+	}
+	return d.DriverV2.DeleteVLAN(ctx, vlanID, force)
+}
+
+// AddServicePort implements types.DriverV2
+func (d *Driver) AddServicePort(ctx context.Context, req *types.AddServicePortRequest) error {
+	if err := d.policy.Allow(ctx, d.device, "AddServicePort"); err != nil {
+		return err
+	}
+	return d.DriverV2.AddServicePort(ctx, req)
+}
+
+// DeleteServicePort implements types.DriverV2
+func (d *Driver) DeleteServicePort(ctx context.Context, ponPort string, ontID int) error {
+	if err := d.policy.Allow(ctx, d.device, "DeleteServicePort"); err != nil {
+		return err
+	}
+	return d.DriverV2.DeleteServicePort(ctx, ponPort, ontID)
+}
+
+// RestoreSubscriberConfig implements types.DriverV2
+func (d *Driver) RestoreSubscriberConfig(ctx context.Context, snapshot *types.SubscriberSnapshot, targetPONPort string, targetONUID int) (*types.SubscriberResult, error) {
+	if err := d.policy.Allow(ctx, d.device, "RestoreSubscriberConfig"); err != nil {
+		return nil, err
+	}
+	return d.DriverV2.RestoreSubscriberConfig(ctx, snapshot, targetPONPort, targetONUID)
+}
+
+// ReplaceONU implements types.DriverV2
+func (d *Driver) ReplaceONU(ctx context.Context, subscriberID string, newSerial string) (*types.ReplaceResult, error) {
+	if err := d.policy.Allow(ctx, d.device, "ReplaceONU"); err != nil {
+		return nil, err
+	}
+	return d.DriverV2.ReplaceONU(ctx, subscriberID, newSerial)
+}
+
+// SoftSuspendSubscriber implements types.DriverV2
+func (d *Driver) SoftSuspendSubscriber(ctx context.Context, subscriberID string, opts *types.SuspendOptions) (*types.SuspensionState, error) {
+	if err := d.policy.Allow(ctx, d.device, "SoftSuspendSubscriber"); err != nil {
+		return nil, err
+	}
+	return d.DriverV2.SoftSuspendSubscriber(ctx, subscriberID, opts)
+}
+
+// MoveSubscriber implements types.DriverV2
+func (d *Driver) MoveSubscriber(ctx context.Context, subscriberID string, targetPONPort string, targetONUID int) (*types.MoveResult, error) {
+	if err := d.policy.Allow(ctx, d.device, "MoveSubscriber"); err != nil {
+		return nil, err
+	}
+	return d.DriverV2.MoveSubscriber(ctx, subscriberID, targetPONPort, targetONUID)
+}
+
+// AddONUToSubscriber implements types.DriverV2
+func (d *Driver) AddONUToSubscriber(ctx context.Context, subscriberID string, binding model.ONUBinding, tier *model.ServiceTier) (*types.SubscriberResult, error) {
+	if err := d.policy.Allow(ctx, d.device, "AddONUToSubscriber"); err != nil {
+		return nil, err
+	}
+	return d.DriverV2.AddONUToSubscriber(ctx, subscriberID, binding, tier)
+}
+
+// RemoveONUFromSubscriber implements types.DriverV2
+func (d *Driver) RemoveONUFromSubscriber(ctx context.Context, subscriberID string, serial string) error {
+	if err := d.policy.Allow(ctx, d.device, "RemoveONUFromSubscriber"); err != nil {
+		return err
+	}
+	return d.DriverV2.RemoveONUFromSubscriber(ctx, subscriberID, serial)
+}
@@ -0,0 +1,197 @@
+// Package maintenance enforces maintenance windows: changes to a device
+// are only sent while one of its windows is open. Outside the windows a
+// write is either rejected or held until the next window opens.
+//
+// Writes made with a context from types.WithOverride go through at any
+// time; the override reason is recorded in their audit records.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// ErrFrozen is returned for writes outside the maintenance windows of the
+// device in ModeBlock
+var ErrFrozen = errors.New("outside maintenance window")
+
+// Mode is what happens to a write outside the maintenance windows
+type Mode string
+
+// Modes
+const (
+	// ModeBlock fails the write with ErrFrozen
+	ModeBlock Mode = "block"
+
+	// ModeQueue holds the write until the next window opens or its
+	// context is done
+	ModeQueue Mode = "queue"
+)
+
+// Window is a recurring maintenance window
+type Window struct {
+	// Days the window opens on. If empty, it opens every day.
+	Days []time.Weekday
+
+	// Start is the time of day the window opens, e.g. 2*time.Hour for
+	// 02:00
+	Start time.Duration
+
+	// Duration is how long the window stays open. Windows may run past
+	// midnight.
+	Duration time.Duration
+
+	// Location is the time zone of Days and Start. If nil, UTC is used.
+	Location *time.Location
+}
+
+// Contains reports whether the window is open at t
+func (w Window) Contains(t time.Time) bool {
+	t = t.In(w.location())
+	// A window that opened on one of the previous days may still be open
+	for back := 0; time.Duration(back)*24*time.Hour < w.Duration+24*time.Hour; back++ {
+		open := w.openOn(t.AddDate(0, 0, -back))
+		if !open.IsZero() && !t.Before(open) && t.Before(open.Add(w.Duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns when the window next opens after t, or the zero time if it
+// never does
+func (w Window) Next(t time.Time) time.Time {
+	if w.Duration <= 0 {
+		return time.Time{}
+	}
+	t = t.In(w.location())
+	for ahead := 0; ahead <= 7; ahead++ {
+		if open := w.openOn(t.AddDate(0, 0, ahead)); open.After(t) {
+			return open
+		}
+	}
+	return time.Time{}
+}
+
+// openOn returns when the window opens on the day of t, or the zero time
+// if it does not open that day
+func (w Window) openOn(t time.Time) time.Time {
+	if w.Duration <= 0 {
+		return time.Time{}
+	}
+	if len(w.Days) > 0 {
+		found := false
+		for _, d := range w.Days {
+			found = found || d == t.Weekday()
+		}
+		if !found {
+			return time.Time{}
+		}
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return midnight.Add(w.Start)
+}
+
+func (w Window) location() *time.Location {
+	if w.Location == nil {
+		return time.UTC
+	}
+	return w.Location
+}
+
+// Config configures a Policy
+type Config struct {
+	// Windows are the maintenance windows of each device by name
+	Windows map[string][]Window
+
+	// Default are the windows of devices not in Windows. If empty, those
+	// devices accept writes at any time.
+	Default []Window
+
+	// Mode is what happens to writes outside the windows. Defaults to
+	// ModeBlock.
+	Mode Mode
+}
+
+// Policy decides when writes to each device are allowed
+type Policy struct {
+	config Config
+	now    func() time.Time
+}
+
+// NewPolicy creates a Policy
+func NewPolicy(config Config) *Policy {
+	if config.Mode == "" {
+		config.Mode = ModeBlock
+	}
+	return &Policy{config: config, now: time.Now}
+}
+
+func (p *Policy) windows(device string) []Window {
+	if w, ok := p.config.Windows[device]; ok {
+		return w
+	}
+	return p.config.Default
+}
+
+// Open reports whether writes to device are allowed at t. Devices without
+// windows are always open.
+func (p *Policy) Open(device string, t time.Time) bool {
+	windows := p.windows(device)
+	if len(windows) == 0 {
+		return true
+	}
+	for _, w := range windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns when the next window of device opens after t, or the zero
+// time if none does
+func (p *Policy) Next(device string, t time.Time) time.Time {
+	var next time.Time
+	for _, w := range p.windows(device) {
+		if n := w.Next(t); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return next
+}
+
+// Allow is called before a write to device. It returns nil if the write
+// may proceed: a window is open or ctx carries an override. Otherwise it
+// fails with ErrFrozen in ModeBlock, and in ModeQueue waits for the next
+// window to open.
+func (p *Policy) Allow(ctx context.Context, device, operation string) error {
+	for {
+		now := p.now()
+		if p.Open(device, now) {
+			return nil
+		}
+		if types.OverrideFromContext(ctx) != "" {
+			return nil
+		}
+		next := p.Next(device, now)
+		if p.config.Mode != ModeQueue || next.IsZero() {
+			if next.IsZero() {
+				return fmt.Errorf("%s on %s: %w", operation, device, ErrFrozen)
+			}
+			return fmt.Errorf("%s on %s: %w, next opens %s", operation, device, ErrFrozen, next.Format(time.RFC3339))
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s on %s: waiting for maintenance window: %w", operation, device, ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestWindow(t *testing.T) {
+	// Saturday 23:00 for three hours
+	w := Window{Days: []time.Weekday{time.Saturday}, Start: 23 * time.Hour, Duration: 3 * time.Hour}
+	sat := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC) // a Saturday
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{sat.Add(22 * time.Hour), false},
+		{sat.Add(23 * time.Hour), true},
+		{sat.Add(25 * time.Hour), true}, // Sunday 01:00
+		{sat.Add(26 * time.Hour), false},
+		{sat.AddDate(0, 0, 1).Add(23 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		if got := w.Contains(tt.at); got != tt.want {
+			t.Errorf("Contains(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+
+	if got, want := w.Next(sat.Add(26*time.Hour)), sat.AddDate(0, 0, 7).Add(23*time.Hour); !got.Equal(want) {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+}
+
+func TestDriver(t *testing.T) {
+	mock := &testutil.MockDriverV2{ONUs: []types.ONUInfo{{PONPort: "0/1", ONUID: 1, Serial: "VSOL00000001"}}}
+	night := Window{Start: 2 * time.Hour, Duration: 2 * time.Hour}
+	policy := NewPolicy(Config{Windows: map[string][]Window{"olt-1": {night}}})
+	policy.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	frozen := policy.Wrap(mock, "olt-1")
+	if _, err := frozen.RestartONU(ctx, "0/1", 1); !errors.Is(err, ErrFrozen) {
+		t.Errorf("RestartONU outside window: err = %v", err)
+	}
+	if _, err := frozen.GetONUList(ctx, nil); err != nil {
+		t.Errorf("reads must pass: %v", err)
+	}
+	if _, err := frozen.RestartONU(types.WithOverride(ctx, "INC-1234"), "0/1", 1); err != nil {
+		t.Errorf("RestartONU with override: %v", err)
+	}
+
+	// Devices without windows are never frozen
+	if err := policy.Wrap(mock, "olt-2").CreateVLAN(ctx, &types.CreateVLANRequest{ID: 100}); err != nil {
+		t.Errorf("CreateVLAN on olt-2: %v", err)
+	}
+
+	policy.now = func() time.Time { return time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC) }
+	if _, err := frozen.RestartONU(ctx, "0/1", 1); err != nil {
+		t.Errorf("RestartONU inside window: %v", err)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	policy := NewPolicy(Config{Default: []Window{{Start: 2 * time.Hour, Duration: time.Hour}}, Mode: ModeQueue})
+	var now atomic.Int64
+	now.Store(time.Date(2024, 3, 1, 1, 59, 59, 950_000_000, time.UTC).UnixNano())
+	policy.now = func() time.Time { return time.Unix(0, now.Load()) }
+
+	// The write waits for the window, then goes through
+	done := make(chan error, 1)
+	go func() { done <- policy.Allow(context.Background(), "olt-1", "RestartOLT") }()
+	select {
+	case err := <-done:
+		t.Fatalf("write was not held: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	now.Add(int64(time.Second))
+	if err := <-done; err != nil {
+		t.Errorf("Allow = %v", err)
+	}
+
+	// A held write gives up with its context
+	now.Store(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).UnixNano())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := policy.Allow(ctx, "olt-1", "RestartOLT"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Allow = %v", err)
+	}
+}
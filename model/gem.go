@@ -0,0 +1,133 @@
+package model
+
+import "fmt"
+
+// DefaultServiceName is the OLT service name of GEM port 1 when
+// GEMPort.Service is not set
+const DefaultServiceName = "INTERNET"
+
+// TCont is an upstream bandwidth container (T-CONT) of a GPON ONU
+type TCont struct {
+	// ID is the T-CONT index on the ONU, from 1 to 8
+	ID int `json:"id"`
+
+	// DBAProfile is the name of the DBA profile of the T-CONT (optional,
+	// the tier bandwidth applies to T-CONT 1 otherwise)
+	DBAProfile string `json:"dbaProfile,omitempty"`
+}
+
+// GEMPort is a GEM port of a GPON ONU and the VLAN it carries
+type GEMPort struct {
+	// ID is the GEM port index on the ONU, from 1 to 32
+	ID int `json:"id"`
+
+	// TCont is the ID of the T-CONT carrying the GEM port upstream
+	TCont int `json:"tcont"`
+
+	// VLAN is the network-side VLAN ID
+	VLAN int `json:"vlan"`
+
+	// UserVLAN is the VLAN ID on the ONU side (default: VLAN)
+	UserVLAN int `json:"userVlan,omitempty"`
+
+	// SVLAN is the outer VLAN ID added on the network side (Q-in-Q,
+	// optional), VLAN being the inner C-VLAN
+	SVLAN int `json:"svlan,omitempty"`
+
+	// Service is the OLT service name (default: INTERNET for GEM port 1,
+	// GEM<ID> otherwise)
+	Service string `json:"service,omitempty"`
+}
+
+// GetUserVLAN returns the ONU-side VLAN, defaulting to VLAN
+func (g *GEMPort) GetUserVLAN() int {
+	if g.UserVLAN == 0 {
+		return g.VLAN
+	}
+	return g.UserVLAN
+}
+
+// GetService returns the OLT service name of the GEM port
+func (g *GEMPort) GetService() string {
+	switch {
+	case g.Service != "":
+		return g.Service
+	case g.ID == 1:
+		return DefaultServiceName
+	default:
+		return fmt.Sprintf("GEM%d", g.ID)
+	}
+}
+
+// GEMMapping returns the T-CONTs and GEM ports of the subscriber's ONU.
+// Without explicit GEMPorts this is T-CONT 1 and GEM port 1 carrying VLAN
+// in SVLAN, or nothing if VLAN is not set either.
+func (s *SubscriberSpec) GEMMapping() ([]TCont, []GEMPort) {
+	if len(s.GEMPorts) > 0 {
+		return s.TConts, s.GEMPorts
+	}
+	if s.VLAN <= 0 {
+		return nil, nil
+	}
+	return []TCont{{ID: 1}}, []GEMPort{{ID: 1, TCont: 1, VLAN: s.VLAN, SVLAN: s.GetSVLAN()}}
+}
+
+// ValidateGEMMapping checks that every GEM port is carried by a defined
+// T-CONT and that IDs, VLANs and service names are valid and unique. It
+// checks SVLAN too, which the default GEM port carries, and that the
+// multicast GEM port is defined.
+func (s *SubscriberSpec) ValidateGEMMapping() error {
+	if err := s.ValidateQinQ(); err != nil {
+		return err
+	}
+	if len(s.TConts) > 0 && len(s.GEMPorts) == 0 {
+		return fmt.Errorf("T-CONTs are configured without GEM ports")
+	}
+	tconts := make(map[int]bool)
+	for _, t := range s.TConts {
+		if t.ID < 1 || t.ID > 8 {
+			return fmt.Errorf("T-CONT id must be between 1 and 8")
+		}
+		if tconts[t.ID] {
+			return fmt.Errorf("T-CONT %d is configured twice", t.ID)
+		}
+		tconts[t.ID] = true
+		if err := validateCLIValue("DBA profile", t.DBAProfile, false); err != nil {
+			return err
+		}
+	}
+	gems := make(map[int]bool)
+	services := make(map[string]bool)
+	for _, g := range s.GEMPorts {
+		if g.ID < 1 || g.ID > 32 {
+			return fmt.Errorf("GEM port id must be between 1 and 32")
+		}
+		if gems[g.ID] {
+			return fmt.Errorf("GEM port %d is configured twice", g.ID)
+		}
+		gems[g.ID] = true
+		if !tconts[g.TCont] {
+			return fmt.Errorf("GEM port %d uses undefined T-CONT %d", g.ID, g.TCont)
+		}
+		if g.VLAN < 1 || g.VLAN > 4094 {
+			return fmt.Errorf("GEM port %d vlan must be between 1 and 4094", g.ID)
+		}
+		if u := g.GetUserVLAN(); u < 1 || u > 4094 {
+			return fmt.Errorf("GEM port %d user vlan must be between 1 and 4094", g.ID)
+		}
+		if g.SVLAN < 0 || g.SVLAN > 4094 {
+			return fmt.Errorf("GEM port %d svlan must be between 1 and 4094", g.ID)
+		}
+		if err := validateCLIValue("service name", g.Service, false); err != nil {
+			return err
+		}
+		if services[g.GetService()] {
+			return fmt.Errorf("service %s is configured twice", g.GetService())
+		}
+		services[g.GetService()] = true
+	}
+	if m := s.Multicast; m != nil && len(s.GEMPorts) > 0 && !gems[m.GetGEMPort()] {
+		return fmt.Errorf("multicast uses undefined GEM port %d", m.GetGEMPort())
+	}
+	return nil
+}
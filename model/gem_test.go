@@ -0,0 +1,76 @@
+package model
+
+import "testing"
+
+func TestValidateGEMMapping(t *testing.T) {
+	valid := func() *SubscriberSpec {
+		return &SubscriberSpec{
+			VLAN:   100,
+			TConts: []TCont{{ID: 1}, {ID: 2, DBAProfile: "dba_voice"}},
+			GEMPorts: []GEMPort{
+				{ID: 1, TCont: 1, VLAN: 100},
+				{ID: 2, TCont: 2, VLAN: 200, UserVLAN: 20},
+			},
+		}
+	}
+	tests := []struct {
+		name    string
+		modify  func(s *SubscriberSpec)
+		wantErr bool
+	}{
+		{"valid", func(s *SubscriberSpec) {}, false},
+		{"default mapping", func(s *SubscriberSpec) { s.TConts, s.GEMPorts = nil, nil }, false},
+		{"T-CONTs without GEM ports", func(s *SubscriberSpec) { s.GEMPorts = nil }, true},
+		{"duplicate T-CONT", func(s *SubscriberSpec) { s.TConts[1].ID = 1 }, true},
+		{"T-CONT out of range", func(s *SubscriberSpec) { s.TConts[1].ID = 9 }, true},
+		{"duplicate GEM port", func(s *SubscriberSpec) { s.GEMPorts[1].ID = 1 }, true},
+		{"undefined T-CONT", func(s *SubscriberSpec) { s.GEMPorts[1].TCont = 3 }, true},
+		{"missing vlan", func(s *SubscriberSpec) { s.GEMPorts[0].VLAN = 0 }, true},
+		{"duplicate service", func(s *SubscriberSpec) { s.GEMPorts[1].Service = "INTERNET" }, true},
+		{"service with space", func(s *SubscriberSpec) { s.GEMPorts[1].Service = "TV 1" }, true},
+		{"gem svlan", func(s *SubscriberSpec) { s.GEMPorts[1].SVLAN = 1000 }, false},
+		{"gem svlan out of range", func(s *SubscriberSpec) { s.GEMPorts[1].SVLAN = 4095 }, true},
+		{"svlan", func(s *SubscriberSpec) { v := 1000; s.SVLAN = &v }, false},
+		{"svlan out of range", func(s *SubscriberSpec) { v := 0; s.SVLAN = &v }, true},
+		{"svlan without vlan", func(s *SubscriberSpec) { v := 1000; s.SVLAN, s.VLAN = &v, 0 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.modify(s)
+			if err := s.ValidateGEMMapping(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateGEMMapping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGEMMapping(t *testing.T) {
+	s := &SubscriberSpec{VLAN: 100}
+	tconts, gems := s.GEMMapping()
+	if len(tconts) != 1 || len(gems) != 1 || gems[0].VLAN != 100 || gems[0].GetService() != DefaultServiceName {
+		t.Errorf("GEMMapping() = %+v, %+v", tconts, gems)
+	}
+	if tconts, gems := (&SubscriberSpec{}).GEMMapping(); tconts != nil || gems != nil {
+		t.Errorf("GEMMapping() without VLAN = %+v, %+v", tconts, gems)
+	}
+	if g := (&GEMPort{ID: 3, VLAN: 300}); g.GetService() != "GEM3" || g.GetUserVLAN() != 300 {
+		t.Errorf("defaults = %q, %d", g.GetService(), g.GetUserVLAN())
+	}
+}
+
+func TestQinQ(t *testing.T) {
+	s := &SubscriberSpec{VLAN: 100}
+	if s.IsQinQ() || s.GetSVLAN() != 0 {
+		t.Errorf("IsQinQ() = %v, GetSVLAN() = %d without svlan", s.IsQinQ(), s.GetSVLAN())
+	}
+	svlan := 1000
+	s.SVLAN = &svlan
+	if _, gems := s.GEMMapping(); !s.IsQinQ() || gems[0].SVLAN != 1000 {
+		t.Errorf("IsQinQ() = %v, GEMMapping() = %+v", s.IsQinQ(), gems)
+	}
+	s = &SubscriberSpec{VLAN: 100, GEMPorts: []GEMPort{{ID: 1, TCont: 1, VLAN: 100, SVLAN: 1000}}}
+	if !s.IsQinQ() {
+		t.Error("IsQinQ() = false with a GEM port svlan")
+	}
+}
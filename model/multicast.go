@@ -0,0 +1,46 @@
+package model
+
+import "fmt"
+
+// MulticastService is the IPTV service of an ONU: IGMP on the multicast
+// VLAN, joined through one GEM port
+type MulticastService struct {
+	// VLAN is the multicast VLAN ID
+	VLAN int `json:"vlan"`
+
+	// Profile is the name of an IGMP profile on the OLT (optional)
+	Profile string `json:"profile,omitempty"`
+
+	// MaxGroups limits the channels the ONU may join at once (optional,
+	// OLT default otherwise)
+	MaxGroups int `json:"maxGroups,omitempty"`
+
+	// GEMPort is the GEM port carrying multicast traffic (GPON only,
+	// default: 1)
+	GEMPort int `json:"gemPort,omitempty"`
+}
+
+// GetGEMPort returns the multicast GEM port, defaulting to 1
+func (m *MulticastService) GetGEMPort() int {
+	if m.GEMPort == 0 {
+		return 1
+	}
+	return m.GEMPort
+}
+
+// Validate checks that the multicast service can be provisioned
+func (m *MulticastService) Validate() error {
+	if m.VLAN < 1 || m.VLAN > 4094 {
+		return fmt.Errorf("multicast vlan must be between 1 and 4094")
+	}
+	if err := validateCLIValue("IGMP profile", m.Profile, false); err != nil {
+		return err
+	}
+	if m.MaxGroups < 0 || m.MaxGroups > 1024 {
+		return fmt.Errorf("max groups must be between 1 and 1024")
+	}
+	if m.GEMPort < 0 || m.GEMPort > 32 {
+		return fmt.Errorf("multicast GEM port must be between 1 and 32")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package model
+
+import "testing"
+
+func TestMulticastService(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       MulticastService
+		wantErr bool
+	}{
+		{"valid", MulticastService{VLAN: 1000, Profile: "iptv", MaxGroups: 8}, false},
+		{"missing vlan", MulticastService{}, true},
+		{"profile with space", MulticastService{VLAN: 1000, Profile: "iptv 1"}, true},
+		{"too many groups", MulticastService{VLAN: 1000, MaxGroups: 2000}, true},
+		{"GEM port out of range", MulticastService{VLAN: 1000, GEMPort: 33}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.m.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if m := (&MulticastService{}); m.GetGEMPort() != 1 {
+		t.Errorf("GetGEMPort() = %d, want 1", m.GetGEMPort())
+	}
+}
@@ -0,0 +1,39 @@
+package model
+
+import "fmt"
+
+// GetSVLAN returns the S-VLAN ID, or 0 without Q-in-Q
+func (s *SubscriberSpec) GetSVLAN() int {
+	if s.SVLAN == nil {
+		return 0
+	}
+	return *s.SVLAN
+}
+
+// IsQinQ returns true if the subscriber's traffic is double tagged, with
+// SVLAN or the SVLAN of a GEM port
+func (s *SubscriberSpec) IsQinQ() bool {
+	if s.GetSVLAN() > 0 {
+		return true
+	}
+	for _, g := range s.GEMPorts {
+		if g.SVLAN > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateQinQ checks that SVLAN is a valid VLAN ID carrying a C-VLAN
+func (s *SubscriberSpec) ValidateQinQ() error {
+	if s.SVLAN == nil {
+		return nil
+	}
+	if *s.SVLAN < 1 || *s.SVLAN > 4094 {
+		return fmt.Errorf("svlan must be between 1 and 4094")
+	}
+	if s.VLAN < 1 || s.VLAN > 4094 {
+		return fmt.Errorf("vlan must be between 1 and 4094 when svlan is set")
+	}
+	return nil
+}
@@ -9,6 +9,10 @@ type Subscriber struct {
 	// Name is the unique identifier for this subscriber
 	Name string
 
+	// Tenant owns the subscriber. Callers scoped with types.WithTenant only
+	// reach subscribers of their tenant.
+	Tenant string
+
 	// Annotations contains additional metadata
 	Annotations map[string]string
 
@@ -66,6 +70,30 @@ type SubscriberSpec struct {
 	// For backward compatibility, if ONUBindings is empty the legacy
 	// ONUSerial field is used to synthesize a primary binding.
 	ONUBindings []ONUBinding `json:"onuBindings,omitempty"`
+
+	// Voice is the SIP voice service of the ONU's POTS ports (optional,
+	// triple-play ONUs only)
+	Voice *VoiceService `json:"voice,omitempty"`
+
+	// Multicast is the IGMP/IPTV service of the ONU (optional)
+	Multicast *MulticastService `json:"multicast,omitempty"`
+
+	// TR069 is the TR-069 management WAN of the ONU (optional)
+	TR069 *TR069Service `json:"tr069,omitempty"`
+
+	// WAN is the routed WAN of a router-mode ONU, which dials PPPoE with
+	// Username and Password (optional, bridged ONU otherwise)
+	WAN *WANService `json:"wan,omitempty"`
+
+	// CATV enables (true) or disables (false) the RF video port of a combo
+	// GPON+CATV ONU (optional, left unchanged otherwise)
+	CATV *bool `json:"catv,omitempty"`
+
+	// TConts and GEMPorts map the ONU's services to T-CONTs and GEM ports
+	// (optional, GPON only). Without them the ONU gets T-CONT 1 and GEM
+	// port 1 carrying VLAN.
+	TConts   []TCont   `json:"tconts,omitempty"`
+	GEMPorts []GEMPort `json:"gemPorts,omitempty"`
 }
 
 // IsEnabled returns true if the subscriber is enabled (default: true)
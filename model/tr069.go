@@ -0,0 +1,63 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// DefaultTR069Priority is the 802.1p priority of the management WAN when
+// TR069Service.Priority is not set
+const DefaultTR069Priority = 5
+
+// TR069Service is the TR-069 management WAN of an ONU, configured over
+// OMCI so the ONU registers with the ACS on its own
+type TR069Service struct {
+	// VLAN is the management VLAN ID
+	VLAN int `json:"vlan"`
+
+	// Priority is the 802.1p priority of the management WAN (default: 5)
+	Priority *int `json:"priority,omitempty"`
+
+	// ACSURL is the URL of the auto-configuration server
+	ACSURL string `json:"acsUrl"`
+
+	// Username and Password authenticate the ONU with the ACS (optional)
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// GetPriority returns the management WAN priority, defaulting to
+// DefaultTR069Priority
+func (t *TR069Service) GetPriority() int {
+	if t.Priority == nil {
+		return DefaultTR069Priority
+	}
+	return *t.Priority
+}
+
+// Validate checks that the management WAN can be provisioned. Values are
+// passed to the OLT CLI, so they may not contain whitespace or quotes.
+func (t *TR069Service) Validate() error {
+	if t.VLAN < 1 || t.VLAN > 4094 {
+		return fmt.Errorf("TR-069 vlan must be between 1 and 4094")
+	}
+	if p := t.GetPriority(); p < 0 || p > 7 {
+		return fmt.Errorf("TR-069 priority must be between 0 and 7")
+	}
+	if err := validateCLIValue("ACS URL", t.ACSURL, true); err != nil {
+		return err
+	}
+	if u, err := url.Parse(t.ACSURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("ACS URL must be an http or https URL")
+	}
+	if err := validateCLIValue("ACS username", t.Username, false); err != nil {
+		return err
+	}
+	if err := validateCLIValue("ACS password", t.Password, false); err != nil {
+		return err
+	}
+	if t.Password != "" && t.Username == "" {
+		return fmt.Errorf("ACS username is required with a password")
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package model
+
+import "testing"
+
+func TestTR069Service(t *testing.T) {
+	priority := 8
+	tests := []struct {
+		name    string
+		svc     TR069Service
+		wantErr bool
+	}{
+		{"valid", TR069Service{VLAN: 46, ACSURL: "http://acs.example.net:7547/", Username: "cpe", Password: "secret"}, false},
+		{"no credentials", TR069Service{VLAN: 46, ACSURL: "https://acs.example.net"}, false},
+		{"missing vlan", TR069Service{ACSURL: "http://acs.example.net"}, true},
+		{"missing URL", TR069Service{VLAN: 46}, true},
+		{"not a URL", TR069Service{VLAN: 46, ACSURL: "acs.example.net"}, true},
+		{"priority out of range", TR069Service{VLAN: 46, ACSURL: "http://acs.example.net", Priority: &priority}, true},
+		{"password without username", TR069Service{VLAN: 46, ACSURL: "http://acs.example.net", Password: "secret"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.svc.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if p := (&TR069Service{}).GetPriority(); p != DefaultTR069Priority {
+		t.Errorf("GetPriority() = %d, want %d", p, DefaultTR069Priority)
+	}
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultSIPPort is the SIP server port used when VoiceService.ServerPort
+// is not set
+const DefaultSIPPort = 5060
+
+// VoiceService is the SIP voice service of a triple-play ONU, one SIP
+// account per POTS port
+type VoiceService struct {
+	// VLAN is the voice VLAN ID
+	VLAN int `json:"vlan"`
+
+	// Server is the SIP proxy/registrar host name or IP address
+	Server string `json:"server"`
+
+	// ServerPort is the SIP server port (default: 5060)
+	ServerPort int `json:"serverPort,omitempty"`
+
+	// Lines are the SIP accounts of the POTS ports
+	Lines []VoiceLine `json:"lines"`
+}
+
+// VoiceLine is the SIP account of one POTS port
+type VoiceLine struct {
+	// Port is the POTS port, numbered from 1
+	Port int `json:"port"`
+
+	// User is the SIP user, usually the phone number
+	User string `json:"user"`
+
+	// AuthUser is the SIP authentication user (default: User)
+	AuthUser string `json:"authUser,omitempty"`
+
+	// Password is the SIP authentication password
+	Password string `json:"password,omitempty"`
+
+	// Enabled controls the POTS port (default: true)
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// GetServerPort returns the SIP server port, defaulting to DefaultSIPPort
+func (v *VoiceService) GetServerPort() int {
+	if v.ServerPort == 0 {
+		return DefaultSIPPort
+	}
+	return v.ServerPort
+}
+
+// Validate checks that the voice service can be provisioned. Values are
+// passed to the OLT CLI, so they may not contain whitespace or quotes.
+func (v *VoiceService) Validate() error {
+	if v.VLAN < 1 || v.VLAN > 4094 {
+		return fmt.Errorf("voice vlan must be between 1 and 4094")
+	}
+	if err := validateCLIValue("SIP server", v.Server, true); err != nil {
+		return err
+	}
+	if v.ServerPort < 0 || v.ServerPort > 65535 {
+		return fmt.Errorf("SIP server port must be between 1 and 65535")
+	}
+	if len(v.Lines) == 0 {
+		return fmt.Errorf("at least one voice line is required")
+	}
+	ports := make(map[int]bool)
+	for _, l := range v.Lines {
+		if l.Port < 1 || l.Port > 8 {
+			return fmt.Errorf("POTS port must be between 1 and 8")
+		}
+		if ports[l.Port] {
+			return fmt.Errorf("POTS port %d is configured twice", l.Port)
+		}
+		ports[l.Port] = true
+		if err := validateCLIValue("SIP user", l.User, true); err != nil {
+			return err
+		}
+		if err := validateCLIValue("SIP auth user", l.AuthUser, false); err != nil {
+			return err
+		}
+		if err := validateCLIValue("SIP password", l.Password, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAuthUser returns the SIP authentication user, defaulting to User
+func (l *VoiceLine) GetAuthUser() string {
+	if l.AuthUser == "" {
+		return l.User
+	}
+	return l.AuthUser
+}
+
+// IsEnabled returns true if the POTS port is enabled (default: true)
+func (l *VoiceLine) IsEnabled() bool {
+	if l.Enabled == nil {
+		return true
+	}
+	return *l.Enabled
+}
+
+// validateCLIValue rejects values that cannot be passed as a single OLT CLI
+// argument
+func validateCLIValue(field, value string, required bool) error {
+	if value == "" {
+		if required {
+			return fmt.Errorf("%s is required", field)
+		}
+		return nil
+	}
+	if strings.ContainsAny(value, " \t\r\n\"'") {
+		return fmt.Errorf("%s contains unsupported characters", field)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package model
+
+import "testing"
+
+func TestVoiceServiceValidate(t *testing.T) {
+	valid := func() *VoiceService {
+		return &VoiceService{VLAN: 200, Server: "sip.example.net", Lines: []VoiceLine{
+			{Port: 1, User: "5550101", Password: "secret"},
+			{Port: 2, User: "5550102", AuthUser: "auth2", Password: "secret", Enabled: boolPtr(false)},
+		}}
+	}
+	tests := []struct {
+		name    string
+		modify  func(v *VoiceService)
+		wantErr bool
+	}{
+		{"valid", func(v *VoiceService) {}, false},
+		{"missing vlan", func(v *VoiceService) { v.VLAN = 0 }, true},
+		{"missing server", func(v *VoiceService) { v.Server = "" }, true},
+		{"bad server port", func(v *VoiceService) { v.ServerPort = 70000 }, true},
+		{"no lines", func(v *VoiceService) { v.Lines = nil }, true},
+		{"duplicate port", func(v *VoiceService) { v.Lines[1].Port = 1 }, true},
+		{"port out of range", func(v *VoiceService) { v.Lines[0].Port = 9 }, true},
+		{"missing user", func(v *VoiceService) { v.Lines[0].User = "" }, true},
+		{"password with space", func(v *VoiceService) { v.Lines[0].Password = "a b" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := valid()
+			tt.modify(v)
+			if err := v.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVoiceDefaults(t *testing.T) {
+	v := &VoiceService{}
+	if v.GetServerPort() != DefaultSIPPort {
+		t.Errorf("GetServerPort() = %d", v.GetServerPort())
+	}
+	l := VoiceLine{User: "5550101"}
+	if l.GetAuthUser() != "5550101" || !l.IsEnabled() {
+		t.Errorf("line defaults: auth user %q, enabled %v", l.GetAuthUser(), l.IsEnabled())
+	}
+}
@@ -0,0 +1,56 @@
+package model
+
+import "fmt"
+
+// WAN modes of a WANService
+const (
+	WANModePPPoE = "pppoe"
+	WANModeDHCP  = "dhcp"
+)
+
+// WANService is the routed WAN of a router-mode (HGU) ONU, configured over
+// OMCI so the ONU dials PPPoE itself with the subscriber's Username and
+// Password. Bridged ONUs have no WANService; the PPPoE session then runs
+// on the CPE behind the ONU.
+type WANService struct {
+	// Mode is "pppoe" (default) or "dhcp"
+	Mode string `json:"mode,omitempty"`
+
+	// VLAN is the WAN VLAN ID (default: the subscriber VLAN)
+	VLAN int `json:"vlan,omitempty"`
+
+	// Priority is the 802.1p priority of the WAN (default: 0)
+	Priority int `json:"priority,omitempty"`
+
+	// LANPorts are the Ethernet ports routed to the WAN, numbered from 1.
+	// Without them the ONU's port bindings are left unchanged.
+	LANPorts []int `json:"lanPorts,omitempty"`
+}
+
+// GetMode returns the WAN mode, defaulting to WANModePPPoE
+func (w *WANService) GetMode() string {
+	if w.Mode == "" {
+		return WANModePPPoE
+	}
+	return w.Mode
+}
+
+// Validate checks the WAN service. The PPPoE credentials are those of the
+// subscriber and are checked with the subscriber.
+func (w *WANService) Validate() error {
+	if m := w.GetMode(); m != WANModePPPoE && m != WANModeDHCP {
+		return fmt.Errorf("WAN mode must be %s or %s", WANModePPPoE, WANModeDHCP)
+	}
+	if w.VLAN < 0 || w.VLAN > 4094 {
+		return fmt.Errorf("WAN vlan must be between 1 and 4094")
+	}
+	if w.Priority < 0 || w.Priority > 7 {
+		return fmt.Errorf("WAN priority must be between 0 and 7")
+	}
+	for _, p := range w.LANPorts {
+		if p < 1 || p > 8 {
+			return fmt.Errorf("LAN port must be between 1 and 8")
+		}
+	}
+	return nil
+}
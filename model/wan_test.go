@@ -0,0 +1,28 @@
+package model
+
+import "testing"
+
+func TestWANService(t *testing.T) {
+	tests := []struct {
+		name    string
+		svc     WANService
+		wantErr bool
+	}{
+		{"defaults", WANService{}, false},
+		{"dhcp", WANService{Mode: WANModeDHCP, VLAN: 100, Priority: 1, LANPorts: []int{1, 2, 3, 4}}, false},
+		{"unknown mode", WANService{Mode: "static"}, true},
+		{"vlan out of range", WANService{VLAN: 4095}, true},
+		{"priority out of range", WANService{Priority: 8}, true},
+		{"LAN port out of range", WANService{LANPorts: []int{0}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.svc.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if m := (&WANService{}).GetMode(); m != WANModePPPoE {
+		t.Errorf("GetMode() = %q, want %q", m, WANModePPPoE)
+	}
+}
@@ -0,0 +1,246 @@
+// Package optical keeps the optical signal history of ONUs. It samples
+// successive ONU lists into a state.Store, one bucket per ONU and day, and
+// answers range queries with a summary and Rx trend, so a slowly degrading
+// fiber or laser shows before the ONU drops.
+package optical
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/state"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Defaults applied by New for zero Config fields
+const (
+	DefaultInterval  = 5 * time.Minute
+	DefaultRetention = 7 * 24 * time.Hour
+)
+
+// Common query windows
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+// keyPrefix is the state.Store namespace of this package
+const keyPrefix = "optical/"
+
+// dayLayout names the daily buckets of an ONU
+const dayLayout = "2006-01-02"
+
+// Sample is one optical reading of an ONU
+type Sample struct {
+	At          time.Time `json:"at"`
+	RxPowerDBm  float64   `json:"rx_power_dbm"`
+	TxPowerDBm  float64   `json:"tx_power_dbm"`
+	Temperature float64   `json:"temperature_c,omitempty"`
+}
+
+// bucket is the persisted samples of one ONU and day
+type bucket struct {
+	Serial  string   `json:"serial,omitempty"`
+	Samples []Sample `json:"samples"`
+}
+
+// Config configures a Recorder
+type Config struct {
+	// Store persists samples. Defaults to a state.MemoryStore.
+	Store state.Store
+
+	// Interval is the minimum time between two samples of an ONU; faster
+	// observations are dropped. Defaults to DefaultInterval.
+	Interval time.Duration
+
+	// Retention is how long samples are kept. Defaults to
+	// DefaultRetention.
+	Retention time.Duration
+}
+
+// Recorder records and queries ONU optical history
+type Recorder struct {
+	config Config
+}
+
+// New creates a Recorder
+func New(config Config) *Recorder {
+	if config.Store == nil {
+		config.Store = state.NewMemoryStore()
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Retention <= 0 {
+		config.Retention = DefaultRetention
+	}
+	return &Recorder{config: config}
+}
+
+// Observe records the optical levels of the ONU list of device read at
+// time at. ONUs without a reading (offline, or not reported by the
+// adapter) are skipped. Calls for the same device must not run
+// concurrently.
+func (r *Recorder) Observe(ctx context.Context, device string, onus []types.ONUInfo, at time.Time) error {
+	for _, onu := range onus {
+		if onu.RxPowerDBm == 0 && onu.TxPowerDBm == 0 {
+			continue
+		}
+		if err := r.observe(ctx, device, onu, at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Recorder) observe(ctx context.Context, device string, onu types.ONUInfo, at time.Time) error {
+	prefix := onuPrefix(device, onu.PONPort, onu.ONUID)
+	key := prefix + at.UTC().Format(dayLayout)
+
+	var b bucket
+	err := state.GetJSON(ctx, r.config.Store, key, &b)
+	switch {
+	case errors.Is(err, state.ErrNotFound):
+		// First sample of the day: drop expired days, and the whole
+		// history of an ONU replaced on the same port and ID
+		if err := r.prune(ctx, prefix, onu.Serial, at); err != nil {
+			return err
+		}
+	case err != nil:
+		return fmt.Errorf("failed to load ONU %s:%d optical history: %w", onu.PONPort, onu.ONUID, err)
+	case onu.Serial != "" && b.Serial != "" && onu.Serial != b.Serial:
+		if err := r.prune(ctx, prefix, onu.Serial, at); err != nil {
+			return err
+		}
+		b = bucket{}
+	}
+
+	if n := len(b.Samples); n > 0 && at.Sub(b.Samples[n-1].At) < r.config.Interval {
+		return nil
+	}
+	if onu.Serial != "" {
+		b.Serial = onu.Serial
+	}
+	b.Samples = append(b.Samples, Sample{
+		At:          at,
+		RxPowerDBm:  onu.RxPowerDBm,
+		TxPowerDBm:  onu.TxPowerDBm,
+		Temperature: onu.Temperature,
+	})
+	if err := state.PutJSON(ctx, r.config.Store, key, &b); err != nil {
+		return fmt.Errorf("failed to save ONU %s:%d optical history: %w", onu.PONPort, onu.ONUID, err)
+	}
+	return nil
+}
+
+// prune deletes the days of an ONU older than the retention, and every
+// day recorded for another serial
+func (r *Recorder) prune(ctx context.Context, prefix, serial string, at time.Time) error {
+	keys, err := r.config.Store.List(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list optical history: %w", err)
+	}
+	cutoff := at.Add(-r.config.Retention).UTC().Format(dayLayout)
+	for _, key := range keys {
+		expired := strings.TrimPrefix(key, prefix) < cutoff
+		if !expired && serial != "" {
+			var b bucket
+			if err := state.GetJSON(ctx, r.config.Store, key, &b); err == nil && b.Serial != "" && b.Serial != serial {
+				expired = true
+			}
+		}
+		if !expired {
+			continue
+		}
+		if err := r.config.Store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete optical history %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Query returns the samples of an ONU taken in [from, to], oldest first
+func (r *Recorder) Query(ctx context.Context, device, ponPort string, onuID int, from, to time.Time) ([]Sample, error) {
+	prefix := onuPrefix(device, ponPort, onuID)
+	keys, err := r.config.Store.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list optical history: %w", err)
+	}
+	first, last := from.UTC().Format(dayLayout), to.UTC().Format(dayLayout)
+	var samples []Sample
+	for _, key := range keys {
+		day := strings.TrimPrefix(key, prefix)
+		if day < first || day > last {
+			continue
+		}
+		var b bucket
+		if err := state.GetJSON(ctx, r.config.Store, key, &b); err != nil {
+			if errors.Is(err, state.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		for _, s := range b.Samples {
+			if !s.At.Before(from) && !s.At.After(to) {
+				samples = append(samples, s)
+			}
+		}
+	}
+	return samples, nil
+}
+
+// Last returns the samples of an ONU taken within window before now, e.g.
+// Day or Week
+func (r *Recorder) Last(ctx context.Context, device, ponPort string, onuID int, window time.Duration, now time.Time) ([]Sample, error) {
+	return r.Query(ctx, device, ponPort, onuID, now.Add(-window), now)
+}
+
+// Summary describes a series of samples
+type Summary struct {
+	Count int `json:"count"`
+
+	MinRxPowerDBm float64 `json:"min_rx_power_dbm"`
+	MaxRxPowerDBm float64 `json:"max_rx_power_dbm"`
+	AvgRxPowerDBm float64 `json:"avg_rx_power_dbm"`
+
+	// RxTrendDBPerDay is the least-squares slope of the Rx power;
+	// negative when the signal degrades
+	RxTrendDBPerDay float64 `json:"rx_trend_db_per_day"`
+
+	MaxTemperature float64 `json:"max_temperature_c,omitempty"`
+}
+
+// Summarize computes the summary of samples
+func Summarize(samples []Sample) Summary {
+	s := Summary{Count: len(samples)}
+	if len(samples) == 0 {
+		return s
+	}
+	s.MinRxPowerDBm, s.MaxRxPowerDBm = math.Inf(1), math.Inf(-1)
+	t0 := samples[0].At
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range samples {
+		s.MinRxPowerDBm = math.Min(s.MinRxPowerDBm, sample.RxPowerDBm)
+		s.MaxRxPowerDBm = math.Max(s.MaxRxPowerDBm, sample.RxPowerDBm)
+		s.MaxTemperature = math.Max(s.MaxTemperature, sample.Temperature)
+		x := sample.At.Sub(t0).Hours() / 24
+		sumX += x
+		sumY += sample.RxPowerDBm
+		sumXY += x * sample.RxPowerDBm
+		sumXX += x * x
+	}
+	n := float64(len(samples))
+	s.AvgRxPowerDBm = sumY / n
+	if d := n*sumXX - sumX*sumX; d > 0 {
+		s.RxTrendDBPerDay = (n*sumXY - sumX*sumY) / d
+	}
+	return s
+}
+
+func onuPrefix(device, ponPort string, onuID int) string {
+	return fmt.Sprintf("%s%s/%s:%d/", keyPrefix, device, ponPort, onuID)
+}
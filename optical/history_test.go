@@ -0,0 +1,88 @@
+package optical
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/state"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func onu(serial string, rx float64) []types.ONUInfo {
+	return []types.ONUInfo{{PONPort: "0/1", ONUID: 5, Serial: serial, RxPowerDBm: rx, TxPowerDBm: 2.1, Temperature: 45}}
+}
+
+func TestObserveAndQuery(t *testing.T) {
+	ctx := context.Background()
+	r := New(Config{})
+	t0 := time.Date(2026, 1, 1, 22, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 6; i++ {
+		if err := r.Observe(ctx, "olt-1", onu("VSOL00000005", -20-float64(i)*0.1), t0.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("Observe() error = %v", err)
+		}
+	}
+	// Faster than the interval: dropped
+	r.Observe(ctx, "olt-1", onu("VSOL00000005", -30), t0.Add(5*time.Hour+time.Minute)) //nolint:errcheck // checked below
+	// No reading: skipped
+	r.Observe(ctx, "olt-1", []types.ONUInfo{{PONPort: "0/1", ONUID: 6}}, t0) //nolint:errcheck // checked below
+
+	samples, err := r.Last(ctx, "olt-1", "0/1", 5, Day, t0.Add(6*time.Hour))
+	if err != nil {
+		t.Fatalf("Last() error = %v", err)
+	}
+	if len(samples) != 6 || samples[0].At != t0 || samples[5].RxPowerDBm != -20.5 {
+		t.Fatalf("Last() = %+v, want 6 samples across two days", samples)
+	}
+	if samples, _ := r.Query(ctx, "olt-1", "0/1", 5, t0.Add(2*time.Hour), t0.Add(3*time.Hour)); len(samples) != 2 {
+		t.Errorf("Query() = %+v, want 2 samples", samples)
+	}
+	if samples, _ := r.Last(ctx, "olt-1", "0/1", 6, Day, t0); len(samples) != 0 {
+		t.Errorf("ONU without reading recorded: %+v", samples)
+	}
+}
+
+func TestRetentionAndReplacement(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore()
+	r := New(Config{Store: store, Retention: 2 * Day})
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for d := 0; d < 4; d++ {
+		r.Observe(ctx, "olt-1", onu("VSOL00000005", -20), t0.Add(time.Duration(d)*Day)) //nolint:errcheck // checked below
+	}
+	keys, _ := store.List(ctx, keyPrefix)
+	if len(keys) != 3 {
+		t.Errorf("keys = %v, want days older than the retention pruned", keys)
+	}
+
+	// An ONU replaced on the same port and ID starts a new history
+	if err := r.Observe(ctx, "olt-1", onu("VSOL00000099", -18), t0.Add(3*Day+time.Hour)); err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+	samples, _ := r.Last(ctx, "olt-1", "0/1", 5, Week, t0.Add(4*Day))
+	if len(samples) != 1 || samples[0].RxPowerDBm != -18 {
+		t.Errorf("samples after replacement = %+v", samples)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []Sample{
+		{At: t0, RxPowerDBm: -20, Temperature: 40},
+		{At: t0.Add(Day), RxPowerDBm: -20.5, Temperature: 48},
+		{At: t0.Add(2 * Day), RxPowerDBm: -21},
+	}
+	s := Summarize(samples)
+	if s.Count != 3 || s.MinRxPowerDBm != -21 || s.MaxRxPowerDBm != -20 || s.AvgRxPowerDBm != -20.5 || s.MaxTemperature != 48 {
+		t.Errorf("Summarize() = %+v", s)
+	}
+	if math.Abs(s.RxTrendDBPerDay+0.5) > 1e-9 {
+		t.Errorf("RxTrendDBPerDay = %v, want -0.5", s.RxTrendDBPerDay)
+	}
+	if s := Summarize(nil); s.Count != 0 || s.RxTrendDBPerDay != 0 {
+		t.Errorf("Summarize(nil) = %+v", s)
+	}
+}
@@ -0,0 +1,241 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Migration moves a subscriber's ONU from one OLT to another, as for an
+// OLT swap or rebalancing PON ports across OLTs. Moves within one OLT are
+// MoveSubscriber's.
+type Migration struct {
+	// Source is the OLT the ONU is provisioned on
+	Source *types.EquipmentConfig
+
+	// SubscriberID is the subscriber on Source, in its adapter's format
+	SubscriberID string
+
+	// Target is the OLT to provision the ONU on
+	Target *types.EquipmentConfig
+
+	// TargetPONPort is the PON port on Target
+	TargetPONPort string
+
+	// TargetONUID is the ONU ID on TargetPONPort. Zero keeps the ONU ID
+	// of the source.
+	TargetONUID int
+
+	// DryRun captures the configuration and checks the target without
+	// changing either OLT
+	DryRun bool
+}
+
+// MigrationResult is the outcome of a migration
+type MigrationResult struct {
+	Source        string `json:"source"`
+	Target        string `json:"target"`
+	TargetPONPort string `json:"target_pon_port"`
+	TargetONUID   int    `json:"target_onu_id"`
+
+	// SubscriberID is the subscriber on Target, or on Source after a
+	// rollback
+	SubscriberID string `json:"subscriber_id,omitempty"`
+
+	// Snapshot is the configuration captured on Source. If the ONU was
+	// removed from Source and could not be restored anywhere, it is what
+	// to provision it from by hand.
+	Snapshot *types.SubscriberSnapshot `json:"snapshot,omitempty"`
+
+	// Plan lists the steps of the migration, taken or, with DryRun, to take
+	Plan []string `json:"plan"`
+
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// RolledBack is set if provisioning on Target failed and the ONU was
+	// provisioned on Source again
+	RolledBack bool `json:"rolled_back,omitempty"`
+
+	// VerificationStatus is "online" if the ONU reports optical levels on
+	// Target, "pending" if it does not yet
+	VerificationStatus string `json:"verification_status,omitempty"`
+
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Migrate moves the ONU of a subscriber with the same serial, VLANs,
+// profiles, bandwidth and service ports from one OLT to another. The
+// configuration is captured from the source and the target is checked
+// for a free ONU ID, the serial and the VLANs before anything changes;
+// then the ONU is deleted on the source and restored on the target. If
+// the target fails, the ONU is restored on the source at its old port and
+// ONU ID.
+//
+// The ONU is deleted first so that the serial is never provisioned on
+// both OLTs at once. An ONU that is not online on the target yet, because
+// the fiber is still to be moved, is not an error: the result is
+// "pending" with a warning.
+func (o *Orchestrator) Migrate(ctx context.Context, m Migration) (*MigrationResult, error) {
+	if m.Source == nil || m.Target == nil || m.SubscriberID == "" || m.TargetPONPort == "" {
+		return nil, fmt.Errorf("source, subscriber ID, target and target PON port are required")
+	}
+	if m.Source.Name == m.Target.Name {
+		return nil, fmt.Errorf("source and target are both %s; use MoveSubscriber within one OLT", m.Source.Name)
+	}
+	for _, device := range []*types.EquipmentConfig{m.Source, m.Target} {
+		if err := types.CheckTenant(ctx, "device", device.Name, device.Tenant); err != nil {
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, o.config.Timeout)
+	defer cancel()
+
+	source, release, err := o.connectV2(ctx, m.Source)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	target, release, err := o.connectV2(ctx, m.Target)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	result := &MigrationResult{
+		Source:        m.Source.Name,
+		Target:        m.Target.Name,
+		TargetPONPort: m.TargetPONPort,
+		DryRun:        m.DryRun,
+	}
+	snapshot, err := source.CaptureSubscriberConfig(ctx, m.SubscriberID)
+	if err != nil {
+		return nil, &types.HumanError{
+			Code:    types.ErrCodeSnapshotFailed,
+			Message: fmt.Sprintf("failed to capture subscriber %s on %s: %v", m.SubscriberID, m.Source.Name, err),
+			Vendor:  string(m.Source.Vendor),
+		}
+	}
+	result.Snapshot = snapshot
+	result.TargetONUID = m.TargetONUID
+	if result.TargetONUID == 0 {
+		result.TargetONUID = snapshot.ONUID
+	}
+
+	if err := checkMigrationTarget(ctx, target, snapshot, result); err != nil {
+		return result, fmt.Errorf("target %s: %w", m.Target.Name, err)
+	}
+	result.Plan = []string{
+		fmt.Sprintf("delete subscriber %s (ONU %s at %s:%d) on %s", m.SubscriberID, snapshot.Serial, snapshot.PONPort, snapshot.ONUID, m.Source.Name),
+		fmt.Sprintf("provision ONU %s at %s:%d on %s with VLAN %d and %d service ports", snapshot.Serial, m.TargetPONPort, result.TargetONUID, m.Target.Name, snapshot.VLAN, len(snapshot.ServicePorts)),
+		fmt.Sprintf("verify ONU %s on %s", snapshot.Serial, m.Target.Name),
+	}
+	if m.DryRun {
+		return result, nil
+	}
+
+	log := m.Source.Log().With("subscriber_id", m.SubscriberID, "serial", snapshot.Serial, "target", m.Target.Name)
+	if err := source.DeleteSubscriber(ctx, m.SubscriberID); err != nil {
+		return result, fmt.Errorf("failed to delete subscriber %s on %s: %w", m.SubscriberID, m.Source.Name, err)
+	}
+
+	restored, err := target.RestoreSubscriberConfig(ctx, snapshot, m.TargetPONPort, result.TargetONUID)
+	if err != nil {
+		restoreErr := &types.HumanError{
+			Code:    types.ErrCodeRestoreFailed,
+			Message: fmt.Sprintf("failed to provision ONU %s on %s: %v", snapshot.Serial, m.Target.Name, err),
+			Vendor:  string(m.Target.Vendor),
+		}
+		// Roll back even if ctx timed out: the ONU is on neither OLT now
+		rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), o.config.Timeout)
+		defer cancel()
+		back, rollbackErr := source.RestoreSubscriberConfig(rollbackCtx, snapshot, snapshot.PONPort, snapshot.ONUID)
+		if rollbackErr != nil {
+			log.Error("migration rollback failed; ONU is provisioned on neither OLT", "error", rollbackErr)
+			return result, errors.Join(restoreErr, fmt.Errorf("rollback on %s failed, ONU %s is not provisioned: %w", m.Source.Name, snapshot.Serial, rollbackErr))
+		}
+		log.Warn("migration failed and was rolled back", "error", err)
+		result.RolledBack = true
+		result.SubscriberID = back.SubscriberID
+		return result, restoreErr
+	}
+	result.SubscriberID = restored.SubscriberID
+	log.Info("migrated ONU", "pon_port", m.TargetPONPort, "onu_id", result.TargetONUID)
+
+	result.VerificationStatus = "online"
+	if _, err := target.GetONUPower(ctx, m.TargetPONPort, result.TargetONUID); err != nil {
+		result.VerificationStatus = "pending"
+		result.Warnings = append(result.Warnings, fmt.Sprintf("ONU %s not online on %s yet: %v", snapshot.Serial, m.Target.Name, err))
+	}
+	return result, nil
+}
+
+// connectV2 connects the driver of a device that must support DriverV2
+func (o *Orchestrator) connectV2(ctx context.Context, device *types.EquipmentConfig) (types.DriverV2, func(), error) {
+	driver, release, err := o.connect(ctx, device)
+	if err != nil {
+		return nil, nil, err
+	}
+	v2, ok := driver.(types.DriverV2)
+	if !ok {
+		release()
+		return nil, nil, fmt.Errorf("%s adapter does not support subscriber migration", device.Vendor)
+	}
+	return v2, release, nil
+}
+
+// checkMigrationTarget checks that the target can take the snapshot: the
+// ONU ID is free, the serial is not provisioned and the VLANs exist. VLANs
+// that cannot be listed are a warning.
+func checkMigrationTarget(ctx context.Context, target types.DriverV2, snapshot *types.SubscriberSnapshot, result *MigrationResult) error {
+	onus, err := target.GetONUList(ctx, &types.ONUFilter{PONPort: result.TargetPONPort})
+	if err != nil {
+		return fmt.Errorf("failed to list ONUs: %w", err)
+	}
+	for _, onu := range onus {
+		if onu.ONUID == result.TargetONUID {
+			return fmt.Errorf("ONU ID %d is in use on %s by %s", onu.ONUID, result.TargetPONPort, onu.Serial)
+		}
+	}
+	existing, err := target.GetONUBySerial(ctx, snapshot.Serial)
+	if err != nil {
+		return fmt.Errorf("failed to look up ONU %s: %w", snapshot.Serial, err)
+	}
+	if existing != nil {
+		return fmt.Errorf("ONU %s is already provisioned at %s:%d", snapshot.Serial, existing.PONPort, existing.ONUID)
+	}
+
+	vlans, err := target.ListVLANs(ctx)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("VLANs not checked: %v", err))
+		return nil
+	}
+	var missing []int
+	for _, id := range snapshotVLANs(snapshot) {
+		if !slices.ContainsFunc(vlans, func(v types.VLANInfo) bool { return v.ID == id }) {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("VLANs %v do not exist", missing)
+	}
+	return nil
+}
+
+// snapshotVLANs returns the VLANs a snapshot needs on the OLT
+func snapshotVLANs(snapshot *types.SubscriberSnapshot) []int {
+	var vlans []int
+	add := func(id int) {
+		if id > 0 && !slices.Contains(vlans, id) {
+			vlans = append(vlans, id)
+		}
+	}
+	add(snapshot.VLAN)
+	for _, sp := range snapshot.ServicePorts {
+		add(sp.VLAN)
+	}
+	slices.Sort(vlans)
+	return vlans
+}
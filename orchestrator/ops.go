@@ -0,0 +1,143 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// ONUFailure is an ONU an operation failed on
+type ONUFailure struct {
+	PONPort string `json:"pon_port"`
+	ONUID   int    `json:"onu_id"`
+	Serial  string `json:"serial,omitempty"`
+	Error   string `json:"error"`
+}
+
+// ONUResult is the result of an operation applied to the ONUs of one
+// device
+type ONUResult struct {
+	Matched  int          `json:"matched"`
+	Applied  int          `json:"applied"`
+	Failures []ONUFailure `json:"failures,omitempty"`
+}
+
+// ApplyProfile returns an operation that applies profile to every ONU
+// matching filter. The device fails if any ONU fails; the ONUResult lists
+// which.
+func ApplyProfile(profile *types.ONUProfile, filter *types.ONUFilter) Operation {
+	return ForEachONU(filter, func(ctx context.Context, driver types.DriverV2, onu types.ONUInfo) error {
+		return driver.ApplyProfile(ctx, onu.PONPort, onu.ONUID, profile)
+	})
+}
+
+// ForEachONU returns an operation that calls fn for every ONU matching
+// filter, continuing past failures
+func ForEachONU(filter *types.ONUFilter, fn func(ctx context.Context, driver types.DriverV2, onu types.ONUInfo) error) Operation {
+	return func(ctx context.Context, device *types.EquipmentConfig, driver types.Driver) (any, error) {
+		v2, ok := driver.(types.DriverV2)
+		if !ok {
+			return nil, fmt.Errorf("%s adapter does not support ONU operations", device.Vendor)
+		}
+		onus, err := v2.GetONUList(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ONUs: %w", err)
+		}
+
+		result := &ONUResult{Matched: len(onus)}
+		for _, onu := range onus {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			if err := fn(ctx, v2, onu); err != nil {
+				result.Failures = append(result.Failures, ONUFailure{
+					PONPort: onu.PONPort, ONUID: onu.ONUID, Serial: onu.Serial, Error: err.Error(),
+				})
+				continue
+			}
+			result.Applied++
+		}
+		if len(result.Failures) > 0 {
+			return result, fmt.Errorf("%d of %d ONUs failed", len(result.Failures), result.Matched)
+		}
+		return result, nil
+	}
+}
+
+// BulkProvision returns an operation that provisions the ONUs listed for
+// each device by name with the adapter's BulkProvision. Devices without
+// operations succeed without a call; the device fails if any ONU fails.
+func BulkProvision(ops map[string][]types.BulkProvisionOp) Operation {
+	return func(ctx context.Context, device *types.EquipmentConfig, driver types.Driver) (any, error) {
+		deviceOps := ops[device.Name]
+		if len(deviceOps) == 0 {
+			return &types.BulkResult{}, nil
+		}
+		v2, ok := driver.(types.DriverV2)
+		if !ok {
+			return nil, fmt.Errorf("%s adapter does not support bulk provisioning", device.Vendor)
+		}
+		result, err := v2.BulkProvision(ctx, deviceOps)
+		if err != nil {
+			return result, err
+		}
+		if result.Failed > 0 {
+			return result, fmt.Errorf("%d of %d ONUs failed", result.Failed, len(deviceOps))
+		}
+		return result, nil
+	}
+}
+
+// bulkOpticalReader is implemented by adapters that read the optical
+// levels of every ONU in one operation
+type bulkOpticalReader interface {
+	GetBulkONUOpticalSNMP(ctx context.Context) (map[string]*types.ONUPowerReading, error)
+}
+
+// CollectOptical returns an operation that reads the optical levels of
+// every ONU, sorted by port and ONU ID. Adapters with a bulk SNMP read use
+// it; others are queried per ONU, skipping ONUs that cannot be read.
+func CollectOptical() Operation {
+	return func(ctx context.Context, device *types.EquipmentConfig, driver types.Driver) (any, error) {
+		var readings []*types.ONUPowerReading
+		if bulk, ok := driver.(bulkOpticalReader); ok {
+			byONU, err := bulk.GetBulkONUOpticalSNMP(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read optical levels: %w", err)
+			}
+			for _, r := range byONU {
+				readings = append(readings, r)
+			}
+		} else {
+			v2, ok := driver.(types.DriverV2)
+			if !ok {
+				return nil, fmt.Errorf("%s adapter does not support optical readings", device.Vendor)
+			}
+			onus, err := v2.GetONUList(ctx, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list ONUs: %w", err)
+			}
+			for _, onu := range onus {
+				r, err := v2.GetONUPower(ctx, onu.PONPort, onu.ONUID)
+				if err != nil {
+					if ctx.Err() != nil {
+						return nil, ctx.Err()
+					}
+					device.Log().Debug("skipping ONU without optical reading", "pon_port", onu.PONPort, "onu_id", onu.ONUID, "error", err)
+					continue
+				}
+				readings = append(readings, r)
+			}
+		}
+
+		sort.Slice(readings, func(i, j int) bool {
+			if readings[i].PONPort != readings[j].PONPort {
+				return readings[i].PONPort < readings[j].PONPort
+			}
+			return readings[i].ONUID < readings[j].ONUID
+		})
+		return readings, nil
+	}
+}
@@ -0,0 +1,359 @@
+// Package orchestrator runs one operation across many devices: applying a
+// profile to every matching ONU of a fleet, collecting optical levels from
+// every OLT, and the like. Migrate moves an ONU with its service
+// configuration from one OLT to another.
+//
+// Devices are processed concurrently up to a limit, and a failure on one
+// device does not stop the others; the Report lists the outcome of each.
+// With a state.Store, each device that succeeds is checkpointed under the
+// job ID, so running the job again after a crash or a partial failure only
+// processes the devices that have not succeeded yet.
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/state"
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/workers"
+)
+
+// Defaults applied by New for zero Config fields
+const (
+	DefaultConcurrency = 8
+	DefaultTimeout     = 10 * time.Minute
+)
+
+// keyPrefix is the state.Store namespace of job checkpoints
+const keyPrefix = "orchestrator/"
+
+// ErrAborted is reported for devices not processed because the job reached
+// Config.MaxFailures
+var ErrAborted = errors.New("job aborted after too many failures")
+
+// Operation is run once per device with a connected driver. The result is
+// kept in the device's report entry and, when checkpointing, stored as
+// JSON. An error fails the device.
+type Operation func(ctx context.Context, device *types.EquipmentConfig, driver types.Driver) (any, error)
+
+// Config configures an Orchestrator
+type Config struct {
+	// Concurrency is the number of devices processed at once when Pool is
+	// nil. Defaults to DefaultConcurrency.
+	Concurrency int
+
+	// Timeout bounds the operation on one device, including connecting.
+	// Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// MaxFailures aborts a job once this many devices have failed; devices
+	// not started by then are reported with ErrAborted. Zero never aborts.
+	MaxFailures int
+
+	// Store keeps job checkpoints. If nil, jobs cannot be resumed.
+	Store state.Store
+
+	// Pool bounds the devices processed at once. Share a pool with other
+	// subsystems to keep them off a device while a job works on it. If
+	// nil, a pool of Concurrency with one task per device is used.
+	Pool *workers.Pool
+
+	// TagsOf returns the current tags of a device for Job.Tags. If nil,
+	// EquipmentConfig.Tags is used. Registry.TagsOf can be used to select
+	// by tags set at runtime.
+	TagsOf func(device *types.EquipmentConfig) map[string]string
+}
+
+// Job is an operation to run on a set of devices
+type Job struct {
+	// ID names the job for checkpointing. Running a job with the ID of an
+	// earlier run resumes it. Required when Config.Store is set.
+	ID string
+
+	Devices   []*types.EquipmentConfig
+	Operation Operation
+
+	// Tags, if not empty, limits the job to the devices whose tags match.
+	// Other devices are left out of the report.
+	Tags types.Selector
+}
+
+// Status is the outcome of a job on one device
+type Status string
+
+const (
+	// StatusSucceeded means the operation succeeded in this run
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed means connecting or the operation failed
+	StatusFailed Status = "failed"
+	// StatusResumed means the device succeeded in an earlier run of the job
+	// and was not processed again
+	StatusResumed Status = "resumed"
+	// StatusSkipped means the device was not processed because the job was
+	// aborted or cancelled
+	StatusSkipped Status = "skipped"
+)
+
+// DeviceResult is the outcome of a job on one device
+type DeviceResult struct {
+	Device   string
+	Status   Status
+	Result   any // the operation's result; json.RawMessage when resumed
+	Err      error
+	Duration time.Duration
+}
+
+// Report is the outcome of a job
+type Report struct {
+	Job       string
+	Results   []DeviceResult // in job device order
+	Succeeded int            // including resumed devices
+	Failed    int
+	Skipped   int
+	Duration  time.Duration
+}
+
+// Failures returns the results of the devices that failed or were skipped,
+// which a later run of the job would process
+func (r *Report) Failures() []DeviceResult {
+	var failed []DeviceResult
+	for _, res := range r.Results {
+		if res.Status == StatusFailed || res.Status == StatusSkipped {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// checkpoint records a device that succeeded
+type checkpoint struct {
+	Finished time.Time       `json:"finished"`
+	Result   json.RawMessage `json:"result,omitempty"`
+}
+
+// Orchestrator runs jobs across devices. It is safe for concurrent use.
+type Orchestrator struct {
+	factory types.DriverFactory
+	config  Config
+}
+
+// New creates an Orchestrator. Drivers are created with factory; a driver
+// that is not connected is connected for the job and disconnected after.
+func New(factory types.DriverFactory, config Config) *Orchestrator {
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Pool == nil {
+		config.Pool = workers.New(config.Concurrency, 1)
+	}
+	return &Orchestrator{factory: factory, config: config}
+}
+
+// Run runs job on its devices, at most Concurrency at a time, and returns
+// the report. Device failures are reported per device; the error is
+// non-nil only if the job could not be run or its checkpoints could not
+// be read. A job for a tenant-scoped ctx is refused as a whole if any
+// device belongs to another tenant.
+func (o *Orchestrator) Run(ctx context.Context, job Job) (*Report, error) {
+	if job.Operation == nil {
+		return nil, fmt.Errorf("job operation is required")
+	}
+	if len(job.Tags) > 0 {
+		job.Devices = o.selectDevices(job.Devices, job.Tags)
+	}
+	for _, device := range job.Devices {
+		if err := types.CheckTenant(ctx, "device", device.Name, device.Tenant); err != nil {
+			return nil, err
+		}
+	}
+	if o.config.Store != nil && job.ID == "" {
+		return nil, fmt.Errorf("job ID is required for checkpointing")
+	}
+	done, err := o.checkpoints(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	report := &Report{Job: job.ID, Results: make([]DeviceResult, len(job.Devices))}
+
+	var (
+		mu       sync.Mutex
+		failures int
+	)
+	aborted := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return o.config.MaxFailures > 0 && failures >= o.config.MaxFailures
+	}
+
+	var pending []int
+	var keys []string
+	for i, device := range job.Devices {
+		if cp, ok := done[device.Name]; ok {
+			report.Results[i] = DeviceResult{Device: device.Name, Status: StatusResumed, Result: cp.Result}
+			continue
+		}
+		pending = append(pending, i)
+		keys = append(keys, device.Name)
+	}
+
+	errs := o.config.Pool.Each(ctx, keys, func(n int) error {
+		i := pending[n]
+		device := job.Devices[i]
+		if aborted() {
+			report.Results[i] = DeviceResult{Device: device.Name, Status: StatusSkipped, Err: ErrAborted}
+			return nil
+		}
+		res := o.runDevice(ctx, job, device)
+		if res.Status == StatusFailed {
+			mu.Lock()
+			failures++
+			mu.Unlock()
+		}
+		report.Results[i] = res
+		return nil
+	})
+	for n, err := range errs {
+		if err != nil {
+			i := pending[n]
+			report.Results[i] = DeviceResult{Device: job.Devices[i].Name, Status: StatusSkipped, Err: err}
+		}
+	}
+
+	for _, res := range report.Results {
+		switch res.Status {
+		case StatusSucceeded, StatusResumed:
+			report.Succeeded++
+		case StatusFailed:
+			report.Failed++
+		case StatusSkipped:
+			report.Skipped++
+		}
+	}
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// selectDevices returns the devices whose tags match sel
+func (o *Orchestrator) selectDevices(devices []*types.EquipmentConfig, sel types.Selector) []*types.EquipmentConfig {
+	var selected []*types.EquipmentConfig
+	for _, device := range devices {
+		tags := device.Tags
+		if o.config.TagsOf != nil {
+			tags = o.config.TagsOf(device)
+		}
+		if sel.Matches(tags) {
+			selected = append(selected, device)
+		}
+	}
+	return selected
+}
+
+// runDevice runs the job operation on one device and checkpoints success
+func (o *Orchestrator) runDevice(ctx context.Context, job Job, device *types.EquipmentConfig) DeviceResult {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, o.config.Timeout)
+	defer cancel()
+
+	res := DeviceResult{Device: device.Name}
+	res.Result, res.Err = o.run(ctx, job.Operation, device)
+	res.Duration = time.Since(start)
+	if res.Err != nil {
+		res.Status = StatusFailed
+		device.Log().Warn("orchestrated operation failed", "job", job.ID, "error", res.Err)
+		return res
+	}
+	res.Status = StatusSucceeded
+
+	if o.config.Store != nil {
+		cp := checkpoint{Finished: time.Now()}
+		if res.Result != nil {
+			// A result that cannot be encoded is not worth failing a device
+			// that succeeded; it is just not kept
+			cp.Result, _ = json.Marshal(res.Result) //nolint:errcheck // see above
+		}
+		if err := state.PutJSON(context.WithoutCancel(ctx), o.config.Store, checkpointKey(job.ID, device.Name), cp); err != nil {
+			device.Log().Warn("failed to checkpoint orchestrated operation", "job", job.ID, "error", err)
+		}
+	}
+	return res
+}
+
+func (o *Orchestrator) run(ctx context.Context, op Operation, device *types.EquipmentConfig) (any, error) {
+	driver, release, err := o.connect(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return op(ctx, device, driver)
+}
+
+// connect creates the driver of a device and connects it if it is not
+// connected. release disconnects a driver connected here.
+func (o *Orchestrator) connect(ctx context.Context, device *types.EquipmentConfig) (driver types.Driver, release func(), err error) {
+	driver, err = o.factory(device)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create driver for %s: %w", device.Name, err)
+	}
+	if driver.IsConnected() {
+		return driver, func() {}, nil
+	}
+	if err := driver.Connect(ctx, device); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", device.Name, err)
+	}
+	return driver, func() {
+		driver.Disconnect(context.WithoutCancel(ctx)) //nolint:errcheck // best effort
+	}, nil
+}
+
+// checkpoints returns the checkpoints of job keyed by device name
+func (o *Orchestrator) checkpoints(ctx context.Context, job string) (map[string]checkpoint, error) {
+	done := make(map[string]checkpoint)
+	if o.config.Store == nil {
+		return done, nil
+	}
+	prefix := checkpointKey(job, "")
+	keys, err := o.config.Store.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints of job %s: %w", job, err)
+	}
+	for _, key := range keys {
+		var cp checkpoint
+		if err := state.GetJSON(ctx, o.config.Store, key, &cp); err != nil {
+			return nil, err
+		}
+		done[key[len(prefix):]] = cp
+	}
+	return done, nil
+}
+
+// Forget deletes the checkpoints of job, so its next run processes every
+// device again
+func (o *Orchestrator) Forget(ctx context.Context, job string) error {
+	if o.config.Store == nil {
+		return nil
+	}
+	keys, err := o.config.Store.List(ctx, checkpointKey(job, ""))
+	if err != nil {
+		return fmt.Errorf("failed to list checkpoints of job %s: %w", job, err)
+	}
+	for _, key := range keys {
+		if err := o.config.Store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete checkpoint %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func checkpointKey(job, device string) string {
+	return keyPrefix + job + "/" + device
+}
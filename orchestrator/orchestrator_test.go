@@ -0,0 +1,285 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/state"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/workers"
+)
+
+func newFleet() (map[string]*testutil.MockDriverV2, []*types.EquipmentConfig, types.DriverFactory) {
+	drivers := map[string]*testutil.MockDriverV2{}
+	var devices []*types.EquipmentConfig
+	for _, name := range []string{"olt-1", "olt-2", "olt-3"} {
+		drivers[name] = &testutil.MockDriverV2{ONUs: []types.ONUInfo{
+			{PONPort: "0/1", ONUID: 1, Serial: name + "-1", RxPowerDBm: -20},
+			{PONPort: "0/1", ONUID: 2, Serial: name + "-2", RxPowerDBm: -22},
+			{PONPort: "0/2", ONUID: 1, Serial: name + "-3", RxPowerDBm: -24},
+		}}
+		devices = append(devices, &types.EquipmentConfig{Name: name, Vendor: types.VendorVSOL})
+	}
+	factory := func(c *types.EquipmentConfig) (types.Driver, error) {
+		d, ok := drivers[c.Name]
+		if !ok {
+			return nil, errors.New("no driver")
+		}
+		return d, nil
+	}
+	return drivers, devices, factory
+}
+
+func TestRunResumes(t *testing.T) {
+	ctx := context.Background()
+	drivers, devices, factory := newFleet()
+	errBusy := errors.New("device busy")
+	drivers["olt-2"].Faults = map[string]*testutil.Fault{"ApplyProfile:0/1:2": {Err: errBusy}}
+	drivers["olt-3"].Faults = map[string]*testutil.Fault{"Connect": {Err: errBusy}}
+
+	store := state.NewMemoryStore()
+	o := New(factory, Config{Store: store})
+	job := Job{
+		ID:        "profile-100m",
+		Devices:   devices,
+		Operation: ApplyProfile(&types.ONUProfile{LineProfile: "100M"}, &types.ONUFilter{PONPort: "0/1"}),
+	}
+
+	report, err := o.Run(ctx, job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Succeeded != 1 || report.Failed != 2 || len(report.Failures()) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	partial := report.Results[1].Result.(*ONUResult)
+	if partial.Matched != 2 || partial.Applied != 1 || len(partial.Failures) != 1 || partial.Failures[0].ONUID != 2 {
+		t.Errorf("olt-2 result = %+v", partial)
+	}
+	if drivers["olt-1"].IsConnected() {
+		t.Error("driver connected for the job was left connected")
+	}
+	for _, onu := range drivers["olt-1"].ONUs {
+		if want := map[string]string{"0/1": "100M"}[onu.PONPort]; onu.LineProfile != want {
+			t.Errorf("olt-1 %s:%d profile = %q, want %q", onu.PONPort, onu.ONUID, onu.LineProfile, want)
+		}
+	}
+
+	// The second run only touches the devices that failed
+	drivers["olt-2"].Faults = nil
+	drivers["olt-1"].Calls = nil
+	report, err = o.Run(ctx, job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := []Status{report.Results[0].Status, report.Results[1].Status, report.Results[2].Status}
+	if statuses[0] != StatusResumed || statuses[1] != StatusSucceeded || statuses[2] != StatusFailed {
+		t.Errorf("statuses = %v", statuses)
+	}
+	if len(drivers["olt-1"].Calls) != 0 {
+		t.Errorf("resumed device was called: %v", drivers["olt-1"].Calls)
+	}
+	var resumed ONUResult
+	if err := json.Unmarshal(report.Results[0].Result.(json.RawMessage), &resumed); err != nil || resumed.Applied != 2 {
+		t.Errorf("resumed result = %+v, %v", resumed, err)
+	}
+
+	if err := o.Forget(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := store.List(ctx, "orchestrator/"); len(keys) != 0 {
+		t.Errorf("checkpoints left after Forget: %v", keys)
+	}
+}
+
+func TestRunTenant(t *testing.T) {
+	drivers, devices, factory := newFleet()
+	devices[0].Tenant, devices[1].Tenant = "isp-a", "isp-a"
+	ctx := types.WithTenant(context.Background(), "isp-a")
+	o := New(factory, Config{})
+
+	_, err := o.Run(ctx, Job{Devices: devices, Operation: CollectOptical()})
+	if !errors.Is(err, types.ErrTenantDenied) {
+		t.Fatalf("Run() with another tenant's device = %v", err)
+	}
+	if drivers["olt-1"].IsConnected() || len(drivers["olt-1"].Calls) != 0 {
+		t.Error("no device should be touched when the job is refused")
+	}
+
+	report, err := o.Run(ctx, Job{Devices: devices[:2], Operation: CollectOptical()})
+	if err != nil || report.Succeeded != 2 {
+		t.Errorf("Run() own devices = %+v, %v", report, err)
+	}
+}
+
+func TestRunTags(t *testing.T) {
+	drivers, devices, factory := newFleet()
+	devices[0].Tags = map[string]string{"ring": "north"}
+	devices[2].Tags = map[string]string{"ring": "north"}
+	o := New(factory, Config{})
+
+	report, err := o.Run(context.Background(), Job{Devices: devices, Operation: CollectOptical(), Tags: types.Selector{"ring": "north"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 2 || report.Results[0].Device != "olt-1" || report.Results[1].Device != "olt-3" {
+		t.Errorf("results = %+v", report.Results)
+	}
+	if len(drivers["olt-2"].Calls) != 0 {
+		t.Error("olt-2 is not on the ring and should not be touched")
+	}
+}
+
+func TestRunMaxFailures(t *testing.T) {
+	_, devices, _ := newFleet()
+	failing := func(*types.EquipmentConfig) (types.Driver, error) { return nil, errors.New("unreachable") }
+
+	o := New(failing, Config{Concurrency: 1, MaxFailures: 2})
+	report, err := o.Run(context.Background(), Job{Devices: devices, Operation: CollectOptical()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Failed != 2 || report.Skipped != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	// The device not started when the job aborted is skipped
+	for _, res := range report.Results {
+		if res.Status == StatusSkipped && !errors.Is(res.Err, ErrAborted) {
+			t.Errorf("skipped %s error = %v, want ErrAborted", res.Device, res.Err)
+		}
+	}
+
+	if _, err := New(failing, Config{Store: state.NewMemoryStore()}).Run(context.Background(), Job{Operation: CollectOptical()}); err == nil {
+		t.Error("Run() without a job ID and with a store should fail")
+	}
+}
+
+func TestCollectOptical(t *testing.T) {
+	drivers, devices, factory := newFleet()
+	drivers["olt-1"].Faults = map[string]*testutil.Fault{"GetONUPower:0/1:2": {Err: errors.New("no reading")}}
+
+	report, err := New(factory, Config{}).Run(context.Background(), Job{Devices: devices[:1], Operation: CollectOptical()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	readings := report.Results[0].Result.([]*types.ONUPowerReading)
+	if len(readings) != 2 || readings[0].RxPowerDBm != -20 || readings[1].PONPort != "0/2" {
+		t.Errorf("readings = %+v", readings)
+	}
+}
+
+func TestBulkProvision(t *testing.T) {
+	drivers, devices, factory := newFleet()
+	drivers["olt-2"].Faults = map[string]*testutil.Fault{"BulkProvision:NEW00000003": {Err: errors.New("ONU ID exhausted")}}
+
+	report, err := New(factory, Config{Pool: workers.New(2, 1)}).Run(context.Background(), Job{
+		Devices: devices,
+		Operation: BulkProvision(map[string][]types.BulkProvisionOp{
+			"olt-1": {{Serial: "NEW00000001", PONPort: "0/3"}},
+			"olt-2": {{Serial: "NEW00000002", PONPort: "0/3"}, {Serial: "NEW00000003", PONPort: "0/3"}},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Succeeded != 2 || report.Failed != 1 || report.Results[1].Status != StatusFailed {
+		t.Fatalf("report = %+v", report)
+	}
+	if r := report.Results[1].Result.(*types.BulkResult); r.Succeeded != 1 || r.Failed != 1 {
+		t.Errorf("olt-2 result = %+v", r)
+	}
+	if len(drivers["olt-3"].Calls) != 2 { // Connect and Disconnect only
+		t.Errorf("olt-3 calls = %v", drivers["olt-3"].Calls)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	drivers, devices, factory := newFleet()
+	for _, d := range drivers {
+		d.VLANs = []types.VLANInfo{{ID: 100}}
+	}
+	drivers["olt-3"].VLANs = nil
+	source := drivers["olt-1"]
+	if _, err := source.CreateSubscriber(ctx, testutil.NewTestSubscriber("SUB00000001", "0/3", 100), testutil.NewTestServiceTier(50, 100)); err != nil {
+		t.Fatal(err)
+	}
+	o := New(factory, Config{})
+	migration := Migration{Source: devices[0], SubscriberID: "test-SUB00000001", Target: devices[1], TargetPONPort: "0/3", DryRun: true}
+
+	result, err := o.Migrate(ctx, migration)
+	if err != nil {
+		t.Fatalf("Migrate(dry run) error = %v", err)
+	}
+	if result.TargetONUID != 1 || len(result.Plan) != 3 || result.Snapshot.Serial != "SUB00000001" {
+		t.Errorf("Migrate(dry run) = %+v", result)
+	}
+	if slices.Contains(source.Calls, "DeleteSubscriber:test-SUB00000001") || len(drivers["olt-2"].ONUs) != 3 {
+		t.Errorf("dry run changed the OLTs: source calls = %v", source.Calls)
+	}
+
+	for name, m := range map[string]Migration{
+		"onu id in use": {Source: devices[0], SubscriberID: "test-SUB00000001", Target: devices[1], TargetPONPort: "0/1"},
+		"vlan missing":  {Source: devices[0], SubscriberID: "test-SUB00000001", Target: devices[2], TargetPONPort: "0/3"},
+		"same olt":      {Source: devices[0], SubscriberID: "test-SUB00000001", Target: devices[0], TargetPONPort: "0/3"},
+	} {
+		if _, err := o.Migrate(ctx, m); err == nil {
+			t.Errorf("Migrate(%s) error = nil", name)
+		}
+	}
+
+	migration.DryRun = false
+	result, err = o.Migrate(ctx, migration)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if result.VerificationStatus != "online" || result.RolledBack {
+		t.Errorf("Migrate() = %+v", result)
+	}
+	if len(source.ONUs) != 3 {
+		t.Errorf("source ONUs = %+v, want the migrated ONU deleted", source.ONUs)
+	}
+	moved := drivers["olt-2"].ONUs[3]
+	if moved.Serial != "SUB00000001" || moved.PONPort != "0/3" || moved.ONUID != 1 || moved.VLAN != 100 || moved.BandwidthDown != 100 {
+		t.Errorf("target ONU = %+v", moved)
+	}
+}
+
+func TestMigrateRollback(t *testing.T) {
+	ctx := context.Background()
+	drivers, devices, factory := newFleet()
+	source := drivers["olt-1"]
+	if _, err := source.CreateSubscriber(ctx, testutil.NewTestSubscriber("SUB00000001", "0/3", 0), nil); err != nil {
+		t.Fatal(err)
+	}
+	drivers["olt-2"].Faults = map[string]*testutil.Fault{"RestoreSubscriberConfig": {Err: errors.New("line profile missing")}}
+
+	result, err := New(factory, Config{}).Migrate(ctx, Migration{
+		Source: devices[0], SubscriberID: "test-SUB00000001", Target: devices[1], TargetPONPort: "0/3", TargetONUID: 7,
+	})
+	var herr *types.HumanError
+	if !errors.As(err, &herr) || herr.Code != types.ErrCodeRestoreFailed {
+		t.Fatalf("Migrate() error = %v, want %s", err, types.ErrCodeRestoreFailed)
+	}
+	if !result.RolledBack || result.Warnings != nil {
+		t.Errorf("Migrate() = %+v", result)
+	}
+	if onu := source.ONUs[len(source.ONUs)-1]; onu.Serial != "SUB00000001" || onu.PONPort != "0/3" || onu.ONUID != 1 {
+		t.Errorf("source ONU after rollback = %+v", onu)
+	}
+
+	source.Faults = map[string]*testutil.Fault{"RestoreSubscriberConfig": {Err: errors.New("device busy")}}
+	if _, err := source.CreateSubscriber(ctx, testutil.NewTestSubscriber("SUB00000002", "0/4", 0), nil); err != nil {
+		t.Fatal(err)
+	}
+	result, err = New(factory, Config{}).Migrate(ctx, Migration{
+		Source: devices[0], SubscriberID: "test-SUB00000002", Target: devices[1], TargetPONPort: "0/3",
+	})
+	if err == nil || result.RolledBack || result.Snapshot.Serial != "SUB00000002" {
+		t.Errorf("Migrate() = %+v, %v; want failed rollback with the snapshot", result, err)
+	}
+}
@@ -0,0 +1,385 @@
+// Package outage correlates dying gasps into probable power outages.
+//
+// An ONU that loses power sends a dying gasp before going dark. One gasp
+// is a subscriber unplugging their ONU; many gasps on the same PON port
+// within seconds are a power cut in the area the port serves. A Correlator
+// collects gasps from ONU states polled over CLI and from alarms or traps,
+// groups those of each PON port within a window, and reports a cluster as
+// a single probable outage, located with the PON topology at the deepest
+// splitter all the gasping ONUs share.
+package outage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/alarms"
+	"github.com/nanoncore/nano-southbound/collector"
+	"github.com/nanoncore/nano-southbound/events"
+	"github.com/nanoncore/nano-southbound/lifecycle"
+	"github.com/nanoncore/nano-southbound/topology"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Defaults applied by New for zero Config fields
+const (
+	DefaultWindow  = 30 * time.Second
+	DefaultMinONUs = 3
+)
+
+// Source identifies how a gasp was learned
+type Source string
+
+// Gasp sources
+const (
+	SourcePoll  Source = "poll"
+	SourceAlarm Source = "alarm"
+	SourceTrap  Source = "trap"
+)
+
+// Gasp is a dying-gasp indication from one ONU
+type Gasp struct {
+	Device  string    `json:"device"`
+	PONPort string    `json:"pon_port"`
+	ONUID   int       `json:"onu_id"`
+	Serial  string    `json:"serial,omitempty"`
+	At      time.Time `json:"at"`
+	Source  Source    `json:"source"`
+}
+
+// Outage is a cluster of gasps on one PON port correlated into a probable
+// power outage
+type Outage struct {
+	Device  string `json:"device"`
+	PONPort string `json:"pon_port"`
+
+	// Location is the topology node the gasping ONUs share: a splitter
+	// ID, or the "<olt>:<port>" ID of the port. Empty without topology.
+	Location string        `json:"location,omitempty"`
+	Kind     topology.Kind `json:"kind,omitempty"`
+
+	// Behind is the number of ONUs behind Location, 0 without topology
+	Behind int `json:"behind,omitempty"`
+
+	Gasps []Gasp    `json:"gasps"`
+	Start time.Time `json:"start"` // first gasp
+	End   time.Time `json:"end"`   // last gasp
+}
+
+// Splitter returns the ID of the splitter the outage is located at, or
+// the empty string if it is located at the port or not located
+func (o Outage) Splitter() string {
+	if o.Kind == topology.KindSplitter {
+		return o.Location
+	}
+	return ""
+}
+
+// Config configures a Correlator
+type Config struct {
+	// Window is how long a cluster stays open after its first gasp.
+	// Defaults to DefaultWindow.
+	Window time.Duration
+
+	// MinONUs is the number of ONUs of a port that must gasp within
+	// Window for the cluster to be an outage. Smaller clusters are
+	// published as single gasps. Defaults to DefaultMinONUs.
+	MinONUs int
+
+	// Topology locates outages at a splitter. Optional; SetTopology
+	// replaces it when the graph is rebuilt.
+	Topology *topology.Graph
+
+	// Publisher receives an events.TypePowerOutage event per outage and
+	// an events.TypeONUDyingGasp event per gasp not part of one. Optional.
+	Publisher events.Publisher
+}
+
+// Correlator groups dying gasps into outages. It is safe for concurrent
+// use.
+type Correlator struct {
+	config Config
+
+	mu       sync.Mutex
+	graph    *topology.Graph
+	open     map[string][]Gasp // by "<device>|<port>", while the window is open
+	gasping  map[string]bool   // ONUs in dying gasp at the last poll, by device
+	polled   map[string]bool   // devices with a poll baseline
+	recorded map[string]time.Time
+}
+
+// New creates a Correlator
+func New(config Config) *Correlator {
+	if config.Window <= 0 {
+		config.Window = DefaultWindow
+	}
+	if config.MinONUs <= 0 {
+		config.MinONUs = DefaultMinONUs
+	}
+	return &Correlator{
+		config:   config,
+		graph:    config.Topology,
+		open:     make(map[string][]Gasp),
+		gasping:  make(map[string]bool),
+		polled:   make(map[string]bool),
+		recorded: make(map[string]time.Time),
+	}
+}
+
+// SetTopology replaces the graph outages are located with
+func (c *Correlator) SetTopology(g *topology.Graph) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.graph = g
+}
+
+// Gasp records a dying gasp. Repeated gasps of the same ONU within the
+// window count once.
+func (c *Correlator) Gasp(g Gasp) {
+	if g.At.IsZero() {
+		g.At = time.Now()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(g)
+}
+
+func (c *Correlator) add(g Gasp) {
+	onu := onuKey(g.Device, g.PONPort, g.ONUID)
+	if last, ok := c.recorded[onu]; ok && g.At.Sub(last) < c.config.Window {
+		return
+	}
+	c.recorded[onu] = g.At
+	key := g.Device + "|" + g.PONPort
+	c.open[key] = append(c.open[key], g)
+}
+
+// Observe feeds a collector sample to the correlator. It can be passed
+// directly as a collector.Handler. ONU lists and alarms are used; other
+// samples and failed ones are ignored.
+func (c *Correlator) Observe(s collector.Sample) {
+	if s.Err != nil {
+		return
+	}
+	switch s.Class {
+	case collector.MetricONUList:
+		c.ONUs(s.Device, s.ONUs, s.CollectedAt)
+	case collector.MetricAlarms:
+		for _, a := range s.Alarms {
+			c.Alarm(s.Device, s.Vendor, SourceAlarm, a)
+		}
+	}
+}
+
+// ONUs records a gasp for every ONU of device that entered the dying-gasp
+// state since the previous list. The first list of a device is the
+// baseline: ONUs already gasping in it are not counted.
+func (c *Correlator) ONUs(device string, onus []types.ONUInfo, at time.Time) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	baseline := !c.polled[device]
+	c.polled[device] = true
+	now := make(map[string]bool)
+	for _, onu := range onus {
+		if lifecycle.StateOf(onu) != lifecycle.StateDyingGasp {
+			continue
+		}
+		key := onuKey(device, onu.PONPort, onu.ONUID)
+		now[key] = true
+		if !baseline && !c.gasping[key] {
+			c.add(Gasp{Device: device, PONPort: onu.PONPort, ONUID: onu.ONUID, Serial: onu.Serial, At: at, Source: SourcePoll})
+		}
+	}
+	prefix := device + "|"
+	for key := range c.gasping {
+		if strings.HasPrefix(key, prefix) && !now[key] {
+			delete(c.gasping, key)
+		}
+	}
+	for key := range now {
+		c.gasping[key] = true
+	}
+}
+
+// Alarm records a gasp if a is a dying-gasp alarm on an ONU, e.g. from
+// GetAlarms or an SNMP trap. Other alarms are ignored.
+func (c *Correlator) Alarm(device string, vendor types.Vendor, source Source, a types.OLTAlarm) {
+	n := alarms.Normalize(vendor, a)
+	if n.Kind != alarms.KindDyingGasp {
+		return
+	}
+	port, id, ok := strings.Cut(n.Object, ":")
+	if !ok {
+		return
+	}
+	onuID, err := strconv.Atoi(id)
+	if err != nil {
+		return
+	}
+	at := a.RaisedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	c.Gasp(Gasp{Device: device, PONPort: port, ONUID: onuID, At: at, Source: source})
+}
+
+// Flush closes the clusters whose window ended by now. Clusters of at
+// least MinONUs ONUs are returned and published as outages; the gasps of
+// smaller ones are published one by one.
+func (c *Correlator) Flush(now time.Time) []Outage {
+	c.mu.Lock()
+	var outages []Outage
+	var single []Gasp
+	for key, gasps := range c.open {
+		if now.Sub(gasps[0].At) < c.config.Window {
+			continue
+		}
+		delete(c.open, key)
+		if len(gasps) < c.config.MinONUs {
+			single = append(single, gasps...)
+			continue
+		}
+		outages = append(outages, c.correlate(gasps))
+	}
+	for onu, at := range c.recorded {
+		if now.Sub(at) >= c.config.Window {
+			delete(c.recorded, onu)
+		}
+	}
+	c.mu.Unlock()
+
+	sort.Slice(outages, func(i, j int) bool { return outages[i].Start.Before(outages[j].Start) })
+	sort.Slice(single, func(i, j int) bool { return single[i].At.Before(single[j].At) })
+	for _, o := range outages {
+		c.publishOutage(o)
+	}
+	for _, g := range single {
+		c.publishGasp(g)
+	}
+	return outages
+}
+
+// Run flushes clusters as their windows end until ctx is cancelled
+func (c *Correlator) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.config.Window / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			c.Flush(now)
+		}
+	}
+}
+
+// correlate builds the outage of a cluster, locating it in the topology
+func (c *Correlator) correlate(gasps []Gasp) Outage {
+	sort.Slice(gasps, func(i, j int) bool { return gasps[i].At.Before(gasps[j].At) })
+	o := Outage{
+		Device:  gasps[0].Device,
+		PONPort: gasps[0].PONPort,
+		Gasps:   gasps,
+		Start:   gasps[0].At,
+		End:     gasps[len(gasps)-1].At,
+	}
+	if c.graph == nil {
+		return o
+	}
+
+	// ONUs reported by alarms have no serial; find them by ONU ID
+	byID := make(map[int]string)
+	for _, n := range c.graph.Downstream(o.Device + ":" + o.PONPort) {
+		byID[n.ONUID] = n.ID
+	}
+	var shared []*topology.Node
+	for _, g := range gasps {
+		serial := g.Serial
+		if serial == "" {
+			serial = byID[g.ONUID]
+		}
+		path := c.graph.Path(serial)
+		if len(path) == 0 {
+			continue
+		}
+		path = path[:len(path)-1] // the ONU itself
+		if shared == nil {
+			shared = path
+			continue
+		}
+		n := 0
+		for n < len(shared) && n < len(path) && shared[n].ID == path[n].ID {
+			n++
+		}
+		shared = shared[:n]
+	}
+	if len(shared) == 0 {
+		return o
+	}
+	at := shared[len(shared)-1]
+	if at.Kind == topology.KindOLT {
+		return o
+	}
+	o.Location, o.Kind = at.ID, at.Kind
+	o.Behind = len(c.graph.Downstream(at.ID))
+	return o
+}
+
+func (c *Correlator) publishOutage(o Outage) {
+	if c.config.Publisher == nil {
+		return
+	}
+	serials := make([]string, 0, len(o.Gasps))
+	ids := make([]int, 0, len(o.Gasps))
+	for _, g := range o.Gasps {
+		ids = append(ids, g.ONUID)
+		if g.Serial != "" {
+			serials = append(serials, g.Serial)
+		}
+	}
+	c.config.Publisher.Publish(events.Event{
+		Type:   events.TypePowerOutage,
+		Device: o.Device,
+		Time:   o.End,
+		Data: map[string]any{
+			"pon_port": o.PONPort,
+			"location": o.Location,
+			"splitter": o.Splitter(),
+			"behind":   o.Behind,
+			"gasps":    len(o.Gasps),
+			"onu_ids":  ids,
+			"serials":  serials,
+			"start":    o.Start,
+		},
+	})
+}
+
+func (c *Correlator) publishGasp(g Gasp) {
+	if c.config.Publisher == nil {
+		return
+	}
+	c.config.Publisher.Publish(events.Event{
+		Type:   events.TypeONUDyingGasp,
+		Device: g.Device,
+		Time:   g.At,
+		Data: map[string]any{
+			"pon_port": g.PONPort,
+			"onu_id":   g.ONUID,
+			"serial":   g.Serial,
+			"source":   string(g.Source),
+		},
+	})
+}
+
+func onuKey(device, ponPort string, onuID int) string {
+	return fmt.Sprintf("%s|%s:%d", device, ponPort, onuID)
+}
@@ -0,0 +1,116 @@
+package outage
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/collector"
+	"github.com/nanoncore/nano-southbound/events"
+	"github.com/nanoncore/nano-southbound/topology"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (r *recorder) Publish(e events.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func graph(t *testing.T) *topology.Graph {
+	t.Helper()
+	b := topology.NewBuilder(topology.Config{})
+	b.AddSplitter(topology.Splitter{ID: "cab-12", OLT: "olt-1", PONPort: "0/1", Ratio: 8, DistanceM: 900})
+	b.AddSplitter(topology.Splitter{ID: "cab-13", OLT: "olt-1", PONPort: "0/1", Ratio: 8, DistanceM: 2500})
+	var onus []types.ONUInfo
+	for i, serial := range []string{"ONU00001", "ONU00002", "ONU00003", "ONU00004"} {
+		onus = append(onus, types.ONUInfo{PONPort: "0/1", ONUID: i + 1, Serial: serial})
+		splitter := "cab-12"
+		if i == 3 {
+			splitter = "cab-13"
+		}
+		b.AddHint(topology.Hint{Serial: serial, Splitter: splitter})
+	}
+	b.AddONUs("olt-1", onus)
+	g, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func onu(id int, serial, state string) types.ONUInfo {
+	return types.ONUInfo{PONPort: "0/1", ONUID: id, Serial: serial, OperState: state, IsOnline: state == "working"}
+}
+
+func TestCorrelate(t *testing.T) {
+	rec := &recorder{}
+	c := New(Config{Topology: graph(t), Publisher: rec})
+	t0 := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+
+	all := []types.ONUInfo{onu(1, "ONU00001", "working"), onu(2, "ONU00002", "working"), onu(3, "ONU00003", "working"), onu(4, "ONU00004", "working")}
+	c.ONUs("olt-1", all, t0)
+
+	// Three ONUs behind cab-12 gasp: two seen polling, one by trap
+	c.ONUs("olt-1", []types.ONUInfo{onu(1, "ONU00001", "dying_gasp"), onu(2, "ONU00002", "dying_gasp"), all[2], all[3]}, t0.Add(5*time.Second))
+	c.Alarm("olt-1", types.VendorHuawei, SourceTrap, types.OLTAlarm{Type: "ONT dying-gasp", SourceID: "0/1:3", RaisedAt: t0.Add(7 * time.Second)})
+	// Still gasping at the next poll: not counted again
+	c.ONUs("olt-1", []types.ONUInfo{onu(1, "ONU00001", "dying_gasp"), onu(2, "ONU00002", "dying_gasp"), all[2], all[3]}, t0.Add(10*time.Second))
+
+	if got := c.Flush(t0.Add(20 * time.Second)); len(got) != 0 {
+		t.Fatalf("cluster flushed before its window ended: %+v", got)
+	}
+	outages := c.Flush(t0.Add(40 * time.Second))
+	if len(outages) != 1 {
+		t.Fatalf("outages = %+v", outages)
+	}
+	o := outages[0]
+	if o.Splitter() != "cab-12" || o.Behind != 3 || len(o.Gasps) != 3 || o.Device != "olt-1" || o.PONPort != "0/1" {
+		t.Errorf("outage = %+v", o)
+	}
+	if !o.Start.Equal(t0.Add(5*time.Second)) || !o.End.Equal(t0.Add(7*time.Second)) {
+		t.Errorf("outage spans %s to %s", o.Start, o.End)
+	}
+	if len(rec.events) != 1 || rec.events[0].Type != events.TypePowerOutage || rec.events[0].Data["splitter"] != "cab-12" {
+		t.Errorf("events = %+v", rec.events)
+	}
+
+	// ONUs on both splitters: located at the port
+	rec.events = nil
+	t1 := t0.Add(time.Hour)
+	for i, s := range []string{"ONU00001", "ONU00003", "ONU00004"} {
+		c.Gasp(Gasp{Device: "olt-1", PONPort: "0/1", ONUID: []int{1, 3, 4}[i], Serial: s, At: t1})
+	}
+	outages = c.Flush(t1.Add(time.Minute))
+	if len(outages) != 1 || outages[0].Location != "olt-1:0/1" || outages[0].Kind != topology.KindPort || outages[0].Splitter() != "" {
+		t.Errorf("outages = %+v", outages)
+	}
+}
+
+func TestSingleGasp(t *testing.T) {
+	rec := &recorder{}
+	c := New(Config{Publisher: rec})
+	t0 := time.Now()
+
+	c.Observe(collector.Sample{Device: "olt-1", Class: collector.MetricONUList, CollectedAt: t0,
+		ONUs: []types.ONUInfo{onu(1, "ONU00001", "dying_gasp")}})
+	if c.Flush(t0.Add(time.Hour)); len(rec.events) != 0 {
+		t.Fatalf("ONU gasping in the baseline was published: %+v", rec.events)
+	}
+
+	c.Observe(collector.Sample{Device: "olt-1", Vendor: types.VendorHuawei, Class: collector.MetricAlarms, Alarms: []types.OLTAlarm{
+		{Type: "ONT dying-gasp", SourceID: "0/2:7", RaisedAt: t0},
+		{Type: "ONT LOSi", SourceID: "0/2:8", RaisedAt: t0},
+	}})
+	if outages := c.Flush(t0.Add(time.Minute)); len(outages) != 0 {
+		t.Errorf("single gasp reported as an outage: %+v", outages)
+	}
+	if len(rec.events) != 1 || rec.events[0].Type != events.TypeONUDyingGasp || rec.events[0].Data["onu_id"] != 7 {
+		t.Errorf("events = %+v", rec.events)
+	}
+}
@@ -0,0 +1,329 @@
+// Package pool manages long-lived driver sessions to devices.
+//
+// A Manager owns the driver instances for each device, reuses connected
+// sessions across calls, bounds the number of concurrent sessions per
+// device and disconnects sessions that have been idle too long.
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// DefaultIdleTimeout is how long an unused session stays connected when
+// Config.IdleTimeout is zero
+const DefaultIdleTimeout = 5 * time.Minute
+
+// ErrClosed is returned by Acquire after Close
+var ErrClosed = errors.New("session pool is closed")
+
+// Factory creates an unconnected driver for a device
+type Factory func(config *types.EquipmentConfig) (types.Driver, error)
+
+// Config configures a Manager
+type Config struct {
+	// MaxSessionsPerDevice bounds the sessions open to one device. Acquire
+	// blocks while the limit is reached. Defaults to 1, since many OLTs
+	// allow only a few concurrent management sessions.
+	MaxSessionsPerDevice int
+
+	// IdleTimeout disconnects sessions unused for this long. Zero uses
+	// DefaultIdleTimeout; negative keeps idle sessions open.
+	IdleTimeout time.Duration
+}
+
+// Stats describes the sessions of one device
+type Stats struct {
+	Open  int `json:"open"`
+	InUse int `json:"in_use"`
+	Idle  int `json:"idle"`
+}
+
+// Manager pools driver sessions per device. It is safe for concurrent use.
+type Manager struct {
+	factory Factory
+	config  Config
+
+	mu      sync.Mutex
+	devices map[string]*device
+	closed  bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// device holds the sessions of one device
+type device struct {
+	config *types.EquipmentConfig
+	slots  chan struct{} // one per session in use
+	idle   []*Session
+	inUse  int
+}
+
+// Session is a driver leased from a Manager. Call Release when done, or
+// Discard if the connection is known to be broken.
+type Session struct {
+	// Driver is the connected driver. It must not be used after Release
+	// or Discard.
+	Driver types.Driver
+
+	manager  *Manager
+	key      string
+	lastUsed time.Time
+	once     sync.Once
+}
+
+// New creates a Manager. If idle eviction is enabled, a background
+// goroutine runs until Close.
+func New(factory Factory, config Config) *Manager {
+	if config.MaxSessionsPerDevice <= 0 {
+		config.MaxSessionsPerDevice = 1
+	}
+	if config.IdleTimeout == 0 {
+		config.IdleTimeout = DefaultIdleTimeout
+	}
+
+	m := &Manager{
+		factory: factory,
+		config:  config,
+		devices: make(map[string]*device),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if config.IdleTimeout > 0 {
+		go m.evictLoop()
+	} else {
+		close(m.done)
+	}
+	return m
+}
+
+// Key identifies a device in the pool: its name, or address and port if
+// it has none
+func Key(config *types.EquipmentConfig) string {
+	if config.Name != "" {
+		return config.Name
+	}
+	return fmt.Sprintf("%s:%d", config.Address, config.Port)
+}
+
+// Acquire returns a connected session to the device, reusing an idle one
+// if possible. It blocks while the device has MaxSessionsPerDevice
+// sessions in use.
+func (m *Manager) Acquire(ctx context.Context, config *types.EquipmentConfig) (*Session, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+	key := Key(config)
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil, ErrClosed
+	}
+	dev, ok := m.devices[key]
+	if !ok {
+		dev = &device{
+			config: config,
+			slots:  make(chan struct{}, m.config.MaxSessionsPerDevice),
+		}
+		m.devices[key] = dev
+	}
+	m.mu.Unlock()
+
+	select {
+	case dev.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		<-dev.slots
+		return nil, ErrClosed
+	}
+	var s *Session
+	if n := len(dev.idle); n > 0 {
+		s = dev.idle[n-1]
+		dev.idle = dev.idle[:n-1]
+	}
+	dev.inUse++
+	m.mu.Unlock()
+
+	if s != nil && s.Driver.IsConnected() {
+		return s, nil
+	}
+
+	driver, err := m.connect(ctx, dev.config, s)
+	if err != nil {
+		m.mu.Lock()
+		dev.inUse--
+		m.mu.Unlock()
+		<-dev.slots
+		return nil, err
+	}
+	if s == nil {
+		s = &Session{manager: m, key: key}
+	}
+	s.Driver = driver
+	return s, nil
+}
+
+// connect reconnects the driver of a stale session, or creates a new one
+func (m *Manager) connect(ctx context.Context, config *types.EquipmentConfig, stale *Session) (types.Driver, error) {
+	var driver types.Driver
+	if stale != nil {
+		driver = stale.Driver
+	} else {
+		d, err := m.factory(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create driver for %s: %w", Key(config), err)
+		}
+		driver = d
+	}
+	if err := driver.Connect(ctx, config); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", Key(config), err)
+	}
+	return driver, nil
+}
+
+// Do runs fn with a pooled session, discarding it if fn leaves the driver
+// disconnected
+func (m *Manager) Do(ctx context.Context, config *types.EquipmentConfig, fn func(types.Driver) error) error {
+	s, err := m.Acquire(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer s.Release()
+	return fn(s.Driver)
+}
+
+// Release returns the session to the pool. Sessions that are no longer
+// connected are dropped.
+func (s *Session) Release() {
+	s.once.Do(func() { s.manager.release(s, false) })
+}
+
+// Discard disconnects the session and removes it from the pool
+func (s *Session) Discard() {
+	s.once.Do(func() { s.manager.release(s, true) })
+}
+
+func (m *Manager) release(s *Session, discard bool) {
+	m.mu.Lock()
+	dev := m.devices[s.key]
+	dev.inUse--
+	keep := !discard && !m.closed && s.Driver.IsConnected()
+	if keep {
+		// Return a fresh Session so a stale handle cannot release twice
+		dev.idle = append(dev.idle, &Session{
+			Driver:   s.Driver,
+			manager:  m,
+			key:      s.key,
+			lastUsed: time.Now(),
+		})
+	}
+	m.mu.Unlock()
+	<-dev.slots
+
+	if !keep {
+		disconnect(s.Driver, dev.config)
+	}
+}
+
+// Stats returns session counts per device key
+func (m *Manager) Stats() map[string]Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make(map[string]Stats, len(m.devices))
+	for key, dev := range m.devices {
+		stats[key] = Stats{
+			Open:  dev.inUse + len(dev.idle),
+			InUse: dev.inUse,
+			Idle:  len(dev.idle),
+		}
+	}
+	return stats
+}
+
+// EvictIdle disconnects sessions idle for longer than maxIdle and returns
+// how many were closed
+func (m *Manager) EvictIdle(maxIdle time.Duration) int {
+	cutoff := time.Now().Add(-maxIdle)
+	return m.evict(func(s *Session) bool { return s.lastUsed.Before(cutoff) })
+}
+
+// evict disconnects the idle sessions matching drop
+func (m *Manager) evict(drop func(*Session) bool) int {
+	type victim struct {
+		driver types.Driver
+		config *types.EquipmentConfig
+	}
+	var victims []victim
+
+	m.mu.Lock()
+	for _, dev := range m.devices {
+		kept := dev.idle[:0]
+		for _, s := range dev.idle {
+			if drop(s) {
+				victims = append(victims, victim{s.Driver, dev.config})
+				continue
+			}
+			kept = append(kept, s)
+		}
+		dev.idle = kept
+	}
+	m.mu.Unlock()
+
+	for _, v := range victims {
+		disconnect(v.driver, v.config)
+	}
+	return len(victims)
+}
+
+func (m *Manager) evictLoop() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.config.IdleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.EvictIdle(m.config.IdleTimeout)
+		}
+	}
+}
+
+// Close disconnects every idle session and stops eviction. Sessions in
+// use are disconnected when released.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	close(m.stop)
+	m.mu.Unlock()
+
+	<-m.done
+	m.evict(func(*Session) bool { return true })
+}
+
+func disconnect(driver types.Driver, config *types.EquipmentConfig) {
+	if !driver.IsConnected() {
+		return
+	}
+	if err := driver.Disconnect(context.Background()); err != nil {
+		config.Log().Warn("pooled session disconnect failed", "error", err)
+	}
+}
@@ -0,0 +1,213 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// countingFactory creates MockDrivers and remembers them
+type countingFactory struct {
+	mu      sync.Mutex
+	drivers []*testutil.MockDriver
+	err     error
+}
+
+func (f *countingFactory) create(_ *types.EquipmentConfig) (types.Driver, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	d := &testutil.MockDriver{}
+	f.drivers = append(f.drivers, d)
+	return d, nil
+}
+
+func (f *countingFactory) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.drivers)
+}
+
+var olt1 = &types.EquipmentConfig{Name: "olt-1", Address: "192.0.2.1"}
+
+func TestAcquireReusesSession(t *testing.T) {
+	f := &countingFactory{}
+	m := New(f.create, Config{IdleTimeout: -1})
+	defer m.Close()
+	ctx := context.Background()
+
+	s1, err := m.Acquire(ctx, olt1)
+	if err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+	if !s1.Driver.IsConnected() {
+		t.Fatal("session should be connected")
+	}
+	s1.Release()
+	s1.Release() // no-op
+
+	s2, err := m.Acquire(ctx, olt1)
+	if err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+	defer s2.Release()
+	if s2.Driver != s1.Driver {
+		t.Error("idle session should be reused")
+	}
+	if f.count() != 1 {
+		t.Errorf("factory called %d times, want 1", f.count())
+	}
+	if got := m.Stats()["olt-1"]; got != (Stats{Open: 1, InUse: 1}) {
+		t.Errorf("Stats() = %+v", got)
+	}
+}
+
+func TestAcquireReconnectsStaleSession(t *testing.T) {
+	f := &countingFactory{}
+	m := New(f.create, Config{IdleTimeout: -1})
+	defer m.Close()
+	ctx := context.Background()
+
+	s, _ := m.Acquire(ctx, olt1)
+	s.Release()
+	f.drivers[0].Connected = false // dropped by the device while idle
+
+	s, err := m.Acquire(ctx, olt1)
+	if err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+	defer s.Release()
+	if !s.Driver.IsConnected() || f.count() != 1 {
+		t.Errorf("stale session should be reconnected in place (drivers=%d)", f.count())
+	}
+}
+
+func TestMaxSessionsPerDevice(t *testing.T) {
+	f := &countingFactory{}
+	m := New(f.create, Config{MaxSessionsPerDevice: 2, IdleTimeout: -1})
+	defer m.Close()
+
+	var inUse, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := m.Do(context.Background(), olt1, func(types.Driver) error {
+				n := inUse.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				inUse.Add(-1)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Do() error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak.Load() > 2 {
+		t.Errorf("peak concurrent sessions = %d, want <= 2", peak.Load())
+	}
+	if f.count() > 2 {
+		t.Errorf("created %d drivers, want <= 2", f.count())
+	}
+
+	// A blocked Acquire honours its context
+	s1, _ := m.Acquire(context.Background(), olt1)
+	s2, _ := m.Acquire(context.Background(), olt1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.Acquire(ctx, olt1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() error = %v, want deadline exceeded", err)
+	}
+	s1.Release()
+	s2.Release()
+}
+
+func TestDiscardAndConnectFailure(t *testing.T) {
+	f := &countingFactory{}
+	m := New(f.create, Config{IdleTimeout: -1})
+	defer m.Close()
+	ctx := context.Background()
+
+	s, _ := m.Acquire(ctx, olt1)
+	s.Discard()
+	if f.drivers[0].IsConnected() {
+		t.Error("Discard should disconnect the driver")
+	}
+	if got := m.Stats()["olt-1"]; got.Open != 0 {
+		t.Errorf("Stats() = %+v, want no open sessions", got)
+	}
+
+	f.err = errors.New("unsupported vendor")
+	if _, err := m.Acquire(ctx, olt1); err == nil {
+		t.Fatal("Acquire() expected factory error")
+	}
+	// The slot was returned: a later Acquire does not block
+	f.err = nil
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	s, err := m.Acquire(ctx, olt1)
+	if err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+	s.Release()
+}
+
+func TestEvictIdle(t *testing.T) {
+	f := &countingFactory{}
+	m := New(f.create, Config{IdleTimeout: 20 * time.Millisecond})
+	defer m.Close()
+
+	s, _ := m.Acquire(context.Background(), olt1)
+	s.Release()
+
+	deadline := time.Now().Add(time.Second)
+	for m.Stats()["olt-1"].Idle > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if m.Stats()["olt-1"].Idle != 0 {
+		t.Fatal("idle session not evicted")
+	}
+	if f.drivers[0].IsConnected() {
+		t.Error("evicted driver should be disconnected")
+	}
+}
+
+func TestClose(t *testing.T) {
+	f := &countingFactory{}
+	m := New(f.create, Config{MaxSessionsPerDevice: 2})
+	ctx := context.Background()
+
+	idle, _ := m.Acquire(ctx, olt1)
+	busy, _ := m.Acquire(ctx, olt1)
+	idle.Release()
+
+	m.Close()
+	if f.drivers[0].IsConnected() {
+		t.Error("Close should disconnect idle sessions")
+	}
+	if _, err := m.Acquire(ctx, olt1); !errors.Is(err, ErrClosed) {
+		t.Errorf("Acquire() after Close error = %v, want ErrClosed", err)
+	}
+
+	busy.Release()
+	if f.drivers[1].IsConnected() {
+		t.Error("sessions released after Close should be disconnected")
+	}
+}
@@ -0,0 +1,413 @@
+// Package preprovision provisions ONUs that have not been installed yet.
+// An entry registers the subscriber to create for a serial; the Queue
+// polls the autofind list of its devices and, once the serial appears,
+// creates the subscriber on the PON port it was found on and publishes
+// the outcome as a provisioning event.
+//
+// Entries are kept in a state.Store, so they survive restarts until they
+// are provisioned or removed.
+package preprovision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/events"
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/state"
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
+	"github.com/nanoncore/nano-southbound/workers"
+)
+
+// Defaults applied by New for zero Config fields
+const (
+	DefaultInterval    = time.Minute
+	DefaultTimeout     = 2 * time.Minute
+	DefaultConcurrency = 8
+	DefaultMaxAttempts = 3
+)
+
+// keyPrefix is the state.Store namespace of this package
+const keyPrefix = "preprovision/"
+
+// Operation is the operation of the provisioning events of this package
+const Operation = "preprovision"
+
+// Status is the state of an entry
+type Status string
+
+const (
+	// StatusPending means the serial has not been provisioned yet
+	StatusPending Status = "pending"
+	// StatusFailed means provisioning failed MaxAttempts times. The entry
+	// is not tried again until it is added again.
+	StatusFailed Status = "failed"
+)
+
+// Entry is the provisioning desired for a serial
+type Entry struct {
+	Serial string `json:"serial"`
+
+	// Device and PONPort, if set, limit where the serial is provisioned.
+	// Seen anywhere else, it is left in autofind.
+	Device  string `json:"device,omitempty"`
+	PONPort string `json:"pon_port,omitempty"`
+
+	Subscriber *model.Subscriber  `json:"subscriber"`
+	Tier       *model.ServiceTier `json:"tier,omitempty"`
+
+	Status    Status    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	Attempts  int       `json:"attempts,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// matches reports whether the entry may be provisioned at a place its
+// serial was discovered
+func (e *Entry) matches(device, ponPort string) bool {
+	return e.Status == StatusPending &&
+		(e.Device == "" || e.Device == device) &&
+		(e.PONPort == "" || e.PONPort == ponPort)
+}
+
+// Config configures a Queue
+type Config struct {
+	// Store keeps the entries. Defaults to a state.MemoryStore.
+	Store state.Store
+
+	// Interval between scans. Defaults to DefaultInterval.
+	Interval time.Duration
+
+	// Timeout bounds the scan and provisioning of one device, including
+	// connecting. Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// Concurrency is the number of devices scanned at once when Pool is
+	// nil. Defaults to DefaultConcurrency.
+	Concurrency int
+
+	// Pool bounds the devices scanned at once. If nil, a pool of
+	// Concurrency with one task per device is used.
+	Pool *workers.Pool
+
+	// MaxAttempts is the number of failed provisioning attempts after
+	// which an entry is marked failed. Defaults to DefaultMaxAttempts.
+	MaxAttempts int
+}
+
+// Result is the outcome of provisioning one entry
+type Result struct {
+	Serial       string `json:"serial"`
+	Device       string `json:"device"`
+	PONPort      string `json:"pon_port"`
+	SubscriberID string `json:"subscriber_id,omitempty"`
+	Err          error  `json:"-"`
+}
+
+// Report is the outcome of a scan
+type Report struct {
+	ScannedAt time.Time
+	Results   []Result
+
+	// Errors holds the devices whose autofind list could not be read
+	Errors map[string]error
+}
+
+// onuDiscoverer is the part of types.DriverV2 a scan uses
+type onuDiscoverer interface {
+	DiscoverONUs(ctx context.Context, ponPorts []string) ([]types.ONUDiscovery, error)
+}
+
+// Queue holds pre-provisioning entries and provisions them as their
+// serials are discovered. It is safe for concurrent use.
+type Queue struct {
+	devices   []*types.EquipmentConfig
+	factory   types.DriverFactory
+	publisher events.Publisher
+	config    Config
+
+	mu       sync.Mutex
+	inflight map[string]bool // serials being provisioned
+}
+
+// New creates a Queue scanning devices. publisher may be nil. A driver
+// that is not connected is connected for the scan and disconnected after.
+func New(devices []*types.EquipmentConfig, factory types.DriverFactory, publisher events.Publisher, config Config) *Queue {
+	if config.Store == nil {
+		config.Store = state.NewMemoryStore()
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	if config.Pool == nil {
+		config.Pool = workers.New(config.Concurrency, 1)
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	return &Queue{
+		devices:   devices,
+		factory:   factory,
+		publisher: publisher,
+		config:    config,
+		inflight:  make(map[string]bool),
+	}
+}
+
+// Add registers the provisioning of a serial, replacing any entry for it.
+// The subscriber's ONU serial is set to the entry's if it has none.
+func (q *Queue) Add(ctx context.Context, entry Entry) error {
+	entry.Serial = common.SerialKey(entry.Serial)
+	if entry.Serial == "" || entry.Subscriber == nil {
+		return fmt.Errorf("serial and subscriber are required")
+	}
+	sub := *entry.Subscriber
+	if primary := sub.GetPrimaryONU(); primary == nil {
+		sub.Spec.ONUSerial = entry.Serial
+	} else if common.SerialKey(primary.Serial) != entry.Serial {
+		return fmt.Errorf("subscriber %s has ONU %s, not %s", sub.Name, primary.Serial, entry.Serial)
+	}
+	entry.Subscriber = &sub
+	entry.Status = StatusPending
+	entry.Attempts = 0
+	entry.LastError = ""
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+	return state.PutJSON(ctx, q.config.Store, entryKey(entry.Serial), entry)
+}
+
+// Remove deletes the entry of a serial. Removing an unknown serial is not
+// an error.
+func (q *Queue) Remove(ctx context.Context, serial string) error {
+	return q.config.Store.Delete(ctx, entryKey(common.SerialKey(serial)))
+}
+
+// Get returns the entry of a serial, or nil if there is none
+func (q *Queue) Get(ctx context.Context, serial string) (*Entry, error) {
+	var entry Entry
+	err := state.GetJSON(ctx, q.config.Store, entryKey(common.SerialKey(serial)), &entry)
+	if errors.Is(err, state.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// List returns every entry, pending and failed, sorted by serial
+func (q *Queue) List(ctx context.Context) ([]Entry, error) {
+	keys, err := q.config.Store.List(ctx, keyPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pre-provisioning entries: %w", err)
+	}
+	entries := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+		var entry Entry
+		if err := state.GetJSON(ctx, q.config.Store, key, &entry); err != nil {
+			if errors.Is(err, state.ErrNotFound) {
+				continue // provisioned meanwhile
+			}
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Run scans immediately and then every Interval until ctx is cancelled
+func (q *Queue) Run(ctx context.Context) error {
+	ticker := time.NewTicker(q.config.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := q.Scan(ctx); err != nil {
+			slog.Warn("pre-provisioning scan failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan reads the autofind list of every device and provisions the pending
+// entries whose serials it finds. Devices are not read while no entry is
+// pending.
+func (q *Queue) Scan(ctx context.Context) (*Report, error) {
+	report := &Report{ScannedAt: time.Now().UTC(), Errors: make(map[string]error)}
+	entries, err := q.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !hasPending(entries) {
+		return report, nil
+	}
+
+	results := make([][]Result, len(q.devices))
+	readErrs := make([]error, len(q.devices))
+	keys := make([]string, len(q.devices))
+	for i, config := range q.devices {
+		keys[i] = config.Name
+	}
+	errs := q.config.Pool.Each(ctx, keys, func(i int) error {
+		results[i], readErrs[i] = q.scanDevice(ctx, q.devices[i])
+		return nil
+	})
+	for i, err := range errs {
+		if err == nil {
+			err = readErrs[i]
+		}
+		if err != nil {
+			report.Errors[q.devices[i].Name] = err
+		}
+		report.Results = append(report.Results, results[i]...)
+	}
+	return report, nil
+}
+
+// scanDevice reads the autofind list of one device and provisions the
+// entries found in it
+func (q *Queue) scanDevice(ctx context.Context, config *types.EquipmentConfig) ([]Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, q.config.Timeout)
+	defer cancel()
+
+	driver, err := q.factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver for %s: %w", config.Name, err)
+	}
+	disc, ok := driver.(onuDiscoverer)
+	if !ok {
+		return nil, nil
+	}
+	if !driver.IsConnected() {
+		if err := driver.Connect(ctx, config); err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", config.Name, err)
+		}
+		defer driver.Disconnect(context.WithoutCancel(ctx)) //nolint:errcheck // best effort
+	}
+	discovered, err := disc.DiscoverONUs(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read autofind of %s: %w", config.Name, err)
+	}
+	return q.Observe(ctx, config, driver, discovered)
+}
+
+// Observe provisions the pending entries among the ONUs discovered on a
+// device with its connected driver. It is what Scan does per device, for
+// callers that read the autofind list themselves. A failed provisioning is
+// reported in its Result and counted against the entry's attempts; the
+// error is for entries that could not be read or updated.
+func (q *Queue) Observe(ctx context.Context, config *types.EquipmentConfig, driver types.Driver, discovered []types.ONUDiscovery) ([]Result, error) {
+	var results []Result
+	for _, onu := range discovered {
+		serial := common.SerialKey(onu.Serial)
+		if !q.claim(serial) {
+			continue
+		}
+		res, err := q.provision(ctx, config, driver, serial, onu.PONPort)
+		q.unclaim(serial)
+		if err != nil {
+			return results, err
+		}
+		if res != nil {
+			results = append(results, *res)
+		}
+	}
+	return results, nil
+}
+
+// provision creates the subscriber of the entry of serial, if it is
+// pending for this place, and records the outcome
+func (q *Queue) provision(ctx context.Context, config *types.EquipmentConfig, driver types.Driver, serial, ponPort string) (*Result, error) {
+	entry, err := q.Get(ctx, serial)
+	if err != nil || entry == nil || !entry.matches(config.Name, ponPort) {
+		return nil, err
+	}
+
+	log := config.Log().With("serial", serial, "pon_port", ponPort, "subscriber", entry.Subscriber.Name)
+	res := &Result{Serial: serial, Device: config.Name, PONPort: ponPort}
+	created, err := driver.CreateSubscriber(ctx, types.PlaceSubscriber(entry.Subscriber, ponPort), entry.Tier)
+	if err == nil {
+		res.SubscriberID = created.SubscriberID
+	}
+	q.publish(config.Name, entry, res, err)
+	if err != nil {
+		res.Err = err
+		entry.Attempts++
+		entry.LastError = err.Error()
+		if entry.Attempts >= q.config.MaxAttempts {
+			entry.Status = StatusFailed
+		}
+		log.Warn("pre-provisioning failed", "attempts", entry.Attempts, "error", err)
+		return res, state.PutJSON(context.WithoutCancel(ctx), q.config.Store, entryKey(serial), entry)
+	}
+
+	log.Info("pre-provisioned ONU", "subscriber_id", created.SubscriberID)
+	return res, q.config.Store.Delete(context.WithoutCancel(ctx), entryKey(serial))
+}
+
+// publish publishes the provisioning event of an entry
+func (q *Queue) publish(device string, entry *Entry, res *Result, err error) {
+	if q.publisher == nil {
+		return
+	}
+	data := map[string]any{
+		"operation":  Operation,
+		"subscriber": entry.Subscriber.Name,
+		"serial":     entry.Serial,
+		"pon_port":   res.PONPort,
+	}
+	typ := events.TypeProvisioningSucceeded
+	if err != nil {
+		typ = events.TypeProvisioningFailed
+		data["error"] = err.Error()
+	} else {
+		data["subscriber_id"] = res.SubscriberID
+	}
+	q.publisher.Publish(events.Event{Type: typ, Device: device, Data: data})
+}
+
+// claim marks a serial as being provisioned, so a serial in the autofind
+// list of two devices scanned at once is only provisioned once. It
+// returns false if the serial is already claimed.
+func (q *Queue) claim(serial string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.inflight[serial] {
+		return false
+	}
+	q.inflight[serial] = true
+	return true
+}
+
+func (q *Queue) unclaim(serial string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.inflight, serial)
+}
+
+func hasPending(entries []Entry) bool {
+	for _, e := range entries {
+		if e.Status == StatusPending {
+			return true
+		}
+	}
+	return false
+}
+
+func entryKey(serial string) string {
+	return keyPrefix + serial
+}
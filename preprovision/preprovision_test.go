@@ -0,0 +1,130 @@
+package preprovision
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/events"
+	"github.com/nanoncore/nano-southbound/state"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (r *recorder) Publish(e events.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func newQueue(t *testing.T, store state.Store, publisher events.Publisher) (*Queue, map[string]*testutil.MockDriverV2) {
+	t.Helper()
+	drivers := map[string]*testutil.MockDriverV2{
+		"olt-1": {Connected: true},
+		"olt-2": {Connected: true},
+	}
+	devices := []*types.EquipmentConfig{{Name: "olt-1"}, {Name: "olt-2"}}
+	factory := func(c *types.EquipmentConfig) (types.Driver, error) {
+		d, ok := drivers[c.Name]
+		if !ok {
+			return nil, errors.New("no driver")
+		}
+		return d, nil
+	}
+	return New(devices, factory, publisher, Config{Store: store, MaxAttempts: 2}), drivers
+}
+
+func TestScan(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore()
+	rec := &recorder{}
+	q, drivers := newQueue(t, store, rec)
+
+	sub := testutil.NewTestSubscriber("", "", 100)
+	sub.Name = "alice"
+	// Entries match the ASCII serials of autofind in any representation
+	if err := q.Add(ctx, Entry{Serial: "fhtt-00000001", Subscriber: sub, Tier: testutil.NewTestServiceTier(50, 100)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Add(ctx, Entry{Serial: "4648545400000002", Device: "olt-1", Subscriber: testutil.NewTestSubscriber("FHTT00000002", "", 200)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Add(ctx, Entry{Serial: "FHTT00000003", Subscriber: testutil.NewTestSubscriber("FHTT00000004", "", 200)}); err == nil {
+		t.Error("Add() of a subscriber with another serial succeeded")
+	}
+
+	// Nothing in autofind yet
+	report, err := q.Scan(ctx)
+	if err != nil || len(report.Results) != 0 || len(report.Errors) != 0 {
+		t.Fatalf("Scan() = %+v, %v", report, err)
+	}
+
+	drivers["olt-2"].Discovered = []types.ONUDiscovery{
+		{PONPort: "0/3", Serial: "FHTT00000001"},
+		{PONPort: "0/3", Serial: "FHTT00000002"}, // only wanted on olt-1
+		{PONPort: "0/4", Serial: "FHTT00000009"},
+	}
+	report, err = q.Scan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 1 || report.Results[0].Device != "olt-2" || report.Results[0].PONPort != "0/3" ||
+		report.Results[0].SubscriberID != "alice" || report.Results[0].Err != nil {
+		t.Fatalf("Scan() results = %+v", report.Results)
+	}
+	onu := drivers["olt-2"].ONUs[0]
+	if onu.Serial != "FHTT00000001" || onu.PONPort != "0/3" || onu.VLAN != 100 || onu.BandwidthDown != 100 {
+		t.Errorf("provisioned ONU = %+v", onu)
+	}
+	if e, _ := q.Get(ctx, "FHTT00000001"); e != nil {
+		t.Errorf("entry after provisioning = %+v, want removed", e)
+	}
+	if len(rec.events) != 1 || rec.events[0].Type != events.TypeProvisioningSucceeded || rec.events[0].Data["subscriber_id"] != "alice" {
+		t.Errorf("events = %+v", rec.events)
+	}
+
+	// The entry survives a restart on the same store
+	q, _ = newQueue(t, store, nil)
+	entries, err := q.List(ctx)
+	if err != nil || len(entries) != 1 || entries[0].Serial != "FHTT00000002" || entries[0].Status != StatusPending {
+		t.Errorf("List() = %+v, %v", entries, err)
+	}
+}
+
+func TestScanFailure(t *testing.T) {
+	ctx := context.Background()
+	rec := &recorder{}
+	q, drivers := newQueue(t, nil, rec)
+	if err := q.Add(ctx, Entry{Serial: "FHTT00000001", Subscriber: testutil.NewTestSubscriber("FHTT00000001", "", 100)}); err != nil {
+		t.Fatal(err)
+	}
+	drivers["olt-1"].Discovered = []types.ONUDiscovery{{PONPort: "0/1", Serial: "FHTT00000001"}}
+	drivers["olt-1"].Faults = map[string]*testutil.Fault{"CreateSubscriber": {Err: errors.New("line profile missing")}}
+	drivers["olt-2"].Faults = map[string]*testutil.Fault{"DiscoverONUs": {Err: errors.New("timeout")}}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		report, err := q.Scan(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Failed after MaxAttempts, the entry is not pending and the
+		// devices are not read again
+		scanned := attempt < 3
+		if (report.Errors["olt-2"] != nil) != scanned || (len(report.Results) == 1) != scanned {
+			t.Errorf("scan %d = %+v", attempt, report)
+		}
+	}
+	entry, err := q.Get(ctx, "FHTT00000001")
+	if err != nil || entry.Status != StatusFailed || entry.Attempts != 2 || entry.LastError != "line profile missing" {
+		t.Errorf("entry = %+v, %v", entry, err)
+	}
+	if len(rec.events) != 2 || rec.events[1].Type != events.TypeProvisioningFailed {
+		t.Errorf("events = %+v", rec.events)
+	}
+}
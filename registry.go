@@ -0,0 +1,440 @@
+package southbound
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/state"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// tagKeyPrefix is the state.Store namespace of device tags
+const tagKeyPrefix = "tags/"
+
+// DeviceState is the connection state of a registered device
+type DeviceState string
+
+const (
+	// DeviceStateRegistered means the driver exists but was never connected
+	DeviceStateRegistered DeviceState = "registered"
+	// DeviceStateConnected means the last connect succeeded
+	DeviceStateConnected DeviceState = "connected"
+	// DeviceStateDisconnected means the device was disconnected on request
+	DeviceStateDisconnected DeviceState = "disconnected"
+	// DeviceStateFailed means the last connect or disconnect failed
+	DeviceStateFailed DeviceState = "failed"
+)
+
+// DriverFactory creates the driver for a device. NewDriver is the default.
+type DriverFactory func(vendor Vendor, protocol Protocol, config *EquipmentConfig) (Driver, error)
+
+// Device is a device held by a Registry
+type Device struct {
+	config *EquipmentConfig
+	driver Driver
+
+	mu          sync.Mutex
+	state       DeviceState
+	lastErr     error
+	connectedAt time.Time
+
+	// tags has its own lock, as mu is held while connecting
+	tagMu sync.RWMutex
+	tags  map[string]string
+
+	health health
+}
+
+// Name returns the device name
+func (d *Device) Name() string { return d.config.Name }
+
+// Config returns the device configuration
+func (d *Device) Config() *EquipmentConfig { return d.config }
+
+// Driver returns the vendor adapter for the device
+func (d *Device) Driver() Driver { return d.driver }
+
+// State returns the connection state and the error of the last failed
+// connect or disconnect
+func (d *Device) State() (DeviceState, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state, d.lastErr
+}
+
+// ConnectedAt returns when the device last connected
+func (d *Device) ConnectedAt() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.connectedAt
+}
+
+// Tags returns a copy of the device tags: those of its configuration,
+// updated by Registry.SetTags and DeleteTags
+func (d *Device) Tags() map[string]string {
+	d.tagMu.RLock()
+	defer d.tagMu.RUnlock()
+	return maps.Clone(d.tags)
+}
+
+// HasTag reports whether the device has tag key, with value if value is
+// non-empty
+func (d *Device) HasTag(key, value string) bool {
+	d.tagMu.RLock()
+	defer d.tagMu.RUnlock()
+	v, ok := d.tags[key]
+	return ok && (value == "" || v == value)
+}
+
+// matches reports whether the device tags satisfy sel
+func (d *Device) matches(sel types.Selector) bool {
+	d.tagMu.RLock()
+	defer d.tagMu.RUnlock()
+	return sel.Matches(d.tags)
+}
+
+func (d *Device) connect(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.driver.Connect(ctx, d.config); err != nil {
+		d.state = DeviceStateFailed
+		d.lastErr = err
+		return fmt.Errorf("failed to connect %s: %w", d.config.Name, err)
+	}
+	d.state = DeviceStateConnected
+	d.lastErr = nil
+	d.connectedAt = time.Now()
+	return nil
+}
+
+func (d *Device) disconnect(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.driver.IsConnected() {
+		if d.state == DeviceStateConnected {
+			d.state = DeviceStateDisconnected
+		}
+		return nil
+	}
+	if err := d.driver.Disconnect(ctx); err != nil {
+		d.state = DeviceStateFailed
+		d.lastErr = err
+		return fmt.Errorf("failed to disconnect %s: %w", d.config.Name, err)
+	}
+	d.state = DeviceStateDisconnected
+	return nil
+}
+
+// Registry holds the managed devices, builds their vendor adapters and
+// manages their connections. It is safe for concurrent use.
+type Registry struct {
+	factory DriverFactory
+
+	mu       sync.RWMutex
+	devices  map[string]*Device
+	tagStore state.Store
+}
+
+// NewRegistry creates an empty registry. A nil factory uses NewDriver.
+func NewRegistry(factory DriverFactory) *Registry {
+	if factory == nil {
+		factory = func(vendor Vendor, protocol Protocol, config *EquipmentConfig) (Driver, error) {
+			return NewDriver(vendor, protocol, config)
+		}
+	}
+	return &Registry{
+		factory: factory,
+		devices: make(map[string]*Device),
+	}
+}
+
+// Add registers a device and creates its driver. Devices are keyed by
+// name, which must be unique. The device is not connected.
+func (r *Registry) Add(config *EquipmentConfig) (*Device, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+	if config.Name == "" {
+		return nil, fmt.Errorf("device name is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.devices[config.Name]; ok {
+		return nil, fmt.Errorf("device %q already registered", config.Name)
+	}
+	driver, err := r.factory(config.Vendor, config.Protocol, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver for %s: %w", config.Name, err)
+	}
+
+	dev := &Device{config: config, driver: driver, state: DeviceStateRegistered, tags: maps.Clone(config.Tags)}
+	if dev.tags == nil {
+		dev.tags = make(map[string]string)
+	}
+	r.devices[config.Name] = dev
+	return dev, nil
+}
+
+// Remove disconnects and unregisters a device. The device is removed even
+// if disconnecting fails. Tenant-scoped callers may only remove devices of
+// their tenant.
+func (r *Registry) Remove(ctx context.Context, name string) error {
+	r.mu.Lock()
+	dev, ok := r.devices[name]
+	if ok && types.CheckTenant(ctx, "device", name, dev.config.Tenant) != nil {
+		ok = false
+	}
+	if ok {
+		delete(r.devices, name)
+	}
+	r.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("device %q not found", name)
+	}
+	return dev.disconnect(ctx)
+}
+
+// Lookup returns the named device if ctx may reach it. Devices of another
+// tenant than ctx is scoped to are reported as not found, so their names
+// do not leak.
+func (r *Registry) Lookup(ctx context.Context, name string) (*Device, error) {
+	dev, ok := r.Get(name)
+	if !ok || types.CheckTenant(ctx, "device", name, dev.config.Tenant) != nil {
+		return nil, fmt.Errorf("device %q not found", name)
+	}
+	return dev, nil
+}
+
+// Visible returns the devices ctx may reach, sorted by name: every device
+// for unscoped contexts, the tenant's devices otherwise
+func (r *Registry) Visible(ctx context.Context) []*Device {
+	return r.Select(func(d *Device) bool {
+		return types.CheckTenant(ctx, "device", d.config.Name, d.config.Tenant) == nil
+	})
+}
+
+// Match returns the devices whose tags satisfy sel, sorted by name
+func (r *Registry) Match(sel types.Selector) []*Device {
+	return r.Select(func(d *Device) bool { return d.matches(sel) })
+}
+
+// TagsOf returns the current tags of the registered device with config's
+// name, or config.Tags if it is not registered. Its signature matches
+// orchestrator.Config.TagsOf and collector.Config.TagsOf, so tags set at
+// runtime drive their selection.
+func (r *Registry) TagsOf(config *EquipmentConfig) map[string]string {
+	if dev, ok := r.Get(config.Name); ok {
+		return dev.Tags()
+	}
+	return config.Tags
+}
+
+// LoadTags reads the tags persisted for every registered device from
+// store, replacing their configured tags, and persists later changes made
+// with SetTags and DeleteTags there. Call it after adding the devices.
+func (r *Registry) LoadTags(ctx context.Context, store state.Store) error {
+	r.mu.Lock()
+	r.tagStore = store
+	r.mu.Unlock()
+
+	for _, dev := range r.List() {
+		var tags map[string]string
+		err := state.GetJSON(ctx, store, tagKeyPrefix+dev.config.Name, &tags)
+		if errors.Is(err, state.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load tags of %s: %w", dev.config.Name, err)
+		}
+		if tags == nil {
+			tags = make(map[string]string)
+		}
+		dev.tagMu.Lock()
+		dev.tags = tags
+		dev.tagMu.Unlock()
+	}
+	return nil
+}
+
+// SetTags adds or replaces tags of the named device and persists them if
+// LoadTags was called
+func (r *Registry) SetTags(ctx context.Context, name string, tags map[string]string) error {
+	return r.updateTags(ctx, name, func(t map[string]string) { maps.Copy(t, tags) })
+}
+
+// DeleteTags removes tags of the named device and persists the change if
+// LoadTags was called
+func (r *Registry) DeleteTags(ctx context.Context, name string, keys ...string) error {
+	return r.updateTags(ctx, name, func(t map[string]string) {
+		for _, k := range keys {
+			delete(t, k)
+		}
+	})
+}
+
+func (r *Registry) updateTags(ctx context.Context, name string, update func(map[string]string)) error {
+	dev, err := r.Lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+	r.mu.RLock()
+	store := r.tagStore
+	r.mu.RUnlock()
+
+	dev.tagMu.Lock()
+	defer dev.tagMu.Unlock()
+	tags := maps.Clone(dev.tags)
+	update(tags)
+	if store != nil {
+		if err := state.PutJSON(ctx, store, tagKeyPrefix+name, tags); err != nil {
+			return fmt.Errorf("failed to save tags of %s: %w", name, err)
+		}
+	}
+	dev.tags = tags
+	return nil
+}
+
+// ByTenant returns the devices of a tenant
+func (r *Registry) ByTenant(tenant string) []*Device {
+	return r.Select(func(d *Device) bool { return d.config.Tenant == tenant })
+}
+
+// Get returns the device with the given name. Get, Driver and the other
+// methods without a context are not tenant-scoped; use Lookup and Visible
+// on behalf of tenant-scoped callers.
+func (r *Registry) Get(name string) (*Device, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	dev, ok := r.devices[name]
+	return dev, ok
+}
+
+// Driver returns the driver of the named device
+func (r *Registry) Driver(name string) (Driver, error) {
+	dev, ok := r.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("device %q not found", name)
+	}
+	return dev.driver, nil
+}
+
+// DriverFor returns the driver of the registered device with config's
+// name. Its signature matches types.DriverFactory and pool.Factory, so
+// the subsystems taking a factory can share the registry's adapters.
+func (r *Registry) DriverFor(config *EquipmentConfig) (Driver, error) {
+	return r.Driver(config.Name)
+}
+
+// Configs returns the configuration of every device, sorted by name
+func (r *Registry) Configs() []*EquipmentConfig {
+	devices := r.List()
+	configs := make([]*EquipmentConfig, len(devices))
+	for i, dev := range devices {
+		configs[i] = dev.config
+	}
+	return configs
+}
+
+// ByAddress returns the devices with the given management address
+func (r *Registry) ByAddress(address string) []*Device {
+	return r.Select(func(d *Device) bool { return d.config.Address == address })
+}
+
+// ByTag returns the devices with tag key. If value is non-empty, the tag
+// must also have that value.
+func (r *Registry) ByTag(key, value string) []*Device {
+	return r.Select(func(d *Device) bool { return d.HasTag(key, value) })
+}
+
+// List returns every device, sorted by name
+func (r *Registry) List() []*Device {
+	return r.Select(func(*Device) bool { return true })
+}
+
+// Select returns the devices matching match, sorted by name
+func (r *Registry) Select(match func(*Device) bool) []*Device {
+	r.mu.RLock()
+	var devices []*Device
+	for _, dev := range r.devices {
+		if match(dev) {
+			devices = append(devices, dev)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(devices, func(i, j int) bool { return devices[i].config.Name < devices[j].config.Name })
+	return devices
+}
+
+// Len returns the number of registered devices
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.devices)
+}
+
+// Connect connects the named device
+func (r *Registry) Connect(ctx context.Context, name string) error {
+	dev, err := r.Lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+	return dev.connect(ctx)
+}
+
+// Disconnect disconnects the named device
+func (r *Registry) Disconnect(ctx context.Context, name string) error {
+	dev, err := r.Lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+	return dev.disconnect(ctx)
+}
+
+// ConnectAll connects every device ctx may reach that is not connected,
+// concurrently. It returns the connect errors keyed by device name.
+func (r *Registry) ConnectAll(ctx context.Context) map[string]error {
+	return r.each(r.Visible(ctx), func(dev *Device) error {
+		if dev.driver.IsConnected() {
+			return nil
+		}
+		return dev.connect(ctx)
+	})
+}
+
+// Close disconnects every device ctx may reach concurrently and returns
+// the disconnect errors keyed by device name. Devices stay registered.
+func (r *Registry) Close(ctx context.Context) map[string]error {
+	return r.each(r.Visible(ctx), func(dev *Device) error { return dev.disconnect(ctx) })
+}
+
+func (r *Registry) each(devices []*Device, fn func(*Device) error) map[string]error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = make(map[string]error)
+	)
+	for _, dev := range devices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(dev); err != nil {
+				mu.Lock()
+				errs[dev.config.Name] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
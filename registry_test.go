@@ -0,0 +1,243 @@
+package southbound
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/state"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// mockFactory hands out MockDrivers keyed by device name
+type mockFactory map[string]*testutil.MockDriver
+
+func (f mockFactory) create(_ Vendor, _ Protocol, config *EquipmentConfig) (Driver, error) {
+	if config.Vendor == "unknown" {
+		return nil, errors.New("unsupported vendor: unknown")
+	}
+	d := &testutil.MockDriver{}
+	f[config.Name] = d
+	return d, nil
+}
+
+func newTestRegistry(t *testing.T) (*Registry, mockFactory) {
+	t.Helper()
+	f := mockFactory{}
+	r := NewRegistry(f.create)
+	for _, cfg := range []*EquipmentConfig{
+		{Name: "olt-b", Vendor: VendorHuawei, Address: "192.0.2.2", Tags: map[string]string{"site": "ams", "core": ""}},
+		{Name: "olt-a", Vendor: VendorVSOL, Address: "192.0.2.1", Tags: map[string]string{"site": "ams"}},
+		{Name: "olt-c", Vendor: VendorZTE, Address: "192.0.2.1", Tags: map[string]string{"site": "fra"}},
+	} {
+		if _, err := r.Add(cfg); err != nil {
+			t.Fatalf("Add(%s) error: %v", cfg.Name, err)
+		}
+	}
+	return r, f
+}
+
+func names(devices []*Device) string {
+	n := make([]string, len(devices))
+	for i, d := range devices {
+		n[i] = d.Name()
+	}
+	return strings.Join(n, ",")
+}
+
+func TestRegistryAdd(t *testing.T) {
+	r, _ := newTestRegistry(t)
+
+	if _, err := r.Add(&EquipmentConfig{Name: "olt-a"}); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Errorf("duplicate Add() error = %v", err)
+	}
+	if _, err := r.Add(&EquipmentConfig{Vendor: VendorZTE}); err == nil {
+		t.Error("Add() without name should fail")
+	}
+	if _, err := r.Add(&EquipmentConfig{Name: "x", Vendor: "unknown"}); err == nil {
+		t.Error("Add() should propagate factory errors")
+	}
+	if r.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", r.Len())
+	}
+
+	dev, ok := r.Get("olt-a")
+	if !ok || dev.Config().Vendor != VendorVSOL {
+		t.Fatalf("Get(olt-a) = %v, %v", dev, ok)
+	}
+	if state, _ := dev.State(); state != DeviceStateRegistered {
+		t.Errorf("state = %s, want registered", state)
+	}
+}
+
+func TestRegistryLookup(t *testing.T) {
+	r, _ := newTestRegistry(t)
+
+	if got := names(r.List()); got != "olt-a,olt-b,olt-c" {
+		t.Errorf("List() = %s", got)
+	}
+	if got := names(r.ByAddress("192.0.2.1")); got != "olt-a,olt-c" {
+		t.Errorf("ByAddress() = %s", got)
+	}
+	if got := names(r.ByTag("site", "ams")); got != "olt-a,olt-b" {
+		t.Errorf("ByTag(site=ams) = %s", got)
+	}
+	if got := names(r.ByTag("site", "")); got != "olt-a,olt-b,olt-c" {
+		t.Errorf("ByTag(site) = %s", got)
+	}
+	if got := names(r.ByTag("core", "")); got != "olt-b" {
+		t.Errorf("ByTag(core) = %s", got)
+	}
+	if _, err := r.Driver("missing"); err == nil {
+		t.Error("Driver() of unknown device should fail")
+	}
+	if d, err := r.DriverFor(&EquipmentConfig{Name: "olt-c"}); err != nil || d == nil {
+		t.Errorf("DriverFor() = %v, %v", d, err)
+	}
+	if n := len(r.Configs()); n != 3 {
+		t.Errorf("Configs() returned %d configs", n)
+	}
+}
+
+func TestRegistryTenants(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	for name, tenant := range map[string]string{"olt-a": "isp-a", "olt-b": "isp-b"} {
+		dev, _ := r.Get(name)
+		dev.Config().Tenant = tenant
+	}
+	ctx := types.WithTenant(context.Background(), "isp-a")
+
+	if got := names(r.Visible(ctx)); got != "olt-a" {
+		t.Errorf("Visible(isp-a) = %s", got)
+	}
+	if got := names(r.Visible(context.Background())); got != "olt-a,olt-b,olt-c" {
+		t.Errorf("Visible(unscoped) = %s", got)
+	}
+	if got := names(r.ByTenant("isp-b")); got != "olt-b" {
+		t.Errorf("ByTenant(isp-b) = %s", got)
+	}
+	if _, err := r.Lookup(ctx, "olt-b"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Lookup() of another tenant's device = %v", err)
+	}
+	if err := r.Connect(ctx, "olt-c"); err == nil {
+		t.Error("Connect() of an unlabeled device should fail for a scoped caller")
+	}
+	if errs := r.ConnectAll(ctx); len(errs) != 0 {
+		t.Errorf("ConnectAll() = %v", errs)
+	}
+	if state, _ := mustGet(t, r, "olt-b").State(); state != DeviceStateRegistered {
+		t.Errorf("olt-b state = %s, want untouched", state)
+	}
+	if err := r.Remove(ctx, "olt-b"); err == nil || r.Len() != 3 {
+		t.Errorf("Remove() of another tenant's device = %v, %d devices left", err, r.Len())
+	}
+}
+
+func mustGet(t *testing.T, r *Registry, name string) *Device {
+	t.Helper()
+	dev, ok := r.Get(name)
+	if !ok {
+		t.Fatalf("device %s not found", name)
+	}
+	return dev
+}
+
+func TestRegistryTags(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewMemoryStore()
+	r, _ := newTestRegistry(t)
+	if err := r.LoadTags(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.SetTags(ctx, "olt-c", map[string]string{"site": "ams", "ring": "3"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.DeleteTags(ctx, "olt-b", "core"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetTags(ctx, "missing", map[string]string{"site": "ams"}); err == nil {
+		t.Error("SetTags() of unknown device should fail")
+	}
+	if got := names(r.Match(types.Selector{"site": "ams"})); got != "olt-a,olt-b,olt-c" {
+		t.Errorf("Match(site=ams) = %s", got)
+	}
+	if got := names(r.ByTag("core", "")); got != "" {
+		t.Errorf("ByTag(core) = %s after delete", got)
+	}
+	if got := r.TagsOf(&EquipmentConfig{Name: "olt-c"}); got["ring"] != "3" {
+		t.Errorf("TagsOf(olt-c) = %v", got)
+	}
+	if cfg := mustGet(t, r, "olt-c").Config(); cfg.Tags["site"] != "fra" {
+		t.Error("SetTags() must not modify the device configuration")
+	}
+
+	// A new registry with the same configuration picks the tags up
+	r2, _ := newTestRegistry(t)
+	if err := r2.LoadTags(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(r2.Match(types.Selector{"ring": "3"})); got != "olt-c" {
+		t.Errorf("Match(ring=3) after reload = %s", got)
+	}
+	if mustGet(t, r2, "olt-b").HasTag("core", "") {
+		t.Error("deleted tag came back after reload")
+	}
+	if !mustGet(t, r2, "olt-a").HasTag("site", "ams") {
+		t.Error("configured tags of devices without persisted tags should be kept")
+	}
+}
+
+func TestRegistryLifecycle(t *testing.T) {
+	r, drivers := newTestRegistry(t)
+	ctx := context.Background()
+	drivers["olt-c"].ConnectError = errors.New("connection refused")
+
+	errs := r.ConnectAll(ctx)
+	if len(errs) != 1 || errs["olt-c"] == nil {
+		t.Fatalf("ConnectAll() errors = %v", errs)
+	}
+	dev, _ := r.Get("olt-a")
+	if state, err := dev.State(); state != DeviceStateConnected || err != nil {
+		t.Errorf("olt-a state = %s, %v", state, err)
+	}
+	if dev.ConnectedAt().IsZero() {
+		t.Error("ConnectedAt not set")
+	}
+	failed, _ := r.Get("olt-c")
+	if state, err := failed.State(); state != DeviceStateFailed || err == nil {
+		t.Errorf("olt-c state = %s, %v", state, err)
+	}
+
+	if err := r.Disconnect(ctx, "olt-a"); err != nil {
+		t.Fatalf("Disconnect() error: %v", err)
+	}
+	if drivers["olt-a"].IsConnected() {
+		t.Error("driver still connected")
+	}
+	if state, _ := dev.State(); state != DeviceStateDisconnected {
+		t.Errorf("state = %s, want disconnected", state)
+	}
+
+	if err := r.Connect(ctx, "olt-a"); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	if err := r.Remove(ctx, "olt-a"); err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	if drivers["olt-a"].IsConnected() {
+		t.Error("Remove should disconnect")
+	}
+	if _, ok := r.Get("olt-a"); ok {
+		t.Error("device still registered after Remove")
+	}
+
+	if errs := r.Close(ctx); len(errs) != 0 {
+		t.Errorf("Close() errors = %v", errs)
+	}
+	if drivers["olt-b"].IsConnected() {
+		t.Error("Close should disconnect every device")
+	}
+}
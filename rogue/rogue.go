@@ -0,0 +1,390 @@
+// Package rogue detects rogue and alien ONUs across a fleet of OLTs:
+//
+//   - ONUs transmitting outside their assigned upstream window, as reported
+//     by OLTs that detect them (types.RogueONUDetector)
+//   - serials provisioned on more than one PON port, on one OLT or several
+//   - serials in autofind on one port while provisioned on another, e.g. a
+//     cloned ONU or one moved without being deprovisioned
+//
+// The last two need the whole fleet, so each scan reads every device
+// before comparing. Findings are published as events when first seen and
+// again when they are gone.
+package rogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/events"
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
+	"github.com/nanoncore/nano-southbound/workers"
+)
+
+// Defaults applied by New for zero Config fields
+const (
+	DefaultInterval    = 5 * time.Minute
+	DefaultTimeout     = 2 * time.Minute
+	DefaultConcurrency = 8
+)
+
+// Config configures a Detector
+type Config struct {
+	// Interval between scans. Defaults to DefaultInterval.
+	Interval time.Duration
+
+	// Timeout bounds the reads of one device, including connecting.
+	// Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// Concurrency is the number of devices read at once when Pool is nil.
+	// Defaults to DefaultConcurrency.
+	Concurrency int
+
+	// Pool bounds the devices read at once. If nil, a pool of Concurrency
+	// with one task per device is used.
+	Pool *workers.Pool
+}
+
+// Finding is a rogue ONU seen on a device
+type Finding struct {
+	Device string `json:"device"`
+	types.RogueONU
+
+	// Elsewhere lists the other places the serial is provisioned, as
+	// "<device>:<pon port>", for duplicate serials and ONUs provisioned
+	// elsewhere
+	Elsewhere []string `json:"elsewhere,omitempty"`
+}
+
+// key identifies a finding across scans
+func (f Finding) key() string {
+	return fmt.Sprintf("%s|%s|%s|%d|%s", f.Kind, f.Device, f.PONPort, f.ONUID, f.Serial)
+}
+
+// Report is the outcome of a scan
+type Report struct {
+	ScannedAt time.Time
+	Findings  []Finding
+
+	// Errors holds the devices that could not be fully read. Their
+	// earlier findings are kept rather than cleared.
+	Errors map[string]error
+}
+
+// onuLister and onuDiscoverer are the parts of types.DriverV2 a scan uses
+type onuLister interface {
+	GetONUList(ctx context.Context, filter *types.ONUFilter) ([]types.ONUInfo, error)
+}
+
+type onuDiscoverer interface {
+	DiscoverONUs(ctx context.Context, ponPorts []string) ([]types.ONUDiscovery, error)
+}
+
+// reading is what a scan read from one device
+type reading struct {
+	onus       []types.ONUInfo
+	discovered []types.ONUDiscovery
+	rogue      []types.RogueONU
+	err        error
+}
+
+// Detector scans a fixed set of devices for rogue ONUs. It is safe for
+// concurrent use.
+type Detector struct {
+	devices   []*types.EquipmentConfig
+	factory   types.DriverFactory
+	publisher events.Publisher
+	config    Config
+
+	mu     sync.Mutex
+	active map[string]Finding // by key
+}
+
+// New creates a Detector. publisher may be nil. A driver that is not
+// connected is connected for the scan and disconnected after.
+func New(devices []*types.EquipmentConfig, factory types.DriverFactory, publisher events.Publisher, config Config) *Detector {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	if config.Pool == nil {
+		config.Pool = workers.New(config.Concurrency, 1)
+	}
+	return &Detector{
+		devices:   devices,
+		factory:   factory,
+		publisher: publisher,
+		config:    config,
+		active:    make(map[string]Finding),
+	}
+}
+
+// Run scans immediately and then every Interval until ctx is cancelled
+func (d *Detector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+	for {
+		d.Scan(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Active returns the findings of the last scan that are still open,
+// sorted by device, port and serial
+func (d *Detector) Active() []Finding {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	findings := make([]Finding, 0, len(d.active))
+	for _, f := range d.active {
+		findings = append(findings, f)
+	}
+	sortFindings(findings)
+	return findings
+}
+
+// Scan reads every device, compares the fleet and returns the findings.
+// New findings are published as events.TypeRogueONUDetected, findings of
+// an earlier scan no longer seen as events.TypeRogueONUCleared.
+func (d *Detector) Scan(ctx context.Context) *Report {
+	readings := make([]reading, len(d.devices))
+	keys := make([]string, len(d.devices))
+	for i, config := range d.devices {
+		keys[i] = config.Name
+	}
+	errs := d.config.Pool.Each(ctx, keys, func(i int) error {
+		readings[i] = d.read(ctx, d.devices[i])
+		return nil
+	})
+
+	report := &Report{ScannedAt: time.Now().UTC(), Errors: make(map[string]error)}
+	for i, err := range errs {
+		if err == nil {
+			err = readings[i].err
+		}
+		if err != nil {
+			report.Errors[d.devices[i].Name] = err
+		}
+	}
+	report.Findings = analyze(d.devices, readings, report.ScannedAt)
+	d.update(report)
+	return report
+}
+
+// read reads the provisioned ONUs, the autofind list and the OLT's own
+// rogue detection of one device, whichever the adapter supports
+func (d *Detector) read(ctx context.Context, config *types.EquipmentConfig) reading {
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	var r reading
+	fail := func(what string, err error) {
+		config.Log().Warn("rogue ONU scan read failed", "what", what, "error", err)
+		r.err = errors.Join(r.err, fmt.Errorf("failed to read %s of %s: %w", what, config.Name, err))
+	}
+
+	driver, err := d.factory(config)
+	if err != nil {
+		fail("driver", err)
+		return r
+	}
+	if !driver.IsConnected() {
+		if err := driver.Connect(ctx, config); err != nil {
+			fail("connection", err)
+			return r
+		}
+		defer driver.Disconnect(context.WithoutCancel(ctx)) //nolint:errcheck // best effort
+	}
+
+	if l, ok := driver.(onuLister); ok {
+		if r.onus, err = l.GetONUList(ctx, nil); err != nil {
+			fail("ONU list", err)
+		}
+	}
+	if disc, ok := driver.(onuDiscoverer); ok {
+		if r.discovered, err = disc.DiscoverONUs(ctx, nil); err != nil {
+			fail("autofind", err)
+		}
+	}
+	if det, ok := driver.(types.RogueONUDetector); ok {
+		if r.rogue, err = det.GetRogueONUs(ctx); err != nil {
+			fail("rogue ONUs", err)
+		}
+	}
+	return r
+}
+
+// location is where a serial is provisioned
+type location struct {
+	device  string
+	ponPort string
+	onuID   int
+}
+
+func (l location) String() string {
+	return l.device + ":" + l.ponPort
+}
+
+// analyze turns the readings of every device into findings
+func analyze(devices []*types.EquipmentConfig, readings []reading, now time.Time) []Finding {
+	var findings []Finding
+	provisioned := make(map[string][]location) // by normalized serial
+	for i, r := range readings {
+		name := devices[i].Name
+		for _, rogue := range r.rogue {
+			findings = append(findings, Finding{Device: name, RogueONU: rogue})
+		}
+		for _, onu := range r.onus {
+			if onu.Serial == "" {
+				continue
+			}
+			s := common.SerialKey(onu.Serial)
+			provisioned[s] = append(provisioned[s], location{device: name, ponPort: onu.PONPort, onuID: onu.ONUID})
+		}
+	}
+
+	for s, locs := range provisioned {
+		if len(locs) < 2 {
+			continue
+		}
+		for i, loc := range locs {
+			findings = append(findings, Finding{
+				Device: loc.device,
+				RogueONU: types.RogueONU{
+					Kind:       types.RogueDuplicateSerial,
+					PONPort:    loc.ponPort,
+					ONUID:      loc.onuID,
+					Serial:     s,
+					Detail:     fmt.Sprintf("provisioned on %d PON ports", len(locs)),
+					DetectedAt: now,
+				},
+				Elsewhere: others(locs, i),
+			})
+		}
+	}
+
+	for i, r := range readings {
+		name := devices[i].Name
+		for _, disc := range r.discovered {
+			s := common.SerialKey(disc.Serial)
+			var elsewhere []string
+			for _, loc := range provisioned[s] {
+				if loc.device != name || loc.ponPort != disc.PONPort {
+					elsewhere = append(elsewhere, loc.String())
+				}
+			}
+			if len(elsewhere) == 0 {
+				continue
+			}
+			findings = append(findings, Finding{
+				Device: name,
+				RogueONU: types.RogueONU{
+					Kind:       types.RogueProvisionedElsewhere,
+					PONPort:    disc.PONPort,
+					ONUID:      -1,
+					Serial:     s,
+					Detail:     "in autofind while provisioned elsewhere",
+					DetectedAt: now,
+				},
+				Elsewhere: elsewhere,
+			})
+		}
+	}
+	sortFindings(findings)
+	return findings
+}
+
+// others returns the locations other than locs[skip]
+func others(locs []location, skip int) []string {
+	var out []string
+	for i, loc := range locs {
+		if i != skip {
+			out = append(out, loc.String())
+		}
+	}
+	return out
+}
+
+// update replaces the active findings with those of report and publishes
+// the difference. Findings of devices that failed to read are kept.
+func (d *Detector) update(report *Report) {
+	current := make(map[string]Finding, len(report.Findings))
+	for _, f := range report.Findings {
+		current[f.key()] = f
+	}
+
+	d.mu.Lock()
+	var detected, cleared []Finding
+	for k, f := range current {
+		if _, ok := d.active[k]; !ok {
+			detected = append(detected, f)
+		}
+	}
+	for k, f := range d.active {
+		if _, ok := current[k]; ok {
+			continue
+		}
+		if _, failed := report.Errors[f.Device]; failed {
+			current[k] = f
+			continue
+		}
+		cleared = append(cleared, f)
+	}
+	d.active = current
+	d.mu.Unlock()
+
+	sortFindings(detected)
+	sortFindings(cleared)
+	for _, f := range detected {
+		d.publish(events.TypeRogueONUDetected, f, report.ScannedAt)
+	}
+	for _, f := range cleared {
+		d.publish(events.TypeRogueONUCleared, f, report.ScannedAt)
+	}
+}
+
+func (d *Detector) publish(typ events.Type, f Finding, at time.Time) {
+	if d.publisher == nil {
+		return
+	}
+	data := map[string]any{
+		"kind":     string(f.Kind),
+		"pon_port": f.PONPort,
+		"onu_id":   f.ONUID,
+		"serial":   f.Serial,
+		"detail":   f.Detail,
+	}
+	if len(f.Elsewhere) > 0 {
+		data["elsewhere"] = f.Elsewhere
+	}
+	d.publisher.Publish(events.Event{Type: typ, Device: f.Device, Time: at, Data: data})
+}
+
+func sortFindings(findings []Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Device != b.Device {
+			return a.Device < b.Device
+		}
+		if a.PONPort != b.PONPort {
+			return a.PONPort < b.PONPort
+		}
+		if a.Serial != b.Serial {
+			return a.Serial < b.Serial
+		}
+		return a.Kind < b.Kind
+	})
+}
@@ -0,0 +1,193 @@
+package rogue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/events"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// detectingOLT is a MockDriverV2 whose OLT detects rogue ONUs itself
+type detectingOLT struct {
+	*testutil.MockDriverV2
+	rogue []types.RogueONU
+}
+
+func (d *detectingOLT) GetRogueONUs(context.Context) ([]types.RogueONU, error) {
+	return d.rogue, nil
+}
+
+func factoryFor(drivers map[string]types.Driver) types.DriverFactory {
+	return func(c *types.EquipmentConfig) (types.Driver, error) {
+		d, ok := drivers[c.Name]
+		if !ok {
+			return nil, errors.New("no driver")
+		}
+		return d, nil
+	}
+}
+
+type recorder struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (r *recorder) Publish(e events.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) take() []events.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.events
+	r.events = nil
+	return e
+}
+
+func TestScan(t *testing.T) {
+	ctx := context.Background()
+	olt1 := &detectingOLT{
+		MockDriverV2: &testutil.MockDriverV2{
+			ONUs: []types.ONUInfo{
+				{PONPort: "0/1/0", ONUID: 1, Serial: "HWTC00000001"},
+				{PONPort: "0/1/1", ONUID: 1, Serial: "HWTC00000002"},
+			},
+		},
+		rogue: []types.RogueONU{{Kind: types.RogueTransmitting, PONPort: "0/1/3", ONUID: -1, Detail: "continuous-mode"}},
+	}
+	olt2 := &testutil.MockDriverV2{
+		ONUs: []types.ONUInfo{
+			{PONPort: "0/1", ONUID: 4, Serial: "hwtc00000001"},
+			{PONPort: "0/2", ONUID: 1, Serial: "VSOL00000003"},
+		},
+		Discovered: []types.ONUDiscovery{
+			{PONPort: "0/3", Serial: "HWTC00000002"},
+			{PONPort: "0/3", Serial: "VSOL00000009"},
+		},
+	}
+	devices := []*types.EquipmentConfig{{Name: "olt-1"}, {Name: "olt-2"}}
+	rec := &recorder{}
+	d := New(devices, factoryFor(map[string]types.Driver{"olt-1": olt1, "olt-2": olt2}), rec, Config{})
+
+	report := d.Scan(ctx)
+	if len(report.Errors) != 0 {
+		t.Fatalf("errors = %v", report.Errors)
+	}
+	want := []struct {
+		device, port, serial string
+		kind                 types.RogueKind
+		elsewhere            string
+	}{
+		{"olt-1", "0/1/0", "HWTC00000001", types.RogueDuplicateSerial, "olt-2:0/1"},
+		{"olt-1", "0/1/3", "", types.RogueTransmitting, ""},
+		{"olt-2", "0/1", "HWTC00000001", types.RogueDuplicateSerial, "olt-1:0/1/0"},
+		{"olt-2", "0/3", "HWTC00000002", types.RogueProvisionedElsewhere, "olt-1:0/1/1"},
+	}
+	if len(report.Findings) != len(want) {
+		t.Fatalf("findings = %+v", report.Findings)
+	}
+	for i, w := range want {
+		f := report.Findings[i]
+		elsewhere := ""
+		if len(f.Elsewhere) > 0 {
+			elsewhere = f.Elsewhere[0]
+		}
+		if f.Device != w.device || f.PONPort != w.port || f.Serial != w.serial || f.Kind != w.kind || elsewhere != w.elsewhere {
+			t.Errorf("finding %d = %+v, want %+v", i, f, w)
+		}
+	}
+	if got := rec.take(); len(got) != 4 || got[0].Type != events.TypeRogueONUDetected {
+		t.Errorf("events = %+v", got)
+	}
+	if olt2.IsConnected() {
+		t.Error("driver left connected after scan")
+	}
+
+	// Unchanged findings are not published again
+	d.Scan(ctx)
+	if got := rec.take(); len(got) != 0 {
+		t.Errorf("repeated findings were published: %+v", got)
+	}
+
+	// The duplicate is removed from olt-2; both of its findings clear
+	olt2.ONUs = olt2.ONUs[1:]
+	d.Scan(ctx)
+	got := rec.take()
+	if len(got) != 2 || got[0].Type != events.TypeRogueONUCleared || got[1].Type != events.TypeRogueONUCleared {
+		t.Errorf("events = %+v", got)
+	}
+	if n := len(d.Active()); n != 2 {
+		t.Errorf("%d active findings, want 2", n)
+	}
+}
+
+func TestAnalyzeSerialForms(t *testing.T) {
+	devices := []*types.EquipmentConfig{{Name: "olt-1"}, {Name: "olt-2"}}
+	readings := []reading{
+		{
+			onus: []types.ONUInfo{
+				{PONPort: "0/1/0", ONUID: 1, Serial: "4857544300000001"},
+				{PONPort: "0/1/1", ONUID: 1, Serial: "hwtc-00000002"},
+			},
+		},
+		{
+			onus: []types.ONUInfo{{PONPort: "0/1", ONUID: 4, Serial: "HWTC-00000001"}},
+			discovered: []types.ONUDiscovery{
+				{PONPort: "0/3", Serial: "48:57:54:43:00:00:00:02"},
+			},
+		},
+	}
+
+	findings := analyze(devices, readings, time.Now())
+	want := []struct {
+		device, port string
+		kind         types.RogueKind
+	}{
+		{"olt-1", "0/1/0", types.RogueDuplicateSerial},
+		{"olt-2", "0/1", types.RogueDuplicateSerial},
+		{"olt-2", "0/3", types.RogueProvisionedElsewhere},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("findings = %+v", findings)
+	}
+	for i, w := range want {
+		f := findings[i]
+		if f.Device != w.device || f.PONPort != w.port || f.Kind != w.kind {
+			t.Errorf("finding %d = %+v, want %+v", i, f, w)
+		}
+	}
+	if findings[0].Serial != "HWTC00000001" || findings[2].Serial != "HWTC00000002" {
+		t.Errorf("serials = %q, %q, want canonical form", findings[0].Serial, findings[2].Serial)
+	}
+}
+
+func TestScanKeepsFindingsOfFailedDevices(t *testing.T) {
+	ctx := context.Background()
+	olt1 := &detectingOLT{
+		MockDriverV2: &testutil.MockDriverV2{},
+		rogue:        []types.RogueONU{{Kind: types.RogueTransmitting, PONPort: "0/1/3", ONUID: 7, Serial: "HWTC00000007", DetectedAt: time.Now()}},
+	}
+	rec := &recorder{}
+	d := New([]*types.EquipmentConfig{{Name: "olt-1"}}, factoryFor(map[string]types.Driver{"olt-1": olt1}), rec, Config{})
+	d.Scan(ctx)
+	rec.take()
+
+	olt1.Faults = map[string]*testutil.Fault{"Connect": {Err: errors.New("unreachable")}}
+	report := d.Scan(ctx)
+	if report.Errors["olt-1"] == nil {
+		t.Fatal("connect failure not reported")
+	}
+	if got := rec.take(); len(got) != 0 {
+		t.Errorf("finding cleared while the device could not be read: %+v", got)
+	}
+	if n := len(d.Active()); n != 1 {
+		t.Errorf("%d active findings, want 1", n)
+	}
+}
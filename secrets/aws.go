@@ -0,0 +1,185 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Defaults applied by NewAWS for zero AWSConfig fields
+const (
+	DefaultAWSSecretID = "nano/devices/{name}"
+	DefaultAWSTimeout  = 10 * time.Second
+)
+
+// AWSConfig configures an AWS Secrets Manager provider
+type AWSConfig struct {
+	// Region defaults to $AWS_REGION, then $AWS_DEFAULT_REGION
+	Region string
+
+	// AccessKeyID, SecretAccessKey and SessionToken default to
+	// $AWS_ACCESS_KEY_ID, $AWS_SECRET_ACCESS_KEY and $AWS_SESSION_TOKEN
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// SecretID is the name or ARN of each device's secret, where {name} and
+	// {address} are replaced with those of the device. Defaults to
+	// DefaultAWSSecretID. The secret string is JSON with "username",
+	// "password" and "snmp_community" keys.
+	SecretID string
+
+	// Endpoint overrides the regional endpoint, e.g. for VPC endpoints
+	Endpoint string
+
+	// Client defaults to an http.Client with DefaultAWSTimeout
+	Client *http.Client
+}
+
+// AWS reads credentials from AWS Secrets Manager
+type AWS struct {
+	config AWSConfig
+	now    func() time.Time
+}
+
+var _ types.CredentialsProvider = (*AWS)(nil)
+
+// NewAWS creates an AWS Secrets Manager provider
+func NewAWS(config AWSConfig) *AWS {
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_REGION")
+	}
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if config.AccessKeyID == "" {
+		config.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if config.SecretID == "" {
+		config.SecretID = DefaultAWSSecretID
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://secretsmanager." + config.Region + ".amazonaws.com"
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: DefaultAWSTimeout}
+	}
+	return &AWS{config: config, now: time.Now}
+}
+
+// Credentials implements types.CredentialsProvider
+func (a *AWS) Credentials(ctx context.Context, config *types.EquipmentConfig) (*types.Credentials, error) {
+	if a.config.Region == "" || a.config.AccessKeyID == "" {
+		return nil, fmt.Errorf("aws region and credentials are not set")
+	}
+	id := expand(a.config.SecretID, config)
+	payload, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode secret request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.Endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	a.sign(req, payload)
+
+	resp, err := a.config.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %w", id, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %w", id, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		json.Unmarshal(body, &e) //nolint:errcheck // best effort
+		return nil, fmt.Errorf("failed to get secret %s: %s %s %s", id, resp.Status, e.Type, e.Message)
+	}
+
+	var secret struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("failed to decode secret %s: %w", id, err)
+	}
+	if secret.SecretString == "" {
+		return nil, fmt.Errorf("secret %s has no secret string", id)
+	}
+	return parseSecret([]byte(secret.SecretString))
+}
+
+// sign adds an AWS Signature Version 4 to req
+func (a *AWS) sign(req *http.Request, payload []byte) {
+	now := a.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.config.SessionToken)
+	}
+
+	// Canonical headers are sorted by name
+	signedHeaders := "content-type;host;x-amz-date"
+	canonicalHeaders := "content-type:" + req.Header.Get("Content-Type") + "\n" +
+		"host:" + req.URL.Host + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	if a.config.SessionToken != "" {
+		signedHeaders += ";x-amz-security-token"
+		canonicalHeaders += "x-amz-security-token:" + a.config.SessionToken + "\n"
+	}
+	signedHeaders += ";x-amz-target"
+	canonicalHeaders += "x-amz-target:" + req.Header.Get("X-Amz-Target") + "\n"
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := req.Method + "\n" + path + "\n" + req.URL.Query().Encode() + "\n" +
+		canonicalHeaders + "\n" + signedHeaders + "\n" + payloadHash
+
+	scope := date + "/" + a.config.Region + "/secretsmanager/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	signature := hex.EncodeToString(hmacSHA256(signingKey(a.config.SecretAccessKey, date, a.config.Region, "secretsmanager"), stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+a.config.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// signingKey derives the SigV4 signing key
+func signingKey(secret, date, region, service string) []byte {
+	k := hmacSHA256([]byte("AWS4"+secret), date)
+	k = hmacSHA256(k, region)
+	k = hmacSHA256(k, service)
+	return hmacSHA256(k, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
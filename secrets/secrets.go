@@ -0,0 +1,95 @@
+// Package secrets provides types.CredentialsProvider implementations that
+// keep device passwords and SNMP communities out of EquipmentConfig:
+// environment variables, HashiCorp Vault and AWS Secrets Manager.
+//
+// Set a provider as EquipmentConfig.Credentials; drivers resolve it on
+// every Connect, so rotated secrets are picked up on reconnect.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// DefaultEnvPrefix is the Env prefix used when it is empty
+const DefaultEnvPrefix = "NANO_"
+
+// Static returns fixed credentials
+type Static types.Credentials
+
+var _ types.CredentialsProvider = Static{}
+
+// Credentials implements types.CredentialsProvider
+func (s Static) Credentials(context.Context, *types.EquipmentConfig) (*types.Credentials, error) {
+	c := types.Credentials(s)
+	return &c, nil
+}
+
+// Env reads credentials from environment variables. For a device named
+// "olt-1" and the default prefix it reads NANO_OLT_1_USERNAME,
+// NANO_OLT_1_PASSWORD and NANO_OLT_1_SNMP_COMMUNITY, falling back to
+// NANO_USERNAME, NANO_PASSWORD and NANO_SNMP_COMMUNITY.
+type Env struct {
+	// Prefix of every variable. Defaults to DefaultEnvPrefix.
+	Prefix string
+}
+
+var _ types.CredentialsProvider = Env{}
+
+// Credentials implements types.CredentialsProvider
+func (e Env) Credentials(_ context.Context, config *types.EquipmentConfig) (*types.Credentials, error) {
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	device := envName(config.Name)
+	lookup := func(name string) string {
+		if device != "" {
+			if v, ok := os.LookupEnv(prefix + device + "_" + name); ok {
+				return v
+			}
+		}
+		return os.Getenv(prefix + name)
+	}
+	return &types.Credentials{
+		Username:      lookup("USERNAME"),
+		Password:      lookup("PASSWORD"),
+		SNMPCommunity: lookup("SNMP_COMMUNITY"),
+	}, nil
+}
+
+// envName upper-cases name and replaces characters not allowed in
+// environment variable names with underscores
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// expand replaces {name} and {address} in a secret path with those of the
+// device
+func expand(path string, config *types.EquipmentConfig) string {
+	return strings.NewReplacer("{name}", config.Name, "{address}", config.Address).Replace(path)
+}
+
+// parseSecret decodes a JSON secret with "username", "password" and
+// "snmp_community" keys
+func parseSecret(data []byte) (*types.Credentials, error) {
+	var c types.Credentials
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to decode secret: %w", err)
+	}
+	return &c, nil
+}
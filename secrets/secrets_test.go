@@ -0,0 +1,136 @@
+package secrets
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var device = &types.EquipmentConfig{Name: "olt-1", Address: "10.0.0.1"}
+
+func TestStatic(t *testing.T) {
+	c, err := Static{Username: "admin", Password: "pw"}.Credentials(context.Background(), device)
+	if err != nil || c.Username != "admin" || c.Password != "pw" {
+		t.Errorf("Credentials() = %+v, %v", c, err)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("NANO_USERNAME", "shared")
+	t.Setenv("NANO_PASSWORD", "shared-pw")
+	t.Setenv("NANO_OLT_1_PASSWORD", "device-pw")
+	t.Setenv("TEST_SNMP_COMMUNITY", "private")
+
+	c, err := Env{}.Credentials(context.Background(), device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (types.Credentials{Username: "shared", Password: "device-pw"}); *c != want {
+		t.Errorf("Credentials() = %+v, want %+v", *c, want)
+	}
+
+	c, _ = Env{Prefix: "TEST_"}.Credentials(context.Background(), device)
+	if want := (types.Credentials{SNMPCommunity: "private"}); *c != want {
+		t.Errorf("Credentials() with prefix = %+v, want %+v", *c, want)
+	}
+}
+
+func TestVault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, `{"errors":["permission denied"]}`) //nolint:errcheck // test server
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/olts/olt-1" || r.Header.Get("X-Vault-Namespace") != "isp" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"errors":[]}`) //nolint:errcheck // test server
+			return
+		}
+		io.WriteString(w, `{"data":{"data":{"username":"admin","password":"s3cret","snmp_community":"ro"},"metadata":{"version":3}}}`) //nolint:errcheck // test server
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	v := NewVault(VaultConfig{Address: srv.URL, Token: "token", Namespace: "isp", Mount: "kv", Path: "olts/{name}"})
+	c, err := v.Credentials(ctx, device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (types.Credentials{Username: "admin", Password: "s3cret", SNMPCommunity: "ro"}); *c != want {
+		t.Errorf("Credentials() = %+v, want %+v", *c, want)
+	}
+
+	v = NewVault(VaultConfig{Address: srv.URL, Token: "wrong"})
+	if _, err := v.Credentials(ctx, device); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Credentials() with a bad token = %v", err)
+	}
+	v = NewVault(VaultConfig{Address: srv.URL, Token: "token", Namespace: "isp"})
+	if _, err := v.Credentials(ctx, device); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Credentials() of a missing secret = %v", err)
+	}
+}
+
+func TestAWS(t *testing.T) {
+	var auth, target, secretID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, target = r.Header.Get("Authorization"), r.Header.Get("X-Amz-Target")
+		var body struct{ SecretId string }
+		json.NewDecoder(r.Body).Decode(&body) //nolint:errcheck // checked below
+		secretID = body.SecretId
+		if secretID != "prod/olt-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`) //nolint:errcheck // test server
+			return
+		}
+		io.WriteString(w, `{"Name":"prod/olt-1","SecretString":"{\"username\":\"admin\",\"password\":\"pw\"}"}`) //nolint:errcheck // test server
+	}))
+	defer srv.Close()
+
+	a := NewAWS(AWSConfig{
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		SecretID:        "prod/{name}",
+		Endpoint:        srv.URL,
+	})
+	a.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	c, err := a.Credentials(context.Background(), device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (types.Credentials{Username: "admin", Password: "pw"}); *c != want {
+		t.Errorf("Credentials() = %+v, want %+v", *c, want)
+	}
+	if target != "secretsmanager.GetSecretValue" {
+		t.Errorf("X-Amz-Target = %q", target)
+	}
+	wantAuth := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240301/eu-west-1/secretsmanager/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature="
+	if !strings.HasPrefix(auth, wantAuth) || len(auth) != len(wantAuth)+64 {
+		t.Errorf("Authorization = %q", auth)
+	}
+
+	a.config.SecretID = "missing"
+	if _, err := a.Credentials(context.Background(), device); err == nil || !strings.Contains(err.Error(), "ResourceNotFoundException") {
+		t.Errorf("Credentials() of a missing secret = %v", err)
+	}
+}
+
+func TestSigningKey(t *testing.T) {
+	// Example from the AWS Signature Version 4 documentation
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	if got := hex.EncodeToString(key); got != "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d" {
+		t.Errorf("signingKey() = %s", got)
+	}
+}
@@ -0,0 +1,138 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Defaults applied by NewVault for zero VaultConfig fields
+const (
+	DefaultVaultMount   = "secret"
+	DefaultVaultPath    = "nano/devices/{name}"
+	DefaultVaultTimeout = 10 * time.Second
+)
+
+// VaultConfig configures a Vault provider
+type VaultConfig struct {
+	// Address of the Vault server. Defaults to $VAULT_ADDR.
+	Address string
+
+	// Token authenticates requests. Defaults to $VAULT_TOKEN.
+	Token string
+
+	// Namespace is the Vault Enterprise namespace, if any
+	Namespace string
+
+	// Mount is the KV version 2 secrets engine mount. Defaults to
+	// DefaultVaultMount.
+	Mount string
+
+	// Path of each device's secret within the mount, where {name} and
+	// {address} are replaced with those of the device. Defaults to
+	// DefaultVaultPath. The secret holds "username", "password" and
+	// "snmp_community" keys.
+	Path string
+
+	// Client defaults to an http.Client with DefaultVaultTimeout
+	Client *http.Client
+}
+
+// Vault reads credentials from a HashiCorp Vault KV version 2 engine
+type Vault struct {
+	config VaultConfig
+}
+
+var _ types.CredentialsProvider = (*Vault)(nil)
+
+// NewVault creates a Vault provider
+func NewVault(config VaultConfig) *Vault {
+	if config.Address == "" {
+		config.Address = os.Getenv("VAULT_ADDR")
+	}
+	if config.Token == "" {
+		config.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if config.Mount == "" {
+		config.Mount = DefaultVaultMount
+	}
+	if config.Path == "" {
+		config.Path = DefaultVaultPath
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: DefaultVaultTimeout}
+	}
+	return &Vault{config: config}
+}
+
+// Credentials implements types.CredentialsProvider
+func (v *Vault) Credentials(ctx context.Context, config *types.EquipmentConfig) (*types.Credentials, error) {
+	if v.config.Address == "" {
+		return nil, fmt.Errorf("vault address is not set")
+	}
+	path := strings.Trim(expand(v.config.Path, config), "/")
+	u := strings.TrimRight(v.config.Address, "/") + "/v1/" +
+		url.PathEscape(strings.Trim(v.config.Mount, "/")) + "/data/" + escapePath(path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.config.Token)
+	if v.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.config.Namespace)
+	}
+	resp, err := v.config.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %s: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read vault secret %s: %s", path, vaultError(resp.Status, body))
+	}
+
+	var secret struct {
+		Data struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("failed to decode vault secret %s: %w", path, err)
+	}
+	if len(secret.Data.Data) == 0 || string(secret.Data.Data) == "null" {
+		return nil, fmt.Errorf("vault secret %s has no data", path)
+	}
+	return parseSecret(secret.Data.Data)
+}
+
+// vaultError returns the errors of a Vault error response, or its status
+func vaultError(status string, body []byte) string {
+	var e struct {
+		Errors []string `json:"errors"`
+	}
+	if json.Unmarshal(body, &e) == nil && len(e.Errors) > 0 {
+		return status + ": " + strings.Join(e.Errors, "; ")
+	}
+	return status
+}
+
+// escapePath escapes each element of a "/"-separated path
+func escapePath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
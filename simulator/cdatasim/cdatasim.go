@@ -0,0 +1,457 @@
+// Package cdatasim simulates a C-Data FD1104S/FD1208S series GPON OLT.
+// ONU configuration goes to a candidate configuration that only takes
+// effect on "commit", so a commit that fails silently leaves the running
+// configuration unchanged while every command appears to succeed.
+package cdatasim
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/simulator"
+)
+
+// Defaults applied by New for zero Config fields
+const (
+	DefaultHostname = "OLT"
+	DefaultUsername = "admin"
+	DefaultPassword = "admin"
+	DefaultPorts    = 4
+)
+
+// DefaultQuirks are the firmware quirks simulated when Config.Quirks is
+// nil: "--More--" pages of 24 lines. Add "commit" to SilentFailures to
+// simulate commits that are acknowledged but not applied.
+var DefaultQuirks = simulator.Quirks{PageLines: 24}
+
+// Config configures a simulated OLT
+type Config struct {
+	Hostname string
+	Username string
+	Password string
+
+	// Ports is the number of PON ports, named 1/1/1 to 1/1/Ports
+	Ports int
+
+	// Quirks defaults to DefaultQuirks
+	Quirks *simulator.Quirks
+}
+
+// OLT is a simulated C-Data OLT. It has no SNMP agent: the C-Data adapter
+// manages the OLT over the CLI only.
+type OLT struct {
+	// ONUs is the running ONU state. Add ONUs to the autofind list with
+	// Discover.
+	ONUs *simulator.Inventory
+
+	config  Config
+	quirks  simulator.Quirks
+	started time.Time
+	cli     *simulator.CLIServer
+
+	mu       sync.Mutex
+	shutdown map[string]bool
+}
+
+// New creates a simulated OLT. Call Start to serve it.
+func New(config Config) (*OLT, error) {
+	if config.Hostname == "" {
+		config.Hostname = DefaultHostname
+	}
+	if config.Username == "" {
+		config.Username = DefaultUsername
+	}
+	if config.Password == "" {
+		config.Password = DefaultPassword
+	}
+	if config.Ports <= 0 {
+		config.Ports = DefaultPorts
+	}
+	quirks := DefaultQuirks
+	if config.Quirks != nil {
+		quirks = *config.Quirks
+	}
+
+	o := &OLT{ONUs: simulator.NewInventory(), config: config, quirks: quirks, started: time.Now(), shutdown: map[string]bool{}}
+	cli, err := simulator.NewCLIServer(simulator.CLIConfig{
+		Username:  config.Username,
+		Password:  config.Password,
+		PageLines: quirks.PageLines,
+		PagerOff:  []string{"terminal length 0"},
+		NewShell:  func() simulator.Shell { return &shell{olt: o, mode: modeEnable} },
+	})
+	if err != nil {
+		return nil, err
+	}
+	o.cli = cli
+	return o, nil
+}
+
+// Start serves the CLI over SSH on addr
+func (o *OLT) Start(addr string) error {
+	return o.cli.Start(addr)
+}
+
+// SSHAddr returns the address of the CLI, or nil before Start
+func (o *OLT) SSHAddr() net.Addr { return o.cli.Addr() }
+
+// Close stops the simulator
+func (o *OLT) Close() error {
+	return o.cli.Close()
+}
+
+// PortShutdown reports whether a PON port is shut down
+func (o *OLT) PortShutdown(port string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.shutdown[port]
+}
+
+// validPort reports whether port is one of the OLT's PON ports
+func (o *OLT) validPort(port string) bool {
+	n, ok := strings.CutPrefix(port, "1/1/")
+	if !ok {
+		return false
+	}
+	i, err := strconv.Atoi(n)
+	return err == nil && i >= 1 && i <= o.config.Ports
+}
+
+// shell modes
+const (
+	modeEnable = iota
+	modeConfig
+	modeInterface
+)
+
+// shell is one CLI session. ONU changes are collected in candidate until
+// commit.
+type shell struct {
+	olt       *OLT
+	mode      int
+	port      string
+	candidate []change
+}
+
+// change is an uncommitted ONU change
+type change func(inv *simulator.Inventory) error
+
+func (s *shell) Prompt() string {
+	host := s.olt.config.Hostname
+	switch s.mode {
+	case modeEnable:
+		return host + "# "
+	case modeConfig:
+		return host + "(config)# "
+	default:
+		return host + "(config-if-gpon-olt_" + s.port + ")# "
+	}
+}
+
+func (s *shell) Exec(line string) (string, bool) {
+	f := strings.Fields(line)
+	switch {
+	case line == "exit" || line == "quit":
+		switch s.mode {
+		case modeInterface:
+			s.mode = modeConfig
+		case modeConfig:
+			s.mode = modeEnable
+		default:
+			return "", true
+		}
+		return "", false
+	case line == "end":
+		s.mode = modeEnable
+		return "", false
+	case line == "enable":
+		return "", false
+	case line == "configure terminal":
+		s.mode = modeConfig
+		return "", false
+	case line == "commit":
+		if s.mode == modeEnable {
+			return unknown(), false
+		}
+		return s.commit(line), false
+	case line == "show system":
+		return s.olt.showSystem(), false
+	case line == "show gpon onu autofind":
+		return s.olt.showAutofind(), false
+	case len(f) == 5 && f[0] == "show" && f[1] == "gpon" && f[2] == "onu-info" && f[3] == "by-sn":
+		return s.olt.showONUBySN(f[4]), false
+	case len(f) == 5 && f[0] == "show" && f[1] == "gpon" && (f[2] == "onu-info" || f[2] == "onu-statistics" || f[2] == "onu-optical-info"):
+		return s.olt.showONU(f[2], f[3], f[4]), false
+	case line == "show gpon onu-list":
+		return s.olt.showONUList(), false
+	case len(f) == 4 && f[0] == "show" && f[1] == "gpon" && f[2] == "olt-optical-info":
+		return s.olt.showOLTOptical(f[3]), false
+	case line == "show gpon port-state":
+		return s.olt.showPortState(), false
+	case line == "show alarm active":
+		return s.olt.quirks.Color(fmt.Sprintf("%-7s%-10s%-12s%-18s%-21s%s", "Index", "Severity", "Alarm-Name", "Source", "Time", "Description")), false
+	case len(f) == 2 && f[0] == "interface" && strings.HasPrefix(f[1], "gpon-olt_"):
+		if s.mode == modeEnable {
+			return unknown(), false
+		}
+		port := strings.TrimPrefix(f[1], "gpon-olt_")
+		if !s.olt.validPort(port) {
+			return "% Invalid interface " + f[1], false
+		}
+		s.mode, s.port = modeInterface, port
+		return "", false
+	}
+	if s.mode != modeInterface {
+		return unknown(), false
+	}
+	return s.execInterface(f), false
+}
+
+// execInterface adds an ONU or port command of the interface mode to the
+// candidate configuration
+func (s *shell) execInterface(f []string) string {
+	if len(f) == 0 {
+		return unknown()
+	}
+	no := f[0] == "no"
+	if no {
+		f = f[1:]
+	}
+	if len(f) == 1 && f[0] == "shutdown" {
+		port := s.port
+		s.candidate = append(s.candidate, func(*simulator.Inventory) error {
+			s.olt.mu.Lock()
+			defer s.olt.mu.Unlock()
+			s.olt.shutdown[port] = !no
+			return nil
+		})
+		return ""
+	}
+	if len(f) < 2 || !strings.HasPrefix(f[0], "onu-") {
+		return unknown()
+	}
+	id, err := strconv.Atoi(f[1])
+	if err != nil || id < 1 || id > 128 {
+		return "% Invalid ONU ID " + f[1]
+	}
+	port := s.port
+
+	var c change
+	switch {
+	case no && f[0] == "onu-set":
+		c = func(inv *simulator.Inventory) error {
+			if !inv.Delete(port, id) {
+				return fmt.Errorf("onu %d not found", id)
+			}
+			return nil
+		}
+	case f[0] == "onu-set":
+		// onu-set <id> type <type> sn <serial>
+		serial := valueAfter(f, "sn")
+		if serial == "" {
+			return "% Incomplete command"
+		}
+		if _, ok := s.olt.ONUs.Get(port, id); ok {
+			return fmt.Sprintf("%% ONU %d already exist", id)
+		}
+		c = func(inv *simulator.Inventory) error {
+			return inv.Add(simulator.ONU{Port: port, ID: id, Serial: serial, Disabled: true})
+		}
+	case f[0] == "onu-profile":
+		profile := valueAfter(f, "line")
+		c = update(port, id, func(onu *simulator.ONU) { onu.Profile = profile })
+	case f[0] == "onu-vlan":
+		vlan, _ := strconv.Atoi(valueAfter(f, "svlan"))
+		c = update(port, id, func(onu *simulator.ONU) { onu.VLAN = vlan })
+	case f[0] == "onu-ratelimit":
+		c = update(port, id, func(onu *simulator.ONU) {})
+	case f[0] == "onu-activate":
+		c = update(port, id, func(onu *simulator.ONU) { onu.Disabled = false })
+	case f[0] == "onu-deactivate":
+		c = update(port, id, func(onu *simulator.ONU) { onu.Disabled = true })
+	default:
+		return unknown()
+	}
+	s.candidate = append(s.candidate, c)
+	return ""
+}
+
+// update returns a change applying fn to an ONU
+func update(port string, id int, fn func(*simulator.ONU)) change {
+	return func(inv *simulator.Inventory) error {
+		if !inv.Update(port, id, fn) {
+			return fmt.Errorf("onu %d not found", id)
+		}
+		return nil
+	}
+}
+
+// commit applies the candidate configuration. With the silent commit
+// quirk the candidate is discarded and the commit still reports success.
+func (s *shell) commit(line string) string {
+	candidate := s.candidate
+	s.candidate = nil
+	if s.olt.quirks.Silent(line) {
+		return "Commit complete."
+	}
+	for _, c := range candidate {
+		if err := c(s.olt.ONUs); err != nil {
+			return "% Commit failed: " + err.Error()
+		}
+	}
+	return "Commit complete."
+}
+
+func (o *OLT) showSystem() string {
+	uptime := time.Since(o.started).Round(time.Second)
+	return fmt.Sprintf(`System Name       : %s
+System Type       : FD1104S
+Serial Number     : CDATSIM00001
+Software Version  : V1.2.5
+Uptime            : %s`, o.config.Hostname, uptime)
+}
+
+func (o *OLT) showAutofind() string {
+	var b strings.Builder
+	b.WriteString(o.quirks.Color(fmt.Sprintf("%-16s%-16s%-10s%s", "Interface", "SN", "Distance", "RxPower")) + "\n")
+	b.WriteString(strings.Repeat("-", 50) + "\n")
+	for _, onu := range o.ONUs.Autofind("") {
+		fmt.Fprintf(&b, "%-16s%-16s%-10d%.1f\n", "gpon-olt_"+onu.Port, onu.Serial, onu.DistanceM, onu.RxPowerDBm)
+	}
+	return b.String()
+}
+
+// showONUList formats "show gpon onu-list", the provisioned ONUs of every
+// port
+func (o *OLT) showONUList() string {
+	var b strings.Builder
+	b.WriteString(o.quirks.Color(fmt.Sprintf("%-16s%-8s%-14s%-13s%-14s%-6s%-9s%s", "Interface", "ONU-ID", "SN", "State", "Line-profile", "VLAN", "RxPower", "Distance")) + "\n")
+	b.WriteString(strings.Repeat("-", 88) + "\n")
+	for _, onu := range o.ONUs.List("") {
+		state, rx, distance := onuState(onu), "-", "-"
+		if state == "online" {
+			rx, distance = fmt.Sprintf("%.2f", onu.RxPowerDBm), strconv.Itoa(onu.DistanceM)
+		}
+		profile := onu.Profile
+		if profile == "" {
+			profile = "-"
+		}
+		fmt.Fprintf(&b, "%-16s%-8d%-14s%-13s%-14s%-6d%-9s%s\n", "gpon-olt_"+onu.Port, onu.ID, onu.Serial, state, profile, onu.VLAN, rx, distance)
+	}
+	return b.String()
+}
+
+// showOLTOptical formats "show gpon olt-optical-info <interface>"
+func (o *OLT) showOLTOptical(iface string) string {
+	port, ok := strings.CutPrefix(iface, "gpon-olt_")
+	if !ok || !o.validPort(port) {
+		return "% Invalid parameter"
+	}
+	return fmt.Sprintf("%-12s: %s\n%-12s: 4.50 dBm\n%-12s: -22.10 dBm\n%-12s: 38 C", "Interface", iface, "Tx power", "Rx power", "Temperature")
+}
+
+// showPortState formats "show gpon port-state"
+func (o *OLT) showPortState() string {
+	var b strings.Builder
+	b.WriteString(o.quirks.Color(fmt.Sprintf("%-16s%-9s%-6s%-6s%-5s%s", "Interface", "Admin", "Oper", "ONUs", "Max", "Description")) + "\n")
+	b.WriteString(strings.Repeat("-", 54) + "\n")
+	for i := 1; i <= o.config.Ports; i++ {
+		port := fmt.Sprintf("1/1/%d", i)
+		admin, oper := "enable", "up"
+		if o.PortShutdown(port) {
+			admin, oper = "disable", "down"
+		}
+		fmt.Fprintf(&b, "%-16s%-9s%-6s%-6d%-5d%s\n", "gpon-olt_"+port, admin, oper, len(o.ONUs.List(port)), 128, "-")
+	}
+	return b.String()
+}
+
+// onuState returns the state of onu as shown by the OLT
+func onuState(onu simulator.ONU) string {
+	switch {
+	case onu.Disabled:
+		return "deactivated"
+	case onu.Offline:
+		return "offline"
+	}
+	return "online"
+}
+
+// showONUBySN formats "show gpon onu-info by-sn <sn>", the onu-info of the
+// provisioned ONU with serial sn
+func (o *OLT) showONUBySN(sn string) string {
+	for _, onu := range o.ONUs.List("") {
+		if strings.EqualFold(onu.Serial, sn) {
+			return o.showONU("onu-info", "gpon-olt_"+onu.Port, strconv.Itoa(onu.ID))
+		}
+	}
+	return "% ONU not found"
+}
+
+// showONU formats "show gpon onu-info|onu-statistics|onu-optical-info
+// <interface> <id>"
+func (o *OLT) showONU(what, iface, id string) string {
+	port, ok := strings.CutPrefix(iface, "gpon-olt_")
+	n, err := strconv.Atoi(id)
+	if !ok || err != nil || !o.validPort(port) {
+		return "% Invalid parameter"
+	}
+	onu, ok := o.ONUs.Get(port, n)
+	if !ok {
+		return "% ONU not found"
+	}
+
+	var b strings.Builder
+	if what == "onu-statistics" {
+		fmt.Fprintf(&b, "%-14s: %d\n", "Rx bytes", onu.BytesDown)
+		fmt.Fprintf(&b, "%-14s: %d\n", "Tx bytes", onu.BytesUp)
+		fmt.Fprintf(&b, "%-14s: %d\n", "Rx packets", onu.BytesDown/1000)
+		fmt.Fprintf(&b, "%-14s: %d\n", "Tx packets", onu.BytesUp/1000)
+		fmt.Fprintf(&b, "%-14s: %d\n", "Errors", 0)
+		fmt.Fprintf(&b, "%-14s: %d", "Drops", 0)
+		return b.String()
+	}
+
+	state := onuState(onu)
+	if what == "onu-optical-info" {
+		if state != "online" {
+			return fmt.Sprintf("%% ONU %d is not online", onu.ID)
+		}
+		fmt.Fprintf(&b, "%-14s: %s\n", "SN", onu.Serial)
+		fmt.Fprintf(&b, "%-14s: %.2f dBm\n", "Rx power", onu.RxPowerDBm)
+		fmt.Fprintf(&b, "%-14s: %.2f dBm\n", "Tx power", onu.TxPowerDBm)
+		fmt.Fprintf(&b, "%-14s: %.2f dBm\n", "OLT Rx power", onu.RxPowerDBm-1.5)
+		fmt.Fprintf(&b, "%-14s: %d m", "Distance", onu.DistanceM)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%-14s: %s\n", "Interface", iface)
+	fmt.Fprintf(&b, "%-14s: %d\n", "ONU ID", onu.ID)
+	fmt.Fprintf(&b, "%-14s: %s\n", "SN", onu.Serial)
+	fmt.Fprintf(&b, "%-14s: %s\n", "Line profile", onu.Profile)
+	fmt.Fprintf(&b, "%-14s: %d\n", "VLAN", onu.VLAN)
+	fmt.Fprintf(&b, "%-14s: %s", "State", state)
+	if state == "online" {
+		fmt.Fprintf(&b, "\n%-14s: %.2f dBm\n", "Rx power", onu.RxPowerDBm)
+		fmt.Fprintf(&b, "%-14s: %.2f dBm\n", "Tx power", onu.TxPowerDBm)
+		fmt.Fprintf(&b, "%-14s: %d m", "Distance", onu.DistanceM)
+	}
+	return b.String()
+}
+
+// valueAfter returns the argument following key in args
+func valueAfter(args []string, key string) string {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == key {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func unknown() string {
+	return "% Unknown command."
+}
@@ -0,0 +1,177 @@
+package cdatasim
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/drivers/cli"
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/simulator"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/cdata"
+)
+
+func startOLT(t *testing.T, config Config) *OLT {
+	t.Helper()
+	olt, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := olt.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { olt.Close() })
+	return olt
+}
+
+// connect connects the C-Data adapter to olt
+func connect(t *testing.T, olt *OLT) types.Driver {
+	t.Helper()
+	config := &types.EquipmentConfig{
+		Name:     "sim",
+		Vendor:   types.VendorCData,
+		Address:  "127.0.0.1",
+		Port:     olt.SSHAddr().(*net.TCPAddr).Port,
+		Protocol: types.ProtocolCLI,
+		Username: DefaultUsername,
+		Password: DefaultPassword,
+		Timeout:  5 * time.Second,
+	}
+	base, err := cli.NewDriver(config)
+	if err != nil {
+		t.Fatalf("cli.NewDriver() error = %v", err)
+	}
+	driver := cdata.NewAdapter(base, config)
+	if err := driver.Connect(context.Background(), config); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { driver.Disconnect(context.Background()) })
+	return driver
+}
+
+func newSubscriber(serial, port, id string) *model.Subscriber {
+	subscriber := testutil.NewTestSubscriber(serial, port, 300)
+	subscriber.Annotations["nanoncore.com/pon-port"] = port
+	subscriber.Annotations["nanoncore.com/onu-id"] = id
+	return subscriber
+}
+
+func TestAdapterAgainstSimulator(t *testing.T) {
+	olt := startOLT(t, Config{})
+	olt.ONUs.Discover(simulator.ONU{Port: "1/1/2", Serial: "CDAT12345678", DistanceM: 850})
+
+	ctx := context.Background()
+	driver := connect(t, olt)
+	if err := driver.HealthCheck(ctx); err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+
+	if _, err := driver.CreateSubscriber(ctx, newSubscriber("CDAT12345678", "1/1/2", "4"), testutil.NewTestServiceTier(100, 50)); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	onu, ok := olt.ONUs.Get("1/1/2", 4)
+	if !ok || onu.Serial != "CDAT12345678" || onu.VLAN != 300 || onu.Disabled || onu.DistanceM != 850 {
+		t.Fatalf("provisioned ONU = %+v, %v", onu, ok)
+	}
+
+	status, err := driver.GetSubscriberStatus(ctx, "onu-1/1/2-4")
+	if err != nil {
+		t.Fatalf("GetSubscriberStatus() error = %v", err)
+	}
+	if !status.IsOnline {
+		t.Errorf("GetSubscriberStatus() = %+v, want online", status)
+	}
+
+	found, err := driver.(types.ONULookup).GetONUBySerial(ctx, "4344415412345678")
+	if err != nil {
+		t.Fatalf("GetONUBySerial() error = %v", err)
+	}
+	if found == nil || found.PONPort != "1/1/2" || found.ONUID != 4 || found.VLAN != 300 || !found.IsOnline {
+		t.Errorf("GetONUBySerial() = %+v", found)
+	}
+
+	v2 := driver.(types.DriverV2)
+	onus, err := v2.GetONUList(ctx, &types.ONUFilter{Status: "online"})
+	if err != nil {
+		t.Fatalf("GetONUList() error = %v", err)
+	}
+	if len(onus) != 1 || onus[0].Serial != "CDAT12345678" || onus[0].PONPort != "1/1/2" || onus[0].DistanceM != 850 {
+		t.Errorf("GetONUList() = %+v", onus)
+	}
+	power, err := v2.GetONUPower(ctx, "1/1/2", 4)
+	if err != nil {
+		t.Fatalf("GetONUPower() error = %v", err)
+	}
+	if power.DistanceM != 850 || power.Serial != "CDAT12345678" {
+		t.Errorf("GetONUPower() = %+v", power)
+	}
+	oltStatus, err := v2.GetOLTStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetOLTStatus() error = %v", err)
+	}
+	if oltStatus.Model != "FD1104S" || oltStatus.TotalONUs != 1 || len(oltStatus.PONPorts) != DefaultPorts || oltStatus.PONPorts[1].ONUCount != 1 {
+		t.Errorf("GetOLTStatus() = %+v", oltStatus)
+	}
+	if alarms, err := v2.GetAlarms(ctx); err != nil || len(alarms) != 0 {
+		t.Errorf("GetAlarms() = %+v, %v", alarms, err)
+	}
+
+	if err := driver.SuspendSubscriber(ctx, "onu-1/1/2-4"); err != nil {
+		t.Fatalf("SuspendSubscriber() error = %v", err)
+	}
+	if status, _ := driver.GetSubscriberStatus(ctx, "onu-1/1/2-4"); status.State != "suspended" {
+		t.Errorf("GetSubscriberStatus() state = %q, want suspended", status.State)
+	}
+
+	if err := driver.DeleteSubscriber(ctx, "onu-1/1/2-4"); err != nil {
+		t.Fatalf("DeleteSubscriber() error = %v", err)
+	}
+	if _, ok := olt.ONUs.Get("1/1/2", 4); ok {
+		t.Error("ONU not deleted")
+	}
+}
+
+func TestSilentCommitFailure(t *testing.T) {
+	olt := startOLT(t, Config{Quirks: &simulator.Quirks{SilentFailures: []string{"commit"}}})
+
+	// Every command of the create succeeds, so only the verification that
+	// follows it notices the ONU is missing
+	_, err := connect(t, olt).CreateSubscriber(context.Background(), newSubscriber("CDAT12345678", "1/1/1", "1"), testutil.NewTestServiceTier(100, 50))
+	if err == nil || !strings.Contains(err.Error(), "verification failed") {
+		t.Fatalf("CreateSubscriber() error = %v, want verification failure", err)
+	}
+	if onus := olt.ONUs.List(""); len(onus) != 0 {
+		t.Errorf("ONUs = %+v, want none", onus)
+	}
+}
+
+func TestSetPortStateAgainstSimulator(t *testing.T) {
+	olt := startOLT(t, Config{})
+	ctx := context.Background()
+	v2 := connect(t, olt).(types.DriverV2)
+
+	if err := v2.SetPortState(ctx, "1/1/3", false); err != nil {
+		t.Fatalf("SetPortState(false) error = %v", err)
+	}
+	if !olt.PortShutdown("1/1/3") {
+		t.Fatal("port 1/1/3 not shut down")
+	}
+	ports, err := v2.ListPorts(ctx)
+	if err != nil {
+		t.Fatalf("ListPorts() error = %v", err)
+	}
+	if len(ports) != DefaultPorts || ports[2].AdminState != "disabled" || ports[2].OperState != "down" || ports[0].AdminState != "enabled" {
+		t.Errorf("ListPorts() = %+v", ports)
+	}
+
+	if err := v2.SetPortState(ctx, "1/1/3", true); err != nil {
+		t.Fatalf("SetPortState(true) error = %v", err)
+	}
+	if olt.PortShutdown("1/1/3") {
+		t.Error("port 1/1/3 still shut down")
+	}
+}
@@ -0,0 +1,418 @@
+package simulator
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultMorePrompt is the pager prompt used when CLIConfig.MorePrompt is
+// empty
+const DefaultMorePrompt = "--More--"
+
+// ErrClosed is returned when starting a closed server
+var ErrClosed = errors.New("simulator: server closed")
+
+// Shell handles the commands of one CLI session
+type Shell interface {
+	// Prompt is written before each command
+	Prompt() string
+
+	// Exec runs a command line and returns its output. exit ends the
+	// session.
+	Exec(line string) (output string, exit bool)
+}
+
+// CLIConfig configures a CLIServer
+type CLIConfig struct {
+	// Username and Password are required for SSH and, with Login, at the
+	// CLI login prompt. An empty Username accepts any credentials.
+	Username string
+	Password string
+
+	// Banner is written when a session starts
+	Banner string
+
+	// Login asks for the username and password again once the SSH session
+	// has started, as V-SOL OLTs do
+	Login bool
+
+	// PageLines paginates output longer than this many lines. Zero
+	// disables paging.
+	PageLines int
+
+	// MorePrompt is shown between pages. Defaults to DefaultMorePrompt.
+	MorePrompt string
+
+	// PagerOff are the commands that disable paging for the rest of the
+	// session, e.g. "terminal length 0"
+	PagerOff []string
+
+	// HostKey defaults to a generated ed25519 key
+	HostKey ssh.Signer
+
+	// NewShell creates the command handler of each session
+	NewShell func() Shell
+}
+
+// CLIServer serves a simulated device CLI over SSH
+type CLIServer struct {
+	config    CLIConfig
+	sshConfig *ssh.ServerConfig
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewCLIServer creates a CLI server. Call Start to accept connections.
+func NewCLIServer(config CLIConfig) (*CLIServer, error) {
+	if config.NewShell == nil {
+		return nil, fmt.Errorf("NewShell is required")
+	}
+	if config.MorePrompt == "" {
+		config.MorePrompt = DefaultMorePrompt
+	}
+	if config.HostKey == nil {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate host key: %w", err)
+		}
+		signer, err := ssh.NewSignerFromKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create host key signer: %w", err)
+		}
+		config.HostKey = signer
+	}
+
+	s := &CLIServer{config: config, conns: make(map[net.Conn]struct{})}
+	s.sshConfig = &ssh.ServerConfig{
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if s.authorized(meta.User(), string(password)) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("invalid credentials for %s", meta.User())
+		},
+	}
+	s.sshConfig.AddHostKey(config.HostKey)
+	return s, nil
+}
+
+// Start listens on addr, e.g. "127.0.0.1:0", and serves connections in
+// the background until Close
+func (s *CLIServer) Start(addr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s.listener = l
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			if !s.track(conn) {
+				conn.Close()
+				return
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				defer s.untrack(conn)
+				s.serveConn(conn)
+			}()
+		}
+	}()
+	return nil
+}
+
+// Addr returns the listening address, or nil before Start
+func (s *CLIServer) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Close stops the server and closes all sessions
+func (s *CLIServer) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *CLIServer) authorized(user, password string) bool {
+	return s.config.Username == "" || user == s.config.Username && password == s.config.Password
+}
+
+func (s *CLIServer) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *CLIServer) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+	conn.Close()
+}
+
+func (s *CLIServer) serveConn(nc net.Conn) {
+	conn, chans, reqs, err := ssh.NewServerConn(nc, s.sshConfig)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	for nch := range chans {
+		if nch.ChannelType() != "session" {
+			nch.Reject(ssh.UnknownChannelType, "unsupported channel type") //nolint:errcheck // client gone
+			continue
+		}
+		ch, requests, err := nch.Accept()
+		if err != nil {
+			continue
+		}
+		go s.serveChannel(ch, requests)
+	}
+}
+
+func (s *CLIServer) serveChannel(ch ssh.Channel, requests <-chan *ssh.Request) {
+	started := false
+	for req := range requests {
+		switch req.Type {
+		case "pty-req", "env", "window-change":
+			req.Reply(true, nil) //nolint:errcheck // client gone
+		case "shell", "exec":
+			if started {
+				req.Reply(false, nil) //nolint:errcheck // client gone
+				continue
+			}
+			started = true
+			req.Reply(true, nil) //nolint:errcheck // client gone
+			var command string
+			if req.Type == "exec" {
+				var payload struct{ Command string }
+				if err := ssh.Unmarshal(req.Payload, &payload); err == nil {
+					command = payload.Command
+				}
+			}
+			go func() {
+				defer ch.Close()
+				if req.Type == "exec" {
+					out, _ := s.config.NewShell().Exec(command)
+					io.WriteString(ch, crlf(out)) //nolint:errcheck // client gone
+				} else {
+					s.runShell(ch)
+				}
+				ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0})) //nolint:errcheck // client gone
+			}()
+		default:
+			req.Reply(false, nil) //nolint:errcheck // client gone
+		}
+	}
+}
+
+// session is the terminal state of one shell
+type session struct {
+	rw        io.ReadWriter
+	r         *bufio.Reader
+	lastCR    bool
+	pageLines int
+	more      string
+}
+
+func (s *CLIServer) runShell(ch ssh.Channel) {
+	sess := &session{rw: ch, r: bufio.NewReader(ch), pageLines: s.config.PageLines, more: s.config.MorePrompt}
+	if s.config.Banner != "" {
+		if _, err := io.WriteString(ch, crlf(s.config.Banner)+"\r\n"); err != nil {
+			return
+		}
+	}
+	if s.config.Login && !s.login(sess) {
+		return
+	}
+
+	shell := s.config.NewShell()
+	for {
+		if _, err := io.WriteString(ch, shell.Prompt()); err != nil {
+			return
+		}
+		line, err := sess.readLine(true)
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if s.pagerOff(line) {
+			sess.pageLines = 0
+			continue
+		}
+		out, exit := shell.Exec(line)
+		if err := sess.write(out); err != nil || exit {
+			return
+		}
+	}
+}
+
+// login runs the CLI login prompt, allowing three attempts
+func (s *CLIServer) login(sess *session) bool {
+	for range 3 {
+		if _, err := io.WriteString(sess.rw, "Login: "); err != nil {
+			return false
+		}
+		user, err := sess.readLine(true)
+		if err != nil {
+			return false
+		}
+		if _, err := io.WriteString(sess.rw, "Password: "); err != nil {
+			return false
+		}
+		password, err := sess.readLine(false)
+		if err != nil {
+			return false
+		}
+		if s.authorized(strings.TrimSpace(user), password) {
+			return true
+		}
+		if _, err := io.WriteString(sess.rw, "Login incorrect\r\n"); err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+func (s *CLIServer) pagerOff(line string) bool {
+	for _, c := range s.config.PagerOff {
+		if line == c {
+			return true
+		}
+	}
+	return false
+}
+
+// readLine reads a line terminated by CR, LF or CRLF, echoing it if echo
+// is set. Backspace edits the line and Ctrl-C discards it.
+func (sess *session) readLine(echo bool) (string, error) {
+	var line []byte
+	for {
+		b, err := sess.r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		lastCR := sess.lastCR
+		sess.lastCR = b == '\r'
+		switch b {
+		case '\n':
+			if lastCR {
+				continue
+			}
+			fallthrough
+		case '\r':
+			_, err := io.WriteString(sess.rw, "\r\n")
+			return string(line), err
+		case 0x7f, '\b':
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				if echo {
+					if _, err := io.WriteString(sess.rw, "\b \b"); err != nil {
+						return "", err
+					}
+				}
+			}
+			continue
+		case 0x03:
+			line = line[:0]
+			_, err := io.WriteString(sess.rw, "^C\r\n")
+			return "", err
+		case 0x04:
+			if len(line) == 0 {
+				return "", io.EOF
+			}
+			continue
+		}
+		line = append(line, b)
+		if echo {
+			if _, err := sess.rw.Write([]byte{b}); err != nil {
+				return "", err
+			}
+		}
+	}
+}
+
+// write writes command output, pausing at the pager prompt after every
+// page. A space or return shows the next page and q stops the output.
+func (sess *session) write(out string) error {
+	if out == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(out, "\r\n", "\n"), "\n"), "\n")
+	var page strings.Builder
+	for i, line := range lines {
+		if sess.pageLines > 0 && i > 0 && i%sess.pageLines == 0 {
+			page.WriteString(sess.more)
+			if _, err := io.WriteString(sess.rw, page.String()); err != nil {
+				return err
+			}
+			page.Reset()
+			key, err := sess.r.ReadByte()
+			if err != nil {
+				return err
+			}
+			sess.lastCR = key == '\r'
+			if _, err := io.WriteString(sess.rw, "\r\n"); err != nil {
+				return err
+			}
+			if key == 'q' || key == 'Q' || key == 0x03 {
+				return nil
+			}
+		}
+		page.WriteString(line)
+		page.WriteString("\r\n")
+	}
+	_, err := io.WriteString(sess.rw, page.String())
+	return err
+}
+
+// crlf converts LF line endings to CRLF
+func crlf(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+}
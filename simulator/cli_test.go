@@ -0,0 +1,196 @@
+package simulator
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// lineShell prints n numbered lines for the command "lines" and echoes
+// anything else
+type lineShell struct{ n int }
+
+func (s *lineShell) Prompt() string { return "sim> " }
+
+func (s *lineShell) Exec(line string) (string, bool) {
+	switch line {
+	case "exit":
+		return "", true
+	case "lines":
+		var b strings.Builder
+		for i := range s.n {
+			b.WriteString("line " + string(rune('a'+i)) + "\n")
+		}
+		return b.String(), false
+	}
+	return "you said " + line, false
+}
+
+// terminal is the client side of a shell session
+type terminal struct {
+	t      *testing.T
+	stdin  io.Writer
+	output chan []byte
+	buf    bytes.Buffer
+}
+
+func startCLI(t *testing.T, config CLIConfig) *CLIServer {
+	t.Helper()
+	if config.NewShell == nil {
+		config.NewShell = func() Shell { return &lineShell{n: 5} }
+	}
+	s, err := NewCLIServer(config)
+	if err != nil {
+		t.Fatalf("NewCLIServer() error = %v", err)
+	}
+	if err := s.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func dial(t *testing.T, s *CLIServer, user, password string) (*ssh.Client, error) {
+	return ssh.Dial("tcp", s.Addr().String(), &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.Password(password)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec // test server
+		Timeout:         5 * time.Second,
+	})
+}
+
+func openShell(t *testing.T, s *CLIServer) *terminal {
+	t.Helper()
+	client, err := dial(t, s, "admin", "secret")
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	stdin, _ := session.StdinPipe()
+	stdout, _ := session.StdoutPipe()
+	if err := session.RequestPty("vt100", 80, 40, ssh.TerminalModes{}); err != nil {
+		t.Fatalf("RequestPty() error = %v", err)
+	}
+	if err := session.Shell(); err != nil {
+		t.Fatalf("Shell() error = %v", err)
+	}
+
+	term := &terminal{t: t, stdin: stdin, output: make(chan []byte, 64)}
+	go func() {
+		defer close(term.output)
+		for {
+			buf := make([]byte, 1024)
+			n, err := stdout.Read(buf)
+			if n > 0 {
+				term.output <- buf[:n]
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return term
+}
+
+// expect reads until the output ends with suffix and returns everything
+// read up to it
+func (term *terminal) expect(suffix string) string {
+	term.t.Helper()
+	timeout := time.After(5 * time.Second)
+	for !strings.HasSuffix(term.buf.String(), suffix) {
+		select {
+		case b, ok := <-term.output:
+			if !ok {
+				term.t.Fatalf("session closed waiting for %q, got %q", suffix, term.buf.String())
+			}
+			term.buf.Write(b)
+		case <-timeout:
+			term.t.Fatalf("timeout waiting for %q, got %q", suffix, term.buf.String())
+		}
+	}
+	out := term.buf.String()
+	term.buf.Reset()
+	return out
+}
+
+func (term *terminal) send(s string) {
+	term.t.Helper()
+	if _, err := io.WriteString(term.stdin, s); err != nil {
+		term.t.Fatalf("send error = %v", err)
+	}
+}
+
+func TestCLILogin(t *testing.T) {
+	s := startCLI(t, CLIConfig{Username: "admin", Password: "secret", Banner: "Welcome", Login: true})
+	term := openShell(t, s)
+
+	if out := term.expect("Login: "); !strings.HasPrefix(out, "Welcome\r\n") {
+		t.Errorf("banner = %q", out)
+	}
+	term.send("admin\r")
+	term.expect("Password: ")
+	term.send("wrong\r")
+	term.expect("Login incorrect\r\nLogin: ")
+	term.send("admin\r\n")
+	term.expect("Password: ")
+	// The password is not echoed
+	term.send("secret\r")
+	if out := term.expect("sim> "); out != "\r\nsim> " {
+		t.Errorf("after password = %q", out)
+	}
+
+	term.send("hello\r")
+	if out := term.expect("sim> "); out != "hello\r\nyou said hello\r\nsim> " {
+		t.Errorf("command output = %q", out)
+	}
+}
+
+func TestCLIRejectsBadCredentials(t *testing.T) {
+	s := startCLI(t, CLIConfig{Username: "admin", Password: "secret"})
+	if _, err := dial(t, s, "admin", "wrong"); err == nil {
+		t.Fatal("Dial() with a wrong password succeeded")
+	}
+}
+
+func TestCLIPager(t *testing.T) {
+	s := startCLI(t, CLIConfig{Username: "admin", Password: "secret", PageLines: 2, PagerOff: []string{"terminal length 0"}})
+	term := openShell(t, s)
+	term.expect("sim> ")
+
+	term.send("lines\r")
+	if out := term.expect(DefaultMorePrompt); out != "lines\r\nline a\r\nline b\r\n--More--" {
+		t.Errorf("first page = %q", out)
+	}
+	term.send(" ")
+	if out := term.expect(DefaultMorePrompt); out != "\r\nline c\r\nline d\r\n--More--" {
+		t.Errorf("second page = %q", out)
+	}
+	term.send("q")
+	if out := term.expect("sim> "); out != "\r\nsim> " {
+		t.Errorf("after quit = %q", out)
+	}
+
+	term.send("terminal length 0\r")
+	term.expect("sim> ")
+	term.send("lines\r")
+	if out := term.expect("sim> "); strings.Contains(out, DefaultMorePrompt) || !strings.Contains(out, "line e") {
+		t.Errorf("unpaged output = %q", out)
+	}
+}
+
+func TestCLIExit(t *testing.T) {
+	s := startCLI(t, CLIConfig{})
+	term := openShell(t, s)
+	term.expect("sim> ")
+	term.send("exit\r")
+	for range term.output {
+	}
+}
@@ -0,0 +1,710 @@
+// Package huaweisim simulates a Huawei MA5800 series GPON OLT: the VRP
+// style CLI with its "---- More ----" pager, hex-encoded ONT serials, and
+// the ONT serial, optical and traffic tables of its SNMP MIB.
+package huaweisim
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/nanoncore/nano-southbound/simulator"
+)
+
+// Defaults applied by New for zero Config fields
+const (
+	DefaultHostname  = "MA5800-X7"
+	DefaultUsername  = "root"
+	DefaultPassword  = "admin123"
+	DefaultCommunity = "public"
+	DefaultSlot      = 1
+	DefaultPorts     = 16
+
+	// MorePrompt is the pager prompt of the MA5800
+	MorePrompt = "  ---- More ( Press 'Q' to break ) ----"
+)
+
+// DefaultQuirks are the firmware quirks simulated when Config.Quirks is
+// nil. Paging is off, as after "screen-length 0 temporary"; set
+// Quirks.PageLines to page output behind MorePrompt.
+var DefaultQuirks = simulator.Quirks{}
+
+// Config configures a simulated OLT
+type Config struct {
+	Hostname  string
+	Username  string
+	Password  string
+	Community string
+
+	// Slot is the slot of the GPON board in frame 0
+	Slot int
+
+	// Ports is the number of PON ports of the board, named 0/<slot>/0 to
+	// 0/<slot>/<Ports-1>
+	Ports int
+
+	// Quirks defaults to DefaultQuirks
+	Quirks *simulator.Quirks
+}
+
+// ServicePort is a service-port binding an ONT GEM port to a VLAN
+type ServicePort struct {
+	Index    int
+	VLAN     int
+	Port     string
+	ONTID    int
+	GEMPort  int
+	UserVLAN int
+}
+
+// OLT is a simulated Huawei OLT
+type OLT struct {
+	// ONUs is the ONT state. Add ONTs to the autofind list with Discover.
+	ONUs *simulator.Inventory
+
+	config  Config
+	quirks  simulator.Quirks
+	started time.Time
+	cli     *simulator.CLIServer
+	snmp    *simulator.SNMPAgent
+
+	mu           sync.Mutex
+	servicePorts []ServicePort
+	shutdown     map[string]bool
+}
+
+// New creates a simulated OLT. Call Start to serve it.
+func New(config Config) (*OLT, error) {
+	if config.Hostname == "" {
+		config.Hostname = DefaultHostname
+	}
+	if config.Username == "" {
+		config.Username = DefaultUsername
+	}
+	if config.Password == "" {
+		config.Password = DefaultPassword
+	}
+	if config.Community == "" {
+		config.Community = DefaultCommunity
+	}
+	if config.Slot <= 0 {
+		config.Slot = DefaultSlot
+	}
+	if config.Ports <= 0 {
+		config.Ports = DefaultPorts
+	}
+	quirks := DefaultQuirks
+	if config.Quirks != nil {
+		quirks = *config.Quirks
+	}
+
+	o := &OLT{ONUs: simulator.NewInventory(), config: config, quirks: quirks, started: time.Now(), shutdown: map[string]bool{}}
+	cli, err := simulator.NewCLIServer(simulator.CLIConfig{
+		Username:   config.Username,
+		Password:   config.Password,
+		Banner:     "Huawei Integrated Access Software (MA5800).\nCopyright(C) Huawei Technologies Co., Ltd. 2002-2019. All rights reserved.",
+		PageLines:  quirks.PageLines,
+		MorePrompt: MorePrompt,
+		PagerOff:   []string{"screen-length 0 temporary", "scroll"},
+		NewShell:   func() simulator.Shell { return &shell{olt: o} },
+	})
+	if err != nil {
+		return nil, err
+	}
+	o.cli = cli
+	o.snmp = simulator.NewSNMPAgent(config.Community, o.mib)
+	return o, nil
+}
+
+// Start serves the CLI over SSH on sshAddr and, unless snmpAddr is empty,
+// SNMP on snmpAddr
+func (o *OLT) Start(sshAddr, snmpAddr string) error {
+	if err := o.cli.Start(sshAddr); err != nil {
+		return err
+	}
+	if snmpAddr != "" {
+		if err := o.snmp.Start(snmpAddr); err != nil {
+			o.cli.Close() //nolint:errcheck // start failed
+			return err
+		}
+	}
+	return nil
+}
+
+// SSHAddr returns the address of the CLI, or nil before Start
+func (o *OLT) SSHAddr() net.Addr { return o.cli.Addr() }
+
+// SNMPAddr returns the address of the SNMP agent, or nil if it is not
+// started
+func (o *OLT) SNMPAddr() net.Addr { return o.snmp.Addr() }
+
+// Close stops the simulator
+func (o *OLT) Close() error {
+	err := o.snmp.Close()
+	if cerr := o.cli.Close(); cerr != nil {
+		err = cerr
+	}
+	return err
+}
+
+// PortShutdown reports whether a PON port is shut down
+func (o *OLT) PortShutdown(port string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.shutdown[port]
+}
+
+// ServicePorts returns the configured service-ports
+func (o *OLT) ServicePorts() []ServicePort {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]ServicePort(nil), o.servicePorts...)
+}
+
+// validPort reports whether frame/slot/port is one of the OLT's PON ports
+func (o *OLT) validPort(frame, slot, port int) bool {
+	return frame == 0 && slot == o.config.Slot && port >= 0 && port < o.config.Ports
+}
+
+// shell modes
+const (
+	modeUser = iota
+	modeEnable
+	modeConfig
+	modeInterface
+)
+
+// shell is one CLI session
+type shell struct {
+	olt   *OLT
+	mode  int
+	frame int
+	slot  int
+}
+
+func (s *shell) Prompt() string {
+	host := s.olt.config.Hostname
+	switch s.mode {
+	case modeUser, modeEnable:
+		return "<" + host + ">"
+	case modeConfig:
+		return "[" + host + "]"
+	default:
+		return fmt.Sprintf("[%s-if-gpon-%d-%d]", host, s.frame, s.slot)
+	}
+}
+
+func (s *shell) Exec(line string) (string, bool) {
+	f := strings.Fields(line)
+	switch {
+	case line == "quit":
+		switch s.mode {
+		case modeInterface:
+			s.mode = modeConfig
+		case modeConfig:
+			s.mode = modeEnable
+		default:
+			return "", true
+		}
+		return "", false
+	case line == "return":
+		if s.mode >= modeConfig {
+			s.mode = modeEnable
+		}
+		return "", false
+	case line == "enable":
+		if s.mode == modeUser {
+			s.mode = modeEnable
+		}
+		return "", false
+	case line == "config":
+		if s.mode == modeUser {
+			return unknown(), false
+		}
+		s.mode = modeConfig
+		return "", false
+	case line == "display version":
+		return s.olt.displayVersion(), false
+	case line == "display board 0":
+		return s.olt.displayBoard(), false
+	case line == "display ont autofind all":
+		return s.olt.displayAutofind(), false
+	case line == "display service-port all":
+		return s.olt.displayServicePorts(), false
+	case len(f) == 5 && f[0] == "display" && f[1] == "ont" && (f[2] == "info" || f[2] == "optical-info" || f[2] == "traffic"):
+		return s.olt.displayONT(f[2], f[3], f[4], ""), false
+	case len(f) == 6 && f[0] == "display" && f[1] == "ont":
+		// display ont info 0/1 0 5
+		return s.olt.displayONT(f[2], f[3], f[4], f[5]), false
+	case len(f) == 3 && f[0] == "interface" && f[1] == "gpon":
+		if s.mode < modeConfig {
+			return unknown(), false
+		}
+		var frame, slot int
+		if _, err := fmt.Sscanf(f[2], "%d/%d", &frame, &slot); err != nil || !s.olt.validPort(frame, slot, 0) {
+			return "  Failure: The board does not exist", false
+		}
+		s.mode, s.frame, s.slot = modeInterface, frame, slot
+		return "", false
+	case len(f) > 0 && (f[0] == "service-port" || f[0] == "undo" && len(f) > 1 && f[1] == "service-port"):
+		if s.mode != modeConfig {
+			return unknown(), false
+		}
+		return s.olt.servicePort(line, f), false
+	}
+	if s.mode != modeInterface {
+		return unknown(), false
+	}
+	return s.olt.execInterface(s.frame, s.slot, line, f), false
+}
+
+// execInterface runs a command in the GPON interface mode of frame/slot
+func (o *OLT) execInterface(frame, slot int, line string, f []string) string {
+	switch {
+	case line == "display port state all":
+		return o.displayPortState(frame, slot)
+	case len(f) == 2 && f[0] == "shutdown", len(f) == 3 && f[0] == "undo" && f[1] == "shutdown":
+		// shutdown <port> / undo shutdown <port>
+		p, err := strconv.Atoi(f[len(f)-1])
+		if err != nil {
+			return unknown()
+		}
+		if !o.validPort(frame, slot, p) {
+			return "  Failure: The port does not exist"
+		}
+		if o.quirks.Silent(line) {
+			return ""
+		}
+		o.mu.Lock()
+		o.shutdown[fspName(frame, slot, p)] = f[0] == "shutdown"
+		o.mu.Unlock()
+		return ""
+	}
+	if len(f) < 4 || f[0] != "ont" {
+		return unknown()
+	}
+	// ont port native-vlan <port> <id> ... names the port attribute first
+	args := f[2:]
+	if f[1] == "port" {
+		args = f[3:]
+	}
+	if len(args) < 2 {
+		return unknown()
+	}
+	p, err1 := strconv.Atoi(args[0])
+	id, err2 := strconv.Atoi(args[1])
+	if err1 != nil || err2 != nil {
+		return unknown()
+	}
+	if !o.validPort(frame, slot, p) {
+		return "  Failure: The port does not exist"
+	}
+	port := fspName(frame, slot, p)
+
+	if f[1] == "add" {
+		// ont add <port> <id> sn-auth <sn> omci ont-lineprofile-id <n> ...
+		serial := valueAfter(f, "sn-auth")
+		if serial == "" {
+			return unknown()
+		}
+		serial = decodeSerial(serial)
+		if o.quirks.Silent(line) {
+			return addedOutput(p, id)
+		}
+		if err := o.ONUs.Add(simulator.ONU{Port: port, ID: id, Serial: serial, Profile: valueAfter(f, "ont-lineprofile-id")}); err != nil {
+			if strings.Contains(err.Error(), "serial") {
+				return "  Failure: SN already exists"
+			}
+			return "  Failure: The ONT ID has already existed"
+		}
+		return addedOutput(p, id)
+	}
+
+	if _, ok := o.ONUs.Get(port, id); !ok {
+		return "  Failure: The ONT does not exist"
+	}
+	if o.quirks.Silent(line) {
+		return ""
+	}
+	switch f[1] {
+	case "delete":
+		o.ONUs.Delete(port, id)
+		o.removeServicePorts(port, id)
+		return "  Number of ONTs that can be deleted: 1, success: 1"
+	case "activate", "deactivate":
+		o.ONUs.Update(port, id, func(onu *simulator.ONU) { onu.Disabled = f[1] == "deactivate" })
+	case "reset":
+		o.ONUs.Update(port, id, func(onu *simulator.ONU) { onu.OnlineSince = time.Now() })
+	case "port":
+		// ont port native-vlan <port> <id> eth 1 vlan <vlan> priority 0
+		if v, err := strconv.Atoi(valueAfter(f, "vlan")); err == nil {
+			o.ONUs.Update(port, id, func(onu *simulator.ONU) { onu.VLAN = v })
+		}
+	case "modify":
+		if v := valueAfter(f, "ont-lineprofile-id"); v != "" {
+			o.ONUs.Update(port, id, func(onu *simulator.ONU) { onu.Profile = v })
+		}
+	case "traffic-policy":
+	default:
+		return unknown()
+	}
+	return ""
+}
+
+// servicePort runs a service-port or undo service-port command
+func (o *OLT) servicePort(line string, f []string) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if f[0] == "undo" {
+		// undo service-port port <f/s/p> ont <id>
+		port := valueAfter(f, "port")
+		id, err := strconv.Atoi(valueAfter(f, "ont"))
+		if port == "" || err != nil {
+			return unknown()
+		}
+		if o.quirks.Silent(line) {
+			return ""
+		}
+		if !o.removeServicePortsLocked(port, id) {
+			return "  Failure: The service virtual port does not exist"
+		}
+		return ""
+	}
+
+	// service-port vlan <vlan> gpon <f/s/p> ont <id> gemport <n> multi-service user-vlan <vlan> ...
+	vlan, err1 := strconv.Atoi(valueAfter(f, "vlan"))
+	id, err2 := strconv.Atoi(valueAfter(f, "ont"))
+	gem, err3 := strconv.Atoi(valueAfter(f, "gemport"))
+	if err1 != nil || err2 != nil || err3 != nil {
+		return unknown()
+	}
+	port := valueAfter(f, "gpon")
+	if _, ok := o.ONUs.Get(port, id); !ok {
+		return "  Failure: The ONT does not exist"
+	}
+	userVLAN, _ := strconv.Atoi(valueAfter(f, "user-vlan"))
+	if o.quirks.Silent(line) {
+		return ""
+	}
+	index := 0
+	for _, sp := range o.servicePorts {
+		index = max(index, sp.Index+1)
+	}
+	o.servicePorts = append(o.servicePorts, ServicePort{Index: index, VLAN: vlan, Port: port, ONTID: id, GEMPort: gem, UserVLAN: userVLAN})
+	return ""
+}
+
+// removeServicePorts removes the service-ports of an ONT
+func (o *OLT) removeServicePorts(port string, id int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.removeServicePortsLocked(port, id)
+}
+
+// removeServicePortsLocked removes the service-ports of an ONT and reports
+// whether it had any. o.mu must be held.
+func (o *OLT) removeServicePortsLocked(port string, id int) bool {
+	kept := o.servicePorts[:0]
+	for _, sp := range o.servicePorts {
+		if sp.Port != port || sp.ONTID != id {
+			kept = append(kept, sp)
+		}
+	}
+	removed := len(kept) != len(o.servicePorts)
+	o.servicePorts = kept
+	return removed
+}
+
+// displayBoard formats "display board 0": the GPON board in the configured
+// slot and the active control board in slot 8
+func (o *OLT) displayBoard() string {
+	rule := "  " + strings.Repeat("-", 73)
+	var b strings.Builder
+	b.WriteString(rule + "\n")
+	b.WriteString("  SlotID  BoardName  Status          SubType0 SubType1    Online/Offline\n")
+	b.WriteString(rule + "\n")
+	for slot := 0; slot <= 8; slot++ {
+		switch slot {
+		case o.config.Slot:
+			fmt.Fprintf(&b, "  %-8d%-11s%s\n", slot, "H901GPHF", "Normal")
+		case 8:
+			fmt.Fprintf(&b, "  %-8d%-11s%-16s%s\n", slot, "H901MPLA", "Active_normal", "CPCF")
+		default:
+			fmt.Fprintf(&b, "  %d\n", slot)
+		}
+	}
+	b.WriteString(rule)
+	return b.String()
+}
+
+// displayPortState formats "display port state all" of the GPON board in
+// frame/slot. A shut down port is in state Disable; a port without online
+// ONTs is Offline.
+func (o *OLT) displayPortState(frame, slot int) string {
+	rule := "  " + strings.Repeat("-", 76)
+	var b strings.Builder
+	for p := 0; p < o.config.Ports; p++ {
+		port := fspName(frame, slot, p)
+		state := "Offline"
+		for _, onu := range o.ONUs.List(port) {
+			if !onu.Disabled && !onu.Offline {
+				state = "Online"
+			}
+		}
+		if o.PortShutdown(port) {
+			state = "Disable"
+		}
+		b.WriteString(rule + "\n")
+		for _, kv := range [][2]string{
+			{"F/S/P", port},
+			{"Optical Module status", "Online"},
+			{"Port state", state},
+			{"Laser state", "Normal"},
+			{"Temperature(C)", "37"},
+			{"TX power(dBm)", "4.23"},
+		} {
+			fmt.Fprintf(&b, "  %-41s%s\n", kv[0], kv[1])
+		}
+	}
+	b.WriteString(rule)
+	return b.String()
+}
+
+// displayServicePorts formats the service-ports in the MA5800 layout, with
+// the ONT ID as VPI and the GEM port as VCI. Service-ports of ONTs that
+// are not online are down.
+func (o *OLT) displayServicePorts() string {
+	o.mu.Lock()
+	servicePorts := append([]ServicePort(nil), o.servicePorts...)
+	o.mu.Unlock()
+	if len(servicePorts) == 0 {
+		return "  Failure: No service virtual port can be operated"
+	}
+	rule := "  " + strings.Repeat("-", 77)
+	var b strings.Builder
+	b.WriteString("  Switch-Oriented Flow List\n" + rule + "\n")
+	b.WriteString("   INDEX VLAN VLAN     PORT F/ S/ P VPI  VCI   FLOW  FLOW       RX   TX   STATE\n")
+	b.WriteString("         ID   ATTR     TYPE                    TYPE  PARA\n" + rule + "\n")
+	up := 0
+	for _, sp := range servicePorts {
+		var frame, slot, port int
+		fmt.Sscanf(sp.Port, "%d/%d/%d", &frame, &slot, &port)
+		state := "down"
+		if onu, ok := o.ONUs.Get(sp.Port, sp.ONTID); ok && !onu.Disabled && !onu.Offline {
+			state = "up"
+			up++
+		}
+		fmt.Fprintf(&b, "   %5d %4d common   gpon %d/%-2d/%d  %-4d %-5d vlan  %-10d -    -    %s\n",
+			sp.Index, sp.VLAN, frame, slot, port, sp.ONTID, sp.GEMPort, sp.UserVLAN, state)
+	}
+	b.WriteString(rule + "\n")
+	fmt.Fprintf(&b, "   Total : %d  (Up/Down :    %d/%d)\n", len(servicePorts), up, len(servicePorts)-up)
+	b.WriteString("   Note : F--Frame, S--Slot, P--Port,\n")
+	b.WriteString("          VPI indicates ONT ID or VPI, VCI indicates GEM index or VCI")
+	return b.String()
+}
+
+func addedOutput(port, id int) string {
+	return fmt.Sprintf("  Number of ONTs that can be added: 1, success: 1\n  PortID :%d, ONTID :%d", port, id)
+}
+
+func (o *OLT) displayVersion() string {
+	uptime := time.Since(o.started)
+	days := int(uptime.Hours()) / 24
+	return fmt.Sprintf(`  {<cr>|backplane<K>|frameid/slotid<S><Length 1-15>}:
+
+  VERSION : MA5800V100R019C10
+  PATCH   : SPC100
+  PRODUCT : MA5800-X7
+  Uptime is %d day(s), %d hour(s), %d minute(s), %d second(s)`,
+		days, int(uptime.Hours())%24, int(uptime.Minutes())%60, int(uptime.Seconds())%60)
+}
+
+// displayAutofind formats the autofind list of all ports. SNs are shown
+// hex encoded.
+func (o *OLT) displayAutofind() string {
+	pending := o.ONUs.Autofind("")
+	if len(pending) == 0 {
+		return "  Failure: The automatically found ONTs do not exist"
+	}
+	var b strings.Builder
+	b.WriteString(o.quirks.Color(fmt.Sprintf("%-8s%-12s%-20s%-11s%-16s%s", "F/S/P", "ONT", "SN", "VendorID", "EquipmentID", "Time")) + "\n")
+	b.WriteString(strings.Repeat("-", 86) + "\n")
+	for i, onu := range pending {
+		model := onu.Model
+		if model == "" {
+			model = "-"
+		}
+		fmt.Fprintf(&b, "%-8s%-12d%-20s%-11s%-16s%s\n", onu.Port, i+1, hexSerial(onu.Serial), vendorID(onu.Serial), model,
+			onu.DiscoveredAt.Format("2006-01-02 15:04:05"))
+	}
+	return b.String()
+}
+
+// displayONT formats "display ont <what> <f/s> <port> <id>". The four
+// argument form, "display ont info <f/s/p> <id>", leaves id empty.
+func (o *OLT) displayONT(what, fs, port, id string) string {
+	fsp := fs + "/" + port
+	if id == "" {
+		fsp, id = fs, port
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return unknown()
+	}
+	onu, ok := o.ONUs.Get(fsp, n)
+	if !ok {
+		return "  Failure: The ONT does not exist"
+	}
+	online := !onu.Disabled && !onu.Offline
+
+	var b strings.Builder
+	switch what {
+	case "info":
+		control, run, configState := "active", "online", "normal"
+		if onu.Disabled {
+			control, run, configState = "deactivated", "offline", "deactivated"
+		} else if onu.Offline {
+			run, configState = "offline", "initial"
+		}
+		b.WriteString("  " + strings.Repeat("-", 75) + "\n")
+		for _, kv := range [][2]string{
+			{"F/S/P", onu.Port},
+			{"ONT-ID", id},
+			{"Control flag", control},
+			{"Run state", run},
+			{"Config state", configState},
+			{"Match state", "match"},
+			{"SN", fmt.Sprintf("%s (%s-%s)", hexSerial(onu.Serial), vendorID(onu.Serial), onu.Serial[min(4, len(onu.Serial)):])},
+			{"Description", "nanoncore"},
+			{"ONT distance(m)", strconv.Itoa(onu.DistanceM)},
+			{"Line profile ID", onu.Profile},
+		} {
+			fmt.Fprintf(&b, "  %-24s: %s\n", kv[0], kv[1])
+		}
+		// Only ranging ONTs report an online duration
+		if online {
+			d := time.Since(onu.OnlineSince)
+			fmt.Fprintf(&b, "  %-24s: %d days %02d:%02d:%02d\n", "Online duration",
+				int(d.Hours())/24, int(d.Hours())%24, int(d.Minutes())%60, int(d.Seconds())%60)
+		}
+		b.WriteString("  " + strings.Repeat("-", 75))
+	case "optical-info":
+		if !online {
+			return "  Failure: The ONT is not online"
+		}
+		fmt.Fprintf(&b, "  %-38s: %.2f dBm\n", "Rx optical power", onu.RxPowerDBm)
+		fmt.Fprintf(&b, "  %-38s: %.2f dBm\n", "Tx optical power", onu.TxPowerDBm)
+		fmt.Fprintf(&b, "  %-38s: %.2f dBm\n", "OLT Rx ONT optical power", onu.RxPowerDBm-1.5)
+		fmt.Fprintf(&b, "  %-38s: %.0f C\n", "Temperature", onu.Temperature)
+		fmt.Fprintf(&b, "  %-38s: %.3f V\n", "Voltage", onu.Voltage)
+		fmt.Fprintf(&b, "  %-38s: %.0f mA", "Laser bias current", onu.BiasCurrent)
+	case "traffic":
+		fmt.Fprintf(&b, "  %-20s: %d bytes\n", "Upstream traffic", onu.BytesUp)
+		fmt.Fprintf(&b, "  %-20s: %d bytes", "Downstream traffic", onu.BytesDown)
+	}
+	return b.String()
+}
+
+// mib returns the Huawei ONT tables, indexed by <port index>.<ont id>
+// with the port index frame<<16 | slot<<8 | port
+func (o *OLT) mib() []gosnmp.SnmpPDU {
+	const (
+		sysDescr       = "1.3.6.1.2.1.1.1.0"
+		sysUpTime      = "1.3.6.1.2.1.1.3.0"
+		sysName        = "1.3.6.1.2.1.1.5.0"
+		ontSerial      = "1.3.6.1.4.1.2011.6.128.1.1.2.43.1.3"
+		ontDistance    = "1.3.6.1.4.1.2011.6.128.1.1.2.43.1.12"
+		ontTemperature = "1.3.6.1.4.1.2011.6.128.1.1.2.51.1.1"
+		ontCurrent     = "1.3.6.1.4.1.2011.6.128.1.1.2.51.1.2"
+		ontTxPower     = "1.3.6.1.4.1.2011.6.128.1.1.2.51.1.3"
+		ontRxPower     = "1.3.6.1.4.1.2011.6.128.1.1.2.51.1.4"
+		ontVoltage     = "1.3.6.1.4.1.2011.6.128.1.1.2.51.1.5"
+		ontUpBytes     = "1.3.6.1.4.1.2011.6.128.1.1.4.23.1.3"
+		ontDownBytes   = "1.3.6.1.4.1.2011.6.128.1.1.4.23.1.4"
+
+		// invalid is reported for the optical values of offline ONTs
+		invalid = 2147483647
+	)
+	pdus := []gosnmp.SnmpPDU{
+		{Name: sysDescr, Type: gosnmp.OctetString, Value: []byte("Huawei Integrated Access Software MA5800 V100R019C10")},
+		{Name: sysUpTime, Type: gosnmp.TimeTicks, Value: uint32(time.Since(o.started) / (10 * time.Millisecond))},
+		{Name: sysName, Type: gosnmp.OctetString, Value: []byte(o.config.Hostname)},
+	}
+	for _, onu := range o.ONUs.List("") {
+		var frame, slot, port int
+		if _, err := fmt.Sscanf(onu.Port, "%d/%d/%d", &frame, &slot, &port); err != nil {
+			continue
+		}
+		index := fmt.Sprintf(".%d.%d", frame<<16|slot<<8|port, onu.ID)
+		rx, tx, temp, volt, bias := invalid, invalid, invalid, invalid, invalid
+		if !onu.Disabled && !onu.Offline {
+			rx = int(onu.RxPowerDBm * 100)
+			tx = int(onu.TxPowerDBm * 100)
+			temp = int(onu.Temperature * 256)
+			volt = int(onu.Voltage * 1000)
+			bias = int(onu.BiasCurrent * 1000)
+		}
+		pdus = append(pdus,
+			gosnmp.SnmpPDU{Name: ontSerial + index, Type: gosnmp.OctetString, Value: []byte(hexSerial(onu.Serial))},
+			gosnmp.SnmpPDU{Name: ontDistance + index, Type: gosnmp.Integer, Value: onu.DistanceM},
+			gosnmp.SnmpPDU{Name: ontTemperature + index, Type: gosnmp.Integer, Value: temp},
+			gosnmp.SnmpPDU{Name: ontCurrent + index, Type: gosnmp.Integer, Value: bias},
+			gosnmp.SnmpPDU{Name: ontTxPower + index, Type: gosnmp.Integer, Value: tx},
+			gosnmp.SnmpPDU{Name: ontRxPower + index, Type: gosnmp.Integer, Value: rx},
+			gosnmp.SnmpPDU{Name: ontVoltage + index, Type: gosnmp.Integer, Value: volt},
+			gosnmp.SnmpPDU{Name: ontUpBytes + index, Type: gosnmp.Counter64, Value: onu.BytesUp},
+			gosnmp.SnmpPDU{Name: ontDownBytes + index, Type: gosnmp.Counter64, Value: onu.BytesDown},
+		)
+	}
+	return pdus
+}
+
+// hexSerial encodes the vendor ID of a serial such as "HWTC0011D168" in
+// hex, as Huawei displays it: "485754430011D168"
+func hexSerial(serial string) string {
+	if len(serial) < 4 {
+		return serial
+	}
+	return strings.ToUpper(hex.EncodeToString([]byte(serial[:4]))) + serial[4:]
+}
+
+// decodeSerial converts a hex-encoded serial back to its vendor ID form.
+// Serials already in that form are returned unchanged.
+func decodeSerial(serial string) string {
+	if len(serial) != 16 {
+		return serial
+	}
+	vendor, err := hex.DecodeString(serial[:8])
+	if err != nil {
+		return serial
+	}
+	return string(vendor) + serial[8:]
+}
+
+func vendorID(serial string) string {
+	return serial[:min(4, len(serial))]
+}
+
+func fspName(frame, slot, port int) string {
+	return fmt.Sprintf("%d/%d/%d", frame, slot, port)
+}
+
+// valueAfter returns the argument following key in args
+func valueAfter(args []string, key string) string {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == key {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func unknown() string {
+	return "                  ^\n  % Unknown command, the error locates at '^'"
+}
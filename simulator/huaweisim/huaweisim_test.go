@@ -0,0 +1,253 @@
+package huaweisim
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/drivers/cli"
+	"github.com/nanoncore/nano-southbound/simulator"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/huawei"
+)
+
+func startOLT(t *testing.T, config Config) *OLT {
+	t.Helper()
+	olt, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := olt.Start("127.0.0.1:0", "127.0.0.1:0"); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { olt.Close() })
+	return olt
+}
+
+// connect connects the Huawei adapter to olt over CLI, with SNMP as
+// secondary
+func connect(t *testing.T, olt *OLT) *huawei.Adapter {
+	t.Helper()
+	return connectConfig(t, equipmentConfig(olt))
+}
+
+// equipmentConfig returns the equipment config of olt
+func equipmentConfig(olt *OLT) *types.EquipmentConfig {
+	return &types.EquipmentConfig{
+		Name:          "sim",
+		Vendor:        types.VendorHuawei,
+		Address:       "127.0.0.1",
+		Port:          olt.SSHAddr().(*net.TCPAddr).Port,
+		SecondaryPort: olt.SNMPAddr().(*net.UDPAddr).Port,
+		Protocol:      types.ProtocolCLI,
+		Username:      DefaultUsername,
+		Password:      DefaultPassword,
+		Timeout:       5 * time.Second,
+	}
+}
+
+// connectConfig connects the Huawei adapter with config
+func connectConfig(t *testing.T, config *types.EquipmentConfig) *huawei.Adapter {
+	t.Helper()
+	base, err := cli.NewDriver(config)
+	if err != nil {
+		t.Fatalf("cli.NewDriver() error = %v", err)
+	}
+	driver := huawei.NewAdapter(base, config)
+	if err := driver.Connect(context.Background(), config); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { driver.Disconnect(context.Background()) })
+	return driver.(*huawei.Adapter)
+}
+
+func TestAdapterAgainstSimulator(t *testing.T) {
+	olt := startOLT(t, Config{})
+	olt.ONUs.Discover(simulator.ONU{Port: "0/1/0", Serial: "HWTC0011D168", Model: "HG8245Q2", RxPowerDBm: -18.25})
+	olt.ONUs.Discover(simulator.ONU{Port: "0/1/3", Serial: "HWTC0011D169", Model: "EG8145V5"})
+
+	ctx := context.Background()
+	driver := connect(t, olt)
+
+	found, err := driver.DiscoverONUs(ctx, []string{"0/1/0"})
+	if err != nil {
+		t.Fatalf("DiscoverONUs() error = %v", err)
+	}
+	// The autofind list shows the vendor ID of the SN hex encoded, which
+	// DiscoverONUs decodes
+	if len(found) != 1 || found[0].Serial != "HWTC0011D168" || found[0].Vendor != "HWTC" || found[0].Model != "HG8245Q2" {
+		t.Fatalf("DiscoverONUs() = %+v", found)
+	}
+
+	subscriber := testutil.NewTestSubscriber(found[0].Serial, "0/1/0", 200)
+	subscriber.Annotations["nano.io/onu-id"] = "7"
+	if _, err := driver.CreateSubscriber(ctx, subscriber, testutil.NewTestServiceTier(0, 0)); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	onu, ok := olt.ONUs.Get("0/1/0", 7)
+	if !ok || onu.Serial != "HWTC0011D168" || onu.VLAN != 200 {
+		t.Fatalf("provisioned ONT = %+v, %v", onu, ok)
+	}
+	if sps := olt.ServicePorts(); len(sps) != 1 || sps[0].VLAN != 200 || sps[0].ONTID != 7 {
+		t.Fatalf("service-ports = %+v", sps)
+	}
+
+	onus, err := driver.GetONUList(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetONUList() error = %v", err)
+	}
+	if len(onus) != 1 {
+		t.Fatalf("GetONUList() returned %d ONUs, want 1", len(onus))
+	}
+	if got := onus[0]; got.PONPort != "0/1/0" || got.ONUID != 7 || got.Serial != "HWTC0011D168" || !got.IsOnline || got.RxPowerDBm != -18.25 {
+		t.Errorf("GetONUList()[0] = %+v", got)
+	}
+
+	if err := driver.SuspendSubscriber(ctx, "ont-0/1/0-7"); err != nil {
+		t.Fatalf("SuspendSubscriber() error = %v", err)
+	}
+	status, err := driver.GetSubscriberStatus(ctx, "ont-0/1/0-7")
+	if err != nil {
+		t.Fatalf("GetSubscriberStatus() error = %v", err)
+	}
+	if status.State != "suspended" {
+		t.Errorf("GetSubscriberStatus() state = %q, want suspended", status.State)
+	}
+
+	if err := driver.DeleteSubscriber(ctx, "ont-0/1/0-7"); err != nil {
+		t.Fatalf("DeleteSubscriber() error = %v", err)
+	}
+	if _, ok := olt.ONUs.Get("0/1/0", 7); ok {
+		t.Error("ONT not deleted")
+	}
+	if sps := olt.ServicePorts(); len(sps) != 0 {
+		t.Errorf("service-ports after delete = %+v", sps)
+	}
+}
+
+func TestSilentFailure(t *testing.T) {
+	olt := startOLT(t, Config{Quirks: &simulator.Quirks{SilentFailures: []string{"service-port"}}})
+
+	ctx := context.Background()
+	driver := connect(t, olt)
+	subscriber := testutil.NewTestSubscriber("HWTC0011D168", "0/1/0", 200)
+	subscriber.Annotations["nano.io/onu-id"] = "7"
+
+	// The service-port is accepted without an error but never created
+	if _, err := driver.CreateSubscriber(ctx, subscriber, testutil.NewTestServiceTier(0, 0)); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	if _, ok := olt.ONUs.Get("0/1/0", 7); !ok {
+		t.Fatal("ONT not created")
+	}
+	if sps := olt.ServicePorts(); len(sps) != 0 {
+		t.Fatalf("service-ports = %+v, want none", sps)
+	}
+}
+
+func TestBulkProvisionAgainstSimulator(t *testing.T) {
+	olt := startOLT(t, Config{})
+	ctx := context.Background()
+	driver := connect(t, olt)
+
+	result, err := driver.BulkProvision(ctx, []types.BulkProvisionOp{
+		{Serial: "HWTC0011D168", PONPort: "0/1/0", ONUID: 3, Profile: &types.ONUProfile{VLAN: 100}},
+		{Serial: "485754430011D169", PONPort: "0/1/0", ONUID: 4, Profile: &types.ONUProfile{VLAN: 200}},
+		{Serial: "HWTC0011D168", PONPort: "0/1/0", ONUID: 5},
+	})
+	if err != nil {
+		t.Fatalf("BulkProvision() error = %v", err)
+	}
+	if result.Succeeded != 2 || result.Failed != 1 {
+		t.Fatalf("BulkProvision() = %+v", result)
+	}
+	if r := result.Results[2]; r.Success || r.ErrorCode != types.ErrCodeONUExists {
+		t.Errorf("duplicate serial result = %+v", r)
+	}
+	onu, ok := olt.ONUs.Get("0/1/0", 4)
+	if !ok || onu.Serial != "HWTC0011D169" || onu.VLAN != 200 {
+		t.Errorf("provisioned ONT = %+v, %v", onu, ok)
+	}
+	if sps := olt.ServicePorts(); len(sps) != 2 {
+		t.Errorf("service-ports = %+v", sps)
+	}
+}
+
+func TestServicePortsAgainstSimulator(t *testing.T) {
+	// Page the listing, which the driver has to advance
+	olt := startOLT(t, Config{Quirks: &simulator.Quirks{PageLines: 4}})
+	ctx := context.Background()
+	config := equipmentConfig(olt)
+	config.Metadata = map[string]string{"disable_pager": "false"}
+	driver := connectConfig(t, config)
+	for id, serial := range []string{"HWTC0011D168", "HWTC0011D169", "HWTC0011D170"} {
+		if err := olt.ONUs.Add(simulator.ONU{Port: "0/1/2", ID: id, Serial: serial}); err != nil {
+			t.Fatal(err)
+		}
+		if err := driver.AddServicePort(ctx, &types.AddServicePortRequest{VLAN: 100 + id, PONPort: "0/1/2", ONTID: id, UserVLAN: 10}); err != nil {
+			t.Fatalf("AddServicePort() error = %v", err)
+		}
+	}
+
+	err := driver.AddServicePort(ctx, &types.AddServicePortRequest{VLAN: 100, PONPort: "0/1/2", ONTID: 9})
+	var he *types.HumanError
+	if !errors.As(err, &he) || he.Code != types.ErrCodeONUNotFound {
+		t.Errorf("AddServicePort(unknown ONT) error = %v", err)
+	}
+
+	sps, err := driver.ListServicePorts(ctx)
+	if err != nil {
+		t.Fatalf("ListServicePorts() error = %v", err)
+	}
+	if len(sps) != 3 {
+		t.Fatalf("ListServicePorts() = %+v", sps)
+	}
+	if sp := sps[2]; sp.Index != 2 || sp.VLAN != 102 || sp.Interface != "0/1/2" || sp.ONTID != 2 || sp.GemPort != 1 || sp.UserVLAN != 10 {
+		t.Errorf("service-port = %+v", sp)
+	}
+
+	if err := driver.DeleteServicePort(ctx, "0/1/2", 1); err != nil {
+		t.Fatalf("DeleteServicePort() error = %v", err)
+	}
+	if sps := olt.ServicePorts(); len(sps) != 2 {
+		t.Errorf("service-ports = %+v", sps)
+	}
+	err = driver.DeleteServicePort(ctx, "0/1/2", 1)
+	if !errors.As(err, &he) || he.Code != types.ErrCodeServicePortNotFound {
+		t.Errorf("DeleteServicePort(again) error = %v", err)
+	}
+}
+
+func TestSetPortStateAgainstSimulator(t *testing.T) {
+	olt := startOLT(t, Config{Ports: 4})
+	ctx := context.Background()
+	driver := connect(t, olt)
+
+	if err := driver.SetPortState(ctx, "0/1/2", false); err != nil {
+		t.Fatalf("SetPortState(false) error = %v", err)
+	}
+	if !olt.PortShutdown("0/1/2") {
+		t.Fatal("port 0/1/2 not shut down")
+	}
+	ports, err := driver.ListPorts(ctx)
+	if err != nil {
+		t.Fatalf("ListPorts() error = %v", err)
+	}
+	if len(ports) != 4 || ports[2].Port != "0/1/2" || ports[2].AdminState != "disabled" || ports[0].AdminState != "enabled" {
+		t.Errorf("ListPorts() = %+v", ports)
+	}
+
+	if err := driver.SetPortState(ctx, "0/1/2", true); err != nil {
+		t.Fatalf("SetPortState(true) error = %v", err)
+	}
+	if olt.PortShutdown("0/1/2") {
+		t.Error("port 0/1/2 still shut down")
+	}
+	var he *types.HumanError
+	if err := driver.SetPortState(ctx, "0/1/9", false); !errors.As(err, &he) || he.Code != types.ErrCodePortNotFound {
+		t.Errorf("SetPortState(unknown port) error = %v", err)
+	}
+}
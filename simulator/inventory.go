@@ -0,0 +1,229 @@
+package simulator
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ONU is a simulated ONU. Port is the PON port in the format of the
+// vendor, e.g. "0/1" for V-SOL or "0/1/0" for Huawei.
+type ONU struct {
+	Port    string
+	ID      int
+	Serial  string
+	Model   string
+	Profile string
+	VLAN    int
+
+	// Disabled ONUs are provisioned but deactivated
+	Disabled bool
+
+	// Offline ONUs are provisioned but not ranging
+	Offline bool
+
+	RxPowerDBm  float64
+	TxPowerDBm  float64
+	DistanceM   int
+	Temperature float64
+	Voltage     float64
+	BiasCurrent float64
+	BytesUp     uint64
+	BytesDown   uint64
+
+	// DiscoveredAt is when the ONU appeared in the autofind list
+	DiscoveredAt time.Time
+
+	// OnlineSince is when the ONU last came online. Add sets it if zero.
+	OnlineSince time.Time
+}
+
+// Inventory is the ONU state of a simulated OLT: the ONUs waiting in the
+// autofind list and the provisioned ones. It is safe for concurrent use.
+type Inventory struct {
+	mu          sync.Mutex
+	pending     []ONU
+	provisioned map[string]map[int]ONU
+}
+
+// NewInventory creates an empty inventory
+func NewInventory() *Inventory {
+	return &Inventory{provisioned: make(map[string]map[int]ONU)}
+}
+
+// Discover adds an unprovisioned ONU to the autofind list. Optical values
+// left zero get typical defaults.
+func (inv *Inventory) Discover(onu ONU) {
+	onu = withDefaults(onu)
+	if onu.DiscoveredAt.IsZero() {
+		onu.DiscoveredAt = time.Now()
+	}
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	for i, p := range inv.pending {
+		if p.Serial == onu.Serial {
+			inv.pending[i] = onu
+			return
+		}
+	}
+	inv.pending = append(inv.pending, onu)
+}
+
+// Autofind returns the unprovisioned ONUs on port, or on all ports if port
+// is empty
+func (inv *Inventory) Autofind(port string) []ONU {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	var onus []ONU
+	for _, p := range inv.pending {
+		if port == "" || p.Port == port {
+			onus = append(onus, p)
+		}
+	}
+	return onus
+}
+
+// Add provisions an ONU. It fails if the port already has an ONU with the
+// same ID or the serial is provisioned anywhere. The ONU leaves the
+// autofind list and inherits the optical values it was discovered with.
+func (inv *Inventory) Add(onu ONU) error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if _, ok := inv.provisioned[onu.Port][onu.ID]; ok {
+		return fmt.Errorf("onu %d already exists on %s", onu.ID, onu.Port)
+	}
+	for _, onus := range inv.provisioned {
+		for _, o := range onus {
+			if onu.Serial != "" && o.Serial == onu.Serial {
+				return fmt.Errorf("serial %s already exists on %s onu %d", onu.Serial, o.Port, o.ID)
+			}
+		}
+	}
+	for i, p := range inv.pending {
+		if p.Serial == onu.Serial {
+			onu = inherit(onu, p)
+			inv.pending = append(inv.pending[:i], inv.pending[i+1:]...)
+			break
+		}
+	}
+	if onu.OnlineSince.IsZero() {
+		onu.OnlineSince = time.Now()
+	}
+	if inv.provisioned[onu.Port] == nil {
+		inv.provisioned[onu.Port] = make(map[int]ONU)
+	}
+	inv.provisioned[onu.Port][onu.ID] = withDefaults(onu)
+	return nil
+}
+
+// NextID returns the lowest free ONU ID on port, starting at 1
+func (inv *Inventory) NextID(port string) int {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	id := 1
+	for {
+		if _, ok := inv.provisioned[port][id]; !ok {
+			return id
+		}
+		id++
+	}
+}
+
+// Get returns the provisioned ONU with id on port
+func (inv *Inventory) Get(port string, id int) (ONU, bool) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	onu, ok := inv.provisioned[port][id]
+	return onu, ok
+}
+
+// Update applies fn to the provisioned ONU with id on port. It reports
+// whether the ONU exists.
+func (inv *Inventory) Update(port string, id int, fn func(*ONU)) bool {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	onu, ok := inv.provisioned[port][id]
+	if !ok {
+		return false
+	}
+	fn(&onu)
+	inv.provisioned[port][id] = onu
+	return true
+}
+
+// Delete removes the provisioned ONU with id on port. It reports whether
+// the ONU existed.
+func (inv *Inventory) Delete(port string, id int) bool {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if _, ok := inv.provisioned[port][id]; !ok {
+		return false
+	}
+	delete(inv.provisioned[port], id)
+	return true
+}
+
+// List returns the provisioned ONUs on port, or on all ports if port is
+// empty, sorted by port and ID
+func (inv *Inventory) List(port string) []ONU {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	var onus []ONU
+	for p, byID := range inv.provisioned {
+		if port != "" && p != port {
+			continue
+		}
+		for _, onu := range byID {
+			onus = append(onus, onu)
+		}
+	}
+	sort.Slice(onus, func(i, j int) bool {
+		if onus[i].Port != onus[j].Port {
+			return onus[i].Port < onus[j].Port
+		}
+		return onus[i].ID < onus[j].ID
+	})
+	return onus
+}
+
+// withDefaults fills in typical optical values
+func withDefaults(onu ONU) ONU {
+	if onu.RxPowerDBm == 0 {
+		onu.RxPowerDBm = -19.5
+	}
+	if onu.TxPowerDBm == 0 {
+		onu.TxPowerDBm = 2.3
+	}
+	if onu.DistanceM == 0 {
+		onu.DistanceM = 1200
+	}
+	if onu.Temperature == 0 {
+		onu.Temperature = 45
+	}
+	if onu.Voltage == 0 {
+		onu.Voltage = 3.3
+	}
+	if onu.BiasCurrent == 0 {
+		onu.BiasCurrent = 12
+	}
+	return onu
+}
+
+// inherit copies the values an ONU was discovered with into a provisioned
+// ONU where it has none
+func inherit(onu, discovered ONU) ONU {
+	if onu.Model == "" {
+		onu.Model = discovered.Model
+	}
+	if onu.RxPowerDBm == 0 {
+		onu.RxPowerDBm = discovered.RxPowerDBm
+	}
+	if onu.TxPowerDBm == 0 {
+		onu.TxPowerDBm = discovered.TxPowerDBm
+	}
+	if onu.DistanceM == 0 {
+		onu.DistanceM = discovered.DistanceM
+	}
+	return onu
+}
@@ -0,0 +1,60 @@
+package simulator
+
+import "testing"
+
+func TestInventory(t *testing.T) {
+	inv := NewInventory()
+	inv.Discover(ONU{Port: "0/1", Serial: "ABCD00000001", Model: "X1", RxPowerDBm: -25})
+	inv.Discover(ONU{Port: "0/2", Serial: "ABCD00000002"})
+
+	if got := inv.Autofind("0/1"); len(got) != 1 || got[0].TxPowerDBm == 0 || got[0].DiscoveredAt.IsZero() {
+		t.Fatalf("Autofind(0/1) = %+v", got)
+	}
+	if id := inv.NextID("0/1"); id != 1 {
+		t.Fatalf("NextID() = %d, want 1", id)
+	}
+
+	if err := inv.Add(ONU{Port: "0/1", ID: 1, Serial: "ABCD00000001"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	onu, ok := inv.Get("0/1", 1)
+	if !ok || onu.Model != "X1" || onu.RxPowerDBm != -25 {
+		t.Fatalf("Get() = %+v, %v; want the discovered model and optics", onu, ok)
+	}
+	if len(inv.Autofind("")) != 1 {
+		t.Errorf("provisioned ONU still in the autofind list")
+	}
+	if id := inv.NextID("0/1"); id != 2 {
+		t.Errorf("NextID() = %d, want 2", id)
+	}
+
+	for name, dup := range map[string]ONU{
+		"id":     {Port: "0/1", ID: 1, Serial: "ABCD00000009"},
+		"serial": {Port: "0/3", ID: 1, Serial: "ABCD00000001"},
+	} {
+		if err := inv.Add(dup); err == nil {
+			t.Errorf("Add() with duplicate %s succeeded", name)
+		}
+	}
+
+	if !inv.Update("0/1", 1, func(onu *ONU) { onu.VLAN = 100 }) {
+		t.Fatal("Update() = false")
+	}
+	if onu, _ := inv.Get("0/1", 1); onu.VLAN != 100 {
+		t.Errorf("VLAN = %d, want 100", onu.VLAN)
+	}
+	if inv.Update("0/1", 2, func(*ONU) {}) {
+		t.Error("Update() of a missing ONU = true")
+	}
+
+	if err := inv.Add(ONU{Port: "0/0", ID: 3, Serial: "ABCD00000003"}); err != nil {
+		t.Fatal(err)
+	}
+	list := inv.List("")
+	if len(list) != 2 || list[0].Port != "0/0" || list[1].Port != "0/1" {
+		t.Errorf("List() = %+v, want sorted by port", list)
+	}
+	if !inv.Delete("0/1", 1) || inv.Delete("0/1", 1) {
+		t.Error("Delete() should succeed once")
+	}
+}
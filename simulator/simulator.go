@@ -0,0 +1,48 @@
+// Package simulator provides the building blocks of the vendor OLT
+// simulators in its subpackages: an SSH CLI server, an SNMP agent and an
+// ONU inventory. The simulators reproduce the output formats the vendor
+// adapters parse, along with firmware quirks such as ANSI colour codes,
+// "--More--" pagination and commands that fail silently, so adapters can
+// be exercised in CI and integrations tested without hardware.
+//
+// See vsolsim, huaweisim and cdatasim for the simulated devices, and
+// cmd/olt-sim for a standalone simulator.
+package simulator
+
+import (
+	"strings"
+)
+
+// Quirks are firmware behaviours a simulator reproduces. The vendor
+// simulators enable the quirks of their device by default.
+type Quirks struct {
+	// ANSI wraps table headers in colour escape codes
+	ANSI bool
+
+	// PageLines paginates output longer than this many lines until the
+	// session disables paging. Zero disables paging.
+	PageLines int
+
+	// SilentFailures are command prefixes that print their normal output
+	// but leave the device unchanged, like a commit that fails without an
+	// error
+	SilentFailures []string
+}
+
+// Silent reports whether command matches one of q.SilentFailures
+func (q Quirks) Silent(command string) bool {
+	for _, p := range q.SilentFailures {
+		if strings.HasPrefix(command, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Color wraps s in a bold colour escape code if ANSI is enabled
+func (q Quirks) Color(s string) string {
+	if !q.ANSI {
+		return s
+	}
+	return "\x1b[1;32m" + s + "\x1b[0m"
+}
@@ -0,0 +1,254 @@
+package simulator
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// MIB returns the variables an SNMPAgent serves. It is called for every
+// request so the values follow the simulated device state; the variables
+// need not be sorted.
+type MIB func() []gosnmp.SnmpPDU
+
+// SNMPAgent is a read-only SNMP v1/v2c agent serving Get, GetNext and
+// GetBulk requests over UDP
+type SNMPAgent struct {
+	community string
+	mib       MIB
+
+	mu   sync.Mutex
+	conn net.PacketConn
+	done chan struct{}
+}
+
+// NewSNMPAgent creates an agent that answers requests with community. An
+// empty community accepts any. Call Start to serve requests.
+func NewSNMPAgent(community string, mib MIB) *SNMPAgent {
+	return &SNMPAgent{community: community, mib: mib}
+}
+
+// Start listens on addr, e.g. "127.0.0.1:0", and serves requests in the
+// background until Close
+func (a *SNMPAgent) Start(addr string) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	a.mu.Lock()
+	a.conn = conn
+	a.done = make(chan struct{})
+	a.mu.Unlock()
+
+	go func() {
+		defer close(a.done)
+		buf := make([]byte, 65535)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			resp, err := a.handle(buf[:n])
+			if err != nil || resp == nil {
+				continue
+			}
+			conn.WriteTo(resp, from) //nolint:errcheck // client retries
+		}
+	}()
+	return nil
+}
+
+// Addr returns the listening address, or nil before Start
+func (a *SNMPAgent) Addr() net.Addr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.conn == nil {
+		return nil
+	}
+	return a.conn.LocalAddr()
+}
+
+// Close stops the agent
+func (a *SNMPAgent) Close() error {
+	a.mu.Lock()
+	conn, done := a.conn, a.done
+	a.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+	err := conn.Close()
+	<-done
+	return err
+}
+
+// handle decodes a request and returns the encoded response, or nil if the
+// request is dropped
+func (a *SNMPAgent) handle(req []byte) ([]byte, error) {
+	packet, err := (&gosnmp.GoSNMP{}).SnmpDecodePacket(req)
+	if err != nil {
+		return nil, err
+	}
+	if packet.Version == gosnmp.Version3 {
+		return nil, fmt.Errorf("SNMPv3 is not supported")
+	}
+	// Agents silently drop requests with a wrong community
+	if a.community != "" && packet.Community != a.community {
+		return nil, nil
+	}
+
+	resp := &gosnmp.SnmpPacket{
+		Version:   packet.Version,
+		Community: packet.Community,
+		PDUType:   gosnmp.GetResponse,
+		RequestID: packet.RequestID,
+	}
+	table := newOIDTable(a.mib())
+	switch packet.PDUType {
+	case gosnmp.GetRequest:
+		for i, v := range packet.Variables {
+			pdu, ok := table.get(v.Name)
+			if !ok {
+				if packet.Version == gosnmp.Version1 {
+					return errorResponse(resp, packet, gosnmp.NoSuchName, i)
+				}
+				pdu = gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.NoSuchObject}
+			}
+			resp.Variables = append(resp.Variables, pdu)
+		}
+	case gosnmp.GetNextRequest:
+		for i, v := range packet.Variables {
+			pdu, ok := table.next(v.Name)
+			if !ok {
+				if packet.Version == gosnmp.Version1 {
+					return errorResponse(resp, packet, gosnmp.NoSuchName, i)
+				}
+				pdu = gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.EndOfMibView}
+			}
+			resp.Variables = append(resp.Variables, pdu)
+		}
+	case gosnmp.GetBulkRequest:
+		nonRepeaters := int(packet.NonRepeaters)
+		maxRepetitions := int(packet.MaxRepetitions)
+		if maxRepetitions <= 0 {
+			maxRepetitions = 10
+		}
+		for i, v := range packet.Variables {
+			name := v.Name
+			reps := maxRepetitions
+			if i < nonRepeaters {
+				reps = 1
+			}
+			for range reps {
+				pdu, ok := table.next(name)
+				if !ok {
+					resp.Variables = append(resp.Variables, gosnmp.SnmpPDU{Name: name, Type: gosnmp.EndOfMibView})
+					break
+				}
+				resp.Variables = append(resp.Variables, pdu)
+				name = pdu.Name
+			}
+		}
+	default:
+		return errorResponse(resp, packet, gosnmp.GenErr, 0)
+	}
+	return resp.MarshalMsg()
+}
+
+// errorResponse returns resp with an error status for the variable at
+// index, echoing the request variables
+func errorResponse(resp, req *gosnmp.SnmpPacket, status gosnmp.SNMPError, index int) ([]byte, error) {
+	resp.Error = status
+	resp.ErrorIndex = uint8(index + 1)
+	for _, v := range req.Variables {
+		resp.Variables = append(resp.Variables, gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.Null})
+	}
+	return resp.MarshalMsg()
+}
+
+// oidTable is a MIB sorted in lexicographic OID order
+type oidTable struct {
+	pdus []gosnmp.SnmpPDU
+	oids [][]uint64
+}
+
+func newOIDTable(pdus []gosnmp.SnmpPDU) *oidTable {
+	t := &oidTable{}
+	for _, pdu := range pdus {
+		oid, err := parseOID(pdu.Name)
+		if err != nil {
+			continue
+		}
+		pdu.Name = "." + strings.TrimPrefix(pdu.Name, ".")
+		t.pdus = append(t.pdus, pdu)
+		t.oids = append(t.oids, oid)
+	}
+	sort.Sort(t)
+	return t
+}
+
+func (t *oidTable) Len() int           { return len(t.pdus) }
+func (t *oidTable) Less(i, j int) bool { return compareOID(t.oids[i], t.oids[j]) < 0 }
+func (t *oidTable) Swap(i, j int) {
+	t.pdus[i], t.pdus[j] = t.pdus[j], t.pdus[i]
+	t.oids[i], t.oids[j] = t.oids[j], t.oids[i]
+}
+
+// get returns the variable named name
+func (t *oidTable) get(name string) (gosnmp.SnmpPDU, bool) {
+	oid, err := parseOID(name)
+	if err != nil {
+		return gosnmp.SnmpPDU{}, false
+	}
+	i := sort.Search(len(t.oids), func(i int) bool { return compareOID(t.oids[i], oid) >= 0 })
+	if i < len(t.oids) && compareOID(t.oids[i], oid) == 0 {
+		return t.pdus[i], true
+	}
+	return gosnmp.SnmpPDU{}, false
+}
+
+// next returns the first variable after name
+func (t *oidTable) next(name string) (gosnmp.SnmpPDU, bool) {
+	oid, err := parseOID(name)
+	if err != nil {
+		return gosnmp.SnmpPDU{}, false
+	}
+	i := sort.Search(len(t.oids), func(i int) bool { return compareOID(t.oids[i], oid) > 0 })
+	if i < len(t.oids) {
+		return t.pdus[i], true
+	}
+	return gosnmp.SnmpPDU{}, false
+}
+
+func parseOID(name string) ([]uint64, error) {
+	name = strings.TrimPrefix(name, ".")
+	if name == "" {
+		return nil, nil
+	}
+	parts := strings.Split(name, ".")
+	oid := make([]uint64, len(parts))
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q: %w", name, err)
+		}
+		oid[i] = n
+	}
+	return oid, nil
+}
+
+func compareOID(a, b []uint64) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
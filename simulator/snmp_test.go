@@ -0,0 +1,108 @@
+package simulator
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func testMIB() []gosnmp.SnmpPDU {
+	return []gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("sim")},
+		{Name: ".1.3.6.1.4.1.9.1.10.2", Type: gosnmp.Integer, Value: 102},
+		{Name: "1.3.6.1.4.1.9.1.2.1", Type: gosnmp.Integer, Value: 21},
+		{Name: "1.3.6.1.4.1.9.1.10.1", Type: gosnmp.Integer, Value: 101},
+		{Name: "1.3.6.1.4.1.9.1.9", Type: gosnmp.Counter64, Value: uint64(1) << 40},
+	}
+}
+
+func startAgent(t *testing.T, version gosnmp.SnmpVersion, community string) *gosnmp.GoSNMP {
+	t.Helper()
+	agent := NewSNMPAgent("public", testMIB)
+	if err := agent.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { agent.Close() })
+
+	client := &gosnmp.GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(agent.Addr().(*net.UDPAddr).Port),
+		Community: community,
+		Version:   version,
+		Timeout:   time.Second,
+		Retries:   0,
+		MaxOids:   gosnmp.MaxOids,
+	}
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { client.Conn.Close() })
+	return client
+}
+
+func TestSNMPWalk(t *testing.T) {
+	for _, version := range []gosnmp.SnmpVersion{gosnmp.Version1, gosnmp.Version2c} {
+		client := startAgent(t, version, "public")
+
+		var got []string
+		walk := client.Walk
+		if version == gosnmp.Version2c {
+			walk = client.BulkWalk
+		}
+		err := walk("1.3.6.1.4.1.9.1", func(pdu gosnmp.SnmpPDU) error {
+			got = append(got, pdu.Name)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%v walk error = %v", version, err)
+		}
+		// Sorted numerically, not as strings
+		want := []string{".1.3.6.1.4.1.9.1.2.1", ".1.3.6.1.4.1.9.1.9", ".1.3.6.1.4.1.9.1.10.1", ".1.3.6.1.4.1.9.1.10.2"}
+		if len(got) != len(want) {
+			t.Fatalf("%v walk = %v, want %v", version, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%v walk[%d] = %s, want %s", version, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestSNMPGet(t *testing.T) {
+	client := startAgent(t, gosnmp.Version2c, "public")
+	result, err := client.Get([]string{"1.3.6.1.2.1.1.5.0", "1.3.6.1.4.1.9.1.9", "1.3.6.1.4.1.9.1.3"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	vars := result.Variables
+	if string(vars[0].Value.([]byte)) != "sim" {
+		t.Errorf("sysName = %v", vars[0].Value)
+	}
+	if vars[1].Value != uint64(1)<<40 {
+		t.Errorf("counter = %v", vars[1].Value)
+	}
+	if vars[2].Type != gosnmp.NoSuchObject {
+		t.Errorf("missing OID type = %v, want NoSuchObject", vars[2].Type)
+	}
+}
+
+func TestSNMPGetV1NoSuchName(t *testing.T) {
+	client := startAgent(t, gosnmp.Version1, "public")
+	result, err := client.Get([]string{"1.3.6.1.2.1.1.5.0", "1.3.6.1.4.1.9.1.3"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if result.Error != gosnmp.NoSuchName || result.ErrorIndex != 2 {
+		t.Errorf("Get() error status = %v index %d, want NoSuchName index 2", result.Error, result.ErrorIndex)
+	}
+}
+
+func TestSNMPWrongCommunity(t *testing.T) {
+	client := startAgent(t, gosnmp.Version2c, "private")
+	if _, err := client.Get([]string{"1.3.6.1.2.1.1.5.0"}); err == nil {
+		t.Fatal("Get() with a wrong community succeeded")
+	}
+}
@@ -0,0 +1,427 @@
+// Package vsolsim simulates a V-SOL V1600 series GPON OLT: the CLI with
+// its double login, "--More--" pager and coloured tables, and the ONU
+// tables of its SNMP MIB.
+package vsolsim
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/nanoncore/nano-southbound/simulator"
+)
+
+// Defaults applied by New for zero Config fields
+const (
+	DefaultHostname  = "OLT"
+	DefaultUsername  = "admin"
+	DefaultPassword  = "admin"
+	DefaultCommunity = "public"
+	DefaultPorts     = 8
+
+	// DefaultProfile is listed for ONUs added without a line profile
+	DefaultProfile = "default"
+)
+
+// DefaultQuirks are the firmware quirks simulated when Config.Quirks is
+// nil: coloured table headers and 20-line pages
+var DefaultQuirks = simulator.Quirks{ANSI: true, PageLines: 20}
+
+// Config configures a simulated OLT
+type Config struct {
+	Hostname  string
+	Username  string
+	Password  string
+	Community string
+
+	// Ports is the number of PON ports, named 0/1 to 0/Ports
+	Ports int
+
+	// Quirks defaults to DefaultQuirks
+	Quirks *simulator.Quirks
+}
+
+// OLT is a simulated V-SOL OLT
+type OLT struct {
+	// ONUs is the ONU state. Add ONUs to the autofind list with Discover.
+	ONUs *simulator.Inventory
+
+	config  Config
+	quirks  simulator.Quirks
+	started time.Time
+	cli     *simulator.CLIServer
+	snmp    *simulator.SNMPAgent
+}
+
+// New creates a simulated OLT. Call Start to serve it.
+func New(config Config) (*OLT, error) {
+	if config.Hostname == "" {
+		config.Hostname = DefaultHostname
+	}
+	if config.Username == "" {
+		config.Username = DefaultUsername
+	}
+	if config.Password == "" {
+		config.Password = DefaultPassword
+	}
+	if config.Community == "" {
+		config.Community = DefaultCommunity
+	}
+	if config.Ports <= 0 {
+		config.Ports = DefaultPorts
+	}
+	quirks := DefaultQuirks
+	if config.Quirks != nil {
+		quirks = *config.Quirks
+	}
+
+	o := &OLT{ONUs: simulator.NewInventory(), config: config, quirks: quirks, started: time.Now()}
+	cli, err := simulator.NewCLIServer(simulator.CLIConfig{
+		Username:  config.Username,
+		Password:  config.Password,
+		Login:     true,
+		PageLines: quirks.PageLines,
+		PagerOff:  []string{"terminal length 0"},
+		NewShell:  func() simulator.Shell { return &shell{olt: o} },
+	})
+	if err != nil {
+		return nil, err
+	}
+	o.cli = cli
+	o.snmp = simulator.NewSNMPAgent(config.Community, o.mib)
+	return o, nil
+}
+
+// Start serves the CLI over SSH on sshAddr and, unless snmpAddr is empty,
+// SNMP on snmpAddr
+func (o *OLT) Start(sshAddr, snmpAddr string) error {
+	if err := o.cli.Start(sshAddr); err != nil {
+		return err
+	}
+	if snmpAddr != "" {
+		if err := o.snmp.Start(snmpAddr); err != nil {
+			o.cli.Close() //nolint:errcheck // start failed
+			return err
+		}
+	}
+	return nil
+}
+
+// SSHAddr returns the address of the CLI, or nil before Start
+func (o *OLT) SSHAddr() net.Addr { return o.cli.Addr() }
+
+// SNMPAddr returns the address of the SNMP agent, or nil if it is not
+// started
+func (o *OLT) SNMPAddr() net.Addr { return o.snmp.Addr() }
+
+// Close stops the simulator
+func (o *OLT) Close() error {
+	err := o.snmp.Close()
+	if cerr := o.cli.Close(); cerr != nil {
+		err = cerr
+	}
+	return err
+}
+
+// validPort reports whether port is one of the OLT's PON ports
+func (o *OLT) validPort(port string) bool {
+	n, ok := strings.CutPrefix(port, "0/")
+	if !ok {
+		return false
+	}
+	i, err := strconv.Atoi(n)
+	return err == nil && i >= 1 && i <= o.config.Ports
+}
+
+// shell modes
+const (
+	modeUser = iota
+	modeEnable
+	modeConfig
+	modeInterface
+)
+
+// shell is one CLI session
+type shell struct {
+	olt  *OLT
+	mode int
+	port string
+}
+
+func (s *shell) Prompt() string {
+	host := s.olt.config.Hostname
+	switch s.mode {
+	case modeUser:
+		return host + "> "
+	case modeEnable:
+		return host + "# "
+	case modeConfig:
+		return host + "(config)# "
+	default:
+		return host + "(config-if-gpon-" + s.port + ")# "
+	}
+}
+
+func (s *shell) Exec(line string) (string, bool) {
+	f := strings.Fields(line)
+	switch {
+	case line == "exit" || line == "quit":
+		switch s.mode {
+		case modeInterface:
+			s.mode = modeConfig
+		case modeConfig:
+			s.mode = modeEnable
+		default:
+			return "", true
+		}
+		return "", false
+	case line == "end":
+		if s.mode >= modeConfig {
+			s.mode = modeEnable
+		}
+		return "", false
+	case line == "enable":
+		if s.mode == modeUser {
+			s.mode = modeEnable
+		}
+		return "", false
+	case line == "configure terminal":
+		if s.mode == modeUser {
+			return unknown(), false
+		}
+		s.mode = modeConfig
+		return "", false
+	case line == "show system":
+		return s.olt.showSystem(), false
+	case line == "commit" || line == "write":
+		return "", false
+	case len(f) == 3 && f[0] == "interface" && f[1] == "gpon":
+		if s.mode < modeConfig {
+			return unknown(), false
+		}
+		if !s.olt.validPort(f[2]) {
+			return "Error: Invalid interface " + f[2], false
+		}
+		s.mode, s.port = modeInterface, f[2]
+		return "", false
+	}
+	if s.mode != modeInterface {
+		return unknown(), false
+	}
+	return s.olt.execInterface(s.port, line, f), false
+}
+
+// execInterface runs a command in the GPON interface mode of port
+func (o *OLT) execInterface(port, line string, f []string) string {
+	switch {
+	case line == "show onu auto-find":
+		return o.showAutofind(port)
+	case line == "show onu info all" || line == "show onu info":
+		return o.showONUInfo(port)
+	case line == "show onu state":
+		return o.showONUState(port)
+	case line == "onu confirm":
+		return o.confirm(port, line)
+	case len(f) == 7 && f[0] == "onu" && f[1] == "add" && f[3] == "profile" && f[5] == "sn":
+		id, err := strconv.Atoi(f[2])
+		if err != nil {
+			return unknown()
+		}
+		if o.quirks.Silent(line) {
+			return ""
+		}
+		if err := o.ONUs.Add(simulator.ONU{Port: port, ID: id, Serial: f[6], Profile: f[4]}); err != nil {
+			return "Error: " + err.Error()
+		}
+		return ""
+	case len(f) == 3 && f[0] == "no" && f[1] == "onu":
+		id, err := strconv.Atoi(f[2])
+		if err != nil {
+			return unknown()
+		}
+		if o.quirks.Silent(line) {
+			return ""
+		}
+		if !o.ONUs.Delete(port, id) {
+			return fmt.Sprintf("Error: ONU %d does not exist", id)
+		}
+		return ""
+	case len(f) >= 3 && f[0] == "onu":
+		id, err := strconv.Atoi(f[1])
+		if err != nil {
+			return unknown()
+		}
+		if _, ok := o.ONUs.Get(port, id); !ok {
+			return fmt.Sprintf("Error: ONU %d does not exist", id)
+		}
+		if o.quirks.Silent(line) {
+			return ""
+		}
+		o.ONUs.Update(port, id, func(onu *simulator.ONU) { configureONU(onu, f[2:]) })
+		return ""
+	}
+	return unknown()
+}
+
+// confirm provisions the first ONU in the autofind list of port with the
+// lowest free ID
+func (o *OLT) confirm(port, line string) string {
+	pending := o.ONUs.Autofind(port)
+	if len(pending) == 0 {
+		return "Error: No ONU in auto-find list"
+	}
+	id := o.ONUs.NextID(port)
+	if !o.quirks.Silent(line) {
+		if err := o.ONUs.Add(simulator.ONU{Port: port, ID: id, Serial: pending[0].Serial, Profile: "AN5506-04-F1"}); err != nil {
+			return "Error: " + err.Error()
+		}
+	}
+	return fmt.Sprintf("Register pon %s onu %d OK", strings.TrimPrefix(port, "0/"), id)
+}
+
+// configureONU applies an "onu <id> ..." command to onu
+func configureONU(onu *simulator.ONU, args []string) {
+	switch args[0] {
+	case "activate":
+		onu.Disabled = false
+	case "deactivate":
+		onu.Disabled = true
+	case "profile":
+		if len(args) >= 4 && args[1] == "line" && args[2] == "name" {
+			onu.Profile = args[3]
+		}
+	case "service":
+		// onu <id> service <name> gemport <n> vlan <vlan> ...
+		if v := valueAfter(args, "vlan"); v > 0 {
+			onu.VLAN = v
+		}
+	case "service-port":
+		if v := valueAfter(args, "uservlan"); v > 0 {
+			onu.VLAN = v
+		}
+	}
+}
+
+// valueAfter returns the integer following key in args
+func valueAfter(args []string, key string) int {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == key {
+			n, _ := strconv.Atoi(args[i+1])
+			return n
+		}
+	}
+	return 0
+}
+
+func (o *OLT) showSystem() string {
+	uptime := time.Since(o.started).Round(time.Second)
+	return fmt.Sprintf(`System Name         : %s
+System Description  : V1600G GPON OLT
+OLT Serial Number   : VSOL00SIM0001
+Hardware Version    : V2.0
+Software Version    : V2.1.6R
+Uptime              : %s`, o.config.Hostname, uptime)
+}
+
+// showAutofind formats the autofind list of port. V-SOL reports the
+// index as slot/frame/port:n.
+func (o *OLT) showAutofind(port string) string {
+	pending := o.ONUs.Autofind(port)
+	var b strings.Builder
+	b.WriteString(o.quirks.Color(fmt.Sprintf("%-25s%-25s%s", "OnuIndex", "Sn", "State")) + "\n")
+	b.WriteString(strings.Repeat("-", 57) + "\n")
+	for i, onu := range pending {
+		fmt.Fprintf(&b, "%-25s%-25s%s\n", fmt.Sprintf("1/1/%s:%d", portNumber(port), i+1), onu.Serial, "unknow")
+	}
+	return b.String()
+}
+
+func (o *OLT) showONUInfo(port string) string {
+	var b strings.Builder
+	b.WriteString(o.quirks.Color(fmt.Sprintf("%-11s%-21s%-23s%-8s%s", "Onuindex", "Model", "Profile", "Mode", "AuthInfo")) + "\n")
+	b.WriteString(strings.Repeat("-", 76) + "\n")
+	for _, onu := range o.ONUs.List(port) {
+		model := onu.Model
+		if model == "" {
+			model = "unknown"
+		}
+		profile := onu.Profile
+		if profile == "" {
+			profile = DefaultProfile
+		}
+		fmt.Fprintf(&b, "%-11s%-21s%-23s%-8s%s\n", fmt.Sprintf("GPON%s:%d", onu.Port, onu.ID), model, profile, "sn", onu.Serial)
+	}
+	return b.String()
+}
+
+func (o *OLT) showONUState(port string) string {
+	var b strings.Builder
+	b.WriteString(o.quirks.Color(fmt.Sprintf("%-12s%-15s%-14s%-15s%s", "OnuIndex", "Admin State", "OMCC State", "Phase State", "Channel")) + "\n")
+	b.WriteString(strings.Repeat("-", 63) + "\n")
+	for _, onu := range o.ONUs.List(port) {
+		admin, phase := "enable", "working"
+		if onu.Disabled {
+			admin, phase = "disable", "OffLine"
+		} else if onu.Offline {
+			phase = "OffLine"
+		}
+		fmt.Fprintf(&b, "%-12s%-15s%-14s%-15s%s\n", fmt.Sprintf("1/1/%s:%d", portNumber(port), onu.ID), admin, "enable", phase, "1(GPON)")
+	}
+	return b.String()
+}
+
+// mib returns the V-SOL ONU tables, indexed by <pon>.<onu>
+func (o *OLT) mib() []gosnmp.SnmpPDU {
+	const (
+		sysDescr    = "1.3.6.1.2.1.1.1.0"
+		sysUpTime   = "1.3.6.1.2.1.1.3.0"
+		sysName     = "1.3.6.1.2.1.1.5.0"
+		onuAdmin    = "1.3.6.1.4.1.37950.1.1.6.1.1.2.1.1"
+		onuSerial   = "1.3.6.1.4.1.37950.1.1.6.1.1.2.1.5"
+		onuModel    = "1.3.6.1.4.1.37950.1.1.6.1.1.2.1.6"
+		onuPhase    = "1.3.6.1.4.1.37950.1.1.6.1.1.2.1.10"
+		onuTxPower  = "1.3.6.1.4.1.37950.1.1.6.1.1.3.1.6"
+		onuRxPower  = "1.3.6.1.4.1.37950.1.1.6.1.1.3.1.7"
+		onuDistance = "1.3.6.1.4.1.37950.1.1.6.1.1.3.1.8"
+	)
+	pdus := []gosnmp.SnmpPDU{
+		{Name: sysDescr, Type: gosnmp.OctetString, Value: []byte("V1600G GPON OLT")},
+		{Name: sysUpTime, Type: gosnmp.TimeTicks, Value: uint32(time.Since(o.started) / (10 * time.Millisecond))},
+		{Name: sysName, Type: gosnmp.OctetString, Value: []byte(o.config.Hostname)},
+	}
+	for _, onu := range o.ONUs.List("") {
+		index := "." + portNumber(onu.Port) + "." + strconv.Itoa(onu.ID)
+		admin, phase := 1, "working"
+		if onu.Disabled {
+			admin, phase = 2, "offline"
+		} else if onu.Offline {
+			phase = "offline"
+		}
+		pdus = append(pdus,
+			gosnmp.SnmpPDU{Name: onuAdmin + index, Type: gosnmp.Integer, Value: admin},
+			gosnmp.SnmpPDU{Name: onuSerial + index, Type: gosnmp.OctetString, Value: []byte(onu.Serial)},
+			gosnmp.SnmpPDU{Name: onuModel + index, Type: gosnmp.OctetString, Value: []byte(onu.Model)},
+			gosnmp.SnmpPDU{Name: onuPhase + index, Type: gosnmp.OctetString, Value: []byte(phase)},
+			gosnmp.SnmpPDU{Name: onuDistance + index, Type: gosnmp.Integer, Value: onu.DistanceM},
+		)
+		if phase == "working" {
+			pdus = append(pdus,
+				gosnmp.SnmpPDU{Name: onuTxPower + index, Type: gosnmp.OctetString, Value: []byte(fmt.Sprintf("%.3f(dBm)", onu.TxPowerDBm))},
+				gosnmp.SnmpPDU{Name: onuRxPower + index, Type: gosnmp.OctetString, Value: []byte(fmt.Sprintf("%.3f(dBm)", onu.RxPowerDBm))},
+			)
+		}
+	}
+	return pdus
+}
+
+// portNumber returns the port of a 0/<port> PON port name
+func portNumber(port string) string {
+	return strings.TrimPrefix(port, "0/")
+}
+
+func unknown() string {
+	return "% Unknown command."
+}
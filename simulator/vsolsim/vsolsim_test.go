@@ -0,0 +1,162 @@
+package vsolsim
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/drivers/cli"
+	"github.com/nanoncore/nano-southbound/drivers/snmp"
+	"github.com/nanoncore/nano-southbound/simulator"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/vsol"
+)
+
+func startOLT(t *testing.T, config Config) *OLT {
+	t.Helper()
+	olt, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := olt.Start("127.0.0.1:0", "127.0.0.1:0"); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { olt.Close() })
+	return olt
+}
+
+// connect connects the V-SOL adapter to olt over the primary protocol,
+// with the other one as secondary
+func connect(t *testing.T, olt *OLT, protocol types.Protocol) types.DriverV2 {
+	t.Helper()
+	sshPort := olt.SSHAddr().(*net.TCPAddr).Port
+	snmpPort := olt.SNMPAddr().(*net.UDPAddr).Port
+	config := &types.EquipmentConfig{
+		Name:     "sim",
+		Vendor:   types.VendorVSOL,
+		Address:  "127.0.0.1",
+		Protocol: protocol,
+		Username: DefaultUsername,
+		Password: DefaultPassword,
+		Timeout:  5 * time.Second,
+		Metadata: map[string]string{},
+	}
+	var base types.Driver
+	var err error
+	if protocol == types.ProtocolSNMP {
+		config.Port = snmpPort
+		config.Metadata["cli_port"] = strconv.Itoa(sshPort)
+		base, err = snmp.NewDriver(config)
+	} else {
+		config.Port = sshPort
+		config.SecondaryPort = snmpPort
+		base, err = cli.NewDriver(config)
+	}
+	if err != nil {
+		t.Fatalf("NewDriver() error = %v", err)
+	}
+	driver := vsol.NewAdapter(base, config)
+	if err := driver.Connect(context.Background(), config); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { driver.Disconnect(context.Background()) })
+	return driver.(types.DriverV2)
+}
+
+func TestAdapterAgainstSimulator(t *testing.T) {
+	olt := startOLT(t, Config{})
+	olt.ONUs.Discover(simulator.ONU{Port: "0/1", Serial: "FHTT99990001", Model: "HG6143D"})
+	olt.ONUs.Discover(simulator.ONU{Port: "0/2", Serial: "FHTT99990002"})
+	// Enough ONUs on one port to page the autofind list
+	for i := range 25 {
+		olt.ONUs.Discover(simulator.ONU{Port: "0/3", Serial: "VSOL0000" + string(rune('A'+i)) + "000"})
+	}
+
+	ctx := context.Background()
+	driver := connect(t, olt, types.ProtocolCLI)
+
+	found, err := driver.DiscoverONUs(ctx, []string{"0/1", "0/2", "0/3"})
+	if err != nil {
+		t.Fatalf("DiscoverONUs() error = %v", err)
+	}
+	if len(found) != 27 {
+		t.Fatalf("DiscoverONUs() found %d ONUs, want 27", len(found))
+	}
+	if found[0].PONPort != "0/1" || found[0].Serial != "FHTT99990001" {
+		t.Errorf("DiscoverONUs()[0] = %s %s, want 0/1 FHTT99990001", found[0].PONPort, found[0].Serial)
+	}
+
+	_, err = driver.CreateSubscriber(ctx, testutil.NewTestSubscriber("FHTT99990001", "0/1", 100), testutil.NewTestServiceTier(0, 0))
+	if err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	onu, ok := olt.ONUs.Get("0/1", 1)
+	if !ok || onu.Serial != "FHTT99990001" || onu.VLAN != 100 {
+		t.Fatalf("provisioned ONU = %+v, %v", onu, ok)
+	}
+
+	onus, err := driver.GetONUList(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetONUList() error = %v", err)
+	}
+	if len(onus) != 1 || onus[0].Serial != "FHTT99990001" || onus[0].ONUID != 1 || !onus[0].IsOnline {
+		t.Fatalf("GetONUList() = %+v", onus)
+	}
+
+	if err := driver.SuspendSubscriber(ctx, "onu-0/1-1"); err != nil {
+		t.Fatalf("SuspendSubscriber() error = %v", err)
+	}
+	if onu, _ := olt.ONUs.Get("0/1", 1); !onu.Disabled {
+		t.Error("ONU not deactivated")
+	}
+}
+
+func TestGetONUListSNMP(t *testing.T) {
+	olt := startOLT(t, Config{})
+	if err := olt.ONUs.Add(simulator.ONU{Port: "0/2", ID: 3, Serial: "FHTT00000003", Model: "HG6143D", RxPowerDBm: -21.5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := olt.ONUs.Add(simulator.ONU{Port: "0/2", ID: 4, Serial: "FHTT00000004", Disabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	driver := connect(t, olt, types.ProtocolSNMP)
+	onus, err := driver.GetONUList(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetONUList() error = %v", err)
+	}
+	if len(onus) != 2 {
+		t.Fatalf("GetONUList() returned %d ONUs, want 2", len(onus))
+	}
+	byID := map[int]types.ONUInfo{}
+	for _, onu := range onus {
+		byID[onu.ONUID] = onu
+	}
+	if got := byID[3]; got.PONPort != "0/2" || got.Serial != "FHTT00000003" || !got.IsOnline || got.RxPowerDBm != -21.5 {
+		t.Errorf("ONU 3 = %+v", got)
+	}
+	if got := byID[4]; got.IsOnline || got.AdminState != "disabled" {
+		t.Errorf("ONU 4 = %+v", got)
+	}
+}
+
+func TestSilentFailure(t *testing.T) {
+	olt := startOLT(t, Config{Quirks: &simulator.Quirks{SilentFailures: []string{"onu add"}}})
+
+	ctx := context.Background()
+	driver := connect(t, olt, types.ProtocolCLI)
+	subscriber := testutil.NewTestSubscriber("FHTT99990001", "0/1", 100)
+	subscriber.Annotations["nano.io/onu-id"] = "5"
+
+	// The firmware accepts the command without an error, so the failure
+	// only shows when the ONU is looked for
+	if _, err := driver.CreateSubscriber(ctx, subscriber, testutil.NewTestServiceTier(0, 0)); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	if _, ok := olt.ONUs.Get("0/1", 5); ok {
+		t.Fatal("ONU created despite silent failure")
+	}
+}
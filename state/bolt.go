@@ -0,0 +1,133 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultBoltTimeout is the BoltConfig.Timeout used when it is zero
+const DefaultBoltTimeout = 5 * time.Second
+
+// boltBucket is the bucket holding every key of a BoltStore
+var boltBucket = []byte("state")
+
+// BoltConfig configures a BoltStore
+type BoltConfig struct {
+	// NoSync skips the fsync of every write. Without it a machine crash
+	// may lose the latest writes, but never corrupts older ones.
+	NoSync bool
+
+	// Timeout bounds the wait for another process to release the file.
+	// Defaults to DefaultBoltTimeout.
+	Timeout time.Duration
+}
+
+// BoltStore is an embedded Store kept in a bbolt database file, for a
+// single instance
+type BoltStore struct {
+	db *bolt.DB
+}
+
+var _ Store = (*BoltStore)(nil)
+
+// OpenBoltStore opens the store at path, creating it if needed. A store
+// can only be opened by one process at a time.
+func OpenBoltStore(path string, config BoltConfig) (*BoltStore, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultBoltTimeout
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: config.Timeout, NoSync: config.NoSync})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close() //nolint:errcheck // already failing
+		return nil, fmt.Errorf("failed to open state file: %w", err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+// Get implements Store
+func (s *BoltStore) Get(_ context.Context, key string) ([]byte, error) {
+	var value []byte
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// Cursor rather than Bucket.Get, which cannot tell an empty value
+		// from a missing key
+		k, v := tx.Bucket(boltBucket).Cursor().Seek([]byte(key))
+		if found = k != nil && string(k) == key; found {
+			value = append([]byte{}, v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, boltError("get", key, err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	return value, nil
+}
+
+// Put implements Store
+func (s *BoltStore) Put(_ context.Context, key string, value []byte) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put([]byte(key), value)
+	})
+	return boltError("put", key, err)
+}
+
+// Delete implements Store
+func (s *BoltStore) Delete(_ context.Context, key string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Delete([]byte(key))
+	})
+	return boltError("delete", key, err)
+}
+
+// List implements Store
+func (s *BoltStore) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltBucket).Cursor()
+		for k, _ := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, _ = c.Next() {
+			keys = append(keys, string(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, boltError("list", prefix, err)
+	}
+	return keys, nil
+}
+
+// Close closes the database file. The store cannot be used afterwards.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// boltError wraps an error of operation op on key, mapping a closed
+// database to ErrStoreClosed
+func boltError(op, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, bolt.ErrDatabaseNotOpen) {
+		err = ErrStoreClosed
+	}
+	return fmt.Errorf("failed to %s state %s: %w", op, key, err)
+}
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBoltStore(t *testing.T) {
+	s, err := OpenBoltStore(filepath.Join(t.TempDir(), "state.db"), BoltConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	testStore(t, s)
+}
+
+func TestBoltStoreReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state", "state.db")
+
+	s, err := OpenBoltStore(path, BoltConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"a/1", "a/2", "a/3"} {
+		if err := s.Put(ctx, k, []byte("v-"+k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Put(ctx, "a/1", []byte("updated")) //nolint:errcheck // checked on reopen
+	s.Delete(ctx, "a/2")                 //nolint:errcheck // checked on reopen
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(ctx, "a/4", nil); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("Put() after Close = %v, want ErrStoreClosed", err)
+	}
+	if _, err := s.Get(ctx, "a/1"); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("Get() after Close = %v, want ErrStoreClosed", err)
+	}
+
+	s, err = OpenBoltStore(path, BoltConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if keys, _ := s.List(ctx, "a/"); !slices.Equal(keys, []string{"a/1", "a/3"}) {
+		t.Errorf("List() after reopen = %v", keys)
+	}
+	if v, _ := s.Get(ctx, "a/1"); string(v) != "updated" {
+		t.Errorf("Get() after reopen = %q", v)
+	}
+}
+
+func TestBoltStoreCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	if err := os.WriteFile(path, []byte("garbage\n{\"k\":\"a\"}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenBoltStore(path, BoltConfig{}); err == nil {
+		t.Error("expected error for a corrupt file")
+	}
+}
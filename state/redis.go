@@ -0,0 +1,179 @@
+package state
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Defaults applied by NewRedisStore for zero RedisConfig fields
+const (
+	DefaultRedisAddr        = "localhost:6379"
+	DefaultRedisDialTimeout = 5 * time.Second
+	DefaultRedisTimeout     = 5 * time.Second
+	DefaultRedisPoolSize    = 10
+)
+
+// redisScanCount is the COUNT hint of SCAN calls made by List
+const redisScanCount = 500
+
+// RedisConfig configures a RedisStore
+type RedisConfig struct {
+	// Addr is the host:port of the server. Defaults to DefaultRedisAddr.
+	Addr string
+
+	// Username and Password authenticate with AUTH. Username requires
+	// Redis 6 ACLs.
+	Username string
+	Password string
+
+	// DB is the database selected with SELECT
+	DB int
+
+	// KeyPrefix is prepended to every key, so several deployments can share
+	// a server, e.g. "nano:"
+	KeyPrefix string
+
+	// TLS enables TLS when set
+	TLS *tls.Config
+
+	// DialTimeout defaults to DefaultRedisDialTimeout. Timeout bounds the
+	// reads and writes of each command and defaults to
+	// DefaultRedisTimeout.
+	DialTimeout time.Duration
+	Timeout     time.Duration
+
+	// PoolSize is the maximum number of open connections. Defaults to
+	// DefaultRedisPoolSize.
+	PoolSize int
+}
+
+// RedisStore keeps state in Redis, so several instances can share it
+type RedisStore struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+var _ Store = (*RedisStore)(nil)
+
+// NewRedisStore creates a RedisStore. Connections are opened on first use;
+// call Ping to check the configuration up front.
+func NewRedisStore(config RedisConfig) *RedisStore {
+	if config.Addr == "" {
+		config.Addr = DefaultRedisAddr
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = DefaultRedisDialTimeout
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultRedisTimeout
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = DefaultRedisPoolSize
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:            config.Addr,
+		Username:        config.Username,
+		Password:        config.Password,
+		DB:              config.DB,
+		TLSConfig:       config.TLS,
+		DialTimeout:     config.DialTimeout,
+		ReadTimeout:     config.Timeout,
+		WriteTimeout:    config.Timeout,
+		PoolSize:        config.PoolSize,
+		DisableIdentity: true,
+	})
+	return &RedisStore{client: client, keyPrefix: config.KeyPrefix}
+}
+
+// Ping checks that the server is reachable
+func (s *RedisStore) Ping(ctx context.Context) error {
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to reach redis: %w", redisError(err))
+	}
+	return nil
+}
+
+// Get implements Store
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	v, err := s.client.Get(ctx, s.keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state %s: %w", key, redisError(err))
+	}
+	return v, nil
+}
+
+// Put implements Store
+func (s *RedisStore) Put(ctx context.Context, key string, value []byte) error {
+	if err := s.client.Set(ctx, s.keyPrefix+key, value, 0).Err(); err != nil {
+		return fmt.Errorf("failed to put state %s: %w", key, redisError(err))
+	}
+	return nil
+}
+
+// Delete implements Store
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, s.keyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to delete state %s: %w", key, redisError(err))
+	}
+	return nil
+}
+
+// List implements Store. It uses SCAN, so keys written while it runs may or
+// may not be returned.
+func (s *RedisStore) List(ctx context.Context, prefix string) ([]string, error) {
+	match := redisGlobEscape(s.keyPrefix+prefix) + "*"
+	seen := make(map[string]bool)
+	iter := s.client.Scan(ctx, 0, match, redisScanCount).Iterator()
+	for iter.Next(ctx) {
+		seen[strings.TrimPrefix(iter.Val(), s.keyPrefix)] = true
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list state %s: %w", prefix, redisError(err))
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Close closes the connections. The store cannot be used afterwards.
+func (s *RedisStore) Close() error {
+	if err := s.client.Close(); err != nil && !errors.Is(err, redis.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+// redisError maps the error of a closed client to ErrStoreClosed
+func redisError(err error) error {
+	if errors.Is(err, redis.ErrClosed) {
+		return ErrStoreClosed
+	}
+	return err
+}
+
+// redisGlobEscape escapes the glob metacharacters of s for SCAN MATCH
+func redisGlobEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
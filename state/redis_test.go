@@ -0,0 +1,79 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisStore(t *testing.T) {
+	srv := miniredis.RunT(t)
+	srv.RequireAuth("secret")
+	s := NewRedisStore(RedisConfig{Addr: srv.Addr(), Password: "secret", DB: 2, KeyPrefix: "nano:"})
+	defer s.Close()
+
+	if err := s.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+
+	srv.Select(2)
+	if !srv.Exists("nano:a/2") {
+		t.Errorf("server keys = %v in db 2, want prefixed keys", srv.Keys())
+	}
+}
+
+func TestRedisStoreListPages(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	s := NewRedisStore(RedisConfig{Addr: srv.Addr()})
+	defer s.Close()
+
+	// More keys than one SCAN page
+	for i := range redisScanCount + 10 {
+		if err := s.Put(ctx, fmt.Sprintf("a/%04d", i), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv.Set("b/1", "x") //nolint:errcheck // test setup
+	keys, err := s.List(ctx, "a/")
+	if err != nil || len(keys) != redisScanCount+10 || keys[0] != "a/0000" {
+		t.Errorf("List() = %d keys, %v", len(keys), err)
+	}
+}
+
+func TestRedisStoreErrors(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	srv.RequireAuth("secret")
+
+	s := NewRedisStore(RedisConfig{Addr: srv.Addr(), Password: "wrong"})
+	if err := s.Ping(ctx); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("Ping() with a wrong password = %v", err)
+	}
+	s.Close()
+
+	s = NewRedisStore(RedisConfig{Addr: srv.Addr(), Password: "secret"})
+	srv.SetError("ERR out of memory")
+	if _, err := s.Get(ctx, "k"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() on a failing server = %v", err)
+	}
+	srv.SetError("")
+	if _, err := s.Get(ctx, "k"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after the server recovered = %v, want ErrNotFound", err)
+	}
+	s.Close()
+	if _, err := s.Get(ctx, "k"); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("Get() after Close = %v, want ErrStoreClosed", err)
+	}
+}
+
+func TestRedisGlobEscape(t *testing.T) {
+	if got := redisGlobEscape(`a*b?[c]\`); got != `a\*b\?\[c\]\\` {
+		t.Errorf("redisGlobEscape() = %q", got)
+	}
+}
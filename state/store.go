@@ -0,0 +1,121 @@
+// Package state provides the key-value store shared by subsystems that
+// keep state across restarts, such as ONU lifecycle history.
+//
+// Keys are "/"-separated paths whose first element names the subsystem,
+// e.g. "lifecycle/olt-1/0/1:5", so subsystems can share one store.
+//
+// MemoryStore suits tests, BoltStore is an embedded bbolt store for a
+// single instance and RedisStore lets several instances share state.
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned by Store.Get for unknown keys
+var ErrNotFound = errors.New("state not found")
+
+// ErrStoreClosed is returned by stores used after Close
+var ErrStoreClosed = errors.New("state store closed")
+
+// Store persists values by key. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Get returns the value of key or ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Put stores value under key, replacing any previous value
+	Put(ctx context.Context, key string, value []byte) error
+
+	// Delete removes key. Deleting an unknown key is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// List returns the keys starting with prefix, sorted
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// GetJSON decodes the value of key into v
+func GetJSON(ctx context.Context, s Store, key string, v any) error {
+	data, err := s.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode state %s: %w", key, err)
+	}
+	return nil
+}
+
+// PutJSON stores v under key as JSON
+func PutJSON(ctx context.Context, s Store, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode state %s: %w", key, err)
+	}
+	return s.Put(ctx, key, data)
+}
+
+// MemoryStore keeps state in memory. It is meant for tests and for
+// deployments that do not need state to survive a restart.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	values map[string][]byte
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{values: make(map[string][]byte)}
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.values[key]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	return append([]byte(nil), v...), nil
+}
+
+// Put implements Store
+func (s *MemoryStore) Put(_ context.Context, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = append([]byte(nil), value...)
+	return nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+// List implements Store
+func (s *MemoryStore) List(_ context.Context, prefix string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedKeys(s.values, prefix), nil
+}
+
+func sortedKeys(values map[string][]byte, prefix string) []string {
+	var keys []string
+	for k := range values {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
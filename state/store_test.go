@@ -0,0 +1,71 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+// testStore checks the Store contract on an empty store
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := s.Get(ctx, "a/1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() on empty store = %v, want ErrNotFound", err)
+	}
+	for _, k := range []string{"a/2", "a/1", "b/1"} {
+		if err := s.Put(ctx, k, []byte(k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v, err := s.Get(ctx, "a/1"); err != nil || string(v) != "a/1" {
+		t.Errorf("Get() = %q, %v", v, err)
+	}
+	if keys, _ := s.List(ctx, "a/"); !slices.Equal(keys, []string{"a/1", "a/2"}) {
+		t.Errorf("List() = %v", keys)
+	}
+	if err := s.Delete(ctx, "a/1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Delete() of unknown key = %v", err)
+	}
+	if keys, _ := s.List(ctx, ""); !slices.Equal(keys, []string{"a/2", "b/1"}) {
+		t.Errorf("List() after delete = %v", keys)
+	}
+	if keys, _ := s.List(ctx, "a*"); len(keys) != 0 {
+		t.Errorf("List() with glob characters = %v, want none", keys)
+	}
+	if err := s.Put(ctx, "empty", nil); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := s.Get(ctx, "empty"); err != nil || len(v) != 0 {
+		t.Errorf("Get() of empty value = %q, %v", v, err)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	type rec struct {
+		Name  string
+		Count int
+	}
+
+	if err := PutJSON(ctx, s, "k", rec{"x", 3}); err != nil {
+		t.Fatal(err)
+	}
+	var got rec
+	if err := GetJSON(ctx, s, "k", &got); err != nil || got != (rec{"x", 3}) {
+		t.Errorf("GetJSON() = %+v, %v", got, err)
+	}
+	if err := GetJSON(ctx, s, "missing", &got); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetJSON() of unknown key = %v", err)
+	}
+}
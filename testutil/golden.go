@@ -0,0 +1,174 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// update rewrites golden files from the current parser output
+var update = flag.Bool("update", false, "rewrite golden files with the current parser output")
+
+// GoldenExt is the extension of the expected output of a fixture
+const GoldenExt = ".golden.json"
+
+// GoldenParser parses the raw device output of a fixture. The result is
+// compared to the golden file as JSON, so fields that vary between runs,
+// such as timestamps, must be cleared.
+type GoldenParser func(t *testing.T, input []byte) any
+
+// RunGolden validates parsers against a corpus of captured device output.
+// dir holds a directory per parser, named as in parsers, and each fixture
+// in it is paired with a golden file of the same name plus GoldenExt:
+//
+//	testdata/golden/parseONUState/v1600g-2.1.6.txt
+//	testdata/golden/parseONUState/v1600g-2.1.6.txt.golden.json
+//
+// New firmware output is added by capturing it into a fixture and running
+// the tests with -update to write its golden file, which is then reviewed.
+// Fixture directories without a parser fail the test.
+func RunGolden(t *testing.T, dir string, parsers map[string]GoldenParser) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read golden corpus: %v", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() && parsers[e.Name()] == nil {
+			t.Errorf("golden fixtures in %s have no parser", filepath.Join(dir, e.Name()))
+		}
+	}
+
+	names := make([]string, 0, len(parsers))
+	for name := range parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parse := parsers[name]
+		t.Run(name, func(t *testing.T) {
+			fixtures, err := filepath.Glob(filepath.Join(dir, name, "*"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			n := 0
+			for _, fixture := range fixtures {
+				if strings.HasSuffix(fixture, GoldenExt) {
+					continue
+				}
+				n++
+				t.Run(filepath.Base(fixture), func(t *testing.T) {
+					input, err := os.ReadFile(fixture)
+					if err != nil {
+						t.Fatal(err)
+					}
+					checkGolden(t, fixture+GoldenExt, parse(t, input))
+				})
+			}
+			if n == 0 {
+				t.Errorf("no fixtures in %s", filepath.Join(dir, name))
+			}
+		})
+	}
+}
+
+func checkGolden(t *testing.T, path string, result any) {
+	t.Helper()
+	got, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal parser result: %v", err)
+	}
+	got = append(got, '\n')
+
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("missing golden file %s; run the test with -update to create it", path)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("parser output differs from %s; run with -update if the change is intended\n%s", path, lineDiff(string(want), string(got)))
+	}
+}
+
+// lineDiff lists the lines that differ between want and got
+func lineDiff(want, got string) string {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var b strings.Builder
+	for i := 0; i < len(w) || i < len(g); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			fmt.Fprintf(&b, "line %d:\n  - %s\n  + %s\n", i+1, wl, gl)
+		}
+	}
+	return b.String()
+}
+
+// ParseSNMPWalk parses the output of "snmpwalk -On" into values keyed by
+// numeric OID with a leading dot, typed as the SNMP driver returns them:
+// strings for STRING and Hex-STRING, int64 for INTEGER and uint64 for
+// counters, gauges and timeticks. Hex-STRING values are decoded to their
+// raw bytes, as the driver returns octet strings.
+func ParseSNMPWalk(data []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		oid, rest, ok := strings.Cut(line, " = ")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"OID = TYPE: value\"", i+1)
+		}
+		oid = "." + strings.TrimPrefix(oid, ".")
+		typ, value, _ := strings.Cut(rest, ": ")
+		switch typ {
+		case "STRING":
+			values[oid] = strings.Trim(value, `"`)
+		case "Hex-STRING":
+			raw, err := hex.DecodeString(strings.ReplaceAll(value, " ", ""))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			values[oid] = string(raw)
+		case "INTEGER":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			values[oid] = n
+		case "Counter32", "Counter64", "Gauge32", "Timeticks":
+			// Timeticks: (12345) 0:02:03.45
+			value = strings.Trim(strings.Fields(value + " ")[0], "()")
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			values[oid] = n
+		default:
+			values[oid] = value
+		}
+	}
+	return values, nil
+}
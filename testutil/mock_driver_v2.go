@@ -0,0 +1,1184 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.DriverV2 = (*MockDriverV2)(nil)
+
+// Fault makes calls to a MockDriverV2 fail or stall.
+type Fault struct {
+	// Err is returned by the affected calls.
+	Err error
+
+	// Delay holds each affected call before it returns. A context that
+	// is done first fails the call with its error, as a device timeout
+	// would. A Delay without Err is a slow call that succeeds.
+	Delay time.Duration
+
+	// Hang holds each affected call until its context is done.
+	Hang bool
+
+	// Skip lets that many calls through before the fault applies.
+	Skip int
+
+	// Times limits the fault to that many calls; zero is every call.
+	Times int
+
+	seen int
+}
+
+// MockDriverV2 is an in-memory types.DriverV2 for testing code built on
+// this package. Writes change its state and reads are answered from it,
+// so a test can provision an ONU and then find it in GetONUList. The zero
+// value is an empty, disconnected OLT on which every PON port exists.
+//
+// Subscriber IDs are the subscriber names passed to CreateSubscriber. The
+// PON port of a new subscriber is taken from its primary ONU binding, the
+// "nano.io/pon-port" annotation, or the port it was discovered on.
+type MockDriverV2 struct {
+	mu sync.Mutex
+
+	// Connected tracks connection state.
+	Connected bool
+
+	// OLT is the base of GetOLTStatus; ports and ONU counts are filled in.
+	OLT types.OLTStatus
+
+	// Ports are the PON ports of the OLT. If empty, every port exists.
+	Ports []types.PONPortStatus
+
+	// Discovered are unprovisioned ONUs; provisioning removes them.
+	Discovered []types.ONUDiscovery
+
+	// ONUs are the provisioned ONUs.
+	ONUs []types.ONUInfo
+
+	// VLANs are the configured VLANs.
+	VLANs []types.VLANInfo
+
+	// ServicePorts are the configured service ports.
+	ServicePorts []types.ServicePort
+
+	// Alarms are the active alarms.
+	Alarms []types.OLTAlarm
+
+	// Responses script the result of a method by name, e.g.
+	// Responses["GetOLTStatus"] = &types.OLTStatus{...}, in place of the
+	// answer from the state. The value must have the method's result type;
+	// nil gives the zero value. Methods that return only an error are
+	// scripted with Faults.
+	Responses map[string]any
+
+	// Faults inject failures by recorded call, e.g. "DeleteVLAN:100", or
+	// by method name for every call to it. A call fault takes precedence.
+	// "BulkProvision:<serial>" fails only that operation of a bulk
+	// provision, with the fault's Err.
+	Faults map[string]*Fault
+
+	// Calls records method calls as "Method" or "Method:arg".
+	Calls []string
+
+	subscribers map[string][]model.ONUBinding
+	suspensions map[string]*types.SuspensionState
+}
+
+// inject records a call and applies its fault
+func (m *MockDriverV2) inject(ctx context.Context, name string) error {
+	m.mu.Lock()
+	m.Calls = append(m.Calls, name)
+	f := m.fault(name)
+	if f == nil {
+		if method, _, ok := strings.Cut(name, ":"); ok {
+			f = m.fault(method)
+		}
+	}
+	m.mu.Unlock()
+	if f == nil {
+		return nil
+	}
+
+	if f.Hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if f.Delay > 0 {
+		timer := time.NewTimer(f.Delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return f.Err
+}
+
+// fault returns the fault registered for key if it applies to this call
+func (m *MockDriverV2) fault(key string) *Fault {
+	f := m.Faults[key]
+	if f == nil {
+		return nil
+	}
+	f.seen++
+	if f.seen <= f.Skip || (f.Times > 0 && f.seen > f.Skip+f.Times) {
+		return nil
+	}
+	return f
+}
+
+// call runs a method: it applies faults, then returns the scripted
+// response or the answer of fn, which runs with the state locked
+func call[T any](ctx context.Context, m *MockDriverV2, name string, fn func() (T, error)) (T, error) {
+	var zero T
+	if err := m.inject(ctx, name); err != nil {
+		return zero, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	method, _, _ := strings.Cut(name, ":")
+	if v, ok := m.Responses[method]; ok {
+		if v == nil {
+			return zero, nil
+		}
+		r, ok := v.(T)
+		if !ok {
+			panic(fmt.Sprintf("testutil: response for %s is %T, want %T", method, v, zero))
+		}
+		return r, nil
+	}
+	return fn()
+}
+
+// exec runs a method that returns only an error
+func exec(ctx context.Context, m *MockDriverV2, name string, fn func() error) error {
+	if err := m.inject(ctx, name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fn()
+}
+
+func onuCall(method, ponPort string, onuID int) string {
+	return fmt.Sprintf("%s:%s:%d", method, ponPort, onuID)
+}
+
+// === State helpers; the caller holds mu ===
+
+func (m *MockDriverV2) onu(ponPort string, onuID int) *types.ONUInfo {
+	for i := range m.ONUs {
+		if m.ONUs[i].PONPort == ponPort && m.ONUs[i].ONUID == onuID {
+			return &m.ONUs[i]
+		}
+	}
+	return nil
+}
+
+func (m *MockDriverV2) onuBySerial(serial string) *types.ONUInfo {
+	for i := range m.ONUs {
+		if strings.EqualFold(m.ONUs[i].Serial, serial) {
+			return &m.ONUs[i]
+		}
+	}
+	return nil
+}
+
+func (m *MockDriverV2) findONU(ponPort string, onuID int) (*types.ONUInfo, error) {
+	if onu := m.onu(ponPort, onuID); onu != nil {
+		return onu, nil
+	}
+	return nil, fmt.Errorf("ONU %s:%d not found", ponPort, onuID)
+}
+
+func (m *MockDriverV2) port(name string) (*types.PONPortStatus, error) {
+	for i := range m.Ports {
+		if m.Ports[i].Port == name {
+			return &m.Ports[i], nil
+		}
+	}
+	if len(m.Ports) == 0 {
+		return &types.PONPortStatus{Port: name, AdminState: "enabled", OperState: "up"}, nil
+	}
+	return nil, fmt.Errorf("PON port %s not found", name)
+}
+
+func (m *MockDriverV2) nextID(ponPort string) int {
+	id := 1
+	for m.onu(ponPort, id) != nil {
+		id++
+	}
+	return id
+}
+
+// provision adds an ONU, taking it out of the discovered list
+func (m *MockDriverV2) provision(serial, ponPort string, onuID int) (*types.ONUInfo, error) {
+	if serial == "" {
+		return nil, fmt.Errorf("serial is required")
+	}
+	if m.onuBySerial(serial) != nil {
+		return nil, fmt.Errorf("ONU %s already provisioned", serial)
+	}
+	discovered := slices.IndexFunc(m.Discovered, func(d types.ONUDiscovery) bool {
+		return strings.EqualFold(d.Serial, serial)
+	})
+	if ponPort == "" && discovered >= 0 {
+		ponPort = m.Discovered[discovered].PONPort
+	}
+	if ponPort == "" {
+		return nil, fmt.Errorf("no PON port for ONU %s", serial)
+	}
+	if _, err := m.port(ponPort); err != nil {
+		return nil, err
+	}
+	if onuID == 0 {
+		onuID = m.nextID(ponPort)
+	} else if m.onu(ponPort, onuID) != nil {
+		return nil, fmt.Errorf("ONU ID %d already in use on %s", onuID, ponPort)
+	}
+
+	onu := types.ONUInfo{
+		PONPort:       ponPort,
+		ONUID:         onuID,
+		Serial:        serial,
+		AdminState:    "enabled",
+		OperState:     "online",
+		IsOnline:      true,
+		ProvisionedAt: time.Now(),
+	}
+	if discovered >= 0 {
+		d := m.Discovered[discovered]
+		onu.Model = d.Model
+		onu.RxPowerDBm = d.RxPowerDBm
+		onu.DistanceM = d.DistanceM
+		m.Discovered = slices.Delete(m.Discovered, discovered, discovered+1)
+	}
+	m.ONUs = append(m.ONUs, onu)
+	return &m.ONUs[len(m.ONUs)-1], nil
+}
+
+// deprovision removes an ONU and its service ports
+func (m *MockDriverV2) deprovision(ponPort string, onuID int) {
+	m.ONUs = slices.DeleteFunc(m.ONUs, func(o types.ONUInfo) bool {
+		return o.PONPort == ponPort && o.ONUID == onuID
+	})
+	m.ServicePorts = slices.DeleteFunc(m.ServicePorts, func(sp types.ServicePort) bool {
+		return sp.Interface == ponPort && sp.ONTID == onuID
+	})
+}
+
+func (m *MockDriverV2) bindings(subscriberID string) ([]model.ONUBinding, error) {
+	b, ok := m.subscribers[subscriberID]
+	if !ok {
+		return nil, fmt.Errorf("subscriber %s not found", subscriberID)
+	}
+	return b, nil
+}
+
+// primary returns the primary ONU of a subscriber
+func (m *MockDriverV2) primary(subscriberID string) (*types.ONUInfo, error) {
+	b, err := m.bindings(subscriberID)
+	if err != nil {
+		return nil, err
+	}
+	for _, binding := range b {
+		if binding.Role == model.ONUBindingRolePrimary {
+			return m.findONU(binding.PONPort, binding.ONUID)
+		}
+	}
+	return nil, fmt.Errorf("subscriber %s has no primary ONU", subscriberID)
+}
+
+// setAdmin enables or disables every ONU of a subscriber
+func (m *MockDriverV2) setAdmin(subscriberID string, enabled bool) error {
+	b, err := m.bindings(subscriberID)
+	if err != nil {
+		return err
+	}
+	for _, binding := range b {
+		onu, err := m.findONU(binding.PONPort, binding.ONUID)
+		if err != nil {
+			return err
+		}
+		onu.IsOnline = enabled
+		if enabled {
+			onu.AdminState, onu.OperState = "enabled", "online"
+		} else {
+			onu.AdminState, onu.OperState = "disabled", "offline"
+		}
+	}
+	return nil
+}
+
+func (m *MockDriverV2) snapshot(subscriberID string) (*types.SubscriberSnapshot, error) {
+	onu, err := m.primary(subscriberID)
+	if err != nil {
+		return nil, err
+	}
+	snap := &types.SubscriberSnapshot{
+		Serial:            onu.Serial,
+		PONPort:           onu.PONPort,
+		ONUID:             onu.ONUID,
+		VLAN:              onu.VLAN,
+		LineProfile:       onu.LineProfile,
+		ServiceProfile:    onu.ServiceProfile,
+		ONUProfile:        onu.ONUProfile,
+		BandwidthUpKbps:   onu.BandwidthUp * 1000,
+		BandwidthDownKbps: onu.BandwidthDown * 1000,
+		Metadata:          map[string]string{"subscriber_id": subscriberID},
+		CapturedAt:        time.Now(),
+	}
+	for _, sp := range m.ServicePorts {
+		if sp.Interface == onu.PONPort && sp.ONTID == onu.ONUID {
+			snap.ServicePorts = append(snap.ServicePorts, types.ServicePortSnapshot{
+				Index:        sp.Index,
+				VLAN:         sp.VLAN,
+				UserVLAN:     sp.UserVLAN,
+				GemPort:      sp.GemPort,
+				TagTransform: sp.TagTransform,
+			})
+		}
+	}
+	return snap, nil
+}
+
+// apply sets the configuration of a snapshot on an ONU
+func (m *MockDriverV2) apply(onu *types.ONUInfo, snap *types.SubscriberSnapshot) {
+	onu.VLAN = snap.VLAN
+	onu.LineProfile = snap.LineProfile
+	onu.ServiceProfile = snap.ServiceProfile
+	onu.ONUProfile = snap.ONUProfile
+	onu.BandwidthUp = snap.BandwidthUpKbps / 1000
+	onu.BandwidthDown = snap.BandwidthDownKbps / 1000
+}
+
+func (m *MockDriverV2) addServicePort(req types.AddServicePortRequest) error {
+	if !slices.ContainsFunc(m.VLANs, func(v types.VLANInfo) bool { return v.ID == req.VLAN }) {
+		return fmt.Errorf("VLAN %d does not exist", req.VLAN)
+	}
+	if _, err := m.findONU(req.PONPort, req.ONTID); err != nil {
+		return err
+	}
+	index := 1
+	for _, sp := range m.ServicePorts {
+		index = max(index, sp.Index+1)
+	}
+	m.ServicePorts = append(m.ServicePorts, types.ServicePort{
+		Index:        index,
+		VLAN:         req.VLAN,
+		Interface:    req.PONPort,
+		ONTID:        req.ONTID,
+		GemPort:      req.GemPort,
+		UserVLAN:     req.UserVLAN,
+		TagTransform: req.TagTransform,
+		ETHPort:      req.ETHPort,
+	})
+	return nil
+}
+
+// rebind moves a subscriber binding from one ONU to another
+func (m *MockDriverV2) rebind(subscriberID, serial string, to *types.ONUInfo) {
+	for i, b := range m.subscribers[subscriberID] {
+		if b.Serial == serial {
+			m.subscribers[subscriberID][i].Serial = to.Serial
+			m.subscribers[subscriberID][i].PONPort = to.PONPort
+			m.subscribers[subscriberID][i].ONUID = to.ONUID
+		}
+	}
+}
+
+// === Driver ===
+
+func (m *MockDriverV2) Connect(ctx context.Context, _ *types.EquipmentConfig) error {
+	return exec(ctx, m, "Connect", func() error {
+		m.Connected = true
+		return nil
+	})
+}
+
+func (m *MockDriverV2) Disconnect(ctx context.Context) error {
+	return exec(ctx, m, "Disconnect", func() error {
+		m.Connected = false
+		return nil
+	})
+}
+
+func (m *MockDriverV2) IsConnected() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Connected
+}
+
+func (m *MockDriverV2) CreateSubscriber(ctx context.Context, sub *model.Subscriber, tier *model.ServiceTier) (*types.SubscriberResult, error) {
+	return call(ctx, m, "CreateSubscriber:"+sub.Name, func() (*types.SubscriberResult, error) {
+		if _, ok := m.subscribers[sub.Name]; ok {
+			return nil, fmt.Errorf("subscriber %s already exists", sub.Name)
+		}
+		binding := sub.GetPrimaryONU()
+		if binding == nil {
+			return nil, fmt.Errorf("subscriber %s has no ONU", sub.Name)
+		}
+		ponPort := binding.PONPort
+		if ponPort == "" {
+			ponPort = sub.Annotations["nano.io/pon-port"]
+		}
+		onu, err := m.provision(binding.Serial, ponPort, binding.ONUID)
+		if err != nil {
+			return nil, err
+		}
+		onu.VLAN = sub.Spec.VLAN
+		if tier != nil {
+			onu.BandwidthUp = tier.Spec.BandwidthUp
+			onu.BandwidthDown = tier.Spec.BandwidthDown
+		}
+		if !sub.IsEnabled() {
+			onu.AdminState, onu.OperState, onu.IsOnline = "disabled", "offline", false
+		}
+
+		if m.subscribers == nil {
+			m.subscribers = make(map[string][]model.ONUBinding)
+		}
+		m.subscribers[sub.Name] = []model.ONUBinding{{
+			Serial:  onu.Serial,
+			PONPort: onu.PONPort,
+			ONUID:   onu.ONUID,
+			Role:    model.ONUBindingRolePrimary,
+		}}
+		return &types.SubscriberResult{
+			SubscriberID:  sub.Name,
+			InterfaceName: fmt.Sprintf("%s:%d", onu.PONPort, onu.ONUID),
+			VLAN:          onu.VLAN,
+		}, nil
+	})
+}
+
+func (m *MockDriverV2) UpdateSubscriber(ctx context.Context, sub *model.Subscriber, tier *model.ServiceTier) error {
+	return exec(ctx, m, "UpdateSubscriber:"+sub.Name, func() error {
+		onu, err := m.primary(sub.Name)
+		if err != nil {
+			return err
+		}
+		onu.VLAN = sub.Spec.VLAN
+		if tier != nil {
+			onu.BandwidthUp = tier.Spec.BandwidthUp
+			onu.BandwidthDown = tier.Spec.BandwidthDown
+		}
+		return m.setAdmin(sub.Name, sub.IsEnabled())
+	})
+}
+
+func (m *MockDriverV2) DeleteSubscriber(ctx context.Context, subscriberID string) error {
+	return exec(ctx, m, "DeleteSubscriber:"+subscriberID, func() error {
+		b, err := m.bindings(subscriberID)
+		if err != nil {
+			return err
+		}
+		for _, binding := range b {
+			m.deprovision(binding.PONPort, binding.ONUID)
+		}
+		delete(m.subscribers, subscriberID)
+		delete(m.suspensions, subscriberID)
+		return nil
+	})
+}
+
+func (m *MockDriverV2) SuspendSubscriber(ctx context.Context, subscriberID string) error {
+	return exec(ctx, m, "SuspendSubscriber:"+subscriberID, func() error {
+		return m.setAdmin(subscriberID, false)
+	})
+}
+
+// ResumeSubscriber lifts a hard or soft suspension, restoring the
+// configuration captured by SoftSuspendSubscriber.
+func (m *MockDriverV2) ResumeSubscriber(ctx context.Context, subscriberID string) error {
+	return exec(ctx, m, "ResumeSubscriber:"+subscriberID, func() error {
+		if state, ok := m.suspensions[subscriberID]; ok {
+			onu, err := m.primary(subscriberID)
+			if err != nil {
+				return err
+			}
+			m.apply(onu, state.OriginalSnapshot)
+			delete(m.suspensions, subscriberID)
+		}
+		return m.setAdmin(subscriberID, true)
+	})
+}
+
+func (m *MockDriverV2) GetSubscriberStatus(ctx context.Context, subscriberID string) (*types.SubscriberStatus, error) {
+	return call(ctx, m, "GetSubscriberStatus:"+subscriberID, func() (*types.SubscriberStatus, error) {
+		onu, err := m.primary(subscriberID)
+		if err != nil {
+			return nil, err
+		}
+		status := &types.SubscriberStatus{
+			SubscriberID:  subscriberID,
+			State:         "active",
+			UptimeSeconds: onu.UptimeSeconds,
+			LastActivity:  time.Now(),
+			IsOnline:      onu.IsOnline,
+			Metadata:      map[string]interface{}{"serial": onu.Serial},
+		}
+		switch {
+		case onu.AdminState == "disabled":
+			status.State = "suspended"
+		case m.suspensions[subscriberID] != nil:
+			status.State = "suspended"
+		case !onu.IsOnline:
+			status.State = "offline"
+		}
+		return status, nil
+	})
+}
+
+func (m *MockDriverV2) GetSubscriberStats(ctx context.Context, subscriberID string) (*types.SubscriberStats, error) {
+	return call(ctx, m, "GetSubscriberStats:"+subscriberID, func() (*types.SubscriberStats, error) {
+		onu, err := m.primary(subscriberID)
+		if err != nil {
+			return nil, err
+		}
+		return &types.SubscriberStats{
+			BytesUp:     onu.BytesUp,
+			BytesDown:   onu.BytesDown,
+			PacketsUp:   onu.PacketsUp,
+			PacketsDown: onu.PacketsDown,
+			Timestamp:   time.Now(),
+		}, nil
+	})
+}
+
+func (m *MockDriverV2) HealthCheck(ctx context.Context) error {
+	return exec(ctx, m, "HealthCheck", func() error {
+		if !m.Connected {
+			return fmt.Errorf("not connected")
+		}
+		return nil
+	})
+}
+
+// === DriverV2 ===
+
+func (m *MockDriverV2) DiscoverONUs(ctx context.Context, ponPorts []string) ([]types.ONUDiscovery, error) {
+	return call(ctx, m, "DiscoverONUs", func() ([]types.ONUDiscovery, error) {
+		result := []types.ONUDiscovery{}
+		for _, d := range m.Discovered {
+			if len(ponPorts) == 0 || slices.Contains(ponPorts, d.PONPort) {
+				result = append(result, d)
+			}
+		}
+		return result, nil
+	})
+}
+
+func (m *MockDriverV2) GetONUList(ctx context.Context, filter *types.ONUFilter) ([]types.ONUInfo, error) {
+	return call(ctx, m, "GetONUList", func() ([]types.ONUInfo, error) {
+		result := []types.ONUInfo{}
+		for _, onu := range m.ONUs {
+			if filter != nil {
+				if filter.PONPort != "" && onu.PONPort != filter.PONPort ||
+					filter.Status == "online" && !onu.IsOnline ||
+					filter.Status == "offline" && onu.IsOnline ||
+					filter.Profile != "" && onu.LineProfile != filter.Profile && onu.ServiceProfile != filter.Profile ||
+					filter.Serial != "" && !strings.Contains(strings.ToUpper(onu.Serial), strings.ToUpper(filter.Serial)) ||
+					filter.VLAN != 0 && onu.VLAN != filter.VLAN {
+					continue
+				}
+			}
+			result = append(result, onu)
+		}
+		return types.PageONUs(result, filter), nil
+	})
+}
+
+func (m *MockDriverV2) GetONUBySerial(ctx context.Context, serial string) (*types.ONUInfo, error) {
+	return call(ctx, m, "GetONUBySerial:"+serial, func() (*types.ONUInfo, error) {
+		if onu := m.onuBySerial(serial); onu != nil {
+			found := *onu
+			return &found, nil
+		}
+		return nil, nil
+	})
+}
+
+func (m *MockDriverV2) GetPONPower(ctx context.Context, ponPort string) (*types.PONPowerReading, error) {
+	return call(ctx, m, "GetPONPower:"+ponPort, func() (*types.PONPowerReading, error) {
+		port, err := m.port(ponPort)
+		if err != nil {
+			return nil, err
+		}
+		return &types.PONPowerReading{
+			PONPort:    ponPort,
+			TxPowerDBm: port.TxPowerDBm,
+			RxPowerDBm: port.RxPowerDBm,
+			Timestamp:  time.Now(),
+		}, nil
+	})
+}
+
+func (m *MockDriverV2) GetONUPower(ctx context.Context, ponPort string, onuID int) (*types.ONUPowerReading, error) {
+	return call(ctx, m, onuCall("GetONUPower", ponPort, onuID), func() (*types.ONUPowerReading, error) {
+		onu, err := m.findONU(ponPort, onuID)
+		if err != nil {
+			return nil, err
+		}
+		return &types.ONUPowerReading{
+			PONPort:         ponPort,
+			ONUID:           onuID,
+			Serial:          onu.Serial,
+			TxPowerDBm:      onu.TxPowerDBm,
+			RxPowerDBm:      onu.RxPowerDBm,
+			DistanceM:       onu.DistanceM,
+			TxHighThreshold: types.GPONTxHighThreshold,
+			TxLowThreshold:  types.GPONTxLowThreshold,
+			RxHighThreshold: types.GPONRxHighThreshold,
+			RxLowThreshold:  types.GPONRxLowThreshold,
+			IsWithinSpec:    types.IsPowerWithinSpec(onu.RxPowerDBm, onu.TxPowerDBm),
+			Timestamp:       time.Now(),
+		}, nil
+	})
+}
+
+func (m *MockDriverV2) GetONUDistance(ctx context.Context, ponPort string, onuID int) (int, error) {
+	return call(ctx, m, onuCall("GetONUDistance", ponPort, onuID), func() (int, error) {
+		onu, err := m.findONU(ponPort, onuID)
+		if err != nil {
+			return -1, err
+		}
+		return onu.DistanceM, nil
+	})
+}
+
+func (m *MockDriverV2) RestartONU(ctx context.Context, ponPort string, onuID int) (*types.RestartONUResult, error) {
+	return call(ctx, m, onuCall("RestartONU", ponPort, onuID), func() (*types.RestartONUResult, error) {
+		onu, err := m.findONU(ponPort, onuID)
+		if err != nil {
+			return nil, err
+		}
+		onu.UptimeSeconds = 0
+		return &types.RestartONUResult{
+			Success:            true,
+			DeactivateSuccess:  true,
+			DeactivateVerified: true,
+			ActivateSuccess:    true,
+			ActivateVerified:   true,
+			Message:            "ONU restarted",
+		}, nil
+	})
+}
+
+func (m *MockDriverV2) FactoryResetONU(ctx context.Context, ponPort string, onuID int, opts *types.FactoryResetOptions) (*types.FactoryResetResult, error) {
+	return call(ctx, m, onuCall("FactoryResetONU", ponPort, onuID), func() (*types.FactoryResetResult, error) {
+		if err := types.CheckFactoryReset(opts, "mock"); err != nil {
+			return nil, err
+		}
+		onu, err := m.findONU(ponPort, onuID)
+		if err != nil {
+			return nil, err
+		}
+		onu.UptimeSeconds = 0
+		return &types.FactoryResetResult{
+			Success:      true,
+			ResetSent:    true,
+			Reregistered: !opts.SkipsVerification(),
+			Message:      "ONU reset to factory settings",
+		}, nil
+	})
+}
+
+func (m *MockDriverV2) ApplyProfile(ctx context.Context, ponPort string, onuID int, profile *types.ONUProfile) error {
+	return exec(ctx, m, onuCall("ApplyProfile", ponPort, onuID), func() error {
+		onu, err := m.findONU(ponPort, onuID)
+		if err != nil {
+			return err
+		}
+		onu.LineProfile = profile.LineProfile
+		onu.ServiceProfile = profile.ServiceProfile
+		onu.BandwidthUp = profile.BandwidthUp / 1000
+		onu.BandwidthDown = profile.BandwidthDown / 1000
+		if profile.VLAN != 0 {
+			onu.VLAN = profile.VLAN
+		}
+		return nil
+	})
+}
+
+// BulkProvision provisions each operation independently, so faults
+// registered for single serials fail only their operation.
+func (m *MockDriverV2) BulkProvision(ctx context.Context, operations []types.BulkProvisionOp) (*types.BulkResult, error) {
+	return call(ctx, m, "BulkProvision", func() (*types.BulkResult, error) {
+		result := &types.BulkResult{Results: make([]types.BulkOpResult, 0, len(operations))}
+		for _, op := range operations {
+			r := types.BulkOpResult{Serial: op.Serial}
+			var err error
+			if f := m.fault("BulkProvision:" + op.Serial); f != nil {
+				err = f.Err
+			}
+			var onu *types.ONUInfo
+			if err == nil {
+				onu, err = m.provision(op.Serial, op.PONPort, op.ONUID)
+			}
+			if err != nil {
+				r.Error = err.Error()
+				result.Failed++
+				result.Results = append(result.Results, r)
+				continue
+			}
+			if op.Profile != nil {
+				onu.LineProfile = op.Profile.LineProfile
+				onu.ServiceProfile = op.Profile.ServiceProfile
+				onu.BandwidthUp = op.Profile.BandwidthUp / 1000
+				onu.BandwidthDown = op.Profile.BandwidthDown / 1000
+				onu.VLAN = op.Profile.VLAN
+			}
+			r.Success, r.PONPort, r.ONUID = true, onu.PONPort, onu.ONUID
+			result.Succeeded++
+			result.Results = append(result.Results, r)
+		}
+		return result, nil
+	})
+}
+
+func (m *MockDriverV2) RunDiagnostics(ctx context.Context, ponPort string, onuID int) (*types.ONUDiagnostics, error) {
+	return call(ctx, m, onuCall("RunDiagnostics", ponPort, onuID), func() (*types.ONUDiagnostics, error) {
+		onu, err := m.findONU(ponPort, onuID)
+		if err != nil {
+			return nil, err
+		}
+		diag := &types.ONUDiagnostics{
+			Serial:         onu.Serial,
+			PONPort:        ponPort,
+			ONUID:          onuID,
+			AdminState:     onu.AdminState,
+			OperState:      onu.OperState,
+			BytesUp:        onu.BytesUp,
+			BytesDown:      onu.BytesDown,
+			LineProfile:    onu.LineProfile,
+			ServiceProfile: onu.ServiceProfile,
+			VLAN:           onu.VLAN,
+			BandwidthUp:    onu.BandwidthUp * 1000,
+			BandwidthDown:  onu.BandwidthDown * 1000,
+			Power: &types.ONUPowerReading{
+				PONPort:      ponPort,
+				ONUID:        onuID,
+				Serial:       onu.Serial,
+				TxPowerDBm:   onu.TxPowerDBm,
+				RxPowerDBm:   onu.RxPowerDBm,
+				DistanceM:    onu.DistanceM,
+				IsWithinSpec: types.IsPowerWithinSpec(onu.RxPowerDBm, onu.TxPowerDBm),
+			},
+			Timestamp: time.Now(),
+		}
+		source := fmt.Sprintf("%s:%d", ponPort, onuID)
+		for _, alarm := range m.Alarms {
+			if alarm.SourceID == source || alarm.SourceID == onu.Serial {
+				diag.Alarms = append(diag.Alarms, alarm.Type)
+			}
+		}
+		return diag, nil
+	})
+}
+
+func (m *MockDriverV2) GetAlarms(ctx context.Context) ([]types.OLTAlarm, error) {
+	return call(ctx, m, "GetAlarms", func() ([]types.OLTAlarm, error) {
+		return append([]types.OLTAlarm{}, m.Alarms...), nil
+	})
+}
+
+// RestartOLT succeeds and drops the connection, as a reboot does.
+func (m *MockDriverV2) RestartOLT(ctx context.Context) (*types.RestartOLTResult, error) {
+	return call(ctx, m, "RestartOLT", func() (*types.RestartOLTResult, error) {
+		m.Connected = false
+		return &types.RestartOLTResult{Success: true, SaveSuccess: true, Message: "OLT restarting"}, nil
+	})
+}
+
+func (m *MockDriverV2) GetOLTStatus(ctx context.Context) (*types.OLTStatus, error) {
+	return call(ctx, m, "GetOLTStatus", func() (*types.OLTStatus, error) {
+		status := m.OLT
+		status.IsReachable = m.Connected
+		status.PONPorts = nil
+		for _, p := range m.portStatus() {
+			status.PONPorts = append(status.PONPorts, *p)
+		}
+		status.TotalONUs = len(m.ONUs)
+		status.ActiveONUs = 0
+		for _, onu := range m.ONUs {
+			if onu.IsOnline {
+				status.ActiveONUs++
+			}
+		}
+		status.LastPoll = time.Now()
+		return &status, nil
+	})
+}
+
+// portStatus returns copies of the ports with their ONU counts
+func (m *MockDriverV2) portStatus() []*types.PONPortStatus {
+	ports := make([]*types.PONPortStatus, 0, len(m.Ports))
+	for _, p := range m.Ports {
+		p.ONUCount = 0
+		for _, onu := range m.ONUs {
+			if onu.PONPort == p.Port {
+				p.ONUCount++
+			}
+		}
+		ports = append(ports, &p)
+	}
+	return ports
+}
+
+func (m *MockDriverV2) ListPorts(ctx context.Context) ([]*types.PONPortStatus, error) {
+	return call(ctx, m, "ListPorts", func() ([]*types.PONPortStatus, error) {
+		return m.portStatus(), nil
+	})
+}
+
+func (m *MockDriverV2) SetPortState(ctx context.Context, port string, enabled bool) error {
+	return exec(ctx, m, "SetPortState:"+port, func() error {
+		for i := range m.Ports {
+			if m.Ports[i].Port == port {
+				m.Ports[i].AdminState = "disabled"
+				if enabled {
+					m.Ports[i].AdminState = "enabled"
+				}
+				return nil
+			}
+		}
+		if len(m.Ports) == 0 {
+			return nil
+		}
+		return fmt.Errorf("PON port %s not found", port)
+	})
+}
+
+func (m *MockDriverV2) vlanServicePorts(vlanID int) int {
+	n := 0
+	for _, sp := range m.ServicePorts {
+		if sp.VLAN == vlanID {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *MockDriverV2) ListVLANs(ctx context.Context) ([]types.VLANInfo, error) {
+	return call(ctx, m, "ListVLANs", func() ([]types.VLANInfo, error) {
+		vlans := append([]types.VLANInfo{}, m.VLANs...)
+		for i := range vlans {
+			vlans[i].ServicePortCount = m.vlanServicePorts(vlans[i].ID)
+		}
+		return vlans, nil
+	})
+}
+
+func (m *MockDriverV2) GetVLAN(ctx context.Context, vlanID int) (*types.VLANInfo, error) {
+	return call(ctx, m, "GetVLAN:"+strconv.Itoa(vlanID), func() (*types.VLANInfo, error) {
+		for _, v := range m.VLANs {
+			if v.ID == vlanID {
+				v.ServicePortCount = m.vlanServicePorts(vlanID)
+				return &v, nil
+			}
+		}
+		return nil, nil
+	})
+}
+
+func (m *MockDriverV2) CreateVLAN(ctx context.Context, req *types.CreateVLANRequest) error {
+	return exec(ctx, m, "CreateVLAN:"+strconv.Itoa(req.ID), func() error {
+		if req.ID < 1 || req.ID > 4094 {
+			return fmt.Errorf("invalid VLAN ID %d", req.ID)
+		}
+		if slices.ContainsFunc(m.VLANs, func(v types.VLANInfo) bool { return v.ID == req.ID }) {
+			return fmt.Errorf("VLAN %d already exists", req.ID)
+		}
+		vlanType := req.Type
+		if vlanType == "" {
+			vlanType = "smart"
+		}
+		m.VLANs = append(m.VLANs, types.VLANInfo{
+			ID:          req.ID,
+			Name:        req.Name,
+			Type:        vlanType,
+			Description: req.Description,
+			CreatedAt:   time.Now(),
+		})
+		return nil
+	})
+}
+
+func (m *MockDriverV2) DeleteVLAN(ctx context.Context, vlanID int, force bool) error {
+	return exec(ctx, m, "DeleteVLAN:"+strconv.Itoa(vlanID), func() error {
+		i := slices.IndexFunc(m.VLANs, func(v types.VLANInfo) bool { return v.ID == vlanID })
+		if i < 0 {
+			return fmt.Errorf("VLAN %d not found", vlanID)
+		}
+		if n := m.vlanServicePorts(vlanID); n > 0 {
+			if !force {
+				return fmt.Errorf("VLAN %d has %d service ports", vlanID, n)
+			}
+			m.ServicePorts = slices.DeleteFunc(m.ServicePorts, func(sp types.ServicePort) bool { return sp.VLAN == vlanID })
+		}
+		m.VLANs = slices.Delete(m.VLANs, i, i+1)
+		return nil
+	})
+}
+
+func (m *MockDriverV2) ListServicePorts(ctx context.Context) ([]types.ServicePort, error) {
+	return call(ctx, m, "ListServicePorts", func() ([]types.ServicePort, error) {
+		return append([]types.ServicePort{}, m.ServicePorts...), nil
+	})
+}
+
+// AddServicePort requires the VLAN and the ONU to exist.
+func (m *MockDriverV2) AddServicePort(ctx context.Context, req *types.AddServicePortRequest) error {
+	return exec(ctx, m, onuCall("AddServicePort", req.PONPort, req.ONTID), func() error {
+		return m.addServicePort(*req)
+	})
+}
+
+func (m *MockDriverV2) DeleteServicePort(ctx context.Context, ponPort string, ontID int) error {
+	return exec(ctx, m, onuCall("DeleteServicePort", ponPort, ontID), func() error {
+		n := len(m.ServicePorts)
+		m.ServicePorts = slices.DeleteFunc(m.ServicePorts, func(sp types.ServicePort) bool {
+			return sp.Interface == ponPort && sp.ONTID == ontID
+		})
+		if len(m.ServicePorts) == n {
+			return fmt.Errorf("no service port for ONU %s:%d", ponPort, ontID)
+		}
+		return nil
+	})
+}
+
+func (m *MockDriverV2) GetONUProfiles(ctx context.Context) ([]types.ONUInfo, error) {
+	return call(ctx, m, "GetONUProfiles", func() ([]types.ONUInfo, error) {
+		result := make([]types.ONUInfo, 0, len(m.ONUs))
+		for _, onu := range m.ONUs {
+			result = append(result, types.ONUInfo{
+				PONPort:        onu.PONPort,
+				ONUID:          onu.ONUID,
+				Serial:         onu.Serial,
+				ONUProfile:     onu.ONUProfile,
+				LineProfile:    onu.LineProfile,
+				ServiceProfile: onu.ServiceProfile,
+				VLAN:           onu.VLAN,
+			})
+		}
+		return result, nil
+	})
+}
+
+func (m *MockDriverV2) CaptureSubscriberConfig(ctx context.Context, subscriberID string) (*types.SubscriberSnapshot, error) {
+	return call(ctx, m, "CaptureSubscriberConfig:"+subscriberID, func() (*types.SubscriberSnapshot, error) {
+		return m.snapshot(subscriberID)
+	})
+}
+
+// RestoreSubscriberConfig provisions the snapshot on the target. If the
+// snapshot was captured from a subscriber of this mock, the subscriber is
+// rebound to the new ONU.
+func (m *MockDriverV2) RestoreSubscriberConfig(ctx context.Context, snapshot *types.SubscriberSnapshot, targetPONPort string, targetONUID int) (*types.SubscriberResult, error) {
+	return call(ctx, m, onuCall("RestoreSubscriberConfig", targetPONPort, targetONUID), func() (*types.SubscriberResult, error) {
+		onu, err := m.provision(snapshot.Serial, targetPONPort, targetONUID)
+		if err != nil {
+			return nil, err
+		}
+		m.apply(onu, snapshot)
+		for _, sp := range snapshot.ServicePorts {
+			err := m.addServicePort(types.AddServicePortRequest{
+				VLAN:         sp.VLAN,
+				PONPort:      onu.PONPort,
+				ONTID:        onu.ONUID,
+				GemPort:      sp.GemPort,
+				UserVLAN:     sp.UserVLAN,
+				TagTransform: sp.TagTransform,
+			})
+			if err != nil {
+				m.deprovision(onu.PONPort, onu.ONUID)
+				return nil, err
+			}
+		}
+
+		subscriberID := snapshot.Metadata["subscriber_id"]
+		if _, ok := m.subscribers[subscriberID]; !ok {
+			subscriberID = fmt.Sprintf("%s:%d", onu.PONPort, onu.ONUID)
+		}
+		return &types.SubscriberResult{
+			SubscriberID:  subscriberID,
+			InterfaceName: fmt.Sprintf("%s:%d", onu.PONPort, onu.ONUID),
+			VLAN:          onu.VLAN,
+		}, nil
+	})
+}
+
+func (m *MockDriverV2) ReplaceONU(ctx context.Context, subscriberID string, newSerial string) (*types.ReplaceResult, error) {
+	return call(ctx, m, "ReplaceONU:"+subscriberID, func() (*types.ReplaceResult, error) {
+		if newSerial == "" {
+			return nil, fmt.Errorf("new serial is required")
+		}
+		snap, err := m.snapshot(subscriberID)
+		if err != nil {
+			return nil, err
+		}
+		if m.onuBySerial(newSerial) != nil {
+			return nil, fmt.Errorf("ONU %s already provisioned", newSerial)
+		}
+		onu := m.onu(snap.PONPort, snap.ONUID)
+		onu.Serial = newSerial
+		m.Discovered = slices.DeleteFunc(m.Discovered, func(d types.ONUDiscovery) bool {
+			return strings.EqualFold(d.Serial, newSerial)
+		})
+		m.rebind(subscriberID, snap.Serial, onu)
+		return &types.ReplaceResult{
+			OldSerial:          snap.Serial,
+			NewSerial:          newSerial,
+			Snapshot:           snap,
+			VerificationStatus: "online",
+		}, nil
+	})
+}
+
+func (m *MockDriverV2) SoftSuspendSubscriber(ctx context.Context, subscriberID string, opts *types.SuspendOptions) (*types.SuspensionState, error) {
+	return call(ctx, m, "SoftSuspendSubscriber:"+subscriberID, func() (*types.SuspensionState, error) {
+		if state, ok := m.suspensions[subscriberID]; ok {
+			return state, nil
+		}
+		snap, err := m.snapshot(subscriberID)
+		if err != nil {
+			return nil, err
+		}
+		onu := m.onu(snap.PONPort, snap.ONUID)
+		state := &types.SuspensionState{Mode: opts.Mode, OriginalSnapshot: snap, SuspendedAt: time.Now()}
+		switch opts.Mode {
+		case types.SuspensionModeThrottle, types.SuspensionModeWalledGarden, types.SuspensionModeQuarantine:
+		default:
+			return nil, fmt.Errorf("unsupported suspension mode %q", opts.Mode)
+		}
+		if opts.Mode != types.SuspensionModeWalledGarden {
+			state.AppliedBandwidthKbps = opts.ThrottleBandwidthKbps
+			onu.BandwidthUp = opts.ThrottleBandwidthKbps / 1000
+			onu.BandwidthDown = opts.ThrottleBandwidthKbps / 1000
+		}
+		if opts.Mode != types.SuspensionModeThrottle {
+			state.AppliedVLAN = opts.WalledGardenVLAN
+			onu.VLAN = opts.WalledGardenVLAN
+		}
+		if m.suspensions == nil {
+			m.suspensions = make(map[string]*types.SuspensionState)
+		}
+		m.suspensions[subscriberID] = state
+		return state, nil
+	})
+}
+
+func (m *MockDriverV2) GetSuspensionState(ctx context.Context, subscriberID string) (*types.SuspensionState, error) {
+	return call(ctx, m, "GetSuspensionState:"+subscriberID, func() (*types.SuspensionState, error) {
+		if _, err := m.bindings(subscriberID); err != nil {
+			return nil, err
+		}
+		return m.suspensions[subscriberID], nil
+	})
+}
+
+// MoveSubscriber moves the primary ONU with its service ports.
+func (m *MockDriverV2) MoveSubscriber(ctx context.Context, subscriberID string, targetPONPort string, targetONUID int) (*types.MoveResult, error) {
+	return call(ctx, m, "MoveSubscriber:"+subscriberID, func() (*types.MoveResult, error) {
+		snap, err := m.snapshot(subscriberID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := m.port(targetPONPort); err != nil {
+			return nil, err
+		}
+		if targetONUID == 0 {
+			targetONUID = m.nextID(targetPONPort)
+		} else if m.onu(targetPONPort, targetONUID) != nil {
+			return nil, fmt.Errorf("ONU ID %d already in use on %s", targetONUID, targetPONPort)
+		}
+		for i := range m.ServicePorts {
+			if m.ServicePorts[i].Interface == snap.PONPort && m.ServicePorts[i].ONTID == snap.ONUID {
+				m.ServicePorts[i].Interface, m.ServicePorts[i].ONTID = targetPONPort, targetONUID
+			}
+		}
+		onu := m.onu(snap.PONPort, snap.ONUID)
+		onu.PONPort, onu.ONUID = targetPONPort, targetONUID
+		m.rebind(subscriberID, snap.Serial, onu)
+		return &types.MoveResult{
+			OldPONPort:         snap.PONPort,
+			NewPONPort:         targetPONPort,
+			OldONUID:           snap.ONUID,
+			NewONUID:           targetONUID,
+			Snapshot:           snap,
+			VerificationStatus: "online",
+		}, nil
+	})
+}
+
+func (m *MockDriverV2) CheckONUCompatibility(ctx context.Context, subscriberID string, newSerial string) (*types.CompatibilityReport, error) {
+	return call(ctx, m, "CheckONUCompatibility:"+subscriberID, func() (*types.CompatibilityReport, error) {
+		onu, err := m.primary(subscriberID)
+		if err != nil {
+			return nil, err
+		}
+		return &types.CompatibilityReport{Compatible: true, CurrentProfile: onu.LineProfile}, nil
+	})
+}
+
+func (m *MockDriverV2) AddONUToSubscriber(ctx context.Context, subscriberID string, binding model.ONUBinding, tier *model.ServiceTier) (*types.SubscriberResult, error) {
+	return call(ctx, m, "AddONUToSubscriber:"+subscriberID, func() (*types.SubscriberResult, error) {
+		if _, err := m.bindings(subscriberID); err != nil {
+			return nil, err
+		}
+		onu, err := m.provision(binding.Serial, binding.PONPort, binding.ONUID)
+		if err != nil {
+			return nil, err
+		}
+		if tier != nil {
+			onu.BandwidthUp = tier.Spec.BandwidthUp
+			onu.BandwidthDown = tier.Spec.BandwidthDown
+		}
+		binding.PONPort, binding.ONUID = onu.PONPort, onu.ONUID
+		if binding.Role == "" {
+			binding.Role = model.ONUBindingRoleSecondary
+		}
+		m.subscribers[subscriberID] = append(m.subscribers[subscriberID], binding)
+		return &types.SubscriberResult{
+			SubscriberID:  subscriberID,
+			InterfaceName: fmt.Sprintf("%s:%d", onu.PONPort, onu.ONUID),
+		}, nil
+	})
+}
+
+func (m *MockDriverV2) RemoveONUFromSubscriber(ctx context.Context, subscriberID string, serial string) error {
+	return exec(ctx, m, "RemoveONUFromSubscriber:"+subscriberID, func() error {
+		b, err := m.bindings(subscriberID)
+		if err != nil {
+			return err
+		}
+		i := slices.IndexFunc(b, func(binding model.ONUBinding) bool { return binding.Serial == serial })
+		if i < 0 {
+			return fmt.Errorf("ONU %s not bound to subscriber %s", serial, subscriberID)
+		}
+		m.deprovision(b[i].PONPort, b[i].ONUID)
+		m.subscribers[subscriberID] = slices.Delete(b, i, i+1)
+		return nil
+	})
+}
+
+func (m *MockDriverV2) ListSubscriberONUs(ctx context.Context, subscriberID string) ([]model.ONUBinding, error) {
+	return call(ctx, m, "ListSubscriberONUs:"+subscriberID, func() ([]model.ONUBinding, error) {
+		b, err := m.bindings(subscriberID)
+		if err != nil {
+			return nil, err
+		}
+		return append([]model.ONUBinding{}, b...), nil
+	})
+}
@@ -0,0 +1,179 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestMockDriverV2Provisioning(t *testing.T) {
+	ctx := context.Background()
+	d := &MockDriverV2{
+		Discovered: []types.ONUDiscovery{{PONPort: "0/2", Serial: "VSOL00000001", Model: "V2802GWT"}},
+		VLANs:      []types.VLANInfo{{ID: 100}},
+	}
+
+	found, err := d.DiscoverONUs(ctx, []string{"0/2"})
+	if err != nil || len(found) != 1 {
+		t.Fatalf("DiscoverONUs() = %v, %v", found, err)
+	}
+
+	// Port from the discovery, not the annotation-less subscriber
+	sub := NewTestSubscriber("VSOL00000001", "", 100)
+	result, err := d.CreateSubscriber(ctx, sub, NewTestServiceTier(50, 100))
+	if err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	onu, _ := d.GetONUBySerial(ctx, "VSOL00000001")
+	if onu == nil || onu.PONPort != "0/2" || onu.ONUID != 1 || onu.Model != "V2802GWT" || onu.BandwidthDown != 100 {
+		t.Fatalf("GetONUBySerial() = %+v", onu)
+	}
+	if found, _ := d.DiscoverONUs(ctx, nil); len(found) != 0 {
+		t.Errorf("provisioned ONU still discovered: %v", found)
+	}
+	if _, err := d.CreateSubscriber(ctx, NewTestSubscriber("VSOL00000001", "0/1", 100), nil); err == nil {
+		t.Error("CreateSubscriber() of a provisioned serial succeeded")
+	}
+
+	if err := d.AddServicePort(ctx, &types.AddServicePortRequest{VLAN: 100, PONPort: "0/2", ONTID: 1}); err != nil {
+		t.Fatalf("AddServicePort() error = %v", err)
+	}
+	if err := d.DeleteVLAN(ctx, 100, false); err == nil {
+		t.Error("DeleteVLAN() of a VLAN in use succeeded")
+	}
+
+	if err := d.SuspendSubscriber(ctx, result.SubscriberID); err != nil {
+		t.Fatal(err)
+	}
+	if onus, _ := d.GetONUList(ctx, &types.ONUFilter{Status: "online"}); len(onus) != 0 {
+		t.Errorf("suspended ONU listed as online: %v", onus)
+	}
+	status, _ := d.GetSubscriberStatus(ctx, result.SubscriberID)
+	if status.State != "suspended" {
+		t.Errorf("State = %q, want suspended", status.State)
+	}
+
+	move, err := d.MoveSubscriber(ctx, result.SubscriberID, "0/3", 0)
+	if err != nil || move.NewPONPort != "0/3" || move.NewONUID != 1 {
+		t.Fatalf("MoveSubscriber() = %+v, %v", move, err)
+	}
+	sps, _ := d.ListServicePorts(ctx)
+	if len(sps) != 1 || sps[0].Interface != "0/3" {
+		t.Errorf("service ports did not follow the move: %+v", sps)
+	}
+
+	if err := d.DeleteSubscriber(ctx, result.SubscriberID); err != nil {
+		t.Fatal(err)
+	}
+	if onus, _ := d.GetONUList(ctx, nil); len(onus) != 0 {
+		t.Errorf("GetONUList() after delete = %v", onus)
+	}
+	if sps, _ := d.ListServicePorts(ctx); len(sps) != 0 {
+		t.Errorf("service ports left after delete: %v", sps)
+	}
+}
+
+func TestMockDriverV2SoftSuspend(t *testing.T) {
+	ctx := context.Background()
+	d := &MockDriverV2{}
+	if _, err := d.CreateSubscriber(ctx, NewTestSubscriber("VSOL00000001", "0/1", 100), NewTestServiceTier(50, 100)); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &types.SuspendOptions{Mode: types.SuspensionModeQuarantine, WalledGardenVLAN: 999, ThrottleBandwidthKbps: 1000}
+	if _, err := d.SoftSuspendSubscriber(ctx, "test-VSOL00000001", opts); err != nil {
+		t.Fatal(err)
+	}
+	onu, _ := d.GetONUBySerial(ctx, "VSOL00000001")
+	if onu.VLAN != 999 || onu.BandwidthDown != 1 {
+		t.Errorf("quarantined ONU = VLAN %d, %d Mbps", onu.VLAN, onu.BandwidthDown)
+	}
+
+	if err := d.ResumeSubscriber(ctx, "test-VSOL00000001"); err != nil {
+		t.Fatal(err)
+	}
+	onu, _ = d.GetONUBySerial(ctx, "VSOL00000001")
+	if onu.VLAN != 100 || onu.BandwidthDown != 100 {
+		t.Errorf("resumed ONU = VLAN %d, %d Mbps; want the original", onu.VLAN, onu.BandwidthDown)
+	}
+	if state, _ := d.GetSuspensionState(ctx, "test-VSOL00000001"); state != nil {
+		t.Errorf("GetSuspensionState() after resume = %+v", state)
+	}
+}
+
+func TestMockDriverV2Faults(t *testing.T) {
+	ctx := context.Background()
+	errDown := errors.New("device unreachable")
+	d := &MockDriverV2{
+		Faults: map[string]*Fault{
+			"GetAlarms":                  {Err: errDown, Skip: 1, Times: 1},
+			"GetVLAN:200":                {Err: errDown},
+			"GetOLTStatus":               {Hang: true},
+			"BulkProvision:VSOL00000002": {Err: errDown},
+		},
+	}
+
+	for i, want := range []error{nil, errDown, nil} {
+		if _, err := d.GetAlarms(ctx); !errors.Is(err, want) {
+			t.Errorf("GetAlarms() call %d error = %v, want %v", i+1, err, want)
+		}
+	}
+
+	if _, err := d.GetVLAN(ctx, 100); err != nil {
+		t.Errorf("GetVLAN(100) error = %v, want the fault only on 200", err)
+	}
+	if _, err := d.GetVLAN(ctx, 200); !errors.Is(err, errDown) {
+		t.Errorf("GetVLAN(200) error = %v", err)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := d.GetOLTStatus(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetOLTStatus() error = %v, want a timeout", err)
+	}
+
+	result, err := d.BulkProvision(ctx, []types.BulkProvisionOp{
+		{Serial: "VSOL00000001", PONPort: "0/1"},
+		{Serial: "VSOL00000002", PONPort: "0/1"},
+		{Serial: "VSOL00000003", PONPort: "0/1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Succeeded != 2 || result.Failed != 1 || result.Results[1].Success {
+		t.Errorf("BulkProvision() = %+v, want only the second to fail", result)
+	}
+	if result.Results[2].ONUID != 2 {
+		t.Errorf("third ONU ID = %d, want 2", result.Results[2].ONUID)
+	}
+}
+
+func TestMockDriverV2Responses(t *testing.T) {
+	ctx := context.Background()
+	d := &MockDriverV2{
+		ONUs: []types.ONUInfo{{PONPort: "0/1", ONUID: 1, Serial: "VSOL00000001"}},
+		Responses: map[string]any{
+			"GetOLTStatus":       &types.OLTStatus{Model: "V1600G"},
+			"GetONUBySerial":     nil,
+			"ListSubscriberONUs": []model.ONUBinding{{Serial: "VSOL00000001"}},
+		},
+	}
+
+	status, err := d.GetOLTStatus(ctx)
+	if err != nil || status.Model != "V1600G" {
+		t.Errorf("GetOLTStatus() = %+v, %v", status, err)
+	}
+	if onu, err := d.GetONUBySerial(ctx, "VSOL00000001"); onu != nil || err != nil {
+		t.Errorf("GetONUBySerial() = %+v, %v; want the scripted nil", onu, err)
+	}
+	if b, err := d.ListSubscriberONUs(ctx, "unknown"); len(b) != 1 || err != nil {
+		t.Errorf("ListSubscriberONUs() = %v, %v", b, err)
+	}
+	if len(d.Calls) != 3 || d.Calls[1] != "GetONUBySerial:VSOL00000001" {
+		t.Errorf("Calls = %v", d.Calls)
+	}
+}
@@ -3,6 +3,7 @@ package testutil
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/nanoncore/nano-southbound/drivers/netconf"
@@ -103,6 +104,11 @@ type MockSNMPExecutor struct {
 	// BulkGetError is the error for BulkGetSNMP.
 	BulkGetError error
 
+	// MIB maps full OIDs with a leading dot to values, e.g. from
+	// ParseSNMPWalk. Gets and walks of any subtree not found above fall
+	// back to it, with walk indexes formed as the SNMP driver forms them.
+	MIB map[string]interface{}
+
 	// Calls records all method calls for verification.
 	Calls []string
 }
@@ -125,6 +131,10 @@ func (m *MockSNMPExecutor) GetSNMP(_ context.Context, oid string) (interface{},
 		}
 	}
 
+	if val, ok := m.MIB["."+strings.TrimPrefix(oid, ".")]; ok {
+		return val, nil
+	}
+
 	return nil, fmt.Errorf("no result for OID %s", oid)
 }
 
@@ -146,7 +156,14 @@ func (m *MockSNMPExecutor) WalkSNMP(_ context.Context, oid string) (map[string]i
 		}
 	}
 
-	return map[string]interface{}{}, nil
+	results := map[string]interface{}{}
+	prefix := "." + strings.TrimPrefix(oid, ".") + "."
+	for name, val := range m.MIB {
+		if strings.HasPrefix(name, prefix) {
+			results[name[len(oid)+1:]] = val
+		}
+	}
+	return results, nil
 }
 
 func (m *MockSNMPExecutor) BulkGetSNMP(_ context.Context, oids []string) (map[string]interface{}, error) {
@@ -163,7 +180,14 @@ func (m *MockSNMPExecutor) BulkGetSNMP(_ context.Context, oids []string) (map[st
 		return m.BulkGetResults, nil
 	}
 
-	return map[string]interface{}{}, nil
+	results := map[string]interface{}{}
+	for _, oid := range oids {
+		name := "." + strings.TrimPrefix(oid, ".")
+		if val, ok := m.MIB[name]; ok {
+			results[name] = val
+		}
+	}
+	return results, nil
 }
 
 // MockDriver implements types.Driver for testing.
@@ -393,6 +417,9 @@ type MockNETCONFExecutor struct {
 	// EditConfigError is returned by EditConfig if set.
 	EditConfigError error
 
+	// CopyConfigError is returned by CopyConfig if set.
+	CopyConfigError error
+
 	// RPCResponses maps operation string to response bytes.
 	RPCResponses map[string][]byte
 
@@ -408,6 +435,9 @@ type MockNETCONFExecutor struct {
 	// UnlockError is returned by Unlock if set.
 	UnlockError error
 
+	// ValidateError is returned by Validate and ValidateConfig if set.
+	ValidateError error
+
 	// Capabilities is the list of capabilities returned by GetCapabilities.
 	Capabilities []string
 
@@ -432,7 +462,7 @@ func (m *MockNETCONFExecutor) RPC(_ context.Context, operation string) ([]byte,
 	return []byte("<ok/>"), nil
 }
 
-func (m *MockNETCONFExecutor) Get(_ context.Context, filter string) ([]byte, error) {
+func (m *MockNETCONFExecutor) Get(_ context.Context, filter string, _ ...netconf.GetOption) ([]byte, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.Calls = append(m.Calls, "Get:"+filter)
@@ -449,7 +479,7 @@ func (m *MockNETCONFExecutor) Get(_ context.Context, filter string) ([]byte, err
 	return []byte("<data/>"), nil
 }
 
-func (m *MockNETCONFExecutor) GetConfig(_ context.Context, source, filter string) ([]byte, error) {
+func (m *MockNETCONFExecutor) GetConfig(_ context.Context, source, filter string, _ ...netconf.GetOption) ([]byte, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	key := source + "|" + filter
@@ -474,6 +504,13 @@ func (m *MockNETCONFExecutor) EditConfig(_ context.Context, _, _ string, _ ...ne
 	return m.EditConfigError
 }
 
+func (m *MockNETCONFExecutor) CopyConfig(_ context.Context, source, target string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Calls = append(m.Calls, "CopyConfig:"+source+"->"+target)
+	return m.CopyConfigError
+}
+
 func (m *MockNETCONFExecutor) Commit(_ context.Context) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -495,6 +532,20 @@ func (m *MockNETCONFExecutor) Unlock(_ context.Context, target string) error {
 	return m.UnlockError
 }
 
+func (m *MockNETCONFExecutor) Validate(_ context.Context, source string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Calls = append(m.Calls, "Validate:"+source)
+	return m.ValidateError
+}
+
+func (m *MockNETCONFExecutor) ValidateConfig(_ context.Context, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Calls = append(m.Calls, "ValidateConfig")
+	return m.ValidateError
+}
+
 func (m *MockNETCONFExecutor) HasCapability(cap string) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -521,16 +572,16 @@ func (m *MockDriver) RPC(ctx context.Context, operation string) ([]byte, error)
 	return nil, fmt.Errorf("NETCONF executor not available")
 }
 
-func (m *MockDriver) Get(ctx context.Context, filter string) ([]byte, error) {
+func (m *MockDriver) Get(ctx context.Context, filter string, options ...netconf.GetOption) ([]byte, error) {
 	if m.NETCONFExec != nil {
-		return m.NETCONFExec.Get(ctx, filter)
+		return m.NETCONFExec.Get(ctx, filter, options...)
 	}
 	return nil, fmt.Errorf("NETCONF executor not available")
 }
 
-func (m *MockDriver) GetConfig(ctx context.Context, source, filter string) ([]byte, error) {
+func (m *MockDriver) GetConfig(ctx context.Context, source, filter string, options ...netconf.GetOption) ([]byte, error) {
 	if m.NETCONFExec != nil {
-		return m.NETCONFExec.GetConfig(ctx, source, filter)
+		return m.NETCONFExec.GetConfig(ctx, source, filter, options...)
 	}
 	return nil, fmt.Errorf("NETCONF executor not available")
 }
@@ -542,6 +593,13 @@ func (m *MockDriver) EditConfig(ctx context.Context, target, config string, opti
 	return fmt.Errorf("NETCONF executor not available")
 }
 
+func (m *MockDriver) CopyConfig(ctx context.Context, source, target string) error {
+	if m.NETCONFExec != nil {
+		return m.NETCONFExec.CopyConfig(ctx, source, target)
+	}
+	return fmt.Errorf("NETCONF executor not available")
+}
+
 func (m *MockDriver) Commit(ctx context.Context) error {
 	if m.NETCONFExec != nil {
 		return m.NETCONFExec.Commit(ctx)
@@ -563,6 +621,20 @@ func (m *MockDriver) Unlock(ctx context.Context, target string) error {
 	return fmt.Errorf("NETCONF executor not available")
 }
 
+func (m *MockDriver) Validate(ctx context.Context, source string) error {
+	if m.NETCONFExec != nil {
+		return m.NETCONFExec.Validate(ctx, source)
+	}
+	return fmt.Errorf("NETCONF executor not available")
+}
+
+func (m *MockDriver) ValidateConfig(ctx context.Context, config string) error {
+	if m.NETCONFExec != nil {
+		return m.NETCONFExec.ValidateConfig(ctx, config)
+	}
+	return fmt.Errorf("NETCONF executor not available")
+}
+
 func (m *MockDriver) HasCapability(cap string) bool {
 	if m.NETCONFExec != nil {
 		return m.NETCONFExec.HasCapability(cap)
@@ -0,0 +1,334 @@
+// Package thresholds evaluates optical and environmental readings against
+// configurable warning and critical limits.
+//
+// Limits are resolved per metric from, in order of precedence, the ONU's
+// serial, the ONU's service tier and the global defaults. Levels rise as
+// soon as a limit is crossed but only fall once the value is back inside
+// the limit by Hysteresis, so readings hovering around a limit do not
+// alert on every poll.
+package thresholds
+
+import (
+	"sync"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Metric is an optical or environmental measurement
+type Metric string
+
+// Metrics evaluated by the engine
+const (
+	// MetricONURx is the power received by the ONU (ONUPowerReading.RxPowerDBm)
+	MetricONURx Metric = "onu_rx_power_dbm"
+	// MetricONUTx is the power sent by the ONU (ONUPowerReading.TxPowerDBm)
+	MetricONUTx Metric = "onu_tx_power_dbm"
+	// MetricOLTRx is the power received from the ONU at the OLT
+	// (ONUPowerReading.OLTRxDBm)
+	MetricOLTRx Metric = "olt_rx_power_dbm"
+	// MetricPONTx is the OLT transmit power of a PON port
+	MetricPONTx Metric = "pon_tx_power_dbm"
+	// MetricPONTemperature is the PON SFP temperature in Celsius
+	MetricPONTemperature Metric = "pon_temperature_c"
+	// MetricSensorTemperature is an OLT temperature sensor in Celsius
+	// (TemperatureSensor.Celsius)
+	MetricSensorTemperature Metric = "sensor_temperature_c"
+	// MetricFanSpeed is the speed of an OLT fan in RPM (FanStatus.SpeedRPM).
+	// Fan speeds depend on the chassis, so it has no default limits.
+	MetricFanSpeed Metric = "fan_speed_rpm"
+)
+
+// Level is the severity of a reading
+type Level int
+
+// Levels in increasing severity
+const (
+	LevelNormal Level = iota
+	LevelWarning
+	LevelCritical
+)
+
+// MarshalText encodes the level by name
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l Level) String() string {
+	switch l {
+	case LevelWarning:
+		return "warning"
+	case LevelCritical:
+		return "critical"
+	default:
+		return "normal"
+	}
+}
+
+// Range is an inclusive [Low, High] band. A zero Range is unset.
+type Range struct {
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// IsSet reports whether r has limits
+func (r Range) IsSet() bool {
+	return r.Low != 0 || r.High != 0
+}
+
+// Limits are the normal ranges of one metric. Values outside Warning are a
+// warning and values outside Critical are critical; either may be unset.
+type Limits struct {
+	Warning  Range `json:"warning"`
+	Critical Range `json:"critical"`
+}
+
+// Thresholds maps metrics to their limits
+type Thresholds map[Metric]Limits
+
+// DefaultThresholds are conservative GPON class B+ limits, matching
+// types.GPONRxLowThreshold and friends at the critical level, and the
+// operating range of typical OLT chassis for sensor temperatures
+var DefaultThresholds = Thresholds{
+	MetricONURx:          {Warning: Range{-25, -9}, Critical: Range{types.GPONRxLowThreshold, types.GPONRxHighThreshold}},
+	MetricONUTx:          {Warning: Range{1, 4.5}, Critical: Range{types.GPONTxLowThreshold, types.GPONTxHighThreshold}},
+	MetricOLTRx:          {Warning: Range{-27, -10}, Critical: Range{-30, -8}},
+	MetricPONTx:          {Warning: Range{2, 6}, Critical: Range{1.5, 7}},
+	MetricPONTemperature: {Warning: Range{0, 70}, Critical: Range{-10, 80}},
+
+	MetricSensorTemperature: {Warning: Range{-5, 65}, Critical: Range{-10, 75}},
+}
+
+// DefaultHysteresis is the Config.Hysteresis used when it is zero
+const DefaultHysteresis = 1.0
+
+// Config configures an Engine
+type Config struct {
+	// Global limits apply to every reading. Defaults to DefaultThresholds.
+	Global Thresholds
+
+	// Tiers overrides limits per service tier name
+	Tiers map[string]Thresholds
+
+	// ONUs overrides limits per ONU serial
+	ONUs map[string]Thresholds
+
+	// TierOf returns the service tier of an ONU serial, or "" if unknown
+	TierOf func(serial string) string
+
+	// Hysteresis is how far back inside a limit, in the metric's unit, a
+	// value must be before its level falls. Defaults to DefaultHysteresis;
+	// negative disables hysteresis.
+	Hysteresis float64
+}
+
+// Alert is a change of level of one metric
+type Alert struct {
+	Device string `json:"device"`
+
+	// Object is the reading key for ONUs, as returned by
+	// GetBulkONUOpticalSNMP, the PON port, or the name of a temperature
+	// sensor or fan
+	Object  string `json:"object"`
+	PONPort string `json:"pon_port"`
+	ONUID   int    `json:"onu_id,omitempty"`
+	Serial  string `json:"serial,omitempty"`
+
+	Metric   Metric  `json:"metric"`
+	Value    float64 `json:"value"`
+	Level    Level   `json:"level"`
+	Previous Level   `json:"previous"`
+
+	// Direction is "low" or "high" for warning and critical levels
+	Direction string `json:"direction,omitempty"`
+
+	Limits Limits `json:"limits"`
+}
+
+// Engine evaluates readings and remembers the level of every metric, so
+// that only changes are reported. It is safe for concurrent use.
+type Engine struct {
+	config Config
+
+	mu     sync.Mutex
+	levels map[string]map[string]Level // device -> object/metric -> level
+}
+
+// NewEngine creates an Engine
+func NewEngine(config Config) *Engine {
+	if config.Global == nil {
+		config.Global = DefaultThresholds
+	}
+	if config.Hysteresis == 0 {
+		config.Hysteresis = DefaultHysteresis
+	}
+	if config.Hysteresis < 0 {
+		config.Hysteresis = 0
+	}
+	return &Engine{config: config, levels: make(map[string]map[string]Level)}
+}
+
+// Limits returns the limits of metric for an ONU serial, which may be
+// empty, after applying tier and ONU overrides
+func (e *Engine) Limits(serial string, metric Metric) (Limits, bool) {
+	if l, ok := e.config.ONUs[serial][metric]; ok && serial != "" {
+		return l, true
+	}
+	if serial != "" && e.config.TierOf != nil {
+		if l, ok := e.config.Tiers[e.config.TierOf(serial)][metric]; ok {
+			return l, true
+		}
+	}
+	l, ok := e.config.Global[metric]
+	return l, ok
+}
+
+// EvaluateONUs evaluates bulk ONU readings of device, keyed as returned by
+// GetBulkONUOpticalSNMP, and returns the metrics whose level changed. Zero
+// values are treated as not reported.
+func (e *Engine) EvaluateONUs(device string, readings map[string]*types.ONUPowerReading) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var alerts []Alert
+	for key, r := range readings {
+		if r == nil {
+			continue
+		}
+		base := Alert{Device: device, Object: key, PONPort: r.PONPort, ONUID: r.ONUID, Serial: r.Serial}
+		for _, m := range []struct {
+			metric Metric
+			value  float64
+		}{
+			{MetricONURx, r.RxPowerDBm},
+			{MetricONUTx, r.TxPowerDBm},
+			{MetricOLTRx, r.OLTRxDBm},
+		} {
+			if a, ok := e.evaluate(base, r.Serial, m.metric, m.value); ok {
+				alerts = append(alerts, a)
+			}
+		}
+	}
+	return alerts
+}
+
+// EvaluatePON evaluates a PON port reading of device
+func (e *Engine) EvaluatePON(device string, r *types.PONPowerReading) []Alert {
+	if r == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var alerts []Alert
+	base := Alert{Device: device, Object: r.PONPort, PONPort: r.PONPort}
+	if a, ok := e.evaluate(base, "", MetricPONTx, r.TxPowerDBm); ok {
+		alerts = append(alerts, a)
+	}
+	if a, ok := e.evaluate(base, "", MetricPONTemperature, r.Temperature); ok {
+		alerts = append(alerts, a)
+	}
+	return alerts
+}
+
+// EvaluateEnvironment evaluates the temperature sensors and fan speeds of
+// device. Fan and power supply failures are alarms, not readings; see
+// types.EnvironmentStatus.Alarms.
+func (e *Engine) EvaluateEnvironment(device string, env *types.EnvironmentStatus) []Alert {
+	if env == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var alerts []Alert
+	for _, s := range env.Temperatures {
+		if a, ok := e.evaluate(Alert{Device: device, Object: s.Name}, "", MetricSensorTemperature, s.Celsius); ok {
+			alerts = append(alerts, a)
+		}
+	}
+	for _, f := range env.Fans {
+		if a, ok := e.evaluate(Alert{Device: device, Object: f.Name}, "", MetricFanSpeed, float64(f.SpeedRPM)); ok {
+			alerts = append(alerts, a)
+		}
+	}
+	return alerts
+}
+
+// Level returns the current level of a metric
+func (e *Engine) Level(device, object string, metric Metric) Level {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.levels[device][object+"/"+string(metric)]
+}
+
+// Forget drops the levels of device
+func (e *Engine) Forget(device string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.levels, device)
+}
+
+// evaluate updates the level of one metric and returns an Alert if it
+// changed. The caller holds e.mu.
+func (e *Engine) evaluate(base Alert, serial string, metric Metric, value float64) (Alert, bool) {
+	if value == 0 {
+		return Alert{}, false
+	}
+	limits, ok := e.Limits(serial, metric)
+	if !ok || (!limits.Warning.IsSet() && !limits.Critical.IsSet()) {
+		return Alert{}, false
+	}
+
+	levels := e.levels[base.Device]
+	if levels == nil {
+		levels = make(map[string]Level)
+		e.levels[base.Device] = levels
+	}
+	k := base.Object + "/" + string(metric)
+	prev := levels[k]
+	level := nextLevel(value, limits, prev, e.config.Hysteresis)
+	levels[k] = level
+	if level == prev {
+		return Alert{}, false
+	}
+
+	a := base
+	a.Metric, a.Value, a.Level, a.Previous, a.Limits = metric, value, level, prev, limits
+	if level != LevelNormal {
+		a.Direction = direction(value, limits)
+	}
+	return a, true
+}
+
+// nextLevel rises to the level of value immediately and falls only to the
+// level value would have with every range narrowed by hysteresis
+func nextLevel(value float64, l Limits, prev Level, hysteresis float64) Level {
+	raw := levelOf(value, l, 0)
+	if raw >= prev {
+		return raw
+	}
+	return min(levelOf(value, l, hysteresis), prev)
+}
+
+// levelOf classifies value against l with every range narrowed by margin
+func levelOf(value float64, l Limits, margin float64) Level {
+	outside := func(r Range) bool {
+		return r.IsSet() && (value < r.Low+margin || value > r.High-margin)
+	}
+	switch {
+	case outside(l.Critical):
+		return LevelCritical
+	case outside(l.Warning):
+		return LevelWarning
+	default:
+		return LevelNormal
+	}
+}
+
+func direction(value float64, l Limits) string {
+	r := l.Warning
+	if !r.IsSet() {
+		r = l.Critical
+	}
+	if value < (r.Low+r.High)/2 {
+		return "low"
+	}
+	return "high"
+}
@@ -0,0 +1,126 @@
+package thresholds
+
+import (
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func onu(serial string, rx float64) map[string]*types.ONUPowerReading {
+	return map[string]*types.ONUPowerReading{"0/1:1": {PONPort: "0/1", ONUID: 1, Serial: serial, RxPowerDBm: rx}}
+}
+
+func TestHysteresis(t *testing.T) {
+	e := NewEngine(Config{})
+	steps := []struct {
+		rx    float64
+		level Level
+		alert bool
+	}{
+		{-20, LevelNormal, false},
+		{-25.5, LevelWarning, true},  // below warning low -25
+		{-24.5, LevelWarning, false}, // back inside, but not by 1 dB
+		{-28.5, LevelCritical, true}, // below critical low -28 rises at once
+		{-27.5, LevelCritical, false},
+		{-26.5, LevelWarning, true}, // inside critical by 1 dB, still outside warning
+		{-23.9, LevelNormal, true},
+	}
+	for i, s := range steps {
+		alerts := e.EvaluateONUs("olt-1", onu("", s.rx))
+		if got := e.Level("olt-1", "0/1:1", MetricONURx); got != s.level {
+			t.Errorf("step %d (%v dBm): level %s, want %s", i, s.rx, got, s.level)
+		}
+		if (len(alerts) == 1) != s.alert {
+			t.Errorf("step %d (%v dBm): alerts %+v, want alert %v", i, s.rx, alerts, s.alert)
+		}
+	}
+}
+
+func TestAlert(t *testing.T) {
+	e := NewEngine(Config{})
+	alerts := e.EvaluateONUs("olt-1", onu("HWTC00000001", -29))
+	if len(alerts) != 1 {
+		t.Fatalf("got %+v, want one alert", alerts)
+	}
+	a := alerts[0]
+	if a.Device != "olt-1" || a.Serial != "HWTC00000001" || a.Metric != MetricONURx ||
+		a.Level != LevelCritical || a.Previous != LevelNormal || a.Direction != "low" {
+		t.Errorf("alert = %+v", a)
+	}
+
+	// Zero values are not reported and do not clear the level
+	if alerts := e.EvaluateONUs("olt-1", onu("HWTC00000001", 0)); len(alerts) != 0 {
+		t.Errorf("zero reading produced %+v", alerts)
+	}
+	if e.Level("olt-1", "0/1:1", MetricONURx) != LevelCritical {
+		t.Error("zero reading changed the level")
+	}
+
+	e.Forget("olt-1")
+	if e.Level("olt-1", "0/1:1", MetricONURx) != LevelNormal {
+		t.Error("Forget kept the level")
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	long := Limits{Critical: Range{-32, -8}}
+	e := NewEngine(Config{
+		Tiers:  map[string]Thresholds{"long-reach": {MetricONURx: long}},
+		ONUs:   map[string]Thresholds{"VIP00000001": {MetricONURx: {Warning: Range{-20, -9}}}},
+		TierOf: func(serial string) string { return map[string]string{"LR000001": "long-reach"}[serial] },
+	})
+
+	tests := []struct {
+		serial string
+		rx     float64
+		want   Level
+	}{
+		{"OTHER001", -29, LevelCritical}, // global
+		{"LR000001", -29, LevelNormal},   // tier allows -32
+		{"LR000001", -33, LevelCritical},
+		{"VIP00000001", -21, LevelWarning}, // ONU override
+		{"VIP00000001", -29, LevelWarning}, // override has no critical range
+	}
+	for _, tt := range tests {
+		e.Forget("olt-1")
+		e.EvaluateONUs("olt-1", onu(tt.serial, tt.rx))
+		if got := e.Level("olt-1", "0/1:1", MetricONURx); got != tt.want {
+			t.Errorf("%s at %v dBm: level %s, want %s", tt.serial, tt.rx, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluatePON(t *testing.T) {
+	e := NewEngine(Config{Hysteresis: -1})
+	if got := e.EvaluatePON("olt-1", &types.PONPowerReading{PONPort: "0/1", TxPowerDBm: 1.8}); len(got) != 1 || got[0].Level != LevelWarning {
+		t.Fatalf("got %+v, want PON tx warning", got)
+	}
+	// Without hysteresis the level falls as soon as the value is back inside
+	if got := e.EvaluatePON("olt-1", &types.PONPowerReading{PONPort: "0/1", TxPowerDBm: 2.1}); len(got) != 1 || got[0].Level != LevelNormal {
+		t.Errorf("got %+v, want PON tx back to normal", got)
+	}
+}
+
+func TestEvaluateEnvironment(t *testing.T) {
+	e := NewEngine(Config{Global: Thresholds{
+		MetricSensorTemperature: DefaultThresholds[MetricSensorTemperature],
+		MetricFanSpeed:          {Critical: Range{1500, 9000}},
+	}})
+	env := &types.EnvironmentStatus{
+		Temperatures: []types.TemperatureSensor{{Name: "0/1", Celsius: 45}, {Name: "0/8", Celsius: 68}},
+		Fans:         []types.FanStatus{{Name: "0", SpeedRPM: 4200}, {Name: "1", SpeedRPM: 900}},
+	}
+	alerts := e.EvaluateEnvironment("olt-1", env)
+	if len(alerts) != 2 {
+		t.Fatalf("got %+v, want two alerts", alerts)
+	}
+	if a := alerts[0]; a.Object != "0/8" || a.Metric != MetricSensorTemperature || a.Level != LevelWarning || a.Direction != "high" {
+		t.Errorf("alerts[0] = %+v", a)
+	}
+	if a := alerts[1]; a.Object != "1" || a.Metric != MetricFanSpeed || a.Level != LevelCritical || a.Direction != "low" {
+		t.Errorf("alerts[1] = %+v", a)
+	}
+	if e.EvaluateEnvironment("olt-1", nil) != nil {
+		t.Error("nil environment produced alerts")
+	}
+}
@@ -0,0 +1,290 @@
+package topology
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nanoncore/nano-southbound/inventory"
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
+)
+
+// DefaultClusterGapM is the Config.ClusterGapM used when it is zero
+const DefaultClusterGapM = 150
+
+// Config configures a Builder
+type Config struct {
+	// ClusterGapM is the distance gap, in meters, that separates two
+	// clusters of ONUs on a port. ONUs closer to each other than this are
+	// placed behind the same splitter. Defaults to DefaultClusterGapM.
+	ClusterGapM int
+}
+
+// Splitter is a splitter declared by the operator
+type Splitter struct {
+	ID      string
+	OLT     string
+	PONPort string
+
+	// Parent is the splitter feeding this one, empty if it is fed by the
+	// PON port directly
+	Parent string
+
+	// ParentPort is the output of Parent this splitter is on
+	ParentPort int
+
+	Ratio     int
+	DistanceM int
+}
+
+// Hint places an ONU on a declared splitter
+type Hint struct {
+	Serial   string
+	Splitter string
+	Port     int // splitter output, 0 if unknown
+}
+
+// Builder assembles a Graph from ONU lists, declared splitters and hints
+type Builder struct {
+	config    Config
+	onus      map[string][]types.ONUInfo // by OLT
+	splitters []Splitter
+	hints     map[string]Hint // by serial key
+}
+
+// NewBuilder creates an empty Builder
+func NewBuilder(config Config) *Builder {
+	if config.ClusterGapM <= 0 {
+		config.ClusterGapM = DefaultClusterGapM
+	}
+	return &Builder{
+		config: config,
+		onus:   make(map[string][]types.ONUInfo),
+		hints:  make(map[string]Hint),
+	}
+}
+
+// AddONUs adds the ONUs of an OLT, e.g. from GetONUList. Fiber distances
+// are used to infer splitters.
+func (b *Builder) AddONUs(olt string, onus []types.ONUInfo) {
+	b.onus[olt] = append(b.onus[olt], onus...)
+}
+
+// AddInventory adds the ONUs of every OLT in an inventory snapshot
+func (b *Builder) AddInventory(snap *inventory.Snapshot) {
+	for _, olt := range snap.OLTs {
+		onus := make([]types.ONUInfo, len(olt.ONUs))
+		for i, o := range olt.ONUs {
+			onus[i] = types.ONUInfo{PONPort: o.PONPort, ONUID: o.ONUID, Serial: o.Serial, DistanceM: o.DistanceM}
+		}
+		b.AddONUs(olt.Name, onus)
+	}
+}
+
+// AddSplitter declares a splitter
+func (b *Builder) AddSplitter(s Splitter) {
+	b.splitters = append(b.splitters, s)
+}
+
+// AddHint places an ONU on a declared splitter, overriding inference. The
+// serial may be given in any of its representations.
+func (b *Builder) AddHint(h Hint) {
+	b.hints[common.SerialKey(h.Serial)] = h
+}
+
+// Build assembles the graph. It fails on splitters with duplicate or
+// missing IDs, unknown parents, or parents on another port, and on hints
+// naming unknown splitters.
+func (b *Builder) Build() (*Graph, error) {
+	g := &Graph{nodes: make(map[string]*Node)}
+	add := func(n *Node) {
+		g.nodes[n.ID] = n
+		if p, ok := g.nodes[n.Parent]; ok {
+			p.Children = append(p.Children, n.ID)
+		}
+	}
+	port := func(olt, ponPort string) string {
+		if _, ok := g.nodes[olt]; !ok {
+			add(&Node{ID: olt, Kind: KindOLT, OLT: olt})
+			g.olts = append(g.olts, olt)
+		}
+		id := portID(olt, ponPort)
+		if _, ok := g.nodes[id]; !ok {
+			add(&Node{ID: id, Kind: KindPort, OLT: olt, PONPort: ponPort, Parent: olt})
+		}
+		return id
+	}
+
+	// Declared splitters, parents first
+	declared := make(map[string]Splitter, len(b.splitters))
+	for _, s := range b.splitters {
+		if s.ID == "" {
+			return nil, fmt.Errorf("splitter ID is required")
+		}
+		if _, ok := declared[s.ID]; ok {
+			return nil, fmt.Errorf("duplicate splitter %q", s.ID)
+		}
+		declared[s.ID] = s
+	}
+	var addSplitter func(s Splitter, seen map[string]bool) error
+	addSplitter = func(s Splitter, seen map[string]bool) error {
+		if _, ok := g.nodes[s.ID]; ok {
+			return nil
+		}
+		if seen[s.ID] {
+			return fmt.Errorf("splitter %q is its own ancestor", s.ID)
+		}
+		seen[s.ID] = true
+		parent := port(s.OLT, s.PONPort)
+		if s.Parent != "" {
+			p, ok := declared[s.Parent]
+			if !ok {
+				return fmt.Errorf("splitter %q: unknown parent %q", s.ID, s.Parent)
+			}
+			if p.OLT != s.OLT || p.PONPort != s.PONPort {
+				return fmt.Errorf("splitter %q: parent %q is on another PON port", s.ID, s.Parent)
+			}
+			if err := addSplitter(p, seen); err != nil {
+				return err
+			}
+			parent = p.ID
+		}
+		add(&Node{ID: s.ID, Kind: KindSplitter, OLT: s.OLT, PONPort: s.PONPort, Parent: parent,
+			DistanceM: s.DistanceM, Ratio: s.Ratio, SplitterPort: s.ParentPort})
+		return nil
+	}
+	for _, s := range b.splitters {
+		if err := addSplitter(s, map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+
+	olts := make([]string, 0, len(b.onus))
+	for olt := range b.onus {
+		olts = append(olts, olt)
+	}
+	sort.Strings(olts)
+	for _, olt := range olts {
+		byPort := make(map[string][]types.ONUInfo)
+		for _, onu := range b.onus[olt] {
+			byPort[onu.PONPort] = append(byPort[onu.PONPort], onu)
+		}
+		ports := make([]string, 0, len(byPort))
+		for p := range byPort {
+			ports = append(ports, p)
+		}
+		sort.Strings(ports)
+		for _, p := range ports {
+			if err := b.placePort(g, port(olt, p), olt, p, byPort[p], add); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Strings(g.olts)
+	for _, n := range g.nodes {
+		sort.Slice(n.Children, func(i, j int) bool { return g.less(n.Children[i], n.Children[j]) })
+	}
+	return g, nil
+}
+
+// placePort attaches the ONUs of one PON port: hinted ONUs to their
+// splitter, the others by distance cluster
+func (b *Builder) placePort(g *Graph, portNode, olt, ponPort string, onus []types.ONUInfo, add func(*Node)) error {
+	var free []types.ONUInfo
+	for _, onu := range onus {
+		h, ok := b.hints[common.SerialKey(onu.Serial)]
+		if !ok {
+			free = append(free, onu)
+			continue
+		}
+		s, ok := g.nodes[h.Splitter]
+		if !ok || s.Kind != KindSplitter {
+			return fmt.Errorf("ONU %s: unknown splitter %q", onu.Serial, h.Splitter)
+		}
+		add(onuNode(olt, onu, s.ID, h.Port, false))
+	}
+
+	// ONUs without a distance cannot be placed and hang off the port
+	sort.Slice(free, func(i, j int) bool { return free[i].DistanceM < free[j].DistanceM })
+	var clusters [][]types.ONUInfo
+	for _, onu := range free {
+		if onu.DistanceM <= 0 {
+			add(onuNode(olt, onu, portNode, 0, false))
+			continue
+		}
+		n := len(clusters)
+		if n == 0 || onu.DistanceM-clusters[n-1][len(clusters[n-1])-1].DistanceM > b.config.ClusterGapM {
+			clusters = append(clusters, nil)
+			n++
+		}
+		clusters[n-1] = append(clusters[n-1], onu)
+	}
+
+	// Each cluster goes to the nearest declared leaf splitter of the port
+	// within the gap, or to a splitter inferred at the cluster's start
+	leaves := g.leafSplitters(portNode)
+	for i, cluster := range clusters {
+		parent := ""
+		best := b.config.ClusterGapM + 1
+		for _, s := range leaves {
+			if d := abs(cluster[0].DistanceM - s.DistanceM); s.DistanceM > 0 && d < best {
+				parent, best = s.ID, d
+			}
+		}
+		if parent == "" {
+			parent = fmt.Sprintf("%s:auto-%d", portNode, i+1)
+			add(&Node{ID: parent, Kind: KindSplitter, OLT: olt, PONPort: ponPort, Parent: portNode,
+				DistanceM: cluster[0].DistanceM, Inferred: true})
+		}
+		for _, onu := range cluster {
+			add(onuNode(olt, onu, parent, 0, true))
+		}
+	}
+	return nil
+}
+
+// leafSplitters returns the splitters under id that feed no other splitter
+func (g *Graph) leafSplitters(id string) []*Node {
+	var leaves []*Node
+	g.walk(id, func(n *Node) {
+		if n.Kind != KindSplitter {
+			return
+		}
+		for _, c := range n.Children {
+			if g.nodes[c].Kind == KindSplitter {
+				return
+			}
+		}
+		leaves = append(leaves, n)
+	})
+	return leaves
+}
+
+func onuNode(olt string, onu types.ONUInfo, parent string, port int, inferred bool) *Node {
+	return &Node{ID: onu.Serial, Kind: KindONU, OLT: olt, PONPort: onu.PONPort, Parent: parent,
+		DistanceM: onu.DistanceM, ONUID: onu.ONUID, SplitterPort: port, Inferred: inferred}
+}
+
+// less orders siblings: splitters before ONUs, then by splitter port,
+// distance and ID
+func (g *Graph) less(a, b string) bool {
+	na, nb := g.nodes[a], g.nodes[b]
+	if na.Kind != nb.Kind {
+		return na.Kind != KindONU && nb.Kind == KindONU
+	}
+	if na.SplitterPort != nb.SplitterPort {
+		return na.SplitterPort < nb.SplitterPort
+	}
+	if na.DistanceM != nb.DistanceM {
+		return na.DistanceM < nb.DistanceM
+	}
+	return na.ID < nb.ID
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
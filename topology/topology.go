@@ -0,0 +1,233 @@
+// Package topology models the passive optical network behind each OLT: PON
+// ports feed splitters, splitters feed further splitters or ONUs. OLTs do
+// not report splitters, so the tree is assembled from what the operator
+// knows (declared splitters and ONU placement hints) and, for the rest,
+// inferred by clustering the ONUs of a port by fiber distance: ONUs at
+// about the same distance most likely hang off the same splitter.
+//
+// The resulting Graph answers outage questions: which ONUs are behind a
+// splitter, and which splitter or port most likely failed given the ONUs
+// that went down.
+package topology
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kind is the kind of a node
+type Kind string
+
+// Node kinds
+const (
+	KindOLT      Kind = "olt"
+	KindPort     Kind = "port"
+	KindSplitter Kind = "splitter"
+	KindONU      Kind = "onu"
+)
+
+// Node is an element of the network. IDs are the OLT name for OLTs,
+// "<olt>:<port>" for PON ports, the declared ID for splitters (or
+// "<olt>:<port>:auto-N" when inferred) and the serial for ONUs.
+type Node struct {
+	ID       string   `json:"id"`
+	Kind     Kind     `json:"kind"`
+	OLT      string   `json:"olt"`
+	PONPort  string   `json:"pon_port,omitempty"`
+	Parent   string   `json:"parent,omitempty"`
+	Children []string `json:"children,omitempty"`
+
+	// DistanceM is the fiber distance from the OLT, 0 if unknown
+	DistanceM int `json:"distance_m,omitempty"`
+
+	// Ratio is the split ratio of a splitter, 0 if unknown
+	Ratio int `json:"ratio,omitempty"`
+
+	// SplitterPort is the splitter output an ONU or splitter is on, 0 if
+	// unknown
+	SplitterPort int `json:"splitter_port,omitempty"`
+
+	// ONUID is the ONU ID of an ONU on its PON port
+	ONUID int `json:"onu_id,omitempty"`
+
+	// Inferred marks splitters and placements derived from distances
+	// rather than declared by the operator
+	Inferred bool `json:"inferred,omitempty"`
+}
+
+// Graph is a built topology. It is read-only and safe for concurrent use.
+type Graph struct {
+	nodes map[string]*Node
+	olts  []string
+}
+
+// Node returns the node with the given ID
+func (g *Graph) Node(id string) (*Node, bool) {
+	n, ok := g.nodes[id]
+	return n, ok
+}
+
+// OLTs returns the OLT nodes, sorted by name
+func (g *Graph) OLTs() []*Node {
+	nodes := make([]*Node, len(g.olts))
+	for i, id := range g.olts {
+		nodes[i] = g.nodes[id]
+	}
+	return nodes
+}
+
+// Children returns the nodes fed by id
+func (g *Graph) Children(id string) []*Node {
+	n, ok := g.nodes[id]
+	if !ok {
+		return nil
+	}
+	children := make([]*Node, len(n.Children))
+	for i, c := range n.Children {
+		children[i] = g.nodes[c]
+	}
+	return children
+}
+
+// Path returns the nodes from the OLT down to id, or nil if id is unknown
+func (g *Graph) Path(id string) []*Node {
+	var path []*Node
+	for n, ok := g.nodes[id]; ok; n, ok = g.nodes[n.Parent] {
+		path = append(path, n)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Splitters returns the splitters of a PON port, in tree order
+func (g *Graph) Splitters(olt, ponPort string) []*Node {
+	var splitters []*Node
+	g.walk(portID(olt, ponPort), func(n *Node) {
+		if n.Kind == KindSplitter {
+			splitters = append(splitters, n)
+		}
+	})
+	return splitters
+}
+
+// Downstream returns the ONUs behind id: every ONU whose service depends
+// on it. For an ONU it is the ONU itself.
+func (g *Graph) Downstream(id string) []*Node {
+	var onus []*Node
+	g.walk(id, func(n *Node) {
+		if n.Kind == KindONU {
+			onus = append(onus, n)
+		}
+	})
+	return onus
+}
+
+// walk visits id and the nodes below it, depth first in child order
+func (g *Graph) walk(id string, visit func(*Node)) {
+	n, ok := g.nodes[id]
+	if !ok {
+		return
+	}
+	visit(n)
+	for _, c := range n.Children {
+		g.walk(c, visit)
+	}
+}
+
+// Suspect is a node whose failure would explain a set of ONUs going down
+type Suspect struct {
+	Node *Node
+	// Down is the number of ONUs behind the node, all of them down
+	Down int
+}
+
+// Suspects returns the likely points of failure for the ONUs with the
+// given serials going down: the topmost ports and splitters whose every
+// ONU is down, most ONUs first. ONUs down alone are returned as
+// themselves. Unknown serials are ignored.
+func (g *Graph) Suspects(down []string) []Suspect {
+	isDown := make(map[string]bool, len(down))
+	for _, s := range down {
+		if n, ok := g.nodes[s]; ok && n.Kind == KindONU {
+			isDown[s] = true
+		}
+	}
+
+	// allDown[id] is the number of ONUs behind id if all are down, else -1
+	allDown := make(map[string]int)
+	var count func(n *Node) int
+	count = func(n *Node) int {
+		if n.Kind == KindONU {
+			if isDown[n.ID] {
+				allDown[n.ID] = 1
+				return 1
+			}
+			allDown[n.ID] = -1
+			return -1
+		}
+		total := 0
+		for _, c := range n.Children {
+			if k := count(g.nodes[c]); k < 0 || total < 0 {
+				total = -1
+			} else {
+				total += k
+			}
+		}
+		if total == 0 {
+			total = -1 // nothing behind it to explain
+		}
+		allDown[n.ID] = total
+		return total
+	}
+	for _, id := range g.olts {
+		count(g.nodes[id])
+	}
+
+	var suspects []Suspect
+	var collect func(n *Node)
+	collect = func(n *Node) {
+		if k := allDown[n.ID]; k > 0 {
+			suspects = append(suspects, Suspect{Node: n, Down: k})
+			return
+		}
+		for _, c := range n.Children {
+			collect(g.nodes[c])
+		}
+	}
+	for _, id := range g.olts {
+		collect(g.nodes[id])
+	}
+	sort.SliceStable(suspects, func(i, j int) bool { return suspects[i].Down > suspects[j].Down })
+	return suspects
+}
+
+func portID(olt, ponPort string) string {
+	return olt + ":" + ponPort
+}
+
+// String renders the tree of the graph, one node per line, for logs and
+// debugging
+func (g *Graph) String() string {
+	var b strings.Builder
+	var render func(n *Node, depth int)
+	render = func(n *Node, depth int) {
+		fmt.Fprintf(&b, "%s%s %s", strings.Repeat("  ", depth), n.Kind, n.ID)
+		if n.DistanceM > 0 {
+			fmt.Fprintf(&b, " @%dm", n.DistanceM)
+		}
+		if n.Inferred {
+			b.WriteString(" (inferred)")
+		}
+		b.WriteByte('\n')
+		for _, c := range n.Children {
+			render(g.nodes[c], depth+1)
+		}
+	}
+	for _, id := range g.olts {
+		render(g.nodes[id], 0)
+	}
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package topology
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func ids(nodes []*Node) string {
+	s := make([]string, len(nodes))
+	for i, n := range nodes {
+		s[i] = n.ID
+	}
+	return strings.Join(s, ",")
+}
+
+func buildFixture(t *testing.T) *Graph {
+	t.Helper()
+	b := NewBuilder(Config{ClusterGapM: 100})
+	b.AddSplitter(Splitter{ID: "cab-7", OLT: "olt-1", PONPort: "0/1", Ratio: 8, DistanceM: 1000})
+	b.AddSplitter(Splitter{ID: "cab-7a", OLT: "olt-1", PONPort: "0/1", Parent: "cab-7", ParentPort: 1, Ratio: 4, DistanceM: 2000})
+	b.AddHint(Hint{Serial: "ONU5", Splitter: "cab-7", Port: 3})
+	b.AddONUs("olt-1", []types.ONUInfo{
+		{PONPort: "0/1", ONUID: 1, Serial: "ONU1", DistanceM: 2030},
+		{PONPort: "0/1", ONUID: 2, Serial: "ONU2", DistanceM: 2010},
+		{PONPort: "0/1", ONUID: 3, Serial: "ONU3", DistanceM: 4500},
+		{PONPort: "0/1", ONUID: 4, Serial: "ONU4", DistanceM: 4560},
+		{PONPort: "0/1", ONUID: 5, Serial: "ONU5", DistanceM: 1100},
+		{PONPort: "0/1", ONUID: 6, Serial: "ONU6"},
+		{PONPort: "0/2", ONUID: 1, Serial: "ONU7", DistanceM: 800},
+	})
+	g, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestBuild(t *testing.T) {
+	g := buildFixture(t)
+
+	if got := ids(g.OLTs()); got != "olt-1" {
+		t.Errorf("OLTs = %s", got)
+	}
+	if got := ids(g.Children("olt-1:0/1")); got != "cab-7,olt-1:0/1:auto-2,ONU6" {
+		t.Errorf("port children = %s", got)
+	}
+	if got := ids(g.Children("cab-7")); got != "cab-7a,ONU5" {
+		t.Errorf("cab-7 children = %s", got)
+	}
+	if got := ids(g.Downstream("cab-7a")); got != "ONU2,ONU1" {
+		t.Errorf("cab-7a downstream = %s", got)
+	}
+	auto, ok := g.Node("olt-1:0/1:auto-2")
+	if !ok || !auto.Inferred || auto.DistanceM != 4500 {
+		t.Errorf("inferred splitter = %+v", auto)
+	}
+	if got := ids(g.Downstream(auto.ID)); got != "ONU3,ONU4" {
+		t.Errorf("inferred downstream = %s", got)
+	}
+	if onu, _ := g.Node("ONU5"); onu.Inferred || onu.SplitterPort != 3 {
+		t.Errorf("hinted ONU = %+v", onu)
+	}
+	if got := ids(g.Path("ONU1")); got != "olt-1,olt-1:0/1,cab-7,cab-7a,ONU1" {
+		t.Errorf("path = %s", got)
+	}
+	if got := ids(g.Splitters("olt-1", "0/1")); got != "cab-7,cab-7a,olt-1:0/1:auto-2" {
+		t.Errorf("splitters = %s", got)
+	}
+}
+
+func TestBuildHintSerialForms(t *testing.T) {
+	b := NewBuilder(Config{})
+	b.AddSplitter(Splitter{ID: "cab-1", OLT: "olt-1", PONPort: "0/1", Ratio: 8})
+	b.AddHint(Hint{Serial: "hwtc-00000001", Splitter: "cab-1", Port: 1})
+	b.AddHint(Hint{Serial: "4857544300000002", Splitter: "cab-1", Port: 2})
+	b.AddONUs("olt-1", []types.ONUInfo{
+		{PONPort: "0/1", ONUID: 1, Serial: "4857544300000001"},
+		{PONPort: "0/1", ONUID: 2, Serial: "HWTC00000002"},
+	})
+	g, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(g.Children("cab-1")); got != "4857544300000001,HWTC00000002" {
+		t.Errorf("cab-1 children = %s", got)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(b *Builder)
+	}{
+		{"unknown parent", func(b *Builder) {
+			b.AddSplitter(Splitter{ID: "s1", OLT: "olt-1", PONPort: "0/1", Parent: "s0"})
+		}},
+		{"duplicate", func(b *Builder) {
+			b.AddSplitter(Splitter{ID: "s1", OLT: "olt-1", PONPort: "0/1"})
+			b.AddSplitter(Splitter{ID: "s1", OLT: "olt-1", PONPort: "0/2"})
+		}},
+		{"cycle", func(b *Builder) {
+			b.AddSplitter(Splitter{ID: "s1", OLT: "olt-1", PONPort: "0/1", Parent: "s2"})
+			b.AddSplitter(Splitter{ID: "s2", OLT: "olt-1", PONPort: "0/1", Parent: "s1"})
+		}},
+		{"unknown hint", func(b *Builder) {
+			b.AddHint(Hint{Serial: "ONU1", Splitter: "s9"})
+			b.AddONUs("olt-1", []types.ONUInfo{{PONPort: "0/1", ONUID: 1, Serial: "ONU1"}})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBuilder(Config{})
+			tt.fn(b)
+			if _, err := b.Build(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestSuspects(t *testing.T) {
+	g := buildFixture(t)
+
+	got := g.Suspects([]string{"ONU1", "ONU2", "ONU5", "ONU3", "unknown"})
+	var s []string
+	for _, sus := range got {
+		s = append(s, sus.Node.ID)
+	}
+	if strings.Join(s, ",") != "cab-7,ONU3" || got[0].Down != 3 {
+		t.Errorf("suspects = %v", got)
+	}
+
+	got = g.Suspects([]string{"ONU7"})
+	if len(got) != 1 || got[0].Node.ID != "olt-1:0/2" {
+		t.Errorf("suspects = %v", got)
+	}
+}
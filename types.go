@@ -20,6 +20,15 @@ type (
 	SubscriberStatus = types.SubscriberStatus
 	SubscriberStats  = types.SubscriberStats
 	EquipmentStatus  = types.EquipmentStatus
+	Option           = types.Option
+)
+
+// Re-export construction options
+var (
+	WithSNMPCommunity = types.WithSNMPCommunity
+	WithCLIFallback   = types.WithCLIFallback
+	WithPONPortList   = types.WithPONPortList
+	WithModel         = types.WithModel
 )
 
 // Re-export constants
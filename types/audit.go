@@ -0,0 +1,195 @@
+package types
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditRecord describes one configuration-changing operation on a device
+type AuditRecord struct {
+	// Seq, PrevHash and Hash are set by tamper-evident sinks that chain
+	// records together
+	Seq      uint64 `json:"seq,omitempty"`
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+
+	Time    time.Time `json:"time"`
+	Device  string    `json:"device"`
+	Address string    `json:"address"`
+	Vendor  Vendor    `json:"vendor"`
+
+	// Operation is the adapter method or transaction name, e.g.
+	// "CreateSubscriber"
+	Operation string `json:"operation"`
+
+	// Target identifies what was changed, e.g. a subscriber or ONU
+	Target string `json:"target,omitempty"`
+
+	// Caller is the identity attached to the context with WithCaller
+	Caller string `json:"caller,omitempty"`
+
+	// Override is the reason attached with WithOverride, set when the
+	// change was made outside a maintenance window
+	Override string `json:"override,omitempty"`
+
+	// Protocol is the transport of the commands: cli, netconf or gnmi
+	Protocol string `json:"protocol"`
+
+	// Commands are the CLI commands, NETCONF RPCs or gNMI operations sent
+	Commands []string `json:"commands"`
+
+	Success  bool          `json:"success"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// AuditSink receives audit records. Implementations must be safe for
+// concurrent use.
+type AuditSink interface {
+	WriteAudit(ctx context.Context, record *AuditRecord) error
+}
+
+type callerKey struct{}
+
+// WithCaller attaches the identity of the user or system performing the
+// operations in ctx, for audit records
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the identity attached with WithCaller
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+type overrideKey struct{}
+
+// WithOverride marks the operations in ctx as allowed outside maintenance
+// windows. The reason is recorded in audit records.
+func WithOverride(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, overrideKey{}, reason)
+}
+
+// OverrideFromContext returns the reason attached with WithOverride
+func OverrideFromContext(ctx context.Context) string {
+	reason, _ := ctx.Value(overrideKey{}).(string)
+	return reason
+}
+
+type auditScopeKey struct{}
+
+// auditScope collects the commands of one operation
+type auditScope struct {
+	mu       sync.Mutex
+	protocol string
+	commands []string
+	err      error
+}
+
+// BeginAudit starts an audited operation. Write commands sent by protocol
+// drivers with the returned context are collected into one record, which
+// end writes to config.Audit with the operation's result. Operations that
+// sent no write commands are not recorded. Nested operations are part of
+// the outermost one.
+func BeginAudit(ctx context.Context, config *EquipmentConfig, operation, target string) (context.Context, func(err error)) {
+	if config == nil || config.Audit == nil || ctx.Value(auditScopeKey{}) != nil {
+		return ctx, func(error) {}
+	}
+	scope := &auditScope{}
+	start := time.Now()
+	return context.WithValue(ctx, auditScopeKey{}, scope), func(err error) {
+		scope.mu.Lock()
+		defer scope.mu.Unlock()
+		if len(scope.commands) == 0 {
+			return
+		}
+		if err == nil {
+			err = scope.err
+		}
+		writeAudit(ctx, config, &AuditRecord{
+			Operation: operation,
+			Target:    target,
+			Protocol:  scope.protocol,
+			Commands:  scope.commands,
+			Success:   err == nil,
+			Error:     errString(err),
+			Duration:  time.Since(start),
+		}, start)
+	}
+}
+
+// AuditCommands is called by protocol drivers after sending configuration
+// changes. Inside an operation started with BeginAudit the commands are
+// added to it; otherwise they are recorded on their own, named after the
+// calling adapter method.
+func AuditCommands(ctx context.Context, config *EquipmentConfig, protocol string, commands []string, err error) {
+	if config == nil || config.Audit == nil || len(commands) == 0 {
+		return
+	}
+	if scope, ok := ctx.Value(auditScopeKey{}).(*auditScope); ok {
+		scope.mu.Lock()
+		scope.protocol = protocol
+		scope.commands = append(scope.commands, commands...)
+		if scope.err == nil {
+			scope.err = err
+		}
+		scope.mu.Unlock()
+		return
+	}
+	writeAudit(ctx, config, &AuditRecord{
+		Operation: adapterMethod(),
+		Protocol:  protocol,
+		Commands:  commands,
+		Success:   err == nil,
+		Error:     errString(err),
+	}, time.Now())
+}
+
+// writeAudit fills in the device and caller and writes record. Sink
+// failures are logged rather than failing the operation, which has
+// already been applied.
+func writeAudit(ctx context.Context, config *EquipmentConfig, record *AuditRecord, at time.Time) {
+	record.Time = at
+	record.Device = config.Name
+	record.Address = config.Address
+	record.Vendor = config.Vendor
+	record.Caller = CallerFromContext(ctx)
+	record.Override = OverrideFromContext(ctx)
+	if err := config.Audit.WriteAudit(context.WithoutCancel(ctx), record); err != nil {
+		config.Log().Warn("failed to write audit record", "operation", record.Operation, "error", err)
+	}
+}
+
+// adapterMethod returns the name of the outermost exported vendor adapter
+// method on the call stack, e.g. "ApplyProfile", or "exec" if there is none
+func adapterMethod() string {
+	const receiver = ".(*Adapter)."
+	method := "exec"
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if i := strings.Index(frame.Function, "/vendors/"); i >= 0 {
+			if j := strings.Index(frame.Function[i:], receiver); j >= 0 {
+				name, _, _ := strings.Cut(frame.Function[i+j+len(receiver):], ".")
+				if name != "" && name[0] >= 'A' && name[0] <= 'Z' {
+					method = name
+				}
+			}
+		}
+		if !more {
+			return method
+		}
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type memoryAudit struct {
+	mu      sync.Mutex
+	records []AuditRecord
+}
+
+func (m *memoryAudit) WriteAudit(_ context.Context, r *AuditRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, *r)
+	return nil
+}
+
+func TestBeginAudit(t *testing.T) {
+	sink := &memoryAudit{}
+	config := &EquipmentConfig{Name: "olt-1", Vendor: VendorVSOL, Address: "192.0.2.1", Audit: sink}
+	ctx := WithCaller(context.Background(), "alice")
+
+	ctx, end := BeginAudit(ctx, config, "CreateSubscriber", "VSOL00000001")
+	inner, endInner := BeginAudit(ctx, config, "ApplyProfile", "")
+	AuditCommands(ctx, config, "cli", []string{"interface gpon 0/1", "onu add 1 sn VSOL00000001"}, nil)
+	AuditCommands(inner, config, "cli", []string{"onu 1 profile line FAST"}, errors.New("rejected"))
+	endInner(nil)
+	if len(sink.records) != 0 {
+		t.Fatal("nested operation must not write its own record")
+	}
+	end(nil)
+
+	if len(sink.records) != 1 {
+		t.Fatalf("got %d records, want 1", len(sink.records))
+	}
+	r := sink.records[0]
+	if r.Operation != "CreateSubscriber" || r.Target != "VSOL00000001" || r.Caller != "alice" || r.Device != "olt-1" || r.Vendor != VendorVSOL {
+		t.Errorf("record = %+v", r)
+	}
+	if len(r.Commands) != 3 || r.Success || r.Error != "rejected" {
+		t.Errorf("record should hold all commands and the first error, got %+v", r)
+	}
+}
+
+func TestAuditCommands(t *testing.T) {
+	sink := &memoryAudit{}
+	config := &EquipmentConfig{Name: "olt-1", Audit: sink}
+
+	AuditCommands(context.Background(), config, "netconf", []string{"<commit/>"}, nil)
+	if len(sink.records) != 1 || sink.records[0].Operation != "exec" || !sink.records[0].Success {
+		t.Errorf("records = %+v", sink.records)
+	}
+
+	// Operations without write commands are not recorded
+	_, end := BeginAudit(context.Background(), config, "GetONUList", "")
+	end(nil)
+	if len(sink.records) != 1 {
+		t.Errorf("read-only operation was recorded: %+v", sink.records)
+	}
+
+	ctx := WithOverride(context.Background(), "INC-1234 outage repair")
+	AuditCommands(ctx, config, "cli", []string{"ont reset 1"}, nil)
+	if len(sink.records) != 2 || sink.records[1].Override != "INC-1234 outage repair" {
+		t.Errorf("override not recorded: %+v", sink.records)
+	}
+
+	// No sink: no-op
+	AuditCommands(context.Background(), &EquipmentConfig{}, "cli", []string{"reboot"}, nil)
+	AuditCommands(context.Background(), nil, "cli", []string{"reboot"}, nil)
+}
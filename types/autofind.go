@@ -0,0 +1,38 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AutoFindConfig is the ONU auto-find setting of a PON port. With auto-find
+// disabled, the OLT does not list unprovisioned ONUs and DiscoverONUs finds
+// none on the port.
+type AutoFindConfig struct {
+	// Enabled turns auto-find on or off
+	Enabled bool `json:"enabled"`
+
+	// AgingTime is how long an ONU stays in the auto-find list after it
+	// was last seen, in whole seconds. 0 leaves the OLT setting unchanged.
+	AgingTime time.Duration `json:"aging_time,omitempty"`
+}
+
+// Validate checks the aging time
+func (c *AutoFindConfig) Validate() error {
+	if c.AgingTime < 0 {
+		return fmt.Errorf("auto-find aging time must not be negative")
+	}
+	if c.AgingTime%time.Second != 0 {
+		return fmt.Errorf("auto-find aging time %s is not a whole number of seconds", c.AgingTime)
+	}
+	return nil
+}
+
+// AutoFindManager is implemented by adapters that configure ONU auto-find
+// on PON ports, which freshly deployed OLTs may have disabled
+type AutoFindManager interface {
+	// SetAutoFind enables or disables auto-find on a PON port and sets its
+	// aging time
+	SetAutoFind(ctx context.Context, ponPort string, cfg *AutoFindConfig) error
+}
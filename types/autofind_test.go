@@ -0,0 +1,23 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAutoFindConfigValidate(t *testing.T) {
+	tests := []struct {
+		cfg     AutoFindConfig
+		wantErr bool
+	}{
+		{AutoFindConfig{Enabled: true}, false},
+		{AutoFindConfig{Enabled: true, AgingTime: 5 * time.Minute}, false},
+		{AutoFindConfig{AgingTime: -time.Second}, true},
+		{AutoFindConfig{AgingTime: 1500 * time.Millisecond}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v.Validate() error = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// ConfigBackupManager defines device configuration backup and restore.
+// Implemented by vendor adapters whose devices support copy-config.
+type ConfigBackupManager interface {
+	// BackupConfig saves the running configuration. If destination is a URL
+	// (e.g. "file:///backup.xml", "scp://host/path") the device writes the
+	// backup itself; if empty, the configuration is returned in Content.
+	BackupConfig(ctx context.Context, destination string) (*ConfigBackup, error)
+
+	// RestoreConfig replaces the configuration with the one stored at the
+	// source URL. The change is staged and committed atomically where the
+	// device supports a candidate datastore.
+	RestoreConfig(ctx context.Context, source string) error
+}
+
+// ConfigBackup describes a saved device configuration.
+type ConfigBackup struct {
+	// Vendor of the backed-up device
+	Vendor string `json:"vendor"`
+
+	// Source datastore the backup was taken from (e.g. "running")
+	Source string `json:"source"`
+
+	// Destination URL, empty for inline backups
+	Destination string `json:"destination,omitempty"`
+
+	// Format of the configuration ("xml" for NETCONF)
+	Format string `json:"format"`
+
+	// Content holds the configuration for inline backups
+	Content []byte `json:"content,omitempty"`
+
+	// Checksum is the hex SHA-256 of Content, for inline backups
+	Checksum string `json:"checksum,omitempty"`
+
+	// TakenAt is when the backup completed
+	TakenAt time.Time `json:"taken_at"`
+}
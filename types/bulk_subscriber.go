@@ -0,0 +1,23 @@
+package types
+
+import (
+	"context"
+
+	"github.com/nanoncore/nano-southbound/model"
+)
+
+// BulkSubscriberProvisioner provisions many subscribers in one device
+// transaction. Implemented by NETCONF vendor adapters, where the whole
+// batch is sent as a single edit-config and commit.
+type BulkSubscriberProvisioner interface {
+	// BulkCreateSubscribers provisions every request in one transaction.
+	// Requests whose configuration cannot be generated fail individually;
+	// if the device rejects the transaction, every remaining request fails.
+	BulkCreateSubscribers(ctx context.Context, requests []BulkSubscriberRequest) (*BulkResult, error)
+}
+
+// BulkSubscriberRequest is one subscriber in a bulk provisioning call.
+type BulkSubscriberRequest struct {
+	Subscriber *model.Subscriber  `json:"subscriber"`
+	Tier       *model.ServiceTier `json:"tier"`
+}
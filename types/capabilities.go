@@ -0,0 +1,194 @@
+package types
+
+// Capabilities lists the operations an adapter supports, so callers can
+// check for a feature instead of calling it and handling the error. A
+// capability being set means the adapter implements the operation; the
+// device may still reject it, e.g. on older firmware.
+type Capabilities struct {
+	// Subscribers is subscriber CRUD and status through Driver
+	Subscribers bool `json:"subscribers"`
+
+	// ONUList is GetONUList and GetONUBySerial
+	ONUList bool `json:"onu_list"`
+
+	// ONUStream is ONUStreamer
+	ONUStream bool `json:"onu_stream"`
+
+	// ONUDiscovery is DiscoverONUs, listing unprovisioned ONUs
+	ONUDiscovery bool `json:"onu_discovery"`
+
+	// AutoFind is AutoFindManager
+	AutoFind bool `json:"auto_find"`
+
+	// Diagnostics is RunDiagnostics
+	Diagnostics bool `json:"diagnostics"`
+
+	// Optical is GetPONPower, GetONUPower and GetONUDistance
+	Optical bool `json:"optical"`
+
+	// Alarms is GetAlarms
+	Alarms bool `json:"alarms"`
+
+	// OLTStatus is GetOLTStatus
+	OLTStatus bool `json:"olt_status"`
+
+	// Boards is BoardInventory
+	Boards bool `json:"boards"`
+
+	// Transceivers is TransceiverInventory
+	Transceivers bool `json:"transceivers"`
+
+	// Environment is EnvironmentMonitor
+	Environment bool `json:"environment"`
+
+	// LineQuality is LineQualityReader
+	LineQuality bool `json:"line_quality"`
+
+	// RogueDetection is RogueONUDetector
+	RogueDetection bool `json:"rogue_detection"`
+
+	ONURestart bool `json:"onu_restart"`
+	OLTRestart bool `json:"olt_restart"`
+
+	// ONUFactoryReset is FactoryResetONU
+	ONUFactoryReset bool `json:"onu_factory_reset"`
+
+	// ApplyProfile is applying a bandwidth profile to a provisioned ONU
+	ApplyProfile bool `json:"apply_profile"`
+
+	// BulkProvision is DriverV2.BulkProvision
+	BulkProvision bool `json:"bulk_provision"`
+
+	// BulkSubscribers is BulkSubscriberProvisioner
+	BulkSubscribers bool `json:"bulk_subscribers"`
+
+	// Ports is PortManager: ListPorts and SetPortState
+	Ports bool `json:"ports"`
+
+	// VLANs is VLANManager
+	VLANs bool `json:"vlans"`
+
+	// ServicePorts is ServicePortManager
+	ServicePorts bool `json:"service_ports"`
+
+	// SubscriberMigration is SubscriberMigrator: capturing, restoring,
+	// replacing and moving subscribers, and soft suspension
+	SubscriberMigration bool `json:"subscriber_migration"`
+
+	// MultiONU is MultiONUManager, binding several ONUs to one subscriber
+	MultiONU bool `json:"multi_onu"`
+
+	// ONUProfileQuery is GetONUProfiles
+	ONUProfileQuery bool `json:"onu_profile_query"`
+
+	ConfigBackup  bool `json:"config_backup"`
+	ConfigRestore bool `json:"config_restore"`
+
+	// FirmwareUpgrade is FirmwareManager, ONUFirmwareUpgrade is
+	// ONUFirmwareManager
+	FirmwareUpgrade    bool `json:"firmware_upgrade"`
+	ONUFirmwareUpgrade bool `json:"onu_firmware_upgrade"`
+
+	// WiFi is WifiManager
+	WiFi bool `json:"wifi"`
+
+	// ONUWAN is ONUWANManager, ONULAN is ONULANManager
+	ONUWAN bool `json:"onu_wan"`
+	ONULAN bool `json:"onu_lan"`
+
+	// ONUMode is ONUModeSwitcher
+	ONUMode bool `json:"onu_mode"`
+
+	// ONUEthPorts is ONUEthPortManager
+	ONUEthPorts bool `json:"onu_eth_ports"`
+
+	// CATV is ONUCATVManager
+	CATV bool `json:"catv"`
+
+	// Voice is ONUVoiceManager
+	Voice bool `json:"voice"`
+
+	// ONUTests is ONUTester
+	ONUTests bool `json:"onu_tests"`
+
+	// Profile managers
+	LineProfiles    bool `json:"line_profiles"`
+	ServiceProfiles bool `json:"service_profiles"`
+	DBAProfiles     bool `json:"dba_profiles"`
+	TrafficProfiles bool `json:"traffic_profiles"`
+	ONUProfiles     bool `json:"onu_profiles"`
+}
+
+// CapabilityReporter is implemented by adapters that report their
+// capabilities
+type CapabilityReporter interface {
+	Capabilities() Capabilities
+}
+
+// CapabilitiesOf returns the capabilities reported by d, or those detected
+// from the interfaces it implements if it does not report them
+func CapabilitiesOf(d Driver) Capabilities {
+	if r, ok := d.(CapabilityReporter); ok {
+		return r.Capabilities()
+	}
+	return DetectCapabilities(d)
+}
+
+// DetectCapabilities derives capabilities from the interfaces d implements.
+// Adapters start from it and clear the operations they stub out.
+func DetectCapabilities(d Driver) Capabilities {
+	c := Capabilities{Subscribers: d != nil}
+	if _, ok := d.(ONUInventoryProvider); ok {
+		c.ONUList = true
+		c.ONUDiscovery = true
+		c.ONUProfileQuery = true
+	}
+	if _, ok := d.(DiagnosticsProvider); ok {
+		c.Diagnostics = true
+		c.Alarms = true
+	}
+	if _, ok := d.(OLTManager); ok {
+		c.OLTStatus = true
+		c.OLTRestart = true
+	}
+	if _, ok := d.(ONUOperator); ok {
+		c.ONURestart = true
+		c.ONUFactoryReset = true
+		c.ApplyProfile = true
+	}
+	_, c.Optical = d.(OpticalReader)
+	_, c.BulkProvision = d.(DriverV2)
+	_, c.Ports = d.(PortManager)
+	_, c.VLANs = d.(VLANManager)
+	_, c.ServicePorts = d.(ServicePortManager)
+	_, c.SubscriberMigration = d.(SubscriberMigrator)
+	_, c.MultiONU = d.(MultiONUManager)
+	_, c.ONUStream = d.(ONUStreamer)
+	_, c.AutoFind = d.(AutoFindManager)
+	_, c.Boards = d.(BoardInventory)
+	_, c.Transceivers = d.(TransceiverInventory)
+	_, c.Environment = d.(EnvironmentMonitor)
+	_, c.LineQuality = d.(LineQualityReader)
+	_, c.RogueDetection = d.(RogueONUDetector)
+	_, c.BulkSubscribers = d.(BulkSubscriberProvisioner)
+	if _, ok := d.(ConfigBackupManager); ok {
+		c.ConfigBackup = true
+		c.ConfigRestore = true
+	}
+	_, c.FirmwareUpgrade = d.(FirmwareManager)
+	_, c.ONUFirmwareUpgrade = d.(ONUFirmwareManager)
+	_, c.WiFi = d.(WifiManager)
+	_, c.ONUWAN = d.(ONUWANManager)
+	_, c.ONULAN = d.(ONULANManager)
+	_, c.ONUMode = d.(ONUModeSwitcher)
+	_, c.ONUEthPorts = d.(ONUEthPortManager)
+	_, c.CATV = d.(ONUCATVManager)
+	_, c.Voice = d.(ONUVoiceManager)
+	_, c.ONUTests = d.(ONUTester)
+	_, c.LineProfiles = d.(LineProfileManager)
+	_, c.ServiceProfiles = d.(ServiceProfileManager)
+	_, c.DBAProfiles = d.(DBAProfileManager)
+	_, c.TrafficProfiles = d.(TrafficProfileManager)
+	_, c.ONUProfiles = d.(ONUProfileManager)
+	return c
+}
@@ -0,0 +1,75 @@
+package types
+
+import "testing"
+
+// v2Driver implements DriverV2 and FirmwareManager through nil embedded
+// interfaces; only its method set matters
+type v2Driver struct {
+	DriverV2
+	FirmwareManager
+}
+
+// portDriver implements Driver with only the port and VLAN parts of
+// DriverV2
+type portDriver struct {
+	Driver
+	PortManager
+	VLANManager
+}
+
+// reportingDriver reports its own capabilities
+type reportingDriver struct {
+	Driver
+}
+
+func (reportingDriver) Capabilities() Capabilities {
+	return Capabilities{Subscribers: true, Alarms: true}
+}
+
+func TestCapabilitiesOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver Driver
+		want   Capabilities
+	}{
+		{
+			name:   "nil driver",
+			driver: nil,
+			want:   Capabilities{},
+		},
+		{
+			name:   "plain driver",
+			driver: &reconcileDriver{},
+			want:   Capabilities{Subscribers: true},
+		},
+		{
+			name:   "reported",
+			driver: reportingDriver{},
+			want:   Capabilities{Subscribers: true, Alarms: true},
+		},
+		{
+			name:   "detected from interfaces",
+			driver: &v2Driver{},
+			want: Capabilities{
+				Subscribers: true, ONUList: true, ONUDiscovery: true, Diagnostics: true,
+				Optical: true, Alarms: true, OLTStatus: true, ONURestart: true, OLTRestart: true,
+				ONUFactoryReset: true, ApplyProfile: true, BulkProvision: true, Ports: true, VLANs: true,
+				ServicePorts: true, SubscriberMigration: true, MultiONU: true,
+				ONUProfileQuery: true, FirmwareUpgrade: true,
+			},
+		},
+		{
+			name:   "detected from parts of DriverV2",
+			driver: &portDriver{},
+			want:   Capabilities{Subscribers: true, Ports: true, VLANs: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CapabilitiesOf(tt.driver); got != tt.want {
+				t.Errorf("CapabilitiesOf() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"maps"
+)
+
+// Credentials are the secrets used to manage a device. Empty fields keep
+// the value of the EquipmentConfig.
+type Credentials struct {
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	SNMPCommunity string `json:"snmp_community,omitempty"`
+}
+
+// CredentialsProvider resolves the credentials of a device when a driver
+// connects, so they need not be kept in EquipmentConfig. Implementations
+// must be safe for concurrent use.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context, config *EquipmentConfig) (*Credentials, error)
+}
+
+// ResolveCredentials returns a copy of config with the credentials of its
+// Credentials provider applied. config is returned unchanged if it has no
+// provider. Drivers call it on Connect.
+func ResolveCredentials(ctx context.Context, config *EquipmentConfig) (*EquipmentConfig, error) {
+	if config == nil || config.Credentials == nil {
+		return config, nil
+	}
+	creds, err := config.Credentials.Credentials(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials for %s: %w", config.Address, err)
+	}
+	resolved := *config
+	if creds == nil {
+		return &resolved, nil
+	}
+	if creds.Username != "" {
+		resolved.Username = creds.Username
+	}
+	if creds.Password != "" {
+		resolved.Password = creds.Password
+	}
+	if creds.SNMPCommunity != "" {
+		resolved.SNMPCommunity = creds.SNMPCommunity
+		// Secondary SNMP drivers read the community from metadata
+		if _, ok := resolved.Metadata["snmp_community"]; ok {
+			resolved.Metadata = maps.Clone(resolved.Metadata)
+			resolved.Metadata["snmp_community"] = creds.SNMPCommunity
+		}
+	}
+	return &resolved, nil
+}
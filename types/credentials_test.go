@@ -0,0 +1,46 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type credentialsFunc func() (*Credentials, error)
+
+func (f credentialsFunc) Credentials(context.Context, *EquipmentConfig) (*Credentials, error) {
+	return f()
+}
+
+func TestResolveCredentials(t *testing.T) {
+	ctx := context.Background()
+
+	plain := &EquipmentConfig{Username: "admin"}
+	if got, err := ResolveCredentials(ctx, plain); err != nil || got != plain {
+		t.Errorf("ResolveCredentials() without provider = %p, %v, want the config itself", got, err)
+	}
+
+	config := &EquipmentConfig{
+		Username: "admin",
+		Metadata: map[string]string{"snmp_community": "public"},
+		Credentials: credentialsFunc(func() (*Credentials, error) {
+			return &Credentials{Password: "s3cret", SNMPCommunity: "private"}, nil
+		}),
+	}
+	got, err := ResolveCredentials(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Username != "admin" || got.Password != "s3cret" || got.SNMPCommunity != "private" ||
+		got.Metadata["snmp_community"] != "private" {
+		t.Errorf("ResolveCredentials() = %+v", got)
+	}
+	if config.Password != "" || config.Metadata["snmp_community"] != "public" {
+		t.Errorf("ResolveCredentials() modified the config: %+v", config)
+	}
+
+	config.Credentials = credentialsFunc(func() (*Credentials, error) { return nil, errors.New("vault sealed") })
+	if _, err := ResolveCredentials(ctx, config); err == nil {
+		t.Error("expected error")
+	}
+}
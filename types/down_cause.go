@@ -0,0 +1,52 @@
+package types
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Causes of an ONU last going offline, in ONUInfo.LastDownCause
+const (
+	// DownCauseLOS is a loss of signal or frame: a cut or dirty fiber, or
+	// failed ONU optics
+	DownCauseLOS = "los"
+
+	// DownCauseDyingGasp is the ONU losing power
+	DownCauseDyingGasp = "dying_gasp"
+
+	// DownCauseDeactivated is the ONU deactivated or disabled from the OLT
+	DownCauseDeactivated = "deactivated"
+
+	// DownCauseReset is the ONU rebooted or re-registered
+	DownCauseReset = "reset"
+
+	// DownCauseOther is a cause reported by the OLT that is none of the
+	// above
+	DownCauseOther = "other"
+)
+
+var (
+	reDownCauseLOS  = regexp.MustCompile(`\blo[sfb]i?\b|loss of`)
+	reDownCauseNone = regexp.MustCompile(`^(-+|n/?a|none|null)?$`)
+)
+
+// NormalizeDownCause maps the last down cause reported by an OLT, such as
+// Huawei "LOSi/LOBi" or "dying-gasp", to one of the DownCause values. It
+// returns "" if the OLT reports no cause.
+func NormalizeDownCause(raw string) string {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	switch {
+	case reDownCauseNone.MatchString(s):
+		return ""
+	case strings.Contains(s, "dying"), strings.Contains(s, "dgi"), strings.Contains(s, "power off"):
+		return DownCauseDyingGasp
+	case reDownCauseLOS.MatchString(s):
+		return DownCauseLOS
+	case strings.Contains(s, "deactiv"), strings.Contains(s, "disable"):
+		return DownCauseDeactivated
+	case strings.Contains(s, "reset"), strings.Contains(s, "reboot"), strings.Contains(s, "restart"), strings.Contains(s, "re-register"):
+		return DownCauseReset
+	default:
+		return DownCauseOther
+	}
+}
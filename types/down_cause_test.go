@@ -0,0 +1,27 @@
+package types
+
+import "testing"
+
+func TestNormalizeDownCause(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"LOS", DownCauseLOS},
+		{"LOSi/LOBi", DownCauseLOS},
+		{"LOFi", DownCauseLOS},
+		{"dying-gasp", DownCauseDyingGasp},
+		{"Dying Gasp", DownCauseDyingGasp},
+		{"deactive ONT success", DownCauseDeactivated},
+		{"ONT reset", DownCauseReset},
+		{"re-register ONT", DownCauseReset},
+		{"SFi", DownCauseOther},
+		{"-", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeDownCause(tt.raw); got != tt.want {
+			t.Errorf("NormalizeDownCause(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -2,21 +2,48 @@ package types
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nanoncore/nano-southbound/model"
 )
 
 // DriverV2 extends the base Driver interface with OLT-level operations,
-// enhanced diagnostics, and bulk provisioning capabilities.
+// enhanced diagnostics, and bulk provisioning capabilities. It is made of
+// smaller interfaces, so callers that need one group of operations can
+// depend on it alone.
+//
+// Adapters should implement this interface to provide full functionality,
+// with a compile-time check:
+//
+//	var _ types.DriverV2 = (*Adapter)(nil)
 //
-// Adapters should implement this interface to provide full functionality.
 // The base Driver interface remains supported for backwards compatibility.
 type DriverV2 interface {
 	Driver // Embed base interface
 
-	// === ONU Discovery ===
+	ONUInventoryProvider
+	OpticalReader
+	ONUOperator
+	DiagnosticsProvider
+	OLTManager
+	PortManager
+	VLANManager
+	ServicePortManager
+	SubscriberMigrator
+	MultiONUManager
+
+	// BulkProvision provisions multiple ONUs in a single session.
+	// More efficient than individual CreateSubscriber calls.
+	// Returns results for each operation (some may succeed while others fail).
+	BulkProvision(ctx context.Context, operations []BulkProvisionOp) (*BulkResult, error)
+}
 
+// ONUInventoryProvider lists the provisioned and unprovisioned ONUs of an
+// OLT
+type ONUInventoryProvider interface {
 	// DiscoverONUs finds unprovisioned ONUs on the OLT.
 	// If ponPorts is empty, discovers on all PON ports.
 	// Returns ONUs that have registered but are not yet provisioned.
@@ -30,8 +57,17 @@ type DriverV2 interface {
 	// Returns nil if not found.
 	GetONUBySerial(ctx context.Context, serial string) (*ONUInfo, error)
 
-	// === Optical Diagnostics ===
+	// GetONUProfiles fetches profile, line profile, service profile, and VLAN
+	// assignments for all provisioned ONUs. This is used by the config sync
+	// tier (30min default) to keep profile/VLAN data fresh without the overhead
+	// of a full GetONUList. Returns a slice of ONUInfo with only profile-related
+	// fields populated (PONPort, ONUID, Serial, ONUProfile, LineProfile,
+	// ServiceProfile, VLAN).
+	GetONUProfiles(ctx context.Context) ([]ONUInfo, error)
+}
 
+// OpticalReader reads optical levels and fiber distances
+type OpticalReader interface {
 	// GetPONPower returns optical power readings for a PON port.
 	// This is the aggregate power at the OLT splitter.
 	GetPONPower(ctx context.Context, ponPort string) (*PONPowerReading, error)
@@ -43,27 +79,27 @@ type DriverV2 interface {
 	// GetONUDistance returns estimated fiber distance to ONU in meters.
 	// Returns -1 if distance cannot be determined.
 	GetONUDistance(ctx context.Context, ponPort string, onuID int) (int, error)
+}
 
-	// === ONU Operations ===
-
+// ONUOperator restarts, resets and re-profiles provisioned ONUs
+type ONUOperator interface {
 	// RestartONU triggers a reboot of the specified ONU.
 	// Uses deactivate/activate sequence and verifies state changes.
 	// Returns detailed result including verification status.
 	RestartONU(ctx context.Context, ponPort string, onuID int) (*RestartONUResult, error)
 
+	// FactoryResetONU restores the factory configuration of an ONU and
+	// waits for it to register again. The reset must be confirmed with
+	// opts.Force.
+	FactoryResetONU(ctx context.Context, ponPort string, onuID int, opts *FactoryResetOptions) (*FactoryResetResult, error)
+
 	// ApplyProfile applies a bandwidth/service profile to an ONU.
 	// This is a faster path than full UpdateSubscriber for profile changes.
 	ApplyProfile(ctx context.Context, ponPort string, onuID int, profile *ONUProfile) error
+}
 
-	// === Bulk Operations ===
-
-	// BulkProvision provisions multiple ONUs in a single session.
-	// More efficient than individual CreateSubscriber calls.
-	// Returns results for each operation (some may succeed while others fail).
-	BulkProvision(ctx context.Context, operations []BulkProvisionOp) (*BulkResult, error)
-
-	// === Comprehensive Diagnostics ===
-
+// DiagnosticsProvider runs ONU diagnostics and reads OLT alarms
+type DiagnosticsProvider interface {
 	// RunDiagnostics performs comprehensive diagnostics on an ONU.
 	// Returns power readings, counters, configuration, and any alarms.
 	RunDiagnostics(ctx context.Context, ponPort string, onuID int) (*ONUDiagnostics, error)
@@ -71,30 +107,31 @@ type DriverV2 interface {
 	// GetAlarms returns active alarms from the OLT.
 	// Includes ONU alarms, port alarms, and system alarms.
 	GetAlarms(ctx context.Context) ([]OLTAlarm, error)
+}
 
-	// === OLT Operations ===
+// OLTManager reads the status of an OLT and restarts it
+type OLTManager interface {
+	// GetOLTStatus returns comprehensive OLT status including
+	// PON port status, resource utilization, and uptime.
+	GetOLTStatus(ctx context.Context) (*OLTStatus, error)
 
 	// RestartOLT triggers a full reboot of the OLT device.
 	// Saves running config before rebooting. The SSH connection will drop
 	// after the reboot command is sent — callers should handle this gracefully.
 	RestartOLT(ctx context.Context) (*RestartOLTResult, error)
+}
 
-	// === OLT Status ===
-
-	// GetOLTStatus returns comprehensive OLT status including
-	// PON port status, resource utilization, and uptime.
-	GetOLTStatus(ctx context.Context) (*OLTStatus, error)
-
-	// === Port Management ===
-
+// PortManager lists and administers PON ports
+type PortManager interface {
 	// ListPorts returns status for all PON ports on the OLT.
 	ListPorts(ctx context.Context) ([]*PONPortStatus, error)
 
 	// SetPortState enables or disables a PON port administratively.
 	SetPortState(ctx context.Context, port string, enabled bool) error
+}
 
-	// === VLAN Management ===
-
+// VLANManager manages the VLANs of an OLT
+type VLANManager interface {
 	// ListVLANs returns all configured VLANs on the OLT.
 	ListVLANs(ctx context.Context) ([]VLANInfo, error)
 
@@ -108,9 +145,10 @@ type DriverV2 interface {
 	// DeleteVLAN removes a VLAN from the OLT.
 	// If force is false, returns error if VLAN has associated service ports.
 	DeleteVLAN(ctx context.Context, vlanID int, force bool) error
+}
 
-	// === Service Port Management ===
-
+// ServicePortManager manages the service ports mapping VLANs to ONUs
+type ServicePortManager interface {
 	// ListServicePorts returns all service port configurations.
 	ListServicePorts(ctx context.Context) ([]ServicePort, error)
 
@@ -119,19 +157,11 @@ type DriverV2 interface {
 
 	// DeleteServicePort removes a service port mapping.
 	DeleteServicePort(ctx context.Context, ponPort string, ontID int) error
+}
 
-	// === Config Sync (Tiered Polling) ===
-
-	// GetONUProfiles fetches profile, line profile, service profile, and VLAN
-	// assignments for all provisioned ONUs. This is used by the config sync
-	// tier (30min default) to keep profile/VLAN data fresh without the overhead
-	// of a full GetONUList. Returns a slice of ONUInfo with only profile-related
-	// fields populated (PONPort, ONUID, Serial, ONUProfile, LineProfile,
-	// ServiceProfile, VLAN).
-	GetONUProfiles(ctx context.Context) ([]ONUInfo, error)
-
-	// === Subscriber Config Snapshot (used by RMA, Move, Swap, Soft Suspend) ===
-
+// SubscriberMigrator captures and restores subscriber configurations, to
+// replace, move and soft-suspend subscribers
+type SubscriberMigrator interface {
 	// CaptureSubscriberConfig reads the full provisioning state of an ONU.
 	// Returns a snapshot that can be used to recreate the ONU on a different
 	// port or with a different serial via RestoreSubscriberConfig.
@@ -143,44 +173,38 @@ type DriverV2 interface {
 	// targetONUID specify where to provision the new ONU.
 	RestoreSubscriberConfig(ctx context.Context, snapshot *SubscriberSnapshot, targetPONPort string, targetONUID int) (*SubscriberResult, error)
 
-	// === ONU Replacement (RMA) ===
-
 	// ReplaceONU performs a full ONU replacement using create-first strategy:
 	// (1) CaptureSubscriberConfig on old ONU, (2) RestoreSubscriberConfig with
 	// new serial on same PON port, (3) verify new ONU online, (4) DeleteSubscriber
 	// on old ONU. If step 2 fails, old ONU remains untouched.
 	ReplaceONU(ctx context.Context, subscriberID string, newSerial string) (*ReplaceResult, error)
 
-	// === Soft Suspension ===
-
 	// SoftSuspendSubscriber applies a soft suspension mode without fully
 	// deactivating the ONU. Captures original config in SuspensionState
 	// for later restoration by ResumeSubscriber. Modes: throttle reduces
 	// bandwidth, walled-garden redirects to a captive VLAN, quarantine
-	// applies both. Existing SuspendSubscriber (hard suspend) is unchanged.
+	// applies both. SuspendSubscriber deactivates the ONU (hard suspend)
+	// unless the device's MetadataSuspendMode selects a soft mode.
 	SoftSuspendSubscriber(ctx context.Context, subscriberID string, opts *SuspendOptions) (*SuspensionState, error)
 
 	// GetSuspensionState returns the current soft suspension state for a
 	// subscriber, or nil if the subscriber is not soft-suspended.
 	GetSuspensionState(ctx context.Context, subscriberID string) (*SuspensionState, error)
 
-	// === Subscriber Move ===
-
 	// MoveSubscriber moves a subscriber to a different PON port using create-first
 	// strategy: (1) CaptureSubscriberConfig, (2) RestoreSubscriberConfig on target
 	// PON port/ONU ID, (3) verify new ONU online, (4) DeleteSubscriber on old port.
 	// If step 2 fails, old ONU remains untouched.
 	MoveSubscriber(ctx context.Context, subscriberID string, targetPONPort string, targetONUID int) (*MoveResult, error)
 
-	// === ONU Compatibility Check (for Swap) ===
-
 	// CheckONUCompatibility checks whether a new ONU is compatible with the
 	// current subscriber's profile requirements (ETH ports, POTS ports,
 	// T-CONT support, GPON vs XGS-PON). Used as a pre-flight check before swap.
 	CheckONUCompatibility(ctx context.Context, subscriberID string, newSerial string) (*CompatibilityReport, error)
+}
 
-	// === Multi-ONU Provisioning ===
-
+// MultiONUManager binds several ONUs to one subscriber
+type MultiONUManager interface {
 	// AddONUToSubscriber provisions an additional ONU for an existing subscriber.
 	// Each ONU gets an independent service port. The binding specifies the
 	// ONU's serial, PON port, ONU ID, and role (primary/secondary/redundant).
@@ -243,6 +267,13 @@ type ONUFilter struct {
 
 	// VLAN filters by VLAN ID
 	VLAN int `json:"vlan,omitempty"`
+
+	// After lists only the ONUs after the one with this cursor, as returned
+	// by NextONUCursor, in PON port and ONU ID order
+	After string `json:"after,omitempty"`
+
+	// Limit caps the number of ONUs returned, 0 for no limit
+	Limit int `json:"limit,omitempty"`
 }
 
 // ONUInfo represents a provisioned ONU.
@@ -334,6 +365,14 @@ type ONUInfo struct {
 	// LastOnline is when the ONU was last online
 	LastOnline time.Time `json:"last_online,omitempty"`
 
+	// LastOfflineTime is when the ONU last went offline
+	LastOfflineTime time.Time `json:"last_offline_time,omitempty"`
+
+	// LastDownCause is why the ONU last went offline, one of the DownCause
+	// values; the cause reported by the OLT is kept in Metadata as
+	// "last_down_cause"
+	LastDownCause string `json:"last_down_cause,omitempty"`
+
 	// ProvisionedAt is when the ONU was provisioned
 	ProvisionedAt time.Time `json:"provisioned_at,omitempty"`
 
@@ -557,9 +596,17 @@ type ONUDiagnostics struct {
 	BandwidthUp    int    `json:"bandwidth_up_kbps"`
 	BandwidthDown  int    `json:"bandwidth_down_kbps"`
 
+	// LineQuality are the upstream BER and FEC counters of the ONU, if
+	// the adapter reads them
+	LineQuality *LineQuality `json:"line_quality,omitempty"`
+
 	// Alarms contains active alarms for this ONU
 	Alarms []string `json:"alarms,omitempty"`
 
+	// Tests contains the results of loopback and self-tests, see
+	// RunDiagnosticsWithTests
+	Tests []ONUTestResult `json:"tests,omitempty"`
+
 	// VendorData contains vendor-specific diagnostic data
 	VendorData map[string]interface{} `json:"vendor_data,omitempty"`
 
@@ -686,6 +733,9 @@ type PONPortStatus struct {
 	// OutOctets is the total outbound octets counter
 	OutOctets uint64 `json:"out_octets,omitempty"`
 
+	// LineQuality are the upstream BER and FEC counters, if read
+	LineQuality *LineQuality `json:"line_quality,omitempty"`
+
 	// Metadata contains vendor-specific port data
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
@@ -710,6 +760,9 @@ type HumanError struct {
 
 	// Recoverable indicates if this error should be retried
 	Recoverable bool `json:"recoverable"`
+
+	// Err is the underlying error, if any
+	Err error `json:"-"`
 }
 
 // Error implements the error interface.
@@ -725,20 +778,35 @@ func (e *HumanError) IsRecoverable() bool {
 	return e.Recoverable
 }
 
+// Unwrap returns the underlying error.
+func (e *HumanError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is a HumanError with the same code, so that
+// errors.Is(err, ErrONUNotFound) matches any ONU-not-found error.
+func (e *HumanError) Is(target error) bool {
+	t, ok := target.(*HumanError)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
 // Common error codes that adapters should use
 const (
-	ErrCodeONUExists       = "ONU_EXISTS"
-	ErrCodeONUNotFound     = "ONU_NOT_FOUND"
-	ErrCodeInvalidSerial   = "INVALID_SERIAL"
-	ErrCodePortNotFound    = "PORT_NOT_FOUND"
-	ErrCodeConfigLocked    = "CONFIG_LOCKED"
-	ErrCodeTimeout         = "TIMEOUT"
-	ErrCodeConnReset       = "CONN_RESET"
-	ErrCodeONUFull         = "ONU_FULL"
-	ErrCodeProfileNotFound = "PROFILE_NOT_FOUND"
-	ErrCodeUnknownCommand  = "UNKNOWN_CMD"
-	ErrCodeAuthFailed      = "AUTH_FAILED"
-	ErrCodeUnknown         = "UNKNOWN_ERROR"
+	ErrCodeONUExists        = "ONU_EXISTS"
+	ErrCodeONUNotFound      = "ONU_NOT_FOUND"
+	ErrCodeInvalidSerial    = "INVALID_SERIAL"
+	ErrCodePortNotFound     = "PORT_NOT_FOUND"
+	ErrCodeConfigLocked     = "CONFIG_LOCKED"
+	ErrCodeTimeout          = "TIMEOUT"
+	ErrCodeConnReset        = "CONN_RESET"
+	ErrCodeONUFull          = "ONU_FULL"
+	ErrCodePortFull         = ErrCodeONUFull // no free ONU ID on the PON port
+	ErrCodeUnreachable      = "UNREACHABLE"
+	ErrCodeProfileNotFound  = "PROFILE_NOT_FOUND"
+	ErrCodeUnknownCommand   = "UNKNOWN_CMD"
+	ErrCodeAuthFailed       = "AUTH_FAILED"
+	ErrCodeValidationFailed = "VALIDATION_FAILED"
+	ErrCodeUnknown          = "UNKNOWN_ERROR"
 )
 
 // Typical GPON optical power thresholds
@@ -827,6 +895,27 @@ type ServicePortSnapshot struct {
 	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
+// ServicePortRequests returns the requests that recreate the service ports
+// of a snapshot on an ONU, or one for its VLAN if no service ports were
+// captured
+func (s *SubscriberSnapshot) ServicePortRequests(ponPort string, onuID int) []AddServicePortRequest {
+	var requests []AddServicePortRequest
+	for _, sp := range s.ServicePorts {
+		requests = append(requests, AddServicePortRequest{
+			VLAN:         sp.VLAN,
+			PONPort:      ponPort,
+			ONTID:        onuID,
+			GemPort:      sp.GemPort,
+			UserVLAN:     sp.UserVLAN,
+			TagTransform: sp.TagTransform,
+		})
+	}
+	if len(requests) == 0 && s.VLAN > 0 {
+		requests = append(requests, AddServicePortRequest{VLAN: s.VLAN, PONPort: ponPort, ONTID: onuID})
+	}
+	return requests
+}
+
 // ReplaceResult contains the outcome of an ONU replacement (RMA) operation.
 type ReplaceResult struct {
 	// OldSerial is the serial of the replaced ONU
@@ -875,6 +964,64 @@ type SuspendOptions struct {
 	ThrottleBandwidthKbps int `json:"throttle_bandwidth_kbps,omitempty"`
 }
 
+// EquipmentConfig.Metadata keys for suspensions
+const (
+	// MetadataSuspendMode is the SuspensionMode SuspendSubscriber applies
+	// on the device. Empty or "hard" deactivates the ONU; "walled-garden"
+	// moves it to the captive portal VLAN so the subscriber sees a payment
+	// page rather than a dead line.
+	MetadataSuspendMode = "suspend_mode"
+
+	// MetadataWalledGardenVLAN is the captive portal VLAN of the device,
+	// used when SuspendOptions.WalledGardenVLAN is zero
+	MetadataWalledGardenVLAN = "walled_garden_vlan"
+)
+
+// ConfiguredSuspension returns the soft suspension that SuspendSubscriber
+// applies on a device per its MetadataSuspendMode, or nil if it
+// deactivates the ONU.
+func ConfiguredSuspension(config *EquipmentConfig) (*SuspendOptions, error) {
+	if config == nil {
+		return nil, nil
+	}
+	mode := SuspensionMode(strings.TrimSpace(config.Metadata[MetadataSuspendMode]))
+	if mode == "" || mode == SuspensionModeHard {
+		return nil, nil
+	}
+	return ResolveSuspendOptions(&SuspendOptions{Mode: mode}, config)
+}
+
+// ResolveSuspendOptions validates soft suspension options for a device and
+// returns a copy with a zero WalledGardenVLAN taken from its
+// MetadataWalledGardenVLAN.
+func ResolveSuspendOptions(opts *SuspendOptions, config *EquipmentConfig) (*SuspendOptions, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("suspend options are required")
+	}
+	resolved := *opts
+	switch resolved.Mode {
+	case SuspensionModeThrottle:
+		return &resolved, nil
+	case SuspensionModeWalledGarden, SuspensionModeQuarantine:
+	default:
+		return nil, fmt.Errorf("invalid suspension mode: %q", resolved.Mode)
+	}
+
+	if resolved.WalledGardenVLAN == 0 && config != nil {
+		if raw := strings.TrimSpace(config.Metadata[MetadataWalledGardenVLAN]); raw != "" {
+			vlan, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", MetadataWalledGardenVLAN, raw, err)
+			}
+			resolved.WalledGardenVLAN = vlan
+		}
+	}
+	if resolved.WalledGardenVLAN < 1 || resolved.WalledGardenVLAN > 4094 {
+		return nil, fmt.Errorf("walled-garden VLAN %d out of range 1-4094 for mode %q; set it in the options or %s", resolved.WalledGardenVLAN, resolved.Mode, MetadataWalledGardenVLAN)
+	}
+	return &resolved, nil
+}
+
 // SuspensionState captures the state of a soft suspension, including the
 // original config snapshot for restoration on resume.
 type SuspensionState struct {
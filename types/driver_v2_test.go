@@ -221,3 +221,36 @@ func TestGPONThresholdConstants(t *testing.T) {
 		t.Errorf("GPONTxHighThreshold (%v) should be positive", GPONTxHighThreshold)
 	}
 }
+
+func TestConfiguredSuspension(t *testing.T) {
+	opts, err := ConfiguredSuspension(&EquipmentConfig{})
+	if err != nil || opts != nil {
+		t.Errorf("ConfiguredSuspension(no metadata) = %+v, %v, want hard suspend", opts, err)
+	}
+
+	config := &EquipmentConfig{Metadata: map[string]string{
+		MetadataSuspendMode:      "walled-garden",
+		MetadataWalledGardenVLAN: "999",
+	}}
+	opts, err = ConfiguredSuspension(config)
+	if err != nil || opts == nil || opts.Mode != SuspensionModeWalledGarden || opts.WalledGardenVLAN != 999 {
+		t.Errorf("ConfiguredSuspension() = %+v, %v", opts, err)
+	}
+
+	// Explicit options win over the device's VLAN
+	opts, err = ResolveSuspendOptions(&SuspendOptions{Mode: SuspensionModeQuarantine, WalledGardenVLAN: 998}, config)
+	if err != nil || opts.WalledGardenVLAN != 998 {
+		t.Errorf("ResolveSuspendOptions() = %+v, %v", opts, err)
+	}
+
+	for name, metadata := range map[string]map[string]string{
+		"no vlan":      {MetadataSuspendMode: "walled-garden"},
+		"bad vlan":     {MetadataSuspendMode: "walled-garden", MetadataWalledGardenVLAN: "captive"},
+		"vlan range":   {MetadataSuspendMode: "walled-garden", MetadataWalledGardenVLAN: "4095"},
+		"unknown mode": {MetadataSuspendMode: "redirect"},
+	} {
+		if _, err := ConfiguredSuspension(&EquipmentConfig{Metadata: metadata}); err == nil {
+			t.Errorf("ConfiguredSuspension(%s) error = nil", name)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// FanStatus is a fan of the OLT chassis
+type FanStatus struct {
+	// Name identifies the fan (e.g., "0", "fan 1")
+	Name string `json:"name"`
+
+	// Status is the fan state as reported (e.g., "Normal", "Failed")
+	Status string `json:"status"`
+
+	// Normal indicates if the fan is working
+	Normal bool `json:"normal"`
+
+	// SpeedRPM is the fan speed, 0 if not reported
+	SpeedRPM int `json:"speed_rpm,omitempty"`
+}
+
+// PowerSupplyStatus is a power supply unit or power board of the OLT
+type PowerSupplyStatus struct {
+	// Name identifies the power supply (e.g., "0/18", "PSU 1")
+	Name string `json:"name"`
+
+	// Status is the power supply state as reported
+	Status string `json:"status"`
+
+	// Normal indicates if the power supply is delivering power
+	Normal bool `json:"normal"`
+}
+
+// TemperatureSensor is a temperature reading of the OLT
+type TemperatureSensor struct {
+	// Name identifies the sensor (e.g., "0/1", "system")
+	Name string `json:"name"`
+
+	// Celsius is the temperature
+	Celsius float64 `json:"celsius"`
+}
+
+// EnvironmentStatus is the state of the fans, power supplies and
+// temperature sensors of an OLT. Parts the OLT does not report are empty.
+type EnvironmentStatus struct {
+	Fans          []FanStatus         `json:"fans,omitempty"`
+	PowerSupplies []PowerSupplyStatus `json:"power_supplies,omitempty"`
+	Temperatures  []TemperatureSensor `json:"temperatures,omitempty"`
+
+	// Timestamp is when the status was read
+	Timestamp time.Time `json:"timestamp"`
+
+	// Metadata contains vendor-specific data
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Alarms returns an alarm for every fan and power supply that is not
+// normal, named so that alarms.Normalize classifies them as fan and power
+// failures
+func (e *EnvironmentStatus) Alarms() []OLTAlarm {
+	var out []OLTAlarm
+	for _, f := range e.Fans {
+		if !f.Normal {
+			out = append(out, OLTAlarm{
+				ID:       "fan:" + f.Name,
+				Severity: "major",
+				Type:     "fan failure",
+				Source:   "system",
+				SourceID: "fan " + f.Name,
+				Message:  "Fan " + f.Name + " is " + f.Status,
+				RaisedAt: e.Timestamp,
+			})
+		}
+	}
+	for _, p := range e.PowerSupplies {
+		if !p.Normal {
+			out = append(out, OLTAlarm{
+				ID:       "psu:" + p.Name,
+				Severity: "critical",
+				Type:     "power supply failure",
+				Source:   "system",
+				SourceID: "power supply " + p.Name,
+				Message:  "Power supply " + p.Name + " is " + p.Status,
+				RaisedAt: e.Timestamp,
+			})
+		}
+	}
+	return out
+}
+
+// EnvironmentMonitor is implemented by adapters that report the fans,
+// power supplies and temperatures of the OLT
+type EnvironmentMonitor interface {
+	GetEnvironment(ctx context.Context) (*EnvironmentStatus, error)
+}
@@ -0,0 +1,122 @@
+package types
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Sentinel errors for errors.Is. They match any HumanError with the same
+// code, whatever its message or vendor:
+//
+//	if errors.Is(err, types.ErrONUNotFound) { ... }
+var (
+	ErrONUExists       = &HumanError{Code: ErrCodeONUExists}
+	ErrONUNotFound     = &HumanError{Code: ErrCodeONUNotFound}
+	ErrProfileNotFound = &HumanError{Code: ErrCodeProfileNotFound}
+	ErrPortNotFound    = &HumanError{Code: ErrCodePortNotFound}
+	ErrPortFull        = &HumanError{Code: ErrCodePortFull}
+	ErrAuthFailed      = &HumanError{Code: ErrCodeAuthFailed}
+	ErrConfigLocked    = &HumanError{Code: ErrCodeConfigLocked}
+	ErrUnreachable     = &HumanError{Code: ErrCodeUnreachable}
+	ErrTimeout         = &HumanError{Code: ErrCodeTimeout}
+)
+
+// ErrorCode returns the code of the first HumanError in err's chain, or ""
+// if there is none
+func ErrorCode(err error) string {
+	var h *HumanError
+	if errors.As(err, &h) {
+		return h.Code
+	}
+	return ""
+}
+
+// codePatterns map device and transport error text to error codes. They
+// are checked in order against the lowercased text, so the more specific
+// patterns come first.
+var codePatterns = []struct {
+	code    string
+	pattern *regexp.Regexp
+}{
+	{ErrCodeAuthFailed, regexp.MustCompile(`unable to authenticate|authentication fail|auth(entication)? failed|permission denied|access denied|login incorrect`)},
+	{ErrCodeConfigLocked, regexp.MustCompile(`\blocked\b|being (configured|modified) by|(another|other) user`)},
+	{ErrCodeUnreachable, regexp.MustCompile(`connection refused|no route to host|(network|host) is unreachable|no such host`)},
+	{ErrCodeTimeout, regexp.MustCompile(`timeout|timed out`)},
+	{ErrCodeConnReset, regexp.MustCompile(`connection reset|broken pipe`)},
+	{ErrCodeProfileNotFound, regexp.MustCompile(`profile.*(not|n't) (exist|found)`)},
+	{ErrCodeONUExists, regexp.MustCompile(`\bon[ut]\b.*already (exist|registered|bound)|(sn|serial).*already exist`)},
+	{ErrCodeONUNotFound, regexp.MustCompile(`\bon[ut]\b.*(not|n't) (exist|found)`)},
+	{ErrCodePortNotFound, regexp.MustCompile(`\bport\b.*(not|n't) (exist|found)`)},
+	{ErrCodePortFull, regexp.MustCompile(`upper limit|no (free|available) on[ut]|on[ut] (id )?is full|reached the maximum`)},
+	{ErrCodeUnknownCommand, regexp.MustCompile(`unknown command|no matched command|unrecognized command|invalid input`)},
+}
+
+// CodeForMessage returns the error code of an error message from a device
+// or its transport, or ErrCodeUnknown if the message matches no code. The
+// patterns are vendor-neutral; adapters with a known error format map it
+// themselves first.
+func CodeForMessage(msg string) string {
+	msg = strings.ToLower(msg)
+	for _, c := range codePatterns {
+		if c.pattern.MatchString(msg) {
+			return c.code
+		}
+	}
+	return ErrCodeUnknown
+}
+
+// recoverableCodes are the codes of errors that may clear on retry
+var recoverableCodes = map[string]bool{
+	ErrCodeConfigLocked: true,
+	ErrCodeUnreachable:  true,
+	ErrCodeTimeout:      true,
+	ErrCodeConnReset:    true,
+}
+
+// AsHumanError converts err into a *HumanError of vendor with the code of
+// its message, keeping err as the underlying error. Errors that already
+// hold a HumanError, and errors whose message matches no code, are
+// returned unchanged.
+func AsHumanError(vendor string, err error) error {
+	if err == nil || ErrorCode(err) != "" {
+		return err
+	}
+	code := CodeForMessage(err.Error())
+	if code == ErrCodeUnknown {
+		return err
+	}
+	return &HumanError{
+		Code:        code,
+		Message:     err.Error(),
+		Vendor:      vendor,
+		Recoverable: recoverableCodes[code] || ClassifyError(err) == ErrorTransient,
+		Err:         err,
+	}
+}
+
+// ConnectError converts a failure to connect to a device into a
+// *HumanError of vendor: ErrCodeAuthFailed if the device rejected the
+// credentials, ErrCodeUnreachable otherwise
+func ConnectError(vendor string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if CodeForMessage(err.Error()) == ErrCodeAuthFailed {
+		return &HumanError{
+			Code:    ErrCodeAuthFailed,
+			Message: err.Error(),
+			Action:  "Check the device credentials",
+			Vendor:  vendor,
+			Err:     err,
+		}
+	}
+	return &HumanError{
+		Code:        ErrCodeUnreachable,
+		Message:     err.Error(),
+		Action:      "Check that the device is up and reachable from this host",
+		Vendor:      vendor,
+		Recoverable: true,
+		Err:         err,
+	}
+}
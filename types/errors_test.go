@@ -0,0 +1,92 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestHumanErrorIs(t *testing.T) {
+	err := fmt.Errorf("provisioning failed: %w", &HumanError{Code: ErrCodeONUNotFound, Message: "ONT 5 not found", Vendor: "huawei"})
+	if !errors.Is(err, ErrONUNotFound) {
+		t.Error("errors.Is(err, ErrONUNotFound) = false")
+	}
+	if errors.Is(err, ErrONUExists) {
+		t.Error("errors.Is(err, ErrONUExists) = true")
+	}
+	if ErrorCode(err) != ErrCodeONUNotFound || ErrorCode(errors.New("plain")) != "" {
+		t.Errorf("ErrorCode() = %q", ErrorCode(err))
+	}
+
+	// The underlying error stays reachable
+	dial := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	err = &HumanError{Code: ErrCodeUnreachable, Err: dial}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || !errors.Is(err, ErrUnreachable) {
+		t.Errorf("errors.As/Is on %v failed", err)
+	}
+}
+
+func TestCodeForMessage(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"ssh: handshake failed: ssh: unable to authenticate, attempted methods [none password]", ErrCodeAuthFailed},
+		{"dial tcp 10.0.0.1:22: connect: connection refused", ErrCodeUnreachable},
+		{"dial tcp 10.0.0.1:22: i/o timeout", ErrCodeTimeout},
+		{"Failure: The configuration is being modified by other user", ErrCodeConfigLocked},
+		{"Failure: SN already exists", ErrCodeONUExists},
+		{"% Error: onu 5 already exist", ErrCodeONUExists},
+		{"Failure: The ONT does not exist", ErrCodeONUNotFound},
+		{"Failure: The line profile does not exist", ErrCodeProfileNotFound},
+		{"Failure: The port does not exist", ErrCodePortNotFound},
+		{"Failure: The number of ONTs reaches the upper limit", ErrCodePortFull},
+		{"% Unknown command.", ErrCodeUnknownCommand},
+		{"something else went wrong", ErrCodeUnknown},
+	}
+	for _, tt := range tests {
+		if got := CodeForMessage(tt.msg); got != tt.want {
+			t.Errorf("CodeForMessage(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestAsHumanError(t *testing.T) {
+	err := AsHumanError("vsol", errors.New("dial tcp 10.0.0.1:22: connect: connection refused"))
+	var h *HumanError
+	if !errors.As(err, &h) || h.Code != ErrCodeUnreachable || h.Vendor != "vsol" || !h.Recoverable {
+		t.Errorf("AsHumanError() = %#v", err)
+	}
+	if ClassifyError(err) != ErrorTransient {
+		t.Error("unreachable error should be transient")
+	}
+
+	// Typed and unclassified errors are returned unchanged
+	typed := &HumanError{Code: ErrCodeValidationFailed}
+	if got := AsHumanError("vsol", typed); got != typed {
+		t.Errorf("AsHumanError(typed) = %v", got)
+	}
+	plain := errors.New("something else went wrong")
+	if got := AsHumanError("vsol", plain); got != plain {
+		t.Errorf("AsHumanError(plain) = %v", got)
+	}
+	if AsHumanError("vsol", nil) != nil {
+		t.Error("AsHumanError(nil) != nil")
+	}
+}
+
+func TestConnectError(t *testing.T) {
+	err := ConnectError("huawei", errors.New("failed to dial SSH: ssh: handshake failed: ssh: unable to authenticate"))
+	if !errors.Is(err, ErrAuthFailed) || ClassifyError(err) != ErrorPermanent {
+		t.Errorf("ConnectError(auth) = %v", err)
+	}
+	err = ConnectError("huawei", errors.New("failed to dial SSH: dial tcp 10.0.0.1:22: i/o timeout"))
+	if !errors.Is(err, ErrUnreachable) || ClassifyError(err) != ErrorTransient {
+		t.Errorf("ConnectError(timeout) = %v", err)
+	}
+	if ConnectError("huawei", nil) != nil {
+		t.Error("ConnectError(nil) != nil")
+	}
+}
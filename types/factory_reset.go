@@ -0,0 +1,107 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultFactoryResetTimeout is how long FactoryResetONU waits for the
+	// ONU to register again when FactoryResetOptions.VerifyTimeout is not
+	// set
+	DefaultFactoryResetTimeout = 5 * time.Minute
+
+	// DefaultFactoryResetPollInterval is how often FactoryResetONU checks
+	// whether the ONU registered again
+	DefaultFactoryResetPollInterval = 15 * time.Second
+)
+
+// FactoryResetOptions controls DriverV2.FactoryResetONU
+type FactoryResetOptions struct {
+	// Force confirms the reset. A factory reset erases the configuration
+	// stored on the ONU, such as WAN, Wi-Fi and voice settings, so adapters
+	// refuse to send it without Force.
+	Force bool `json:"force"`
+
+	// VerifyTimeout is how long to wait for the ONU to register again.
+	// Defaults to DefaultFactoryResetTimeout; negative skips verification.
+	VerifyTimeout time.Duration `json:"verify_timeout,omitempty"`
+
+	// PollInterval is how often the ONU state is checked while waiting.
+	// Defaults to DefaultFactoryResetPollInterval.
+	PollInterval time.Duration `json:"poll_interval,omitempty"`
+}
+
+// FactoryResetResult is the outcome of DriverV2.FactoryResetONU
+type FactoryResetResult struct {
+	// Success is true when the reset was sent and, unless verification
+	// was skipped, the ONU registered again
+	Success bool `json:"success"`
+
+	// ResetSent is true when the OLT accepted the reset command
+	ResetSent bool `json:"reset_sent"`
+
+	// Reregistered is true when the ONU was seen online after the reset
+	Reregistered bool `json:"reregistered"`
+
+	// Message contains a human-readable description of what happened
+	Message string `json:"message"`
+
+	// Error contains error details if the operation failed
+	Error string `json:"error,omitempty"`
+}
+
+// CheckFactoryReset returns the error FactoryResetONU fails with when opts
+// does not confirm the reset
+func CheckFactoryReset(opts *FactoryResetOptions, vendor string) error {
+	if opts == nil || !opts.Force {
+		return &HumanError{
+			Code:    ErrCodeValidationFailed,
+			Message: "factory reset erases the ONU configuration and must be confirmed with Force",
+			Vendor:  vendor,
+		}
+	}
+	return nil
+}
+
+// AwaitReregistration polls online until it reports the ONU online, the
+// verify timeout passes or ctx is done. The first check is made one poll
+// interval after the call, as the ONU goes offline while it resets. Errors
+// from online are treated as the ONU not being back yet.
+func (o *FactoryResetOptions) AwaitReregistration(ctx context.Context, online func(context.Context) (bool, error)) bool {
+	timeout, interval := DefaultFactoryResetTimeout, DefaultFactoryResetPollInterval
+	if o != nil {
+		if o.VerifyTimeout != 0 {
+			timeout = o.VerifyTimeout
+		}
+		if o.PollInterval > 0 {
+			interval = o.PollInterval
+		}
+	}
+	if timeout < 0 {
+		return false
+	}
+
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+		if ok, err := online(ctx); err == nil && ok {
+			return true
+		}
+		if !time.Now().Before(deadline) {
+			return false
+		}
+	}
+}
+
+// SkipsVerification reports whether FactoryResetONU returns without
+// waiting for the ONU to register again
+func (o *FactoryResetOptions) SkipsVerification() bool {
+	return o != nil && o.VerifyTimeout < 0
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckFactoryReset(t *testing.T) {
+	for _, opts := range []*FactoryResetOptions{nil, {}} {
+		var he *HumanError
+		if err := CheckFactoryReset(opts, "test"); !errors.As(err, &he) || he.Code != ErrCodeValidationFailed {
+			t.Errorf("CheckFactoryReset(%+v) = %v, want %s", opts, err, ErrCodeValidationFailed)
+		}
+	}
+	if err := CheckFactoryReset(&FactoryResetOptions{Force: true}, "test"); err != nil {
+		t.Errorf("CheckFactoryReset(Force) = %v", err)
+	}
+}
+
+func TestAwaitReregistration(t *testing.T) {
+	opts := &FactoryResetOptions{Force: true, VerifyTimeout: time.Second, PollInterval: time.Millisecond}
+	polls := 0
+	ok := opts.AwaitReregistration(context.Background(), func(context.Context) (bool, error) {
+		polls++
+		if polls == 1 {
+			return false, errors.New("ONU offline")
+		}
+		return polls == 3, nil
+	})
+	if !ok || polls != 3 {
+		t.Errorf("AwaitReregistration() = %v after %d polls, want true after 3", ok, polls)
+	}
+
+	opts.VerifyTimeout = 5 * time.Millisecond
+	if opts.AwaitReregistration(context.Background(), func(context.Context) (bool, error) { return false, nil }) {
+		t.Error("AwaitReregistration() = true for an ONU that never comes back")
+	}
+
+	opts.VerifyTimeout = -1
+	if !opts.SkipsVerification() || opts.AwaitReregistration(context.Background(), func(context.Context) (bool, error) {
+		t.Error("online called with verification skipped")
+		return true, nil
+	}) {
+		t.Error("AwaitReregistration() = true with verification skipped")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	opts.VerifyTimeout = 0
+	if opts.AwaitReregistration(ctx, func(context.Context) (bool, error) { return true, nil }) {
+		t.Error("AwaitReregistration() = true after ctx was cancelled")
+	}
+}
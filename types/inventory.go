@@ -0,0 +1,100 @@
+package types
+
+import "context"
+
+// BoardInfo is a board (card) in an OLT chassis slot.
+type BoardInfo struct {
+	// Slot is the slot identifier (e.g., "0/1")
+	Slot string `json:"slot"`
+
+	// Type is the board type (e.g., "H901GPHF", "GPON")
+	Type string `json:"type"`
+
+	// Status is the board state as reported (e.g., "normal", "failed")
+	Status string `json:"status"`
+
+	// Online indicates if the board is in service
+	Online bool `json:"online"`
+
+	// SerialNumber is the board serial number
+	SerialNumber string `json:"serial_number,omitempty"`
+
+	// Hardware is the board hardware version
+	Hardware string `json:"hardware,omitempty"`
+
+	// Firmware is the board software version
+	Firmware string `json:"firmware,omitempty"`
+
+	// Ports is the number of ports on the board
+	Ports int `json:"ports,omitempty"`
+
+	// Metadata contains vendor-specific board data
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// BoardInventory is implemented by adapters that list the boards of a
+// chassis OLT
+type BoardInventory interface {
+	// GetBoards returns the boards, in slot order
+	GetBoards(ctx context.Context) ([]BoardInfo, error)
+}
+
+// Transceiver port types
+const (
+	TransceiverPortPON    = "pon"
+	TransceiverPortUplink = "uplink"
+)
+
+// TransceiverInfo is the pluggable optical module (SFP, SFP+, XFP) of a PON
+// or uplink port. Readings the OLT does not report are left at zero.
+type TransceiverInfo struct {
+	// Port is the port identifier (e.g., "0/1/0")
+	Port string `json:"port"`
+
+	// PortType is "pon" or "uplink"
+	PortType string `json:"port_type"`
+
+	// Present indicates if a module is plugged in
+	Present bool `json:"present"`
+
+	// Type is the module type (e.g., "GPON C+", "10GBASE-LR")
+	Type string `json:"type,omitempty"`
+
+	// Vendor is the module vendor name
+	Vendor string `json:"vendor,omitempty"`
+
+	// PartNumber is the vendor part number
+	PartNumber string `json:"part_number,omitempty"`
+
+	// SerialNumber is the module serial number
+	SerialNumber string `json:"serial_number,omitempty"`
+
+	// WavelengthNM is the transmit wavelength in nanometres
+	WavelengthNM int `json:"wavelength_nm,omitempty"`
+
+	// TemperatureC is the module temperature in Celsius
+	TemperatureC float64 `json:"temperature_c,omitempty"`
+
+	// TxPowerDBm is the transmit power
+	TxPowerDBm float64 `json:"tx_power_dbm,omitempty"`
+
+	// RxPowerDBm is the receive power
+	RxPowerDBm float64 `json:"rx_power_dbm,omitempty"`
+
+	// VoltageV is the supply voltage
+	VoltageV float64 `json:"voltage_v,omitempty"`
+
+	// BiasCurrentMA is the laser bias current
+	BiasCurrentMA float64 `json:"bias_current_ma,omitempty"`
+
+	// Metadata contains vendor-specific module data
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// TransceiverInventory is implemented by adapters that read the optical
+// modules of the OLT ports
+type TransceiverInventory interface {
+	// GetTransceivers returns the modules of the PON and uplink ports, in
+	// port order
+	GetTransceivers(ctx context.Context) ([]TransceiverInfo, error)
+}
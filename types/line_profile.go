@@ -288,7 +288,8 @@ type DBAProfile struct {
 	MaxBW     int    `json:"max_bw"`     // kbps (types 3, 4, 5)
 }
 
-// TrafficProfile represents a V-SOL traffic shaping profile.
+// TrafficProfile represents a V-SOL traffic shaping profile
+// (Huawei: "traffic table ip", with SIR as CIR).
 // Traffic profiles control per-GEM port SIR/PIR shaping.
 type TrafficProfile struct {
 	ID   int    `json:"id"`
@@ -0,0 +1,65 @@
+package types
+
+import "context"
+
+// LineQuality are the upstream error counters of a PON port or of one ONU,
+// for optical quality trending. Counters are cumulative since the OLT last
+// cleared them; rates come from the difference of two readings.
+type LineQuality struct {
+	// BIPErrors is the upstream BIP-8 error count
+	BIPErrors uint64 `json:"bip_errors"`
+
+	// FECCorrected is the number of upstream codewords corrected by FEC
+	FECCorrected uint64 `json:"fec_corrected"`
+
+	// FECUncorrectable is the number of upstream codewords FEC could not
+	// correct
+	FECUncorrectable uint64 `json:"fec_uncorrectable"`
+
+	// FECTotal is the number of upstream codewords received, 0 if not
+	// reported
+	FECTotal uint64 `json:"fec_total,omitempty"`
+
+	// BER is the upstream bit error rate as reported by the OLT, 0 if not
+	// reported
+	BER float64 `json:"ber,omitempty"`
+}
+
+// UncorrectableRatio returns the share of received codewords FEC could not
+// correct, or 0 if the total is not reported
+func (q *LineQuality) UncorrectableRatio() float64 {
+	if q.FECTotal == 0 {
+		return 0
+	}
+	return float64(q.FECUncorrectable) / float64(q.FECTotal)
+}
+
+// LineQualityReader is implemented by adapters that read the upstream BER
+// and FEC counters of PON ports and ONUs
+type LineQualityReader interface {
+	// GetPONLineQuality returns the counters of a PON port
+	GetPONLineQuality(ctx context.Context, ponPort string) (*LineQuality, error)
+
+	// GetONULineQuality returns the counters of one ONU
+	GetONULineQuality(ctx context.Context, ponPort string, onuID int) (*LineQuality, error)
+}
+
+// ListPortsWithLineQuality lists the PON ports of a driver and adds the line
+// quality counters of each port when the driver is a LineQualityReader.
+// Ports whose counters cannot be read are returned without them.
+func ListPortsWithLineQuality(ctx context.Context, d DriverV2) ([]*PONPortStatus, error) {
+	ports, err := d.ListPorts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reader, ok := d.(LineQualityReader)
+	if !ok {
+		return ports, nil
+	}
+	for _, port := range ports {
+		if q, err := reader.GetPONLineQuality(ctx, port.Port); err == nil {
+			port.LineQuality = q
+		}
+	}
+	return ports, nil
+}
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// lineQualityDriver has two ports and fails to read the counters of 0/2
+type lineQualityDriver struct {
+	DriverV2
+}
+
+func (lineQualityDriver) ListPorts(_ context.Context) ([]*PONPortStatus, error) {
+	return []*PONPortStatus{{Port: "0/1"}, {Port: "0/2"}}, nil
+}
+
+func (lineQualityDriver) GetPONLineQuality(_ context.Context, ponPort string) (*LineQuality, error) {
+	if ponPort == "0/2" {
+		return nil, errors.New("timeout")
+	}
+	return &LineQuality{FECCorrected: 10, FECUncorrectable: 1, FECTotal: 1000}, nil
+}
+
+func (lineQualityDriver) GetONULineQuality(_ context.Context, _ string, _ int) (*LineQuality, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestListPortsWithLineQuality(t *testing.T) {
+	ports, err := ListPortsWithLineQuality(context.Background(), lineQualityDriver{})
+	if err != nil {
+		t.Fatalf("ListPortsWithLineQuality() error = %v", err)
+	}
+	if ports[0].LineQuality == nil || ports[0].LineQuality.UncorrectableRatio() != 0.001 {
+		t.Errorf("port 0/1 line quality = %+v", ports[0].LineQuality)
+	}
+	if ports[1].LineQuality != nil {
+		t.Errorf("port 0/2 line quality = %+v", ports[1].LineQuality)
+	}
+
+	type plainDriver struct{ DriverV2 }
+	ports, err = ListPortsWithLineQuality(context.Background(), plainDriver{lineQualityDriver{}})
+	if err != nil || len(ports) != 2 || ports[0].LineQuality != nil {
+		t.Errorf("ListPortsWithLineQuality() = %+v, %v", ports, err)
+	}
+}
@@ -0,0 +1,21 @@
+package types
+
+import "log/slog"
+
+// Log returns the logger for this equipment: Logger if set, otherwise
+// slog.Default(). Records carry the equipment vendor and name so output
+// from many devices can be told apart.
+func (c *EquipmentConfig) Log() *slog.Logger {
+	if c == nil {
+		return slog.Default()
+	}
+	logger := c.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	device := c.Name
+	if device == "" {
+		device = c.Address
+	}
+	return logger.With("vendor", string(c.Vendor), "device", device)
+}
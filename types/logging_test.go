@@ -0,0 +1,43 @@
+package types
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestEquipmentConfigLog(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := &EquipmentConfig{
+		Name:    "olt-1",
+		Vendor:  VendorHuawei,
+		Address: "192.0.2.1",
+		Logger:  slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	}
+
+	cfg.Log().Debug("command", "command", "display version")
+	out := buf.String()
+	for _, want := range []string{"level=DEBUG", "vendor=huawei", "device=olt-1", `command="display version"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output %q missing %q", out, want)
+		}
+	}
+
+	buf.Reset()
+	cfg.Name = ""
+	cfg.Log().Warn("suppressed")
+	if !strings.Contains(buf.String(), "device=192.0.2.1") {
+		t.Errorf("log output %q should fall back to address", buf.String())
+	}
+}
+
+func TestEquipmentConfigLogDefault(t *testing.T) {
+	var cfg *EquipmentConfig
+	if cfg.Log() == nil {
+		t.Fatal("nil config should log to slog.Default()")
+	}
+	if (&EquipmentConfig{}).Log() == nil {
+		t.Fatal("config without Logger should log to slog.Default()")
+	}
+}
@@ -0,0 +1,149 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// DriverMetrics is a snapshot of a driver's operational counters. All
+// counters are cumulative since the driver was created.
+type DriverMetrics struct {
+	// Commands is the number of requests sent to the device: CLI
+	// commands, NETCONF RPCs, gNMI Get/Set requests and SNMP operations
+	Commands uint64 `json:"commands"`
+
+	// CommandErrors is the number of requests that failed
+	CommandErrors uint64 `json:"command_errors"`
+
+	// SNMPPDUs is the number of SNMP PDUs sent, including retries and
+	// the GETNEXT/GETBULK requests of a walk
+	SNMPPDUs uint64 `json:"snmp_pdus"`
+
+	// Retries is the number of requests retransmitted after no response
+	Retries uint64 `json:"retries"`
+
+	// Timeouts is the number of requests that failed with a timeout
+	Timeouts uint64 `json:"timeouts"`
+
+	// Reconnects is the number of successful connects after the first
+	Reconnects uint64 `json:"reconnects"`
+
+	// ParseFailures is the number of device responses that could not be
+	// parsed
+	ParseFailures uint64 `json:"parse_failures"`
+}
+
+// Add returns the sum of m and o
+func (m DriverMetrics) Add(o DriverMetrics) DriverMetrics {
+	return DriverMetrics{
+		Commands:      m.Commands + o.Commands,
+		CommandErrors: m.CommandErrors + o.CommandErrors,
+		SNMPPDUs:      m.SNMPPDUs + o.SNMPPDUs,
+		Retries:       m.Retries + o.Retries,
+		Timeouts:      m.Timeouts + o.Timeouts,
+		Reconnects:    m.Reconnects + o.Reconnects,
+		ParseFailures: m.ParseFailures + o.ParseFailures,
+	}
+}
+
+// MetricsProvider is implemented by drivers and adapters that expose
+// operational counters
+type MetricsProvider interface {
+	DriverMetrics() DriverMetrics
+}
+
+// CollectMetrics sums the metrics of every driver that implements
+// MetricsProvider. Adapters use it to combine their own counters with
+// those of their protocol drivers.
+func CollectMetrics(drivers ...Driver) DriverMetrics {
+	var total DriverMetrics
+	for _, d := range drivers {
+		if p, ok := d.(MetricsProvider); ok {
+			total = total.Add(p.DriverMetrics())
+		}
+	}
+	return total
+}
+
+// DriverCounters accumulates DriverMetrics. The zero value is ready to use
+// and it is safe for concurrent use.
+type DriverCounters struct {
+	commands      atomic.Uint64
+	commandErrors atomic.Uint64
+	snmpPDUs      atomic.Uint64
+	retries       atomic.Uint64
+	timeouts      atomic.Uint64
+	connects      atomic.Uint64
+	parseFailures atomic.Uint64
+}
+
+// RecordCommand counts one request and its outcome
+func (c *DriverCounters) RecordCommand(err error) {
+	c.commands.Add(1)
+	if err == nil {
+		return
+	}
+	c.commandErrors.Add(1)
+	if IsTimeout(err) {
+		c.timeouts.Add(1)
+	}
+}
+
+// RecordSNMPPDU counts one SNMP PDU sent
+func (c *DriverCounters) RecordSNMPPDU() {
+	c.snmpPDUs.Add(1)
+}
+
+// RecordRetry counts one retransmission
+func (c *DriverCounters) RecordRetry() {
+	c.retries.Add(1)
+}
+
+// RecordConnect counts a successful connect. Every connect after the
+// first is a reconnect.
+func (c *DriverCounters) RecordConnect() {
+	c.connects.Add(1)
+}
+
+// RecordParseFailure counts a response that could not be parsed
+func (c *DriverCounters) RecordParseFailure() {
+	c.parseFailures.Add(1)
+}
+
+// Snapshot returns the current counter values
+func (c *DriverCounters) Snapshot() DriverMetrics {
+	var reconnects uint64
+	if n := c.connects.Load(); n > 1 {
+		reconnects = n - 1
+	}
+	return DriverMetrics{
+		Commands:      c.commands.Load(),
+		CommandErrors: c.commandErrors.Load(),
+		SNMPPDUs:      c.snmpPDUs.Load(),
+		Retries:       c.retries.Load(),
+		Timeouts:      c.timeouts.Load(),
+		Reconnects:    reconnects,
+		ParseFailures: c.parseFailures.Load(),
+	}
+}
+
+// IsTimeout reports whether err is a timeout: a context deadline, a
+// network timeout, or a protocol library timeout reported only in the
+// error text (gosnmp "request timeout", goexpect "timer expired").
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var t interface{ Timeout() bool }
+	if errors.As(err, &t) && t.Timeout() {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "timeout") || strings.Contains(msg, "timed out") || strings.Contains(msg, "timer expired")
+}
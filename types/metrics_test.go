@@ -0,0 +1,76 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string { return "i/o deadline" }
+func (timeoutErr) Timeout() bool { return true }
+
+func TestIsTimeout(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("connection refused"), false},
+		{context.DeadlineExceeded, true},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), true},
+		{timeoutErr{}, true},
+		{errors.New("request timeout (after 3 retries)"), true},
+		{errors.New("expect: timer expired after 30 seconds"), true},
+	}
+	for _, tt := range tests {
+		if got := IsTimeout(tt.err); got != tt.want {
+			t.Errorf("IsTimeout(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestDriverCounters(t *testing.T) {
+	var c DriverCounters
+	c.RecordConnect()
+	c.RecordCommand(nil)
+	c.RecordCommand(errors.New("invalid input"))
+	c.RecordCommand(context.DeadlineExceeded)
+	c.RecordSNMPPDU()
+	c.RecordSNMPPDU()
+	c.RecordRetry()
+	c.RecordParseFailure()
+
+	want := DriverMetrics{Commands: 3, CommandErrors: 2, Timeouts: 1, SNMPPDUs: 2, Retries: 1, ParseFailures: 1}
+	if got := c.Snapshot(); got != want {
+		t.Errorf("Snapshot() = %+v, want %+v", got, want)
+	}
+
+	c.RecordConnect()
+	c.RecordConnect()
+	if got := c.Snapshot().Reconnects; got != 2 {
+		t.Errorf("Reconnects = %d, want 2", got)
+	}
+}
+
+// metricsDriver is a Driver that reports fixed metrics
+type metricsDriver struct {
+	Driver
+	m DriverMetrics
+}
+
+func (d metricsDriver) DriverMetrics() DriverMetrics { return d.m }
+
+func TestCollectMetrics(t *testing.T) {
+	a := metricsDriver{m: DriverMetrics{Commands: 2, Retries: 1}}
+	b := metricsDriver{m: DriverMetrics{Commands: 3, ParseFailures: 4}}
+	var plain Driver
+
+	got := CollectMetrics(a, plain, b)
+	want := DriverMetrics{Commands: 5, Retries: 1, ParseFailures: 4}
+	if got != want {
+		t.Errorf("CollectMetrics() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ONUCursor returns the cursor of an ONU for ONUFilter.After
+func ONUCursor(onu ONUInfo) string {
+	return fmt.Sprintf("%s:%d", onu.PONPort, onu.ONUID)
+}
+
+// NextONUCursor returns the cursor of the page after page, listed with
+// filter, or "" if page is the last one
+func NextONUCursor(page []ONUInfo, filter *ONUFilter) string {
+	if filter == nil || filter.Limit <= 0 || len(page) < filter.Limit {
+		return ""
+	}
+	return ONUCursor(page[len(page)-1])
+}
+
+// PageONUs returns the page of onus selected by the After and Limit of
+// filter, sorted by PON port and ONU ID. Without After and Limit onus are
+// returned as is. Adapters call it on their filtered list.
+func PageONUs(onus []ONUInfo, filter *ONUFilter) []ONUInfo {
+	if filter == nil || filter.After == "" && filter.Limit <= 0 {
+		return onus
+	}
+	slices.SortStableFunc(onus, compareONUs)
+	if filter.After != "" {
+		port, id := parseONUCursor(filter.After)
+		after := ONUInfo{PONPort: port, ONUID: id}
+		i, _ := slices.BinarySearchFunc(onus, after, compareONUs)
+		for i < len(onus) && compareONUs(onus[i], after) == 0 {
+			i++
+		}
+		onus = onus[i:]
+	}
+	if filter.Limit > 0 && len(onus) > filter.Limit {
+		onus = onus[:filter.Limit]
+	}
+	return onus
+}
+
+// parseONUCursor splits a cursor of ONUCursor into PON port and ONU ID
+func parseONUCursor(cursor string) (string, int) {
+	i := strings.LastIndex(cursor, ":")
+	if i < 0 {
+		return cursor, -1
+	}
+	id, err := strconv.Atoi(cursor[i+1:])
+	if err != nil {
+		return cursor, -1
+	}
+	return cursor[:i], id
+}
+
+// compareONUs orders ONUs by PON port, then by ONU ID
+func compareONUs(a, b ONUInfo) int {
+	if c := ComparePONPorts(a.PONPort, b.PONPort); c != 0 {
+		return c
+	}
+	return a.ONUID - b.ONUID
+}
+
+// ComparePONPorts orders PON ports such as "0/1/2" by their numbers
+func ComparePONPorts(a, b string) int {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, errX := strconv.Atoi(as[i])
+		y, errY := strconv.Atoi(bs[i])
+		if errX != nil || errY != nil {
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+			continue
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return len(as) - len(bs)
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPageONUs(t *testing.T) {
+	onus := func() []ONUInfo {
+		return []ONUInfo{
+			{PONPort: "0/10", ONUID: 1},
+			{PONPort: "0/2", ONUID: 3},
+			{PONPort: "0/2", ONUID: 1},
+			{PONPort: "0/1", ONUID: 7},
+		}
+	}
+	cursors := func(page []ONUInfo) []string {
+		var c []string
+		for _, onu := range page {
+			c = append(c, ONUCursor(onu))
+		}
+		return c
+	}
+
+	if got := PageONUs(onus(), &ONUFilter{PONPort: "0/2"}); !reflect.DeepEqual(got, onus()) {
+		t.Errorf("PageONUs() without paging = %v, want input order", cursors(got))
+	}
+
+	filter := &ONUFilter{Limit: 2}
+	var all []string
+	for i := 0; i < 3; i++ {
+		page := PageONUs(onus(), filter)
+		all = append(all, cursors(page)...)
+		if filter.After = NextONUCursor(page, filter); filter.After == "" {
+			break
+		}
+	}
+	want := []string{"0/1:7", "0/2:1", "0/2:3", "0/10:1"}
+	if !reflect.DeepEqual(all, want) {
+		t.Errorf("pages = %v, want %v", all, want)
+	}
+	if filter.After != "" {
+		t.Errorf("cursor after the last page = %q", filter.After)
+	}
+
+	// A cursor of a deleted ONU resumes after its position
+	if got := cursors(PageONUs(onus(), &ONUFilter{After: "0/2:2"})); !reflect.DeepEqual(got, []string{"0/2:3", "0/10:1"}) {
+		t.Errorf("PageONUs(After: 0/2:2) = %v", got)
+	}
+}
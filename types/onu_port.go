@@ -0,0 +1,80 @@
+package types
+
+import (
+	"context"
+	"fmt"
+)
+
+// ONUPortVLANMode is how an ONU Ethernet port handles VLAN tags
+type ONUPortVLANMode string
+
+const (
+	// ONUPortVLANAccess untags one VLAN on the port
+	ONUPortVLANAccess ONUPortVLANMode = "access"
+
+	// ONUPortVLANTrunk passes a list of tagged VLANs, and optionally
+	// untags a native VLAN
+	ONUPortVLANTrunk ONUPortVLANMode = "trunk"
+
+	// ONUPortVLANTransparent passes frames with their tags unchanged
+	ONUPortVLANTransparent ONUPortVLANMode = "transparent"
+)
+
+// ONUPortVLAN is the VLAN configuration of an ONU Ethernet port
+type ONUPortVLAN struct {
+	Mode ONUPortVLANMode `json:"mode"`
+
+	// VLAN is the access VLAN, or the native VLAN of a trunk (0 for none)
+	VLAN int `json:"vlan,omitempty"`
+
+	// TrunkVLANs are the tagged VLANs of a trunk
+	TrunkVLANs []int `json:"trunk_vlans,omitempty"`
+}
+
+// Validate checks that the VLAN configuration is complete for its mode
+func (v *ONUPortVLAN) Validate() error {
+	if v == nil {
+		return fmt.Errorf("port VLAN is required")
+	}
+	switch v.Mode {
+	case ONUPortVLANAccess:
+		if err := validateRange("access vlan", v.VLAN, 1, 4094); err != nil {
+			return err
+		}
+	case ONUPortVLANTrunk:
+		if len(v.TrunkVLANs) == 0 {
+			return fmt.Errorf("trunk vlans are required")
+		}
+		if v.VLAN != 0 {
+			if err := validateRange("native vlan", v.VLAN, 1, 4094); err != nil {
+				return err
+			}
+		}
+		for _, vlan := range v.TrunkVLANs {
+			if err := validateRange("trunk vlan", vlan, 1, 4094); err != nil {
+				return err
+			}
+		}
+	case ONUPortVLANTransparent:
+	default:
+		return fmt.Errorf("unsupported port VLAN mode %q", v.Mode)
+	}
+	return nil
+}
+
+// ONUEthPortManager is implemented by adapters that administer the
+// Ethernet (UNI) ports of ONUs. Ports are numbered from 1.
+type ONUEthPortManager interface {
+	// SetONUEthPortState enables or disables an ONU Ethernet port
+	SetONUEthPortState(ctx context.Context, ponPort string, onuID, port int, enabled bool) error
+
+	// SetONUEthPortVLAN sets the VLAN mode of an ONU Ethernet port
+	SetONUEthPortVLAN(ctx context.Context, ponPort string, onuID, port int, vlan *ONUPortVLAN) error
+}
+
+// ONUCATVManager is implemented by adapters that switch the CATV RF port
+// of combo GPON+CATV ONUs
+type ONUCATVManager interface {
+	// SetONUCATVState enables or disables the RF video output of an ONU
+	SetONUCATVState(ctx context.Context, ponPort string, onuID int, enabled bool) error
+}
@@ -0,0 +1,28 @@
+package types
+
+import "testing"
+
+func TestONUPortVLANValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		vlan    *ONUPortVLAN
+		wantErr bool
+	}{
+		{"access", &ONUPortVLAN{Mode: ONUPortVLANAccess, VLAN: 100}, false},
+		{"access without vlan", &ONUPortVLAN{Mode: ONUPortVLANAccess}, true},
+		{"trunk", &ONUPortVLAN{Mode: ONUPortVLANTrunk, TrunkVLANs: []int{100, 200}}, false},
+		{"trunk with native", &ONUPortVLAN{Mode: ONUPortVLANTrunk, VLAN: 10, TrunkVLANs: []int{100}}, false},
+		{"trunk without vlans", &ONUPortVLAN{Mode: ONUPortVLANTrunk, VLAN: 10}, true},
+		{"trunk vlan out of range", &ONUPortVLAN{Mode: ONUPortVLANTrunk, TrunkVLANs: []int{4095}}, true},
+		{"transparent", &ONUPortVLAN{Mode: ONUPortVLANTransparent}, false},
+		{"unknown mode", &ONUPortVLAN{Mode: "hybrid"}, true},
+		{"nil", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.vlan.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ONUTestKind is a test the OLT can run on an ONU
+type ONUTestKind string
+
+const (
+	// ONUTestSelfTest is the OMCI self-test of the ONU
+	ONUTestSelfTest ONUTestKind = "self_test"
+
+	// ONUTestLoopback loops traffic back on an ONU Ethernet port, to check
+	// the drop and the port without a device behind it
+	ONUTestLoopback ONUTestKind = "loopback"
+)
+
+// ONUTest is a test to run on an ONU
+type ONUTest struct {
+	Kind ONUTestKind `json:"kind"`
+
+	// Port is the Ethernet port a loopback runs on, numbered from 1
+	Port int `json:"port,omitempty"`
+}
+
+// Validate checks that the test can be sent to an ONU
+func (t ONUTest) Validate() error {
+	switch t.Kind {
+	case ONUTestSelfTest:
+		return nil
+	case ONUTestLoopback:
+		return validateRange("loopback port", t.Port, 1, 8)
+	default:
+		return fmt.Errorf("unsupported ONU test %q", t.Kind)
+	}
+}
+
+// ONUTestResult is the outcome of an ONUTest
+type ONUTestResult struct {
+	ONUTest
+
+	// Passed is true when the ONU reported the test as passed
+	Passed bool `json:"passed"`
+
+	// Detail is the result as reported by the ONU, e.g. the failed check
+	Detail string `json:"detail,omitempty"`
+
+	// Raw is the CLI output of the test
+	Raw string `json:"raw,omitempty"`
+
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ONUTester is implemented by adapters that run loopback and self-tests on
+// ONUs, so a drop can be validated before dispatching a technician
+type ONUTester interface {
+	// RunONUTest runs a test on an ONU. A test that ran and failed is a
+	// result with Passed false, not an error.
+	RunONUTest(ctx context.Context, ponPort string, onuID int, test ONUTest) (*ONUTestResult, error)
+}
+
+// RunDiagnosticsWithTests runs DriverV2.RunDiagnostics and, if d is an
+// ONUTester, the given tests, reporting their results in
+// ONUDiagnostics.Tests. A test that cannot be run is recorded as failed
+// with the error as its detail.
+func RunDiagnosticsWithTests(ctx context.Context, d DriverV2, ponPort string, onuID int, tests ...ONUTest) (*ONUDiagnostics, error) {
+	diag, err := d.RunDiagnostics(ctx, ponPort, onuID)
+	if err != nil {
+		return nil, err
+	}
+	tester, ok := d.(ONUTester)
+	if !ok {
+		if len(tests) > 0 {
+			return diag, fmt.Errorf("ONU tests are not supported by this driver")
+		}
+		return diag, nil
+	}
+	for _, test := range tests {
+		res, err := tester.RunONUTest(ctx, ponPort, onuID, test)
+		if err != nil {
+			res = &ONUTestResult{ONUTest: test, Detail: err.Error(), Timestamp: time.Now()}
+		}
+		diag.Tests = append(diag.Tests, *res)
+	}
+	return diag, nil
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// diagDriver returns empty diagnostics and fails loopbacks on port 2
+type diagDriver struct {
+	DriverV2
+}
+
+func (diagDriver) RunDiagnostics(_ context.Context, ponPort string, onuID int) (*ONUDiagnostics, error) {
+	return &ONUDiagnostics{PONPort: ponPort, ONUID: onuID}, nil
+}
+
+func (diagDriver) RunONUTest(_ context.Context, _ string, _ int, test ONUTest) (*ONUTestResult, error) {
+	if test.Port == 2 {
+		return nil, errors.New("port 2 is down")
+	}
+	return &ONUTestResult{ONUTest: test, Passed: true, Detail: "pass"}, nil
+}
+
+func TestONUTestValidate(t *testing.T) {
+	tests := []struct {
+		test    ONUTest
+		wantErr bool
+	}{
+		{ONUTest{Kind: ONUTestSelfTest}, false},
+		{ONUTest{Kind: ONUTestLoopback, Port: 1}, false},
+		{ONUTest{Kind: ONUTestLoopback}, true},
+		{ONUTest{Kind: "cable"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.test.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v.Validate() error = %v, wantErr %v", tt.test, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRunDiagnosticsWithTests(t *testing.T) {
+	diag, err := RunDiagnosticsWithTests(context.Background(), diagDriver{}, "0/1", 5,
+		ONUTest{Kind: ONUTestSelfTest},
+		ONUTest{Kind: ONUTestLoopback, Port: 2},
+	)
+	if err != nil {
+		t.Fatalf("RunDiagnosticsWithTests() error = %v", err)
+	}
+	if len(diag.Tests) != 2 {
+		t.Fatalf("Tests = %+v", diag.Tests)
+	}
+	if !diag.Tests[0].Passed || diag.Tests[1].Passed || diag.Tests[1].Detail != "port 2 is down" {
+		t.Errorf("Tests = %+v", diag.Tests)
+	}
+
+	type plainDriver struct{ DriverV2 }
+	if _, err := RunDiagnosticsWithTests(context.Background(), plainDriver{diagDriver{}}, "0/1", 5, ONUTest{Kind: ONUTestSelfTest}); err == nil {
+		t.Error("expected error for a driver without ONU tests")
+	}
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"context"
+	"iter"
+)
+
+// ONUStreamer is implemented by adapters that read the ONUs of GetONUList
+// one PON port at a time, so that OLTs with thousands of ONUs can be
+// processed without holding the whole list
+type ONUStreamer interface {
+	// GetONUStream yields the ONUs matching filter as each port is read.
+	// The After and Limit of filter are ignored. A failure is yielded once,
+	// with a zero ONUInfo, and ends the stream.
+	GetONUStream(ctx context.Context, filter *ONUFilter) iter.Seq2[ONUInfo, error]
+}
+
+// StreamONUs yields the ONUs of d matching filter, with GetONUStream if d
+// is an ONUStreamer and from a single GetONUList otherwise
+//
+//	for onu, err := range types.StreamONUs(ctx, driver, nil) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func StreamONUs(ctx context.Context, d ONUInventoryProvider, filter *ONUFilter) iter.Seq2[ONUInfo, error] {
+	if s, ok := d.(ONUStreamer); ok {
+		return s.GetONUStream(ctx, filter)
+	}
+	return StreamONUsByPort(ctx, nil, filter, d.GetONUList)
+}
+
+// StreamONUsByPort yields the ONUs matching filter one port of ports at a
+// time, listed with list and the filter limited to the port. The PON port
+// of filter, if any, replaces ports; without either, the ONUs are listed
+// at once. Adapters implement GetONUStream with it.
+func StreamONUsByPort(ctx context.Context, ports []string, filter *ONUFilter, list func(context.Context, *ONUFilter) ([]ONUInfo, error)) iter.Seq2[ONUInfo, error] {
+	return func(yield func(ONUInfo, error) bool) {
+		var f ONUFilter
+		if filter != nil {
+			f = *filter
+		}
+		f.After, f.Limit = "", 0
+		scan := ports
+		if f.PONPort != "" || len(ports) == 0 {
+			scan = []string{f.PONPort}
+		}
+		for _, port := range scan {
+			if err := ctx.Err(); err != nil {
+				yield(ONUInfo{}, err)
+				return
+			}
+			f.PONPort = port
+			onus, err := list(ctx, &f)
+			if err != nil {
+				yield(ONUInfo{}, err)
+				return
+			}
+			for _, onu := range onus {
+				if !yield(onu, nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStreamONUsByPort(t *testing.T) {
+	var listed []ONUFilter
+	list := func(_ context.Context, f *ONUFilter) ([]ONUInfo, error) {
+		listed = append(listed, *f)
+		if f.PONPort == "0/3" {
+			return nil, errors.New("timeout")
+		}
+		return []ONUInfo{{PONPort: f.PONPort, ONUID: 1}, {PONPort: f.PONPort, ONUID: 2}}, nil
+	}
+	ctx := context.Background()
+
+	var got []string
+	for onu, err := range StreamONUsByPort(ctx, []string{"0/1", "0/2"}, &ONUFilter{Status: "online", Limit: 1}, list) {
+		if err != nil {
+			t.Fatalf("stream error = %v", err)
+		}
+		got = append(got, ONUCursor(onu))
+	}
+	if want := []string{"0/1:1", "0/1:2", "0/2:1", "0/2:2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("streamed %v, want %v", got, want)
+	}
+	if want := []ONUFilter{{PONPort: "0/1", Status: "online"}, {PONPort: "0/2", Status: "online"}}; !reflect.DeepEqual(listed, want) {
+		t.Errorf("listed with %+v, want %+v", listed, want)
+	}
+
+	// Breaking out stops the listing of the next ports
+	listed = nil
+	for range StreamONUsByPort(ctx, []string{"0/1", "0/2"}, nil, list) {
+		break
+	}
+	if len(listed) != 1 {
+		t.Errorf("listed %d ports after break, want 1", len(listed))
+	}
+
+	// A failure ends the stream
+	var errs int
+	for _, err := range StreamONUsByPort(ctx, []string{"0/3", "0/1"}, nil, list) {
+		if err != nil {
+			errs++
+		}
+	}
+	if errs != 1 || len(listed) != 2 {
+		t.Errorf("errors = %d, listed = %+v", errs, listed)
+	}
+
+	// The port of the filter replaces the ports
+	listed = nil
+	for range StreamONUsByPort(ctx, []string{"0/1", "0/2"}, &ONUFilter{PONPort: "0/2"}, list) {
+	}
+	if len(listed) != 1 || listed[0].PONPort != "0/2" {
+		t.Errorf("listed %+v", listed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	for _, err := range StreamONUsByPort(cancelled, []string{"0/1"}, nil, list) {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error = %v, want context.Canceled", err)
+		}
+	}
+}
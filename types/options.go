@@ -0,0 +1,52 @@
+package types
+
+// Option sets a setting of an EquipmentConfig when a driver or adapter is
+// constructed. Options replace the Metadata keys adapters used to read;
+// Metadata is left for vendor-specific extensions.
+type Option func(*EquipmentConfig)
+
+// Apply applies opts to c in order
+func (c *EquipmentConfig) Apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+// WithSNMPCommunity sets the SNMP community, taking precedence over the
+// "snmp_community" metadata key
+func WithSNMPCommunity(community string) Option {
+	return func(c *EquipmentConfig) {
+		c.SNMPCommunity = community
+	}
+}
+
+// WithCLIFallback has adapters built on an SNMP driver open a CLI session
+// too, with the credentials of the config
+func WithCLIFallback(enabled bool) Option {
+	return func(c *EquipmentConfig) {
+		c.CLIFallback = enabled
+	}
+}
+
+// WithPONPortList sets the PON ports of the device
+func WithPONPortList(ports ...string) Option {
+	return func(c *EquipmentConfig) {
+		c.PONPorts = ports
+	}
+}
+
+// WithModel sets the device model
+func WithModel(model string) Option {
+	return func(c *EquipmentConfig) {
+		c.Model = model
+	}
+}
+
+// DeviceModel returns Model, or the "model" metadata key of configs that
+// predate it
+func (c *EquipmentConfig) DeviceModel() string {
+	if c.Model != "" {
+		return c.Model
+	}
+	return c.Metadata["model"]
+}
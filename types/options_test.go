@@ -0,0 +1,38 @@
+package types
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestApplyOptions(t *testing.T) {
+	cfg := &EquipmentConfig{}
+	cfg.Apply(
+		WithSNMPCommunity("private"),
+		WithCLIFallback(true),
+		WithPONPortList("0/1", "0/2"),
+		WithModel("v1600g8"),
+	)
+	if cfg.SNMPCommunity != "private" || !cfg.CLIFallback || cfg.Model != "v1600g8" {
+		t.Errorf("config = %+v", cfg)
+	}
+	if !slices.Equal(cfg.PONPorts, []string{"0/1", "0/2"}) {
+		t.Errorf("PONPorts = %v", cfg.PONPorts)
+	}
+}
+
+func TestDeviceModel(t *testing.T) {
+	cfg := &EquipmentConfig{}
+	if got := cfg.DeviceModel(); got != "" {
+		t.Errorf("DeviceModel() = %q, want empty", got)
+	}
+	// Configs that predate WithModel set the model in metadata
+	cfg.Metadata = map[string]string{"model": "ma5608t"}
+	if got := cfg.DeviceModel(); got != "ma5608t" {
+		t.Errorf("DeviceModel() = %q, want ma5608t", got)
+	}
+	cfg.Apply(WithModel("ma5800-x7"))
+	if got := cfg.DeviceModel(); got != "ma5800-x7" {
+		t.Errorf("DeviceModel() = %q, want ma5800-x7", got)
+	}
+}
@@ -0,0 +1,158 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// ErrPolicyViolation matches every PolicyError with errors.Is
+var ErrPolicyViolation = errors.New("command denied by policy")
+
+// PolicyError is returned by protocol drivers for a configuration change
+// the device's CommandPolicy does not permit. Nothing is sent.
+type PolicyError struct {
+	Device   string
+	Protocol string
+	Command  string
+
+	// Rule is the deny pattern the command matched, or empty if it matched
+	// no allow pattern
+	Rule string
+}
+
+func (e *PolicyError) Error() string {
+	if e.Rule == "" {
+		return fmt.Sprintf("%s command %q on %s is not in the allowlist", e.Protocol, e.Command, e.Device)
+	}
+	return fmt.Sprintf("%s command %q on %s is denied by rule %q", e.Protocol, e.Command, e.Device, e.Rule)
+}
+
+// Is reports whether target is ErrPolicyViolation
+func (e *PolicyError) Is(target error) bool {
+	return target == ErrPolicyViolation
+}
+
+// DefaultDeny are patterns for commands that wipe or reload a device. It
+// is not applied unless listed in a CommandPolicy.
+var DefaultDeny = []string{
+	`(?i)^\s*erase\s+(startup|nvram|flash)`,
+	`(?i)^\s*write\s+erase`,
+	`(?i)^\s*(format|delete)\s+\S*flash`,
+	`(?i)^\s*(reload|reboot)(\s|$)`,
+	`(?i)^\s*reset\s+saved-configuration`,
+	`(?i)factory[-_ ]?(default|reset)`,
+	`(?i)<delete-config>`,
+}
+
+// CommandRules are regular expressions matched against the configuration
+// changes sent to a device: CLI commands, NETCONF RPCs, or gNMI operations
+// as "<type> <path> = <value>". Read-only commands and RPCs are not
+// checked.
+type CommandRules struct {
+	// Allow, if not empty, limits changes to those matching a pattern
+	Allow []string
+
+	// Deny rejects changes matching a pattern, even if allowed
+	Deny []string
+}
+
+// CommandPolicy validates configuration changes before protocol drivers
+// send them. Rules in Default apply to every device, and rules in Vendors
+// are added for devices of that vendor. Patterns are compiled on first
+// use; an invalid pattern fails every check. Safe for concurrent use.
+type CommandPolicy struct {
+	Default CommandRules
+	Vendors map[Vendor]CommandRules
+
+	once     sync.Once
+	compiled map[Vendor]*compiledRules
+	err      error
+}
+
+type compiledRules struct {
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+}
+
+func (p *CommandPolicy) compile() {
+	p.compiled = make(map[Vendor]*compiledRules)
+	add := func(r *compiledRules, rules CommandRules) error {
+		for _, list := range []struct {
+			patterns []string
+			into     *[]*regexp.Regexp
+		}{{rules.Allow, &r.allow}, {rules.Deny, &r.deny}} {
+			for _, pattern := range list.patterns {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return fmt.Errorf("invalid command policy pattern %q: %w", pattern, err)
+				}
+				*list.into = append(*list.into, re)
+			}
+		}
+		return nil
+	}
+
+	def := &compiledRules{}
+	if p.err = add(def, p.Default); p.err != nil {
+		return
+	}
+	p.compiled[""] = def
+	for vendor, rules := range p.Vendors {
+		r := &compiledRules{allow: def.allow[:len(def.allow):len(def.allow)], deny: def.deny[:len(def.deny):len(def.deny)]}
+		if p.err = add(r, rules); p.err != nil {
+			return
+		}
+		p.compiled[vendor] = r
+	}
+}
+
+// Check returns a PolicyError if command may not be sent to a device of
+// vendor
+func (p *CommandPolicy) Check(vendor Vendor, protocol, command string) error {
+	p.once.Do(p.compile)
+	if p.err != nil {
+		return p.err
+	}
+	rules, ok := p.compiled[vendor]
+	if !ok {
+		rules = p.compiled[""]
+	}
+	for _, re := range rules.deny {
+		if re.MatchString(command) {
+			return &PolicyError{Protocol: protocol, Command: command, Rule: re.String()}
+		}
+	}
+	if len(rules.allow) == 0 {
+		return nil
+	}
+	for _, re := range rules.allow {
+		if re.MatchString(command) {
+			return nil
+		}
+	}
+	return &PolicyError{Protocol: protocol, Command: command}
+}
+
+// CheckCommands is called by protocol drivers before sending configuration
+// changes. It returns the first violation of config.CommandPolicy, or nil
+// if there is no policy.
+func CheckCommands(config *EquipmentConfig, protocol string, commands []string) error {
+	if config == nil || config.CommandPolicy == nil {
+		return nil
+	}
+	for _, command := range commands {
+		err := config.CommandPolicy.Check(config.Vendor, protocol, command)
+		if err == nil {
+			continue
+		}
+		var pe *PolicyError
+		if errors.As(err, &pe) {
+			pe.Device = config.Name
+		}
+		config.Log().Warn("command denied by policy", "protocol", protocol, "command", command, "error", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCommandPolicy(t *testing.T) {
+	p := &CommandPolicy{
+		Default: CommandRules{Deny: DefaultDeny},
+		Vendors: map[Vendor]CommandRules{
+			VendorHuawei: {Allow: []string{`^ont `, `^service-port `}, Deny: []string{`^ont delete all`}},
+		},
+	}
+	tests := []struct {
+		vendor  Vendor
+		command string
+		allowed bool
+	}{
+		{VendorVSOL, "onu add 1 sn VSOL00000001", true},
+		{VendorVSOL, "erase startup-config", false},
+		{VendorVSOL, "Write Erase", false},
+		{VendorVSOL, "reload", false},
+		{VendorNokia, "<rpc><delete-config><target><startup/></target></delete-config></rpc>", false},
+		{VendorHuawei, "ont add 1 sn-auth HWTC00000001", true},
+		{VendorHuawei, "ont delete all", false},
+		{VendorHuawei, "sysname core", false},
+		{VendorHuawei, "reboot system", false},
+	}
+	for _, tt := range tests {
+		err := p.Check(tt.vendor, "cli", tt.command)
+		if (err == nil) != tt.allowed {
+			t.Errorf("Check(%s, %q) = %v, want allowed %v", tt.vendor, tt.command, err, tt.allowed)
+		}
+		if err != nil && !errors.Is(err, ErrPolicyViolation) {
+			t.Errorf("Check(%s, %q) = %v, want ErrPolicyViolation", tt.vendor, tt.command, err)
+		}
+	}
+
+	bad := &CommandPolicy{Default: CommandRules{Deny: []string{"("}}}
+	if err := bad.Check(VendorVSOL, "cli", "onu add"); err == nil || errors.Is(err, ErrPolicyViolation) {
+		t.Errorf("invalid pattern: err = %v", err)
+	}
+
+	if err := CheckCommands(&EquipmentConfig{Name: "olt-1"}, "cli", []string{"reload"}); err != nil {
+		t.Errorf("no policy: err = %v", err)
+	}
+}
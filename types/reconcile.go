@@ -0,0 +1,184 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/nanoncore/nano-southbound/model"
+)
+
+// ReconcileAction is what Reconcile did to bring a subscriber in line
+type ReconcileAction string
+
+const (
+	// ReconcileUnchanged means the device already matched the desired state
+	ReconcileUnchanged ReconcileAction = "unchanged"
+	// ReconcileCreated means the ONU was not provisioned and was created
+	ReconcileCreated ReconcileAction = "created"
+	// ReconcileUpdated means the ONU existed and drifted fields were updated
+	ReconcileUpdated ReconcileAction = "updated"
+)
+
+// Location annotations read and set by Reconcile. Vendor adapters accept
+// different keys, so all of them are checked.
+var (
+	ponPortAnnotations = []string{"nanoncore.com/gpon-fsp", "nanoncore.com/pon-port", "nano.io/pon-port"}
+	onuIDAnnotations   = []string{"nanoncore.com/ont-id", "nanoncore.com/onu-id", "nano.io/onu-id"}
+)
+
+// ReconcileChange is one field where the device differs from the desired
+// state
+type ReconcileChange struct {
+	Field   string `json:"field"`
+	Actual  string `json:"actual"`
+	Desired string `json:"desired"`
+}
+
+// ReconcileResult describes the outcome of Reconcile
+type ReconcileResult struct {
+	// Action is what was done
+	Action ReconcileAction `json:"action"`
+
+	// Changes lists the drifted fields. Empty unless Action is updated.
+	Changes []ReconcileChange `json:"changes,omitempty"`
+
+	// ONU is the device state read before any change, nil if the ONU was
+	// not provisioned
+	ONU *ONUInfo `json:"onu,omitempty"`
+
+	// Subscriber is the CreateSubscriber result when Action is created
+	Subscriber *SubscriberResult `json:"subscriber,omitempty"`
+}
+
+// ONULookup is implemented by drivers that can find a provisioned ONU by
+// serial number. DriverV2 includes it.
+type ONULookup interface {
+	GetONUBySerial(ctx context.Context, serial string) (*ONUInfo, error)
+}
+
+// Reconcile brings a subscriber's ONU on the device in line with the
+// desired subscriber and tier. It reads the ONU by serial, creates it if it
+// is not provisioned, updates it if VLAN, bandwidth or profiles drifted,
+// and does nothing if it already matches, so it is safe to re-run where
+// CreateSubscriber would fail or duplicate configuration.
+//
+// Fields the device does not report (zero or empty in ONUInfo) are not
+// compared. An ONU found on a different PON port or ONU ID than the
+// subscriber's annotations is an error: moving ONUs is out of scope.
+// Subscribers of another tenant than ctx is scoped to are refused.
+func Reconcile(ctx context.Context, driver Driver, subscriber *model.Subscriber, tier *model.ServiceTier) (*ReconcileResult, error) {
+	if subscriber == nil || tier == nil {
+		return nil, fmt.Errorf("subscriber and tier are required")
+	}
+	if subscriber.Spec.ONUSerial == "" {
+		return nil, fmt.Errorf("subscriber %s has no ONU serial", subscriber.Name)
+	}
+	if err := CheckTenant(ctx, "subscriber", subscriber.Name, subscriber.Tenant); err != nil {
+		return nil, err
+	}
+	lookup, ok := driver.(ONULookup)
+	if !ok {
+		return nil, fmt.Errorf("reconcile requires a driver that supports GetONUBySerial")
+	}
+
+	actual, err := lookup.GetONUBySerial(ctx, subscriber.Spec.ONUSerial)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ONU %s: %w", subscriber.Spec.ONUSerial, err)
+	}
+	if actual == nil {
+		res, err := driver.CreateSubscriber(ctx, subscriber, tier)
+		if err != nil {
+			return nil, err
+		}
+		return &ReconcileResult{Action: ReconcileCreated, Subscriber: res}, nil
+	}
+
+	if err := checkLocation(actual, subscriber); err != nil {
+		return nil, err
+	}
+
+	result := &ReconcileResult{Action: ReconcileUnchanged, ONU: actual}
+	result.Changes = DiffSubscriber(actual, subscriber, tier)
+	if len(result.Changes) == 0 {
+		return result, nil
+	}
+
+	// Pin the update to the ONU that was found, whatever ID the adapter
+	// would otherwise derive
+	if err := driver.UpdateSubscriber(ctx, pinLocation(subscriber, actual), tier); err != nil {
+		return nil, fmt.Errorf("failed to update ONU %s: %w", subscriber.Spec.ONUSerial, err)
+	}
+	result.Action = ReconcileUpdated
+	return result, nil
+}
+
+// DiffSubscriber compares the ONU state read from a device with the
+// desired subscriber and tier. Fields the device does not report are
+// skipped.
+func DiffSubscriber(actual *ONUInfo, subscriber *model.Subscriber, tier *model.ServiceTier) []ReconcileChange {
+	var changes []ReconcileChange
+	diffInt := func(field string, actual, desired int) {
+		if actual != 0 && desired != 0 && actual != desired {
+			changes = append(changes, ReconcileChange{field, strconv.Itoa(actual), strconv.Itoa(desired)})
+		}
+	}
+	diffString := func(field, actual, desired string) {
+		if actual != "" && desired != "" && actual != desired {
+			changes = append(changes, ReconcileChange{field, actual, desired})
+		}
+	}
+
+	diffInt("vlan", actual.VLAN, subscriber.Spec.VLAN)
+	diffInt("bandwidth_up", actual.BandwidthUp, tier.Spec.BandwidthUp)
+	diffInt("bandwidth_down", actual.BandwidthDown, tier.Spec.BandwidthDown)
+	diffString("line_profile", actual.LineProfile, tier.Annotations["nanoncore.com/line-profile"])
+	diffString("service_profile", actual.ServiceProfile, tier.Annotations["nanoncore.com/service-profile"])
+	return changes
+}
+
+// checkLocation fails if the subscriber pins its ONU to a different
+// location than where the serial was found
+func checkLocation(actual *ONUInfo, subscriber *model.Subscriber) error {
+	if port, ok := firstAnnotation(subscriber, ponPortAnnotations); ok && port != actual.PONPort {
+		return fmt.Errorf("ONU %s is on PON port %s, subscriber expects %s", actual.Serial, actual.PONPort, port)
+	}
+	if id, ok := firstAnnotation(subscriber, onuIDAnnotations); ok && id != strconv.Itoa(actual.ONUID) {
+		return fmt.Errorf("ONU %s has ID %d, subscriber expects %s", actual.Serial, actual.ONUID, id)
+	}
+	return nil
+}
+
+// PlaceSubscriber returns a copy of subscriber annotated with ponPort
+// under every key the adapters read, e.g. to provision an ONU on the port
+// it was discovered on
+func PlaceSubscriber(subscriber *model.Subscriber, ponPort string) *model.Subscriber {
+	placed := *subscriber
+	placed.Annotations = make(map[string]string, len(subscriber.Annotations)+len(ponPortAnnotations)+len(onuIDAnnotations))
+	for k, v := range subscriber.Annotations {
+		placed.Annotations[k] = v
+	}
+	for _, key := range ponPortAnnotations {
+		placed.Annotations[key] = ponPort
+	}
+	return &placed
+}
+
+// pinLocation returns a copy of subscriber annotated with the ONU's actual
+// PON port and ID under every key the adapters read
+func pinLocation(subscriber *model.Subscriber, actual *ONUInfo) *model.Subscriber {
+	pinned := PlaceSubscriber(subscriber, actual.PONPort)
+	for _, key := range onuIDAnnotations {
+		pinned.Annotations[key] = strconv.Itoa(actual.ONUID)
+	}
+	return pinned
+}
+
+func firstAnnotation(subscriber *model.Subscriber, keys []string) (string, bool) {
+	for _, key := range keys {
+		if v, ok := subscriber.Annotations[key]; ok && v != "" {
+			return v, true
+		}
+	}
+	return "", false
+}
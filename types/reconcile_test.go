@@ -0,0 +1,134 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/model"
+)
+
+// reconcileDriver is a Driver whose ONU state is a single optional ONU
+type reconcileDriver struct {
+	Driver
+	onu     *ONUInfo
+	created []*model.Subscriber
+	updated []*model.Subscriber
+}
+
+func (d *reconcileDriver) GetONUBySerial(_ context.Context, serial string) (*ONUInfo, error) {
+	if d.onu == nil || d.onu.Serial != serial {
+		return nil, nil
+	}
+	return d.onu, nil
+}
+
+func (d *reconcileDriver) CreateSubscriber(_ context.Context, sub *model.Subscriber, _ *model.ServiceTier) (*SubscriberResult, error) {
+	d.created = append(d.created, sub)
+	return &SubscriberResult{SubscriberID: sub.Name}, nil
+}
+
+func (d *reconcileDriver) UpdateSubscriber(_ context.Context, sub *model.Subscriber, _ *model.ServiceTier) error {
+	d.updated = append(d.updated, sub)
+	return nil
+}
+
+func reconcileFixture() (*model.Subscriber, *model.ServiceTier) {
+	sub := &model.Subscriber{
+		Name:        "sub-1",
+		Annotations: map[string]string{},
+		Spec:        model.SubscriberSpec{ONUSerial: "VSOL00000001", VLAN: 100},
+	}
+	tier := &model.ServiceTier{Spec: model.ServiceTierSpec{BandwidthUp: 50, BandwidthDown: 100}}
+	return sub, tier
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates missing ONU", func(t *testing.T) {
+		d := &reconcileDriver{}
+		sub, tier := reconcileFixture()
+		res, err := Reconcile(ctx, d, sub, tier)
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		if res.Action != ReconcileCreated || len(d.created) != 1 || res.Subscriber == nil {
+			t.Errorf("result = %+v, created = %d", res, len(d.created))
+		}
+	})
+
+	t.Run("matching ONU is unchanged", func(t *testing.T) {
+		d := &reconcileDriver{onu: &ONUInfo{Serial: "VSOL00000001", PONPort: "0/1", ONUID: 5, VLAN: 100, BandwidthUp: 50, BandwidthDown: 100}}
+		sub, tier := reconcileFixture()
+		res, err := Reconcile(ctx, d, sub, tier)
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		if res.Action != ReconcileUnchanged || len(d.created)+len(d.updated) != 0 {
+			t.Errorf("result = %+v, created = %d, updated = %d", res, len(d.created), len(d.updated))
+		}
+	})
+
+	t.Run("drifted ONU is updated in place", func(t *testing.T) {
+		d := &reconcileDriver{onu: &ONUInfo{Serial: "VSOL00000001", PONPort: "0/2", ONUID: 7, VLAN: 200, BandwidthDown: 100}}
+		sub, tier := reconcileFixture()
+		res, err := Reconcile(ctx, d, sub, tier)
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		if res.Action != ReconcileUpdated || len(d.updated) != 1 {
+			t.Fatalf("result = %+v, updated = %d", res, len(d.updated))
+		}
+		if len(res.Changes) != 1 || res.Changes[0] != (ReconcileChange{"vlan", "200", "100"}) {
+			t.Errorf("changes = %+v", res.Changes)
+		}
+		pinned := d.updated[0].Annotations
+		if pinned["nanoncore.com/pon-port"] != "0/2" || pinned["nanoncore.com/onu-id"] != "7" {
+			t.Errorf("update not pinned to the found ONU: %v", pinned)
+		}
+		if len(sub.Annotations) != 0 {
+			t.Errorf("caller's subscriber was modified: %v", sub.Annotations)
+		}
+	})
+
+	t.Run("ONU at another location is an error", func(t *testing.T) {
+		d := &reconcileDriver{onu: &ONUInfo{Serial: "VSOL00000001", PONPort: "0/2", ONUID: 7}}
+		sub, tier := reconcileFixture()
+		sub.Annotations["nano.io/pon-port"] = "0/1"
+		_, err := Reconcile(ctx, d, sub, tier)
+		if err == nil || !strings.Contains(err.Error(), "subscriber expects 0/1") {
+			t.Errorf("Reconcile() error = %v", err)
+		}
+		if len(d.updated)+len(d.created) != 0 {
+			t.Error("no change should be applied")
+		}
+	})
+
+	t.Run("subscriber of another tenant is refused", func(t *testing.T) {
+		d := &reconcileDriver{}
+		sub, tier := reconcileFixture()
+		sub.Tenant = "isp-a"
+		_, err := Reconcile(WithTenant(ctx, "isp-b"), d, sub, tier)
+		if !errors.Is(err, ErrTenantDenied) || len(d.created) != 0 {
+			t.Errorf("Reconcile() error = %v, created = %d", err, len(d.created))
+		}
+		if _, err := Reconcile(WithTenant(ctx, "isp-a"), d, sub, tier); err != nil {
+			t.Errorf("Reconcile() own subscriber error = %v", err)
+		}
+	})
+}
+
+func TestDiffSubscriberSkipsUnreported(t *testing.T) {
+	sub, tier := reconcileFixture()
+	tier.Annotations = map[string]string{"nanoncore.com/line-profile": "line_100M"}
+
+	if changes := DiffSubscriber(&ONUInfo{}, sub, tier); len(changes) != 0 {
+		t.Errorf("unreported fields should not differ: %+v", changes)
+	}
+	changes := DiffSubscriber(&ONUInfo{LineProfile: "line_50M", BandwidthUp: 20}, sub, tier)
+	if len(changes) != 2 {
+		t.Errorf("changes = %+v, want bandwidth_up and line_profile", changes)
+	}
+}
@@ -0,0 +1,179 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrorClass tells whether a failed request may succeed if sent again
+type ErrorClass int
+
+const (
+	// ErrorPermanent errors fail again on retry: rejected commands,
+	// invalid configuration, authentication failures, cancellation
+	ErrorPermanent ErrorClass = iota
+	// ErrorTransient errors may clear on retry: timeouts, dropped
+	// connections, a busy or locked device
+	ErrorTransient
+)
+
+// String returns "transient" or "permanent"
+func (c ErrorClass) String() string {
+	if c == ErrorTransient {
+		return "transient"
+	}
+	return "permanent"
+}
+
+// classifiedError overrides ClassifyError for the error it wraps
+type classifiedError struct {
+	err   error
+	class ErrorClass
+}
+
+func (e *classifiedError) Error() string { return e.err.Error() }
+func (e *classifiedError) Unwrap() error { return e.err }
+
+// Transient marks err as transient. Drivers use it for protocol errors
+// that mean the request was not applied and can be sent again, e.g. a
+// NETCONF lock-denied or a gRPC Unavailable.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, class: ErrorTransient}
+}
+
+// Permanent marks err as permanent, e.g. a write whose outcome is unknown
+// and must not be sent twice
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, class: ErrorPermanent}
+}
+
+// ClassifyError classifies err. Errors marked with Transient or Permanent
+// keep their mark, a HumanError is transient if it is recoverable, and
+// otherwise timeouts and network errors are transient. Cancellation and
+// everything else is permanent.
+func ClassifyError(err error) ErrorClass {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return ErrorPermanent
+	}
+	var c *classifiedError
+	if errors.As(err, &c) {
+		return c.class
+	}
+	var h *HumanError
+	if errors.As(err, &h) {
+		if h.Recoverable {
+			return ErrorTransient
+		}
+		return ErrorPermanent
+	}
+	if IsTimeout(err) {
+		return ErrorTransient
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return ErrorTransient
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"connection reset", "connection refused", "broken pipe"} {
+		if strings.Contains(msg, s) {
+			return ErrorTransient
+		}
+	}
+	return ErrorPermanent
+}
+
+// DefaultRetryPolicy is used by drivers whose EquipmentConfig has no
+// Retry policy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Jitter:         0.2,
+}
+
+// RetryPolicy retries failed device requests with exponential backoff.
+// Only transient errors are retried, and drivers retry only requests that
+// are safe to send twice: reads, and writes the device rejected without
+// applying.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first. 1 or
+	// less disables retries.
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry, doubled after
+	// each further attempt up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Jitter randomizes each wait by up to this fraction, 0 to 1, so
+	// devices failing together do not retry in lockstep
+	Jitter float64
+
+	// Classify decides which errors are retried. Defaults to
+	// ClassifyError.
+	Classify func(error) ErrorClass
+
+	// OnRetry is called before each retry with the attempt that failed
+	OnRetry func(attempt int, err error)
+}
+
+// NoRetry makes a single attempt
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// RetryPolicy returns the retry policy for this equipment: Retry if set,
+// otherwise DefaultRetryPolicy
+func (c *EquipmentConfig) RetryPolicy() *RetryPolicy {
+	if c == nil || c.Retry == nil {
+		return &DefaultRetryPolicy
+	}
+	return c.Retry
+}
+
+// Do calls fn until it succeeds, returns a permanent error, MaxAttempts is
+// reached or ctx is done. fn receives the attempt number, starting at 1.
+// The error of the last attempt is returned.
+func (p *RetryPolicy) Do(ctx context.Context, fn func(attempt int) error) error {
+	classify := p.Classify
+	if classify == nil {
+		classify = ClassifyError
+	}
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || attempt >= p.MaxAttempts || classify(err) != ErrorTransient || ctx.Err() != nil {
+			return err
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, err)
+		}
+
+		wait := backoff
+		if p.Jitter > 0 {
+			wait += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(wait))
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		backoff *= 2
+		if p.MaxBackoff > 0 {
+			backoff = min(backoff, p.MaxBackoff)
+		}
+	}
+}
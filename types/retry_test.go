@@ -0,0 +1,80 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{nil, ErrorPermanent},
+		{errors.New("% Unknown command"), ErrorPermanent},
+		{context.Canceled, ErrorPermanent},
+		{fmt.Errorf("command failed: %w", context.DeadlineExceeded), ErrorTransient},
+		{fmt.Errorf("read: %w", io.EOF), ErrorTransient},
+		{errors.New("write tcp 10.0.0.1:22: connection reset by peer"), ErrorTransient},
+		{&HumanError{Code: ErrCodeConfigLocked, Recoverable: true}, ErrorTransient},
+		{&HumanError{Code: ErrCodeValidationFailed}, ErrorPermanent},
+		{Transient(errors.New("in-use")), ErrorTransient},
+		{Permanent(fmt.Errorf("send: %w", io.EOF)), ErrorPermanent},
+		{fmt.Errorf("rpc: %w", Transient(errors.New("busy"))), ErrorTransient},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("ClassifyError(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	ctx := context.Background()
+	errBusy := Transient(errors.New("busy"))
+	var retried []int
+	p := &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		OnRetry:        func(attempt int, _ error) { retried = append(retried, attempt) },
+	}
+
+	calls := 0
+	err := p.Do(ctx, func(int) error {
+		calls++
+		if calls < 3 {
+			return errBusy
+		}
+		return nil
+	})
+	if err != nil || calls != 3 || len(retried) != 2 {
+		t.Errorf("Do() = %v after %d calls, retried %v", err, calls, retried)
+	}
+
+	calls = 0
+	if err := p.Do(ctx, func(int) error { calls++; return errBusy }); !errors.Is(err, errBusy) || calls != 3 {
+		t.Errorf("Do() = %v after %d calls, want the last error after 3", err, calls)
+	}
+
+	calls = 0
+	errRejected := errors.New("invalid vlan")
+	if err := p.Do(ctx, func(int) error { calls++; return errRejected }); err != errRejected || calls != 1 {
+		t.Errorf("permanent error retried: %v after %d calls", err, calls)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	calls = 0
+	if err := p.Do(cancelled, func(int) error { calls++; return errBusy }); calls != 1 || err == nil {
+		t.Errorf("Do() with a cancelled context made %d calls", calls)
+	}
+
+	calls = 0
+	if err := NoRetry.Do(ctx, func(int) error { calls++; return errBusy }); err == nil || calls != 1 {
+		t.Errorf("NoRetry made %d calls", calls)
+	}
+}
@@ -0,0 +1,49 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// RogueKind is why an ONU is considered rogue
+type RogueKind string
+
+const (
+	// RogueTransmitting is an ONU transmitting outside its assigned
+	// upstream window, e.g. a laser stuck in continuous mode, as detected
+	// by the OLT
+	RogueTransmitting RogueKind = "transmitting"
+
+	// RogueDuplicateSerial is a serial provisioned on more than one PON
+	// port
+	RogueDuplicateSerial RogueKind = "duplicate_serial"
+
+	// RogueProvisionedElsewhere is a serial in autofind on one port while
+	// provisioned on another, e.g. a cloned or moved ONU
+	RogueProvisionedElsewhere RogueKind = "provisioned_elsewhere"
+)
+
+// RogueONU is an ONU behaving as a rogue or alien ONU
+type RogueONU struct {
+	Kind    RogueKind `json:"kind"`
+	PONPort string    `json:"pon_port"`
+
+	// ONUID is the ONU ID, -1 if the OLT could not identify the ONU
+	ONUID int `json:"onu_id"`
+
+	// Serial is the ONU serial, empty if unknown
+	Serial string `json:"serial,omitempty"`
+
+	// Detail is the vendor's description, e.g. the rogue type
+	Detail string `json:"detail,omitempty"`
+
+	DetectedAt time.Time `json:"detected_at,omitempty"`
+}
+
+// RogueONUDetector is implemented by adapters that read the OLT's own
+// rogue ONU detection
+type RogueONUDetector interface {
+	// GetRogueONUs returns the ONUs the OLT detected transmitting outside
+	// their window
+	GetRogueONUs(ctx context.Context) ([]RogueONU, error)
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"context"
+	"fmt"
+)
+
+// ServiceProfileManager defines CRUD operations for ONU service profiles.
+// Implemented by vendors that support service profile management via CLI.
+type ServiceProfileManager interface {
+	ListServiceProfiles(ctx context.Context) ([]*ServiceProfile, error)
+	GetServiceProfile(ctx context.Context, name string) (*ServiceProfile, error)
+	CreateServiceProfile(ctx context.Context, profile *ServiceProfile) error
+	DeleteServiceProfile(ctx context.Context, name string) error
+}
+
+// ServiceProfile defines the ports of an ONU model
+// (Huawei: "ont-srvprofile").
+type ServiceProfile struct {
+	// Name is the profile name (required for create/delete/show).
+	Name string `json:"name"`
+
+	// ID is the profile ID (optional on create, set on read/list).
+	ID *int `json:"id,omitempty"`
+
+	// EthPorts and PotsPorts are the number of Ethernet and POTS ports of
+	// the ONU. Nil means adaptive: the ONU reports its own ports.
+	EthPorts  *int `json:"eth_ports,omitempty"`
+	PotsPorts *int `json:"pots_ports,omitempty"`
+
+	// Committed indicates whether the profile is committed on the OLT.
+	Committed *bool `json:"committed,omitempty"`
+}
+
+// Validate checks that the service profile parameters are valid.
+func (p *ServiceProfile) Validate() error {
+	if p == nil {
+		return fmt.Errorf("profile is required")
+	}
+	if p.Name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+	if p.ID != nil {
+		if err := validateRange("profile id", *p.ID, 0, 8192); err != nil {
+			return err
+		}
+	}
+	if p.EthPorts != nil {
+		if err := validateRange("eth ports", *p.EthPorts, 0, 24); err != nil {
+			return err
+		}
+	}
+	if p.PotsPorts != nil {
+		if err := validateRange("pots ports", *p.PotsPorts, 0, 8); err != nil {
+			return err
+		}
+	}
+	return nil
+}
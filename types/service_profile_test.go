@@ -0,0 +1,20 @@
+package types
+
+import "testing"
+
+func TestServiceProfileValidate(t *testing.T) {
+	eth, pots, tooMany := 4, 2, 99
+	valid := &ServiceProfile{Name: "hg8245", EthPorts: &eth, PotsPorts: &pots}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	for name, p := range map[string]*ServiceProfile{
+		"nil":       nil,
+		"no name":   {},
+		"eth ports": {Name: "x", EthPorts: &tooMany},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
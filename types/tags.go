@@ -0,0 +1,55 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Selector selects devices by tag. Each key must be present; a non-empty
+// value must also match. An empty selector matches every device.
+type Selector map[string]string
+
+// ParseSelector parses a comma-separated selector such as
+// "site=ams,region=eu,core", where a bare key only requires the tag
+func ParseSelector(s string) (Selector, error) {
+	sel := Selector{}
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(term, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "" {
+			return nil, fmt.Errorf("invalid selector term %q", term)
+		}
+		sel[key] = value
+	}
+	return sel, nil
+}
+
+// Matches reports whether tags satisfy the selector
+func (s Selector) Matches(tags map[string]string) bool {
+	for key, want := range s {
+		v, ok := tags[key]
+		if !ok || want != "" && v != want {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the selector as ParseSelector accepts it, keys sorted
+func (s Selector) String() string {
+	terms := make([]string, 0, len(s))
+	for key, value := range s {
+		if value == "" {
+			terms = append(terms, key)
+			continue
+		}
+		terms = append(terms, key+"="+value)
+	}
+	sort.Strings(terms)
+	return strings.Join(terms, ",")
+}
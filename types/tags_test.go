@@ -0,0 +1,35 @@
+package types
+
+import "testing"
+
+func TestSelector(t *testing.T) {
+	sel, err := ParseSelector(" site=ams, core ,region=eu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sel.String(); got != "core,region=eu,site=ams" {
+		t.Errorf("String() = %q", got)
+	}
+
+	tests := []struct {
+		tags map[string]string
+		want bool
+	}{
+		{map[string]string{"site": "ams", "region": "eu", "core": ""}, true},
+		{map[string]string{"site": "ams", "region": "eu", "core": "yes", "ring": "3"}, true},
+		{map[string]string{"site": "fra", "region": "eu", "core": ""}, false},
+		{map[string]string{"site": "ams", "region": "eu"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := sel.Matches(tt.tags); got != tt.want {
+			t.Errorf("Matches(%v) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+	if !(Selector{}).Matches(nil) {
+		t.Error("empty selector should match everything")
+	}
+	if _, err := ParseSelector("=ams"); err == nil {
+		t.Error("ParseSelector should reject a term without key")
+	}
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrTenantDenied is returned when a tenant-scoped caller addresses a
+// device or subscriber of another tenant
+var ErrTenantDenied = errors.New("belongs to another tenant")
+
+type tenantKey struct{}
+
+// WithTenant scopes the operations in ctx to a tenant: only devices and
+// subscribers labeled with that tenant may be touched. Contexts without a
+// tenant are unscoped and reach everything.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant attached with WithTenant
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// CheckTenant returns an error wrapping ErrTenantDenied if ctx is scoped
+// to a tenant other than owner, the tenant of the named resource.
+// Unlabeled resources belong to no tenant and are denied to every scoped
+// caller.
+func CheckTenant(ctx context.Context, kind, name, owner string) error {
+	tenant := TenantFromContext(ctx)
+	if tenant == "" || tenant == owner {
+		return nil
+	}
+	return fmt.Errorf("%s %q %w", kind, name, ErrTenantDenied)
+}
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Transaction groups the steps of a multi-step device change, such as the
+// ONU, service-port and traffic commands of CreateSubscriber. Every step
+// that completes registers a compensating action; if a later step fails,
+// Rollback runs them in reverse order so no partial configuration is left
+// on the device.
+//
+// A Transaction is not safe for concurrent use.
+type Transaction struct {
+	name   string
+	config *EquipmentConfig
+	undo   []compensation
+	closed bool
+}
+
+// compensation undoes one completed step
+type compensation struct {
+	step string
+	fn   func(ctx context.Context) error
+}
+
+// RollbackError is returned when a transaction failed and undoing its
+// completed steps failed too. Both errors match errors.Is and errors.As.
+type RollbackError struct {
+	// Err is the error that caused the rollback
+	Err error
+
+	// RollbackErr holds the failures of the compensating actions
+	RollbackErr error
+}
+
+func (e *RollbackError) Error() string {
+	return fmt.Sprintf("%v (rollback failed: %v)", e.Err, e.RollbackErr)
+}
+
+func (e *RollbackError) Unwrap() []error {
+	return []error{e.Err, e.RollbackErr}
+}
+
+// NewTransaction starts a transaction. config is used for logging and may
+// be nil.
+func NewTransaction(name string, config *EquipmentConfig) *Transaction {
+	return &Transaction{name: name, config: config}
+}
+
+// Step runs do and, if it succeeds, registers undo as its compensating
+// action. undo may be nil for steps that need no cleanup, for example
+// because undoing an earlier step removes their configuration too.
+func (t *Transaction) Step(ctx context.Context, name string, do, undo func(ctx context.Context) error) error {
+	if t.closed {
+		return fmt.Errorf("transaction %s is already finished", t.name)
+	}
+	if err := do(ctx); err != nil {
+		return err
+	}
+	t.Compensate(name, undo)
+	return nil
+}
+
+// Compensate registers undo for a step that was run outside Step, e.g.
+// once a device-assigned ID is known
+func (t *Transaction) Compensate(name string, undo func(ctx context.Context) error) {
+	if undo != nil {
+		t.undo = append(t.undo, compensation{step: name, fn: undo})
+	}
+}
+
+// ExecStep runs commands as one step and registers undoCommands as its
+// compensating action. It returns the command outputs.
+func (t *Transaction) ExecStep(ctx context.Context, cli CLIExecutor, name string, commands, undoCommands []string) ([]string, error) {
+	var outputs []string
+	var undo func(context.Context) error
+	if len(undoCommands) > 0 {
+		undo = func(ctx context.Context) error {
+			_, err := cli.ExecCommands(ctx, undoCommands)
+			return err
+		}
+	}
+	err := t.Step(ctx, name, func(ctx context.Context) error {
+		var err error
+		outputs, err = cli.ExecCommands(ctx, commands)
+		return err
+	}, undo)
+	return outputs, err
+}
+
+// Commit finishes the transaction and discards the compensating actions
+func (t *Transaction) Commit() {
+	t.closed = true
+	t.undo = nil
+}
+
+// Rollback runs the compensating actions of the completed steps in reverse
+// order. It keeps going when one fails and returns all failures. The
+// actions run even if ctx is already cancelled, since a timeout is a common
+// reason to roll back. Rollback after Commit is a no-op.
+func (t *Transaction) Rollback(ctx context.Context) error {
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+
+	ctx = context.WithoutCancel(ctx)
+	var errs []error
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		c := t.undo[i]
+		if err := c.fn(ctx); err != nil {
+			t.config.Log().Warn("transaction rollback step failed",
+				"transaction", t.name, "step", c.step, "error", err)
+			errs = append(errs, fmt.Errorf("undo %s: %w", c.step, err))
+		}
+	}
+	t.undo = nil
+	return errors.Join(errs...)
+}
+
+// RunTransaction runs fn in a new transaction. It commits if fn succeeds
+// and rolls back if fn fails, returning fn's error, or a *RollbackError if
+// the rollback failed too.
+func RunTransaction(ctx context.Context, name string, config *EquipmentConfig, fn func(tx *Transaction) error) error {
+	tx := NewTransaction(name, config)
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return &RollbackError{Err: err, RollbackErr: rbErr}
+		}
+		return err
+	}
+	tx.Commit()
+	return nil
+}
@@ -0,0 +1,136 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTransactionRollbackOrder(t *testing.T) {
+	var calls []string
+	step := func(name string, fail bool) (func(context.Context) error, func(context.Context) error) {
+		return func(context.Context) error {
+				calls = append(calls, "do "+name)
+				if fail {
+					return errors.New(name + " failed")
+				}
+				return nil
+			}, func(context.Context) error {
+				calls = append(calls, "undo "+name)
+				return nil
+			}
+	}
+
+	err := RunTransaction(context.Background(), "test", nil, func(tx *Transaction) error {
+		for _, s := range []struct {
+			name string
+			fail bool
+		}{{"a", false}, {"b", false}, {"c", true}, {"d", false}} {
+			do, undo := step(s.name, s.fail)
+			if err := tx.Step(context.Background(), s.name, do, undo); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "c failed" {
+		t.Fatalf("RunTransaction() error = %v, want c failed", err)
+	}
+
+	want := []string{"do a", "do b", "do c", "undo b", "undo a"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestTransactionCommit(t *testing.T) {
+	undone := false
+	tx := NewTransaction("test", nil)
+	err := tx.Step(context.Background(), "a",
+		func(context.Context) error { return nil },
+		func(context.Context) error { undone = true; return nil })
+	if err != nil {
+		t.Fatalf("Step() error = %v", err)
+	}
+	tx.Commit()
+
+	if err := tx.Rollback(context.Background()); err != nil || undone {
+		t.Errorf("Rollback after Commit: err = %v, undone = %v", err, undone)
+	}
+	if err := tx.Step(context.Background(), "b", func(context.Context) error { return nil }, nil); err == nil {
+		t.Error("Step after Commit should fail")
+	}
+}
+
+func TestTransactionRollbackError(t *testing.T) {
+	errStep := errors.New("step failed")
+	errUndo := errors.New("undo failed")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var undoCtxErr error
+	err := RunTransaction(ctx, "test", nil, func(tx *Transaction) error {
+		tx.Compensate("a", func(ctx context.Context) error {
+			undoCtxErr = ctx.Err()
+			return errUndo
+		})
+		cancel()
+		return errStep
+	})
+
+	var rbErr *RollbackError
+	if !errors.As(err, &rbErr) {
+		t.Fatalf("error = %v, want *RollbackError", err)
+	}
+	if !errors.Is(err, errStep) || !errors.Is(err, errUndo) {
+		t.Errorf("error %v should wrap both the step and undo errors", err)
+	}
+	if undoCtxErr != nil {
+		t.Errorf("rollback ran with cancelled context: %v", undoCtxErr)
+	}
+}
+
+func TestTransactionExecStep(t *testing.T) {
+	cli := &recordingCLI{fail: "onu 1 tcont 1"}
+	err := RunTransaction(context.Background(), "test", nil, func(tx *Transaction) error {
+		if _, err := tx.ExecStep(context.Background(), cli, "add", []string{"onu add 1"}, []string{"no onu 1"}); err != nil {
+			return err
+		}
+		_, err := tx.ExecStep(context.Background(), cli, "configure", []string{"onu 1 tcont 1"}, nil)
+		return err
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	want := []string{"onu add 1", "onu 1 tcont 1", "no onu 1"}
+	if !reflect.DeepEqual(cli.commands, want) {
+		t.Errorf("commands = %v, want %v", cli.commands, want)
+	}
+}
+
+// recordingCLI records commands and fails on one of them
+type recordingCLI struct {
+	fail     string
+	commands []string
+}
+
+func (c *recordingCLI) ExecCommand(_ context.Context, command string) (string, error) {
+	c.commands = append(c.commands, command)
+	if command == c.fail {
+		return "", errors.New("rejected")
+	}
+	return "", nil
+}
+
+func (c *recordingCLI) ExecCommands(ctx context.Context, commands []string) ([]string, error) {
+	var outputs []string
+	for _, cmd := range commands {
+		out, err := c.ExecCommand(ctx, cmd)
+		if err != nil {
+			return outputs, err
+		}
+		outputs = append(outputs, out)
+	}
+	return outputs, nil
+}
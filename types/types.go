@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"time"
 
 	"github.com/nanoncore/nano-southbound/model"
@@ -77,15 +78,38 @@ type EquipmentConfig struct {
 	// Password for authentication
 	Password string
 
+	// Credentials resolves Username, Password and SNMPCommunity on Connect,
+	// e.g. from Vault, instead of keeping them in the config. Optional.
+	Credentials CredentialsProvider
+
 	// TLSEnabled indicates if TLS should be used
 	TLSEnabled bool
 
 	// TLSSkipVerify skips TLS certificate verification (insecure, for testing)
 	TLSSkipVerify bool
 
+	// TLSCertFile is the PEM client certificate presented for mutual TLS.
+	// Must be set together with TLSKeyFile.
+	TLSCertFile string
+
+	// TLSKeyFile is the PEM private key for TLSCertFile
+	TLSKeyFile string
+
+	// TLSCAFile is a PEM CA bundle used to verify the server certificate.
+	// If empty, the system root CAs are used. The gNMI driver reloads it,
+	// like the client key pair, when the file changes.
+	TLSCAFile string
+
+	// TLSServerName overrides the server name used for certificate verification
+	TLSServerName string
+
 	// Timeout for operations
 	Timeout time.Duration
 
+	// Retry is the policy for retrying failed requests. If nil,
+	// DefaultRetryPolicy is used; NoRetry disables retries.
+	Retry *RetryPolicy
+
 	// Metadata contains vendor-specific configuration
 	Metadata map[string]string
 
@@ -99,10 +123,44 @@ type EquipmentConfig struct {
 	// SNMPVersion is the SNMP version: "1", "2c" (default), or "3"
 	SNMPVersion string
 
+	// Model is the device model, e.g. "MA5800-X15", for adapters whose
+	// commands differ by model. Set it with WithModel.
+	Model string
+
+	// PONPorts lists the PON ports of the device, e.g. "0/1", for adapters
+	// that cannot discover them. Set it with WithPONPortList.
+	PONPorts []string
+
+	// CLIFallback has adapters whose base driver is SNMP open a CLI
+	// session too, for the operations SNMP cannot do. Set it with
+	// WithCLIFallback.
+	CLIFallback bool
+
 	// PasswordAuthOnly disables keyboard-interactive SSH auth.
 	// Some devices (e.g., V-SOL OLTs) have non-compliant SSH implementations
 	// that fail when keyboard-interactive is offered.
 	PasswordAuthOnly bool
+
+	// Tenant owns the device. Callers scoped with WithTenant only reach
+	// devices of their tenant.
+	Tenant string
+
+	// Tags are free-form labels used to select devices (e.g. "site",
+	// "region"). A tag without a value is stored with an empty value.
+	Tags map[string]string
+
+	// Logger receives driver and adapter logs. Commands and responses are
+	// logged at debug level, suppressed errors at warn level. If nil,
+	// slog.Default() is used.
+	Logger *slog.Logger
+
+	// Audit receives a record of every configuration change sent to the
+	// device. If nil, changes are not audited.
+	Audit AuditSink
+
+	// CommandPolicy validates configuration changes before they are sent.
+	// If nil, every change is allowed.
+	CommandPolicy *CommandPolicy
 }
 
 // Driver is the interface that all southbound drivers must implement
@@ -142,6 +200,15 @@ type Driver interface {
 	HealthCheck(ctx context.Context) error
 }
 
+// DriverFactory creates the driver for a device, e.g.
+//
+//	func(c *types.EquipmentConfig) (types.Driver, error) {
+//		return southbound.NewDriver(c.Vendor, c.Protocol, c)
+//	}
+//
+// Registry.DriverFor can be used to share the adapters of a registry.
+type DriverFactory func(config *EquipmentConfig) (Driver, error)
+
 // CLIExecutor is an optional interface for drivers that support CLI execution
 // Vendor adapters can use this to send vendor-specific commands
 type CLIExecutor interface {
@@ -70,6 +70,10 @@ type CreateVLANRequest struct {
 
 	// Type is the VLAN type (default: "smart")
 	Type string `json:"type,omitempty"`
+
+	// UplinkPort is the upstream port to add the VLAN to (optional,
+	// frame/slot/port)
+	UplinkPort string `json:"uplink_port,omitempty"`
 }
 
 // AddServicePortRequest contains parameters for adding a service port.
@@ -103,4 +107,5 @@ const (
 	ErrCodeInvalidVLANID       = "INVALID_VLAN_ID"
 	ErrCodeVLANHasServicePorts = "VLAN_HAS_SERVICE_PORTS"
 	ErrCodeServicePortExists   = "SERVICE_PORT_EXISTS"
+	ErrCodeServicePortNotFound = "SERVICE_PORT_NOT_FOUND"
 )
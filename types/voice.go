@@ -0,0 +1,16 @@
+package types
+
+import (
+	"context"
+
+	"github.com/nanoncore/nano-southbound/model"
+)
+
+// ONUVoiceManager is implemented by adapters that provision the SIP voice
+// service of triple-play ONUs. CreateSubscriber of these adapters also
+// provisions SubscriberSpec.Voice.
+type ONUVoiceManager interface {
+	// SetONUVoice configures the voice VLAN, SIP server and the SIP
+	// account and state of each POTS port of an ONU
+	SetONUVoice(ctx context.Context, ponPort string, onuID int, voice *model.VoiceService) error
+}
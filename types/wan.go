@@ -0,0 +1,320 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/model"
+)
+
+// WANMode is how the WAN connection of a routed ONU gets its address
+type WANMode string
+
+const (
+	WANModePPPoE WANMode = "pppoe"
+	WANModeDHCP  WANMode = "dhcp"
+)
+
+// ONUWANConfig is the WAN, Wi-Fi and LAN configuration of a routed (HGU)
+// ONU, pushed to the ONU by the OLT
+type ONUWANConfig struct {
+	Mode WANMode `json:"mode"`
+
+	// VLAN is the WAN VLAN and Priority its 802.1p priority
+	VLAN     int `json:"vlan"`
+	Priority int `json:"priority,omitempty"`
+
+	// Username and Password are the PPPoE credentials. Adapters never read
+	// the password back.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// LANPorts are the Ethernet ports routed to the WAN, numbered from 1
+	LANPorts []int `json:"lan_ports,omitempty"`
+
+	// WiFi is the SSID and key of the first radio. Nil leaves Wi-Fi
+	// unchanged.
+	WiFi *WifiConfig `json:"wifi,omitempty"`
+}
+
+// Validate checks that the WAN configuration can be sent to an ONU
+func (c *ONUWANConfig) Validate() error {
+	if c == nil {
+		return fmt.Errorf("WAN config is required")
+	}
+	switch c.Mode {
+	case WANModePPPoE:
+		if c.Username == "" || c.Password == "" {
+			return fmt.Errorf("PPPoE username and password are required")
+		}
+		if err := validateCLIValue("PPPoE username", c.Username, true); err != nil {
+			return err
+		}
+		if err := validateCLIValue("PPPoE password", c.Password, true); err != nil {
+			return err
+		}
+	case WANModeDHCP:
+	default:
+		return fmt.Errorf("unsupported WAN mode %q", c.Mode)
+	}
+	if err := validateRange("WAN vlan", c.VLAN, 1, 4094); err != nil {
+		return err
+	}
+	if err := validateRange("WAN priority", c.Priority, 0, 7); err != nil {
+		return err
+	}
+	for _, p := range c.LANPorts {
+		if err := validateRange("LAN port", p, 1, 8); err != nil {
+			return err
+		}
+	}
+	if w := c.WiFi; w != nil && w.Enabled {
+		if len(w.SSID) < 1 || len(w.SSID) > 32 {
+			return fmt.Errorf("SSID must be 1 to 32 characters")
+		}
+		if len(w.Password) < 8 || len(w.Password) > 63 {
+			return fmt.Errorf("WPA2 key must be 8 to 63 characters")
+		}
+		if err := validateCLIValue("SSID", w.SSID, false); err != nil {
+			return err
+		}
+		if err := validateCLIValue("WPA2 key", w.Password, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SubscriberWANConfig returns the WAN configuration CreateSubscriber pushes
+// to the ONU of a router-mode subscriber, or nil if the subscriber has no
+// Spec.WAN. A PPPoE WAN dials with the subscriber's Username and Password.
+func SubscriberWANConfig(subscriber *model.Subscriber) (*ONUWANConfig, error) {
+	wan := subscriber.Spec.WAN
+	if wan == nil {
+		return nil, nil
+	}
+	if err := wan.Validate(); err != nil {
+		return nil, err
+	}
+	cfg := &ONUWANConfig{
+		Mode:     WANMode(wan.GetMode()),
+		VLAN:     wan.VLAN,
+		Priority: wan.Priority,
+		LANPorts: wan.LANPorts,
+	}
+	if cfg.VLAN == 0 {
+		cfg.VLAN = subscriber.Spec.VLAN
+	}
+	if cfg.Mode == WANModePPPoE {
+		cfg.Username = subscriber.Spec.Username
+		cfg.Password = subscriber.Spec.Password
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// validateCLIValue rejects characters that cannot be passed in a quoted
+// CLI argument, and spaces if the argument is not quoted
+func validateCLIValue(field, value string, noSpaces bool) error {
+	if strings.ContainsAny(value, "\"\r\n\t") || (noSpaces && strings.Contains(value, " ")) {
+		return fmt.Errorf("%s contains unsupported characters", field)
+	}
+	return nil
+}
+
+// ONUWANManager is implemented by adapters that configure the WAN, Wi-Fi
+// and LAN of routed ONUs over OMCI
+type ONUWANManager interface {
+	// SetONUWANConfig replaces the WAN connection of an ONU, and sets its
+	// Wi-Fi and LAN port bindings
+	SetONUWANConfig(ctx context.Context, ponPort string, onuID int, cfg *ONUWANConfig) error
+
+	// GetONUWANConfig reads the WAN connection of an ONU. The password and
+	// Wi-Fi configuration are not returned.
+	GetONUWANConfig(ctx context.Context, ponPort string, onuID int) (*ONUWANConfig, error)
+}
+
+// DefaultONUDHCPLeaseTime is the lease time used when
+// ONULANConfig.LeaseTime is not set
+const DefaultONUDHCPLeaseTime = 24 * time.Hour
+
+// ONULANConfig is the LAN side of a routed ONU: its own address, which LAN
+// hosts use as gateway and to reach its management interface, and its
+// DHCP server. Converting a bridged ONU to router mode takes both this and
+// an ONUWANConfig.
+type ONULANConfig struct {
+	// IPAddress and Netmask are the ONU's IPv4 LAN address, e.g.
+	// 192.168.1.1 and 255.255.255.0
+	IPAddress string `json:"ip_address"`
+	Netmask   string `json:"netmask"`
+
+	// DHCPServer enables the ONU's DHCP server on the LAN
+	DHCPServer bool `json:"dhcp_server"`
+
+	// DHCPStart and DHCPEnd are the first and last address of the DHCP
+	// pool, within the LAN subnet
+	DHCPStart string `json:"dhcp_start,omitempty"`
+	DHCPEnd   string `json:"dhcp_end,omitempty"`
+
+	// LeaseTime is the DHCP lease time. Defaults to
+	// DefaultONUDHCPLeaseTime.
+	LeaseTime time.Duration `json:"lease_time,omitempty"`
+}
+
+// Lease returns the DHCP lease time, defaulting to DefaultONUDHCPLeaseTime
+func (c *ONULANConfig) Lease() time.Duration {
+	if c.LeaseTime <= 0 {
+		return DefaultONUDHCPLeaseTime
+	}
+	return c.LeaseTime
+}
+
+// Validate checks that the LAN address is a host address of its subnet and
+// that the DHCP pool lies within the subnet without including it
+func (c *ONULANConfig) Validate() error {
+	if c == nil {
+		return fmt.Errorf("LAN config is required")
+	}
+	addr, err := netip.ParseAddr(c.IPAddress)
+	if err != nil || !addr.Is4() {
+		return fmt.Errorf("invalid LAN IP address %q", c.IPAddress)
+	}
+	mask := net.ParseIP(c.Netmask).To4()
+	if mask == nil {
+		return fmt.Errorf("invalid LAN netmask %q", c.Netmask)
+	}
+	ones, bits := net.IPMask(mask).Size()
+	if bits == 0 || ones == 0 || ones > 30 {
+		return fmt.Errorf("invalid LAN netmask %q", c.Netmask)
+	}
+	subnet := netip.PrefixFrom(addr, ones).Masked()
+	if addr == subnet.Addr() || isBroadcast(addr, subnet) {
+		return fmt.Errorf("LAN IP address %s is not a host address of %s", addr, subnet)
+	}
+	if !c.DHCPServer {
+		return nil
+	}
+
+	start, err := netip.ParseAddr(c.DHCPStart)
+	if err != nil || !subnet.Contains(start) {
+		return fmt.Errorf("DHCP start %q is not in %s", c.DHCPStart, subnet)
+	}
+	end, err := netip.ParseAddr(c.DHCPEnd)
+	if err != nil || !subnet.Contains(end) {
+		return fmt.Errorf("DHCP end %q is not in %s", c.DHCPEnd, subnet)
+	}
+	switch {
+	case end.Less(start):
+		return fmt.Errorf("DHCP end %s is before start %s", end, start)
+	case start == subnet.Addr() || isBroadcast(end, subnet):
+		return fmt.Errorf("DHCP pool %s-%s includes the network or broadcast address", start, end)
+	case !addr.Less(start) && !end.Less(addr):
+		return fmt.Errorf("DHCP pool %s-%s includes the LAN IP address", start, end)
+	}
+	return nil
+}
+
+// isBroadcast reports whether addr is the broadcast address of subnet
+func isBroadcast(addr netip.Addr, subnet netip.Prefix) bool {
+	next := addr.Next()
+	return !next.IsValid() || !subnet.Contains(next)
+}
+
+// ONULANManager is implemented by adapters that configure the LAN side of
+// routed ONUs
+type ONULANManager interface {
+	// SetONULANConfig sets the LAN address and DHCP server of an ONU
+	SetONULANConfig(ctx context.Context, ponPort string, onuID int, cfg *ONULANConfig) error
+}
+
+// ONUMode is the operating mode of an ONU
+type ONUMode string
+
+const (
+	// ONUModeBridge bridges the ONU's Ethernet ports to the service VLAN
+	// (SFU); PPPoE runs on the CPE behind the ONU
+	ONUModeBridge ONUMode = "bridge"
+
+	// ONUModeRouter routes the ONU's LAN ports through a WAN on the ONU
+	// (HGU)
+	ONUModeRouter ONUMode = "router"
+)
+
+// ONUModeConfig is the mode to switch an ONU to and the services the ONU
+// needs in that mode
+type ONUModeConfig struct {
+	Mode ONUMode `json:"mode"`
+
+	// WAN is the WAN connection of a router, with the LAN ports routed to
+	// it. Required in router mode.
+	WAN *ONUWANConfig `json:"wan,omitempty"`
+
+	// LAN is the LAN address and DHCP server of a router. Nil leaves them
+	// unchanged.
+	LAN *ONULANConfig `json:"lan,omitempty"`
+
+	// VLAN is the service VLAN the Ethernet ports of a bridge untag.
+	// Required in bridge mode.
+	VLAN int `json:"vlan,omitempty"`
+
+	// EthPorts are the Ethernet ports of a bridge, numbered from 1
+	// (default: port 1)
+	EthPorts []int `json:"eth_ports,omitempty"`
+}
+
+// BridgePorts returns the Ethernet ports of a bridge, defaulting to port 1
+func (c *ONUModeConfig) BridgePorts() []int {
+	if len(c.EthPorts) == 0 {
+		return []int{1}
+	}
+	return c.EthPorts
+}
+
+// Validate checks that the services of the mode are complete
+func (c *ONUModeConfig) Validate() error {
+	if c == nil {
+		return fmt.Errorf("ONU mode config is required")
+	}
+	switch c.Mode {
+	case ONUModeRouter:
+		if c.WAN == nil {
+			return fmt.Errorf("router mode requires a WAN config")
+		}
+		if err := c.WAN.Validate(); err != nil {
+			return err
+		}
+		if c.LAN != nil {
+			return c.LAN.Validate()
+		}
+	case ONUModeBridge:
+		if c.WAN != nil || c.LAN != nil {
+			return fmt.Errorf("bridge mode takes no WAN or LAN config")
+		}
+		if err := validateRange("bridge vlan", c.VLAN, 1, 4094); err != nil {
+			return err
+		}
+		for _, p := range c.EthPorts {
+			if err := validateRange("Ethernet port", p, 1, 8); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported ONU mode %q", c.Mode)
+	}
+	return nil
+}
+
+// ONUModeSwitcher is implemented by adapters that switch ONUs between
+// bridge and router mode
+type ONUModeSwitcher interface {
+	// SetONUMode switches an ONU to cfg.Mode and reconfigures its services
+	// for it: a router gets its WAN, routed LAN ports and LAN; a bridge
+	// loses its WAN and its Ethernet ports untag the service VLAN.
+	SetONUMode(ctx context.Context, ponPort string, onuID int, cfg *ONUModeConfig) error
+}
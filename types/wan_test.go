@@ -0,0 +1,126 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/model"
+)
+
+func TestONUWANConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *ONUWANConfig
+		wantErr bool
+	}{
+		{"dhcp", &ONUWANConfig{Mode: WANModeDHCP, VLAN: 100, LANPorts: []int{1, 2}}, false},
+		{"pppoe", &ONUWANConfig{Mode: WANModePPPoE, VLAN: 100, Username: "user@isp", Password: "secret"}, false},
+		{"nil", nil, true},
+		{"unknown mode", &ONUWANConfig{Mode: "static", VLAN: 100}, true},
+		{"pppoe without credentials", &ONUWANConfig{Mode: WANModePPPoE, VLAN: 100}, true},
+		{"password with space", &ONUWANConfig{Mode: WANModePPPoE, VLAN: 100, Username: "u", Password: "a b"}, true},
+		{"vlan out of range", &ONUWANConfig{Mode: WANModeDHCP, VLAN: 4095}, true},
+		{"lan port out of range", &ONUWANConfig{Mode: WANModeDHCP, VLAN: 100, LANPorts: []int{0}}, true},
+		{"wifi", &ONUWANConfig{Mode: WANModeDHCP, VLAN: 100, WiFi: &WifiConfig{SSID: "Home Net", Password: "password1", Enabled: true}}, false},
+		{"wifi short key", &ONUWANConfig{Mode: WANModeDHCP, VLAN: 100, WiFi: &WifiConfig{SSID: "home", Password: "short", Enabled: true}}, true},
+		{"wifi quote in ssid", &ONUWANConfig{Mode: WANModeDHCP, VLAN: 100, WiFi: &WifiConfig{SSID: `a"b`, Password: "password1", Enabled: true}}, true},
+		{"wifi disabled", &ONUWANConfig{Mode: WANModeDHCP, VLAN: 100, WiFi: &WifiConfig{}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubscriberWANConfig(t *testing.T) {
+	sub := &model.Subscriber{Spec: model.SubscriberSpec{VLAN: 100, Username: "user@isp", Password: "secret"}}
+	if cfg, err := SubscriberWANConfig(sub); cfg != nil || err != nil {
+		t.Errorf("SubscriberWANConfig(bridged) = %+v, %v, want nil", cfg, err)
+	}
+
+	sub.Spec.WAN = &model.WANService{LANPorts: []int{1, 2}}
+	cfg, err := SubscriberWANConfig(sub)
+	if err != nil || cfg.Mode != WANModePPPoE || cfg.VLAN != 100 || cfg.Username != "user@isp" || cfg.Password != "secret" || len(cfg.LANPorts) != 2 {
+		t.Errorf("SubscriberWANConfig() = %+v, %v", cfg, err)
+	}
+
+	sub.Spec.WAN = &model.WANService{Mode: model.WANModeDHCP, VLAN: 200}
+	cfg, err = SubscriberWANConfig(sub)
+	if err != nil || cfg.Mode != WANModeDHCP || cfg.VLAN != 200 || cfg.Username != "" {
+		t.Errorf("SubscriberWANConfig(dhcp) = %+v, %v", cfg, err)
+	}
+
+	sub.Spec.WAN = &model.WANService{}
+	sub.Spec.Password = ""
+	if _, err := SubscriberWANConfig(sub); err == nil {
+		t.Error("SubscriberWANConfig() of PPPoE without a password succeeded")
+	}
+}
+
+func TestONULANConfigValidate(t *testing.T) {
+	valid := func() *ONULANConfig {
+		return &ONULANConfig{IPAddress: "192.168.1.1", Netmask: "255.255.255.0", DHCPServer: true,
+			DHCPStart: "192.168.1.100", DHCPEnd: "192.168.1.200"}
+	}
+	tests := []struct {
+		name    string
+		modify  func(c *ONULANConfig)
+		wantErr bool
+	}{
+		{"valid", func(c *ONULANConfig) {}, false},
+		{"no dhcp", func(c *ONULANConfig) { c.DHCPServer, c.DHCPStart, c.DHCPEnd = false, "", "" }, false},
+		{"invalid address", func(c *ONULANConfig) { c.IPAddress = "192.168.1" }, true},
+		{"ipv6 address", func(c *ONULANConfig) { c.IPAddress = "fe80::1" }, true},
+		{"non-contiguous mask", func(c *ONULANConfig) { c.Netmask = "255.0.255.0" }, true},
+		{"network address", func(c *ONULANConfig) { c.IPAddress = "192.168.1.0" }, true},
+		{"broadcast address", func(c *ONULANConfig) { c.IPAddress = "192.168.1.255" }, true},
+		{"pool outside subnet", func(c *ONULANConfig) { c.DHCPEnd = "192.168.2.10" }, true},
+		{"pool reversed", func(c *ONULANConfig) { c.DHCPStart, c.DHCPEnd = c.DHCPEnd, c.DHCPStart }, true},
+		{"pool includes lan address", func(c *ONULANConfig) { c.DHCPStart = "192.168.1.1" }, true},
+		{"pool includes broadcast", func(c *ONULANConfig) { c.DHCPEnd = "192.168.1.255" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.modify(c)
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if valid().Lease() != DefaultONUDHCPLeaseTime {
+		t.Errorf("Lease() = %v", valid().Lease())
+	}
+}
+
+func TestONUModeConfigValidate(t *testing.T) {
+	wan := &ONUWANConfig{Mode: WANModeDHCP, VLAN: 100}
+	tests := []struct {
+		name    string
+		cfg     *ONUModeConfig
+		wantErr bool
+	}{
+		{"router", &ONUModeConfig{Mode: ONUModeRouter, WAN: wan}, false},
+		{"router with LAN", &ONUModeConfig{Mode: ONUModeRouter, WAN: wan, LAN: &ONULANConfig{IPAddress: "192.168.1.1", Netmask: "255.255.255.0"}}, false},
+		{"router without WAN", &ONUModeConfig{Mode: ONUModeRouter}, true},
+		{"router with bad LAN", &ONUModeConfig{Mode: ONUModeRouter, WAN: wan, LAN: &ONULANConfig{IPAddress: "192.168.1.0", Netmask: "255.255.255.0"}}, true},
+		{"bridge", &ONUModeConfig{Mode: ONUModeBridge, VLAN: 100, EthPorts: []int{1, 2}}, false},
+		{"bridge without VLAN", &ONUModeConfig{Mode: ONUModeBridge}, true},
+		{"bridge with WAN", &ONUModeConfig{Mode: ONUModeBridge, VLAN: 100, WAN: wan}, true},
+		{"bridge port out of range", &ONUModeConfig{Mode: ONUModeBridge, VLAN: 100, EthPorts: []int{9}}, true},
+		{"unknown mode", &ONUModeConfig{Mode: "hybrid"}, true},
+		{"nil", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if p := (&ONUModeConfig{}).BridgePorts(); len(p) != 1 || p[0] != 1 {
+		t.Errorf("BridgePorts() = %v, want [1]", p)
+	}
+}
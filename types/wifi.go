@@ -10,10 +10,11 @@ type WifiManager interface {
 	// GetWifiConfig reads ONU Wi-Fi configuration when supported.
 	GetWifiConfig(ctx context.Context, target WifiTarget) (*WifiActionResult, error)
 
-	// SetWifiConfig applies SSID/password/enabled on an ONU.
+	// SetWifiConfig applies SSID/password/enabled/channel on an ONU.
 	SetWifiConfig(ctx context.Context, target WifiTarget, cfg WifiConfig) (*WifiActionResult, error)
 
-	// SetWifiEnabled toggles ONU Wi-Fi state.
+	// SetWifiEnabled toggles ONU Wi-Fi state, of the radio selected by the
+	// target on dual-band ONUs.
 	SetWifiEnabled(ctx context.Context, target WifiTarget, enabled bool) (*WifiActionResult, error)
 
 	// ProbeWifiCapabilities checks whether an ONU supports OMCI Wi-Fi management
@@ -30,13 +31,29 @@ type WifiTarget struct {
 	// Optional resolved coordinates when already known.
 	PONPort string `json:"ponPort,omitempty"`
 	ONUID   int    `json:"onuId,omitempty"`
+
+	// Radio selects the radio of a dual-band ONU. Empty is the ONU's
+	// first (or only) radio.
+	Radio WifiRadio `json:"radio,omitempty"`
 }
 
+// WifiRadio identifies a radio of a dual-band ONU.
+type WifiRadio string
+
+const (
+	WifiRadio24GHz WifiRadio = "2.4GHz"
+	WifiRadio5GHz  WifiRadio = "5GHz"
+)
+
 // WifiConfig is the desired ONU Wi-Fi config.
 type WifiConfig struct {
 	SSID     string `json:"ssid,omitempty"`
 	Password string `json:"password,omitempty"`
 	Enabled  bool   `json:"enabled"`
+
+	// Channel is the radio channel, a number or "auto". Empty keeps the
+	// adapter default.
+	Channel string `json:"channel,omitempty"`
 }
 
 // WifiObservedSource defines the verification source for observed config.
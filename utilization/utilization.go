@@ -0,0 +1,165 @@
+// Package utilization reports how full the PON ports of an OLT are: the
+// ONUs of each port against its split ratio, and the bandwidth committed
+// to them against the port's line rate. Reports are built from the OLT
+// status and ONU list of a DriverV2, per port and for the whole OLT, to
+// plan splitter changes and port upgrades.
+package utilization
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Capacity is the usable line rate of a PON port
+type Capacity struct {
+	DownMbps int `json:"down_mbps"`
+	UpMbps   int `json:"up_mbps"`
+}
+
+// Line rates of common PON technologies
+var (
+	GPON     = Capacity{DownMbps: 2488, UpMbps: 1244}
+	XGPON    = Capacity{DownMbps: 9953, UpMbps: 2488}
+	XGSPON   = Capacity{DownMbps: 9953, UpMbps: 9953}
+	EPON     = Capacity{DownMbps: 1000, UpMbps: 1000}
+	TenGEPON = Capacity{DownMbps: 10000, UpMbps: 10000}
+)
+
+// Config configures a report
+type Config struct {
+	// Capacity is the line rate of the ports. Defaults to GPON.
+	Capacity Capacity
+
+	// Ports overrides Capacity per port, e.g. for XGS-PON cards in a GPON
+	// OLT
+	Ports map[string]Capacity
+}
+
+func (c Config) capacityOf(port string) Capacity {
+	if capacity, ok := c.Ports[port]; ok {
+		return capacity
+	}
+	if c.Capacity == (Capacity{}) {
+		return GPON
+	}
+	return c.Capacity
+}
+
+// Port is the utilization of one PON port
+type Port struct {
+	Port       string `json:"port"`
+	OperState  string `json:"oper_state,omitempty"`
+	ONUs       int    `json:"onus"`
+	OnlineONUs int    `json:"online_onus"`
+
+	// MaxONUs is the split ratio of the port, 0 if the adapter does not
+	// report it
+	MaxONUs int `json:"max_onus"`
+
+	// Fill is ONUs / MaxONUs, 0 without MaxONUs
+	Fill float64 `json:"fill"`
+
+	// CommittedDownMbps and CommittedUpMbps sum the bandwidth limits of
+	// the port's ONUs. ONUs the adapter reports no limit for are counted
+	// in UnknownBandwidth instead.
+	CommittedDownMbps int `json:"committed_down_mbps"`
+	CommittedUpMbps   int `json:"committed_up_mbps"`
+	UnknownBandwidth  int `json:"unknown_bandwidth,omitempty"`
+
+	Capacity Capacity `json:"capacity"`
+
+	// OversubscriptionDown and OversubscriptionUp are committed over
+	// available bandwidth; above 1 the port is oversold
+	OversubscriptionDown float64 `json:"oversubscription_down"`
+	OversubscriptionUp   float64 `json:"oversubscription_up"`
+}
+
+// Report is the utilization of an OLT and its PON ports
+type Report struct {
+	Ports []Port `json:"ports"`
+
+	ONUs       int     `json:"onus"`
+	OnlineONUs int     `json:"online_onus"`
+	MaxONUs    int     `json:"max_onus"`
+	Fill       float64 `json:"fill"`
+
+	CommittedDownMbps int      `json:"committed_down_mbps"`
+	CommittedUpMbps   int      `json:"committed_up_mbps"`
+	Capacity          Capacity `json:"capacity"`
+
+	OversubscriptionDown float64 `json:"oversubscription_down"`
+	OversubscriptionUp   float64 `json:"oversubscription_up"`
+}
+
+// Collect reads the OLT status and ONU list of driver and builds its
+// report
+func Collect(ctx context.Context, driver types.DriverV2, config Config) (*Report, error) {
+	status, err := driver.GetOLTStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OLT status: %w", err)
+	}
+	onus, err := driver.GetONUList(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ONUs: %w", err)
+	}
+	return Build(status.PONPorts, onus, config), nil
+}
+
+// Build computes the report of ports and onus. ONUs are counted from onus
+// rather than PONPortStatus.ONUCount, which not every adapter fills; a
+// port only known from onus is reported without MaxONUs.
+func Build(ports []types.PONPortStatus, onus []types.ONUInfo, config Config) *Report {
+	byPort := make(map[string]*Port, len(ports))
+	for _, p := range ports {
+		byPort[p.Port] = &Port{Port: p.Port, OperState: p.OperState, MaxONUs: p.MaxONUs}
+	}
+	for _, onu := range onus {
+		p := byPort[onu.PONPort]
+		if p == nil {
+			p = &Port{Port: onu.PONPort}
+			byPort[onu.PONPort] = p
+		}
+		p.ONUs++
+		if onu.IsOnline {
+			p.OnlineONUs++
+		}
+		if onu.BandwidthDown == 0 && onu.BandwidthUp == 0 {
+			p.UnknownBandwidth++
+			continue
+		}
+		p.CommittedDownMbps += onu.BandwidthDown
+		p.CommittedUpMbps += onu.BandwidthUp
+	}
+
+	report := &Report{Ports: make([]Port, 0, len(byPort))}
+	for _, p := range byPort {
+		p.Capacity = config.capacityOf(p.Port)
+		p.Fill = ratio(p.ONUs, p.MaxONUs)
+		p.OversubscriptionDown = ratio(p.CommittedDownMbps, p.Capacity.DownMbps)
+		p.OversubscriptionUp = ratio(p.CommittedUpMbps, p.Capacity.UpMbps)
+
+		report.ONUs += p.ONUs
+		report.OnlineONUs += p.OnlineONUs
+		report.MaxONUs += p.MaxONUs
+		report.CommittedDownMbps += p.CommittedDownMbps
+		report.CommittedUpMbps += p.CommittedUpMbps
+		report.Capacity.DownMbps += p.Capacity.DownMbps
+		report.Capacity.UpMbps += p.Capacity.UpMbps
+		report.Ports = append(report.Ports, *p)
+	}
+	sort.Slice(report.Ports, func(i, j int) bool { return report.Ports[i].Port < report.Ports[j].Port })
+	report.Fill = ratio(report.ONUs, report.MaxONUs)
+	report.OversubscriptionDown = ratio(report.CommittedDownMbps, report.Capacity.DownMbps)
+	report.OversubscriptionUp = ratio(report.CommittedUpMbps, report.Capacity.UpMbps)
+	return report
+}
+
+func ratio(n, d int) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
@@ -0,0 +1,67 @@
+package utilization
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestBuild(t *testing.T) {
+	ports := []types.PONPortStatus{
+		{Port: "0/1", OperState: "up", MaxONUs: 64},
+		{Port: "0/2", OperState: "up", MaxONUs: 128},
+	}
+	onus := []types.ONUInfo{
+		{PONPort: "0/1", ONUID: 1, IsOnline: true, BandwidthDown: 1000, BandwidthUp: 500},
+		{PONPort: "0/1", ONUID: 2, IsOnline: true, BandwidthDown: 1000, BandwidthUp: 500},
+		{PONPort: "0/1", ONUID: 3, BandwidthDown: 1000, BandwidthUp: 500},
+		{PONPort: "0/1", ONUID: 4},
+		{PONPort: "0/2", ONUID: 1, IsOnline: true, BandwidthDown: 5000, BandwidthUp: 5000},
+	}
+	report := Build(ports, onus, Config{Ports: map[string]Capacity{"0/2": XGSPON}})
+
+	if len(report.Ports) != 2 {
+		t.Fatalf("Ports = %+v", report.Ports)
+	}
+	p := report.Ports[0]
+	if p.Port != "0/1" || p.ONUs != 4 || p.OnlineONUs != 2 || p.Fill != 4.0/64 || p.UnknownBandwidth != 1 {
+		t.Errorf("port 0/1 = %+v", p)
+	}
+	if p.Capacity != GPON || p.CommittedDownMbps != 3000 || p.OversubscriptionDown != 3000.0/2488 {
+		t.Errorf("port 0/1 bandwidth = %+v", p)
+	}
+	if p := report.Ports[1]; p.Capacity != XGSPON || p.OversubscriptionUp != 5000.0/9953 {
+		t.Errorf("port 0/2 = %+v", p)
+	}
+	if report.ONUs != 5 || report.MaxONUs != 192 || report.CommittedDownMbps != 8000 || report.Capacity.DownMbps != 2488+9953 {
+		t.Errorf("report = %+v", report)
+	}
+
+	// A port only known from its ONUs has no fill
+	report = Build(nil, onus[:1], Config{Capacity: EPON})
+	if p := report.Ports[0]; p.MaxONUs != 0 || p.Fill != 0 || p.Capacity != EPON {
+		t.Errorf("port without status = %+v", p)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	d := &testutil.MockDriverV2{
+		Ports: []types.PONPortStatus{{Port: "0/1", MaxONUs: 128}},
+		ONUs:  []types.ONUInfo{{PONPort: "0/1", ONUID: 1, IsOnline: true, BandwidthDown: 100, BandwidthUp: 50}},
+	}
+	report, err := Collect(context.Background(), d, Config{})
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if report.ONUs != 1 || report.MaxONUs != 128 || report.CommittedUpMbps != 50 {
+		t.Errorf("report = %+v", report)
+	}
+
+	d.Faults = map[string]*testutil.Fault{"GetONUList": {Err: errors.New("timeout")}}
+	if _, err := Collect(context.Background(), d, Config{}); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -3,6 +3,7 @@ package adtran
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -17,13 +18,15 @@ import (
 // Adapter wraps a base driver with Adtran-specific logic
 // Adtran SDX series uses NETCONF/YANG for OLT management
 type Adapter struct {
+	netconf.BBFDriver
 	baseDriver      types.Driver
 	netconfExecutor netconf.NETCONFExecutor
 	config          *types.EquipmentConfig
 }
 
 // NewAdapter creates a new Adtran adapter
-func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig) types.Driver {
+func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig, opts ...types.Option) types.Driver {
+	config.Apply(opts...)
 	adapter := &Adapter{
 		baseDriver: baseDriver,
 		config:     config,
@@ -33,6 +36,7 @@ func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig) types.Dr
 	if executor, ok := baseDriver.(netconf.NETCONFExecutor); ok {
 		adapter.netconfExecutor = executor
 	}
+	adapter.BBFDriver = netconf.NewBBFDriver("Adtran", adapter.netconfExecutor)
 
 	return adapter
 }
@@ -49,20 +53,44 @@ func (a *Adapter) IsConnected() bool {
 	return a.baseDriver.IsConnected()
 }
 
+// DriverMetrics implements types.MetricsProvider
+func (a *Adapter) DriverMetrics() types.DriverMetrics {
+	return types.CollectMetrics(a.baseDriver)
+}
+
+// Capabilities implements types.CapabilityReporter. Of DriverV2 only the
+// ONU list, discovery, ONU optical levels and OLT status are supported.
+func (a *Adapter) Capabilities() types.Capabilities {
+	c := types.DetectCapabilities(a)
+	a.ClearUnsupported(&c)
+	return c
+}
+
 // CreateSubscriber provisions an ONT on the Adtran OLT
 func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (*types.SubscriberResult, error) {
 	if a.netconfExecutor == nil {
 		return nil, fmt.Errorf("NETCONF executor not available - Adtran requires NETCONF driver")
 	}
+	if err := subscriber.Spec.ValidateGEMMapping(); err != nil {
+		return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "adtran"}
+	}
 
 	// Extract subscriber parameters
 	params := a.extractSubscriberParams(subscriber, tier)
 
 	// Build ONT provisioning configuration
-	config := a.buildONTConfig(params)
+	config, err := a.buildONTConfig(params)
+	if err != nil {
+		return nil, netconf.AsHumanError("adtran", err)
+	}
+
+	// Check the generated configuration before touching the datastore
+	if err := a.validateConfig(ctx, config); err != nil {
+		return nil, err
+	}
 
 	// Apply configuration via NETCONF edit-config
-	err := a.netconfExecutor.EditConfig(ctx, "", config,
+	err = a.netconfExecutor.EditConfig(ctx, "", config,
 		netconf.WithMerge(),
 		netconf.WithRollbackOnError(),
 	)
@@ -93,6 +121,23 @@ func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscr
 	return result, nil
 }
 
+// validateConfig runs <validate> on generated configuration when the device
+// supports it. Device-side rejections are returned as *types.HumanError.
+func (a *Adapter) validateConfig(ctx context.Context, config string) error {
+	if !a.netconfExecutor.HasCapability(netconf.CapValidate) {
+		return nil
+	}
+	err := a.netconfExecutor.ValidateConfig(ctx, config)
+	if err == nil {
+		return nil
+	}
+	var verr *netconf.ValidationError
+	if errors.As(err, &verr) {
+		return verr.ToHumanError("adtran")
+	}
+	return fmt.Errorf("Adtran ONT validation failed: %w", err)
+}
+
 // subscriberParams holds parsed subscriber parameters for Adtran
 type subscriberParams struct {
 	SerialNumber     string
@@ -105,6 +150,7 @@ type subscriberParams struct {
 	BandwidthProfile string
 	BandwidthUp      int
 	BandwidthDown    int
+	GEMPorts         []model.GEMPort
 }
 
 // extractSubscriberParams extracts parameters from Subscriber and ServiceTier
@@ -113,6 +159,7 @@ func (a *Adapter) extractSubscriberParams(subscriber *model.Subscriber, tier *mo
 		SerialNumber: subscriber.Spec.ONUSerial,
 		VLAN:         subscriber.Spec.VLAN,
 		Description:  fmt.Sprintf("Nanoncore subscriber %s", subscriber.Name),
+		GEMPorts:     subscriber.Spec.GEMPorts,
 	}
 
 	// Get PON port from metadata or annotations
@@ -171,26 +218,26 @@ func (a *Adapter) extractSubscriberParams(subscriber *model.Subscriber, tier *mo
 }
 
 // buildONTConfig builds Adtran YANG XML for ONT provisioning
-func (a *Adapter) buildONTConfig(params *subscriberParams) string {
-	return fmt.Sprintf(`
-<config xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">
-  <ont xmlns="http://www.adtran.com/ns/yang/adtran-ont">
-    <serial-number>%s</serial-number>
-    <ont-id>%d</ont-id>
-    <pon-port>%s</pon-port>
-    <admin-state>enabled</admin-state>
-    <description>%s</description>
-    <ont-profile>%s</ont-profile>
-    <service-profile>%s</service-profile>
-  </ont>
-</config>`,
-		params.SerialNumber,
-		params.ONTID,
-		params.PONPort,
-		params.Description,
-		params.ONTProfile,
-		params.ServiceProfile,
-	)
+func (a *Adapter) buildONTConfig(params *subscriberParams) (string, error) {
+	ont := &ontConfig{
+		SerialNumber:   params.SerialNumber,
+		ONTID:          params.ONTID,
+		PONPort:        params.PONPort,
+		AdminState:     "enabled",
+		Description:    params.Description,
+		ONTProfile:     params.ONTProfile,
+		ServiceProfile: params.ServiceProfile,
+	}
+	for _, gem := range params.GEMPorts {
+		ont.Services = append(ont.Services, ontService{
+			Name:        gem.GetService(),
+			GEMPort:     gem.ID,
+			TCont:       gem.TCont,
+			UserVLAN:    gem.GetUserVLAN(),
+			NetworkVLAN: gem.VLAN,
+		})
+	}
+	return netconf.MarshalConfig(ont)
 }
 
 // UpdateSubscriber updates subscriber configuration
@@ -199,9 +246,16 @@ func (a *Adapter) UpdateSubscriber(ctx context.Context, subscriber *model.Subscr
 		return fmt.Errorf("NETCONF executor not available")
 	}
 
+	if err := subscriber.Spec.ValidateGEMMapping(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "adtran"}
+	}
+
 	// For Adtran, update is same as create with merge operation
 	params := a.extractSubscriberParams(subscriber, tier)
-	config := a.buildONTConfig(params)
+	config, err := a.buildONTConfig(params)
+	if err != nil {
+		return netconf.AsHumanError("adtran", err)
+	}
 
 	return a.netconfExecutor.EditConfig(ctx, "", config,
 		netconf.WithMerge(),
@@ -274,10 +328,17 @@ func (a *Adapter) GetSubscriberStatus(ctx context.Context, subscriberID string)
 
 	serialNumber := a.parseSubscriberSerial(subscriberID)
 
-	// Query ONT state
+	// Query ONT state; with XPath only the parsed leaves are returned
 	filter := fmt.Sprintf(GetONTStateFilterXML, serialNumber)
+	xpath := netconf.XPathFilter{
+		Select:     netconf.XPathLeaves(fmt.Sprintf(GetONTStateXPath, netconf.XPathLiteral(serialNumber)), ONTStateLeaves...),
+		Namespaces: map[string]string{"ont": NSAdtranONT},
+	}
 
-	response, err := a.netconfExecutor.Get(ctx, filter)
+	response, err := a.netconfExecutor.Get(ctx, filter,
+		netconf.WithXPath(xpath),
+		netconf.WithDefaults(netconf.WithDefaultsReportAll),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ONT status: %w", err)
 	}
@@ -321,7 +382,7 @@ func (a *Adapter) GetSubscriberStats(ctx context.Context, subscriberID string) (
 	serviceID := fmt.Sprintf("svc-%s", serialNumber)
 	filter := fmt.Sprintf(GetServiceStatsFilterXML, serviceID)
 
-	response, err := a.netconfExecutor.Get(ctx, filter)
+	response, err := a.netconfExecutor.Get(ctx, filter, netconf.WithDefaults(netconf.WithDefaultsReportAll))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ONT stats: %w", err)
 	}
@@ -474,7 +535,7 @@ func parseUptime(uptime string) int64 {
 
 // detectModel returns the detected OLT model
 func (a *Adapter) detectModel() string {
-	if model, ok := a.config.Metadata["model"]; ok {
+	if model := a.config.DeviceModel(); model != "" {
 		return model
 	}
 	return "sdx-600"
@@ -567,7 +628,7 @@ func (a *Adapter) DiscoverONTs(ctx context.Context, ponPort string) ([]ONTState,
   </port>
 </gpon-state>`, ponPort)
 
-	response, err := a.netconfExecutor.Get(ctx, filter)
+	response, err := a.netconfExecutor.Get(ctx, filter, netconf.WithDefaults(netconf.WithDefaultsReportAll))
 	if err != nil {
 		return nil, err
 	}
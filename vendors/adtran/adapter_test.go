@@ -2,6 +2,7 @@ package adtran
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -584,6 +585,85 @@ func TestCreateSubscriber_EditConfigFails(t *testing.T) {
 	}
 }
 
+func TestCreateSubscriber_InvalidONTID(t *testing.T) {
+	a, _, nc := newTestAdapter()
+
+	sub := testutil.NewTestSubscriber("ADTN12345678", "0/1", 100)
+	sub.Annotations["nanoncore.com/ont-id"] = "2000"
+	tier := testutil.NewTestServiceTier(50, 200)
+
+	_, err := a.CreateSubscriber(context.Background(), sub, tier)
+	var he *types.HumanError
+	if !errors.As(err, &he) {
+		t.Fatalf("expected *types.HumanError, got %v", err)
+	}
+	if he.Code != types.ErrCodeValidationFailed || he.Vendor != "adtran" {
+		t.Errorf("HumanError = %+v", he)
+	}
+	if len(nc.Calls) != 0 {
+		t.Errorf("no NETCONF calls expected for invalid config, got %v", nc.Calls)
+	}
+}
+
+func TestBuildONTConfig(t *testing.T) {
+	a := &Adapter{}
+	config, err := a.buildONTConfig(&subscriberParams{
+		SerialNumber:   "ADTN12345678",
+		ONTID:          5,
+		PONPort:        "gpon-0/0/1",
+		ONTProfile:     "ont-default",
+		ServiceProfile: "svc-100M",
+	})
+	if err != nil {
+		t.Fatalf("buildONTConfig failed: %v", err)
+	}
+	if strings.Contains(config, "<config") {
+		t.Errorf("edit-config content should not carry its own <config> wrapper:\n%s", config)
+	}
+	if !strings.Contains(config, "<ont-id>5</ont-id>") {
+		t.Errorf("missing ont-id:\n%s", config)
+	}
+
+	if _, err := a.buildONTConfig(&subscriberParams{ONTID: 5, PONPort: "gpon-0/0/1"}); err == nil {
+		t.Error("expected error for missing serial number")
+	}
+}
+
+func TestBuildONTConfigServices(t *testing.T) {
+	a := &Adapter{}
+	params := &subscriberParams{
+		SerialNumber:   "ADTN12345678",
+		ONTID:          5,
+		PONPort:        "gpon-0/0/1",
+		ONTProfile:     "ont-default",
+		ServiceProfile: "svc-100M",
+		GEMPorts: []model.GEMPort{
+			{ID: 1, TCont: 1, VLAN: 100},
+			{ID: 2, TCont: 2, VLAN: 300, UserVLAN: 30, Service: "IPTV"},
+		},
+	}
+	config, err := a.buildONTConfig(params)
+	if err != nil {
+		t.Fatalf("buildONTConfig failed: %v", err)
+	}
+	for _, want := range []string{
+		"<name>INTERNET</name>",
+		"<name>IPTV</name>",
+		"<gem-port>2</gem-port>",
+		"<user-vlan>30</user-vlan>",
+		"<network-vlan>300</network-vlan>",
+	} {
+		if !strings.Contains(config, want) {
+			t.Errorf("missing %s in:\n%s", want, config)
+		}
+	}
+
+	params.GEMPorts[1].VLAN = 5000
+	if _, err := a.buildONTConfig(params); err == nil {
+		t.Error("expected error for an out of range service VLAN")
+	}
+}
+
 func TestUpdateSubscriber_Success(t *testing.T) {
 	a, _, nc := newTestAdapter()
 
@@ -1103,3 +1183,46 @@ func TestIsConnected_DelegatesToBaseDriver(t *testing.T) {
 // --- Interface compliance ---
 
 var _ types.Driver = (*Adapter)(nil)
+
+func TestRestoreConfig_Running(t *testing.T) {
+	a, _, nc := newTestAdapter()
+
+	if err := a.RestoreConfig(context.Background(), "sftp://host/sdx.xml"); err != nil {
+		t.Fatalf("RestoreConfig failed: %v", err)
+	}
+	if len(nc.Calls) != 1 || nc.Calls[0] != "CopyConfig:sftp://host/sdx.xml->running" {
+		t.Fatalf("Calls = %v", nc.Calls)
+	}
+}
+
+func TestBackupConfig_CopyFails(t *testing.T) {
+	a, _, nc := newTestAdapter()
+	nc.CopyConfigError = fmt.Errorf("url not reachable")
+
+	_, err := a.BackupConfig(context.Background(), "sftp://host/sdx.xml")
+	if err == nil || !strings.Contains(err.Error(), "adtran config backup failed") {
+		t.Fatalf("expected backup error, got %v", err)
+	}
+}
+
+func TestBulkCreateSubscribers(t *testing.T) {
+	a, _, nc := newTestAdapter()
+	tier := testutil.NewTestServiceTier(50, 200)
+
+	result, err := a.BulkCreateSubscribers(context.Background(), []types.BulkSubscriberRequest{
+		{Subscriber: testutil.NewTestSubscriber("ADTN00000001", "0/1", 101), Tier: tier},
+		{Subscriber: nil},
+	})
+	if err != nil {
+		t.Fatalf("BulkCreateSubscribers failed: %v", err)
+	}
+	if result.Succeeded != 1 || result.Failed != 1 {
+		t.Fatalf("Succeeded=%d Failed=%d, want 1/1", result.Succeeded, result.Failed)
+	}
+	if r := result.Results[0]; r.Serial != "ADTN00000001" || r.PONPort == "" {
+		t.Errorf("Results[0] = %+v", r)
+	}
+	if len(nc.Calls) != 1 || nc.Calls[0] != "EditConfig" {
+		t.Errorf("Calls = %v, want one EditConfig", nc.Calls)
+	}
+}
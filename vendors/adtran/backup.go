@@ -0,0 +1,29 @@
+package adtran
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/drivers/netconf"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.ConfigBackupManager = (*Adapter)(nil)
+
+// BackupConfig saves the running configuration to destination via
+// copy-config, or returns it inline when destination is empty
+func (a *Adapter) BackupConfig(ctx context.Context, destination string) (*types.ConfigBackup, error) {
+	if a.netconfExecutor == nil {
+		return nil, fmt.Errorf("NETCONF executor not available")
+	}
+	return netconf.BackupConfig(ctx, a.netconfExecutor, "adtran", destination)
+}
+
+// RestoreConfig loads the configuration stored at the source URL.
+// The restore is committed through candidate when the OLT exposes it.
+func (a *Adapter) RestoreConfig(ctx context.Context, source string) error {
+	if a.netconfExecutor == nil {
+		return fmt.Errorf("NETCONF executor not available")
+	}
+	return netconf.RestoreConfig(ctx, a.netconfExecutor, "adtran", source)
+}
@@ -0,0 +1,40 @@
+package adtran
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/drivers/netconf"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.BulkSubscriberProvisioner = (*Adapter)(nil)
+
+// BulkCreateSubscribers provisions ONTs with a single edit-config and commit
+func (a *Adapter) BulkCreateSubscribers(ctx context.Context, requests []types.BulkSubscriberRequest) (*types.BulkResult, error) {
+	if a.netconfExecutor == nil {
+		return nil, fmt.Errorf("NETCONF executor not available - Adtran requires NETCONF driver")
+	}
+
+	build := func(i int, op *types.BulkOpResult) (string, error) {
+		req := requests[i]
+		if req.Subscriber == nil {
+			return "", fmt.Errorf("subscriber is required")
+		}
+		params := a.extractSubscriberParams(req.Subscriber, req.Tier)
+		op.Serial = params.SerialNumber
+		op.PONPort = params.PONPort
+		op.ONUID = params.ONTID
+		op.Metadata = map[string]interface{}{
+			"subscriber_id":   req.Subscriber.Name,
+			"service_profile": params.ServiceProfile,
+		}
+		return a.buildONTConfig(params)
+	}
+
+	return netconf.ProvisionBulk(ctx, a.netconfExecutor, "adtran", len(requests), build,
+		netconf.WithMerge(),
+		netconf.WithTestThenSet(),
+		netconf.WithRollbackOnError(),
+	), nil
+}
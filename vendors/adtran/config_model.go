@@ -0,0 +1,59 @@
+package adtran
+
+import (
+	"encoding/xml"
+
+	"github.com/nanoncore/nano-southbound/drivers/netconf"
+)
+
+// Adtran ONT configuration model used for provisioning. Field tags mirror
+// the adtran-ont YANG schema; Validate enforces the constraints the OLT
+// would otherwise reject at commit.
+
+// maxONTID is the highest ONT ID on a GPON port
+const maxONTID = 1022
+
+// ontConfig is adtran-ont:ont
+type ontConfig struct {
+	XMLName        xml.Name `xml:"http://www.adtran.com/ns/yang/adtran-ont ont"`
+	SerialNumber   string   `xml:"serial-number"`
+	ONTID          int      `xml:"ont-id"`
+	PONPort        string   `xml:"pon-port"`
+	AdminState     string   `xml:"admin-state"`
+	Description    string   `xml:"description,omitempty"`
+	ONTProfile     string   `xml:"ont-profile"`
+	ServiceProfile string   `xml:"service-profile"`
+
+	// Services are set for subscribers with explicit GEM ports, one per
+	// GEM port; otherwise the service profile maps the single VLAN
+	Services []ontService `xml:"service,omitempty"`
+}
+
+// ontService is adtran-ont:ont/service
+type ontService struct {
+	Name        string `xml:"name"`
+	GEMPort     int    `xml:"gem-port"`
+	TCont       int    `xml:"tcont"`
+	UserVLAN    int    `xml:"user-vlan"`
+	NetworkVLAN int    `xml:"network-vlan"`
+}
+
+// Validate checks mandatory leaves, the ONT ID range and service VLANs
+func (o *ontConfig) Validate() error {
+	errs := []error{
+		netconf.RequireLeaf("/ont/serial-number", o.SerialNumber),
+		netconf.CheckRange("/ont/ont-id", o.ONTID, 0, maxONTID),
+		netconf.RequireLeaf("/ont/pon-port", o.PONPort),
+		netconf.RequireLeaf("/ont/admin-state", o.AdminState),
+		netconf.RequireLeaf("/ont/ont-profile", o.ONTProfile),
+		netconf.RequireLeaf("/ont/service-profile", o.ServiceProfile),
+	}
+	for _, svc := range o.Services {
+		errs = append(errs,
+			netconf.RequireLeaf("/ont/service/name", svc.Name),
+			netconf.CheckRange("/ont/service/user-vlan", svc.UserVLAN, 1, 4094),
+			netconf.CheckRange("/ont/service/network-vlan", svc.NetworkVLAN, 1, 4094),
+		)
+	}
+	return netconf.FirstError(errs...)
+}
@@ -0,0 +1,46 @@
+package adtran
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/drivers/netconf"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// DriverV2 monitoring of the Adtran adapter. ONU and PON port state is read
+// from the BBF TR-385 models of the SDX by the embedded netconf.BBFDriver,
+// system information from ietf-system. Provisioning stays on Driver: the other
+// DriverV2 operations return an error and are cleared from Capabilities.
+
+var _ types.DriverV2 = (*Adapter)(nil)
+
+// GetOLTStatus returns the system information of the OLT with its PON
+// ports and ONU counts
+func (a *Adapter) GetOLTStatus(ctx context.Context) (*types.OLTStatus, error) {
+	info, err := a.GetSystemInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system information: %w", err)
+	}
+	status := &types.OLTStatus{
+		OLTID:         a.config.Name,
+		Vendor:        "adtran",
+		Model:         info.Model,
+		Firmware:      info.SoftwareVer,
+		SerialNumber:  info.SerialNumber,
+		IsReachable:   true,
+		IsHealthy:     true,
+		UptimeSeconds: info.UptimeSecs,
+		CPUPercent:    info.CPUPercent,
+		MemoryPercent: info.MemoryPercent,
+		Temperature:   info.Temperature,
+		LastPoll:      time.Now(),
+		Metadata:      map[string]interface{}{"hostname": info.Hostname},
+	}
+	if status.Model == "" {
+		status.Model = a.detectModel()
+	}
+	netconf.NewBBFClient(a.netconfExecutor).AddPONState(ctx, status)
+	return status, nil
+}
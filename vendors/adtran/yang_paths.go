@@ -25,6 +25,7 @@ const (
 	NSAdtranXpon    = "http://www.adtran.com/ns/yang/adtran-xpon"
 	NSAdtranSystem  = "http://www.adtran.com/ns/yang/adtran-system"
 	NSAdtranService = "http://www.adtran.com/ns/yang/adtran-service"
+	NSAdtranONT     = "http://www.adtran.com/ns/yang/adtran-ont"
 )
 
 // Configuration Paths - BBF TR-385 compliant with Adtran extensions
@@ -260,6 +261,16 @@ const GetONTStateFilterXML = `
   <serial-number>%s</serial-number>
 </ont-state>`
 
+// GetONTStateXPath locates one ONT for XPath filtering; %s is an XPath
+// literal for the serial number. The "ont" prefix is bound to NSAdtranONT.
+const GetONTStateXPath = "/ont:ont-state[ont:serial-number=%s]"
+
+// ONTStateLeaves are the ONT state leaves read by GetSubscriberStatus
+var ONTStateLeaves = []string{
+	"ont:ont-id", "ont:pon-port", "ont:admin-state", "ont:operational-status",
+	"ont:optical-info", "ont:distance", "ont:uptime",
+}
+
 // GetServiceStatsFilterXML is the filter for service statistics
 const GetServiceStatsFilterXML = `
 <service-state xmlns="http://www.adtran.com/ns/yang/adtran-service">
@@ -1,3 +1,6 @@
+// Package cdata adapts the CLI driver to C-Data FD1104S/FD1208S series
+// OLTs. Commands follow the FD1104S/FD1208S CLI Reference Manual and are not
+// yet verified on hardware.
 package cdata
 
 import (
@@ -10,6 +13,7 @@ import (
 
 	"github.com/nanoncore/nano-southbound/model"
 	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
 )
 
 // Adapter wraps a base driver with C-Data-specific logic
@@ -29,7 +33,8 @@ type Adapter struct {
 }
 
 // NewAdapter creates a new C-Data adapter
-func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig) types.Driver {
+func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig, opts ...types.Option) types.Driver {
+	config.Apply(opts...)
 	adapter := &Adapter{baseDriver: baseDriver, config: config}
 
 	// Check if base driver supports CLI execution
@@ -52,11 +57,38 @@ func (a *Adapter) IsConnected() bool {
 	return a.baseDriver.IsConnected()
 }
 
+// DriverMetrics implements types.MetricsProvider
+func (a *Adapter) DriverMetrics() types.DriverMetrics {
+	return types.CollectMetrics(a.baseDriver)
+}
+
+// Capabilities implements types.CapabilityReporter. Configuration restore
+// is not supported over CLI; the DriverV2 operations that return
+// notImplemented are cleared.
+func (a *Adapter) Capabilities() types.Capabilities {
+	c := types.DetectCapabilities(a)
+	c.ConfigRestore = false
+	c.ONUFactoryReset = false
+	c.OLTRestart = false
+	c.BulkProvision = false
+	c.VLANs = false
+	c.ServicePorts = false
+	c.SubscriberMigration = false
+	c.MultiONU = false
+	return c
+}
+
 // CreateSubscriber provisions an ONU on the C-Data OLT
-func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (*types.SubscriberResult, error) {
+func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (_ *types.SubscriberResult, err error) {
+	ctx, endAudit := types.BeginAudit(ctx, a.config, "CreateSubscriber", subscriber.Spec.ONUSerial)
+	defer func() { endAudit(err) }()
+
 	if a.cliExecutor == nil {
 		return nil, fmt.Errorf("CLI executor not available - C-Data requires CLI driver")
 	}
+	if err := a.validateGEMPorts(&subscriber.Spec); err != nil {
+		return nil, err
+	}
 
 	// Parse subscriber info
 	ponPort := a.getPONPort(subscriber)
@@ -77,15 +109,30 @@ func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscr
 		commands = a.buildEPONCommands(ponPort, onuID, serial, vlan, bandwidthDown, bandwidthUp, subscriber, tier)
 	}
 
-	// Execute commands
-	outputs, err := a.cliExecutor.ExecCommands(ctx, commands)
-	if err != nil {
-		return nil, a.translateError(err)
-	}
+	// Execute commands. The ONU is deleted again if any command after its
+	// creation fails or it cannot be verified.
+	var outputs []string
+	err = types.RunTransaction(ctx, "create subscriber "+subscriber.Name, a.config, func(tx *types.Transaction) error {
+		create, configure := common.SplitCommandsAfter(commands, "onu-set ")
+		out, err := tx.ExecStep(ctx, a.cliExecutor, "add onu", create, a.buildDeleteCommands(ponPort, onuID))
+		outputs = append(outputs, out...)
+		if err != nil {
+			return a.translateError(err)
+		}
+		out, err = tx.ExecStep(ctx, a.cliExecutor, "configure onu", configure, nil)
+		outputs = append(outputs, out...)
+		if err != nil {
+			return a.translateError(err)
+		}
 
-	// Verify the ONU was actually created (C-Data can fail silently)
-	if verifyErr := a.verifyONUExists(ctx, ponPort, onuID); verifyErr != nil {
-		return nil, fmt.Errorf("C-Data provisioning verification failed: %w", verifyErr)
+		// Verify the ONU was actually created (C-Data can fail silently)
+		if verifyErr := a.verifyONUExists(ctx, ponPort, onuID); verifyErr != nil {
+			return fmt.Errorf("C-Data provisioning verification failed: %w", verifyErr)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Build result
@@ -135,11 +182,14 @@ func (a *Adapter) buildGPONCommands(ponPort string, onuID int, serial string, vl
 		// Assign line profile (T-CONT + GEM port mapping)
 		// onu-profile <onu-id> line <name> service <name>
 		fmt.Sprintf("onu-profile %d line %s service %s", onuID, lineProfile, serviceProfile),
+	}
 
-		// Configure VLAN translation for the ONU
-		// onu-vlan <onu-id> mode translate user-vlan <cvlan> svlan <svlan>
-		fmt.Sprintf("onu-vlan %d mode translate user-vlan %d svlan %d", onuID, vlan, vlan),
+	// Configure VLAN translation for the ONU, per GEM port if the
+	// subscriber has several services
+	commands = append(commands, vlanCommands(onuID, vlan, &subscriber.Spec)...)
+	commands = append(commands, catvCommands(onuID, &subscriber.Spec)...)
 
+	commands = append(commands,
 		// Configure bandwidth rate limiting
 		// onu-ratelimit <onu-id> upstream <kbps> downstream <kbps>
 		fmt.Sprintf("onu-ratelimit %d upstream %d downstream %d",
@@ -158,11 +208,48 @@ func (a *Adapter) buildGPONCommands(ponPort string, onuID int, serial string, vl
 
 		// Exit config mode
 		"end",
+	)
+
+	return commands
+}
+
+// validateGEMPorts checks the GEM mapping of a subscriber. GEM ports only
+// exist on GPON.
+func (a *Adapter) validateGEMPorts(spec *model.SubscriberSpec) error {
+	err := spec.ValidateGEMMapping()
+	if err == nil && len(spec.GEMPorts) > 0 && a.detectPONType() != "gpon" {
+		err = fmt.Errorf("GEM ports are only supported on GPON")
+	}
+	if err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "cdata"}
 	}
+	return nil
+}
 
+// vlanCommands builds the VLAN translation of an ONU: one rule for the
+// subscriber VLAN, or one per GEM port when GEM ports are set (GPON only)
+func vlanCommands(onuID, vlan int, spec *model.SubscriberSpec) []string {
+	if len(spec.GEMPorts) == 0 {
+		return []string{onuVLANCommand(fmt.Sprintf("%d", onuID), vlan, vlan, spec.GetSVLAN())}
+	}
+	commands := make([]string, 0, len(spec.GEMPorts))
+	for _, gem := range spec.GEMPorts {
+		commands = append(commands, onuVLANCommand(fmt.Sprintf("%d gemport %d", onuID, gem.ID), gem.GetUserVLAN(), gem.VLAN, gem.SVLAN))
+	}
 	return commands
 }
 
+// onuVLANCommand translates userVLAN to vlan, stacking svlan on top of it
+// for Q-in-Q.
+// onu-vlan <onu-id> [gemport <id>] mode translate user-vlan <uvlan> svlan <vlan>
+// onu-vlan <onu-id> [gemport <id>] mode stack user-vlan <uvlan> cvlan <vlan> svlan <svlan>
+func onuVLANCommand(onu string, userVLAN, vlan, svlan int) string {
+	if svlan > 0 {
+		return fmt.Sprintf("onu-vlan %s mode stack user-vlan %d cvlan %d svlan %d", onu, userVLAN, vlan, svlan)
+	}
+	return fmt.Sprintf("onu-vlan %s mode translate user-vlan %d svlan %d", onu, userVLAN, vlan)
+}
+
 // buildEPONCommands builds C-Data EPON CLI commands
 func (a *Adapter) buildEPONCommands(ponPort string, onuID int, mac string, vlan int, bwDown, bwUp int, subscriber *model.Subscriber, tier *model.ServiceTier) []string {
 	// C-Data EPON CLI reference
@@ -180,10 +267,13 @@ func (a *Adapter) buildEPONCommands(ponPort string, onuID int, mac string, vlan
 
 		// Assign profiles
 		fmt.Sprintf("onu-profile %d line %s service %s", onuID, lineProfile, serviceProfile),
+	}
 
-		// Configure VLAN
-		fmt.Sprintf("onu-vlan %d mode translate user-vlan %d svlan %d", onuID, vlan, vlan),
+	// Configure VLAN
+	commands = append(commands, vlanCommands(onuID, vlan, &subscriber.Spec)...)
+	commands = append(commands, catvCommands(onuID, &subscriber.Spec)...)
 
+	commands = append(commands,
 		// Configure bandwidth
 		fmt.Sprintf("onu-ratelimit %d upstream %d downstream %d", onuID, bwUp*1000, bwDown*1000),
 
@@ -193,7 +283,7 @@ func (a *Adapter) buildEPONCommands(ponPort string, onuID int, mac string, vlan
 		"exit",
 		"commit",
 		"end",
-	}
+	)
 
 	return commands
 }
@@ -202,6 +292,9 @@ func (a *Adapter) UpdateSubscriber(ctx context.Context, subscriber *model.Subscr
 	if a.cliExecutor == nil {
 		return fmt.Errorf("CLI executor not available")
 	}
+	if err := a.validateGEMPorts(&subscriber.Spec); err != nil {
+		return err
+	}
 
 	ponPort := a.getPONPort(subscriber)
 	onuID := a.getONUID(subscriber)
@@ -219,25 +312,31 @@ func (a *Adapter) UpdateSubscriber(ctx context.Context, subscriber *model.Subscr
 			fmt.Sprintf("interface gpon-olt_%s", ponPort),
 			// Update profiles
 			fmt.Sprintf("onu-profile %d line %s service %s", onuID, lineProfile, serviceProfile),
-			// Update VLAN
-			fmt.Sprintf("onu-vlan %d mode translate user-vlan %d svlan %d", onuID, vlan, vlan),
+		}
+		// Update VLAN
+		commands = append(commands, vlanCommands(onuID, vlan, &subscriber.Spec)...)
+		commands = append(commands, catvCommands(onuID, &subscriber.Spec)...)
+		commands = append(commands,
 			// Update bandwidth
 			fmt.Sprintf("onu-ratelimit %d upstream %d downstream %d", onuID, bwUp*1000, bwDown*1000),
 			"exit",
 			"commit",
 			"end",
-		}
+		)
 	} else {
 		commands = []string{
 			"configure terminal",
 			fmt.Sprintf("interface epon-olt_%s", ponPort),
 			fmt.Sprintf("onu-profile %d line %s service %s", onuID, lineProfile, serviceProfile),
-			fmt.Sprintf("onu-vlan %d mode translate user-vlan %d svlan %d", onuID, vlan, vlan),
+		}
+		commands = append(commands, vlanCommands(onuID, vlan, &subscriber.Spec)...)
+		commands = append(commands, catvCommands(onuID, &subscriber.Spec)...)
+		commands = append(commands,
 			fmt.Sprintf("onu-ratelimit %d upstream %d downstream %d", onuID, bwUp*1000, bwDown*1000),
 			"exit",
 			"commit",
 			"end",
-		}
+		)
 	}
 
 	_, err := a.cliExecutor.ExecCommands(ctx, commands)
@@ -254,35 +353,30 @@ func (a *Adapter) DeleteSubscriber(ctx context.Context, subscriberID string) err
 
 	ponPort, onuID := a.parseSubscriberID(subscriberID)
 
-	var commands []string
-
-	if a.detectPONType() == "gpon" {
-		commands = []string{
-			"configure terminal",
-			fmt.Sprintf("interface gpon-olt_%s", ponPort),
-			fmt.Sprintf("no onu-set %d", onuID),
-			"exit",
-			"commit",
-			"end",
-		}
-	} else {
-		commands = []string{
-			"configure terminal",
-			fmt.Sprintf("interface epon-olt_%s", ponPort),
-			fmt.Sprintf("no onu-set %d", onuID),
-			"exit",
-			"commit",
-			"end",
-		}
-	}
-
-	_, err := a.cliExecutor.ExecCommands(ctx, commands)
+	_, err := a.cliExecutor.ExecCommands(ctx, a.buildDeleteCommands(ponPort, onuID))
 	if err != nil {
 		return a.translateError(err)
 	}
 	return nil
 }
 
+// buildDeleteCommands builds the commands that remove an ONU and all of
+// its configuration
+func (a *Adapter) buildDeleteCommands(ponPort string, onuID int) []string {
+	iface := fmt.Sprintf("interface gpon-olt_%s", ponPort)
+	if a.detectPONType() != "gpon" {
+		iface = fmt.Sprintf("interface epon-olt_%s", ponPort)
+	}
+	return []string{
+		"configure terminal",
+		iface,
+		fmt.Sprintf("no onu-set %d", onuID),
+		"exit",
+		"commit",
+		"end",
+	}
+}
+
 func (a *Adapter) SuspendSubscriber(ctx context.Context, subscriberID string) error {
 	if a.cliExecutor == nil {
 		return fmt.Errorf("CLI executor not available")
@@ -355,6 +449,66 @@ func (a *Adapter) ResumeSubscriber(ctx context.Context, subscriberID string) err
 	return nil
 }
 
+// RestartONU restarts an ONU with onu-deactivate and onu-activate,
+// verifying with onu-info that it went offline and registered again.
+func (a *Adapter) RestartONU(ctx context.Context, ponPort string, onuID int) (*types.RestartONUResult, error) {
+	result := &types.RestartONUResult{}
+
+	if a.cliExecutor == nil {
+		result.Error = "CLI executor not available"
+		result.Message = "Cannot connect to OLT"
+		return result, fmt.Errorf("CLI executor not available")
+	}
+
+	// Step 1: Deactivate the ONU
+	if err := a.execONUCommands(ctx, ponPort, fmt.Sprintf("onu-deactivate %d", onuID)); err != nil {
+		result.Error = err.Error()
+		result.Message = "Failed to send deactivate command"
+		return result, fmt.Errorf("failed to deactivate ONU: %w", err)
+	}
+	result.DeactivateSuccess = true
+
+	// Step 2: Verify ONU is offline with retries
+	verified, retries := common.WaitONUState(ctx, common.RestartOfflineWaits, func(ctx context.Context) bool {
+		state := a.onuState(ctx, ponPort, onuID)
+		return state == "offline" || state == "suspended"
+	})
+	result.DeactivateVerified = verified
+	result.RetryCount += retries
+
+	// Step 3: Activate the ONU
+	if err := a.execONUCommands(ctx, ponPort, fmt.Sprintf("onu-activate %d", onuID)); err != nil {
+		result.Error = err.Error()
+		result.Message = "Deactivated but failed to send activate command"
+		return result, fmt.Errorf("failed to activate ONU: %w", err)
+	}
+	result.ActivateSuccess = true
+
+	// Step 4: Verify ONU is back online with retries
+	verified, retries = common.WaitONUState(ctx, common.RestartOnlineWaits, func(ctx context.Context) bool {
+		return a.onuState(ctx, ponPort, onuID) == "online"
+	})
+	result.ActivateVerified = verified
+	result.RetryCount += retries
+
+	common.FinishRestart(result)
+	return result, nil
+}
+
+// onuState returns the state parsed from onu-info, or "" if it could not
+// be read
+func (a *Adapter) onuState(ctx context.Context, ponPort string, onuID int) string {
+	cmd := fmt.Sprintf("show gpon onu-info gpon-olt_%s %d", ponPort, onuID)
+	if a.detectPONType() != "gpon" {
+		cmd = fmt.Sprintf("show epon onu-info epon-olt_%s %d", ponPort, onuID)
+	}
+	output, err := a.cliExecutor.ExecCommand(ctx, cmd)
+	if err != nil {
+		return ""
+	}
+	return a.parseONUStatus(output, "").State
+}
+
 func (a *Adapter) GetSubscriberStatus(ctx context.Context, subscriberID string) (*types.SubscriberStatus, error) {
 	if a.cliExecutor == nil {
 		return nil, fmt.Errorf("CLI executor not available")
@@ -461,6 +615,73 @@ func (a *Adapter) DiscoverONUs(ctx context.Context, ponPorts []string) ([]types.
 	return discoveries, nil
 }
 
+// GetONUBySerial finds a provisioned ONU by GPON serial number, in ASCII or
+// hex form, or by MAC address on EPON. Returns nil if not found.
+func (a *Adapter) GetONUBySerial(ctx context.Context, serial string) (*types.ONUInfo, error) {
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available")
+	}
+
+	var cmd string
+	if a.detectPONType() == "gpon" {
+		normalized, err := common.NormalizeSerial(serial)
+		if err != nil {
+			return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "cdata"}
+		}
+		cmd = fmt.Sprintf("show gpon onu-info by-sn %s", normalized)
+	} else {
+		cmd = fmt.Sprintf("show epon onu-info by-mac %s", common.SanitizeCLIParam(serial))
+	}
+
+	output, err := a.cliExecutor.ExecCommand(ctx, cmd)
+	if err != nil {
+		return nil, a.translateError(err)
+	}
+	outputLower := strings.ToLower(output)
+	if strings.Contains(outputLower, "not found") || strings.Contains(outputLower, "no onu") {
+		return nil, nil
+	}
+	return a.parseONUInfo(output), nil
+}
+
+// parseONUInfo parses the "key : value" lines of onu-info into an ONUInfo
+func (a *Adapter) parseONUInfo(output string) *types.ONUInfo {
+	onu := &types.ONUInfo{}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "interface":
+			onu.PONPort = a.extractPortFromInterface(value)
+		case "onu id":
+			onu.ONUID, _ = strconv.Atoi(value)
+		case "sn":
+			onu.Serial = common.DecodeHexSerial(value)
+		case "mac":
+			onu.MAC = value
+			if onu.Serial == "" {
+				onu.Serial = value
+			}
+		case "line profile":
+			onu.LineProfile = value
+		case "vlan":
+			onu.VLAN, _ = strconv.Atoi(value)
+		case "state":
+			setONUState(onu, value)
+		case "rx power":
+			onu.RxPowerDBm, _ = strconv.ParseFloat(strings.TrimSuffix(value, " dBm"), 64)
+		case "tx power":
+			onu.TxPowerDBm, _ = strconv.ParseFloat(strings.TrimSuffix(value, " dBm"), 64)
+		case "distance":
+			onu.DistanceM, _ = strconv.Atoi(strings.TrimSuffix(value, " m"))
+		}
+	}
+	return onu
+}
+
 // parseAutofindOutput parses C-Data autofind CLI output
 func (a *Adapter) parseAutofindOutput(output string) []types.ONUDiscovery {
 	discoveries := []types.ONUDiscovery{}
@@ -644,7 +865,7 @@ func (a *Adapter) verifyONUExists(ctx context.Context, ponPort string, onuID int
 
 // detectModel determines the C-Data OLT model
 func (a *Adapter) detectModel() string {
-	if model, ok := a.config.Metadata["model"]; ok {
+	if model := a.config.DeviceModel(); model != "" {
 		return model
 	}
 	return "fd1104s"
@@ -2,17 +2,23 @@ package cdata
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/nanoncore/nano-southbound/model"
 	"github.com/nanoncore/nano-southbound/testutil"
 	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
 )
 
-// Compile-time interface compliance check.
+// Compile-time interface compliance checks.
 var _ types.Driver = (*Adapter)(nil)
+var _ types.ONULookup = (*Adapter)(nil)
 
 // ---------------------------------------------------------------------------
 // Helpers
@@ -396,6 +402,84 @@ func TestBuildGPONCommands(t *testing.T) {
 	}
 }
 
+func TestBuildGPONCommandsMultiService(t *testing.T) {
+	a := &Adapter{config: newGPONConfig()}
+	sub := newSubscriber("CDAT12345678", "1/1/2", 100, "5", "router")
+	sub.Spec.TConts = []model.TCont{{ID: 1}, {ID: 2}}
+	sub.Spec.GEMPorts = []model.GEMPort{
+		{ID: 1, TCont: 1, VLAN: 100},
+		{ID: 2, TCont: 2, VLAN: 300, UserVLAN: 30, Service: "IPTV"},
+	}
+
+	cmds := a.buildGPONCommands("1/1/2", 5, "CDAT12345678", 100, 100, 50, sub, newTier(50, 100, "", ""))
+	want := []string{
+		"onu-vlan 5 gemport 1 mode translate user-vlan 100 svlan 100",
+		"onu-vlan 5 gemport 2 mode translate user-vlan 30 svlan 300",
+	}
+	if i := slices.Index(cmds, want[0]); i < 0 || !slices.Equal(cmds[i:i+2], want) {
+		t.Errorf("expected %v in %v", want, cmds)
+	}
+	if slices.Contains(cmds, "onu-vlan 5 mode translate user-vlan 100 svlan 100") {
+		t.Error("unexpected single VLAN rule with GEM ports set")
+	}
+}
+
+func TestBuildCommandsQinQ(t *testing.T) {
+	svlan := 1000
+	sub := newSubscriber("AA:BB:CC:DD:EE:FF", "1/1/3", 200, "10", "")
+	sub.Spec.SVLAN = &svlan
+
+	a := &Adapter{config: newEPONConfig()}
+	cmds := a.buildEPONCommands("1/1/3", 10, "AA:BB:CC:DD:EE:FF", 200, 50, 25, sub, newTier(25, 50, "", ""))
+	if want := "onu-vlan 10 mode stack user-vlan 200 cvlan 200 svlan 1000"; !slices.Contains(cmds, want) {
+		t.Errorf("expected %q in %v", want, cmds)
+	}
+
+	a = &Adapter{config: newGPONConfig()}
+	sub.Spec.SVLAN = nil
+	sub.Spec.TConts = []model.TCont{{ID: 1}}
+	sub.Spec.GEMPorts = []model.GEMPort{{ID: 1, TCont: 1, VLAN: 200, UserVLAN: 20, SVLAN: 1000}}
+	cmds = a.buildGPONCommands("1/1/3", 10, "CDAT12345678", 200, 50, 25, sub, newTier(25, 50, "", ""))
+	if want := "onu-vlan 10 gemport 1 mode stack user-vlan 20 cvlan 200 svlan 1000"; !slices.Contains(cmds, want) {
+		t.Errorf("expected %q in %v", want, cmds)
+	}
+}
+
+func TestCreateSubscriber_GEMPortsRequireGPON(t *testing.T) {
+	mock := cliMockDriver(nil)
+	a := NewAdapter(mock, newEPONConfig()).(*Adapter)
+	sub := newSubscriber("AA:BB:CC:DD:EE:FF", "1/1/3", 200, "10", "")
+	sub.Spec.TConts = []model.TCont{{ID: 1}}
+	sub.Spec.GEMPorts = []model.GEMPort{{ID: 1, TCont: 1, VLAN: 200}}
+
+	_, err := a.CreateSubscriber(context.Background(), sub, newTier(25, 50, "", ""))
+	var he *types.HumanError
+	if !errors.As(err, &he) || he.Code != types.ErrCodeValidationFailed {
+		t.Fatalf("CreateSubscriber() error = %v, want validation error", err)
+	}
+	if len(mock.CLIExec.Commands) != 0 {
+		t.Errorf("no commands expected, got %v", mock.CLIExec.Commands)
+	}
+}
+
+func TestCreateSubscriber_RollsBackUnverifiedONU(t *testing.T) {
+	mock := cliMockDriver(map[string]string{
+		"show gpon onu-info gpon-olt_1/1/2 5": "% ONU not found",
+	})
+	a := NewAdapter(mock, newGPONConfig()).(*Adapter)
+	sub := newSubscriber("CDAT12345678", "1/1/2", 100, "5", "router")
+
+	_, err := a.CreateSubscriber(context.Background(), sub, newTier(50, 100, "", ""))
+	if err == nil || !strings.Contains(err.Error(), "verification failed") {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+
+	cmds := mock.CLIExec.Commands
+	if last := cmds[len(cmds)-4]; last != "no onu-set 5" {
+		t.Errorf("expected rollback with \"no onu-set 5\", commands: %v", cmds)
+	}
+}
+
 func TestBuildEPONCommands(t *testing.T) {
 	a := &Adapter{config: newEPONConfig()}
 	sub := newSubscriber("AA:BB:CC:DD:EE:FF", "1/1/3", 200, "10", "bridge")
@@ -430,6 +514,48 @@ func TestBuildEPONCommands(t *testing.T) {
 // Parsers
 // ---------------------------------------------------------------------------
 
+func TestGetONUBySerial(t *testing.T) {
+	a := NewAdapter(cliMockDriver(map[string]string{
+		"show gpon onu-info by-sn CDAT12345678": "Interface     : gpon-olt_1/1/2\nONU ID        : 4\nSN            : CDAT12345678\n" +
+			"Line profile  : line_100M\nVLAN          : 300\nState         : online\nRx power      : -18.50 dBm\n" +
+			"Tx power      : 2.10 dBm\nDistance      : 850 m",
+		"show gpon onu-info by-sn CDAT00000000": "% ONU not found",
+	}), newGPONConfig()).(*Adapter)
+	ctx := context.Background()
+
+	onu, err := a.GetONUBySerial(ctx, "4344415412345678")
+	if err != nil {
+		t.Fatalf("GetONUBySerial() error = %v", err)
+	}
+	want := types.ONUInfo{PONPort: "1/1/2", ONUID: 4, Serial: "CDAT12345678", LineProfile: "line_100M", VLAN: 300,
+		AdminState: "enabled", OperState: "online", IsOnline: true, RxPowerDBm: -18.5, TxPowerDBm: 2.1, DistanceM: 850}
+	if onu == nil || !reflect.DeepEqual(*onu, want) {
+		t.Errorf("GetONUBySerial() = %+v, want %+v", onu, want)
+	}
+
+	if onu, err := a.GetONUBySerial(ctx, "CDAT00000000"); onu != nil || err != nil {
+		t.Errorf("GetONUBySerial(missing) = %+v, %v; want nil, nil", onu, err)
+	}
+	var herr *types.HumanError
+	if _, err := a.GetONUBySerial(ctx, "CDAT;reload"); !errors.As(err, &herr) || herr.Code != types.ErrCodeValidationFailed {
+		t.Errorf("GetONUBySerial(invalid) error = %v, want a validation error", err)
+	}
+}
+
+func TestGetONUBySerial_EPON(t *testing.T) {
+	a := NewAdapter(cliMockDriver(map[string]string{
+		"show epon onu-info by-mac AA:BB:CC:DD:EE:FF": "Interface     : epon-olt_1/1/1\nONU ID        : 2\nMAC           : AA:BB:CC:DD:EE:FF\nState         : Deactivated",
+	}), newEPONConfig()).(*Adapter)
+
+	onu, err := a.GetONUBySerial(context.Background(), "AA:BB:CC:DD:EE:FF")
+	if err != nil {
+		t.Fatalf("GetONUBySerial() error = %v", err)
+	}
+	if onu == nil || onu.PONPort != "1/1/1" || onu.ONUID != 2 || onu.Serial != "AA:BB:CC:DD:EE:FF" || onu.AdminState != "disabled" || onu.IsOnline {
+		t.Errorf("GetONUBySerial() = %+v", onu)
+	}
+}
+
 func TestParseONUStatus(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -906,6 +1032,66 @@ func TestResumeSubscriber_NoCLI(t *testing.T) {
 	}
 }
 
+// fastRestartWaits shortens the RestartONU verification waits for the test
+func fastRestartWaits(t *testing.T) {
+	offline, online := common.RestartOfflineWaits, common.RestartOnlineWaits
+	common.RestartOfflineWaits = []time.Duration{time.Millisecond, time.Millisecond}
+	common.RestartOnlineWaits = []time.Duration{time.Millisecond, time.Millisecond}
+	t.Cleanup(func() {
+		common.RestartOfflineWaits, common.RestartOnlineWaits = offline, online
+	})
+}
+
+func TestRestartONU_GPON(t *testing.T) {
+	fastRestartWaits(t)
+	mock := &testutil.MockCLIExecutor{
+		SequentialOutputs: map[string][]string{
+			"show gpon onu-info gpon-olt_1/1/1 3": {"Status: deactivated", "Status: offline", "Status: online"},
+		},
+	}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, newGPONConfig()).(*Adapter)
+
+	result, err := adapter.RestartONU(context.Background(), "1/1/1", 3)
+	if err != nil {
+		t.Fatalf("RestartONU() error = %v", err)
+	}
+	if !result.Success || !result.DeactivateVerified || !result.ActivateVerified {
+		t.Errorf("result = %+v", result)
+	}
+	if result.Message != "ONU restart completed and verified" {
+		t.Errorf("Message = %q", result.Message)
+	}
+	deactivate := slices.Index(mock.Commands, "onu-deactivate 3")
+	activate := slices.Index(mock.Commands, "onu-activate 3")
+	if deactivate < 0 || activate < deactivate {
+		t.Errorf("commands = %v", mock.Commands)
+	}
+}
+
+func TestRestartONU_ActivateFails(t *testing.T) {
+	fastRestartWaits(t)
+	mock := &testutil.MockCLIExecutor{
+		Errors: map[string]error{"onu-activate 3": fmt.Errorf("connection lost")},
+	}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, newGPONConfig()).(*Adapter)
+
+	result, err := adapter.RestartONU(context.Background(), "1/1/1", 3)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if result.Success || !result.DeactivateSuccess || result.ActivateSuccess {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestRestartONU_NoCLI(t *testing.T) {
+	adapter := NewAdapter(&simpleDriver{}, newGPONConfig()).(*Adapter)
+
+	if _, err := adapter.RestartONU(context.Background(), "1/1/1", 3); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
 func TestGetSubscriberStatus_Success(t *testing.T) {
 	cfg := newGPONConfig()
 	showCmd := "show gpon onu-info gpon-olt_1/1/2 5"
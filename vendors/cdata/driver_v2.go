@@ -0,0 +1,545 @@
+package cdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// DriverV2 operations of the C-Data adapter. Operations C-Data does not
+// support yet return an error and are cleared from Capabilities.
+
+var _ types.DriverV2 = (*Adapter)(nil)
+
+// showCommand returns "show gpon <args>" or "show epon <args>" depending on
+// the PON type of the OLT
+func (a *Adapter) showCommand(args string) string {
+	if a.detectPONType() == "gpon" {
+		return "show gpon " + args
+	}
+	return "show epon " + args
+}
+
+// oltInterface returns the name of the OLT interface of ponPort, e.g.
+// "gpon-olt_1/1/1"
+func (a *Adapter) oltInterface(ponPort string) string {
+	if a.detectPONType() == "gpon" {
+		return "gpon-olt_" + ponPort
+	}
+	return "epon-olt_" + ponPort
+}
+
+// show runs a show command and returns its output. C-Data reports errors
+// in the output, on a line starting with "%".
+func (a *Adapter) show(ctx context.Context, cmd string) (string, error) {
+	if a.cliExecutor == nil {
+		return "", fmt.Errorf("CLI executor not available")
+	}
+	output, err := a.cliExecutor.ExecCommand(ctx, cmd)
+	if err != nil {
+		return "", a.translateError(err)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if msg, ok := strings.CutPrefix(strings.TrimSpace(line), "%"); ok {
+			return "", a.translateError(errors.New(strings.TrimSpace(msg)))
+		}
+	}
+	return output, nil
+}
+
+// GetONUList returns the provisioned ONUs of all PON ports from onu-list,
+// filtered in memory
+func (a *Adapter) GetONUList(ctx context.Context, filter *types.ONUFilter) ([]types.ONUInfo, error) {
+	output, err := a.show(ctx, a.showCommand("onu-list"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ONU list: %w", err)
+	}
+	return types.PageONUs(filterONUs(a.parseONUList(output), filter), filter), nil
+}
+
+// parseONUList parses the onu-list table:
+//
+//	Interface       ONU-ID  SN            State    Line-profile  VLAN  RxPower  Distance
+//	gpon-olt_1/1/2  4       CDAT12345678  online   line_100M     300   -18.50   850
+//
+// EPON OLTs show the MAC address in the SN column. Values not reported are
+// shown as "-".
+func (a *Adapter) parseONUList(output string) []types.ONUInfo {
+	var onus []types.ONUInfo
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.Contains(fields[0], "-olt_") {
+			continue
+		}
+		id, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		onu := types.ONUInfo{
+			PONPort: a.extractPortFromInterface(fields[0]),
+			ONUID:   id,
+			Serial:  fields[2],
+		}
+		if strings.Count(fields[2], ":") == 5 {
+			onu.MAC = fields[2]
+		}
+		setONUState(&onu, fields[3])
+		if len(fields) > 4 && fields[4] != "-" {
+			onu.LineProfile = fields[4]
+		}
+		if len(fields) > 5 {
+			onu.VLAN, _ = strconv.Atoi(fields[5])
+		}
+		if len(fields) > 6 {
+			onu.RxPowerDBm, _ = strconv.ParseFloat(fields[6], 64)
+		}
+		if len(fields) > 7 {
+			onu.DistanceM, _ = strconv.Atoi(fields[7])
+		}
+		onus = append(onus, onu)
+	}
+	return onus
+}
+
+// setONUState sets the admin and operational state of onu from the state
+// shown by the OLT
+func setONUState(onu *types.ONUInfo, state string) {
+	onu.OperState = strings.ToLower(state)
+	onu.AdminState = "enabled"
+	switch onu.OperState {
+	case "online", "working":
+		onu.OperState, onu.IsOnline = "online", true
+	case "deactivated", "disabled":
+		onu.OperState, onu.AdminState = "offline", "disabled"
+	}
+}
+
+// filterONUs returns the ONUs matching filter
+func filterONUs(onus []types.ONUInfo, filter *types.ONUFilter) []types.ONUInfo {
+	if filter == nil {
+		return onus
+	}
+	var filtered []types.ONUInfo
+	for _, onu := range onus {
+		switch {
+		case filter.PONPort != "" && onu.PONPort != filter.PONPort:
+		case filter.Status == "online" && !onu.IsOnline:
+		case filter.Status == "offline" && onu.IsOnline:
+		case filter.Profile != "" && onu.LineProfile != filter.Profile:
+		case filter.Serial != "" && !strings.Contains(strings.ToLower(onu.Serial), strings.ToLower(filter.Serial)):
+		case filter.VLAN > 0 && onu.VLAN != filter.VLAN:
+		default:
+			filtered = append(filtered, onu)
+		}
+	}
+	return filtered
+}
+
+// GetONUProfiles returns the line profile and VLAN of every ONU, which
+// onu-list already reports
+func (a *Adapter) GetONUProfiles(ctx context.Context) ([]types.ONUInfo, error) {
+	return a.GetONUList(ctx, nil)
+}
+
+// GetPONPower returns the transceiver readings of a PON port from
+// olt-optical-info
+func (a *Adapter) GetPONPower(ctx context.Context, ponPort string) (*types.PONPowerReading, error) {
+	output, err := a.show(ctx, a.showCommand("olt-optical-info "+a.oltInterface(ponPort)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PON power: %w", err)
+	}
+	values := parseKeyValues(output)
+	return &types.PONPowerReading{
+		PONPort:     ponPort,
+		TxPowerDBm:  parseLeadingFloat(values["tx power"]),
+		RxPowerDBm:  parseLeadingFloat(values["rx power"]),
+		Temperature: parseLeadingFloat(values["temperature"]),
+		Timestamp:   time.Now(),
+		Metadata:    map[string]interface{}{"cli_output": output},
+	}, nil
+}
+
+// GetONUPower returns the optical levels of an ONU from onu-optical-info
+func (a *Adapter) GetONUPower(ctx context.Context, ponPort string, onuID int) (*types.ONUPowerReading, error) {
+	output, err := a.show(ctx, a.showCommand(fmt.Sprintf("onu-optical-info %s %d", a.oltInterface(ponPort), onuID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ONU power: %w", err)
+	}
+	values := parseKeyValues(output)
+	reading := &types.ONUPowerReading{
+		PONPort:         ponPort,
+		ONUID:           onuID,
+		Serial:          values["sn"],
+		TxPowerDBm:      parseLeadingFloat(values["tx power"]),
+		RxPowerDBm:      parseLeadingFloat(values["rx power"]),
+		OLTRxDBm:        parseLeadingFloat(values["olt rx power"]),
+		DistanceM:       int(parseLeadingFloat(values["distance"])),
+		TxHighThreshold: types.GPONTxHighThreshold,
+		TxLowThreshold:  types.GPONTxLowThreshold,
+		RxHighThreshold: types.GPONRxHighThreshold,
+		RxLowThreshold:  types.GPONRxLowThreshold,
+		Timestamp:       time.Now(),
+		Metadata:        map[string]interface{}{"cli_output": output},
+	}
+	reading.IsWithinSpec = types.IsPowerWithinSpec(reading.RxPowerDBm, reading.TxPowerDBm)
+	return reading, nil
+}
+
+// GetONUDistance returns the fiber distance of an ONU, or -1 if the OLT
+// does not report it
+func (a *Adapter) GetONUDistance(ctx context.Context, ponPort string, onuID int) (int, error) {
+	power, err := a.GetONUPower(ctx, ponPort, onuID)
+	if err != nil {
+		return -1, err
+	}
+	if power.DistanceM == 0 {
+		return -1, nil
+	}
+	return power.DistanceM, nil
+}
+
+// parseKeyValues parses the "key : value" lines of a show command, keys
+// lowercased
+func parseKeyValues(output string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return values
+}
+
+// parseLeadingFloat parses the number at the start of a value with a unit,
+// e.g. "-18.50 dBm", or returns 0
+func parseLeadingFloat(value string) float64 {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+	f, _ := strconv.ParseFloat(strings.TrimRight(fields[0], "%CmV"), 64)
+	return f
+}
+
+// RunDiagnostics combines the onu-info, optical levels and counters of an
+// ONU. Only the onu-info read is required.
+func (a *Adapter) RunDiagnostics(ctx context.Context, ponPort string, onuID int) (*types.ONUDiagnostics, error) {
+	output, err := a.show(ctx, a.showCommand(fmt.Sprintf("onu-info %s %d", a.oltInterface(ponPort), onuID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ONU info: %w", err)
+	}
+	info := a.parseONUInfo(output)
+	diag := &types.ONUDiagnostics{
+		Serial:      info.Serial,
+		PONPort:     ponPort,
+		ONUID:       onuID,
+		AdminState:  info.AdminState,
+		OperState:   info.OperState,
+		LineProfile: info.LineProfile,
+		VLAN:        info.VLAN,
+		Timestamp:   time.Now(),
+		VendorData:  map[string]interface{}{"cli_output": output},
+	}
+	if power, err := a.GetONUPower(ctx, ponPort, onuID); err == nil {
+		diag.Power = power
+	}
+	if stats, err := a.GetSubscriberStats(ctx, fmt.Sprintf("onu-%s-%d", ponPort, onuID)); err == nil {
+		diag.BytesUp = stats.BytesUp
+		diag.BytesDown = stats.BytesDown
+		diag.Errors = stats.ErrorsUp + stats.ErrorsDown
+		diag.Drops = stats.Drops
+	}
+	return diag, nil
+}
+
+// GetAlarms returns the active alarms of the OLT
+func (a *Adapter) GetAlarms(ctx context.Context) ([]types.OLTAlarm, error) {
+	output, err := a.show(ctx, "show alarm active")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alarms: %w", err)
+	}
+	return parseAlarms(output), nil
+}
+
+// parseAlarms parses the active alarm table:
+//
+//	Index  Severity  Alarm-Name  Source            Time                 Description
+//	1      Critical  ONU-LOS     gpon-olt_1/1/2:4  2024-01-15 10:30:00  ONU loss of signal
+//
+// The source is an OLT interface, an ONU on it (interface:id) or the
+// system.
+func parseAlarms(output string) []types.OLTAlarm {
+	alarms := []types.OLTAlarm{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[0]); err != nil {
+			continue
+		}
+		alarm := types.OLTAlarm{
+			ID:       fields[0],
+			Severity: strings.ToLower(fields[1]),
+			Type:     strings.ToLower(fields[2]),
+			Source:   "system",
+			RaisedAt: time.Now(),
+			Message:  strings.Join(fields[6:], " "),
+			Metadata: map[string]interface{}{"source": fields[3]},
+		}
+		if iface, onu, ok := strings.Cut(fields[3], ":"); ok {
+			alarm.Source, alarm.SourceID = "onu", strings.TrimPrefix(strings.TrimPrefix(iface, "gpon-olt_"), "epon-olt_")+":"+onu
+		} else if strings.Contains(fields[3], "-olt_") {
+			alarm.Source, alarm.SourceID = "port", strings.TrimPrefix(strings.TrimPrefix(fields[3], "gpon-olt_"), "epon-olt_")
+		}
+		if at, err := time.ParseInLocation("2006-01-02 15:04:05", fields[4]+" "+fields[5], time.Local); err == nil {
+			alarm.RaisedAt = at
+		}
+		alarms = append(alarms, alarm)
+	}
+	return alarms
+}
+
+// GetOLTStatus returns the system information of the OLT with its PON
+// ports and ONU counts. Port and ONU reads are best effort: their errors
+// are reported in the metadata.
+func (a *Adapter) GetOLTStatus(ctx context.Context) (*types.OLTStatus, error) {
+	output, err := a.show(ctx, "show system")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system information: %w", err)
+	}
+	values := parseKeyValues(output)
+	status := &types.OLTStatus{
+		OLTID:         a.config.Name,
+		Vendor:        "cdata",
+		Model:         values["system type"],
+		Firmware:      values["software version"],
+		SerialNumber:  values["serial number"],
+		IsReachable:   true,
+		IsHealthy:     true,
+		CPUPercent:    parseLeadingFloat(values["cpu usage"]),
+		MemoryPercent: parseLeadingFloat(values["memory usage"]),
+		Temperature:   parseLeadingFloat(values["temperature"]),
+		LastPoll:      time.Now(),
+		Metadata:      map[string]interface{}{"hostname": values["system name"]},
+	}
+	if status.Model == "" {
+		status.Model = a.detectModel()
+	}
+	if uptime, err := time.ParseDuration(values["uptime"]); err == nil {
+		status.UptimeSeconds = int64(uptime.Seconds())
+	}
+
+	onusPerPort := make(map[string]int)
+	if onus, err := a.GetONUList(ctx, nil); err != nil {
+		status.Metadata["onu_error"] = err.Error()
+	} else {
+		status.TotalONUs = len(onus)
+		for _, onu := range onus {
+			onusPerPort[onu.PONPort]++
+			if onu.IsOnline {
+				status.ActiveONUs++
+			}
+		}
+	}
+	if ports, err := a.ListPorts(ctx); err != nil {
+		status.Metadata["port_error"] = err.Error()
+	} else {
+		for _, p := range ports {
+			if p.ONUCount == 0 {
+				p.ONUCount = onusPerPort[p.Port]
+			}
+			status.PONPorts = append(status.PONPorts, *p)
+		}
+	}
+	return status, nil
+}
+
+// ListPorts returns the state of the PON ports from port-state:
+//
+//	Interface       Admin    Oper  ONUs  Max  Description
+//	gpon-olt_1/1/1  enable   up    12    128  building-a
+func (a *Adapter) ListPorts(ctx context.Context) ([]*types.PONPortStatus, error) {
+	output, err := a.show(ctx, a.showCommand("port-state"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PON port state: %w", err)
+	}
+	var ports []*types.PONPortStatus
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !strings.Contains(fields[0], "-olt_") {
+			continue
+		}
+		port := &types.PONPortStatus{
+			Port:       a.extractPortFromInterface(fields[0]),
+			AdminState: "enabled",
+			OperState:  strings.ToLower(fields[2]),
+		}
+		if strings.HasPrefix(strings.ToLower(fields[1]), "disable") {
+			port.AdminState = "disabled"
+		}
+		if len(fields) > 3 {
+			port.ONUCount, _ = strconv.Atoi(fields[3])
+		}
+		if len(fields) > 4 {
+			port.MaxONUs, _ = strconv.Atoi(fields[4])
+		}
+		if len(fields) > 5 {
+			port.Description = strings.Join(fields[5:], " ")
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// SetPortState enables or disables a PON port with no shutdown or shutdown
+// in its OLT interface
+func (a *Adapter) SetPortState(ctx context.Context, port string, enabled bool) error {
+	cmd := "shutdown"
+	if enabled {
+		cmd = "no shutdown"
+	}
+	if err := a.execONUCommands(ctx, port, cmd); err != nil {
+		action := "enable"
+		if !enabled {
+			action = "disable"
+		}
+		return fmt.Errorf("failed to %s port %s: %w", action, port, err)
+	}
+	return nil
+}
+
+// ApplyProfile changes the profiles, VLAN and rate limit of a provisioned
+// ONU. Only the parts set in profile are applied; a missing line or
+// service profile name gets the default CreateSubscriber uses.
+func (a *Adapter) ApplyProfile(ctx context.Context, ponPort string, onuID int, profile *types.ONUProfile) error {
+	if profile == nil {
+		return fmt.Errorf("profile cannot be nil")
+	}
+	if profile.VLAN < 0 || profile.VLAN > 4094 {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: "vlan must be between 1 and 4094", Vendor: "cdata"}
+	}
+
+	var commands []string
+	if profile.LineProfile != "" || profile.ServiceProfile != "" {
+		tier := &model.ServiceTier{
+			Annotations: map[string]string{},
+			Spec: model.ServiceTierSpec{
+				BandwidthDown: profile.BandwidthDown / 1000,
+				BandwidthUp:   profile.BandwidthUp / 1000,
+			},
+		}
+		if profile.LineProfile != "" {
+			tier.Annotations["nanoncore.com/line-profile"] = profile.LineProfile
+		}
+		if profile.ServiceProfile != "" {
+			tier.Annotations["nanoncore.com/service-profile"] = profile.ServiceProfile
+		}
+		commands = append(commands, fmt.Sprintf("onu-profile %d line %s service %s", onuID, a.getLineProfile(tier), a.getServiceProfile(tier)))
+	}
+	if profile.VLAN > 0 {
+		commands = append(commands, onuVLANCommand(strconv.Itoa(onuID), profile.VLAN, profile.VLAN, 0))
+	}
+	if profile.BandwidthUp > 0 || profile.BandwidthDown > 0 {
+		commands = append(commands, fmt.Sprintf("onu-ratelimit %d upstream %d downstream %d", onuID, profile.BandwidthUp, profile.BandwidthDown))
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+	return a.execONUCommands(ctx, ponPort, commands...)
+}
+
+// notImplemented is the error of the DriverV2 operations C-Data does not
+// support yet
+func notImplemented(operation string) error {
+	return fmt.Errorf("%s not yet implemented for C-Data", operation)
+}
+
+func (a *Adapter) FactoryResetONU(ctx context.Context, ponPort string, onuID int, opts *types.FactoryResetOptions) (*types.FactoryResetResult, error) {
+	return nil, notImplemented("FactoryResetONU")
+}
+
+func (a *Adapter) BulkProvision(ctx context.Context, operations []types.BulkProvisionOp) (*types.BulkResult, error) {
+	return nil, notImplemented("BulkProvision")
+}
+
+func (a *Adapter) RestartOLT(ctx context.Context) (*types.RestartOLTResult, error) {
+	err := notImplemented("RestartOLT")
+	return &types.RestartOLTResult{Error: err.Error(), Message: "C-Data OLT reboot not yet implemented"}, err
+}
+
+func (a *Adapter) ListVLANs(ctx context.Context) ([]types.VLANInfo, error) {
+	return nil, notImplemented("ListVLANs")
+}
+
+func (a *Adapter) GetVLAN(ctx context.Context, vlanID int) (*types.VLANInfo, error) {
+	return nil, notImplemented("GetVLAN")
+}
+
+func (a *Adapter) CreateVLAN(ctx context.Context, req *types.CreateVLANRequest) error {
+	return notImplemented("CreateVLAN")
+}
+
+func (a *Adapter) DeleteVLAN(ctx context.Context, vlanID int, force bool) error {
+	return notImplemented("DeleteVLAN")
+}
+
+func (a *Adapter) ListServicePorts(ctx context.Context) ([]types.ServicePort, error) {
+	return nil, notImplemented("ListServicePorts")
+}
+
+func (a *Adapter) AddServicePort(ctx context.Context, req *types.AddServicePortRequest) error {
+	return notImplemented("AddServicePort")
+}
+
+func (a *Adapter) DeleteServicePort(ctx context.Context, ponPort string, ontID int) error {
+	return notImplemented("DeleteServicePort")
+}
+
+func (a *Adapter) CaptureSubscriberConfig(ctx context.Context, subscriberID string) (*types.SubscriberSnapshot, error) {
+	return nil, notImplemented("CaptureSubscriberConfig")
+}
+
+func (a *Adapter) RestoreSubscriberConfig(ctx context.Context, snapshot *types.SubscriberSnapshot, targetPONPort string, targetONUID int) (*types.SubscriberResult, error) {
+	return nil, notImplemented("RestoreSubscriberConfig")
+}
+
+func (a *Adapter) ReplaceONU(ctx context.Context, subscriberID string, newSerial string) (*types.ReplaceResult, error) {
+	return nil, notImplemented("ReplaceONU")
+}
+
+func (a *Adapter) SoftSuspendSubscriber(ctx context.Context, subscriberID string, opts *types.SuspendOptions) (*types.SuspensionState, error) {
+	return nil, notImplemented("SoftSuspendSubscriber")
+}
+
+func (a *Adapter) GetSuspensionState(ctx context.Context, subscriberID string) (*types.SuspensionState, error) {
+	return nil, notImplemented("GetSuspensionState")
+}
+
+func (a *Adapter) MoveSubscriber(ctx context.Context, subscriberID string, targetPONPort string, targetONUID int) (*types.MoveResult, error) {
+	return nil, notImplemented("MoveSubscriber")
+}
+
+func (a *Adapter) CheckONUCompatibility(ctx context.Context, subscriberID string, newSerial string) (*types.CompatibilityReport, error) {
+	return nil, notImplemented("CheckONUCompatibility")
+}
+
+func (a *Adapter) AddONUToSubscriber(ctx context.Context, subscriberID string, binding model.ONUBinding, tier *model.ServiceTier) (*types.SubscriberResult, error) {
+	return nil, notImplemented("AddONUToSubscriber")
+}
+
+func (a *Adapter) RemoveONUFromSubscriber(ctx context.Context, subscriberID string, serial string) error {
+	return notImplemented("RemoveONUFromSubscriber")
+}
+
+func (a *Adapter) ListSubscriberONUs(ctx context.Context, subscriberID string) ([]model.ONUBinding, error) {
+	return nil, notImplemented("ListSubscriberONUs")
+}
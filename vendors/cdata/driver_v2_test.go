@@ -0,0 +1,229 @@
+package cdata
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+const onuListOutput = `Interface       ONU-ID  SN            State        Line-profile  VLAN  RxPower  Distance
+----------------------------------------------------------------------------------------
+gpon-olt_1/1/1  1       CDAT00000001  online       line_100M     100   -18.50   850
+gpon-olt_1/1/1  2       CDAT00000002  offline      line_50M      100   -        -
+gpon-olt_1/1/2  1       HWTC0011D168  deactivated  -             200   -        -
+`
+
+func TestGetONUList(t *testing.T) {
+	a := NewAdapter(cliMockDriver(map[string]string{"show gpon onu-list": onuListOutput}), newGPONConfig()).(*Adapter)
+	ctx := context.Background()
+
+	onus, err := a.GetONUList(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetONUList() error = %v", err)
+	}
+	if len(onus) != 3 {
+		t.Fatalf("GetONUList() = %d ONUs, want 3", len(onus))
+	}
+	first := onus[0]
+	if first.PONPort != "1/1/1" || first.ONUID != 1 || !first.IsOnline || first.LineProfile != "line_100M" ||
+		first.VLAN != 100 || first.RxPowerDBm != -18.5 || first.DistanceM != 850 {
+		t.Errorf("onus[0] = %+v", first)
+	}
+	if last := onus[2]; last.AdminState != "disabled" || last.LineProfile != "" || last.IsOnline {
+		t.Errorf("onus[2] = %+v", last)
+	}
+
+	tests := []struct {
+		name   string
+		filter *types.ONUFilter
+		want   int
+	}{
+		{"port", &types.ONUFilter{PONPort: "1/1/1"}, 2},
+		{"online", &types.ONUFilter{Status: "online"}, 1},
+		{"offline", &types.ONUFilter{Status: "offline"}, 2},
+		{"profile", &types.ONUFilter{Profile: "line_50M"}, 1},
+		{"serial", &types.ONUFilter{Serial: "hwtc"}, 1},
+		{"vlan", &types.ONUFilter{VLAN: 200}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onus, err := a.GetONUList(ctx, tt.filter)
+			if err != nil || len(onus) != tt.want {
+				t.Errorf("GetONUList() = %+v, %v; want %d ONUs", onus, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetONUList_EPON(t *testing.T) {
+	a := NewAdapter(cliMockDriver(map[string]string{
+		"show epon onu-list": "epon-olt_1/1/3  5  AA:BB:CC:DD:EE:FF  online  line_100M  100  -20.00  1200",
+	}), newEPONConfig()).(*Adapter)
+
+	onus, err := a.GetONUList(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetONUList() error = %v", err)
+	}
+	if len(onus) != 1 || onus[0].PONPort != "1/1/3" || onus[0].MAC != "AA:BB:CC:DD:EE:FF" {
+		t.Errorf("GetONUList() = %+v", onus)
+	}
+}
+
+func TestGetONUPower(t *testing.T) {
+	a := NewAdapter(cliMockDriver(map[string]string{
+		"show gpon onu-optical-info gpon-olt_1/1/1 1": "SN            : CDAT00000001\nRx power      : -18.50 dBm\n" +
+			"Tx power      : 2.10 dBm\nOLT Rx power  : -20.00 dBm\nDistance      : 850 m",
+		"show gpon onu-optical-info gpon-olt_1/1/1 2": "% ONU 2 is not online",
+	}), newGPONConfig()).(*Adapter)
+	ctx := context.Background()
+
+	power, err := a.GetONUPower(ctx, "1/1/1", 1)
+	if err != nil {
+		t.Fatalf("GetONUPower() error = %v", err)
+	}
+	if power.RxPowerDBm != -18.5 || power.TxPowerDBm != 2.1 || power.OLTRxDBm != -20 || power.DistanceM != 850 ||
+		power.Serial != "CDAT00000001" || !power.IsWithinSpec || power.RxLowThreshold != types.GPONRxLowThreshold {
+		t.Errorf("GetONUPower() = %+v", power)
+	}
+	if distance, err := a.GetONUDistance(ctx, "1/1/1", 1); err != nil || distance != 850 {
+		t.Errorf("GetONUDistance() = %d, %v", distance, err)
+	}
+	if _, err := a.GetONUPower(ctx, "1/1/1", 2); err == nil {
+		t.Error("GetONUPower(offline) error = nil")
+	}
+}
+
+func TestGetPONPower(t *testing.T) {
+	a := NewAdapter(cliMockDriver(map[string]string{
+		"show gpon olt-optical-info gpon-olt_1/1/1": "Interface   : gpon-olt_1/1/1\nTx power    : 4.50 dBm\nRx power    : -22.10 dBm\nTemperature : 38 C",
+	}), newGPONConfig()).(*Adapter)
+
+	power, err := a.GetPONPower(context.Background(), "1/1/1")
+	if err != nil {
+		t.Fatalf("GetPONPower() error = %v", err)
+	}
+	if power.PONPort != "1/1/1" || power.TxPowerDBm != 4.5 || power.RxPowerDBm != -22.1 || power.Temperature != 38 {
+		t.Errorf("GetPONPower() = %+v", power)
+	}
+}
+
+func TestGetAlarms(t *testing.T) {
+	a := NewAdapter(cliMockDriver(map[string]string{
+		"show alarm active": `Index  Severity  Alarm-Name  Source            Time                 Description
+1      Critical  ONU-LOS     gpon-olt_1/1/2:4  2024-01-15 10:30:00  ONU loss of signal
+2      Major     PON-LOS     gpon-olt_1/1/3    2024-01-15 10:31:00  PON port down
+3      Minor     FAN-FAIL    system            2024-01-15 10:32:00  Fan 2 failure`,
+	}), newGPONConfig()).(*Adapter)
+
+	alarms, err := a.GetAlarms(context.Background())
+	if err != nil {
+		t.Fatalf("GetAlarms() error = %v", err)
+	}
+	if len(alarms) != 3 {
+		t.Fatalf("GetAlarms() = %+v, want 3 alarms", alarms)
+	}
+	want := []struct{ severity, source, sourceID, message string }{
+		{"critical", "onu", "1/1/2:4", "ONU loss of signal"},
+		{"major", "port", "1/1/3", "PON port down"},
+		{"minor", "system", "", "Fan 2 failure"},
+	}
+	for i, w := range want {
+		got := alarms[i]
+		if got.Severity != w.severity || got.Source != w.source || got.SourceID != w.sourceID || got.Message != w.message {
+			t.Errorf("alarms[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+	if alarms[0].RaisedAt.Year() != 2024 {
+		t.Errorf("RaisedAt = %v", alarms[0].RaisedAt)
+	}
+}
+
+func TestGetOLTStatus(t *testing.T) {
+	a := NewAdapter(cliMockDriver(map[string]string{
+		"show system": "System Name       : OLT\nSystem Type       : FD1208S\nSerial Number     : CD123456\n" +
+			"Software Version  : V1.2.5\nUptime            : 26h0m0s\nCPU Usage         : 12%",
+		"show gpon onu-list": onuListOutput,
+		"show gpon port-state": `Interface       Admin    Oper  ONUs  Max  Description
+gpon-olt_1/1/1  enable   up    2     128  building-a
+gpon-olt_1/1/2  disable  down  0     128`,
+	}), newGPONConfig()).(*Adapter)
+
+	status, err := a.GetOLTStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetOLTStatus() error = %v", err)
+	}
+	if status.Vendor != "cdata" || status.Model != "FD1208S" || status.Firmware != "V1.2.5" || status.SerialNumber != "CD123456" ||
+		status.UptimeSeconds != 26*3600 || status.CPUPercent != 12 || status.TotalONUs != 3 || status.ActiveONUs != 1 {
+		t.Errorf("GetOLTStatus() = %+v", status)
+	}
+	if len(status.PONPorts) != 2 {
+		t.Fatalf("PONPorts = %+v", status.PONPorts)
+	}
+	if p := status.PONPorts[0]; p.Port != "1/1/1" || p.AdminState != "enabled" || p.ONUCount != 2 || p.Description != "building-a" {
+		t.Errorf("PONPorts[0] = %+v", p)
+	}
+	// The port table reports no ONUs on 1/1/2; the count comes from the
+	// ONU list
+	if p := status.PONPorts[1]; p.AdminState != "disabled" || p.OperState != "down" || p.ONUCount != 1 {
+		t.Errorf("PONPorts[1] = %+v", p)
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	a := NewAdapter(&testutil.MockDriver{CLIExec: mock}, newGPONConfig()).(*Adapter)
+
+	err := a.ApplyProfile(context.Background(), "1/1/1", 3, &types.ONUProfile{LineProfile: "line_200M", VLAN: 300, BandwidthUp: 50000, BandwidthDown: 200000})
+	if err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+	for _, want := range []string{
+		"interface gpon-olt_1/1/1",
+		"onu-profile 3 line line_200M service service_internet",
+		"onu-vlan 3 mode translate user-vlan 300 svlan 300",
+		"onu-ratelimit 3 upstream 50000 downstream 200000",
+		"commit",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("commands = %v, missing %q", mock.Commands, want)
+		}
+	}
+
+	if err := a.ApplyProfile(context.Background(), "1/1/1", 3, nil); err == nil {
+		t.Error("ApplyProfile(nil) error = nil")
+	}
+}
+
+func TestSetPortState(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	a := NewAdapter(&testutil.MockDriver{CLIExec: mock}, newGPONConfig()).(*Adapter)
+
+	if err := a.SetPortState(context.Background(), "1/1/2", false); err != nil {
+		t.Fatalf("SetPortState() error = %v", err)
+	}
+	want := []string{"configure terminal", "interface gpon-olt_1/1/2", "shutdown", "exit", "commit", "end"}
+	if !slices.Equal(mock.Commands, want) {
+		t.Errorf("commands = %v, want %v", mock.Commands, want)
+	}
+
+	mock.Commands = nil
+	if err := a.SetPortState(context.Background(), "1/1/2", true); err != nil {
+		t.Fatalf("SetPortState() error = %v", err)
+	}
+	if !slices.Contains(mock.Commands, "no shutdown") {
+		t.Errorf("commands = %v, want no shutdown", mock.Commands)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	c := NewAdapter(cliMockDriver(nil), newGPONConfig()).(*Adapter).Capabilities()
+	if !c.ONUList || !c.Optical || !c.Alarms || !c.OLTStatus || !c.ApplyProfile || !c.ONUDiscovery || !c.Ports {
+		t.Errorf("Capabilities() = %+v, want monitoring supported", c)
+	}
+	if c.OLTRestart || c.VLANs || c.BulkProvision || c.ConfigRestore {
+		t.Errorf("Capabilities() = %+v, want stubbed operations cleared", c)
+	}
+}
@@ -3,6 +3,8 @@ package cdata
 import (
 	"fmt"
 	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
 )
 
 // ErrorCode represents a normalized error code for C-Data errors
@@ -252,6 +254,30 @@ func (e *TranslatedError) Error() string {
 	return fmt.Sprintf("[%s] %s (action: %s)", e.Code, e.Human, e.Action)
 }
 
+// Unwrap returns the original CLI error
+func (e *TranslatedError) Unwrap() error {
+	return e.Original
+}
+
+// Is reports whether target is a *types.HumanError with the equivalent
+// code, so that errors.Is(err, types.ErrONUNotFound) matches C-Data errors
+func (e *TranslatedError) Is(target error) bool {
+	h, ok := target.(*types.HumanError)
+	return ok && h.Code != "" && h.Code == e.humanCode()
+}
+
+// humanCode returns the types.ErrCode* equivalent of the C-Data code
+func (e *TranslatedError) humanCode() string {
+	switch e.Code {
+	case ErrProfileMissing:
+		return types.ErrCodeProfileNotFound
+	case ErrConnRefuse:
+		return types.ErrCodeUnreachable
+	default:
+		return string(e.Code)
+	}
+}
+
 // translateError converts a raw CLI error into a human-readable error
 func (a *Adapter) translateError(err error) error {
 	if err == nil {
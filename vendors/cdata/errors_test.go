@@ -1,8 +1,11 @@
 package cdata
 
 import (
+	"errors"
 	"fmt"
 	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
 )
 
 func TestTranslateError_KnownPatterns(t *testing.T) {
@@ -193,3 +196,21 @@ func TestGetSuggestedAction(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslatedError_Is(t *testing.T) {
+	adapter := &Adapter{}
+	raw := fmt.Errorf("line profile not exist")
+	err := fmt.Errorf("provision: %w", adapter.translateError(raw))
+	if !errors.Is(err, types.ErrProfileNotFound) {
+		t.Error("expected errors.Is(err, types.ErrProfileNotFound)")
+	}
+	if errors.Is(err, types.ErrONUNotFound) {
+		t.Error("profile error should not match types.ErrONUNotFound")
+	}
+	if !errors.Is(err, raw) {
+		t.Error("expected the original error in the chain")
+	}
+	if err := adapter.translateError(fmt.Errorf("Connection refused")); !errors.Is(err, types.ErrUnreachable) {
+		t.Errorf("expected %v to match types.ErrUnreachable", err)
+	}
+}
@@ -0,0 +1,36 @@
+package cdata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+)
+
+// TestGolden runs the parsers over the captured output in testdata/golden.
+// Timestamps the parsers stamp with time.Now are cleared, as is the raw
+// output the parsers copy into metadata.
+func TestGolden(t *testing.T) {
+	a := &Adapter{config: newGPONConfig()}
+	testutil.RunGolden(t, "testdata/golden", map[string]testutil.GoldenParser{
+		"parseAutofindOutput": func(_ *testing.T, in []byte) any {
+			discoveries := a.parseAutofindOutput(string(in))
+			for i := range discoveries {
+				discoveries[i].DiscoveredAt = time.Time{}
+			}
+			return discoveries
+		},
+		"parseONUStatus": func(_ *testing.T, in []byte) any {
+			status := a.parseONUStatus(string(in), "onu-1/1/1-1")
+			status.LastActivity = time.Time{}
+			delete(status.Metadata, "cli_output")
+			return status
+		},
+		"parseONUStats": func(_ *testing.T, in []byte) any {
+			stats := a.parseONUStats(string(in))
+			stats.Timestamp = time.Time{}
+			delete(stats.Metadata, "cli_output")
+			return stats
+		},
+	})
+}
@@ -0,0 +1,91 @@
+package cdata
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var (
+	_ types.ONUEthPortManager = (*Adapter)(nil)
+	_ types.ONUCATVManager    = (*Adapter)(nil)
+)
+
+// SetONUEthPortState enables or disables an ONU Ethernet port with
+// onu-port.
+func (a *Adapter) SetONUEthPortState(ctx context.Context, ponPort string, onuID, port int, enabled bool) error {
+	state := "disable"
+	if enabled {
+		state = "enable"
+	}
+	return a.execONUCommands(ctx, ponPort, fmt.Sprintf("onu-port %d eth %d state %s", onuID, port, state))
+}
+
+// SetONUCATVState enables or disables the CATV RF port of a combo ONU with
+// onu-port catv.
+func (a *Adapter) SetONUCATVState(ctx context.Context, ponPort string, onuID int, enabled bool) error {
+	return a.execONUCommands(ctx, ponPort, catvCommand(onuID, enabled))
+}
+
+// catvCommand switches the CATV RF port of an ONU
+func catvCommand(onuID int, enabled bool) string {
+	state := "disable"
+	if enabled {
+		state = "enable"
+	}
+	return fmt.Sprintf("onu-port %d catv state %s", onuID, state)
+}
+
+// catvCommands returns the command applying the CATV flag of a subscriber,
+// if it is set
+func catvCommands(onuID int, spec *model.SubscriberSpec) []string {
+	if spec.CATV == nil {
+		return nil
+	}
+	return []string{catvCommand(onuID, *spec.CATV)}
+}
+
+// SetONUEthPortVLAN sets the VLAN mode of an ONU Ethernet port with
+// onu-port vlan-mode
+func (a *Adapter) SetONUEthPortVLAN(ctx context.Context, ponPort string, onuID, port int, vlan *types.ONUPortVLAN) error {
+	if err := vlan.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "cdata"}
+	}
+	cmd := fmt.Sprintf("onu-port %d eth %d vlan-mode %s", onuID, port, vlan.Mode)
+	switch vlan.Mode {
+	case types.ONUPortVLANAccess:
+		cmd += fmt.Sprintf(" vlan %d", vlan.VLAN)
+	case types.ONUPortVLANTrunk:
+		vlans := make([]string, len(vlan.TrunkVLANs))
+		for i, v := range vlan.TrunkVLANs {
+			vlans[i] = strconv.Itoa(v)
+		}
+		cmd += " allowed-vlan " + strings.Join(vlans, ",")
+		if vlan.VLAN > 0 {
+			cmd += fmt.Sprintf(" native-vlan %d", vlan.VLAN)
+		}
+	}
+	return a.execONUCommands(ctx, ponPort, cmd)
+}
+
+// execONUCommands runs ONU or port commands in the OLT interface of
+// ponPort and commits them
+func (a *Adapter) execONUCommands(ctx context.Context, ponPort string, cmds ...string) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	iface := fmt.Sprintf("interface gpon-olt_%s", ponPort)
+	if a.detectPONType() != "gpon" {
+		iface = fmt.Sprintf("interface epon-olt_%s", ponPort)
+	}
+	commands := append([]string{"configure terminal", iface}, cmds...)
+	commands = append(commands, "exit", "commit", "end")
+	if _, err := a.cliExecutor.ExecCommands(ctx, commands); err != nil {
+		return a.translateError(err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package cdata
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestSetONUEthPort(t *testing.T) {
+	ctx := context.Background()
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, newGPONConfig()).(*Adapter)
+
+	if err := adapter.SetONUEthPortState(ctx, "1/1/1", 3, 2, false); err != nil {
+		t.Fatalf("SetONUEthPortState() error = %v", err)
+	}
+	vlan := &types.ONUPortVLAN{Mode: types.ONUPortVLANTrunk, VLAN: 10, TrunkVLANs: []int{100, 200}}
+	if err := adapter.SetONUEthPortVLAN(ctx, "1/1/1", 3, 2, vlan); err != nil {
+		t.Fatalf("SetONUEthPortVLAN() error = %v", err)
+	}
+	for _, want := range []string{
+		"interface gpon-olt_1/1/1",
+		"onu-port 3 eth 2 state disable",
+		"onu-port 3 eth 2 vlan-mode trunk allowed-vlan 100,200 native-vlan 10",
+		"commit",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	if err := adapter.SetONUEthPortVLAN(ctx, "1/1/1", 3, 2, &types.ONUPortVLAN{Mode: types.ONUPortVLANAccess}); err == nil {
+		t.Error("access mode without VLAN should be rejected")
+	}
+}
+
+func TestSetONUCATVState(t *testing.T) {
+	ctx := context.Background()
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, newGPONConfig()).(*Adapter)
+
+	if err := adapter.SetONUCATVState(ctx, "1/1/1", 3, true); err != nil {
+		t.Fatalf("SetONUCATVState() error = %v", err)
+	}
+	if !slices.Contains(mock.Commands, "onu-port 3 catv state enable") {
+		t.Errorf("commands = %v", mock.Commands)
+	}
+
+	// The subscriber flag is applied by UpdateSubscriber
+	mock.Commands = nil
+	sub := newSubscriber("CDAT12345678", "1/1/2", 100, "5", "router")
+	disabled := false
+	sub.Spec.CATV = &disabled
+	if err := adapter.UpdateSubscriber(ctx, sub, newTier(50, 100, "", "")); err != nil {
+		t.Fatalf("UpdateSubscriber() error = %v", err)
+	}
+	if !slices.Contains(mock.Commands, "onu-port 5 catv state disable") {
+		t.Errorf("commands = %v", mock.Commands)
+	}
+
+	// Without the flag the CATV port is left as it is
+	mock.Commands = nil
+	sub.Spec.CATV = nil
+	if err := adapter.UpdateSubscriber(ctx, sub, newTier(50, 100, "", "")); err != nil {
+		t.Fatalf("UpdateSubscriber() error = %v", err)
+	}
+	if slices.ContainsFunc(mock.Commands, func(c string) bool { return strings.Contains(c, "catv") }) {
+		t.Errorf("commands = %v", mock.Commands)
+	}
+}
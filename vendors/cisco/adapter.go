@@ -3,6 +3,7 @@ package cisco
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -23,7 +24,8 @@ type Adapter struct {
 }
 
 // NewAdapter creates a new Cisco adapter
-func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig) types.Driver {
+func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig, opts ...types.Option) types.Driver {
+	config.Apply(opts...)
 	adapter := &Adapter{
 		baseDriver: baseDriver,
 		config:     config,
@@ -49,20 +51,41 @@ func (a *Adapter) IsConnected() bool {
 	return a.baseDriver.IsConnected()
 }
 
+// DriverMetrics implements types.MetricsProvider
+func (a *Adapter) DriverMetrics() types.DriverMetrics {
+	return types.CollectMetrics(a.baseDriver)
+}
+
+// Capabilities implements types.CapabilityReporter
+func (a *Adapter) Capabilities() types.Capabilities {
+	return types.DetectCapabilities(a)
+}
+
 // CreateSubscriber provisions a subscriber using Cisco IOS-XR YANG models
 func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (*types.SubscriberResult, error) {
 	if a.netconfExecutor == nil {
 		return nil, fmt.Errorf("NETCONF executor not available - Cisco requires NETCONF driver")
 	}
+	if err := subscriber.Spec.ValidateQinQ(); err != nil {
+		return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "cisco"}
+	}
 
 	// Extract subscriber parameters
 	params := a.extractSubscriberParams(subscriber, tier)
 
 	// Build the interface and subscriber configuration
-	config := a.buildSubscriberConfig(params)
+	config, err := a.buildSubscriberConfig(params)
+	if err != nil {
+		return nil, netconf.AsHumanError("cisco", err)
+	}
+
+	// Check the generated configuration before touching the datastore
+	if err := a.validateConfig(ctx, config); err != nil {
+		return nil, err
+	}
 
 	// Apply configuration via NETCONF edit-config
-	err := a.netconfExecutor.EditConfig(ctx, "", config,
+	err = a.netconfExecutor.EditConfig(ctx, "", config,
 		netconf.WithMerge(),
 		netconf.WithRollbackOnError(),
 	)
@@ -93,12 +116,30 @@ func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscr
 	return result, nil
 }
 
+// validateConfig runs <validate> on generated configuration when the device
+// supports it. Device-side rejections are returned as *types.HumanError.
+func (a *Adapter) validateConfig(ctx context.Context, config string) error {
+	if !a.netconfExecutor.HasCapability(netconf.CapValidate) {
+		return nil
+	}
+	err := a.netconfExecutor.ValidateConfig(ctx, config)
+	if err == nil {
+		return nil
+	}
+	var verr *netconf.ValidationError
+	if errors.As(err, &verr) {
+		return verr.ToHumanError("cisco")
+	}
+	return fmt.Errorf("Cisco subscriber validation failed: %w", err)
+}
+
 // subscriberParams holds parsed subscriber parameters for Cisco
 type subscriberParams struct {
 	NodeName        string
 	ParentInterface string
 	InterfaceName   string
 	VLAN            int
+	SVLAN           int
 	MAC             string
 	IPv4Address     string
 	IPv6Address     string
@@ -114,6 +155,7 @@ type subscriberParams struct {
 func (a *Adapter) extractSubscriberParams(subscriber *model.Subscriber, tier *model.ServiceTier) *subscriberParams {
 	params := &subscriberParams{
 		VLAN:        subscriber.Spec.VLAN,
+		SVLAN:       subscriber.Spec.GetSVLAN(),
 		MAC:         subscriber.Spec.MACAddress,
 		IPv4Address: subscriber.Spec.IPAddress,
 		IPv6Address: subscriber.Spec.IPv6Address,
@@ -182,52 +224,29 @@ func (a *Adapter) extractSubscriberParams(subscriber *model.Subscriber, tier *mo
 }
 
 // buildSubscriberConfig builds Cisco IOS-XR YANG XML for subscriber provisioning
-func (a *Adapter) buildSubscriberConfig(params *subscriberParams) string {
-	// Build sub-interface configuration with IPoE subscriber attachment
-	return fmt.Sprintf(`
-<interface-configurations xmlns="http://cisco.com/ns/yang/Cisco-IOS-XR-ifmgr-cfg">
-  <interface-configuration>
-    <active>act</active>
-    <interface-name>%s</interface-name>
-    <interface-mode-non-physical>l2-transport</interface-mode-non-physical>
-    <description>Nanoncore subscriber VLAN %d</description>
-    <vlan-sub-configuration xmlns="http://cisco.com/ns/yang/Cisco-IOS-XR-l2-eth-infra-cfg">
-      <vlan-identifier>
-        <vlan-type>vlan-type-dot1q</vlan-type>
-        <first-tag>%d</first-tag>
-      </vlan-identifier>
-    </vlan-sub-configuration>
-    <ipsub xmlns="http://cisco.com/ns/yang/Cisco-IOS-XR-subscriber-ipsub-cfg">
-      <subscriber>
-        <ipv4>
-          <l2-connected>
-            <initiator>
-              <dhcp/>
-            </initiator>
-          </l2-connected>
-        </ipv4>
-      </subscriber>
-    </ipsub>
-    <qos xmlns="http://cisco.com/ns/yang/Cisco-IOS-XR-qos-ma-cfg">
-      <input>
-        <service-policy>
-          <service-policy-name>%s</service-policy-name>
-        </service-policy>
-      </input>
-      <output>
-        <service-policy>
-          <service-policy-name>%s</service-policy-name>
-        </service-policy>
-      </output>
-    </qos>
-  </interface-configuration>
-</interface-configurations>`,
-		params.InterfaceName,
-		params.VLAN,
-		params.VLAN,
-		params.PolicyInput,
-		params.PolicyOutput,
-	)
+func (a *Adapter) buildSubscriberConfig(params *subscriberParams) (string, error) {
+	// Q-in-Q sub-interfaces match the S-VLAN as first tag and the
+	// subscriber VLAN as second tag
+	vlans := &vlanSubConfiguration{VLANType: "vlan-type-dot1q", FirstTag: params.VLAN}
+	if params.SVLAN > 0 {
+		vlans.FirstTag, vlans.SecondTag = params.SVLAN, params.VLAN
+	}
+
+	// Sub-interface configuration with IPoE subscriber attachment
+	return netconf.MarshalConfig(&interfaceConfigurations{
+		InterfaceConfiguration: []interfaceConfiguration{{
+			Active:                   "act",
+			InterfaceName:            params.InterfaceName,
+			InterfaceModeNonPhysical: "l2-transport",
+			Description:              fmt.Sprintf("Nanoncore subscriber VLAN %d", params.VLAN),
+			VLANSubConfiguration:     vlans,
+			IPSub:                    &ipSub{},
+			QoS: &interfaceQoS{
+				Input:  params.PolicyInput,
+				Output: params.PolicyOutput,
+			},
+		}},
+	})
 }
 
 // UpdateSubscriber updates subscriber configuration
@@ -235,10 +254,16 @@ func (a *Adapter) UpdateSubscriber(ctx context.Context, subscriber *model.Subscr
 	if a.netconfExecutor == nil {
 		return fmt.Errorf("NETCONF executor not available")
 	}
+	if err := subscriber.Spec.ValidateQinQ(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "cisco"}
+	}
 
 	// For Cisco, update is same as create with merge operation
 	params := a.extractSubscriberParams(subscriber, tier)
-	config := a.buildSubscriberConfig(params)
+	config, err := a.buildSubscriberConfig(params)
+	if err != nil {
+		return netconf.AsHumanError("cisco", err)
+	}
 
 	return a.netconfExecutor.EditConfig(ctx, "", config,
 		netconf.WithMerge(),
@@ -316,10 +341,19 @@ func (a *Adapter) GetSubscriberStatus(ctx context.Context, subscriberID string)
 	nodeName := a.getNodeName()
 	interfaceName := a.parseSubscriberInterface(subscriberID)
 
-	// Query subscriber session
+	// Query subscriber session; with XPath only the parsed leaves are returned
 	filter := fmt.Sprintf(GetSubscriberSessionFilterXML, nodeName, subscriberID)
+	xpath := netconf.XPathFilter{
+		Select: netconf.XPathLeaves(
+			fmt.Sprintf(GetSubscriberSessionXPath, netconf.XPathLiteral(nodeName), netconf.XPathLiteral(subscriberID)),
+			SubscriberSessionLeaves...),
+		Namespaces: map[string]string{"sm": NSCiscoSubSession},
+	}
 
-	response, err := a.netconfExecutor.Get(ctx, filter)
+	response, err := a.netconfExecutor.Get(ctx, filter,
+		netconf.WithXPath(xpath),
+		netconf.WithDefaults(netconf.WithDefaultsReportAll),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get subscriber status: %w", err)
 	}
@@ -359,7 +393,7 @@ func (a *Adapter) GetSubscriberStats(ctx context.Context, subscriberID string) (
 	// Query interface statistics
 	filter := fmt.Sprintf(GetInterfaceStatsFilterXML, interfaceName)
 
-	response, err := a.netconfExecutor.Get(ctx, filter)
+	response, err := a.netconfExecutor.Get(ctx, filter, netconf.WithDefaults(netconf.WithDefaultsReportAll))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get subscriber stats: %w", err)
 	}
@@ -660,7 +694,7 @@ func (a *Adapter) GetSubscriberSummary(ctx context.Context) (*SubscriberSummary,
 	nodeName := a.getNodeName()
 	filter := fmt.Sprintf(GetSubscriberSummaryFilterXML, nodeName)
 
-	response, err := a.netconfExecutor.Get(ctx, filter)
+	response, err := a.netconfExecutor.Get(ctx, filter, netconf.WithDefaults(netconf.WithDefaultsReportAll))
 	if err != nil {
 		return nil, err
 	}
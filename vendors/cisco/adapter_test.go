@@ -2,9 +2,12 @@ package cisco
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/nanoncore/nano-southbound/drivers/netconf"
 	"github.com/nanoncore/nano-southbound/model"
 	"github.com/nanoncore/nano-southbound/testutil"
 	"github.com/nanoncore/nano-southbound/types"
@@ -353,6 +356,37 @@ func TestExtractSubscriberParams_NilTier(t *testing.T) {
 	}
 }
 
+func TestBuildSubscriberConfig_QinQ(t *testing.T) {
+	config := testutil.NewTestEquipmentConfig(types.VendorCisco, "10.0.0.1")
+	a := &Adapter{config: config}
+
+	sub := testutil.NewTestSubscriber("SN004", "0/1", 100)
+	svlan := 1000
+	sub.Spec.SVLAN = &svlan
+
+	params := a.extractSubscriberParams(sub, nil)
+	if params.SVLAN != 1000 {
+		t.Errorf("SVLAN = %d, want 1000", params.SVLAN)
+	}
+	xml, err := a.buildSubscriberConfig(params)
+	if err != nil {
+		t.Fatalf("buildSubscriberConfig() error = %v", err)
+	}
+	for _, want := range []string{"<first-tag>1000</first-tag>", "<second-tag>100</second-tag>"} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("config missing %s:\n%s", want, xml)
+		}
+	}
+
+	svlan = 5000
+	na, _, _ := newTestAdapter(t)
+	_, err = na.CreateSubscriber(context.Background(), sub, nil)
+	var he *types.HumanError
+	if !errors.As(err, &he) || he.Code != types.ErrCodeValidationFailed {
+		t.Fatalf("expected validation error for invalid svlan, got %v", err)
+	}
+}
+
 func TestExtractSubscriberParams_TierAnnotations(t *testing.T) {
 	config := testutil.NewTestEquipmentConfig(types.VendorCisco, "10.0.0.1")
 	a := &Adapter{config: config}
@@ -604,6 +638,57 @@ func TestCreateSubscriber_EditConfigFails(t *testing.T) {
 	}
 }
 
+func TestCreateSubscriber_ValidationFails(t *testing.T) {
+	a, mockNE, _ := newTestAdapter(t)
+	mockNE.Capabilities = append(mockNE.Capabilities, netconf.CapValidate)
+	mockNE.ValidateError = &netconf.ValidationError{Errors: []netconf.RPCError{{
+		Type:    "application",
+		Tag:     "invalid-value",
+		Message: "unknown dynamic-template",
+	}}}
+	ctx := context.Background()
+
+	sub := testutil.NewTestSubscriber("SN103", "0/1", 100)
+	tier := testutil.NewTestServiceTier(10, 50)
+
+	_, err := a.CreateSubscriber(ctx, sub, tier)
+	var he *types.HumanError
+	if !errors.As(err, &he) {
+		t.Fatalf("expected *types.HumanError, got %v", err)
+	}
+	if he.Code != types.ErrCodeValidationFailed || he.Vendor != "cisco" {
+		t.Errorf("HumanError = %+v", he)
+	}
+	for _, call := range mockNE.Calls {
+		if call == "EditConfig" {
+			t.Error("EditConfig should not be called after validation fails")
+		}
+	}
+}
+
+func TestCreateSubscriber_InvalidVLAN(t *testing.T) {
+	a, mockNE, _ := newTestAdapter(t)
+	ctx := context.Background()
+
+	sub := testutil.NewTestSubscriber("SN104", "0/1", 5000)
+	tier := testutil.NewTestServiceTier(10, 50)
+
+	_, err := a.CreateSubscriber(ctx, sub, tier)
+	var he *types.HumanError
+	if !errors.As(err, &he) {
+		t.Fatalf("expected *types.HumanError, got %v", err)
+	}
+	if he.Code != types.ErrCodeValidationFailed {
+		t.Errorf("Code = %s, want %s", he.Code, types.ErrCodeValidationFailed)
+	}
+	if !strings.Contains(he.Message, "first-tag") {
+		t.Errorf("Message should name the offending leaf: %s", he.Message)
+	}
+	if len(mockNE.Calls) != 0 {
+		t.Errorf("no NETCONF calls expected for invalid config, got %v", mockNE.Calls)
+	}
+}
+
 func TestUpdateSubscriber_Success(t *testing.T) {
 	a, _, _ := newTestAdapter(t)
 	ctx := context.Background()
@@ -960,3 +1045,71 @@ func TestGetSystemInfo_NoNETCONF(t *testing.T) {
 		t.Fatal("expected error when NETCONF executor is nil")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Configuration backup and restore
+// ---------------------------------------------------------------------------
+
+func TestBackupConfig_ToURL(t *testing.T) {
+	a, mockNE, _ := newTestAdapter(t)
+
+	backup, err := a.BackupConfig(context.Background(), "scp://backup/bng1.xml")
+	if err != nil {
+		t.Fatalf("BackupConfig failed: %v", err)
+	}
+	if backup.Vendor != "cisco" || backup.Destination != "scp://backup/bng1.xml" {
+		t.Errorf("backup = %+v", backup)
+	}
+	if len(mockNE.Calls) != 1 || mockNE.Calls[0] != "CopyConfig:running->scp://backup/bng1.xml" {
+		t.Errorf("Calls = %v", mockNE.Calls)
+	}
+}
+
+func TestRestoreConfig_Candidate(t *testing.T) {
+	a, mockNE, _ := newTestAdapter(t)
+	mockNE.Capabilities = append(mockNE.Capabilities, netconf.CapCandidate)
+
+	if err := a.RestoreConfig(context.Background(), "file:///harddisk:/bng1.xml"); err != nil {
+		t.Fatalf("RestoreConfig failed: %v", err)
+	}
+	want := []string{"Lock:candidate", "CopyConfig:file:///harddisk:/bng1.xml->candidate", "Commit", "Unlock:candidate"}
+	if fmt.Sprint(mockNE.Calls) != fmt.Sprint(want) {
+		t.Errorf("Calls = %v, want %v", mockNE.Calls, want)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Bulk provisioning
+// ---------------------------------------------------------------------------
+
+func TestBulkCreateSubscribers(t *testing.T) {
+	a, mockNE, _ := newTestAdapter(t)
+	tier := testutil.NewTestServiceTier(10, 50)
+
+	requests := []types.BulkSubscriberRequest{
+		{Subscriber: testutil.NewTestSubscriber("SN301", "0/1", 301), Tier: tier},
+		{Subscriber: testutil.NewTestSubscriber("SN302", "0/1", 5000), Tier: tier},
+		{Subscriber: testutil.NewTestSubscriber("SN303", "0/1", 303), Tier: tier},
+	}
+
+	result, err := a.BulkCreateSubscribers(context.Background(), requests)
+	if err != nil {
+		t.Fatalf("BulkCreateSubscribers failed: %v", err)
+	}
+	if result.Succeeded != 2 || result.Failed != 1 {
+		t.Fatalf("Succeeded=%d Failed=%d, want 2/1", result.Succeeded, result.Failed)
+	}
+	if r := result.Results[1]; r.Success || r.ErrorCode != types.ErrCodeValidationFailed {
+		t.Errorf("Results[1] = %+v", r)
+	}
+
+	edits := 0
+	for _, call := range mockNE.Calls {
+		if call == "EditConfig" {
+			edits++
+		}
+	}
+	if edits != 1 {
+		t.Errorf("EditConfig calls = %d, want 1 for the whole batch", edits)
+	}
+}
@@ -0,0 +1,29 @@
+package cisco
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/drivers/netconf"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.ConfigBackupManager = (*Adapter)(nil)
+
+// BackupConfig saves the running configuration to destination via
+// copy-config, or returns it inline when destination is empty
+func (a *Adapter) BackupConfig(ctx context.Context, destination string) (*types.ConfigBackup, error) {
+	if a.netconfExecutor == nil {
+		return nil, fmt.Errorf("NETCONF executor not available")
+	}
+	return netconf.BackupConfig(ctx, a.netconfExecutor, "cisco", destination)
+}
+
+// RestoreConfig loads the configuration stored at the source URL.
+// IOS-XR stages the restore in the candidate datastore and commits it.
+func (a *Adapter) RestoreConfig(ctx context.Context, source string) error {
+	if a.netconfExecutor == nil {
+		return fmt.Errorf("NETCONF executor not available")
+	}
+	return netconf.RestoreConfig(ctx, a.netconfExecutor, "cisco", source)
+}
@@ -0,0 +1,80 @@
+package cisco
+
+import (
+	"encoding/xml"
+
+	"github.com/nanoncore/nano-southbound/drivers/netconf"
+)
+
+// IOS-XR native configuration models used for subscriber provisioning.
+// Field tags mirror the YANG schema; Validate enforces the constraints the
+// router would otherwise reject at commit.
+
+// interfaceConfigurations is Cisco-IOS-XR-ifmgr-cfg:interface-configurations
+type interfaceConfigurations struct {
+	XMLName                xml.Name                 `xml:"http://cisco.com/ns/yang/Cisco-IOS-XR-ifmgr-cfg interface-configurations"`
+	InterfaceConfiguration []interfaceConfiguration `xml:"interface-configuration"`
+}
+
+type interfaceConfiguration struct {
+	Active                   string                `xml:"active"`
+	InterfaceName            string                `xml:"interface-name"`
+	InterfaceModeNonPhysical string                `xml:"interface-mode-non-physical,omitempty"`
+	Description              string                `xml:"description,omitempty"`
+	VLANSubConfiguration     *vlanSubConfiguration `xml:"http://cisco.com/ns/yang/Cisco-IOS-XR-l2-eth-infra-cfg vlan-sub-configuration"`
+	IPSub                    *ipSub                `xml:"http://cisco.com/ns/yang/Cisco-IOS-XR-subscriber-ipsub-cfg ipsub"`
+	QoS                      *interfaceQoS         `xml:"http://cisco.com/ns/yang/Cisco-IOS-XR-qos-ma-cfg qos"`
+}
+
+type vlanSubConfiguration struct {
+	VLANType  string `xml:"vlan-identifier>vlan-type"`
+	FirstTag  int    `xml:"vlan-identifier>first-tag"`
+	SecondTag int    `xml:"vlan-identifier>second-tag,omitempty"`
+}
+
+type ipSub struct {
+	DHCPInitiator netconf.Empty `xml:"subscriber>ipv4>l2-connected>initiator>dhcp"`
+}
+
+type interfaceQoS struct {
+	Input  string `xml:"input>service-policy>service-policy-name"`
+	Output string `xml:"output>service-policy>service-policy-name"`
+}
+
+// Validate checks mandatory leaves and ranges
+func (c *interfaceConfigurations) Validate() error {
+	for _, ic := range c.InterfaceConfiguration {
+		if err := ic.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ic *interfaceConfiguration) validate() error {
+	const base = "/interface-configurations/interface-configuration"
+	err := netconf.FirstError(
+		netconf.RequireLeaf(base+"/active", ic.Active),
+		netconf.RequireLeaf(base+"/interface-name", ic.InterfaceName),
+	)
+	if err != nil {
+		return err
+	}
+	if ic.VLANSubConfiguration != nil {
+		if err := netconf.CheckVLANID(base+"/vlan-sub-configuration/vlan-identifier/first-tag", ic.VLANSubConfiguration.FirstTag); err != nil {
+			return err
+		}
+		if second := ic.VLANSubConfiguration.SecondTag; second != 0 {
+			if err := netconf.CheckVLANID(base+"/vlan-sub-configuration/vlan-identifier/second-tag", second); err != nil {
+				return err
+			}
+		}
+	}
+	if ic.QoS != nil {
+		return netconf.FirstError(
+			netconf.RequireLeaf(base+"/qos/input/service-policy/service-policy-name", ic.QoS.Input),
+			netconf.RequireLeaf(base+"/qos/output/service-policy/service-policy-name", ic.QoS.Output),
+		)
+	}
+	return nil
+}
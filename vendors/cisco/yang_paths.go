@@ -279,6 +279,18 @@ const GetSubscriberSessionFilterXML = `
   </nodes>
 </subscriber-session-mon>`
 
+// GetSubscriberSessionXPath locates one subscriber session for XPath
+// filtering; %s are XPath literals for the node name and session ID.
+// The "sm" prefix is bound to NSCiscoSubSession.
+const GetSubscriberSessionXPath = "/sm:subscriber-session-mon/sm:nodes/sm:node[sm:node-name=%s]/sm:session-ids/sm:session-id[sm:session-id=%s]"
+
+// SubscriberSessionLeaves are the session leaves read by GetSubscriberStatus
+var SubscriberSessionLeaves = []string{
+	"sm:subscriber-label", "sm:state", "sm:mac-address", "sm:ipv4-address",
+	"sm:ipv6-address", "sm:interface-name", "sm:outer-vlan", "sm:up-time",
+	"sm:accounting-session-id", "sm:session-type",
+}
+
 // GetSubscriberSummaryFilterXML is the filter for subscriber summary
 const GetSubscriberSummaryFilterXML = `
 <subscriber-session-mon xmlns="http://cisco.com/ns/yang/Cisco-IOS-XR-subscriber-session-mon-oper">
@@ -0,0 +1,63 @@
+package common
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// downTimeLayouts are the time formats of last down times, with or without
+// a UTC offset
+var downTimeLayouts = []string{
+	"2006-01-02 15:04:05-07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+}
+
+// ParseLastDown sets the last down cause and offline time of an ONU from
+// the "Last down cause" and "Last down time" (or "Last offline ...") lines
+// of an ONU info output. Times without a UTC offset are in local time. It
+// reports whether a cause or a time was found.
+func ParseLastDown(onu *types.ONUInfo, output string) bool {
+	found := false
+	for _, line := range strings.Split(StripANSI(output), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if !strings.HasPrefix(key, "last down") && !strings.HasPrefix(key, "last offline") {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(key, "cause"), strings.HasSuffix(key, "reason"):
+			if cause := types.NormalizeDownCause(value); cause != "" {
+				onu.LastDownCause = cause
+				if onu.Metadata == nil {
+					onu.Metadata = map[string]interface{}{}
+				}
+				onu.Metadata["last_down_cause"] = value
+				found = true
+			}
+		case strings.HasSuffix(key, "time"):
+			if at, ok := parseDownTime(value); ok {
+				onu.LastOfflineTime = at
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// parseDownTime parses a last down time in one of downTimeLayouts
+func parseDownTime(value string) (time.Time, bool) {
+	for _, layout := range downTimeLayouts {
+		if at, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return at, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestParseLastDown(t *testing.T) {
+	output := "  Run state               : offline\n" +
+		"  Last down cause         : dying-gasp\n" +
+		"  Last up time            : 2024-01-15 08:02:11+08:00\n" +
+		"  Last down time          : 2024-01-15 10:20:11+08:00\n"
+	onu := &types.ONUInfo{}
+	if !ParseLastDown(onu, output) {
+		t.Fatal("ParseLastDown() found nothing")
+	}
+	if onu.LastDownCause != types.DownCauseDyingGasp || onu.Metadata["last_down_cause"] != "dying-gasp" {
+		t.Errorf("LastDownCause = %q, metadata %v", onu.LastDownCause, onu.Metadata)
+	}
+	if want := time.Date(2024, 1, 15, 2, 20, 11, 0, time.UTC); !onu.LastOfflineTime.Equal(want) {
+		t.Errorf("LastOfflineTime = %v, want %v", onu.LastOfflineTime, want)
+	}
+
+	onu = &types.ONUInfo{}
+	if !ParseLastDown(onu, "Last Offline Reason: LOS\nLast Offline Time: 2024/03/02 07:15:00\n") {
+		t.Fatal("ParseLastDown() found nothing")
+	}
+	if onu.LastDownCause != types.DownCauseLOS || !onu.LastOfflineTime.Equal(time.Date(2024, 3, 2, 7, 15, 0, 0, time.Local)) {
+		t.Errorf("ParseLastDown() = %q, %v", onu.LastDownCause, onu.LastOfflineTime)
+	}
+
+	// An ONU that never went down
+	onu = &types.ONUInfo{}
+	if ParseLastDown(onu, "  Last down cause         : -\n  Last down time          : -\n") || onu.Metadata != nil {
+		t.Errorf("ParseLastDown() = %+v", onu)
+	}
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// reCounterLine matches a counter of statistics output, "name : value" or
+// "name    value", e.g. "Upstream FEC uncorrected codewords : 12"
+var reCounterLine = regexp.MustCompile(`^\s*([A-Za-z][^:]*?)\s*(?::|\s{2,})\s*(\d+(?:\.\d+)?(?:[eE][-+]?\d+)?)\s*$`)
+
+// reBER matches a BER counter name
+var reBER = regexp.MustCompile(`\bber\b|bit error rate`)
+
+// ParseLineQuality reads the upstream BIP and FEC counters and BER of PON
+// port or ONU line quality output. Counters are recognized by name;
+// downstream counters and byte counts are skipped. found is false if the
+// output has none of them.
+func ParseLineQuality(output string) (q *types.LineQuality, found bool) {
+	q = &types.LineQuality{}
+	for _, line := range strings.Split(StripANSI(output), "\n") {
+		m := reCounterLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name := strings.ToLower(m[1])
+		if strings.Contains(name, "downstream") || strings.HasPrefix(name, "ds ") || strings.Contains(name, "byte") {
+			continue
+		}
+		if reBER.MatchString(name) {
+			if v, err := strconv.ParseFloat(m[2], 64); err == nil {
+				q.BER, found = v, true
+			}
+			continue
+		}
+		v, err := strconv.ParseUint(m[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch {
+		case strings.Contains(name, "bip"):
+			q.BIPErrors = v
+		case strings.Contains(name, "fec") && strings.Contains(name, "uncorrect"):
+			q.FECUncorrectable = v
+		case strings.Contains(name, "fec") && strings.Contains(name, "correct"):
+			q.FECCorrected = v
+		case strings.Contains(name, "fec") && (strings.Contains(name, "total") || strings.Contains(name, "received")):
+			q.FECTotal = v
+		default:
+			continue
+		}
+		found = true
+	}
+	return q, found
+}
@@ -0,0 +1,31 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestParseLineQuality(t *testing.T) {
+	output := `  -----------------------------------------------------------
+  Upstream frame BIP error count          : 37
+  Downstream frame BIP error count        : 5
+  Upstream FEC corrected codewords        : 1200
+  Upstream FEC uncorrected codewords      : 3
+  Upstream FEC total codewords            : 9000000
+  Upstream FEC corrected bytes            : 4100
+  Upstream BER                            : 1.2e-09
+  -----------------------------------------------------------`
+	q, found := ParseLineQuality(output)
+	want := types.LineQuality{BIPErrors: 37, FECCorrected: 1200, FECUncorrectable: 3, FECTotal: 9000000, BER: 1.2e-9}
+	if !found || *q != want {
+		t.Errorf("ParseLineQuality() = %+v, %v, want %+v", q, found, want)
+	}
+	if r := q.UncorrectableRatio(); r < 3.3e-7 || r > 3.4e-7 {
+		t.Errorf("UncorrectableRatio() = %v", r)
+	}
+
+	if _, found := ParseLineQuality("  Port state : Online"); found {
+		t.Error("ParseLineQuality() found counters in port state")
+	}
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// Waits before each ONU state check during RestartONU. Re-registration
+// takes longer than going offline, so the online checks wait longer.
+var (
+	RestartOfflineWaits = []time.Duration{3 * time.Second, 5 * time.Second, 10 * time.Second}
+	RestartOnlineWaits  = []time.Duration{5 * time.Second, 10 * time.Second, 15 * time.Second}
+)
+
+// WaitONUState calls check after each of waits until it returns true. It
+// returns whether it did, and how many checks after the first one failed.
+func WaitONUState(ctx context.Context, waits []time.Duration, check func(ctx context.Context) bool) (verified bool, retries int) {
+	for attempt, wait := range waits {
+		select {
+		case <-ctx.Done():
+			return false, retries
+		case <-time.After(wait):
+		}
+		if check(ctx) {
+			return true, retries
+		}
+		if attempt > 0 {
+			retries++
+		}
+	}
+	return false, retries
+}
+
+// FinishRestart sets Success and Message of a RestartONU result from the
+// outcome of its steps
+func FinishRestart(result *types.RestartONUResult) {
+	if !result.DeactivateSuccess || !result.ActivateSuccess {
+		return
+	}
+	result.Success = true
+	switch {
+	case result.DeactivateVerified && result.ActivateVerified:
+		result.Message = "ONU restart completed and verified"
+	case result.ActivateVerified:
+		result.Message = "ONU restart completed, deactivation not verified but ONU is now online"
+	case result.DeactivateVerified:
+		result.Message = "ONU restarted but still coming online (may take a few more seconds)"
+	default:
+		result.Message = "ONU restart commands sent, verification pending"
+	}
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestWaitONUState(t *testing.T) {
+	waits := []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond}
+
+	checks := 0
+	verified, retries := WaitONUState(context.Background(), waits, func(context.Context) bool {
+		checks++
+		return checks == 3
+	})
+	if !verified || retries != 1 {
+		t.Errorf("WaitONUState() = %v, %d; want true, 1", verified, retries)
+	}
+
+	verified, retries = WaitONUState(context.Background(), waits, func(context.Context) bool { return false })
+	if verified || retries != 2 {
+		t.Errorf("WaitONUState() = %v, %d; want false, 2", verified, retries)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if verified, _ := WaitONUState(ctx, []time.Duration{time.Hour}, func(context.Context) bool { return true }); verified {
+		t.Error("WaitONUState() verified after ctx was cancelled")
+	}
+}
+
+func TestFinishRestart(t *testing.T) {
+	tests := []struct {
+		result  types.RestartONUResult
+		success bool
+		message string
+	}{
+		{types.RestartONUResult{DeactivateSuccess: true}, false, ""},
+		{types.RestartONUResult{DeactivateSuccess: true, ActivateSuccess: true}, true, "ONU restart commands sent, verification pending"},
+		{types.RestartONUResult{DeactivateSuccess: true, ActivateSuccess: true, DeactivateVerified: true, ActivateVerified: true}, true, "ONU restart completed and verified"},
+		{types.RestartONUResult{DeactivateSuccess: true, ActivateSuccess: true, ActivateVerified: true}, true, "ONU restart completed, deactivation not verified but ONU is now online"},
+	}
+	for _, tt := range tests {
+		result := tt.result
+		FinishRestart(&result)
+		if result.Success != tt.success || result.Message != tt.message {
+			t.Errorf("FinishRestart(%+v) = %v, %q; want %v, %q", tt.result, result.Success, result.Message, tt.success, tt.message)
+		}
+	}
+}
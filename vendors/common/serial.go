@@ -0,0 +1,110 @@
+package common
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// A GPON serial number is a 4-character vendor ID and an 8-hex-digit
+// vendor-specific serial. OLTs show it in ASCII form ("HWTC0011D168"),
+// with a separator ("HWTC-0011D168"), or fully hex encoded
+// ("485754430011D168", sometimes "48:57:54:43:00:11:D1:68").
+
+// NormalizeSerial returns the canonical ASCII form of a GPON serial number,
+// uppercase and without separator, from any of its representations. Colons
+// are only accepted in the hex form, so colon-separated MAC addresses are
+// rejected; a bare 12-digit MAC address with a letter vendor-like prefix
+// cannot be told apart from a serial.
+func NormalizeSerial(serial string) (string, error) {
+	s := strings.ToUpper(strings.TrimSpace(serial))
+	colons := strings.Contains(s, ":")
+	if colons {
+		s = strings.ReplaceAll(s, ":", "")
+	} else if len(s) == 13 && s[4] == '-' {
+		s = s[:4] + s[5:]
+	}
+	switch len(s) {
+	case 12:
+		if !colons && isVendorID(s[:4]) && isHex(s[4:]) {
+			return s, nil
+		}
+	case 16:
+		if isHex(s) {
+			vendor, _ := hex.DecodeString(s[:8])
+			if isVendorID(string(vendor)) {
+				return string(vendor) + s[8:], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("invalid GPON serial %q: want a 4-character vendor ID and 8 hex digits, or 16 hex digits", serial)
+}
+
+// ValidateSerial checks that serial is a GPON serial number in any of its
+// representations
+func ValidateSerial(serial string) error {
+	_, err := NormalizeSerial(serial)
+	return err
+}
+
+// SerialToHex returns the 16-hex-digit form of a GPON serial number, as
+// used in SNMP tables and some OLT CLIs
+func SerialToHex(serial string) (string, error) {
+	s, err := NormalizeSerial(serial)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString([]byte(s[:4]))) + s[4:], nil
+}
+
+// DecodeHexSerial returns the ASCII form of a serial number read from an
+// OLT, e.g. "485754430011D168" -> "HWTC0011D168". Values that are not GPON
+// serials, such as EPON MAC addresses, are returned unchanged.
+func DecodeHexSerial(serial string) string {
+	if s, err := NormalizeSerial(serial); err == nil {
+		return s
+	}
+	return serial
+}
+
+// SerialKey returns the form of a serial number to use as a map key or
+// for prefix matching: the canonical ASCII form of a GPON serial, or the
+// trimmed, uppercased value for anything else, such as EPON MAC addresses.
+func SerialKey(serial string) string {
+	if s, err := NormalizeSerial(serial); err == nil {
+		return s
+	}
+	return strings.ToUpper(strings.TrimSpace(serial))
+}
+
+// SerialsEqual reports whether a and b are the same GPON serial number,
+// whatever their representation. Values that are not GPON serials are
+// compared case-insensitively.
+func SerialsEqual(a, b string) bool {
+	return SerialKey(a) == SerialKey(b)
+}
+
+// isVendorID reports whether s is a vendor ID: uppercase letters or
+// digits, starting with a letter
+func isVendorID(s string) bool {
+	if len(s) != 4 || s[0] < 'A' || s[0] > 'Z' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package common
+
+import "testing"
+
+func TestNormalizeSerial(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "ASCII", input: "HWTC0011D168", want: "HWTC0011D168"},
+		{name: "lowercase", input: "hwtc0011d168", want: "HWTC0011D168"},
+		{name: "dash", input: "HWTC-0011D168", want: "HWTC0011D168"},
+		{name: "hex", input: "485754430011D168", want: "HWTC0011D168"},
+		{name: "hex with colons", input: "48:57:54:43:00:11:d1:68", want: "HWTC0011D168"},
+		{name: "surrounding space", input: "  ZTEG00000001 ", want: "ZTEG00000001"},
+		{name: "vendor with digit", input: "TP1G00000001", want: "TP1G00000001"},
+		{name: "short", input: "HWTC", wantErr: true},
+		{name: "non-hex serial", input: "HWTC0011Z168", wantErr: true},
+		{name: "non-printable vendor", input: "000000000011D168", wantErr: true},
+		{name: "MAC address", input: "AA:BB:CC:DD:EE:FF", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeSerial(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeSerial(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeSerial(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if (ValidateSerial(tt.input) != nil) != tt.wantErr {
+				t.Errorf("ValidateSerial(%q) disagrees with NormalizeSerial", tt.input)
+			}
+		})
+	}
+}
+
+func TestSerialToHex(t *testing.T) {
+	if got, err := SerialToHex("HWTC-0011d168"); err != nil || got != "485754430011D168" {
+		t.Errorf("SerialToHex() = %q, %v", got, err)
+	}
+	if _, err := SerialToHex("bogus"); err == nil {
+		t.Error("expected error for an invalid serial")
+	}
+}
+
+func TestDecodeHexSerial(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "hex-encoded serial", input: "485754430011D168", expected: "HWTC0011D168"},
+		{name: "already ASCII serial", input: "HWTC00000101", expected: "HWTC00000101"},
+		{name: "short serial", input: "HWTC", expected: "HWTC"},
+		{name: "MAC address", input: "aa:bb:cc:dd:ee:ff", expected: "aa:bb:cc:dd:ee:ff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecodeHexSerial(tt.input); got != tt.expected {
+				t.Errorf("DecodeHexSerial(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsVendorID(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "HWTC", input: "HWTC", want: true},
+		{name: "ZTEG", input: "ZTEG", want: true},
+		{name: "hex vendor ID", input: "4857", want: false},
+		{name: "short", input: "AB", want: false},
+		{name: "lowercase", input: "hwtc", want: false},
+		{name: "starts with numbers", input: "12AB", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isVendorID(tt.input); got != tt.want {
+				t.Errorf("isVendorID(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSerialKey(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"485754430011D168", "HWTC0011D168"},
+		{" hwtc-0011d168 ", "HWTC0011D168"},
+		{"aa:bb:cc:dd:ee:ff", "AA:BB:CC:DD:EE:FF"},
+		{" fhtt ", "FHTT"},
+	}
+	for _, tt := range tests {
+		if got := SerialKey(tt.input); got != tt.want {
+			t.Errorf("SerialKey(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSerialsEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"HWTC0011D168", "485754430011D168", true},
+		{"hwtc-0011d168", "HWTC0011D168", true},
+		{"HWTC0011D168", "HWTC0011D169", false},
+		{"aa:bb:cc:dd:ee:ff", "AA:BB:CC:DD:EE:FF", true},
+	}
+	for _, tt := range tests {
+		if got := SerialsEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("SerialsEqual(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
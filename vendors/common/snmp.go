@@ -1,6 +1,11 @@
 package common
 
-import "strings"
+import (
+	"context"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
 
 // SNMPInvalidValue is the magic value indicating offline/invalid SNMP reading.
 // Used by Huawei, V-SOL, and other vendors to indicate the ONU is offline
@@ -188,3 +193,27 @@ func ParseStringSNMPValue(value interface{}) (string, bool) {
 func IsValidSNMPValue(value int64) bool {
 	return value != SNMPInvalidValue && value != 0
 }
+
+// WalkSubtree walks the rows of an SNMP table whose index starts with
+// scope, e.g. the ONUs of one PON port, and returns them keyed by their
+// full index like a walk of the whole table. An empty scope walks the
+// whole table.
+func WalkSubtree(ctx context.Context, exec types.SNMPExecutor, oid, scope string) (map[string]interface{}, error) {
+	if scope == "" {
+		return exec.WalkSNMP(ctx, oid)
+	}
+	rows, err := exec.WalkSNMP(ctx, oid+"."+scope)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]interface{}, len(rows))
+	for index, val := range rows {
+		// Keep the leading dot of the indexes of the executor
+		if rest, ok := strings.CutPrefix(index, "."); ok {
+			results["."+scope+"."+rest] = val
+		} else {
+			results[scope+"."+index] = val
+		}
+	}
+	return results, nil
+}
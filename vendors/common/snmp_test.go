@@ -1,6 +1,12 @@
 package common
 
-import "testing"
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+)
 
 func TestGetSNMPResult(t *testing.T) {
 	tests := []struct {
@@ -213,3 +219,25 @@ func TestIsValidSNMPValue(t *testing.T) {
 		})
 	}
 }
+
+func TestWalkSubtree(t *testing.T) {
+	mock := &testutil.MockSNMPExecutor{MIB: map[string]interface{}{
+		".1.2.3.1.1": "a",
+		".1.2.3.1.2": "b",
+		".1.2.3.2.1": "c",
+	}}
+	got, err := WalkSubtree(context.Background(), mock, "1.2.3", "1")
+	if err != nil {
+		t.Fatalf("WalkSubtree() error = %v", err)
+	}
+	if want := map[string]interface{}{".1.1": "a", ".1.2": "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkSubtree() = %v, want %v", got, want)
+	}
+	if want := []string{"WalkSNMP:1.2.3.1"}; !reflect.DeepEqual(mock.Calls, want) {
+		t.Errorf("calls = %v, want %v", mock.Calls, want)
+	}
+
+	if got, _ := WalkSubtree(context.Background(), mock, "1.2.3", ""); len(got) != 3 {
+		t.Errorf("WalkSubtree() without scope = %v", got)
+	}
+}
@@ -43,3 +43,35 @@ func SanitizeCLIParam(s string) string {
 	)
 	return strings.TrimSpace(r.Replace(s))
 }
+
+// SplitCommandsAfter splits a command sequence after the first command
+// starting with prefix. Provisioning uses it to separate the command that
+// creates an object from those that configure it, so that only a created
+// object is rolled back. If no command matches, head is empty.
+func SplitCommandsAfter(commands []string, prefix string) (head, tail []string) {
+	for i, cmd := range commands {
+		if strings.HasPrefix(cmd, prefix) {
+			return commands[:i+1], commands[i+1:]
+		}
+	}
+	return nil, commands
+}
+
+// testResultRegex matches the result line of ONU self-test and loopback
+// output, e.g. "Test result : pass"
+var testResultRegex = regexp.MustCompile(`(?mi)^\s*(?:test\s*)?result\s*:\s*(.+?)\s*$`)
+
+// ParseTestResult extracts the result of ONU self-test or loopback output
+// and whether it reports a pass. found is false if the output has no
+// result line.
+func ParseTestResult(output string) (result string, passed, found bool) {
+	m := testResultRegex.FindStringSubmatch(StripANSI(output))
+	if m == nil {
+		return "", false, false
+	}
+	switch strings.ToLower(m[1]) {
+	case "pass", "passed", "success", "successful", "normal", "ok":
+		passed = true
+	}
+	return m[1], passed, true
+}
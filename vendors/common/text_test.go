@@ -236,3 +236,41 @@ func TestSanitizeCLIParam(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitCommandsAfter(t *testing.T) {
+	commands := []string{"configure terminal", "interface gpon 0/1", "onu add 5 profile P sn X", "onu 5 tcont 1", "end"}
+
+	head, tail := SplitCommandsAfter(commands, "onu add ")
+	if len(head) != 3 || head[2] != "onu add 5 profile P sn X" {
+		t.Errorf("head = %v", head)
+	}
+	if len(tail) != 2 || tail[0] != "onu 5 tcont 1" {
+		t.Errorf("tail = %v", tail)
+	}
+
+	head, tail = SplitCommandsAfter(commands, "llid ")
+	if len(head) != 0 || len(tail) != len(commands) {
+		t.Errorf("no match: head = %v, tail = %v", head, tail)
+	}
+}
+
+func TestParseTestResult(t *testing.T) {
+	tests := []struct {
+		output string
+		result string
+		passed bool
+		found  bool
+	}{
+		{"  ONT ID      : 5\n  Test result : pass\n", "pass", true, true},
+		{"Result: Failed (no loopback frame received)\n", "Failed (no loopback frame received)", false, true},
+		{"  Test Result : Success\n", "Success", true, true},
+		{"% Unknown command\n", "", false, false},
+	}
+	for _, tt := range tests {
+		result, passed, found := ParseTestResult(tt.output)
+		if result != tt.result || passed != tt.passed || found != tt.found {
+			t.Errorf("ParseTestResult(%q) = %q, %v, %v; want %q, %v, %v",
+				tt.output, result, passed, found, tt.result, tt.passed, tt.found)
+		}
+	}
+}
@@ -1,10 +1,16 @@
+// Package huawei adapts the CLI and SNMP drivers to Huawei MA5600T and
+// MA5800-X series OLTs. Many of its commands and output formats follow the
+// MA5800 command reference and configuration guides rather than captures
+// from a device, and are not yet verified on hardware.
 package huawei
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log/slog"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -18,8 +24,9 @@ import (
 
 // Compile-time interface conformance checks
 var (
-	_ types.Driver   = (*Adapter)(nil)
-	_ types.DriverV2 = (*Adapter)(nil)
+	_ types.Driver           = (*Adapter)(nil)
+	_ types.DriverV2         = (*Adapter)(nil)
+	_ types.RogueONUDetector = (*Adapter)(nil)
 )
 
 // Package-level compiled regexes for parsing Huawei CLI output.
@@ -38,6 +45,24 @@ var (
 	reHWONTSubscriberID = regexp.MustCompile(`ont-(\d+)/(\d+)/(\d+)-(\d+)`)
 	reHWVersionString   = regexp.MustCompile(`V(\d+R\d+C\d+)`)
 	reHWPortFromDescr   = regexp.MustCompile(`(\d+)/(\d+)/(\d+)`)
+	reHWFSP             = regexp.MustCompile(`^\d+/\d+/\d+$`)
+
+	// reHWFailure matches the line the CLI prints when it rejects a command
+	reHWFailure = regexp.MustCompile(`(?m)^\s*Failure:\s*(.+?)\s*$`)
+
+	// reHWAddedONTID matches the ONT ID ont add reports
+	reHWAddedONTID = regexp.MustCompile(`ONTID\s*:\s*(\d+)`)
+
+	// reHWServicePortRow matches a row of display service-port all: index,
+	// VLAN, VLAN attribute, port type, F/S /P, VPI (ONT ID), VCI (GEM
+	// index), flow type and parameter, RX and TX traffic tables and state,
+	// e.g. "     0  100 common   gpon 0/1 /0  1    1     vlan  100    35   35   up"
+	reHWServicePortRow = regexp.MustCompile(`^\s*(\d+)\s+(\d+)\s+(\S+)\s+([a-z]+)\s+(\d+)\s*/\s*(\d+)\s*/\s*(\d+)\s+(\d+)\s+(\d+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)`)
+
+	// reHWMorePrompt matches the pager prompt left in paged output. The
+	// OLT erases it with cursor movement only, so after ANSI stripping it
+	// may run into the next row.
+	reHWMorePrompt = regexp.MustCompile(`-*\s*More \( Press 'Q' to break \)\s*-*`)
 )
 
 // Adapter wraps a base driver with Huawei-specific logic
@@ -50,6 +75,7 @@ type Adapter struct {
 	cliExecutor     types.CLIExecutor
 	snmpExecutor    types.SNMPExecutor
 	config          *types.EquipmentConfig
+	metrics         types.DriverCounters // parse failures
 
 	// Soft suspension state tracking
 	suspensionMu     sync.RWMutex
@@ -58,7 +84,8 @@ type Adapter struct {
 
 // NewAdapter creates a new Huawei adapter
 // If the base driver is CLI, it automatically creates an SNMP driver for monitoring
-func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig) types.Driver {
+func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig, opts ...types.Option) types.Driver {
+	config.Apply(opts...)
 	adapter := &Adapter{
 		baseDriver:       baseDriver,
 		config:           config,
@@ -111,7 +138,9 @@ func (a *Adapter) createSNMPDriver() {
 
 	snmpDriver, err := snmp.NewDriver(&snmpConfig)
 	if err != nil {
-		return // SNMP creation failed, continue without it
+		a.config.Log().Warn("huawei: failed to create secondary SNMP driver, continuing without SNMP",
+			"address", a.config.Address, "error", err)
+		return
 	}
 
 	a.secondaryDriver = snmpDriver
@@ -137,9 +166,9 @@ func (a *Adapter) Connect(ctx context.Context, config *types.EquipmentConfig) er
 			snmpConfig.Port = 161
 		}
 		if err := a.secondaryDriver.Connect(ctx, &snmpConfig); err != nil {
-			// Log but don't fail - secondary is optional for some operations
-			// SNMP may not be required if only doing CLI operations
-			_ = err // Explicitly ignore error - secondary driver is optional
+			// Don't fail - SNMP is not required for CLI-only operations
+			a.config.Log().Warn("huawei: secondary SNMP driver connect failed, continuing without SNMP",
+				"address", a.config.Address, "error", err)
 		}
 	}
 
@@ -149,7 +178,9 @@ func (a *Adapter) Connect(ctx context.Context, config *types.EquipmentConfig) er
 func (a *Adapter) Disconnect(ctx context.Context) error {
 	// Disconnect secondary driver first (if present)
 	if a.secondaryDriver != nil {
-		_ = a.secondaryDriver.Disconnect(ctx)
+		if err := a.secondaryDriver.Disconnect(ctx); err != nil {
+			a.config.Log().Warn("huawei: secondary SNMP driver disconnect failed", "error", err)
+		}
 	}
 
 	// Disconnect primary driver
@@ -160,8 +191,26 @@ func (a *Adapter) IsConnected() bool {
 	return a.baseDriver.IsConnected()
 }
 
+// DriverMetrics implements types.MetricsProvider, combining the adapter's
+// parse failures with the counters of its protocol drivers
+func (a *Adapter) DriverMetrics() types.DriverMetrics {
+	return a.metrics.Snapshot().Add(types.CollectMetrics(a.baseDriver, a.secondaryDriver))
+}
+
+// Capabilities implements types.CapabilityReporter. OLT restart and ONU
+// profile queries are not implemented yet.
+func (a *Adapter) Capabilities() types.Capabilities {
+	c := types.DetectCapabilities(a)
+	c.OLTRestart = false
+	c.ONUProfileQuery = false
+	return c
+}
+
 // CreateSubscriber provisions an ONT on the Huawei OLT
-func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (*types.SubscriberResult, error) {
+func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (_ *types.SubscriberResult, err error) {
+	ctx, endAudit := types.BeginAudit(ctx, a.config, "CreateSubscriber", subscriber.Spec.ONUSerial)
+	defer func() { endAudit(err) }()
+
 	if a.cliExecutor == nil {
 		return nil, fmt.Errorf("CLI executor not available - Huawei requires CLI driver")
 	}
@@ -172,17 +221,81 @@ func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscr
 	serial := subscriber.Spec.ONUSerial
 	vlan := subscriber.Spec.VLAN
 
+	// Services of the ONT, one service-port per GEM port
+	if err := subscriber.Spec.ValidateGEMMapping(); err != nil {
+		return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	tconts, gems := subscriber.Spec.GEMMapping()
+	if len(gems) == 0 {
+		tconts, gems = []model.TCont{{ID: 1}}, []model.GEMPort{{ID: 1, TCont: 1, VLAN: vlan}}
+	}
+
 	// Get profile IDs
 	lineProfileID := a.getLineProfileID(tier)
 	srvProfileID := a.getServiceProfileID(tier)
+	if err := a.ensureTierProfiles(ctx, tier, lineProfileID, srvProfileID, tconts, gems); err != nil {
+		return nil, fmt.Errorf("Huawei provisioning failed: %w", err)
+	}
+	if tier, err = a.resolveTrafficTable(ctx, tier); err != nil {
+		return nil, fmt.Errorf("Huawei provisioning failed: %w", err)
+	}
 
-	// Huawei MA5800 CLI command sequence
-	commands := a.buildProvisioningCommands(frame, slot, port, ontID, serial, vlan, lineProfileID, srvProfileID, tier)
+	// Huawei MA5800 CLI command sequence. Each step is undone if a later
+	// one fails, so a failed create leaves no half-configured ONT or
+	// dangling service-port behind.
+	steps := a.buildProvisioningSteps(frame, slot, port, ontID, serial, gems, lineProfileID, srvProfileID, tier)
 
-	// Execute commands
-	outputs, err := a.cliExecutor.ExecCommands(ctx, commands)
+	// Voice is configured on the ONT and removed with it
+	if voice := subscriber.Spec.Voice; voice != nil {
+		if err := voice.Validate(); err != nil {
+			return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+		}
+		commands := []string{"enable", "config", fmt.Sprintf("interface gpon %d/%d", frame, slot)}
+		commands = append(commands, voiceCommands(port, ontID, voice)...)
+		steps = append(steps, provisioningStep{name: "configure voice", commands: append(commands, "quit", "quit")})
+	}
+	if t := subscriber.Spec.TR069; t != nil {
+		if err := t.Validate(); err != nil {
+			return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+		}
+		profile, err := a.ensureTR069Profile(ctx, t)
+		if err != nil {
+			return nil, fmt.Errorf("Huawei provisioning failed: %w", err)
+		}
+		commands := []string{"enable", "config", fmt.Sprintf("interface gpon %d/%d", frame, slot)}
+		commands = append(commands, tr069Commands(port, ontID, t, profile)...)
+		steps = append(steps, provisioningStep{name: "configure tr069", commands: append(commands, "quit", "quit")})
+	}
+	// A router-mode ONT dials PPPoE itself on its WAN IP host
+	wan, err := types.SubscriberWANConfig(subscriber)
 	if err != nil {
-		return nil, fmt.Errorf("Huawei provisioning failed: %w", err)
+		return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	if wan != nil {
+		commands := []string{"enable", "config", fmt.Sprintf("interface gpon %d/%d", frame, slot)}
+		commands = append(commands, wanConfigCommands(port, ontID, wan)...)
+		steps = append(steps, provisioningStep{name: "configure wan", commands: append(commands, "quit", "quit")})
+	}
+	if m := subscriber.Spec.Multicast; m != nil {
+		if err := m.Validate(); err != nil {
+			return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+		}
+		steps = append(steps, provisioningStep{name: "configure multicast", commands: multicastCommands(frame, slot, port, ontID, m)})
+	}
+
+	var outputs []string
+	err = types.RunTransaction(ctx, "create subscriber "+subscriber.Name, a.config, func(tx *types.Transaction) error {
+		for _, step := range steps {
+			out, err := tx.ExecStep(ctx, a.cliExecutor, step.name, step.commands, step.undo)
+			outputs = append(outputs, out...)
+			if err != nil {
+				return fmt.Errorf("%s: %w", step.name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Huawei provisioning failed: %w", types.AsHumanError("huawei", err))
 	}
 
 	// Build result
@@ -210,48 +323,109 @@ func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscr
 	return result, nil
 }
 
-// buildProvisioningCommands builds Huawei GPON CLI commands
-func (a *Adapter) buildProvisioningCommands(frame, slot, port, ontID int, serial string, vlan int, lineProfileID, srvProfileID int, tier *model.ServiceTier) []string {
-	// Huawei MA5800/MA5600T GPON CLI reference
-	// Based on Huawei SmartAX MA5800-X series CLI documentation
+// provisioningStep is one step of a subscriber create and the commands
+// that undo it
+type provisioningStep struct {
+	name     string
+	commands []string
+	undo     []string
+}
 
-	commands := []string{
-		// Enter privileged exec mode first (required before config)
-		"enable",
+// buildProvisioningSteps builds the Huawei GPON CLI steps of a subscriber
+// create. The native VLAN of the ONT Ethernet port is the user VLAN of the
+// first GEM port.
+func (a *Adapter) buildProvisioningSteps(frame, slot, port, ontID int, serial string, gems []model.GEMPort, lineProfileID, srvProfileID int, tier *model.ServiceTier) []provisioningStep {
+	servicePorts := make([]string, 0, len(gems)+1)
+	for _, gem := range gems {
+		servicePorts = append(servicePorts, servicePortCommand(frame, slot, port, ontID, gem))
+	}
 
-		// Enter global config mode
-		"config",
+	// Huawei MA5800/MA5600T GPON CLI reference
+	// Based on Huawei SmartAX MA5800-X series CLI documentation
 
-		// Navigate to GPON interface
-		fmt.Sprintf("interface gpon %d/%d", frame, slot),
+	steps := []provisioningStep{
+		{
+			name: "add ont",
+			commands: []string{
+				// Enter privileged exec mode first (required before config)
+				"enable",
 
-		// Add ONT with serial number authentication
-		// ont add <port> <ont-id> sn-auth <serial> omci ont-lineprofile-id <id> ont-srvprofile-id <id> desc <description>
-		fmt.Sprintf("ont add %d %d sn-auth %s omci ont-lineprofile-id %d ont-srvprofile-id %d desc nanoncore",
-			port, ontID, common.SanitizeCLIParam(serial), lineProfileID, srvProfileID),
+				// Enter global config mode
+				"config",
 
-		// Configure native VLAN on ONT ETH port
-		// ont port native-vlan <port> <ont-id> eth <eth-port> vlan <vlan> priority <0-7>
-		fmt.Sprintf("ont port native-vlan %d %d eth 1 vlan %d priority 0", port, ontID, vlan),
+				// Navigate to GPON interface
+				fmt.Sprintf("interface gpon %d/%d", frame, slot),
 
-		// Exit GPON interface
-		"quit",
+				// Add ONT with serial number authentication
+				// ont add <port> <ont-id> sn-auth <serial> omci ont-lineprofile-id <id> ont-srvprofile-id <id> desc <description>
+				fmt.Sprintf("ont add %d %d sn-auth %s omci ont-lineprofile-id %d ont-srvprofile-id %d desc nanoncore",
+					port, ontID, common.SanitizeCLIParam(serial), lineProfileID, srvProfileID),
 
-		// Configure service port for traffic
-		// service-port <id> vlan <vlan> gpon <frame>/<slot>/<port> ont <ont-id> gemport <gemport> multi-service user-vlan <vlan> tag-transform translate
-		fmt.Sprintf("service-port vlan %d gpon %d/%d/%d ont %d gemport 1 multi-service user-vlan %d tag-transform translate",
-			vlan, frame, slot, port, ontID, vlan),
+				// Configure native VLAN on ONT ETH port
+				// ont port native-vlan <port> <ont-id> eth <eth-port> vlan <vlan> priority <0-7>
+				fmt.Sprintf("ont port native-vlan %d %d eth 1 vlan %d priority 0", port, ontID, gems[0].GetUserVLAN()),
 
-		// Apply configuration
-		"quit",
+				// Exit GPON interface
+				"quit",
+			},
+			undo: []string{
+				"enable",
+				"config",
+				fmt.Sprintf("interface gpon %d/%d", frame, slot),
+				fmt.Sprintf("ont delete %d %d", port, ontID),
+				"quit",
+				"quit",
+			},
+		},
+		{
+			name: "add service-port",
+			// Configure a service port for the traffic of each GEM port,
+			// then apply configuration
+			commands: append(servicePorts, "quit"),
+			// The ONT cannot be deleted while it still has service ports
+			undo: []string{
+				"enable",
+				"config",
+				fmt.Sprintf("undo service-port port %d/%d/%d ont %d", frame, slot, port, ontID),
+				"quit",
+			},
+		},
 	}
 
-	// Add traffic profile commands if bandwidth is specified
+	// Add traffic profile commands if bandwidth is specified. The traffic
+	// policy is removed with the ONT, so it needs no undo.
 	if tier.Spec.BandwidthDown > 0 || tier.Spec.BandwidthUp > 0 {
-		trafficCommands := a.buildTrafficProfileCommands(frame, slot, port, ontID, tier)
-		commands = append(commands, trafficCommands...)
+		steps = append(steps, provisioningStep{
+			name:     "bind traffic policy",
+			commands: a.buildTrafficProfileCommands(frame, slot, port, ontID, tier),
+		})
+	}
+
+	return steps
+}
+
+// servicePortCommand builds the service-port of a GEM port. With an
+// S-VLAN the user VLAN is translated to the C-VLAN and the S-VLAN is added
+// as outer tag.
+func servicePortCommand(frame, slot, port, ontID int, gem model.GEMPort) string {
+	if gem.SVLAN > 0 {
+		// service-port vlan <svlan> gpon <frame>/<slot>/<port> ont <ont-id> gemport <gemport> multi-service user-vlan <vlan> tag-transform translate-and-add inner-vlan <cvlan>
+		return fmt.Sprintf("service-port vlan %d gpon %d/%d/%d ont %d gemport %d multi-service user-vlan %d tag-transform translate-and-add inner-vlan %d",
+			gem.SVLAN, frame, slot, port, ontID, gem.ID, gem.GetUserVLAN(), gem.VLAN)
 	}
+	// service-port vlan <vlan> gpon <frame>/<slot>/<port> ont <ont-id> gemport <gemport> multi-service user-vlan <vlan> tag-transform translate
+	return fmt.Sprintf("service-port vlan %d gpon %d/%d/%d ont %d gemport %d multi-service user-vlan %d tag-transform translate",
+		gem.VLAN, frame, slot, port, ontID, gem.ID, gem.GetUserVLAN())
+}
 
+// buildProvisioningCommands returns the full CLI command sequence of a
+// single-VLAN subscriber create
+func (a *Adapter) buildProvisioningCommands(frame, slot, port, ontID int, serial string, vlan int, lineProfileID, srvProfileID int, tier *model.ServiceTier) []string {
+	var commands []string
+	gems := []model.GEMPort{{ID: 1, TCont: 1, VLAN: vlan}}
+	for _, step := range a.buildProvisioningSteps(frame, slot, port, ontID, serial, gems, lineProfileID, srvProfileID, tier) {
+		commands = append(commands, step.commands...)
+	}
 	return commands
 }
 
@@ -286,6 +460,10 @@ func (a *Adapter) UpdateSubscriber(ctx context.Context, subscriber *model.Subscr
 	vlan := subscriber.Spec.VLAN
 	lineProfileID := a.getLineProfileID(tier)
 	srvProfileID := a.getServiceProfileID(tier)
+	tier, err := a.resolveTrafficTable(ctx, tier)
+	if err != nil {
+		return err
+	}
 	trafficTableID := a.getTrafficTableID(tier)
 
 	commands := []string{
@@ -306,7 +484,7 @@ func (a *Adapter) UpdateSubscriber(ctx context.Context, subscriber *model.Subscr
 		"quit",
 	}
 
-	_, err := a.cliExecutor.ExecCommands(ctx, commands)
+	_, err = a.cliExecutor.ExecCommands(ctx, commands)
 	return err
 }
 
@@ -333,11 +511,23 @@ func (a *Adapter) DeleteSubscriber(ctx context.Context, subscriberID string) err
 	return err
 }
 
+// SuspendSubscriber deactivates the ONT, or applies the soft suspension
+// configured by the device's suspend_mode metadata, such as moving it to
+// the walled-garden VLAN.
 func (a *Adapter) SuspendSubscriber(ctx context.Context, subscriberID string) error {
 	if a.cliExecutor == nil {
 		return fmt.Errorf("CLI executor not available")
 	}
 
+	opts, err := types.ConfiguredSuspension(a.config)
+	if err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	if opts != nil {
+		_, err := a.SoftSuspendSubscriber(ctx, subscriberID, opts)
+		return err
+	}
+
 	frame, slot, port, ontID := a.parseSubscriberID(subscriberID)
 
 	commands := []string{
@@ -352,15 +542,23 @@ func (a *Adapter) SuspendSubscriber(ctx context.Context, subscriberID string) er
 		"quit",
 	}
 
-	_, err := a.cliExecutor.ExecCommands(ctx, commands)
+	_, err = a.cliExecutor.ExecCommands(ctx, commands)
 	return err
 }
 
+// ResumeSubscriber lifts a soft suspension, or activates the ONT.
 func (a *Adapter) ResumeSubscriber(ctx context.Context, subscriberID string) error {
 	if a.cliExecutor == nil {
 		return fmt.Errorf("CLI executor not available")
 	}
 
+	a.suspensionMu.RLock()
+	state := a.suspensionStates[subscriberID]
+	a.suspensionMu.RUnlock()
+	if state != nil {
+		return a.liftSoftSuspension(ctx, subscriberID, state)
+	}
+
 	frame, slot, port, ontID := a.parseSubscriberID(subscriberID)
 
 	commands := []string{
@@ -546,6 +744,7 @@ type ONTDiscovery struct {
 	Slot      int       `json:"slot"`
 	Port      int       `json:"port"`
 	Serial    string    `json:"serial"`
+	VendorID  string    `json:"vendor_id"`
 	EquipID   string    `json:"equip_id"`   // Equipment identifier (ONT model)
 	LOID      string    `json:"loid"`       // Logical ONU ID (if LOID auth used)
 	Distance  int       `json:"distance_m"` // Distance in meters
@@ -575,60 +774,67 @@ type ONTStats struct {
 // BulkScanONUsSNMP performs SNMP walk to get all ONUs (like legacy PHP code)
 // This is much faster than querying each ONU individually
 func (a *Adapter) BulkScanONUsSNMP(ctx context.Context) ([]ONTStats, error) {
+	return a.scanONUsSNMP(ctx, "")
+}
+
+// scanONUsSNMP walks the ONU tables, limited to the ONUs of one PON port
+// with the port index of the port as scope, or all ONUs if scope is empty
+func (a *Adapter) scanONUsSNMP(ctx context.Context, scope string) ([]ONTStats, error) {
 	if a.snmpExecutor == nil {
 		return nil, fmt.Errorf("SNMP executor not available")
 	}
 
 	// Walk serial numbers to get all ONUs
-	serials, err := a.snmpExecutor.WalkSNMP(ctx, OIDOnuSerialNumber)
+	serials, err := common.WalkSubtree(ctx, a.snmpExecutor, OIDOnuSerialNumber, scope)
 	if err != nil {
 		return nil, fmt.Errorf("failed to walk serial numbers: %w", err)
 	}
 
 	// Walk Rx power to determine online status
-	rxPowers, err := a.snmpExecutor.WalkSNMP(ctx, OIDOnuRxPower)
+	rxPowers, err := common.WalkSubtree(ctx, a.snmpExecutor, OIDOnuRxPower, scope)
 	if err != nil {
 		// Non-fatal, continue without power data
+		a.config.Log().Warn("huawei: ONU Rx power walk failed", "error", err)
 		rxPowers = make(map[string]interface{})
 	}
 
 	// Walk Tx power
-	txPowers, err := a.snmpExecutor.WalkSNMP(ctx, OIDOnuTxPower)
+	txPowers, err := common.WalkSubtree(ctx, a.snmpExecutor, OIDOnuTxPower, scope)
 	if err != nil {
 		txPowers = make(map[string]interface{})
 	}
 
 	// Walk temperature
-	temperatures, err := a.snmpExecutor.WalkSNMP(ctx, OIDOnuTemperature)
+	temperatures, err := common.WalkSubtree(ctx, a.snmpExecutor, OIDOnuTemperature, scope)
 	if err != nil {
 		temperatures = make(map[string]interface{})
 	}
 
 	// Walk voltage
-	voltages, err := a.snmpExecutor.WalkSNMP(ctx, OIDOnuVoltage)
+	voltages, err := common.WalkSubtree(ctx, a.snmpExecutor, OIDOnuVoltage, scope)
 	if err != nil {
 		voltages = make(map[string]interface{})
 	}
 
 	// Walk distance
-	distances, err := a.snmpExecutor.WalkSNMP(ctx, OIDOnuDistance)
+	distances, err := common.WalkSubtree(ctx, a.snmpExecutor, OIDOnuDistance, scope)
 	if err != nil {
 		distances = make(map[string]interface{})
 	}
 
 	// Walk bias current
-	biasCurrents, err := a.snmpExecutor.WalkSNMP(ctx, OIDOnuCurrent)
+	biasCurrents, err := common.WalkSubtree(ctx, a.snmpExecutor, OIDOnuCurrent, scope)
 	if err != nil {
 		biasCurrents = make(map[string]interface{})
 	}
 
 	// Walk traffic counters
-	upBytes, err := a.snmpExecutor.WalkSNMP(ctx, OIDOnuUpBytes)
+	upBytes, err := common.WalkSubtree(ctx, a.snmpExecutor, OIDOnuUpBytes, scope)
 	if err != nil {
 		upBytes = make(map[string]interface{})
 	}
 
-	downBytes, err := a.snmpExecutor.WalkSNMP(ctx, OIDOnuDownBytes)
+	downBytes, err := common.WalkSubtree(ctx, a.snmpExecutor, OIDOnuDownBytes, scope)
 	if err != nil {
 		downBytes = make(map[string]interface{})
 	}
@@ -643,12 +849,13 @@ func (a *Adapter) BulkScanONUsSNMP(ctx context.Context) ([]ONTStats, error) {
 		}
 
 		// Decode serial number (hex to readable)
-		serial := DecodeHexSerial(hexSerial)
+		serial := common.DecodeHexSerial(hexSerial)
 
 		// Parse index to get frame/slot/port/onuID
 		frame, slot, port, onuID, err := ParseONUIndex(index)
 		if err != nil {
-			slog.Debug("failed to parse ONU index", "index", index, "error", err)
+			a.metrics.RecordParseFailure()
+			a.config.Log().Debug("failed to parse ONU index", "index", index, "error", err)
 			continue
 		}
 
@@ -775,11 +982,17 @@ func (a *Adapter) parseAutofindOutput(output string) []ONTDiscovery {
 				Slot:      slot,
 				Port:      port,
 				Serial:    fields[2],
+				VendorID:  fields[3],
+				EquipID:   fields[4],
 				Timestamp: time.Now(),
 			}
 
-			if len(fields) >= 5 {
-				discovery.EquipID = fields[4]
+			// The Time column is when the ONT was first found, in the
+			// OLT's local time
+			if len(fields) >= 7 {
+				if at, err := time.ParseInLocation("2006-01-02 15:04:05", fields[5]+" "+fields[6], time.Local); err == nil {
+					discovery.Timestamp = at
+				}
 			}
 
 			discoveries = append(discoveries, discovery)
@@ -931,7 +1144,7 @@ func (a *Adapter) parseONTStats(output string) *types.SubscriberStats {
 
 // detectModel determines the Huawei OLT model
 func (a *Adapter) detectModel() string {
-	if model, ok := a.config.Metadata["model"]; ok {
+	if model := a.config.DeviceModel(); model != "" {
 		return model
 	}
 	return "ma5800"
@@ -981,7 +1194,8 @@ func (a *Adapter) getServiceProfileID(tier *model.ServiceTier) int {
 	return common.GetAnnotationIntWithDefault(tier.Annotations, 1, "nanoncore.com/srv-profile-id")
 }
 
-// getTrafficTableID returns the traffic table ID for a service tier
+// getTrafficTableID returns the traffic table ID for a service tier, as
+// set by resolveTrafficTable
 func (a *Adapter) getTrafficTableID(tier *model.ServiceTier) int {
 	if tier == nil {
 		return 1 // default traffic table ID
@@ -989,8 +1203,8 @@ func (a *Adapter) getTrafficTableID(tier *model.ServiceTier) int {
 	if id, ok := common.GetAnnotationInt(tier.Annotations, "nanoncore.com/traffic-table-id"); ok {
 		return id
 	}
-	// Generate based on bandwidth (use bandwidth as table ID)
-	// This assumes traffic tables are pre-configured with matching IDs
+	// Unresolved tier: use bandwidth as table ID, assuming traffic tables
+	// are pre-configured with matching IDs
 	return tier.Spec.BandwidthDown
 }
 
@@ -1013,6 +1227,71 @@ func (a *Adapter) parseSubscriberID(subscriberID string) (frame, slot, port, ont
 	return 0, 1, 0, hash
 }
 
+// parsePONPort parses a frame/slot/port PON port, e.g. "0/1/0"
+func parsePONPort(ponPort string) (frame, slot, port int, err error) {
+	parts := strings.Split(ponPort, "/")
+	if len(parts) != 3 {
+		return 0, 0, 0, fmt.Errorf("invalid PON port format: %s (expected frame/slot/port)", ponPort)
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		if nums[i], err = strconv.Atoi(p); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid PON port format: %s (expected frame/slot/port)", ponPort)
+		}
+	}
+	return nums[0], nums[1], nums[2], nil
+}
+
+// execONTCommands runs the commands build returns for the port number of
+// ponPort in its interface gpon view. A command the CLI rejects with
+// "Failure:" fails the call, and an unknown ONT is ErrCodeONUNotFound.
+func (a *Adapter) execONTCommands(ctx context.Context, ponPort string, onuID int, build func(port int) []string) error {
+	_, err := a.runONTCommands(ctx, ponPort, onuID, build)
+	return err
+}
+
+// runONTCommands is execONTCommands, returning the output of each command
+// build returned
+func (a *Adapter) runONTCommands(ctx context.Context, ponPort string, onuID int, build func(port int) []string) ([]string, error) {
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available")
+	}
+	frame, slot, port, err := parsePONPort(ponPort)
+	if err != nil {
+		return nil, err
+	}
+	built := build(port)
+	commands := []string{"enable", "config", fmt.Sprintf("interface gpon %d/%d", frame, slot)}
+	commands = append(commands, built...)
+	commands = append(commands, "quit", "quit")
+
+	outputs, err := a.cliExecutor.ExecCommands(ctx, commands)
+	output := strings.Join(outputs, "\n")
+	if err == nil {
+		if m := reHWFailure.FindStringSubmatch(output); m != nil {
+			err = errors.New(m[1])
+		}
+	}
+	if err != nil && (strings.Contains(output, "does not exist") || strings.Contains(err.Error(), "does not exist")) {
+		return nil, &types.HumanError{
+			Code:    types.ErrCodeONUNotFound,
+			Message: fmt.Sprintf("ONT %d on port %s not found", onuID, ponPort),
+			Vendor:  "huawei",
+			Raw:     output,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	results := make([]string, len(built))
+	for i := range built {
+		if 3+i < len(outputs) {
+			results[i] = outputs[3+i]
+		}
+	}
+	return results, nil
+}
+
 // ============================================================================
 // DriverV2 Interface Implementation
 // ============================================================================
@@ -1020,18 +1299,32 @@ func (a *Adapter) parseSubscriberID(subscriberID string) (frame, slot, port, ont
 // interface expected by nano-agent CLI commands.
 
 // GetONUList returns all provisioned ONUs matching the filter.
-// Adapts the existing BulkScanONUsSNMP() method to DriverV2 format.
+// Adapts the existing BulkScanONUsSNMP() method to DriverV2 format. A PON
+// port filter, or a complete serial the CLI can look up, limits the walks
+// to the ONUs of one port.
 func (a *Adapter) GetONUList(ctx context.Context, filter *types.ONUFilter) ([]types.ONUInfo, error) {
 	if a.snmpExecutor == nil {
 		return nil, fmt.Errorf("SNMP executor not available - Huawei requires SNMP for ONU listing")
 	}
 
-	// Use existing bulk scan method
-	onts, err := a.BulkScanONUsSNMP(ctx)
+	scope, ok, err := a.onuListScope(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []types.ONUInfo{}, nil
+	}
+	onts, err := a.scanONUsSNMP(ctx, scope)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan ONUs: %w", err)
 	}
 
+	// Serials match in any representation and case, or by part
+	var serial string
+	if filter != nil {
+		serial = common.SerialKey(filter.Serial)
+	}
+
 	// Convert to DriverV2 format
 	results := make([]types.ONUInfo, 0, len(onts))
 	for _, ont := range onts {
@@ -1082,7 +1375,7 @@ func (a *Adapter) GetONUList(ctx context.Context, filter *types.ONUFilter) ([]ty
 					continue
 				}
 			}
-			if filter.Serial != "" && !strings.Contains(ont.Serial, filter.Serial) {
+			if serial != "" && !strings.Contains(common.SerialKey(ont.Serial), serial) {
 				continue
 			}
 		}
@@ -1090,26 +1383,144 @@ func (a *Adapter) GetONUList(ctx context.Context, filter *types.ONUFilter) ([]ty
 		results = append(results, info)
 	}
 
-	return results, nil
+	return types.PageONUs(results, filter), nil
 }
 
-// GetONUBySerial finds a specific ONU by serial number.
+// onuListScope returns the SNMP port index the ONUs of filter are on: that
+// of its PON port, or of the port of its serial if it is a complete serial
+// and the CLI is available to look it up. The scope is empty if the ONUs
+// may be on any port, and ok is false if no ONU can match.
+func (a *Adapter) onuListScope(ctx context.Context, filter *types.ONUFilter) (scope string, ok bool, err error) {
+	if filter == nil {
+		return "", true, nil
+	}
+	ponPort := filter.PONPort
+	if ponPort == "" && filter.Serial != "" && a.cliExecutor != nil && common.ValidateSerial(filter.Serial) == nil {
+		onu, err := a.getONUBySerialCLI(ctx, filter.Serial)
+		switch {
+		case err != nil:
+			// Walk all ports rather than fail the listing
+			a.config.Log().Debug("huawei: ONT lookup by serial failed", "serial", filter.Serial, "error", err)
+		case onu == nil:
+			return "", false, nil
+		default:
+			ponPort = onu.PONPort
+		}
+	}
+	if ponPort == "" {
+		return "", true, nil
+	}
+	frame, slot, port, err := parsePONPort(ponPort)
+	if err != nil {
+		return "", false, err
+	}
+	return strconv.Itoa(frame<<16 | slot<<8 | port), true, nil
+}
+
+// GetONUBySerial finds a specific ONU by serial number, in ASCII or hex
+// form. SNMP is preferred as it reports optical levels; without it the ONU
+// is read with `display ont info by-sn`. Returns nil if not found.
 func (a *Adapter) GetONUBySerial(ctx context.Context, serial string) (*types.ONUInfo, error) {
+	serial = common.DecodeHexSerial(serial)
+	if a.snmpExecutor == nil && a.cliExecutor != nil {
+		return a.getONUBySerialCLI(ctx, serial)
+	}
 	filter := &types.ONUFilter{Serial: serial}
 	onus, err := a.GetONUList(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(onus) == 0 {
-		return nil, nil // Not found
+	for i := range onus {
+		if common.SerialsEqual(onus[i].Serial, serial) {
+			a.addLastDown(ctx, &onus[i])
+			return &onus[i], nil
+		}
+	}
+	return nil, nil // Not found
+}
+
+// addLastDown adds the last down cause and time of an ONU read over SNMP,
+// which are only shown by display ont info. Errors are ignored as the ONU
+// is complete without them.
+func (a *Adapter) addLastDown(ctx context.Context, onu *types.ONUInfo) {
+	if a.cliExecutor == nil {
+		return
+	}
+	frame, slot, port, err := parsePONPort(onu.PONPort)
+	if err != nil {
+		return
 	}
+	output, err := a.cliExecutor.ExecCommand(ctx, fmt.Sprintf("display ont info %d/%d %d %d", frame, slot, port, onu.ONUID))
+	if err == nil {
+		common.ParseLastDown(onu, output)
+	}
+}
 
-	return &onus[0], nil
+// getONUBySerialCLI reads an ONU with `display ont info by-sn`, which takes
+// the hex form of the serial
+func (a *Adapter) getONUBySerialCLI(ctx context.Context, serial string) (*types.ONUInfo, error) {
+	hexSerial, err := common.SerialToHex(serial)
+	if err != nil {
+		return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	output, err := a.cliExecutor.ExecCommand(ctx, "display ont info by-sn "+hexSerial)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ONU by serial: %w", err)
+	}
+	if strings.Contains(output, "does not exist") {
+		return nil, nil
+	}
+	if m := reHWFailure.FindStringSubmatch(output); m != nil {
+		return nil, fmt.Errorf("failed to get ONU by serial: %s", m[1])
+	}
+	return parseONTInfoBySN(output), nil
 }
 
-// DiscoverONUs finds unprovisioned ONUs on the OLT.
-// Adapts the existing DiscoverONTs() method to new DriverV2 signature.
+// parseONTInfoBySN parses the "key : value" lines of `display ont info
+// by-sn`, which adds F/S/P and ONT-ID to the fields of `display ont info`
+func parseONTInfoBySN(output string) *types.ONUInfo {
+	onu := &types.ONUInfo{}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "F/S/P":
+			onu.PONPort = value
+		case "ONT-ID":
+			onu.ONUID, _ = strconv.Atoi(value)
+		case "Control flag":
+			onu.AdminState = "enabled"
+			if value != "active" {
+				onu.AdminState = "disabled"
+			}
+		case "Run state":
+			onu.OperState = value
+			onu.IsOnline = value == "online"
+		case "SN":
+			// 48575443A1B2C3D4 (HWTC-A1B2C3D4)
+			if fields := strings.Fields(value); len(fields) > 0 {
+				onu.Serial = common.DecodeHexSerial(fields[0])
+			}
+		case "ONT distance(m)":
+			onu.DistanceM, _ = strconv.Atoi(value)
+		case "Line profile ID":
+			onu.LineProfile = value
+		case "Service profile ID":
+			onu.ServiceProfile = value
+		}
+	}
+	common.ParseLastDown(onu, output)
+	return onu
+}
+
+// DiscoverONUs finds unprovisioned ONUs on the OLT, on ponPorts (F/S/P) or
+// on all ports if empty. It adapts DiscoverONTs to the DriverV2 format:
+// serials are returned in ASCII form like on other vendors, the hex form
+// shown by the OLT being kept in the metadata.
 func (a *Adapter) DiscoverONUs(ctx context.Context, ponPorts []string) ([]types.ONUDiscovery, error) {
 	if a.cliExecutor == nil {
 		return nil, fmt.Errorf("CLI executor not available - Huawei requires CLI for discovery")
@@ -1121,38 +1532,34 @@ func (a *Adapter) DiscoverONUs(ctx context.Context, ponPorts []string) ([]types.
 		return nil, fmt.Errorf("failed to discover ONTs: %w", err)
 	}
 
+	portSet := make(map[string]bool, len(ponPorts))
+	for _, p := range ponPorts {
+		portSet[p] = true
+	}
+
 	// Convert to DriverV2 format
 	results := make([]types.ONUDiscovery, 0, len(discoveries))
 	for _, disc := range discoveries {
 		ponPort := fmt.Sprintf("%d/%d/%d", disc.Frame, disc.Slot, disc.Port)
-
-		// Filter by PON ports if specified
-		if len(ponPorts) > 0 {
-			found := false
-			for _, filterPort := range ponPorts {
-				if filterPort == ponPort {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
-			}
+		if len(portSet) > 0 && !portSet[ponPort] {
+			continue
 		}
 
 		discovery := types.ONUDiscovery{
 			PONPort:      ponPort,
-			Serial:       disc.Serial,
+			Serial:       common.DecodeHexSerial(disc.Serial),
 			Model:        disc.EquipID,
+			Vendor:       disc.VendorID,
 			DistanceM:    disc.Distance,
 			RxPowerDBm:   disc.RxPower,
 			DiscoveredAt: disc.Timestamp,
 			Metadata: map[string]interface{}{
-				"frame":    disc.Frame,
-				"slot":     disc.Slot,
-				"port":     disc.Port,
-				"loid":     disc.LOID,
-				"equip_id": disc.EquipID,
+				"frame":      disc.Frame,
+				"slot":       disc.Slot,
+				"port":       disc.Port,
+				"loid":       disc.LOID,
+				"equip_id":   disc.EquipID,
+				"serial_hex": disc.Serial,
 			},
 		}
 
@@ -1178,6 +1585,7 @@ func (a *Adapter) RunDiagnostics(ctx context.Context, ponPort string, onuID int)
 	stats, err := a.GetSubscriberStats(ctx, subscriberID)
 	if err != nil {
 		// Non-fatal, continue with status only
+		a.config.Log().Warn("huawei: diagnostics stats unavailable", "subscriber", subscriberID, "error", err)
 		stats = &types.SubscriberStats{}
 	}
 
@@ -1228,10 +1636,19 @@ func (a *Adapter) RunDiagnostics(ctx context.Context, ponPort string, onuID int)
 		}
 	}
 
+	if q, err := a.GetONULineQuality(ctx, ponPort, onuID); err == nil {
+		diag.LineQuality = q
+	}
+
 	return diag, nil
 }
 
-// GetOLTStatus returns comprehensive OLT status.
+// GetOLTStatus returns comprehensive OLT status. System metrics, ONU counts
+// and PON ports come from SNMP; without SNMP the version, product and
+// uptime are read from display version and the PON ports from display
+// port state. Boards come from display board, and
+// the CPU usage of the active control board from display cpu if SNMP did
+// not report it. A failed board is reported as unhealthy.
 func (a *Adapter) GetOLTStatus(ctx context.Context) (*types.OLTStatus, error) {
 	status := &types.OLTStatus{
 		OLTID:       a.config.Name,
@@ -1243,81 +1660,143 @@ func (a *Adapter) GetOLTStatus(ctx context.Context) (*types.OLTStatus, error) {
 		Metadata:    make(map[string]interface{}),
 	}
 
-	// Get ONU counts via SNMP
+	snmpOK := false
 	if a.snmpExecutor != nil {
-		onts, err := a.BulkScanONUsSNMP(ctx)
-		if err == nil {
-			status.TotalONUs = len(onts)
-			activeCount := 0
-			for _, ont := range onts {
-				if ont.IsOnline {
-					activeCount++
+		snmpOK = a.addSystemStatusSNMP(ctx, status)
+
+		// ONU counts and PON ports from one ONU scan
+		onus, err := a.GetONUList(ctx, nil)
+		if err != nil {
+			status.Metadata["onu_error"] = err.Error()
+			onus = []types.ONUInfo{}
+		} else {
+			status.TotalONUs = len(onus)
+			for _, onu := range onus {
+				if onu.IsOnline {
+					status.ActiveONUs++
 				}
 			}
-			status.ActiveONUs = activeCount
+		}
+		ports, err := a.listPorts(ctx, onus)
+		if err != nil {
+			status.Metadata["port_error"] = err.Error()
+		}
+		for _, port := range ports {
+			status.PONPorts = append(status.PONPorts, *port)
 		}
 	}
 
-	// Try to get system information via SNMP
-	if a.snmpExecutor != nil {
-		// Query standard MIB-II system OIDs and Huawei SmartAX telemetry OIDs
-		systemOIDs := []string{
-			OIDSysDescr,
-			OIDSysUpTime,
-			OIDSysName,
-			OIDSmartAXCPU,
-			OIDSmartAXMemory,
-			OIDSmartAXTemperature,
+	if a.cliExecutor == nil {
+		return status, nil
+	}
+
+	if !snmpOK {
+		output, err := a.cliExecutor.ExecCommand(ctx, "display version")
+		if err != nil {
+			status.IsReachable = false
+			status.IsHealthy = false
+			return status, nil
 		}
+		version, product, uptime := parseDisplayVersion(output)
+		status.Firmware = version
+		status.UptimeSeconds = uptime
+		if product != "" {
+			status.Model = product
+		}
+	}
 
-		results, err := a.snmpExecutor.BulkGetSNMP(ctx, systemOIDs)
-		if err == nil {
-			// Parse uptime (in hundredths of seconds)
-			if uptime, ok := GetSNMPResult(results, OIDSysUpTime); ok {
-				if uptimeVal, ok := ParseNumericSNMPValue(uptime); ok {
-					status.UptimeSeconds = int64(uptimeVal / 100)
+	boards, err := a.getBoards(ctx)
+	if err != nil {
+		status.Metadata["board_error"] = err.Error()
+		return status, nil
+	}
+	status.Metadata["boards"] = boards
+	if a.snmpExecutor == nil {
+		ports, err := a.listPortsCLI(ctx, boards, nil)
+		if err != nil {
+			status.Metadata["port_error"] = err.Error()
+		}
+		for _, port := range ports {
+			status.PONPorts = append(status.PONPorts, *port)
+		}
+	}
+	for _, board := range boards {
+		if !board.IsNormal() {
+			status.IsHealthy = false
+		}
+		if board.IsActiveControl() && status.CPUPercent == 0 {
+			status.CPUPercent = board.CPUPercent
+			if status.CPUPercent == 0 {
+				if cpu, err := a.getCPUCLI(ctx, board.Slot); err == nil {
+					status.CPUPercent = cpu
 				}
 			}
+		}
+	}
 
-			// Parse firmware from sysDescr
-			if descr, ok := GetSNMPResult(results, OIDSysDescr); ok {
-				if descrStr, ok := descr.(string); ok {
-					status.Metadata["sys_descr"] = descrStr
-					// Try to extract version from description
-					versionRe := reHWVersionString
-					if match := versionRe.FindStringSubmatch(descrStr); len(match) > 1 {
-						status.Firmware = match[1]
-					}
-				}
-			}
+	return status, nil
+}
 
-			// Parse CPU utilization
-			if cpu, ok := GetSNMPResult(results, OIDSmartAXCPU); ok {
-				if cpuVal, ok := ParseNumericSNMPValue(cpu); ok {
-					status.CPUPercent = cpuVal
-				}
-			}
+// addSystemStatusSNMP adds the uptime, firmware, CPU, memory and
+// temperature of the SmartAX system MIB to status, reporting whether the
+// OLT answered
+func (a *Adapter) addSystemStatusSNMP(ctx context.Context, status *types.OLTStatus) bool {
+	// Query standard MIB-II system OIDs and Huawei SmartAX telemetry OIDs
+	systemOIDs := []string{
+		OIDSysDescr,
+		OIDSysUpTime,
+		OIDSysName,
+		OIDSmartAXCPU,
+		OIDSmartAXMemory,
+		OIDSmartAXTemperature,
+	}
 
-			// Parse memory utilization
-			if mem, ok := GetSNMPResult(results, OIDSmartAXMemory); ok {
-				if memVal, ok := ParseNumericSNMPValue(mem); ok {
-					status.MemoryPercent = memVal
-				}
-			}
+	results, err := a.snmpExecutor.BulkGetSNMP(ctx, systemOIDs)
+	if err != nil {
+		status.Metadata["snmp_error"] = err.Error()
+		return false
+	}
 
-			// Parse board temperature
-			if temp, ok := GetSNMPResult(results, OIDSmartAXTemperature); ok {
-				if tempVal, ok := ParseNumericSNMPValue(temp); ok {
-					status.Temperature = tempVal
-				}
+	// Parse uptime (in hundredths of seconds)
+	if uptime, ok := GetSNMPResult(results, OIDSysUpTime); ok {
+		if uptimeVal, ok := ParseNumericSNMPValue(uptime); ok {
+			status.UptimeSeconds = int64(uptimeVal / 100)
+		}
+	}
+
+	// Parse firmware from sysDescr
+	if descr, ok := GetSNMPResult(results, OIDSysDescr); ok {
+		if descrStr, ok := descr.(string); ok {
+			status.Metadata["sys_descr"] = descrStr
+			// Try to extract version from description
+			if match := reHWVersionString.FindStringSubmatch(descrStr); len(match) > 1 {
+				status.Firmware = match[1]
 			}
 		}
 	}
 
-	// TODO: Query PON port status if needed
-	// This would require walking GPON interface table via SNMP
+	// Parse CPU utilization
+	if cpu, ok := GetSNMPResult(results, OIDSmartAXCPU); ok {
+		if cpuVal, ok := ParseNumericSNMPValue(cpu); ok {
+			status.CPUPercent = cpuVal
+		}
+	}
 
-	return status, nil
+	// Parse memory utilization
+	if mem, ok := GetSNMPResult(results, OIDSmartAXMemory); ok {
+		if memVal, ok := ParseNumericSNMPValue(mem); ok {
+			status.MemoryPercent = memVal
+		}
+	}
+
+	// Parse board temperature
+	if temp, ok := GetSNMPResult(results, OIDSmartAXTemperature); ok {
+		if tempVal, ok := ParseNumericSNMPValue(temp); ok {
+			status.Temperature = tempVal
+		}
+	}
+
+	return true
 }
 
 // GetPONPower returns optical power readings for a PON port.
@@ -1478,7 +1957,8 @@ func (a *Adapter) GetONUDistance(ctx context.Context, ponPort string, onuID int)
 	return -1, nil
 }
 
-// RestartONU triggers a reboot of the specified ONU.
+// RestartONU reboots an ONT with ont reset, then verifies with display ont
+// info that it went offline and registered again.
 func (a *Adapter) RestartONU(ctx context.Context, ponPort string, onuID int) (*types.RestartONUResult, error) {
 	result := &types.RestartONUResult{
 		Success: false,
@@ -1490,46 +1970,96 @@ func (a *Adapter) RestartONU(ctx context.Context, ponPort string, onuID int) (*t
 		return result, fmt.Errorf("CLI executor not available")
 	}
 
-	// Parse PON port (format: frame/slot/port, e.g., "0/0/1")
-	parts := strings.Split(ponPort, "/")
-	if len(parts) != 3 {
-		result.Error = fmt.Sprintf("invalid PON port format: %s (expected frame/slot/port)", ponPort)
+	// PON port format: frame/slot/port, e.g., "0/0/1"
+	frame, slot, port, err := parsePONPort(ponPort)
+	if err != nil {
+		result.Error = err.Error()
 		result.Message = "Invalid PON port format"
-		return result, fmt.Errorf("invalid PON port format: %s (expected frame/slot/port)", ponPort)
-	}
-
-	frame, _ := strconv.Atoi(parts[0])
-	slot, _ := strconv.Atoi(parts[1])
-	port, _ := strconv.Atoi(parts[2])
-
-	commands := []string{
-		"enable",
-		"config",
-		fmt.Sprintf("interface gpon %d/%d", frame, slot),
-		fmt.Sprintf("ont reset %d %d", port, onuID),
-		"quit",
-		"quit",
-		"quit",
+		return result, err
 	}
 
-	_, err := a.cliExecutor.ExecCommands(ctx, commands)
+	// ont reset reboots the ONT, which takes the place of deactivate and
+	// activate
+	err = a.execONTCommands(ctx, ponPort, onuID, func(port int) []string {
+		return []string{fmt.Sprintf("ont reset %d %d", port, onuID)}
+	})
 	if err != nil {
 		result.Error = err.Error()
 		result.Message = "Failed to send reset command"
 		return result, err
 	}
-
-	result.Success = true
 	result.DeactivateSuccess = true
 	result.ActivateSuccess = true
-	result.Message = "ONT reset command sent successfully"
+
+	runState := func(ctx context.Context) string {
+		output, err := a.cliExecutor.ExecCommand(ctx, fmt.Sprintf("display ont info %d/%d %d %d", frame, slot, port, onuID))
+		if err != nil {
+			return ""
+		}
+		return a.parseONTStatus(output, "").State
+	}
+
+	// Verify the ONT went offline, then that it is back online
+	verified, retries := common.WaitONUState(ctx, common.RestartOfflineWaits, func(ctx context.Context) bool {
+		return runState(ctx) == "offline"
+	})
+	result.DeactivateVerified = verified
+	result.RetryCount += retries
+	verified, retries = common.WaitONUState(ctx, common.RestartOnlineWaits, func(ctx context.Context) bool {
+		return runState(ctx) == "online"
+	})
+	result.ActivateVerified = verified
+	result.RetryCount += retries
+
+	common.FinishRestart(result)
 	return result, nil
 }
 
-// RestartOLT triggers a full reboot of the Huawei OLT device.
-// TODO: Implement once verified on real Huawei OLT hardware.
-// Likely command: enable → config → reboot (in system view).
-func (a *Adapter) RestartOLT(ctx context.Context) (*types.RestartOLTResult, error) {
+// FactoryResetONU restores the factory configuration of an ONT with ont
+// factory-setting-restore, then polls display ont info until the ONT is
+// online again.
+func (a *Adapter) FactoryResetONU(ctx context.Context, ponPort string, onuID int, opts *types.FactoryResetOptions) (*types.FactoryResetResult, error) {
+	if err := types.CheckFactoryReset(opts, "huawei"); err != nil {
+		return nil, err
+	}
+	result := &types.FactoryResetResult{}
+	err := a.execONTCommands(ctx, ponPort, onuID, func(port int) []string {
+		return []string{fmt.Sprintf("ont factory-setting-restore %d %d", port, onuID)}
+	})
+	if err != nil {
+		result.Error = err.Error()
+		result.Message = "Failed to send factory reset command"
+		return result, fmt.Errorf("huawei ONT factory reset failed: %w", err)
+	}
+	result.ResetSent = true
+
+	if opts.SkipsVerification() {
+		result.Success = true
+		result.Message = "ONT factory reset command sent; re-registration not verified"
+		return result, nil
+	}
+	frame, slot, port, _ := parsePONPort(ponPort)
+	result.Reregistered = opts.AwaitReregistration(ctx, func(ctx context.Context) (bool, error) {
+		output, err := a.cliExecutor.ExecCommand(ctx, fmt.Sprintf("display ont info %d/%d %d %d", frame, slot, port, onuID))
+		if err != nil {
+			return false, err
+		}
+		return a.parseONTStatus(output, "").IsOnline, nil
+	})
+	if !result.Reregistered {
+		result.Error = "ONT did not register again"
+		result.Message = "ONT factory reset command sent but the ONT did not come back online"
+		return result, nil
+	}
+	result.Success = true
+	result.Message = "ONT reset to factory settings and registered again"
+	return result, nil
+}
+
+// RestartOLT triggers a full reboot of the Huawei OLT device.
+// TODO: Implement once verified on real Huawei OLT hardware.
+// Likely command: enable → config → reboot (in system view).
+func (a *Adapter) RestartOLT(ctx context.Context) (*types.RestartOLTResult, error) {
 	return &types.RestartOLTResult{
 		Success: false,
 		Error:   "not yet implemented for Huawei (needs lab verification)",
@@ -1537,7 +2067,9 @@ func (a *Adapter) RestartOLT(ctx context.Context) (*types.RestartOLTResult, erro
 	}, fmt.Errorf("RestartOLT not yet implemented for Huawei")
 }
 
-// ApplyProfile applies a bandwidth/service profile to an ONU.
+// ApplyProfile applies a bandwidth/service profile to an ONU: its line and
+// service profiles, the native VLAN of its first Ethernet port and the
+// traffic table of its bandwidth, in one interface gpon session.
 func (a *Adapter) ApplyProfile(ctx context.Context, ponPort string, onuID int, profile *types.ONUProfile) error {
 	if a.cliExecutor == nil {
 		return fmt.Errorf("CLI executor not available - Huawei requires CLI for profile management")
@@ -1547,23 +2079,13 @@ func (a *Adapter) ApplyProfile(ctx context.Context, ponPort string, onuID int, p
 		return fmt.Errorf("profile cannot be nil")
 	}
 
-	// Parse PON port (format: frame/slot/port)
-	parts := strings.Split(ponPort, "/")
-	if len(parts) != 3 {
-		return fmt.Errorf("invalid PON port format: %s (expected frame/slot/port)", ponPort)
-	}
-
-	frame, err := strconv.Atoi(parts[0])
+	frame, slot, port, err := parsePONPort(ponPort)
 	if err != nil {
-		return fmt.Errorf("invalid frame number: %s", parts[0])
+		return err
 	}
-	slot, err := strconv.Atoi(parts[1])
+	trafficTableID, err := a.profileTrafficTable(ctx, profile)
 	if err != nil {
-		return fmt.Errorf("invalid slot number: %s", parts[1])
-	}
-	port, err := strconv.Atoi(parts[2])
-	if err != nil {
-		return fmt.Errorf("invalid port number: %s", parts[2])
+		return fmt.Errorf("failed to apply profile: %w", err)
 	}
 
 	commands := []string{
@@ -1572,83 +2094,140 @@ func (a *Adapter) ApplyProfile(ctx context.Context, ponPort string, onuID int, p
 		fmt.Sprintf("interface gpon %d/%d", frame, slot),
 	}
 
-	// Update line profile if specified
+	// ont modify accepts profile names as well as IDs
 	if profile.LineProfile != "" {
-		// Try to use profile name directly (ont modify supports both name and ID)
 		commands = append(commands,
-			fmt.Sprintf("ont modify %d %d ont-lineprofile-name %s", port, onuID, common.SanitizeCLIParam(profile.LineProfile)))
+			fmt.Sprintf("ont modify %d %d %s", port, onuID, profileParam("ont-lineprofile", profile.LineProfile)))
 	}
-
-	// Update service profile if specified
 	if profile.ServiceProfile != "" {
 		commands = append(commands,
-			fmt.Sprintf("ont modify %d %d ont-srvprofile-name %s", port, onuID, common.SanitizeCLIParam(profile.ServiceProfile)))
+			fmt.Sprintf("ont modify %d %d %s", port, onuID, profileParam("ont-srvprofile", profile.ServiceProfile)))
+	}
+	commands = append(commands, ontProfileCommands(port, onuID, profile, trafficTableID)...)
+	commands = append(commands, "quit", "quit")
+
+	output, err := a.execConfigCommands(ctx, commands)
+	if err != nil {
+		switch failureCode(output + "\n" + err.Error()) {
+		case types.ErrCodeProfileNotFound:
+			return &types.HumanError{
+				Code:    types.ErrCodeProfileNotFound,
+				Message: fmt.Sprintf("Profile not found: %s / %s", profile.LineProfile, profile.ServiceProfile),
+				Vendor:  "huawei",
+				Raw:     output,
+			}
+		case types.ErrCodeONUNotFound:
+			return &types.HumanError{
+				Code:    types.ErrCodeONUNotFound,
+				Message: fmt.Sprintf("ONT %d on port %s not found", onuID, ponPort),
+				Vendor:  "huawei",
+				Raw:     output,
+			}
+		}
+		return fmt.Errorf("failed to apply profile: %w", err)
 	}
 
-	// Update VLAN if specified
+	return nil
+}
+
+// ontProfileCommands builds the interface gpon commands that set the
+// native VLAN and traffic policy of an ONT from profile. A traffic table
+// ID of 0 binds no traffic policy.
+func ontProfileCommands(port, ontID int, profile *types.ONUProfile, trafficTableID int) []string {
+	var commands []string
 	if profile.VLAN > 0 {
 		priority := profile.Priority
 		if priority < 0 || priority > 7 {
 			priority = 0
 		}
+		// ont port native-vlan <port> <ont-id> eth <eth-port> vlan <vlan> priority <0-7>
 		commands = append(commands,
-			fmt.Sprintf("ont port native-vlan %d %d eth 1 vlan %d priority %d", port, onuID, profile.VLAN, priority))
+			fmt.Sprintf("ont port native-vlan %d %d eth 1 vlan %d priority %d", port, ontID, profile.VLAN, priority))
 	}
-
-	commands = append(commands, "quit")
-
-	// Apply bandwidth/traffic profile if specified
-	if profile.BandwidthDown > 0 || profile.BandwidthUp > 0 {
-		// Look up traffic table ID from profile metadata or use bandwidth-based ID
-		trafficTableID := 1
-		if profile.Metadata != nil {
-			if id, ok := profile.Metadata["traffic_table_id"].(int); ok {
-				trafficTableID = id
-			}
-		}
-		// Fallback: use bandwidth in Mbps as table ID (common Huawei convention)
-		if trafficTableID == 1 && profile.BandwidthDown > 0 {
-			trafficTableID = profile.BandwidthDown / 1000 // Convert kbps to Mbps
-			if trafficTableID == 0 {
-				trafficTableID = 1
-			}
-		}
-
+	if trafficTableID > 0 {
+		// ont traffic-policy <port> <ont-id> profile-id <id>
 		commands = append(commands,
-			fmt.Sprintf("interface gpon %d/%d", frame, slot),
-			fmt.Sprintf("ont traffic-policy %d %d profile-id %d", port, onuID, trafficTableID),
-			"quit")
+			fmt.Sprintf("ont traffic-policy %d %d profile-id %d", port, ontID, trafficTableID))
 	}
+	return commands
+}
 
-	commands = append(commands, "quit")
+// profileTrafficTable returns the traffic table of the bandwidth of
+// profile, 0 if it sets none. Metadata "traffic_table_id" selects a table
+// explicitly; otherwise the table is resolved as for a service tier, the
+// bandwidth rounded up to whole Mbps.
+func (a *Adapter) profileTrafficTable(ctx context.Context, profile *types.ONUProfile) (int, error) {
+	if id, ok := profile.Metadata["traffic_table_id"].(int); ok {
+		return id, nil
+	}
+	if profile.BandwidthDown <= 0 && profile.BandwidthUp <= 0 {
+		return 0, nil
+	}
+	tier := &model.ServiceTier{
+		Spec: model.ServiceTierSpec{
+			BandwidthDown: (max(profile.BandwidthDown, 0) + 999) / 1000,
+			BandwidthUp:   (max(profile.BandwidthUp, 0) + 999) / 1000,
+		},
+	}
+	tier, err := a.resolveTrafficTable(ctx, tier)
+	if err != nil {
+		return 0, err
+	}
+	return a.getTrafficTableID(tier), nil
+}
 
+// profileParam builds the profile argument of ont add and ont modify, by ID
+// for a numeric profile and by name otherwise
+func profileParam(kind, profile string) string {
+	if isNumeric(profile) {
+		return fmt.Sprintf("%s-id %s", kind, profile)
+	}
+	return fmt.Sprintf("%s-name %s", kind, common.SanitizeCLIParam(profile))
+}
+
+// execConfigCommands runs commands and returns their joined output. A
+// command the CLI rejects with "Failure:" fails the call.
+func (a *Adapter) execConfigCommands(ctx context.Context, commands []string) (string, error) {
 	outputs, err := a.cliExecutor.ExecCommands(ctx, commands)
+	output := strings.Join(outputs, "\n")
 	if err != nil {
-		output := strings.Join(outputs, "\n")
-		// Check for common errors
-		if strings.Contains(output, "does not exist") || strings.Contains(err.Error(), "does not exist") {
-			return &types.HumanError{
-				Code:    types.ErrCodeONUNotFound,
-				Message: fmt.Sprintf("ONT %d on port %s not found", onuID, ponPort),
-				Vendor:  "huawei",
-				Raw:     output,
-			}
-		}
-		if strings.Contains(output, "profile") && strings.Contains(output, "not exist") {
-			return &types.HumanError{
-				Code:    types.ErrCodeProfileNotFound,
-				Message: fmt.Sprintf("Profile not found: %s / %s", profile.LineProfile, profile.ServiceProfile),
-				Vendor:  "huawei",
-				Raw:     output,
-			}
-		}
-		return fmt.Errorf("failed to apply profile: %w", err)
+		return output, err
 	}
+	if m := reHWFailure.FindStringSubmatch(output); m != nil {
+		return output, errors.New(m[1])
+	}
+	return output, nil
+}
 
-	return nil
+// failureCode maps a Huawei CLI failure to its error code
+func failureCode(msg string) string {
+	msg = strings.ToLower(msg)
+	switch {
+	case strings.Contains(msg, "already exist"):
+		return types.ErrCodeONUExists
+	case strings.Contains(msg, "profile") && strings.Contains(msg, "not exist"):
+		return types.ErrCodeProfileNotFound
+	case strings.Contains(msg, "port does not exist"):
+		return types.ErrCodePortNotFound
+	case strings.Contains(msg, "does not exist") || strings.Contains(msg, "not found"):
+		return types.ErrCodeONUNotFound
+	case strings.Contains(msg, "upper limit"):
+		return types.ErrCodeONUFull
+	case strings.Contains(msg, "timeout"):
+		return types.ErrCodeTimeout
+	case strings.Contains(msg, "serial"):
+		return types.ErrCodeInvalidSerial
+	default:
+		return types.CodeForMessage(msg)
+	}
 }
 
-// BulkProvision provisions multiple ONUs in a single session.
+// BulkProvision adds the ONTs of operations in one config session. Each
+// ONT is added with the line and service profiles of its operation, given
+// the native VLAN and traffic policy of its profile and a service-port for
+// its VLAN; an ONT whose service configuration fails is deleted again.
+// Traffic tables are resolved before the session, once per bandwidth. The
+// OLT assigns the ONT ID of an operation without ONUID.
 func (a *Adapter) BulkProvision(ctx context.Context, operations []types.BulkProvisionOp) (*types.BulkResult, error) {
 	if a.cliExecutor == nil {
 		return nil, fmt.Errorf("CLI executor not available - Huawei requires CLI for provisioning")
@@ -1658,110 +2237,141 @@ func (a *Adapter) BulkProvision(ctx context.Context, operations []types.BulkProv
 		Results: make([]types.BulkOpResult, len(operations)),
 	}
 
+	// Creating a traffic table leaves config mode, so tables are resolved
+	// before entering it
+	trafficTables := make([]int, len(operations))
+	resolved := make(map[[2]int]int)
 	for i, op := range operations {
-		opResult := types.BulkOpResult{
-			Serial:   op.Serial,
-			PONPort:  op.PONPort,
-			ONUID:    op.ONUID,
-			Metadata: make(map[string]interface{}),
-		}
-
-		// Build subscriber from operation
-		subscriber := &model.Subscriber{
-			Name:        fmt.Sprintf("bulk-%s", op.Serial),
-			Annotations: make(map[string]string),
-			Spec: model.SubscriberSpec{
-				ONUSerial: op.Serial,
-			},
-		}
-
-		// Set PON port annotation
-		if op.PONPort != "" {
-			subscriber.Annotations["nanoncore.com/gpon-fsp"] = op.PONPort
+		if op.Profile == nil {
+			continue
 		}
-
-		// Set ONU ID annotation
-		if op.ONUID > 0 {
-			subscriber.Annotations["nanoncore.com/ont-id"] = strconv.Itoa(op.ONUID)
+		key := [2]int{op.Profile.BandwidthDown, op.Profile.BandwidthUp}
+		id, ok := resolved[key]
+		if _, explicit := op.Profile.Metadata["traffic_table_id"]; explicit || !ok {
+			var err error
+			if id, err = a.profileTrafficTable(ctx, op.Profile); err != nil {
+				return nil, fmt.Errorf("failed to resolve traffic table: %w", err)
+			}
+			if !explicit {
+				resolved[key] = id
+			}
 		}
+		trafficTables[i] = id
+	}
 
-		// Build tier from profile
-		var tier *model.ServiceTier
-		if op.Profile != nil {
-			subscriber.Spec.VLAN = op.Profile.VLAN
-
-			tier = &model.ServiceTier{
-				Name:        fmt.Sprintf("bulk-tier-%s", op.Serial),
-				Annotations: make(map[string]string),
-				Spec: model.ServiceTierSpec{
-					BandwidthDown: op.Profile.BandwidthDown / 1000, // Convert kbps to Mbps
-					BandwidthUp:   op.Profile.BandwidthUp / 1000,
-				},
-			}
+	// Enter config mode once
+	if _, err := a.cliExecutor.ExecCommands(ctx, []string{"enable", "config"}); err != nil {
+		return nil, fmt.Errorf("failed to enter config mode: %w", err)
+	}
+	defer func() { _, _ = a.cliExecutor.ExecCommand(ctx, "return") }()
 
-			// Set profile annotations if specified
-			if op.Profile.LineProfile != "" {
-				tier.Annotations["nanoncore.com/line-profile-name"] = op.Profile.LineProfile
-			}
-			if op.Profile.ServiceProfile != "" {
-				tier.Annotations["nanoncore.com/srv-profile-name"] = op.Profile.ServiceProfile
-			}
+	for i, op := range operations {
+		opResult := a.bulkAddONT(ctx, op, trafficTables[i])
+		if opResult.Success {
+			result.Succeeded++
 		} else {
-			// Default tier with no bandwidth limits
-			tier = &model.ServiceTier{
-				Name: fmt.Sprintf("bulk-tier-%s", op.Serial),
-				Spec: model.ServiceTierSpec{
-					BandwidthDown: 100, // Default 100 Mbps
-					BandwidthUp:   50,  // Default 50 Mbps
-				},
-			}
+			result.Failed++
 		}
+		result.Results[i] = opResult
+	}
 
-		// Call CreateSubscriber
-		subResult, err := a.CreateSubscriber(ctx, subscriber, tier)
-		if err != nil {
-			opResult.Success = false
-			opResult.Error = err.Error()
-
-			// Map error to code
-			switch {
-			case strings.Contains(err.Error(), "exists") || strings.Contains(err.Error(), "already"):
-				opResult.ErrorCode = types.ErrCodeONUExists
-			case strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "does not exist"):
-				opResult.ErrorCode = types.ErrCodeONUNotFound
-			case strings.Contains(err.Error(), "timeout"):
-				opResult.ErrorCode = types.ErrCodeTimeout
-			case strings.Contains(err.Error(), "serial"):
-				opResult.ErrorCode = types.ErrCodeInvalidSerial
-			default:
-				opResult.ErrorCode = types.ErrCodeUnknown
-			}
+	return result, nil
+}
 
-			result.Failed++
-		} else {
-			opResult.Success = true
-			result.Succeeded++
+// bulkAddONT adds the ONT of one bulk operation from config mode
+func (a *Adapter) bulkAddONT(ctx context.Context, op types.BulkProvisionOp, trafficTableID int) types.BulkOpResult {
+	opResult := types.BulkOpResult{
+		Serial:   op.Serial,
+		PONPort:  op.PONPort,
+		ONUID:    op.ONUID,
+		Metadata: make(map[string]interface{}),
+	}
+	fail := func(code string, err error) types.BulkOpResult {
+		opResult.ErrorCode = code
+		opResult.Error = err.Error()
+		return opResult
+	}
 
-			// Extract ONU ID from result metadata
-			if subResult != nil && subResult.Metadata != nil {
-				if ontID, ok := subResult.Metadata["ont_id"].(int); ok {
-					opResult.ONUID = ontID
-				}
-				opResult.Metadata["session_id"] = subResult.SessionID
-				opResult.Metadata["interface"] = subResult.InterfaceName
-			}
+	serial, err := common.NormalizeSerial(op.Serial)
+	if err != nil {
+		return fail(types.ErrCodeInvalidSerial, err)
+	}
+	frame, slot, port, err := parsePONPort(op.PONPort)
+	if err != nil {
+		return fail(types.ErrCodeValidationFailed, err)
+	}
+	profile := op.Profile
+	if profile == nil {
+		profile = &types.ONUProfile{}
+	}
+	lineProfile, srvProfile := profile.LineProfile, profile.ServiceProfile
+	if lineProfile == "" {
+		lineProfile = "1"
+	}
+	if srvProfile == "" {
+		srvProfile = "1"
+	}
+	iface := fmt.Sprintf("interface gpon %d/%d", frame, slot)
+
+	// ont add <port> [<ont-id>] sn-auth <serial> omci <line-profile> <srv-profile> desc <description>
+	ontID := ""
+	if op.ONUID > 0 {
+		ontID = fmt.Sprintf(" %d", op.ONUID)
+	}
+	add := fmt.Sprintf("ont add %d%s sn-auth %s omci %s %s desc nanoncore",
+		port, ontID, serial, profileParam("ont-lineprofile", lineProfile), profileParam("ont-srvprofile", srvProfile))
+	output, err := a.execConfigCommands(ctx, []string{iface, add, "quit"})
+	if err != nil {
+		return fail(failureCode(output+"\n"+err.Error()), err)
+	}
+	if op.ONUID == 0 {
+		m := reHWAddedONTID.FindStringSubmatch(output)
+		if m == nil {
+			return fail(types.ErrCodeUnknown, fmt.Errorf("OLT did not report the ONT ID assigned to %s", serial))
 		}
+		opResult.ONUID, _ = strconv.Atoi(m[1])
+	}
+	id := opResult.ONUID
 
-		result.Results[i] = opResult
+	commands := []string{iface}
+	commands = append(commands, ontProfileCommands(port, id, profile, trafficTableID)...)
+	commands = append(commands, "quit")
+	if profile.VLAN > 0 {
+		commands = append(commands, servicePortCommand(frame, slot, port, id, model.GEMPort{ID: 1, TCont: 1, VLAN: profile.VLAN}))
+	}
+	if len(commands) > 2 {
+		if output, err := a.execConfigCommands(ctx, commands); err != nil {
+			// Leave no half-configured ONT behind, back in config mode
+			_, _ = a.cliExecutor.ExecCommands(ctx, []string{
+				"return",
+				"config",
+				fmt.Sprintf("undo service-port port %d/%d/%d ont %d", frame, slot, port, id),
+				iface,
+				fmt.Sprintf("ont delete %d %d", port, id),
+				"quit",
+			})
+			return fail(failureCode(output+"\n"+err.Error()), err)
+		}
 	}
 
-	return result, nil
+	opResult.Success = true
+	opResult.Metadata["session_id"] = fmt.Sprintf("ont-%d/%d/%d-%d", frame, slot, port, id)
+	opResult.Metadata["interface"] = fmt.Sprintf("gpon %d/%d/%d ont %d", frame, slot, port, id)
+	return opResult
 }
 
-// GetAlarms returns active alarms from the OLT.
+// GetAlarms returns the active alarms of the OLT from hwAlarmTable. Without
+// SNMP, or if the walk fails, they are read from "display alarm active all".
 func (a *Adapter) GetAlarms(ctx context.Context) ([]types.OLTAlarm, error) {
+	if a.snmpExecutor != nil {
+		alarms, err := a.getAlarmsSNMP(ctx)
+		if err == nil || a.cliExecutor == nil {
+			return alarms, err
+		}
+		a.config.Log().Warn("huawei: alarm table walk failed, falling back to CLI", "error", err)
+	}
 	if a.cliExecutor == nil {
-		return nil, fmt.Errorf("CLI executor not available - Huawei requires CLI for alarm query")
+		return nil, fmt.Errorf("CLI executor not available - Huawei requires CLI or SNMP for alarm query")
 	}
 
 	output, err := a.cliExecutor.ExecCommand(ctx, "display alarm active all")
@@ -1772,6 +2382,94 @@ func (a *Adapter) GetAlarms(ctx context.Context) ([]types.OLTAlarm, error) {
 	return a.parseAlarms(output), nil
 }
 
+// getAlarmsSNMP reads the active alarms from hwAlarmTable. The alarm level
+// column lists every active alarm; the other columns are optional.
+func (a *Adapter) getAlarmsSNMP(ctx context.Context) ([]types.OLTAlarm, error) {
+	levels, err := a.snmpExecutor.WalkSNMP(ctx, OIDHwAlarmLevel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk alarm table: %w", err)
+	}
+	columns := make(map[string]map[string]interface{})
+	for _, oid := range []string{OIDHwAlarmID, OIDHwAlarmName, OIDHwAlarmSource, OIDHwAlarmRaiseTime, OIDHwAlarmDescription} {
+		values, err := a.snmpExecutor.WalkSNMP(ctx, oid)
+		if err != nil {
+			a.config.Log().Warn("huawei: alarm table column walk failed", "oid", oid, "error", err)
+			values = make(map[string]interface{})
+		}
+		columns[oid] = values
+	}
+	text := func(oid, index string) string {
+		value, _ := common.ParseStringSNMPValue(columns[oid][index])
+		return strings.TrimSpace(value)
+	}
+
+	alarms := make([]types.OLTAlarm, 0, len(levels))
+	for index, levelVal := range levels {
+		level, _ := common.ParseIntSNMPValue(levelVal)
+		alarm := types.OLTAlarm{
+			ID:       strings.TrimPrefix(index, "."),
+			Severity: ConvertAlarmLevel(level),
+			SourceID: text(OIDHwAlarmSource, index),
+			Message:  text(OIDHwAlarmDescription, index),
+			Metadata: map[string]interface{}{"level": level},
+		}
+		name := text(OIDHwAlarmName, index)
+		alarm.Type, alarm.Source = classifyAlarm(name)
+		if kind := alarmSourceKind(alarm.SourceID); kind != "" {
+			alarm.Source = kind
+		}
+		if alarm.Message == "" {
+			alarm.Message = name
+		}
+		if id, ok := common.ParseIntSNMPValue(columns[OIDHwAlarmID][index]); ok {
+			alarm.Metadata["alarm_id"] = id
+		}
+		if name != "" {
+			alarm.Metadata["name"] = name
+		}
+		raised, _ := common.ParseStringSNMPValue(columns[OIDHwAlarmRaiseTime][index])
+		if alarm.RaisedAt = ParseDateAndTime([]byte(raised)); alarm.RaisedAt.IsZero() {
+			alarm.RaisedAt = time.Now()
+		}
+		alarms = append(alarms, alarm)
+	}
+	sort.Slice(alarms, func(i, j int) bool { return alarms[i].RaisedAt.Before(alarms[j].RaisedAt) })
+	return alarms, nil
+}
+
+// classifyAlarm returns the normalized type of an alarm name and the
+// source it implies
+func classifyAlarm(name string) (alarmType, source string) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.Contains(lower, "los"):
+		return "los", "onu"
+	case strings.Contains(lower, "power"):
+		return "power", "onu"
+	case strings.Contains(lower, "dying"):
+		return "dying_gasp", "onu"
+	case strings.Contains(lower, "config"):
+		return "config", "system"
+	case strings.Contains(lower, "link"):
+		return "link", "port"
+	default:
+		return lower, "unknown"
+	}
+}
+
+// alarmSourceKind returns the source an alarm source ID names: an ONT for
+// "0/1/0:5", a port for "0/1/0", or "" if it cannot tell
+func alarmSourceKind(sourceID string) string {
+	switch {
+	case strings.Contains(sourceID, ":"):
+		return "onu"
+	case strings.Contains(sourceID, "/"):
+		return "port"
+	default:
+		return ""
+	}
+}
+
 // parseAlarms parses Huawei CLI output for active alarms.
 // Huawei alarm format varies by model, but typically:
 // Alarm ID   Severity   Type         Source            Time                    Description
@@ -1817,58 +2515,25 @@ func (a *Adapter) parseAlarms(output string) []types.OLTAlarm {
 		}
 
 		// Map severity
-		if len(fields) >= 2 {
-			severity := strings.ToLower(fields[1])
-			switch {
-			case strings.Contains(severity, "critical"):
-				alarm.Severity = "critical"
-			case strings.Contains(severity, "major"):
-				alarm.Severity = "major"
-			case strings.Contains(severity, "minor"):
-				alarm.Severity = "minor"
-			case strings.Contains(severity, "warning"):
-				alarm.Severity = "warning"
-			default:
-				alarm.Severity = "unknown"
-			}
-		}
-
-		// Map alarm type
-		if len(fields) >= 3 {
-			alarmType := strings.ToLower(fields[2])
-			switch {
-			case strings.Contains(alarmType, "los"):
-				alarm.Type = "los"
-				alarm.Source = "onu"
-			case strings.Contains(alarmType, "power"):
-				alarm.Type = "power"
-				alarm.Source = "onu"
-			case strings.Contains(alarmType, "dying"):
-				alarm.Type = "dying_gasp"
-				alarm.Source = "onu"
-			case strings.Contains(alarmType, "config"):
-				alarm.Type = "config"
-				alarm.Source = "system"
-			case strings.Contains(alarmType, "link"):
-				alarm.Type = "link"
-				alarm.Source = "port"
-			default:
-				alarm.Type = alarmType
-				alarm.Source = "unknown"
-			}
-		}
-
-		// Extract source ID (e.g., PON port or ONU identifier)
-		if len(fields) >= 4 {
-			alarm.SourceID = fields[3]
-			// Try to determine source type from ID format
-			if strings.Contains(alarm.SourceID, ":") {
-				// Format: 0/0/1:5 suggests ONU
-				alarm.Source = "onu"
-			} else if strings.Contains(alarm.SourceID, "/") {
-				// Format: 0/0/1 suggests port
-				alarm.Source = "port"
-			}
+		severity := strings.ToLower(fields[1])
+		switch {
+		case strings.Contains(severity, "critical"):
+			alarm.Severity = "critical"
+		case strings.Contains(severity, "major"):
+			alarm.Severity = "major"
+		case strings.Contains(severity, "minor"):
+			alarm.Severity = "minor"
+		case strings.Contains(severity, "warning"):
+			alarm.Severity = "warning"
+		default:
+			alarm.Severity = "unknown"
+		}
+
+		// Map alarm type, and the source type from the source ID format
+		alarm.Type, alarm.Source = classifyAlarm(fields[2])
+		alarm.SourceID = fields[3]
+		if kind := alarmSourceKind(alarm.SourceID); kind != "" {
+			alarm.Source = kind
 		}
 
 		// Try to parse timestamp (format: YYYY-MM-DD HH:MM:SS)
@@ -1905,13 +2570,102 @@ func (a *Adapter) parseAlarms(output string) []types.OLTAlarm {
 	return alarms
 }
 
-// ListPorts returns status for all PON ports on the OLT.
-// Uses SNMP to query interface status and counts ONUs per port.
+// GetRogueONUs implements types.RogueONUDetector with the OLT's rogue ONT
+// detection
+func (a *Adapter) GetRogueONUs(ctx context.Context) ([]types.RogueONU, error) {
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available - Huawei requires CLI for rogue ONT detection")
+	}
+
+	output, err := a.cliExecutor.ExecCommand(ctx, "display ont rogue")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rogue ONTs: %w", err)
+	}
+
+	return a.parseRogueONUs(output), nil
+}
+
+// parseRogueONUs parses Huawei rogue ONT output. The ONT ID and SN are "-"
+// when the OLT detected rogue light on the port but could not identify
+// the ONT:
+// F/S/P   ONT-ID  SN                Rogue type        Detected time
+// 0/1/0   5       485754430A2C4F13  continuous-mode   2024-01-15 10:30:00
+// 0/1/3   -       -                 unknown           2024-01-15 10:31:22
+func (a *Adapter) parseRogueONUs(output string) []types.RogueONU {
+	rogues := []types.RogueONU{}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !reHWFSP.MatchString(fields[0]) {
+			continue
+		}
+
+		rogue := types.RogueONU{
+			Kind:    types.RogueTransmitting,
+			PONPort: fields[0],
+			ONUID:   -1,
+			Detail:  fields[3],
+		}
+		if id, err := strconv.Atoi(fields[1]); err == nil {
+			rogue.ONUID = id
+		}
+		if fields[2] != "-" {
+			rogue.Serial = fields[2]
+		}
+		if len(fields) >= 6 {
+			if t, err := time.Parse("2006-01-02 15:04:05", fields[4]+" "+fields[5]); err == nil {
+				rogue.DetectedAt = t
+			}
+		}
+		rogues = append(rogues, rogue)
+	}
+
+	return rogues
+}
+
+// ListPorts returns status for all PON ports on the OLT. The interface
+// table is read over SNMP, with ONUs counted per port; without SNMP, or if
+// the walk fails or finds no PON ports, the ports of each PON board come
+// from display port state.
 func (a *Adapter) ListPorts(ctx context.Context) ([]*types.PONPortStatus, error) {
-	if a.snmpExecutor == nil {
-		return nil, fmt.Errorf("SNMP executor not available - Huawei requires SNMP for port listing")
+	if a.snmpExecutor == nil && a.cliExecutor == nil {
+		return nil, fmt.Errorf("no executor available for port listing")
 	}
 
+	// Get ONU list to count ONUs per port
+	onus := []types.ONUInfo{}
+	if a.snmpExecutor != nil {
+		var err error
+		onus, err = a.GetONUList(ctx, nil)
+		if err != nil {
+			// Non-fatal, continue without ONU counts
+			a.config.Log().Warn("huawei: ONU list unavailable for port counts", "error", err)
+			onus = []types.ONUInfo{}
+		}
+	}
+	return a.listPorts(ctx, onus)
+}
+
+// listPorts returns the status of the PON ports, counting the ONUs of each
+// port in onus
+func (a *Adapter) listPorts(ctx context.Context, onus []types.ONUInfo) ([]*types.PONPortStatus, error) {
+	if a.snmpExecutor != nil {
+		ports, err := a.listPortsSNMP(ctx, onus)
+		if err == nil && len(ports) > 0 || a.cliExecutor == nil {
+			return ports, err
+		}
+		a.config.Log().Warn("huawei: no PON ports over SNMP, using CLI", "error", err)
+	}
+	boards, err := a.getBoards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return a.listPortsCLI(ctx, boards, onus)
+}
+
+// listPortsSNMP returns the status of the PON ports from the interface
+// table
+func (a *Adapter) listPortsSNMP(ctx context.Context, onus []types.ONUInfo) ([]*types.PONPortStatus, error) {
 	// Walk interface descriptions to identify PON/GPON ports
 	descrResults, err := a.snmpExecutor.WalkSNMP(ctx, OIDIfDescr)
 	if err != nil {
@@ -1934,16 +2688,10 @@ func (a *Adapter) ListPorts(ctx context.Context) ([]*types.PONPortStatus, error)
 	aliasResults, err := a.snmpExecutor.WalkSNMP(ctx, OIDIfAlias)
 	if err != nil {
 		// Non-fatal, continue without aliases
+		a.config.Log().Warn("huawei: interface alias walk failed", "error", err)
 		aliasResults = make(map[string]interface{})
 	}
 
-	// Get ONU list to count ONUs per port
-	onus, err := a.GetONUList(ctx, nil)
-	if err != nil {
-		// Non-fatal, continue without ONU counts
-		onus = []types.ONUInfo{}
-	}
-
 	// Count ONUs per port
 	onuCountByPort := make(map[string]int)
 	for _, onu := range onus {
@@ -2030,38 +2778,22 @@ func (a *Adapter) parsePortFromDescr(descr string) string {
 	return ""
 }
 
-// SetPortState enables or disables a PON port administratively.
-// Uses CLI to execute shutdown/undo shutdown commands.
+// SetPortState enables or disables a PON port administratively with
+// undo shutdown or shutdown in the GPON interface of its board.
 func (a *Adapter) SetPortState(ctx context.Context, port string, enabled bool) error {
 	if a.cliExecutor == nil {
 		return fmt.Errorf("CLI executor not available - Huawei requires CLI for port management")
 	}
 
-	// Parse PON port (format: frame/slot/port, e.g., "0/0/1")
-	parts := strings.Split(port, "/")
-	if len(parts) != 3 {
-		return fmt.Errorf("invalid PON port format: %s (expected frame/slot/port)", port)
-	}
-
-	frame, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return fmt.Errorf("invalid frame number: %s", parts[0])
-	}
-	slot, err := strconv.Atoi(parts[1])
-	if err != nil {
-		return fmt.Errorf("invalid slot number: %s", parts[1])
-	}
-	portNum, err := strconv.Atoi(parts[2])
+	frame, slot, portNum, err := parsePONPort(port)
 	if err != nil {
-		return fmt.Errorf("invalid port number: %s", parts[2])
+		return err
 	}
 
-	// Build CLI commands
-	var portCmd string
+	// shutdown <port> / undo shutdown <port>
+	portCmd := fmt.Sprintf("shutdown %d", portNum)
 	if enabled {
-		portCmd = fmt.Sprintf("undo port %d shutdown", portNum)
-	} else {
-		portCmd = fmt.Sprintf("port %d shutdown", portNum)
+		portCmd = "undo " + portCmd
 	}
 
 	commands := []string{
@@ -2071,11 +2803,18 @@ func (a *Adapter) SetPortState(ctx context.Context, port string, enabled bool) e
 		portCmd,
 		"quit",
 		"quit",
-		"quit",
 	}
 
-	_, err = a.cliExecutor.ExecCommands(ctx, commands)
+	output, err := a.execConfigCommands(ctx, commands)
 	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "does not exist") {
+			return &types.HumanError{
+				Code:    types.ErrCodePortNotFound,
+				Message: fmt.Sprintf("PON port %s not found", port),
+				Vendor:  "huawei",
+				Raw:     output,
+			}
+		}
 		action := "enable"
 		if !enabled {
 			action = "disable"
@@ -2120,7 +2859,22 @@ func (a *Adapter) ListVLANs(ctx context.Context) ([]types.VLANInfo, error) {
 	return a.parseVLANList(output), nil
 }
 
-// parseVLANList parses Huawei CLI output for VLAN list.
+// huaweiVLANTypes are the VLAN types of the MA5600T/MA5800 CLI. A smart
+// VLAN isolates its service ports from each other; a mux VLAN carries one
+// service port; a standard VLAN has Ethernet ports only; a super VLAN
+// aggregates sub-VLANs.
+var huaweiVLANTypes = []string{"smart", "mux", "standard", "super"}
+
+// parseVLANList parses Huawei CLI output for VLAN list. Two layouts are
+// recognized: the display vlan all table of the OLT,
+//
+//	VLAN   Type     Attribute    STND Port NUM   SERV Port NUM   VLAN-Con NUM
+//	 100   smart    common                   1               2              -
+//
+// and a named layout with the service port count and description,
+//
+//	VLAN ID   Name                      Type      Service Ports   Description
+//	100       Customer_VLAN_100         smart     5               Customer traffic
 func (a *Adapter) parseVLANList(output string) []types.VLANInfo {
 	vlans := []types.VLANInfo{}
 
@@ -2145,7 +2899,6 @@ func (a *Adapter) parseVLANList(output string) []types.VLANInfo {
 			continue
 		}
 
-		// Parse VLAN line: "100       Customer_VLAN_100          smart     5               Customer traffic"
 		fields := strings.Fields(line)
 		if len(fields) < 2 {
 			continue
@@ -2156,6 +2909,27 @@ func (a *Adapter) parseVLANList(output string) []types.VLANInfo {
 			continue
 		}
 
+		// OLT layout: "100   smart    common   1   2   -"
+		if slices.Contains(huaweiVLANTypes, fields[1]) {
+			vlan := types.VLANInfo{
+				ID:       vlanID,
+				Type:     fields[1],
+				Metadata: map[string]interface{}{},
+			}
+			if len(fields) >= 3 {
+				vlan.Metadata["attribute"] = fields[2]
+			}
+			if len(fields) >= 4 {
+				vlan.Metadata["standard_port_count"], _ = strconv.Atoi(fields[3])
+			}
+			if len(fields) >= 5 {
+				vlan.ServicePortCount, _ = strconv.Atoi(fields[4])
+			}
+			vlans = append(vlans, vlan)
+			continue
+		}
+
+		// Named layout: "100       Customer_VLAN_100          smart     5               Customer traffic"
 		vlan := types.VLANInfo{
 			ID:   vlanID,
 			Name: fields[1],
@@ -2178,7 +2952,7 @@ func (a *Adapter) parseVLANList(output string) []types.VLANInfo {
 	return vlans
 }
 
-// GetVLAN retrieves a specific VLAN by ID.
+// GetVLAN retrieves a specific VLAN by ID, or nil if it does not exist.
 func (a *Adapter) GetVLAN(ctx context.Context, vlanID int) (*types.VLANInfo, error) {
 	if a.cliExecutor == nil {
 		return nil, fmt.Errorf("CLI executor not available")
@@ -2191,11 +2965,13 @@ func (a *Adapter) GetVLAN(ctx context.Context, vlanID int) (*types.VLANInfo, err
 	}
 
 	// Check if VLAN doesn't exist
-	if strings.Contains(output, "does not exist") || strings.Contains(output, "Error") {
+	if strings.Contains(output, "does not exist") || strings.Contains(output, "Error") || reHWFailure.MatchString(output) {
 		return nil, nil
 	}
 
-	// Parse single VLAN output
+	// Parse single VLAN output. The OLT prefixes its keys with "VLAN"
+	// ("VLAN name", "VLAN type") and reports the service ports as
+	// "Service virtual port number".
 	vlan := &types.VLANInfo{
 		ID:   vlanID,
 		Type: "smart",
@@ -2203,34 +2979,33 @@ func (a *Adapter) GetVLAN(ctx context.Context, vlanID int) (*types.VLANInfo, err
 
 	lines := strings.Split(output, "\n")
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "Name") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				vlan.Name = strings.TrimSpace(parts[1])
-			}
-		} else if strings.HasPrefix(line, "Description") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				vlan.Description = strings.TrimSpace(parts[1])
-			}
-		} else if strings.HasPrefix(line, "Service Port Count") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				vlan.ServicePortCount, _ = strconv.Atoi(strings.TrimSpace(parts[1]))
-			}
-		} else if strings.HasPrefix(line, "Type") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				vlan.Type = strings.TrimSpace(parts[1])
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(key)), "vlan ")
+		value = strings.TrimSpace(value)
+		switch key {
+		case "name":
+			vlan.Name = value
+		case "description", "desc":
+			vlan.Description = value
+		case "service port count", "service virtual port number":
+			vlan.ServicePortCount, _ = strconv.Atoi(value)
+		case "type":
+			if value != "" {
+				vlan.Type = value
 			}
+		case "attribute":
+			vlan.Metadata = map[string]interface{}{"attribute": value}
 		}
 	}
 
 	return vlan, nil
 }
 
-// CreateVLAN creates a new VLAN on the OLT.
+// CreateVLAN creates a new VLAN on the OLT, of the request type (smart by
+// default), and adds it to the uplink port if the request names one.
 func (a *Adapter) CreateVLAN(ctx context.Context, req *types.CreateVLANRequest) error {
 	if a.cliExecutor == nil {
 		return fmt.Errorf("CLI executor not available")
@@ -2244,38 +3019,65 @@ func (a *Adapter) CreateVLAN(ctx context.Context, req *types.CreateVLANRequest)
 			Vendor:  "huawei",
 		}
 	}
+	vlanType := strings.ToLower(req.Type)
+	if vlanType == "" {
+		vlanType = "smart"
+	}
+	if !slices.Contains(huaweiVLANTypes, vlanType) {
+		return &types.HumanError{
+			Code:    types.ErrCodeValidationFailed,
+			Message: fmt.Sprintf("VLAN type %q is not supported (%s)", req.Type, strings.Join(huaweiVLANTypes, ", ")),
+			Vendor:  "huawei",
+		}
+	}
+	var uplink [3]int
+	if req.UplinkPort != "" {
+		frame, slot, port, err := parsePONPort(req.UplinkPort)
+		if err != nil {
+			return &types.HumanError{
+				Code:    types.ErrCodeValidationFailed,
+				Message: fmt.Sprintf("invalid uplink port %q (expected frame/slot/port)", req.UplinkPort),
+				Vendor:  "huawei",
+			}
+		}
+		uplink = [3]int{frame, slot, port}
+	}
 
 	// Build commands
 	commands := []string{
 		"enable",
 		"config",
-		fmt.Sprintf("vlan %d smart", req.ID),
+		fmt.Sprintf("vlan %d %s", req.ID, vlanType),
 	}
 
 	if req.Name != "" {
-		commands = append(commands, fmt.Sprintf("name %s", common.SanitizeCLIParam(req.Name)))
+		commands = append(commands, fmt.Sprintf("vlan name %d %s", req.ID, common.SanitizeCLIParam(req.Name)))
 	}
 	if req.Description != "" {
-		commands = append(commands, fmt.Sprintf("description %s", common.SanitizeCLIParam(req.Description)))
+		commands = append(commands, fmt.Sprintf("vlan desc %d description %s", req.ID, common.SanitizeCLIParam(req.Description)))
+	}
+	if req.UplinkPort != "" {
+		// port vlan <vlan> <frame>/<slot> <port>
+		commands = append(commands, fmt.Sprintf("port vlan %d %d/%d %d", req.ID, uplink[0], uplink[1], uplink[2]))
 	}
 
-	commands = append(commands, "quit", "quit")
+	commands = append(commands, "quit")
 
-	outputs, err := a.cliExecutor.ExecCommands(ctx, commands)
-	output := strings.Join(outputs, "\n")
+	output, err := a.execConfigCommands(ctx, commands)
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") || strings.Contains(output, "already exists") {
+		if strings.Contains(err.Error(), "already exist") || strings.Contains(output, "already exist") {
 			return &types.HumanError{
 				Code:    types.ErrCodeVLANExists,
 				Message: fmt.Sprintf("VLAN %d already exists", req.ID),
 				Vendor:  "huawei",
+				Raw:     output,
 			}
 		}
 		return fmt.Errorf("failed to create VLAN: %w", err)
 	}
 
 	// Check output for errors
-	if strings.Contains(output, "Error") || strings.Contains(output, "already exists") {
+	if strings.Contains(output, "Error") {
 		return &types.HumanError{
 			Code:    types.ErrCodeVLANExists,
 			Message: fmt.Sprintf("VLAN %d already exists", req.ID),
@@ -2287,7 +3089,9 @@ func (a *Adapter) CreateVLAN(ctx context.Context, req *types.CreateVLANRequest)
 	return nil
 }
 
-// DeleteVLAN removes a VLAN from the OLT.
+// DeleteVLAN removes a VLAN from the OLT. The OLT refuses to delete a VLAN
+// that still has service ports; with force they are removed first, and a
+// VLAN that does not exist is not an error.
 func (a *Adapter) DeleteVLAN(ctx context.Context, vlanID int, force bool) error {
 	if a.cliExecutor == nil {
 		return fmt.Errorf("CLI executor not available")
@@ -2299,6 +3103,9 @@ func (a *Adapter) DeleteVLAN(ctx context.Context, vlanID int, force bool) error
 		return err
 	}
 	if vlan == nil {
+		if force {
+			return nil
+		}
 		return &types.HumanError{
 			Code:    types.ErrCodeVLANNotFound,
 			Message: fmt.Sprintf("VLAN %d not found", vlanID),
@@ -2315,28 +3122,25 @@ func (a *Adapter) DeleteVLAN(ctx context.Context, vlanID int, force bool) error
 		}
 	}
 
-	commands := []string{
-		"enable",
-		"config",
-		fmt.Sprintf("undo vlan %d", vlanID),
-		"quit",
+	commands := []string{"enable", "config"}
+	if vlan.ServicePortCount > 0 {
+		commands = append(commands, fmt.Sprintf("undo service-port vlan %d", vlanID))
 	}
+	commands = append(commands, fmt.Sprintf("undo vlan %d", vlanID), "quit")
 
-	outputs, err := a.cliExecutor.ExecCommands(ctx, commands)
-	output := strings.Join(outputs, "\n")
+	output, err := a.execConfigCommands(ctx, commands)
 	if err != nil {
-		return fmt.Errorf("failed to delete VLAN: %w", err)
-	}
-
-	// Check for warning about service ports
-	if strings.Contains(output, "service port") && !force {
-		return &types.HumanError{
-			Code:    types.ErrCodeVLANHasServicePorts,
-			Message: fmt.Sprintf("VLAN %d has service ports configured", vlanID),
-			Action:  "Use --force to delete anyway",
-			Vendor:  "huawei",
-			Raw:     output,
+		// The service port count can be stale, or missing from the display
+		if strings.Contains(strings.ToLower(output+err.Error()), "service") {
+			return &types.HumanError{
+				Code:    types.ErrCodeVLANHasServicePorts,
+				Message: fmt.Sprintf("VLAN %d has service ports configured", vlanID),
+				Action:  "Use --force to delete anyway, or remove service ports first",
+				Vendor:  "huawei",
+				Raw:     output,
+			}
 		}
+		return fmt.Errorf("failed to delete VLAN: %w", err)
 	}
 
 	return nil
@@ -2344,7 +3148,9 @@ func (a *Adapter) DeleteVLAN(ctx context.Context, vlanID int, force bool) error
 
 // ==================== Service Port Management Methods ====================
 
-// ListServicePorts returns all service port configurations.
+// ListServicePorts returns all service port configurations from display
+// service-port all. Paged output is accepted: the pager prompts are
+// dropped before parsing.
 func (a *Adapter) ListServicePorts(ctx context.Context) ([]types.ServicePort, error) {
 	if a.cliExecutor == nil {
 		return nil, fmt.Errorf("CLI executor not available")
@@ -2360,13 +3166,24 @@ func (a *Adapter) ListServicePorts(ctx context.Context) ([]types.ServicePort, er
 }
 
 // parseServicePortList parses Huawei CLI output for service port list.
+// MA5800 firmware lists the ONT ID and GEM index as VPI and VCI, with the
+// user VLAN as flow parameter of vlan flows; the VLAN attribute, traffic
+// tables and state go into Metadata. The tag transform is not part of this
+// layout, which is taken from the MA5800 command reference. Older firmware
+// lists Interface, ONT, GemPort, User-VLAN and Transform columns.
 func (a *Adapter) parseServicePortList(output string) []types.ServicePort {
 	servicePorts := []types.ServicePort{}
 
+	output = reHWMorePrompt.ReplaceAllString(common.StripANSI(output), "")
 	lines := strings.Split(output, "\n")
 	inTable := false
 
 	for _, line := range lines {
+		if m := reHWServicePortRow.FindStringSubmatch(line); m != nil {
+			servicePorts = append(servicePorts, parseServicePortRow(m))
+			continue
+		}
+
 		line = strings.TrimSpace(line)
 
 		// Skip empty lines and headers
@@ -2386,7 +3203,7 @@ func (a *Adapter) parseServicePortList(output string) []types.ServicePort {
 
 		// Parse line: "1       100     0/0/1           101     1         100          translate"
 		fields := strings.Fields(line)
-		if len(fields) < 5 {
+		if len(fields) < 5 || !reHWFSP.MatchString(fields[2]) {
 			continue
 		}
 
@@ -2420,12 +3237,57 @@ func (a *Adapter) parseServicePortList(output string) []types.ServicePort {
 	return servicePorts
 }
 
-// AddServicePort creates a service port mapping.
+// parseServicePortRow converts a match of reHWServicePortRow
+func parseServicePortRow(m []string) types.ServicePort {
+	index, _ := strconv.Atoi(m[1])
+	vlan, _ := strconv.Atoi(m[2])
+	ontID, _ := strconv.Atoi(m[8])
+	gemport, _ := strconv.Atoi(m[9])
+	sp := types.ServicePort{
+		Index:     index,
+		VLAN:      vlan,
+		Interface: fmt.Sprintf("%s/%s/%s", m[5], m[6], m[7]),
+		ONTID:     ontID,
+		GemPort:   gemport,
+		Metadata: map[string]interface{}{
+			"vlan_attr": m[3],
+			"port_type": m[4],
+			"flow_type": m[10],
+			"state":     m[14],
+		},
+	}
+	if m[10] == "vlan" {
+		sp.UserVLAN, _ = strconv.Atoi(m[11])
+	}
+	if rx, err := strconv.Atoi(m[12]); err == nil {
+		sp.Metadata["rx_traffic_table"] = rx
+	}
+	if tx, err := strconv.Atoi(m[13]); err == nil {
+		sp.Metadata["tx_traffic_table"] = tx
+	}
+	return sp
+}
+
+// huaweiTagTransforms are the tag-transform modes of a service-port
+var huaweiTagTransforms = []string{"translate", "transparent", "add", "translate-and-add", "default"}
+
+// AddServicePort creates a service port mapping. The tag transform
+// defaults to translate. With an ETH port the native VLAN of that ONT port
+// is set to the user VLAN as well.
 func (a *Adapter) AddServicePort(ctx context.Context, req *types.AddServicePortRequest) error {
 	if a.cliExecutor == nil {
 		return fmt.Errorf("CLI executor not available")
 	}
 
+	frame, slot, port, err := parsePONPort(req.PONPort)
+	if err != nil {
+		return &types.HumanError{
+			Code:    types.ErrCodeValidationFailed,
+			Message: fmt.Sprintf("invalid PON port %q", req.PONPort),
+			Vendor:  "huawei",
+		}
+	}
+
 	// Default values
 	gemPort := req.GemPort
 	if gemPort == 0 {
@@ -2435,75 +3297,84 @@ func (a *Adapter) AddServicePort(ctx context.Context, req *types.AddServicePortR
 	if userVLAN == 0 {
 		userVLAN = req.VLAN
 	}
+	transform := req.TagTransform
+	if transform == "" {
+		transform = "translate"
+	}
+	if !slices.Contains(huaweiTagTransforms, transform) {
+		return &types.HumanError{
+			Code:    types.ErrCodeValidationFailed,
+			Message: fmt.Sprintf("unsupported tag transform %q (want one of %s)", transform, strings.Join(huaweiTagTransforms, ", ")),
+			Vendor:  "huawei",
+		}
+	}
 
-	// Build command
-	cmd := fmt.Sprintf(
-		"service-port vlan %d gpon %s ont %d gemport %d multi-service user-vlan %d",
-		req.VLAN, req.PONPort, req.ONTID, gemPort, userVLAN,
-	)
-
+	// service-port vlan <vlan> gpon <f/s/p> ont <id> gemport <n> multi-service user-vlan <vlan> tag-transform <mode>
 	commands := []string{
 		"enable",
 		"config",
-		cmd,
-		"quit",
+		fmt.Sprintf("service-port vlan %d gpon %d/%d/%d ont %d gemport %d multi-service user-vlan %d tag-transform %s",
+			req.VLAN, frame, slot, port, req.ONTID, gemPort, userVLAN, transform),
 	}
+	if req.ETHPort > 0 {
+		commands = append(commands,
+			fmt.Sprintf("interface gpon %d/%d", frame, slot),
+			fmt.Sprintf("ont port native-vlan %d %d eth %d vlan %d priority 0", port, req.ONTID, req.ETHPort, userVLAN),
+			"quit",
+		)
+	}
+	commands = append(commands, "quit")
 
-	outputs, err := a.cliExecutor.ExecCommands(ctx, commands)
-	output := strings.Join(outputs, "\n")
+	output, err := a.execConfigCommands(ctx, commands)
 	if err != nil {
-		if strings.Contains(err.Error(), "does not exist") {
-			return &types.HumanError{
-				Code:    types.ErrCodeONUNotFound,
-				Message: fmt.Sprintf("ONT %d on port %s not found", req.ONTID, req.PONPort),
-				Vendor:  "huawei",
-			}
-		}
-		if strings.Contains(err.Error(), "VLAN") && strings.Contains(err.Error(), "not exist") {
-			return &types.HumanError{
-				Code:    types.ErrCodeVLANNotFound,
-				Message: fmt.Sprintf("VLAN %d does not exist", req.VLAN),
-				Vendor:  "huawei",
-			}
-		}
-		return fmt.Errorf("failed to add service port: %w", err)
+		return servicePortError(err, output, req)
 	}
+	return nil
+}
 
-	// Check output for errors
-	if strings.Contains(output, "Error") || strings.Contains(output, "does not exist") {
-		if strings.Contains(output, "ONT") || strings.Contains(output, "ont") {
-			return &types.HumanError{
-				Code:    types.ErrCodeONUNotFound,
-				Message: fmt.Sprintf("ONT %d on port %s not found", req.ONTID, req.PONPort),
-				Vendor:  "huawei",
-				Raw:     output,
-			}
+// servicePortError maps a failed service-port command to its error
+func servicePortError(err error, output string, req *types.AddServicePortRequest) error {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "vlan") && strings.Contains(msg, "not exist"):
+		return &types.HumanError{
+			Code:    types.ErrCodeVLANNotFound,
+			Message: fmt.Sprintf("VLAN %d does not exist", req.VLAN),
+			Vendor:  "huawei",
+			Raw:     output,
 		}
-		if strings.Contains(output, "VLAN") || strings.Contains(output, "vlan") {
-			return &types.HumanError{
-				Code:    types.ErrCodeVLANNotFound,
-				Message: fmt.Sprintf("VLAN %d does not exist", req.VLAN),
-				Vendor:  "huawei",
-				Raw:     output,
-			}
+	case strings.Contains(msg, "already") || strings.Contains(msg, "conflict"):
+		return &types.HumanError{
+			Code:    types.ErrCodeServicePortExists,
+			Message: fmt.Sprintf("a service port for VLAN %d on ONT %d of port %s already exists", req.VLAN, req.ONTID, req.PONPort),
+			Vendor:  "huawei",
+			Raw:     output,
+		}
+	case strings.Contains(msg, "ont") && strings.Contains(msg, "not exist"):
+		return &types.HumanError{
+			Code:    types.ErrCodeONUNotFound,
+			Message: fmt.Sprintf("ONT %d on port %s not found", req.ONTID, req.PONPort),
+			Vendor:  "huawei",
+			Raw:     output,
 		}
 	}
-
-	return nil
+	return fmt.Errorf("failed to add service port: %w", err)
 }
 
-// DeleteServicePort removes a service port mapping.
 // GetONUProfiles fetches ONU profile and VLAN assignments.
 // TODO: Implement Huawei SNMP/CLI profile retrieval.
 func (a *Adapter) GetONUProfiles(ctx context.Context) ([]types.ONUInfo, error) {
 	return nil, fmt.Errorf("GetONUProfiles not yet implemented for Huawei")
 }
 
+// DeleteServicePort removes the service port mappings of an ONT. An ONT
+// without service ports is reported as not found.
 func (a *Adapter) DeleteServicePort(ctx context.Context, ponPort string, ontID int) error {
 	if a.cliExecutor == nil {
 		return fmt.Errorf("CLI executor not available")
 	}
 
+	// undo service-port port <f/s/p> ont <id>
 	cmd := fmt.Sprintf("undo service-port port %s ont %d", ponPort, ontID)
 
 	commands := []string{
@@ -2513,8 +3384,16 @@ func (a *Adapter) DeleteServicePort(ctx context.Context, ponPort string, ontID i
 		"quit",
 	}
 
-	_, err := a.cliExecutor.ExecCommands(ctx, commands)
+	output, err := a.execConfigCommands(ctx, commands)
 	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "not exist") {
+			return &types.HumanError{
+				Code:    types.ErrCodeServicePortNotFound,
+				Message: fmt.Sprintf("no service port on ONT %d of port %s", ontID, ponPort),
+				Vendor:  "huawei",
+				Raw:     output,
+			}
+		}
 		return fmt.Errorf("failed to delete service port: %w", err)
 	}
 
@@ -2611,7 +3490,7 @@ func (a *Adapter) CaptureSubscriberConfig(ctx context.Context, subscriberID stri
 		}
 	}
 
-	slog.Info("huawei: captured subscriber config",
+	a.config.Log().Info("huawei: captured subscriber config",
 		"subscriber_id", subscriberID, "serial", serial, "pon_port", ponPort)
 
 	return snapshot, nil
@@ -2749,7 +3628,7 @@ func (a *Adapter) ReplaceONU(ctx context.Context, subscriberID string, newSerial
 	// But if the old ONU was on a different logical entry, clean up.
 	var warnings []string
 	if oldSerial != newSerial {
-		slog.Info("huawei: replacement ONT created, old serial replaced",
+		a.config.Log().Info("huawei: replacement ONT created, old serial replaced",
 			"old_serial", oldSerial, "new_serial", newSerial)
 	}
 
@@ -2770,15 +3649,10 @@ func (a *Adapter) SoftSuspendSubscriber(ctx context.Context, subscriberID string
 	if a.cliExecutor == nil {
 		return nil, fmt.Errorf("CLI executor not available")
 	}
-	if opts == nil {
-		return nil, fmt.Errorf("suspend options are required")
-	}
-
-	switch opts.Mode {
-	case types.SuspensionModeThrottle, types.SuspensionModeWalledGarden, types.SuspensionModeQuarantine:
-		// valid
-	default:
-		return nil, fmt.Errorf("invalid suspension mode: %q", opts.Mode)
+	// Validates the mode and takes a zero walled-garden VLAN from the device
+	opts, err := types.ResolveSuspendOptions(opts, a.config)
+	if err != nil {
+		return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
 	}
 
 	frame, slot, port, ontID := a.parseSubscriberID(subscriberID)
@@ -2823,7 +3697,10 @@ func (a *Adapter) SoftSuspendSubscriber(ctx context.Context, subscriberID string
 	// Apply walled-garden: redirect service port VLAN
 	if opts.Mode == types.SuspensionModeWalledGarden || opts.Mode == types.SuspensionModeQuarantine {
 		// Delete existing service port, create new one with walled-garden VLAN
-		_ = a.DeleteServicePort(ctx, ponPort, ontID)
+		if err := a.DeleteServicePort(ctx, ponPort, ontID); err != nil {
+			a.config.Log().Warn("huawei: soft_suspend: failed to delete existing service port, may not exist",
+				"pon_port", ponPort, "ont_id", ontID, "error", err)
+		}
 
 		walledVLAN := opts.WalledGardenVLAN
 		err := a.AddServicePort(ctx, &types.AddServicePortRequest{
@@ -2844,12 +3721,47 @@ func (a *Adapter) SoftSuspendSubscriber(ctx context.Context, subscriberID string
 	a.suspensionStates[subscriberID] = state
 	a.suspensionMu.Unlock()
 
-	slog.Info("huawei: soft suspension applied",
+	a.config.Log().Info("huawei: soft suspension applied",
 		"subscriber_id", subscriberID, "mode", opts.Mode)
 
 	return state, nil
 }
 
+// liftSoftSuspension puts the ONT back on the service ports captured before
+// a soft suspension and forgets the suspension. A throttle traffic policy
+// is left for the caller to replace with the subscriber's tier, as the
+// original policy is not captured.
+func (a *Adapter) liftSoftSuspension(ctx context.Context, subscriberID string, state *types.SuspensionState) error {
+	frame, slot, port, ontID := a.parseSubscriberID(subscriberID)
+	ponPort := fmt.Sprintf("%d/%d/%d", frame, slot, port)
+
+	if state.AppliedVLAN > 0 {
+		if state.OriginalSnapshot == nil {
+			return fmt.Errorf("no configuration captured for %s before suspension", subscriberID)
+		}
+		if err := a.DeleteServicePort(ctx, ponPort, ontID); err != nil {
+			return fmt.Errorf("failed to remove walled-garden VLAN %d: %w", state.AppliedVLAN, err)
+		}
+		for _, req := range state.OriginalSnapshot.ServicePortRequests(ponPort, ontID) {
+			if err := a.AddServicePort(ctx, &req); err != nil {
+				return fmt.Errorf("failed to restore VLAN %d: %w", req.VLAN, err)
+			}
+		}
+	}
+	if state.AppliedBandwidthKbps > 0 {
+		a.config.Log().Warn("huawei: resume: throttle traffic policy not restored, reapply the subscriber tier",
+			"subscriber_id", subscriberID, "throttle_kbps", state.AppliedBandwidthKbps)
+	}
+
+	a.suspensionMu.Lock()
+	delete(a.suspensionStates, subscriberID)
+	a.suspensionMu.Unlock()
+
+	a.config.Log().Info("huawei: soft suspension lifted",
+		"subscriber_id", subscriberID, "mode", state.Mode)
+	return nil
+}
+
 // GetSuspensionState returns the current soft suspension state for a subscriber.
 func (a *Adapter) GetSuspensionState(ctx context.Context, subscriberID string) (*types.SuspensionState, error) {
 	a.suspensionMu.RLock()
@@ -2900,11 +3812,11 @@ func (a *Adapter) MoveSubscriber(ctx context.Context, subscriberID string, targe
 	var warnings []string
 	if err := a.DeleteSubscriber(ctx, subscriberID); err != nil {
 		warnings = append(warnings, fmt.Sprintf("failed to delete old ONT: %v", err))
-		slog.Warn("huawei: move succeeded but old ONT deletion failed",
+		a.config.Log().Warn("huawei: move succeeded but old ONT deletion failed",
 			"subscriber_id", subscriberID, "error", err)
 	}
 
-	slog.Info("huawei: subscriber moved",
+	a.config.Log().Info("huawei: subscriber moved",
 		"subscriber_id", subscriberID,
 		"from", oldPONPort, "to", targetPONPort)
 
@@ -3037,7 +3949,7 @@ func (a *Adapter) AddONUToSubscriber(ctx context.Context, subscriberID string, b
 		return nil, fmt.Errorf("failed to provision additional ONT: %w", err)
 	}
 
-	slog.Info("huawei: added ONT to subscriber",
+	a.config.Log().Info("huawei: added ONT to subscriber",
 		"subscriber_id", subscriberID, "serial", binding.Serial,
 		"fsp", fmt.Sprintf("%d/%d/%d", frame, slot, port), "ont_id", binding.ONUID)
 
@@ -3060,12 +3972,12 @@ func (a *Adapter) RemoveONUFromSubscriber(ctx context.Context, subscriberID stri
 	}
 
 	for _, onu := range onus {
-		if onu.Serial == serial {
+		if common.SerialsEqual(onu.Serial, serial) {
 			ontSubID := fmt.Sprintf("ont-%s-%d", onu.PONPort, onu.ONUID)
 			if err := a.DeleteSubscriber(ctx, ontSubID); err != nil {
 				return fmt.Errorf("failed to remove ONT %s: %w", serial, err)
 			}
-			slog.Info("huawei: removed ONT from subscriber",
+			a.config.Log().Info("huawei: removed ONT from subscriber",
 				"subscriber_id", subscriberID, "serial", serial)
 			return nil
 		}
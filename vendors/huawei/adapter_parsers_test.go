@@ -787,51 +787,3 @@ func TestIsOnuOnline(t *testing.T) {
 		})
 	}
 }
-
-func TestIsASCIISerial(t *testing.T) {
-	tests := []struct {
-		name   string
-		serial string
-		want   bool
-	}{
-		{name: "ASCII serial HWTC", serial: "HWTC00001234", want: true},
-		{name: "ASCII serial ZTEG", serial: "ZTEG00000001", want: true},
-		{name: "hex serial", serial: "485754430011D168", want: false},
-		{name: "short serial", serial: "AB", want: false},
-		{name: "starts with lowercase", serial: "hwtc00001234", want: false},
-		{name: "starts with numbers", serial: "1234ABCD", want: false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := isASCIISerial(tt.serial)
-			if got != tt.want {
-				t.Errorf("isASCIISerial(%q) = %v, want %v", tt.serial, got, tt.want)
-			}
-		})
-	}
-}
-
-func TestHexToByte(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected byte
-	}{
-		{name: "zero", input: "00", expected: 0},
-		{name: "uppercase FF", input: "FF", expected: 255},
-		{name: "lowercase ff", input: "ff", expected: 255},
-		{name: "mixed Af", input: "Af", expected: 175},
-		{name: "letter H (0x48)", input: "48", expected: 72},
-		{name: "letter W (0x57)", input: "57", expected: 87},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := hexToByte(tt.input)
-			if got != tt.expected {
-				t.Errorf("hexToByte(%q) = %d, want %d", tt.input, got, tt.expected)
-			}
-		})
-	}
-}
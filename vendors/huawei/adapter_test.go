@@ -2,13 +2,18 @@ package huawei
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/nanoncore/nano-southbound/model"
 	"github.com/nanoncore/nano-southbound/testutil"
 	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
 )
 
 // ============================================================================
@@ -784,6 +789,91 @@ func TestCreateSubscriber_Success(t *testing.T) {
 	}
 }
 
+func TestCreateSubscriber_RollbackOnFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		failCmd  string
+		wantUndo []string
+		noUndo   []string
+	}{
+		{
+			name:     "service-port fails",
+			failCmd:  "service-port vlan 100 gpon 0/1/0 ont 5 gemport 1 multi-service user-vlan 100 tag-transform translate",
+			wantUndo: []string{"ont delete 0 5"},
+			noUndo:   []string{"undo service-port port 0/1/0 ont 5"},
+		},
+		{
+			name:     "traffic policy fails",
+			failCmd:  "ont traffic-policy 0 5 profile-id 100",
+			wantUndo: []string{"undo service-port port 0/1/0 ont 5", "ont delete 0 5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &testutil.MockCLIExecutor{
+				Errors: map[string]error{tt.failCmd: fmt.Errorf("Failure: command rejected")},
+			}
+			adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"))
+			sub := testutil.NewTestSubscriber("HWTC00001234", "0/1/0", 100)
+			sub.Annotations["nanoncore.com/gpon-fsp"] = "0/1/0"
+			sub.Annotations["nanoncore.com/ont-id"] = "5"
+			tier := testutil.NewTestServiceTier(50, 100)
+
+			_, err := adapter.CreateSubscriber(context.Background(), sub, tier)
+			if err == nil || !strings.Contains(err.Error(), "command rejected") {
+				t.Fatalf("CreateSubscriber() error = %v", err)
+			}
+
+			failedAt := -1
+			for i, cmd := range mock.Commands {
+				if cmd == tt.failCmd {
+					failedAt = i
+				}
+			}
+			rollback := mock.Commands[failedAt+1:]
+			last := -1
+			for _, want := range tt.wantUndo {
+				idx := -1
+				for i, cmd := range rollback {
+					if cmd == want {
+						idx = i
+					}
+				}
+				if idx <= last {
+					t.Errorf("expected %q after the failure, in order; got %v", want, rollback)
+				}
+				last = idx
+			}
+			for _, cmd := range rollback {
+				for _, unwanted := range tt.noUndo {
+					if cmd == unwanted {
+						t.Errorf("unexpected %q for a step that did not complete", cmd)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCreateSubscriber_TypedError(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Errors: map[string]error{"ont add 0 5 sn-auth HWTC00001234 omci ont-lineprofile-id 1 ont-srvprofile-id 1 desc nanoncore": fmt.Errorf("Failure: SN already exists")},
+	}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"))
+	sub := testutil.NewTestSubscriber("HWTC00001234", "0/1/0", 100)
+	sub.Annotations["nanoncore.com/gpon-fsp"] = "0/1/0"
+	sub.Annotations["nanoncore.com/ont-id"] = "5"
+
+	_, err := adapter.CreateSubscriber(context.Background(), sub, testutil.NewTestServiceTier(50, 100))
+	if !errors.Is(err, types.ErrONUExists) {
+		t.Fatalf("CreateSubscriber() error = %v, want types.ErrONUExists", err)
+	}
+	if got := types.ErrorCode(err); got != types.ErrCodeONUExists {
+		t.Errorf("ErrorCode() = %q", got)
+	}
+}
+
 // ============================================================================
 // DeleteSubscriber tests
 // ============================================================================
@@ -1230,6 +1320,13 @@ func TestDiscoverONUs_WithFilter(t *testing.T) {
 	if results[0].PONPort != "0/1/0" {
 		t.Errorf("PONPort = %q, want %q", results[0].PONPort, "0/1/0")
 	}
+	// Serials are decoded to the ASCII form other vendors report
+	if results[0].Serial != "HWTC0A2C4F13" || results[0].Metadata["serial_hex"] != "485754430A2C4F13" || results[0].Vendor != "HWTC" {
+		t.Errorf("Serial = %q (%v), Vendor = %q", results[0].Serial, results[0].Metadata["serial_hex"], results[0].Vendor)
+	}
+	if got := results[0].DiscoveredAt.Format("2006-01-02 15:04:05"); got != "2024-01-15 10:30:00" {
+		t.Errorf("DiscoveredAt = %s, want the autofind time", got)
+	}
 }
 
 func TestDiscoverONUs_NoFilter(t *testing.T) {
@@ -1296,8 +1393,23 @@ func TestRestartONU_InvalidPort(t *testing.T) {
 	}
 }
 
+// fastRestartWaits shortens the RestartONU verification waits for the test
+func fastRestartWaits(t *testing.T) {
+	offline, online := common.RestartOfflineWaits, common.RestartOnlineWaits
+	common.RestartOfflineWaits = []time.Duration{time.Millisecond, time.Millisecond}
+	common.RestartOnlineWaits = []time.Duration{time.Millisecond, time.Millisecond}
+	t.Cleanup(func() {
+		common.RestartOfflineWaits, common.RestartOnlineWaits = offline, online
+	})
+}
+
 func TestRestartONU_Success(t *testing.T) {
-	mock := &testutil.MockCLIExecutor{}
+	fastRestartWaits(t)
+	mock := &testutil.MockCLIExecutor{
+		SequentialOutputs: map[string][]string{
+			"display ont info 0/1 0 5": {"  Run state : offline\n", "  Run state : online\n"},
+		},
+	}
 	adapter := &Adapter{
 		baseDriver:  &testutil.MockDriver{},
 		cliExecutor: mock,
@@ -1314,20 +1426,84 @@ func TestRestartONU_Success(t *testing.T) {
 	if !result.DeactivateSuccess {
 		t.Error("expected DeactivateSuccess = true")
 	}
+	if !result.DeactivateVerified || !result.ActivateVerified {
+		t.Errorf("expected offline and online to be verified, got %+v", result)
+	}
 
 	// Verify reset command was sent
-	found := false
-	for _, cmd := range mock.Commands {
-		if strings.Contains(cmd, "ont reset 0 5") {
-			found = true
-			break
-		}
-	}
-	if !found {
+	if !slices.Contains(mock.Commands, "ont reset 0 5") {
 		t.Errorf("expected 'ont reset 0 5' command, got: %v", mock.Commands)
 	}
 }
 
+func TestRestartONU_Unverified(t *testing.T) {
+	fastRestartWaits(t)
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	result, err := adapter.RestartONU(context.Background(), "0/1/0", 5)
+	if err != nil {
+		t.Fatalf("RestartONU() error = %v", err)
+	}
+	if !result.Success || result.DeactivateVerified || result.ActivateVerified {
+		t.Errorf("result = %+v", result)
+	}
+	if result.Message != "ONU restart commands sent, verification pending" {
+		t.Errorf("Message = %q", result.Message)
+	}
+}
+
+func TestFactoryResetONU_RequiresForce(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	_, err := adapter.FactoryResetONU(context.Background(), "0/1/0", 5, &types.FactoryResetOptions{})
+	var he *types.HumanError
+	if !errors.As(err, &he) || he.Code != types.ErrCodeValidationFailed {
+		t.Fatalf("FactoryResetONU() error = %v, want %s", err, types.ErrCodeValidationFailed)
+	}
+	if len(mock.Commands) != 0 {
+		t.Errorf("commands sent without Force: %v", mock.Commands)
+	}
+}
+
+func TestFactoryResetONU_Reregisters(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		SequentialOutputs: map[string][]string{
+			"display ont info 0/1 0 5": {"  Run state : offline\n", "  Run state : online\n"},
+		},
+	}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	opts := &types.FactoryResetOptions{Force: true, VerifyTimeout: time.Second, PollInterval: time.Millisecond}
+	result, err := adapter.FactoryResetONU(context.Background(), "0/1/0", 5, opts)
+	if err != nil {
+		t.Fatalf("FactoryResetONU() error = %v", err)
+	}
+	if !result.Success || !result.ResetSent || !result.Reregistered {
+		t.Errorf("result = %+v", result)
+	}
+	if !slices.Contains(mock.Commands, "ont factory-setting-restore 0 5") {
+		t.Errorf("missing factory reset command in %v", mock.Commands)
+	}
+}
+
+func TestFactoryResetONU_NotReregistered(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{"display ont info 0/1 0 5": "  Run state : offline\n"},
+	}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	opts := &types.FactoryResetOptions{Force: true, VerifyTimeout: 5 * time.Millisecond, PollInterval: time.Millisecond}
+	result, err := adapter.FactoryResetONU(context.Background(), "0/1/0", 5, opts)
+	if err != nil {
+		t.Fatalf("FactoryResetONU() error = %v", err)
+	}
+	if result.Success || !result.ResetSent || result.Reregistered {
+		t.Errorf("result = %+v", result)
+	}
+}
+
 // ============================================================================
 // ApplyProfile tests
 // ============================================================================
@@ -1431,6 +1607,56 @@ func TestApplyProfile_BandwidthProfile(t *testing.T) {
 	}
 }
 
+func TestApplyProfile_ProfileNotFound(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{
+			"ont modify 0 5 ont-lineprofile-name missing": "  Failure: The line profile does not exist",
+		},
+	}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	err := adapter.ApplyProfile(context.Background(), "0/1/0", 5, &types.ONUProfile{LineProfile: "missing"})
+	var humanErr *types.HumanError
+	if !errors.As(err, &humanErr) || humanErr.Code != types.ErrCodeProfileNotFound {
+		t.Errorf("ApplyProfile() error = %v, want %s", err, types.ErrCodeProfileNotFound)
+	}
+}
+
+func TestApplyProfile_TrafficTableMetadata(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	profile := &types.ONUProfile{
+		ServiceProfile: "10",
+		BandwidthDown:  100000,
+		Metadata:       map[string]interface{}{"traffic_table_id": 20},
+	}
+	if err := adapter.ApplyProfile(context.Background(), "0/1/0", 5, profile); err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+
+	want := []string{
+		"enable",
+		"config",
+		"interface gpon 0/1",
+		"ont modify 0 5 ont-srvprofile-id 10",
+		"ont traffic-policy 0 5 profile-id 20",
+		"quit",
+		"quit",
+	}
+	if !reflect.DeepEqual(mock.Commands, want) {
+		t.Errorf("commands = %v, want %v", mock.Commands, want)
+	}
+}
+
 // ============================================================================
 // BulkProvision tests
 // ============================================================================
@@ -1524,6 +1750,89 @@ func TestBulkProvision_WithError(t *testing.T) {
 	}
 }
 
+func TestBulkProvision_OneSession(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{
+			"ont add 0 sn-auth HWTC00001234 omci ont-lineprofile-name line1 ont-srvprofile-id 1 desc nanoncore": "  Number of ONTs that can be added: 1, success: 1\n  PortID :0, ONTID :9",
+		},
+	}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	ops := []types.BulkProvisionOp{
+		{Serial: "HWTC00001234", PONPort: "0/1/0", Profile: &types.ONUProfile{LineProfile: "line1", VLAN: 100, Metadata: map[string]interface{}{"traffic_table_id": 12}}},
+		{Serial: "HWTC00005678", PONPort: "0/1/1", ONUID: 6},
+	}
+	result, err := adapter.BulkProvision(context.Background(), ops)
+	if err != nil {
+		t.Fatalf("BulkProvision() error = %v", err)
+	}
+	if result.Succeeded != 2 || result.Results[0].ONUID != 9 {
+		t.Fatalf("BulkProvision() = %+v", result)
+	}
+
+	count := 0
+	for _, cmd := range mock.Commands {
+		if cmd == "config" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("entered config mode %d times, want 1: %v", count, mock.Commands)
+	}
+	cmdStr := strings.Join(mock.Commands, " | ")
+	for _, want := range []string{
+		"ont port native-vlan 0 9 eth 1 vlan 100 priority 0",
+		"ont traffic-policy 0 9 profile-id 12",
+		"service-port vlan 100 gpon 0/1/0 ont 9 gemport 1 multi-service user-vlan 100 tag-transform translate",
+		"ont add 1 6 sn-auth HWTC00005678 omci ont-lineprofile-id 1 ont-srvprofile-id 1 desc nanoncore",
+	} {
+		if !strings.Contains(cmdStr, want) {
+			t.Errorf("expected %q in: %s", want, cmdStr)
+		}
+	}
+}
+
+func TestBulkProvision_Failures(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{
+			"ont add 0 5 sn-auth HWTC00001234 omci ont-lineprofile-id 1 ont-srvprofile-id 1 desc nanoncore":        "  Failure: SN already exists",
+			"service-port vlan 100 gpon 0/1/0 ont 6 gemport 1 multi-service user-vlan 100 tag-transform translate": "  Failure: The VLAN does not exist",
+		},
+	}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	ops := []types.BulkProvisionOp{
+		{Serial: "HWTC00001234", PONPort: "0/1/0", ONUID: 5},
+		{Serial: "HWTC00005678", PONPort: "0/1/0", ONUID: 6, Profile: &types.ONUProfile{VLAN: 100}},
+		{Serial: "not-a-serial", PONPort: "0/1/0", ONUID: 7},
+	}
+	result, err := adapter.BulkProvision(context.Background(), ops)
+	if err != nil {
+		t.Fatalf("BulkProvision() error = %v", err)
+	}
+	if result.Failed != 3 {
+		t.Fatalf("BulkProvision() = %+v, want 3 failures", result)
+	}
+	if code := result.Results[0].ErrorCode; code != types.ErrCodeONUExists {
+		t.Errorf("Results[0].ErrorCode = %s, want %s", code, types.ErrCodeONUExists)
+	}
+	if code := result.Results[2].ErrorCode; code != types.ErrCodeInvalidSerial {
+		t.Errorf("Results[2].ErrorCode = %s, want %s", code, types.ErrCodeInvalidSerial)
+	}
+	// The ONT whose service-port failed is deleted again
+	if !slices.Contains(mock.Commands, "ont delete 0 6") {
+		t.Errorf("expected rollback of ONT 6, got: %v", mock.Commands)
+	}
+}
+
 // ============================================================================
 // GetAlarms tests
 // ============================================================================
@@ -1588,6 +1897,82 @@ func TestGetAlarms_Error(t *testing.T) {
 	}
 }
 
+func TestGetAlarms_SNMP(t *testing.T) {
+	snmpMock := &testutil.MockSNMPExecutor{
+		WalkResults: map[string]map[string]interface{}{
+			OIDHwAlarmLevel:  {".7": int64(1), ".8": int64(2)},
+			OIDHwAlarmID:     {".7": int64(2146), ".8": int64(4100)},
+			OIDHwAlarmName:   {".7": "ONT LOS", ".8": "Board fault"},
+			OIDHwAlarmSource: {".7": "0/1/0:5", ".8": "0/2"},
+			OIDHwAlarmRaiseTime: {
+				".7": []byte{0x07, 0xE8, 1, 15, 10, 30, 0, 0},
+				".8": []byte{0x07, 0xE8, 1, 15, 9, 0, 0, 0},
+			},
+			OIDHwAlarmDescription: {".7": "The ONT optical signal is lost"},
+		},
+	}
+	cliMock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{
+		baseDriver:   &testutil.MockDriver{},
+		cliExecutor:  cliMock,
+		snmpExecutor: snmpMock,
+		config:       testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	alarms, err := adapter.GetAlarms(context.Background())
+	if err != nil {
+		t.Fatalf("GetAlarms() error = %v", err)
+	}
+	if len(alarms) != 2 {
+		t.Fatalf("GetAlarms() = %+v, want 2 alarms", alarms)
+	}
+	// Oldest first
+	if got := alarms[0]; got.ID != "8" || got.Severity != "major" || got.Source != "port" || got.Message != "Board fault" {
+		t.Errorf("alarms[0] = %+v", got)
+	}
+	got := alarms[1]
+	if got.ID != "7" || got.Severity != "critical" || got.Type != "los" || got.Source != "onu" || got.SourceID != "0/1/0:5" ||
+		got.Message != "The ONT optical signal is lost" || !got.RaisedAt.Equal(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)) ||
+		got.Metadata["alarm_id"] != int64(2146) {
+		t.Errorf("alarms[1] = %+v", got)
+	}
+	if len(cliMock.Commands) != 0 {
+		t.Errorf("CLI commands = %v, want none", cliMock.Commands)
+	}
+}
+
+func TestGetAlarms_SNMPFallback(t *testing.T) {
+	snmpMock := &testutil.MockSNMPExecutor{
+		WalkErrors: map[string]error{OIDHwAlarmLevel: fmt.Errorf("no such object")},
+	}
+	cliMock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{
+			"display alarm active all": `  ------------------------------------------------------------
+  12345      Critical   LOS          0/0/1:5           2024-01-15 10:30:00    Loss of signal`,
+		},
+	}
+	adapter := &Adapter{
+		baseDriver:   &testutil.MockDriver{},
+		cliExecutor:  cliMock,
+		snmpExecutor: snmpMock,
+		config:       testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	alarms, err := adapter.GetAlarms(context.Background())
+	if err != nil {
+		t.Fatalf("GetAlarms() error = %v", err)
+	}
+	if len(alarms) != 1 || alarms[0].ID != "12345" || alarms[0].Type != "los" {
+		t.Errorf("GetAlarms() = %+v", alarms)
+	}
+
+	// Without CLI the walk error is returned
+	adapter.cliExecutor = nil
+	if _, err := adapter.GetAlarms(context.Background()); err == nil {
+		t.Error("expected error when the alarm table walk fails without CLI")
+	}
+}
+
 // ============================================================================
 // SetPortState tests
 // ============================================================================
@@ -1629,15 +2014,9 @@ func TestSetPortState_Enable(t *testing.T) {
 		t.Fatalf("SetPortState() error = %v", err)
 	}
 
-	found := false
-	for _, cmd := range mock.Commands {
-		if strings.Contains(cmd, "undo port 0 shutdown") {
-			found = true
-			break
-		}
-	}
-	if !found {
-		t.Errorf("expected 'undo port 0 shutdown' command, got: %v", mock.Commands)
+	want := []string{"enable", "config", "interface gpon 0/1", "undo shutdown 0", "quit", "quit"}
+	if !slices.Equal(mock.Commands, want) {
+		t.Errorf("commands = %v, want %v", mock.Commands, want)
 	}
 }
 
@@ -1654,15 +2033,25 @@ func TestSetPortState_Disable(t *testing.T) {
 		t.Fatalf("SetPortState() error = %v", err)
 	}
 
-	found := false
-	for _, cmd := range mock.Commands {
-		if strings.Contains(cmd, "port 0 shutdown") {
-			found = true
-			break
-		}
+	if !slices.Contains(mock.Commands, "shutdown 0") {
+		t.Errorf("expected 'shutdown 0' command, got: %v", mock.Commands)
 	}
-	if !found {
-		t.Errorf("expected 'port 0 shutdown' command, got: %v", mock.Commands)
+}
+
+func TestSetPortState_PortNotFound(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"shutdown 16": "  Failure: The port does not exist",
+	}}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	err := adapter.SetPortState(context.Background(), "0/1/16", false)
+	var he *types.HumanError
+	if !errors.As(err, &he) || he.Code != types.ErrCodePortNotFound {
+		t.Errorf("SetPortState() error = %v, want %s", err, types.ErrCodePortNotFound)
 	}
 }
 
@@ -1850,8 +2239,8 @@ func TestCreateVLAN_Success(t *testing.T) {
 	if !strings.Contains(cmdStr, "vlan 100 smart") {
 		t.Errorf("expected 'vlan 100 smart' command in: %s", cmdStr)
 	}
-	if !strings.Contains(cmdStr, "name TestVLAN") {
-		t.Errorf("expected 'name TestVLAN' command in: %s", cmdStr)
+	if !strings.Contains(cmdStr, "vlan name 100 TestVLAN") {
+		t.Errorf("expected 'vlan name 100 TestVLAN' command in: %s", cmdStr)
 	}
 }
 
@@ -1873,6 +2262,54 @@ func TestCreateVLAN_AlreadyExists(t *testing.T) {
 	}
 }
 
+func TestCreateVLAN_TypeAndUplink(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	err := adapter.CreateVLAN(context.Background(), &types.CreateVLANRequest{ID: 300, Type: "mux", UplinkPort: "0/9/0"})
+	if err != nil {
+		t.Fatalf("CreateVLAN() error = %v", err)
+	}
+	want := []string{"enable", "config", "vlan 300 mux", "port vlan 300 0/9 0", "quit"}
+	if !reflect.DeepEqual(mock.Commands, want) {
+		t.Errorf("commands = %v, want %v", mock.Commands, want)
+	}
+
+	for _, req := range []*types.CreateVLANRequest{
+		{ID: 300, Type: "isolate"},
+		{ID: 300, UplinkPort: "0/9"},
+	} {
+		err := adapter.CreateVLAN(context.Background(), req)
+		var he *types.HumanError
+		if !errors.As(err, &he) || he.Code != types.ErrCodeValidationFailed {
+			t.Errorf("CreateVLAN(%+v) error = %v, want %s", req, err, types.ErrCodeValidationFailed)
+		}
+	}
+}
+
+func TestCreateVLAN_FailureOutput(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{
+			"vlan 100 smart": "  Failure: The VLAN already exists",
+		},
+	}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	err := adapter.CreateVLAN(context.Background(), &types.CreateVLANRequest{ID: 100})
+	var he *types.HumanError
+	if !errors.As(err, &he) || he.Code != types.ErrCodeVLANExists {
+		t.Errorf("CreateVLAN() error = %v, want %s", err, types.ErrCodeVLANExists)
+	}
+}
+
 // ============================================================================
 // DeleteVLAN tests
 // ============================================================================
@@ -1966,6 +2403,53 @@ func TestDeleteVLAN_Success(t *testing.T) {
 	}
 }
 
+func TestDeleteVLAN_Force(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{
+			"display vlan 100": "  VLAN ID: 100\n  VLAN type: smart\n  Service virtual port number: 2\n",
+			"display vlan 999": "  Failure: The VLAN does not exist",
+		},
+	}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	if err := adapter.DeleteVLAN(context.Background(), 100, true); err != nil {
+		t.Fatalf("DeleteVLAN() error = %v", err)
+	}
+	want := []string{"display vlan 100", "enable", "config", "undo service-port vlan 100", "undo vlan 100", "quit"}
+	if !reflect.DeepEqual(mock.Commands, want) {
+		t.Errorf("commands = %v, want %v", mock.Commands, want)
+	}
+
+	// A missing VLAN is already deleted
+	if err := adapter.DeleteVLAN(context.Background(), 999, true); err != nil {
+		t.Errorf("DeleteVLAN(missing, force) error = %v", err)
+	}
+}
+
+func TestDeleteVLAN_RefusedWithServicePorts(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{
+			"display vlan 100": "  VLAN ID: 100\n  VLAN type: smart\n",
+			"undo vlan 100":    "  Failure: The VLAN has service virtual ports",
+		},
+	}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	err := adapter.DeleteVLAN(context.Background(), 100, false)
+	var he *types.HumanError
+	if !errors.As(err, &he) || he.Code != types.ErrCodeVLANHasServicePorts {
+		t.Errorf("DeleteVLAN() error = %v, want %s", err, types.ErrCodeVLANHasServicePorts)
+	}
+}
+
 // ============================================================================
 // ListServicePorts tests
 // ============================================================================
@@ -2087,6 +2571,72 @@ func TestAddServicePort_DefaultGemPort(t *testing.T) {
 	}
 }
 
+func TestAddServicePort_TransformAndETHPort(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	err := adapter.AddServicePort(context.Background(), &types.AddServicePortRequest{
+		VLAN: 300, PONPort: "0/1/2", ONTID: 7, GemPort: 2, UserVLAN: 30, TagTransform: "transparent", ETHPort: 2,
+	})
+	if err != nil {
+		t.Fatalf("AddServicePort() error = %v", err)
+	}
+	want := []string{
+		"enable", "config",
+		"service-port vlan 300 gpon 0/1/2 ont 7 gemport 2 multi-service user-vlan 30 tag-transform transparent",
+		"interface gpon 0/1", "ont port native-vlan 2 7 eth 2 vlan 30 priority 0", "quit",
+		"quit",
+	}
+	if !slices.Equal(mock.Commands, want) {
+		t.Errorf("commands = %v, want %v", mock.Commands, want)
+	}
+
+	err = adapter.AddServicePort(context.Background(), &types.AddServicePortRequest{VLAN: 300, PONPort: "0/1/2", ONTID: 7, TagTransform: "tag"})
+	var he *types.HumanError
+	if !errors.As(err, &he) || he.Code != types.ErrCodeValidationFailed {
+		t.Errorf("AddServicePort(tag) error = %v", err)
+	}
+}
+
+func TestAddServicePort_Failures(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"  Failure: The VLAN does not exist", types.ErrCodeVLANNotFound},
+		{"  Failure: The ONT does not exist", types.ErrCodeONUNotFound},
+		{"  Failure: Service virtual port has existed already", types.ErrCodeServicePortExists},
+		{"  Failure: The traffic table does not exist", types.ErrCodeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+				"service-port vlan 100 gpon 0/1/0 ont 5 gemport 1 multi-service user-vlan 100 tag-transform translate": tt.output,
+			}}
+			adapter := &Adapter{
+				baseDriver:  &testutil.MockDriver{},
+				cliExecutor: mock,
+				config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+			}
+			err := adapter.AddServicePort(context.Background(), &types.AddServicePortRequest{VLAN: 100, PONPort: "0/1/0", ONTID: 5})
+			var he *types.HumanError
+			if tt.want == types.ErrCodeUnknown {
+				if err == nil || errors.As(err, &he) {
+					t.Errorf("AddServicePort() error = %v, want a plain error", err)
+				}
+				return
+			}
+			if !errors.As(err, &he) || he.Code != tt.want {
+				t.Errorf("AddServicePort() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
 // ============================================================================
 // DeleteServicePort tests
 // ============================================================================
@@ -2127,6 +2677,23 @@ func TestDeleteServicePort_Success(t *testing.T) {
 	}
 }
 
+func TestDeleteServicePort_NotFound(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"undo service-port port 0/1/0 ont 5": "  Failure: The service virtual port does not exist",
+	}}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	err := adapter.DeleteServicePort(context.Background(), "0/1/0", 5)
+	var he *types.HumanError
+	if !errors.As(err, &he) || he.Code != types.ErrCodeServicePortNotFound {
+		t.Errorf("DeleteServicePort() error = %v, want %s", err, types.ErrCodeServicePortNotFound)
+	}
+}
+
 // ============================================================================
 // GetONUProfiles tests
 // ============================================================================
@@ -2612,23 +3179,13 @@ func TestGetONUList_WithStatusFilter(t *testing.T) {
 }
 
 func TestGetONUList_WithPONPortFilter(t *testing.T) {
+	// Port index 1 is port 0/0/1, port index 2 port 0/0/2
 	snmpExec := &testutil.MockSNMPExecutor{
-		WalkResults: map[string]map[string]interface{}{
-			OIDOnuSerialNumber: {
-				"0.1.0": "HWTC00001234", // port 0/0/1
-				"0.2.0": "ZTEG00005678", // port 0/0/2
-			},
-			OIDOnuRxPower: {
-				"0.1.0": int64(-1850),
-				"0.2.0": int64(-2200),
-			},
-			OIDOnuTxPower:     {},
-			OIDOnuTemperature: {},
-			OIDOnuVoltage:     {},
-			OIDOnuDistance:    {},
-			OIDOnuCurrent:     {},
-			OIDOnuUpBytes:     {},
-			OIDOnuDownBytes:   {},
+		MIB: map[string]interface{}{
+			"." + OIDOnuSerialNumber + ".1.0": "HWTC00001234",
+			"." + OIDOnuSerialNumber + ".2.0": "ZTEG00005678",
+			"." + OIDOnuRxPower + ".1.0":      int64(-1850),
+			"." + OIDOnuRxPower + ".2.0":      int64(-2200),
 		},
 	}
 
@@ -2644,8 +3201,16 @@ func TestGetONUList_WithPONPortFilter(t *testing.T) {
 		t.Fatalf("GetONUList() error = %v", err)
 	}
 
-	if len(results) != 1 {
-		t.Fatalf("expected 1 ONU on port 0/0/1, got %d", len(results))
+	if len(results) != 1 || results[0].Serial != "HWTC00001234" || results[0].RxPowerDBm != -18.5 {
+		t.Fatalf("expected 1 ONU on port 0/0/1, got %+v", results)
+	}
+	// Only the rows of the port are walked
+	if !slices.Contains(snmpExec.Calls, "WalkSNMP:"+OIDOnuSerialNumber+".1") || slices.Contains(snmpExec.Calls, "WalkSNMP:"+OIDOnuSerialNumber) {
+		t.Errorf("calls = %v", snmpExec.Calls)
+	}
+
+	if _, err := adapter.GetONUList(context.Background(), &types.ONUFilter{PONPort: "0/1"}); err == nil {
+		t.Error("expected error for invalid PON port filter")
 	}
 }
 
@@ -2673,13 +3238,79 @@ func TestGetONUList_WithSerialFilter(t *testing.T) {
 		config:       testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
 	}
 
-	results, err := adapter.GetONUList(context.Background(), &types.ONUFilter{Serial: "HWTC"})
-	if err != nil {
-		t.Fatalf("GetONUList() error = %v", err)
+	for _, serial := range []string{"HWTC", "hwtc", "zteg-00005678", "5A54454700005678"} {
+		results, err := adapter.GetONUList(context.Background(), &types.ONUFilter{Serial: serial})
+		if err != nil {
+			t.Fatalf("GetONUList(%q) error = %v", serial, err)
+		}
+		if len(results) != 1 {
+			t.Errorf("expected 1 ONU matching serial %q, got %d", serial, len(results))
+		}
 	}
+}
 
-	if len(results) != 1 {
-		t.Fatalf("expected 1 ONU matching serial, got %d", len(results))
+func TestGetONUList_SerialLookup(t *testing.T) {
+	// Port index 259 is port 0/1/3
+	snmpExec := &testutil.MockSNMPExecutor{
+		MIB: map[string]interface{}{
+			"." + OIDOnuSerialNumber + ".259.7": "485754430011D168",
+			"." + OIDOnuSerialNumber + ".260.1": "48575443000000AA",
+		},
+	}
+	cliExec := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display ont info by-sn 485754430011D168": "  F/S/P                   : 0/1/3\n  ONT-ID                  : 7\n",
+		"display ont info by-sn 4857544300000001": "  Failure: The ONT does not exist",
+	}}
+	adapter := &Adapter{
+		baseDriver:   &testutil.MockDriver{},
+		snmpExecutor: snmpExec,
+		cliExecutor:  cliExec,
+		config:       testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	results, err := adapter.GetONUList(context.Background(), &types.ONUFilter{Serial: "HWTC0011D168"})
+	if err != nil || len(results) != 1 || results[0].PONPort != "0/1/3" || results[0].ONUID != 7 {
+		t.Fatalf("GetONUList() = %+v, %v", results, err)
+	}
+	if !slices.Contains(snmpExec.Calls, "WalkSNMP:"+OIDOnuSerialNumber+".259") {
+		t.Errorf("walk not limited to port 0/1/3: %v", snmpExec.Calls)
+	}
+
+	// A serial the OLT does not know is not walked for
+	snmpExec.Calls = nil
+	results, err = adapter.GetONUList(context.Background(), &types.ONUFilter{Serial: "HWTC00000001"})
+	if err != nil || len(results) != 0 || len(snmpExec.Calls) != 0 {
+		t.Errorf("GetONUList() = %+v, %v; calls = %v", results, err, snmpExec.Calls)
+	}
+}
+
+func TestGetONUList_Paging(t *testing.T) {
+	snmpExec := &testutil.MockSNMPExecutor{
+		MIB: map[string]interface{}{
+			"." + OIDOnuSerialNumber + ".260.2": "HWTC00000004",
+			"." + OIDOnuSerialNumber + ".259.9": "HWTC00000003",
+			"." + OIDOnuSerialNumber + ".259.1": "HWTC00000001",
+			"." + OIDOnuSerialNumber + ".259.2": "HWTC00000002",
+		},
+	}
+	adapter := &Adapter{
+		baseDriver:   &testutil.MockDriver{},
+		snmpExecutor: snmpExec,
+		config:       testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	filter := &types.ONUFilter{Limit: 3}
+	page, err := adapter.GetONUList(context.Background(), filter)
+	if err != nil || len(page) != 3 || page[0].Serial != "HWTC00000001" || page[2].Serial != "HWTC00000003" {
+		t.Fatalf("first page = %+v, %v", page, err)
+	}
+	filter.After = types.NextONUCursor(page, filter)
+	page, err = adapter.GetONUList(context.Background(), filter)
+	if err != nil || len(page) != 1 || page[0].Serial != "HWTC00000004" {
+		t.Fatalf("second page = %+v, %v", page, err)
+	}
+	if next := types.NextONUCursor(page, filter); next != "" {
+		t.Errorf("NextONUCursor() after the last page = %q", next)
 	}
 }
 
@@ -2731,6 +3362,68 @@ func TestGetONUBySerial_Found(t *testing.T) {
 	if onu.Serial != "HWTC00001234" {
 		t.Errorf("Serial = %q, want %q", onu.Serial, "HWTC00001234")
 	}
+	// The hex form of the serial finds the same ONU
+	onu, err = adapter.GetONUBySerial(context.Background(), "4857544300001234")
+	if err != nil || onu == nil || onu.Serial != "HWTC00001234" {
+		t.Errorf("GetONUBySerial(hex) = %+v, %v", onu, err)
+	}
+
+	// The last down cause is read from display ont info when CLI is available
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display ont info 0/0 1 0": "  Run state               : offline\n  Last down cause         : dying-gasp\n",
+	}}
+	adapter.cliExecutor = mock
+	onu, err = adapter.GetONUBySerial(context.Background(), "HWTC00001234")
+	if err != nil || onu == nil || onu.LastDownCause != types.DownCauseDyingGasp {
+		t.Errorf("GetONUBySerial() = %+v, %v; commands %v", onu, err, mock.Commands)
+	}
+}
+
+func TestGetONUBySerial_CLI(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display ont info by-sn 485754430011D168": `  -----------------------------------------------------------------------------
+  F/S/P                   : 0/1/3
+  ONT-ID                  : 7
+  Control flag            : active
+  Run state               : online
+  Config state            : normal
+  SN                      : 485754430011D168 (HWTC-0011D168)
+  ONT distance(m)         : 1250
+  Line profile ID         : 10
+  Last down cause         : LOSi/LOBi
+  Last down time          : 2024-01-15 10:20:11+08:00
+  -----------------------------------------------------------------------------`,
+		"display ont info by-sn 4857544300000000": "  Failure: The ONT does not exist",
+	}}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	onu, err := adapter.GetONUBySerial(context.Background(), "HWTC-0011D168")
+	if err != nil {
+		t.Fatalf("GetONUBySerial() error = %v", err)
+	}
+	want := types.ONUInfo{PONPort: "0/1/3", ONUID: 7, Serial: "HWTC0011D168", AdminState: "enabled",
+		OperState: "online", IsOnline: true, DistanceM: 1250, LineProfile: "10",
+		LastDownCause: types.DownCauseLOS, Metadata: map[string]interface{}{"last_down_cause": "LOSi/LOBi"}}
+	if onu == nil || !onu.LastOfflineTime.Equal(time.Date(2024, 1, 15, 2, 20, 11, 0, time.UTC)) {
+		t.Errorf("LastOfflineTime = %v", onu.LastOfflineTime)
+	}
+	if onu != nil {
+		onu.LastOfflineTime = time.Time{}
+	}
+	if onu == nil || !reflect.DeepEqual(*onu, want) {
+		t.Errorf("GetONUBySerial() = %+v, want %+v", onu, want)
+	}
+
+	if onu, err := adapter.GetONUBySerial(context.Background(), "HWTC00000000"); onu != nil || err != nil {
+		t.Errorf("GetONUBySerial(missing) = %+v, %v; want nil, nil", onu, err)
+	}
+	if _, err := adapter.GetONUBySerial(context.Background(), "HWTC; reboot"); err == nil {
+		t.Error("expected error for an invalid serial")
+	}
 }
 
 func TestGetONUBySerial_NotFound(t *testing.T) {
@@ -2977,6 +3670,50 @@ func TestSoftSuspendSubscriber_WalledGarden(t *testing.T) {
 	}
 }
 
+func TestSuspendSubscriber_WalledGardenMode(t *testing.T) {
+	adapter := newTestAdapter(map[string]string{
+		"display ont info 0 5": "SN : HWTC12345678\nStatus: online\n",
+		"display service-port all": `
+  Index   VLAN    Interface       ONT     GemPort   User-VLAN   Transform
+  ---------------------------------------------------------------------------------
+  7       100     0/1/0           5       1         100         translate
+`,
+	})
+	adapter.config.Metadata = map[string]string{
+		types.MetadataSuspendMode:      "walled-garden",
+		types.MetadataWalledGardenVLAN: "999",
+	}
+	mock := adapter.cliExecutor.(*testutil.MockCLIExecutor)
+	ctx := context.Background()
+
+	if err := adapter.SuspendSubscriber(ctx, "ont-0/1/0-5"); err != nil {
+		t.Fatalf("SuspendSubscriber() error: %v", err)
+	}
+	if slices.Contains(mock.Commands, "ont deactivate 0 5") ||
+		!slices.Contains(mock.Commands, "service-port vlan 999 gpon 0/1/0 ont 5 gemport 1 multi-service user-vlan 999 tag-transform translate") {
+		t.Errorf("suspend commands = %v, want the ONT moved to VLAN 999", mock.Commands)
+	}
+
+	mock.Commands = nil
+	if err := adapter.ResumeSubscriber(ctx, "ont-0/1/0-5"); err != nil {
+		t.Fatalf("ResumeSubscriber() error: %v", err)
+	}
+	if slices.Contains(mock.Commands, "ont activate 0 5") ||
+		!slices.Contains(mock.Commands, "undo service-port port 0/1/0 ont 5") ||
+		!slices.Contains(mock.Commands, "service-port vlan 100 gpon 0/1/0 ont 5 gemport 1 multi-service user-vlan 100 tag-transform translate") {
+		t.Errorf("resume commands = %v, want the ONT back on VLAN 100", mock.Commands)
+	}
+	if state, _ := adapter.GetSuspensionState(ctx, "ont-0/1/0-5"); state != nil {
+		t.Errorf("GetSuspensionState() after resume = %+v, want nil", state)
+	}
+
+	// Without a suspend mode the ONT is deactivated as before
+	adapter.config.Metadata = nil
+	if err := adapter.SuspendSubscriber(ctx, "ont-0/1/0-5"); err != nil || !slices.Contains(mock.Commands, "ont deactivate 0 5") {
+		t.Errorf("SuspendSubscriber() = %v, commands = %v", err, mock.Commands)
+	}
+}
+
 func TestSoftSuspendSubscriber_InvalidMode(t *testing.T) {
 	adapter := newTestAdapter(map[string]string{})
 
@@ -3232,3 +3969,62 @@ func TestListSubscriberONUs_Placeholder(t *testing.T) {
 		t.Errorf("Role = %q, want primary", bindings[0].Role)
 	}
 }
+
+func TestCreateSubscriber_MultiService(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display ont-lineprofile gpon profile-id 1": "  Failure: The profile does not exist",
+	}}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"))
+	sub := testutil.NewTestSubscriber("HWTC00001234", "0/1/0", 100)
+	sub.Annotations["nanoncore.com/gpon-fsp"] = "0/1/0"
+	sub.Annotations["nanoncore.com/ont-id"] = "5"
+	sub.Spec.TConts = []model.TCont{{ID: 1}, {ID: 2, DBAProfile: "iptv"}}
+	sub.Spec.GEMPorts = []model.GEMPort{
+		{ID: 1, TCont: 1, VLAN: 100},
+		{ID: 2, TCont: 2, VLAN: 300, UserVLAN: 30, Service: "IPTV"},
+	}
+
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{Name: "triple"}); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	for _, want := range []string{
+		"tcont 2 dba-profile-name iptv",
+		"gem add 2 eth tcont 2",
+		"gem mapping 2 0 vlan 30",
+		"ont port native-vlan 0 5 eth 1 vlan 100 priority 0",
+		"service-port vlan 100 gpon 0/1/0 ont 5 gemport 1 multi-service user-vlan 100 tag-transform translate",
+		"service-port vlan 300 gpon 0/1/0 ont 5 gemport 2 multi-service user-vlan 30 tag-transform translate",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	sub.Spec.GEMPorts[1].TCont = 3
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err == nil {
+		t.Error("expected error for a GEM port on an undefined T-CONT")
+	}
+}
+
+func TestCreateSubscriber_QinQ(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{}}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"))
+	sub := testutil.NewTestSubscriber("HWTC00001234", "0/1/0", 100)
+	sub.Annotations["nanoncore.com/gpon-fsp"] = "0/1/0"
+	sub.Annotations["nanoncore.com/ont-id"] = "5"
+	svlan := 1000
+	sub.Spec.SVLAN = &svlan
+
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	want := "service-port vlan 1000 gpon 0/1/0 ont 5 gemport 1 multi-service user-vlan 100 tag-transform translate-and-add inner-vlan 100"
+	if !slices.Contains(mock.Commands, want) {
+		t.Errorf("missing command %q in %v", want, mock.Commands)
+	}
+
+	svlan = 4095
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err == nil {
+		t.Error("expected error for an invalid svlan")
+	}
+}
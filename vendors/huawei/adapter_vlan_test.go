@@ -59,6 +59,34 @@ func TestParseVLANListEmpty(t *testing.T) {
 	}
 }
 
+func TestParseVLANListOLTLayout(t *testing.T) {
+	adapter := &Adapter{}
+
+	output := `
+  Command:
+          display vlan all
+  --------------------------------------------------------------------------
+    VLAN   Type     Attribute    STND Port NUM   SERV Port NUM   VLAN-Con NUM
+  --------------------------------------------------------------------------
+       1   smart    common                   1               0              -
+     100   smart    common                   1               2              -
+     300   mux      common                   0               1              -
+  --------------------------------------------------------------------------
+   Total: 3
+`
+	vlans := adapter.parseVLANList(output)
+
+	if len(vlans) != 3 {
+		t.Fatalf("Expected 3 VLANs, got %d", len(vlans))
+	}
+	if vlans[1].ID != 100 || vlans[1].Type != "smart" || vlans[1].ServicePortCount != 2 || vlans[1].Name != "" {
+		t.Errorf("Unexpected VLAN 100: %+v", vlans[1])
+	}
+	if vlans[2].Type != "mux" || vlans[2].Metadata["attribute"] != "common" {
+		t.Errorf("Unexpected VLAN 300: %+v", vlans[2])
+	}
+}
+
 func TestParseServicePortList(t *testing.T) {
 	adapter := &Adapter{}
 
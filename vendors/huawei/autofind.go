@@ -0,0 +1,56 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.AutoFindManager = (*Adapter)(nil)
+
+// SetAutoFind enables or disables ONT auto-find on a PON port with port
+// ont-auto-find in the GPON interface of its board, and sets the aging time
+// of the port's autofind entries.
+func (a *Adapter) SetAutoFind(ctx context.Context, ponPort string, cfg *types.AutoFindConfig) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	if err := cfg.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	frame, slot, port, err := parsePONPort(ponPort)
+	if err != nil {
+		return err
+	}
+
+	state := "disable"
+	if cfg.Enabled {
+		state = "enable"
+	}
+	commands := []string{
+		"enable",
+		"config",
+		fmt.Sprintf("interface gpon %d/%d", frame, slot),
+		fmt.Sprintf("port %d ont-auto-find %s", port, state),
+	}
+	if cfg.AgingTime > 0 {
+		commands = append(commands, fmt.Sprintf("port %d ont-auto-find aging-time %d", port, int(cfg.AgingTime.Seconds())))
+	}
+	commands = append(commands, "quit", "quit")
+
+	output, err := a.execConfigCommands(ctx, commands)
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "does not exist") {
+			return &types.HumanError{
+				Code:    types.ErrCodePortNotFound,
+				Message: fmt.Sprintf("PON port %s not found", ponPort),
+				Vendor:  "huawei",
+				Raw:     output,
+			}
+		}
+		return fmt.Errorf("failed to set auto-find on port %s: %w", ponPort, err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package huawei
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestSetAutoFind(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{cliExecutor: mock, config: testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")}
+
+	if err := adapter.SetAutoFind(context.Background(), "0/1/3", &types.AutoFindConfig{Enabled: true, AgingTime: 10 * time.Minute}); err != nil {
+		t.Fatalf("SetAutoFind() error = %v", err)
+	}
+	want := []string{"enable", "config", "interface gpon 0/1", "port 3 ont-auto-find enable", "port 3 ont-auto-find aging-time 600", "quit", "quit"}
+	if !slices.Equal(mock.Commands, want) {
+		t.Errorf("commands = %v, want %v", mock.Commands, want)
+	}
+
+	mock.Commands = nil
+	if err := adapter.SetAutoFind(context.Background(), "0/1/3", &types.AutoFindConfig{}); err != nil || !slices.Contains(mock.Commands, "port 3 ont-auto-find disable") {
+		t.Errorf("SetAutoFind(disabled) = %v, commands %v", err, mock.Commands)
+	}
+
+	var herr *types.HumanError
+	mock.Outputs = map[string]string{"port 9 ont-auto-find enable": "  Failure: The port does not exist"}
+	if err := adapter.SetAutoFind(context.Background(), "0/1/9", &types.AutoFindConfig{Enabled: true}); !errors.As(err, &herr) || herr.Code != types.ErrCodePortNotFound {
+		t.Errorf("missing port error = %v", err)
+	}
+	if err := adapter.SetAutoFind(context.Background(), "0/1/3", &types.AutoFindConfig{AgingTime: -time.Second}); !errors.As(err, &herr) || herr.Code != types.ErrCodeValidationFailed {
+		t.Errorf("invalid aging time error = %v", err)
+	}
+}
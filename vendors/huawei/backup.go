@@ -0,0 +1,47 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/drivers/cli"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.ConfigBackupManager = (*Adapter)(nil)
+
+// backupCommands are the MA5600T/MA5800 configuration transfer commands
+var backupCommands = cli.BackupCommands{
+	Show: "display current-configuration",
+	Export: func(server, file string) string {
+		return fmt.Sprintf("backup configuration tftp %s %s", server, file)
+	},
+	Import: func(server, file string) string {
+		return fmt.Sprintf("load configuration tftp %s %s", server, file)
+	},
+}
+
+// BackupConfig returns the current configuration inline, or uploads it to
+// a tftp:// destination
+func (a *Adapter) BackupConfig(ctx context.Context, destination string) (*types.ConfigBackup, error) {
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available")
+	}
+	// Configuration transfer requires privileged mode
+	if _, err := a.cliExecutor.ExecCommand(ctx, "enable"); err != nil {
+		return nil, fmt.Errorf("huawei config backup failed: %w", err)
+	}
+	return cli.BackupConfig(ctx, a.cliExecutor, "huawei", backupCommands, destination)
+}
+
+// RestoreConfig loads the configuration from a tftp:// source. The OLT
+// applies it after its next reboot.
+func (a *Adapter) RestoreConfig(ctx context.Context, source string) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	if _, err := a.cliExecutor.ExecCommand(ctx, "enable"); err != nil {
+		return fmt.Errorf("huawei config restore failed: %w", err)
+	}
+	return cli.RestoreConfig(ctx, a.cliExecutor, "huawei", backupCommands, source)
+}
@@ -0,0 +1,140 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Board status of the OLT status. Boards and their state come from display
+// board; CPU usage per board from hwCpuDevTable, or display cpu for the
+// active control board without SNMP.
+
+var (
+	// reBoardRow matches a slot of display board: slot ID, board name and
+	// status, e.g. "  9       H901MPLA   Active_normal   CPCF"
+	reBoardRow = regexp.MustCompile(`^\s*(\d+)\s+(\S+)\s+(\S+)`)
+
+	// reCPUOccupancy matches "CPU occupancy: 15%" in display cpu
+	reCPUOccupancy = regexp.MustCompile(`(?i)CPU occupancy\s*:\s*(\d+(?:\.\d+)?)%`)
+
+	// reDisplayProduct matches "PRODUCT : MA5800-X7" in display version
+	reDisplayProduct = regexp.MustCompile(`(?m)^\s*PRODUCT\s*:\s*(\S+)`)
+
+	// reDisplayUptime matches the uptime line of display version
+	reDisplayUptime = regexp.MustCompile(`Uptime is (\d+) day\(s\), (\d+) hour\(s\), (\d+) minute\(s\), (\d+) second\(s\)`)
+)
+
+// Board is a board of the OLT frame
+type Board struct {
+	Slot   int    `json:"slot"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+
+	// CPUPercent is the CPU usage of the board, 0 if not reported
+	CPUPercent float64 `json:"cpu_percent,omitempty"`
+}
+
+// IsNormal reports whether the board is in service: Normal, or
+// Active_normal and Standby_normal for control boards
+func (b Board) IsNormal() bool {
+	return strings.HasSuffix(strings.ToLower(b.Status), "normal")
+}
+
+// IsActiveControl reports whether the board is the active control board
+func (b Board) IsActiveControl() bool {
+	return strings.EqualFold(b.Status, "Active_normal")
+}
+
+// IsPON reports whether the board is a GPON, XG-PON or XGS-PON service
+// board, such as the H901GPHF, H901XGHD or H901XSHF
+func (b Board) IsPON() bool {
+	return strings.Contains(b.Name, "GP") || strings.Contains(b.Name, "XG") || strings.Contains(b.Name, "XS")
+}
+
+// getBoards returns the boards of frame 0 from display board, with the
+// CPU usage of each board from hwCpuDevTable if SNMP is available
+func (a *Adapter) getBoards(ctx context.Context) ([]Board, error) {
+	output, err := a.cliExecutor.ExecCommand(ctx, "display board 0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to display boards: %w", err)
+	}
+	boards := parseBoards(output)
+	if a.snmpExecutor == nil {
+		return boards, nil
+	}
+	duties, err := a.snmpExecutor.WalkSNMP(ctx, OIDHwCpuDevDuty)
+	if err != nil {
+		a.config.Log().Warn("huawei: CPU table walk failed", "error", err)
+		return boards, nil
+	}
+	for index, value := range duties {
+		// Index: <frame>.<slot>.<cpu>
+		parts := strings.Split(strings.TrimPrefix(index, "."), ".")
+		if len(parts) != 3 || parts[0] != "0" {
+			continue
+		}
+		slot, err := strconv.Atoi(parts[1])
+		duty, ok := ParseNumericSNMPValue(value)
+		if err != nil || !ok {
+			continue
+		}
+		for i := range boards {
+			if boards[i].Slot == slot {
+				boards[i].CPUPercent = max(boards[i].CPUPercent, duty)
+			}
+		}
+	}
+	return boards, nil
+}
+
+// parseBoards parses display board. Empty slots, listed with their slot ID
+// only, are skipped.
+func parseBoards(output string) []Board {
+	boards := []Board{}
+	for _, line := range strings.Split(output, "\n") {
+		m := reBoardRow.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		slot, _ := strconv.Atoi(m[1])
+		boards = append(boards, Board{Slot: slot, Name: m[2], Status: m[3]})
+	}
+	sort.Slice(boards, func(i, j int) bool { return boards[i].Slot < boards[j].Slot })
+	return boards
+}
+
+// getCPUCLI returns the CPU usage of a board from display cpu
+func (a *Adapter) getCPUCLI(ctx context.Context, slot int) (float64, error) {
+	output, err := a.cliExecutor.ExecCommand(ctx, fmt.Sprintf("display cpu 0/%d", slot))
+	if err != nil {
+		return 0, fmt.Errorf("failed to display CPU usage: %w", err)
+	}
+	m := reCPUOccupancy.FindStringSubmatch(output)
+	if m == nil {
+		return 0, fmt.Errorf("CPU occupancy not found in display cpu output")
+	}
+	return strconv.ParseFloat(m[1], 64)
+}
+
+// parseDisplayVersion parses the system version, product and uptime of
+// display version
+func parseDisplayVersion(output string) (version, product string, uptimeSeconds int64) {
+	if m := reDisplayVersion.FindStringSubmatch(output); m != nil {
+		version = m[1]
+	}
+	if m := reDisplayProduct.FindStringSubmatch(output); m != nil {
+		product = m[1]
+	}
+	if m := reDisplayUptime.FindStringSubmatch(output); m != nil {
+		var parts [4]int64
+		for i := range parts {
+			parts[i], _ = strconv.ParseInt(m[i+1], 10, 64)
+		}
+		uptimeSeconds = ((parts[0]*24+parts[1])*60+parts[2])*60 + parts[3]
+	}
+	return version, product, uptimeSeconds
+}
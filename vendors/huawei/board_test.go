@@ -0,0 +1,118 @@
+package huawei
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+const displayBoard = `  -------------------------------------------------------------------------
+  SlotID  BoardName  Status          SubType0 SubType1    Online/Offline
+  -------------------------------------------------------------------------
+  0       H901GPHF   Normal
+  1
+  2       H901GPHF   Failed
+  8       H901MPLA   Active_normal   CPCF
+  9       H901MPLA   Standby_normal  CPCF
+  -------------------------------------------------------------------------
+`
+
+const displayVersion = `  {<cr>|backplane<K>|frameid/slotid<S><Length 1-15>}:
+
+  VERSION : MA5800V100R019C10
+  PATCH   : SPC100
+  PRODUCT : MA5800-X7
+  Uptime is 1 day(s), 2 hour(s), 3 minute(s), 4 second(s)`
+
+func TestParseBoards(t *testing.T) {
+	want := []Board{
+		{Slot: 0, Name: "H901GPHF", Status: "Normal"},
+		{Slot: 2, Name: "H901GPHF", Status: "Failed"},
+		{Slot: 8, Name: "H901MPLA", Status: "Active_normal"},
+		{Slot: 9, Name: "H901MPLA", Status: "Standby_normal"},
+	}
+	boards := parseBoards(displayBoard)
+	if !reflect.DeepEqual(boards, want) {
+		t.Fatalf("parseBoards() = %+v, want %+v", boards, want)
+	}
+	if boards[1].IsNormal() || !boards[3].IsNormal() || !boards[2].IsActiveControl() || boards[3].IsActiveControl() {
+		t.Errorf("board states of %+v", boards)
+	}
+}
+
+func TestParseDisplayVersion(t *testing.T) {
+	version, product, uptime := parseDisplayVersion(displayVersion)
+	if version != "MA5800V100R019C10" || product != "MA5800-X7" || uptime != 93784 {
+		t.Errorf("parseDisplayVersion() = %q, %q, %d", version, product, uptime)
+	}
+}
+
+func TestGetOLTStatus_CLI(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display version": displayVersion,
+		"display board 0": displayBoard,
+		"display cpu 0/8": "  CPU occupancy: 23%",
+	}}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{Connected: true},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	status, err := adapter.GetOLTStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetOLTStatus() error = %v", err)
+	}
+	if status.Model != "MA5800-X7" || status.Firmware != "MA5800V100R019C10" || status.UptimeSeconds != 93784 || status.CPUPercent != 23 {
+		t.Errorf("GetOLTStatus() = %+v", status)
+	}
+	// The failed board in slot 2 makes the OLT unhealthy
+	if !status.IsReachable || status.IsHealthy {
+		t.Errorf("IsReachable = %v, IsHealthy = %v", status.IsReachable, status.IsHealthy)
+	}
+	if boards, ok := status.Metadata["boards"].([]Board); !ok || len(boards) != 4 {
+		t.Errorf("boards = %+v", status.Metadata["boards"])
+	}
+}
+
+func TestGetOLTStatus_SNMP(t *testing.T) {
+	cli := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display board 0": displayBoard,
+	}}
+	snmp := &testutil.MockSNMPExecutor{
+		BulkGetResults: map[string]interface{}{
+			OIDSysDescr:   "Huawei Integrated Access Software MA5800V100R019C10",
+			OIDSysUpTime:  uint32(360000),
+			OIDSmartAXCPU: int64(0),
+		},
+		WalkResults: map[string]map[string]interface{}{
+			OIDHwCpuDevDuty: {".0.8.0": int64(17), ".0.9.0": int64(4)},
+		},
+	}
+	adapter := &Adapter{
+		baseDriver:   &testutil.MockDriver{Connected: true},
+		cliExecutor:  cli,
+		snmpExecutor: snmp,
+		config:       testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	status, err := adapter.GetOLTStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetOLTStatus() error = %v", err)
+	}
+	if status.Firmware != "100R019C10" || status.UptimeSeconds != 3600 || status.CPUPercent != 17 {
+		t.Errorf("GetOLTStatus() = %+v", status)
+	}
+	for _, cmd := range cli.Commands {
+		if cmd == "display version" || cmd == "display cpu 0/8" {
+			t.Errorf("unexpected CLI command %q with SNMP", cmd)
+		}
+	}
+	boards := status.Metadata["boards"].([]Board)
+	if boards[3].Slot != 9 || boards[3].CPUPercent != 4 {
+		t.Errorf("boards = %+v", boards)
+	}
+}
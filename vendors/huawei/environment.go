@@ -0,0 +1,94 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.EnvironmentMonitor = (*Adapter)(nil)
+
+// Environment of the OLT frame. Power boards come from display board, fans
+// from display fan and board temperatures from display temperature of each
+// board.
+
+var (
+	// reFanRow matches a fan of display fan: fan ID, status and speed in
+	// RPM, e.g. "  0      Normal    3840"
+	reFanRow = regexp.MustCompile(`^\s*(\d+)\s+([A-Za-z_]+)(?:\s+(\d+))?\s*$`)
+
+	// reBoardTemperature matches "The temperature of the board is 45C" or
+	// "Temperature: 45" in display temperature
+	reBoardTemperature = regexp.MustCompile(`(?i)temperature[^:\d\n-]*(?::|\bis)\s*(-?\d+(?:\.\d+)?)`)
+)
+
+// IsPower reports whether the board is a power board, such as the H901PISB
+// or H801PILA
+func (b Board) IsPower() bool {
+	return strings.Contains(b.Name, "PIS") || strings.Contains(b.Name, "PIL")
+}
+
+// GetEnvironment returns the fans, the power boards and the temperature of
+// every board in service. Fans and temperatures that cannot be read are
+// left out, with the error in Metadata.
+func (a *Adapter) GetEnvironment(ctx context.Context) (*types.EnvironmentStatus, error) {
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available")
+	}
+	boards, err := a.getBoards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	env := &types.EnvironmentStatus{Timestamp: time.Now(), Metadata: map[string]interface{}{}}
+
+	var commands, slots []string
+	for _, b := range boards {
+		slot := fmt.Sprintf("0/%d", b.Slot)
+		switch {
+		case b.IsPower():
+			env.PowerSupplies = append(env.PowerSupplies, types.PowerSupplyStatus{Name: slot, Status: b.Status, Normal: b.IsNormal()})
+		case b.IsNormal():
+			commands = append(commands, "display temperature "+slot)
+			slots = append(slots, slot)
+		}
+	}
+
+	if output, err := a.cliExecutor.ExecCommand(ctx, "display fan 0"); err != nil {
+		env.Metadata["fan_error"] = err.Error()
+	} else {
+		env.Fans = parseFans(output)
+	}
+
+	if len(commands) > 0 {
+		outputs, err := a.cliExecutor.ExecCommands(ctx, commands)
+		if err != nil {
+			env.Metadata["temperature_error"] = err.Error()
+		}
+		for i, output := range outputs {
+			if m := reBoardTemperature.FindStringSubmatch(output); m != nil {
+				celsius, _ := strconv.ParseFloat(m[1], 64)
+				env.Temperatures = append(env.Temperatures, types.TemperatureSensor{Name: slots[i], Celsius: celsius})
+			}
+		}
+	}
+	return env, nil
+}
+
+// parseFans parses the fan rows of display fan
+func parseFans(output string) []types.FanStatus {
+	fans := []types.FanStatus{}
+	for _, line := range strings.Split(output, "\n") {
+		m := reFanRow.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		rpm, _ := strconv.Atoi(m[3])
+		fans = append(fans, types.FanStatus{Name: m[1], Status: m[2], Normal: strings.EqualFold(m[2], "normal"), SpeedRPM: rpm})
+	}
+	return fans
+}
@@ -0,0 +1,55 @@
+package huawei
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+const displayFan = `  ----------------------------------------
+  FanID  Status    Speed(rpm)
+  ----------------------------------------
+  0      Normal    3840
+  1      Failed    0
+  ----------------------------------------`
+
+func TestGetEnvironment(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display board 0":         displayBoard + "  18      H901PISB   Normal\n  19      H901PISB   Fault\n",
+		"display fan 0":           displayFan,
+		"display temperature 0/0": "  The temperature of the board is 45C",
+		"display temperature 0/8": "  Temperature: 52",
+		"display temperature 0/9": "  Failure: Unknown command",
+	}}
+	adapter := &Adapter{cliExecutor: mock, config: testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")}
+
+	env, err := adapter.GetEnvironment(context.Background())
+	if err != nil {
+		t.Fatalf("GetEnvironment() error = %v", err)
+	}
+	wantFans := []types.FanStatus{{Name: "0", Status: "Normal", Normal: true, SpeedRPM: 3840}, {Name: "1", Status: "Failed"}}
+	if !reflect.DeepEqual(env.Fans, wantFans) {
+		t.Errorf("Fans = %+v, want %+v", env.Fans, wantFans)
+	}
+	wantPSUs := []types.PowerSupplyStatus{{Name: "0/18", Status: "Normal", Normal: true}, {Name: "0/19", Status: "Fault"}}
+	if !reflect.DeepEqual(env.PowerSupplies, wantPSUs) {
+		t.Errorf("PowerSupplies = %+v, want %+v", env.PowerSupplies, wantPSUs)
+	}
+	// The failed board in slot 2 is not read
+	wantTemps := []types.TemperatureSensor{{Name: "0/0", Celsius: 45}, {Name: "0/8", Celsius: 52}}
+	if !reflect.DeepEqual(env.Temperatures, wantTemps) {
+		t.Errorf("Temperatures = %+v, want %+v", env.Temperatures, wantTemps)
+	}
+	if alarms := env.Alarms(); len(alarms) != 2 || alarms[0].SourceID != "fan 1" || alarms[1].SourceID != "power supply 0/19" {
+		t.Errorf("Alarms() = %+v", alarms)
+	}
+
+	mock.Errors = map[string]error{"display fan 0": errors.New("timeout")}
+	if env, err := adapter.GetEnvironment(context.Background()); err != nil || len(env.Fans) != 0 || env.Metadata["fan_error"] == nil {
+		t.Errorf("GetEnvironment() = %+v, %v", env, err)
+	}
+}
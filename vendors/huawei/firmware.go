@@ -0,0 +1,195 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/drivers/cli"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var (
+	_ types.FirmwareManager    = (*Adapter)(nil)
+	_ types.ONUFirmwareManager = (*Adapter)(nil)
+)
+
+var (
+	// reDisplayVersion matches "VERSION : MA5800V100R019C10" in display version
+	reDisplayVersion = regexp.MustCompile(`(?m)^\s*VERSION\s*:\s*(\S+)`)
+
+	// ONT software version and load state, from display ont version and
+	// display ont-load select
+	reONTMainSoftware = regexp.MustCompile(`(?mi)^\s*Main Software Version\s*:\s*(\S+)`)
+	reONTLoadState    = regexp.MustCompile(`(?mi)^\s*Load state\s*:\s*(\S+)`)
+	reONTLoadProgress = regexp.MustCompile(`(?mi)^\s*Load progress\s*:\s*(\d+)`)
+	reONTLoadFailure  = regexp.MustCompile(`(?mi)^\s*Fail(?:ure)? reason\s*:\s*(.+?)\s*$`)
+)
+
+// FirmwareVersion returns the running system version from display version
+func (a *Adapter) FirmwareVersion(ctx context.Context) (string, error) {
+	if a.cliExecutor == nil {
+		return "", fmt.Errorf("CLI executor not available")
+	}
+	output, err := a.cliExecutor.ExecCommand(ctx, "display version")
+	if err != nil {
+		return "", fmt.Errorf("huawei display version failed: %w", err)
+	}
+	match := reDisplayVersion.FindStringSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("huawei firmware version not found in display version output")
+	}
+	return match[1], nil
+}
+
+// StageFirmware loads the system package from a tftp:// or sftp:// URL into
+// the standby area of the control boards.
+func (a *Adapter) StageFirmware(ctx context.Context, image *types.FirmwareImage) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	t, err := cli.ParseTransferURL(image.URL, "tftp", "sftp")
+	if err != nil {
+		return fmt.Errorf("huawei firmware staging failed: %w", err)
+	}
+	if _, err := a.cliExecutor.ExecCommand(ctx, "enable"); err != nil {
+		return fmt.Errorf("huawei firmware staging failed: %w", err)
+	}
+
+	cmd := fmt.Sprintf("load packetfile tftp %s %s", t.Server, t.File)
+	if t.Scheme == "sftp" {
+		cmd = fmt.Sprintf("load packetfile sftp %s %s %s %s", t.Server, t.User, t.Password, t.File)
+	}
+	if err := cli.ExecTransfer(ctx, a.cliExecutor, cmd); err != nil {
+		return fmt.Errorf("huawei firmware staging failed: %w", err)
+	}
+	return nil
+}
+
+// ActivateFirmware sets the staged package as the one loaded at the next
+// reboot. RestartOLT is not yet implemented for Huawei, so upgrades must
+// use NoReboot and reboot the OLT separately.
+func (a *Adapter) ActivateFirmware(ctx context.Context, image *types.FirmwareImage) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	commands := []string{
+		"enable",
+		"config",
+		fmt.Sprintf("activate packetfile %s", image.Version),
+		"quit",
+	}
+	if _, err := a.cliExecutor.ExecCommands(ctx, commands); err != nil {
+		return fmt.Errorf("huawei firmware activation failed: %w", err)
+	}
+	return nil
+}
+
+// UploadONUFirmware registers the ONT image at a tftp:// or sftp:// URL
+// with the OLT, which downloads it when the first ONT load starts.
+func (a *Adapter) UploadONUFirmware(ctx context.Context, image *types.FirmwareImage) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	t, err := cli.ParseTransferURL(image.URL, "tftp", "sftp")
+	if err != nil {
+		return fmt.Errorf("huawei ONT image upload failed: %w", err)
+	}
+	cmd := fmt.Sprintf("ont-load info program %s tftp %s", t.File, t.Server)
+	if t.Scheme == "sftp" {
+		cmd = fmt.Sprintf("ont-load info program %s sftp %s %s %s", t.File, t.Server, t.User, t.Password)
+	}
+	commands := []string{"enable", "config", "diagnose", cmd, "quit", "quit"}
+	if _, err := a.cliExecutor.ExecCommands(ctx, commands); err != nil {
+		return fmt.Errorf("huawei ONT image upload failed: %w", err)
+	}
+	return nil
+}
+
+// UpgradeONUFirmware selects one ONT and starts the OMCI load. The ONT
+// reboots into the new image as soon as the download completes.
+func (a *Adapter) UpgradeONUFirmware(ctx context.Context, ponPort string, onuID int, image *types.FirmwareImage) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	parts := strings.Split(ponPort, "/")
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid PON port format: %s (expected frame/slot/port)", ponPort)
+	}
+	commands := []string{
+		"enable",
+		"config",
+		"diagnose",
+		fmt.Sprintf("ont-load select %s/%s %s %d", parts[0], parts[1], parts[2], onuID),
+		"ont-load start activemode immediate",
+		"quit",
+		"quit",
+	}
+	if _, err := a.cliExecutor.ExecCommands(ctx, commands); err != nil {
+		return fmt.Errorf("huawei ONT upgrade failed: %w", err)
+	}
+	return nil
+}
+
+// GetONUFirmwareStatus reads the running ONT version and the state of the
+// last ONT load
+func (a *Adapter) GetONUFirmwareStatus(ctx context.Context, ponPort string, onuID int) (*types.ONUFirmwareStatus, error) {
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available")
+	}
+	parts := strings.Split(ponPort, "/")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid PON port format: %s (expected frame/slot/port)", ponPort)
+	}
+	port, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid port number: %s", parts[2])
+	}
+
+	outputs, err := a.cliExecutor.ExecCommands(ctx, []string{
+		"enable",
+		"config",
+		fmt.Sprintf("interface gpon %s/%s", parts[0], parts[1]),
+		fmt.Sprintf("display ont version %d %d", port, onuID),
+		"quit",
+		"diagnose",
+		fmt.Sprintf("display ont-load select %s/%s %d %d", parts[0], parts[1], port, onuID),
+		"quit",
+		"quit",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("huawei ONT firmware status failed: %w", err)
+	}
+	if len(outputs) < 7 {
+		return nil, fmt.Errorf("huawei ONT firmware status failed: missing command output")
+	}
+	return parseONTFirmwareStatus(ponPort, onuID, outputs[3], outputs[6]), nil
+}
+
+// parseONTFirmwareStatus parses display ont version and display ont-load
+// select output
+func parseONTFirmwareStatus(ponPort string, onuID int, versionOutput, loadOutput string) *types.ONUFirmwareStatus {
+	status := &types.ONUFirmwareStatus{PONPort: ponPort, ONUID: onuID, State: types.ONUUpgradeIdle}
+	if m := reONTMainSoftware.FindStringSubmatch(versionOutput); m != nil {
+		status.Version = m[1]
+	}
+	if m := reONTLoadProgress.FindStringSubmatch(loadOutput); m != nil {
+		status.Percent, _ = strconv.Atoi(m[1])
+	}
+	if m := reONTLoadState.FindStringSubmatch(loadOutput); m != nil {
+		switch strings.ToLower(m[1]) {
+		case "loading", "activating", "waiting":
+			status.State = types.ONUUpgradeInProgress
+		case "success", "succeeded":
+			status.State = types.ONUUpgradeSucceeded
+		case "fail", "failed":
+			status.State = types.ONUUpgradeFailed
+			if f := reONTLoadFailure.FindStringSubmatch(loadOutput); f != nil {
+				status.Message = f[1]
+			}
+		}
+	}
+	return status
+}
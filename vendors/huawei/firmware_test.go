@@ -0,0 +1,89 @@
+package huawei
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestFirmwareVersion(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{
+			"display version": "  VERSION : MA5800V100R019C10\n  PATCH   : SPC200\n  PRODUCT : MA5800-X7\n",
+		},
+	}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"))
+
+	version, err := adapter.(*Adapter).FirmwareVersion(context.Background())
+	if err != nil {
+		t.Fatalf("FirmwareVersion() error = %v", err)
+	}
+	if version != "MA5800V100R019C10" {
+		t.Errorf("FirmwareVersion() = %q", version)
+	}
+}
+
+func TestStageFirmware(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	image := &types.FirmwareImage{Version: "MA5800V100R019C10", URL: "tftp://192.0.2.10/MA5800.bin"}
+	if err := adapter.StageFirmware(context.Background(), image); err != nil {
+		t.Fatalf("StageFirmware() error = %v", err)
+	}
+	if !slices.Contains(mock.Commands, "load packetfile tftp 192.0.2.10 MA5800.bin") {
+		t.Errorf("commands = %v", mock.Commands)
+	}
+
+	image.URL = "scp://192.0.2.10/MA5800.bin"
+	if err := adapter.StageFirmware(context.Background(), image); err == nil {
+		t.Error("scp URL should be rejected")
+	}
+}
+
+func TestParseONTFirmwareStatus(t *testing.T) {
+	version := "  ONT-ID                   : 5\n  Main Software Version    : V5R019C00S100\n  Standby Software Version : V5R019C00S050\n"
+
+	tests := []struct {
+		name        string
+		load        string
+		wantState   string
+		wantPercent int
+		wantMessage string
+	}{
+		{"idle", "", types.ONUUpgradeIdle, 0, ""},
+		{"loading", "  Load state    : loading\n  Load progress : 45%\n", types.ONUUpgradeInProgress, 45, ""},
+		{"success", "  Load state    : success\n  Load progress : 100%\n", types.ONUUpgradeSucceeded, 100, ""},
+		{"failed", "  Load state    : failed\n  Fail reason   : ONT does not support the file\n", types.ONUUpgradeFailed, 0, "ONT does not support the file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := parseONTFirmwareStatus("0/1/0", 5, version, tt.load)
+			if status.Version != "V5R019C00S100" {
+				t.Errorf("Version = %q", status.Version)
+			}
+			if status.State != tt.wantState || status.Percent != tt.wantPercent || status.Message != tt.wantMessage {
+				t.Errorf("status = %+v", status)
+			}
+		})
+	}
+}
+
+func TestUpgradeONUFirmware(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	image := &types.FirmwareImage{Version: "V5R019C00S100", URL: "tftp://192.0.2.10/ont.bin"}
+	if err := adapter.UpgradeONUFirmware(context.Background(), "0/1/0", 5, image); err != nil {
+		t.Fatalf("UpgradeONUFirmware() error = %v", err)
+	}
+	if !slices.Contains(mock.Commands, "ont-load select 0/1 0 5") || !slices.Contains(mock.Commands, "ont-load start activemode immediate") {
+		t.Errorf("commands = %v", mock.Commands)
+	}
+	if err := adapter.UpgradeONUFirmware(context.Background(), "0/1", 5, image); err == nil {
+		t.Error("expected error for invalid PON port")
+	}
+}
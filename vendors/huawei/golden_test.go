@@ -0,0 +1,73 @@
+package huawei
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// TestGolden runs the parsers over the captured output in testdata/golden.
+// Timestamps the parsers stamp with time.Now are cleared, as is the raw
+// output the parsers copy into metadata.
+func TestGolden(t *testing.T) {
+	adapter := &Adapter{config: testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")}
+	testutil.RunGolden(t, "testdata/golden", map[string]testutil.GoldenParser{
+		"parseAutofindOutput": func(_ *testing.T, in []byte) any {
+			discoveries := adapter.parseAutofindOutput(string(in))
+			for i := range discoveries {
+				discoveries[i].Timestamp = time.Time{}
+			}
+			return discoveries
+		},
+		"parseONTStatus": func(_ *testing.T, in []byte) any {
+			status := adapter.parseONTStatus(string(in), "ont-0/1/0-5")
+			status.LastActivity = time.Time{}
+			delete(status.Metadata, "cli_output")
+			return status
+		},
+		"parseOpticalInfo": func(_ *testing.T, in []byte) any {
+			status := &types.SubscriberStatus{Metadata: map[string]interface{}{}}
+			adapter.parseOpticalInfo(string(in), status)
+			return status.Metadata
+		},
+		"parseONTStats": func(_ *testing.T, in []byte) any {
+			stats := adapter.parseONTStats(string(in))
+			stats.Timestamp = time.Time{}
+			delete(stats.Metadata, "cli_output")
+			return stats
+		},
+		"parseAlarms": func(_ *testing.T, in []byte) any {
+			return adapter.parseAlarms(string(in))
+		},
+		"parseRogueONUs": func(_ *testing.T, in []byte) any {
+			return adapter.parseRogueONUs(string(in))
+		},
+		"parseVLANList": func(_ *testing.T, in []byte) any {
+			return adapter.parseVLANList(string(in))
+		},
+		"parseServicePortList": func(_ *testing.T, in []byte) any {
+			return adapter.parseServicePortList(string(in))
+		},
+		"BulkScanONUsSNMP": func(t *testing.T, in []byte) any {
+			mib, err := testutil.ParseSNMPWalk(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a := &Adapter{
+				config:       adapter.config,
+				snmpExecutor: &testutil.MockSNMPExecutor{MIB: mib},
+			}
+			onts, err := a.BulkScanONUsSNMP(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Correlated from SNMP tables, which come unordered
+			sort.Slice(onts, func(i, j int) bool { return onts[i].Index < onts[j].Index })
+			return onts
+		},
+	})
+}
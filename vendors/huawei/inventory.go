@@ -0,0 +1,180 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var (
+	_ types.BoardInventory       = (*Adapter)(nil)
+	_ types.TransceiverInventory = (*Adapter)(nil)
+)
+
+// Board and optical module inventory. Boards come from display board, with
+// their versions from display version of each slot; modules from display
+// port state of the GPON boards, and of the uplink boards in the GIU view.
+
+var (
+	// rePCBVersion matches "Pcb      Version: H901GPHF VER B" in display
+	// version of a slot
+	rePCBVersion = regexp.MustCompile(`(?im)^\s*Pcb\s+Version\s*:\s*(.+?)\s*$`)
+
+	// reBoardSoftware matches "Main Board Software Version: MA5800V100R019C10"
+	// in display version of a slot
+	reBoardSoftware = regexp.MustCompile(`(?im)software\s+version\s*:\s*(\S+)`)
+)
+
+// IsUplink reports whether the board carries uplink ports: the GIU boards,
+// such as the H902GICF or H901X2CS, and the control boards
+func (b Board) IsUplink() bool {
+	return strings.Contains(b.Name, "GIC") || strings.Contains(b.Name, "X2CS") || strings.Contains(b.Name, "MPL")
+}
+
+// GetBoards returns the boards of frame 0. The hardware and software
+// versions are read from each slot; boards whose versions cannot be read
+// are returned without them.
+func (a *Adapter) GetBoards(ctx context.Context) ([]types.BoardInfo, error) {
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available")
+	}
+	boards, err := a.getBoards(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]types.BoardInfo, len(boards))
+	commands := make([]string, len(boards))
+	for i, b := range boards {
+		infos[i] = types.BoardInfo{
+			Slot:   fmt.Sprintf("0/%d", b.Slot),
+			Type:   b.Name,
+			Status: b.Status,
+			Online: b.IsNormal(),
+		}
+		if b.CPUPercent > 0 {
+			infos[i].Metadata = map[string]interface{}{"cpu_percent": b.CPUPercent}
+		}
+		commands[i] = fmt.Sprintf("display version 0/%d", b.Slot)
+	}
+	if len(commands) == 0 {
+		return infos, nil
+	}
+
+	outputs, err := a.cliExecutor.ExecCommands(ctx, commands)
+	if err != nil {
+		a.config.Log().Warn("huawei: board versions not read", "error", err)
+	}
+	for i, output := range outputs {
+		infos[i].Hardware, infos[i].Firmware = parseBoardVersion(output)
+	}
+	return infos, nil
+}
+
+// parseBoardVersion parses the PCB and software versions of display version
+// of a slot
+func parseBoardVersion(output string) (hardware, firmware string) {
+	if m := rePCBVersion.FindStringSubmatch(output); m != nil {
+		hardware = m[1]
+	}
+	if m := reBoardSoftware.FindStringSubmatch(output); m != nil {
+		firmware = m[1]
+	}
+	return hardware, firmware
+}
+
+// GetTransceivers returns the optical modules of the PON ports of the GPON
+// boards and of the uplink ports of the uplink boards
+func (a *Adapter) GetTransceivers(ctx context.Context) ([]types.TransceiverInfo, error) {
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available")
+	}
+	boards, err := a.getBoards(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	commands := []string{"enable", "config"}
+	portTypes := map[int]string{}
+	for _, board := range boards {
+		var view, portType string
+		switch {
+		case board.IsPON():
+			view, portType = "gpon", types.TransceiverPortPON
+		case board.IsUplink():
+			view, portType = "giu", types.TransceiverPortUplink
+		default:
+			continue
+		}
+		commands = append(commands, fmt.Sprintf("interface %s 0/%d", view, board.Slot), "display port state all", "quit")
+		portTypes[len(commands)-2] = portType
+	}
+	if len(commands) == 2 {
+		return []types.TransceiverInfo{}, nil
+	}
+	commands = append(commands, "quit")
+
+	outputs, err := a.cliExecutor.ExecCommands(ctx, commands)
+	if err != nil {
+		return nil, fmt.Errorf("failed to display port state: %w", err)
+	}
+	transceivers := []types.TransceiverInfo{}
+	for i, output := range outputs {
+		if portType, ok := portTypes[i]; ok {
+			transceivers = append(transceivers, parseTransceivers(output, portType)...)
+		}
+	}
+	return transceivers, nil
+}
+
+// parseTransceivers parses the optical module lines of display port state,
+// a block of key-value lines per port starting with its F/S/P
+func parseTransceivers(output, portType string) []types.TransceiverInfo {
+	transceivers := []types.TransceiverInfo{}
+	var t *types.TransceiverInfo
+	for _, line := range strings.Split(output, "\n") {
+		m := reKeyValue.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		key, value := strings.ToLower(m[1]), m[2]
+		if key == "f/s/p" {
+			transceivers = append(transceivers, types.TransceiverInfo{Port: value, PortType: portType})
+			t = &transceivers[len(transceivers)-1]
+			continue
+		}
+		if t == nil {
+			continue
+		}
+		number, _ := strconv.ParseFloat(value, 64)
+		switch key {
+		case "optical module status":
+			t.Present = strings.EqualFold(value, "online")
+		case "optical module type", "module type":
+			t.Type = value
+		case "vendor name":
+			t.Vendor = value
+		case "vendor pn":
+			t.PartNumber = value
+		case "vendor sn":
+			t.SerialNumber = value
+		case "wavelength(nm)":
+			t.WavelengthNM = int(number)
+		case "temperature(c)":
+			t.TemperatureC = number
+		case "tx power(dbm)":
+			t.TxPowerDBm = number
+		case "rx power(dbm)":
+			t.RxPowerDBm = number
+		case "supply voltage(v)":
+			t.VoltageV = number
+		case "tx bias current(ma)", "laser bias current(ma)":
+			t.BiasCurrentMA = number
+		}
+	}
+	return transceivers
+}
@@ -0,0 +1,109 @@
+package huawei
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+const displaySlotVersion = `  Main Board: H901GPHF
+  ----------------------------------------------------------
+  Pcb      Version: H901GPHF VER B
+  Base-BOM Version: 03033KYB
+  Main Board Software Version: MA5800V100R019C10
+  ----------------------------------------------------------`
+
+const displayUplinkPortState = `  ----------------------------------------------------------------------------
+  F/S/P                                    0/8/0
+  Optical Module status                    Online
+  Port state                               Online
+  Module type                              10GBASE-LR
+  Vendor name                              HUAWEI
+  Vendor PN                                OSX010000
+  Vendor SN                                HA1234567890
+  Wavelength(nm)                           1310
+  Temperature(C)                           41
+  TX power(dBm)                            -1.52
+  RX power(dBm)                            -3.07
+  Supply voltage(V)                        3.29
+  TX bias current(mA)                      35.6
+  ----------------------------------------------------------------------------
+  F/S/P                                    0/8/1
+  Optical Module status                    Offline
+  ----------------------------------------------------------------------------`
+
+func TestGetBoards(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{
+			"display board 0":     displayBoard,
+			"display version 0/0": displaySlotVersion,
+			"display version 0/2": "  Failure: The board is not in service",
+			"display version 0/8": "  Main Board Software Version: MA5800V100R019C10",
+			"display version 0/9": "",
+		},
+	}
+	adapter := &Adapter{cliExecutor: mock, config: testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")}
+
+	boards, err := adapter.GetBoards(context.Background())
+	if err != nil {
+		t.Fatalf("GetBoards() error = %v", err)
+	}
+	if len(boards) != 4 {
+		t.Fatalf("GetBoards() = %+v, want 4 boards", boards)
+	}
+	if b := boards[0]; b.Slot != "0/0" || b.Type != "H901GPHF" || !b.Online || b.Hardware != "H901GPHF VER B" || b.Firmware != "MA5800V100R019C10" {
+		t.Errorf("boards[0] = %+v", b)
+	}
+	if b := boards[1]; b.Slot != "0/2" || b.Online || b.Firmware != "" {
+		t.Errorf("boards[1] = %+v", b)
+	}
+
+	// Boards are still listed if their versions cannot be read
+	mock.Errors = map[string]error{"display version 0/0": errors.New("timeout")}
+	if boards, err := adapter.GetBoards(context.Background()); err != nil || len(boards) != 4 || boards[0].Firmware != "" {
+		t.Errorf("GetBoards() = %+v, %v", boards, err)
+	}
+}
+
+func TestGetTransceivers(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		SequentialOutputs: map[string][]string{
+			"display port state all": {displayPortState, "", displayUplinkPortState, ""},
+		},
+		Outputs: map[string]string{"display board 0": displayBoard},
+	}
+	adapter := &Adapter{cliExecutor: mock, config: testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")}
+
+	transceivers, err := adapter.GetTransceivers(context.Background())
+	if err != nil {
+		t.Fatalf("GetTransceivers() error = %v", err)
+	}
+	if len(transceivers) != 4 {
+		t.Fatalf("GetTransceivers() = %+v, want 4 modules", transceivers)
+	}
+	if tr := transceivers[0]; tr.Port != "0/1/0" || tr.PortType != types.TransceiverPortPON || !tr.Present || tr.TemperatureC != 37 || tr.TxPowerDBm != 4.23 {
+		t.Errorf("transceivers[0] = %+v", tr)
+	}
+	want := types.TransceiverInfo{
+		Port: "0/8/0", PortType: types.TransceiverPortUplink, Present: true, Type: "10GBASE-LR",
+		Vendor: "HUAWEI", PartNumber: "OSX010000", SerialNumber: "HA1234567890", WavelengthNM: 1310,
+		TemperatureC: 41, TxPowerDBm: -1.52, RxPowerDBm: -3.07, VoltageV: 3.29, BiasCurrentMA: 35.6,
+	}
+	if tr := transceivers[2]; !reflect.DeepEqual(tr, want) {
+		t.Errorf("transceivers[2] = %+v, want %+v", tr, want)
+	}
+	if tr := transceivers[3]; tr.Port != "0/8/1" || tr.Present {
+		t.Errorf("transceivers[3] = %+v", tr)
+	}
+	// The GPON boards in the GPON view and the control boards in the GIU view
+	for _, cmd := range []string{"interface gpon 0/0", "interface gpon 0/2", "interface giu 0/8", "interface giu 0/9"} {
+		if !slices.Contains(mock.Commands, cmd) {
+			t.Errorf("commands = %v, want %q", mock.Commands, cmd)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
+)
+
+var _ types.LineQualityReader = (*Adapter)(nil)
+
+// Upstream BIP and FEC counters from display statistics in the GPON
+// interface view: of a port, and of an ONT with ont-line-quality.
+
+// GetPONLineQuality returns the upstream BIP and FEC counters of a PON port
+func (a *Adapter) GetPONLineQuality(ctx context.Context, ponPort string) (*types.LineQuality, error) {
+	outputs, err := a.runONTCommands(ctx, ponPort, 0, func(port int) []string {
+		return []string{fmt.Sprintf("display statistics port %d", port)}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to display port statistics: %w", err)
+	}
+	q, found := common.ParseLineQuality(outputs[0])
+	if !found {
+		return nil, fmt.Errorf("no line quality counters for port %s", ponPort)
+	}
+	return q, nil
+}
+
+// GetONULineQuality returns the upstream BIP and FEC counters of an ONT
+func (a *Adapter) GetONULineQuality(ctx context.Context, ponPort string, onuID int) (*types.LineQuality, error) {
+	outputs, err := a.runONTCommands(ctx, ponPort, onuID, func(port int) []string {
+		return []string{fmt.Sprintf("display statistics ont-line-quality %d %d", port, onuID)}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to display ONT line quality: %w", err)
+	}
+	q, found := common.ParseLineQuality(outputs[0])
+	if !found {
+		return nil, fmt.Errorf("no line quality counters for ONT %s/%d", ponPort, onuID)
+	}
+	return q, nil
+}
@@ -0,0 +1,37 @@
+package huawei
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestGetLineQuality(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display statistics port 0": "  Upstream BIP error count          : 25\n" +
+			"  Upstream FEC corrected codewords  : 1200\n" +
+			"  Upstream FEC uncorrectable codewords : 4\n" +
+			"  Upstream FEC total codewords      : 900000\n",
+		"display statistics ont-line-quality 0 5": "  Upstream BIP error count  : 7\n  Upstream BER  : 1.5e-09\n",
+	}}
+	adapter := &Adapter{cliExecutor: mock, config: testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")}
+
+	q, err := adapter.GetPONLineQuality(context.Background(), "0/1/0")
+	if err != nil {
+		t.Fatalf("GetPONLineQuality() error = %v", err)
+	}
+	if *q != (types.LineQuality{BIPErrors: 25, FECCorrected: 1200, FECUncorrectable: 4, FECTotal: 900000}) {
+		t.Errorf("GetPONLineQuality() = %+v", q)
+	}
+
+	q, err = adapter.GetONULineQuality(context.Background(), "0/1/0", 5)
+	if err != nil || q.BIPErrors != 7 || q.BER != 1.5e-9 {
+		t.Errorf("GetONULineQuality() = %+v, %v", q, err)
+	}
+
+	if _, err := adapter.GetONULineQuality(context.Background(), "0/1/0", 6); err == nil {
+		t.Error("GetONULineQuality() without counters should fail")
+	}
+}
@@ -0,0 +1,29 @@
+package huawei
+
+import (
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/model"
+)
+
+// multicastCommands returns the commands adding an ONT GEM port as IGMP
+// user of the multicast VLAN; the user is removed with the ONT. The
+// multicast VLAN and IGMP profile must exist on the OLT.
+func multicastCommands(frame, slot, port, ontID int, m *model.MulticastService) []string {
+	user := fmt.Sprintf("port %d/%d/%d ontid %d gemport %d", frame, slot, port, ontID, m.GetGEMPort())
+	add := "igmp user add " + user + " no-auth"
+	if m.MaxGroups > 0 {
+		add += fmt.Sprintf(" max-program %d", m.MaxGroups)
+	}
+	commands := []string{"enable", "config", "btv", add}
+	if m.Profile != "" {
+		commands = append(commands, fmt.Sprintf("igmp user bind-profile %s profile-name %s", user, m.Profile))
+	}
+	return append(commands,
+		"quit",
+		fmt.Sprintf("multicast-vlan %d", m.VLAN),
+		"igmp multicast-vlan member "+user,
+		"quit",
+		"quit",
+	)
+}
@@ -0,0 +1,48 @@
+package huawei
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestCreateSubscriberWithMulticast(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"))
+
+	sub := &model.Subscriber{
+		Name:        "test-sub",
+		Annotations: map[string]string{"nanoncore.com/gpon-fsp": "0/1/0", "nanoncore.com/ont-id": "5"},
+		Spec: model.SubscriberSpec{ONUSerial: "HWTC00001234", VLAN: 100,
+			TConts: []model.TCont{{ID: 1}},
+			GEMPorts: []model.GEMPort{
+				{ID: 1, TCont: 1, VLAN: 100},
+				{ID: 2, TCont: 1, VLAN: 1000, Service: "IPTV"},
+			},
+			Multicast: &model.MulticastService{VLAN: 1000, Profile: "iptv", MaxGroups: 8, GEMPort: 2},
+		},
+	}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	for _, want := range []string{
+		"btv",
+		"igmp user add port 0/1/0 ontid 5 gemport 2 no-auth max-program 8",
+		"igmp user bind-profile port 0/1/0 ontid 5 gemport 2 profile-name iptv",
+		"multicast-vlan 1000",
+		"igmp multicast-vlan member port 0/1/0 ontid 5 gemport 2",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	sub.Spec.Multicast.GEMPort = 3
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err == nil {
+		t.Error("multicast on an undefined GEM port should be rejected")
+	}
+}
@@ -0,0 +1,54 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.ONUEthPortManager = (*Adapter)(nil)
+
+// SetONUEthPortState sets the operational state of an ONT Ethernet port
+// with ont port attribute
+func (a *Adapter) SetONUEthPortState(ctx context.Context, ponPort string, onuID, port int, enabled bool) error {
+	state := "off"
+	if enabled {
+		state = "on"
+	}
+	err := a.execONTCommands(ctx, ponPort, onuID, func(p int) []string {
+		return []string{fmt.Sprintf("ont port attribute %d %d eth %d operational-state %s", p, onuID, port, state)}
+	})
+	if err != nil {
+		return fmt.Errorf("huawei ONT port state failed: %w", err)
+	}
+	return nil
+}
+
+// SetONUEthPortVLAN sets the VLAN mode of an ONT Ethernet port. Access and
+// trunk native VLANs are set with ont port native-vlan, trunk VLANs are
+// added with ont port vlan.
+func (a *Adapter) SetONUEthPortVLAN(ctx context.Context, ponPort string, onuID, port int, vlan *types.ONUPortVLAN) error {
+	if err := vlan.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	err := a.execONTCommands(ctx, ponPort, onuID, func(p int) []string {
+		var commands []string
+		switch vlan.Mode {
+		case types.ONUPortVLANTransparent:
+			commands = append(commands, fmt.Sprintf("ont port vlan %d %d eth %d transparent", p, onuID, port))
+		default:
+			if vlan.VLAN > 0 {
+				commands = append(commands, fmt.Sprintf("ont port native-vlan %d %d eth %d vlan %d priority 0", p, onuID, port, vlan.VLAN))
+			}
+			for _, v := range vlan.TrunkVLANs {
+				commands = append(commands, fmt.Sprintf("ont port vlan %d %d eth %d %d", p, onuID, port, v))
+			}
+		}
+		return commands
+	})
+	if err != nil {
+		return fmt.Errorf("huawei ONT port VLAN failed: %w", err)
+	}
+	return nil
+}
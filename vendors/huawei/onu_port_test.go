@@ -0,0 +1,54 @@
+package huawei
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestSetONUEthPort(t *testing.T) {
+	ctx := context.Background()
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	if err := adapter.SetONUEthPortState(ctx, "0/1/0", 5, 2, false); err != nil {
+		t.Fatalf("SetONUEthPortState() error = %v", err)
+	}
+	trunk := &types.ONUPortVLAN{Mode: types.ONUPortVLANTrunk, VLAN: 10, TrunkVLANs: []int{100, 200}}
+	if err := adapter.SetONUEthPortVLAN(ctx, "0/1/0", 5, 2, trunk); err != nil {
+		t.Fatalf("SetONUEthPortVLAN() error = %v", err)
+	}
+	if err := adapter.SetONUEthPortVLAN(ctx, "0/1/0", 5, 3, &types.ONUPortVLAN{Mode: types.ONUPortVLANTransparent}); err != nil {
+		t.Fatalf("SetONUEthPortVLAN() error = %v", err)
+	}
+	for _, want := range []string{
+		"ont port attribute 0 5 eth 2 operational-state off",
+		"ont port native-vlan 0 5 eth 2 vlan 10 priority 0",
+		"ont port vlan 0 5 eth 2 100",
+		"ont port vlan 0 5 eth 2 200",
+		"ont port vlan 0 5 eth 3 transparent",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+}
+
+func TestSetONUEthPortUnknownONT(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{
+			"ont port attribute 0 9 eth 1 operational-state on": "  Failure: The ONT does not exist\n",
+		},
+	}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	err := adapter.SetONUEthPortState(context.Background(), "0/1/0", 9, 1, true)
+	var herr *types.HumanError
+	if !errors.As(err, &herr) || herr.Code != types.ErrCodeONUNotFound {
+		t.Errorf("SetONUEthPortState() error = %v, want ONU not found", err)
+	}
+}
@@ -0,0 +1,36 @@
+package huawei
+
+import (
+	"context"
+	"iter"
+	"slices"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.ONUStreamer = (*Adapter)(nil)
+
+// GetONUStream yields the ONTs of GetONUList one PON port at a time, each
+// read with walks limited to the rows of the port
+func (a *Adapter) GetONUStream(ctx context.Context, filter *types.ONUFilter) iter.Seq2[types.ONUInfo, error] {
+	return func(yield func(types.ONUInfo, error) bool) {
+		var ports []string
+		if filter == nil || filter.PONPort == "" {
+			// The ONT counts of ListPorts would need the full walk
+			statuses, err := a.listPorts(ctx, nil)
+			if err != nil {
+				yield(types.ONUInfo{}, err)
+				return
+			}
+			for _, p := range statuses {
+				ports = append(ports, p.Port)
+			}
+			slices.SortFunc(ports, types.ComparePONPorts)
+		}
+		for onu, err := range types.StreamONUsByPort(ctx, ports, filter, a.GetONUList) {
+			if !yield(onu, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package huawei
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestGetONUStream(t *testing.T) {
+	// Port index 259 is port 0/1/3, 258 port 0/1/2
+	snmpExec := &testutil.MockSNMPExecutor{MIB: map[string]interface{}{
+		"." + OIDIfDescr + ".10":            "GPON 0/1/3",
+		"." + OIDIfDescr + ".11":            "GPON 0/1/2",
+		"." + OIDIfAdminStatus + ".10":      int64(1),
+		"." + OIDIfAdminStatus + ".11":      int64(1),
+		"." + OIDIfOperStatus + ".10":       int64(1),
+		"." + OIDIfOperStatus + ".11":       int64(1),
+		"." + OIDOnuSerialNumber + ".259.1": "HWTC00000003",
+		"." + OIDOnuSerialNumber + ".258.2": "HWTC00000002",
+		"." + OIDOnuSerialNumber + ".258.1": "HWTC00000001",
+	}}
+	adapter := &Adapter{
+		baseDriver:   &testutil.MockDriver{},
+		snmpExecutor: snmpExec,
+		config:       testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	var serials []string
+	for onu, err := range adapter.GetONUStream(context.Background(), nil) {
+		if err != nil {
+			t.Fatalf("GetONUStream() error = %v", err)
+		}
+		serials = append(serials, onu.Serial)
+	}
+	// Ports are read in order, the ONTs of a port in walk order
+	if len(serials) != 3 || serials[2] != "HWTC00000003" {
+		t.Errorf("serials = %v", serials)
+	}
+	for _, call := range snmpExec.Calls {
+		if call == "WalkSNMP:"+OIDOnuSerialNumber {
+			t.Errorf("full ONT table walked: %v", snmpExec.Calls)
+		}
+	}
+
+	// A port filter reads that port only
+	snmpExec.Calls = nil
+	var ports []string
+	for onu, err := range adapter.GetONUStream(context.Background(), &types.ONUFilter{PONPort: "0/1/3"}) {
+		if err != nil {
+			t.Fatalf("GetONUStream() error = %v", err)
+		}
+		ports = append(ports, onu.PONPort)
+	}
+	if !reflect.DeepEqual(ports, []string{"0/1/3"}) {
+		t.Errorf("ports = %v", ports)
+	}
+}
@@ -0,0 +1,112 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// PON port state over CLI, for OLTs without SNMP. display port state is a
+// command of the GPON interface view, so it is run once per PON board.
+
+// reKeyValue matches a "key    value" line of display port state, with
+// the key and value separated by at least two spaces
+var reKeyValue = regexp.MustCompile(`^\s*(\S.*?)\s{2,}(\S.*?)\s*$`)
+
+// listPortsCLI returns the PON ports of the PON boards from display port
+// state, counting the ONUs of each port in onus
+func (a *Adapter) listPortsCLI(ctx context.Context, boards []Board, onus []types.ONUInfo) ([]*types.PONPortStatus, error) {
+	commands := []string{"enable", "config"}
+	for _, board := range boards {
+		if board.IsPON() {
+			commands = append(commands, fmt.Sprintf("interface gpon 0/%d", board.Slot), "display port state all", "quit")
+		}
+	}
+	if len(commands) == 2 {
+		return []*types.PONPortStatus{}, nil
+	}
+	commands = append(commands, "quit")
+
+	outputs, err := a.cliExecutor.ExecCommands(ctx, commands)
+	if err != nil {
+		return nil, fmt.Errorf("failed to display port state: %w", err)
+	}
+
+	onuCountByPort := make(map[string]int)
+	for _, onu := range onus {
+		onuCountByPort[onu.PONPort]++
+	}
+	ports := []*types.PONPortStatus{}
+	for i, cmd := range commands {
+		if cmd != "display port state all" || i >= len(outputs) {
+			continue
+		}
+		for _, port := range parsePortState(outputs[i]) {
+			port.ONUCount = onuCountByPort[port.Port]
+			ports = append(ports, port)
+		}
+	}
+	return ports, nil
+}
+
+// parsePortState parses display port state, a block of key-value lines
+// per port starting with its F/S/P:
+//
+//	F/S/P                                    0/1/0
+//	Optical Module status                    Online
+//	Port state                               Online
+//	...
+//
+// A shut down port is in state Disable.
+func parsePortState(output string) []*types.PONPortStatus {
+	ports := []*types.PONPortStatus{}
+	var port *types.PONPortStatus
+	for _, line := range strings.Split(output, "\n") {
+		m := reKeyValue.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		key, value := strings.ToLower(m[1]), m[2]
+		if key == "f/s/p" {
+			port = &types.PONPortStatus{
+				Port:       value,
+				AdminState: "enabled",
+				OperState:  "unknown",
+				MaxONUs:    128, // Typical GPON limit
+				Metadata:   map[string]interface{}{},
+			}
+			ports = append(ports, port)
+			continue
+		}
+		if port == nil {
+			continue
+		}
+		switch key {
+		case "port state":
+			switch strings.ToLower(value) {
+			case "online":
+				port.OperState = "up"
+			case "offline":
+				port.OperState = "down"
+			case "disable", "disabled", "deactivated":
+				port.AdminState = "disabled"
+				port.OperState = "down"
+			}
+		case "optical module status":
+			port.Metadata["optical_module"] = value
+		case "laser state":
+			port.Metadata["laser_state"] = value
+		case "temperature(c)":
+			if v, err := strconv.ParseFloat(value, 64); err == nil {
+				port.Metadata["temperature_c"] = v
+			}
+		case "tx power(dbm)":
+			port.TxPowerDBm, _ = strconv.ParseFloat(value, 64)
+		}
+	}
+	return ports
+}
@@ -0,0 +1,66 @@
+package huawei
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+const displayPortState = `  ----------------------------------------------------------------------------
+  F/S/P                                    0/1/0
+  Optical Module status                    Online
+  Port state                               Online
+  Laser state                              Normal
+  Available bandwidth(Kbps)                1238110
+  Temperature(C)                           37
+  TX power(dBm)                            4.23
+  ----------------------------------------------------------------------------
+  F/S/P                                    0/1/1
+  Optical Module status                    Online
+  Port state                               Disable
+  Laser state                              Normal
+  Temperature(C)                           36
+  TX power(dBm)                            4.11
+  ----------------------------------------------------------------------------`
+
+func TestParsePortState(t *testing.T) {
+	ports := parsePortState(displayPortState)
+	if len(ports) != 2 {
+		t.Fatalf("parsePortState() = %+v, want 2 ports", ports)
+	}
+	if p := ports[0]; p.Port != "0/1/0" || p.AdminState != "enabled" || p.OperState != "up" || p.TxPowerDBm != 4.23 || p.Metadata["temperature_c"] != 37.0 {
+		t.Errorf("ports[0] = %+v", p)
+	}
+	if p := ports[1]; p.Port != "0/1/1" || p.AdminState != "disabled" || p.OperState != "down" {
+		t.Errorf("ports[1] = %+v", p)
+	}
+}
+
+func TestListPorts_CLI(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{SequentialOutputs: map[string][]string{
+		"display port state all": {displayPortState, "  F/S/P                                    0/2/0\n  Port state                               Offline"},
+	}, Outputs: map[string]string{
+		"display board 0": displayBoard,
+	}}
+	adapter := &Adapter{
+		baseDriver:  &testutil.MockDriver{Connected: true},
+		cliExecutor: mock,
+		config:      testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"),
+	}
+
+	ports, err := adapter.ListPorts(context.Background())
+	if err != nil {
+		t.Fatalf("ListPorts() error = %v", err)
+	}
+	if len(ports) != 3 || ports[2].Port != "0/2/0" || ports[2].OperState != "down" {
+		t.Errorf("ListPorts() = %+v", ports)
+	}
+	// The GPON boards in slots 0 and 2; not the control boards
+	if !slices.Contains(mock.Commands, "interface gpon 0/0") || !slices.Contains(mock.Commands, "interface gpon 0/2") ||
+		slices.Contains(mock.Commands, "interface gpon 0/8") {
+		t.Errorf("commands = %v", mock.Commands)
+	}
+}
@@ -0,0 +1,432 @@
+package huawei
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
+)
+
+var (
+	_ types.LineProfileManager    = (*Adapter)(nil)
+	_ types.ServiceProfileManager = (*Adapter)(nil)
+)
+
+// defaultDBAProfileID is the DBA profile bound to T-CONT 1 of the line
+// profiles generated from a tier without a nanoncore.com/dba-profile-id
+// annotation. IDs 1 to 9 are predefined on the OLT.
+const defaultDBAProfileID = 9
+
+var (
+	reHWProfileValidName = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,32}$`)
+	reHWProfileRow       = regexp.MustCompile(`^\s*(\d+)\s+(\S+)\s+\d+\s*$`)
+	reHWProfileID        = regexp.MustCompile(`(?m)^\s*Profile-ID\s*:\s*(\d+)`)
+	reHWProfileName      = regexp.MustCompile(`(?m)^\s*Profile-name\s*:\s*(\S+)`)
+	reHWProfileTcont     = regexp.MustCompile(`<T-CONT\s+(\d+)>\s+DBA Profile-ID:\s*(\d+)`)
+	reHWProfileGem       = regexp.MustCompile(`<Gem Index\s+(\d+)>`)
+	reHWProfileMapping   = regexp.MustCompile(`^\s*(\d+)\s+(\d+|-)\s+`)
+	reHWProfilePorts     = regexp.MustCompile(`(?m)^\s*(ETH|POTS)\s+(\d+|adaptive)\s*$`)
+)
+
+// ListLineProfiles lists the GPON ONT line profiles on the OLT.
+func (a *Adapter) ListLineProfiles(ctx context.Context) ([]*types.LineProfile, error) {
+	output, err := a.execProfileDisplay(ctx, "display ont-lineprofile gpon all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list line profiles: %w", err)
+	}
+	profiles := []*types.LineProfile{}
+	for _, row := range parseProfileTable(output) {
+		profiles = append(profiles, &types.LineProfile{Name: row.name, ID: &row.id})
+	}
+	return profiles, nil
+}
+
+// GetLineProfile retrieves a GPON ONT line profile by name.
+func (a *Adapter) GetLineProfile(ctx context.Context, name string) (*types.LineProfile, error) {
+	if err := validateProfileName(name); err != nil {
+		return nil, err
+	}
+	output, err := a.execProfileDisplay(ctx, fmt.Sprintf("display ont-lineprofile gpon profile-name %s", name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get line profile: %w", err)
+	}
+	if profileMissing(output) {
+		return nil, fmt.Errorf("profile name %s not found", name)
+	}
+	return parseLineProfile(output), nil
+}
+
+// CreateLineProfile creates and commits a GPON ONT line profile. Tcont DBA
+// is a DBA profile ID, or a DBA profile name if it is not a number. GEM
+// port services are mapped by VLAN, in order; Huawei mappings have no name,
+// so service names are not sent.
+func (a *Adapter) CreateLineProfile(ctx context.Context, profile *types.LineProfile) error {
+	if err := profile.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	if err := validateProfileName(profile.Name); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	if err := a.execProfileConfig(ctx, buildLineProfileCommands(profile)); err != nil {
+		return fmt.Errorf("failed to create line profile %s: %w", profile.Name, err)
+	}
+	return nil
+}
+
+// DeleteLineProfile deletes a GPON ONT line profile by name. The OLT
+// refuses to delete a profile still bound to ONTs.
+func (a *Adapter) DeleteLineProfile(ctx context.Context, name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	if err := a.execProfileConfig(ctx, []string{fmt.Sprintf("undo ont-lineprofile gpon profile-name %s", name)}); err != nil {
+		return fmt.Errorf("failed to delete line profile %s: %w", name, err)
+	}
+	return nil
+}
+
+// ListServiceProfiles lists the GPON ONT service profiles on the OLT.
+func (a *Adapter) ListServiceProfiles(ctx context.Context) ([]*types.ServiceProfile, error) {
+	output, err := a.execProfileDisplay(ctx, "display ont-srvprofile gpon all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service profiles: %w", err)
+	}
+	profiles := []*types.ServiceProfile{}
+	for _, row := range parseProfileTable(output) {
+		profiles = append(profiles, &types.ServiceProfile{Name: row.name, ID: &row.id})
+	}
+	return profiles, nil
+}
+
+// GetServiceProfile retrieves a GPON ONT service profile by name.
+func (a *Adapter) GetServiceProfile(ctx context.Context, name string) (*types.ServiceProfile, error) {
+	if err := validateProfileName(name); err != nil {
+		return nil, err
+	}
+	output, err := a.execProfileDisplay(ctx, fmt.Sprintf("display ont-srvprofile gpon profile-name %s", name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service profile: %w", err)
+	}
+	if profileMissing(output) {
+		return nil, fmt.Errorf("profile name %s not found", name)
+	}
+	return parseServiceProfile(output), nil
+}
+
+// CreateServiceProfile creates and commits a GPON ONT service profile.
+func (a *Adapter) CreateServiceProfile(ctx context.Context, profile *types.ServiceProfile) error {
+	if err := profile.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	if err := validateProfileName(profile.Name); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	if err := a.execProfileConfig(ctx, buildServiceProfileCommands(profile)); err != nil {
+		return fmt.Errorf("failed to create service profile %s: %w", profile.Name, err)
+	}
+	return nil
+}
+
+// DeleteServiceProfile deletes a GPON ONT service profile by name. The OLT
+// refuses to delete a profile still bound to ONTs.
+func (a *Adapter) DeleteServiceProfile(ctx context.Context, name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	if err := a.execProfileConfig(ctx, []string{fmt.Sprintf("undo ont-srvprofile gpon profile-name %s", name)}); err != nil {
+		return fmt.Errorf("failed to delete service profile %s: %w", name, err)
+	}
+	return nil
+}
+
+// ensureTierProfiles creates the line and service profiles a subscriber
+// create binds the ONT to when they do not exist on the OLT, generated from
+// the tier. Profiles are shared by every subscriber of the tier, so they
+// are kept if the create fails afterwards.
+func (a *Adapter) ensureTierProfiles(ctx context.Context, tier *model.ServiceTier, lineProfileID, srvProfileID int, tconts []model.TCont, gems []model.GEMPort) error {
+	exists, err := a.profileExists(ctx, "ont-lineprofile", lineProfileID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := a.CreateLineProfile(ctx, tierLineProfile(tier, lineProfileID, tconts, gems)); err != nil {
+			return err
+		}
+	}
+
+	exists, err = a.profileExists(ctx, "ont-srvprofile", srvProfileID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		id := srvProfileID
+		if err := a.CreateServiceProfile(ctx, &types.ServiceProfile{Name: tierProfileName(tier, id), ID: &id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// profileExists reports whether the kind ("ont-lineprofile" or
+// "ont-srvprofile") profile with the given ID exists. Only an explicit
+// "does not exist" from the OLT counts as missing.
+func (a *Adapter) profileExists(ctx context.Context, kind string, id int) (bool, error) {
+	output, err := a.execProfileDisplay(ctx, fmt.Sprintf("display %s gpon profile-id %d", kind, id))
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s %d: %w", kind, id, err)
+	}
+	return !profileMissing(output), nil
+}
+
+// tierLineProfile is the line profile generated for a tier, from the GEM
+// mapping of the subscriber that is being provisioned: its T-CONTs, with
+// their DBA profile or the tier's, and its GEM ports mapped to their user
+// VLAN, as their service-ports expect.
+func tierLineProfile(tier *model.ServiceTier, id int, tconts []model.TCont, gems []model.GEMPort) *types.LineProfile {
+	dba := defaultDBAProfileID
+	if tier != nil {
+		dba = common.GetAnnotationIntWithDefault(tier.Annotations, defaultDBAProfileID, "nanoncore.com/dba-profile-id")
+	}
+	profile := &types.LineProfile{Name: tierProfileName(tier, id), ID: &id}
+	for _, t := range tconts {
+		tcont := &types.LineProfileTcont{ID: t.ID, DBA: t.DBAProfile}
+		if tcont.DBA == "" {
+			tcont.DBA = strconv.Itoa(dba)
+		}
+		for _, g := range gems {
+			if g.TCont != t.ID {
+				continue
+			}
+			gem := &types.LineProfileGemport{ID: g.ID, TcontID: t.ID}
+			if vlan := g.GetUserVLAN(); vlan > 0 {
+				gem.Services = []*types.LineProfileService{{Name: g.GetService(), GemportID: g.ID, VLAN: vlan}}
+			}
+			tcont.Gemports = append(tcont.Gemports, gem)
+		}
+		profile.Tconts = append(profile.Tconts, tcont)
+	}
+	return profile
+}
+
+// tierProfileName names the profiles generated for a tier after the tier,
+// or after the profile ID if the tier name is not a valid profile name
+func tierProfileName(tier *model.ServiceTier, id int) string {
+	if tier != nil {
+		if name := "nano-" + tier.Name; tier.Name != "" && validateProfileName(name) == nil {
+			return name
+		}
+	}
+	return fmt.Sprintf("nano-%d", id)
+}
+
+func buildLineProfileCommands(profile *types.LineProfile) []string {
+	commands := []string{profileHeader("ont-lineprofile", profile.Name, profile.ID)}
+	for _, tcont := range profile.Tconts {
+		if tcont == nil {
+			continue
+		}
+		switch {
+		case tcont.DBA == "":
+			commands = append(commands, fmt.Sprintf("tcont %d", tcont.ID))
+		case isNumeric(tcont.DBA):
+			commands = append(commands, fmt.Sprintf("tcont %d dba-profile-id %s", tcont.ID, tcont.DBA))
+		default:
+			commands = append(commands, fmt.Sprintf("tcont %d dba-profile-name %s", tcont.ID, common.SanitizeCLIParam(tcont.DBA)))
+		}
+	}
+	for _, tcont := range profile.Tconts {
+		if tcont == nil {
+			continue
+		}
+		for _, gem := range tcont.Gemports {
+			if gem == nil {
+				continue
+			}
+			commands = append(commands, fmt.Sprintf("gem add %d eth tcont %d", gem.ID, gem.TcontID))
+			idx := 0
+			for _, svc := range gem.Services {
+				if svc == nil || svc.VLAN == 0 {
+					continue
+				}
+				commands = append(commands, fmt.Sprintf("gem mapping %d %d vlan %d", gem.ID, idx, svc.VLAN))
+				idx++
+			}
+		}
+	}
+	return append(commands, "commit", "quit")
+}
+
+func buildServiceProfileCommands(profile *types.ServiceProfile) []string {
+	return []string{
+		profileHeader("ont-srvprofile", profile.Name, profile.ID),
+		fmt.Sprintf("ont-port eth %s pots %s", portCount(profile.EthPorts), portCount(profile.PotsPorts)),
+		"commit",
+		"quit",
+	}
+}
+
+// profileHeader enters the profile view, creating the profile
+func profileHeader(kind, name string, id *int) string {
+	if id != nil {
+		return fmt.Sprintf("%s gpon profile-id %d profile-name %s", kind, *id, name)
+	}
+	return fmt.Sprintf("%s gpon profile-name %s", kind, name)
+}
+
+func portCount(n *int) string {
+	if n == nil {
+		return "adaptive"
+	}
+	return strconv.Itoa(*n)
+}
+
+// execProfileDisplay runs a profile display command. A display of a profile
+// that does not exist is not an error; callers check the output with
+// profileMissing.
+func (a *Adapter) execProfileDisplay(ctx context.Context, cmd string) (string, error) {
+	if a.cliExecutor == nil {
+		return "", fmt.Errorf("CLI executor not available - Huawei requires CLI driver")
+	}
+	output, err := a.cliExecutor.ExecCommand(ctx, cmd)
+	if err != nil && !strings.Contains(err.Error(), "does not exist") {
+		return "", err
+	}
+	if err != nil {
+		return err.Error(), nil
+	}
+	return output, nil
+}
+
+// execProfileConfig runs profile commands in config mode. A command the CLI
+// rejects with "Failure:" fails the call.
+func (a *Adapter) execProfileConfig(ctx context.Context, cmds []string) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available - Huawei requires CLI driver")
+	}
+	commands := append([]string{"enable", "config"}, cmds...)
+	commands = append(commands, "quit")
+	outputs, err := a.cliExecutor.ExecCommands(ctx, commands)
+	if err != nil {
+		return err
+	}
+	if m := reHWFailure.FindStringSubmatch(strings.Join(outputs, "\n")); m != nil {
+		return errors.New(m[1])
+	}
+	return nil
+}
+
+func validateProfileName(name string) error {
+	if name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+	if !reHWProfileValidName.MatchString(name) {
+		return fmt.Errorf("profile name %q is invalid (up to 32 alphanumeric, underscore, hyphen or dot characters)", name)
+	}
+	return nil
+}
+
+func profileMissing(output string) bool {
+	return strings.Contains(output, "does not exist")
+}
+
+func isNumeric(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+type profileRow struct {
+	id   int
+	name string
+}
+
+// parseProfileTable parses the Profile-ID / Profile-name / Binding times
+// table of "display ont-lineprofile gpon all" and "display ont-srvprofile
+// gpon all"
+func parseProfileTable(output string) []profileRow {
+	var rows []profileRow
+	for _, line := range strings.Split(output, "\n") {
+		m := reHWProfileRow.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		id, _ := strconv.Atoi(m[1])
+		rows = append(rows, profileRow{id: id, name: m[2]})
+	}
+	return rows
+}
+
+// parseLineProfile parses the display of one line profile. GEM ports are
+// listed under the T-CONT that carries them; their VLAN mappings follow
+// the Mapping index header and are named by their index.
+func parseLineProfile(output string) *types.LineProfile {
+	profile := &types.LineProfile{}
+	if m := reHWProfileName.FindStringSubmatch(output); m != nil {
+		profile.Name = m[1]
+	}
+	if m := reHWProfileID.FindStringSubmatch(output); m != nil {
+		id, _ := strconv.Atoi(m[1])
+		profile.ID = &id
+	}
+
+	var tcont *types.LineProfileTcont
+	var gem *types.LineProfileGemport
+	inMappings := false
+	for _, line := range strings.Split(output, "\n") {
+		if m := reHWProfileTcont.FindStringSubmatch(line); m != nil {
+			id, _ := strconv.Atoi(m[1])
+			tcont = &types.LineProfileTcont{ID: id, DBA: m[2]}
+			profile.Tconts = append(profile.Tconts, tcont)
+			gem, inMappings = nil, false
+			continue
+		}
+		if m := reHWProfileGem.FindStringSubmatch(line); m != nil && tcont != nil {
+			id, _ := strconv.Atoi(m[1])
+			gem = &types.LineProfileGemport{ID: id, TcontID: tcont.ID}
+			tcont.Gemports = append(tcont.Gemports, gem)
+			inMappings = false
+			continue
+		}
+		if gem == nil {
+			continue
+		}
+		if strings.Contains(line, "Mapping") && strings.Contains(line, "VLAN") {
+			inMappings = true
+			continue
+		}
+		if m := reHWProfileMapping.FindStringSubmatch(line); m != nil && inMappings {
+			vlan, _ := strconv.Atoi(m[2])
+			gem.Services = append(gem.Services, &types.LineProfileService{Name: m[1], GemportID: gem.ID, VLAN: vlan})
+		}
+	}
+	return profile
+}
+
+// parseServiceProfile parses the display of one service profile
+func parseServiceProfile(output string) *types.ServiceProfile {
+	profile := &types.ServiceProfile{}
+	if m := reHWProfileName.FindStringSubmatch(output); m != nil {
+		profile.Name = m[1]
+	}
+	if m := reHWProfileID.FindStringSubmatch(output); m != nil {
+		id, _ := strconv.Atoi(m[1])
+		profile.ID = &id
+	}
+	for _, m := range reHWProfilePorts.FindAllStringSubmatch(output, -1) {
+		if m[2] == "adaptive" {
+			continue
+		}
+		n, _ := strconv.Atoi(m[2])
+		if m[1] == "ETH" {
+			profile.EthPorts = &n
+		} else {
+			profile.PotsPorts = &n
+		}
+	}
+	return profile
+}
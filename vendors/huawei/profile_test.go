@@ -0,0 +1,213 @@
+package huawei
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+const lineProfileDisplay = `  -----------------------------------------------------------------------------
+  Profile-ID          :10
+  Profile-name        :nano-gold
+  Access-type         :GPON
+  -----------------------------------------------------------------------------
+  FEC upstream switch :Disable
+  -----------------------------------------------------------------------------
+  <T-CONT   0>          DBA Profile-ID:1
+  <T-CONT   1>          DBA Profile-ID:12
+   <Gem Index 1>
+   -----------------------------------------------------------------------------
+    |Serv-Type:ETH  |Encrypt:off |Cascade:off |GEM-CAR:-   |Upstream-priority-queue:0
+   -----------------------------------------------------------------------------
+    Mapping VLAN Priority Port Port Bundle Flow  Transparent
+    index             type  ID   ID     CAR
+   -----------------------------------------------------------------------------
+    0       100  -        -    -    -      -     -
+    1       200  -        -    -    -      -     -
+   -----------------------------------------------------------------------------
+`
+
+func TestListLineProfiles(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display ont-lineprofile gpon all": `  -----------------------------------------------------------------------------
+  Profile-ID  Profile-name                                Binding times
+  -----------------------------------------------------------------------------
+  0           line-profile_default_0                      0
+  10          nano-gold                                   12
+  -----------------------------------------------------------------------------
+  Total: 2
+`,
+	}}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	profiles, err := adapter.ListLineProfiles(context.Background())
+	if err != nil {
+		t.Fatalf("ListLineProfiles() error = %v", err)
+	}
+	if len(profiles) != 2 || profiles[1].Name != "nano-gold" || *profiles[1].ID != 10 {
+		t.Errorf("unexpected profiles %+v", profiles)
+	}
+}
+
+func TestGetLineProfile(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display ont-lineprofile gpon profile-name nano-gold": lineProfileDisplay,
+		"display ont-lineprofile gpon profile-name missing":   "  Failure: The profile does not exist",
+	}}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	profile, err := adapter.GetLineProfile(context.Background(), "nano-gold")
+	if err != nil {
+		t.Fatalf("GetLineProfile() error = %v", err)
+	}
+	if profile.Name != "nano-gold" || *profile.ID != 10 || len(profile.Tconts) != 2 {
+		t.Fatalf("unexpected profile %+v", profile)
+	}
+	tcont := profile.Tconts[1]
+	if tcont.ID != 1 || tcont.DBA != "12" || len(tcont.Gemports) != 1 {
+		t.Fatalf("unexpected T-CONT %+v", tcont)
+	}
+	gem := tcont.Gemports[0]
+	if gem.ID != 1 || gem.TcontID != 1 || len(gem.Services) != 2 || gem.Services[1].VLAN != 200 {
+		t.Errorf("unexpected GEM port %+v", gem)
+	}
+
+	if _, err := adapter.GetLineProfile(context.Background(), "missing"); err == nil {
+		t.Error("expected error for a missing profile")
+	}
+}
+
+func TestCreateLineProfile(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	id := 10
+	profile := &types.LineProfile{
+		Name: "nano-gold",
+		ID:   &id,
+		Tconts: []*types.LineProfileTcont{
+			{ID: 1, DBA: "12", Gemports: []*types.LineProfileGemport{
+				{ID: 1, TcontID: 1, Services: []*types.LineProfileService{{Name: "INTERNET", GemportID: 1, VLAN: 100}}},
+			}},
+			{ID: 2, DBA: "iptv"},
+		},
+	}
+	if err := adapter.CreateLineProfile(context.Background(), profile); err != nil {
+		t.Fatalf("CreateLineProfile() error = %v", err)
+	}
+	want := []string{
+		"enable",
+		"config",
+		"ont-lineprofile gpon profile-id 10 profile-name nano-gold",
+		"tcont 1 dba-profile-id 12",
+		"tcont 2 dba-profile-name iptv",
+		"gem add 1 eth tcont 1",
+		"gem mapping 1 0 vlan 100",
+		"commit",
+		"quit",
+		"quit",
+	}
+	if !slices.Equal(mock.Commands, want) {
+		t.Errorf("commands = %v, want %v", mock.Commands, want)
+	}
+
+	if err := adapter.CreateLineProfile(context.Background(), &types.LineProfile{Name: "bad name"}); err == nil {
+		t.Error("expected error for an invalid profile name")
+	}
+}
+
+func TestCreateServiceProfile(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	pots := 2
+	if err := adapter.CreateServiceProfile(context.Background(), &types.ServiceProfile{Name: "hg8245", PotsPorts: &pots}); err != nil {
+		t.Fatalf("CreateServiceProfile() error = %v", err)
+	}
+	for _, want := range []string{"ont-srvprofile gpon profile-name hg8245", "ont-port eth adaptive pots 2", "commit"} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+}
+
+func TestGetServiceProfile(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display ont-srvprofile gpon profile-name hg8245": `  Profile-ID  : 20
+  Profile-name: hg8245
+  Access-type : GPON
+  -----------------------------------------------------------------------------
+  Port-type     Port-number
+  -----------------------------------------------------------------------------
+  POTS          2
+  ETH           adaptive
+  -----------------------------------------------------------------------------
+`,
+	}}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	profile, err := adapter.GetServiceProfile(context.Background(), "hg8245")
+	if err != nil {
+		t.Fatalf("GetServiceProfile() error = %v", err)
+	}
+	if profile.Name != "hg8245" || *profile.ID != 20 || profile.EthPorts != nil || profile.PotsPorts == nil || *profile.PotsPorts != 2 {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+}
+
+func TestDeleteProfileFailure(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"undo ont-lineprofile gpon profile-name nano-gold": "  Failure: The profile has been bound",
+	}}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	if err := adapter.DeleteLineProfile(context.Background(), "nano-gold"); err == nil {
+		t.Error("expected error for a bound profile")
+	}
+	if err := adapter.DeleteServiceProfile(context.Background(), "nano-gold"); err != nil {
+		t.Errorf("DeleteServiceProfile() error = %v", err)
+	}
+}
+
+func TestCreateSubscriberCreatesMissingProfiles(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display ont-lineprofile gpon profile-id 10": "  Failure: The profile does not exist",
+		"display ont-srvprofile gpon profile-id 20":  lineProfileDisplay,
+	}}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	sub := testutil.NewTestSubscriber("HWTC00001234", "0/1/0", 100)
+	tier := &model.ServiceTier{
+		Name: "gold",
+		Annotations: map[string]string{
+			"nanoncore.com/line-profile-id": "10",
+			"nanoncore.com/srv-profile-id":  "20",
+			"nanoncore.com/dba-profile-id":  "12",
+		},
+	}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, tier); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	for _, want := range []string{
+		"ont-lineprofile gpon profile-id 10 profile-name nano-gold",
+		"tcont 1 dba-profile-id 12",
+		"gem add 1 eth tcont 1",
+		"gem mapping 1 0 vlan 100",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+	if slices.Contains(mock.Commands, "ont-srvprofile gpon profile-id 20 profile-name nano-gold") {
+		t.Error("existing service profile should not be recreated")
+	}
+	ontAdd := slices.IndexFunc(mock.Commands, func(cmd string) bool { return strings.HasPrefix(cmd, "ont add") })
+	if slices.Index(mock.Commands, "commit") > ontAdd {
+		t.Error("line profile should be created before the ONT is added")
+	}
+}
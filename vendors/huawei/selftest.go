@@ -0,0 +1,40 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
+)
+
+var _ types.ONUTester = (*Adapter)(nil)
+
+// RunONUTest runs the OMCI self-test of an ONT with ont self-test, or a
+// loopback on one of its Ethernet ports with ont port loopback-test.
+func (a *Adapter) RunONUTest(ctx context.Context, ponPort string, onuID int, test types.ONUTest) (*types.ONUTestResult, error) {
+	if err := test.Validate(); err != nil {
+		return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	outputs, err := a.runONTCommands(ctx, ponPort, onuID, func(port int) []string {
+		if test.Kind == types.ONUTestLoopback {
+			return []string{fmt.Sprintf("ont port loopback-test %d %d eth %d", port, onuID, test.Port)}
+		}
+		return []string{fmt.Sprintf("ont self-test %d %d", port, onuID)}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("huawei ONT %s failed: %w", test.Kind, err)
+	}
+	detail, passed, found := common.ParseTestResult(outputs[0])
+	if !found {
+		return nil, fmt.Errorf("huawei ONT %s returned no result", test.Kind)
+	}
+	return &types.ONUTestResult{
+		ONUTest:   test,
+		Passed:    passed,
+		Detail:    detail,
+		Raw:       outputs[0],
+		Timestamp: time.Now(),
+	}, nil
+}
@@ -0,0 +1,39 @@
+package huawei
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestRunONUTest(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{
+			"ont self-test 0 5":                "  F/S/P       : 0/1/0\n  ONT-ID      : 5\n  Test result : pass\n",
+			"ont port loopback-test 0 5 eth 2": "  Test result : fail\n",
+		},
+	}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	res, err := adapter.RunONUTest(context.Background(), "0/1/0", 5, types.ONUTest{Kind: types.ONUTestSelfTest})
+	if err != nil {
+		t.Fatalf("RunONUTest() error = %v", err)
+	}
+	if !res.Passed || res.Detail != "pass" {
+		t.Errorf("self-test result = %+v", res)
+	}
+
+	res, err = adapter.RunONUTest(context.Background(), "0/1/0", 5, types.ONUTest{Kind: types.ONUTestLoopback, Port: 2})
+	if err != nil {
+		t.Fatalf("RunONUTest() error = %v", err)
+	}
+	if res.Passed || res.Port != 2 {
+		t.Errorf("loopback result = %+v", res)
+	}
+
+	if _, err := adapter.RunONUTest(context.Background(), "0/1/0", 5, types.ONUTest{Kind: types.ONUTestLoopback, Port: 3}); err == nil {
+		t.Error("expected error for output without a result")
+	}
+}
@@ -3,6 +3,7 @@ package huawei
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/nanoncore/nano-southbound/vendors/common"
 )
@@ -59,6 +60,10 @@ const (
 	OIDCardCPU    = "1.3.6.1.4.1.2011.2.6.7.1.1.2.1.5" // CPU utilization %
 	OIDCardMemory = "1.3.6.1.4.1.2011.2.6.7.1.1.2.1.6" // Memory utilization %
 
+	// hwCpuDevTable (HUAWEI-CPU-MIB): CPU usage % per CPU, indexed by
+	// <frame>.<slot>.<cpu>
+	OIDHwCpuDevDuty = "1.3.6.1.4.1.2011.6.3.4.1.2"
+
 	// Standard MIB-II Interface Counters
 	OIDIfDescr       = "1.3.6.1.2.1.2.2.1.2"     // Interface description
 	OIDIfAdminStatus = "1.3.6.1.2.1.2.2.1.7"     // Admin status (1=up, 2=down, 3=testing)
@@ -69,6 +74,15 @@ const (
 	OIDIfHCOutOctets = "1.3.6.1.2.1.31.1.1.1.10" // 64-bit output bytes
 	OIDIfAlias       = "1.3.6.1.2.1.31.1.1.1.1"  // Interface alias (PON port description)
 
+	// hwAlarmTable: active alarms, indexed by alarm serial number
+	// (HUAWEI-DEVICE-MIB)
+	OIDHwAlarmTable       = "1.3.6.1.4.1.2011.2.6.7.1.1.3.1"
+	OIDHwAlarmID          = "1.3.6.1.4.1.2011.2.6.7.1.1.3.1.2" // Alarm type ID
+	OIDHwAlarmLevel       = "1.3.6.1.4.1.2011.2.6.7.1.1.3.1.3" // 1=critical, 2=major, 3=minor, 4=warning
+	OIDHwAlarmName        = "1.3.6.1.4.1.2011.2.6.7.1.1.3.1.4" // Alarm name, e.g. "ONT LOS"
+	OIDHwAlarmSource      = "1.3.6.1.4.1.2011.2.6.7.1.1.3.1.5" // Source, e.g. "0/1/0:5" for an ONT
+	OIDHwAlarmRaiseTime   = "1.3.6.1.4.1.2011.2.6.7.1.1.3.1.6" // DateAndTime the alarm was raised
+	OIDHwAlarmDescription = "1.3.6.1.4.1.2011.2.6.7.1.1.3.1.7" // Alarm description
 )
 
 // ConvertOpticalPower converts raw SNMP value to dBm
@@ -107,73 +121,49 @@ func ConvertTemperature(rawValue int64) float64 {
 	return float64(rawValue) / 256.0
 }
 
-// IsOnuOnline checks if ONU is online based on Rx power value
-// Huawei returns 2147483647 when ONU is offline
-func IsOnuOnline(rxPowerRaw int64) bool {
-	return rxPowerRaw != common.SNMPInvalidValue
-}
-
-// DecodeHexSerial converts hex serial number to readable format
-// Handles both hex-encoded serials (e.g., "485754430011D168" -> "HWTC0011D168")
-// and already-ASCII serials (e.g., "HWTC00000101" -> "HWTC00000101")
-func DecodeHexSerial(hexSerial string) string {
-	if len(hexSerial) < 8 {
-		return hexSerial
-	}
-
-	// Check if serial is already in ASCII format (starts with letters like "HWTC", "ZTEG", etc.)
-	// ASCII ONU serials typically have 4 letter prefix followed by numbers
-	if isASCIISerial(hexSerial) {
-		return hexSerial
-	}
-
-	// First 8 chars are vendor ID in hex (ASCII)
-	vendorHex := hexSerial[:8]
-	vendorID := ""
-	for i := 0; i < 8; i += 2 {
-		if i+2 <= len(vendorHex) {
-			b := hexToByte(vendorHex[i : i+2])
-			if b >= 32 && b <= 126 { // Printable ASCII
-				vendorID += string(rune(b))
-			}
-		}
+// ConvertAlarmLevel converts an hwAlarmTable level to a normalized severity
+func ConvertAlarmLevel(level int64) string {
+	switch level {
+	case 1:
+		return "critical"
+	case 2:
+		return "major"
+	case 3:
+		return "minor"
+	case 4:
+		return "warning"
+	default:
+		return "unknown"
 	}
-
-	// Rest is the serial number
-	serialPart := hexSerial[8:]
-
-	return vendorID + serialPart
 }
 
-// isASCIISerial checks if the serial is already in ASCII format (not hex-encoded)
-func isASCIISerial(serial string) bool {
-	if len(serial) < 4 {
-		return false
+// ParseDateAndTime parses an SNMPv2-TC DateAndTime octet string: year (2
+// octets), month, day, hour, minutes, seconds, deci-seconds and optionally
+// the UTC offset direction, hours and minutes. Without offset the time is
+// taken as UTC. Returns the zero time if b is not a DateAndTime.
+func ParseDateAndTime(b []byte) time.Time {
+	if len(b) != 8 && len(b) != 11 {
+		return time.Time{}
+	}
+	year := int(b[0])<<8 | int(b[1])
+	if b[2] < 1 || b[2] > 12 || b[3] < 1 || b[3] > 31 {
+		return time.Time{}
 	}
-	// Check if first 4 chars are uppercase letters (typical ONU vendor ID)
-	for i := 0; i < 4; i++ {
-		c := serial[i]
-		if c < 'A' || c > 'Z' {
-			return false
+	loc := time.UTC
+	if len(b) == 11 {
+		offset := (int(b[9])*60 + int(b[10])) * 60
+		if b[8] == '-' {
+			offset = -offset
 		}
+		loc = time.FixedZone("", offset)
 	}
-	return true
+	return time.Date(year, time.Month(b[2]), int(b[3]), int(b[4]), int(b[5]), int(b[6]), int(b[7])*100_000_000, loc)
 }
 
-func hexToByte(hex string) byte {
-	var b byte
-	for _, c := range hex {
-		b <<= 4
-		switch {
-		case c >= '0' && c <= '9':
-			b |= byte(c - '0')
-		case c >= 'A' && c <= 'F':
-			b |= byte(c - 'A' + 10)
-		case c >= 'a' && c <= 'f':
-			b |= byte(c - 'a' + 10)
-		}
-	}
-	return b
+// IsOnuOnline checks if ONU is online based on Rx power value
+// Huawei returns 2147483647 when ONU is offline
+func IsOnuOnline(rxPowerRaw int64) bool {
+	return rxPowerRaw != common.SNMPInvalidValue
 }
 
 // ParseONUIndex extracts frame, slot, port, and ONU ID from SNMP index
@@ -220,4 +210,5 @@ func ParseONUIndex(index string) (frame, slot, port, onuID int, err error) {
 var (
 	GetSNMPResult         = common.GetSNMPResult
 	ParseNumericSNMPValue = common.ParseNumericSNMPValue
+	DecodeHexSerial       = common.DecodeHexSerial
 )
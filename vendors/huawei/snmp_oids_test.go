@@ -1,6 +1,9 @@
 package huawei
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestParseONUIndex(t *testing.T) {
 	tests := []struct {
@@ -181,34 +184,22 @@ func TestParseNumericSNMPValue(t *testing.T) {
 	}
 }
 
-func TestDecodeHexSerial(t *testing.T) {
+func TestParseDateAndTime(t *testing.T) {
 	tests := []struct {
-		name     string
-		input    string
-		expected string
+		name  string
+		input []byte
+		want  time.Time
 	}{
-		{
-			name:     "hex-encoded serial",
-			input:    "485754430011D168",
-			expected: "HWTC0011D168",
-		},
-		{
-			name:     "already ASCII serial",
-			input:    "HWTC00000101",
-			expected: "HWTC00000101",
-		},
-		{
-			name:     "short serial",
-			input:    "HWTC",
-			expected: "HWTC",
-		},
+		{name: "utc", input: []byte{0x07, 0xE8, 1, 15, 10, 30, 0, 5}, want: time.Date(2024, 1, 15, 10, 30, 0, 500_000_000, time.UTC)},
+		{name: "offset", input: []byte{0x07, 0xE8, 1, 15, 10, 30, 0, 0, '+', 8, 0}, want: time.Date(2024, 1, 15, 2, 30, 0, 0, time.UTC)},
+		{name: "bad month", input: []byte{0x07, 0xE8, 13, 15, 10, 30, 0, 0}},
+		{name: "text", input: []byte("2024-01-15")},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := DecodeHexSerial(tt.input)
-			if result != tt.expected {
-				t.Errorf("DecodeHexSerial(%q) = %q, want %q", tt.input, result, tt.expected)
+			if got := ParseDateAndTime(tt.input); !got.Equal(tt.want) {
+				t.Errorf("ParseDateAndTime(%v) = %v, want %v", tt.input, got, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,49 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/nanoncore/nano-southbound/model"
+)
+
+// tr069IPIndex is the ONT IP host of the TR-069 management WAN; the WAN
+// uses wanIPIndex and voice voiceIPIndex
+const tr069IPIndex = 2
+
+// ensureTR069Profile returns the TR-069 server profile of t's ACS, creating
+// it if the OLT reports it does not exist. Profiles are named after a hash
+// of the ACS URL and credentials, so ONTs of the same ACS share one, and
+// are not removed with the subscriber.
+func (a *Adapter) ensureTR069Profile(ctx context.Context, t *model.TR069Service) (string, error) {
+	h := fnv.New32a()
+	h.Write([]byte(t.ACSURL + "\x00" + t.Username + "\x00" + t.Password))
+	name := fmt.Sprintf("nano-acs-%08x", h.Sum32())
+
+	output, err := a.execProfileDisplay(ctx, fmt.Sprintf("display ont tr069-server-profile profile-name %s", name))
+	if err != nil {
+		return "", fmt.Errorf("failed to get TR-069 server profile: %w", err)
+	}
+	if !profileMissing(output) {
+		return name, nil
+	}
+	add := fmt.Sprintf("ont tr069-server-profile add profile-name %s url %s", name, t.ACSURL)
+	if t.Username != "" {
+		add += fmt.Sprintf(" user-name %s password %s", t.Username, t.Password)
+	}
+	if err := a.execProfileConfig(ctx, []string{add}); err != nil {
+		return "", fmt.Errorf("failed to create TR-069 server profile %s: %w", name, err)
+	}
+	return name, nil
+}
+
+// tr069Commands returns the interface gpon commands putting an ONT's
+// management WAN on its own IP host in the TR-069 VLAN and binding it to
+// the server profile
+func tr069Commands(port, onuID int, t *model.TR069Service, profile string) []string {
+	return []string{
+		fmt.Sprintf("ont ipconfig %d %d ip-index %d dhcp vlan %d priority %d", port, onuID, tr069IPIndex, t.VLAN, t.GetPriority()),
+		fmt.Sprintf("ont tr069-server-config %d %d profile-name %s", port, onuID, profile),
+	}
+}
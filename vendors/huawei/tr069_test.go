@@ -0,0 +1,66 @@
+package huawei
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestCreateSubscriberWithTR069(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"))
+
+	sub := &model.Subscriber{
+		Name:        "test-sub",
+		Annotations: map[string]string{"nanoncore.com/gpon-fsp": "0/1/0", "nanoncore.com/ont-id": "5"},
+		Spec: model.SubscriberSpec{ONUSerial: "HWTC00001234", VLAN: 100, TR069: &model.TR069Service{
+			VLAN: 46, ACSURL: "http://acs.example.net:7547", Username: "cpe", Password: "secret",
+		}},
+	}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	if !slices.Contains(mock.Commands, "ont ipconfig 0 5 ip-index 2 dhcp vlan 46 priority 5") {
+		t.Errorf("management WAN not provisioned: %v", mock.Commands)
+	}
+	i := slices.IndexFunc(mock.Commands, func(c string) bool { return strings.HasPrefix(c, "ont tr069-server-config 0 5 profile-name nano-acs-") })
+	if i < 0 {
+		t.Fatalf("TR-069 profile not bound: %v", mock.Commands)
+	}
+	// The existing profile is reused, not created again
+	if slices.ContainsFunc(mock.Commands, func(c string) bool { return strings.HasPrefix(c, "ont tr069-server-profile add") }) {
+		t.Errorf("unexpected profile create: %v", mock.Commands)
+	}
+
+	sub.Spec.TR069.ACSURL = ""
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err == nil {
+		t.Error("TR-069 without ACS URL should be rejected")
+	}
+}
+
+func TestEnsureTR069Profile(t *testing.T) {
+	svc := &model.TR069Service{VLAN: 46, ACSURL: "http://acs.example.net:7547", Username: "cpe", Password: "secret"}
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+	name, err := adapter.ensureTR069Profile(context.Background(), svc)
+	if err != nil {
+		t.Fatalf("ensureTR069Profile() error = %v", err)
+	}
+
+	mock = &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display ont tr069-server-profile profile-name " + name: "  Failure: The profile does not exist",
+	}}
+	adapter = NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+	if got, err := adapter.ensureTR069Profile(context.Background(), svc); err != nil || got != name {
+		t.Fatalf("ensureTR069Profile() = %q, %v, want %q", got, err, name)
+	}
+	want := "ont tr069-server-profile add profile-name " + name + " url http://acs.example.net:7547 user-name cpe password secret"
+	if !slices.Contains(mock.Commands, want) {
+		t.Errorf("missing command %q in %v", want, mock.Commands)
+	}
+}
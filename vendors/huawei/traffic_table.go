@@ -0,0 +1,172 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
+)
+
+// Huawei traffic profiles are IP traffic tables ("traffic table ip"), the
+// CAR bound to an ONT with "ont traffic-policy". SIR is the table CIR.
+
+var _ types.TrafficProfileManager = (*Adapter)(nil)
+
+var (
+	reHWTrafficIndex = regexp.MustCompile(`(?m)^\s*TD Index\s*:\s*(\d+)`)
+	reHWTrafficName  = regexp.MustCompile(`(?m)^\s*TD Name\s*:\s*(\S+)`)
+	reHWTrafficCIR   = regexp.MustCompile(`(?m)^\s*CIR\s*:\s*(\d+)`)
+	reHWTrafficPIR   = regexp.MustCompile(`(?m)^\s*PIR\s*:\s*(\d+)`)
+	reHWTrafficRow   = regexp.MustCompile(`(?m)^[ \t]*(\d+)[ \t]+(\d+)[ \t]+\d+[ \t]+(\d+)[ \t]+\d+`)
+)
+
+// ListTrafficProfiles lists the IP traffic tables on the OLT. The table
+// listing has no names; GetTrafficProfile returns the name of one table.
+func (a *Adapter) ListTrafficProfiles(ctx context.Context) ([]types.TrafficProfile, error) {
+	output, err := a.execProfileDisplay(ctx, "display traffic table ip from-index 0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list traffic tables: %w", err)
+	}
+	tables := []types.TrafficProfile{}
+	for _, m := range reHWTrafficRow.FindAllStringSubmatch(output, -1) {
+		id, _ := strconv.Atoi(m[1])
+		cir, _ := strconv.Atoi(m[2])
+		pir, _ := strconv.Atoi(m[3])
+		tables = append(tables, types.TrafficProfile{ID: id, SIR: cir, PIR: pir})
+	}
+	return tables, nil
+}
+
+// GetTrafficProfile retrieves an IP traffic table by name.
+func (a *Adapter) GetTrafficProfile(ctx context.Context, name string) (*types.TrafficProfile, error) {
+	if err := validateProfileName(name); err != nil {
+		return nil, err
+	}
+	output, err := a.execProfileDisplay(ctx, fmt.Sprintf("display traffic table ip name %s", name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get traffic table: %w", err)
+	}
+	table, ok := parseTrafficTable(output)
+	if !ok {
+		return nil, fmt.Errorf("traffic table %q not found", name)
+	}
+	return table, nil
+}
+
+// CreateTrafficProfile creates an IP traffic table. The OLT assigns the
+// index unless ID is set; a SIR of 0 commits the whole PIR.
+func (a *Adapter) CreateTrafficProfile(ctx context.Context, profile types.TrafficProfile) error {
+	if err := validateProfileName(profile.Name); err != nil {
+		return err
+	}
+	if profile.PIR <= 0 {
+		return fmt.Errorf("PIR must be greater than 0")
+	}
+	if profile.SIR < 0 || profile.SIR > profile.PIR {
+		return fmt.Errorf("SIR must be between 0 and PIR")
+	}
+	if err := a.execProfileConfig(ctx, []string{buildTrafficTableCommand(profile)}); err != nil {
+		return fmt.Errorf("failed to create traffic table %s: %w", profile.Name, err)
+	}
+	return nil
+}
+
+// DeleteTrafficProfile deletes an IP traffic table by name. The OLT refuses
+// to delete a table still bound to ONTs or service ports.
+func (a *Adapter) DeleteTrafficProfile(ctx context.Context, name string) error {
+	table, err := a.GetTrafficProfile(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := a.execProfileConfig(ctx, []string{fmt.Sprintf("undo traffic table ip index %d", table.ID)}); err != nil {
+		return fmt.Errorf("failed to delete traffic table %s: %w", name, err)
+	}
+	return nil
+}
+
+// resolveTrafficTable returns tier with the nanoncore.com/traffic-table-id
+// annotation set to the traffic table of its bandwidth, creating the table
+// if the OLT reports it does not exist. A tier that already sets the
+// annotation or has no bandwidth is returned as is, and so is a tier whose
+// table the OLT display does not show, leaving the bandwidth-as-ID
+// fallback of getTrafficTableID.
+func (a *Adapter) resolveTrafficTable(ctx context.Context, tier *model.ServiceTier) (*model.ServiceTier, error) {
+	if tier == nil {
+		return nil, nil
+	}
+	if _, ok := common.GetAnnotationInt(tier.Annotations, "nanoncore.com/traffic-table-id"); ok {
+		return tier, nil
+	}
+	mbps := tier.Spec.BandwidthDown
+	if mbps == 0 {
+		mbps = tier.Spec.BandwidthUp
+	}
+	if mbps <= 0 {
+		return tier, nil
+	}
+
+	kbps := mbps * 1000
+	name := fmt.Sprintf("nano_car_%d", kbps)
+	output, err := a.execProfileDisplay(ctx, fmt.Sprintf("display traffic table ip name %s", name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get traffic table: %w", err)
+	}
+	table, ok := parseTrafficTable(output)
+	if !ok && profileMissing(output) {
+		if err := a.CreateTrafficProfile(ctx, types.TrafficProfile{Name: name, SIR: kbps, PIR: kbps}); err != nil {
+			return nil, err
+		}
+		if table, err = a.GetTrafficProfile(ctx, name); err != nil {
+			return nil, err
+		}
+		ok = true
+	}
+	if !ok {
+		return tier, nil
+	}
+
+	resolved := *tier
+	resolved.Annotations = make(map[string]string, len(tier.Annotations)+1)
+	for k, v := range tier.Annotations {
+		resolved.Annotations[k] = v
+	}
+	resolved.Annotations["nanoncore.com/traffic-table-id"] = strconv.Itoa(table.ID)
+	return &resolved, nil
+}
+
+func buildTrafficTableCommand(profile types.TrafficProfile) string {
+	cir := profile.SIR
+	if cir == 0 {
+		cir = profile.PIR
+	}
+	if profile.ID > 0 {
+		return fmt.Sprintf("traffic table ip index %d name %s cir %d pir %d priority 0 priority-policy local-setting",
+			profile.ID, profile.Name, cir, profile.PIR)
+	}
+	return fmt.Sprintf("traffic table ip name %s cir %d pir %d priority 0 priority-policy local-setting",
+		profile.Name, cir, profile.PIR)
+}
+
+// parseTrafficTable parses the display of one IP traffic table
+func parseTrafficTable(output string) (*types.TrafficProfile, bool) {
+	m := reHWTrafficIndex.FindStringSubmatch(output)
+	if m == nil {
+		return nil, false
+	}
+	table := &types.TrafficProfile{}
+	table.ID, _ = strconv.Atoi(m[1])
+	if m := reHWTrafficName.FindStringSubmatch(output); m != nil {
+		table.Name = m[1]
+	}
+	if m := reHWTrafficCIR.FindStringSubmatch(output); m != nil {
+		table.SIR, _ = strconv.Atoi(m[1])
+	}
+	if m := reHWTrafficPIR.FindStringSubmatch(output); m != nil {
+		table.PIR, _ = strconv.Atoi(m[1])
+	}
+	return table, true
+}
@@ -0,0 +1,109 @@
+package huawei
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+const trafficTableDisplay = `  TD Index            : 12
+  TD Name             : nano_car_100000
+  Priority            : 0
+  Mapping Index       : -
+  CTAG Mapping Priority: -
+  CIR                 : 100000 kbps
+  CBS                 : 3202000 bytes
+  PIR                 : 100000 kbps
+  PBS                 : 6404000 bytes
+  Color Mode          : color-blind
+`
+
+func TestGetTrafficProfile(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display traffic table ip name nano_car_100000": trafficTableDisplay,
+		"display traffic table ip name missing":         "  Failure: The traffic table does not exist",
+	}}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	table, err := adapter.GetTrafficProfile(context.Background(), "nano_car_100000")
+	if err != nil {
+		t.Fatalf("GetTrafficProfile() error = %v", err)
+	}
+	want := types.TrafficProfile{ID: 12, Name: "nano_car_100000", SIR: 100000, PIR: 100000}
+	if *table != want {
+		t.Errorf("GetTrafficProfile() = %+v, want %+v", *table, want)
+	}
+	if _, err := adapter.GetTrafficProfile(context.Background(), "missing"); err == nil {
+		t.Error("expected error for a missing traffic table")
+	}
+}
+
+func TestListTrafficProfiles(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"display traffic table ip from-index 0": `  --------------------------------------------------------------------------
+  TID   CIR     CBS      PIR     PBS      Pri Copy-policy   Pri-Policy
+        (kbps)  (bytes)  (kbps)  (bytes)
+  --------------------------------------------------------------------------
+    0    1024    34768    2048    69536    6  -             tag-pri
+   12  100000  3202000  100000  6404000    0  -             local-pri
+  --------------------------------------------------------------------------
+  Total Num : 2
+`,
+	}}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	tables, err := adapter.ListTrafficProfiles(context.Background())
+	if err != nil {
+		t.Fatalf("ListTrafficProfiles() error = %v", err)
+	}
+	want := []types.TrafficProfile{{ID: 0, SIR: 1024, PIR: 2048}, {ID: 12, SIR: 100000, PIR: 100000}}
+	if !slices.Equal(tables, want) {
+		t.Errorf("ListTrafficProfiles() = %+v, want %+v", tables, want)
+	}
+}
+
+func TestCreateTrafficProfile(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	if err := adapter.CreateTrafficProfile(context.Background(), types.TrafficProfile{ID: 20, Name: "biz", SIR: 50000, PIR: 100000}); err != nil {
+		t.Fatalf("CreateTrafficProfile() error = %v", err)
+	}
+	want := "traffic table ip index 20 name biz cir 50000 pir 100000 priority 0 priority-policy local-setting"
+	if !slices.Contains(mock.Commands, want) {
+		t.Errorf("missing command %q in %v", want, mock.Commands)
+	}
+	if err := adapter.CreateTrafficProfile(context.Background(), types.TrafficProfile{Name: "biz"}); err == nil {
+		t.Error("expected error without PIR")
+	}
+}
+
+func TestCreateSubscriberCreatesTrafficTable(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{SequentialOutputs: map[string][]string{
+		"display traffic table ip name nano_car_100000": {"  Failure: The traffic table does not exist", trafficTableDisplay},
+	}}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	sub := testutil.NewTestSubscriber("HWTC00001234", "0/1/0", 100)
+	sub.Annotations["nanoncore.com/gpon-fsp"] = "0/1/0"
+	sub.Annotations["nanoncore.com/ont-id"] = "5"
+	tier := &model.ServiceTier{Spec: model.ServiceTierSpec{BandwidthDown: 100, BandwidthUp: 50}}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, tier); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	for _, want := range []string{
+		"traffic table ip name nano_car_100000 cir 100000 pir 100000 priority 0 priority-policy local-setting",
+		"ont traffic-policy 0 5 profile-id 12",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+	if tier.Annotations != nil {
+		t.Error("tier annotations should not be modified")
+	}
+}
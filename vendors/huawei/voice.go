@@ -0,0 +1,56 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.ONUVoiceManager = (*Adapter)(nil)
+
+// voiceIPIndex is the ONT IP host of the SIP agent; the WAN uses
+// wanIPIndex
+const voiceIPIndex = 1
+
+// SetONUVoice configures the SIP agent of an ONT on its own IP host in the
+// voice VLAN, the SIP user of each POTS port and the port states. The ONT's
+// service profile must define its POTS ports.
+func (a *Adapter) SetONUVoice(ctx context.Context, ponPort string, onuID int, voice *model.VoiceService) error {
+	if voice == nil {
+		return fmt.Errorf("voice service is required")
+	}
+	if err := voice.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	err := a.execONTCommands(ctx, ponPort, onuID, func(port int) []string {
+		return voiceCommands(port, onuID, voice)
+	})
+	if err != nil {
+		return fmt.Errorf("huawei ONT voice configuration failed: %w", err)
+	}
+	return nil
+}
+
+// voiceCommands returns the interface gpon commands applying voice
+func voiceCommands(port, onuID int, voice *model.VoiceService) []string {
+	commands := []string{
+		fmt.Sprintf("ont ipconfig %d %d ip-index %d dhcp vlan %d priority 5", port, onuID, voiceIPIndex, voice.VLAN),
+		fmt.Sprintf("ont sipagent-config %d %d ip-index %d proxy-server %s port %d",
+			port, onuID, voiceIPIndex, voice.Server, voice.GetServerPort()),
+	}
+	for _, l := range voice.Lines {
+		user := fmt.Sprintf("ont sipuser-config %d %d %d telno %s username %s", port, onuID, l.Port, l.User, l.GetAuthUser())
+		if l.Password != "" {
+			user += " password " + l.Password
+		}
+		state := "off"
+		if l.IsEnabled() {
+			state = "on"
+		}
+		commands = append(commands, user,
+			fmt.Sprintf("ont port attribute %d %d pots %d operational-state %s", port, onuID, l.Port, state))
+	}
+	return commands
+}
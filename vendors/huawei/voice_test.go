@@ -0,0 +1,62 @@
+package huawei
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestSetONUVoice(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	disabled := false
+	voice := &model.VoiceService{VLAN: 200, Server: "10.20.0.5", ServerPort: 5080, Lines: []model.VoiceLine{
+		{Port: 1, User: "5550101", AuthUser: "auth1", Password: "secret"},
+		{Port: 2, User: "5550102", Enabled: &disabled},
+	}}
+	if err := adapter.SetONUVoice(context.Background(), "0/1/0", 5, voice); err != nil {
+		t.Fatalf("SetONUVoice() error = %v", err)
+	}
+	for _, want := range []string{
+		"interface gpon 0/1",
+		"ont ipconfig 0 5 ip-index 1 dhcp vlan 200 priority 5",
+		"ont sipagent-config 0 5 ip-index 1 proxy-server 10.20.0.5 port 5080",
+		"ont sipuser-config 0 5 1 telno 5550101 username auth1 password secret",
+		"ont port attribute 0 5 pots 1 operational-state on",
+		"ont sipuser-config 0 5 2 telno 5550102 username 5550102",
+		"ont port attribute 0 5 pots 2 operational-state off",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+}
+
+func TestCreateSubscriberWithVoice(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"))
+
+	sub := &model.Subscriber{
+		Name:        "test-sub",
+		Annotations: map[string]string{"nanoncore.com/gpon-fsp": "0/1/0", "nanoncore.com/ont-id": "5"},
+		Spec: model.SubscriberSpec{ONUSerial: "HWTC00001234", VLAN: 100, Voice: &model.VoiceService{
+			VLAN: 200, Server: "10.20.0.5", Lines: []model.VoiceLine{{Port: 1, User: "5550101"}},
+		}},
+	}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	if !slices.Contains(mock.Commands, "ont sipuser-config 0 5 1 telno 5550101 username 5550101") {
+		t.Errorf("voice not provisioned: %v", mock.Commands)
+	}
+
+	sub.Spec.Voice.Lines = nil
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err == nil {
+		t.Error("invalid voice service should be rejected")
+	}
+}
@@ -0,0 +1,204 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var (
+	_ types.ONUWANManager   = (*Adapter)(nil)
+	_ types.ONULANManager   = (*Adapter)(nil)
+	_ types.ONUModeSwitcher = (*Adapter)(nil)
+)
+
+var (
+	// Fields of display ont ipconfig
+	reONTIPConfigType = regexp.MustCompile(`(?mi)^\s*(?:IP )?config(?:uration)? type\s*:\s*(\S+)`)
+	reONTIPVLAN       = regexp.MustCompile(`(?mi)^\s*(?:Manage )?VLAN\s*:\s*(\d+)`)
+	reONTIPPriority   = regexp.MustCompile(`(?mi)^\s*(?:Manage )?priority\s*:\s*(\d+)`)
+	reONTIPUsername   = regexp.MustCompile(`(?mi)^\s*(?:PPPoE )?user\s*(?:name|account)\s*:\s*(\S+)`)
+)
+
+// wanIPIndex is the ONT IP host the WAN connection is configured on
+const wanIPIndex = 0
+
+// SetONUWANConfig configures the routed WAN of an ONT over OMCI: the IP
+// host (PPPoE or DHCP) with ont ipconfig, the WAN with ont internet-config
+// and ont wan-config, the LAN ports routed to it, and the SSID and WPA2 key
+// of the first radio. The ONT's service profile must allow OMCI management
+// of these ports.
+func (a *Adapter) SetONUWANConfig(ctx context.Context, ponPort string, onuID int, cfg *types.ONUWANConfig) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	if err := cfg.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	err := a.execONTCommands(ctx, ponPort, onuID, func(port int) []string {
+		return wanConfigCommands(port, onuID, cfg)
+	})
+	if err != nil {
+		return fmt.Errorf("huawei ONT WAN configuration failed: %w", err)
+	}
+	return nil
+}
+
+// wanConfigCommands returns the interface gpon commands applying cfg
+func wanConfigCommands(port, onuID int, cfg *types.ONUWANConfig) []string {
+	ipconfig := fmt.Sprintf("ont ipconfig %d %d ip-index %d dhcp vlan %d priority %d",
+		port, onuID, wanIPIndex, cfg.VLAN, cfg.Priority)
+	if cfg.Mode == types.WANModePPPoE {
+		ipconfig = fmt.Sprintf("ont ipconfig %d %d ip-index %d pppoe vlan %d priority %d user-account username %s password %s",
+			port, onuID, wanIPIndex, cfg.VLAN, cfg.Priority, cfg.Username, cfg.Password)
+	}
+	commands := []string{
+		ipconfig,
+		fmt.Sprintf("ont internet-config %d %d ip-index %d", port, onuID, wanIPIndex),
+		fmt.Sprintf("ont wan-config %d %d ip-index %d profile-id 0", port, onuID, wanIPIndex),
+	}
+	for _, lan := range cfg.LANPorts {
+		commands = append(commands, fmt.Sprintf("ont port route %d %d eth %d enable", port, onuID, lan))
+	}
+	if w := cfg.WiFi; w != nil {
+		if w.Enabled {
+			commands = append(commands, fmt.Sprintf(
+				`ont wlan-config %d %d ssid-index 1 ssid-name "%s" auth-mode wpa2-psk encrypt-type aes preshare-key "%s" admin-state enable`,
+				port, onuID, w.SSID, w.Password))
+		} else {
+			commands = append(commands, fmt.Sprintf("ont wlan-config %d %d ssid-index 1 admin-state disable", port, onuID))
+		}
+	}
+	return commands
+}
+
+// SetONULANConfig sets the LAN address and DHCP server of an ONT over OMCI.
+func (a *Adapter) SetONULANConfig(ctx context.Context, ponPort string, onuID int, cfg *types.ONULANConfig) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	if err := cfg.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	err := a.execONTCommands(ctx, ponPort, onuID, func(port int) []string {
+		return lanConfigCommands(port, onuID, cfg)
+	})
+	if err != nil {
+		return fmt.Errorf("huawei ONT LAN configuration failed: %w", err)
+	}
+	return nil
+}
+
+// lanConfigCommands returns the interface gpon commands applying cfg
+func lanConfigCommands(port, onuID int, cfg *types.ONULANConfig) []string {
+	dhcp := fmt.Sprintf("ont lan-dhcp-config %d %d dhcp-server disable", port, onuID)
+	if cfg.DHCPServer {
+		dhcp = fmt.Sprintf("ont lan-dhcp-config %d %d dhcp-server enable start-ip %s end-ip %s lease-time %d",
+			port, onuID, cfg.DHCPStart, cfg.DHCPEnd, int(cfg.Lease().Minutes()))
+	}
+	return []string{
+		fmt.Sprintf("ont lan-ip-config %d %d ip-address %s mask %s", port, onuID, cfg.IPAddress, cfg.Netmask),
+		dhcp,
+	}
+}
+
+// SetONUMode switches an ONT between bridge and router mode over OMCI. A
+// router gets the WAN IP host, the routed LAN ports and, with cfg.LAN, its
+// LAN address; a bridge loses the WAN IP host, and its Ethernet ports stop
+// routing and untag the service VLAN.
+func (a *Adapter) SetONUMode(ctx context.Context, ponPort string, onuID int, cfg *types.ONUModeConfig) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	if err := cfg.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "huawei"}
+	}
+	err := a.execONTCommands(ctx, ponPort, onuID, func(port int) []string {
+		if cfg.Mode == types.ONUModeRouter {
+			commands := wanConfigCommands(port, onuID, cfg.WAN)
+			if cfg.LAN != nil {
+				commands = append(commands, lanConfigCommands(port, onuID, cfg.LAN)...)
+			}
+			return commands
+		}
+		commands := []string{fmt.Sprintf("undo ont ipconfig %d %d ip-index %d", port, onuID, wanIPIndex)}
+		for _, eth := range cfg.BridgePorts() {
+			commands = append(commands,
+				fmt.Sprintf("ont port route %d %d eth %d disable", port, onuID, eth),
+				fmt.Sprintf("ont port native-vlan %d %d eth %d vlan %d priority 0", port, onuID, eth, cfg.VLAN))
+		}
+		return commands
+	})
+	if err != nil {
+		return fmt.Errorf("huawei ONT mode change failed: %w", err)
+	}
+	return nil
+}
+
+// GetONUWANConfig reads the WAN IP host of an ONT from display ont
+// ipconfig
+func (a *Adapter) GetONUWANConfig(ctx context.Context, ponPort string, onuID int) (*types.ONUWANConfig, error) {
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available")
+	}
+	frame, slot, port, err := parsePONPort(ponPort)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := a.cliExecutor.ExecCommands(ctx, []string{
+		"enable",
+		"config",
+		fmt.Sprintf("interface gpon %d/%d", frame, slot),
+		fmt.Sprintf("display ont ipconfig %d %d", port, onuID),
+		"quit",
+		"quit",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("huawei display ont ipconfig failed: %w", err)
+	}
+	if len(outputs) < 4 {
+		return nil, fmt.Errorf("huawei display ont ipconfig failed: missing command output")
+	}
+	cfg := parseONTIPConfig(outputs[3])
+	if cfg == nil {
+		return nil, &types.HumanError{
+			Code:    types.ErrCodeONUNotFound,
+			Message: fmt.Sprintf("no WAN configured on ONT %d on port %s", onuID, ponPort),
+			Vendor:  "huawei",
+			Raw:     outputs[3],
+		}
+	}
+	return cfg, nil
+}
+
+// parseONTIPConfig parses display ont ipconfig output, returning nil if
+// the ONT has no PPPoE or DHCP IP host
+func parseONTIPConfig(output string) *types.ONUWANConfig {
+	m := reONTIPConfigType.FindStringSubmatch(output)
+	if m == nil {
+		return nil
+	}
+	cfg := &types.ONUWANConfig{}
+	switch strings.ToLower(m[1]) {
+	case "pppoe":
+		cfg.Mode = types.WANModePPPoE
+	case "dhcp":
+		cfg.Mode = types.WANModeDHCP
+	default:
+		return nil
+	}
+	if m := reONTIPVLAN.FindStringSubmatch(output); m != nil {
+		cfg.VLAN, _ = strconv.Atoi(m[1])
+	}
+	if m := reONTIPPriority.FindStringSubmatch(output); m != nil {
+		cfg.Priority, _ = strconv.Atoi(m[1])
+	}
+	if m := reONTIPUsername.FindStringSubmatch(output); m != nil && m[1] != "-" {
+		cfg.Username = m[1]
+	}
+	return cfg
+}
@@ -0,0 +1,189 @@
+package huawei
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestSetONUWANConfig(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	cfg := &types.ONUWANConfig{
+		Mode:     types.WANModePPPoE,
+		VLAN:     100,
+		Username: "user@isp",
+		Password: "secret",
+		LANPorts: []int{1, 2},
+		WiFi:     &types.WifiConfig{SSID: "Home Net", Password: "password1", Enabled: true},
+	}
+	if err := adapter.SetONUWANConfig(context.Background(), "0/1/0", 5, cfg); err != nil {
+		t.Fatalf("SetONUWANConfig() error = %v", err)
+	}
+	for _, want := range []string{
+		"interface gpon 0/1",
+		"ont ipconfig 0 5 ip-index 0 pppoe vlan 100 priority 0 user-account username user@isp password secret",
+		"ont internet-config 0 5 ip-index 0",
+		"ont wan-config 0 5 ip-index 0 profile-id 0",
+		"ont port route 0 5 eth 2 enable",
+		`ont wlan-config 0 5 ssid-index 1 ssid-name "Home Net" auth-mode wpa2-psk encrypt-type aes preshare-key "password1" admin-state enable`,
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	var herr *types.HumanError
+	err := adapter.SetONUWANConfig(context.Background(), "0/1/0", 5, &types.ONUWANConfig{Mode: types.WANModePPPoE, VLAN: 100})
+	if !errors.As(err, &herr) || herr.Code != types.ErrCodeValidationFailed {
+		t.Errorf("missing credentials error = %v", err)
+	}
+	if err := adapter.SetONUWANConfig(context.Background(), "0/1", 5, &types.ONUWANConfig{Mode: types.WANModeDHCP, VLAN: 100}); err == nil {
+		t.Error("expected error for invalid PON port")
+	}
+}
+
+func TestCreateSubscriberWithWAN(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1"))
+
+	sub := &model.Subscriber{
+		Name:        "test-sub",
+		Annotations: map[string]string{"nanoncore.com/gpon-fsp": "0/1/0", "nanoncore.com/ont-id": "5"},
+		Spec: model.SubscriberSpec{
+			ONUSerial: "HWTC00001234", VLAN: 100, Username: "user@isp", Password: "secret",
+			WAN: &model.WANService{LANPorts: []int{1}},
+		},
+	}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	for _, want := range []string{
+		"ont ipconfig 0 5 ip-index 0 pppoe vlan 100 priority 0 user-account username user@isp password secret",
+		"ont internet-config 0 5 ip-index 0",
+		"ont port route 0 5 eth 1 enable",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	sub.Spec.Password = ""
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err == nil {
+		t.Error("PPPoE WAN without a password should be rejected")
+	}
+}
+
+func TestSetONUWANConfigRejected(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{
+			"ont ipconfig 0 5 ip-index 0 dhcp vlan 100 priority 0": "  Failure: The ONT does not support this operation\n",
+		},
+	}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	err := adapter.SetONUWANConfig(context.Background(), "0/1/0", 5, &types.ONUWANConfig{Mode: types.WANModeDHCP, VLAN: 100})
+	if err == nil {
+		t.Fatal("rejected command did not fail")
+	}
+}
+
+func TestParseONTIPConfig(t *testing.T) {
+	output := `  -----------------------------------------------------------------------------
+  F/S/P                    : 0/1/0
+  ONT-ID                   : 5
+  IP index                 : 0
+  Config type              : PPPoE
+  IP address               : 100.64.12.7
+  Manage VLAN              : 100
+  Manage priority          : 2
+  PPPoE user account       : user@isp
+  -----------------------------------------------------------------------------
+`
+	cfg := parseONTIPConfig(output)
+	if cfg == nil || cfg.Mode != types.WANModePPPoE || cfg.VLAN != 100 || cfg.Priority != 2 || cfg.Username != "user@isp" || cfg.Password != "" {
+		t.Errorf("parseONTIPConfig() = %+v", cfg)
+	}
+	if cfg := parseONTIPConfig("  Failure: The IP host does not exist\n"); cfg != nil {
+		t.Errorf("parseONTIPConfig() = %+v, want nil", cfg)
+	}
+}
+
+func TestSetONULANConfig(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+
+	cfg := &types.ONULANConfig{IPAddress: "192.168.1.1", Netmask: "255.255.255.0", DHCPServer: true,
+		DHCPStart: "192.168.1.100", DHCPEnd: "192.168.1.200"}
+	if err := adapter.SetONULANConfig(context.Background(), "0/1/0", 5, cfg); err != nil {
+		t.Fatalf("SetONULANConfig() error = %v", err)
+	}
+	for _, want := range []string{
+		"ont lan-ip-config 0 5 ip-address 192.168.1.1 mask 255.255.255.0",
+		"ont lan-dhcp-config 0 5 dhcp-server enable start-ip 192.168.1.100 end-ip 192.168.1.200 lease-time 1440",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	cfg.DHCPServer = false
+	if err := adapter.SetONULANConfig(context.Background(), "0/1/0", 5, cfg); err != nil {
+		t.Fatalf("SetONULANConfig() error = %v", err)
+	}
+	if !slices.Contains(mock.Commands, "ont lan-dhcp-config 0 5 dhcp-server disable") {
+		t.Errorf("commands = %v", mock.Commands)
+	}
+}
+
+func TestSetONUMode(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := NewAdapter(&testutil.MockDriver{CLIExec: mock}, testutil.NewTestEquipmentConfig(types.VendorHuawei, "10.0.0.1")).(*Adapter)
+	ctx := context.Background()
+
+	err := adapter.SetONUMode(ctx, "0/1/0", 5, &types.ONUModeConfig{
+		Mode: types.ONUModeRouter,
+		WAN:  &types.ONUWANConfig{Mode: types.WANModePPPoE, VLAN: 100, Username: "user@isp", Password: "secret", LANPorts: []int{1}},
+		LAN:  &types.ONULANConfig{IPAddress: "192.168.1.1", Netmask: "255.255.255.0"},
+	})
+	if err != nil {
+		t.Fatalf("SetONUMode(router) error = %v", err)
+	}
+	for _, want := range []string{
+		"ont ipconfig 0 5 ip-index 0 pppoe vlan 100 priority 0 user-account username user@isp password secret",
+		"ont port route 0 5 eth 1 enable",
+		"ont lan-ip-config 0 5 ip-address 192.168.1.1 mask 255.255.255.0",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	mock.Commands = nil
+	if err := adapter.SetONUMode(ctx, "0/1/0", 5, &types.ONUModeConfig{Mode: types.ONUModeBridge, VLAN: 100, EthPorts: []int{1, 2}}); err != nil {
+		t.Fatalf("SetONUMode(bridge) error = %v", err)
+	}
+	for _, want := range []string{
+		"undo ont ipconfig 0 5 ip-index 0",
+		"ont port route 0 5 eth 2 disable",
+		"ont port native-vlan 0 5 eth 2 vlan 100 priority 0",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	mock.Commands = nil
+	if err := adapter.SetONUMode(ctx, "0/1/0", 5, &types.ONUModeConfig{Mode: types.ONUModeRouter}); err == nil {
+		t.Error("router mode without a WAN should be rejected")
+	}
+	if len(mock.Commands) != 0 {
+		t.Errorf("commands sent for a rejected mode change: %v", mock.Commands)
+	}
+}
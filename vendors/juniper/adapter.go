@@ -15,7 +15,8 @@ type Adapter struct {
 }
 
 // NewAdapter creates a new Juniper adapter
-func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig) types.Driver {
+func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig, opts ...types.Option) types.Driver {
+	config.Apply(opts...)
 	return &Adapter{
 		baseDriver: baseDriver,
 		config:     config,
@@ -34,6 +35,16 @@ func (a *Adapter) IsConnected() bool {
 	return a.baseDriver.IsConnected()
 }
 
+// DriverMetrics implements types.MetricsProvider
+func (a *Adapter) DriverMetrics() types.DriverMetrics {
+	return types.CollectMetrics(a.baseDriver)
+}
+
+// Capabilities implements types.CapabilityReporter
+func (a *Adapter) Capabilities() types.Capabilities {
+	return types.DetectCapabilities(a)
+}
+
 func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (*types.SubscriberResult, error) {
 	// Junos subscriber management:
 	// - Dynamic profiles
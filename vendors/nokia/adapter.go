@@ -3,6 +3,7 @@ package nokia
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -17,13 +18,15 @@ import (
 // Adapter wraps a base driver with Nokia-specific logic
 // Nokia uses NETCONF/YANG for configuration and gNMI for telemetry (SR OS / SR Linux)
 type Adapter struct {
+	netconf.BBFDriver
 	baseDriver      types.Driver
 	netconfExecutor netconf.NETCONFExecutor
 	config          *types.EquipmentConfig
 }
 
 // NewAdapter creates a new Nokia adapter
-func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig) types.Driver {
+func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig, opts ...types.Option) types.Driver {
+	config.Apply(opts...)
 	adapter := &Adapter{
 		baseDriver: baseDriver,
 		config:     config,
@@ -33,6 +36,7 @@ func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig) types.Dr
 	if executor, ok := baseDriver.(netconf.NETCONFExecutor); ok {
 		adapter.netconfExecutor = executor
 	}
+	adapter.BBFDriver = netconf.NewBBFDriver("Nokia", adapter.netconfExecutor)
 
 	return adapter
 }
@@ -52,20 +56,44 @@ func (a *Adapter) IsConnected() bool {
 	return a.baseDriver.IsConnected()
 }
 
+// DriverMetrics implements types.MetricsProvider
+func (a *Adapter) DriverMetrics() types.DriverMetrics {
+	return types.CollectMetrics(a.baseDriver)
+}
+
+// Capabilities implements types.CapabilityReporter. Of DriverV2 only the
+// ONU list, discovery, ONU optical levels and OLT status are supported.
+func (a *Adapter) Capabilities() types.Capabilities {
+	c := types.DetectCapabilities(a)
+	a.ClearUnsupported(&c)
+	return c
+}
+
 // CreateSubscriber provisions a subscriber with Nokia-specific YANG configuration
 func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (*types.SubscriberResult, error) {
 	if a.netconfExecutor == nil {
 		return nil, fmt.Errorf("NETCONF executor not available - Nokia requires NETCONF driver")
 	}
+	if err := subscriber.Spec.ValidateQinQ(); err != nil {
+		return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "nokia"}
+	}
 
 	// Extract subscriber parameters
 	params := a.extractSubscriberParams(subscriber, tier)
 
 	// Build the subscriber configuration XML
-	config := a.buildSubscriberConfig(params)
+	config, err := a.buildSubscriberConfig(params)
+	if err != nil {
+		return nil, netconf.AsHumanError("nokia", err)
+	}
+
+	// Check the generated configuration before touching the datastore
+	if err := a.validateConfig(ctx, config); err != nil {
+		return nil, err
+	}
 
 	// Apply configuration via NETCONF edit-config
-	err := a.netconfExecutor.EditConfig(ctx, "", config,
+	err = a.netconfExecutor.EditConfig(ctx, "", config,
 		netconf.WithMerge(),
 		netconf.WithRollbackOnError(),
 	)
@@ -97,6 +125,23 @@ func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscr
 	return result, nil
 }
 
+// validateConfig runs <validate> on generated configuration when the device
+// supports it. Device-side rejections are returned as *types.HumanError.
+func (a *Adapter) validateConfig(ctx context.Context, config string) error {
+	if !a.netconfExecutor.HasCapability(netconf.CapValidate) {
+		return nil
+	}
+	err := a.netconfExecutor.ValidateConfig(ctx, config)
+	if err == nil {
+		return nil
+	}
+	var verr *netconf.ValidationError
+	if errors.As(err, &verr) {
+		return verr.ToHumanError("nokia")
+	}
+	return fmt.Errorf("Nokia subscriber validation failed: %w", err)
+}
+
 // subscriberParams holds parsed subscriber parameters for Nokia
 type subscriberParams struct {
 	VPRN           string
@@ -151,12 +196,15 @@ func (a *Adapter) extractSubscriberParams(subscriber *model.Subscriber, tier *mo
 		params.GroupInterface = fmt.Sprintf("grp-%d", subscriber.Spec.VLAN)
 	}
 	if params.SapID == "" {
-		// SAP ID format: port:vlan or lag:vlan
+		// SAP ID format: port:vlan or lag:vlan, port:svlan.cvlan for Q-in-Q
 		port := a.config.Metadata["uplink_port"]
 		if port == "" {
 			port = "1/1/1"
 		}
 		params.SapID = fmt.Sprintf("%s:%d", port, subscriber.Spec.VLAN)
+		if svlan := subscriber.Spec.GetSVLAN(); svlan > 0 {
+			params.SapID = fmt.Sprintf("%s:%d.%d", port, svlan, subscriber.Spec.VLAN)
+		}
 	}
 
 	// Get profile names from tier or use defaults
@@ -192,56 +240,38 @@ func (a *Adapter) extractSubscriberParams(subscriber *model.Subscriber, tier *mo
 }
 
 // buildSubscriberConfig builds Nokia YANG XML for subscriber provisioning
-func (a *Adapter) buildSubscriberConfig(params *subscriberParams) string {
-	// Build the static subscriber host configuration
-	return fmt.Sprintf(`
-<configure xmlns="urn:nokia.com:sros:ns:yang:sr:conf">
-  <service>
-    <vprn>
-      <service-name>%s</service-name>
-      <subscriber-interface>
-        <interface-name>%s</interface-name>
-        <admin-state>enable</admin-state>
-        <group-interface>
-          <group-interface-name>%s</group-interface-name>
-          <admin-state>enable</admin-state>
-          <sap>
-            <sap-id>%s</sap-id>
-            <admin-state>enable</admin-state>
-            <sub-sla-mgmt>
-              <sub-ident-policy>%s</sub-ident-policy>
-              <single-sub-parameters>
-                <sub-profile>%s</sub-profile>
-                <sla-profile>%s</sla-profile>
-              </single-sub-parameters>
-            </sub-sla-mgmt>
-            <static-host>
-              <static-host-id>%s</static-host-id>
-              <admin-state>enable</admin-state>
-              <mac>%s</mac>
-              <ip-address>%s</ip-address>
-              <sub-profile>%s</sub-profile>
-              <sla-profile>%s</sla-profile>
-            </static-host>
-          </sap>
-        </group-interface>
-      </subscriber-interface>
-    </vprn>
-  </service>
-</configure>`,
-		params.VPRN,
-		params.SubInterface,
-		params.GroupInterface,
-		params.SapID,
-		params.SubIdentPolicy,
-		params.SubProfile,
-		params.SLAProfile,
-		params.HostID,
-		params.MAC,
-		params.IPv4Address,
-		params.SubProfile,
-		params.SLAProfile,
-	)
+func (a *Adapter) buildSubscriberConfig(params *subscriberParams) (string, error) {
+	// Static subscriber host on a group-interface SAP
+	return netconf.MarshalConfig(&srosConfigure{
+		VPRN: []srosVPRN{{
+			ServiceName: params.VPRN,
+			SubscriberInterface: []srosSubscriberInterface{{
+				InterfaceName: params.SubInterface,
+				AdminState:    "enable",
+				GroupInterface: []srosGroupInterface{{
+					GroupInterfaceName: params.GroupInterface,
+					AdminState:         "enable",
+					SAP: []srosSAP{{
+						SapID:      params.SapID,
+						AdminState: "enable",
+						SubSLAMgmt: &srosSubSLAMgmt{
+							SubIdentPolicy: params.SubIdentPolicy,
+							SubProfile:     params.SubProfile,
+							SLAProfile:     params.SLAProfile,
+						},
+						StaticHost: []srosStaticHost{{
+							StaticHostID: params.HostID,
+							AdminState:   "enable",
+							MAC:          params.MAC,
+							IPAddress:    params.IPv4Address,
+							SubProfile:   params.SubProfile,
+							SLAProfile:   params.SLAProfile,
+						}},
+					}},
+				}},
+			}},
+		}},
+	})
 }
 
 // UpdateSubscriber updates subscriber configuration
@@ -249,10 +279,16 @@ func (a *Adapter) UpdateSubscriber(ctx context.Context, subscriber *model.Subscr
 	if a.netconfExecutor == nil {
 		return fmt.Errorf("NETCONF executor not available")
 	}
+	if err := subscriber.Spec.ValidateQinQ(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "nokia"}
+	}
 
 	// For Nokia, update is same as create with merge operation
 	params := a.extractSubscriberParams(subscriber, tier)
-	config := a.buildSubscriberConfig(params)
+	config, err := a.buildSubscriberConfig(params)
+	if err != nil {
+		return netconf.AsHumanError("nokia", err)
+	}
 
 	return a.netconfExecutor.EditConfig(ctx, "", config,
 		netconf.WithMerge(),
@@ -385,11 +421,19 @@ func (a *Adapter) GetSubscriberStatus(ctx context.Context, subscriberID string)
 		return nil, fmt.Errorf("NETCONF executor not available")
 	}
 
-	// Build filter for subscriber state
+	// Build filter for subscriber state; with XPath only the parsed leaves
+	// are returned instead of the whole subscriber subtree
 	filter := fmt.Sprintf(GetSubscriberFilterXML, subscriberID)
+	xpath := netconf.XPathFilter{
+		Select:     netconf.XPathLeaves(fmt.Sprintf(GetSubscriberXPath, netconf.XPathLiteral(subscriberID)), SubscriberStateLeaves...),
+		Namespaces: map[string]string{"state": NSNokiaState},
+	}
 
 	// Query state via NETCONF get
-	response, err := a.netconfExecutor.Get(ctx, filter)
+	response, err := a.netconfExecutor.Get(ctx, filter,
+		netconf.WithXPath(xpath),
+		netconf.WithDefaults(netconf.WithDefaultsReportAll),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get subscriber status: %w", err)
 	}
@@ -429,7 +473,7 @@ func (a *Adapter) GetSubscriberStats(ctx context.Context, subscriberID string) (
 	filter := fmt.Sprintf(GetSubscriberStatsFilterXML, subscriberID)
 
 	// Query state via NETCONF get
-	response, err := a.netconfExecutor.Get(ctx, filter)
+	response, err := a.netconfExecutor.Get(ctx, filter, netconf.WithDefaults(netconf.WithDefaultsReportAll))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get subscriber stats: %w", err)
 	}
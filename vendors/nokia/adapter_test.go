@@ -2,6 +2,7 @@ package nokia
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -411,6 +412,20 @@ func TestExtractSubscriberParams_Defaults(t *testing.T) {
 	}
 }
 
+func TestExtractSubscriberParams_QinQ(t *testing.T) {
+	config := testutil.NewTestEquipmentConfig(types.VendorNokia, "10.0.0.1")
+	adapter := &Adapter{config: config}
+
+	sub := testutil.NewTestSubscriber("ABCD12345678", "0/1", 200)
+	svlan := 1000
+	sub.Spec.SVLAN = &svlan
+
+	params := adapter.extractSubscriberParams(sub, nil)
+	if params.SapID != "1/1/1:1000.200" {
+		t.Errorf("SapID = %q, want %q", params.SapID, "1/1/1:1000.200")
+	}
+}
+
 func TestExtractSubscriberParams_NilTier(t *testing.T) {
 	config := testutil.NewTestEquipmentConfig(types.VendorNokia, "10.0.0.1")
 	adapter := &Adapter{config: config}
@@ -682,6 +697,56 @@ func TestCreateSubscriber_EditConfigFails(t *testing.T) {
 
 // --- UpdateSubscriber ---
 
+func TestCreateSubscriber_InvalidMAC(t *testing.T) {
+	adapter, _, mockNC := newTestAdapter(t, true)
+
+	sub := testutil.NewTestSubscriber("ALCL00000001", "1/1/1", 100)
+	sub.Spec.MACAddress = "not-a-mac"
+	tier := testutil.NewTestServiceTier(100, 500)
+
+	_, err := adapter.CreateSubscriber(context.Background(), sub, tier)
+	var he *types.HumanError
+	if !errors.As(err, &he) {
+		t.Fatalf("expected *types.HumanError, got %v", err)
+	}
+	if he.Code != types.ErrCodeValidationFailed || he.Vendor != "nokia" {
+		t.Errorf("HumanError = %+v", he)
+	}
+	if len(mockNC.Calls) != 0 {
+		t.Errorf("no NETCONF calls expected for invalid config, got %v", mockNC.Calls)
+	}
+}
+
+func TestBuildSubscriberConfig_Nokia(t *testing.T) {
+	adapter, _, _ := newTestAdapter(t, true)
+
+	params := &subscriberParams{
+		VPRN:           "1000",
+		SubInterface:   "sub-int-1",
+		GroupInterface: "grp-int-1",
+		SapID:          "1/1/1:100",
+		SubIdentPolicy: "default",
+		SubProfile:     "sub-prof",
+		SLAProfile:     "sla-prof",
+		HostID:         "host-1",
+	}
+	config, err := adapter.buildSubscriberConfig(params)
+	if err != nil {
+		t.Fatalf("buildSubscriberConfig failed: %v", err)
+	}
+	if !strings.Contains(config, `<configure xmlns="urn:nokia.com:sros:ns:yang:sr:conf">`) {
+		t.Errorf("missing configure root:\n%s", config)
+	}
+	if strings.Contains(config, "<mac>") || strings.Contains(config, "<ip-address>") {
+		t.Errorf("empty MAC/IP leaves should be omitted:\n%s", config)
+	}
+
+	params.SapID = "1/1/1:4095"
+	if _, err := adapter.buildSubscriberConfig(params); err == nil {
+		t.Error("expected error for out-of-range SAP VLAN")
+	}
+}
+
 func TestUpdateSubscriber_Success(t *testing.T) {
 	adapter, _, mockNC := newTestAdapter(t, true)
 
@@ -1164,3 +1229,49 @@ func TestGetSystemInfo_GetFails(t *testing.T) {
 		t.Fatal("expected error when Get fails")
 	}
 }
+
+func TestBackupConfig_Inline(t *testing.T) {
+	adapter, _, mockNC := newTestAdapter(t, true)
+	mockNC.GetConfigResponses = map[string][]byte{"running|": []byte("<data><configure/></data>")}
+
+	backup, err := adapter.BackupConfig(context.Background(), "")
+	if err != nil {
+		t.Fatalf("BackupConfig failed: %v", err)
+	}
+	if string(backup.Content) != "<data><configure/></data>" || backup.Checksum == "" {
+		t.Errorf("backup = %+v", backup)
+	}
+}
+
+func TestRestoreConfig_NoNETCONF(t *testing.T) {
+	adapter, _, _ := newTestAdapter(t, false)
+
+	err := adapter.RestoreConfig(context.Background(), "ftp://host/config.xml")
+	if err == nil || !strings.Contains(err.Error(), "NETCONF executor not available") {
+		t.Fatalf("expected NETCONF error, got %v", err)
+	}
+}
+
+func TestBulkCreateSubscribers_EditConfigFails(t *testing.T) {
+	adapter, _, mockNC := newTestAdapter(t, true)
+	mockNC.EditConfigError = fmt.Errorf("commit failed")
+	tier := testutil.NewTestServiceTier(100, 500)
+
+	requests := []types.BulkSubscriberRequest{
+		{Subscriber: testutil.NewTestSubscriber("ALCL00000001", "1/1/1", 100), Tier: tier},
+		{Subscriber: testutil.NewTestSubscriber("ALCL00000002", "1/1/1", 101), Tier: tier},
+	}
+
+	result, err := adapter.BulkCreateSubscribers(context.Background(), requests)
+	if err != nil {
+		t.Fatalf("BulkCreateSubscribers failed: %v", err)
+	}
+	if result.Succeeded != 0 || result.Failed != 2 {
+		t.Fatalf("Succeeded=%d Failed=%d, want 0/2", result.Succeeded, result.Failed)
+	}
+	for _, r := range result.Results {
+		if !strings.Contains(r.Error, "commit failed") {
+			t.Errorf("result error = %q", r.Error)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package nokia
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/drivers/netconf"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.BulkSubscriberProvisioner = (*Adapter)(nil)
+
+// BulkCreateSubscribers provisions static subscriber hosts with a single
+// edit-config and commit
+func (a *Adapter) BulkCreateSubscribers(ctx context.Context, requests []types.BulkSubscriberRequest) (*types.BulkResult, error) {
+	if a.netconfExecutor == nil {
+		return nil, fmt.Errorf("NETCONF executor not available - Nokia requires NETCONF driver")
+	}
+
+	build := func(i int, op *types.BulkOpResult) (string, error) {
+		req := requests[i]
+		if req.Subscriber == nil {
+			return "", fmt.Errorf("subscriber is required")
+		}
+		params := a.extractSubscriberParams(req.Subscriber, req.Tier)
+		op.Serial = req.Subscriber.Spec.ONUSerial
+		op.Metadata = map[string]interface{}{
+			"subscriber_id": req.Subscriber.Name,
+			"sap_id":        params.SapID,
+			"host_id":       params.HostID,
+		}
+		return a.buildSubscriberConfig(params)
+	}
+
+	return netconf.ProvisionBulk(ctx, a.netconfExecutor, "nokia", len(requests), build,
+		netconf.WithMerge(),
+		netconf.WithTestThenSet(),
+		netconf.WithRollbackOnError(),
+	), nil
+}
@@ -0,0 +1,140 @@
+package nokia
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/drivers/netconf"
+)
+
+// SR OS model-driven configuration used for subscriber provisioning.
+// Field tags mirror the nokia-conf YANG schema; Validate enforces the
+// constraints the router would otherwise reject at commit.
+
+// srosConfigure is nokia-conf:configure
+type srosConfigure struct {
+	XMLName xml.Name   `xml:"urn:nokia.com:sros:ns:yang:sr:conf configure"`
+	VPRN    []srosVPRN `xml:"service>vprn"`
+}
+
+type srosVPRN struct {
+	ServiceName         string                    `xml:"service-name"`
+	SubscriberInterface []srosSubscriberInterface `xml:"subscriber-interface"`
+}
+
+type srosSubscriberInterface struct {
+	InterfaceName  string               `xml:"interface-name"`
+	AdminState     string               `xml:"admin-state,omitempty"`
+	GroupInterface []srosGroupInterface `xml:"group-interface"`
+}
+
+type srosGroupInterface struct {
+	GroupInterfaceName string    `xml:"group-interface-name"`
+	AdminState         string    `xml:"admin-state,omitempty"`
+	SAP                []srosSAP `xml:"sap"`
+}
+
+type srosSAP struct {
+	SapID      string           `xml:"sap-id"`
+	AdminState string           `xml:"admin-state,omitempty"`
+	SubSLAMgmt *srosSubSLAMgmt  `xml:"sub-sla-mgmt"`
+	StaticHost []srosStaticHost `xml:"static-host"`
+}
+
+type srosSubSLAMgmt struct {
+	SubIdentPolicy string `xml:"sub-ident-policy"`
+	SubProfile     string `xml:"single-sub-parameters>sub-profile"`
+	SLAProfile     string `xml:"single-sub-parameters>sla-profile"`
+}
+
+type srosStaticHost struct {
+	StaticHostID string `xml:"static-host-id"`
+	AdminState   string `xml:"admin-state,omitempty"`
+	MAC          string `xml:"mac,omitempty"`
+	IPAddress    string `xml:"ip-address,omitempty"`
+	SubProfile   string `xml:"sub-profile"`
+	SLAProfile   string `xml:"sla-profile"`
+}
+
+// Validate checks mandatory leaves, SAP encapsulation and host addresses
+func (c *srosConfigure) Validate() error {
+	const vprnPath = "/configure/service/vprn"
+	for _, vprn := range c.VPRN {
+		if err := netconf.RequireLeaf(vprnPath+"/service-name", vprn.ServiceName); err != nil {
+			return err
+		}
+		for _, si := range vprn.SubscriberInterface {
+			siPath := vprnPath + "/subscriber-interface"
+			if err := netconf.RequireLeaf(siPath+"/interface-name", si.InterfaceName); err != nil {
+				return err
+			}
+			for _, gi := range si.GroupInterface {
+				giPath := siPath + "/group-interface"
+				if err := netconf.RequireLeaf(giPath+"/group-interface-name", gi.GroupInterfaceName); err != nil {
+					return err
+				}
+				for _, sap := range gi.SAP {
+					if err := sap.validate(giPath + "/sap"); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *srosSAP) validate(path string) error {
+	if err := netconf.RequireLeaf(path+"/sap-id", s.SapID); err != nil {
+		return err
+	}
+	if err := checkSAPEncap(path+"/sap-id", s.SapID); err != nil {
+		return err
+	}
+	if s.SubSLAMgmt != nil {
+		err := netconf.FirstError(
+			netconf.RequireLeaf(path+"/sub-sla-mgmt/sub-ident-policy", s.SubSLAMgmt.SubIdentPolicy),
+			netconf.RequireLeaf(path+"/sub-sla-mgmt/single-sub-parameters/sub-profile", s.SubSLAMgmt.SubProfile),
+			netconf.RequireLeaf(path+"/sub-sla-mgmt/single-sub-parameters/sla-profile", s.SubSLAMgmt.SLAProfile),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	for _, h := range s.StaticHost {
+		hostPath := path + "/static-host"
+		err := netconf.FirstError(
+			netconf.RequireLeaf(hostPath+"/static-host-id", h.StaticHostID),
+			netconf.CheckMAC(hostPath+"/mac", h.MAC),
+			netconf.CheckIP(hostPath+"/ip-address", h.IPAddress),
+			netconf.RequireLeaf(hostPath+"/sub-profile", h.SubProfile),
+			netconf.RequireLeaf(hostPath+"/sla-profile", h.SLAProfile),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSAPEncap validates the dot1q tag(s) of a "port:tag[.tag]" SAP ID
+func checkSAPEncap(path, sapID string) error {
+	idx := strings.LastIndex(sapID, ":")
+	if idx == -1 {
+		return nil // null encapsulation
+	}
+	for _, tag := range strings.Split(sapID[idx+1:], ".") {
+		if tag == "*" {
+			continue
+		}
+		id, err := strconv.Atoi(tag)
+		if err != nil {
+			return &netconf.SchemaError{Path: path, Reason: "invalid VLAN tag " + strconv.Quote(tag)}
+		}
+		if err := netconf.CheckVLANID(path, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package nokia
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/drivers/netconf"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// DriverV2 monitoring of the Nokia adapter. ONU and PON port state is read
+// from the BBF TR-385 models of Lightspan/ISAM OLTs by the embedded
+// netconf.BBFDriver; an SR OS router has no PON and reports no ONUs or
+// ports. Provisioning stays on Driver: the other DriverV2 operations
+// return an error and are cleared from Capabilities.
+
+var _ types.DriverV2 = (*Adapter)(nil)
+
+// GetOLTStatus returns the system information of the device with its PON
+// ports and ONU counts
+func (a *Adapter) GetOLTStatus(ctx context.Context) (*types.OLTStatus, error) {
+	info, err := a.GetSystemInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system information: %w", err)
+	}
+	status := &types.OLTStatus{
+		OLTID:         a.config.Name,
+		Vendor:        "nokia",
+		Model:         info.Type,
+		Firmware:      info.Version,
+		IsReachable:   true,
+		IsHealthy:     true,
+		UptimeSeconds: info.UptimeSecs,
+		CPUPercent:    info.CPUPercent,
+		LastPoll:      time.Now(),
+		Metadata:      map[string]interface{}{"hostname": info.Name, "platform": a.detectPlatform()},
+	}
+	netconf.NewBBFClient(a.netconfExecutor).AddPONState(ctx, status)
+	return status, nil
+}
@@ -0,0 +1,83 @@
+package nokia
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/drivers/netconf"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+const lightspanONUStates = `<rpc-reply><data><xpon-onu-states xmlns="urn:bbf:yang:bbf-xpon-onu-states">
+  <onu-state>
+    <onu-ref>ont1</onu-ref>
+    <detected-serial-number>ALCL12345678</detected-serial-number>
+    <onu-presence-state>onu-present-and-on-expected-channel-termination</onu-presence-state>
+    <onu-id>1</onu-id>
+    <onu-channel-termination-ref>ct-1/1/1</onu-channel-termination-ref>
+    <onu-distance>2300</onu-distance>
+    <optical-info><rx-power>-21.4</rx-power><tx-power>2.5</tx-power></optical-info>
+  </onu-state>
+</xpon-onu-states></data></rpc-reply>`
+
+const lightspanONUConfig = `<rpc-reply><data><xpon xmlns="urn:bbf:yang:bbf-xpon"><onus>
+  <onu><name>ont1</name><serial-number>ALCL12345678</serial-number><admin-state>unlocked</admin-state></onu>
+</onus></xpon></data></rpc-reply>`
+
+const lightspanInterfaces = `<rpc-reply><data><interfaces-state xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces">
+  <interface><name>ct-1/1/1</name><type>bbf-xponift:channel-termination</type><admin-status>up</admin-status><oper-status>up</oper-status></interface>
+</interfaces-state></data></rpc-reply>`
+
+func TestDriverV2Monitoring(t *testing.T) {
+	adapter, _, mockNC := newTestAdapter(t, true)
+	mockNC.GetResponses = map[string][]byte{
+		netconf.GetAllONUStatesFilterXML:       []byte(lightspanONUStates),
+		netconf.GetAllInterfaceStatesFilterXML: []byte(lightspanInterfaces),
+		GetSystemInfoFilterXML:                 []byte(`<system><information><system-name>olt-1</system-name><chassis-type>FX-8</chassis-type><software-version>23.6</software-version><up-time>3600</up-time></information></system>`),
+	}
+	mockNC.GetConfigResponses = map[string][]byte{"running|" + netconf.GetAllONUsFilterXML: []byte(lightspanONUConfig)}
+	ctx := context.Background()
+
+	onus, err := adapter.GetONUList(ctx, &types.ONUFilter{PONPort: "ct-1/1/1"})
+	if err != nil {
+		t.Fatalf("GetONUList() error = %v", err)
+	}
+	if len(onus) != 1 || onus[0].Serial != "ALCL12345678" || !onus[0].IsOnline || onus[0].AdminState != "enabled" {
+		t.Errorf("GetONUList() = %+v", onus)
+	}
+
+	power, err := adapter.GetONUPower(ctx, "ct-1/1/1", 1)
+	if err != nil {
+		t.Fatalf("GetONUPower() error = %v", err)
+	}
+	if power.RxPowerDBm != -21.4 || power.TxPowerDBm != 2.5 || !power.IsWithinSpec {
+		t.Errorf("GetONUPower() = %+v", power)
+	}
+	if distance, err := adapter.GetONUDistance(ctx, "ct-1/1/1", 1); err != nil || distance != 2300 {
+		t.Errorf("GetONUDistance() = %d, %v", distance, err)
+	}
+
+	status, err := adapter.GetOLTStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetOLTStatus() error = %v", err)
+	}
+	if status.Vendor != "nokia" || status.Model != "FX-8" || status.Firmware != "23.6" || status.UptimeSeconds != 3600 ||
+		status.TotalONUs != 1 || status.ActiveONUs != 1 || len(status.PONPorts) != 1 || status.PONPorts[0].ONUCount != 1 {
+		t.Errorf("GetOLTStatus() = %+v", status)
+	}
+
+	c := adapter.Capabilities()
+	if !c.ONUList || !c.Optical || !c.OLTStatus || c.Alarms || c.VLANs {
+		t.Errorf("Capabilities() = %+v", c)
+	}
+}
+
+func TestDriverV2Monitoring_NoNETCONF(t *testing.T) {
+	adapter, _, _ := newTestAdapter(t, false)
+	if _, err := adapter.GetONUList(context.Background(), nil); err == nil {
+		t.Error("GetONUList() error = nil without NETCONF")
+	}
+	if _, err := adapter.GetOLTStatus(context.Background()); err == nil {
+		t.Error("GetOLTStatus() error = nil without NETCONF")
+	}
+}
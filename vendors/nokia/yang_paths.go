@@ -291,6 +291,17 @@ const GetSubscriberFilterXML = `
   </subscriber-mgmt>
 </state>`
 
+// GetSubscriberXPath locates one subscriber for XPath filtering; %s is an
+// XPath literal for the subscriber ID. The "state" prefix is bound to
+// NSNokiaState.
+const GetSubscriberXPath = "/state:state/state:subscriber-mgmt/state:subscriber[state:subscriber-id=%s]"
+
+// SubscriberStateLeaves are the subscriber leaves read by GetSubscriberStatus
+var SubscriberStateLeaves = []string{
+	"state:admin-state", "state:oper-state", "state:mac-address", "state:ipv4-address",
+	"state:ipv6-address", "state:sub-profile", "state:sla-profile", "state:up-time",
+}
+
 // GetSubscriberStatsFilterXML is the filter for subscriber statistics
 const GetSubscriberStatsFilterXML = `
 <state xmlns="urn:nokia.com:sros:ns:yang:sr:state">
@@ -1,3 +1,6 @@
+// Package vsol adapts the CLI and SNMP drivers to V-SOL V1600 series GPON
+// and EPON OLTs. The GPON ONU management commands, such as the PRI command
+// set for WAN, LAN, voice and TR-069, are not yet verified on hardware.
 package vsol
 
 import (
@@ -9,8 +12,6 @@ import (
 	"sync"
 	"time"
 
-	"log/slog"
-
 	"github.com/nanoncore/nano-southbound/drivers/cli"
 	"github.com/nanoncore/nano-southbound/drivers/snmp"
 	"github.com/nanoncore/nano-southbound/model"
@@ -32,6 +33,7 @@ type Adapter struct {
 	cliExecutor      types.CLIExecutor
 	snmpExecutor     types.SNMPExecutor
 	config           *types.EquipmentConfig
+	metrics          types.DriverCounters // parse failures
 	wifiProfileMu    sync.RWMutex
 	wifiProfileCache map[string]string
 	suspensionMu     sync.RWMutex
@@ -128,7 +130,8 @@ func (a *Adapter) preferCLI() bool {
 
 // NewAdapter creates a new V-SOL adapter
 // If the base driver is CLI, it automatically creates an SNMP driver for monitoring
-func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig) types.Driver {
+func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig, opts ...types.Option) types.Driver {
+	config.Apply(opts...)
 	// V-SOL OLTs have non-compliant SSH; ensure PasswordAuthOnly is set.
 	config.PasswordAuthOnly = true
 
@@ -189,7 +192,7 @@ func (a *Adapter) createSNMPDriver() {
 
 	snmpDriver, err := snmp.NewDriver(&snmpConfig)
 	if err != nil {
-		slog.Warn("V-SOL: failed to create secondary SNMP driver, continuing without SNMP",
+		a.config.Log().Warn("V-SOL: failed to create secondary SNMP driver, continuing without SNMP",
 			"address", a.config.Address, "error", err)
 		return
 	}
@@ -203,8 +206,9 @@ func (a *Adapter) createSNMPDriver() {
 // createCLIDriver creates a CLI driver for operations not available via SNMP
 // V-SOL CPU/Memory metrics are only available via CLI commands
 func (a *Adapter) createCLIDriver() {
-	// Check if CLI credentials are provided in metadata
-	if a.config.Metadata == nil {
+	// Opened on WithCLIFallback, or on any metadata for configs that
+	// predate it
+	if !a.config.CLIFallback && a.config.Metadata == nil {
 		return
 	}
 
@@ -242,7 +246,7 @@ func (a *Adapter) createCLIDriver() {
 
 	cliDriver, err := cli.NewDriver(cliConfig)
 	if err != nil {
-		slog.Warn("V-SOL: failed to create secondary CLI driver, continuing without CLI",
+		a.config.Log().Warn("V-SOL: failed to create secondary CLI driver, continuing without CLI",
 			"address", a.config.Address, "error", err)
 		return
 	}
@@ -259,9 +263,21 @@ func (a *Adapter) Connect(ctx context.Context, config *types.EquipmentConfig) er
 	// injected by callers (e.g. wifi_command_profile/model hints) is visible
 	// to V-SOL command resolution paths.
 	if config != nil {
-		a.config = config
-		if a.config.Metadata == nil {
-			a.config.Metadata = make(map[string]string)
+		if config.Metadata == nil {
+			config.Metadata = make(map[string]string)
+		}
+		if config != a.config {
+			// Keep the settings of construction options the caller's
+			// config does not set
+			refreshed := *config
+			if refreshed.Model == "" {
+				refreshed.Model = a.config.Model
+			}
+			if len(refreshed.PONPorts) == 0 {
+				refreshed.PONPorts = a.config.PONPorts
+			}
+			refreshed.CLIFallback = refreshed.CLIFallback || a.config.CLIFallback
+			a.config = &refreshed
 		}
 	}
 
@@ -283,7 +299,7 @@ func (a *Adapter) Connect(ctx context.Context, config *types.EquipmentConfig) er
 				snmpConfig.Port = 161
 			}
 			if err := a.secondaryDriver.Connect(ctx, &snmpConfig); err != nil {
-				slog.Warn("V-SOL: secondary SNMP driver connect failed, continuing without SNMP",
+				a.config.Log().Warn("V-SOL: secondary SNMP driver connect failed, continuing without SNMP",
 					"address", a.config.Address, "error", err)
 			}
 		} else if a.snmpExecutor != nil && a.cliExecutor != nil {
@@ -305,7 +321,7 @@ func (a *Adapter) Connect(ctx context.Context, config *types.EquipmentConfig) er
 				Timeout:  a.config.Timeout,
 			}
 			if err := a.secondaryDriver.Connect(ctx, cliConfig); err != nil {
-				slog.Warn("V-SOL: secondary CLI driver connect failed, continuing without CLI",
+				a.config.Log().Warn("V-SOL: secondary CLI driver connect failed, continuing without CLI",
 					"address", a.config.Address, "error", err)
 			}
 		}
@@ -317,7 +333,9 @@ func (a *Adapter) Connect(ctx context.Context, config *types.EquipmentConfig) er
 func (a *Adapter) Disconnect(ctx context.Context) error {
 	// Disconnect secondary driver first
 	if a.secondaryDriver != nil {
-		_ = a.secondaryDriver.Disconnect(ctx)
+		if err := a.secondaryDriver.Disconnect(ctx); err != nil {
+			a.config.Log().Warn("V-SOL: secondary driver disconnect failed", "error", err)
+		}
 	}
 	return a.baseDriver.Disconnect(ctx)
 }
@@ -326,8 +344,25 @@ func (a *Adapter) IsConnected() bool {
 	return a.baseDriver.IsConnected()
 }
 
+// DriverMetrics implements types.MetricsProvider, combining the adapter's
+// parse failures with the counters of its protocol drivers
+func (a *Adapter) DriverMetrics() types.DriverMetrics {
+	return a.metrics.Snapshot().Add(types.CollectMetrics(a.baseDriver, a.secondaryDriver))
+}
+
+// Capabilities implements types.CapabilityReporter. Configuration restore
+// is not supported over CLI.
+func (a *Adapter) Capabilities() types.Capabilities {
+	c := types.DetectCapabilities(a)
+	c.ConfigRestore = false
+	return c
+}
+
 // CreateSubscriber provisions an ONU on the V-SOL OLT
-func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (*types.SubscriberResult, error) {
+func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (_ *types.SubscriberResult, err error) {
+	ctx, endAudit := types.BeginAudit(ctx, a.config, "CreateSubscriber", subscriber.Spec.ONUSerial)
+	defer func() { endAudit(err) }()
+
 	if a.cliExecutor == nil {
 		return nil, fmt.Errorf("CLI executor not available - V-SOL requires CLI driver")
 	}
@@ -358,27 +393,119 @@ func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscr
 		assignedID = onuID
 	)
 
-	if a.detectPONType() == "gpon" {
-		// Auto-assign flow needs command output parsing to capture ONU ID.
-		if onuID <= 0 {
-			var err error
-			assignedID, outputs, err = a.provisionGPONWithConfirm(ctx, ponPort, serial, vlan, subscriber)
-			if err != nil {
-				return nil, fmt.Errorf("V-SOL provisioning failed: %w", err)
+	if err := subscriber.Spec.ValidateGEMMapping(); err != nil {
+		return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "vsol"}
+	}
+	if subscriber.Spec.IsQinQ() && a.detectPONType() != "gpon" {
+		return nil, fmt.Errorf("V-SOL Q-in-Q provisioning is only supported on GPON")
+	}
+
+	voice := subscriber.Spec.Voice
+	if voice != nil {
+		if err := voice.Validate(); err != nil {
+			return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "vsol"}
+		}
+		if a.detectPONType() != "gpon" {
+			return nil, fmt.Errorf("V-SOL voice provisioning is only supported on GPON")
+		}
+	}
+	multicast := subscriber.Spec.Multicast
+	if multicast != nil {
+		if err := multicast.Validate(); err != nil {
+			return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "vsol"}
+		}
+		if a.detectPONType() != "gpon" {
+			return nil, fmt.Errorf("V-SOL multicast provisioning is only supported on GPON")
+		}
+	}
+
+	tr069 := subscriber.Spec.TR069
+	if tr069 != nil {
+		if err := tr069.Validate(); err != nil {
+			return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "vsol"}
+		}
+		if a.detectPONType() != "gpon" {
+			return nil, fmt.Errorf("V-SOL TR-069 provisioning is only supported on GPON")
+		}
+	}
+
+	// A router-mode ONU dials PPPoE itself on its internet WAN
+	wan, err := types.SubscriberWANConfig(subscriber)
+	if err != nil {
+		return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "vsol"}
+	}
+	if wan != nil && a.detectPONType() != "gpon" {
+		return nil, fmt.Errorf("V-SOL WAN provisioning is only supported on GPON")
+	}
+
+	catv := subscriber.Spec.CATV
+	if catv != nil && a.detectPONType() != "gpon" {
+		return nil, fmt.Errorf("V-SOL CATV control is only supported on GPON")
+	}
+
+	// Voice, TR-069, the WAN, CATV and multicast are configured once the
+	// ONU exists
+	configureServices := func(id int) error {
+		if voice != nil {
+			if err := a.SetONUVoice(ctx, ponPort, id, voice); err != nil {
+				return err
 			}
-		} else {
-			commands = a.buildGPONCommands(ponPort, onuID, serial, vlan, bandwidthDown, bandwidthUp, subscriber, tier)
 		}
-	} else {
-		commands = a.buildEPONCommands(ponPort, onuID, serial, vlan, bandwidthDown, bandwidthUp, subscriber, tier)
+		if tr069 != nil {
+			if err := a.setONUTR069(ctx, ponPort, id, tr069); err != nil {
+				return err
+			}
+		}
+		if wan != nil {
+			if err := a.setONUWAN(ctx, ponPort, id, wan); err != nil {
+				return err
+			}
+		}
+		if catv != nil {
+			if err := a.SetONUCATVState(ctx, ponPort, id, *catv); err != nil {
+				return err
+			}
+		}
+		if multicast != nil {
+			return a.setONUMulticast(ctx, ponPort, id, multicast)
+		}
+		return nil
 	}
 
-	if len(commands) > 0 {
-		var err error
-		outputs, err = a.cliExecutor.ExecCommands(ctx, commands)
+	// The ONU is deleted again if any command after its creation fails
+	err = types.RunTransaction(ctx, "create subscriber "+subscriber.Name, a.config, func(tx *types.Transaction) error {
+		createPrefix := "onu add "
+		if a.detectPONType() == "gpon" {
+			// Auto-assign flow needs command output parsing to capture ONU ID.
+			if onuID <= 0 {
+				var err error
+				assignedID, outputs, err = a.provisionGPONWithConfirm(ctx, tx, ponPort, serial, subscriber)
+				if err != nil {
+					return err
+				}
+				return configureServices(assignedID)
+			}
+			commands = a.buildGPONCommands(ponPort, onuID, serial, vlan, bandwidthDown, bandwidthUp, subscriber, tier)
+		} else {
+			commands = a.buildEPONCommands(ponPort, onuID, serial, vlan, bandwidthDown, bandwidthUp, subscriber, tier)
+			createPrefix = "llid "
+		}
+
+		create, configure := common.SplitCommandsAfter(commands, createPrefix)
+		out, err := tx.ExecStep(ctx, a.cliExecutor, "add onu", create, a.buildDeleteCommands(ponPort, onuID))
+		outputs = append(outputs, out...)
 		if err != nil {
-			return nil, fmt.Errorf("V-SOL provisioning failed: %w", err)
+			return err
 		}
+		out, err = tx.ExecStep(ctx, a.cliExecutor, "configure onu", configure, nil)
+		outputs = append(outputs, out...)
+		if err != nil {
+			return err
+		}
+		return configureServices(onuID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("V-SOL provisioning failed: %w", types.AsHumanError("vsol", err))
 	}
 
 	// Apply bandwidth profiles if specified (GPON only — EPON uses llid flowctrl in buildEPONCommands)
@@ -390,12 +517,12 @@ func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscr
 
 		bwProfiles, err := a.findOrCreateBandwidthProfiles(ctx, bwUpKbps, bwDnKbps)
 		if err != nil {
-			slog.Warn("failed to create bandwidth profiles, skipping bandwidth config",
+			a.config.Log().Warn("failed to create bandwidth profiles, skipping bandwidth config",
 				"onu_id", targetID, "pon_port", ponPort, "error", err)
 		} else if bwProfiles != nil {
 			bwCmds := buildBandwidthCommands(ponPort, targetID, bwProfiles)
 			if _, err := a.cliExecutor.ExecCommands(ctx, bwCmds); err != nil {
-				slog.Warn("failed to apply bandwidth profiles",
+				a.config.Log().Warn("failed to apply bandwidth profiles",
 					"onu_id", targetID, "pon_port", ponPort, "error", err)
 			}
 		}
@@ -423,7 +550,10 @@ func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscr
 	return result, nil
 }
 
-func (a *Adapter) provisionGPONWithConfirm(ctx context.Context, ponPort, serial string, vlan int, subscriber *model.Subscriber) (int, []string, error) {
+// provisionGPONWithConfirm provisions an ONU from the auto-find list with
+// "onu confirm". Once the assigned ONU ID is known, deleting the ONU is
+// registered with tx.
+func (a *Adapter) provisionGPONWithConfirm(ctx context.Context, tx *types.Transaction, ponPort, serial string, subscriber *model.Subscriber) (int, []string, error) {
 	outputs := make([]string, 0, 8)
 	record := func(output string) {
 		if output != "" {
@@ -451,8 +581,13 @@ func (a *Adapter) provisionGPONWithConfirm(ctx context.Context, ponPort, serial
 
 	onuID, ok := parseVSOLConfirmOnuID(confirmOut)
 	if !ok {
+		a.metrics.RecordParseFailure()
 		return 0, outputs, fmt.Errorf("unable to parse ONU ID from confirm output")
 	}
+	tx.Compensate("confirm onu", func(ctx context.Context) error {
+		_, err := a.cliExecutor.ExecCommands(ctx, a.buildDeleteCommands(ponPort, onuID))
+		return err
+	})
 
 	if lineProfile, hasLineProfile := common.GetAnnotationString(subscriber.Annotations, "nano.io/line-profile"); hasLineProfile && lineProfile != "" {
 		if out, err := a.cliExecutor.ExecCommand(ctx, fmt.Sprintf("onu %d profile line name %s", onuID, common.SanitizeCLIParam(lineProfile))); err != nil {
@@ -461,16 +596,9 @@ func (a *Adapter) provisionGPONWithConfirm(ctx context.Context, ponPort, serial
 		} else {
 			record(out)
 		}
-	} else if vlan > 0 {
+	} else {
 		// Standard provisioning (no line profile) to populate VLAN
-		steps := []string{
-			fmt.Sprintf("onu %d tcont 1", onuID),
-			fmt.Sprintf("onu %d gemport 1 tcont 1", onuID),
-			fmt.Sprintf("onu %d service INTERNET gemport 1 vlan %d cos 0-7", onuID, vlan),
-			fmt.Sprintf("onu %d service-port 1 gemport 1 uservlan %d vlan %d", onuID, vlan, vlan),
-			fmt.Sprintf("onu %d portvlan eth 1 mode tag vlan %d", onuID, vlan),
-		}
-		for _, cmd := range steps {
+		for _, cmd := range buildGEMCommands(onuID, &subscriber.Spec) {
 			out, err := a.cliExecutor.ExecCommand(ctx, cmd)
 			record(out)
 			if err != nil {
@@ -548,26 +676,7 @@ func (a *Adapter) buildGPONCommands(ponPort string, onuID int, serial string, vl
 			// No manual service-port needed - line profile manages VLAN
 		} else {
 			// Standard provisioning with explicit service-port configuration
-			// Configure T-CONT and GEM port
-			commands = append(commands,
-				fmt.Sprintf("onu %d tcont 1", onuID),
-				fmt.Sprintf("onu %d gemport 1 tcont 1", onuID),
-			)
-
-			// Configure VLAN service (OLT-side)
-			commands = append(commands,
-				fmt.Sprintf("onu %d service INTERNET gemport 1 vlan %d cos 0-7", onuID, vlan),
-			)
-
-			// Configure VLAN service-port (OLT-side mapping)
-			commands = append(commands,
-				fmt.Sprintf("onu %d service-port 1 gemport 1 uservlan %d vlan %d", onuID, vlan, vlan),
-			)
-
-			// Configure VLAN tagging on ONU ports (ONU-side)
-			commands = append(commands,
-				fmt.Sprintf("onu %d portvlan eth 1 mode tag vlan %d", onuID, vlan),
-			)
+			commands = append(commands, buildGEMCommands(onuID, &subscriber.Spec)...)
 		}
 	}
 
@@ -579,6 +688,47 @@ func (a *Adapter) buildGPONCommands(ponPort string, onuID int, serial string, vl
 	return commands
 }
 
+// buildGEMCommands returns the T-CONT, GEM port, service and service-port
+// commands of the subscriber's GEM mapping, and tags ONU port eth 1 with
+// the user VLAN of the first GEM port
+func buildGEMCommands(onuID int, spec *model.SubscriberSpec) []string {
+	tconts, gems := spec.GEMMapping()
+	if len(gems) == 0 {
+		return nil
+	}
+	var commands []string
+
+	// Configure T-CONTs and GEM ports
+	for _, t := range tconts {
+		if t.DBAProfile != "" {
+			commands = append(commands, fmt.Sprintf("onu %d tcont %d dba %s", onuID, t.ID, t.DBAProfile))
+		} else {
+			commands = append(commands, fmt.Sprintf("onu %d tcont %d", onuID, t.ID))
+		}
+	}
+	for _, g := range gems {
+		commands = append(commands, fmt.Sprintf("onu %d gemport %d tcont %d", onuID, g.ID, g.TCont))
+	}
+
+	// Configure VLAN services (OLT-side) and service-ports (OLT-side mapping)
+	for _, g := range gems {
+		commands = append(commands,
+			fmt.Sprintf("onu %d service %s gemport %d vlan %d cos 0-7", onuID, g.GetService(), g.ID, g.VLAN))
+	}
+	for i, g := range gems {
+		cmd := fmt.Sprintf("onu %d service-port %d gemport %d uservlan %d vlan %d", onuID, i+1, g.ID, g.GetUserVLAN(), g.VLAN)
+		if g.SVLAN > 0 {
+			// Q-in-Q: the S-VLAN is added as outer tag
+			cmd += fmt.Sprintf(" svlan %d", g.SVLAN)
+		}
+		commands = append(commands, cmd)
+	}
+
+	// Configure VLAN tagging on ONU ports (ONU-side)
+	commands = append(commands, fmt.Sprintf("onu %d portvlan eth 1 mode tag vlan %d", onuID, gems[0].GetUserVLAN()))
+	return commands
+}
+
 // buildEPONCommands builds V-SOL EPON CLI commands
 func (a *Adapter) buildEPONCommands(ponPort string, onuID int, mac string, vlan int, bwDown, bwUp int, subscriber *model.Subscriber, tier *model.ServiceTier) []string {
 	// V-SOL EPON CLI reference
@@ -644,9 +794,15 @@ func (a *Adapter) UpdateSubscriber(ctx context.Context, subscriber *model.Subscr
 				// ONU-side tagging
 				fmt.Sprintf("onu %d portvlan eth 1 mode tag vlan %d", onuID, vlan))
 		}
+		if catv := subscriber.Spec.CATV; catv != nil {
+			commands = append(commands, catvCommand(onuID, *catv))
+		}
 
 		commands = append(commands, "exit")
 	} else {
+		if subscriber.Spec.CATV != nil {
+			return fmt.Errorf("V-SOL CATV control is only supported on GPON")
+		}
 		// EPON update
 		commands = []string{
 			fmt.Sprintf("interface epon %s", ponPort),
@@ -669,10 +825,15 @@ func (a *Adapter) DeleteSubscriber(ctx context.Context, subscriberID string) err
 	// Parse subscriberID to get PON port and ONU ID
 	ponPort, onuID := a.parseSubscriberID(subscriberID)
 
-	var commands []string
+	_, err := a.cliExecutor.ExecCommands(ctx, a.buildDeleteCommands(ponPort, onuID))
+	return err
+}
 
+// buildDeleteCommands builds the commands that remove an ONU and all of
+// its configuration
+func (a *Adapter) buildDeleteCommands(ponPort string, onuID int) []string {
 	if a.detectPONType() == "gpon" {
-		commands = []string{
+		return []string{
 			"configure terminal",
 			fmt.Sprintf("interface gpon %s", ponPort),
 			fmt.Sprintf("no onu %d", onuID),
@@ -680,26 +841,34 @@ func (a *Adapter) DeleteSubscriber(ctx context.Context, subscriberID string) err
 			"commit",
 			"end",
 		}
-	} else {
-		commands = []string{
-			"configure terminal",
-			fmt.Sprintf("interface epon %s", ponPort),
-			fmt.Sprintf("no llid %d", onuID),
-			"exit",
-			"commit",
-			"end",
-		}
 	}
-
-	_, err := a.cliExecutor.ExecCommands(ctx, commands)
-	return err
+	return []string{
+		"configure terminal",
+		fmt.Sprintf("interface epon %s", ponPort),
+		fmt.Sprintf("no llid %d", onuID),
+		"exit",
+		"commit",
+		"end",
+	}
 }
 
+// SuspendSubscriber deactivates the ONU, or applies the soft suspension
+// configured by the device's suspend_mode metadata, such as moving it to
+// the walled-garden VLAN.
 func (a *Adapter) SuspendSubscriber(ctx context.Context, subscriberID string) error {
 	if a.cliExecutor == nil {
 		return fmt.Errorf("CLI executor not available")
 	}
 
+	opts, err := types.ConfiguredSuspension(a.config)
+	if err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "vsol"}
+	}
+	if opts != nil {
+		_, err := a.SoftSuspendSubscriber(ctx, subscriberID, opts)
+		return err
+	}
+
 	ponPort, onuID := a.parseSubscriberID(subscriberID)
 
 	var commands []string
@@ -723,15 +892,23 @@ func (a *Adapter) SuspendSubscriber(ctx context.Context, subscriberID string) er
 		}
 	}
 
-	_, err := a.cliExecutor.ExecCommands(ctx, commands)
+	_, err = a.cliExecutor.ExecCommands(ctx, commands)
 	return err
 }
 
+// ResumeSubscriber lifts a soft suspension, or activates the ONU.
 func (a *Adapter) ResumeSubscriber(ctx context.Context, subscriberID string) error {
 	if a.cliExecutor == nil {
 		return fmt.Errorf("CLI executor not available")
 	}
 
+	a.suspensionMu.RLock()
+	state := a.suspensionStates[subscriberID]
+	a.suspensionMu.RUnlock()
+	if state != nil {
+		return a.liftSoftSuspension(ctx, subscriberID, state)
+	}
+
 	ponPort, onuID := a.parseSubscriberID(subscriberID)
 
 	var commands []string
@@ -918,16 +1095,31 @@ func (a *Adapter) DiscoverONUs(ctx context.Context, ponPorts []string) ([]types.
 	return discoveries, nil
 }
 
-// GetONUList returns all provisioned ONUs matching the filter (DriverV2)
+// GetONUList returns all provisioned ONUs matching the filter (DriverV2).
+// A PON port filter, or a complete serial the CLI can look up, limits the
+// SNMP walks or CLI queries to the ONUs of one port.
 func (a *Adapter) GetONUList(ctx context.Context, filter *types.ONUFilter) ([]types.ONUInfo, error) {
+	scopePort, ok := a.onuListScope(ctx, filter)
+	if !ok {
+		return []types.ONUInfo{}, nil
+	}
+
 	// Try SNMP first if available (much faster than CLI - 1 walk vs 8 port iterations)
 	if a.snmpExecutor != nil && !a.preferCLI() {
-		onus, err := a.getONUListSNMP(ctx)
+		scope := ""
+		if scopePort != "" {
+			idx, err := PortToPONIndex(scopePort)
+			if err != nil {
+				return []types.ONUInfo{}, nil
+			}
+			scope = strconv.Itoa(idx)
+		}
+		onus, err := a.getONUListSNMP(ctx, scope)
 		if err == nil {
 			if filter != nil {
 				onus = a.filterONUList(onus, filter)
 			}
-			return onus, nil
+			return types.PageONUs(onus, filter), nil
 		}
 		// Fall through to CLI on SNMP failure
 	}
@@ -944,6 +1136,9 @@ func (a *Adapter) GetONUList(ctx context.Context, filter *types.ONUFilter) ([]ty
 	if a.detectPONType() == "gpon" {
 		// V1600 style: configure terminal -> interface gpon -> show onu info
 		ponPorts := a.getPONPortList()
+		if scopePort != "" {
+			ponPorts = []string{scopePort}
+		}
 		for _, ponPort := range ponPorts {
 			commands := []string{
 				"configure terminal",
@@ -966,7 +1161,7 @@ func (a *Adapter) GetONUList(ctx context.Context, filter *types.ONUFilter) ([]ty
 				if filter != nil {
 					onus = a.filterONUList(onus, filter)
 				}
-				return onus, nil
+				return types.PageONUs(onus, filter), nil
 			}
 
 			// Parse the "show onu info" output (index 2: config=0, interface=1, info=2)
@@ -1010,7 +1205,30 @@ func (a *Adapter) GetONUList(ctx context.Context, filter *types.ONUFilter) ([]ty
 		allOnus = a.filterONUList(allOnus, filter)
 	}
 
-	return allOnus, nil
+	return types.PageONUs(allOnus, filter), nil
+}
+
+// onuListScope returns the PON port the ONUs of filter are on: its PON
+// port, or the port of its serial if it is a complete serial and the CLI
+// is available to look it up. The port is empty if the ONUs may be on any
+// port, and ok is false if no ONU can match.
+func (a *Adapter) onuListScope(ctx context.Context, filter *types.ONUFilter) (ponPort string, ok bool) {
+	if filter == nil {
+		return "", true
+	}
+	if filter.PONPort != "" || filter.Serial == "" || a.cliExecutor == nil || common.ValidateSerial(filter.Serial) != nil {
+		return filter.PONPort, true
+	}
+	onu, err := a.GetONUBySerial(ctx, filter.Serial)
+	switch {
+	case err != nil:
+		// List all ports rather than fail the listing
+		a.config.Log().Debug("vsol: ONU lookup by serial failed", "serial", filter.Serial, "error", err)
+		return "", true
+	case onu == nil:
+		return "", false
+	}
+	return onu.PONPort, true
 }
 
 // mergeONUState merges state info into ONU list
@@ -1050,6 +1268,9 @@ func (a *Adapter) mergeONUState(onus []types.ONUInfo, states []ONUStateInfo) {
 
 // getPONPortList returns the list of PON ports to scan
 func (a *Adapter) getPONPortList() []string {
+	if a.config != nil && len(a.config.PONPorts) > 0 {
+		return a.config.PONPorts
+	}
 	// Default PON ports for V1600 series (8 or 16 ports typically)
 	// Start with common ports, can be expanded based on model detection
 	return []string{"0/1", "0/2", "0/3", "0/4", "0/5", "0/6", "0/7", "0/8"}
@@ -1120,9 +1341,24 @@ func (a *Adapter) GetONUDetails(ctx context.Context, ponPort string, onuID int)
 		}
 	}
 
+	a.addLastDown(ctx, onu)
+
 	return onu, nil
 }
 
+// addLastDown adds the last down cause and time of an ONU from the ONU info
+// also read by GetSubscriberStatus. Errors are ignored as the ONU is
+// complete without them.
+func (a *Adapter) addLastDown(ctx context.Context, onu *types.ONUInfo) {
+	cmd := fmt.Sprintf("show onu-info gpon %s %d", onu.PONPort, onu.ONUID)
+	if a.detectPONType() != "gpon" {
+		cmd = fmt.Sprintf("show llid-info epon %s %d", onu.PONPort, onu.ONUID)
+	}
+	if output, err := a.cliExecutor.ExecCommand(ctx, cmd); err == nil {
+		common.ParseLastDown(onu, output)
+	}
+}
+
 // GetAllONUDetails fetches detailed information for all ONUs.
 // This is more efficient than calling GetONUDetails for each ONU individually
 // as it batches commands per PON port.
@@ -1602,7 +1838,8 @@ func detectONUVendor(serial string) string {
 	return ""
 }
 
-// GetONUBySerial finds a specific ONU by serial number (DriverV2)
+// GetONUBySerial finds a specific ONU by serial number, in ASCII or hex
+// form (DriverV2)
 func (a *Adapter) GetONUBySerial(ctx context.Context, serial string) (*types.ONUInfo, error) {
 	if a.cliExecutor == nil {
 		return nil, fmt.Errorf("CLI executor not available")
@@ -1610,6 +1847,7 @@ func (a *Adapter) GetONUBySerial(ctx context.Context, serial string) (*types.ONU
 
 	// V-SOL CLI command to search for ONU by serial
 	var cmd string
+	serial = common.DecodeHexSerial(serial)
 	sanitizedSerial := common.SanitizeCLIParam(serial)
 	if a.detectPONType() == "gpon" {
 		cmd = fmt.Sprintf("show onu sn %s", sanitizedSerial)
@@ -1937,19 +2175,12 @@ func (a *Adapter) RestartONU(ctx context.Context, ponPort string, onuID int) (*t
 	result.DeactivateSuccess = true
 
 	// Step 2: Verify ONU is offline with retries
-	// Wait times: initial 3s, then retry after 5s, then retry after 10s
-	deactivateWaits := []time.Duration{3 * time.Second, 5 * time.Second, 10 * time.Second}
-	for attempt, waitTime := range deactivateWaits {
-		time.Sleep(waitTime)
+	verified, retries := common.WaitONUState(ctx, common.RestartOfflineWaits, func(ctx context.Context) bool {
 		stateOutput, stateErr := a.cliExecutor.ExecCommand(ctx, "show onu state")
-		if stateErr == nil && a.verifyONUState(stateOutput, onuID, false) {
-			result.DeactivateVerified = true
-			break
-		}
-		if attempt > 0 {
-			result.RetryCount++
-		}
-	}
+		return stateErr == nil && a.verifyONUState(stateOutput, onuID, false)
+	})
+	result.DeactivateVerified = verified
+	result.RetryCount += retries
 
 	// Step 3: Activate the ONU
 	_, err = a.cliExecutor.ExecCommand(ctx, fmt.Sprintf("onu %d activate", onuID))
@@ -1963,37 +2194,60 @@ func (a *Adapter) RestartONU(ctx context.Context, ponPort string, onuID int) (*t
 	result.ActivateSuccess = true
 
 	// Step 4: Verify ONU is back online with retries
-	// Wait times: initial 5s, then retry after 10s, then retry after 15s (ONU re-registration takes time)
-	activateWaits := []time.Duration{5 * time.Second, 10 * time.Second, 15 * time.Second}
-	for attempt, waitTime := range activateWaits {
-		time.Sleep(waitTime)
+	verified, retries = common.WaitONUState(ctx, common.RestartOnlineWaits, func(ctx context.Context) bool {
 		stateOutput, stateErr := a.cliExecutor.ExecCommand(ctx, "show onu state")
-		if stateErr == nil && a.verifyONUState(stateOutput, onuID, true) {
-			result.ActivateVerified = true
-			break
-		}
-		if attempt > 0 {
-			result.RetryCount++
-		}
-	}
+		return stateErr == nil && a.verifyONUState(stateOutput, onuID, true)
+	})
+	result.ActivateVerified = verified
+	result.RetryCount += retries
 
 	// Exit interface and config modes
 	_, _ = a.cliExecutor.ExecCommands(ctx, []string{"exit", "exit"})
 
-	// Determine overall success and message
-	if result.DeactivateSuccess && result.ActivateSuccess {
-		result.Success = true
-		if result.DeactivateVerified && result.ActivateVerified {
-			result.Message = "ONU restart completed and verified"
-		} else if result.ActivateVerified {
-			result.Message = "ONU restart completed, deactivation not verified but ONU is now online"
-		} else if result.DeactivateVerified {
-			result.Message = "ONU restarted but still coming online (may take a few more seconds)"
-		} else {
-			result.Message = "ONU restart commands sent, verification pending"
-		}
+	common.FinishRestart(result)
+	return result, nil
+}
+
+// FactoryResetONU restores the factory configuration of an ONU with onu
+// restore, then polls show onu state until the ONU is working again. GPON
+// only.
+func (a *Adapter) FactoryResetONU(ctx context.Context, ponPort string, onuID int, opts *types.FactoryResetOptions) (*types.FactoryResetResult, error) {
+	if err := types.CheckFactoryReset(opts, "vsol"); err != nil {
+		return nil, err
+	}
+	result := &types.FactoryResetResult{}
+	if err := a.execONUCommands(ctx, ponPort, fmt.Sprintf("onu %d restore", onuID)); err != nil {
+		result.Error = err.Error()
+		result.Message = "Failed to send factory reset command"
+		return result, fmt.Errorf("vsol ONU factory reset failed: %w", err)
 	}
+	result.ResetSent = true
 
+	if opts.SkipsVerification() {
+		result.Success = true
+		result.Message = "ONU factory reset command sent; re-registration not verified"
+		return result, nil
+	}
+	result.Reregistered = opts.AwaitReregistration(ctx, func(ctx context.Context) (bool, error) {
+		outputs, err := a.cliExecutor.ExecCommands(ctx, []string{
+			"configure terminal",
+			fmt.Sprintf("interface gpon %s", ponPort),
+			"show onu state",
+			"exit",
+			"exit",
+		})
+		if err != nil || len(outputs) < 3 {
+			return false, err
+		}
+		return a.verifyONUState(outputs[2], onuID, true), nil
+	})
+	if !result.Reregistered {
+		result.Error = "ONU did not register again"
+		result.Message = "ONU factory reset command sent but the ONU did not come back online"
+		return result, nil
+	}
+	result.Success = true
+	result.Message = "ONU reset to factory settings and registered again"
 	return result, nil
 }
 
@@ -2243,7 +2497,7 @@ func (a *Adapter) ApplyProfile(ctx context.Context, ponPort string, onuID int, p
 		if profile.BandwidthUp > 0 || profile.BandwidthDown > 0 {
 			bwProfiles, err := a.findOrCreateBandwidthProfiles(ctx, profile.BandwidthUp, profile.BandwidthDown)
 			if err != nil {
-				slog.Warn("failed to create bandwidth profiles for profile update",
+				a.config.Log().Warn("failed to create bandwidth profiles for profile update",
 					"onu_id", onuID, "pon_port", ponPort, "error", err)
 			} else if bwProfiles != nil {
 				commands = append(commands, buildBandwidthONUCommands(onuID, bwProfiles)...)
@@ -2307,7 +2561,7 @@ func (a *Adapter) BulkProvision(ctx context.Context, operations []types.BulkProv
 		var err error
 		cache, err = a.newProfileCache(ctx)
 		if err != nil {
-			slog.Warn("failed to pre-fetch profile cache for bulk provision, will fetch per-operation", "error", err)
+			a.config.Log().Warn("failed to pre-fetch profile cache for bulk provision, will fetch per-operation", "error", err)
 		}
 	}
 
@@ -2352,7 +2606,7 @@ func (a *Adapter) BulkProvision(ctx context.Context, operations []types.BulkProv
 			if op.Profile != nil && (op.Profile.BandwidthUp > 0 || op.Profile.BandwidthDown > 0) {
 				bwProfiles, err := a.findOrCreateBandwidthProfilesCached(ctx, op.Profile.BandwidthUp, op.Profile.BandwidthDown, cache)
 				if err != nil {
-					slog.Warn("failed to create bandwidth profiles for bulk provision",
+					a.config.Log().Warn("failed to create bandwidth profiles for bulk provision",
 						"onu_id", op.ONUID, "pon_port", op.PONPort, "error", err)
 				} else if bwProfiles != nil {
 					commands = append(commands, buildBandwidthONUCommands(op.ONUID, bwProfiles)...)
@@ -2392,8 +2646,10 @@ func (a *Adapter) BulkProvision(ctx context.Context, operations []types.BulkProv
 		result.Results[i] = opResult
 	}
 
-	// Commit all changes (ignore error - some operations may have succeeded)
-	_, _ = a.cliExecutor.ExecCommand(ctx, "commit")
+	// Commit all changes (don't fail - some operations may have succeeded)
+	if _, err := a.cliExecutor.ExecCommand(ctx, "commit"); err != nil {
+		a.config.Log().Warn("V-SOL: bulk provision commit failed", "error", err)
+	}
 
 	return result, nil
 }
@@ -2489,6 +2745,10 @@ func (a *Adapter) RunDiagnostics(ctx context.Context, ponPort string, onuID int)
 		diag.VendorData["config_output"] = output
 	}
 
+	if q, err := a.GetONULineQuality(ctx, ponPort, onuID); err == nil {
+		diag.LineQuality = q
+	}
+
 	return diag, nil
 }
 
@@ -2599,6 +2859,7 @@ func (a *Adapter) enrichStatusWithCLIMetrics(ctx context.Context, status *types.
 			}
 		} else {
 			status.Metadata["cpu_parse_fail"] = "regex did not match"
+			a.metrics.RecordParseFailure()
 		}
 	}
 
@@ -2622,6 +2883,7 @@ func (a *Adapter) enrichStatusWithCLIMetrics(ctx context.Context, status *types.
 			status.MemoryPercent = (memTotal - memFree) / memTotal * 100
 		} else {
 			status.Metadata["mem_parse_fail"] = "memTotal is 0"
+			a.metrics.RecordParseFailure()
 		}
 	}
 
@@ -2906,14 +3168,16 @@ func extractPONIndexFromOID(oidSuffix string) int {
 }
 
 // getONUListSNMP retrieves all ONUs using SNMP bulk walk (faster than CLI)
-// This replaces 8 CLI iterations with a single SNMP walk operation
-func (a *Adapter) getONUListSNMP(ctx context.Context) ([]types.ONUInfo, error) {
+// This replaces 8 CLI iterations with a single SNMP walk operation. A
+// non-empty scope, the SNMP index of a PON port, limits the walks to the
+// ONUs of that port.
+func (a *Adapter) getONUListSNMP(ctx context.Context, scope string) ([]types.ONUInfo, error) {
 	if a.snmpExecutor == nil {
 		return nil, fmt.Errorf("SNMP executor not available")
 	}
 
 	// Walk serial numbers to discover all ONUs (primary table)
-	serials, err := a.snmpExecutor.WalkSNMP(ctx, OIDONUSerialNumber)
+	serials, err := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUSerialNumber, scope)
 	if err != nil {
 		return nil, fmt.Errorf("failed to walk ONU serials: %w", err)
 	}
@@ -2923,23 +3187,23 @@ func (a *Adapter) getONUListSNMP(ctx context.Context) ([]types.ONUInfo, error) {
 	}
 
 	// Walk additional attributes (non-fatal if any fail)
-	adminStates, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONUAdminState)
-	phaseStates, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONUPhaseState)
-	models, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONUModel)
-	vendors, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONUVendorID)
-	rxPowers, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONURxPower)
-	txPowers, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONUTxPower)
-	distances, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONUDistance)
-	temperatures, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONUTemperature)
-	voltages, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONUVoltage)
-	biasCurrents, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONUBiasCurrent)
-	profiles, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONUProfile)
-	lineProfiles, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONULineProfile)
+	adminStates, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUAdminState, scope)
+	phaseStates, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUPhaseState, scope)
+	models, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUModel, scope)
+	vendors, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUVendorID, scope)
+	rxPowers, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONURxPower, scope)
+	txPowers, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUTxPower, scope)
+	distances, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUDistance, scope)
+	temperatures, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUTemperature, scope)
+	voltages, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUVoltage, scope)
+	biasCurrents, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUBiasCurrent, scope)
+	profiles, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUProfile, scope)
+	lineProfiles, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONULineProfile, scope)
 	// Service VLAN is available via SNMP at OIDONUServiceVLAN
 	// Format: {pon_idx}.{onu_idx}.{gem_idx} - we need to map this to {pon_idx}.{onu_idx}
-	serviceVLANs, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONUServiceVLAN)
-	upstreamBytes, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONUUpstreamBytes)
-	downstreamBytes, _ := a.snmpExecutor.WalkSNMP(ctx, OIDONUDownstreamBytes)
+	serviceVLANs, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUServiceVLAN, scope)
+	upstreamBytes, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUUpstreamBytes, scope)
+	downstreamBytes, _ := common.WalkSubtree(ctx, a.snmpExecutor, OIDONUDownstreamBytes, scope)
 
 	// Build results by correlating tables via index
 	results := make([]types.ONUInfo, 0, len(serials))
@@ -3651,7 +3915,7 @@ func (a *Adapter) parseONUStats(output string) *types.SubscriberStats {
 
 // detectModel determines the V-SOL OLT model
 func (a *Adapter) detectModel() string {
-	if model, ok := a.config.Metadata["model"]; ok {
+	if model := a.config.DeviceModel(); model != "" {
 		return model
 	}
 	return "v1600g"
@@ -4904,7 +5168,7 @@ func (a *Adapter) CaptureSubscriberConfig(ctx context.Context, subscriberID stri
 	allServicePorts, err := a.ListServicePorts(ctx)
 	if err != nil {
 		// Non-fatal: we can still capture the basic config
-		slog.Warn("failed to list service ports during snapshot",
+		a.config.Log().Warn("failed to list service ports during snapshot",
 			"subscriber_id", subscriberID, "error", err)
 	}
 
@@ -5088,7 +5352,7 @@ func (a *Adapter) ReplaceONU(ctx context.Context, subscriberID string, newSerial
 		}
 	}
 
-	slog.Info("replace_onu: replacement complete",
+	a.config.Log().Info("replace_onu: replacement complete",
 		"old_serial", oldSerial, "new_serial", newSerial,
 		"pon_port", ponPort, "onu_id", onuID,
 		"verification", result.VerificationStatus)
@@ -5113,6 +5377,12 @@ func (a *Adapter) SoftSuspendSubscriber(ctx context.Context, subscriberID string
 		return nil, fmt.Errorf("unsupported suspension mode: %q", opts.Mode)
 	}
 
+	// A zero walled-garden VLAN is taken from the device
+	opts, err := types.ResolveSuspendOptions(opts, a.config)
+	if err != nil {
+		return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "vsol"}
+	}
+
 	ponPort, onuID := a.parseSubscriberID(subscriberID)
 
 	// Step 1: Capture current config for restoration
@@ -5150,14 +5420,11 @@ func (a *Adapter) SoftSuspendSubscriber(ctx context.Context, subscriberID string
 
 	if opts.Mode == types.SuspensionModeWalledGarden || opts.Mode == types.SuspensionModeQuarantine {
 		walledVLAN := opts.WalledGardenVLAN
-		if walledVLAN <= 0 {
-			return nil, fmt.Errorf("walled_garden_vlan is required for mode %q", opts.Mode)
-		}
 		state.AppliedVLAN = walledVLAN
 
 		// Delete current service port and re-create with walled-garden VLAN
 		if err := a.DeleteServicePort(ctx, ponPort, onuID); err != nil {
-			slog.Warn("soft_suspend: failed to delete existing service port, may not exist",
+			a.config.Log().Warn("soft_suspend: failed to delete existing service port, may not exist",
 				"subscriber_id", subscriberID, "error", err)
 		}
 
@@ -5181,7 +5448,7 @@ func (a *Adapter) SoftSuspendSubscriber(ctx context.Context, subscriberID string
 	a.suspensionStates[subscriberID] = state
 	a.suspensionMu.Unlock()
 
-	slog.Info("soft_suspend: suspension applied",
+	a.config.Log().Info("soft_suspend: suspension applied",
 		"subscriber_id", subscriberID, "mode", opts.Mode,
 		"throttle_kbps", state.AppliedBandwidthKbps,
 		"walled_vlan", state.AppliedVLAN)
@@ -5189,6 +5456,44 @@ func (a *Adapter) SoftSuspendSubscriber(ctx context.Context, subscriberID string
 	return state, nil
 }
 
+// liftSoftSuspension puts the ONU back on the service ports captured before
+// a soft suspension and forgets the suspension. A throttle bandwidth
+// profile is left for the caller to replace with the subscriber's tier, as
+// the original profile is not captured.
+func (a *Adapter) liftSoftSuspension(ctx context.Context, subscriberID string, state *types.SuspensionState) error {
+	ponPort, onuID := a.parseSubscriberID(subscriberID)
+
+	if state.AppliedVLAN > 0 {
+		if state.OriginalSnapshot == nil {
+			return fmt.Errorf("no configuration captured for %s before suspension", subscriberID)
+		}
+		if err := a.DeleteServicePort(ctx, ponPort, onuID); err != nil {
+			return fmt.Errorf("failed to remove walled-garden VLAN %d: %w", state.AppliedVLAN, err)
+		}
+		for _, req := range state.OriginalSnapshot.ServicePortRequests(ponPort, onuID) {
+			if req.UserVLAN == 0 {
+				req.UserVLAN = req.VLAN
+			}
+			req.ETHPort = 1
+			if err := a.AddServicePort(ctx, &req); err != nil {
+				return fmt.Errorf("failed to restore VLAN %d: %w", req.VLAN, err)
+			}
+		}
+	}
+	if state.AppliedBandwidthKbps > 0 {
+		a.config.Log().Warn("resume: throttle bandwidth not restored, reapply the subscriber tier",
+			"subscriber_id", subscriberID, "throttle_kbps", state.AppliedBandwidthKbps)
+	}
+
+	a.suspensionMu.Lock()
+	delete(a.suspensionStates, subscriberID)
+	a.suspensionMu.Unlock()
+
+	a.config.Log().Info("resume: soft suspension lifted",
+		"subscriber_id", subscriberID, "mode", state.Mode)
+	return nil
+}
+
 // GetSuspensionState returns the current soft suspension state for a subscriber.
 func (a *Adapter) GetSuspensionState(ctx context.Context, subscriberID string) (*types.SuspensionState, error) {
 	a.suspensionMu.RLock()
@@ -5247,7 +5552,7 @@ func (a *Adapter) MoveSubscriber(ctx context.Context, subscriberID string, targe
 		result.Warnings = append(result.Warnings, fmt.Sprintf("old subscriber cleanup: %v", deleteErr))
 	}
 
-	slog.Info("move_subscriber: move complete",
+	a.config.Log().Info("move_subscriber: move complete",
 		"subscriber_id", subscriberID,
 		"old_port", oldPONPort, "new_port", targetPONPort,
 		"old_onu_id", oldONUID, "new_onu_id", targetONUID,
@@ -5272,7 +5577,7 @@ func (a *Adapter) CheckONUCompatibility(ctx context.Context, subscriberID string
 	snapshot, err := a.CaptureSubscriberConfig(ctx, subscriberID)
 	if err != nil {
 		// If we can't capture config, we can still do basic serial-prefix checks
-		slog.Warn("compatibility check: could not capture current config",
+		a.config.Log().Warn("compatibility check: could not capture current config",
 			"subscriber_id", subscriberID, "error", err)
 	} else {
 		report.CurrentProfile = snapshot.ONUProfile
@@ -5401,7 +5706,7 @@ func (a *Adapter) AddONUToSubscriber(ctx context.Context, subscriberID string, b
 		return nil, fmt.Errorf("failed to add ONU %s to subscriber %s: %w", binding.Serial, subscriberID, err)
 	}
 
-	slog.Info("multi_onu: added ONU to subscriber",
+	a.config.Log().Info("multi_onu: added ONU to subscriber",
 		"subscriber_id", subscriberID, "serial", binding.Serial,
 		"pon_port", binding.PONPort, "onu_id", binding.ONUID,
 		"role", binding.Role)
@@ -5425,12 +5730,12 @@ func (a *Adapter) RemoveONUFromSubscriber(ctx context.Context, subscriberID stri
 	}
 
 	for _, onu := range onus {
-		if onu.Serial == serial {
+		if common.SerialsEqual(onu.Serial, serial) {
 			onuSubID := fmt.Sprintf("onu-%s-%d", onu.PONPort, onu.ONUID)
 			if err := a.DeleteSubscriber(ctx, onuSubID); err != nil {
 				return fmt.Errorf("failed to remove ONU %s: %w", serial, err)
 			}
-			slog.Info("multi_onu: removed ONU from subscriber",
+			a.config.Log().Info("multi_onu: removed ONU from subscriber",
 				"subscriber_id", subscriberID, "serial", serial)
 			return nil
 		}
@@ -3,9 +3,11 @@ package vsol
 import (
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/testutil"
 	"github.com/nanoncore/nano-southbound/types"
 )
 
@@ -1418,6 +1420,56 @@ func TestBuildGPONCommands(t *testing.T) {
 			t.Errorf("expected service-port command in: %v", cmds)
 		}
 	})
+
+	t.Run("explicit GEM mapping", func(t *testing.T) {
+		sub := &model.Subscriber{
+			Annotations: map[string]string{},
+			Spec: model.SubscriberSpec{
+				ONUSerial: "FHTT12345678",
+				TConts:    []model.TCont{{ID: 1}, {ID: 2, DBAProfile: "dba_voice"}},
+				GEMPorts: []model.GEMPort{
+					{ID: 1, TCont: 1, VLAN: 100, UserVLAN: 10},
+					{ID: 2, TCont: 2, VLAN: 200, Service: "VOIP"},
+				},
+			},
+		}
+		tier := &model.ServiceTier{Spec: model.ServiceTierSpec{BandwidthDown: 100, BandwidthUp: 50}}
+		cmds := adapter.buildGPONCommands("0/1", 5, "FHTT12345678", 0, 100000, 50000, sub, tier)
+
+		want := []string{
+			"onu 5 tcont 1",
+			"onu 5 tcont 2 dba dba_voice",
+			"onu 5 gemport 1 tcont 1",
+			"onu 5 gemport 2 tcont 2",
+			"onu 5 service INTERNET gemport 1 vlan 100 cos 0-7",
+			"onu 5 service VOIP gemport 2 vlan 200 cos 0-7",
+			"onu 5 service-port 1 gemport 1 uservlan 10 vlan 100",
+			"onu 5 service-port 2 gemport 2 uservlan 200 vlan 200",
+			"onu 5 portvlan eth 1 mode tag vlan 10",
+		}
+		if got := cmds[3 : len(cmds)-2]; !equalStringSlices(got, want) {
+			t.Errorf("GEM commands = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Q-in-Q", func(t *testing.T) {
+		svlan := 1000
+		sub := &model.Subscriber{
+			Annotations: map[string]string{},
+			Spec:        model.SubscriberSpec{ONUSerial: "FHTT12345678", VLAN: 100, SVLAN: &svlan},
+		}
+		tier := &model.ServiceTier{Spec: model.ServiceTierSpec{BandwidthDown: 100, BandwidthUp: 50}}
+		cmds := adapter.buildGPONCommands("0/1", 5, "FHTT12345678", 100, 100000, 50000, sub, tier)
+
+		want := "onu 5 service-port 1 gemport 1 uservlan 100 vlan 100 svlan 1000"
+		found := false
+		for _, cmd := range cmds {
+			found = found || cmd == want
+		}
+		if !found {
+			t.Errorf("missing %q in %v", want, cmds)
+		}
+	})
 }
 
 // =============================================================================
@@ -1604,6 +1656,22 @@ func TestConnect(t *testing.T) {
 			t.Fatalf("unexpected error: %v", err)
 		}
 	})
+
+	t.Run("keeps construction options", func(t *testing.T) {
+		mock := &mockDriverCLI{}
+		adapter := &Adapter{
+			baseDriver:  mock,
+			cliExecutor: mock,
+			config:      &types.EquipmentConfig{Model: "v1600g4", PONPorts: []string{"0/1"}},
+		}
+		config := &types.EquipmentConfig{Address: "10.0.0.1", Metadata: map[string]string{"firmware": "V2.1.6R"}}
+		if err := adapter.Connect(context.Background(), config); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if adapter.detectModel() != "v1600g4" || !slices.Equal(adapter.getPONPortList(), []string{"0/1"}) || adapter.config.Metadata["firmware"] != "V2.1.6R" {
+			t.Errorf("config = %+v", adapter.config)
+		}
+	})
 }
 
 func TestDisconnect(t *testing.T) {
@@ -1904,6 +1972,45 @@ func TestCreateSubscriber(t *testing.T) {
 		}
 	})
 
+	rollbackCases := []struct {
+		name       string
+		failCmd    string
+		wantDelete bool
+	}{
+		{"configuration fails after ONU add", "onu 5 tcont 1", true},
+		{"ONU add fails", "onu add 5 profile AN5506-04-F1 sn FHTT12345678", false},
+	}
+	for _, tc := range rollbackCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exec := &testutil.MockCLIExecutor{Errors: map[string]error{tc.failCmd: fmt.Errorf("%% Error")}}
+			adapter := &Adapter{
+				cliExecutor: exec,
+				config:      &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "gpon"}},
+			}
+			sub := &model.Subscriber{
+				Name: "test-sub",
+				Annotations: map[string]string{
+					"nanoncore.com/pon-port": "0/1",
+					"nanoncore.com/onu-id":   "5",
+				},
+				Spec: model.SubscriberSpec{ONUSerial: "FHTT12345678", VLAN: 100},
+			}
+			if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err == nil {
+				t.Fatal("expected error")
+			}
+
+			deleted := false
+			for _, cmd := range exec.Commands {
+				if cmd == "no onu 5" {
+					deleted = true
+				}
+			}
+			if deleted != tc.wantDelete {
+				t.Errorf("ONU deleted = %v, want %v; commands: %v", deleted, tc.wantDelete, exec.Commands)
+			}
+		})
+	}
+
 	t.Run("no CLI executor", func(t *testing.T) {
 		adapter := &Adapter{config: &types.EquipmentConfig{Metadata: map[string]string{}}}
 		_, err := adapter.CreateSubscriber(context.Background(), &model.Subscriber{}, &model.ServiceTier{})
@@ -2176,6 +2283,7 @@ Output bytes:                 1144072
 Input packets:                50
 Output packets:               17484`,
 				"show running-config onu 1": `onu 1 service-port 1 gemport 1 uservlan 702 vlan 702 new_cos 0`,
+				"show onu-info gpon 0/1 1":  "Status: Online\nLast Down Cause: Dying Gasp\nLast Down Time: 2024/03/02 07:15:00",
 			},
 		}
 		adapter := &Adapter{
@@ -2208,6 +2316,9 @@ Output packets:               17484`,
 		if onu.VLAN != 702 {
 			t.Errorf("VLAN = %d, want 702", onu.VLAN)
 		}
+		if onu.LastDownCause != types.DownCauseDyingGasp || onu.LastOfflineTime.IsZero() {
+			t.Errorf("last down = %q, %v", onu.LastDownCause, onu.LastOfflineTime)
+		}
 	})
 
 	t.Run("no CLI executor", func(t *testing.T) {
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"testing"
+
+	"github.com/nanoncore/nano-southbound/types"
 )
 
 type fakeSNMPExecutor struct {
@@ -44,7 +46,7 @@ func TestGetONUListSNMPLineProfile(t *testing.T) {
 		snmpExecutor: executor,
 	}
 
-	results, err := adapter.getONUListSNMP(context.Background())
+	results, err := adapter.getONUListSNMP(context.Background(), "")
 	if err != nil {
 		t.Fatalf("getONUListSNMP returned error: %v", err)
 	}
@@ -92,7 +94,7 @@ func TestGetONUListSNMPTrafficBytes(t *testing.T) {
 		snmpExecutor: executor,
 	}
 
-	results, err := adapter.getONUListSNMP(context.Background())
+	results, err := adapter.getONUListSNMP(context.Background(), "")
 	if err != nil {
 		t.Fatalf("getONUListSNMP returned error: %v", err)
 	}
@@ -108,3 +110,74 @@ func TestGetONUListSNMPTrafficBytes(t *testing.T) {
 		t.Errorf("BytesDown = %d, want 10000000", onu.BytesDown)
 	}
 }
+
+func TestGetONUListSNMPScope(t *testing.T) {
+	// Only the rows of PON index 1 are walked when the listing is scoped
+	executor := &fakeSNMPExecutor{
+		walks: map[string]map[string]interface{}{
+			OIDONUSerialNumber + ".1": {
+				".6": "FHTT00000001",
+				".2": "FHTT00000002",
+			},
+			OIDONUSerialNumber: {
+				".1.6": "FHTT00000001",
+				".1.2": "FHTT00000002",
+				".2.1": "FHTT00000003",
+			},
+		},
+	}
+	cli := &mockCLIExecutor{outputs: map[string]string{
+		"show onu sn FHTT00000001": "port: 0/1\nid: 6\n",
+		"show onu sn FHTT0000000F": "not found",
+	}}
+	adapter := &Adapter{
+		snmpExecutor: executor,
+		cliExecutor:  cli,
+		config:       &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "gpon"}},
+	}
+
+	results, err := adapter.GetONUList(context.Background(), &types.ONUFilter{PONPort: "0/1"})
+	if err != nil || len(results) != 2 {
+		t.Fatalf("GetONUList(PONPort) = %+v, %v", results, err)
+	}
+
+	// A complete serial is looked up to scope the walks to its port
+	results, err = adapter.GetONUList(context.Background(), &types.ONUFilter{Serial: "FHTT00000001"})
+	if err != nil || len(results) != 1 || results[0].PONPort != "0/1" || results[0].ONUID != 6 {
+		t.Fatalf("GetONUList(Serial) = %+v, %v", results, err)
+	}
+	if results, err := adapter.GetONUList(context.Background(), &types.ONUFilter{Serial: "FHTT0000000F"}); err != nil || len(results) != 0 {
+		t.Errorf("GetONUList(unknown serial) = %+v, %v", results, err)
+	}
+
+	// Paging sorts by port and ONU ID
+	results, err = adapter.GetONUList(context.Background(), &types.ONUFilter{Limit: 2})
+	if err != nil || len(results) != 2 || results[0].ONUID != 2 || results[1].ONUID != 6 {
+		t.Fatalf("GetONUList(Limit) = %+v, %v", results, err)
+	}
+	results, err = adapter.GetONUList(context.Background(), &types.ONUFilter{Limit: 2, After: types.ONUCursor(results[1])})
+	if err != nil || len(results) != 1 || results[0].PONPort != "0/2" {
+		t.Fatalf("GetONUList(After) = %+v, %v", results, err)
+	}
+}
+
+func TestGetONUStream(t *testing.T) {
+	executor := &fakeSNMPExecutor{
+		walks: map[string]map[string]interface{}{
+			OIDONUSerialNumber + ".1": {".6": "FHTT00000001"},
+			OIDONUSerialNumber + ".3": {".1": "FHTT00000003"},
+		},
+	}
+	adapter := &Adapter{snmpExecutor: executor, config: &types.EquipmentConfig{}}
+
+	var ports []string
+	for onu, err := range adapter.GetONUStream(context.Background(), nil) {
+		if err != nil {
+			t.Fatalf("GetONUStream() error = %v", err)
+		}
+		ports = append(ports, onu.PONPort)
+	}
+	if len(ports) != 2 || ports[0] != "0/1" || ports[1] != "0/3" {
+		t.Errorf("ports = %v", ports)
+	}
+}
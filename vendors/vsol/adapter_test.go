@@ -2,11 +2,13 @@ package vsol
 
 import (
 	"context"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/testutil"
 	"github.com/nanoncore/nano-southbound/types"
 )
 
@@ -290,9 +292,9 @@ func TestProvisionGPONWithConfirm(t *testing.T) {
 
 	assignedID, outputs, err := adapter.provisionGPONWithConfirm(
 		context.Background(),
+		types.NewTransaction("test", nil),
 		"0/1",
 		subscriber.Spec.ONUSerial,
-		subscriber.Spec.VLAN,
 		subscriber,
 	)
 	if err != nil {
@@ -1025,6 +1027,55 @@ onu 5 service-port 1 gemport 1 uservlan 100 vlan 100`,
 	}
 }
 
+func TestSuspendSubscriber_WalledGardenMode(t *testing.T) {
+	mock := &mockCLIExecutor{
+		outputs: map[string]string{
+			"show onu info all": `Onuindex         Model            Profile          Mode  AuthInfo
+---------------------------------------------------------------------------
+GPON0/1:5        AN5506-04-F1     AN5506-04-F1     sn    VSOL12345678`,
+			"show service-port all": `Index   VLAN  Interface  ONT-ID  GemPort  UserVLAN  TagTransform
+--------------------------------------------------------------
+1       100   0/1        5       1        100       translate`,
+		},
+	}
+	adapter := &Adapter{
+		cliExecutor: mock,
+		config: &types.EquipmentConfig{Metadata: map[string]string{
+			"pon_type":                     "gpon",
+			types.MetadataSuspendMode:      "walled-garden",
+			types.MetadataWalledGardenVLAN: "999",
+		}},
+	}
+	ctx := context.Background()
+
+	if err := adapter.SuspendSubscriber(ctx, "onu-0/1-5"); err != nil {
+		t.Fatalf("SuspendSubscriber() error: %v", err)
+	}
+	if slices.Contains(mock.commands, "onu 5 deactivate") || !slices.Contains(mock.commands, "onu 5 service INTERNET gemport 1 vlan 999 cos 0-7") {
+		t.Errorf("suspend commands = %v, want the ONU moved to VLAN 999", mock.commands)
+	}
+	if state, _ := adapter.GetSuspensionState(ctx, "onu-0/1-5"); state == nil || state.AppliedVLAN != 999 {
+		t.Fatalf("GetSuspensionState() = %+v", state)
+	}
+
+	mock.commands = nil
+	if err := adapter.ResumeSubscriber(ctx, "onu-0/1-5"); err != nil {
+		t.Fatalf("ResumeSubscriber() error: %v", err)
+	}
+	if slices.Contains(mock.commands, "onu 5 activate") || !slices.Contains(mock.commands, "onu 5 service INTERNET gemport 1 vlan 100 cos 0-7") {
+		t.Errorf("resume commands = %v, want the ONU back on VLAN 100", mock.commands)
+	}
+	if state, _ := adapter.GetSuspensionState(ctx, "onu-0/1-5"); state != nil {
+		t.Errorf("GetSuspensionState() after resume = %+v, want nil", state)
+	}
+
+	// Walled-garden mode without a VLAN is a configuration error
+	delete(adapter.config.Metadata, types.MetadataWalledGardenVLAN)
+	if err := adapter.SuspendSubscriber(ctx, "onu-0/1-5"); err == nil {
+		t.Error("SuspendSubscriber() without a walled-garden VLAN succeeded")
+	}
+}
+
 func TestSoftSuspendSubscriber_InvalidMode(t *testing.T) {
 	adapter := &Adapter{
 		cliExecutor: &mockCLIExecutor{outputs: map[string]string{}},
@@ -1345,6 +1396,62 @@ func TestListSubscriberONUs_ReturnsPlaceholder(t *testing.T) {
 	}
 }
 
+func TestFactoryResetONU(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		SequentialOutputs: map[string][]string{
+			"show onu state": {
+				"1/1/1:5     disable        disable       OffLine        1(GPON)",
+				"1/1/1:5     enable         enable        working        1(GPON)",
+			},
+		},
+	}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{}}
+
+	if _, err := adapter.FactoryResetONU(context.Background(), "0/1", 5, nil); err == nil {
+		t.Fatal("expected error without Force")
+	}
+	if len(mock.Commands) != 0 {
+		t.Fatalf("commands sent without Force: %v", mock.Commands)
+	}
+
+	opts := &types.FactoryResetOptions{Force: true, VerifyTimeout: time.Second, PollInterval: time.Millisecond}
+	result, err := adapter.FactoryResetONU(context.Background(), "0/1", 5, opts)
+	if err != nil {
+		t.Fatalf("FactoryResetONU() error = %v", err)
+	}
+	if !result.Success || !result.ResetSent || !result.Reregistered {
+		t.Errorf("result = %+v", result)
+	}
+	if !slices.Contains(mock.Commands, "onu 5 restore") {
+		t.Errorf("missing factory reset command in %v", mock.Commands)
+	}
+}
+
+func TestFactoryResetONU_SkipVerification(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{}}
+
+	opts := &types.FactoryResetOptions{Force: true, VerifyTimeout: -1}
+	result, err := adapter.FactoryResetONU(context.Background(), "0/1", 5, opts)
+	if err != nil {
+		t.Fatalf("FactoryResetONU() error = %v", err)
+	}
+	if !result.Success || result.Reregistered || slices.Contains(mock.Commands, "show onu state") {
+		t.Errorf("result = %+v, commands = %v", result, mock.Commands)
+	}
+}
+
 // Ensure unused imports are used
 var _ = types.DBAProfile{}
 var _ = strings.Contains
+
+func TestNewAdapterOptions(t *testing.T) {
+	cfg := &types.EquipmentConfig{Address: "10.0.0.1"}
+	adapter := NewAdapter(&mockDriverCLI{}, cfg, types.WithModel("v1600g4"), types.WithPONPortList("0/1", "0/2")).(*Adapter)
+	if got := adapter.detectModel(); got != "v1600g4" {
+		t.Errorf("detectModel() = %q, want v1600g4", got)
+	}
+	if got := adapter.getPONPortList(); !slices.Equal(got, []string{"0/1", "0/2"}) {
+		t.Errorf("getPONPortList() = %v", got)
+	}
+}
@@ -0,0 +1,31 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.AutoFindManager = (*Adapter)(nil)
+
+// SetAutoFind enables or disables ONU auto-find on a PON port and sets the
+// aging time of its auto-find entries, in the interface gpon view. GPON
+// only.
+func (a *Adapter) SetAutoFind(ctx context.Context, ponPort string, cfg *types.AutoFindConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "vsol"}
+	}
+	state := "disable"
+	if cfg.Enabled {
+		state = "enable"
+	}
+	cmds := []string{"onu auto-find " + state}
+	if cfg.AgingTime > 0 {
+		cmds = append(cmds, fmt.Sprintf("onu auto-find aging-time %d", int(cfg.AgingTime.Seconds())))
+	}
+	if err := a.execONUCommands(ctx, ponPort, cmds...); err != nil {
+		return fmt.Errorf("vsol auto-find failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package vsol
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestSetAutoFind(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{}}
+
+	if err := adapter.SetAutoFind(context.Background(), "0/2", &types.AutoFindConfig{Enabled: true, AgingTime: 5 * time.Minute}); err != nil {
+		t.Fatalf("SetAutoFind() error = %v", err)
+	}
+	want := []string{"configure terminal", "interface gpon 0/2", "onu auto-find enable", "onu auto-find aging-time 300", "exit", "exit"}
+	if !slices.Equal(mock.Commands, want) {
+		t.Errorf("commands = %v, want %v", mock.Commands, want)
+	}
+
+	mock.Outputs = map[string]string{"onu auto-find disable": "% Unknown command.\n"}
+	if err := adapter.SetAutoFind(context.Background(), "0/2", &types.AutoFindConfig{}); err == nil {
+		t.Error("expected error for a rejected command")
+	}
+
+	adapter.config = &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "epon"}}
+	if err := adapter.SetAutoFind(context.Background(), "0/2", &types.AutoFindConfig{Enabled: true}); err == nil {
+		t.Error("expected error on EPON")
+	}
+}
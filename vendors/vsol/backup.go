@@ -0,0 +1,34 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/drivers/cli"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.ConfigBackupManager = (*Adapter)(nil)
+
+// backupCommands only reads the running configuration: V-SOL has no
+// CLI command for TFTP transfer that works across firmware versions
+var backupCommands = cli.BackupCommands{
+	Show: "show running-config",
+}
+
+// BackupConfig returns the running configuration inline. URL destinations
+// are not supported.
+func (a *Adapter) BackupConfig(ctx context.Context, destination string) (*types.ConfigBackup, error) {
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available")
+	}
+	return cli.BackupConfig(ctx, a.cliExecutor, "vsol", backupCommands, destination)
+}
+
+// RestoreConfig is not supported over CLI and always returns an error
+func (a *Adapter) RestoreConfig(ctx context.Context, source string) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	return cli.RestoreConfig(ctx, a.cliExecutor, "vsol", backupCommands, source)
+}
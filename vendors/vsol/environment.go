@@ -0,0 +1,65 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
+)
+
+var _ types.EnvironmentMonitor = (*Adapter)(nil)
+
+var (
+	// reSysTemperature matches "Temperature: 45" in show sys temperature
+	reSysTemperature = regexp.MustCompile(`(?i)temperature[:\s]+(-?\d+(?:\.\d+)?)`)
+
+	// reSysFan matches a fan of show sys fan, e.g. "Fan 1    Normal    4200"
+	reSysFan = regexp.MustCompile(`(?im)^\s*fan\s*(\d+)\s*:?\s+([a-z_]+)(?:\s+(\d+))?`)
+)
+
+// GetEnvironment returns the system temperature, over SNMP if available,
+// and the fans of show sys fan. V-SOL does not report its power supplies.
+func (a *Adapter) GetEnvironment(ctx context.Context) (*types.EnvironmentStatus, error) {
+	if a.cliExecutor == nil && a.snmpExecutor == nil {
+		return nil, fmt.Errorf("no executor available (need CLI or SNMP)")
+	}
+	env := &types.EnvironmentStatus{Timestamp: time.Now(), Metadata: map[string]interface{}{}}
+
+	if a.snmpExecutor != nil {
+		results, err := a.snmpExecutor.BulkGetSNMP(ctx, []string{OIDVSOLTemperature})
+		if val, ok := common.GetSNMPResult(results, OIDVSOLTemperature); err == nil && ok {
+			if temp, ok := common.ParseIntSNMPValue(val); ok {
+				env.Temperatures = []types.TemperatureSensor{{Name: "system", Celsius: float64(temp)}}
+			}
+		}
+	}
+	if a.cliExecutor == nil {
+		return env, nil
+	}
+
+	// show sys commands are only available in config mode
+	_, _ = a.cliExecutor.ExecCommand(ctx, "configure terminal")
+	if len(env.Temperatures) == 0 {
+		if output, err := a.cliExecutor.ExecCommand(ctx, "show sys temperature"); err != nil {
+			env.Metadata["temperature_error"] = err.Error()
+		} else if m := reSysTemperature.FindStringSubmatch(output); m != nil {
+			celsius, _ := strconv.ParseFloat(m[1], 64)
+			env.Temperatures = []types.TemperatureSensor{{Name: "system", Celsius: celsius}}
+		}
+	}
+
+	if output, err := a.cliExecutor.ExecCommand(ctx, "show sys fan"); err != nil {
+		env.Metadata["fan_error"] = err.Error()
+	} else {
+		for _, m := range reSysFan.FindAllStringSubmatch(output, -1) {
+			rpm, _ := strconv.Atoi(m[3])
+			env.Fans = append(env.Fans, types.FanStatus{Name: m[1], Status: m[2], Normal: strings.EqualFold(m[2], "normal"), SpeedRPM: rpm})
+		}
+	}
+	return env, nil
+}
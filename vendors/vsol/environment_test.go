@@ -0,0 +1,42 @@
+package vsol
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestGetEnvironment(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"show sys temperature": "Temperature: 47",
+		"show sys fan":         "Fan 1    Normal    4200\nFan 2    Stop      0\n",
+	}}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{}}
+
+	env, err := adapter.GetEnvironment(context.Background())
+	if err != nil {
+		t.Fatalf("GetEnvironment() error = %v", err)
+	}
+	if want := []types.TemperatureSensor{{Name: "system", Celsius: 47}}; !reflect.DeepEqual(env.Temperatures, want) {
+		t.Errorf("Temperatures = %+v, want %+v", env.Temperatures, want)
+	}
+	wantFans := []types.FanStatus{{Name: "1", Status: "Normal", Normal: true, SpeedRPM: 4200}, {Name: "2", Status: "Stop"}}
+	if !reflect.DeepEqual(env.Fans, wantFans) {
+		t.Errorf("Fans = %+v, want %+v", env.Fans, wantFans)
+	}
+
+	// The temperature is read over SNMP when available
+	mock.Commands = nil
+	adapter.snmpExecutor = &testutil.MockSNMPExecutor{BulkGetResults: map[string]interface{}{OIDVSOLTemperature: 39}}
+	env, err = adapter.GetEnvironment(context.Background())
+	if err != nil || len(env.Temperatures) != 1 || env.Temperatures[0].Celsius != 39 {
+		t.Errorf("GetEnvironment() = %+v, %v", env, err)
+	}
+	if slices.Contains(mock.Commands, "show sys temperature") {
+		t.Errorf("commands = %v", mock.Commands)
+	}
+}
@@ -0,0 +1,162 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/drivers/cli"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var (
+	_ types.FirmwareManager    = (*Adapter)(nil)
+	_ types.ONUFirmwareManager = (*Adapter)(nil)
+)
+
+// ONU upgrade state from "show onu <id> upgrade-status", e.g.
+// "Upgrade status: upgrading" and "Progress: 45%"
+var (
+	reONUUpgradeStatus   = regexp.MustCompile(`(?i)upgrade status\s*:\s*(\S+)`)
+	reONUUpgradeProgress = regexp.MustCompile(`(?i)progress\s*:\s*(\d+)`)
+	reONUUpgradeReason   = regexp.MustCompile(`(?im)fail(?:ure)? reason\s*:\s*(.+?)\s*$`)
+)
+
+// FirmwareVersion returns the software version from show version
+func (a *Adapter) FirmwareVersion(ctx context.Context) (string, error) {
+	if a.cliExecutor == nil {
+		return "", fmt.Errorf("CLI executor not available")
+	}
+	// show version is only available in config mode
+	_, _ = a.cliExecutor.ExecCommand(ctx, "configure terminal")
+	output, err := a.cliExecutor.ExecCommand(ctx, "show version")
+	if err != nil {
+		return "", fmt.Errorf("vsol show version failed: %w", err)
+	}
+	match := reTelemetrySoftwareVer.FindStringSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("vsol software version not found in show version output")
+	}
+	return match[1], nil
+}
+
+// StageFirmware downloads the system image from a tftp:// URL. V-SOL
+// writes it to the backup partition and boots it on the next reboot.
+func (a *Adapter) StageFirmware(ctx context.Context, image *types.FirmwareImage) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	t, err := cli.ParseTransferURL(image.URL, "tftp")
+	if err != nil {
+		return fmt.Errorf("vsol firmware staging failed: %w", err)
+	}
+	_, _ = a.cliExecutor.ExecCommand(ctx, "configure terminal")
+	cmd := fmt.Sprintf("upgrade system tftp %s %s", t.Server, t.File)
+	if err := cli.ExecTransfer(ctx, a.cliExecutor, cmd); err != nil {
+		return fmt.Errorf("vsol firmware staging failed: %w", err)
+	}
+	return nil
+}
+
+// ActivateFirmware is a no-op: a staged image is booted on the next reboot
+func (a *Adapter) ActivateFirmware(ctx context.Context, image *types.FirmwareImage) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	return nil
+}
+
+// UploadONUFirmware downloads an ONU image from a tftp:// URL to the OLT's
+// ONU image storage. GPON only.
+func (a *Adapter) UploadONUFirmware(ctx context.Context, image *types.FirmwareImage) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	if a.detectPONType() != "gpon" {
+		return fmt.Errorf("vsol ONU firmware upgrade is only supported on GPON")
+	}
+	t, err := cli.ParseTransferURL(image.URL, "tftp")
+	if err != nil {
+		return fmt.Errorf("vsol ONU image upload failed: %w", err)
+	}
+	_, _ = a.cliExecutor.ExecCommand(ctx, "configure terminal")
+	cmd := fmt.Sprintf("load onu-image tftp %s %s", t.Server, t.File)
+	if err := cli.ExecTransfer(ctx, a.cliExecutor, cmd); err != nil {
+		return fmt.Errorf("vsol ONU image upload failed: %w", err)
+	}
+	return nil
+}
+
+// UpgradeONUFirmware starts the OMCI upgrade of one ONU with the uploaded
+// image, which is referenced by its file name
+func (a *Adapter) UpgradeONUFirmware(ctx context.Context, ponPort string, onuID int, image *types.FirmwareImage) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	t, err := cli.ParseTransferURL(image.URL, "tftp")
+	if err != nil {
+		return fmt.Errorf("vsol ONU upgrade failed: %w", err)
+	}
+	commands := []string{
+		"configure terminal",
+		fmt.Sprintf("interface gpon %s", ponPort),
+		fmt.Sprintf("onu %d upgrade %s", onuID, path.Base(t.File)),
+		"exit",
+		"exit",
+	}
+	if _, err := a.cliExecutor.ExecCommands(ctx, commands); err != nil {
+		return fmt.Errorf("vsol ONU upgrade failed: %w", err)
+	}
+	return nil
+}
+
+// GetONUFirmwareStatus reads the running ONU software version and the
+// state of its last upgrade
+func (a *Adapter) GetONUFirmwareStatus(ctx context.Context, ponPort string, onuID int) (*types.ONUFirmwareStatus, error) {
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available")
+	}
+	outputs, err := a.cliExecutor.ExecCommands(ctx, []string{
+		"configure terminal",
+		fmt.Sprintf("interface gpon %s", ponPort),
+		fmt.Sprintf("show onu %d version", onuID),
+		fmt.Sprintf("show onu %d upgrade-status", onuID),
+		"exit",
+		"exit",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("vsol ONU firmware status failed: %w", err)
+	}
+	if len(outputs) < 4 {
+		return nil, fmt.Errorf("vsol ONU firmware status failed: missing command output")
+	}
+	return parseONUFirmwareStatus(ponPort, onuID, outputs[2], outputs[3]), nil
+}
+
+// parseONUFirmwareStatus parses show onu version and upgrade-status output
+func parseONUFirmwareStatus(ponPort string, onuID int, versionOutput, upgradeOutput string) *types.ONUFirmwareStatus {
+	status := &types.ONUFirmwareStatus{PONPort: ponPort, ONUID: onuID, State: types.ONUUpgradeIdle}
+	if m := reTelemetrySoftwareVer.FindStringSubmatch(versionOutput); m != nil {
+		status.Version = m[1]
+	}
+	if m := reONUUpgradeProgress.FindStringSubmatch(upgradeOutput); m != nil {
+		status.Percent, _ = strconv.Atoi(m[1])
+	}
+	if m := reONUUpgradeStatus.FindStringSubmatch(upgradeOutput); m != nil {
+		switch strings.ToLower(m[1]) {
+		case "upgrading", "downloading", "activating":
+			status.State = types.ONUUpgradeInProgress
+		case "success", "complete", "completed":
+			status.State = types.ONUUpgradeSucceeded
+		case "fail", "failed":
+			status.State = types.ONUUpgradeFailed
+			if r := reONUUpgradeReason.FindStringSubmatch(upgradeOutput); r != nil {
+				status.Message = r[1]
+			}
+		}
+	}
+	return status
+}
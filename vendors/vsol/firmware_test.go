@@ -0,0 +1,61 @@
+package vsol
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestParseONUFirmwareStatus(t *testing.T) {
+	version := "Onu Type:          V2802GW\nSoftware Version:  V1.1.2\nHardware Version:  V1.0\n"
+
+	tests := []struct {
+		name        string
+		upgrade     string
+		wantState   string
+		wantPercent int
+		wantMessage string
+	}{
+		{"idle", "Upgrade status: idle\n", types.ONUUpgradeIdle, 0, ""},
+		{"upgrading", "Upgrade status: upgrading\nProgress: 60%\n", types.ONUUpgradeInProgress, 60, ""},
+		{"success", "Upgrade status: success\nProgress: 100%\n", types.ONUUpgradeSucceeded, 100, ""},
+		{"failed", "Upgrade status: failed\nFail reason: image mismatch\n", types.ONUUpgradeFailed, 0, "image mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := parseONUFirmwareStatus("0/1", 7, version, tt.upgrade)
+			if status.Version != "V1.1.2" {
+				t.Errorf("Version = %q", status.Version)
+			}
+			if status.State != tt.wantState || status.Percent != tt.wantPercent || status.Message != tt.wantMessage {
+				t.Errorf("status = %+v", status)
+			}
+		})
+	}
+}
+
+func TestUpgradeONUFirmware(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{}}
+
+	image := &types.FirmwareImage{Version: "V1.2.0", URL: "tftp://192.0.2.10/onu/V2802GW-V1.2.0.bin"}
+	if err := adapter.UploadONUFirmware(context.Background(), image); err != nil {
+		t.Fatalf("UploadONUFirmware() error = %v", err)
+	}
+	if err := adapter.UpgradeONUFirmware(context.Background(), "0/1", 7, image); err != nil {
+		t.Fatalf("UpgradeONUFirmware() error = %v", err)
+	}
+	for _, want := range []string{"load onu-image tftp 192.0.2.10 onu/V2802GW-V1.2.0.bin", "interface gpon 0/1", "onu 7 upgrade V2802GW-V1.2.0.bin"} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	epon := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "epon"}}}
+	if err := epon.UploadONUFirmware(context.Background(), image); err == nil {
+		t.Error("expected error on EPON")
+	}
+}
@@ -0,0 +1,70 @@
+package vsol
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+// TestGolden runs the parsers over the captured output in testdata/golden.
+// Timestamps the parsers stamp with time.Now are cleared.
+func TestGolden(t *testing.T) {
+	adapter := &Adapter{}
+	testutil.RunGolden(t, "testdata/golden", map[string]testutil.GoldenParser{
+		"parseV1600ONUList": func(_ *testing.T, in []byte) any {
+			return adapter.parseV1600ONUList(string(in), "")
+		},
+		"parseONUState": func(_ *testing.T, in []byte) any {
+			return adapter.parseONUState(string(in))
+		},
+		"parseONUOpticalInfo": func(_ *testing.T, in []byte) any {
+			return adapter.parseONUOpticalInfo(string(in))
+		},
+		"parseONUStatistics": func(_ *testing.T, in []byte) any {
+			return adapter.parseONUStatistics(string(in))
+		},
+		"parseVLANList": func(_ *testing.T, in []byte) any {
+			return adapter.parseVLANList(string(in))
+		},
+		"parseServicePortList": func(_ *testing.T, in []byte) any {
+			return adapter.parseServicePortList(string(in))
+		},
+		"parseAlarms": func(_ *testing.T, in []byte) any {
+			return adapter.parseAlarms(string(in))
+		},
+		"parseAutofindOutput": func(_ *testing.T, in []byte) any {
+			discoveries := adapter.parseAutofindOutput(string(in))
+			for i := range discoveries {
+				discoveries[i].DiscoveredAt = time.Time{}
+			}
+			return discoveries
+		},
+		"getONUListSNMP": func(t *testing.T, in []byte) any {
+			mib, err := testutil.ParseSNMPWalk(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a := &Adapter{snmpExecutor: &testutil.MockSNMPExecutor{MIB: mib}}
+			onus, err := a.getONUListSNMP(context.Background(), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			sortONUs(onus)
+			return onus
+		},
+	})
+}
+
+// sortONUs orders ONUs correlated from SNMP tables, which come unordered
+func sortONUs(onus []types.ONUInfo) {
+	sort.Slice(onus, func(i, j int) bool {
+		if onus[i].PONPort != onus[j].PONPort {
+			return onus[i].PONPort < onus[j].PONPort
+		}
+		return onus[i].ONUID < onus[j].ONUID
+	})
+}
@@ -0,0 +1,81 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var (
+	_ types.BoardInventory       = (*Adapter)(nil)
+	_ types.TransceiverInventory = (*Adapter)(nil)
+)
+
+// reHardwareVersion matches "Hardware Version:            V1.0" in show
+// version
+var reHardwareVersion = regexp.MustCompile(`(?i)hardware version[:\s]+(\S+)`)
+
+// GetBoards returns the single board of the V-SOL pizza-box OLT, with the
+// serial number and versions of show version
+func (a *Adapter) GetBoards(ctx context.Context) ([]types.BoardInfo, error) {
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available")
+	}
+	// show version is only available in config mode
+	_, _ = a.cliExecutor.ExecCommand(ctx, "configure terminal")
+	output, err := a.cliExecutor.ExecCommand(ctx, "show version")
+	if err != nil {
+		return nil, fmt.Errorf("vsol show version failed: %w", err)
+	}
+
+	board := types.BoardInfo{
+		Slot:   "0",
+		Type:   strings.ToUpper(a.detectModel()),
+		Status: "normal",
+		Online: true,
+		Ports:  len(a.getPONPortList()),
+	}
+	if m := reTelemetrySerialNum.FindStringSubmatch(output); m != nil {
+		board.SerialNumber = m[1]
+	}
+	if m := reTelemetrySoftwareVer.FindStringSubmatch(output); m != nil {
+		board.Firmware = m[1]
+	}
+	if m := reHardwareVersion.FindStringSubmatch(output); m != nil {
+		board.Hardware = m[1]
+	}
+	return []types.BoardInfo{board}, nil
+}
+
+// GetTransceivers returns the PON optical modules, read like GetPONPower.
+// V-SOL reports no vendor data of the modules, and the uplink modules are
+// not read. Ports whose module cannot be read are left out.
+func (a *Adapter) GetTransceivers(ctx context.Context) ([]types.TransceiverInfo, error) {
+	if a.cliExecutor == nil && a.snmpExecutor == nil {
+		return nil, fmt.Errorf("no executor available (need CLI or SNMP)")
+	}
+	transceivers := []types.TransceiverInfo{}
+	for _, port := range a.getPONPortList() {
+		reading, err := a.GetPONPower(ctx, port)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			a.config.Log().Warn("V-SOL: PON module not read", "port", port, "error", err)
+			continue
+		}
+		transceivers = append(transceivers, types.TransceiverInfo{
+			Port:         port,
+			PortType:     types.TransceiverPortPON,
+			Present:      reading.TxPowerDBm != 0 || reading.Temperature != 0,
+			Type:         strings.ToUpper(a.detectPONType()),
+			TemperatureC: reading.Temperature,
+			TxPowerDBm:   reading.TxPowerDBm,
+			RxPowerDBm:   reading.RxPowerDBm,
+		})
+	}
+	return transceivers, nil
+}
@@ -0,0 +1,51 @@
+package vsol
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestGetBoards(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"show version": "Olt Serial Number:           V2104230071\nSoftware Version:            V2.1.6R\nHardware Version:            V1.2\n",
+	}}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"model": "v1600g2"}}}
+
+	boards, err := adapter.GetBoards(context.Background())
+	if err != nil {
+		t.Fatalf("GetBoards() error = %v", err)
+	}
+	want := types.BoardInfo{Slot: "0", Type: "V1600G2", Status: "normal", Online: true, SerialNumber: "V2104230071", Hardware: "V1.2", Firmware: "V2.1.6R", Ports: 8}
+	if len(boards) != 1 || !reflect.DeepEqual(boards[0], want) {
+		t.Errorf("GetBoards() = %+v, want %+v", boards, want)
+	}
+}
+
+func TestGetTransceivers(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{"show pon optical gpon 0/1": "tx power: 3.5\nrx power: -12.3\ntemp: 42.5"},
+		Errors:  map[string]error{"show pon optical gpon 0/2": errors.New("timeout")},
+	}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "gpon"}}}
+
+	transceivers, err := adapter.GetTransceivers(context.Background())
+	if err != nil {
+		t.Fatalf("GetTransceivers() error = %v", err)
+	}
+	// Port 0/2 could not be read
+	if len(transceivers) != 7 {
+		t.Fatalf("GetTransceivers() = %+v, want 7 modules", transceivers)
+	}
+	if tr := transceivers[0]; tr.Port != "0/1" || tr.PortType != types.TransceiverPortPON || !tr.Present || tr.Type != "GPON" ||
+		tr.TxPowerDBm != 3.5 || tr.RxPowerDBm != -12.3 || tr.TemperatureC != 42.5 {
+		t.Errorf("transceivers[0] = %+v", tr)
+	}
+	if tr := transceivers[1]; tr.Port != "0/3" || tr.Present {
+		t.Errorf("transceivers[1] = %+v", tr)
+	}
+}
@@ -0,0 +1,36 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.ONULANManager = (*Adapter)(nil)
+
+// SetONULANConfig sets the LAN address and DHCP server of an ONU with the
+// PRI command set. GPON only.
+func (a *Adapter) SetONULANConfig(ctx context.Context, ponPort string, onuID int, cfg *types.ONULANConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "vsol"}
+	}
+	if err := a.execONUCommands(ctx, ponPort, lanCommands(onuID, cfg)...); err != nil {
+		return fmt.Errorf("vsol ONU LAN configuration failed: %w", err)
+	}
+	return nil
+}
+
+// lanCommands returns the interface gpon commands applying cfg
+func lanCommands(onuID int, cfg *types.ONULANConfig) []string {
+	dhcp := fmt.Sprintf("onu %d pri lan_dhcp disable", onuID)
+	if cfg.DHCPServer {
+		// PRI firmware takes the lease time in seconds
+		dhcp = fmt.Sprintf("onu %d pri lan_dhcp enable start %s end %s lease %d",
+			onuID, cfg.DHCPStart, cfg.DHCPEnd, int(cfg.Lease().Seconds()))
+	}
+	return []string{
+		fmt.Sprintf("onu %d pri lan_ip %s mask %s", onuID, cfg.IPAddress, cfg.Netmask),
+		dhcp,
+	}
+}
@@ -0,0 +1,41 @@
+package vsol
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestSetONULANConfig(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{}}
+
+	cfg := &types.ONULANConfig{
+		IPAddress:  "192.168.1.1",
+		Netmask:    "255.255.255.0",
+		DHCPServer: true,
+		DHCPStart:  "192.168.1.100",
+		DHCPEnd:    "192.168.1.200",
+		LeaseTime:  2 * time.Hour,
+	}
+	if err := adapter.SetONULANConfig(context.Background(), "0/1", 7, cfg); err != nil {
+		t.Fatalf("SetONULANConfig() error = %v", err)
+	}
+	for _, want := range []string{
+		"onu 7 pri lan_ip 192.168.1.1 mask 255.255.255.0",
+		"onu 7 pri lan_dhcp enable start 192.168.1.100 end 192.168.1.200 lease 7200",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	cfg.DHCPEnd = "192.168.2.10"
+	if err := adapter.SetONULANConfig(context.Background(), "0/1", 7, cfg); err == nil {
+		t.Error("DHCP pool outside the subnet should be rejected")
+	}
+}
@@ -0,0 +1,44 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
+)
+
+var _ types.LineQualityReader = (*Adapter)(nil)
+
+// GetPONLineQuality returns the upstream BIP and FEC counters of a PON port
+// from show pon statistics.
+func (a *Adapter) GetPONLineQuality(ctx context.Context, ponPort string) (*types.LineQuality, error) {
+	cmd := fmt.Sprintf("show pon statistics %s %s", a.detectPONType(), ponPort)
+	return a.readLineQuality(ctx, cmd)
+}
+
+// GetONULineQuality returns the upstream BIP and FEC counters of an ONU
+// from the ONU statistics also read by GetSubscriberStats
+func (a *Adapter) GetONULineQuality(ctx context.Context, ponPort string, onuID int) (*types.LineQuality, error) {
+	cmd := fmt.Sprintf("show onu statistics gpon %s %d", ponPort, onuID)
+	if a.detectPONType() != "gpon" {
+		cmd = fmt.Sprintf("show llid statistics epon %s %d", ponPort, onuID)
+	}
+	return a.readLineQuality(ctx, cmd)
+}
+
+// readLineQuality runs a statistics command and parses its counters
+func (a *Adapter) readLineQuality(ctx context.Context, cmd string) (*types.LineQuality, error) {
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available")
+	}
+	output, err := a.cliExecutor.ExecCommand(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("vsol %s failed: %w", cmd, err)
+	}
+	q, found := common.ParseLineQuality(output)
+	if !found {
+		return nil, fmt.Errorf("no line quality counters in %s output", cmd)
+	}
+	return q, nil
+}
@@ -0,0 +1,34 @@
+package vsol
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestGetLineQuality(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{Outputs: map[string]string{
+		"show pon statistics gpon 0/1":   "BIP8 errors: 12\nFEC corrected codewords: 340\nFEC uncorrectable codewords: 2\n",
+		"show onu statistics gpon 0/1 7": "Rx Bytes: 1000\nTx Bytes: 2000\nUpstream BIP errors: 3\n",
+		"show pon statistics gpon 0/2":   "Rx Bytes: 1000\n",
+	}}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{}}
+
+	q, err := adapter.GetPONLineQuality(context.Background(), "0/1")
+	if err != nil {
+		t.Fatalf("GetPONLineQuality() error = %v", err)
+	}
+	if *q != (types.LineQuality{BIPErrors: 12, FECCorrected: 340, FECUncorrectable: 2}) {
+		t.Errorf("GetPONLineQuality() = %+v", q)
+	}
+
+	if q, err := adapter.GetONULineQuality(context.Background(), "0/1", 7); err != nil || q.BIPErrors != 3 {
+		t.Errorf("GetONULineQuality() = %+v, %v", q, err)
+	}
+
+	if _, err := adapter.GetPONLineQuality(context.Background(), "0/2"); err == nil {
+		t.Error("GetPONLineQuality() without counters should fail")
+	}
+}
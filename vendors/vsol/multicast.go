@@ -0,0 +1,30 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/model"
+)
+
+// setONUMulticast joins an ONU GEM port to the multicast VLAN, with an
+// optional IGMP profile and channel limit. The multicast VLAN and IGMP
+// profile must exist on the OLT. GPON only.
+func (a *Adapter) setONUMulticast(ctx context.Context, ponPort string, onuID int, m *model.MulticastService) error {
+	if err := a.execONUCommands(ctx, ponPort, multicastCommands(onuID, m)...); err != nil {
+		return fmt.Errorf("vsol ONU multicast configuration failed: %w", err)
+	}
+	return nil
+}
+
+// multicastCommands returns the interface gpon commands applying m
+func multicastCommands(onuID int, m *model.MulticastService) []string {
+	commands := []string{fmt.Sprintf("onu %d multicast vlan %d gemport %d", onuID, m.VLAN, m.GetGEMPort())}
+	if m.Profile != "" {
+		commands = append(commands, fmt.Sprintf("onu %d multicast profile %s", onuID, m.Profile))
+	}
+	if m.MaxGroups > 0 {
+		commands = append(commands, fmt.Sprintf("onu %d multicast max-group %d", onuID, m.MaxGroups))
+	}
+	return commands
+}
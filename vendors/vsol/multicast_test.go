@@ -0,0 +1,40 @@
+package vsol
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestCreateSubscriberWithMulticast(t *testing.T) {
+	sub := &model.Subscriber{
+		Name:        "test-sub",
+		Annotations: map[string]string{"nanoncore.com/pon-port": "0/1", "nanoncore.com/onu-id": "5"},
+		Spec: model.SubscriberSpec{ONUSerial: "FHTT12345678", VLAN: 100,
+			Multicast: &model.MulticastService{VLAN: 1000, Profile: "iptv", MaxGroups: 4}},
+	}
+
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "gpon"}}}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	for _, want := range []string{
+		"onu 5 multicast vlan 1000 gemport 1",
+		"onu 5 multicast profile iptv",
+		"onu 5 multicast max-group 4",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	adapter = &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "epon"}}}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err == nil {
+		t.Error("multicast on EPON should be rejected")
+	}
+}
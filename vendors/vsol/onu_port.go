@@ -0,0 +1,103 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var (
+	_ types.ONUEthPortManager = (*Adapter)(nil)
+	_ types.ONUCATVManager    = (*Adapter)(nil)
+)
+
+// SetONUEthPortState enables or disables an ONU Ethernet port. GPON only.
+func (a *Adapter) SetONUEthPortState(ctx context.Context, ponPort string, onuID, port int, enabled bool) error {
+	state := "disable"
+	if enabled {
+		state = "enable"
+	}
+	if err := a.execONUCommands(ctx, ponPort, fmt.Sprintf("onu %d port eth %d state %s", onuID, port, state)); err != nil {
+		return fmt.Errorf("vsol ONU port state failed: %w", err)
+	}
+	return nil
+}
+
+// SetONUCATVState enables or disables the CATV RF port of a combo ONU. GPON
+// only.
+func (a *Adapter) SetONUCATVState(ctx context.Context, ponPort string, onuID int, enabled bool) error {
+	if err := a.execONUCommands(ctx, ponPort, catvCommand(onuID, enabled)); err != nil {
+		return fmt.Errorf("vsol ONU CATV state failed: %w", err)
+	}
+	return nil
+}
+
+// catvCommand switches the CATV RF port of an ONU
+func catvCommand(onuID int, enabled bool) string {
+	state := "disable"
+	if enabled {
+		state = "enable"
+	}
+	return fmt.Sprintf("onu %d port catv state %s", onuID, state)
+}
+
+// SetONUEthPortVLAN sets the VLAN mode of an ONU Ethernet port with onu
+// portvlan. Access ports use tag mode, as in provisioning. GPON only.
+func (a *Adapter) SetONUEthPortVLAN(ctx context.Context, ponPort string, onuID, port int, vlan *types.ONUPortVLAN) error {
+	if err := vlan.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "vsol"}
+	}
+	var cmd string
+	switch vlan.Mode {
+	case types.ONUPortVLANAccess:
+		cmd = fmt.Sprintf("onu %d portvlan eth %d mode tag vlan %d", onuID, port, vlan.VLAN)
+	case types.ONUPortVLANTrunk:
+		vlans := make([]string, len(vlan.TrunkVLANs))
+		for i, v := range vlan.TrunkVLANs {
+			vlans[i] = strconv.Itoa(v)
+		}
+		cmd = fmt.Sprintf("onu %d portvlan eth %d mode trunk vlan %s", onuID, port, strings.Join(vlans, ","))
+		if vlan.VLAN > 0 {
+			cmd += fmt.Sprintf(" native %d", vlan.VLAN)
+		}
+	case types.ONUPortVLANTransparent:
+		cmd = fmt.Sprintf("onu %d portvlan eth %d mode transparent", onuID, port)
+	}
+	if err := a.execONUCommands(ctx, ponPort, cmd); err != nil {
+		return fmt.Errorf("vsol ONU port VLAN failed: %w", err)
+	}
+	return nil
+}
+
+// execONUCommands runs ONU commands in the interface gpon view of
+// ponPort, failing at the first one the CLI rejects. GPON only.
+func (a *Adapter) execONUCommands(ctx context.Context, ponPort string, cmds ...string) error {
+	if a.cliExecutor == nil {
+		return fmt.Errorf("CLI executor not available")
+	}
+	if a.detectPONType() != "gpon" {
+		return fmt.Errorf("ONU configuration is only supported on GPON")
+	}
+	commands := append([]string{"configure terminal", fmt.Sprintf("interface gpon %s", ponPort)}, cmds...)
+	outputs, err := a.cliExecutor.ExecCommands(ctx, append(commands, "exit", "exit"))
+	if err != nil {
+		return err
+	}
+	for i := 2; i < len(outputs) && i < len(commands); i++ {
+		if !hasCLIErrorMarker(outputs[i]) {
+			continue
+		}
+		// The code comes from the device message alone, as the command
+		// names the ONU whatever failed
+		msg := firstLine(outputs[i])
+		err := fmt.Errorf("%s: %s", commands[i], msg)
+		if code := types.CodeForMessage(msg); code != types.ErrCodeUnknown {
+			return &types.HumanError{Code: code, Message: err.Error(), Vendor: "vsol", Recoverable: code == types.ErrCodeConfigLocked, Err: err}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package vsol
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestSetONUEthPortVLAN(t *testing.T) {
+	tests := []struct {
+		name string
+		vlan *types.ONUPortVLAN
+		want string
+	}{
+		{"access", &types.ONUPortVLAN{Mode: types.ONUPortVLANAccess, VLAN: 100}, "onu 7 portvlan eth 1 mode tag vlan 100"},
+		{"trunk", &types.ONUPortVLAN{Mode: types.ONUPortVLANTrunk, TrunkVLANs: []int{100, 200}}, "onu 7 portvlan eth 1 mode trunk vlan 100,200"},
+		{"trunk native", &types.ONUPortVLAN{Mode: types.ONUPortVLANTrunk, VLAN: 10, TrunkVLANs: []int{100}}, "onu 7 portvlan eth 1 mode trunk vlan 100 native 10"},
+		{"transparent", &types.ONUPortVLAN{Mode: types.ONUPortVLANTransparent}, "onu 7 portvlan eth 1 mode transparent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &testutil.MockCLIExecutor{}
+			adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{}}
+			if err := adapter.SetONUEthPortVLAN(context.Background(), "0/1", 7, 1, tt.vlan); err != nil {
+				t.Fatalf("SetONUEthPortVLAN() error = %v", err)
+			}
+			if !slices.Contains(mock.Commands, tt.want) {
+				t.Errorf("commands = %v, want %q", mock.Commands, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetONUEthPortState(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{"onu 7 port eth 5 state disable": "% Error: port 5 does not exist\n"},
+	}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{}}
+
+	if err := adapter.SetONUEthPortState(context.Background(), "0/1", 7, 1, false); err != nil {
+		t.Fatalf("SetONUEthPortState() error = %v", err)
+	}
+	if !slices.Contains(mock.Commands, "onu 7 port eth 1 state disable") {
+		t.Errorf("commands = %v", mock.Commands)
+	}
+	if err := adapter.SetONUEthPortState(context.Background(), "0/1", 7, 5, false); !errors.Is(err, types.ErrPortNotFound) {
+		t.Errorf("rejected command error = %v, want types.ErrPortNotFound", err)
+	}
+
+	epon := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "epon"}}}
+	if err := epon.SetONUEthPortState(context.Background(), "0/1", 7, 1, true); err == nil {
+		t.Error("EPON should be rejected")
+	}
+}
+
+func TestCreateSubscriberWithCATV(t *testing.T) {
+	enabled := true
+	sub := &model.Subscriber{
+		Name:        "test-sub",
+		Annotations: map[string]string{"nanoncore.com/pon-port": "0/1", "nanoncore.com/onu-id": "5"},
+		Spec:        model.SubscriberSpec{ONUSerial: "FHTT12345678", VLAN: 100, CATV: &enabled},
+	}
+
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "gpon"}}}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	if !slices.Contains(mock.Commands, "onu 5 port catv state enable") {
+		t.Errorf("CATV not enabled: %v", mock.Commands)
+	}
+
+	mock.Commands = nil
+	enabled = false
+	if err := adapter.UpdateSubscriber(context.Background(), sub, &model.ServiceTier{}); err != nil {
+		t.Fatalf("UpdateSubscriber() error = %v", err)
+	}
+	if !slices.Contains(mock.Commands, "onu 5 port catv state disable") {
+		t.Errorf("CATV not disabled: %v", mock.Commands)
+	}
+
+	adapter = &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "epon"}}}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err == nil {
+		t.Error("CATV on EPON should be rejected")
+	}
+}
@@ -0,0 +1,21 @@
+package vsol
+
+import (
+	"context"
+	"iter"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.ONUStreamer = (*Adapter)(nil)
+
+// GetONUStream yields the ONUs of GetONUList one PON port at a time. EPON
+// OLTs managed over the CLI list all LLIDs with one command, so their ONUs
+// are read at once.
+func (a *Adapter) GetONUStream(ctx context.Context, filter *types.ONUFilter) iter.Seq2[types.ONUInfo, error] {
+	ports := a.getPONPortList()
+	if a.detectPONType() != "gpon" && (a.snmpExecutor == nil || a.preferCLI()) {
+		ports = nil
+	}
+	return types.StreamONUsByPort(ctx, ports, filter, a.GetONUList)
+}
@@ -0,0 +1,60 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
+)
+
+var _ types.ONUTester = (*Adapter)(nil)
+
+// RunONUTest runs the OMCI self-test of an ONU, or a loopback on one of its
+// Ethernet ports. GPON only.
+func (a *Adapter) RunONUTest(ctx context.Context, ponPort string, onuID int, test types.ONUTest) (*types.ONUTestResult, error) {
+	if err := test.Validate(); err != nil {
+		return nil, &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "vsol"}
+	}
+	if a.cliExecutor == nil {
+		return nil, fmt.Errorf("CLI executor not available")
+	}
+	if a.detectPONType() != "gpon" {
+		return nil, fmt.Errorf("ONU tests are only supported on GPON")
+	}
+	cmd := fmt.Sprintf("onu %d selftest", onuID)
+	if test.Kind == types.ONUTestLoopback {
+		cmd = fmt.Sprintf("onu %d port eth %d loopback-test", onuID, test.Port)
+	}
+	// Not runONUCommands: the output of a failed test would be taken for
+	// a rejected command
+	outputs, err := a.cliExecutor.ExecCommands(ctx, []string{
+		"configure terminal",
+		fmt.Sprintf("interface gpon %s", ponPort),
+		cmd,
+		"exit",
+		"exit",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("vsol ONU %s failed: %w", test.Kind, err)
+	}
+	if len(outputs) < 3 {
+		return nil, fmt.Errorf("vsol ONU %s failed: missing command output", test.Kind)
+	}
+	output := outputs[2]
+	detail, passed, found := common.ParseTestResult(output)
+	if !found {
+		if hasCLIErrorMarker(output) {
+			return nil, fmt.Errorf("vsol ONU %s failed: %s", test.Kind, firstLine(output))
+		}
+		return nil, fmt.Errorf("vsol ONU %s returned no result", test.Kind)
+	}
+	return &types.ONUTestResult{
+		ONUTest:   test,
+		Passed:    passed,
+		Detail:    detail,
+		Raw:       output,
+		Timestamp: time.Now(),
+	}, nil
+}
@@ -0,0 +1,43 @@
+package vsol
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestRunONUTest(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{
+		Outputs: map[string]string{
+			"onu 5 selftest":                 "Result: OK\n",
+			"onu 5 port eth 1 loopback-test": "Result: failed\n",
+		},
+	}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{}}
+
+	res, err := adapter.RunONUTest(context.Background(), "0/1", 5, types.ONUTest{Kind: types.ONUTestSelfTest})
+	if err != nil {
+		t.Fatalf("RunONUTest() error = %v", err)
+	}
+	if !res.Passed {
+		t.Errorf("self-test result = %+v", res)
+	}
+
+	res, err = adapter.RunONUTest(context.Background(), "0/1", 5, types.ONUTest{Kind: types.ONUTestLoopback, Port: 1})
+	if err != nil {
+		t.Fatalf("RunONUTest() error = %v", err)
+	}
+	if res.Passed || res.Detail != "failed" {
+		t.Errorf("loopback result = %+v", res)
+	}
+	if !slices.Contains(mock.Commands, "interface gpon 0/1") {
+		t.Errorf("commands = %v", mock.Commands)
+	}
+
+	if _, err := adapter.RunONUTest(context.Background(), "0/1", 5, types.ONUTest{Kind: types.ONUTestLoopback, Port: 9}); err == nil {
+		t.Error("expected validation error for port 9")
+	}
+}
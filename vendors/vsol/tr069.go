@@ -0,0 +1,29 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/model"
+)
+
+// setONUTR069 configures the TR-069 management WAN and ACS of an ONU with
+// the PRI command set. GPON only.
+func (a *Adapter) setONUTR069(ctx context.Context, ponPort string, onuID int, t *model.TR069Service) error {
+	if err := a.execONUCommands(ctx, ponPort, tr069Commands(onuID, t)...); err != nil {
+		return fmt.Errorf("vsol ONU TR-069 configuration failed: %w", err)
+	}
+	return nil
+}
+
+// tr069Commands returns the interface gpon commands applying t
+func tr069Commands(onuID int, t *model.TR069Service) []string {
+	acs := fmt.Sprintf("onu %d pri tr069_acs url %s", onuID, t.ACSURL)
+	if t.Username != "" {
+		acs += fmt.Sprintf(" username %s password %s", t.Username, t.Password)
+	}
+	return []string{
+		fmt.Sprintf("onu %d pri tr069_wan vlan %d priority %d mode dhcp", onuID, t.VLAN, t.GetPriority()),
+		acs,
+	}
+}
@@ -0,0 +1,39 @@
+package vsol
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestCreateSubscriberWithTR069(t *testing.T) {
+	sub := &model.Subscriber{
+		Name:        "test-sub",
+		Annotations: map[string]string{"nanoncore.com/pon-port": "0/1", "nanoncore.com/onu-id": "5"},
+		Spec: model.SubscriberSpec{ONUSerial: "FHTT12345678", VLAN: 100,
+			TR069: &model.TR069Service{VLAN: 46, ACSURL: "http://acs.example.net:7547"}},
+	}
+
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "gpon"}}}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	for _, want := range []string{
+		"onu 5 pri tr069_wan vlan 46 priority 5 mode dhcp",
+		"onu 5 pri tr069_acs url http://acs.example.net:7547",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	adapter = &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "epon"}}}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err == nil {
+		t.Error("TR-069 on EPON should be rejected")
+	}
+}
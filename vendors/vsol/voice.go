@@ -0,0 +1,46 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.ONUVoiceManager = (*Adapter)(nil)
+
+// SetONUVoice configures the voice WAN, SIP server, SIP accounts and POTS
+// port states of an ONU with the PRI command set. GPON only.
+func (a *Adapter) SetONUVoice(ctx context.Context, ponPort string, onuID int, voice *model.VoiceService) error {
+	if voice == nil {
+		return fmt.Errorf("voice service is required")
+	}
+	if err := voice.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "vsol"}
+	}
+	if err := a.execONUCommands(ctx, ponPort, voiceCommands(onuID, voice)...); err != nil {
+		return fmt.Errorf("vsol ONU voice configuration failed: %w", err)
+	}
+	return nil
+}
+
+// voiceCommands returns the interface gpon commands applying voice
+func voiceCommands(onuID int, voice *model.VoiceService) []string {
+	commands := []string{
+		fmt.Sprintf("onu %d pri voip_wan vlan %d priority 5 mode dhcp", onuID, voice.VLAN),
+		fmt.Sprintf("onu %d pri sip_server %s port %d", onuID, voice.Server, voice.GetServerPort()),
+	}
+	for _, l := range voice.Lines {
+		account := fmt.Sprintf("onu %d pri sip_account %d username %s auth_name %s", onuID, l.Port, l.User, l.GetAuthUser())
+		if l.Password != "" {
+			account += " password " + l.Password
+		}
+		state := "disable"
+		if l.IsEnabled() {
+			state = "enable"
+		}
+		commands = append(commands, account, fmt.Sprintf("onu %d pri pots %d %s", onuID, l.Port, state))
+	}
+	return commands
+}
@@ -0,0 +1,74 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func testVoice() *model.VoiceService {
+	disabled := false
+	return &model.VoiceService{VLAN: 200, Server: "sip.example.net", Lines: []model.VoiceLine{
+		{Port: 1, User: "5550101", Password: "secret"},
+		{Port: 2, User: "5550102", Enabled: &disabled},
+	}}
+}
+
+func TestSetONUVoice(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{}}
+
+	if err := adapter.SetONUVoice(context.Background(), "0/1", 7, testVoice()); err != nil {
+		t.Fatalf("SetONUVoice() error = %v", err)
+	}
+	for _, want := range []string{
+		"onu 7 pri voip_wan vlan 200 priority 5 mode dhcp",
+		"onu 7 pri sip_server sip.example.net port 5060",
+		"onu 7 pri sip_account 1 username 5550101 auth_name 5550101 password secret",
+		"onu 7 pri pots 1 enable",
+		"onu 7 pri sip_account 2 username 5550102 auth_name 5550102",
+		"onu 7 pri pots 2 disable",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	invalid := testVoice()
+	invalid.Server = ""
+	if err := adapter.SetONUVoice(context.Background(), "0/1", 7, invalid); err == nil {
+		t.Error("voice without server should be rejected")
+	}
+}
+
+func TestCreateSubscriberWithVoice(t *testing.T) {
+	sub := &model.Subscriber{
+		Name:        "test-sub",
+		Annotations: map[string]string{"nanoncore.com/pon-port": "0/1", "nanoncore.com/onu-id": "5"},
+		Spec:        model.SubscriberSpec{ONUSerial: "FHTT12345678", VLAN: 100, Voice: testVoice()},
+	}
+
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "gpon"}}}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	if !slices.Contains(mock.Commands, "onu 5 pri sip_server sip.example.net port 5060") {
+		t.Errorf("voice not provisioned: %v", mock.Commands)
+	}
+
+	// A failed voice configuration deletes the ONU again
+	mock = &testutil.MockCLIExecutor{Errors: map[string]error{"onu 5 pri pots 1 enable": fmt.Errorf("%% Error")}}
+	adapter = &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "gpon"}}}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err == nil {
+		t.Fatal("expected error")
+	}
+	if !slices.Contains(mock.Commands, "no onu 5") {
+		t.Errorf("ONU not deleted: %v", mock.Commands)
+	}
+}
@@ -0,0 +1,66 @@
+package vsol
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+var _ types.ONUModeSwitcher = (*Adapter)(nil)
+
+// setONUWAN configures the internet WAN of a router-mode ONU with the PRI
+// command set: the PPPoE or DHCP connection and the LAN ports routed to it.
+// GPON only.
+func (a *Adapter) setONUWAN(ctx context.Context, ponPort string, onuID int, cfg *types.ONUWANConfig) error {
+	if err := a.execONUCommands(ctx, ponPort, wanCommands(onuID, cfg)...); err != nil {
+		return fmt.Errorf("vsol ONU WAN configuration failed: %w", err)
+	}
+	return nil
+}
+
+// wanCommands returns the interface gpon commands applying cfg
+func wanCommands(onuID int, cfg *types.ONUWANConfig) []string {
+	wan := fmt.Sprintf("onu %d pri internet_wan vlan %d priority %d mode dhcp", onuID, cfg.VLAN, cfg.Priority)
+	if cfg.Mode == types.WANModePPPoE {
+		wan = fmt.Sprintf("onu %d pri internet_wan vlan %d priority %d mode pppoe username %s password %s",
+			onuID, cfg.VLAN, cfg.Priority, cfg.Username, cfg.Password)
+	}
+	commands := []string{wan}
+	if len(cfg.LANPorts) > 0 {
+		ports := make([]string, len(cfg.LANPorts))
+		for i, p := range cfg.LANPorts {
+			ports[i] = strconv.Itoa(p)
+		}
+		commands = append(commands, fmt.Sprintf("onu %d pri internet_wan bind_lan %s", onuID, strings.Join(ports, ",")))
+	}
+	return commands
+}
+
+// SetONUMode switches an ONU between bridge and router mode with the PRI
+// command set. A router gets the internet WAN, the routed LAN ports and,
+// with cfg.LAN, its LAN address; a bridge loses the internet WAN and its
+// Ethernet ports tag the service VLAN, as in provisioning. GPON only.
+func (a *Adapter) SetONUMode(ctx context.Context, ponPort string, onuID int, cfg *types.ONUModeConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return &types.HumanError{Code: types.ErrCodeValidationFailed, Message: err.Error(), Vendor: "vsol"}
+	}
+	var commands []string
+	if cfg.Mode == types.ONUModeRouter {
+		commands = wanCommands(onuID, cfg.WAN)
+		if cfg.LAN != nil {
+			commands = append(commands, lanCommands(onuID, cfg.LAN)...)
+		}
+	} else {
+		commands = []string{fmt.Sprintf("no onu %d pri internet_wan", onuID)}
+		for _, eth := range cfg.BridgePorts() {
+			commands = append(commands, fmt.Sprintf("onu %d portvlan eth %d mode tag vlan %d", onuID, eth, cfg.VLAN))
+		}
+	}
+	if err := a.execONUCommands(ctx, ponPort, commands...); err != nil {
+		return fmt.Errorf("vsol ONU mode change failed: %w", err)
+	}
+	return nil
+}
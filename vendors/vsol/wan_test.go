@@ -0,0 +1,87 @@
+package vsol
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nanoncore/nano-southbound/model"
+	"github.com/nanoncore/nano-southbound/testutil"
+	"github.com/nanoncore/nano-southbound/types"
+)
+
+func TestCreateSubscriberWithWAN(t *testing.T) {
+	sub := &model.Subscriber{
+		Name:        "test-sub",
+		Annotations: map[string]string{"nanoncore.com/pon-port": "0/1", "nanoncore.com/onu-id": "5"},
+		Spec: model.SubscriberSpec{ONUSerial: "FHTT12345678", VLAN: 100, Username: "user@isp", Password: "secret",
+			WAN: &model.WANService{LANPorts: []int{1, 2}}},
+	}
+
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "gpon"}}}
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err != nil {
+		t.Fatalf("CreateSubscriber() error = %v", err)
+	}
+	for _, want := range []string{
+		"onu 5 pri internet_wan vlan 100 priority 0 mode pppoe username user@isp password secret",
+		"onu 5 pri internet_wan bind_lan 1,2",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	if got := wanCommands(5, &types.ONUWANConfig{Mode: types.WANModeDHCP, VLAN: 200}); !slices.Equal(got, []string{"onu 5 pri internet_wan vlan 200 priority 0 mode dhcp"}) {
+		t.Errorf("wanCommands(dhcp) = %v", got)
+	}
+
+	sub.Spec.WAN = &model.WANService{}
+	sub.Spec.Username = ""
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err == nil {
+		t.Error("PPPoE WAN without credentials should be rejected")
+	}
+	adapter = &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "epon"}}}
+	sub.Spec.Username = "user@isp"
+	if _, err := adapter.CreateSubscriber(context.Background(), sub, &model.ServiceTier{}); err == nil {
+		t.Error("WAN on EPON should be rejected")
+	}
+}
+
+func TestSetONUMode(t *testing.T) {
+	mock := &testutil.MockCLIExecutor{}
+	adapter := &Adapter{cliExecutor: mock, config: &types.EquipmentConfig{Metadata: map[string]string{"pon_type": "gpon"}}}
+	ctx := context.Background()
+
+	err := adapter.SetONUMode(ctx, "0/1", 5, &types.ONUModeConfig{
+		Mode: types.ONUModeRouter,
+		WAN:  &types.ONUWANConfig{Mode: types.WANModeDHCP, VLAN: 100, LANPorts: []int{1}},
+		LAN:  &types.ONULANConfig{IPAddress: "192.168.1.1", Netmask: "255.255.255.0"},
+	})
+	if err != nil {
+		t.Fatalf("SetONUMode(router) error = %v", err)
+	}
+	for _, want := range []string{
+		"onu 5 pri internet_wan vlan 100 priority 0 mode dhcp",
+		"onu 5 pri internet_wan bind_lan 1",
+		"onu 5 pri lan_ip 192.168.1.1 mask 255.255.255.0",
+	} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	mock.Commands = nil
+	if err := adapter.SetONUMode(ctx, "0/1", 5, &types.ONUModeConfig{Mode: types.ONUModeBridge, VLAN: 100}); err != nil {
+		t.Fatalf("SetONUMode(bridge) error = %v", err)
+	}
+	for _, want := range []string{"no onu 5 pri internet_wan", "onu 5 portvlan eth 1 mode tag vlan 100"} {
+		if !slices.Contains(mock.Commands, want) {
+			t.Errorf("missing command %q in %v", want, mock.Commands)
+		}
+	}
+
+	if err := adapter.SetONUMode(ctx, "0/1", 5, &types.ONUModeConfig{Mode: types.ONUModeBridge}); err == nil {
+		t.Error("bridge mode without a VLAN should be rejected")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/nanoncore/nano-southbound/types"
+	"github.com/nanoncore/nano-southbound/vendors/common"
 )
 
 var _ types.WifiManager = (*Adapter)(nil)
@@ -130,6 +131,13 @@ func (a *Adapter) SetWifiConfig(ctx context.Context, target types.WifiTarget, cf
 			Reason:    "password must be at least 8 characters",
 		}, nil
 	}
+	if reason := validateWifiRadio(target.Radio, cfg.Channel); reason != "" {
+		return &types.WifiActionResult{
+			OK:        false,
+			ErrorCode: types.WifiErrorCodeInvalidValue,
+			Reason:    reason,
+		}, nil
+	}
 
 	ponPort, onuID, result := a.resolveWifiTarget(ctx, target)
 	if result != nil {
@@ -160,11 +168,15 @@ func (a *Adapter) SetWifiConfig(ctx context.Context, target types.WifiTarget, cf
 		}, nil
 	}
 
+	if result := unsupportedWifiRadio(profile, target.Radio); result != nil {
+		return result, nil
+	}
+
 	steps := []wifiStep{
 		{name: "ENTER_CONFIG", command: "configure terminal"},
 		{name: "ENTER_PON_INTERFACE", command: fmt.Sprintf("interface gpon %s", ponPort)},
 	}
-	steps = append(steps, wifiConfigSteps(profile, onuID, cfg, a.priDefaults())...)
+	steps = append(steps, wifiConfigSteps(profile, onuID, target.Radio, cfg, a.priDefaults())...)
 	steps = append(steps,
 		wifiStep{name: "EXIT_INTERFACE", command: "exit"},
 		wifiStep{name: "COMMIT", command: "end"},
@@ -174,7 +186,8 @@ func (a *Adapter) SetWifiConfig(ctx context.Context, target types.WifiTarget, cf
 	if !applyResult.OK {
 		return applyResult, nil
 	}
-	if !a.omciWifiReadbackEnabled() {
+	// Readback only covers the first radio
+	if !a.omciWifiReadbackEnabled() || target.Radio == types.WifiRadio5GHz {
 		return applyResult, nil
 	}
 	return a.verifyWifiApply(ctx, target, cfg, applyResult), nil
@@ -188,6 +201,13 @@ func (a *Adapter) SetWifiEnabled(ctx context.Context, target types.WifiTarget, e
 			Reason:    "CLI executor not available",
 		}, nil
 	}
+	if reason := validateWifiRadio(target.Radio, ""); reason != "" {
+		return &types.WifiActionResult{
+			OK:        false,
+			ErrorCode: types.WifiErrorCodeInvalidValue,
+			Reason:    reason,
+		}, nil
+	}
 
 	ponPort, onuID, result := a.resolveWifiTarget(ctx, target)
 	if result != nil {
@@ -218,10 +238,14 @@ func (a *Adapter) SetWifiEnabled(ctx context.Context, target types.WifiTarget, e
 		}, nil
 	}
 
+	if result := unsupportedWifiRadio(profile, target.Radio); result != nil {
+		return result, nil
+	}
+
 	steps := []wifiStep{
 		{name: "ENTER_CONFIG", command: "configure terminal"},
 		{name: "ENTER_PON_INTERFACE", command: fmt.Sprintf("interface gpon %s", ponPort)},
-		{name: "ENABLE_WIFI", command: wifiEnableCommand(profile, onuID, enabled, a.priDefaults())},
+		{name: "ENABLE_WIFI", command: wifiEnableCommand(profile, onuID, target.Radio, enabled, a.priDefaults())},
 		{name: "EXIT_INTERFACE", command: "exit"},
 		{name: "COMMIT", command: "end"},
 	}
@@ -230,7 +254,7 @@ func (a *Adapter) SetWifiEnabled(ctx context.Context, target types.WifiTarget, e
 	if !applyResult.OK {
 		return applyResult, nil
 	}
-	if !a.omciWifiReadbackEnabled() {
+	if !a.omciWifiReadbackEnabled() || target.Radio == types.WifiRadio5GHz {
 		return applyResult, nil
 	}
 	return a.verifyWifiApply(ctx, target, types.WifiConfig{Enabled: enabled}, applyResult), nil
@@ -526,9 +550,8 @@ func (a *Adapter) resolveONUBySerialFromInventory(ctx context.Context, serial st
 		return nil, nil
 	}
 
-	serialTrimmed := strings.TrimSpace(serial)
 	for i := range onus {
-		if strings.EqualFold(strings.TrimSpace(onus[i].Serial), serialTrimmed) {
+		if common.SerialsEqual(onus[i].Serial, serial) {
 			return &onus[i], nil
 		}
 	}
@@ -709,12 +732,15 @@ func ptrTime(t time.Time) *time.Time {
 	return &t
 }
 
-func wifiConfigSteps(profile wifiCommandProfile, onuID int, cfg types.WifiConfig, priDefaults priWifiDefaults) []wifiStep {
+func wifiConfigSteps(profile wifiCommandProfile, onuID int, radio types.WifiRadio, cfg types.WifiConfig, priDefaults priWifiDefaults) []wifiStep {
 	switch profile {
 	case wifiCommandProfilePri:
+		if channel := strings.TrimSpace(cfg.Channel); channel != "" {
+			priDefaults.Channel = priChannel(channel)
+		}
 		return []wifiStep{
-			{name: "SET_SSID", command: priSSIDCommand(onuID, cfg), sensitive: len(strings.TrimSpace(cfg.Password)) > 0},
-			{name: "ENABLE_WIFI", command: wifiEnableCommand(profile, onuID, cfg.Enabled, priDefaults)},
+			{name: "SET_SSID", command: priSSIDCommand(onuID, radio, cfg), sensitive: len(strings.TrimSpace(cfg.Password)) > 0},
+			{name: "ENABLE_WIFI", command: wifiEnableCommand(profile, onuID, radio, cfg.Enabled, priDefaults)},
 		}
 	default:
 		steps := []wifiStep{
@@ -727,20 +753,83 @@ func wifiConfigSteps(profile wifiCommandProfile, onuID int, cfg types.WifiConfig
 				sensitive: true,
 			})
 		}
-		steps = append(steps, wifiStep{name: "ENABLE_WIFI", command: wifiEnableCommand(profile, onuID, cfg.Enabled, priDefaults)})
+		if channel := strings.ToLower(strings.TrimSpace(cfg.Channel)); channel != "" {
+			steps = append(steps, wifiStep{name: "SET_CHANNEL", command: fmt.Sprintf("onu %d wifi channel %s", onuID, channel)})
+		}
+		steps = append(steps, wifiStep{name: "ENABLE_WIFI", command: wifiEnableCommand(profile, onuID, radio, cfg.Enabled, priDefaults)})
 		return steps
 	}
 }
 
-func wifiEnableCommand(profile wifiCommandProfile, onuID int, enabled bool, defaults priWifiDefaults) string {
+// priRadioIndexes returns the wifi_switch index of a radio and the index
+// of its first SSID. PRI firmware numbers SSIDs 1-4 on the first radio
+// and 5-8 on the second.
+func priRadioIndexes(radio types.WifiRadio) (int, int) {
+	if radio == types.WifiRadio5GHz {
+		return 2, 5
+	}
+	return 1, 1
+}
+
+// priChannel converts a channel to the PRI syntax: "auto" or "chl_<n>"
+func priChannel(channel string) string {
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	if _, err := strconv.Atoi(channel); err == nil {
+		return "chl_" + channel
+	}
+	return channel
+}
+
+// validateWifiRadio checks the radio of a target and a channel for it,
+// returning the reason they are invalid or ""
+func validateWifiRadio(radio types.WifiRadio, channel string) string {
+	switch radio {
+	case "", types.WifiRadio24GHz, types.WifiRadio5GHz:
+	default:
+		return fmt.Sprintf("unknown radio %q", radio)
+	}
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	if channel == "" || channel == "auto" {
+		return ""
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(channel, "chl_"))
+	switch {
+	case err != nil:
+		return fmt.Sprintf("invalid channel %q", channel)
+	case radio == types.WifiRadio5GHz && (n < 36 || n > 177):
+		return fmt.Sprintf("channel %d is not a 5GHz channel", n)
+	case radio == types.WifiRadio24GHz && (n < 1 || n > 14):
+		return fmt.Sprintf("channel %d is not a 2.4GHz channel", n)
+	case n < 1 || n > 177:
+		return fmt.Sprintf("invalid channel %d", n)
+	}
+	return ""
+}
+
+// unsupportedWifiRadio fails actions on the 5GHz radio with the legacy
+// command set, which only manages one radio
+func unsupportedWifiRadio(profile wifiCommandProfile, radio types.WifiRadio) *types.WifiActionResult {
+	if profile == wifiCommandProfilePri || radio != types.WifiRadio5GHz {
+		return nil
+	}
+	return &types.WifiActionResult{
+		OK:        false,
+		ErrorCode: types.WifiErrorCodeUnsupportedOperation,
+		Reason:    "legacy Wi-Fi commands manage a single radio; 5GHz requires the pri command profile",
+	}
+}
+
+func wifiEnableCommand(profile wifiCommandProfile, onuID int, radio types.WifiRadio, enabled bool, defaults priWifiDefaults) string {
 	switch profile {
 	case wifiCommandProfilePri:
+		index, _ := priRadioIndexes(radio)
 		if enabled {
 			// PRI path is firmware-specific and therefore metadata-driven.
 			if strings.TrimSpace(defaults.Width) != "" {
 				return fmt.Sprintf(
-					"onu %d pri wifi_switch 1 enable %s %s %s %d %s",
+					"onu %d pri wifi_switch %d enable %s %s %s %d %s",
 					onuID,
+					index,
 					defaults.Country,
 					defaults.Channel,
 					defaults.Standard,
@@ -749,15 +838,16 @@ func wifiEnableCommand(profile wifiCommandProfile, onuID int, enabled bool, defa
 				)
 			}
 			return fmt.Sprintf(
-				"onu %d pri wifi_switch 1 enable %s %s %s %d",
+				"onu %d pri wifi_switch %d enable %s %s %s %d",
 				onuID,
+				index,
 				defaults.Country,
 				defaults.Channel,
 				defaults.Standard,
 				defaults.Power,
 			)
 		}
-		return fmt.Sprintf("onu %d pri wifi_switch 1 disable", onuID)
+		return fmt.Sprintf("onu %d pri wifi_switch %d disable", onuID, index)
 	default:
 		if enabled {
 			return fmt.Sprintf("onu %d wifi enable", onuID)
@@ -766,20 +856,23 @@ func wifiEnableCommand(profile wifiCommandProfile, onuID int, enabled bool, defa
 	}
 }
 
-func priSSIDCommand(onuID int, cfg types.WifiConfig) string {
+func priSSIDCommand(onuID int, radio types.WifiRadio, cfg types.WifiConfig) string {
+	_, index := priRadioIndexes(radio)
 	ssid := strings.TrimSpace(cfg.SSID)
 	password := strings.TrimSpace(cfg.Password)
 	if password == "" {
 		return fmt.Sprintf(
-			"onu %d pri wifi_ssid 1 name %s hide disable auth_mode open encrypt_type none",
+			"onu %d pri wifi_ssid %d name %s hide disable auth_mode open encrypt_type none",
 			onuID,
+			index,
 			quoteArg(ssid),
 		)
 	}
 
 	return fmt.Sprintf(
-		"onu %d pri wifi_ssid 1 name %s hide disable auth_mode wpa2psk encrypt_type aes shared_key %s rekey_interval 3600",
+		"onu %d pri wifi_ssid %d name %s hide disable auth_mode wpa2psk encrypt_type aes shared_key %s rekey_interval 3600",
 		onuID,
+		index,
 		quoteArg(ssid),
 		quoteArg(password),
 	)
@@ -865,8 +958,8 @@ func (a *Adapter) forcedWifiCommandProfile() (wifiCommandProfile, error) {
 func (a *Adapter) hintWifiCommandProfile(ctx context.Context, serial string) wifiCommandProfile {
 	model := ""
 	firmware := ""
-	if a.config != nil && a.config.Metadata != nil {
-		model = strings.ToLower(strings.TrimSpace(a.config.Metadata["model"]))
+	if a.config != nil {
+		model = strings.ToLower(strings.TrimSpace(a.config.DeviceModel()))
 		firmware = strings.ToLower(strings.TrimSpace(a.config.Metadata["firmware"]))
 	}
 	if model == "" && strings.TrimSpace(serial) != "" {
@@ -886,8 +979,8 @@ func (a *Adapter) wifiCommandProfileCacheKey(_ context.Context, serial, ponPort
 	model := ""
 	firmware := ""
 	onuKey := strings.ToLower(strings.TrimSpace(serial))
-	if a.config != nil && a.config.Metadata != nil {
-		model = strings.ToLower(strings.TrimSpace(a.config.Metadata["model"]))
+	if a.config != nil {
+		model = strings.ToLower(strings.TrimSpace(a.config.DeviceModel()))
 		firmware = strings.ToLower(strings.TrimSpace(a.config.Metadata["firmware"]))
 	}
 	if model == "" {
@@ -1254,6 +1254,104 @@ func TestClassifyWifiErrCode_PRIUnsupported(t *testing.T) {
 	}
 }
 
+func TestSetWifiConfig_PriProfile_5GHzChannel(t *testing.T) {
+	mock := &wifiMockCLI{outputByCommand: map[string]string{}, errByCommand: map[string]error{}}
+	adapter := &Adapter{
+		cliExecutor: mock,
+		config: &types.EquipmentConfig{
+			Metadata: map[string]string{
+				"skip_omci_profile_check": "true",
+				"wifi_command_profile":    "pri",
+			},
+		},
+	}
+
+	result, err := adapter.SetWifiConfig(context.Background(), types.WifiTarget{PONPort: "0/2", ONUID: 9, Radio: types.WifiRadio5GHz}, types.WifiConfig{
+		SSID:     "Lab-5G",
+		Password: "StrongPass1",
+		Enabled:  true,
+		Channel:  "44",
+	})
+	if err != nil || !result.OK {
+		t.Fatalf("SetWifiConfig = %+v, %v", result, err)
+	}
+	if !containsString(mock.commands, "onu 9 pri wifi_ssid 5 name \"Lab-5G\" hide disable auth_mode wpa2psk encrypt_type aes shared_key \"StrongPass1\" rekey_interval 3600") {
+		t.Fatalf("expected SSID on the second radio, got sequence: %+v", mock.commands)
+	}
+	if !containsString(mock.commands, "onu 9 pri wifi_switch 2 enable global chl_44 80211acanac 10") {
+		t.Fatalf("expected second radio enabled on channel 44, got sequence: %+v", mock.commands)
+	}
+}
+
+func TestSetWifiEnabled_PriProfile_5GHz(t *testing.T) {
+	mock := &wifiMockCLI{outputByCommand: map[string]string{}, errByCommand: map[string]error{}}
+	adapter := &Adapter{
+		cliExecutor: mock,
+		config: &types.EquipmentConfig{
+			Metadata: map[string]string{
+				"skip_omci_profile_check": "true",
+				"wifi_command_profile":    "pri",
+			},
+		},
+	}
+
+	result, err := adapter.SetWifiEnabled(context.Background(), types.WifiTarget{PONPort: "0/2", ONUID: 9, Radio: types.WifiRadio5GHz}, false)
+	if err != nil || !result.OK {
+		t.Fatalf("SetWifiEnabled = %+v, %v", result, err)
+	}
+	if !containsString(mock.commands, "onu 9 pri wifi_switch 2 disable") {
+		t.Fatalf("expected second radio disabled, got sequence: %+v", mock.commands)
+	}
+}
+
+func TestSetWifiConfig_LegacyProfile_Channel(t *testing.T) {
+	mock := &wifiMockCLI{outputByCommand: map[string]string{}, errByCommand: map[string]error{}}
+	adapter := &Adapter{
+		cliExecutor: mock,
+		config: &types.EquipmentConfig{
+			Metadata: map[string]string{
+				"skip_omci_profile_check": "true",
+				"wifi_command_profile":    "legacy",
+			},
+		},
+	}
+	target := types.WifiTarget{PONPort: "0/1", ONUID: 7}
+
+	result, err := adapter.SetWifiConfig(context.Background(), target, types.WifiConfig{SSID: "Home", Enabled: true, Channel: "6"})
+	if err != nil || !result.OK {
+		t.Fatalf("SetWifiConfig = %+v, %v", result, err)
+	}
+	if !containsString(mock.commands, "onu 7 wifi channel 6") {
+		t.Fatalf("expected channel command, got sequence: %+v", mock.commands)
+	}
+
+	// The legacy command set has no second radio
+	target.Radio = types.WifiRadio5GHz
+	result, _ = adapter.SetWifiConfig(context.Background(), target, types.WifiConfig{SSID: "Home", Enabled: true})
+	if result.OK || result.ErrorCode != types.WifiErrorCodeUnsupportedOperation {
+		t.Fatalf("expected UNSUPPORTED_OPERATION, got %+v", result)
+	}
+}
+
+func TestSetWifiConfig_InvalidChannel(t *testing.T) {
+	adapter := &Adapter{cliExecutor: &wifiMockCLI{}, config: &types.EquipmentConfig{}}
+	for _, tc := range []struct {
+		radio   types.WifiRadio
+		channel string
+	}{
+		{types.WifiRadio24GHz, "36"},
+		{types.WifiRadio5GHz, "6"},
+		{"", "fast"},
+		{"6GHz", ""},
+	} {
+		result, err := adapter.SetWifiConfig(context.Background(), types.WifiTarget{PONPort: "0/1", ONUID: 7, Radio: tc.radio},
+			types.WifiConfig{SSID: "Home", Channel: tc.channel})
+		if err != nil || result.OK || result.ErrorCode != types.WifiErrorCodeInvalidValue {
+			t.Errorf("radio %q channel %q: got %+v, %v", tc.radio, tc.channel, result, err)
+		}
+	}
+}
+
 func containsString(values []string, target string) bool {
 	for _, value := range values {
 		if value == target {
@@ -13,7 +13,8 @@ type Adapter struct {
 	config     *types.EquipmentConfig
 }
 
-func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig) types.Driver {
+func NewAdapter(baseDriver types.Driver, config *types.EquipmentConfig, opts ...types.Option) types.Driver {
+	config.Apply(opts...)
 	return &Adapter{baseDriver: baseDriver, config: config}
 }
 
@@ -29,6 +30,16 @@ func (a *Adapter) IsConnected() bool {
 	return a.baseDriver.IsConnected()
 }
 
+// DriverMetrics implements types.MetricsProvider
+func (a *Adapter) DriverMetrics() types.DriverMetrics {
+	return types.CollectMetrics(a.baseDriver)
+}
+
+// Capabilities implements types.CapabilityReporter
+func (a *Adapter) Capabilities() types.Capabilities {
+	return types.DetectCapabilities(a)
+}
+
 func (a *Adapter) CreateSubscriber(ctx context.Context, subscriber *model.Subscriber, tier *model.ServiceTier) (*types.SubscriberResult, error) {
 	result, err := a.baseDriver.CreateSubscriber(ctx, subscriber, tier)
 	if err == nil && result != nil {
@@ -0,0 +1,145 @@
+// Package workers bounds the fan-out of work across devices.
+//
+// A Pool limits how many tasks run at once, and how many of them run per
+// key. Keys are usually device names, so with the default of one task per
+// key no two tasks, e.g. two writes from different subsystems, ever run
+// against the same OLT concurrently. Subsystems that share a Pool share
+// both limits.
+package workers
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultSize is the Pool size used when New is given zero
+const DefaultSize = 8
+
+// Pool bounds concurrent tasks overall and per key. It is safe for
+// concurrent use. A task must not acquire its own key again, which would
+// deadlock with a per-key limit of one.
+type Pool struct {
+	slots  chan struct{}
+	perKey int
+
+	mu   sync.Mutex
+	keys map[string]*keySlots
+}
+
+// keySlots limits the tasks of one key. It is dropped once unused.
+type keySlots struct {
+	ch   chan struct{}
+	refs int
+}
+
+// New creates a Pool running at most size tasks at once, and at most
+// perKey with the same key. Zero values use DefaultSize and one task per
+// key; a negative perKey removes the per-key limit.
+func New(size, perKey int) *Pool {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	if perKey == 0 {
+		perKey = 1
+	}
+	return &Pool{
+		slots:  make(chan struct{}, size),
+		perKey: perKey,
+		keys:   make(map[string]*keySlots),
+	}
+}
+
+// Acquire blocks until a task with key may run and returns the function
+// that releases it. The key is taken before the pool slot, so tasks
+// waiting for a busy device do not hold slots other devices could use.
+func (p *Pool) Acquire(ctx context.Context, key string) (release func(), err error) {
+	var ks *keySlots
+	if p.perKey > 0 {
+		p.mu.Lock()
+		ks = p.keys[key]
+		if ks == nil {
+			ks = &keySlots{ch: make(chan struct{}, p.perKey)}
+			p.keys[key] = ks
+		}
+		ks.refs++
+		p.mu.Unlock()
+
+		select {
+		case ks.ch <- struct{}{}:
+		case <-ctx.Done():
+			p.unref(key, ks)
+			return nil, ctx.Err()
+		}
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		if ks != nil {
+			<-ks.ch
+			p.unref(key, ks)
+		}
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-p.slots
+			if ks != nil {
+				<-ks.ch
+				p.unref(key, ks)
+			}
+		})
+	}, nil
+}
+
+func (p *Pool) unref(key string, ks *keySlots) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ks.refs--; ks.refs == 0 {
+		delete(p.keys, key)
+	}
+}
+
+// Do runs fn once a task with key may run. It returns ctx.Err() without
+// running fn if ctx is done first.
+func (p *Pool) Do(ctx context.Context, key string, fn func() error) error {
+	release, err := p.Acquire(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn()
+}
+
+// Each runs fn(i) for every index of keys concurrently within the pool's
+// limits and returns the errors by index. Each task waits for its own
+// slot, so a busy key does not hold back the others; tasks not started
+// when ctx is done get ctx.Err(). A nil Pool runs every task at once.
+func (p *Pool) Each(ctx context.Context, keys []string, fn func(i int) error) []error {
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if p != nil {
+				release, err := p.Acquire(ctx, key)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				defer release()
+			}
+			errs[i] = fn(i)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// Busy reports the number of running tasks
+func (p *Pool) Busy() int {
+	return len(p.slots)
+}
@@ -0,0 +1,104 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolLimits(t *testing.T) {
+	p := New(3, 1)
+	var (
+		running, peak atomic.Int32
+		mu            sync.Mutex
+		perKey        = map[string]int{}
+	)
+	keys := []string{"olt-1", "olt-1", "olt-2", "olt-2", "olt-3", "olt-4", "olt-1"}
+	errs := p.Each(context.Background(), keys, func(i int) error {
+		mu.Lock()
+		perKey[keys[i]]++
+		if perKey[keys[i]] > 1 {
+			t.Errorf("two tasks ran on %s at once", keys[i])
+		}
+		mu.Unlock()
+
+		n := running.Add(1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+
+		mu.Lock()
+		perKey[keys[i]]--
+		mu.Unlock()
+		return nil
+	})
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("task %d error = %v", i, err)
+		}
+	}
+	if peak.Load() > 3 {
+		t.Errorf("peak concurrency = %d, want at most 3", peak.Load())
+	}
+	if len(p.keys) != 0 || p.Busy() != 0 {
+		t.Errorf("pool not drained: %d keys, %d busy", len(p.keys), p.Busy())
+	}
+}
+
+func TestPoolCancel(t *testing.T) {
+	p := New(1, 1)
+	release, err := p.Acquire(context.Background(), "olt-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	ran := false
+	if err := p.Do(ctx, "olt-2", func() error { ran = true; return nil }); !errors.Is(err, context.DeadlineExceeded) || ran {
+		t.Errorf("Do() on a full pool = %v, ran = %v", err, ran)
+	}
+
+	errs := p.Each(ctx, []string{"olt-1", "olt-2"}, func(int) error { return nil })
+	if !errors.Is(errs[0], context.DeadlineExceeded) || !errors.Is(errs[1], context.DeadlineExceeded) {
+		t.Errorf("Each() on a full pool = %v", errs)
+	}
+
+	release()
+	release() // idempotent
+	if err := p.Do(context.Background(), "olt-1", func() error { return nil }); err != nil {
+		t.Errorf("Do() after release = %v", err)
+	}
+	if len(p.keys) != 0 || p.Busy() != 0 {
+		t.Errorf("pool not drained: %d keys, %d busy", len(p.keys), p.Busy())
+	}
+}
+
+func TestPoolEachBusyKey(t *testing.T) {
+	p := New(2, 1)
+	// Another user of the pool holds olt-1
+	release, err := p.Acquire(context.Background(), "olt-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var ran atomic.Int32
+	errs := p.Each(ctx, []string{"olt-1", "olt-2", "olt-3"}, func(int) error { ran.Add(1); return nil })
+	if !errors.Is(errs[0], context.DeadlineExceeded) || errs[1] != nil || errs[2] != nil {
+		t.Errorf("Each() = %v", errs)
+	}
+	if ran.Load() != 2 {
+		t.Errorf("ran %d tasks, want 2", ran.Load())
+	}
+}